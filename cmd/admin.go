@@ -9,6 +9,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 	"text/tabwriter"
@@ -17,22 +18,24 @@ import (
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/log"
 	pwd "code.gitea.io/gitea/modules/password"
 	repo_module "code.gitea.io/gitea/modules/repository"
-	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
 	auth_service "code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/auth/source/smtp"
+	"code.gitea.io/gitea/services/orgpolicy"
 	repo_service "code.gitea.io/gitea/services/repository"
 	user_service "code.gitea.io/gitea/services/user"
 
 	"github.com/urfave/cli"
+	"gopkg.in/yaml.v2"
 )
 
 var (
@@ -46,6 +49,7 @@ var (
 			subcmdRegenerate,
 			subcmdAuth,
 			subcmdSendMail,
+			subcmdOrgPolicy,
 		},
 	}
 
@@ -193,6 +197,7 @@ var (
 		Subcommands: []cli.Command{
 			microcmdRegenHooks,
 			microcmdRegenKeys,
+			microcmdRegenFeeds,
 		},
 	}
 
@@ -208,6 +213,67 @@ var (
 		Action: runRegenerateKeys,
 	}
 
+	microcmdRegenFeeds = cli.Command{
+		Name:   "feeds",
+		Usage:  "Regenerate the activity feed of a user or organization",
+		Action: runRegenerateFeeds,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "username,u",
+				Usage: "Name of the user or organization whose feed is regenerated",
+			},
+			cli.DurationFlag{
+				Name:  "older-than",
+				Usage: "Do not restore actions older than this duration, 0 restores everything",
+			},
+		},
+	}
+
+	subcmdOrgPolicy = cli.Command{
+		Name:  "org-policy",
+		Usage: "Export and reconcile organization policies",
+		Subcommands: []cli.Command{
+			microcmdOrgPolicyExport,
+			microcmdOrgPolicyReconcile,
+		},
+	}
+
+	microcmdOrgPolicyExport = cli.Command{
+		Name:   "export",
+		Usage:  "Export the settings, teams and team members of an organization as a policy",
+		Action: runExportOrgPolicy,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "org",
+				Usage: "Name of the organization",
+			},
+			cli.StringFlag{
+				Name:  "file, f",
+				Usage: "Write the policy to this file instead of the standard output",
+			},
+		},
+	}
+
+	microcmdOrgPolicyReconcile = cli.Command{
+		Name:   "reconcile",
+		Usage:  "Report the drift of an organization from a policy and optionally fix it",
+		Action: runReconcileOrgPolicy,
+		Flags: []cli.Flag{
+			cli.StringFlag{
+				Name:  "org",
+				Usage: "Name of the organization",
+			},
+			cli.StringFlag{
+				Name:  "file, f",
+				Usage: "Policy file in YAML format, \"-\" reads it from the standard input",
+			},
+			cli.BoolFlag{
+				Name:  "apply",
+				Usage: "Apply the changes instead of only reporting them",
+			},
+		},
+	}
+
 	subcmdAuth = cli.Command{
 		Name:  "auth",
 		Usage: "Modify external auth providers",
@@ -359,6 +425,15 @@ var (
 			Value: "",
 			Usage: "Group Claim value for restricted users",
 		},
+		cli.StringFlag{
+			Name:  "group-team-map",
+			Value: "",
+			Usage: "JSON mapping between groups and org teams",
+		},
+		cli.BoolFlag{
+			Name:  "group-team-map-removal",
+			Usage: "Activate automatic team membership removal depending on groups",
+		},
 	}
 
 	microcmdAuthUpdateOauth = cli.Command{
@@ -475,20 +550,9 @@ func runChangePassword(c *cli.Context) error {
 	if err := initDB(ctx); err != nil {
 		return err
 	}
-	if len(c.String("password")) < setting.MinPasswordLength {
-		return fmt.Errorf("Password is not long enough. Needs to be at least %d", setting.MinPasswordLength)
-	}
-
-	if !pwd.IsComplexEnough(c.String("password")) {
-		return errors.New("Password does not meet complexity requirements")
-	}
-	pwned, err := pwd.IsPwned(context.Background(), c.String("password"))
-	if err != nil {
+	if err := pwd.Validate(ctx, c.String("password")); err != nil {
 		return err
 	}
-	if pwned {
-		return errors.New("The password you chose is on a list of stolen passwords previously exposed in public data breaches. Please try again with a different password.\nFor more details, see https://haveibeenpwned.com/Passwords")
-	}
 	uname := c.String("username")
 	user, err := user_model.GetUserByName(ctx, uname)
 	if err != nil {
@@ -498,7 +562,7 @@ func runChangePassword(c *cli.Context) error {
 		return err
 	}
 
-	if err = user_model.UpdateUserCols(ctx, user, "passwd", "passwd_hash_algo", "salt"); err != nil {
+	if err = user_model.UpdateUserCols(ctx, user, "passwd", "passwd_hash_algo", "passwd_changed_unix", "salt"); err != nil {
 		return err
 	}
 
@@ -801,6 +865,25 @@ func runRegenerateKeys(_ *cli.Context) error {
 	return asymkey_model.RewriteAllPublicKeys()
 }
 
+func runRegenerateFeeds(c *cli.Context) error {
+	if !c.IsSet("username") {
+		return errors.New("You must provide the username of the feed to regenerate")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	if err := initDB(ctx); err != nil {
+		return err
+	}
+
+	u, err := user_model.GetUserByName(ctx, c.String("username"))
+	if err != nil {
+		return err
+	}
+	return models.RegenerateFeed(ctx, u, c.Duration("older-than"))
+}
+
 func parseOAuth2Config(c *cli.Context) *oauth2.Source {
 	var customURLMapping *oauth2.CustomURLMapping
 	if c.IsSet("use-custom-urls") {
@@ -827,6 +910,8 @@ func parseOAuth2Config(c *cli.Context) *oauth2.Source {
 		GroupClaimName:                c.String("group-claim-name"),
 		AdminGroup:                    c.String("admin-group"),
 		RestrictedGroup:               c.String("restricted-group"),
+		GroupTeamMap:                  c.String("group-team-map"),
+		GroupTeamMapRemoval:           c.Bool("group-team-map-removal"),
 	}
 }
 
@@ -909,6 +994,12 @@ func runUpdateOauth(c *cli.Context) error {
 	if c.IsSet("restricted-group") {
 		oAuth2Config.RestrictedGroup = c.String("restricted-group")
 	}
+	if c.IsSet("group-team-map") {
+		oAuth2Config.GroupTeamMap = c.String("group-team-map")
+	}
+	if c.IsSet("group-team-map-removal") {
+		oAuth2Config.GroupTeamMapRemoval = c.Bool("group-team-map-removal")
+	}
 
 	// update custom URL mapping
 	customURLMapping := &oauth2.CustomURLMapping{}
@@ -1087,6 +1178,92 @@ func runListAuth(c *cli.Context) error {
 	return nil
 }
 
+func getOrgPolicyOrganization(ctx context.Context, c *cli.Context) (*organization.Organization, error) {
+	if !c.IsSet("org") {
+		return nil, fmt.Errorf("--org flag is missing")
+	}
+	if err := initDB(ctx); err != nil {
+		return nil, err
+	}
+	return organization.GetOrgByName(c.String("org"))
+}
+
+func runExportOrgPolicy(c *cli.Context) error {
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	org, err := getOrgPolicyOrganization(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	policy, err := orgpolicy.Export(ctx, org)
+	if err != nil {
+		return err
+	}
+	content, err := yaml.Marshal(policy)
+	if err != nil {
+		return err
+	}
+
+	if c.IsSet("file") {
+		return os.WriteFile(c.String("file"), content, 0o644)
+	}
+	_, err = os.Stdout.Write(content)
+	return err
+}
+
+func runReconcileOrgPolicy(c *cli.Context) error {
+	if !c.IsSet("file") {
+		return fmt.Errorf("--file flag is missing")
+	}
+
+	ctx, cancel := installSignals()
+	defer cancel()
+
+	org, err := getOrgPolicyOrganization(ctx, c)
+	if err != nil {
+		return err
+	}
+
+	var content []byte
+	if c.String("file") == "-" {
+		content, err = io.ReadAll(os.Stdin)
+	} else {
+		content, err = os.ReadFile(c.String("file"))
+	}
+	if err != nil {
+		return err
+	}
+	policy, err := orgpolicy.Parse(content)
+	if err != nil {
+		return err
+	}
+
+	changes, err := orgpolicy.Drift(ctx, org, policy)
+	if err != nil {
+		return err
+	}
+	if len(changes) == 0 {
+		fmt.Printf("Organization %s matches the policy\n", org.Name)
+		return nil
+	}
+	if c.Bool("apply") {
+		orgpolicy.Apply(ctx, changes)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 5, 0, 1, ' ', 0)
+	fmt.Fprintf(w, "Section\tName\tAction\tError\n")
+	for _, change := range changes {
+		errMsg := ""
+		if change.Err != nil {
+			errMsg = change.Err.Error()
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", change.Section, change.Name, change.Action, errMsg)
+	}
+	return w.Flush()
+}
+
 func runDeleteAuth(c *cli.Context) error {
 	if !c.IsSet("id") {
 		return fmt.Errorf("--id flag is missing")
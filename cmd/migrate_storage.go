@@ -82,6 +82,9 @@ var CmdMigrateStorage = cli.Command{
 
 func migrateAttachments(dstStorage storage.ObjectStorage) error {
 	return repo_model.IterateAttachment(func(attach *repo_model.Attachment) error {
+		if attach.IsExternal() {
+			return nil
+		}
 		_, err := storage.Copy(dstStorage, attach.RelativePath(), storage.Attachments, attach.RelativePath())
 		return err
 	})
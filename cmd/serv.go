@@ -7,6 +7,7 @@ package cmd
 
 import (
 	"fmt"
+	"net"
 	"net/http"
 	"net/url"
 	"os"
@@ -225,7 +226,13 @@ func runServ(c *cli.Context) error {
 		}
 	}
 
-	results, err := private.ServCommand(ctx, keyID, username, reponame, requestedMode, verb, lfsVerb)
+	// SSH_CONNECTION is "client_ip client_port server_ip server_port", the client address restricts deploy keys
+	remoteAddr := ""
+	if fields := strings.Fields(os.Getenv("SSH_CONNECTION")); len(fields) == 4 {
+		remoteAddr = net.JoinHostPort(fields[0], fields[1])
+	}
+
+	results, err := private.ServCommand(ctx, keyID, username, reponame, remoteAddr, requestedMode, verb, lfsVerb)
 	if err != nil {
 		if private.IsErrServCommand(err) {
 			errServCommand := err.(private.ErrServCommand)
@@ -0,0 +1,61 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/translation/i18n"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminAuditLog(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	defer func(enabled bool) {
+		setting.Audit.Enabled = enabled
+	}(setting.Audit.Enabled)
+	setting.Audit.Enabled = true
+
+	testLoginFailed(t, "user2", "wrongPassword", i18n.Tr("en", "form.username_password_incorrect"))
+	unittest.AssertExistsAndLoadBean(t, &admin_model.AuditEvent{Action: admin_model.AuditActionSignInFailed, Target: "user2"})
+
+	session := loginUser(t, "user1")
+	unittest.AssertExistsAndLoadBean(t, &admin_model.AuditEvent{Action: admin_model.AuditActionSignIn, DoerName: "user1"})
+
+	req := NewRequest(t, "GET", "/admin/audit?action=auth")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 2, htmlDoc.doc.Find(".admin.audit table tbody tr").Length())
+
+	req = NewRequest(t, "GET", "/admin/audit/export?format=csv&action=auth.signin_failed")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Header().Get("Content-Disposition"), ".csv")
+	assert.Contains(t, resp.Body.String(), "auth.signin_failed")
+	assert.NotContains(t, resp.Body.String(), "auth.signin,")
+
+	req = NewRequest(t, "GET", "/admin/audit/export?format=json&action=auth")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var events []map[string]interface{}
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &events))
+	if assert.Len(t, events, 2) {
+		assert.Equal(t, "auth.signin_failed", events[0]["action"])
+		assert.Equal(t, "auth.signin", events[1]["action"])
+	}
+
+	req = NewRequest(t, "GET", "/admin/audit/export?format=xml")
+	session.MakeRequest(t, req, http.StatusBadRequest)
+
+	// only site admins can read the audit log
+	session = loginUser(t, "user2")
+	req = NewRequest(t, "GET", "/admin/audit")
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
@@ -0,0 +1,133 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIAdminAnnouncements(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/admin/announcements?token="+token, &api.CreateAnnouncementOption{
+		Content:     "Scheduled **maintenance**",
+		Severity:    "warning",
+		Dismissible: true,
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var announcement api.Announcement
+	DecodeJSON(t, resp, &announcement)
+	assert.Equal(t, "warning", announcement.Severity)
+	assert.Nil(t, announcement.End)
+
+	start := time.Now().Add(time.Hour)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/announcements?token="+token, &api.CreateAnnouncementOption{
+		Content:  "Upgrade",
+		Severity: "info",
+		Start:    &start,
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/admin/announcements?token="+token, &api.CreateAnnouncementOption{
+		Content:  "Outage",
+		Severity: "error",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// only the active announcements are public
+	req = NewRequest(t, "GET", "/api/v1/announcements")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var announcements []*api.Announcement
+	DecodeJSON(t, resp, &announcements)
+	if assert.Len(t, announcements, 1) {
+		assert.Equal(t, announcement.ID, announcements[0].ID)
+	}
+
+	req = NewRequest(t, "GET", "/api/v1/admin/announcements?token="+token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &announcements)
+	assert.Len(t, announcements, 2)
+
+	critical := "critical"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/admin/announcements/%d?token=%s", announcement.ID, token), &api.EditAnnouncementOption{
+		Severity: &critical,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &announcement)
+	assert.Equal(t, "critical", announcement.Severity)
+	assert.Equal(t, "Scheduled **maintenance**", announcement.Content)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/admin/announcements/%d?token=%s", announcement.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &admin_model.Announcement{ID: announcement.ID})
+
+	// only site admins manage the announcements of the instance
+	token = getTokenForLoggedInUser(t, loginUser(t, "user2"))
+	req = NewRequest(t, "GET", "/api/v1/admin/announcements?token="+token)
+	MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAnnouncementBanners(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	assert.NoError(t, admin_model.CreateAnnouncement(db.DefaultContext, &admin_model.Announcement{
+		Content:     "Welcome to the **new** instance",
+		Severity:    admin_model.AnnouncementSeverityInfo,
+		Dismissible: true,
+	}))
+	announcement := unittest.AssertExistsAndLoadBean(t, &admin_model.Announcement{Severity: admin_model.AnnouncementSeverityInfo}).(*admin_model.Announcement)
+
+	req := NewRequest(t, "GET", "/explore/repos")
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "<strong>new</strong>")
+
+	// a dismissed announcement is no longer shown to the user
+	session := loginUser(t, "user2")
+	req = NewRequestWithValues(t, "POST", fmt.Sprintf("/announcements/%d/dismiss", announcement.ID), map[string]string{
+		"_csrf": GetCSRF(t, session, "/explore/repos"),
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	req = NewRequest(t, "GET", "/explore/repos")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), "<strong>new</strong>")
+
+	// the announcements of an organization are shown on its pages and the pages of its repositories
+	link := "/org/user3/settings/announcements"
+	req = NewRequestWithValues(t, "POST", link, map[string]string{
+		"_csrf":    GetCSRF(t, session, link),
+		"content":  "Organization *news*",
+		"severity": "critical",
+	})
+	session.MakeRequest(t, req, http.StatusSeeOther)
+	unittest.AssertExistsAndLoadBean(t, &admin_model.Announcement{OrgID: 3, Severity: admin_model.AnnouncementSeverityCritical})
+
+	for _, link := range []string{"/user3", "/user3/repo3"} {
+		req = NewRequest(t, "GET", link)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		assert.Contains(t, resp.Body.String(), "<em>news</em>", link)
+	}
+	req = NewRequest(t, "GET", "/user2/repo1")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), "<em>news</em>")
+
+	req = NewRequest(t, "GET", "/api/v1/orgs/user3/announcements")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var announcements []*api.Announcement
+	DecodeJSON(t, resp, &announcements)
+	assert.Len(t, announcements, 1)
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIActionsRunner(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runners/registration_token?token=%s", token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var registration api.ActionRunnerRegistrationToken
+	DecodeJSON(t, resp, &registration)
+	assert.NotEmpty(t, registration.Token)
+
+	req = NewRequestWithJSON(t, "POST", "/api/actions/runner/register", &api.RegisterRunnerOption{Token: "invalid", Name: "runner"})
+	MakeRequest(t, req, http.StatusUnauthorized)
+	req = NewRequestWithJSON(t, "POST", "/api/actions/runner/register", &api.RegisterRunnerOption{
+		Token:  registration.Token,
+		Name:   "runner",
+		Labels: []string{"linux"},
+	})
+	resp = MakeRequest(t, req, http.StatusCreated)
+	var runner api.RegisteredRunner
+	DecodeJSON(t, resp, &runner)
+
+	runnerRequest := func(method, url string, v interface{}) *http.Request {
+		req := NewRequestWithJSON(t, method, url, v)
+		req.Header.Set("X-Runner-UUID", runner.UUID)
+		req.Header.Set("X-Runner-Token", runner.Token)
+		return req
+	}
+
+	req = NewRequest(t, "POST", "/api/actions/runner/fetch")
+	MakeRequest(t, req, http.StatusUnauthorized)
+	MakeRequest(t, runnerRequest("POST", "/api/actions/runner/fetch", nil), http.StatusNoContent)
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	run := &actions_model.ActionRun{
+		Title:         "Test",
+		RepoID:        repo.ID,
+		Repo:          repo,
+		WorkflowID:    "ci.yml",
+		WorkflowName:  "CI",
+		TriggerUserID: user2.ID,
+		TriggerUser:   user2,
+		Ref:           "refs/heads/master",
+		CommitSHA:     "65f1bf27bc3bf70f64657658635e66094edbcb4d",
+		Event:         "push",
+	}
+	assert.NoError(t, actions_model.InsertRun(db.DefaultContext, run, []*actions_model.ActionRunJob{
+		{Name: "test", JobID: "test", RunsOn: []string{"linux"}, Payload: "{}"},
+		{Name: "windows", JobID: "windows", RunsOn: []string{"windows"}, Payload: "{}"},
+	}))
+
+	resp = MakeRequest(t, runnerRequest("POST", "/api/actions/runner/fetch", nil), http.StatusOK)
+	var task api.ActionTask
+	DecodeJSON(t, resp, &task)
+	assert.Equal(t, run.ID, task.RunID)
+	assert.Equal(t, "test", task.Job)
+	assert.Equal(t, "user2/repo1", task.Repository)
+
+	// the other job needs a runner with other labels
+	MakeRequest(t, runnerRequest("POST", "/api/actions/runner/fetch", nil), http.StatusNoContent)
+
+	jobURL := fmt.Sprintf("/api/actions/runner/jobs/%d", task.JobID)
+	MakeRequest(t, runnerRequest("POST", jobURL+"/logs", &api.AppendJobLogsOption{Lines: []string{"line 1", "line 2"}}), http.StatusOK)
+	MakeRequest(t, runnerRequest("POST", jobURL+"/status", &api.UpdateJobStatusOption{Status: "success"}), http.StatusOK)
+	resp = MakeRequest(t, runnerRequest("POST", jobURL+"/logs", &api.AppendJobLogsOption{Lines: []string{"line 3"}}), http.StatusConflict)
+	var state api.ActionJobState
+	DecodeJSON(t, resp, &state)
+	assert.Equal(t, "success", state.Status)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runs/%d/jobs?token=%s", run.Index, token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var jobs []*api.ActionRunJob
+	DecodeJSON(t, resp, &jobs)
+	if assert.Len(t, jobs, 2) {
+		assert.Equal(t, "success", jobs[0].Status)
+		assert.Equal(t, "waiting", jobs[1].Status)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/jobs/%d/logs?offset=1&token=%s", task.JobID, token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "line 2", strings.TrimSpace(resp.Body.String()))
+
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/actions/runs/%d/cancel?token=%s", run.Index, token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var apiRun api.ActionRun
+	DecodeJSON(t, resp, &apiRun)
+	assert.Equal(t, "cancelled", apiRun.Status)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/actions/runners?token=%s", token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var runners []*api.ActionRunner
+	DecodeJSON(t, resp, &runners)
+	if assert.Len(t, runners, 1) {
+		assert.Equal(t, "runner", runners[0].Name)
+		assert.True(t, runners[0].Online)
+	}
+
+	// runners can only be deleted in their scope
+	adminToken := getTokenForLoggedInUser(t, loginUser(t, "user1"))
+	req = NewRequestf(t, "DELETE", "/api/v1/admin/actions/runners/%d?token=%s", runner.ID, adminToken)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/actions/runners/%d?token=%s", runner.ID, token)
+	MakeRequest(t, req, http.StatusNoContent)
+	MakeRequest(t, runnerRequest("POST", "/api/actions/runner/fetch", nil), http.StatusUnauthorized)
+}
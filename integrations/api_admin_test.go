@@ -209,3 +209,24 @@ func TestAPIEditUser(t *testing.T) {
 	user2 = unittest.AssertExistsAndLoadBean(t, &user_model.User{LoginName: "user2"}).(*user_model.User)
 	assert.True(t, user2.IsRestricted)
 }
+
+func TestAPIAdminSelfTest(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestf(t, "GET", "/api/v1/admin/selftest?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	session = loginUser(t, "user1")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/admin/selftest?token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var report api.SelfTestReport
+	DecodeJSON(t, resp, &report)
+
+	for _, check := range report.Checks {
+		assert.NotEqual(t, "fail", check.Status, "%s: %s", check.Name, check.Output)
+	}
+	assert.Equal(t, "pass", report.Status)
+}
@@ -0,0 +1,137 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPICheckRun(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	sha := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/check_runs?token="+token, &api.CreateCheckRunOption{
+		Name:    "lint",
+		HeadSHA: sha[:10],
+		Status:  "in_progress",
+		Output: &api.CheckRunOutputOption{
+			Title:   "Linting",
+			Summary: "Checking **everything**",
+			Annotations: []*api.CheckRunAnnotation{
+				{Path: "README.md", StartLine: 2, AnnotationLevel: "warning", Message: "line too long"},
+			},
+		},
+	})
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var run api.CheckRun
+	DecodeJSON(t, resp, &run)
+	assert.Equal(t, sha, run.HeadSHA)
+	assert.Equal(t, "in_progress", run.Status)
+	assert.EqualValues(t, 1, run.Output.AnnotationsCount)
+	assert.NotNil(t, run.Started)
+	assert.Equal(t, fmt.Sprintf("%suser2/repo1/checks/%d", setting.AppURL, run.ID), run.HTMLURL)
+
+	// the check run is reported as commit status named after the check
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/commits/%s/statuses?token=%s", sha, token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var statuses []*api.CommitStatus
+	DecodeJSON(t, resp, &statuses)
+	if assert.Len(t, statuses, 1) {
+		assert.Equal(t, "lint", statuses[0].Context)
+		assert.Equal(t, api.CommitStatusPending, statuses[0].State)
+		assert.Equal(t, run.HTMLURL, statuses[0].TargetURL)
+	}
+
+	conclusion := "failure"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/user2/repo1/check_runs/%d?token=%s", run.ID, token), &api.EditCheckRunOption{
+		Conclusion: &conclusion,
+		Output: &api.CheckRunOutputOption{
+			Title: "2 problems",
+			Annotations: []*api.CheckRunAnnotation{
+				{Path: "README.md", StartLine: 1, EndLine: 2, AnnotationLevel: "failure", Message: "missing title"},
+			},
+		},
+	})
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &run)
+	assert.Equal(t, "completed", run.Status)
+	assert.Equal(t, "failure", run.Conclusion)
+	assert.EqualValues(t, 2, run.Output.AnnotationsCount)
+	assert.NotNil(t, run.Completed)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/commits/%s/status?token=%s", sha, token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var combined api.CombinedStatus
+	DecodeJSON(t, resp, &combined)
+	assert.Equal(t, api.CommitStatusFailure, combined.State)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/check_runs/%d/annotations?token=%s", run.ID, token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var annotations []*api.CheckRunAnnotation
+	DecodeJSON(t, resp, &annotations)
+	if assert.Len(t, annotations, 2) {
+		assert.Equal(t, "failure", annotations[0].AnnotationLevel)
+		assert.Equal(t, 2, annotations[1].EndLine)
+	}
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/commits/master/check_runs?status=completed&token=%s", token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var runs []*api.CheckRun
+	DecodeJSON(t, resp, &runs)
+	assert.Len(t, runs, 1)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/commits/master/check_runs?check_name=build&token=%s", token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &runs)
+	assert.Len(t, runs, 0)
+
+	// the output is rendered on the check page
+	req = NewRequestf(t, "GET", "/user2/repo1/checks/%d", run.ID)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "<strong>everything</strong>")
+	assert.Contains(t, resp.Body.String(), fmt.Sprintf("/user2/repo1/src/commit/%s/README.md#L1-L2", sha))
+
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/check_runs/%d/rerequest?token=%s", run.ID, token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &run)
+	assert.Equal(t, "queued", run.Status)
+	assert.Empty(t, run.Conclusion)
+	assert.Equal(t, 2, run.Attempt)
+	assert.EqualValues(t, 0, run.Output.AnnotationsCount)
+
+	// invalid check runs
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/check_runs?token="+token, &api.CreateCheckRunOption{
+		Name:       "lint",
+		HeadSHA:    sha,
+		Conclusion: "great",
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/check_runs?token="+token, &api.CreateCheckRunOption{
+		Name:    "lint",
+		HeadSHA: "0000000000000000000000000000000000000000",
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// only writers can report checks
+	token4 := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/check_runs?token="+token4, &api.CreateCheckRunOption{
+		Name:    "lint",
+		HeadSHA: sha,
+	})
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/check_runs/%d/rerequest?token=%s", run.ID, token4)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/check_runs/%d?token=%s", run.ID, token4)
+	MakeRequest(t, req, http.StatusOK)
+}
@@ -8,7 +8,11 @@ import (
 	"net/http"
 	"testing"
 
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
 	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
 )
 
 func TestCreateForkNoLogin(t *testing.T) {
@@ -16,3 +20,75 @@ func TestCreateForkNoLogin(t *testing.T) {
 	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/forks", &api.CreateForkOption{})
 	MakeRequest(t, req, http.StatusUnauthorized)
 }
+
+func TestAPICreateForkIntoSameOwner(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// the original name is already taken by the repository itself
+	name := "repo1"
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/forks?token="+token, &api.CreateForkOption{Name: &name})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	name = "repo1-experiment"
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/forks?token="+token, &api.CreateForkOption{Name: &name})
+	resp := session.MakeRequest(t, req, http.StatusAccepted)
+	var fork api.Repository
+	DecodeJSON(t, resp, &fork)
+	assert.EqualValues(t, "user2/repo1-experiment", fork.FullName)
+	assert.True(t, fork.Fork)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{OwnerID: 2, Name: name, IsFork: true, ForkID: 1})
+
+	// without a name, the fork gets the first unused name with a suffix
+	for _, expected := range []string{"user2/repo1-fork", "user2/repo1-fork-2"} {
+		req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/forks?token="+token, &api.CreateForkOption{})
+		resp = session.MakeRequest(t, req, http.StatusAccepted)
+		DecodeJSON(t, resp, &fork)
+		assert.EqualValues(t, expected, fork.FullName)
+	}
+}
+
+func TestAPICreateForkIntoOrgWithoutPermission(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// user5 is not allowed to create repositories in org3
+	session := loginUser(t, "user5")
+	token := getTokenForLoggedInUser(t, session)
+
+	org := "user3"
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/forks?token="+token, &api.CreateForkOption{Organization: &org})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIAttachAndDetachFork(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// a public repository can not become a fork of a private one
+	req := NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/parent?token="+token, &api.AttachForkOption{Owner: "user2", Repo: "repo2"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/parent?token="+token, &api.AttachForkOption{Owner: "user3", Repo: "repo21"})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var repo api.Repository
+	DecodeJSON(t, resp, &repo)
+	assert.True(t, repo.Fork)
+	if assert.NotNil(t, repo.Parent) {
+		assert.EqualValues(t, "user3/repo21", repo.Parent.FullName)
+	}
+
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/parent?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &repo)
+	assert.False(t, repo.Fork)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1, IsFork: false})
+
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/parent?token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// only owners may change the fork relationship
+	otherToken := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/parent?token="+otherToken, &api.AttachForkOption{Owner: "user3", Repo: "repo21"})
+	MakeRequest(t, req, http.StatusForbidden)
+}
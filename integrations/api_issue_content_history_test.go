@@ -0,0 +1,89 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIIssueContentHistory(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	issue := unittest.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	_ = issue.LoadRepo(db.DefaultContext)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: issue.Repo.OwnerID}).(*user_model.User)
+	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+
+	issueURL := fmt.Sprintf("/api/v1/repos/%s/%s/issues/%d", owner.Name, issue.Repo.Name, issue.Index)
+	historyURL := issueURL + "/content_history"
+
+	for _, body := range []string{"first edit", "second edit"} {
+		req := NewRequestWithJSON(t, "PATCH", issueURL+"?token="+token, &api.EditIssueOption{
+			Body: &body,
+		})
+		session.MakeRequest(t, req, http.StatusCreated)
+	}
+
+	req := NewRequest(t, "GET", historyURL)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var histories []*api.ContentHistory
+	DecodeJSON(t, resp, &histories)
+	assert.Len(t, histories, 3)
+	assert.True(t, histories[2].IsFirstCreated)
+	assert.EqualValues(t, owner.ID, histories[0].Editor.ID)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("%s/%d", historyURL, histories[0].ID))
+	resp = MakeRequest(t, req, http.StatusOK)
+	var detail *api.ContentHistoryDetail
+	DecodeJSON(t, resp, &detail)
+	assert.Equal(t, "second edit", detail.Content)
+	assert.NotEmpty(t, detail.Changes)
+
+	// a history revision of another issue must not be found
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/%s/issues/2/content_history/%d", owner.Name, issue.Repo.Name, histories[0].ID))
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("%s/%d", historyURL, histories[1].ID))
+	MakeRequest(t, req, http.StatusUnauthorized)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("%s/%d?token=%s", historyURL, histories[1].ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("%s/%d", historyURL, histories[1].ID))
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &detail)
+	assert.True(t, detail.IsDeleted)
+	assert.Empty(t, detail.Content)
+
+	// only site admins can purge the history
+	req = NewRequest(t, "DELETE", historyURL+"?token="+token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	adminSession := loginUser(t, admin.Name)
+	adminToken := getTokenForLoggedInUser(t, adminSession)
+
+	req = NewRequest(t, "DELETE", historyURL+"?token="+adminToken)
+	adminSession.MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequest(t, "GET", historyURL)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &histories)
+	assert.Len(t, histories, 0)
+
+	unittest.AssertExistsAndLoadBean(t, &models.Issue{ID: issue.ID, Content: "second edit"})
+}
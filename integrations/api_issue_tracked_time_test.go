@@ -122,3 +122,64 @@ func TestAPIAddTrackedTimes(t *testing.T) {
 	assert.EqualValues(t, user2.ID, apiNewTime.UserID)
 	assert.EqualValues(t, 947688818, apiNewTime.Created.Unix())
 }
+
+func TestAPIGetTimeReport(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/time_report?group_by=label&token=%s", token)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var report api.TimeReport
+	DecodeJSON(t, resp, &report)
+	assert.EqualValues(t, "label", report.GroupBy)
+	assert.EqualValues(t, 4083, report.Time)
+	if assert.Len(t, report.Entries, 3) {
+		assert.EqualValues(t, "label1", report.Entries[0].Name)
+		assert.EqualValues(t, "user2/repo1", report.Entries[0].Repo)
+		assert.EqualValues(t, 4082, report.Entries[0].Time)
+	}
+
+	since := time.Unix(946684812, 0).Format(time.RFC3339)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/time_report?user=user1&since=%s&token=%s", since, token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &report)
+	assert.EqualValues(t, 20, report.Time)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/time_report?group_by=repository&token=%s", token)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// users without write access only see their own times
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/time_report?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &report)
+	assert.EqualValues(t, 0, report.Time)
+	assert.Empty(t, report.Entries)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/time_report?user=user2&token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/time_report?token=%s", token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestTimeReportExport(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	req := NewRequest(t, "GET", "/user2/repo1/time_report")
+	session.MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequest(t, "GET", "/user2/repo1/time_report/export?format=csv&group_by=milestone")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.EqualValues(t, "milestone,repository,issues,seconds\nmilestone1,user2/repo1,1,3682\n,,2,401\n", resp.Body.String())
+
+	req = NewRequest(t, "GET", "/user2/repo1/time_report/export?format=json")
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var report api.TimeReport
+	DecodeJSON(t, resp, &report)
+	assert.EqualValues(t, 4083, report.Time)
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgBranchProtection(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/branch_protections?token="+token, &api.CreateOrgBranchProtectionOption{
+		BranchPattern: "[master",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/branch_protections?token="+token, &api.CreateOrgBranchProtectionOption{
+		BranchPattern:     "mast*",
+		ApplyToExisting:   true,
+		RequiredApprovals: 2,
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var rule api.OrgBranchProtection
+	DecodeJSON(t, resp, &rule)
+	assert.EqualValues(t, "mast*", rule.BranchPattern)
+	unittest.AssertExistsAndLoadBean(t, &models.OrgProtectedBranch{ID: rule.ID, OrgID: 3})
+
+	// the branches of the repositories inherit the rules
+	req = NewRequestf(t, "GET", "/api/v1/repos/user3/repo3/branches/master?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var branch api.Branch
+	DecodeJSON(t, resp, &branch)
+	assert.True(t, branch.Protected)
+	assert.EqualValues(t, 2, branch.RequiredApprovals)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user3/repo3/branches/test_branch?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &branch)
+	assert.False(t, branch.Protected)
+
+	// the rules can not be overridden by the repository
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+		BranchName: "master",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	allowOverride := true
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/orgs/user3/branch_protections/%d?token=%s", rule.ID, token), &api.EditOrgBranchProtectionOption{
+		AllowOverride: &allowOverride,
+	})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &rule)
+	assert.True(t, rule.AllowOverride)
+	assert.EqualValues(t, 2, rule.RequiredApprovals)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user3/repo3/branch_protections?token="+token, &api.CreateBranchProtectionOption{
+		BranchName: "master",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/branch_protections?token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var rules []*api.OrgBranchProtection
+	DecodeJSON(t, resp, &rules)
+	assert.Len(t, rules, 1)
+
+	// only owners can manage the rules
+	otherToken := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/branch_protections?token=%s", otherToken)
+	MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/orgs/user3/branch_protections/%d?token=%s", rule.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &models.OrgProtectedBranch{ID: rule.ID})
+
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/branch_protections/%d?token=%s", rule.ID, token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
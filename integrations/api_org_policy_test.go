@@ -0,0 +1,65 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgPolicy(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestf(t, "GET", "/api/v1/orgs/user3/policy?token=%s", token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var policy api.OrgPolicy
+	DecodeJSON(t, resp, &policy)
+	assert.NotEmpty(t, policy.Teams)
+
+	// the exported policy doesn't drift
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/policy/drift?token="+token, &policy)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var report api.OrgPolicyReport
+	DecodeJSON(t, resp, &report)
+	assert.False(t, report.Applied)
+	assert.Empty(t, report.Changes)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/policy/drift?token="+token, &api.OrgPolicy{
+		Teams: []*api.OrgPolicyTeam{{Name: "reviewers", Permission: "owner"}},
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/policy/drift?token="+token, &api.OrgPolicy{
+		Teams: []*api.OrgPolicyTeam{{Name: "reviewers", Permission: "read", Units: []string{"repo.code"}, Members: []string{"unknown"}}},
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/policy/apply?token="+token, &api.OrgPolicy{
+		Teams: []*api.OrgPolicyTeam{{Name: "reviewers", Permission: "read", Units: []string{"repo.pulls"}, Members: []string{"user4"}}},
+	})
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &report)
+	assert.True(t, report.Applied)
+	assert.Len(t, report.Changes, 2)
+	for _, change := range report.Changes {
+		assert.Empty(t, change.Error)
+	}
+	team := unittest.AssertExistsAndLoadBean(t, &organization.Team{OrgID: 3, LowerName: "reviewers"}).(*organization.Team)
+	unittest.AssertExistsAndLoadBean(t, &organization.TeamUser{TeamID: team.ID, UID: 4})
+
+	// only owners of the organization can use the policies
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/policy?token=%s", token)
+	MakeRequest(t, req, http.StatusForbidden)
+}
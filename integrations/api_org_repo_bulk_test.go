@@ -0,0 +1,90 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIOrgBulkCreateRepos(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		token := getUserToken(t, "user2")
+
+		// duplicated names are rejected
+		req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/repos/bulk?token="+token, &api.BulkRepoManifest{
+			Repos: []*api.BulkRepoEntry{{Name: "bulk1"}, {Name: "BULK1"}},
+		})
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		// unknown teams are rejected
+		req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/repos/bulk?token="+token, &api.BulkRepoManifest{
+			Repos: []*api.BulkRepoEntry{{Name: "bulk1", Teams: []string{"no-such-team"}}},
+		})
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		manifest := "repos:\n- name: bulk1\n  private: true\n- name: bulk2\n  auto_init: true\n  teams: [team1]\n"
+		req = NewRequestWithBody(t, "POST", "/api/v1/orgs/user3/repos/bulk?token="+token, bytes.NewBufferString(manifest))
+		req.Header.Set("Content-Type", "application/x-yaml")
+		resp := MakeRequest(t, req, http.StatusAccepted)
+
+		var job api.BulkRepoJob
+		DecodeJSON(t, resp, &job)
+		assert.EqualValues(t, 2, job.Total)
+		if assert.Len(t, job.Repos, 2) {
+			assert.EqualValues(t, "bulk1", job.Repos[0].Name)
+			assert.EqualValues(t, "bulk2", job.Repos[1].Name)
+		}
+
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/orgs/user3/repos/bulk/%d?token=%s", job.ID, token))
+		resp = MakeRequest(t, req, http.StatusOK)
+		var progress api.BulkRepoJob
+		DecodeJSON(t, resp, &progress)
+		assert.EqualValues(t, job.ID, progress.ID)
+		assert.EqualValues(t, 2, progress.Total)
+
+		// jobs are not visible to non-members
+		req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/orgs/user3/repos/bulk/%d?token=%s", job.ID, getUserToken(t, "user4")))
+		MakeRequest(t, req, http.StatusForbidden)
+	})
+}
+
+func TestAPIOrgBulkCreateReposTeamAccess(t *testing.T) {
+	onGiteaRun(t, func(*testing.T, *url.URL) {
+		// let the members of team1 create repositories without owning the organization
+		team := unittest.AssertExistsAndLoadBean(t, &organization.Team{ID: 2}).(*organization.Team)
+		team.CanCreateOrgRepo = true
+		_, err := db.GetEngine(db.DefaultContext).ID(team.ID).Cols("can_create_org_repo").Update(team)
+		assert.NoError(t, err)
+
+		token := getUserToken(t, "user4")
+		manifest := &api.BulkRepoManifest{
+			Repos: []*api.BulkRepoEntry{{Name: "bulk-teams", Teams: []string{"team1"}}},
+		}
+
+		// only the owners may grant teams access
+		req := NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/repos/bulk?token="+token, manifest)
+		MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		// unless the organization lets the repository administrators change the team access
+		org := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3}).(*user_model.User)
+		org.RepoAdminChangeTeamAccess = true
+		assert.NoError(t, user_model.UpdateUserCols(db.DefaultContext, org, "repo_admin_change_team_access"))
+
+		req = NewRequestWithJSON(t, "POST", "/api/v1/orgs/user3/repos/bulk?token="+token, manifest)
+		MakeRequest(t, req, http.StatusAccepted)
+	})
+}
@@ -0,0 +1,213 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	cargo_module "code.gitea.io/gitea/modules/packages/cargo"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/cargo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageCargo(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	packageName := "cargo-package"
+	packageVersion := "1.0.3"
+	packageDescription := "Package Description"
+
+	createPackage := func(name, version string) []byte {
+		metadata := `{"name":"` + name + `","vers":"` + version + `","description":"` + packageDescription + `","deps":[{"name":"dep","version_req":"1.0","optional":false,"default_features":true,"kind":"normal"}],"features":{}}`
+		content := []byte("crate content")
+
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+		buf.WriteString(metadata)
+		binary.Write(&buf, binary.LittleEndian, uint32(len(content)))
+		buf.Write(content)
+		return buf.Bytes()
+	}
+
+	token := "Bearer " + getTokenForLoggedInUser(t, loginUser(t, user.Name))
+
+	url := fmt.Sprintf("%sapi/packages/%s/cargo", setting.AppURL, user.Name)
+
+	t.Run("RepositoryConfig", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", url+"/config.json")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result cargo.RepositoryConfigResponse
+		DecodeJSON(t, resp, &result)
+
+		assert.Equal(t, url+"/api/v1/crates", result.DownloadURL)
+		assert.Equal(t, url, result.APIURL)
+		assert.False(t, result.AuthRequired)
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		t.Run("Unauthorized", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequestWithBody(t, "PUT", url+"/api/v1/crates/new", bytes.NewReader(createPackage(packageName, packageVersion)))
+			MakeRequest(t, req, http.StatusUnauthorized)
+		})
+
+		t.Run("InvalidName", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequestWithBody(t, "PUT", url+"/api/v1/crates/new", bytes.NewReader(createPackage("0invalid", packageVersion)))
+			req.Header.Set("Authorization", token)
+			MakeRequest(t, req, http.StatusBadRequest)
+		})
+
+		t.Run("Valid", func(t *testing.T) {
+			defer PrintCurrentTest(t)()
+
+			req := NewRequestWithBody(t, "PUT", url+"/api/v1/crates/new", bytes.NewReader(createPackage(packageName, packageVersion)))
+			req.Header.Set("Authorization", token)
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var result cargo.PublishResponse
+			DecodeJSON(t, resp, &result)
+			assert.Empty(t, result.Warnings.Other)
+
+			pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeCargo)
+			assert.NoError(t, err)
+			assert.Len(t, pvs, 1)
+
+			pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+			assert.NoError(t, err)
+			assert.NotNil(t, pd.SemVer)
+			assert.IsType(t, &cargo_module.Metadata{}, pd.Metadata)
+			assert.Equal(t, packageName, pd.Package.Name)
+			assert.Equal(t, packageVersion, pd.Version.Version)
+
+			pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+			assert.NoError(t, err)
+			assert.Len(t, pfs, 1)
+			assert.Equal(t, fmt.Sprintf("%s-%s.crate", packageName, packageVersion), pfs[0].Name)
+			assert.True(t, pfs[0].IsLead)
+
+			req = NewRequestWithBody(t, "PUT", url+"/api/v1/crates/new", bytes.NewReader(createPackage(packageName, packageVersion)))
+			req.Header.Set("Authorization", token)
+			MakeRequest(t, req, http.StatusConflict)
+		})
+	})
+
+	t.Run("Index", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", url+"/"+cargo_module.IndexPath(packageName))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		lines := strings.Split(strings.TrimSpace(resp.Body.String()), "\n")
+		assert.Len(t, lines, 1)
+
+		var entry cargo.IndexEntry
+		assert.NoError(t, json.Unmarshal([]byte(lines[0]), &entry))
+		assert.Equal(t, packageName, entry.Name)
+		assert.Equal(t, packageVersion, entry.Version)
+		assert.False(t, entry.Yanked)
+		assert.NotEmpty(t, entry.FileChecksum)
+		assert.Len(t, entry.Dependencies, 1)
+		assert.Equal(t, "dep", entry.Dependencies[0].Name)
+		assert.Equal(t, "1.0", entry.Dependencies[0].Req)
+
+		req = NewRequest(t, "GET", url+"/"+cargo_module.IndexPath("unknown-package"))
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("%s/api/v1/crates/%s/%s/download", url, packageName, packageVersion))
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, "crate content", resp.Body.String())
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeCargo)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+		assert.Equal(t, int64(1), pvs[0].DownloadCount)
+	})
+
+	t.Run("Search", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		cases := []struct {
+			Query         string
+			ExpectedTotal int64
+		}{
+			{"", 1},
+			{"cargo", 1},
+			{"dummy", 0},
+		}
+
+		for i, c := range cases {
+			req := NewRequest(t, "GET", fmt.Sprintf("%s/api/v1/crates?q=%s&per_page=10", url, c.Query))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var result cargo.SearchResultResponse
+			DecodeJSON(t, resp, &result)
+
+			assert.Equal(t, c.ExpectedTotal, result.Meta.Total, "case %d: unexpected total hits", i)
+			assert.Len(t, result.Crates, int(c.ExpectedTotal), "case %d: unexpected result count", i)
+		}
+	})
+
+	t.Run("Yank", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		yankURL := fmt.Sprintf("%s/api/v1/crates/%s/%s/yank", url, packageName, packageVersion)
+		unyankURL := fmt.Sprintf("%s/api/v1/crates/%s/%s/unyank", url, packageName, packageVersion)
+
+		isYanked := func() bool {
+			req := NewRequest(t, "GET", url+"/"+cargo_module.IndexPath(packageName))
+			resp := MakeRequest(t, req, http.StatusOK)
+
+			var entry cargo.IndexEntry
+			assert.NoError(t, json.Unmarshal(bytes.TrimSpace(resp.Body.Bytes()), &entry))
+			return entry.Yanked
+		}
+
+		req := NewRequest(t, "DELETE", yankURL)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", yankURL)
+		req.Header.Set("Authorization", token)
+		MakeRequest(t, req, http.StatusOK)
+		assert.True(t, isYanked())
+
+		req = NewRequest(t, "DELETE", yankURL)
+		req.Header.Set("Authorization", token)
+		MakeRequest(t, req, http.StatusOK)
+		assert.True(t, isYanked())
+
+		req = NewRequest(t, "PUT", unyankURL)
+		req.Header.Set("Authorization", token)
+		MakeRequest(t, req, http.StatusOK)
+		assert.False(t, isYanked())
+
+		req = NewRequest(t, "DELETE", fmt.Sprintf("%s/api/v1/crates/%s/%s/yank", url, packageName, "9.9.9"))
+		req.Header.Set("Authorization", token)
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
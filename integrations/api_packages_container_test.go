@@ -6,9 +6,11 @@ package integrations
 
 import (
 	"bytes"
+	"crypto/sha256"
 	"encoding/base64"
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 	"testing"
 
@@ -389,6 +391,79 @@ func TestPackageContainer(t *testing.T) {
 				assert.Equal(t, indexManifestDigest, pd.Files[0].Properties.GetByName(container_module.PropertyDigest))
 			})
 
+			t.Run("Referrers", func(t *testing.T) {
+				defer PrintCurrentTest(t)()
+
+				artifactType := "application/vnd.example.sbom.v1+json"
+				emptyConfigDigest := "sha256:44136fa355b3678a1146ad16f7e8649e94fb4fc21fe77e8310c060f61caaff8a"
+
+				req := NewRequestWithBody(t, "POST", fmt.Sprintf("%s/blobs/uploads?digest=%s", url, emptyConfigDigest), strings.NewReader("{}"))
+				addTokenAuthHeader(req, userToken)
+				MakeRequest(t, req, http.StatusCreated)
+
+				artifactManifestContent := `{"schemaVersion":2,"mediaType":"` + oci.MediaTypeImageManifest + `","artifactType":"` + artifactType + `","config":{"mediaType":"application/vnd.oci.empty.v1+json","digest":"` + emptyConfigDigest + `","size":2},"layers":[{"mediaType":"application/spdx+json","digest":"` + blobDigest + `","size":32}],"subject":{"mediaType":"` + oci.MediaTypeDockerManifest + `","digest":"` + manifestDigest + `","size":` + strconv.Itoa(len(manifestContent)) + `},"annotations":{"org.opencontainers.image.description":"SBOM"}}`
+				artifactManifestDigest := fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(artifactManifestContent)))
+
+				req = NewRequestWithBody(t, "PUT", fmt.Sprintf("%s/manifests/%s", url, artifactManifestDigest), strings.NewReader(artifactManifestContent))
+				addTokenAuthHeader(req, userToken)
+				req.Header.Set("Content-Type", oci.MediaTypeImageManifest)
+				resp := MakeRequest(t, req, http.StatusCreated)
+
+				assert.Equal(t, artifactManifestDigest, resp.Header().Get("Docker-Content-Digest"))
+				assert.Equal(t, manifestDigest, resp.Header().Get("OCI-Subject"))
+
+				pv, err := packages_model.GetVersionByNameAndVersion(db.DefaultContext, user.ID, packages_model.TypeContainer, image, artifactManifestDigest)
+				assert.NoError(t, err)
+
+				pd, err := packages_model.GetPackageDescriptor(db.DefaultContext, pv)
+				assert.NoError(t, err)
+				assert.Equal(t, manifestDigest, pd.Properties.GetByName(container_module.PropertyManifestSubject))
+
+				assert.IsType(t, &container_module.Metadata{}, pd.Metadata)
+				metadata := pd.Metadata.(*container_module.Metadata)
+				assert.Equal(t, container_module.TypeArtifact, metadata.Type)
+				assert.Equal(t, artifactType, metadata.ArtifactType)
+				assert.Equal(t, "SBOM", metadata.Description)
+
+				req = NewRequest(t, "GET", fmt.Sprintf("%s/referrers/%s", url, manifestDigest))
+				addTokenAuthHeader(req, userToken)
+				resp = MakeRequest(t, req, http.StatusOK)
+
+				assert.Equal(t, oci.MediaTypeImageIndex, resp.Header().Get("Content-Type"))
+
+				var index oci.Index
+				DecodeJSON(t, resp, &index)
+				assert.EqualValues(t, oci.MediaTypeImageIndex, index.MediaType)
+				assert.Len(t, index.Manifests, 1)
+				assert.EqualValues(t, oci.MediaTypeImageManifest, index.Manifests[0].MediaType)
+				assert.EqualValues(t, artifactManifestDigest, index.Manifests[0].Digest)
+				assert.EqualValues(t, len(artifactManifestContent), index.Manifests[0].Size)
+				assert.Equal(t, artifactType, index.Manifests[0].ArtifactType)
+				assert.Equal(t, "SBOM", index.Manifests[0].Annotations["org.opencontainers.image.description"])
+
+				req = NewRequest(t, "GET", fmt.Sprintf("%s/referrers/%s?artifactType=%s", url, manifestDigest, "application/vnd.example.signature"))
+				addTokenAuthHeader(req, userToken)
+				resp = MakeRequest(t, req, http.StatusOK)
+
+				assert.Equal(t, "artifactType", resp.Header().Get("OCI-Filters-Applied"))
+
+				index = oci.Index{}
+				DecodeJSON(t, resp, &index)
+				assert.Empty(t, index.Manifests)
+
+				req = NewRequest(t, "GET", fmt.Sprintf("%s/referrers/%s", url, untaggedManifestDigest))
+				addTokenAuthHeader(req, userToken)
+				resp = MakeRequest(t, req, http.StatusOK)
+
+				index = oci.Index{}
+				DecodeJSON(t, resp, &index)
+				assert.Empty(t, index.Manifests)
+
+				req = NewRequest(t, "GET", fmt.Sprintf("%s/referrers/invalid", url))
+				addTokenAuthHeader(req, userToken)
+				MakeRequest(t, req, http.StatusBadRequest)
+			})
+
 			t.Run("UploadBlob/Mount", func(t *testing.T) {
 				defer PrintCurrentTest(t)()
 
@@ -0,0 +1,192 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	terraform_module "code.gitea.io/gitea/modules/packages/terraform"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/terraform"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPackageTerraform(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	moduleName := "network"
+	moduleSystem := "aws"
+	moduleVersion := "1.2.0"
+	moduleDescription := "Creates a network."
+	moduleReadme := "# Network\n\n" + moduleDescription + "\n"
+
+	filename := fmt.Sprintf("%s-%s-%s.tar.gz", moduleName, moduleSystem, moduleVersion)
+
+	createArchive := func(files map[string]string) []byte {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		archive := tar.NewWriter(zw)
+		for name, content := range files {
+			archive.WriteHeader(&tar.Header{
+				Name: name,
+				Mode: 0o600,
+				Size: int64(len(content)),
+			})
+			archive.Write([]byte(content))
+		}
+		archive.Close()
+		zw.Close()
+		return buf.Bytes()
+	}
+
+	content := createArchive(map[string]string{
+		"main.tf":   `resource "null_resource" "test" {}`,
+		"README.md": moduleReadme,
+	})
+
+	url := fmt.Sprintf("/api/packages/%s/terraform/modules/%s/%s", user.Name, moduleName, moduleSystem)
+	registryURL := fmt.Sprintf("/api/packages/terraform/modules/v1/%s/%s/%s", user.Name, moduleName, moduleSystem)
+
+	t.Run("ServiceDiscovery", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", "/.well-known/terraform.json")
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result map[string]string
+		DecodeJSON(t, resp, &result)
+		assert.Equal(t, setting.AppSubURL+"/api/packages/terraform/modules/v1/", result["modules.v1"])
+	})
+
+	t.Run("Upload", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		uploadURL := url + "/v" + moduleVersion
+
+		req := NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(content))
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequestWithBody(t, "PUT", fmt.Sprintf("/api/packages/%s/terraform/modules/Invalid.Name/%s/%s", user.Name, moduleSystem, moduleVersion), bytes.NewReader(content))
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(createArchive(map[string]string{"README.md": moduleReadme})))
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(content))
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeTerraform)
+		assert.NoError(t, err)
+		assert.Len(t, pvs, 1)
+
+		pd, err := packages.GetPackageDescriptor(db.DefaultContext, pvs[0])
+		assert.NoError(t, err)
+		assert.NotNil(t, pd.SemVer)
+		assert.IsType(t, &terraform_module.Metadata{}, pd.Metadata)
+		assert.Equal(t, moduleName+"/"+moduleSystem, pd.Package.Name)
+		assert.Equal(t, moduleVersion, pd.Version.Version)
+
+		metadata := pd.Metadata.(*terraform_module.Metadata)
+		assert.Equal(t, moduleName, metadata.Name)
+		assert.Equal(t, moduleSystem, metadata.System)
+		assert.Equal(t, moduleDescription, metadata.Description)
+		assert.Equal(t, moduleReadme, metadata.Readme)
+
+		pfs, err := packages.GetFilesByVersionID(db.DefaultContext, pvs[0].ID)
+		assert.NoError(t, err)
+		assert.Len(t, pfs, 1)
+		assert.Equal(t, filename, pfs[0].Name)
+		assert.True(t, pfs[0].IsLead)
+
+		req = NewRequestWithBody(t, "PUT", uploadURL, bytes.NewReader(content))
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusConflict)
+	})
+
+	t.Run("PublishFromTag", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "POST", url+"/publish?repository=repo1&tag=v0.0.0")
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "POST", url+"/publish?repository=repo1&tag=latest")
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+
+		// the tag exists but the repository contains no Terraform files
+		req = NewRequest(t, "POST", url+"/publish?repository=repo1&tag=v1.1")
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusBadRequest)
+	})
+
+	t.Run("EnumerateModuleVersions", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", fmt.Sprintf("/api/packages/terraform/modules/v1/%s/unknown/%s/versions", user.Name, moduleSystem))
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNotFound)
+
+		req = NewRequest(t, "GET", registryURL+"/versions")
+		req = AddBasicAuthHeader(req, user.Name)
+		resp := MakeRequest(t, req, http.StatusOK)
+
+		var result terraform.ModuleVersionsResponse
+		DecodeJSON(t, resp, &result)
+		assert.Len(t, result.Modules, 1)
+		assert.Len(t, result.Modules[0].Versions, 1)
+		assert.Equal(t, moduleVersion, result.Modules[0].Versions[0].Version)
+	})
+
+	t.Run("Download", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "GET", registryURL+"/"+moduleVersion+"/download")
+		req = AddBasicAuthHeader(req, user.Name)
+		resp := MakeRequest(t, req, http.StatusNoContent)
+
+		downloadURL := fmt.Sprintf("%s%s/%s/%s", setting.AppURL, url[1:], moduleVersion, filename)
+		assert.Equal(t, downloadURL, resp.Header().Get("X-Terraform-Get"))
+
+		req = NewRequest(t, "GET", fmt.Sprintf("%s/%s/%s", url, moduleVersion, filename))
+		req = AddBasicAuthHeader(req, user.Name)
+		resp = MakeRequest(t, req, http.StatusOK)
+
+		assert.Equal(t, content, resp.Body.Bytes())
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		req := NewRequest(t, "DELETE", url+"/"+moduleVersion)
+		MakeRequest(t, req, http.StatusUnauthorized)
+
+		req = NewRequest(t, "DELETE", url+"/"+moduleVersion)
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNoContent)
+
+		pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeTerraform)
+		assert.NoError(t, err)
+		assert.Empty(t, pvs)
+
+		req = NewRequest(t, "GET", registryURL+"/versions")
+		req = AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusNotFound)
+	})
+}
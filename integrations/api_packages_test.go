@@ -165,3 +165,72 @@ func TestPackageCleanup(t *testing.T) {
 	_, err = packages_model.GetInternalVersionByNameAndVersion(db.DefaultContext, 2, packages_model.TypeContainer, "test", container_model.UploadVersion)
 	assert.ErrorIs(t, err, packages_model.ErrPackageNotExist)
 }
+
+func TestPackagePolicy(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 5}).(*user_model.User)
+	token := getTokenForLoggedInUser(t, loginUser(t, user.Name))
+	adminToken := getTokenForLoggedInUser(t, loginUser(t, "user1"))
+
+	policyURL := fmt.Sprintf("/api/v1/packages/%s/policy", user.Name)
+	uploadPackage := func(version string, content []byte, expectedStatus int) {
+		url := fmt.Sprintf("/api/packages/%s/generic/policy-package/%s/file.bin", user.Name, version)
+		req := NewRequestWithBody(t, "PUT", url, bytes.NewReader(content))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, expectedStatus)
+	}
+
+	t.Run("Quota", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		maxCount := int64(1)
+		req := NewRequestWithJSON(t, "PATCH", policyURL+"?token="+token, &api.EditPackagePolicyOption{MaxCount: &maxCount})
+		MakeRequest(t, req, http.StatusForbidden)
+
+		req = NewRequestWithJSON(t, "PATCH", policyURL+"?token="+adminToken, &api.EditPackagePolicyOption{MaxCount: &maxCount})
+		resp := MakeRequest(t, req, http.StatusOK)
+		var policy api.PackagePolicy
+		DecodeJSON(t, resp, &policy)
+		assert.EqualValues(t, 1, policy.MaxCount)
+
+		uploadPackage("1.0", []byte{1}, http.StatusCreated)
+		uploadPackage("2.0", []byte{2}, http.StatusForbidden)
+
+		maxCount = 0
+		maxSize := int64(2)
+		req = NewRequestWithJSON(t, "PATCH", policyURL+"?token="+adminToken, &api.EditPackagePolicyOption{MaxCount: &maxCount, MaxSize: &maxSize})
+		MakeRequest(t, req, http.StatusOK)
+
+		uploadPackage("2.0", []byte{2, 2}, http.StatusForbidden)
+		uploadPackage("2.0", []byte{2}, http.StatusCreated)
+
+		req = NewRequest(t, "GET", policyURL+"?token="+token)
+		resp = MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &policy)
+		assert.EqualValues(t, 2, policy.UsedCount)
+		assert.EqualValues(t, 2, policy.UsedSize)
+
+		maxSize = 0
+		req = NewRequestWithJSON(t, "PATCH", policyURL+"?token="+adminToken, &api.EditPackagePolicyOption{MaxSize: &maxSize})
+		MakeRequest(t, req, http.StatusOK)
+	})
+
+	t.Run("Retention", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+
+		time.Sleep(time.Second)
+		uploadPackage("3.0", []byte{3}, http.StatusCreated)
+
+		keep := 1
+		req := NewRequestWithJSON(t, "PATCH", policyURL+"?token="+token, &api.EditPackagePolicyOption{KeepLastVersions: &keep})
+		MakeRequest(t, req, http.StatusOK)
+
+		assert.NoError(t, packages_service.ApplyRetentionPolicies(db.DefaultContext))
+
+		pvs, err := packages_model.GetVersionsByPackageName(db.DefaultContext, user.ID, packages_model.TypeGeneric, "policy-package")
+		assert.NoError(t, err)
+		if assert.Len(t, pvs, 1) {
+			assert.Equal(t, "3.0", pvs[0].Version)
+		}
+	})
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIPullPreviewEnvironments(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo1.OwnerID}).(*user_model.User)
+	pr := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/preview_environments?token=%s", owner.Name, repo1.Name, pr.Index, token)
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreatePreviewEnvironmentOption{Name: "staging"})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var env api.PreviewEnvironment
+	DecodeJSON(t, resp, &env)
+	assert.Equal(t, "staging", env.Name)
+	assert.Equal(t, "pending", env.Status)
+	assert.Nil(t, env.Expires)
+
+	// The name is unique within the pull request
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreatePreviewEnvironmentOption{Name: "staging"})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	// A ready environment needs a url
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreatePreviewEnvironmentOption{Name: "docs", Status: "ready"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	status, url := "ready", "https://pr-3.preview.example.com"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/preview_environments/%d?token=%s", owner.Name, repo1.Name, pr.Index, env.ID, token),
+		&api.EditPreviewEnvironmentOption{Status: &status, URL: &url})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &env)
+	assert.Equal(t, "ready", env.Status)
+	assert.Equal(t, url, env.URL)
+
+	// The environment is shown in the header of the pull request
+	req = NewRequest(t, "GET", fmt.Sprintf("/%s/%s/pulls/%d", owner.Name, repo1.Name, pr.Index))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, 1, htmlDoc.doc.Find(fmt.Sprintf(`.preview-environments a[href="%s"]`, url)).Length())
+
+	// Only writers can register environments
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/preview_environments?token=%s", owner.Name, repo1.Name, pr.Index, token4),
+		&api.CreatePreviewEnvironmentOption{Name: "other"})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	// Closing the pull request removes its environments
+	closed := "closed"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d?token=%s", owner.Name, repo1.Name, pr.Index, token),
+		&api.EditPullRequestOption{State: &closed})
+	session.MakeRequest(t, req, http.StatusCreated)
+	unittest.AssertNotExistsBean(t, &models.PreviewEnvironment{ID: env.ID})
+
+	req = NewRequest(t, "GET", urlStr)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var envs []*api.PreviewEnvironment
+	DecodeJSON(t, resp, &envs)
+	assert.Empty(t, envs)
+}
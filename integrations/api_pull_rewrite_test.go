@@ -0,0 +1,48 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+func TestAPIPullRewrite(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo1.OwnerID}).(*user_model.User)
+	pr := unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: 2}).(*models.PullRequest)
+
+	option := &api.RewritePullRequestOption{
+		HeadCommitID: "0000000000000000000000000000000000000000",
+		Steps: []*api.RewritePullRequestStep{
+			{SHA: "0000000000000000000000000000000000000000", Action: "pick"},
+		},
+	}
+
+	// Only users who can push to the head branch may rewrite it
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/rewrite?token=%s", owner.Name, repo1.Name, pr.Index, token4), option)
+	session4.MakeRequest(t, req, http.StatusForbidden)
+
+	// The rewrite is refused if the head branch has moved
+	session := loginUser(t, owner.Name)
+	token := getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/pulls/%d/rewrite?token=%s", owner.Name, repo1.Name, pr.Index, token), option)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// A merged pull request can't be rewritten
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/pulls/2/rewrite?token=%s", owner.Name, repo1.Name, token), option)
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	api "code.gitea.io/gitea/modules/structs"
+	release_service "code.gitea.io/gitea/services/release"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIReleaseSigning(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/releases/signing-key.gpg?token="+token)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/releases/1/verification?token="+token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var verification api.ReleaseVerification
+	DecodeJSON(t, resp, &verification)
+	assert.False(t, verification.Verified)
+	assert.Equal(t, release_service.VerificationNotSigned, verification.Reason)
+
+	key, err := release_service.GenerateSigningKey(db.DefaultContext, 0, "Gitea", "gitea@example.com")
+	assert.NoError(t, err)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/releases/signing-key.gpg?token="+token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, key.PublicKey, resp.Body.String())
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models"
@@ -229,3 +230,97 @@ func TestAPIDeleteReleaseByTagName(t *testing.T) {
 	req = NewRequestf(t, http.MethodDelete, fmt.Sprintf("/api/v1/repos/%s/%s/tags/release-tag?token=%s", owner.Name, repo.Name, token))
 	_ = session.MakeRequest(t, req, http.StatusNoContent)
 }
+
+func TestAPICreateReleaseExternalAttachment(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	checksum := "sha256:" + strings.Repeat("0123456789abcdef", 4)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/releases/1/assets/external?token="+token, &api.CreateExternalAttachmentOption{
+		Name:        "gitea-linux-amd64",
+		ExternalURL: "https://cdn.example.com/gitea-linux-amd64",
+		Checksum:    checksum,
+	})
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var attachment api.Attachment
+	DecodeJSON(t, resp, &attachment)
+	assert.Equal(t, "https://cdn.example.com/gitea-linux-amd64", attachment.ExternalURL)
+	assert.Equal(t, checksum, attachment.Checksum)
+
+	// the external asset is listed with the release
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/releases/1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var release api.Release
+	DecodeJSON(t, resp, &release)
+	var externalURLs []string
+	for _, asset := range release.Attachments {
+		externalURLs = append(externalURLs, asset.ExternalURL)
+	}
+	assert.Contains(t, externalURLs, "https://cdn.example.com/gitea-linux-amd64")
+
+	// downloads are redirected to the external url
+	req = NewRequest(t, "GET", "/attachments/"+attachment.UUID)
+	resp = MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, "https://cdn.example.com/gitea-linux-amd64", resp.Header().Get("Location"))
+	req = NewRequest(t, "GET", "/user2/repo1/releases/download/v1.1/gitea-linux-amd64")
+	resp = MakeRequest(t, req, http.StatusFound)
+	assert.Equal(t, attachment.DownloadURL, resp.Header().Get("Location"))
+
+	// invalid assets
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/releases/1/assets/external?token="+token, &api.CreateExternalAttachmentOption{
+		Name:        "gitea-linux-amd64",
+		ExternalURL: "https://cdn.example.com/gitea-linux-amd64",
+		Checksum:    "sha256:1234",
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/releases/1/assets/external?token="+token, &api.CreateExternalAttachmentOption{
+		Name:        "gitea-linux-amd64",
+		ExternalURL: "file:///etc/passwd",
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
+
+func TestAPIReleaseRestrictedActions(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo.OwnerID}).(*user_model.User)
+
+	// restrict the team of user4 to publishing releases
+	adminToken := getUserToken(t, "user1")
+	canEdit := false
+	req := NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/teams/2?token=%s", adminToken), &api.EditTeamOption{
+		Name:            "team1",
+		CanEditReleases: &canEdit,
+	})
+	resp := MakeRequest(t, req, http.StatusOK)
+	var apiTeam api.Team
+	DecodeJSON(t, resp, &apiTeam)
+	assert.True(t, apiTeam.CanCreateReleases)
+	assert.False(t, apiTeam.CanEditReleases)
+	assert.True(t, apiTeam.CanUploadReleaseAssets)
+
+	session := loginUser(t, "user4")
+	token := getTokenForLoggedInUser(t, session)
+	release := createNewReleaseUsingAPI(t, session, token, owner, repo, "v0.0.1", "", "v0.0.1", "test")
+
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/repos/%s/%s/releases/%d?token=%s", owner.Name, repo.Name, release.ID, token), &api.EditReleaseOption{
+		Title: "edited",
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestf(t, http.MethodDelete, "/api/v1/repos/%s/%s/releases/%d?token=%s", owner.Name, repo.Name, release.ID, token)
+	session.MakeRequest(t, req, http.StatusForbidden)
+
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/releases/%d/assets/external?token=%s", owner.Name, repo.Name, release.ID, token), &api.CreateExternalAttachmentOption{
+		Name:        "gitea-linux-amd64",
+		ExternalURL: "https://cdn.example.com/gitea-linux-amd64",
+	})
+	session.MakeRequest(t, req, http.StatusCreated)
+
+	// site admins are not restricted
+	req = NewRequestf(t, http.MethodDelete, "/api/v1/repos/%s/%s/releases/%d?token=%s", owner.Name, repo.Name, release.ID, adminToken)
+	MakeRequest(t, req, http.StatusNoContent)
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"net/url"
+	"testing"
+
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoAuditLog(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		repo2 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 2}).(*repo_model.Repository)
+		repo2Owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo2.OwnerID}).(*user_model.User)
+		user4 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+
+		session := loginUser(t, repo2Owner.Name)
+		testCtx := NewAPITestContext(t, repo2Owner.Name, repo2.Name)
+
+		t.Run("AddCollaborator", doAPIAddCollaborator(testCtx, user4.Name, perm.AccessModeRead))
+		t.Run("UpdateCollaborator", doAPIAddCollaborator(testCtx, user4.Name, perm.AccessModeWrite))
+
+		req := NewRequestf(t, "GET", "/api/v1/repos/%s/%s/audit?action=collaborator&token=%s", repo2Owner.Name, repo2.Name, testCtx.Token)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+
+		var logs []*api.RepoAuditLog
+		DecodeJSON(t, resp, &logs)
+		if assert.Len(t, logs, 2) {
+			assert.Equal(t, string(repo_model.AuditActionCollaboratorUpdate), logs[0].Action)
+			assert.Equal(t, string(repo_model.AuditActionCollaboratorAdd), logs[1].Action)
+			assert.Equal(t, user4.Name, logs[0].Target)
+			assert.Equal(t, repo2Owner.Name, logs[0].Doer.UserName)
+		}
+
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/audit?action=webhook&token=%s", repo2Owner.Name, repo2.Name, testCtx.Token)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &logs)
+		assert.Empty(t, logs)
+
+		// only repository admins can read the audit log
+		session = loginUser(t, user4.Name)
+		token := getTokenForLoggedInUser(t, session)
+		req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/audit?token=%s", repo2Owner.Name, repo2.Name, token)
+		session.MakeRequest(t, req, http.StatusForbidden)
+	})
+}
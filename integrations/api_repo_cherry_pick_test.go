@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoCherryPick(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo1/git/cherry-pick?token=%s", token)
+
+	// Cherry-pick onto a new branch and open a pull request
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOptions{
+		SHA:               "5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2",
+		BranchName:        "master",
+		NewBranchName:     "cherry-picked",
+		CreatePullRequest: true,
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var result api.CherryPickCommitResponse
+	DecodeJSON(t, resp, &result)
+	assert.EqualValues(t, "cherry-picked", result.Branch)
+	assert.Contains(t, result.Commit.Message, "Cherry-pick: 5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2")
+	if assert.Len(t, result.Commit.Parents, 1) {
+		assert.EqualValues(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", result.Commit.Parents[0].SHA)
+	}
+	if assert.NotNil(t, result.PullRequest) {
+		unittest.AssertExistsAndLoadBean(t, &models.PullRequest{ID: result.PullRequest.ID, HeadBranch: "cherry-picked", BaseBranch: "master"})
+	}
+
+	// The branch exists now
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOptions{
+		SHA:           "5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2",
+		NewBranchName: "cherry-picked",
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// A pull request needs a new branch
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOptions{
+		SHA:               "5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2",
+		CreatePullRequest: true,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// Revert the cherry-picked commit on its branch
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/git/revert?token=%s", token), &api.CherryPickCommitOptions{
+		SHA:        result.Commit.SHA,
+		BranchName: "cherry-picked",
+		Message:    "Revert the change",
+	})
+	resp = session.MakeRequest(t, req, http.StatusCreated)
+	var reverted api.CherryPickCommitResponse
+	DecodeJSON(t, resp, &reverted)
+	assert.EqualValues(t, "cherry-picked", reverted.Branch)
+	assert.Nil(t, reverted.PullRequest)
+	assert.Contains(t, reverted.Commit.Message, "Revert the change")
+
+	// Unknown commits are not found
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CherryPickCommitOptions{SHA: "0000000000000000000000000000000000000000"})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// Readers can't cherry-pick
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo1/git/cherry-pick?token=%s", token4), &api.CherryPickCommitOptions{
+		SHA: "5c050d3b6d2db231ab1f64e324f1b6b9a0b181c2",
+	})
+	session4.MakeRequest(t, req, http.StatusForbidden)
+}
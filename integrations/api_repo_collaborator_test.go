@@ -5,9 +5,11 @@
 package integrations
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models/perm"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -127,5 +129,94 @@ func TestAPIRepoCollaboratorPermission(t *testing.T) {
 
 			assert.Equal(t, "read", repoPermission.Permission)
 		})
+
+		t.Run("TemporaryCollaborator", func(t *testing.T) {
+			expires := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+			req := NewRequestWithJSON(t, "PUT", fmt.Sprintf("/api/v1/repos/%s/%s/collaborators/%s?token=%s", repo2Owner.Name, repo2.Name, user5.Name, testCtx.Token), &api.AddCollaboratorOption{
+				Expires: &expires,
+			})
+			session.MakeRequest(t, req, http.StatusNoContent)
+
+			req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/collaborators/%s/permission?token=%s", repo2Owner.Name, repo2.Name, user5.Name, testCtx.Token)
+			resp := session.MakeRequest(t, req, http.StatusOK)
+			var repoPermission api.RepoCollaboratorPermission
+			DecodeJSON(t, resp, &repoPermission)
+			if assert.NotNil(t, repoPermission.Expires) {
+				assert.True(t, expires.Equal(*repoPermission.Expires))
+			}
+
+			req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/temporary_access?token=%s", repo2Owner.Name, repo2.Name, testCtx.Token)
+			resp = session.MakeRequest(t, req, http.StatusOK)
+			var accesses []*api.TemporaryAccess
+			DecodeJSON(t, resp, &accesses)
+			if assert.Len(t, accesses, 1) {
+				assert.Equal(t, user5.Name, accesses[0].User.UserName)
+				assert.Nil(t, accesses[0].Team)
+			}
+
+			past := time.Now().Add(-time.Hour)
+			req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("/api/v1/repos/%s/%s/collaborators/%s?token=%s", repo2Owner.Name, repo2.Name, user5.Name, testCtx.Token), &api.AddCollaboratorOption{
+				Expires: &past,
+			})
+			session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+		})
+	})
+}
+
+func TestAPIRepoSetCollaborators(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 4}).(*repo_model.Repository)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo.OwnerID}).(*user_model.User)
+	token := getUserToken(t, owner.Name)
+	link := fmt.Sprintf("/api/v1/repos/%s/%s/collaborators?token=%s", owner.Name, repo.Name, token)
+
+	// nothing is changed if a user can't be a collaborator
+	req := NewRequestWithJSON(t, "PUT", link, &api.SetCollaboratorsOption{
+		Collaborators: []*api.CollaboratorAccessOption{{Username: "user4", Permission: "admin"}, {Username: owner.Name}},
+	})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Collaboration{RepoID: repo.ID, UserID: 4, Mode: perm.AccessModeWrite})
+
+	req = NewRequestWithJSON(t, "PUT", link, &api.SetCollaboratorsOption{
+		Collaborators: []*api.CollaboratorAccessOption{{Username: "user4", Permission: "admin"}, {Username: "user2", Permission: "read"}, {Username: "user29"}},
+	})
+	resp := MakeRequest(t, req, http.StatusOK)
+	var changes api.CollaboratorChanges
+	DecodeJSON(t, resp, &changes)
+	if assert.Len(t, changes.Added, 1) {
+		assert.Equal(t, "user2", changes.Added[0].User.UserName)
+		assert.Empty(t, changes.Added[0].OldPermission)
+		assert.Equal(t, "read", changes.Added[0].NewPermission)
+	}
+	if assert.Len(t, changes.Updated, 1) {
+		assert.Equal(t, "user4", changes.Updated[0].User.UserName)
+		assert.Equal(t, "write", changes.Updated[0].OldPermission)
+		assert.Equal(t, "admin", changes.Updated[0].NewPermission)
+	}
+	assert.Len(t, changes.Unchanged, 1)
+	assert.Empty(t, changes.Removed)
+
+	// collaborators listed without permission keep theirs
+	req = NewRequestWithJSON(t, "PUT", link, &api.SetCollaboratorsOption{
+		Collaborators: []*api.CollaboratorAccessOption{{Username: "user4"}, {Username: "user2", Permission: "read"}, {Username: "user29"}},
+	})
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &changes)
+	assert.Empty(t, changes.Updated)
+	assert.Len(t, changes.Unchanged, 3)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Collaboration{RepoID: repo.ID, UserID: 4, Mode: perm.AccessModeAdmin})
+
+	req = NewRequestWithJSON(t, "PUT", link, &api.SetCollaboratorsOption{
+		Collaborators: []*api.CollaboratorAccessOption{{Username: "user2", Permission: "read"}},
 	})
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &changes)
+	assert.Len(t, changes.Removed, 2)
+	assert.Len(t, changes.Unchanged, 1)
+	unittest.AssertCount(t, &repo_model.Collaboration{RepoID: repo.ID}, 1)
+
+	// only repository admins can change the collaborators
+	req = NewRequestWithJSON(t, "PUT", fmt.Sprintf("/api/v1/repos/%s/%s/collaborators?token=%s", owner.Name, repo.Name, getUserToken(t, "user2")), &api.SetCollaboratorsOption{})
+	MakeRequest(t, req, http.StatusForbidden)
 }
@@ -0,0 +1,133 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoDeployTokens(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	setting.LFS.StartServer = true
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/user2/repo2/deploy_tokens?token=%s", token)
+
+	createDeployToken := func(name string, scopes ...string) *api.DeployToken {
+		req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateDeployTokenOption{Name: name, Scopes: scopes})
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var deployToken api.DeployToken
+		DecodeJSON(t, resp, &deployToken)
+		assert.True(t, strings.HasPrefix(deployToken.Token, "gdt_"))
+		return &deployToken
+	}
+	ci := createDeployToken("ci", "read_repository", "read_lfs")
+	assert.Equal(t, []string{"read_repository", "read_lfs"}, ci.Scopes)
+	packages := createDeployToken("packages", "read_package")
+
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.CreateDeployTokenOption{Name: "ci", Scopes: []string{"read_lfs"}})
+	session.MakeRequest(t, req, http.StatusConflict)
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.CreateDeployTokenOption{Name: "push", Scopes: []string{"write_repository"}})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the token is only returned when it is created
+	req = NewRequest(t, "GET", urlStr)
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var deployTokens []*api.DeployToken
+	DecodeJSON(t, resp, &deployTokens)
+	if assert.Len(t, deployTokens, 2) {
+		assert.Empty(t, deployTokens[0].Token)
+		assert.Equal(t, ci.TokenLastEight, deployTokens[0].TokenLastEight)
+	}
+
+	infoRefs := func(repo, service, deployToken string, expectedStatus int) {
+		req := NewRequestf(t, "GET", "/user2/%s.git/info/refs?service=%s", repo, service)
+		if deployToken != "" {
+			req.SetBasicAuth("deploy", deployToken)
+		}
+		MakeRequest(t, req, expectedStatus)
+	}
+	infoRefs("repo2", "git-upload-pack", "", http.StatusUnauthorized)
+	infoRefs("repo2", "git-upload-pack", ci.Token, http.StatusOK)
+	infoRefs("repo2", "git-receive-pack", ci.Token, http.StatusUnauthorized)
+	infoRefs("repo2", "git-upload-pack", packages.Token, http.StatusUnauthorized)
+	// the token only grants access to its own repository
+	infoRefs("repo20", "git-upload-pack", ci.Token, http.StatusUnauthorized)
+
+	lfsBatch := func(operation, deployToken string, expectedStatus int) {
+		req := NewRequestWithJSON(t, "POST", "/user2/repo2.git/info/lfs/objects/batch", &lfs.BatchRequest{Operation: operation})
+		req.Header.Set("Accept", lfs.MediaType)
+		req.Header.Set("Content-Type", lfs.MediaType)
+		req.SetBasicAuth("deploy", deployToken)
+		MakeRequest(t, req, expectedStatus)
+	}
+	lfsBatch("download", ci.Token, http.StatusOK)
+	lfsBatch("upload", ci.Token, http.StatusUnauthorized)
+	lfsBatch("download", packages.Token, http.StatusUnauthorized)
+
+	// only administrators of the repository manage its deploy tokens
+	session4 := loginUser(t, "user4")
+	token4 := getTokenForLoggedInUser(t, session4)
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/user2/repo2/deploy_tokens?token=%s", token4))
+	session4.MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "DELETE", fmt.Sprintf("/api/v1/repos/user2/repo2/deploy_tokens/%d?token=%s", ci.ID, token))
+	session.MakeRequest(t, req, http.StatusNoContent)
+	session.MakeRequest(t, req, http.StatusNotFound)
+	infoRefs("repo2", "git-upload-pack", ci.Token, http.StatusUnauthorized)
+}
+
+func TestAPIRepoDeployTokenPackages(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	session := loginUser(t, user.Name)
+	token := getTokenForLoggedInUser(t, session)
+	req := NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/user2/repo2/deploy_tokens?token=%s", token),
+		&api.CreateDeployTokenOption{Name: "packages", Scopes: []string{"read_package"}})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var deployToken api.DeployToken
+	DecodeJSON(t, resp, &deployToken)
+
+	for _, name := range []string{"linked", "unlinked"} {
+		req = NewRequestWithBody(t, "PUT", fmt.Sprintf("/api/packages/user2/generic/%s/1.0/file.bin", name), bytes.NewReader([]byte{1, 2, 3}))
+		AddBasicAuthHeader(req, user.Name)
+		MakeRequest(t, req, http.StatusCreated)
+	}
+	p, err := packages_model.GetPackageByName(db.DefaultContext, user.ID, packages_model.TypeGeneric, "linked")
+	assert.NoError(t, err)
+	assert.NoError(t, packages_model.SetRepositoryLink(db.DefaultContext, p.ID, 2))
+
+	// the packages of a private owner are only readable through the token if they are linked to its repository
+	user.Visibility = api.VisibleTypePrivate
+	assert.NoError(t, user_model.UpdateUserCols(db.DefaultContext, user, "visibility"))
+
+	getPackage := func(name, deployToken string, expectedStatus int) {
+		req := NewRequestf(t, "GET", "/api/v1/packages/user2/generic/%s/1.0", name)
+		if deployToken != "" {
+			req.SetBasicAuth("deploy", deployToken)
+		}
+		MakeRequest(t, req, expectedStatus)
+	}
+	getPackage("linked", "", http.StatusForbidden)
+	getPackage("linked", deployToken.Token, http.StatusOK)
+	getPackage("unlinked", deployToken.Token, http.StatusForbidden)
+}
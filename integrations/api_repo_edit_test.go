@@ -318,6 +318,20 @@ func TestAPIRepoEdit(t *testing.T) {
 		})
 		_ = session.MakeRequest(t, req, http.StatusOK)
 
+		// Test to change the trust model
+		trustModel := "weboftrust"
+		req = NewRequestWithJSON(t, "PATCH", url, &api.EditRepoOption{
+			TrustModel: &trustModel,
+		})
+		_ = session.MakeRequest(t, req, http.StatusOK)
+		repo15 = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 15}).(*repo_model.Repository)
+		assert.EqualValues(t, repo_model.WebOfTrustTrustModel, repo15.TrustModel)
+		trustModel = "unknown"
+		req = NewRequestWithJSON(t, "PATCH", url, &api.EditRepoOption{
+			TrustModel: &trustModel,
+		})
+		_ = session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
 		// Test using org repo "user3/repo3" where user2 is a collaborator
 		origRepoEditOption = getRepoEditOptionFromRepo(repo3)
 		repoEditOption = getNewRepoEditOption(origRepoEditOption)
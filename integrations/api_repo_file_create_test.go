@@ -65,6 +65,7 @@ func getExpectedFileResponseForCreate(repoFullName, commitID, treePath string) *
 			SHA:         sha,
 			Size:        16,
 			Type:        "file",
+			Mode:        "100644",
 			Encoding:    &encoding,
 			Content:     &content,
 			URL:         &selfURL,
@@ -303,3 +304,68 @@ func TestAPICreateFile(t *testing.T) {
 		gitRepo.Close()
 	})
 }
+
+func TestAPICreateFileEntries(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+		session := loginUser(t, user2.Name)
+		token := getTokenForLoggedInUser(t, session)
+
+		// Test creating an executable file
+		createFileOptions := getCreateFileOptions()
+		executable := true
+		createFileOptions.Executable = &executable
+		url := fmt.Sprintf("/api/v1/repos/%s/%s/contents/%s?token=%s", user2.Name, repo1.Name, "bin/run.sh", token)
+		req := NewRequestWithJSON(t, "POST", url, &createFileOptions)
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var fileResponse api.FileResponse
+		DecodeJSON(t, resp, &fileResponse)
+		assert.EqualValues(t, "file", fileResponse.Content.Type)
+		assert.EqualValues(t, "100755", fileResponse.Content.Mode)
+		assert.True(t, fileResponse.Content.Executable)
+		assert.NotNil(t, fileResponse.Content.DownloadURL)
+
+		// Test creating a symlink
+		createFileOptions = getCreateFileOptions()
+		createFileOptions.Type = "symlink"
+		createFileOptions.Target = "bin/run.sh"
+		url = fmt.Sprintf("/api/v1/repos/%s/%s/contents/%s?token=%s", user2.Name, repo1.Name, "run", token)
+		req = NewRequestWithJSON(t, "POST", url, &createFileOptions)
+		resp = session.MakeRequest(t, req, http.StatusCreated)
+		DecodeJSON(t, resp, &fileResponse)
+		assert.EqualValues(t, "symlink", fileResponse.Content.Type)
+		assert.EqualValues(t, "120000", fileResponse.Content.Mode)
+		assert.EqualValues(t, "bin/run.sh", *fileResponse.Content.Target)
+
+		// Test creating a submodule without a url, should fail
+		createFileOptions = getCreateFileOptions()
+		createFileOptions.Type = "submodule"
+		createFileOptions.SubmoduleSHA = "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+		url = fmt.Sprintf("/api/v1/repos/%s/%s/contents/%s?token=%s", user2.Name, repo1.Name, "lib", token)
+		req = NewRequestWithJSON(t, "POST", url, &createFileOptions)
+		session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		// Test creating a submodule
+		createFileOptions.SubmoduleGitURL = "https://example.com/lib.git"
+		req = NewRequestWithJSON(t, "POST", url, &createFileOptions)
+		resp = session.MakeRequest(t, req, http.StatusCreated)
+		DecodeJSON(t, resp, &fileResponse)
+		assert.EqualValues(t, "submodule", fileResponse.Content.Type)
+		assert.EqualValues(t, "160000", fileResponse.Content.Mode)
+		assert.EqualValues(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", fileResponse.Content.SHA)
+		assert.EqualValues(t, "https://example.com/lib.git", *fileResponse.Content.SubmoduleGitURL)
+
+		// Test removing the executable bit of a file
+		updateFileOptions := getUpdateFileOptions()
+		executable = false
+		updateFileOptions.Executable = &executable
+		updateFileOptions.SHA = "a635aa942442ddfdba07468cf9661c08fbdf0ebf"
+		url = fmt.Sprintf("/api/v1/repos/%s/%s/contents/%s?token=%s", user2.Name, repo1.Name, "bin/run.sh", token)
+		req = NewRequestWithJSON(t, "PUT", url, updateFileOptions)
+		resp = session.MakeRequest(t, req, http.StatusOK)
+		DecodeJSON(t, resp, &fileResponse)
+		assert.EqualValues(t, "100644", fileResponse.Content.Mode)
+		assert.False(t, fileResponse.Content.Executable)
+	})
+}
@@ -62,6 +62,7 @@ func getExpectedFileResponseForUpdate(commitID, treePath string) *api.FileRespon
 			Path:        treePath,
 			SHA:         sha,
 			Type:        "file",
+			Mode:        "100644",
 			Size:        20,
 			Encoding:    &encoding,
 			Content:     &content,
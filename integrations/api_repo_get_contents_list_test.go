@@ -34,6 +34,7 @@ func getExpectedContentsListResponseForContents(ref, refType string) []*api.Cont
 			Path:        treePath,
 			SHA:         sha,
 			Type:        "file",
+			Mode:        "100644",
 			Size:        30,
 			URL:         &selfURL,
 			HTMLURL:     &htmlURL,
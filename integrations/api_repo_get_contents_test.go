@@ -34,6 +34,7 @@ func getExpectedContentsResponseForContents(ref, refType string) *api.ContentsRe
 		Path:        treePath,
 		SHA:         sha,
 		Type:        "file",
+		Mode:        "100644",
 		Size:        30,
 		Encoding:    &encoding,
 		Content:     &content,
@@ -11,6 +11,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 
 	"github.com/stretchr/testify/assert"
@@ -76,3 +77,44 @@ func TestAPIReposGitBlobs(t *testing.T) {
 	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/git/blobs/d56a3073c1dbb7b15963110a049d50cdb5db99fc?access=%s", user3.Name, repo3.Name, token4)
 	session.MakeRequest(t, req, http.StatusNotFound)
 }
+
+func TestAPIReposGitBlobsBatch(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := emptyTestSession(t)
+	readmeSHA := "4b4851ad51df6a7d9f25c979345979eaeb5b349f"
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/blobs", &api.GetGitBlobsOptions{
+		SHAs:  []string{readmeSHA, "0000000000000000000000000000000000000001"},
+		Paths: []string{"README.md", "missing.md"},
+	})
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	var blobs api.GitBlobsResponse
+	DecodeJSON(t, resp, &blobs)
+	assert.Equal(t, []string{"0000000000000000000000000000000000000001", "missing.md"}, blobs.NotFound)
+	assert.False(t, blobs.Truncated)
+	if assert.Len(t, blobs.Blobs, 2) {
+		assert.Equal(t, readmeSHA, blobs.Blobs[0].SHA)
+		assert.Equal(t, readmeSHA, blobs.Blobs[1].SHA)
+		assert.Equal(t, "README.md", blobs.Blobs[1].Path)
+		assert.Equal(t, "IyByZXBvMQoKRGVzY3JpcHRpb24gZm9yIHJlcG8x", blobs.Blobs[1].Content)
+	}
+
+	// the ref of the paths has to exist
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/blobs", &api.GetGitBlobsOptions{
+		Paths: []string{"README.md"},
+		Ref:   "missing-branch",
+	})
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// the number of requested items is limited
+	shas := make([]string, setting.API.MaxBatchItems+1)
+	for i := range shas {
+		shas[i] = readmeSHA
+	}
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/git/blobs", &api.GetGitBlobsOptions{SHAs: shas})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// private repositories aren't visible without a token
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo16/git/blobs", &api.GetGitBlobsOptions{Paths: []string{"README.md"}})
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
@@ -0,0 +1,119 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	stdCtx "context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPICreateCommit(t *testing.T) {
+	onGiteaRun(t, func(t *testing.T, u *url.URL) {
+		user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+		repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+		session := loginUser(t, user2.Name)
+		token := getTokenForLoggedInUser(t, session)
+		urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/git/commits?token=%s", user2.Name, repo1.Name, token)
+
+		gitRepo, err := git.OpenRepository(stdCtx.Background(), repo1.RepoPath())
+		assert.NoError(t, err)
+		defer gitRepo.Close()
+		baseCommitID, err := gitRepo.GetBranchCommitID(repo1.DefaultBranch)
+		assert.NoError(t, err)
+
+		executable := true
+		opts := &api.CreateCommitOptions{
+			Base:    baseCommitID,
+			Message: "Change several files",
+			Author: api.Identity{
+				Name:  "John Doe",
+				Email: "johndoe@example.com",
+			},
+			Files: []*api.CommitFileOptions{
+				{
+					Operation:  "create",
+					Path:       "bot/run.sh",
+					Content:    base64.StdEncoding.EncodeToString([]byte("#!/bin/sh\n")),
+					Executable: &executable,
+				},
+				{
+					Operation: "update",
+					Path:      "README.md",
+					Content:   base64.StdEncoding.EncodeToString([]byte("# repo1\n\nUpdated by a bot\n")),
+					SHA:       "4b4851ad51df6a7d9f25c979345979eaeb5b349f",
+				},
+			},
+		}
+		req := NewRequestWithJSON(t, "POST", urlStr, opts)
+		resp := session.MakeRequest(t, req, http.StatusCreated)
+		var fileResponse api.FileResponse
+		DecodeJSON(t, resp, &fileResponse)
+		assert.Equal(t, "Change several files\n", fileResponse.Commit.Message)
+		assert.Equal(t, "johndoe@example.com", fileResponse.Commit.Author.Email)
+		if assert.Len(t, fileResponse.Commit.Parents, 1) {
+			assert.Equal(t, baseCommitID, fileResponse.Commit.Parents[0].SHA)
+		}
+		commitID, err := gitRepo.GetBranchCommitID(repo1.DefaultBranch)
+		assert.NoError(t, err)
+		assert.Equal(t, fileResponse.Commit.SHA, commitID)
+		commit, err := gitRepo.GetCommit(commitID)
+		assert.NoError(t, err)
+		entry, err := commit.GetTreeEntryByPath("bot/run.sh")
+		assert.NoError(t, err)
+		assert.True(t, entry.IsExecutable())
+
+		// The branch has been changed since the base commit
+		req = NewRequestWithJSON(t, "POST", urlStr, opts)
+		session.MakeRequest(t, req, http.StatusConflict)
+
+		// A created file must not exist
+		opts.Base = commitID
+		opts.Files = []*api.CommitFileOptions{
+			{
+				Operation: "create",
+				Path:      "bot/run.sh",
+			},
+		}
+		req = NewRequestWithJSON(t, "POST", urlStr, opts)
+		session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+		// A new branch is created from the base commit
+		opts.Base = baseCommitID
+		opts.BranchName = "bot-branch"
+		opts.Files = []*api.CommitFileOptions{
+			{
+				Operation: "delete",
+				Path:      "README.md",
+			},
+		}
+		req = NewRequestWithJSON(t, "POST", urlStr, opts)
+		resp = session.MakeRequest(t, req, http.StatusCreated)
+		DecodeJSON(t, resp, &fileResponse)
+		commitID, err = gitRepo.GetBranchCommitID("bot-branch")
+		assert.NoError(t, err)
+		assert.Equal(t, fileResponse.Commit.SHA, commitID)
+		commit, err = gitRepo.GetCommit(commitID)
+		assert.NoError(t, err)
+		_, err = commit.GetTreeEntryByPath("README.md")
+		assert.True(t, git.IsErrNotExist(err))
+
+		// The base commit has to exist
+		opts.Base = "0000000000000000000000000000000000000001"
+		req = NewRequestWithJSON(t, "POST", urlStr, opts)
+		session.MakeRequest(t, req, http.StatusNotFound)
+	})
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoHousekeeping(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	session := loginUser(t, user2.Name)
+	token := getTokenForLoggedInUser(t, session)
+	urlStr := fmt.Sprintf("/api/v1/repos/%s/%s/housekeeping?token=%s", user2.Name, repo1.Name, token)
+
+	// Unknown operations are rejected
+	req := NewRequestWithJSON(t, "POST", urlStr, &api.RepoHousekeepingOptions{
+		Operations: []string{"gc", "defragment"},
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.RepoHousekeepingOptions{
+		Operations: []string{"gc", "clear_language_stats", "gc"},
+	})
+	resp := session.MakeRequest(t, req, http.StatusAccepted)
+	var job api.RepoHousekeepingJob
+	DecodeJSON(t, resp, &job)
+	assert.Equal(t, []string{"gc", "clear_language_stats"}, job.Operations)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/%s/housekeeping/%d?token=%s", user2.Name, repo1.Name, job.ID, token))
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var fetched api.RepoHousekeepingJob
+	DecodeJSON(t, resp, &fetched)
+	assert.Equal(t, job.ID, fetched.ID)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/v1/repos/%s/%s/housekeeping/%d?token=%s", user2.Name, repo1.Name, job.ID+1000, token))
+	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// A repository can only be housekept once per interval
+	req = NewRequestWithJSON(t, "POST", urlStr, &api.RepoHousekeepingOptions{
+		Operations: []string{"clear_last_commit_cache"},
+	})
+	resp = session.MakeRequest(t, req, http.StatusTooManyRequests)
+	assert.NotEmpty(t, resp.Header().Get("Retry-After"))
+
+	// Only owners can housekeep a repository
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/housekeeping?token=%s", user2.Name, repo1.Name, token), &api.RepoHousekeepingOptions{
+		Operations: []string{"gc"},
+	})
+	session.MakeRequest(t, req, http.StatusForbidden)
+}
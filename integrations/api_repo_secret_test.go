@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoSecrets(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/secrets/ci_token?token="+token, &api.SetSecretOption{Value: "s3cr3t"})
+	MakeRequest(t, req, http.StatusCreated)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/secrets/CI_TOKEN?token="+token, &api.SetSecretOption{Value: "updated"})
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/secrets/GITEA_TOKEN?token="+token, &api.SetSecretOption{Value: "reserved"})
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the values of secrets are encrypted and never returned
+	secret := unittest.AssertExistsAndLoadBean(t, &secret_model.Secret{RepoID: 1, Name: "CI_TOKEN"}).(*secret_model.Secret)
+	assert.NotContains(t, secret.Data, "updated")
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/secrets?token=%s", token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), "updated")
+	var secrets []*api.Secret
+	DecodeJSON(t, resp, &secrets)
+	if assert.Len(t, secrets, 1) {
+		assert.Equal(t, "CI_TOKEN", secrets[0].Name)
+	}
+
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/variables/DEPLOY_ENV?token="+token, &api.SetSecretOption{Value: "staging"})
+	MakeRequest(t, req, http.StatusCreated)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/variables/deploy_env?token=%s", token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var variable api.Variable
+	DecodeJSON(t, resp, &variable)
+	assert.Equal(t, "DEPLOY_ENV", variable.Name)
+	assert.Equal(t, "staging", variable.Value)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/secrets/CI_TOKEN?token=%s", token)
+	MakeRequest(t, req, http.StatusNoContent)
+	req = NewRequestf(t, "DELETE", "/api/v1/repos/user2/repo1/secrets/CI_TOKEN?token=%s", token)
+	MakeRequest(t, req, http.StatusNotFound)
+	unittest.AssertCount(t, &secret_model.AuditLog{RepoID: 1}, 4)
+
+	// only administrators of the repository can manage its secrets
+	token4 := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/variables?token=%s", token4)
+	MakeRequest(t, req, http.StatusForbidden)
+}
+
+func TestAPIOrgSecrets(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "PUT", "/api/v1/orgs/user3/secrets/ORG_TOKEN?token="+token, &api.SetSecretOption{Value: "org"})
+	MakeRequest(t, req, http.StatusCreated)
+	req = NewRequestf(t, "GET", "/api/v1/orgs/user3/secrets?token=%s", token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var secrets []*api.Secret
+	DecodeJSON(t, resp, &secrets)
+	assert.Len(t, secrets, 1)
+	unittest.AssertExistsAndLoadBean(t, &secret_model.Secret{OwnerID: 3, Name: "ORG_TOKEN"})
+
+	// only owners of the organization can manage its secrets
+	token4 := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/orgs/user3/secrets/ORG_TOKEN?token="+token4, &api.SetSecretOption{Value: "org"})
+	MakeRequest(t, req, http.StatusForbidden)
+}
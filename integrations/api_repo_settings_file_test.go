@@ -0,0 +1,70 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"encoding/base64"
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoSettingsFile(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	// repositories without settings file
+	req := NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/settings_file/drift?token=%s", token)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/settings_file/apply?token=%s", token)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	createFileOptions := getCreateFileOptions()
+	createFileOptions.Content = base64.StdEncoding.EncodeToString([]byte("labels:\n  - name: bug\n    color: red\n"))
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/contents/.gitea/settings.yaml?token="+token, &createFileOptions)
+	resp := MakeRequest(t, req, http.StatusCreated)
+	var fileResponse api.FileResponse
+	DecodeJSON(t, resp, &fileResponse)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/settings_file/drift?token=%s", token)
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	updateFileOptions := getUpdateFileOptions()
+	updateFileOptions.SHA = fileResponse.Content.SHA
+	updateFileOptions.Content = base64.StdEncoding.EncodeToString([]byte("labels:\n  - name: bug\n    color: '#ff0000'\n"))
+	req = NewRequestWithJSON(t, "PUT", "/api/v1/repos/user2/repo1/contents/.gitea/settings.yaml?token="+token, updateFileOptions)
+	MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/settings_file/apply?token=%s", token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var report api.RepoSettingsReport
+	DecodeJSON(t, resp, &report)
+	assert.EqualValues(t, ".gitea/settings.yaml", report.Path)
+	assert.NotEmpty(t, report.CommitID)
+	for _, change := range report.Changes {
+		assert.Empty(t, change.Error)
+	}
+	unittest.AssertExistsAndLoadBean(t, &models.Label{RepoID: 1, Name: "bug", Color: "#ff0000"})
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/settings_file/drift?token=%s", token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &report)
+	assert.Empty(t, report.Changes)
+
+	// only administrators of the repository can see and apply the settings
+	session = loginUser(t, "user4")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequestf(t, "GET", "/api/v1/repos/user2/repo1/settings_file/drift?token=%s", token)
+	MakeRequest(t, req, http.StatusForbidden)
+	req = NewRequestf(t, "POST", "/api/v1/repos/user2/repo1/settings_file/apply?token=%s", token)
+	MakeRequest(t, req, http.StatusForbidden)
+}
@@ -0,0 +1,110 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIRepoContributorStats(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// the statistics are generated in the background
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/contributors")
+	resp := MakeRequest(t, req, NoExpectedStatus)
+	if resp.Code == http.StatusAccepted {
+		assert.Eventually(t, func() bool {
+			resp = MakeRequest(t, req, NoExpectedStatus)
+			return resp.Code == http.StatusOK
+		}, 5*time.Second, 100*time.Millisecond)
+	}
+	assert.EqualValues(t, http.StatusOK, resp.Code)
+
+	var contributors []*api.ContributorStats
+	DecodeJSON(t, resp, &contributors)
+	if assert.Len(t, contributors, 1) {
+		assert.EqualValues(t, "address1@example.com", contributors[0].Email)
+		assert.EqualValues(t, 1, contributors[0].Commits)
+		assert.Len(t, contributors[0].Weeks, 1)
+	}
+
+	// private repositories need read access
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo2/stats/contributors")
+	MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIRepoCommitActivity(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// the only commit is older than a year
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/commits")
+	resp := MakeRequest(t, req, http.StatusOK)
+	var days []*api.CommitActivityDay
+	DecodeJSON(t, resp, &days)
+	assert.Empty(t, days)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/commits?since=2017-01-01T00:00:00Z")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &days)
+	assert.EqualValues(t, []*api.CommitActivityDay{{Date: "2017-03-19", Commits: 1}}, days)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/commits?since=2017-01-01T00:00:00Z&timezone=Asia/Tokyo&sha=master")
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &days)
+	assert.EqualValues(t, []*api.CommitActivityDay{{Date: "2017-03-20", Commits: 1}}, days)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/commits?timezone=Nowhere/Invalid")
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/commits?sha=not-a-branch")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// private repositories need read access
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo2/stats/commits")
+	MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIRepoCodeStats(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	// the statistics are generated in the background
+	req := NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/code_frequency")
+	resp := MakeRequest(t, req, NoExpectedStatus)
+	if resp.Code == http.StatusAccepted {
+		assert.Eventually(t, func() bool {
+			resp = MakeRequest(t, req, NoExpectedStatus)
+			return resp.Code == http.StatusOK
+		}, 5*time.Second, 100*time.Millisecond)
+	}
+	assert.EqualValues(t, http.StatusOK, resp.Code)
+
+	var weeks []*api.CodeFrequencyWeek
+	DecodeJSON(t, resp, &weeks)
+	if assert.Len(t, weeks, 1) {
+		assert.EqualValues(t, 3, weeks[0].Additions)
+	}
+
+	// both statistics are generated together
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/stats/punch_card")
+	resp = MakeRequest(t, req, http.StatusOK)
+	var hours []*api.PunchCardHour
+	DecodeJSON(t, resp, &hours)
+	assert.Len(t, hours, 7*24)
+	var commits int64
+	for _, hour := range hours {
+		commits += hour.Commits
+	}
+	assert.EqualValues(t, 1, commits)
+
+	// private repositories need read access
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo2/stats/punch_card")
+	MakeRequest(t, req, http.StatusNotFound)
+}
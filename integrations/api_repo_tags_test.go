@@ -66,6 +66,14 @@ func TestAPIRepoTags(t *testing.T) {
 
 	// check if it's gone
 	session.MakeRequest(t, req, http.StatusNotFound)
+
+	// the test instance has no key to sign tags
+	req = NewRequestWithJSON(t, "POST", fmt.Sprintf("/api/v1/repos/%s/%s/tags?token=%s", user.Name, repoName, token), &api.CreateTagOption{
+		TagName: "signed",
+		Message: "signed tag",
+		Sign:    true,
+	})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
 }
 
 func createNewTagUsingAPI(t *testing.T, session *TestSession, token, ownerName, repoName, name, target, msg string) *api.Tag {
@@ -10,6 +10,7 @@ import (
 	"net/url"
 	"os"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
@@ -232,6 +233,32 @@ func TestAPISearchRepo(t *testing.T) {
 	}
 }
 
+func TestAPISearchRepoByTopicsAndLicense(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	search := func(query string, status int) []*api.Repository {
+		req := NewRequest(t, "GET", "/api/v1/repos/search?"+query)
+		resp := MakeRequest(t, req, status)
+		if status != http.StatusOK {
+			return nil
+		}
+		var body api.SearchResults
+		DecodeJSON(t, resp, &body)
+		return body.Data
+	}
+
+	assert.Len(t, search("topics=graphql,database", http.StatusOK), 2)
+	repos := search("topics=golang,graphql&topics_match=all", http.StatusOK)
+	if assert.Len(t, repos, 1) {
+		assert.EqualValues(t, 33, repos[0].ID)
+		assert.EqualValues(t, "MIT", repos[0].License)
+	}
+	assert.Len(t, search("topics=golang&license=MIT", http.StatusOK), 1)
+	assert.Len(t, search("topics=golang&license=GPL-3.0", http.StatusOK), 0)
+	search("topics=golang&topics_match=some", http.StatusUnprocessableEntity)
+	assert.NotEmpty(t, search("sort=pushed&order=desc", http.StatusOK))
+}
+
 var repoCache = make(map[int64]*repo_model.Repository)
 
 func getRepo(t *testing.T, repoID int64) *repo_model.Repository {
@@ -622,6 +649,44 @@ func TestAPIRejectTransfer(t *testing.T) {
 	assert.Equal(t, "user2", apiRepo.Owner.UserName)
 }
 
+func TestAPIScheduleTransfer(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user1")
+	token := getTokenForLoggedInUser(t, session)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	link := fmt.Sprintf("/api/v1/repos/%s/%s/transfer", repo.OwnerName, repo.Name)
+
+	// a cutover in the past or a negative number of days is rejected
+	past := time.Now().Add(-time.Hour)
+	req := NewRequestWithJSON(t, "POST", link+"?token="+token, &api.TransferRepoOption{NewOwner: "user4", ScheduledAt: &past})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+	req = NewRequestWithJSON(t, "POST", link+"?token="+token, &api.TransferRepoOption{NewOwner: "user4", RedirectDays: -1})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	// the transfer of a site admin waits for its cutover only
+	future := time.Now().Add(time.Hour)
+	req = NewRequestWithJSON(t, "POST", link+"?token="+token, &api.TransferRepoOption{NewOwner: "user4", ScheduledAt: &future, RedirectDays: 7})
+	session.MakeRequest(t, req, http.StatusCreated)
+	transfer := unittest.AssertExistsAndLoadBean(t, &models.RepoTransfer{RepoID: repo.ID}).(*models.RepoTransfer)
+	assert.True(t, transfer.Accepted)
+	assert.EqualValues(t, future.Unix(), transfer.ScheduledUnix)
+	assert.EqualValues(t, 7, transfer.RedirectDays)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: repo.ID, OwnerID: repo.OwnerID, Status: repo_model.RepositoryPendingTransfer})
+
+	// the owner can cancel it before the cutover
+	session = loginUser(t, "user2")
+	token = getTokenForLoggedInUser(t, session)
+	req = NewRequest(t, "POST", link+"/cancel?token="+token)
+	session.MakeRequest(t, req, http.StatusOK)
+	unittest.AssertNotExistsBean(t, &models.RepoTransfer{RepoID: repo.ID})
+	repo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: repo.ID}).(*repo_model.Repository)
+	assert.Equal(t, repo_model.RepositoryReady, repo.Status)
+
+	req = NewRequest(t, "POST", link+"/cancel?token="+token)
+	session.MakeRequest(t, req, http.StatusNotFound)
+}
+
 func TestAPIGenerateRepo(t *testing.T) {
 	defer prepareTestEnv(t)()
 
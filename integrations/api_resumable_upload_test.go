@@ -0,0 +1,149 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func newTusRequest(t *testing.T, method, url string, body []byte) *http.Request {
+	req := NewRequestWithBody(t, method, url, bytes.NewReader(body))
+	req.Header.Set("Tus-Resumable", "1.0.0")
+	return req
+}
+
+func TestAPIResumableUploadGenericPackage(t *testing.T) {
+	defer prepareTestEnv(t)()
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	content := []byte("0123456789")
+	url := fmt.Sprintf("/api/packages/%s/generic/resumable/1.0.0/file.bin/uploads", user.Name)
+
+	req := newTusRequest(t, "OPTIONS", url, nil)
+	AddBasicAuthHeader(req, user.Name)
+	resp := MakeRequest(t, req, http.StatusNoContent)
+	assert.Equal(t, "1.0.0", resp.Header().Get("Tus-Version"))
+	assert.Contains(t, resp.Header().Get("Tus-Extension"), "creation")
+
+	req = NewRequest(t, "POST", url)
+	req.Header.Set("Upload-Length", "10")
+	AddBasicAuthHeader(req, user.Name)
+	MakeRequest(t, req, http.StatusPreconditionFailed)
+
+	req = newTusRequest(t, "POST", url, nil)
+	req.Header.Set("Upload-Length", "10")
+	AddBasicAuthHeader(req, user.Name)
+	resp = MakeRequest(t, req, http.StatusCreated)
+	location := resp.Header().Get("Location")
+	assert.True(t, strings.HasPrefix(location, setting.AppURL+url[1:]+"/"))
+	uploadURL := "/" + strings.TrimPrefix(location, setting.AppURL)
+
+	// uploads are only visible to their creator
+	req = newTusRequest(t, "HEAD", uploadURL, nil)
+	AddBasicAuthHeader(req, "user4")
+	MakeRequest(t, req, http.StatusNotFound)
+
+	patch := func(offset int, chunk []byte, expectedStatus int) *httptest.ResponseRecorder {
+		req := newTusRequest(t, "PATCH", uploadURL, chunk)
+		req.Header.Set("Content-Type", "application/offset+octet-stream")
+		req.Header.Set("Upload-Offset", fmt.Sprint(offset))
+		AddBasicAuthHeader(req, user.Name)
+		return MakeRequest(t, req, expectedStatus)
+	}
+
+	resp = patch(0, content[:4], http.StatusNoContent)
+	assert.Equal(t, "4", resp.Header().Get("Upload-Offset"))
+
+	req = newTusRequest(t, "HEAD", uploadURL, nil)
+	AddBasicAuthHeader(req, user.Name)
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "4", resp.Header().Get("Upload-Offset"))
+	assert.Equal(t, "10", resp.Header().Get("Upload-Length"))
+
+	patch(2, content[2:], http.StatusConflict)
+	resp = patch(4, content[4:], http.StatusNoContent)
+	assert.Equal(t, "10", resp.Header().Get("Upload-Offset"))
+
+	// the complete upload is removed
+	req = newTusRequest(t, "HEAD", uploadURL, nil)
+	AddBasicAuthHeader(req, user.Name)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", fmt.Sprintf("/api/packages/%s/generic/resumable/1.0.0/file.bin", user.Name))
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, content, resp.Body.Bytes())
+
+	pvs, err := packages.GetVersionsByPackageType(db.DefaultContext, user.ID, packages.TypeGeneric)
+	assert.NoError(t, err)
+	assert.Len(t, pvs, 1)
+
+	// aborted uploads
+	req = newTusRequest(t, "POST", url, nil)
+	req.Header.Set("Upload-Length", "10")
+	AddBasicAuthHeader(req, user.Name)
+	resp = MakeRequest(t, req, http.StatusCreated)
+	uploadURL = "/" + strings.TrimPrefix(resp.Header().Get("Location"), setting.AppURL)
+
+	req = newTusRequest(t, "DELETE", uploadURL, nil)
+	AddBasicAuthHeader(req, user.Name)
+	MakeRequest(t, req, http.StatusNoContent)
+
+	req = newTusRequest(t, "HEAD", uploadURL, nil)
+	AddBasicAuthHeader(req, user.Name)
+	MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestAPIResumableUploadReleaseAttachment(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo.OwnerID}).(*user_model.User)
+	session := loginUser(t, owner.LowerName)
+	token := getTokenForLoggedInUser(t, session)
+	release := createNewReleaseUsingAPI(t, session, token, owner, repo, "v0.0.2", "", "v0.0.2", "test")
+
+	content := []byte("resumable release attachment")
+	url := fmt.Sprintf("/api/v1/repos/%s/%s/releases/%d/assets/uploads?token=%s", owner.Name, repo.Name, release.ID, token)
+
+	req := newTusRequest(t, "POST", url, nil)
+	req.Header.Set("Upload-Length", fmt.Sprint(len(content)))
+	MakeRequest(t, req, http.StatusBadRequest)
+
+	req = newTusRequest(t, "POST", url, nil)
+	req.Header.Set("Upload-Length", fmt.Sprint(len(content)))
+	req.Header.Set("Upload-Metadata", "filename "+base64.StdEncoding.EncodeToString([]byte("notes.txt")))
+	resp := MakeRequest(t, req, http.StatusCreated)
+	uploadURL := "/" + strings.TrimPrefix(resp.Header().Get("Location"), setting.AppURL) + "?token=" + token
+
+	req = newTusRequest(t, "PATCH", uploadURL, content)
+	req.Header.Set("Content-Type", "application/offset+octet-stream")
+	req.Header.Set("Upload-Offset", "0")
+	MakeRequest(t, req, http.StatusNoContent)
+
+	req = NewRequestf(t, "GET", "/api/v1/repos/%s/%s/releases/%d/assets?token=%s", owner.Name, repo.Name, release.ID, token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	var attachments []*api.Attachment
+	DecodeJSON(t, resp, &attachments)
+	if assert.Len(t, attachments, 1) {
+		assert.Equal(t, "notes.txt", attachments[0].Name)
+		assert.EqualValues(t, len(content), attachments[0].Size)
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAPIUserIssueFilters(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	session := loginUser(t, "user2")
+	token := getTokenForLoggedInUser(t, session)
+
+	req := NewRequestWithJSON(t, "POST", "/api/v1/user/filters?token="+token, &api.CreateIssueFilterOption{
+		Name:   "my bugs",
+		RepoID: 1,
+		Type:   "assigned",
+		State:  "open",
+		Labels: []int64{1, -2},
+	})
+	resp := session.MakeRequest(t, req, http.StatusCreated)
+	var created api.IssueFilter
+	DecodeJSON(t, resp, &created)
+	assert.EqualValues(t, "my bugs", created.Name)
+	assert.EqualValues(t, []int64{1, -2}, created.Labels)
+	assert.EqualValues(t, "labels=1%2C-2&state=open&type=assigned", created.Query)
+	unittest.AssertExistsAndLoadBean(t, &issues_model.IssueFilter{ID: created.ID, UserID: 2})
+
+	// names are unique per user
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/filters?token="+token, &api.CreateIssueFilterOption{Name: "my bugs"})
+	session.MakeRequest(t, req, http.StatusConflict)
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/user/filters?token="+token, &api.CreateIssueFilterOption{Name: "bad", State: "unknown"})
+	session.MakeRequest(t, req, http.StatusUnprocessableEntity)
+
+	req = NewRequestf(t, "GET", "/api/v1/user/filters?repo_id=1&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var filters []*api.IssueFilter
+	DecodeJSON(t, resp, &filters)
+	assert.Len(t, filters, 1)
+
+	req = NewRequestf(t, "GET", "/api/v1/user/filters?repo_id=2&token=%s", token)
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &filters)
+	assert.Len(t, filters, 0)
+
+	keyword := "crash"
+	req = NewRequestWithJSON(t, "PATCH", fmt.Sprintf("/api/v1/user/filters/%d?token=%s", created.ID, token), &api.EditIssueFilterOption{Keyword: &keyword})
+	resp = session.MakeRequest(t, req, http.StatusOK)
+	var edited api.IssueFilter
+	DecodeJSON(t, resp, &edited)
+	assert.EqualValues(t, "crash", edited.Keyword)
+	assert.EqualValues(t, "assigned", edited.Type)
+
+	// other users can not see the filter
+	otherToken := getTokenForLoggedInUser(t, loginUser(t, "user4"))
+	req = NewRequestf(t, "GET", "/api/v1/user/filters/%d?token=%s", created.ID, otherToken)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequestf(t, "DELETE", "/api/v1/user/filters/%d?token=%s", created.ID, token)
+	session.MakeRequest(t, req, http.StatusNoContent)
+	unittest.AssertNotExistsBean(t, &issues_model.IssueFilter{ID: created.ID})
+}
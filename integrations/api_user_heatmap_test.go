@@ -11,6 +11,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
@@ -36,3 +37,26 @@ func TestUserHeatmap(t *testing.T) {
 
 	assert.Equal(t, dummyheatmap, heatmap)
 }
+
+func TestUserContributions(t *testing.T) {
+	defer prepareTestEnv(t)()
+	token := getUserToken(t, "user1")
+
+	fakeNow := time.Date(2011, 10, 20, 0, 0, 0, 0, time.Local)
+	timeutil.Set(fakeNow)
+	defer timeutil.Unset()
+
+	req := NewRequest(t, "GET", "/api/v1/users/user2/contributions?token="+token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	var days []*api.UserContributionDay
+	DecodeJSON(t, resp, &days)
+	assert.EqualValues(t, []*api.UserContributionDay{{Date: "2020-10-20", Contributions: 1}}, days)
+
+	req = NewRequest(t, "GET", "/api/v1/users/user2/contributions?timezone=Asia/Tokyo&token="+token)
+	resp = MakeRequest(t, req, http.StatusOK)
+	DecodeJSON(t, resp, &days)
+	assert.EqualValues(t, []*api.UserContributionDay{{Date: "2020-10-21", Contributions: 1}}, days)
+
+	req = NewRequest(t, "GET", "/api/v1/users/user2/contributions?timezone=Nowhere/Invalid&token="+token)
+	MakeRequest(t, req, http.StatusUnprocessableEntity)
+}
@@ -222,7 +222,7 @@ func TestLDAPUserSync(t *testing.T) {
 	}
 	defer prepareTestEnv(t)()
 	addAuthSourceLDAP(t, "")
-	auth.SyncExternalUsers(context.Background(), true)
+	auth.SyncExternalUsers(context.Background(), true, 0)
 
 	session := loginUser(t, "user1")
 	// Check if users exists
@@ -287,7 +287,7 @@ func TestLDAPUserSSHKeySync(t *testing.T) {
 	defer prepareTestEnv(t)()
 	addAuthSourceLDAP(t, "sshPublicKey")
 
-	auth.SyncExternalUsers(context.Background(), true)
+	auth.SyncExternalUsers(context.Background(), true, 0)
 
 	// Check if users has SSH keys synced
 	for _, u := range gitLDAPUsers {
@@ -323,7 +323,7 @@ func TestLDAPGroupTeamSyncAddMember(t *testing.T) {
 	assert.NoError(t, err)
 	team, err := organization.GetTeam(db.DefaultContext, org.ID, "team11")
 	assert.NoError(t, err)
-	auth.SyncExternalUsers(context.Background(), true)
+	auth.SyncExternalUsers(context.Background(), true, 0)
 	for _, gitLDAPUser := range gitLDAPUsers {
 		user := unittest.AssertExistsAndLoadBean(t, &user_model.User{
 			Name: gitLDAPUser.UserName,
@@ -8,6 +8,9 @@ import (
 	"net/http"
 	"testing"
 
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/stretchr/testify/assert"
@@ -91,3 +94,31 @@ func TestDownloadRawTextFileWithMimeTypeMapping(t *testing.T) {
 	delete(setting.MimeTypeMap.Map, ".xml")
 	setting.MimeTypeMap.Enabled = false
 }
+
+func TestDownloadWithDownloadToken(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	token := &auth_model.DownloadToken{UID: 2, Name: "deploy", Scopes: "user2/repo1:README.md\nuser2/repo2"}
+	assert.NoError(t, auth_model.NewDownloadToken(db.DefaultContext, token))
+
+	req := NewRequest(t, "GET", "/user2/repo1/raw/branch/master/README.md?download_token="+token.Token)
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "# repo1\n\nDescription for repo1", resp.Body.String())
+
+	// outside of the scopes
+	req = NewRequest(t, "GET", "/user2/repo1/raw/blob/4b4851ad51df6a7d9f25c979345979eaeb5b349f?download_token="+token.Token)
+	MakeRequest(t, req, http.StatusNotFound)
+	req = NewRequest(t, "GET", "/user2/repo16/raw/branch/master/README.md?download_token="+token.Token)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	// the token doesn't authenticate anything else
+	req = NewRequest(t, "GET", "/user2/repo2/issues?download_token="+token.Token)
+	MakeRequest(t, req, http.StatusNotFound)
+
+	req = NewRequest(t, "GET", "/user2/repo2/raw/blob/6395b68e1feebb1e4c657b4f9f6ba2676a283c0b")
+	req.Header.Set("Authorization", "token "+token.Token)
+	MakeRequest(t, req, http.StatusOK)
+
+	token = unittest.AssertExistsAndLoadBean(t, &auth_model.DownloadToken{ID: token.ID}).(*auth_model.DownloadToken)
+	assert.EqualValues(t, 2, token.NumDownloads)
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/maintenance"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	defer prepareTestEnv(t)()
+	// the maintenance mode is cached, make sure that it doesn't leak into other tests
+	defer func() {
+		assert.NoError(t, maintenance.Disable(db.DefaultContext))
+	}()
+
+	adminSession := loginUser(t, "user1")
+	req := NewRequestWithValues(t, "POST", "/admin/maintenance-mode", map[string]string{
+		"_csrf":   GetCSRF(t, adminSession, "/admin/maintenance-mode"),
+		"action":  "enable",
+		"message": "Back at noon",
+	})
+	adminSession.MakeRequest(t, req, http.StatusSeeOther)
+	unittest.AssertExistsAndLoadBean(t, &admin_model.MaintenanceMode{Message: "Back at noon"})
+
+	// users can look around but not change anything
+	session := loginUser(t, "user2")
+	req = NewRequest(t, "GET", "/user2/repo1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "Back at noon")
+
+	req = NewRequestWithValues(t, "POST", "/user2/repo1/issues/new", map[string]string{
+		"_csrf": GetCSRF(t, session, "/user2/repo1/issues/new"),
+		"title": "issue",
+	})
+	session.MakeRequest(t, req, http.StatusServiceUnavailable)
+
+	token := getTokenForLoggedInUser(t, adminSession)
+	userToken := getUserToken(t, "user2")
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues?token="+userToken, &api.CreateIssueOption{Title: "issue"})
+	resp = MakeRequest(t, req, http.StatusServiceUnavailable)
+	assert.Contains(t, resp.Body.String(), "Back at noon")
+
+	req = NewRequest(t, "GET", "/api/v1/repos/user2/repo1/issues?token="+userToken)
+	MakeRequest(t, req, http.StatusOK)
+
+	req = NewRequest(t, "GET", "/user2/repo1.git/info/refs?service=git-receive-pack")
+	req.SetBasicAuth("user2", userPassword)
+	MakeRequest(t, req, http.StatusServiceUnavailable)
+
+	// site admins can still change everything
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues?token="+token, &api.CreateIssueOption{Title: "issue"})
+	MakeRequest(t, req, http.StatusCreated)
+
+	req = NewRequestWithValues(t, "POST", "/admin/maintenance-mode", map[string]string{
+		"_csrf":  GetCSRF(t, adminSession, "/admin/maintenance-mode"),
+		"action": "disable",
+	})
+	adminSession.MakeRequest(t, req, http.StatusSeeOther)
+	unittest.AssertNotExistsBean(t, &admin_model.MaintenanceMode{})
+
+	req = NewRequestWithJSON(t, "POST", "/api/v1/repos/user2/repo1/issues?token="+userToken, &api.CreateIssueOption{Title: "issue"})
+	MakeRequest(t, req, http.StatusCreated)
+}
@@ -73,7 +73,7 @@ func TestMirrorPull(t *testing.T) {
 		IsDraft:      false,
 		IsPrerelease: false,
 		IsTag:        true,
-	}, nil, ""))
+	}, nil, "", false))
 
 	_, err = repo_model.GetMirrorByRepoID(ctx, mirror.ID)
 	assert.NoError(t, err)
@@ -85,6 +85,11 @@ func TestMirrorPull(t *testing.T) {
 	assert.NoError(t, err)
 	assert.EqualValues(t, initCount+1, count)
 
+	// the counters of the mirror follow the rebuilt releases
+	mirrorRepo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: mirror.ID}).(*repo_model.Repository)
+	assert.EqualValues(t, count, mirrorRepo.NumTags)
+	assert.EqualValues(t, 0, mirrorRepo.NumReleases)
+
 	release, err := models.GetRelease(repo.ID, "v0.2")
 	assert.NoError(t, err)
 	assert.NoError(t, release_service.DeleteReleaseByID(ctx, release.ID, user, true))
@@ -95,4 +100,7 @@ func TestMirrorPull(t *testing.T) {
 	count, err = models.GetReleaseCountByRepoID(mirror.ID, findOptions)
 	assert.NoError(t, err)
 	assert.EqualValues(t, initCount, count)
+
+	mirrorRepo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: mirror.ID}).(*repo_model.Repository)
+	assert.EqualValues(t, count, mirrorRepo.NumTags)
 }
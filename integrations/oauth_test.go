@@ -258,3 +258,89 @@ func TestRefreshTokenInvalidation(t *testing.T) {
 	refreshReq.Body = io.NopCloser(bytes.NewReader(bs))
 	MakeRequest(t, refreshReq, http.StatusBadRequest)
 }
+
+func TestDeviceAuthorizationGrant(t *testing.T) {
+	defer prepareTestEnv(t)()
+	defer func(interval int64) {
+		setting.OAuth2.DevicePollingInterval = interval
+	}(setting.OAuth2.DevicePollingInterval)
+	setting.OAuth2.DevicePollingInterval = 0
+
+	type deviceResponse struct {
+		DeviceCode      string `json:"device_code"`
+		UserCode        string `json:"user_code"`
+		VerificationURI string `json:"verification_uri"`
+	}
+	requestDevice := func(t *testing.T) *deviceResponse {
+		req := NewRequestWithValues(t, "POST", "/login/oauth/device_authorization", map[string]string{
+			"client_id": "da7da3ba-9a13-4167-856f-3899de0b0138",
+		})
+		resp := MakeRequest(t, req, http.StatusOK)
+		device := new(deviceResponse)
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), device))
+		assert.Equal(t, setting.AppURL+"login/oauth/device", device.VerificationURI)
+		return device
+	}
+	pollToken := func(t *testing.T, deviceCode string, expectedStatus int) map[string]interface{} {
+		req := NewRequestWithValues(t, "POST", "/login/oauth/access_token", map[string]string{
+			"grant_type":  "urn:ietf:params:oauth:grant-type:device_code",
+			"client_id":   "da7da3ba-9a13-4167-856f-3899de0b0138",
+			"device_code": deviceCode,
+		})
+		resp := MakeRequest(t, req, expectedStatus)
+		parsed := make(map[string]interface{})
+		assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &parsed))
+		return parsed
+	}
+	grantDevice := func(t *testing.T, userCode, granted string) {
+		session := loginUser(t, "user2")
+		req := NewRequest(t, "GET", "/login/oauth/device?user_code="+userCode)
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		htmlDoc := NewHTMLParser(t, resp.Body)
+		htmlDoc.AssertElement(t, "#authorize-device", true)
+
+		req = NewRequestWithValues(t, "POST", "/login/oauth/device", map[string]string{
+			"_csrf":     htmlDoc.GetCSRF(),
+			"user_code": userCode,
+			"granted":   granted,
+		})
+		session.MakeRequest(t, req, http.StatusOK)
+	}
+
+	t.Run("Granted", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		device := requestDevice(t)
+		assert.Equal(t, "authorization_pending", pollToken(t, device.DeviceCode, http.StatusBadRequest)["error"])
+
+		grantDevice(t, device.UserCode, "true")
+		token := pollToken(t, device.DeviceCode, http.StatusOK)
+		assert.NotEmpty(t, token["access_token"])
+		assert.NotEmpty(t, token["refresh_token"])
+
+		// the device code can only be used once
+		assert.Equal(t, "invalid_grant", pollToken(t, device.DeviceCode, http.StatusBadRequest)["error"])
+	})
+
+	t.Run("Denied", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		device := requestDevice(t)
+		grantDevice(t, device.UserCode, "false")
+		assert.Equal(t, "access_denied", pollToken(t, device.DeviceCode, http.StatusBadRequest)["error"])
+	})
+
+	t.Run("InvalidUserCode", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		session := loginUser(t, "user2")
+		req := NewRequest(t, "GET", "/login/oauth/device?user_code=BCDF-GHJK")
+		resp := session.MakeRequest(t, req, http.StatusOK)
+		NewHTMLParser(t, resp.Body).AssertElement(t, "#authorize-device", false)
+	})
+
+	t.Run("SlowDown", func(t *testing.T) {
+		defer PrintCurrentTest(t)()
+		setting.OAuth2.DevicePollingInterval = 60
+		device := requestDevice(t)
+		assert.Equal(t, "authorization_pending", pollToken(t, device.DeviceCode, http.StatusBadRequest)["error"])
+		assert.Equal(t, "slow_down", pollToken(t, device.DeviceCode, http.StatusBadRequest)["error"])
+	})
+}
@@ -11,6 +11,7 @@ import (
 	"time"
 
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/test"
@@ -210,3 +211,19 @@ func TestViewTagsList(t *testing.T) {
 
 	assert.EqualValues(t, []string{"v1.0", "delete-tag", "v1.1"}, tagNames)
 }
+
+func TestDownloadReleaseAssetsWithoutReleaseAccess(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	link := "/user2/repo1/releases/download-all/v1.1"
+	resp := MakeRequest(t, NewRequest(t, "GET", link), http.StatusOK)
+	assert.Equal(t, "application/zip", resp.Header().Get("Content-Type"))
+
+	// the assets are not served once the releases unit is disabled, not even to the owner
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	assert.NoError(t, repo_model.UpdateRepositoryUnits(repo, nil, []unit.Type{unit.TypeReleases}))
+
+	MakeRequest(t, NewRequest(t, "GET", link), http.StatusNotFound)
+	session := loginUser(t, "user2")
+	session.MakeRequest(t, NewRequest(t, "GET", link), http.StatusNotFound)
+}
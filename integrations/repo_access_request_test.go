@@ -0,0 +1,66 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoAccessRequest(t *testing.T) {
+	defer prepareTestEnv(t)()
+	setting.Repository.EnableAccessRequests = true
+	defer func() {
+		setting.Repository.EnableAccessRequests = false
+	}()
+
+	session := loginUser(t, "user4")
+
+	notFoundPage := func(url string) *HTMLDoc {
+		req := NewRequest(t, "GET", url)
+		req.Header.Set("Accept", "text/html")
+		resp := session.MakeRequest(t, req, http.StatusNotFound)
+		return NewHTMLParser(t, resp.Body)
+	}
+
+	// the private repository and a missing one offer the same form
+	doc := notFoundPage("/user2/repo2")
+	assert.EqualValues(t, 1, doc.Find(`form[action$="/repo/request_access"]`).Length())
+	csrf := doc.GetCSRF()
+	assert.EqualValues(t, 1, notFoundPage("/user2/not-existing").Find(`form[action$="/repo/request_access"]`).Length())
+
+	for _, repo := range []string{"repo2", "not-existing"} {
+		req := NewRequestWithValues(t, "POST", "/repo/request_access", map[string]string{
+			"_csrf":   csrf,
+			"owner":   "user2",
+			"repo":    repo,
+			"message": "please",
+		})
+		session.MakeRequest(t, req, http.StatusSeeOther)
+	}
+	req := unittest.AssertExistsAndLoadBean(t, &repo_model.AccessRequest{RepoID: 2, UserID: 4}).(*repo_model.AccessRequest)
+	assert.Equal(t, "please", req.Message)
+
+	adminSession := loginUser(t, "user2")
+	resp := adminSession.MakeRequest(t, NewRequest(t, "GET", "/user2/repo2/settings/access_requests"), http.StatusOK)
+	doc = NewHTMLParser(t, resp.Body)
+	approveURL := fmt.Sprintf("/user2/repo2/settings/access_requests/%d/approve", req.ID)
+	assert.EqualValues(t, 1, doc.Find(fmt.Sprintf(`form[action="%s"]`, approveURL)).Length())
+
+	adminSession.MakeRequest(t, NewRequestWithValues(t, "POST", approveURL, map[string]string{
+		"_csrf": doc.GetCSRF(),
+		"mode":  "1",
+	}), http.StatusSeeOther)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.AccessRequest{ID: req.ID, Status: repo_model.AccessRequestStatusApproved})
+
+	session.MakeRequest(t, NewRequest(t, "GET", "/user2/repo2"), http.StatusOK)
+}
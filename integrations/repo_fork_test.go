@@ -73,3 +73,15 @@ func TestRepoForkToOrg(t *testing.T) {
 	_, exists := htmlDoc.doc.Find("a.ui.button[href^=\"/repo/fork/\"]").Attr("href")
 	assert.False(t, exists, "Forking should not be allowed anymore")
 }
+
+func TestRepoForkToSameOwner(t *testing.T) {
+	defer prepareTestEnv(t)()
+	session := loginUser(t, "user2")
+	testRepoFork(t, session, "user2", "repo1", "user2", "repo1-fork")
+
+	// the next fork is suggested a numbered suffix
+	req := NewRequest(t, "GET", "/repo/fork/1")
+	resp := session.MakeRequest(t, req, http.StatusOK)
+	htmlDoc := NewHTMLParser(t, resp.Body)
+	assert.EqualValues(t, "repo1-fork-2", htmlDoc.GetInputValueByName("repo_name"))
+}
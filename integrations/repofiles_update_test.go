@@ -61,6 +61,7 @@ func getExpectedFileResponseForRepofilesCreate(commitID string) *api.FileRespons
 			Path:        treePath,
 			SHA:         "103ff9234cefeee5ec5361d22b49fbb04d385885",
 			Type:        "file",
+			Mode:        "100644",
 			Size:        18,
 			Encoding:    &encoding,
 			Content:     &content,
@@ -128,6 +129,7 @@ func getExpectedFileResponseForRepofilesUpdate(commitID, filename string) *api.F
 			Path:        filename,
 			SHA:         "dbf8d00e022e05b7e5cf7e535de857de57925647",
 			Type:        "file",
+			Mode:        "100644",
 			Size:        43,
 			Encoding:    &encoding,
 			Content:     &content,
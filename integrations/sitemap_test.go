@@ -0,0 +1,58 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package integrations
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSitemap(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/sitemap.xml")
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "<sitemapindex")
+	assert.Contains(t, resp.Body.String(), "<loc>"+setting.AppURL+"explore/repos/sitemap-1.xml</loc>")
+	assert.Contains(t, resp.Body.String(), "<loc>"+setting.AppURL+"explore/users/sitemap-1.xml</loc>")
+
+	req = NewRequest(t, "GET", "/explore/repos/sitemap-1.xml")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Contains(t, resp.Body.String(), "<loc>"+setting.AppURL+"user2/repo1</loc>")
+	assert.Contains(t, resp.Body.String(), "<loc>"+setting.AppURL+"user2/repo1/releases/tag/v1.1</loc>")
+	assert.NotContains(t, resp.Body.String(), "releases/tag/draft-release</loc>")
+	assert.NotContains(t, resp.Body.String(), "user2/repo2</loc>")
+
+	req = NewRequest(t, "GET", "/explore/repos/sitemap-0.xml")
+	MakeRequest(t, req, http.StatusNotFound)
+}
+
+func TestSitemapNoIndex(t *testing.T) {
+	defer prepareTestEnv(t)()
+
+	req := NewRequest(t, "GET", "/user2/repo1")
+	resp := MakeRequest(t, req, http.StatusOK)
+	assert.Empty(t, resp.Header().Get("X-Robots-Tag"))
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	repo.NoIndex = true
+	assert.NoError(t, repo_model.UpdateRepositoryCols(db.DefaultContext, repo, "no_index"))
+
+	req = NewRequest(t, "GET", "/user2/repo1")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.Equal(t, "noindex", resp.Header().Get("X-Robots-Tag"))
+	assert.Contains(t, resp.Body.String(), `<meta name="robots" content="noindex">`)
+
+	req = NewRequest(t, "GET", "/explore/repos/sitemap-1.xml")
+	resp = MakeRequest(t, req, http.StatusOK)
+	assert.NotContains(t, resp.Body.String(), "user2/repo1</loc>")
+}
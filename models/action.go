@@ -16,6 +16,7 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
+	perm_model "code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
@@ -62,6 +63,8 @@ const (
 	ActionPublishRelease                                  // 24
 	ActionPullReviewDismissed                             // 25
 	ActionPullRequestReadyForReview                       // 26
+	ActionPublicizeRepo                                   // 27
+	ActionPrivatizeRepo                                   // 28
 )
 
 // Action represents user operation type and other information to
@@ -88,6 +91,13 @@ func init() {
 	db.RegisterModel(new(Action))
 }
 
+// BeforeInsert will be invoked by XORM before inserting a record
+func (a *Action) BeforeInsert() {
+	if a.ID == 0 {
+		a.ID = db.NextID()
+	}
+}
+
 // GetOpType gets the ActionType of this action.
 func (a *Action) GetOpType() ActionType {
 	return a.OpType
@@ -440,13 +450,218 @@ func activityQueryCondition(opts GetFeedsOptions) (builder.Cond, error) {
 }
 
 // DeleteOldActions deletes all old actions from database.
-func DeleteOldActions(olderThan time.Duration) (err error) {
+// To keep locks short on big tables the deletion is done in batches of at most batchSize actions, a batchSize of 0 deletes everything at once.
+func DeleteOldActions(ctx context.Context, olderThan time.Duration, batchSize int) (err error) {
 	if olderThan <= 0 {
 		return nil
 	}
+	cutoff := time.Now().Add(-olderThan).Unix()
+
+	if batchSize <= 0 {
+		_, err = db.GetEngine(ctx).Where("created_unix < ?", cutoff).Delete(&Action{})
+		return err
+	}
+
+	var lastID int64
+	for {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before deleting actions with id > %d", lastID)
+		default:
+		}
+
+		ids := make([]int64, 0, batchSize)
+		if err = db.GetEngine(ctx).Table("action").
+			Where("id > ? AND created_unix < ?", lastID, cutoff).
+			OrderBy("id").
+			Limit(batchSize).
+			Cols("id").
+			Find(&ids); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return nil
+		}
+		if _, err = db.GetEngine(ctx).In("id", ids).Delete(&Action{}); err != nil {
+			return err
+		}
+		if len(ids) < batchSize {
+			return nil
+		}
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// PruneFeeds deletes the oldest actions of every feed holding more than maxEntries actions done by others.
+// The actions done by the owner of the feed are kept as they are the origin of the fanned out actions and make up the contribution heatmap.
+func PruneFeeds(ctx context.Context, maxEntries int) error {
+	if maxEntries <= 0 {
+		return nil
+	}
+
+	type feedCount struct {
+		UserID int64
+		Count  int64
+	}
+	feeds := make([]*feedCount, 0, 10)
+	if err := db.GetEngine(ctx).Table("action").
+		Select("user_id, COUNT(*) AS count").
+		Where("user_id <> act_user_id").
+		GroupBy("user_id").
+		Having(fmt.Sprintf("COUNT(*) > %d", maxEntries)).
+		Find(&feeds); err != nil {
+		return err
+	}
+
+	for _, feed := range feeds {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before pruning the feed of user %d", feed.UserID)
+		default:
+		}
+
+		// find the id of the newest action which is not kept any more
+		ids := make([]int64, 0, 1)
+		if err := db.GetEngine(ctx).Table("action").
+			Cols("id").
+			Where("user_id = ? AND act_user_id <> ?", feed.UserID, feed.UserID).
+			OrderBy("id DESC").
+			Limit(1, maxEntries).
+			Find(&ids); err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			continue
+		}
+		if _, err := db.GetEngine(ctx).
+			Where("user_id = ? AND act_user_id <> ? AND id <= ?", feed.UserID, feed.UserID, ids[0]).
+			Delete(&Action{}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// regenerateFeedBatchSize is the number of actions restored per transaction by RegenerateFeed
+const regenerateFeedBatchSize = 1000
+
+// RegenerateFeed rebuilds the feed of the given user or organization from the actions of its members and watched repositories.
+// Only actions not older than olderThan are restored, an olderThan of 0 restores everything.
+// To keep transactions short on big tables the actions are restored in batches, so the feed is incomplete until it returns.
+func RegenerateFeed(ctx context.Context, u *user_model.User, olderThan time.Duration) error {
+	e := db.GetEngine(ctx)
+
+	var repoIDs []int64
+	if u.IsOrganization() {
+		if err := e.Table("repository").Cols("id").Where("owner_id = ?", u.ID).Find(&repoIDs); err != nil {
+			return err
+		}
+	} else {
+		if err := e.Table("watch").Cols("repo_id").
+			Where("user_id = ? AND mode <> ?", u.ID, repo_model.WatchModeDont).
+			Find(&repoIDs); err != nil {
+			return err
+		}
+	}
 
-	_, err = db.GetEngine(db.DefaultContext).Where("created_unix < ?", time.Now().Add(-olderThan).Unix()).Delete(&Action{})
-	return
+	// Drop everything which was fanned out to the feed, the actions done by the user themselves stay as they are the origin.
+	if _, err := e.Where("user_id = ? AND act_user_id <> ?", u.ID, u.ID).Delete(&Action{}); err != nil {
+		return err
+	}
+
+	for _, repoID := range repoIDs {
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		perm := access_model.Permission{AccessMode: perm_model.AccessModeOwner}
+		if !u.IsOrganization() {
+			if perm, err = access_model.GetUserRepoPermission(ctx, repo, u); err != nil {
+				return err
+			}
+			if !perm.HasAccess() {
+				continue
+			}
+		}
+
+		cond := builder.NewCond().
+			And(builder.Eq{"repo_id": repoID}).
+			And(builder.Expr("user_id = act_user_id")).
+			And(builder.Neq{"act_user_id": u.ID})
+		if olderThan > 0 {
+			cond = cond.And(builder.Gte{"created_unix": time.Now().Add(-olderThan).Unix()})
+		}
+
+		var lastID int64
+		for {
+			select {
+			case <-ctx.Done():
+				return db.ErrCancelledf("before restoring actions of repo %d with id > %d", repoID, lastID)
+			default:
+			}
+
+			var count int
+			if err := db.WithTx(func(ctx context.Context) error {
+				var err error
+				count, lastID, err = restoreFeedActions(ctx, u, perm, cond, lastID)
+				return err
+			}, ctx); err != nil {
+				return err
+			}
+			if count < regenerateFeedBatchSize {
+				break
+			}
+		}
+	}
+
+	return nil
+}
+
+// restoreFeedActions copies the next batch of actions matching cond after lastID to the feed of u.
+// It returns the number of actions read and the id of the last one.
+func restoreFeedActions(ctx context.Context, u *user_model.User, perm access_model.Permission, cond builder.Cond, lastID int64) (int, int64, error) {
+	e := db.GetEngine(ctx)
+
+	actions := make([]*Action, 0, regenerateFeedBatchSize)
+	if err := e.Where(cond).And("id > ?", lastID).
+		OrderBy("id").
+		Limit(regenerateFeedBatchSize).
+		Find(&actions); err != nil {
+		return 0, lastID, err
+	}
+	if len(actions) == 0 {
+		return 0, lastID, nil
+	}
+	lastID = actions[len(actions)-1].ID
+
+	for _, act := range actions {
+		if !u.IsOrganization() && !isActionVisible(act.OpType, perm.CanRead(unit.TypeCode), perm.CanRead(unit.TypeIssues), perm.CanRead(unit.TypePullRequests)) {
+			continue
+		}
+		act.ID = 0
+		act.UserID = u.ID
+		if _, err := e.InsertOne(act); err != nil {
+			return 0, lastID, err
+		}
+	}
+	return len(actions), lastID, nil
+}
+
+// isActionVisible returns whether a watcher with the given read permissions may see an action of the given type
+func isActionVisible(opType ActionType, canReadCode, canReadIssues, canReadPulls bool) bool {
+	switch opType {
+	case ActionCommitRepo, ActionPushTag, ActionDeleteTag, ActionPublishRelease, ActionDeleteBranch:
+		return canReadCode
+	case ActionCreateIssue, ActionCommentIssue, ActionCloseIssue, ActionReopenIssue:
+		return canReadIssues
+	case ActionCreatePullRequest, ActionCommentPull, ActionMergePullRequest, ActionClosePullRequest, ActionReopenPullRequest:
+		return canReadPulls
+	}
+	return true
 }
 
 func notifyWatchers(ctx context.Context, actions ...*Action) error {
@@ -530,19 +745,8 @@ func notifyWatchers(ctx context.Context, actions ...*Action) error {
 			act.UserID = watcher.UserID
 			act.Repo.Units = nil
 
-			switch act.OpType {
-			case ActionCommitRepo, ActionPushTag, ActionDeleteTag, ActionPublishRelease, ActionDeleteBranch:
-				if !permCode[i] {
-					continue
-				}
-			case ActionCreateIssue, ActionCommentIssue, ActionCloseIssue, ActionReopenIssue:
-				if !permIssue[i] {
-					continue
-				}
-			case ActionCreatePullRequest, ActionCommentPull, ActionMergePullRequest, ActionClosePullRequest, ActionReopenPullRequest:
-				if !permPR[i] {
-					continue
-				}
+			if !isActionVisible(act.OpType, permCode[i], permIssue[i], permPR[i]) {
+				continue
 			}
 
 			if _, err = e.InsertOne(act); err != nil {
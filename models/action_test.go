@@ -7,6 +7,7 @@ package models
 import (
 	"path"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -228,3 +229,53 @@ func TestGetFeedsCorrupted(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Len(t, actions, 0)
 }
+
+func TestDeleteOldActions(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// recent actions are kept, whichever batch they fall in
+	recent := &Action{UserID: 2, ActUserID: 2, OpType: ActionCreateRepo, RepoID: 1}
+	assert.NoError(t, db.Insert(db.DefaultContext, recent))
+
+	assert.NoError(t, DeleteOldActions(db.DefaultContext, time.Hour, 2))
+	unittest.AssertNotExistsBean(t, &Action{ID: 1})
+	unittest.AssertNotExistsBean(t, &Action{ID: 4})
+	assert.Zero(t, unittest.GetCount(t, &Action{UserID: 10}))
+	unittest.AssertExistsAndLoadBean(t, &Action{ID: recent.ID})
+}
+
+func TestPruneFeeds(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	assert.EqualValues(t, 3, unittest.GetCount(t, &Action{UserID: 10}))
+	for i := 0; i < 2; i++ {
+		assert.NoError(t, db.Insert(db.DefaultContext, &Action{UserID: 10, ActUserID: 2, OpType: ActionCreateIssue, RepoID: 1}))
+	}
+
+	// the actions done by the owner of the feed are not counted and kept
+	assert.NoError(t, PruneFeeds(db.DefaultContext, 1))
+	assert.EqualValues(t, 3, unittest.GetCount(t, &Action{UserID: 10, ActUserID: 10}))
+	assert.EqualValues(t, 1, unittest.GetCount(t, &Action{UserID: 10, ActUserID: 2}))
+}
+
+func TestRegenerateFeed(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// feeds of watchers are rebuilt from the origin action of the doer
+	assert.NoError(t, NotifyWatchers(&Action{ActUserID: 8, RepoID: 1, OpType: ActionStarRepo}))
+	_, err := db.GetEngine(db.DefaultContext).Delete(&Action{UserID: 4, ActUserID: 8})
+	assert.NoError(t, err)
+
+	user4 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	assert.NoError(t, RegenerateFeed(db.DefaultContext, user4, 0))
+	unittest.AssertExistsAndLoadBean(t, &Action{UserID: 4, ActUserID: 8, RepoID: 1, OpType: ActionStarRepo})
+
+	// feeds of organizations only contain actions with an origin in the organization's repositories
+	org3 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3}).(*user_model.User)
+	unittest.AssertExistsAndLoadBean(t, &Action{ID: 2, UserID: 3})
+	assert.NoError(t, RegenerateFeed(db.DefaultContext, org3, 0))
+	unittest.AssertNotExistsBean(t, &Action{ID: 2})
+
+	assert.NoError(t, db.Insert(db.DefaultContext, &Action{UserID: 2, ActUserID: 2, RepoID: 3, OpType: ActionCreateIssue}))
+	assert.NoError(t, RegenerateFeed(db.DefaultContext, org3, time.Hour))
+	unittest.AssertExistsAndLoadBean(t, &Action{UserID: 3, ActUserID: 2, RepoID: 3, OpType: ActionCreateIssue})
+}
@@ -0,0 +1,68 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrArtifactNotExist represents a "ArtifactNotExist" kind of error.
+type ErrArtifactNotExist struct {
+	ID int64
+}
+
+// IsErrArtifactNotExist checks if an error is a ErrArtifactNotExist.
+func IsErrArtifactNotExist(err error) bool {
+	_, ok := err.(ErrArtifactNotExist)
+	return ok
+}
+
+func (err ErrArtifactNotExist) Error() string {
+	return fmt.Sprintf("artifact does not exist [id: %d]", err.ID)
+}
+
+// ActionArtifact is a file uploaded by a job
+type ActionArtifact struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RunID       int64  `xorm:"INDEX"`
+	JobID       int64  `xorm:"INDEX"`
+	RepoID      int64  `xorm:"INDEX"`
+	Name        string `xorm:"VARCHAR(255)"`
+	StoragePath string
+	FileSize    int64
+
+	Created timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionArtifact))
+}
+
+// InsertArtifact saves an uploaded artifact
+func InsertArtifact(ctx context.Context, artifact *ActionArtifact) error {
+	return db.Insert(ctx, artifact)
+}
+
+// GetArtifactByID returns an artifact by id
+func GetArtifactByID(ctx context.Context, id int64) (*ActionArtifact, error) {
+	artifact := new(ActionArtifact)
+	has, err := db.GetEngine(ctx).ID(id).Get(artifact)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrArtifactNotExist{ID: id}
+	}
+	return artifact, nil
+}
+
+// GetRunArtifacts returns the artifacts of a run
+func GetRunArtifacts(ctx context.Context, runID int64) ([]*ActionArtifact, error) {
+	artifacts := make([]*ActionArtifact, 0, 5)
+	return artifacts, db.GetEngine(ctx).Where("run_id = ?", runID).OrderBy("name ASC").Find(&artifacts)
+}
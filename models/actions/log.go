@@ -0,0 +1,87 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ActionJobLog is a chunk of log lines of a job, as sent by the runner
+type ActionJobLog struct {
+	ID        int64  `xorm:"pk autoincr"`
+	JobID     int64  `xorm:"INDEX"`
+	RepoID    int64  `xorm:"INDEX"`
+	Offset    int64  `xorm:"NOT NULL DEFAULT 0"` // the number of the first line of the chunk
+	LineCount int64  `xorm:"NOT NULL DEFAULT 0"`
+	Content   string `xorm:"LONGTEXT"`
+
+	Created timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionJobLog))
+}
+
+// AppendJobLogs appends log lines to the log of a job, lines containing line breaks are split.
+// Appending no lines only records that the runner is still running the job.
+func AppendJobLogs(ctx context.Context, job *ActionRunJob, lines []string) error {
+	if len(lines) == 0 {
+		_, err := db.GetEngine(ctx).ID(job.ID).Cols("updated").Update(&ActionRunJob{})
+		return err
+	}
+	content := strings.Join(lines, "\n")
+	count := int64(strings.Count(content, "\n") + 1)
+
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	// reload the length, the runner may send chunks concurrently
+	current, err := GetJobByID(ctx, job.ID)
+	if err != nil {
+		return err
+	}
+	if err := db.Insert(ctx, &ActionJobLog{
+		JobID:     job.ID,
+		RepoID:    job.RepoID,
+		Offset:    current.LogLength,
+		LineCount: count,
+		Content:   content,
+	}); err != nil {
+		return err
+	}
+	job.LogLength = current.LogLength + count
+	if _, err := db.GetEngine(ctx).ID(job.ID).Cols("log_length").Update(job); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// ReadJobLogs returns the log lines of a job starting at the given line
+func ReadJobLogs(ctx context.Context, jobID, offset int64) ([]string, error) {
+	chunks := make([]*ActionJobLog, 0, 10)
+	if err := db.GetEngine(ctx).
+		Where("job_id = ? AND `offset` + line_count > ?", jobID, offset).
+		OrderBy("`offset` ASC").
+		Find(&chunks); err != nil {
+		return nil, err
+	}
+
+	lines := make([]string, 0, 100)
+	for _, chunk := range chunks {
+		chunkLines := strings.Split(chunk.Content, "\n")
+		if skip := offset - chunk.Offset; skip > 0 {
+			chunkLines = chunkLines[skip:]
+		}
+		lines = append(lines, chunkLines...)
+	}
+	return lines, nil
+}
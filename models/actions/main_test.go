@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"path/filepath"
+	"testing"
+
+	"code.gitea.io/gitea/models/unittest"
+)
+
+func TestMain(m *testing.M) {
+	unittest.MainTest(m, &unittest.TestOptions{
+		GiteaRootPath: filepath.Join("..", ".."),
+		FixtureFiles: []string{
+			"action_runner.yml",
+			"action_runner_token.yml",
+			"action_run.yml",
+			"action_run_index.yml",
+			"action_run_job.yml",
+			"action_job_log.yml",
+			"action_artifact.yml",
+			"repository.yml",
+			"user.yml",
+		},
+	})
+}
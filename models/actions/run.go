@@ -0,0 +1,279 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ErrRunNotExist represents a "RunNotExist" kind of error.
+type ErrRunNotExist struct {
+	ID     int64
+	RepoID int64
+	Index  int64
+}
+
+// IsErrRunNotExist checks if an error is a ErrRunNotExist.
+func IsErrRunNotExist(err error) bool {
+	_, ok := err.(ErrRunNotExist)
+	return ok
+}
+
+func (err ErrRunNotExist) Error() string {
+	return fmt.Sprintf("run does not exist [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
+}
+
+// ActionRun represents a run of a workflow triggered by an event
+type ActionRun struct {
+	ID            int64                  `xorm:"pk autoincr"`
+	Title         string                 `xorm:"VARCHAR(255)"`
+	RepoID        int64                  `xorm:"INDEX UNIQUE(repo_index)"`
+	Repo          *repo_model.Repository `xorm:"-"`
+	Index         int64                  `xorm:"INDEX UNIQUE(repo_index)"`
+	WorkflowID    string                 `xorm:"INDEX"` // the file name of the workflow
+	WorkflowName  string
+	TriggerUserID int64
+	TriggerUser   *user_model.User `xorm:"-"`
+	Ref           string
+	CommitSHA     string
+	Event         string
+	IsForkPull    bool   // secrets aren't passed to runs of pull requests from forks
+	Status        Status `xorm:"INDEX"`
+
+	Started timeutil.TimeStamp
+	Stopped timeutil.TimeStamp
+	Created timeutil.TimeStamp `xorm:"created"`
+	Updated timeutil.TimeStamp `xorm:"updated"`
+}
+
+// ActionRunIndex represents the index of the runs of a repository
+type ActionRunIndex db.ResourceIndex
+
+func init() {
+	db.RegisterModel(new(ActionRun))
+	db.RegisterModel(new(ActionRunIndex))
+}
+
+// LoadAttributes loads the repository and the user who triggered the run
+func (run *ActionRun) LoadAttributes(ctx context.Context) error {
+	if run.Repo == nil {
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, run.RepoID)
+		if err != nil {
+			return err
+		}
+		run.Repo = repo
+	}
+	if run.TriggerUser == nil {
+		u, err := user_model.GetUserByIDCtx(ctx, run.TriggerUserID)
+		if user_model.IsErrUserNotExist(err) {
+			u = user_model.NewGhostUser()
+		} else if err != nil {
+			return err
+		}
+		run.TriggerUser = u
+	}
+	return nil
+}
+
+// Link returns the link of the run, the repository has to be loaded
+func (run *ActionRun) Link() string {
+	return fmt.Sprintf("%s/actions/runs/%d", run.Repo.Link(), run.Index)
+}
+
+// HTMLURL returns the absolute URL of the run, the repository has to be loaded
+func (run *ActionRun) HTMLURL() string {
+	return fmt.Sprintf("%s/actions/runs/%d", run.Repo.HTMLURL(), run.Index)
+}
+
+// Duration returns the time the run took, or has been running for
+func (run *ActionRun) Duration() string {
+	return duration(run.Started, run.Stopped)
+}
+
+func duration(started, stopped timeutil.TimeStamp) string {
+	if started == 0 {
+		return ""
+	}
+	if stopped == 0 {
+		stopped = timeutil.TimeStampNow()
+	}
+	return util.SecToTime(int64(stopped - started))
+}
+
+// InsertRun saves a new run with its jobs
+func InsertRun(ctx context.Context, run *ActionRun, jobs []*ActionRunJob) error {
+	index, err := db.GetNextResourceIndex("action_run_index", run.RepoID)
+	if err != nil {
+		return err
+	}
+	run.Index = index
+	run.Status = StatusWaiting
+
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if err := db.Insert(ctx, run); err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		job.RunID = run.ID
+		job.RepoID = run.RepoID
+		job.CommitSHA = run.CommitSHA
+		job.Status = StatusWaiting
+	}
+	if err := db.Insert(ctx, jobs); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// GetRunByID returns a run by id
+func GetRunByID(ctx context.Context, id int64) (*ActionRun, error) {
+	run := new(ActionRun)
+	has, err := db.GetEngine(ctx).ID(id).Get(run)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunNotExist{ID: id}
+	}
+	return run, nil
+}
+
+// GetRunByIndex returns a run of a repository by its index
+func GetRunByIndex(ctx context.Context, repoID, index int64) (*ActionRun, error) {
+	run := new(ActionRun)
+	has, err := db.GetEngine(ctx).Where("repo_id = ? AND `index` = ?", repoID, index).Get(run)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunNotExist{RepoID: repoID, Index: index}
+	}
+	return run, nil
+}
+
+// FindRunOptions represents the options to find the runs of a repository
+type FindRunOptions struct {
+	db.ListOptions
+	RepoID     int64
+	WorkflowID string
+}
+
+// FindRuns returns the runs of a repository, newest first
+func FindRuns(ctx context.Context, opts FindRunOptions) ([]*ActionRun, int64, error) {
+	sess := db.GetEngine(ctx).Where("repo_id = ?", opts.RepoID)
+	if opts.WorkflowID != "" {
+		sess = sess.And("workflow_id = ?", opts.WorkflowID)
+	}
+	sess = sess.OrderBy("id DESC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	runs := make([]*ActionRun, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&runs)
+	return runs, count, err
+}
+
+// updateRunStatus recomputes the status of a run from the statuses of its jobs
+func updateRunStatus(ctx context.Context, runID int64) error {
+	run, err := GetRunByID(ctx, runID)
+	if err != nil {
+		return err
+	}
+	jobs, err := GetRunJobs(ctx, runID)
+	if err != nil {
+		return err
+	}
+
+	run.Status = aggregateStatus(jobs)
+	if run.Started == 0 && run.Status != StatusWaiting {
+		run.Started = timeutil.TimeStampNow()
+	}
+	if run.Status.IsDone() {
+		run.Stopped = timeutil.TimeStampNow()
+	}
+	_, err = db.GetEngine(ctx).ID(run.ID).Cols("status", "started", "stopped").Update(run)
+	return err
+}
+
+// CancelRun cancels the waiting and running jobs of a run and refreshes the status of the run.
+// It returns the cancelled jobs.
+func CancelRun(ctx context.Context, run *ActionRun) ([]*ActionRunJob, error) {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return nil, err
+	}
+	defer committer.Close()
+
+	jobs, err := GetRunJobs(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	cancelled := make([]*ActionRunJob, 0, len(jobs))
+	for _, job := range jobs {
+		if job.Status.IsDone() {
+			continue
+		}
+		if _, err := UpdateJobStatus(ctx, job, StatusCancelled); err != nil {
+			return nil, err
+		}
+		cancelled = append(cancelled, job)
+	}
+
+	updated, err := GetRunByID(ctx, run.ID)
+	if err != nil {
+		return nil, err
+	}
+	run.Status, run.Started, run.Stopped = updated.Status, updated.Started, updated.Stopped
+	return cancelled, committer.Commit()
+}
+
+// DeleteRepoActions deletes the runs, jobs, logs, artifacts and runners of a deleted repository.
+// It returns the storage paths of the deleted artifacts.
+func DeleteRepoActions(ctx context.Context, repoID int64) ([]string, error) {
+	artifacts := make([]*ActionArtifact, 0, 10)
+	if err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Find(&artifacts); err != nil {
+		return nil, err
+	}
+	paths := make([]string, 0, len(artifacts))
+	for _, artifact := range artifacts {
+		paths = append(paths, artifact.StoragePath)
+	}
+
+	if err := db.DeleteBeans(ctx,
+		&ActionRun{RepoID: repoID},
+		&ActionRunJob{RepoID: repoID},
+		&ActionJobLog{RepoID: repoID},
+		&ActionArtifact{RepoID: repoID},
+		&ActionRunner{RepoID: repoID},
+		&ActionRunnerToken{RepoID: repoID},
+	); err != nil {
+		return nil, err
+	}
+	if err := db.DeleteResouceIndex(ctx, "action_run_index", repoID); err != nil {
+		return nil, err
+	}
+	return paths, nil
+}
+
+// DeleteOwnerRunners deletes the runners and registration tokens of a deleted organization
+func DeleteOwnerRunners(ctx context.Context, ownerID int64) error {
+	if ownerID == 0 {
+		return nil
+	}
+	return db.DeleteBeans(ctx,
+		&ActionRunner{OwnerID: ownerID},
+		&ActionRunnerToken{OwnerID: ownerID},
+	)
+}
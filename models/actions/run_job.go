@@ -0,0 +1,229 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ErrJobNotExist represents a "JobNotExist" kind of error.
+type ErrJobNotExist struct {
+	ID int64
+}
+
+// IsErrJobNotExist checks if an error is a ErrJobNotExist.
+func IsErrJobNotExist(err error) bool {
+	_, ok := err.(ErrJobNotExist)
+	return ok
+}
+
+func (err ErrJobNotExist) Error() string {
+	return fmt.Sprintf("job does not exist [id: %d]", err.ID)
+}
+
+// ActionRunJob represents a job of a run
+type ActionRunJob struct {
+	ID        int64      `xorm:"pk autoincr"`
+	RunID     int64      `xorm:"INDEX"`
+	Run       *ActionRun `xorm:"-"`
+	RepoID    int64      `xorm:"INDEX"`
+	CommitSHA string     `xorm:"INDEX"`
+	Name      string     `xorm:"VARCHAR(255)"`
+	JobID     string     `xorm:"VARCHAR(255)"` // the id of the job in the workflow
+	Needs     []string   `xorm:"TEXT JSON"`
+	RunsOn    []string   `xorm:"TEXT JSON"`
+	Payload   string     `xorm:"LONGTEXT"` // the job definition sent to the runner
+	RunnerID  int64      `xorm:"INDEX"`
+	Status    Status     `xorm:"INDEX"`
+	LogLength int64      `xorm:"NOT NULL DEFAULT 0"`
+
+	Started timeutil.TimeStamp
+	Stopped timeutil.TimeStamp
+	Created timeutil.TimeStamp `xorm:"created"`
+	Updated timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionRunJob))
+}
+
+// LoadRun loads the run of the job with its repository and trigger user
+func (job *ActionRunJob) LoadRun(ctx context.Context) error {
+	if job.Run == nil {
+		run, err := GetRunByID(ctx, job.RunID)
+		if err != nil {
+			return err
+		}
+		job.Run = run
+	}
+	return job.Run.LoadAttributes(ctx)
+}
+
+// Duration returns the time the job took, or has been running for
+func (job *ActionRunJob) Duration() string {
+	return duration(job.Started, job.Stopped)
+}
+
+// GetJobByID returns a job by id
+func GetJobByID(ctx context.Context, id int64) (*ActionRunJob, error) {
+	job := new(ActionRunJob)
+	has, err := db.GetEngine(ctx).ID(id).Get(job)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrJobNotExist{ID: id}
+	}
+	return job, nil
+}
+
+// GetRunJobs returns the jobs of a run
+func GetRunJobs(ctx context.Context, runID int64) ([]*ActionRunJob, error) {
+	jobs := make([]*ActionRunJob, 0, 5)
+	return jobs, db.GetEngine(ctx).Where("run_id = ?", runID).OrderBy("id ASC").Find(&jobs)
+}
+
+// UpdateJobStatus changes the status of a job and updates the status of its run.
+// If the job didn't succeed, the waiting jobs needing it are skipped. It returns the skipped jobs.
+func UpdateJobStatus(ctx context.Context, job *ActionRunJob, status Status) ([]*ActionRunJob, error) {
+	job.Status = status
+	now := timeutil.TimeStampNow()
+	if status == StatusRunning && job.Started == 0 {
+		job.Started = now
+	}
+	if status.IsDone() {
+		job.Stopped = now
+	}
+	if _, err := db.GetEngine(ctx).ID(job.ID).Cols("status", "started", "stopped").Update(job); err != nil {
+		return nil, err
+	}
+
+	var skipped []*ActionRunJob
+	if status.IsDone() && status != StatusSuccess {
+		jobs, err := GetRunJobs(ctx, job.RunID)
+		if err != nil {
+			return nil, err
+		}
+		for _, dependent := range jobs {
+			if dependent.Status != StatusWaiting || !contains(dependent.Needs, job.JobID) {
+				continue
+			}
+			dependentSkipped, err := UpdateJobStatus(ctx, dependent, StatusSkipped)
+			if err != nil {
+				return nil, err
+			}
+			skipped = append(skipped, dependent)
+			skipped = append(skipped, dependentSkipped...)
+		}
+	}
+	return skipped, updateRunStatus(ctx, job.RunID)
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// pickJobBatchSize is the number of waiting jobs PickJob loads at once
+const pickJobBatchSize = 50
+
+// PickJob assigns the oldest waiting job the runner can run to the runner,
+// jobs are only picked once the jobs they need have succeeded. It returns nil if there is no job for the runner.
+func PickJob(ctx context.Context, runner *ActionRunner) (*ActionRunJob, error) {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return nil, err
+	}
+	defer committer.Close()
+
+	cond := builder.NewCond().And(builder.Eq{"status": StatusWaiting})
+	if runner.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": runner.RepoID})
+	} else if runner.OwnerID > 0 {
+		cond = cond.And(builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": runner.OwnerID})))
+	}
+
+	// the labels and needs of the jobs are checked here, so walk the waiting jobs a batch at a time
+	var lastID int64
+	for {
+		jobs := make([]*ActionRunJob, 0, pickJobBatchSize)
+		if err := db.GetEngine(ctx).
+			Where(cond.And(builder.Gt{"id": lastID})).
+			OrderBy("id ASC").
+			Limit(pickJobBatchSize).
+			Find(&jobs); err != nil {
+			return nil, err
+		}
+
+		for _, job := range jobs {
+			if !runner.HasLabels(job.RunsOn) {
+				continue
+			}
+			if ready, err := needsSucceeded(ctx, job); err != nil {
+				return nil, err
+			} else if !ready {
+				continue
+			}
+
+			// another runner may have picked the job in the meantime
+			job.Status = StatusRunning
+			job.RunnerID = runner.ID
+			job.Started = timeutil.TimeStampNow()
+			n, err := db.GetEngine(ctx).
+				Where("id = ? AND status = ?", job.ID, StatusWaiting).
+				Cols("status", "runner_id", "started").
+				Update(job)
+			if err != nil {
+				return nil, err
+			}
+			if n == 0 {
+				continue
+			}
+			if err := updateRunStatus(ctx, job.RunID); err != nil {
+				return nil, err
+			}
+			return job, committer.Commit()
+		}
+
+		if len(jobs) < pickJobBatchSize {
+			return nil, committer.Commit()
+		}
+		lastID = jobs[len(jobs)-1].ID
+	}
+}
+
+// needsSucceeded checks whether the jobs needed by a job have succeeded
+func needsSucceeded(ctx context.Context, job *ActionRunJob) (bool, error) {
+	if len(job.Needs) == 0 {
+		return true, nil
+	}
+	siblings, err := GetRunJobs(ctx, job.RunID)
+	if err != nil {
+		return false, err
+	}
+	for _, sibling := range siblings {
+		if contains(job.Needs, sibling.JobID) && sibling.Status != StatusSuccess {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// FindZombieJobs returns the running jobs which haven't been updated since the given time
+func FindZombieJobs(ctx context.Context, olderThan timeutil.TimeStamp) ([]*ActionRunJob, error) {
+	jobs := make([]*ActionRunJob, 0, 10)
+	return jobs, db.GetEngine(ctx).
+		Where("status = ? AND updated < ?", StatusRunning, olderThan).
+		Find(&jobs)
+}
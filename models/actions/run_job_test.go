@@ -0,0 +1,98 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPickJob(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	run := &ActionRun{RepoID: 1, WorkflowID: "ci.yml", TriggerUserID: 2, Ref: "refs/heads/master", CommitSHA: "65f1bf27bc3bf70f64657658635e66094edbcb4d", Event: "push"}
+	assert.NoError(t, InsertRun(db.DefaultContext, run, []*ActionRunJob{
+		{JobID: "build", Name: "build", RunsOn: []string{"linux"}},
+		{JobID: "test", Name: "test", RunsOn: []string{"linux"}, Needs: []string{"build"}},
+		{JobID: "deploy", Name: "deploy", RunsOn: []string{"linux"}, Needs: []string{"test"}},
+		{JobID: "docs", Name: "docs", RunsOn: []string{"windows"}},
+	}))
+	assert.EqualValues(t, 1, run.Index)
+
+	// runners of other repositories don't get the jobs
+	other := &ActionRunner{Name: "other", RepoID: 2, Labels: []string{"linux", "windows"}}
+	assert.NoError(t, CreateRunner(db.DefaultContext, other))
+	job, err := PickJob(db.DefaultContext, other)
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+
+	runner := &ActionRunner{Name: "linux", OwnerID: 2, Labels: []string{"linux"}}
+	assert.NoError(t, CreateRunner(db.DefaultContext, runner))
+	assert.True(t, runner.VerifyToken(runner.Token))
+	assert.False(t, runner.VerifyToken("wrong"))
+
+	job, err = PickJob(db.DefaultContext, runner)
+	assert.NoError(t, err)
+	if assert.NotNil(t, job) {
+		assert.Equal(t, "build", job.JobID)
+		assert.Equal(t, runner.ID, job.RunnerID)
+	}
+	run, err = GetRunByID(db.DefaultContext, run.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusRunning, run.Status)
+
+	// test needs build, which is still running
+	next, err := PickJob(db.DefaultContext, runner)
+	assert.NoError(t, err)
+	assert.Nil(t, next)
+
+	assert.NoError(t, AppendJobLogs(db.DefaultContext, job, []string{"line 1", "line 2\nline 3"}))
+	assert.NoError(t, AppendJobLogs(db.DefaultContext, job, []string{"line 4"}))
+	assert.EqualValues(t, 4, job.LogLength)
+	lines, err := ReadJobLogs(db.DefaultContext, job.ID, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"line 1", "line 2", "line 3", "line 4"}, lines)
+	lines, err = ReadJobLogs(db.DefaultContext, job.ID, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"line 3", "line 4"}, lines)
+
+	skipped, err := UpdateJobStatus(db.DefaultContext, job, StatusFailure)
+	assert.NoError(t, err)
+	if assert.Len(t, skipped, 2) {
+		assert.Equal(t, "test", skipped[0].JobID)
+		assert.Equal(t, "deploy", skipped[1].JobID)
+	}
+
+	cancelled, err := CancelRun(db.DefaultContext, run)
+	assert.NoError(t, err)
+	if assert.Len(t, cancelled, 1) {
+		assert.Equal(t, "docs", cancelled[0].JobID)
+	}
+	run, err = GetRunByID(db.DefaultContext, run.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, StatusFailure, run.Status)
+	assert.NotZero(t, run.Stopped)
+}
+
+func TestAggregateStatus(t *testing.T) {
+	jobs := func(statuses ...Status) []*ActionRunJob {
+		jobs := make([]*ActionRunJob, 0, len(statuses))
+		for _, status := range statuses {
+			jobs = append(jobs, &ActionRunJob{Status: status})
+		}
+		return jobs
+	}
+	assert.Equal(t, StatusWaiting, aggregateStatus(jobs(StatusWaiting, StatusWaiting)))
+	assert.Equal(t, StatusRunning, aggregateStatus(jobs(StatusSuccess, StatusWaiting)))
+	assert.Equal(t, StatusRunning, aggregateStatus(jobs(StatusFailure, StatusRunning)))
+	assert.Equal(t, StatusSuccess, aggregateStatus(jobs(StatusSuccess, StatusSkipped)))
+	assert.Equal(t, StatusFailure, aggregateStatus(jobs(StatusCancelled, StatusFailure)))
+	assert.Equal(t, StatusCancelled, aggregateStatus(jobs(StatusSuccess, StatusCancelled)))
+	assert.Equal(t, StatusSkipped, aggregateStatus(jobs(StatusSkipped)))
+}
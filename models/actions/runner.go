@@ -0,0 +1,257 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	gouuid "github.com/google/uuid"
+)
+
+// ErrRunnerNotExist represents a "RunnerNotExist" kind of error.
+type ErrRunnerNotExist struct {
+	ID   int64
+	UUID string
+}
+
+// IsErrRunnerNotExist checks if an error is a ErrRunnerNotExist.
+func IsErrRunnerNotExist(err error) bool {
+	_, ok := err.(ErrRunnerNotExist)
+	return ok
+}
+
+func (err ErrRunnerNotExist) Error() string {
+	return fmt.Sprintf("runner does not exist [id: %d, uuid: %s]", err.ID, err.UUID)
+}
+
+// ErrRunnerTokenNotExist represents a "RunnerTokenNotExist" kind of error.
+type ErrRunnerTokenNotExist struct {
+	Token string
+}
+
+// IsErrRunnerTokenNotExist checks if an error is a ErrRunnerTokenNotExist.
+func IsErrRunnerTokenNotExist(err error) bool {
+	_, ok := err.(ErrRunnerTokenNotExist)
+	return ok
+}
+
+func (err ErrRunnerTokenNotExist) Error() string {
+	return "runner registration token does not exist or isn't active"
+}
+
+// ActionRunner represents a runner executing the jobs of workflows.
+// Runners of a repository only run its jobs, runners of an organization run the jobs of its repositories
+// and runners without owner and repository run the jobs of all repositories.
+type ActionRunner struct {
+	ID        int64    `xorm:"pk autoincr"`
+	UUID      string   `xorm:"CHAR(36) UNIQUE"`
+	Name      string   `xorm:"VARCHAR(255)"`
+	OwnerID   int64    `xorm:"INDEX NOT NULL DEFAULT 0"`
+	RepoID    int64    `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Token     string   `xorm:"-"`
+	TokenHash string   `xorm:"UNIQUE"`
+	TokenSalt string   `xorm:"NOT NULL"`
+	Labels    []string `xorm:"TEXT JSON"`
+
+	LastOnline timeutil.TimeStamp `xorm:"INDEX"`
+	Created    timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionRunner))
+}
+
+// runnerOnlineTimeout is the time after which a runner which hasn't fetched jobs is shown as offline
+const runnerOnlineTimeout = time.Minute
+
+// IsOnline returns true if the runner has recently fetched jobs
+func (r *ActionRunner) IsOnline() bool {
+	return r.LastOnline.AddDuration(runnerOnlineTimeout) > timeutil.TimeStampNow()
+}
+
+// IsInstanceRunner returns true if the runner runs the jobs of all repositories of the instance
+func (r *ActionRunner) IsInstanceRunner() bool {
+	return r.OwnerID == 0 && r.RepoID == 0
+}
+
+// HasLabels returns true if the runner has all the labels
+func (r *ActionRunner) HasLabels(labels []string) bool {
+	for _, label := range labels {
+		found := false
+		for _, l := range r.Labels {
+			if l == label {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+// VerifyToken returns true if the token is the token of the runner
+func (r *ActionRunner) VerifyToken(token string) bool {
+	return subtle.ConstantTimeCompare([]byte(r.TokenHash), []byte(auth.HashToken(token, r.TokenSalt))) == 1
+}
+
+// CreateRunner registers a runner, the generated token is set on the runner and only available once
+func CreateRunner(ctx context.Context, r *ActionRunner) error {
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return err
+	}
+	r.UUID = gouuid.New().String()
+	r.Token = base.EncodeSha1(gouuid.New().String())
+	r.TokenSalt = salt
+	r.TokenHash = auth.HashToken(r.Token, r.TokenSalt)
+	return db.Insert(ctx, r)
+}
+
+// GetRunnerByUUID returns a runner by its uuid
+func GetRunnerByUUID(ctx context.Context, uuid string) (*ActionRunner, error) {
+	r := new(ActionRunner)
+	has, err := db.GetEngine(ctx).Where("uuid = ?", uuid).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunnerNotExist{UUID: uuid}
+	}
+	return r, nil
+}
+
+// GetRunnerByID returns a runner by id
+func GetRunnerByID(ctx context.Context, id int64) (*ActionRunner, error) {
+	r := new(ActionRunner)
+	has, err := db.GetEngine(ctx).ID(id).Get(r)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunnerNotExist{ID: id}
+	}
+	return r, nil
+}
+
+// FindRunners returns the runners registered for a repository or organization, or the global runners
+func FindRunners(ctx context.Context, ownerID, repoID int64) ([]*ActionRunner, error) {
+	runners := make([]*ActionRunner, 0, 5)
+	return runners, db.GetEngine(ctx).
+		Where("owner_id = ? AND repo_id = ?", ownerID, repoID).
+		OrderBy("id ASC").
+		Find(&runners)
+}
+
+// UpdateRunnerLastOnline records that the runner is online
+func UpdateRunnerLastOnline(ctx context.Context, r *ActionRunner) error {
+	r.LastOnline = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(r.ID).Cols("last_online").Update(r)
+	return err
+}
+
+// DeleteRunner deletes a runner, the jobs it is running are failed.
+// It returns the jobs whose status has changed.
+func DeleteRunner(ctx context.Context, r *ActionRunner) ([]*ActionRunJob, error) {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return nil, err
+	}
+	defer committer.Close()
+
+	jobs := make([]*ActionRunJob, 0, 2)
+	if err := db.GetEngine(ctx).Where("runner_id = ? AND status = ?", r.ID, StatusRunning).Find(&jobs); err != nil {
+		return nil, err
+	}
+	changed := jobs
+	for _, job := range jobs {
+		skipped, err := UpdateJobStatus(ctx, job, StatusFailure)
+		if err != nil {
+			return nil, err
+		}
+		changed = append(changed, skipped...)
+	}
+
+	if _, err := db.GetEngine(ctx).ID(r.ID).Delete(new(ActionRunner)); err != nil {
+		return nil, err
+	}
+	return changed, committer.Commit()
+}
+
+// ActionRunnerToken is a token used to register runners for a repository, an organization or the whole instance
+type ActionRunnerToken struct {
+	ID       int64  `xorm:"pk autoincr"`
+	Token    string `xorm:"UNIQUE"`
+	OwnerID  int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	RepoID   int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	IsActive bool   `xorm:"NOT NULL DEFAULT false"`
+
+	Created timeutil.TimeStamp `xorm:"created"`
+	Updated timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(ActionRunnerToken))
+}
+
+// GetRunnerToken returns an active registration token
+func GetRunnerToken(ctx context.Context, token string) (*ActionRunnerToken, error) {
+	t := new(ActionRunnerToken)
+	has, err := db.GetEngine(ctx).Where("token = ? AND is_active = ?", token, true).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRunnerTokenNotExist{Token: token}
+	}
+	return t, nil
+}
+
+// GetOrCreateRunnerToken returns the active registration token of a scope, a new token is created if there is none
+func GetOrCreateRunnerToken(ctx context.Context, ownerID, repoID int64) (*ActionRunnerToken, error) {
+	t := new(ActionRunnerToken)
+	has, err := db.GetEngine(ctx).
+		Where("owner_id = ? AND repo_id = ? AND is_active = ?", ownerID, repoID, true).
+		Get(t)
+	if err != nil {
+		return nil, err
+	} else if has {
+		return t, nil
+	}
+	return ResetRunnerToken(ctx, ownerID, repoID)
+}
+
+// ResetRunnerToken deactivates the registration tokens of a scope and creates a new one
+func ResetRunnerToken(ctx context.Context, ownerID, repoID int64) (*ActionRunnerToken, error) {
+	token, err := util.CryptoRandomString(40)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return nil, err
+	}
+	defer committer.Close()
+
+	if _, err := db.GetEngine(ctx).
+		Where("owner_id = ? AND repo_id = ?", ownerID, repoID).
+		Cols("is_active").
+		Update(&ActionRunnerToken{IsActive: false}); err != nil {
+		return nil, err
+	}
+	t := &ActionRunnerToken{Token: token, OwnerID: ownerID, RepoID: repoID, IsActive: true}
+	if err := db.Insert(ctx, t); err != nil {
+		return nil, err
+	}
+	return t, committer.Commit()
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Status represents the status of a run or job
+type Status int
+
+// The statuses of runs and jobs
+const (
+	StatusUnknown Status = iota
+	StatusWaiting
+	StatusRunning
+	StatusSuccess
+	StatusFailure
+	StatusCancelled
+	StatusSkipped
+)
+
+var statusNames = map[Status]string{
+	StatusUnknown:   "unknown",
+	StatusWaiting:   "waiting",
+	StatusRunning:   "running",
+	StatusSuccess:   "success",
+	StatusFailure:   "failure",
+	StatusCancelled: "cancelled",
+	StatusSkipped:   "skipped",
+}
+
+// String returns the name of the status
+func (s Status) String() string {
+	return statusNames[s]
+}
+
+// StatusFromString returns the status by name
+func StatusFromString(name string) (Status, bool) {
+	for s, n := range statusNames {
+		if n == name {
+			return s, true
+		}
+	}
+	return StatusUnknown, false
+}
+
+// IsDone returns true if the run or job has finished
+func (s Status) IsDone() bool {
+	return s == StatusSuccess || s == StatusFailure || s == StatusCancelled || s == StatusSkipped
+}
+
+// CommitStatus returns the state of the commit status reporting a job with this status
+func (s Status) CommitStatus() api.CommitStatusState {
+	switch s {
+	case StatusSuccess, StatusSkipped:
+		return api.CommitStatusSuccess
+	case StatusFailure:
+		return api.CommitStatusFailure
+	case StatusCancelled:
+		return api.CommitStatusError
+	}
+	return api.CommitStatusPending
+}
+
+// aggregateStatus returns the status of a run from the statuses of its jobs
+func aggregateStatus(jobs []*ActionRunJob) Status {
+	var done, success, failure, cancelled, skipped int
+	for _, job := range jobs {
+		switch job.Status {
+		case StatusRunning:
+			return StatusRunning
+		case StatusSuccess:
+			success++
+		case StatusFailure:
+			failure++
+		case StatusCancelled:
+			cancelled++
+		case StatusSkipped:
+			skipped++
+		}
+	}
+	done = success + failure + cancelled + skipped
+
+	switch {
+	case done == 0:
+		return StatusWaiting
+	case done < len(jobs):
+		return StatusRunning
+	case failure > 0:
+		return StatusFailure
+	case cancelled > 0:
+		return StatusCancelled
+	case success == 0:
+		return StatusSkipped
+	}
+	return StatusSuccess
+}
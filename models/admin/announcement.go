@@ -0,0 +1,241 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AnnouncementSeverity is the severity of an announcement, it decides how its banner is rendered
+type AnnouncementSeverity string
+
+// The severities of announcements
+const (
+	AnnouncementSeverityInfo     AnnouncementSeverity = "info"
+	AnnouncementSeverityWarning  AnnouncementSeverity = "warning"
+	AnnouncementSeverityCritical AnnouncementSeverity = "critical"
+)
+
+// AnnouncementSeverities are all severities of announcements
+var AnnouncementSeverities = []AnnouncementSeverity{AnnouncementSeverityInfo, AnnouncementSeverityWarning, AnnouncementSeverityCritical}
+
+// IsValid returns whether the severity is known
+func (s AnnouncementSeverity) IsValid() bool {
+	for _, severity := range AnnouncementSeverities {
+		if s == severity {
+			return true
+		}
+	}
+	return false
+}
+
+// Announcement is a markdown message published by a site admin or, if OrgID isn't 0, by the owners of an organization.
+// It is shown as a banner on all pages, respectively on the pages of the organization and its repositories,
+// between StartUnix and EndUnix.
+type Announcement struct {
+	ID          int64                `xorm:"pk autoincr"`
+	OrgID       int64                `xorm:"INDEX NOT NULL DEFAULT 0"`
+	Content     string               `xorm:"TEXT NOT NULL"`
+	Severity    AnnouncementSeverity `xorm:"VARCHAR(20) NOT NULL DEFAULT 'info'"`
+	Dismissible bool                 `xorm:"NOT NULL DEFAULT false"`
+	StartUnix   timeutil.TimeStamp   `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// EndUnix is 0 if the announcement doesn't expire
+	EndUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// AnnouncementDismissal records that a user has dismissed an announcement
+type AnnouncementDismissal struct {
+	ID             int64              `xorm:"pk autoincr"`
+	AnnouncementID int64              `xorm:"UNIQUE(s) NOT NULL"`
+	UserID         int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(Announcement))
+	db.RegisterModel(new(AnnouncementDismissal))
+}
+
+// IsActive returns whether the announcement is shown at the time
+func (a *Announcement) IsActive(now timeutil.TimeStamp) bool {
+	return a.StartUnix <= now && (a.EndUnix == 0 || now < a.EndUnix)
+}
+
+// ErrAnnouncementNotExist represents a "AnnouncementNotExist" kind of error.
+type ErrAnnouncementNotExist struct {
+	ID int64
+}
+
+// IsErrAnnouncementNotExist checks if an error is a ErrAnnouncementNotExist.
+func IsErrAnnouncementNotExist(err error) bool {
+	_, ok := err.(ErrAnnouncementNotExist)
+	return ok
+}
+
+func (err ErrAnnouncementNotExist) Error() string {
+	return fmt.Sprintf("announcement does not exist [id: %d]", err.ID)
+}
+
+// ErrInvalidAnnouncement represents a "InvalidAnnouncement" kind of error.
+type ErrInvalidAnnouncement struct {
+	Reason string
+}
+
+// IsErrInvalidAnnouncement checks if an error is a ErrInvalidAnnouncement.
+func IsErrInvalidAnnouncement(err error) bool {
+	_, ok := err.(ErrInvalidAnnouncement)
+	return ok
+}
+
+func (err ErrInvalidAnnouncement) Error() string {
+	return fmt.Sprintf("invalid announcement: %s", err.Reason)
+}
+
+func (a *Announcement) validate() error {
+	a.Content = strings.TrimSpace(a.Content)
+
+	if a.Content == "" {
+		return ErrInvalidAnnouncement{Reason: "content is required"}
+	}
+	if !a.Severity.IsValid() {
+		return ErrInvalidAnnouncement{Reason: fmt.Sprintf("unknown severity %q", a.Severity)}
+	}
+	if a.EndUnix != 0 && a.EndUnix <= a.StartUnix {
+		return ErrInvalidAnnouncement{Reason: "the end must be after the start"}
+	}
+	return nil
+}
+
+// GetAnnouncementByID returns the announcement with the id
+func GetAnnouncementByID(ctx context.Context, id int64) (*Announcement, error) {
+	a := &Announcement{}
+	has, err := db.GetEngine(ctx).ID(id).Get(a)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAnnouncementNotExist{ID: id}
+	}
+	return a, nil
+}
+
+// FindAnnouncementsOptions are the options to find announcements
+type FindAnnouncementsOptions struct {
+	db.ListOptions
+	// OrgID is -1 to find the announcements of the instance and of all organizations
+	OrgID int64
+	// ActiveAt only finds the announcements shown at the time if it isn't 0
+	ActiveAt timeutil.TimeStamp
+}
+
+func (opts *FindAnnouncementsOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.OrgID >= 0 {
+		cond = cond.And(builder.Eq{"org_id": opts.OrgID})
+	}
+	if opts.ActiveAt != 0 {
+		cond = cond.And(activeAnnouncementCond(opts.ActiveAt))
+	}
+	return cond
+}
+
+func activeAnnouncementCond(now timeutil.TimeStamp) builder.Cond {
+	return builder.Lte{"start_unix": now}.
+		And(builder.Eq{"end_unix": 0}.Or(builder.Gt{"end_unix": now}))
+}
+
+// FindAnnouncements returns announcements, the latest starting first
+func FindAnnouncements(ctx context.Context, opts *FindAnnouncementsOptions) ([]*Announcement, int64, error) {
+	sess := db.GetEngine(ctx).
+		Where(opts.toConds()).
+		OrderBy("start_unix DESC, id DESC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	announcements := make([]*Announcement, 0, 10)
+	count, err := sess.FindAndCount(&announcements)
+	return announcements, count, err
+}
+
+// GetActiveAnnouncements returns the announcements of the instance and of an organization, if orgID isn't 0,
+// which are shown now. The dismissible announcements dismissed by the user are left out.
+func GetActiveAnnouncements(ctx context.Context, orgID, userID int64) ([]*Announcement, error) {
+	cond := activeAnnouncementCond(timeutil.TimeStampNow())
+	if orgID != 0 {
+		cond = cond.And(builder.In("org_id", 0, orgID))
+	} else {
+		cond = cond.And(builder.Eq{"org_id": 0})
+	}
+	if userID != 0 {
+		cond = cond.And(builder.Eq{"dismissible": false}.Or(builder.NotIn("id",
+			builder.Select("announcement_id").From("announcement_dismissal").Where(builder.Eq{"user_id": userID}))))
+	}
+
+	announcements := make([]*Announcement, 0, 2)
+	return announcements, db.GetEngine(ctx).
+		Where(cond).
+		OrderBy("org_id ASC, start_unix DESC, id DESC").
+		Find(&announcements)
+}
+
+// CreateAnnouncement validates and inserts an announcement
+func CreateAnnouncement(ctx context.Context, a *Announcement) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+	return db.Insert(ctx, a)
+}
+
+// UpdateAnnouncement validates and updates the content, severity, dismissibility and schedule of an announcement
+func UpdateAnnouncement(ctx context.Context, a *Announcement) error {
+	if err := a.validate(); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).ID(a.ID).
+		Cols("content", "severity", "dismissible", "start_unix", "end_unix").
+		Update(a)
+	return err
+}
+
+// DeleteAnnouncement deletes an announcement and its dismissals
+func DeleteAnnouncement(ctx context.Context, id int64) error {
+	return db.WithTx(func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).ID(id).Delete(&Announcement{}); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).Where("announcement_id = ?", id).Delete(&AnnouncementDismissal{})
+		return err
+	}, ctx)
+}
+
+// DeleteAnnouncementsByOrgID deletes all announcements of an organization and their dismissals
+func DeleteAnnouncementsByOrgID(ctx context.Context, orgID int64) error {
+	if _, err := db.GetEngine(ctx).
+		In("announcement_id", builder.Select("id").From("announcement").Where(builder.Eq{"org_id": orgID})).
+		Delete(&AnnouncementDismissal{}); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).Where("org_id = ?", orgID).Delete(&Announcement{})
+	return err
+}
+
+// DismissAnnouncement records that the user has dismissed the announcement, dismissing it again is a no-op
+func DismissAnnouncement(ctx context.Context, announcementID, userID int64) error {
+	return db.WithTx(func(ctx context.Context) error {
+		has, err := db.GetEngine(ctx).Exist(&AnnouncementDismissal{AnnouncementID: announcementID, UserID: userID})
+		if err != nil || has {
+			return err
+		}
+		return db.Insert(ctx, &AnnouncementDismissal{AnnouncementID: announcementID, UserID: userID})
+	}, ctx)
+}
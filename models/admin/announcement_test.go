@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnnouncements(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	global := &Announcement{Content: "maintenance", Severity: AnnouncementSeverityWarning, StartUnix: now - 60}
+	dismissible := &Announcement{Content: "welcome", Severity: AnnouncementSeverityInfo, Dismissible: true, StartUnix: now - 30}
+	scheduled := &Announcement{Content: "upgrade", Severity: AnnouncementSeverityInfo, StartUnix: now + 3600}
+	expired := &Announcement{Content: "outage", Severity: AnnouncementSeverityCritical, StartUnix: now - 3600, EndUnix: now - 60}
+	org := &Announcement{OrgID: 3, Content: "org news", Severity: AnnouncementSeverityInfo, StartUnix: now - 60}
+	for _, a := range []*Announcement{global, dismissible, scheduled, expired, org} {
+		assert.NoError(t, CreateAnnouncement(db.DefaultContext, a))
+	}
+
+	announcements, total, err := FindAnnouncements(db.DefaultContext, &FindAnnouncementsOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, total)
+	if assert.Len(t, announcements, 4) {
+		// the latest starting first
+		assert.Equal(t, scheduled.ID, announcements[0].ID)
+	}
+
+	_, total, err = FindAnnouncements(db.DefaultContext, &FindAnnouncementsOptions{OrgID: -1, ActiveAt: now})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+
+	active, err := GetActiveAnnouncements(db.DefaultContext, 0, 2)
+	assert.NoError(t, err)
+	assert.Len(t, active, 2)
+
+	active, err = GetActiveAnnouncements(db.DefaultContext, 3, 2)
+	assert.NoError(t, err)
+	assert.Len(t, active, 3)
+
+	// dismissing is only possible for dismissible announcements and only affects the user
+	assert.NoError(t, DismissAnnouncement(db.DefaultContext, dismissible.ID, 2))
+	assert.NoError(t, DismissAnnouncement(db.DefaultContext, dismissible.ID, 2))
+	assert.NoError(t, DismissAnnouncement(db.DefaultContext, global.ID, 2))
+	active, err = GetActiveAnnouncements(db.DefaultContext, 0, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, active, 1) {
+		assert.Equal(t, global.ID, active[0].ID)
+	}
+	active, err = GetActiveAnnouncements(db.DefaultContext, 0, 4)
+	assert.NoError(t, err)
+	assert.Len(t, active, 2)
+
+	scheduled.StartUnix = now - 10
+	assert.NoError(t, UpdateAnnouncement(db.DefaultContext, scheduled))
+	a, err := GetAnnouncementByID(db.DefaultContext, scheduled.ID)
+	assert.NoError(t, err)
+	assert.True(t, a.IsActive(now))
+
+	assert.NoError(t, DeleteAnnouncement(db.DefaultContext, dismissible.ID))
+	_, err = GetAnnouncementByID(db.DefaultContext, dismissible.ID)
+	assert.True(t, IsErrAnnouncementNotExist(err))
+	unittest.AssertNotExistsBean(t, &AnnouncementDismissal{AnnouncementID: dismissible.ID})
+
+	assert.NoError(t, DeleteAnnouncementsByOrgID(db.DefaultContext, 3))
+	unittest.AssertNotExistsBean(t, &Announcement{OrgID: 3})
+
+	err = CreateAnnouncement(db.DefaultContext, &Announcement{Content: " ", Severity: AnnouncementSeverityInfo})
+	assert.True(t, IsErrInvalidAnnouncement(err))
+	err = CreateAnnouncement(db.DefaultContext, &Announcement{Content: "outage", Severity: AnnouncementSeverityInfo, StartUnix: now, EndUnix: now - 1})
+	assert.True(t, IsErrInvalidAnnouncement(err))
+
+	assert.True(t, AnnouncementSeverityCritical.IsValid())
+	assert.False(t, AnnouncementSeverity("error").IsValid())
+}
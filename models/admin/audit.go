@@ -0,0 +1,187 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AuditAction represents an event of the instance-wide audit log,
+// it consists of the category of the event and what happened, e.g. "auth.signin"
+type AuditAction string
+
+// The events recorded in the instance-wide audit log
+const (
+	AuditActionSignIn                  AuditAction = "auth.signin"
+	AuditActionSignInFailed            AuditAction = "auth.signin_failed"
+	AuditActionSignOut                 AuditAction = "auth.signout"
+	AuditActionTwoFactorFailed         AuditAction = "auth.twofa_failed"
+	AuditActionTokenRejected           AuditAction = "auth.token_rejected"
+	AuditActionDeployKeyRejected       AuditAction = "auth.deploy_key_rejected"
+	AuditActionUserCreate              AuditAction = "admin.user_create"
+	AuditActionUserUpdate              AuditAction = "admin.user_update"
+	AuditActionUserDelete              AuditAction = "admin.user_delete"
+	AuditActionUserSuspend             AuditAction = "admin.user_suspend"
+	AuditActionUserUnsuspend           AuditAction = "admin.user_unsuspend"
+	AuditActionRepoDelete              AuditAction = "admin.repo_delete"
+	AuditActionRepoConsole             AuditAction = "admin.repo_console"
+	AuditActionAuthSourceCreate        AuditAction = "admin.auth_source_create"
+	AuditActionAuthSourceUpdate        AuditAction = "admin.auth_source_update"
+	AuditActionAuthSourceDelete        AuditAction = "admin.auth_source_delete"
+	AuditActionReleaseSigningKeyUpdate AuditAction = "admin.release_signing_key_update"
+	AuditActionReleaseSigningKeyDelete AuditAction = "admin.release_signing_key_delete"
+	AuditActionAnnouncementCreate      AuditAction = "admin.announcement_create"
+	AuditActionAnnouncementUpdate      AuditAction = "admin.announcement_update"
+	AuditActionAnnouncementDelete      AuditAction = "admin.announcement_delete"
+	AuditActionMaintenanceModeEnable   AuditAction = "admin.maintenance_mode_enable"
+	AuditActionMaintenanceModeDisable  AuditAction = "admin.maintenance_mode_disable"
+	AuditActionOrgPurge                AuditAction = "admin.org_purge"
+	AuditActionOperationRequest        AuditAction = "admin.operation_request"
+	AuditActionOperationApprove        AuditAction = "admin.operation_approve"
+	AuditActionOperationCancel         AuditAction = "admin.operation_cancel"
+	AuditActionSiteAdminGrant          AuditAction = "permission.site_admin_grant"
+	AuditActionSiteAdminRevoke         AuditAction = "permission.site_admin_revoke"
+	AuditActionTeamMemberAdd           AuditAction = "permission.team_member_add"
+	AuditActionTeamMemberRemove        AuditAction = "permission.team_member_remove"
+)
+
+// AuditCategories are the categories of the events recorded in the instance-wide audit log
+var AuditCategories = []string{"auth", "admin", "permission"}
+
+// Category returns the category of the event, e.g. "auth"
+func (a AuditAction) Category() string {
+	if i := strings.IndexByte(string(a), '.'); i >= 0 {
+		return string(a)[:i]
+	}
+	return string(a)
+}
+
+// AuditEvent records an authentication event, an admin action or a permission change of the instance
+type AuditEvent struct {
+	ID     int64       `xorm:"pk autoincr"`
+	Action AuditAction `xorm:"VARCHAR(50) INDEX NOT NULL"`
+	// DoerID is 0 if nobody is signed in, e.g. for failed sign in attempts
+	DoerID   int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+	DoerName string `xorm:"NOT NULL DEFAULT ''"`
+	IP       string `xorm:"VARCHAR(50)"`
+	// Target is the affected user, repository, authentication source or team
+	Target string `xorm:"NOT NULL DEFAULT ''"`
+	Detail string `xorm:"TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+func init() {
+	db.RegisterModel(new(AuditEvent))
+}
+
+// InsertAuditEvent inserts an event into the instance-wide audit log
+func InsertAuditEvent(ctx context.Context, event *AuditEvent) error {
+	return db.Insert(ctx, event)
+}
+
+// FindAuditEventsOptions are the options to find events of the instance-wide audit log
+type FindAuditEventsOptions struct {
+	db.ListOptions
+	// Action is either an action like "auth.signin" or the category of actions like "auth"
+	Action string
+	DoerID int64
+	Since  timeutil.TimeStamp
+	Before timeutil.TimeStamp
+}
+
+func (opts *FindAuditEventsOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.Action != "" {
+		if strings.Contains(opts.Action, ".") {
+			cond = cond.And(builder.Eq{"action": opts.Action})
+		} else {
+			cond = cond.And(builder.Like{"action", opts.Action + ".%"})
+		}
+	}
+	if opts.DoerID != 0 {
+		cond = cond.And(builder.Eq{"doer_id": opts.DoerID})
+	}
+	if opts.Since != 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Before != 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Before})
+	}
+	return cond
+}
+
+// FindAuditEvents returns events of the instance-wide audit log, newest first
+func FindAuditEvents(ctx context.Context, opts *FindAuditEventsOptions) ([]*AuditEvent, int64, error) {
+	sess := db.GetEngine(ctx).
+		Where(opts.toConds()).
+		OrderBy("created_unix DESC, id DESC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	events := make([]*AuditEvent, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&events)
+	return events, count, err
+}
+
+// IterateAuditEvents calls fn for all events of the instance-wide audit log matching the options, oldest first.
+// The pagination of the options is ignored.
+func IterateAuditEvents(ctx context.Context, opts *FindAuditEventsOptions, fn func(event *AuditEvent) error) error {
+	return db.Iterate(ctx, new(AuditEvent), opts.toConds(), func(_ int, bean interface{}) error {
+		return fn(bean.(*AuditEvent))
+	})
+}
+
+// DeleteOldAuditEvents deletes all events of the instance-wide audit log which are older than the given duration
+func DeleteOldAuditEvents(ctx context.Context, olderThan time.Duration) error {
+	if olderThan <= 0 {
+		return nil
+	}
+
+	_, err := db.GetEngine(ctx).Where("created_unix < ?", time.Now().Add(-olderThan).Unix()).Delete(&AuditEvent{})
+	return err
+}
+
+// AnonymizeOldAuditEventIPs replaces the IP addresses of the events of the instance-wide audit log
+// which are older than the given duration with the result of anonymize
+func AnonymizeOldAuditEventIPs(ctx context.Context, olderThan time.Duration, anonymize func(ip string) string) error {
+	if olderThan <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-olderThan).Unix()
+	events := make([]*AuditEvent, 0, 50)
+	for lastID := int64(0); ; {
+		events = events[:0]
+		if err := db.GetEngine(ctx).
+			Where("created_unix < ? AND id > ? AND ip <> ''", cutoff, lastID).
+			Asc("id").
+			Limit(50).
+			Cols("id", "ip").
+			Find(&events); err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		for _, event := range events {
+			lastID = event.ID
+			ip := anonymize(event.IP)
+			if ip == event.IP {
+				continue
+			}
+			if _, err := db.GetEngine(ctx).ID(event.ID).Cols("ip").Update(&AuditEvent{IP: ip}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.NoError(t, InsertAuditEvent(db.DefaultContext, &AuditEvent{Action: AuditActionSignInFailed, IP: "127.0.0.1", Target: "user2"}))
+	assert.NoError(t, InsertAuditEvent(db.DefaultContext, &AuditEvent{Action: AuditActionSignIn, DoerID: 2, DoerName: "user2", IP: "127.0.0.1", Target: "user2"}))
+	assert.NoError(t, InsertAuditEvent(db.DefaultContext, &AuditEvent{Action: AuditActionSiteAdminGrant, DoerID: 1, DoerName: "user1", Target: "user2"}))
+
+	events, total, err := FindAuditEvents(db.DefaultContext, &FindAuditEventsOptions{ListOptions: db.ListOptions{Page: 1, PageSize: 2}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	if assert.Len(t, events, 2) {
+		// newest first
+		assert.Equal(t, AuditActionSiteAdminGrant, events[0].Action)
+		assert.Equal(t, AuditActionSignIn, events[1].Action)
+	}
+
+	// a category matches all its actions
+	_, total, err = FindAuditEvents(db.DefaultContext, &FindAuditEventsOptions{Action: "auth"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	_, total, err = FindAuditEvents(db.DefaultContext, &FindAuditEventsOptions{Action: string(AuditActionSignIn)})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	_, total, err = FindAuditEvents(db.DefaultContext, &FindAuditEventsOptions{DoerID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	var exported []AuditAction
+	assert.NoError(t, IterateAuditEvents(db.DefaultContext, &FindAuditEventsOptions{Action: "auth"}, func(event *AuditEvent) error {
+		exported = append(exported, event.Action)
+		return nil
+	}))
+	assert.Equal(t, []AuditAction{AuditActionSignInFailed, AuditActionSignIn}, exported)
+
+	assert.Equal(t, "permission", AuditActionSiteAdminGrant.Category())
+}
+
+func TestDeleteOldAuditEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	old := &AuditEvent{Action: AuditActionSignIn, Target: "user2"}
+	assert.NoError(t, InsertAuditEvent(db.DefaultContext, old))
+	_, err := db.Exec(db.DefaultContext, "UPDATE audit_event SET created_unix = ? WHERE id = ?", time.Now().Add(-48*time.Hour).Unix(), old.ID)
+	assert.NoError(t, err)
+	recent := &AuditEvent{Action: AuditActionSignOut, Target: "user2"}
+	assert.NoError(t, InsertAuditEvent(db.DefaultContext, recent))
+
+	assert.NoError(t, DeleteOldAuditEvents(db.DefaultContext, 24*time.Hour))
+	unittest.AssertNotExistsBean(t, &AuditEvent{ID: old.ID})
+	unittest.AssertExistsAndLoadBean(t, &AuditEvent{ID: recent.ID})
+}
+
+func TestAnonymizeOldAuditEventIPs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	old := &AuditEvent{Action: AuditActionSignIn, IP: "192.168.1.42", Target: "user2"}
+	assert.NoError(t, InsertAuditEvent(db.DefaultContext, old))
+	_, err := db.Exec(db.DefaultContext, "UPDATE audit_event SET created_unix = ? WHERE id = ?", time.Now().Add(-48*time.Hour).Unix(), old.ID)
+	assert.NoError(t, err)
+	recent := &AuditEvent{Action: AuditActionSignOut, IP: "192.168.1.42", Target: "user2"}
+	assert.NoError(t, InsertAuditEvent(db.DefaultContext, recent))
+
+	assert.NoError(t, AnonymizeOldAuditEventIPs(db.DefaultContext, 24*time.Hour, func(string) string { return "anonymized" }))
+	assert.Equal(t, "anonymized", unittest.AssertExistsAndLoadBean(t, &AuditEvent{ID: old.ID}).(*AuditEvent).IP)
+	assert.Equal(t, "192.168.1.42", unittest.AssertExistsAndLoadBean(t, &AuditEvent{ID: recent.ID}).(*AuditEvent).IP)
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// MaintenanceMode records that a site admin has put the instance into maintenance mode,
+// the instance is in maintenance mode as long as such a record exists
+type MaintenanceMode struct {
+	ID          int64              `xorm:"pk autoincr"`
+	Message     string             `xorm:"TEXT"`
+	DoerID      int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(MaintenanceMode))
+}
+
+// GetMaintenanceMode returns the maintenance mode of the instance or nil if it isn't in maintenance mode
+func GetMaintenanceMode(ctx context.Context) (*MaintenanceMode, error) {
+	mode := new(MaintenanceMode)
+	has, err := db.GetEngine(ctx).Desc("id").Get(mode)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return mode, nil
+}
+
+// EnableMaintenanceMode puts the instance into maintenance mode, it replaces the message if it is already in maintenance mode
+func EnableMaintenanceMode(ctx context.Context, mode *MaintenanceMode) error {
+	return db.WithTx(func(ctx context.Context) error {
+		if err := DisableMaintenanceMode(ctx); err != nil {
+			return err
+		}
+		return db.Insert(ctx, mode)
+	}, ctx)
+}
+
+// DisableMaintenanceMode ends the maintenance mode of the instance
+func DisableMaintenanceMode(ctx context.Context) error {
+	_, err := db.GetEngine(ctx).Where("1 = 1").Delete(&MaintenanceMode{})
+	return err
+}
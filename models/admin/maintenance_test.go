@@ -0,0 +1,36 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	mode, err := GetMaintenanceMode(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.Nil(t, mode)
+
+	assert.NoError(t, EnableMaintenanceMode(db.DefaultContext, &MaintenanceMode{Message: "Upgrading", DoerID: 1}))
+	assert.NoError(t, EnableMaintenanceMode(db.DefaultContext, &MaintenanceMode{Message: "Migrating the database", DoerID: 1}))
+	unittest.AssertCount(t, &MaintenanceMode{}, 1)
+	mode, err = GetMaintenanceMode(db.DefaultContext)
+	assert.NoError(t, err)
+	if assert.NotNil(t, mode) {
+		assert.Equal(t, "Migrating the database", mode.Message)
+	}
+
+	assert.NoError(t, DisableMaintenanceMode(db.DefaultContext))
+	mode, err = GetMaintenanceMode(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.Nil(t, mode)
+}
@@ -0,0 +1,129 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// PendingOperationType is the type of a destructive operation which needs the approval of a second site admin
+type PendingOperationType string
+
+// The types of the operations which need the approval of a second site admin
+const (
+	// PendingOperationPurgeUser deletes a user together with the repositories and packages of the user
+	PendingOperationPurgeUser PendingOperationType = "purge_user"
+	// PendingOperationPurgeOrg deletes an organization together with its repositories and packages
+	PendingOperationPurgeOrg PendingOperationType = "purge_org"
+)
+
+// PendingOperation is a destructive operation requested by a site admin, which is carried out
+// once another site admin approves it before ExpiresUnix
+type PendingOperation struct {
+	ID   int64                `xorm:"pk autoincr"`
+	Type PendingOperationType `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL"`
+	// TargetID is the id of the user or organization
+	TargetID      int64              `xorm:"UNIQUE(s) NOT NULL"`
+	TargetName    string             `xorm:"NOT NULL"`
+	RequesterID   int64              `xorm:"INDEX NOT NULL"`
+	RequesterName string             `xorm:"NOT NULL"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	ExpiresUnix   timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(PendingOperation))
+}
+
+// IsExpired returns whether the operation can no longer be approved at the time
+func (op *PendingOperation) IsExpired(now timeutil.TimeStamp) bool {
+	return op.ExpiresUnix <= now
+}
+
+// ErrPendingOperationNotExist represents a "PendingOperationNotExist" kind of error.
+type ErrPendingOperationNotExist struct {
+	ID int64
+}
+
+// IsErrPendingOperationNotExist checks if an error is a ErrPendingOperationNotExist.
+func IsErrPendingOperationNotExist(err error) bool {
+	_, ok := err.(ErrPendingOperationNotExist)
+	return ok
+}
+
+func (err ErrPendingOperationNotExist) Error() string {
+	return fmt.Sprintf("pending operation does not exist [id: %d]", err.ID)
+}
+
+// ErrPendingOperationAlreadyExist represents a "PendingOperationAlreadyExist" kind of error.
+type ErrPendingOperationAlreadyExist struct {
+	Type       PendingOperationType
+	TargetName string
+}
+
+// IsErrPendingOperationAlreadyExist checks if an error is a ErrPendingOperationAlreadyExist.
+func IsErrPendingOperationAlreadyExist(err error) bool {
+	_, ok := err.(ErrPendingOperationAlreadyExist)
+	return ok
+}
+
+func (err ErrPendingOperationAlreadyExist) Error() string {
+	return fmt.Sprintf("pending operation already exists [type: %s, target: %s]", err.Type, err.TargetName)
+}
+
+// CreatePendingOperation records an operation waiting for approval, the expired operation of the same type
+// and target is replaced but a pending one results in ErrPendingOperationAlreadyExist
+func CreatePendingOperation(ctx context.Context, op *PendingOperation) error {
+	return db.WithTx(func(ctx context.Context) error {
+		existing := &PendingOperation{Type: op.Type, TargetID: op.TargetID}
+		has, err := db.GetEngine(ctx).Get(existing)
+		if err != nil {
+			return err
+		} else if has {
+			if !existing.IsExpired(timeutil.TimeStampNow()) {
+				return ErrPendingOperationAlreadyExist{Type: op.Type, TargetName: op.TargetName}
+			}
+			if _, err := db.GetEngine(ctx).ID(existing.ID).Delete(&PendingOperation{}); err != nil {
+				return err
+			}
+		}
+		return db.Insert(ctx, op)
+	}, ctx)
+}
+
+// GetPendingOperationByID returns the pending operation with the id
+func GetPendingOperationByID(ctx context.Context, id int64) (*PendingOperation, error) {
+	op := &PendingOperation{}
+	has, err := db.GetEngine(ctx).ID(id).Get(op)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPendingOperationNotExist{ID: id}
+	}
+	return op, nil
+}
+
+// FindPendingOperations returns all pending operations which haven't expired at the time, the latest first
+func FindPendingOperations(ctx context.Context, now timeutil.TimeStamp) ([]*PendingOperation, error) {
+	ops := make([]*PendingOperation, 0, 5)
+	return ops, db.GetEngine(ctx).Where("expires_unix > ?", now).Desc("id").Find(&ops)
+}
+
+// DeletePendingOperation deletes a pending operation after it has been approved or cancelled,
+// it returns false if it has already been deleted, e.g. because another site admin has approved it in the meantime
+func DeletePendingOperation(ctx context.Context, id int64) (bool, error) {
+	n, err := db.GetEngine(ctx).ID(id).Delete(&PendingOperation{})
+	return n > 0, err
+}
+
+// DeleteExpiredPendingOperations deletes the operations which have expired at the time
+func DeleteExpiredPendingOperations(ctx context.Context, now timeutil.TimeStamp) error {
+	_, err := db.GetEngine(ctx).Where("expires_unix <= ?", now).Delete(&PendingOperation{})
+	return err
+}
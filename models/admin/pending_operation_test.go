@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPendingOperations(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	purgeUser := &PendingOperation{Type: PendingOperationPurgeUser, TargetID: 2, TargetName: "user2", RequesterID: 1, RequesterName: "user1", ExpiresUnix: now + 3600}
+	purgeOrg := &PendingOperation{Type: PendingOperationPurgeOrg, TargetID: 3, TargetName: "user3", RequesterID: 1, RequesterName: "user1", ExpiresUnix: now - 60}
+	assert.NoError(t, CreatePendingOperation(db.DefaultContext, purgeUser))
+	assert.NoError(t, CreatePendingOperation(db.DefaultContext, purgeOrg))
+
+	// only one operation of a type can be pending for a target
+	err := CreatePendingOperation(db.DefaultContext, &PendingOperation{Type: PendingOperationPurgeUser, TargetID: 2, TargetName: "user2", RequesterID: 1, RequesterName: "user1", ExpiresUnix: now + 3600})
+	assert.True(t, IsErrPendingOperationAlreadyExist(err))
+
+	ops, err := FindPendingOperations(db.DefaultContext, now)
+	assert.NoError(t, err)
+	if assert.Len(t, ops, 1) {
+		assert.Equal(t, purgeUser.ID, ops[0].ID)
+	}
+
+	// an expired operation is replaced
+	assert.NoError(t, CreatePendingOperation(db.DefaultContext, &PendingOperation{Type: PendingOperationPurgeOrg, TargetID: 3, TargetName: "user3", RequesterID: 1, RequesterName: "user1", ExpiresUnix: now + 3600}))
+	unittest.AssertNotExistsBean(t, &PendingOperation{ID: purgeOrg.ID})
+
+	deleted, err := DeletePendingOperation(db.DefaultContext, purgeUser.ID)
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	deleted, err = DeletePendingOperation(db.DefaultContext, purgeUser.ID)
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	_, err = GetPendingOperationByID(db.DefaultContext, purgeUser.ID)
+	assert.True(t, IsErrPendingOperationNotExist(err))
+
+	assert.NoError(t, DeleteExpiredPendingOperations(db.DefaultContext, now+7200))
+	unittest.AssertCount(t, &PendingOperation{}, 0)
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package asymkey
+
+import (
+	"fmt"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/keybase/go-crypto/openpgp/packet"
+)
+
+// This file contains functions related to the certification of keys by other keys (web of trust)
+
+// IsGPGKeyCertifiedBy returns true if a user id of the key, as originally imported, carries a valid
+// certification made by a key of another user accepted by isTrusted.
+func IsGPGKeyCertifiedBy(key *GPGKey, isTrusted func(*user_model.User) (bool, error)) (bool, error) {
+	primaryKeyID := key.KeyID
+	if key.PrimaryKeyID != "" {
+		primaryKeyID = key.PrimaryKeyID
+	}
+
+	keyImport, err := GetGPGImportByKeyID(primaryKeyID)
+	if err != nil {
+		if IsErrGPGKeyImportNotExist(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	ekeys, err := checkArmoredGPGKeyString(keyImport.Content)
+	if err != nil {
+		log.Error("Unable to parse imported key %s: %v", primaryKeyID, err)
+		return false, nil
+	}
+
+	checked := make(map[int64]bool)
+	for _, ekey := range ekeys {
+		for name, identity := range ekey.Identities {
+			for _, sig := range identity.Signatures {
+				if sig.IssuerKeyId == nil || checked[int64(*sig.IssuerKeyId)] {
+					continue
+				}
+				certified, err := isCertifiedByTrustedKey(key.OwnerID, name, ekey.PrimaryKey, sig, isTrusted)
+				if err != nil || certified {
+					return certified, err
+				}
+				checked[int64(*sig.IssuerKeyId)] = true
+			}
+		}
+	}
+	return false, nil
+}
+
+func isCertifiedByTrustedKey(ownerID int64, name string, pub *packet.PublicKey, sig *packet.Signature, isTrusted func(*user_model.User) (bool, error)) (bool, error) {
+	certifiers, err := GetGPGKeysByKeyID(fmt.Sprintf("%X", *sig.IssuerKeyId))
+	if err != nil {
+		return false, err
+	}
+	for _, certifier := range certifiers {
+		if certifier.OwnerID == ownerID {
+			continue
+		}
+
+		certifierKey, err := base64DecPubKey(certifier.Content)
+		if err != nil {
+			log.Error("Unable to decode key %s: %v", certifier.KeyID, err)
+			continue
+		}
+		if err := certifierKey.VerifyUserIdSignature(name, pub, sig); err != nil {
+			continue
+		}
+
+		owner, err := user_model.GetUserByID(certifier.OwnerID)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				continue
+			}
+			return false, err
+		}
+		trusted, err := isTrusted(owner)
+		if err != nil || trusted {
+			return trusted, err
+		}
+	}
+	return false, nil
+}
@@ -478,6 +478,12 @@ func CalculateTrustStatus(verification *CommitVerification, repoTrustModel repo_
 		return
 	}
 
+	// In the AllValidKeys trust model every valid signature by a key known to Gitea is trusted
+	if repoTrustModel == repo_model.AllValidKeysTrustModel {
+		verification.TrustStatus = "trusted"
+		return
+	}
+
 	// Now we drop to the more nuanced trust models...
 	verification.TrustStatus = "trusted"
 
@@ -493,32 +499,52 @@ func CalculateTrustStatus(verification *CommitVerification, repoTrustModel repo_
 		return
 	}
 
-	// Check we actually have a GPG SigningKey
+	// Check we actually have a GPG or SSH SigningKey
+	var keyID string
 	if verification.SigningKey != nil {
-		var isMember bool
-		if keyMap != nil {
-			var has bool
-			isMember, has = (*keyMap)[verification.SigningKey.KeyID]
-			if !has {
-				isMember, err = isOwnerMemberCollaborator(verification.SigningUser)
-				(*keyMap)[verification.SigningKey.KeyID] = isMember
-			}
-		} else {
-			isMember, err = isOwnerMemberCollaborator(verification.SigningUser)
+		keyID = verification.SigningKey.KeyID
+	} else if verification.SigningSSHKey != nil {
+		keyID = verification.SigningSSHKey.Fingerprint
+	} else {
+		return
+	}
+
+	var isMember bool
+	if keyMap != nil {
+		var has bool
+		isMember, has = (*keyMap)[keyID]
+		if !has {
+			isMember, err = isTrustedSigner(verification, repoTrustModel, isOwnerMemberCollaborator)
+			(*keyMap)[keyID] = isMember
 		}
+	} else {
+		isMember, err = isTrustedSigner(verification, repoTrustModel, isOwnerMemberCollaborator)
+	}
 
-		if !isMember {
-			verification.TrustStatus = "untrusted"
-			if verification.CommittingUser.ID != verification.SigningUser.ID {
-				// The committing user and the signing user are not the same
-				// This should be marked as questionable unless the signing user is a collaborator/team member etc.
-				verification.TrustStatus = "unmatched"
-			}
-		} else if repoTrustModel == repo_model.CollaboratorCommitterTrustModel && verification.CommittingUser.ID != verification.SigningUser.ID {
-			// The committing user and the signing user are not the same and our trustmodel states that they must match
+	if !isMember {
+		verification.TrustStatus = "untrusted"
+		if verification.CommittingUser.ID != verification.SigningUser.ID {
+			// The committing user and the signing user are not the same
+			// This should be marked as questionable unless the signing user is a collaborator/team member etc.
 			verification.TrustStatus = "unmatched"
 		}
+	} else if repoTrustModel == repo_model.CollaboratorCommitterTrustModel && verification.CommittingUser.ID != verification.SigningUser.ID {
+		// The committing user and the signing user are not the same and our trustmodel states that they must match
+		verification.TrustStatus = "unmatched"
 	}
 
 	return
 }
+
+// isTrustedSigner returns true if the signing user is the owner, a member or a collaborator of the repository.
+// In the WebOfTrust trust model GPG keys certified by such users are trusted too.
+func isTrustedSigner(verification *CommitVerification, repoTrustModel repo_model.TrustModelType, isOwnerMemberCollaborator func(*user_model.User) (bool, error)) (bool, error) {
+	isMember, err := isOwnerMemberCollaborator(verification.SigningUser)
+	if err != nil || isMember {
+		return isMember, err
+	}
+	if repoTrustModel == repo_model.WebOfTrustTrustModel && verification.SigningKey != nil {
+		return IsGPGKeyCertifiedBy(verification.SigningKey, isOwnerMemberCollaborator)
+	}
+	return false, nil
+}
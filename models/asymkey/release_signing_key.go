@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package asymkey
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ReleaseSigningKey is an OpenPGP key used to sign the checksums of the assets of releases.
+// The key with RepoID 0 is the key of the instance, which is used by all repositories without a key of their own.
+type ReleaseSigningKey struct {
+	ID          int64  `xorm:"pk autoincr"`
+	RepoID      int64  `xorm:"UNIQUE NOT NULL"`
+	KeyID       string `xorm:"CHAR(16) NOT NULL"`
+	Fingerprint string `xorm:"VARCHAR(40) NOT NULL"`
+	Identity    string
+	PublicKey   string             `xorm:"TEXT NOT NULL"`
+	PrivateKey  string             `xorm:"TEXT NOT NULL"` // armored private key encrypted with the master key
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ReleaseSigningKey))
+}
+
+// IsInstanceKey returns true if the key is the key of the instance
+func (key *ReleaseSigningKey) IsInstanceKey() bool {
+	return key.RepoID == 0
+}
+
+// ErrReleaseSigningKeyNotExist represents a "ReleaseSigningKeyNotExist" kind of error.
+type ErrReleaseSigningKeyNotExist struct {
+	RepoID int64
+}
+
+// IsErrReleaseSigningKeyNotExist checks if an error is a ErrReleaseSigningKeyNotExist.
+func IsErrReleaseSigningKeyNotExist(err error) bool {
+	_, ok := err.(ErrReleaseSigningKeyNotExist)
+	return ok
+}
+
+func (err ErrReleaseSigningKeyNotExist) Error() string {
+	return fmt.Sprintf("release signing key does not exist [repo_id: %d]", err.RepoID)
+}
+
+// GetReleaseSigningKey returns the release signing key of a repository, or of the instance if repoID is 0
+func GetReleaseSigningKey(ctx context.Context, repoID int64) (*ReleaseSigningKey, error) {
+	key := new(ReleaseSigningKey)
+	has, err := db.GetEngine(ctx).Where("repo_id=?", repoID).Get(key)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrReleaseSigningKeyNotExist{RepoID: repoID}
+	}
+	return key, nil
+}
+
+// SetReleaseSigningKey stores the release signing key of a repository, replacing its previous key
+func SetReleaseSigningKey(ctx context.Context, key *ReleaseSigningKey) error {
+	return db.WithTx(func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("repo_id=?", key.RepoID).Delete(new(ReleaseSigningKey)); err != nil {
+			return err
+		}
+		key.ID = 0
+		return db.Insert(ctx, key)
+	}, ctx)
+}
+
+// DeleteReleaseSigningKey deletes the release signing key of a repository, or of the instance if repoID is 0
+func DeleteReleaseSigningKey(ctx context.Context, repoID int64) error {
+	n, err := db.GetEngine(ctx).Where("repo_id=?", repoID).Delete(new(ReleaseSigningKey))
+	if err != nil {
+		return err
+	} else if n == 0 {
+		return ErrReleaseSigningKeyNotExist{RepoID: repoID}
+	}
+	return nil
+}
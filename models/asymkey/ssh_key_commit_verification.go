@@ -6,59 +6,113 @@ package asymkey
 
 import (
 	"bytes"
+	"encoding/pem"
+	"errors"
 	"fmt"
 	"strings"
 
-	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 
 	"github.com/42wim/sshsig"
+	"golang.org/x/crypto/ssh"
 )
 
 // ParseCommitWithSSHSignature check if signature is good against keystore.
 func ParseCommitWithSSHSignature(c *git.Commit, committer *user_model.User) *CommitVerification {
-	// Now try to associate the signature with the committer, if present
-	if committer.ID != 0 {
-		keys, err := ListPublicKeys(committer.ID, db.ListOptions{})
-		if err != nil { // Skipping failed to get ssh keys of user
-			log.Error("ListPublicKeys: %v", err)
-			return &CommitVerification{
-				CommittingUser: committer,
-				Verified:       false,
-				Reason:         "gpg.error.failed_retrieval_gpg_keys",
-			}
+	// The signature embeds the public key, so look the key up by its fingerprint
+	fingerprint, err := sshSignatureFingerprint(c.Signature.Signature)
+	if err != nil {
+		log.Error("sshSignatureFingerprint: %v", err)
+		return &CommitVerification{
+			CommittingUser: committer,
+			Verified:       false,
+			Reason:         "gpg.error.extract_sign",
 		}
+	}
 
-		committerEmailAddresses, err := user_model.GetEmailAddresses(committer.ID)
-		if err != nil {
-			log.Error("GetEmailAddresses: %v", err)
+	keys, err := SearchPublicKey(0, fingerprint)
+	if err != nil { // Skipping failed to get ssh keys
+		log.Error("SearchPublicKey: %v", err)
+		return &CommitVerification{
+			CommittingUser: committer,
+			Verified:       false,
+			Reason:         "gpg.error.failed_retrieval_gpg_keys",
 		}
+	}
 
-		activated := false
-		for _, e := range committerEmailAddresses {
-			if e.IsActivated && strings.EqualFold(e.Email, c.Committer.Email) {
-				activated = true
-				break
-			}
+	defaultReason := NoKeyFound
+	for _, k := range keys {
+		if !k.Verified || k.Type == KeyTypePrincipal {
+			continue
 		}
 
-		for _, k := range keys {
-			if k.Verified && activated {
-				commitVerification := verifySSHCommitVerification(c.Signature.Signature, c.Signature.Payload, k, committer, committer, c.Committer.Email)
-				if commitVerification != nil {
-					return commitVerification
+		signer, email := committer, c.Committer.Email
+		if committer.ID == k.OwnerID {
+			// The key of the committer only validates the committer email if it is activated
+			if !isActivatedEmailOf(committer.ID, c.Committer.Email) {
+				continue
+			}
+		} else {
+			signer, err = user_model.GetUserByID(k.OwnerID)
+			if err != nil {
+				if !user_model.IsErrUserNotExist(err) {
+					log.Error("GetUserByID: %d for key ID: %d (%s) %v", k.OwnerID, k.ID, k.Fingerprint, err)
 				}
+				continue
 			}
+			email = signer.Email
 		}
+
+		if commitVerification := verifySSHCommitVerification(c.Signature.Signature, c.Signature.Payload, k, committer, signer, email); commitVerification != nil {
+			return commitVerification
+		}
+		// This is a bad situation ... We have a key with the fingerprint of the signature but the signature doesn't match.
+		defaultReason = BadSignature
 	}
 
 	return &CommitVerification{
 		CommittingUser: committer,
 		Verified:       false,
-		Reason:         NoKeyFound,
+		Warning:        defaultReason != NoKeyFound,
+		Reason:         defaultReason,
+		SigningSSHKey: &PublicKey{
+			Fingerprint: fingerprint,
+		},
+	}
+}
+
+// sshSignatureFingerprint returns the fingerprint of the public key embedded in an armored ssh signature
+func sshSignatureFingerprint(armored string) (string, error) {
+	block, _ := pem.Decode([]byte(armored))
+	if block == nil || block.Type != "SSH SIGNATURE" {
+		return "", errors.New("unable to decode ssh signature")
+	}
+
+	var sig sshsig.WrappedSig
+	if err := ssh.Unmarshal(block.Bytes, &sig); err != nil {
+		return "", err
+	}
+	pk, err := ssh.ParsePublicKey([]byte(sig.PublicKey))
+	if err != nil {
+		return "", err
+	}
+	return ssh.FingerprintSHA256(pk), nil
+}
+
+func isActivatedEmailOf(userID int64, email string) bool {
+	emails, err := user_model.GetEmailAddresses(userID)
+	if err != nil {
+		log.Error("GetEmailAddresses: %v", err)
+		return false
+	}
+	for _, e := range emails {
+		if e.IsActivated && strings.EqualFold(e.Email, email) {
+			return true
+		}
 	}
+	return false
 }
 
 func verifySSHCommitVerification(sig, payload string, k *PublicKey, committer, signer *user_model.User, email string) *CommitVerification {
@@ -0,0 +1,122 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package asymkey
+
+import (
+	"bytes"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/42wim/sshsig"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/crypto/ssh"
+)
+
+func sshSignedCommit(t *testing.T, committerEmail string) *git.Commit {
+	payload := "tree 4b825dc642cb6eb9a060e54bf8d69288fbee4904\ncommitter User <" + committerEmail + "> 1650000000 +0000\n\nsigned\n"
+	sig, err := sshsig.Sign([]byte(ed25519PrivateKey), bytes.NewBufferString(payload), "git")
+	assert.NoError(t, err)
+	return &git.Commit{
+		Committer: &git.Signature{Name: "User", Email: committerEmail},
+		Signature: &git.CommitGPGSignature{Signature: string(sig), Payload: payload},
+	}
+}
+
+func TestParseCommitWithSSHSignature(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	pk, _, _, _, err := ssh.ParseAuthorizedKey([]byte(ed25519PublicKey))
+	assert.NoError(t, err)
+	fingerprint := ssh.FingerprintSHA256(pk)
+
+	commit := sshSignedCommit(t, "user2@example.com")
+	fp, err := sshSignatureFingerprint(commit.Signature.Signature)
+	assert.NoError(t, err)
+	assert.EqualValues(t, fingerprint, fp)
+
+	// unknown key
+	verification := ParseCommitWithSignature(commit)
+	assert.False(t, verification.Verified)
+	assert.EqualValues(t, NoKeyFound, verification.Reason)
+	assert.EqualValues(t, fingerprint, verification.SigningSSHKey.Fingerprint)
+
+	key := &PublicKey{
+		OwnerID:     2,
+		Name:        "signing",
+		Fingerprint: fingerprint,
+		Content:     ed25519PublicKey,
+		Mode:        perm.AccessModeWrite,
+		Type:        KeyTypeUser,
+	}
+	assert.NoError(t, db.Insert(db.DefaultContext, key))
+
+	// keys have to be verified to verify commits
+	verification = ParseCommitWithSignature(commit)
+	assert.False(t, verification.Verified)
+
+	key.Verified = true
+	_, err = db.GetEngine(db.DefaultContext).ID(key.ID).Cols("verified").Update(key)
+	assert.NoError(t, err)
+
+	verification = ParseCommitWithSignature(commit)
+	assert.True(t, verification.Verified)
+	assert.EqualValues(t, 2, verification.SigningUser.ID)
+	assert.EqualValues(t, 2, verification.CommittingUser.ID)
+	assert.EqualValues(t, key.ID, verification.SigningSSHKey.ID)
+
+	// signatures by another user than the committer are verified too
+	verification = ParseCommitWithSignature(sshSignedCommit(t, "user4@example.com"))
+	assert.True(t, verification.Verified)
+	assert.EqualValues(t, 2, verification.SigningUser.ID)
+	assert.EqualValues(t, 4, verification.CommittingUser.ID)
+
+	// tampered payload
+	commit.Signature.Payload += "tampered"
+	verification = ParseCommitWithSignature(commit)
+	assert.False(t, verification.Verified)
+	assert.True(t, verification.Warning)
+	assert.EqualValues(t, BadSignature, verification.Reason)
+}
+
+func TestCalculateTrustStatus(t *testing.T) {
+	user2 := &user_model.User{ID: 2}
+	user4 := &user_model.User{ID: 4}
+	isCollaborator := func(user *user_model.User) (bool, error) {
+		return user.ID == 2, nil
+	}
+
+	for _, tc := range []struct {
+		model             repo_model.TrustModelType
+		signer, committer *user_model.User
+		status            string
+	}{
+		{repo_model.CollaboratorTrustModel, user2, user2, "trusted"},
+		{repo_model.CollaboratorTrustModel, user2, user4, "trusted"},
+		{repo_model.CollaboratorTrustModel, user4, user4, "untrusted"},
+		{repo_model.CollaboratorTrustModel, user4, user2, "unmatched"},
+		{repo_model.CommitterTrustModel, user4, user4, "trusted"},
+		{repo_model.CommitterTrustModel, user2, user4, "unmatched"},
+		{repo_model.CollaboratorCommitterTrustModel, user2, user4, "unmatched"},
+		{repo_model.AllValidKeysTrustModel, user4, user2, "trusted"},
+		{repo_model.WebOfTrustTrustModel, user2, user4, "trusted"},
+		{repo_model.WebOfTrustTrustModel, user4, user4, "untrusted"},
+	} {
+		// signatures by ssh keys take the collaborators into account too
+		verification := &CommitVerification{
+			Verified:       true,
+			SigningUser:    tc.signer,
+			CommittingUser: tc.committer,
+			SigningSSHKey:  &PublicKey{Fingerprint: "SHA256:fingerprint"},
+		}
+		assert.NoError(t, CalculateTrustStatus(verification, tc.model, isCollaborator, nil))
+		assert.EqualValues(t, tc.status, verification.TrustStatus, "%s signed by %d committed by %d", tc.model, tc.signer.ID, tc.committer.ID)
+	}
+}
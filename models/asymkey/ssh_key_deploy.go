@@ -7,10 +7,12 @@ package asymkey
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/modules/hostmatcher"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"xorm.io/builder"
@@ -35,6 +37,8 @@ type DeployKey struct {
 	Content     string `xorm:"-"`
 
 	Mode perm.AccessMode `xorm:"NOT NULL DEFAULT 1"`
+	// AllowedCIDRs is a comma separated list of the networks the key can be used from, it can be used from everywhere if it's empty
+	AllowedCIDRs string `xorm:"TEXT"`
 
 	CreatedUnix       timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix       timeutil.TimeStamp `xorm:"updated"`
@@ -48,6 +52,14 @@ func (key *DeployKey) AfterLoad() {
 	key.HasRecentActivity = key.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
 }
 
+// IsAllowedFrom returns true if the key can be used from the remote address
+func (key *DeployKey) IsAllowedFrom(remoteAddr string) bool {
+	if strings.TrimSpace(key.AllowedCIDRs) == "" {
+		return true
+	}
+	return hostmatcher.ParseHostMatchList("AllowedCIDRs", key.AllowedCIDRs).MatchHostName(remoteAddr)
+}
+
 // GetContent gets associated public key content.
 func (key *DeployKey) GetContent() error {
 	pkey, err := GetPublicKeyByID(key.KeyID)
@@ -91,17 +103,18 @@ func checkDeployKey(ctx context.Context, keyID, repoID int64, name string) error
 }
 
 // addDeployKey adds new key-repo relation.
-func addDeployKey(ctx context.Context, keyID, repoID int64, name, fingerprint string, mode perm.AccessMode) (*DeployKey, error) {
+func addDeployKey(ctx context.Context, keyID, repoID int64, name, fingerprint string, mode perm.AccessMode, allowedCIDRs string) (*DeployKey, error) {
 	if err := checkDeployKey(ctx, keyID, repoID, name); err != nil {
 		return nil, err
 	}
 
 	key := &DeployKey{
-		KeyID:       keyID,
-		RepoID:      repoID,
-		Name:        name,
-		Fingerprint: fingerprint,
-		Mode:        mode,
+		KeyID:        keyID,
+		RepoID:       repoID,
+		Name:         name,
+		Fingerprint:  fingerprint,
+		Mode:         mode,
+		AllowedCIDRs: allowedCIDRs,
 	}
 	return key, db.Insert(ctx, key)
 }
@@ -115,7 +128,8 @@ func HasDeployKey(keyID, repoID int64) bool {
 }
 
 // AddDeployKey add new deploy key to database and authorized_keys file.
-func AddDeployKey(repoID int64, name, content string, readOnly bool) (*DeployKey, error) {
+// The key can only be used from the networks of allowedCIDRs if it isn't empty.
+func AddDeployKey(repoID int64, name, content string, readOnly bool, allowedCIDRs string) (*DeployKey, error) {
 	fingerprint, err := calcFingerprint(content)
 	if err != nil {
 		return nil, err
@@ -155,7 +169,7 @@ func AddDeployKey(repoID int64, name, content string, readOnly bool) (*DeployKey
 		}
 	}
 
-	key, err := addDeployKey(ctx, pkey.ID, repoID, name, pkey.Fingerprint, accessMode)
+	key, err := addDeployKey(ctx, pkey.ID, repoID, name, pkey.Fingerprint, accessMode, allowedCIDRs)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,206 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"bufio"
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/gobwas/glob"
+	gouuid "github.com/google/uuid"
+)
+
+// DownloadToken is a token which only allows to download raw files and archives of the repositories in its scopes,
+// e.g. for deployment scripts and CDNs
+type DownloadToken struct {
+	ID             int64  `xorm:"pk autoincr"`
+	UID            int64  `xorm:"INDEX"`
+	Name           string `xorm:"NOT NULL"`
+	Token          string `xorm:"-"`
+	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+	// Scopes is a newline separated list of <owner>/<repo> or <owner>/<repo>:<path glob>
+	Scopes       string `xorm:"TEXT NOT NULL"`
+	NumDownloads int64  `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+	LastUsedUnix timeutil.TimeStamp
+}
+
+func init() {
+	db.RegisterModel(new(DownloadToken))
+}
+
+// DownloadTokenScope is a repository and optionally a glob of the paths in it which a download token can download
+type DownloadTokenScope struct {
+	Repo string
+	Path string
+	glob glob.Glob
+}
+
+// Allows returns true if the file can be downloaded from the repository,
+// an empty tree path is an archive of the repository, it is only allowed if the scope doesn't restrict the paths
+func (s *DownloadTokenScope) Allows(repoFullName, treePath string) bool {
+	if !strings.EqualFold(s.Repo, repoFullName) {
+		return false
+	}
+	if s.glob == nil {
+		return true
+	}
+	return treePath != "" && s.glob.Match(treePath)
+}
+
+// ErrInvalidDownloadTokenScope represents a "InvalidDownloadTokenScope" kind of error.
+type ErrInvalidDownloadTokenScope struct {
+	Scope string
+}
+
+// IsErrInvalidDownloadTokenScope checks if an error is a ErrInvalidDownloadTokenScope.
+func IsErrInvalidDownloadTokenScope(err error) bool {
+	_, ok := err.(ErrInvalidDownloadTokenScope)
+	return ok
+}
+
+func (err ErrInvalidDownloadTokenScope) Error() string {
+	return fmt.Sprintf("invalid download token scope: %s", err.Scope)
+}
+
+// ParseDownloadTokenScopes parses the newline separated scopes of a download token
+func ParseDownloadTokenScopes(scopes string) ([]*DownloadTokenScope, error) {
+	var parsed []*DownloadTokenScope
+	scanner := bufio.NewScanner(strings.NewReader(scopes))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		s := &DownloadTokenScope{Repo: line}
+		if i := strings.IndexByte(line, ':'); i >= 0 {
+			s.Repo, s.Path = strings.TrimSpace(line[:i]), strings.Trim(strings.TrimSpace(line[i+1:]), "/")
+		}
+		if parts := strings.Split(s.Repo, "/"); len(parts) != 2 || parts[0] == "" || parts[1] == "" {
+			return nil, ErrInvalidDownloadTokenScope{Scope: line}
+		}
+		if s.Path != "" {
+			g, err := glob.Compile(s.Path, '/')
+			if err != nil {
+				return nil, ErrInvalidDownloadTokenScope{Scope: line}
+			}
+			s.glob = g
+		}
+		parsed = append(parsed, s)
+	}
+	if len(parsed) == 0 {
+		return nil, ErrInvalidDownloadTokenScope{}
+	}
+	return parsed, nil
+}
+
+// Allows returns true if the scopes of the token allow to download the file from the repository,
+// an empty tree path is an archive of the repository
+func (t *DownloadToken) Allows(repoFullName, treePath string) bool {
+	scopes, err := ParseDownloadTokenScopes(t.Scopes)
+	if err != nil {
+		// the scopes are validated when the token is created
+		return false
+	}
+	for _, s := range scopes {
+		if s.Allows(repoFullName, treePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// ScopeList returns the scopes of the token as they were entered, one per line
+func (t *DownloadToken) ScopeList() []string {
+	var list []string
+	for _, line := range strings.Split(t.Scopes, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			list = append(list, line)
+		}
+	}
+	return list
+}
+
+// ErrDownloadTokenNotExist represents a "DownloadTokenNotExist" kind of error.
+type ErrDownloadTokenNotExist struct {
+	ID int64
+}
+
+// IsErrDownloadTokenNotExist checks if an error is a ErrDownloadTokenNotExist.
+func IsErrDownloadTokenNotExist(err error) bool {
+	_, ok := err.(ErrDownloadTokenNotExist)
+	return ok
+}
+
+func (err ErrDownloadTokenNotExist) Error() string {
+	return fmt.Sprintf("download token does not exist [id: %d]", err.ID)
+}
+
+// NewDownloadToken creates a new download token, its scopes must be valid
+func NewDownloadToken(ctx context.Context, t *DownloadToken) error {
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return err
+	}
+	t.TokenSalt = salt
+	t.Token = base.EncodeSha1(gouuid.New().String())
+	t.TokenHash = HashToken(t.Token, t.TokenSalt)
+	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	return db.Insert(ctx, t)
+}
+
+// GetDownloadTokenBySHA returns the download token with the token value
+func GetDownloadTokenBySHA(ctx context.Context, token string) (*DownloadToken, error) {
+	// A token is defined as being SHA1 sum these are 40 hexadecimal bytes long
+	if len(token) != 40 {
+		return nil, ErrDownloadTokenNotExist{}
+	}
+
+	tokens := make([]*DownloadToken, 0, 1)
+	if err := db.GetEngine(ctx).Where("token_last_eight = ?", token[len(token)-8:]).Find(&tokens); err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(HashToken(token, t.TokenSalt))) == 1 {
+			return t, nil
+		}
+	}
+	return nil, ErrDownloadTokenNotExist{}
+}
+
+// ListDownloadTokens returns the download tokens of a user
+func ListDownloadTokens(ctx context.Context, uid int64) ([]*DownloadToken, error) {
+	tokens := make([]*DownloadToken, 0, 5)
+	return tokens, db.GetEngine(ctx).Where("uid = ?", uid).Desc("created_unix").Find(&tokens)
+}
+
+// DeleteDownloadToken deletes a download token of a user
+func DeleteDownloadToken(ctx context.Context, id, uid int64) error {
+	cnt, err := db.GetEngine(ctx).ID(id).Delete(&DownloadToken{UID: uid})
+	if err != nil {
+		return err
+	} else if cnt != 1 {
+		return ErrDownloadTokenNotExist{ID: id}
+	}
+	return nil
+}
+
+// IncreaseDownloadTokenUsage counts a download with the token
+func IncreaseDownloadTokenUsage(ctx context.Context, id int64) error {
+	_, err := db.GetEngine(ctx).ID(id).Incr("num_downloads").Cols("last_used_unix").
+		Update(&DownloadToken{LastUsedUnix: timeutil.TimeStampNow()})
+	return err
+}
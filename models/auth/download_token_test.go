@@ -0,0 +1,69 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseDownloadTokenScopes(t *testing.T) {
+	scopes, err := ParseDownloadTokenScopes("user2/repo1\n\n  org3/website : /dist/** \n")
+	assert.NoError(t, err)
+	if assert.Len(t, scopes, 2) {
+		assert.Equal(t, "user2/repo1", scopes[0].Repo)
+		assert.Empty(t, scopes[0].Path)
+		assert.Equal(t, "org3/website", scopes[1].Repo)
+		assert.Equal(t, "dist/**", scopes[1].Path)
+	}
+
+	for _, invalid := range []string{"", "repo1", "user2/", "user2/repo1/sub", "user2/repo1:[dist"} {
+		_, err := ParseDownloadTokenScopes(invalid)
+		assert.True(t, IsErrInvalidDownloadTokenScope(err), invalid)
+	}
+}
+
+func TestDownloadTokenAllows(t *testing.T) {
+	token := &DownloadToken{Scopes: "user2/repo1\norg3/website:dist/**"}
+
+	assert.True(t, token.Allows("user2/repo1", "README.md"))
+	assert.True(t, token.Allows("User2/Repo1", ""))
+	assert.True(t, token.Allows("org3/website", "dist/js/index.js"))
+	assert.False(t, token.Allows("org3/website", "src/index.js"))
+	assert.False(t, token.Allows("org3/website", ""))
+	assert.False(t, token.Allows("user2/repo2", "README.md"))
+}
+
+func TestDownloadToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	token := &DownloadToken{UID: 2, Name: "cdn", Scopes: "user2/repo1"}
+	assert.NoError(t, NewDownloadToken(db.DefaultContext, token))
+	assert.Len(t, token.Token, 40)
+
+	found, err := GetDownloadTokenBySHA(db.DefaultContext, token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	_, err = GetDownloadTokenBySHA(db.DefaultContext, "0000000000000000000000000000000000000000")
+	assert.True(t, IsErrDownloadTokenNotExist(err))
+
+	assert.NoError(t, IncreaseDownloadTokenUsage(db.DefaultContext, token.ID))
+	assert.NoError(t, IncreaseDownloadTokenUsage(db.DefaultContext, token.ID))
+	found = unittest.AssertExistsAndLoadBean(t, &DownloadToken{ID: token.ID}).(*DownloadToken)
+	assert.EqualValues(t, 2, found.NumDownloads)
+	assert.NotZero(t, found.LastUsedUnix)
+
+	tokens, err := ListDownloadTokens(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+
+	assert.True(t, IsErrDownloadTokenNotExist(DeleteDownloadToken(db.DefaultContext, token.ID, 1)))
+	assert.NoError(t, DeleteDownloadToken(db.DefaultContext, token.ID, 2))
+	unittest.AssertNotExistsBean(t, &DownloadToken{ID: token.ID})
+}
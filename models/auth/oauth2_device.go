@@ -0,0 +1,169 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// OAuth2DeviceStatus is the state of a device authorization
+type OAuth2DeviceStatus int
+
+// The states of a device authorization
+const (
+	OAuth2DeviceStatusPending OAuth2DeviceStatus = iota
+	OAuth2DeviceStatusApproved
+	OAuth2DeviceStatusDenied
+)
+
+// userCodeChars are the characters of user codes, consonants only to avoid
+// forming words and mixing up similar looking characters (RFC 8628 section 6.1)
+const userCodeChars = "BCDFGHJKLMNPQRSTVWXZ"
+
+// userCodeLength is the length of a user code without the separating dash
+const userCodeLength = 8
+
+// OAuth2DeviceAuthorization is an authorization request of a device which can't open a browser itself,
+// the user approves it by entering its user code on another device (RFC 8628).
+type OAuth2DeviceAuthorization struct {
+	ID             int64              `xorm:"pk autoincr"`
+	ApplicationID  int64              `xorm:"INDEX"`
+	DeviceCode     string             `xorm:"INDEX unique"`
+	UserCode       string             `xorm:"INDEX unique"`
+	Scope          string             `xorm:"TEXT"`
+	Status         OAuth2DeviceStatus `xorm:"NOT NULL DEFAULT 0"`
+	GrantID        int64
+	LastPolledUnix timeutil.TimeStamp
+	ValidUntil     timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(OAuth2DeviceAuthorization))
+}
+
+// TableName sets the table name to `oauth2_device_authorization`
+func (device *OAuth2DeviceAuthorization) TableName() string {
+	return "oauth2_device_authorization"
+}
+
+// FormattedUserCode returns the user code as it is shown to the user, e.g. BCDF-GHJK
+func (device *OAuth2DeviceAuthorization) FormattedUserCode() string {
+	return device.UserCode[:userCodeLength/2] + "-" + device.UserCode[userCodeLength/2:]
+}
+
+// IsExpired returns true if the device authorization can't be used anymore
+func (device *OAuth2DeviceAuthorization) IsExpired() bool {
+	return device.ValidUntil <= timeutil.TimeStampNow()
+}
+
+// Approve marks a pending device authorization as approved with the grant of the user,
+// it returns false if the authorization isn't pending anymore.
+func (device *OAuth2DeviceAuthorization) Approve(ctx context.Context, grantID int64) (bool, error) {
+	device.Status = OAuth2DeviceStatusApproved
+	device.GrantID = grantID
+	n, err := db.GetEngine(ctx).ID(device.ID).Where("status = ?", OAuth2DeviceStatusPending).
+		Cols("status", "grant_id").Update(device)
+	return n == 1, err
+}
+
+// Deny marks a pending device authorization as denied
+func (device *OAuth2DeviceAuthorization) Deny(ctx context.Context) (bool, error) {
+	device.Status = OAuth2DeviceStatusDenied
+	n, err := db.GetEngine(ctx).ID(device.ID).Where("status = ?", OAuth2DeviceStatusPending).
+		Cols("status").Update(device)
+	return n == 1, err
+}
+
+// Poll records a poll of the device for its access token and returns false if the device polled
+// again before the interval has passed.
+func (device *OAuth2DeviceAuthorization) Poll(ctx context.Context, interval int64) (bool, error) {
+	now := timeutil.TimeStampNow()
+	tooFast := device.LastPolledUnix.Add(interval) > now
+	device.LastPolledUnix = now
+	_, err := db.GetEngine(ctx).ID(device.ID).Cols("last_polled_unix").Update(device)
+	return !tooFast, err
+}
+
+// Invalidate deletes the device authorization from the database so its device code can't be used again
+func (device *OAuth2DeviceAuthorization) Invalidate(ctx context.Context) error {
+	_, err := db.GetEngine(ctx).ID(device.ID).NoAutoCondition().Delete(device)
+	return err
+}
+
+func generateUserCode() (string, error) {
+	code := make([]byte, userCodeLength)
+	for i := range code {
+		n, err := util.CryptoRandomInt(int64(len(userCodeChars)))
+		if err != nil {
+			return "", err
+		}
+		code[i] = userCodeChars[n]
+	}
+	return string(code), nil
+}
+
+// CreateDeviceAuthorization creates a device authorization for the application which is valid for
+// the given number of seconds, expired device authorizations of all applications are removed.
+func (app *OAuth2Application) CreateDeviceAuthorization(ctx context.Context, scope string, validFor int64) (*OAuth2DeviceAuthorization, error) {
+	if _, err := db.GetEngine(ctx).Where("valid_until <= ?", timeutil.TimeStampNow()).
+		Delete(new(OAuth2DeviceAuthorization)); err != nil {
+		return nil, err
+	}
+
+	rBytes, err := util.CryptoRandomBytes(32)
+	if err != nil {
+		return nil, err
+	}
+	userCode, err := generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	device := &OAuth2DeviceAuthorization{
+		ApplicationID: app.ID,
+		// Add a prefix like the other secrets to make it easier for code scanners to grab them
+		DeviceCode: "gtd_" + base32Lower.EncodeToString(rBytes),
+		UserCode:   userCode,
+		Scope:      scope,
+		ValidUntil: timeutil.TimeStampNow().Add(validFor),
+	}
+	if err := db.Insert(ctx, device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+// GetOAuth2DeviceAuthorizationByDeviceCode returns a device authorization by its device code
+func GetOAuth2DeviceAuthorizationByDeviceCode(ctx context.Context, deviceCode string) (*OAuth2DeviceAuthorization, error) {
+	device := new(OAuth2DeviceAuthorization)
+	if has, err := db.GetEngine(ctx).Where("device_code = ?", deviceCode).Get(device); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return device, nil
+}
+
+// GetOAuth2DeviceAuthorizationByUserCode returns a device authorization by its user code,
+// the case of the code and the dash or spaces users type are ignored.
+func GetOAuth2DeviceAuthorizationByUserCode(ctx context.Context, userCode string) (*OAuth2DeviceAuthorization, error) {
+	userCode = strings.NewReplacer("-", "", " ", "").Replace(strings.ToUpper(userCode))
+	if len(userCode) != userCodeLength {
+		return nil, nil
+	}
+	device := new(OAuth2DeviceAuthorization)
+	if has, err := db.GetEngine(ctx).Where("user_code = ?", userCode).Get(device); err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return device, nil
+}
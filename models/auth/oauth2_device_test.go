@@ -0,0 +1,88 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOAuth2Application_CreateDeviceAuthorization(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	app := unittest.AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
+
+	expired, err := app.CreateDeviceAuthorization(db.DefaultContext, "", -1)
+	assert.NoError(t, err)
+	assert.True(t, expired.IsExpired())
+
+	device, err := app.CreateDeviceAuthorization(db.DefaultContext, "openid", 900)
+	assert.NoError(t, err)
+	assert.False(t, device.IsExpired())
+	assert.True(t, strings.HasPrefix(device.DeviceCode, "gtd_"))
+	assert.Len(t, device.UserCode, userCodeLength)
+	assert.Equal(t, device.UserCode[:4]+"-"+device.UserCode[4:], device.FormattedUserCode())
+	assert.Equal(t, OAuth2DeviceStatusPending, device.Status)
+
+	// expired device authorizations are removed when a new one is created
+	unittest.AssertNotExistsBean(t, &OAuth2DeviceAuthorization{ID: expired.ID})
+	unittest.AssertExistsAndLoadBean(t, &OAuth2DeviceAuthorization{ID: device.ID, ApplicationID: app.ID, Scope: "openid"})
+}
+
+func TestGetOAuth2DeviceAuthorizationByUserCode(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	app := unittest.AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
+	device, err := app.CreateDeviceAuthorization(db.DefaultContext, "", 900)
+	assert.NoError(t, err)
+
+	for _, code := range []string{device.UserCode, device.FormattedUserCode(), strings.ToLower(device.FormattedUserCode())} {
+		found, err := GetOAuth2DeviceAuthorizationByUserCode(db.DefaultContext, code)
+		assert.NoError(t, err)
+		if assert.NotNil(t, found) {
+			assert.Equal(t, device.ID, found.ID)
+		}
+	}
+
+	found, err := GetOAuth2DeviceAuthorizationByUserCode(db.DefaultContext, "BCDF")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	found, err = GetOAuth2DeviceAuthorizationByDeviceCode(db.DefaultContext, device.DeviceCode)
+	assert.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.Equal(t, device.ID, found.ID)
+	}
+}
+
+func TestOAuth2DeviceAuthorization_ApproveAndPoll(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	app := unittest.AssertExistsAndLoadBean(t, &OAuth2Application{ID: 1}).(*OAuth2Application)
+	device, err := app.CreateDeviceAuthorization(db.DefaultContext, "", 900)
+	assert.NoError(t, err)
+
+	inTime, err := device.Poll(db.DefaultContext, 5)
+	assert.NoError(t, err)
+	assert.True(t, inTime)
+	inTime, err = device.Poll(db.DefaultContext, 5)
+	assert.NoError(t, err)
+	assert.False(t, inTime)
+
+	updated, err := device.Approve(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.True(t, updated)
+	unittest.AssertExistsAndLoadBean(t, &OAuth2DeviceAuthorization{ID: device.ID, Status: OAuth2DeviceStatusApproved, GrantID: 1})
+
+	// only pending device authorizations can be approved or denied
+	updated, err = device.Deny(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.False(t, updated)
+
+	assert.NoError(t, device.Invalidate(db.DefaultContext))
+	unittest.AssertNotExistsBean(t, &OAuth2DeviceAuthorization{ID: device.ID})
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/base32"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// RecoveryCodeCount is the number of recovery codes generated at once
+const RecoveryCodeCount = 10
+
+// RecoveryCode is a one-time code to sign in or reset the password instead of a two-factor passcode.
+// Unlike the scratch token a user has several of them, each can only be used once.
+type RecoveryCode struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UID         int64              `xorm:"INDEX NOT NULL"`
+	Salt        string             `xorm:"NOT NULL"`
+	Hash        string             `xorm:"NOT NULL"`
+	UsedUnix    timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName provides the real table name
+func (RecoveryCode) TableName() string {
+	return "two_factor_recovery_code"
+}
+
+func init() {
+	db.RegisterModel(new(RecoveryCode))
+}
+
+func generateRecoveryCode() (string, error) {
+	codeBytes, err := util.CryptoRandomBytes(6)
+	if err != nil {
+		return "", err
+	}
+	// same alphabet as the scratch token, avoids ambiguous chars like `0`, `O`, `1`, `I`.
+	const base32Chars = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+	code := base32.NewEncoding(base32Chars).WithPadding(base32.NoPadding).EncodeToString(codeBytes)
+	return code[:5] + "-" + code[5:], nil
+}
+
+// normalizeRecoveryCode makes a code typed in by a user comparable to the hashed one, the separator and case don't matter
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	code = strings.NewReplacer("-", "", " ", "").Replace(code)
+	if len(code) != 10 {
+		return ""
+	}
+	return code[:5] + "-" + code[5:]
+}
+
+// GenerateRecoveryCodes replaces all recovery codes of the user by RecoveryCodeCount new ones and returns them.
+// Only their hashes are stored, the returned codes can't be shown again.
+func GenerateRecoveryCodes(ctx context.Context, uid int64) ([]string, error) {
+	codes := make([]string, 0, RecoveryCodeCount)
+	beans := make([]*RecoveryCode, 0, RecoveryCodeCount)
+	for i := 0; i < RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			return nil, err
+		}
+		salt, err := util.CryptoRandomString(10)
+		if err != nil {
+			return nil, err
+		}
+		codes = append(codes, code)
+		beans = append(beans, &RecoveryCode{UID: uid, Salt: salt, Hash: HashToken(code, salt)})
+	}
+
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := DeleteRecoveryCodes(ctx, uid); err != nil {
+			return err
+		}
+		return db.Insert(ctx, beans)
+	}, ctx); err != nil {
+		return nil, err
+	}
+	return codes, nil
+}
+
+// UseRecoveryCode checks the code against the unused recovery codes of the user and marks the matching one as used.
+// It returns false if no unused code matches.
+func UseRecoveryCode(ctx context.Context, uid int64, code string) (bool, error) {
+	code = normalizeRecoveryCode(code)
+	if code == "" {
+		return false, nil
+	}
+
+	codes := make([]*RecoveryCode, 0, RecoveryCodeCount)
+	if err := db.GetEngine(ctx).Where("uid = ? AND used_unix = 0", uid).Find(&codes); err != nil {
+		return false, err
+	}
+	for _, c := range codes {
+		if subtle.ConstantTimeCompare([]byte(c.Hash), []byte(HashToken(code, c.Salt))) != 1 {
+			continue
+		}
+		// the condition on used_unix makes sure a code can't be used twice by concurrent requests
+		n, err := db.GetEngine(ctx).ID(c.ID).Where("used_unix = 0").Cols("used_unix").Update(&RecoveryCode{UsedUnix: timeutil.TimeStampNow()})
+		return n == 1, err
+	}
+	return false, nil
+}
+
+// CountUnusedRecoveryCodes returns the number of recovery codes the user can still use
+func CountUnusedRecoveryCodes(ctx context.Context, uid int64) (int64, error) {
+	return db.GetEngine(ctx).Where("uid = ? AND used_unix = 0", uid).Count(new(RecoveryCode))
+}
+
+// DeleteRecoveryCodes deletes all recovery codes of the user
+func DeleteRecoveryCodes(ctx context.Context, uid int64) error {
+	_, err := db.GetEngine(ctx).Where("uid = ?", uid).Delete(new(RecoveryCode))
+	return err
+}
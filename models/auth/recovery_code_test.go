@@ -0,0 +1,59 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecoveryCodes(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+
+	codes, err := GenerateRecoveryCodes(ctx, 2)
+	assert.NoError(t, err)
+	assert.Len(t, codes, RecoveryCodeCount)
+	assert.Len(t, codes[0], 11)
+
+	count, err := CountUnusedRecoveryCodes(ctx, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, RecoveryCodeCount, count)
+
+	// case and separator don't matter
+	ok, err := UseRecoveryCode(ctx, 2, strings.ToLower(strings.ReplaceAll(codes[0], "-", "")))
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	// a code can only be used once and only by its user
+	ok, err = UseRecoveryCode(ctx, 2, codes[0])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = UseRecoveryCode(ctx, 3, codes[1])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+
+	count, err = CountUnusedRecoveryCodes(ctx, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, RecoveryCodeCount-1, count)
+
+	// new codes replace the old ones
+	newCodes, err := GenerateRecoveryCodes(ctx, 2)
+	assert.NoError(t, err)
+	ok, err = UseRecoveryCode(ctx, 2, codes[1])
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	ok, err = UseRecoveryCode(ctx, 2, newCodes[1])
+	assert.NoError(t, err)
+	assert.True(t, ok)
+
+	assert.NoError(t, DeleteRecoveryCodes(ctx, 2))
+	unittest.AssertNotExistsBean(t, &RecoveryCode{UID: 2})
+}
@@ -115,6 +115,12 @@ type Source struct {
 	IsSyncEnabled bool               `xorm:"INDEX NOT NULL DEFAULT false"`
 	Cfg           convert.Conversion `xorm:"TEXT"`
 
+	// AllowedCIDRs and DeniedCIDRs are comma separated lists of the networks the users of the source can
+	// and can't sign in from, AccessTimeWindows restricts the times they can sign in at, see services/auth
+	AllowedCIDRs      string `xorm:"TEXT"`
+	DeniedCIDRs       string `xorm:"TEXT"`
+	AccessTimeWindows string `xorm:"TEXT"`
+
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 }
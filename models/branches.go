@@ -7,6 +7,7 @@ package models
 import (
 	"context"
 	"fmt"
+	"strconv"
 	"strings"
 	"time"
 
@@ -18,6 +19,7 @@ import (
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
@@ -54,6 +56,9 @@ type ProtectedBranch struct {
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+
+	// OrgRule is set if the protection is inherited from the rules of the organization
+	OrgRule *OrgProtectedBranch `xorm:"-"`
 }
 
 func init() {
@@ -64,7 +69,7 @@ func init() {
 
 // IsProtected returns if the branch is protected
 func (protectBranch *ProtectedBranch) IsProtected() bool {
-	return protectBranch.ID > 0
+	return protectBranch.ID > 0 || protectBranch.OrgRule != nil
 }
 
 // CanUserPush returns if some user could push to this protected branch
@@ -318,6 +323,32 @@ func GetProtectedBranchBy(ctx context.Context, repoID int64, branchName string)
 	return rel, nil
 }
 
+// GetEffectiveProtectedBranch returns the protection which applies to a branch: the protected branch
+// of the repository or the inherited rules of its organization. Rules of the organization which
+// don't allow overrides take precedence over the protected branch of the repository.
+func GetEffectiveProtectedBranch(ctx context.Context, repoID int64, branchName string) (*ProtectedBranch, error) {
+	protectBranch, err := GetProtectedBranchBy(ctx, repoID, branchName)
+	if err != nil {
+		return nil, err
+	}
+
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return protectBranch, nil
+		}
+		return nil, err
+	}
+	rule, err := GetOrgProtectedBranchFor(ctx, repo, branchName)
+	if err != nil {
+		return nil, err
+	}
+	if rule != nil && (protectBranch == nil || !rule.AllowOverride) {
+		return rule.ToProtectedBranch(repoID, branchName), nil
+	}
+	return protectBranch, nil
+}
+
 // WhitelistOptions represent all sorts of whitelists used for protected branches
 type WhitelistOptions struct {
 	UserIDs []int64
@@ -399,16 +430,11 @@ func GetProtectedBranches(repoID int64) ([]*ProtectedBranch, error) {
 
 // IsProtectedBranch checks if branch is protected
 func IsProtectedBranch(repoID int64, branchName string) (bool, error) {
-	protectedBranch := &ProtectedBranch{
-		RepoID:     repoID,
-		BranchName: branchName,
-	}
-
-	has, err := db.GetEngine(db.DefaultContext).Exist(protectedBranch)
+	protectedBranch, err := GetEffectiveProtectedBranch(db.DefaultContext, repoID, branchName)
 	if err != nil {
 		return true, err
 	}
-	return has, nil
+	return protectedBranch != nil, nil
 }
 
 // updateApprovalWhitelist checks whether the user whitelist changed and returns a whitelist with
@@ -511,8 +537,13 @@ type DeletedBranch struct {
 	DeletedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 }
 
+// RefName returns the name of the hidden ref which keeps the commit of the deleted branch
+func (deletedBranch *DeletedBranch) RefName() string {
+	return git.DeletedBranchPrefix + strconv.FormatInt(deletedBranch.ID, 10)
+}
+
 // AddDeletedBranch adds a deleted branch to the database
-func AddDeletedBranch(repoID int64, branchName, commit string, deletedByID int64) error {
+func AddDeletedBranch(repoID int64, branchName, commit string, deletedByID int64) (*DeletedBranch, error) {
 	deletedBranch := &DeletedBranch{
 		RepoID:      repoID,
 		Name:        branchName,
@@ -521,7 +552,7 @@ func AddDeletedBranch(repoID int64, branchName, commit string, deletedByID int64
 	}
 
 	_, err := db.GetEngine(db.DefaultContext).Insert(deletedBranch)
-	return err
+	return deletedBranch, err
 }
 
 // GetDeletedBranches returns all the deleted branches
@@ -530,6 +561,25 @@ func GetDeletedBranches(repoID int64) ([]*DeletedBranch, error) {
 	return deletedBranches, db.GetEngine(db.DefaultContext).Where("repo_id = ?", repoID).Desc("deleted_unix").Find(&deletedBranches)
 }
 
+// GetDeletedBranchesByName returns all deleted branches of the repository with the given name
+func GetDeletedBranchesByName(repoID int64, branch string) ([]*DeletedBranch, error) {
+	deletedBranches := make([]*DeletedBranch, 0)
+	return deletedBranches, db.GetEngine(db.DefaultContext).Where("repo_id = ? AND name = ?", repoID, branch).Desc("deleted_unix").Find(&deletedBranches)
+}
+
+// GetLatestDeletedBranchByName returns the most recently deleted branch of the repository with the given name
+func GetLatestDeletedBranchByName(repoID int64, branch string) (*DeletedBranch, error) {
+	deletedBranch := &DeletedBranch{}
+	has, err := db.GetEngine(db.DefaultContext).Where("repo_id = ? AND name = ?", repoID, branch).Desc("deleted_unix", "id").Get(deletedBranch)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, nil
+	}
+	return deletedBranch, nil
+}
+
 // GetDeletedBranchByID get a deleted branch by its ID
 func GetDeletedBranchByID(repoID, id int64) (*DeletedBranch, error) {
 	deletedBranch := &DeletedBranch{}
@@ -575,16 +625,11 @@ func RemoveDeletedBranchByName(repoID int64, branch string) error {
 	return err
 }
 
-// RemoveOldDeletedBranches removes old deleted branches
-func RemoveOldDeletedBranches(ctx context.Context, olderThan time.Duration) {
-	// Nothing to do for shutdown or terminate
-	log.Trace("Doing: DeletedBranchesCleanup")
-
+// FindDeletedBranchesOlderThan returns the deleted branches which were deleted before the given duration
+func FindDeletedBranchesOlderThan(ctx context.Context, olderThan time.Duration) ([]*DeletedBranch, error) {
 	deleteBefore := time.Now().Add(-olderThan)
-	_, err := db.GetEngine(db.DefaultContext).Where("deleted_unix < ?", deleteBefore.Unix()).Delete(new(DeletedBranch))
-	if err != nil {
-		log.Error("DeletedBranchesCleanup: %v", err)
-	}
+	deletedBranches := make([]*DeletedBranch, 0, 10)
+	return deletedBranches, db.GetEngine(ctx).Where("deleted_unix < ?", deleteBefore.Unix()).Asc("repo_id").Find(&deletedBranches)
 }
 
 // RenamedBranch provide renamed branch log
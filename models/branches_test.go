@@ -5,6 +5,7 @@
 package models
 
 import (
+	"fmt"
 	"testing"
 
 	"code.gitea.io/gitea/models/db"
@@ -19,8 +20,26 @@ func TestAddDeletedBranch(t *testing.T) {
 	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
 	firstBranch := unittest.AssertExistsAndLoadBean(t, &DeletedBranch{ID: 1}).(*DeletedBranch)
 
-	assert.Error(t, AddDeletedBranch(repo.ID, firstBranch.Name, firstBranch.Commit, firstBranch.DeletedByID))
-	assert.NoError(t, AddDeletedBranch(repo.ID, "test", "5655464564554545466464656", int64(1)))
+	_, err := AddDeletedBranch(repo.ID, firstBranch.Name, firstBranch.Commit, firstBranch.DeletedByID)
+	assert.Error(t, err)
+
+	deletedBranch, err := AddDeletedBranch(repo.ID, "test", "5655464564554545466464656", int64(1))
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("refs/deleted-branches/%d", deletedBranch.ID), deletedBranch.RefName())
+}
+
+func TestGetLatestDeletedBranchByName(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	firstBranch := unittest.AssertExistsAndLoadBean(t, &DeletedBranch{ID: 1}).(*DeletedBranch)
+
+	deletedBranch, err := GetLatestDeletedBranchByName(firstBranch.RepoID, firstBranch.Name)
+	assert.NoError(t, err)
+	assert.NotNil(t, deletedBranch)
+	assert.Equal(t, firstBranch.ID, deletedBranch.ID)
+
+	deletedBranch, err = GetLatestDeletedBranchByName(firstBranch.RepoID, "does-not-exist")
+	assert.NoError(t, err)
+	assert.Nil(t, deletedBranch)
 }
 
 func TestGetDeletedBranches(t *testing.T) {
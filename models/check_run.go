@@ -0,0 +1,377 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// CheckRunStatus is the progress of a check run
+type CheckRunStatus string
+
+// enumerates all check run statuses
+const (
+	CheckRunStatusQueued     CheckRunStatus = "queued"
+	CheckRunStatusInProgress CheckRunStatus = "in_progress"
+	CheckRunStatusCompleted  CheckRunStatus = "completed"
+)
+
+// IsValid returns true if the status is known
+func (s CheckRunStatus) IsValid() bool {
+	switch s {
+	case CheckRunStatusQueued, CheckRunStatusInProgress, CheckRunStatusCompleted:
+		return true
+	}
+	return false
+}
+
+// CheckRunConclusion is the result of a completed check run
+type CheckRunConclusion string
+
+// enumerates all check run conclusions
+const (
+	CheckRunConclusionNone           CheckRunConclusion = ""
+	CheckRunConclusionSuccess        CheckRunConclusion = "success"
+	CheckRunConclusionFailure        CheckRunConclusion = "failure"
+	CheckRunConclusionNeutral        CheckRunConclusion = "neutral"
+	CheckRunConclusionCancelled      CheckRunConclusion = "cancelled"
+	CheckRunConclusionSkipped        CheckRunConclusion = "skipped"
+	CheckRunConclusionTimedOut       CheckRunConclusion = "timed_out"
+	CheckRunConclusionActionRequired CheckRunConclusion = "action_required"
+)
+
+// IsValid returns true if the conclusion is known
+func (c CheckRunConclusion) IsValid() bool {
+	switch c {
+	case CheckRunConclusionSuccess, CheckRunConclusionFailure, CheckRunConclusionNeutral, CheckRunConclusionCancelled,
+		CheckRunConclusionSkipped, CheckRunConclusionTimedOut, CheckRunConclusionActionRequired:
+		return true
+	}
+	return false
+}
+
+// CheckRunAnnotationLevel is the severity of an annotation
+type CheckRunAnnotationLevel string
+
+// enumerates all annotation levels
+const (
+	CheckRunAnnotationNotice  CheckRunAnnotationLevel = "notice"
+	CheckRunAnnotationWarning CheckRunAnnotationLevel = "warning"
+	CheckRunAnnotationFailure CheckRunAnnotationLevel = "failure"
+)
+
+// IsValid returns true if the level is known
+func (l CheckRunAnnotationLevel) IsValid() bool {
+	switch l {
+	case CheckRunAnnotationNotice, CheckRunAnnotationWarning, CheckRunAnnotationFailure:
+		return true
+	}
+	return false
+}
+
+// ErrCheckRunNotExist represents a "CheckRunNotExist" kind of error.
+type ErrCheckRunNotExist struct {
+	ID int64
+}
+
+// IsErrCheckRunNotExist checks if an error is a ErrCheckRunNotExist.
+func IsErrCheckRunNotExist(err error) bool {
+	_, ok := err.(ErrCheckRunNotExist)
+	return ok
+}
+
+func (err ErrCheckRunNotExist) Error() string {
+	return fmt.Sprintf("check run does not exist [id: %d]", err.ID)
+}
+
+// ErrInvalidCheckRun represents a "InvalidCheckRun" kind of error.
+type ErrInvalidCheckRun struct {
+	Reason string
+}
+
+// IsErrInvalidCheckRun checks if an error is a ErrInvalidCheckRun.
+func IsErrInvalidCheckRun(err error) bool {
+	_, ok := err.(ErrInvalidCheckRun)
+	return ok
+}
+
+func (err ErrInvalidCheckRun) Error() string {
+	return fmt.Sprintf("invalid check run: %s", err.Reason)
+}
+
+// CheckRun is a check of a commit reported by an external service, with a detailed output.
+// Every change of a check run is mirrored as a commit status with the name of the check as context,
+// so branch protection can require check runs like any other status check.
+type CheckRun struct {
+	ID            int64                  `xorm:"pk autoincr"`
+	RepoID        int64                  `xorm:"INDEX NOT NULL"`
+	Repo          *repo_model.Repository `xorm:"-"`
+	SHA           string                 `xorm:"VARCHAR(64) NOT NULL INDEX"`
+	Name          string                 `xorm:"VARCHAR(255) NOT NULL"`
+	Status        CheckRunStatus         `xorm:"VARCHAR(20) NOT NULL"`
+	Conclusion    CheckRunConclusion     `xorm:"VARCHAR(20)"`
+	DetailsURL    string                 `xorm:"TEXT"`
+	ExternalID    string                 `xorm:"VARCHAR(255)"`
+	Title         string                 `xorm:"VARCHAR(255)"`
+	Summary       string                 `xorm:"TEXT"`
+	Text          string                 `xorm:"TEXT"`
+	Attempt       int                    `xorm:"NOT NULL DEFAULT 1"`
+	CreatorID     int64
+	Creator       *user_model.User `xorm:"-"`
+	StartedUnix   timeutil.TimeStamp
+	CompletedUnix timeutil.TimeStamp
+	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix   timeutil.TimeStamp `xorm:"updated"`
+}
+
+// CheckRunAnnotation is a message of a check run about a range of lines of a file
+type CheckRunAnnotation struct {
+	ID         int64                   `xorm:"pk autoincr"`
+	RepoID     int64                   `xorm:"INDEX NOT NULL"`
+	CheckRunID int64                   `xorm:"INDEX NOT NULL"`
+	Path       string                  `xorm:"VARCHAR(500) NOT NULL"`
+	StartLine  int                     `xorm:"NOT NULL"`
+	EndLine    int                     `xorm:"NOT NULL"`
+	Level      CheckRunAnnotationLevel `xorm:"VARCHAR(10) NOT NULL"`
+	Title      string                  `xorm:"VARCHAR(255)"`
+	Message    string                  `xorm:"TEXT"`
+}
+
+func init() {
+	db.RegisterModel(new(CheckRun))
+	db.RegisterModel(new(CheckRunAnnotation))
+}
+
+// LoadAttributes loads the repository and the creator of the check run
+func (run *CheckRun) LoadAttributes(ctx context.Context) error {
+	var err error
+	if run.Repo == nil {
+		if run.Repo, err = repo_model.GetRepositoryByIDCtx(ctx, run.RepoID); err != nil {
+			return err
+		}
+	}
+	if run.Creator == nil && run.CreatorID > 0 {
+		if run.Creator, err = user_model.GetUserByIDCtx(ctx, run.CreatorID); err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				return err
+			}
+			run.Creator = user_model.NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// HTMLURL returns the URL of the check run page, the repository has to be loaded
+func (run *CheckRun) HTMLURL() string {
+	return fmt.Sprintf("%s/checks/%d", run.Repo.HTMLURL(), run.ID)
+}
+
+// Validate checks the status, conclusion and line ranges of the check run and its annotations.
+// Setting a conclusion completes the check run.
+func (run *CheckRun) Validate(annotations []*CheckRunAnnotation) error {
+	if run.Conclusion != CheckRunConclusionNone {
+		if !run.Conclusion.IsValid() {
+			return ErrInvalidCheckRun{Reason: fmt.Sprintf("unknown conclusion %q", run.Conclusion)}
+		}
+		run.Status = CheckRunStatusCompleted
+	}
+	if run.Status == "" {
+		run.Status = CheckRunStatusQueued
+	}
+	if !run.Status.IsValid() {
+		return ErrInvalidCheckRun{Reason: fmt.Sprintf("unknown status %q", run.Status)}
+	}
+	if run.Status == CheckRunStatusCompleted && run.Conclusion == CheckRunConclusionNone {
+		return ErrInvalidCheckRun{Reason: "a completed check run needs a conclusion"}
+	}
+
+	for _, a := range annotations {
+		if a.Path == "" {
+			return ErrInvalidCheckRun{Reason: "annotation without path"}
+		}
+		if a.StartLine < 1 {
+			return ErrInvalidCheckRun{Reason: fmt.Sprintf("annotation of %s has an invalid start line", a.Path)}
+		}
+		if a.EndLine < a.StartLine {
+			a.EndLine = a.StartLine
+		}
+		if a.Level == "" {
+			a.Level = CheckRunAnnotationNotice
+		}
+		if !a.Level.IsValid() {
+			return ErrInvalidCheckRun{Reason: fmt.Sprintf("unknown annotation level %q", a.Level)}
+		}
+	}
+	return nil
+}
+
+// CommitStatusState returns the state of the commit status mirroring the check run
+func (run *CheckRun) CommitStatusState() api.CommitStatusState {
+	switch run.Conclusion {
+	case CheckRunConclusionSuccess, CheckRunConclusionNeutral, CheckRunConclusionSkipped:
+		return api.CommitStatusSuccess
+	case CheckRunConclusionFailure, CheckRunConclusionActionRequired:
+		return api.CommitStatusFailure
+	case CheckRunConclusionCancelled, CheckRunConclusionTimedOut:
+		return api.CommitStatusError
+	default:
+		return api.CommitStatusPending
+	}
+}
+
+// UpdateTimes sets the start and completion time according to the status of the check run
+func (run *CheckRun) UpdateTimes() {
+	now := timeutil.TimeStampNow()
+	if run.Status != CheckRunStatusQueued && run.StartedUnix.IsZero() {
+		run.StartedUnix = now
+	}
+	if run.Status != CheckRunStatusCompleted {
+		run.CompletedUnix = 0
+	} else if run.CompletedUnix.IsZero() {
+		run.CompletedUnix = now
+	}
+}
+
+// CreateCheckRun inserts a check run with its annotations
+func CreateCheckRun(ctx context.Context, run *CheckRun, annotations []*CheckRunAnnotation) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if run.Attempt == 0 {
+		run.Attempt = 1
+	}
+	if err := db.Insert(ctx, run); err != nil {
+		return err
+	}
+	if err := addCheckRunAnnotations(ctx, run, annotations); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// UpdateCheckRun updates the columns of a check run and appends the annotations
+func UpdateCheckRun(ctx context.Context, run *CheckRun, annotations []*CheckRunAnnotation, cols ...string) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if _, err := db.GetEngine(ctx).ID(run.ID).Cols(cols...).Update(run); err != nil {
+		return err
+	}
+	if err := addCheckRunAnnotations(ctx, run, annotations); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+func addCheckRunAnnotations(ctx context.Context, run *CheckRun, annotations []*CheckRunAnnotation) error {
+	if len(annotations) == 0 {
+		return nil
+	}
+	for _, a := range annotations {
+		a.RepoID = run.RepoID
+		a.CheckRunID = run.ID
+	}
+	_, err := db.GetEngine(ctx).Insert(&annotations)
+	return err
+}
+
+// ResetCheckRun requeues a check run for another attempt, its conclusion, output and annotations are cleared
+func ResetCheckRun(ctx context.Context, run *CheckRun) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	run.Status = CheckRunStatusQueued
+	run.Conclusion = CheckRunConclusionNone
+	run.Title = ""
+	run.Summary = ""
+	run.Text = ""
+	run.Attempt++
+	run.StartedUnix = 0
+	run.CompletedUnix = 0
+	if _, err := db.GetEngine(ctx).ID(run.ID).
+		Cols("status", "conclusion", "title", "summary", "text", "attempt", "started_unix", "completed_unix").
+		Update(run); err != nil {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).Where("check_run_id = ?", run.ID).Delete(new(CheckRunAnnotation)); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// GetCheckRunByID returns the check run of the repository with the given id
+func GetCheckRunByID(ctx context.Context, repoID, id int64) (*CheckRun, error) {
+	run := &CheckRun{}
+	has, err := db.GetEngine(ctx).Where("id = ? AND repo_id = ?", id, repoID).Get(run)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCheckRunNotExist{ID: id}
+	}
+	return run, nil
+}
+
+// FindCheckRunsOptions represents the options to find the check runs of a commit
+type FindCheckRunsOptions struct {
+	db.ListOptions
+	RepoID int64
+	SHA    string
+	Name   string
+	Status CheckRunStatus
+}
+
+func (opts *FindCheckRunsOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"repo_id": opts.RepoID, "sha": opts.SHA})
+	if opts.Name != "" {
+		cond = cond.And(builder.Eq{"name": opts.Name})
+	}
+	if opts.Status != "" {
+		cond = cond.And(builder.Eq{"status": opts.Status})
+	}
+	return cond
+}
+
+// FindCheckRuns returns the check runs of a commit and their total count
+func FindCheckRuns(ctx context.Context, opts *FindCheckRunsOptions) ([]*CheckRun, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).OrderBy("id DESC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	runs := make([]*CheckRun, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&runs)
+	return runs, count, err
+}
+
+// GetCheckRunAnnotations returns the annotations of a check run ordered by file and line
+func GetCheckRunAnnotations(ctx context.Context, runID int64) ([]*CheckRunAnnotation, error) {
+	annotations := make([]*CheckRunAnnotation, 0, 10)
+	return annotations, db.GetEngine(ctx).
+		Where("check_run_id = ?", runID).
+		OrderBy("path ASC, start_line ASC, id ASC").
+		Find(&annotations)
+}
+
+// CountCheckRunAnnotations returns the number of annotations of a check run
+func CountCheckRunAnnotations(ctx context.Context, runID int64) (int64, error) {
+	return db.GetEngine(ctx).Where("check_run_id = ?", runID).Count(new(CheckRunAnnotation))
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRunValidate(t *testing.T) {
+	run := &CheckRun{}
+	assert.NoError(t, run.Validate(nil))
+	assert.Equal(t, CheckRunStatusQueued, run.Status)
+
+	run = &CheckRun{Status: CheckRunStatusInProgress, Conclusion: CheckRunConclusionNeutral}
+	assert.NoError(t, run.Validate(nil))
+	assert.Equal(t, CheckRunStatusCompleted, run.Status)
+	assert.Equal(t, structs.CommitStatusSuccess, run.CommitStatusState())
+
+	assert.True(t, IsErrInvalidCheckRun((&CheckRun{Status: "done"}).Validate(nil)))
+	assert.True(t, IsErrInvalidCheckRun((&CheckRun{Conclusion: "great"}).Validate(nil)))
+	assert.True(t, IsErrInvalidCheckRun((&CheckRun{Status: CheckRunStatusCompleted}).Validate(nil)))
+
+	annotation := &CheckRunAnnotation{Path: "README.md", StartLine: 3}
+	assert.NoError(t, (&CheckRun{}).Validate([]*CheckRunAnnotation{annotation}))
+	assert.Equal(t, 3, annotation.EndLine)
+	assert.Equal(t, CheckRunAnnotationNotice, annotation.Level)
+	assert.True(t, IsErrInvalidCheckRun((&CheckRun{}).Validate([]*CheckRunAnnotation{{Path: "README.md"}})))
+	assert.True(t, IsErrInvalidCheckRun((&CheckRun{}).Validate([]*CheckRunAnnotation{{Path: "README.md", StartLine: 1, Level: "fatal"}})))
+}
+
+func TestCheckRunCommitStatusState(t *testing.T) {
+	for conclusion, state := range map[CheckRunConclusion]structs.CommitStatusState{
+		CheckRunConclusionNone:           structs.CommitStatusPending,
+		CheckRunConclusionSuccess:        structs.CommitStatusSuccess,
+		CheckRunConclusionSkipped:        structs.CommitStatusSuccess,
+		CheckRunConclusionFailure:        structs.CommitStatusFailure,
+		CheckRunConclusionActionRequired: structs.CommitStatusFailure,
+		CheckRunConclusionCancelled:      structs.CommitStatusError,
+		CheckRunConclusionTimedOut:       structs.CommitStatusError,
+	} {
+		assert.Equal(t, state, (&CheckRun{Conclusion: conclusion}).CommitStatusState(), string(conclusion))
+	}
+}
+
+func TestCheckRunLifecycle(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	sha := "65f1bf27bc3bf70f64657658635e66094edbcb4d"
+	run := &CheckRun{RepoID: 1, SHA: sha, Name: "lint", Status: CheckRunStatusInProgress, CreatorID: 2}
+	assert.NoError(t, CreateCheckRun(db.DefaultContext, run, []*CheckRunAnnotation{
+		{Path: "README.md", StartLine: 2, EndLine: 2, Level: CheckRunAnnotationWarning, Message: "line too long"},
+	}))
+	assert.Equal(t, 1, run.Attempt)
+
+	run.Status = CheckRunStatusCompleted
+	run.Conclusion = CheckRunConclusionFailure
+	assert.NoError(t, UpdateCheckRun(db.DefaultContext, run, []*CheckRunAnnotation{
+		{Path: "README.md", StartLine: 1, EndLine: 1, Level: CheckRunAnnotationFailure, Message: "missing title"},
+	}, "status", "conclusion"))
+
+	annotations, err := GetCheckRunAnnotations(db.DefaultContext, run.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, annotations, 2) {
+		assert.Equal(t, 1, annotations[0].StartLine)
+		assert.Equal(t, int64(1), annotations[0].RepoID)
+	}
+
+	runs, count, err := FindCheckRuns(db.DefaultContext, &FindCheckRunsOptions{RepoID: 1, SHA: sha, Status: CheckRunStatusCompleted})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, runs, 1) {
+		assert.Equal(t, CheckRunConclusionFailure, runs[0].Conclusion)
+	}
+
+	assert.NoError(t, ResetCheckRun(db.DefaultContext, run))
+	run, err = GetCheckRunByID(db.DefaultContext, 1, run.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, CheckRunStatusQueued, run.Status)
+	assert.Equal(t, CheckRunConclusionNone, run.Conclusion)
+	assert.Equal(t, 2, run.Attempt)
+	unittest.AssertCount(t, &CheckRunAnnotation{CheckRunID: run.ID}, 0)
+
+	_, err = GetCheckRunByID(db.DefaultContext, 2, run.ID)
+	assert.True(t, IsErrCheckRunNotExist(err))
+}
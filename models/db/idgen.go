@@ -0,0 +1,77 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Snowflake IDs are laid out as 41 bits of milliseconds since snowflakeEpoch,
+// 5 bits of node ID and 6 bits of sequence. This keeps every ID below 2^53 so
+// that it can still be represented exactly by JavaScript numbers.
+const (
+	snowflakeNodeBits     = 5
+	snowflakeSequenceBits = 6
+	snowflakeMaxNodeID    = 1<<snowflakeNodeBits - 1
+	snowflakeMaxSequence  = 1<<snowflakeSequenceBits - 1
+)
+
+// snowflakeEpoch is 2022-01-01T00:00:00Z in milliseconds
+const snowflakeEpoch int64 = 1640995200000
+
+type snowflakeGenerator struct {
+	mu       sync.Mutex
+	lastTime int64
+	sequence int64
+	now      func() int64
+}
+
+var idGenerator = &snowflakeGenerator{
+	now: func() int64 {
+		return time.Now().UnixNano() / int64(time.Millisecond)
+	},
+}
+
+func (g *snowflakeGenerator) next(nodeID int64) int64 {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	ts := g.now() - snowflakeEpoch
+	if ts < g.lastTime {
+		// the clock went backwards, keep on issuing IDs from the last seen time
+		ts = g.lastTime
+	}
+	if ts == g.lastTime {
+		g.sequence = (g.sequence + 1) & snowflakeMaxSequence
+		if g.sequence == 0 {
+			// sequence exhausted for this millisecond, borrow the next one
+			ts++
+		}
+	} else {
+		g.sequence = 0
+	}
+	g.lastTime = ts
+
+	return ts<<(snowflakeNodeBits+snowflakeSequenceBits) |
+		(nodeID&snowflakeMaxNodeID)<<snowflakeSequenceBits |
+		g.sequence
+}
+
+// IsSnowflakeIDEnabled returns whether high-write tables should use snowflake IDs instead of database auto-increment
+func IsSnowflakeIDEnabled() bool {
+	return setting.Database.IDGenerator == "snowflake"
+}
+
+// NextID returns a new unique ID for a high-write table, or 0 when the database auto-increment should be used.
+// It is meant to be called from BeforeInsert hooks, as xorm only inserts autoincr columns which are non-zero.
+func NextID() int64 {
+	if !IsSnowflakeIDEnabled() {
+		return 0
+	}
+	return idGenerator.next(setting.Database.IDGeneratorNodeID)
+}
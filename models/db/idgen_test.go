@@ -0,0 +1,35 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package db
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSnowflakeGenerator(t *testing.T) {
+	now := snowflakeEpoch + 1000
+	g := &snowflakeGenerator{now: func() int64 { return now }}
+
+	seen := make(map[int64]bool)
+	var last int64
+	for i := 0; i < 3*(snowflakeMaxSequence+1); i++ {
+		id := g.next(3)
+		assert.False(t, seen[id])
+		assert.Greater(t, id, last)
+		assert.EqualValues(t, 3, id>>snowflakeSequenceBits&snowflakeMaxNodeID)
+		seen[id] = true
+		last = id
+	}
+
+	// the clock going backwards must not produce smaller IDs
+	now -= 500
+	assert.Greater(t, g.next(3), last)
+
+	// IDs must stay representable by JavaScript numbers for a long time
+	g = &snowflakeGenerator{now: func() int64 { return snowflakeEpoch + 60*365*24*3600*1000 }}
+	assert.Less(t, g.next(snowflakeMaxNodeID), int64(1)<<53)
+}
@@ -27,4 +27,6 @@ const (
 	SearchOrderByStarsReverse          SearchOrderBy = "num_stars DESC"
 	SearchOrderByForks                 SearchOrderBy = "num_forks ASC"
 	SearchOrderByForksReverse          SearchOrderBy = "num_forks DESC"
+	SearchOrderByLeastPushed           SearchOrderBy = "pushed_unix ASC"
+	SearchOrderByRecentPushed          SearchOrderBy = "pushed_unix DESC"
 )
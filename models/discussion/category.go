@@ -0,0 +1,108 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrCategoryNotExist represents a "CategoryNotExist" kind of error.
+type ErrCategoryNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrCategoryNotExist checks if an error is a ErrCategoryNotExist.
+func IsErrCategoryNotExist(err error) bool {
+	_, ok := err.(ErrCategoryNotExist)
+	return ok
+}
+
+func (err ErrCategoryNotExist) Error() string {
+	return fmt.Sprintf("discussion category does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrCategoryNotEmpty represents a "CategoryNotEmpty" kind of error.
+type ErrCategoryNotEmpty struct {
+	ID int64
+}
+
+// IsErrCategoryNotEmpty checks if an error is a ErrCategoryNotEmpty.
+func IsErrCategoryNotEmpty(err error) bool {
+	_, ok := err.(ErrCategoryNotEmpty)
+	return ok
+}
+
+func (err ErrCategoryNotEmpty) Error() string {
+	return fmt.Sprintf("discussion category still has discussions [id: %d]", err.ID)
+}
+
+// Category groups the discussions of a repository, the discussions of answerable categories can have an accepted answer
+type Category struct {
+	ID             int64  `xorm:"pk autoincr"`
+	RepoID         int64  `xorm:"INDEX NOT NULL"`
+	Name           string `xorm:"NOT NULL"`
+	Description    string `xorm:"TEXT"`
+	IsAnswerable   bool   `xorm:"NOT NULL DEFAULT false"`
+	NumDiscussions int    `xorm:"NOT NULL DEFAULT 0"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the name of this table
+func (Category) TableName() string {
+	return "discussion_category"
+}
+
+func init() {
+	db.RegisterModel(new(Category))
+}
+
+// NewCategory creates a new discussion category
+func NewCategory(ctx context.Context, c *Category) error {
+	return db.Insert(ctx, c)
+}
+
+// GetCategoryByID returns the discussion category of the repository with the id
+func GetCategoryByID(ctx context.Context, repoID, id int64) (*Category, error) {
+	c := &Category{ID: id, RepoID: repoID}
+	has, err := db.GetEngine(ctx).Get(c)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCategoryNotExist{ID: id, RepoID: repoID}
+	}
+	return c, nil
+}
+
+// GetCategories returns the discussion categories of a repository
+func GetCategories(ctx context.Context, repoID int64) ([]*Category, error) {
+	categories := make([]*Category, 0, 5)
+	return categories, db.GetEngine(ctx).Where("repo_id = ?", repoID).Asc("name").Find(&categories)
+}
+
+// UpdateCategory updates the name, description and whether a discussion category is answerable
+func UpdateCategory(ctx context.Context, c *Category) error {
+	_, err := db.GetEngine(ctx).ID(c.ID).Cols("name", "description", "is_answerable").Update(c)
+	return err
+}
+
+// DeleteCategory deletes a discussion category of a repository, it must not have any discussions
+func DeleteCategory(ctx context.Context, repoID, id int64) error {
+	c, err := GetCategoryByID(ctx, repoID, id)
+	if err != nil {
+		return err
+	}
+	if c.NumDiscussions > 0 {
+		return ErrCategoryNotEmpty{ID: id}
+	}
+	_, err = db.GetEngine(ctx).ID(id).Delete(&Category{})
+	return err
+}
@@ -0,0 +1,219 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/container"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrCommentNotExist represents a "CommentNotExist" kind of error.
+type ErrCommentNotExist struct {
+	ID           int64
+	DiscussionID int64
+}
+
+// IsErrCommentNotExist checks if an error is a ErrCommentNotExist.
+func IsErrCommentNotExist(err error) bool {
+	_, ok := err.(ErrCommentNotExist)
+	return ok
+}
+
+func (err ErrCommentNotExist) Error() string {
+	return fmt.Sprintf("discussion comment does not exist [id: %d, discussion_id: %d]", err.ID, err.DiscussionID)
+}
+
+// ErrInvalidAnswer represents a "InvalidAnswer" kind of error.
+type ErrInvalidAnswer struct {
+	DiscussionID int64
+	CommentID    int64
+}
+
+// IsErrInvalidAnswer checks if an error is a ErrInvalidAnswer.
+func IsErrInvalidAnswer(err error) bool {
+	_, ok := err.(ErrInvalidAnswer)
+	return ok
+}
+
+func (err ErrInvalidAnswer) Error() string {
+	return fmt.Sprintf("comment can not be the answer of the discussion [discussion_id: %d, comment_id: %d]", err.DiscussionID, err.CommentID)
+}
+
+// Comment is a comment of a discussion, or a reply to a top level comment
+type Comment struct {
+	ID              int64            `xorm:"pk autoincr"`
+	DiscussionID    int64            `xorm:"INDEX NOT NULL"`
+	ParentID        int64            `xorm:"INDEX NOT NULL DEFAULT 0"` // the top level comment of a reply
+	PosterID        int64            `xorm:"INDEX"`
+	Poster          *user_model.User `xorm:"-"`
+	Content         string           `xorm:"LONGTEXT"`
+	RenderedContent string           `xorm:"-"`
+	Replies         []*Comment       `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the name of this table
+func (Comment) TableName() string {
+	return "discussion_comment"
+}
+
+func init() {
+	db.RegisterModel(new(Comment))
+}
+
+// IsReply returns true if the comment is a reply to another comment
+func (c *Comment) IsReply() bool {
+	return c.ParentID != 0
+}
+
+// HashTag returns the anchor of the comment in the page of the discussion
+func (c *Comment) HashTag() string {
+	return fmt.Sprintf("discussioncomment-%d", c.ID)
+}
+
+// CreateComment adds a comment to a discussion,
+// a reply to a reply is added to the top level comment of the thread
+func CreateComment(ctx context.Context, c *Comment) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if c.ParentID != 0 {
+		parent, err := GetCommentByID(ctx, c.DiscussionID, c.ParentID)
+		if err != nil {
+			return err
+		}
+		if parent.ParentID != 0 {
+			c.ParentID = parent.ParentID
+		}
+	}
+	if err := db.Insert(ctx, c); err != nil {
+		return err
+	}
+	if _, err := db.Exec(ctx, "UPDATE `discussion` SET num_comments=num_comments+1, updated_unix=? WHERE id=?", c.CreatedUnix, c.DiscussionID); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// GetCommentByID returns the comment of the discussion with the id
+func GetCommentByID(ctx context.Context, discussionID, id int64) (*Comment, error) {
+	c := &Comment{ID: id, DiscussionID: discussionID}
+	has, err := db.GetEngine(ctx).Get(c)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCommentNotExist{ID: id, DiscussionID: discussionID}
+	}
+	return c, nil
+}
+
+// GetComments returns the top level comments of a discussion with their replies and posters, the oldest ones first
+func GetComments(ctx context.Context, discussionID int64) ([]*Comment, error) {
+	all := make([]*Comment, 0, 10)
+	if err := db.GetEngine(ctx).Where("discussion_id = ?", discussionID).Asc("created_unix", "id").Find(&all); err != nil {
+		return nil, err
+	}
+
+	posterIDs := make(map[int64]struct{}, len(all))
+	for _, c := range all {
+		posterIDs[c.PosterID] = struct{}{}
+	}
+	posters := make(map[int64]*user_model.User, len(posterIDs))
+	if err := db.GetEngine(ctx).In("id", container.KeysInt64(posterIDs)).Find(&posters); err != nil {
+		return nil, err
+	}
+
+	comments := make([]*Comment, 0, len(all))
+	byID := make(map[int64]*Comment, len(all))
+	for _, c := range all {
+		c.Poster = posters[c.PosterID]
+		if c.Poster == nil {
+			c.Poster = user_model.NewGhostUser()
+		}
+		if c.ParentID == 0 {
+			comments = append(comments, c)
+			byID[c.ID] = c
+		}
+	}
+	for _, c := range all {
+		if parent, ok := byID[c.ParentID]; ok {
+			parent.Replies = append(parent.Replies, c)
+		}
+	}
+	return comments, nil
+}
+
+// UpdateComment updates the content of a comment
+func UpdateComment(ctx context.Context, c *Comment) error {
+	_, err := db.GetEngine(ctx).ID(c.ID).Cols("content").Update(c)
+	return err
+}
+
+// DeleteComment deletes a comment with its replies, the answer of the discussion is unmarked if it is deleted
+func DeleteComment(ctx context.Context, d *Discussion, c *Comment) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	sess := db.GetEngine(ctx)
+	deleted, err := sess.ID(c.ID).Delete(&Comment{})
+	if err != nil {
+		return err
+	}
+	if c.ParentID == 0 {
+		replies, err := sess.Where("parent_id = ?", c.ID).Delete(&Comment{})
+		if err != nil {
+			return err
+		}
+		deleted += replies
+	}
+	if _, err := db.Exec(ctx, "UPDATE `discussion` SET num_comments=num_comments-? WHERE id=?", deleted, d.ID); err != nil {
+		return err
+	}
+	if d.AnswerID == c.ID {
+		d.AnswerID = 0
+		if _, err := sess.ID(d.ID).Cols("answer_id").NoAutoTime().Update(d); err != nil {
+			return err
+		}
+	}
+	return committer.Commit()
+}
+
+// MarkAnswer accepts a top level comment as the answer of a discussion in an answerable category
+func MarkAnswer(ctx context.Context, d *Discussion, commentID int64) error {
+	category, err := GetCategoryByID(ctx, d.RepoID, d.CategoryID)
+	if err != nil {
+		return err
+	}
+	c, err := GetCommentByID(ctx, d.ID, commentID)
+	if err != nil {
+		return err
+	}
+	if !category.IsAnswerable || c.IsReply() {
+		return ErrInvalidAnswer{DiscussionID: d.ID, CommentID: commentID}
+	}
+	d.AnswerID = c.ID
+	_, err = db.GetEngine(ctx).ID(d.ID).Cols("answer_id").NoAutoTime().Update(d)
+	return err
+}
+
+// UnmarkAnswer removes the accepted answer of a discussion
+func UnmarkAnswer(ctx context.Context, d *Discussion) error {
+	d.AnswerID = 0
+	_, err := db.GetEngine(ctx).ID(d.ID).Cols("answer_id").NoAutoTime().Update(d)
+	return err
+}
@@ -0,0 +1,343 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	"xorm.io/builder"
+)
+
+// ErrDiscussionNotExist represents a "DiscussionNotExist" kind of error.
+type ErrDiscussionNotExist struct {
+	ID     int64
+	RepoID int64
+	Index  int64
+}
+
+// IsErrDiscussionNotExist checks if an error is a ErrDiscussionNotExist.
+func IsErrDiscussionNotExist(err error) bool {
+	_, ok := err.(ErrDiscussionNotExist)
+	return ok
+}
+
+func (err ErrDiscussionNotExist) Error() string {
+	return fmt.Sprintf("discussion does not exist [id: %d, repo_id: %d, index: %d]", err.ID, err.RepoID, err.Index)
+}
+
+// Discussion is a conversation of a repository which is not tracked like an issue,
+// e.g. a question, an idea or an announcement
+type Discussion struct {
+	ID              int64                  `xorm:"pk autoincr"`
+	RepoID          int64                  `xorm:"INDEX UNIQUE(repo_index)"`
+	Repo            *repo_model.Repository `xorm:"-"`
+	Index           int64                  `xorm:"UNIQUE(repo_index)"`
+	CategoryID      int64                  `xorm:"INDEX NOT NULL"`
+	Category        *Category              `xorm:"-"`
+	PosterID        int64                  `xorm:"INDEX"`
+	Poster          *user_model.User       `xorm:"-"`
+	Title           string                 `xorm:"name"`
+	Content         string                 `xorm:"LONGTEXT"`
+	RenderedContent string                 `xorm:"-"`
+	NumComments     int                    `xorm:"NOT NULL DEFAULT 0"`
+	AnswerID        int64                  `xorm:"NOT NULL DEFAULT 0"` // the top level comment accepted as the answer
+	Answer          *Comment               `xorm:"-"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+}
+
+// Index represents the index of the discussions of a repository
+type Index db.ResourceIndex
+
+// TableName sets the name of this table
+func (Index) TableName() string {
+	return "discussion_index"
+}
+
+func init() {
+	db.RegisterModel(new(Discussion))
+	db.RegisterModel(new(Index))
+}
+
+// LoadAttributes loads the repository, category and poster of the discussion
+func (d *Discussion) LoadAttributes(ctx context.Context) error {
+	if d.Repo == nil {
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, d.RepoID)
+		if err != nil {
+			return err
+		}
+		d.Repo = repo
+	}
+	if d.Category == nil {
+		c, err := GetCategoryByID(ctx, d.RepoID, d.CategoryID)
+		if err != nil {
+			return err
+		}
+		d.Category = c
+	}
+	return d.LoadPoster(ctx)
+}
+
+// LoadPoster loads the poster of the discussion, it is the ghost user if the poster has been deleted
+func (d *Discussion) LoadPoster(ctx context.Context) error {
+	if d.Poster != nil {
+		return nil
+	}
+	u, err := user_model.GetUserByIDCtx(ctx, d.PosterID)
+	if user_model.IsErrUserNotExist(err) {
+		u = user_model.NewGhostUser()
+	} else if err != nil {
+		return err
+	}
+	d.Poster = u
+	return nil
+}
+
+// IsAnswered returns true if a comment has been accepted as the answer of the discussion
+func (d *Discussion) IsAnswered() bool {
+	return d.AnswerID != 0
+}
+
+// Link returns the link of the discussion, the repository has to be loaded
+func (d *Discussion) Link() string {
+	return fmt.Sprintf("%s/discussions/%d", d.Repo.Link(), d.Index)
+}
+
+// HTMLURL returns the absolute URL of the discussion, the repository has to be loaded
+func (d *Discussion) HTMLURL() string {
+	return fmt.Sprintf("%s/discussions/%d", d.Repo.HTMLURL(), d.Index)
+}
+
+// APIURL returns the URL of the discussion in the API, the repository has to be loaded
+func (d *Discussion) APIURL() string {
+	return fmt.Sprintf("%s/discussions/%d", d.Repo.APIURL(), d.Index)
+}
+
+// NewDiscussion creates a discussion in its category, the category has to belong to the repository
+func NewDiscussion(ctx context.Context, d *Discussion) error {
+	index, err := db.GetNextResourceIndex("discussion_index", d.RepoID)
+	if err != nil {
+		return err
+	}
+	d.Index = index
+
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if err := db.Insert(ctx, d); err != nil {
+		return err
+	}
+	if _, err := db.Exec(ctx, "UPDATE `discussion_category` SET num_discussions=num_discussions+1 WHERE id=?", d.CategoryID); err != nil {
+		return err
+	}
+	if _, err := db.Exec(ctx, "UPDATE `repository` SET num_discussions=num_discussions+1 WHERE id=?", d.RepoID); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// GetDiscussionByIndex returns the discussion of the repository with the index
+func GetDiscussionByIndex(ctx context.Context, repoID, index int64) (*Discussion, error) {
+	d := &Discussion{RepoID: repoID, Index: index}
+	has, err := db.GetEngine(ctx).Get(d)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDiscussionNotExist{RepoID: repoID, Index: index}
+	}
+	return d, nil
+}
+
+// GetDiscussionByID returns the discussion with the id
+func GetDiscussionByID(ctx context.Context, id int64) (*Discussion, error) {
+	d := new(Discussion)
+	has, err := db.GetEngine(ctx).ID(id).Get(d)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDiscussionNotExist{ID: id}
+	}
+	return d, nil
+}
+
+// UpdateDiscussion updates the title, content and category of a discussion
+func UpdateDiscussion(ctx context.Context, d *Discussion, oldCategoryID int64) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if _, err := db.GetEngine(ctx).ID(d.ID).Cols("name", "content", "category_id").Update(d); err != nil {
+		return err
+	}
+	if oldCategoryID != d.CategoryID {
+		if _, err := db.Exec(ctx, "UPDATE `discussion_category` SET num_discussions=num_discussions-1 WHERE id=?", oldCategoryID); err != nil {
+			return err
+		}
+		if _, err := db.Exec(ctx, "UPDATE `discussion_category` SET num_discussions=num_discussions+1 WHERE id=?", d.CategoryID); err != nil {
+			return err
+		}
+	}
+	return committer.Commit()
+}
+
+// DeleteDiscussion deletes a discussion with its comments
+func DeleteDiscussion(ctx context.Context, d *Discussion) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if _, err := db.GetEngine(ctx).Where("discussion_id = ?", d.ID).Delete(&Comment{}); err != nil {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).ID(d.ID).Delete(&Discussion{}); err != nil {
+		return err
+	}
+	if _, err := db.Exec(ctx, "UPDATE `discussion_category` SET num_discussions=num_discussions-1 WHERE id=?", d.CategoryID); err != nil {
+		return err
+	}
+	if _, err := db.Exec(ctx, "UPDATE `repository` SET num_discussions=num_discussions-1 WHERE id=?", d.RepoID); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// DeleteRepoDiscussions deletes the discussions, comments and categories of a deleted repository
+func DeleteRepoDiscussions(ctx context.Context, repoID int64) error {
+	if _, err := db.GetEngine(ctx).
+		Where(builder.In("discussion_id", builder.Select("id").From("discussion").Where(builder.Eq{"repo_id": repoID}))).
+		Delete(&Comment{}); err != nil {
+		return err
+	}
+	if err := db.DeleteBeans(ctx,
+		&Discussion{RepoID: repoID},
+		&Category{RepoID: repoID},
+	); err != nil {
+		return err
+	}
+	return db.DeleteResouceIndex(ctx, "discussion_index", repoID)
+}
+
+// FindDiscussionOptions represents the options to find the discussions of a repository
+type FindDiscussionOptions struct {
+	db.ListOptions
+	RepoID     int64
+	CategoryID int64
+	PosterID   int64
+	IDs        []int64
+	IsAnswered util.OptionalBool
+}
+
+func (opts *FindDiscussionOptions) toConds() builder.Cond {
+	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
+	if opts.CategoryID > 0 {
+		cond = cond.And(builder.Eq{"category_id": opts.CategoryID})
+	}
+	if opts.PosterID > 0 {
+		cond = cond.And(builder.Eq{"poster_id": opts.PosterID})
+	}
+	if opts.IDs != nil {
+		cond = cond.And(builder.In("id", opts.IDs))
+	}
+	if opts.IsAnswered.IsTrue() {
+		cond = cond.And(builder.Neq{"answer_id": 0})
+	} else if opts.IsAnswered.IsFalse() {
+		cond = cond.And(builder.Eq{"answer_id": 0})
+	}
+	return cond
+}
+
+// FindDiscussions returns the discussions matching the options, the ones with the latest activity first
+func FindDiscussions(ctx context.Context, opts *FindDiscussionOptions) ([]*Discussion, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).Desc("updated_unix", "id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	discussions := make([]*Discussion, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&discussions)
+	return discussions, count, err
+}
+
+// SearchDiscussionIDsByKeyword returns the ids of the discussions of the repositories
+// whose title, content or comments contain the keyword
+func SearchDiscussionIDsByKeyword(ctx context.Context, kw string, repoIDs []int64, limit, start int) (int64, []int64, error) {
+	// SQLite's UPPER function only transforms ASCII letters.
+	if setting.Database.UseSQLite3 {
+		kw = util.ToUpperASCII(kw)
+	} else {
+		kw = strings.ToUpper(kw)
+	}
+	repoCond := builder.In("repo_id", repoIDs)
+	cond := builder.And(
+		repoCond,
+		builder.Or(
+			builder.Like{"UPPER(name)", kw},
+			builder.Like{"UPPER(content)", kw},
+			builder.In("id", builder.Select("discussion_id").
+				From("discussion_comment").
+				Where(builder.And(
+					builder.In("discussion_id", builder.Select("id").From("discussion").Where(repoCond)),
+					builder.Like{"UPPER(content)", kw},
+				)),
+			),
+		),
+	)
+
+	res := make([]struct {
+		ID          int64
+		UpdatedUnix int64
+	}, 0, limit)
+	if err := db.GetEngine(ctx).Distinct("id", "updated_unix").Table("discussion").Where(cond).
+		OrderBy("`updated_unix` DESC").Limit(limit, start).
+		Find(&res); err != nil {
+		return 0, nil, err
+	}
+	ids := make([]int64, 0, len(res))
+	for _, r := range res {
+		ids = append(ids, r.ID)
+	}
+
+	total, err := db.GetEngine(ctx).Distinct("id").Table("discussion").Where(cond).Count()
+	if err != nil {
+		return 0, nil, err
+	}
+	return total, ids, nil
+}
+
+// GetParticipantIDs returns the ids of the poster and the commenters of a discussion
+func GetParticipantIDs(ctx context.Context, d *Discussion) ([]int64, error) {
+	ids := make([]int64, 0, 10)
+	if err := db.GetEngine(ctx).Table("discussion_comment").
+		Where("discussion_id = ?", d.ID).
+		Distinct("poster_id").
+		Find(&ids); err != nil {
+		return nil, err
+	}
+	for _, id := range ids {
+		if id == d.PosterID {
+			return ids, nil
+		}
+	}
+	return append(ids, d.PosterID), nil
+}
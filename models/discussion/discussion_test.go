@@ -0,0 +1,80 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscussion(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	general := &Category{RepoID: 1, Name: "General"}
+	assert.NoError(t, NewCategory(db.DefaultContext, general))
+	qa := &Category{RepoID: 1, Name: "Q&A", IsAnswerable: true}
+	assert.NoError(t, NewCategory(db.DefaultContext, qa))
+
+	d := &Discussion{RepoID: 1, CategoryID: general.ID, PosterID: 2, Title: "How to build", Content: "Which go version?"}
+	assert.NoError(t, NewDiscussion(db.DefaultContext, d))
+	assert.EqualValues(t, 1, d.Index)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1, NumDiscussions: 1})
+	unittest.AssertExistsAndLoadBean(t, &Category{ID: general.ID, NumDiscussions: 1})
+
+	comment := &Comment{DiscussionID: d.ID, PosterID: 1, Content: "Go 1.17"}
+	assert.NoError(t, CreateComment(db.DefaultContext, comment))
+	reply := &Comment{DiscussionID: d.ID, ParentID: comment.ID, PosterID: 2, Content: "Thanks"}
+	assert.NoError(t, CreateComment(db.DefaultContext, reply))
+	// replies to replies are added to the top level comment
+	nested := &Comment{DiscussionID: d.ID, ParentID: reply.ID, PosterID: 1, Content: "You're welcome"}
+	assert.NoError(t, CreateComment(db.DefaultContext, nested))
+	assert.Equal(t, comment.ID, nested.ParentID)
+
+	comments, err := GetComments(db.DefaultContext, d.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, comments, 1) {
+		assert.Len(t, comments[0].Replies, 2)
+	}
+
+	// only comments of discussions in answerable categories can be answers
+	assert.True(t, IsErrInvalidAnswer(MarkAnswer(db.DefaultContext, d, comment.ID)))
+	d.CategoryID = qa.ID
+	assert.NoError(t, UpdateDiscussion(db.DefaultContext, d, general.ID))
+	unittest.AssertExistsAndLoadBean(t, &Category{ID: general.ID, NumDiscussions: 0})
+	unittest.AssertExistsAndLoadBean(t, &Category{ID: qa.ID, NumDiscussions: 1})
+	assert.True(t, IsErrInvalidAnswer(MarkAnswer(db.DefaultContext, d, reply.ID)))
+	assert.NoError(t, MarkAnswer(db.DefaultContext, d, comment.ID))
+	assert.True(t, d.IsAnswered())
+
+	d, err = GetDiscussionByIndex(db.DefaultContext, 1, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, comment.ID, d.AnswerID)
+	assert.EqualValues(t, 3, d.NumComments)
+
+	answered, _, err := FindDiscussions(db.DefaultContext, &FindDiscussionOptions{RepoID: 1, IsAnswered: util.OptionalBoolTrue})
+	assert.NoError(t, err)
+	assert.Len(t, answered, 1)
+
+	// deleting the answer deletes its replies and unmarks it
+	assert.NoError(t, DeleteComment(db.DefaultContext, d, comment))
+	d, err = GetDiscussionByIndex(db.DefaultContext, 1, 1)
+	assert.NoError(t, err)
+	assert.False(t, d.IsAnswered())
+	assert.EqualValues(t, 0, d.NumComments)
+
+	assert.True(t, IsErrCategoryNotEmpty(DeleteCategory(db.DefaultContext, 1, qa.ID)))
+	assert.NoError(t, DeleteDiscussion(db.DefaultContext, d))
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1, NumDiscussions: 0})
+	assert.NoError(t, DeleteCategory(db.DefaultContext, 1, qa.ID))
+
+	_, err = GetDiscussionByIndex(db.DefaultContext, 1, 1)
+	assert.True(t, IsErrDiscussionNotExist(err))
+}
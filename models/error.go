@@ -483,6 +483,36 @@ func (err ErrFilePathProtected) Error() string {
 	return fmt.Sprintf("path is protected and can not be changed [path: %s]", err.Path)
 }
 
+// ErrFilePathLocked represents a "FilePathLocked" kind of error.
+type ErrFilePathLocked struct {
+	Path string
+}
+
+// IsErrFilePathLocked checks if an error is an ErrFilePathLocked.
+func IsErrFilePathLocked(err error) bool {
+	_, ok := err.(ErrFilePathLocked)
+	return ok
+}
+
+func (err ErrFilePathLocked) Error() string {
+	return fmt.Sprintf("path is locked and can not be changed by the editor [path: %s]", err.Path)
+}
+
+// ErrFilePathRequiresPullRequest represents a "FilePathRequiresPullRequest" kind of error.
+type ErrFilePathRequiresPullRequest struct {
+	Path string
+}
+
+// IsErrFilePathRequiresPullRequest checks if an error is an ErrFilePathRequiresPullRequest.
+func IsErrFilePathRequiresPullRequest(err error) bool {
+	_, ok := err.(ErrFilePathRequiresPullRequest)
+	return ok
+}
+
+func (err ErrFilePathRequiresPullRequest) Error() string {
+	return fmt.Sprintf("path can only be changed on a new branch for a pull request [path: %s]", err.Path)
+}
+
 // ErrUserDoesNotHaveAccessToRepo represets an error where the user doesn't has access to a given repo.
 type ErrUserDoesNotHaveAccessToRepo struct {
 	UserID   int64
@@ -658,6 +688,26 @@ func (err ErrSHAOrCommitIDNotProvided) Error() string {
 	return "a SHA or commit ID must be proved when updating a file"
 }
 
+// ErrCherryPickConflict represents a "CherryPickConflict" kind of error.
+type ErrCherryPickConflict struct {
+	CommitID string
+	Branch   string
+	Revert   bool
+}
+
+// IsErrCherryPickConflict checks if an error is a ErrCherryPickConflict.
+func IsErrCherryPickConflict(err error) bool {
+	_, ok := err.(ErrCherryPickConflict)
+	return ok
+}
+
+func (err ErrCherryPickConflict) Error() string {
+	if err.Revert {
+		return fmt.Sprintf("commit %s can't be reverted on %s without conflicts", err.CommitID, err.Branch)
+	}
+	return fmt.Sprintf("commit %s can't be cherry-picked onto %s without conflicts", err.CommitID, err.Branch)
+}
+
 // .___
 // |   | ______ ________ __   ____
 // |   |/  ___//  ___/  |  \_/ __ \
@@ -1105,7 +1155,23 @@ func IsErrCircularDependency(err error) bool {
 }
 
 func (err ErrCircularDependency) Error() string {
-	return fmt.Sprintf("circular dependencies exists (two issues blocking each other) [issue id: %d, dependency id: %d]", err.IssueID, err.DependencyID)
+	return fmt.Sprintf("circular dependencies exists (issues blocking each other) [issue id: %d, dependency id: %d]", err.IssueID, err.DependencyID)
+}
+
+// ErrDependencyGraphTooLarge represents an error where the dependencies of an issue are too many to be walked
+type ErrDependencyGraphTooLarge struct {
+	IssueID      int64
+	DependencyID int64
+}
+
+// IsErrDependencyGraphTooLarge checks if an error is a ErrDependencyGraphTooLarge.
+func IsErrDependencyGraphTooLarge(err error) bool {
+	_, ok := err.(ErrDependencyGraphTooLarge)
+	return ok
+}
+
+func (err ErrDependencyGraphTooLarge) Error() string {
+	return fmt.Sprintf("dependency graph is too large to check for circular dependencies [issue id: %d, dependency id: %d]", err.IssueID, err.DependencyID)
 }
 
 // ErrDependenciesLeft represents an error where the issue you're trying to close still has dependencies left.
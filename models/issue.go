@@ -29,7 +29,6 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/references"
 	"code.gitea.io/gitea/modules/setting"
-	"code.gitea.io/gitea/modules/storage"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
@@ -2047,7 +2046,7 @@ func deleteIssue(ctx context.Context, issue *Issue) error {
 	}
 
 	for i := range attachments {
-		admin_model.RemoveStorageWithNotice(ctx, storage.Attachments, "Delete issue attachment", attachments[i].RelativePath())
+		admin_model.RemoveStorageWithNotice(ctx, attachments[i].Storage(), "Delete issue attachment", attachments[i].RelativePath())
 	}
 
 	// delete all database data still assigned to this issue
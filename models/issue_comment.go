@@ -304,6 +304,9 @@ func (c *Comment) LoadIssueCtx(ctx context.Context) (err error) {
 
 // BeforeInsert will be invoked by XORM before inserting a record
 func (c *Comment) BeforeInsert() {
+	if c.ID == 0 {
+		c.ID = db.NextID()
+	}
 	c.PatchQuoted = c.Patch
 	if !utf8.ValidString(c.Patch) {
 		c.PatchQuoted = strconv.Quote(c.Patch)
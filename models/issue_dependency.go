@@ -6,10 +6,13 @@ package models
 
 import (
 	"context"
+	"sort"
 
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
 )
 
 // IssueDependency represents an issue dependency
@@ -51,9 +54,12 @@ func CreateIssueDependency(user *user_model.User, issue, dep *Issue) error {
 	if exists {
 		return ErrDependencyExists{issue.ID, dep.ID}
 	}
-	// And if it would be circular
-	circular, err := issueDepExists(ctx, dep.ID, issue.ID)
+	// And if it would be circular, either directly or through other dependencies
+	circular, err := issueDepPathExists(ctx, dep.ID, issue.ID)
 	if err != nil {
+		if IsErrDependencyGraphTooLarge(err) {
+			return ErrDependencyGraphTooLarge{issue.ID, dep.ID}
+		}
 		return err
 	}
 	if circular {
@@ -117,15 +123,227 @@ func issueDepExists(ctx context.Context, issueID, depID int64) (bool, error) {
 	return db.GetEngine(ctx).Where("(issue_id = ? AND dependency_id = ?)", issueID, depID).Exist(&IssueDependency{})
 }
 
-// IssueNoDependenciesLeft checks if issue can be closed
+// MaxIssueDependencyGraphIssues is the number of issues at which walking the dependencies of an issue stops
+const MaxIssueDependencyGraphIssues = 1000
+
+// issueDepPathExists checks if the issue is blocked by the dependency, directly or transitively.
+// Dependency chains reaching more than MaxIssueDependencyGraphIssues issues are not walked to their end,
+// ErrDependencyGraphTooLarge is returned for them instead.
+func issueDepPathExists(ctx context.Context, issueID, depID int64) (bool, error) {
+	visited := map[int64]bool{issueID: true}
+	frontier := []int64{issueID}
+	for len(frontier) > 0 {
+		deps := make([]*IssueDependency, 0, len(frontier))
+		if err := db.GetEngine(ctx).In("issue_id", frontier).Find(&deps); err != nil {
+			return false, err
+		}
+		frontier = frontier[:0]
+		for _, d := range deps {
+			if d.DependencyID == depID {
+				return true, nil
+			}
+			if !visited[d.DependencyID] {
+				if len(visited) >= MaxIssueDependencyGraphIssues {
+					return false, ErrDependencyGraphTooLarge{issueID, depID}
+				}
+				visited[d.DependencyID] = true
+				frontier = append(frontier, d.DependencyID)
+			}
+		}
+	}
+	return false, nil
+}
+
+// IssueDependencyGraph represents all dependency relationships reachable from an issue
+type IssueDependencyGraph struct {
+	Issue  *Issue
+	Issues map[int64]*Issue
+	Edges  []*IssueDependency
+	// BlockedBy contains the ids of all issues the issue is transitively blocked by
+	BlockedBy []int64
+	// Blocking contains the ids of all issues transitively blocked by the issue
+	Blocking []int64
+	// Cycles contains every dependency cycle found in the graph as a list of issue ids
+	Cycles [][]int64
+	// Truncated is set if the graph was cut off at MaxIssueDependencyGraphIssues issues
+	Truncated bool
+}
+
+// GetIssueDependencyGraph collects all issues connected to the given issue through dependencies
+// in either direction, together with the transitive closure and any dependency cycles.
+// Issues for which canSee returns false are left out before walking on, so neither the graph nor
+// its closure contain anything reached only through them.
+func GetIssueDependencyGraph(ctx context.Context, issue *Issue, canSee func(*Issue) (bool, error)) (*IssueDependencyGraph, error) {
+	graph := &IssueDependencyGraph{
+		Issue:  issue,
+		Issues: map[int64]*Issue{issue.ID: issue},
+		Edges:  make([]*IssueDependency, 0, 10),
+	}
+	hidden := make(map[int64]bool)
+	seenEdges := make(map[int64]bool)
+	frontier := []int64{issue.ID}
+	for len(frontier) > 0 {
+		deps := make([]*IssueDependency, 0, len(frontier))
+		if err := db.GetEngine(ctx).
+			Where(builder.In("issue_id", frontier).Or(builder.In("dependency_id", frontier))).
+			OrderBy("id").
+			Find(&deps); err != nil {
+			return nil, err
+		}
+
+		reached := make([]int64, 0, len(deps))
+		isReached := make(map[int64]bool, len(deps))
+		for _, d := range deps {
+			for _, id := range []int64{d.IssueID, d.DependencyID} {
+				if graph.Issues[id] == nil && !hidden[id] && !isReached[id] {
+					isReached[id] = true
+					reached = append(reached, id)
+				}
+			}
+		}
+		issues, err := GetIssuesByIDs(ctx, reached)
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(issues, func(i, j int) bool { return issues[i].ID < issues[j].ID })
+
+		frontier = frontier[:0]
+		for _, i := range issues {
+			if len(graph.Issues) >= MaxIssueDependencyGraphIssues {
+				graph.Truncated = true
+				break
+			}
+			visible, err := canSee(i)
+			if err != nil {
+				return nil, err
+			}
+			if !visible {
+				hidden[i.ID] = true
+				continue
+			}
+			graph.Issues[i.ID] = i
+			frontier = append(frontier, i.ID)
+		}
+		if graph.Truncated {
+			frontier = frontier[:0]
+		}
+
+		for _, d := range deps {
+			if !seenEdges[d.ID] && graph.Issues[d.IssueID] != nil && graph.Issues[d.DependencyID] != nil {
+				seenEdges[d.ID] = true
+				graph.Edges = append(graph.Edges, d)
+			}
+		}
+	}
+
+	issueIDs := make([]int64, 0, len(graph.Issues))
+	for id := range graph.Issues {
+		issueIDs = append(issueIDs, id)
+	}
+
+	blockedBy := make(map[int64][]int64)
+	blocking := make(map[int64][]int64)
+	for _, e := range graph.Edges {
+		blockedBy[e.IssueID] = append(blockedBy[e.IssueID], e.DependencyID)
+		blocking[e.DependencyID] = append(blocking[e.DependencyID], e.IssueID)
+	}
+	graph.BlockedBy = reachableIssueIDs(issue.ID, blockedBy)
+	graph.Blocking = reachableIssueIDs(issue.ID, blocking)
+	graph.Cycles = findDependencyCycles(issueIDs, blockedBy)
+
+	return graph, nil
+}
+
+// reachableIssueIDs returns the ids of all issues reachable from the start issue, in breadth first order
+func reachableIssueIDs(start int64, adjacency map[int64][]int64) []int64 {
+	result := make([]int64, 0, len(adjacency))
+	visited := map[int64]bool{start: true}
+	queue := []int64{start}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		for _, next := range adjacency[current] {
+			if !visited[next] {
+				visited[next] = true
+				result = append(result, next)
+				queue = append(queue, next)
+			}
+		}
+	}
+	return result
+}
+
+// findDependencyCycles returns each cycle closed by a back edge of a depth first search over the dependencies
+func findDependencyCycles(issueIDs []int64, adjacency map[int64][]int64) [][]int64 {
+	sort.Slice(issueIDs, func(i, j int) bool { return issueIDs[i] < issueIDs[j] })
+
+	const (
+		unvisited = iota
+		inStack
+		done
+	)
+	state := make(map[int64]int, len(issueIDs))
+	stack := make([]int64, 0, len(issueIDs))
+	var cycles [][]int64
+
+	var visit func(id int64)
+	visit = func(id int64) {
+		state[id] = inStack
+		stack = append(stack, id)
+		for _, next := range adjacency[id] {
+			switch state[next] {
+			case unvisited:
+				visit(next)
+			case inStack:
+				for i := len(stack) - 1; i >= 0; i-- {
+					if stack[i] == next {
+						cycles = append(cycles, append([]int64{}, stack[i:]...))
+						break
+					}
+				}
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[id] = done
+	}
+
+	for _, id := range issueIDs {
+		if state[id] == unvisited {
+			visit(id)
+		}
+	}
+	return cycles
+}
+
+// IssueNoDependenciesLeft checks if issue can be closed, i.e. that none of the issues it is
+// blocked by, directly or transitively, is still open.
+// Only the first MaxIssueDependencyGraphIssues issues of the dependency chains are checked.
 func IssueNoDependenciesLeft(ctx context.Context, issue *Issue) (bool, error) {
-	exists, err := db.GetEngine(ctx).
-		Table("issue_dependency").
-		Select("issue.*").
-		Join("INNER", "issue", "issue.id = issue_dependency.dependency_id").
-		Where("issue_dependency.issue_id = ?", issue.ID).
-		And("issue.is_closed = ?", "0").
-		Exist(&Issue{})
-
-	return !exists, err
+	visited := map[int64]bool{issue.ID: true}
+	frontier := []int64{issue.ID}
+	for len(frontier) > 0 {
+		deps := make([]*IssueDependency, 0, len(frontier))
+		if err := db.GetEngine(ctx).In("issue_id", frontier).Find(&deps); err != nil {
+			return false, err
+		}
+		frontier = frontier[:0]
+		for _, d := range deps {
+			if !visited[d.DependencyID] && len(visited) < MaxIssueDependencyGraphIssues {
+				visited[d.DependencyID] = true
+				frontier = append(frontier, d.DependencyID)
+			}
+		}
+		if len(frontier) == 0 {
+			break
+		}
+
+		exists, err := db.GetEngine(ctx).
+			In("id", frontier).
+			And("is_closed = ?", false).
+			Exist(&Issue{})
+		if err != nil || exists {
+			return !exists, err
+		}
+	}
+	return true, nil
 }
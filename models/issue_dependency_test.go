@@ -60,3 +60,84 @@ func TestCreateIssueDependency(t *testing.T) {
 	err = RemoveIssueDependency(user1, issue1, issue2, DependencyTypeBlockedBy)
 	assert.NoError(t, err)
 }
+
+func TestIssueDependencyGraph(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	user1, err := user_model.GetUserByID(1)
+	assert.NoError(t, err)
+	issue1, err := GetIssueByID(1)
+	assert.NoError(t, err)
+	issue2, err := GetIssueByID(2)
+	assert.NoError(t, err)
+	issue3, err := GetIssueByID(3)
+	assert.NoError(t, err)
+
+	// #1 is blocked by #2 which is blocked by #3
+	assert.NoError(t, CreateIssueDependency(user1, issue1, issue2))
+	assert.NoError(t, CreateIssueDependency(user1, issue2, issue3))
+
+	// Transitive cycles must be detected too
+	err = CreateIssueDependency(user1, issue3, issue1)
+	assert.Error(t, err)
+	assert.True(t, IsErrCircularDependency(err))
+
+	graph, err := GetIssueDependencyGraph(db.DefaultContext, issue1, canSeeAll)
+	assert.NoError(t, err)
+	assert.Len(t, graph.Issues, 3)
+	assert.Len(t, graph.Edges, 2)
+	assert.EqualValues(t, []int64{2, 3}, graph.BlockedBy)
+	assert.Empty(t, graph.Blocking)
+	assert.Empty(t, graph.Cycles)
+
+	graph, err = GetIssueDependencyGraph(db.DefaultContext, issue3, canSeeAll)
+	assert.NoError(t, err)
+	assert.Empty(t, graph.BlockedBy)
+	assert.EqualValues(t, []int64{2, 1}, graph.Blocking)
+
+	// Cycles which already exist in the database are reported
+	assert.NoError(t, db.Insert(db.DefaultContext, &IssueDependency{UserID: user1.ID, IssueID: issue3.ID, DependencyID: issue1.ID}))
+	graph, err = GetIssueDependencyGraph(db.DefaultContext, issue2, canSeeAll)
+	assert.NoError(t, err)
+	assert.EqualValues(t, [][]int64{{1, 2, 3}}, graph.Cycles)
+
+	// Nothing is reached through hidden issues
+	graph, err = GetIssueDependencyGraph(db.DefaultContext, issue1, func(issue *Issue) (bool, error) {
+		return issue.ID != issue2.ID, nil
+	})
+	assert.NoError(t, err)
+	assert.Len(t, graph.Issues, 2)
+	assert.Len(t, graph.Edges, 1)
+	assert.Empty(t, graph.BlockedBy)
+	assert.EqualValues(t, []int64{3}, graph.Blocking)
+	assert.Empty(t, graph.Cycles)
+	assert.False(t, graph.Truncated)
+}
+
+func TestIssueNoDependenciesLeftTransitive(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// #1 is blocked by the closed #4 which is blocked by the open #2
+	assert.NoError(t, db.Insert(db.DefaultContext, &IssueDependency{UserID: 1, IssueID: 1, DependencyID: 4}))
+	assert.NoError(t, db.Insert(db.DefaultContext, &IssueDependency{UserID: 1, IssueID: 4, DependencyID: 2}))
+
+	issue1, err := GetIssueByID(1)
+	assert.NoError(t, err)
+	left, err := IssueNoDependenciesLeft(db.DefaultContext, issue1)
+	assert.NoError(t, err)
+	assert.False(t, left)
+
+	user1, err := user_model.GetUserByID(1)
+	assert.NoError(t, err)
+	issue2, err := GetIssueByID(2)
+	assert.NoError(t, err)
+	_, err = ChangeIssueStatus(db.DefaultContext, issue2, user1, true)
+	assert.NoError(t, err)
+	left, err = IssueNoDependenciesLeft(db.DefaultContext, issue1)
+	assert.NoError(t, err)
+	assert.True(t, left)
+}
+
+func canSeeAll(*Issue) (bool, error) {
+	return true, nil
+}
@@ -0,0 +1,173 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+)
+
+// ErrIssueIntakeRuleNotExist represents a "IssueIntakeRuleNotExist" kind of error.
+type ErrIssueIntakeRuleNotExist struct {
+	ID    int64
+	OrgID int64
+}
+
+// IsErrIssueIntakeRuleNotExist checks if an error is a ErrIssueIntakeRuleNotExist.
+func IsErrIssueIntakeRuleNotExist(err error) bool {
+	_, ok := err.(ErrIssueIntakeRuleNotExist)
+	return ok
+}
+
+func (err ErrIssueIntakeRuleNotExist) Error() string {
+	return fmt.Sprintf("issue intake rule does not exist [id: %d, org_id: %d]", err.ID, err.OrgID)
+}
+
+// ErrInvalidIssueIntakeRule represents a "InvalidIssueIntakeRule" kind of error.
+type ErrInvalidIssueIntakeRule struct {
+	Reason string
+}
+
+// IsErrInvalidIssueIntakeRule checks if an error is a ErrInvalidIssueIntakeRule.
+func IsErrInvalidIssueIntakeRule(err error) bool {
+	_, ok := err.(ErrInvalidIssueIntakeRule)
+	return ok
+}
+
+func (err ErrInvalidIssueIntakeRule) Error() string {
+	return fmt.Sprintf("invalid issue intake rule: %s", err.Reason)
+}
+
+// IssueIntakeRule routes new issues of the repositories of an organization into a project column
+// and assigns them to the members of a triage team.
+// Projects belong to repositories, so the project is looked up by its title in the repository of the issue.
+type IssueIntakeRule struct {
+	ID    int64  `xorm:"pk autoincr"`
+	OrgID int64  `xorm:"INDEX NOT NULL"`
+	Name  string `xorm:"NOT NULL"`
+
+	// RepoPattern is a glob matched against repository names, an empty pattern matches all repositories
+	RepoPattern string
+	// TemplateFile is the file name of the issue template the issue has to be created from, empty matches all issues
+	TemplateFile string
+	// Labels are the names of labels the issue has to have, all of them
+	Labels []string `xorm:"JSON TEXT"`
+
+	// ProjectTitle is the title of the open project the issue is added to
+	ProjectTitle string
+	// BoardTitle is the title of the project column the issue is put in, empty means the default column
+	BoardTitle string
+	// TeamID is the team whose members are assigned to the issue
+	TeamID int64
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(IssueIntakeRule))
+}
+
+// Match returns true if a new issue with the given labels, created from the template file in the repository, is routed by the rule
+func (rule *IssueIntakeRule) Match(repoName, templateFile string, labelNames []string) bool {
+	if rule.RepoPattern != "" {
+		g, err := glob.Compile(rule.RepoPattern)
+		if err != nil || !g.Match(repoName) {
+			return false
+		}
+	}
+	if rule.TemplateFile != "" && rule.TemplateFile != templateFile {
+		return false
+	}
+	for _, label := range rule.Labels {
+		found := false
+		for _, name := range labelNames {
+			if strings.EqualFold(label, name) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (rule *IssueIntakeRule) validate() error {
+	rule.Name = strings.TrimSpace(rule.Name)
+	rule.RepoPattern = strings.TrimSpace(rule.RepoPattern)
+	rule.TemplateFile = strings.TrimSpace(rule.TemplateFile)
+	rule.ProjectTitle = strings.TrimSpace(rule.ProjectTitle)
+	rule.BoardTitle = strings.TrimSpace(rule.BoardTitle)
+
+	if rule.Name == "" {
+		return ErrInvalidIssueIntakeRule{Reason: "name is required"}
+	}
+	if rule.RepoPattern != "" {
+		if _, err := glob.Compile(rule.RepoPattern); err != nil {
+			return ErrInvalidIssueIntakeRule{Reason: fmt.Sprintf("invalid repository pattern %q", rule.RepoPattern)}
+		}
+	}
+	if rule.ProjectTitle == "" && rule.BoardTitle != "" {
+		return ErrInvalidIssueIntakeRule{Reason: "a project column needs a project"}
+	}
+	if rule.ProjectTitle == "" && rule.TeamID == 0 {
+		return ErrInvalidIssueIntakeRule{Reason: "a project or a team is required"}
+	}
+	return nil
+}
+
+// CreateIssueIntakeRule saves a new issue intake rule of an organization
+func CreateIssueIntakeRule(ctx context.Context, rule *IssueIntakeRule) error {
+	if err := rule.validate(); err != nil {
+		return err
+	}
+	return db.Insert(ctx, rule)
+}
+
+// GetIssueIntakeRuleByID returns an issue intake rule of an organization by id
+func GetIssueIntakeRuleByID(ctx context.Context, orgID, id int64) (*IssueIntakeRule, error) {
+	rule := new(IssueIntakeRule)
+	has, err := db.GetEngine(ctx).Where("id = ? AND org_id = ?", id, orgID).Get(rule)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueIntakeRuleNotExist{ID: id, OrgID: orgID}
+	}
+	return rule, nil
+}
+
+// GetIssueIntakeRules returns all issue intake rules of an organization in the order they are tried
+func GetIssueIntakeRules(ctx context.Context, orgID int64) ([]*IssueIntakeRule, error) {
+	rules := make([]*IssueIntakeRule, 0, 5)
+	return rules, db.GetEngine(ctx).Where("org_id = ?", orgID).Asc("id").Find(&rules)
+}
+
+// UpdateIssueIntakeRule updates an issue intake rule of an organization
+func UpdateIssueIntakeRule(ctx context.Context, rule *IssueIntakeRule) error {
+	if err := rule.validate(); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).ID(rule.ID).AllCols().Update(rule)
+	return err
+}
+
+// DeleteIssueIntakeRule deletes an issue intake rule of an organization
+func DeleteIssueIntakeRule(ctx context.Context, orgID, id int64) error {
+	affected, err := db.GetEngine(ctx).Where("id = ? AND org_id = ?", id, orgID).Delete(new(IssueIntakeRule))
+	if err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrIssueIntakeRuleNotExist{ID: id, OrgID: orgID}
+	}
+	return nil
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueIntakeRule_Match(t *testing.T) {
+	rule := &IssueIntakeRule{RepoPattern: "api-*", TemplateFile: "bug.md", Labels: []string{"bug", "Needs Triage"}}
+	assert.True(t, rule.Match("api-server", "bug.md", []string{"needs triage", "bug", "ui"}))
+	assert.False(t, rule.Match("web", "bug.md", []string{"bug", "needs triage"}))
+	assert.False(t, rule.Match("api-server", "feature.md", []string{"bug", "needs triage"}))
+	assert.False(t, rule.Match("api-server", "bug.md", []string{"bug"}))
+
+	rule = &IssueIntakeRule{}
+	assert.True(t, rule.Match("web", "", nil))
+}
+
+func TestIssueIntakeRules(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	err := CreateIssueIntakeRule(db.DefaultContext, &IssueIntakeRule{OrgID: 3, Name: "bugs", RepoPattern: "[repo"})
+	assert.True(t, IsErrInvalidIssueIntakeRule(err))
+	err = CreateIssueIntakeRule(db.DefaultContext, &IssueIntakeRule{OrgID: 3, Name: "bugs"})
+	assert.True(t, IsErrInvalidIssueIntakeRule(err))
+	err = CreateIssueIntakeRule(db.DefaultContext, &IssueIntakeRule{OrgID: 3, Name: "bugs", BoardTitle: "Triage", TeamID: 1})
+	assert.True(t, IsErrInvalidIssueIntakeRule(err))
+
+	rule := &IssueIntakeRule{OrgID: 3, Name: " bugs ", Labels: []string{"bug"}, ProjectTitle: "Bugs", BoardTitle: "Triage"}
+	assert.NoError(t, CreateIssueIntakeRule(db.DefaultContext, rule))
+	assert.EqualValues(t, "bugs", rule.Name)
+	assert.NoError(t, CreateIssueIntakeRule(db.DefaultContext, &IssueIntakeRule{OrgID: 3, Name: "triage", TeamID: 1}))
+	assert.NoError(t, CreateIssueIntakeRule(db.DefaultContext, &IssueIntakeRule{OrgID: 6, Name: "triage", TeamID: 2}))
+
+	rules, err := GetIssueIntakeRules(db.DefaultContext, 3)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 2) {
+		assert.EqualValues(t, rule.ID, rules[0].ID)
+		assert.EqualValues(t, []string{"bug"}, rules[0].Labels)
+	}
+
+	rule.TemplateFile = "bug.md"
+	assert.NoError(t, UpdateIssueIntakeRule(db.DefaultContext, rule))
+	rule, err = GetIssueIntakeRuleByID(db.DefaultContext, 3, rule.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "bug.md", rule.TemplateFile)
+
+	_, err = GetIssueIntakeRuleByID(db.DefaultContext, 6, rule.ID)
+	assert.True(t, IsErrIssueIntakeRuleNotExist(err))
+	assert.True(t, IsErrIssueIntakeRuleNotExist(DeleteIssueIntakeRule(db.DefaultContext, 6, rule.ID)))
+	assert.NoError(t, DeleteIssueIntakeRule(db.DefaultContext, 3, rule.ID))
+	unittest.AssertNotExistsBean(t, &IssueIntakeRule{ID: rule.ID})
+}
@@ -11,6 +11,7 @@ import (
 	"code.gitea.io/gitea/models/avatars"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"xorm.io/builder"
@@ -51,9 +52,10 @@ func SaveIssueContentHistory(ctx context.Context, posterID, issueID, commentID i
 		log.Error("can not save issue content history. err=%v", err)
 		return err
 	}
-	// We only keep at most 20 history revisions now. It is enough in most cases.
-	// If there is a special requirement to keep more, we can consider introducing a new setting option then, but not now.
-	keepLimitedContentHistory(ctx, issueID, commentID, 20)
+	// keep the full history unless the number of history revisions is limited
+	if setting.Repository.Issue.MaxContentHistoryRevisions > 0 {
+		keepLimitedContentHistory(ctx, issueID, commentID, setting.Repository.Issue.MaxContentHistoryRevisions)
+	}
 	return nil
 }
 
@@ -179,6 +181,20 @@ func HasIssueContentHistory(dbCtx context.Context, issueID, commentID int64) (bo
 	return exists, err
 }
 
+// FindIssueContentHistories returns all history revisions of an issue (comment_id = 0 means the main issue) or a comment, newest first
+func FindIssueContentHistories(dbCtx context.Context, issueID, commentID int64) ([]*ContentHistory, error) {
+	res := make([]*ContentHistory, 0)
+	err := db.GetEngine(dbCtx).
+		Where(builder.Eq{"issue_id": issueID, "comment_id": commentID}).
+		OrderBy("edited_unix DESC, id DESC").
+		Find(&res)
+	if err != nil {
+		log.Error("can not find issue content histories. err=%v", err)
+		return nil, err
+	}
+	return res, nil
+}
+
 // SoftDeleteIssueContentHistory soft delete
 func SoftDeleteIssueContentHistory(dbCtx context.Context, historyID int64) error {
 	if _, err := db.GetEngine(dbCtx).ID(historyID).Cols("is_deleted", "content_text").Update(&ContentHistory{
@@ -191,6 +207,24 @@ func SoftDeleteIssueContentHistory(dbCtx context.Context, historyID int64) error
 	return nil
 }
 
+// PurgeIssueContentHistory hard deletes all history revisions of an issue (comment_id = 0 means the main issue) or a comment
+func PurgeIssueContentHistory(dbCtx context.Context, issueID, commentID int64) error {
+	if _, err := db.GetEngine(dbCtx).Where(builder.Eq{"issue_id": issueID, "comment_id": commentID}).Delete(&ContentHistory{}); err != nil {
+		log.Error("failed to purge issue content history. err=%v", err)
+		return err
+	}
+	return nil
+}
+
+// PurgeIssueContentHistoryRevision hard deletes a single history revision, e.g. to remove a leaked secret
+func PurgeIssueContentHistoryRevision(dbCtx context.Context, historyID int64) error {
+	if _, err := db.GetEngine(dbCtx).ID(historyID).Delete(&ContentHistory{}); err != nil {
+		log.Error("failed to purge issue content history revision. err=%v", err)
+		return err
+	}
+	return nil
+}
+
 // ErrIssueContentHistoryNotExist not exist error
 type ErrIssueContentHistoryNotExist struct {
 	ID int64
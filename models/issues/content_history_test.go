@@ -77,4 +77,24 @@ func TestContentHistory(t *testing.T) {
 	assert.EqualValues(t, 8, list2[0].HistoryID)
 	assert.EqualValues(t, 7, list2[1].HistoryID)
 	assert.EqualValues(t, 4, list2[2].HistoryID)
+
+	histories, _ := FindIssueContentHistories(dbCtx, 10, 100)
+	assert.Len(t, histories, 3)
+	assert.EqualValues(t, 8, histories[0].ID)
+	assert.Equal(t, "c-e", histories[0].ContentText)
+
+	// purge a single revision
+	assert.NoError(t, PurgeIssueContentHistoryRevision(dbCtx, 7))
+	histories, _ = FindIssueContentHistories(dbCtx, 10, 100)
+	assert.Len(t, histories, 2)
+	h8, h8Prev, _ := GetIssueContentHistoryAndPrev(dbCtx, 8)
+	assert.EqualValues(t, 8, h8.ID)
+	assert.EqualValues(t, 4, h8Prev.ID)
+
+	// purge
+	assert.NoError(t, PurgeIssueContentHistory(dbCtx, 10, 100))
+	histories, _ = FindIssueContentHistories(dbCtx, 10, 100)
+	assert.Len(t, histories, 0)
+	histories, _ = FindIssueContentHistories(dbCtx, 10, 0)
+	assert.Len(t, histories, 3)
 }
@@ -0,0 +1,138 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// CustomEmoji is an emoji uploaded by a site admin or, if OwnerID isn't 0, by the owners of an organization.
+// Emoji of an organization are used as :<orgname>-<name>: in its repositories.
+type CustomEmoji struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	Name        string             `xorm:"VARCHAR(50) UNIQUE(s) NOT NULL"`
+	Size        int64              `xorm:"NOT NULL DEFAULT 0"`
+	UploaderID  int64              `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+}
+
+// AllowedReaction is a reaction allowed by site admins, if there are none the REACTIONS of [ui] are allowed
+type AllowedReaction struct {
+	ID   int64  `xorm:"pk autoincr"`
+	Name string `xorm:"VARCHAR(50) UNIQUE NOT NULL"`
+	Sort int    `xorm:"NOT NULL DEFAULT 0"`
+}
+
+func init() {
+	db.RegisterModel(new(CustomEmoji))
+	db.RegisterModel(new(AllowedReaction))
+}
+
+// RelativePath returns the path of the image of the emoji in the custom emoji storage,
+// <name>.png for the emoji of the instance and <owner id>/<name>.png for the emoji of an organization
+func (e *CustomEmoji) RelativePath() string {
+	if e.OwnerID == 0 {
+		return e.Name + ".png"
+	}
+	return fmt.Sprintf("%d/%s.png", e.OwnerID, e.Name)
+}
+
+// ErrCustomEmojiNotExist represents a "CustomEmojiNotExist" kind of error.
+type ErrCustomEmojiNotExist struct {
+	OwnerID int64
+	Name    string
+}
+
+// IsErrCustomEmojiNotExist checks if an error is a ErrCustomEmojiNotExist.
+func IsErrCustomEmojiNotExist(err error) bool {
+	_, ok := err.(ErrCustomEmojiNotExist)
+	return ok
+}
+
+func (err ErrCustomEmojiNotExist) Error() string {
+	return fmt.Sprintf("custom emoji does not exist [owner_id: %d, name: %s]", err.OwnerID, err.Name)
+}
+
+// GetCustomEmojis returns the emoji uploaded for an owner ordered by name, 0 returns the emoji of the instance
+func GetCustomEmojis(ctx context.Context, ownerID int64) ([]*CustomEmoji, error) {
+	emojis := make([]*CustomEmoji, 0, 10)
+	return emojis, db.GetEngine(ctx).Where("owner_id = ?", ownerID).Asc("name").Find(&emojis)
+}
+
+// GetAllCustomEmojis returns the emoji uploaded for the instance and for all organizations
+func GetAllCustomEmojis(ctx context.Context) ([]*CustomEmoji, error) {
+	emojis := make([]*CustomEmoji, 0, 10)
+	return emojis, db.GetEngine(ctx).Asc("owner_id").Asc("name").Find(&emojis)
+}
+
+// GetCustomEmojiByName returns the emoji uploaded for an owner with the name
+func GetCustomEmojiByName(ctx context.Context, ownerID int64, name string) (*CustomEmoji, error) {
+	e := &CustomEmoji{}
+	has, err := db.GetEngine(ctx).Where("owner_id = ? AND name = ?", ownerID, name).Get(e)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrCustomEmojiNotExist{OwnerID: ownerID, Name: name}
+	}
+	return e, nil
+}
+
+// InsertCustomEmoji inserts an uploaded emoji
+func InsertCustomEmoji(ctx context.Context, e *CustomEmoji) error {
+	return db.Insert(ctx, e)
+}
+
+// DeleteCustomEmoji deletes an uploaded emoji, an emoji of the instance is removed from the allowed reactions as well
+func DeleteCustomEmoji(ctx context.Context, ownerID int64, name string) error {
+	return db.WithTx(func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("owner_id = ? AND name = ?", ownerID, name).Delete(&CustomEmoji{}); err != nil {
+			return err
+		}
+		if ownerID != 0 {
+			return nil
+		}
+		_, err := db.GetEngine(ctx).Where("name = ?", name).Delete(&AllowedReaction{})
+		return err
+	}, ctx)
+}
+
+// DeleteCustomEmojisByOwnerID deletes all emoji uploaded for an organization
+func DeleteCustomEmojisByOwnerID(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(&CustomEmoji{})
+	return err
+}
+
+// GetAllowedReactions returns the names of the reactions allowed by site admins in their order
+func GetAllowedReactions(ctx context.Context) ([]string, error) {
+	reactions := make([]*AllowedReaction, 0, 10)
+	if err := db.GetEngine(ctx).Asc("sort").Find(&reactions); err != nil {
+		return nil, err
+	}
+	names := make([]string, 0, len(reactions))
+	for _, r := range reactions {
+		names = append(names, r.Name)
+	}
+	return names, nil
+}
+
+// SetAllowedReactions replaces the reactions allowed by site admins, an empty list allows the REACTIONS of [ui] again
+func SetAllowedReactions(ctx context.Context, names []string) error {
+	return db.WithTx(func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("1 = 1").Delete(&AllowedReaction{}); err != nil {
+			return err
+		}
+		for i, name := range names {
+			if err := db.Insert(ctx, &AllowedReaction{Name: name, Sort: i}); err != nil {
+				return err
+			}
+		}
+		return nil
+	}, ctx)
+}
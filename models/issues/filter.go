@@ -0,0 +1,198 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// ErrIssueFilterNotExist represents a "IssueFilterNotExist" kind of error.
+type ErrIssueFilterNotExist struct {
+	ID     int64
+	UserID int64
+}
+
+// IsErrIssueFilterNotExist checks if an error is a ErrIssueFilterNotExist.
+func IsErrIssueFilterNotExist(err error) bool {
+	_, ok := err.(ErrIssueFilterNotExist)
+	return ok
+}
+
+func (err ErrIssueFilterNotExist) Error() string {
+	return fmt.Sprintf("issue filter does not exist [id: %d, user_id: %d]", err.ID, err.UserID)
+}
+
+// ErrIssueFilterAlreadyExist represents a "IssueFilterAlreadyExist" kind of error.
+type ErrIssueFilterAlreadyExist struct {
+	UserID int64
+	Name   string
+}
+
+// IsErrIssueFilterAlreadyExist checks if an error is a ErrIssueFilterAlreadyExist.
+func IsErrIssueFilterAlreadyExist(err error) bool {
+	_, ok := err.(ErrIssueFilterAlreadyExist)
+	return ok
+}
+
+func (err ErrIssueFilterAlreadyExist) Error() string {
+	return fmt.Sprintf("issue filter already exists [user_id: %d, name: %s]", err.UserID, err.Name)
+}
+
+// IssueFilter represents a named combination of issue list filters saved by a user
+type IssueFilter struct {
+	ID     int64  `xorm:"pk autoincr"`
+	UserID int64  `xorm:"UNIQUE(s) NOT NULL"`
+	Name   string `xorm:"UNIQUE(s) NOT NULL"`
+	// RepoID is the repository the filter was saved in, 0 if it applies to all repositories
+	RepoID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+
+	Keyword     string
+	ViewType    string
+	SortType    string
+	State       string
+	Labels      string // comma separated label ids, negative ids exclude a label
+	MilestoneID int64
+	AssigneeID  int64
+	PosterID    int64
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(IssueFilter))
+}
+
+// LabelIDs returns the ids of the labels of the filter
+func (f *IssueFilter) LabelIDs() []int64 {
+	ids := make([]int64, 0, strings.Count(f.Labels, ",")+1)
+	for _, s := range strings.Split(f.Labels, ",") {
+		if id, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64); err == nil && id != 0 {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// SetLabelIDs sets the labels of the filter
+func (f *IssueFilter) SetLabelIDs(ids []int64) {
+	labels := make([]string, 0, len(ids))
+	for _, id := range ids {
+		if id != 0 {
+			labels = append(labels, strconv.FormatInt(id, 10))
+		}
+	}
+	f.Labels = strings.Join(labels, ",")
+}
+
+// QueryString returns the query string selecting the filter in an issue list
+func (f *IssueFilter) QueryString() string {
+	v := url.Values{}
+	for key, value := range map[string]string{
+		"q":      f.Keyword,
+		"type":   f.ViewType,
+		"sort":   f.SortType,
+		"state":  f.State,
+		"labels": f.Labels,
+	} {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	for key, value := range map[string]int64{
+		"milestone": f.MilestoneID,
+		"assignee":  f.AssigneeID,
+		"poster":    f.PosterID,
+	} {
+		if value != 0 {
+			v.Set(key, strconv.FormatInt(value, 10))
+		}
+	}
+	return v.Encode()
+}
+
+// CreateIssueFilter saves a new issue filter of a user
+func CreateIssueFilter(ctx context.Context, f *IssueFilter) error {
+	f.Name = strings.TrimSpace(f.Name)
+	exist, err := db.GetEngine(ctx).Exist(&IssueFilter{UserID: f.UserID, Name: f.Name})
+	if err != nil {
+		return err
+	} else if exist {
+		return ErrIssueFilterAlreadyExist{UserID: f.UserID, Name: f.Name}
+	}
+	return db.Insert(ctx, f)
+}
+
+// GetIssueFilterByID returns the issue filter of a user by id
+func GetIssueFilterByID(ctx context.Context, userID, id int64) (*IssueFilter, error) {
+	f := new(IssueFilter)
+	has, err := db.GetEngine(ctx).Where("id = ? AND user_id = ?", id, userID).Get(f)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrIssueFilterNotExist{ID: id, UserID: userID}
+	}
+	return f, nil
+}
+
+// UpdateIssueFilter updates an issue filter
+func UpdateIssueFilter(ctx context.Context, f *IssueFilter) error {
+	f.Name = strings.TrimSpace(f.Name)
+	exist, err := db.GetEngine(ctx).Where("user_id = ? AND name = ? AND id <> ?", f.UserID, f.Name, f.ID).Exist(new(IssueFilter))
+	if err != nil {
+		return err
+	} else if exist {
+		return ErrIssueFilterAlreadyExist{UserID: f.UserID, Name: f.Name}
+	}
+	_, err = db.GetEngine(ctx).ID(f.ID).AllCols().Update(f)
+	return err
+}
+
+// DeleteIssueFilter deletes an issue filter of a user
+func DeleteIssueFilter(ctx context.Context, userID, id int64) error {
+	deleted, err := db.GetEngine(ctx).Where("id = ? AND user_id = ?", id, userID).Delete(new(IssueFilter))
+	if err != nil {
+		return err
+	} else if deleted == 0 {
+		return ErrIssueFilterNotExist{ID: id, UserID: userID}
+	}
+	return nil
+}
+
+// FindIssueFiltersOptions represents the options to find issue filters
+type FindIssueFiltersOptions struct {
+	db.ListOptions
+	UserID int64
+	// RepoID restricts the filters to those saved in the repository or applying to all repositories
+	RepoID int64
+}
+
+func (opts *FindIssueFiltersOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"user_id": opts.UserID})
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.In("repo_id", 0, opts.RepoID))
+	}
+	return cond
+}
+
+// FindIssueFilters returns the issue filters of a user ordered by name
+func FindIssueFilters(ctx context.Context, opts *FindIssueFiltersOptions) ([]*IssueFilter, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).OrderBy("name ASC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	filters := make([]*IssueFilter, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&filters)
+	return filters, count, err
+}
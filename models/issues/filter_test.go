@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issues
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIssueFilter_LabelIDs(t *testing.T) {
+	f := &IssueFilter{}
+	f.SetLabelIDs([]int64{1, 0, -3})
+	assert.EqualValues(t, "1,-3", f.Labels)
+	assert.EqualValues(t, []int64{1, -3}, f.LabelIDs())
+
+	f.SetLabelIDs(nil)
+	assert.Empty(t, f.LabelIDs())
+}
+
+func TestIssueFilter_QueryString(t *testing.T) {
+	f := &IssueFilter{
+		Keyword:     "bug fix",
+		ViewType:    "assigned",
+		State:       "closed",
+		Labels:      "1,-3",
+		MilestoneID: 2,
+	}
+	assert.EqualValues(t, "labels=1%2C-3&milestone=2&q=bug+fix&state=closed&type=assigned", f.QueryString())
+}
+
+func TestIssueFilters(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	global := &IssueFilter{UserID: 2, Name: " Global ", State: "open"}
+	assert.NoError(t, CreateIssueFilter(db.DefaultContext, global))
+	assert.EqualValues(t, "Global", global.Name)
+
+	repo1 := &IssueFilter{UserID: 2, Name: "Repo one", RepoID: 1}
+	assert.NoError(t, CreateIssueFilter(db.DefaultContext, repo1))
+	assert.NoError(t, CreateIssueFilter(db.DefaultContext, &IssueFilter{UserID: 2, Name: "Repo two", RepoID: 2}))
+	assert.NoError(t, CreateIssueFilter(db.DefaultContext, &IssueFilter{UserID: 1, Name: "Global"}))
+
+	err := CreateIssueFilter(db.DefaultContext, &IssueFilter{UserID: 2, Name: "Global"})
+	assert.True(t, IsErrIssueFilterAlreadyExist(err))
+
+	filters, count, err := FindIssueFilters(db.DefaultContext, &FindIssueFiltersOptions{UserID: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, count)
+	assert.Len(t, filters, 3)
+
+	filters, count, err = FindIssueFilters(db.DefaultContext, &FindIssueFiltersOptions{UserID: 2, RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, filters, 2) {
+		assert.EqualValues(t, global.ID, filters[0].ID)
+		assert.EqualValues(t, repo1.ID, filters[1].ID)
+	}
+
+	repo1.Name = "Global"
+	assert.True(t, IsErrIssueFilterAlreadyExist(UpdateIssueFilter(db.DefaultContext, repo1)))
+	repo1.Name = "Renamed"
+	repo1.Keyword = "crash"
+	assert.NoError(t, UpdateIssueFilter(db.DefaultContext, repo1))
+
+	f, err := GetIssueFilterByID(db.DefaultContext, 2, repo1.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "Renamed", f.Name)
+	assert.EqualValues(t, "crash", f.Keyword)
+
+	_, err = GetIssueFilterByID(db.DefaultContext, 1, repo1.ID)
+	assert.True(t, IsErrIssueFilterNotExist(err))
+
+	assert.True(t, IsErrIssueFilterNotExist(DeleteIssueFilter(db.DefaultContext, 1, repo1.ID)))
+	assert.NoError(t, DeleteIssueFilter(db.DefaultContext, 2, repo1.ID))
+	_, err = GetIssueFilterByID(db.DefaultContext, 2, repo1.ID)
+	assert.True(t, IsErrIssueFilterNotExist(err))
+}
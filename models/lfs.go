@@ -246,3 +246,10 @@ func CopyLFS(ctx context.Context, newRepo, oldRepo *repo_model.Repository) error
 
 	return nil
 }
+
+// SumOwnerLFSSize returns the size of the LFS objects of the repositories of the owner
+func SumOwnerLFSSize(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Where(builder.In("repository_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": ownerID}))).
+		SumInt(new(LFSMetaObject), "size")
+}
@@ -179,6 +179,17 @@ func InsertReleases(rels ...*Release) error {
 		}
 	}
 
+	repoIDs := make(map[int64]bool, 1)
+	for _, rel := range rels {
+		if repoIDs[rel.RepoID] {
+			continue
+		}
+		repoIDs[rel.RepoID] = true
+		if err := UpdateRepoReleaseCounts(ctx, rel.RepoID); err != nil {
+			return err
+		}
+	}
+
 	return committer.Commit()
 }
 
@@ -387,6 +387,108 @@ var migrations = []Migration{
 	NewMigration("Add auto merge table", addAutoMergeTable),
 	// v215 -> v216
 	NewMigration("allow to view files in PRs", addReviewViewedFiles),
+	// v216 -> v217
+	NewMigration("Add issue filter table", addIssueFilterTable),
+	// v217 -> v218
+	NewMigration("Add license and pushed time to repository table", addLicenseAndPushedUnixToRepository),
+	// v218 -> v219
+	NewMigration("Add organization protected branch table", addOrgProtectedBranchTable),
+	// v219 -> v220
+	NewMigration("Add secret and secret audit log tables", addSecretTables),
+	// v220 -> v221
+	NewMigration("Add actions tables", addActionsTables),
+	// v221 -> v222
+	NewMigration("Add expiry to collaboration and team repo tables", addExpiryToCollaborationAndTeamRepo),
+	// v222 -> v223
+	NewMigration("Add access request table", addAccessRequestTable),
+	// v223 -> v224
+	NewMigration("Add check run tables", addCheckRunTables),
+	// v224 -> v225
+	NewMigration("Add scheduled visibility change table", addScheduledVisibilityChangeTable),
+	// v225 -> v226
+	NewMigration("Add retry columns to hook task table", addRetryColumnsToHookTask),
+	// v226 -> v227
+	NewMigration("Add secret rotation and client certificate columns to webhook table", addSecretRotationAndClientCertToWebhook),
+	// v227 -> v228
+	NewMigration("Add external url and checksum to attachment table", addExternalURLToAttachment),
+	// v228 -> v229
+	NewMigration("Add package policy table", addPackagePolicyTable),
+	// v229 -> v230
+	NewMigration("Add restricted release actions to team table", addRestrictedReleaseActionsToTeam),
+	// v230 -> v231
+	NewMigration("Add trash columns to repository table", addTrashColumnsToRepository),
+	// v231 -> v232
+	NewMigration("Add template columns to webhook table", addTemplateColumnsToWebhook),
+	// v232 -> v233
+	NewMigration("Add repo audit log table", addRepoAuditLogTable),
+	// v233 -> v234
+	NewMigration("Add audit event table", addAuditEventTable),
+	// v234 -> v235
+	NewMigration("Add payload version to hook task table", addPayloadVersionToHookTask),
+	// v235 -> v236
+	NewMigration("Add GitHub compatibility column to webhook table", addIsGitHubCompatibleToWebhook),
+	// v236 -> v237
+	NewMigration("Add OAuth2 device authorization table", addOAuth2DeviceAuthorizationTable),
+	// v237 -> v238
+	NewMigration("Add repository traffic table", addRepoTrafficTable),
+	// v238 -> v239
+	NewMigration("Add SCIM identity table", addSCIMIdentityTable),
+	// v239 -> v240
+	NewMigration("Add sign-in restrictions to login source table", addSignInRestrictionsToLoginSource),
+	// v240 -> v241
+	NewMigration("Add editor file patterns to repository table", addEditorFilePatternsToRepository),
+	// v241 -> v242
+	NewMigration("Add allowed CIDRs to access token and deploy key tables", addAllowedCIDRsToAccessTokenAndDeployKey),
+	// v242 -> v243
+	NewMigration("Create security event table", createSecurityEventTable),
+	// v243 -> v244
+	NewMigration("Add passwd changed unix to user table", addPasswdChangedUnixToUser),
+	// v244 -> v245
+	NewMigration("Create preview environment table", createPreviewEnvironmentTable),
+	// v245 -> v246
+	NewMigration("Create deploy token table", createDeployTokenTable),
+	// v246 -> v247
+	NewMigration("Create issue intake rule table", createIssueIntakeRuleTable),
+	// v247 -> v248
+	NewMigration("Add security event id to notification table", addSecurityEventIDToNotification),
+	// v248 -> v249
+	NewMigration("Create repo maintenance table", createRepoMaintenanceTable),
+	// v249 -> v250
+	NewMigration("Create two factor recovery code and backup email tables", createRecoveryCodeAndBackupEmailTables),
+	// v250 -> v251
+	NewMigration("Create quota table", createQuotaTable),
+	// v251 -> v252
+	NewMigration("Add is suspended to user table", addIsSuspendedToUser),
+	// v252 -> v253
+	NewMigration("Add is cold to attachment table", addIsColdToAttachment),
+	// v253 -> v254
+	NewMigration("Create org key policy table", createOrgKeyPolicyTable),
+	// v254 -> v255
+	NewMigration("Create custom emoji and allowed reaction tables", createCustomEmojiAndAllowedReactionTables),
+	// v255 -> v256
+	NewMigration("Add owner id to custom emoji", addOwnerIDToCustomEmoji),
+	// v256 -> v257
+	NewMigration("Create download token table", createDownloadTokenTable),
+	// v257 -> v258
+	NewMigration("Create discussion tables", createDiscussionTables),
+	// v258 -> v259
+	NewMigration("Create resumable upload table", createResumableUploadTable),
+	// v259 -> v260
+	NewMigration("Add release and tag counts to repository", addRepoReleaseCounts),
+	// v260 -> v261
+	NewMigration("Create release signing key table", createReleaseSigningKeyTable),
+	// v261 -> v262
+	NewMigration("Add no index to user and repository", addNoIndexToUserAndRepository),
+	// v262 -> v263
+	NewMigration("Create repository code stats table", createRepoCodeStatsTable),
+	// v263 -> v264
+	NewMigration("Create announcement tables", createAnnouncementTables),
+	// v264 -> v265
+	NewMigration("Create maintenance mode table", createMaintenanceModeTable),
+	// v265 -> v266
+	NewMigration("Create pending operation table", createPendingOperationTable),
+	// v266 -> v267
+	NewMigration("Add schedule columns to repository transfers and redirects", addRepoTransferScheduleColumns),
 }
 
 // GetCurrentDBVersion returns the current db version
@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addIssueFilterTable(x *xorm.Engine) error {
+	type IssueFilter struct {
+		ID     int64  `xorm:"pk autoincr"`
+		UserID int64  `xorm:"UNIQUE(s) NOT NULL"`
+		Name   string `xorm:"UNIQUE(s) NOT NULL"`
+		RepoID int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+
+		Keyword     string
+		ViewType    string
+		SortType    string
+		State       string
+		Labels      string
+		MilestoneID int64
+		AssigneeID  int64
+		PosterID    int64
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync2(new(IssueFilter))
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addLicenseAndPushedUnixToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		License    string             `xorm:"VARCHAR(255) INDEX"`
+		PushedUnix timeutil.TimeStamp `xorm:"INDEX"`
+	}
+
+	if err := x.Sync2(new(Repository)); err != nil {
+		return err
+	}
+
+	// the last update is the best guess for the last push of existing repositories
+	_, err := x.Exec("UPDATE repository SET pushed_unix = updated_unix WHERE is_empty = ?", false)
+	return err
+}
@@ -0,0 +1,33 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addOrgProtectedBranchTable(x *xorm.Engine) error {
+	type OrgProtectedBranch struct {
+		ID                     int64    `xorm:"pk autoincr"`
+		OrgID                  int64    `xorm:"INDEX NOT NULL"`
+		BranchPattern          string   `xorm:"NOT NULL"`
+		ApplyToExisting        bool     `xorm:"NOT NULL DEFAULT true"`
+		AllowOverride          bool     `xorm:"NOT NULL DEFAULT false"`
+		CanPush                bool     `xorm:"NOT NULL DEFAULT false"`
+		EnableStatusCheck      bool     `xorm:"NOT NULL DEFAULT false"`
+		StatusCheckContexts    []string `xorm:"JSON TEXT"`
+		RequiredApprovals      int64    `xorm:"NOT NULL DEFAULT 0"`
+		BlockOnRejectedReviews bool     `xorm:"NOT NULL DEFAULT false"`
+		DismissStaleApprovals  bool     `xorm:"NOT NULL DEFAULT false"`
+		RequireSignedCommits   bool     `xorm:"NOT NULL DEFAULT false"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync2(new(OrgProtectedBranch))
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addActionsTables(x *xorm.Engine) error {
+	type ActionRunner struct {
+		ID         int64              `xorm:"pk autoincr"`
+		UUID       string             `xorm:"CHAR(36) UNIQUE"`
+		Name       string             `xorm:"VARCHAR(255)"`
+		OwnerID    int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		RepoID     int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		TokenHash  string             `xorm:"UNIQUE"`
+		TokenSalt  string             `xorm:"NOT NULL"`
+		Labels     []string           `xorm:"TEXT JSON"`
+		LastOnline timeutil.TimeStamp `xorm:"INDEX"`
+		Created    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type ActionRunnerToken struct {
+		ID       int64              `xorm:"pk autoincr"`
+		Token    string             `xorm:"UNIQUE"`
+		OwnerID  int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		RepoID   int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		IsActive bool               `xorm:"NOT NULL DEFAULT false"`
+		Created  timeutil.TimeStamp `xorm:"created"`
+		Updated  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionRun struct {
+		ID            int64  `xorm:"pk autoincr"`
+		Title         string `xorm:"VARCHAR(255)"`
+		RepoID        int64  `xorm:"INDEX UNIQUE(repo_index)"`
+		Index         int64  `xorm:"INDEX UNIQUE(repo_index)"`
+		WorkflowID    string `xorm:"INDEX"`
+		WorkflowName  string
+		TriggerUserID int64
+		Ref           string
+		CommitSHA     string
+		Event         string
+		Status        int `xorm:"INDEX"`
+		Started       timeutil.TimeStamp
+		Stopped       timeutil.TimeStamp
+		Created       timeutil.TimeStamp `xorm:"created"`
+		Updated       timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type ActionRunIndex struct {
+		GroupID  int64 `xorm:"pk"`
+		MaxIndex int64 `xorm:"index"`
+	}
+
+	type ActionRunJob struct {
+		ID        int64    `xorm:"pk autoincr"`
+		RunID     int64    `xorm:"INDEX"`
+		RepoID    int64    `xorm:"INDEX"`
+		CommitSHA string   `xorm:"INDEX"`
+		Name      string   `xorm:"VARCHAR(255)"`
+		JobID     string   `xorm:"VARCHAR(255)"`
+		Needs     []string `xorm:"TEXT JSON"`
+		RunsOn    []string `xorm:"TEXT JSON"`
+		Payload   string   `xorm:"LONGTEXT"`
+		RunnerID  int64    `xorm:"INDEX"`
+		Status    int      `xorm:"INDEX"`
+		LogLength int64    `xorm:"NOT NULL DEFAULT 0"`
+		Started   timeutil.TimeStamp
+		Stopped   timeutil.TimeStamp
+		Created   timeutil.TimeStamp `xorm:"created"`
+		Updated   timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type ActionJobLog struct {
+		ID        int64              `xorm:"pk autoincr"`
+		JobID     int64              `xorm:"INDEX"`
+		RepoID    int64              `xorm:"INDEX"`
+		Offset    int64              `xorm:"NOT NULL DEFAULT 0"`
+		LineCount int64              `xorm:"NOT NULL DEFAULT 0"`
+		Content   string             `xorm:"LONGTEXT"`
+		Created   timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type ActionArtifact struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RunID       int64  `xorm:"INDEX"`
+		JobID       int64  `xorm:"INDEX"`
+		RepoID      int64  `xorm:"INDEX"`
+		Name        string `xorm:"VARCHAR(255)"`
+		StoragePath string
+		FileSize    int64
+		Created     timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(
+		new(ActionRunner),
+		new(ActionRunnerToken),
+		new(ActionRun),
+		new(ActionRunIndex),
+		new(ActionRunJob),
+		new(ActionJobLog),
+		new(ActionArtifact),
+	)
+}
@@ -0,0 +1,25 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addExpiryToCollaborationAndTeamRepo(x *xorm.Engine) error {
+	type Collaboration struct {
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		GrantedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type TeamRepo struct {
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		GrantedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(Collaboration), new(TeamRepo))
+}
@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addCheckRunTables(x *xorm.Engine) error {
+	type CheckRun struct {
+		ID            int64  `xorm:"pk autoincr"`
+		RepoID        int64  `xorm:"INDEX NOT NULL"`
+		SHA           string `xorm:"VARCHAR(64) NOT NULL INDEX"`
+		Name          string `xorm:"VARCHAR(255) NOT NULL"`
+		Status        string `xorm:"VARCHAR(20) NOT NULL"`
+		Conclusion    string `xorm:"VARCHAR(20)"`
+		DetailsURL    string `xorm:"TEXT"`
+		ExternalID    string `xorm:"VARCHAR(255)"`
+		Title         string `xorm:"VARCHAR(255)"`
+		Summary       string `xorm:"TEXT"`
+		Text          string `xorm:"TEXT"`
+		Attempt       int    `xorm:"NOT NULL DEFAULT 1"`
+		CreatorID     int64
+		StartedUnix   timeutil.TimeStamp
+		CompletedUnix timeutil.TimeStamp
+		CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix   timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type CheckRunAnnotation struct {
+		ID         int64  `xorm:"pk autoincr"`
+		RepoID     int64  `xorm:"INDEX NOT NULL"`
+		CheckRunID int64  `xorm:"INDEX NOT NULL"`
+		Path       string `xorm:"VARCHAR(500) NOT NULL"`
+		StartLine  int    `xorm:"NOT NULL"`
+		EndLine    int    `xorm:"NOT NULL"`
+		Level      string `xorm:"VARCHAR(10) NOT NULL"`
+		Title      string `xorm:"VARCHAR(255)"`
+		Message    string `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(CheckRun), new(CheckRunAnnotation))
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addScheduledVisibilityChangeTable(x *xorm.Engine) error {
+	type ScheduledVisibilityChange struct {
+		ID            int64              `xorm:"pk autoincr"`
+		RepoID        int64              `xorm:"UNIQUE NOT NULL"`
+		MakePrivate   bool               `xorm:"NOT NULL DEFAULT false"`
+		DoerID        int64              `xorm:"NOT NULL"`
+		ScheduledUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(new(ScheduledVisibilityChange))
+}
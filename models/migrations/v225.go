@@ -0,0 +1,21 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRetryColumnsToHookTask(x *xorm.Engine) error {
+	type HookTask struct {
+		Attempts      int                `xorm:"NOT NULL DEFAULT 0"`
+		NextRetryUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Latency       int64              `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(HookTask))
+}
@@ -0,0 +1,22 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addSecretRotationAndClientCertToWebhook(x *xorm.Engine) error {
+	type Webhook struct {
+		PreviousSecret    string             `xorm:"TEXT"`
+		SecretRotatedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		ClientCert        string             `xorm:"TEXT"`
+		ClientKey         string             `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(Webhook))
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPackagePolicyTable(x *xorm.Engine) error {
+	type PackagePolicy struct {
+		ID                    int64              `xorm:"pk autoincr"`
+		OwnerID               int64              `xorm:"UNIQUE NOT NULL"`
+		MaxCount              int64              `xorm:"NOT NULL DEFAULT 0"`
+		MaxSize               int64              `xorm:"NOT NULL DEFAULT 0"`
+		KeepLastVersions      int                `xorm:"NOT NULL DEFAULT 0"`
+		UntaggedRetentionDays int                `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix           timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix           timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	return x.Sync2(new(PackagePolicy))
+}
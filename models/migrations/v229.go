@@ -0,0 +1,17 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addRestrictedReleaseActionsToTeam(x *xorm.Engine) error {
+	type Team struct {
+		RestrictedReleaseActions int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(Team))
+}
@@ -0,0 +1,20 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addTrashColumnsToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		TrashedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		TrashedByID int64              `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(Repository))
+}
@@ -0,0 +1,19 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addTemplateColumnsToWebhook(x *xorm.Engine) error {
+	type Webhook struct {
+		IsTemplate bool  `xorm:"NOT NULL DEFAULT false"`
+		IsLocked   bool  `xorm:"NOT NULL DEFAULT false"`
+		TemplateID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(Webhook))
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoAuditLogTable(x *xorm.Engine) error {
+	type RepoAuditLog struct {
+		ID     int64  `xorm:"pk autoincr"`
+		RepoID int64  `xorm:"INDEX NOT NULL"`
+		DoerID int64  `xorm:"INDEX NOT NULL"`
+		Action string `xorm:"VARCHAR(50) INDEX NOT NULL"`
+		Target string `xorm:"NOT NULL DEFAULT ''"`
+		Detail string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync2(new(RepoAuditLog))
+}
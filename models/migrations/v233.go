@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addAuditEventTable(x *xorm.Engine) error {
+	type AuditEvent struct {
+		ID       int64  `xorm:"pk autoincr"`
+		Action   string `xorm:"VARCHAR(50) INDEX NOT NULL"`
+		DoerID   int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		DoerName string `xorm:"NOT NULL DEFAULT ''"`
+		IP       string `xorm:"VARCHAR(50)"`
+		Target   string `xorm:"NOT NULL DEFAULT ''"`
+		Detail   string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync2(new(AuditEvent))
+}
@@ -0,0 +1,35 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// OAuth2DeviceAuthorization here is a snapshot of auth.OAuth2DeviceAuthorization for this version of the database
+type OAuth2DeviceAuthorization struct {
+	ID             int64  `xorm:"pk autoincr"`
+	ApplicationID  int64  `xorm:"INDEX"`
+	DeviceCode     string `xorm:"INDEX unique"`
+	UserCode       string `xorm:"INDEX unique"`
+	Scope          string `xorm:"TEXT"`
+	Status         int    `xorm:"NOT NULL DEFAULT 0"`
+	GrantID        int64
+	LastPolledUnix timeutil.TimeStamp
+	ValidUntil     timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+}
+
+// TableName sets the database table name to be the correct one, as the
+// autogenerated table name for this struct is "o_auth2_device_authorization".
+func (device *OAuth2DeviceAuthorization) TableName() string {
+	return "oauth2_device_authorization"
+}
+
+func addOAuth2DeviceAuthorizationTable(x *xorm.Engine) error {
+	return x.Sync2(new(OAuth2DeviceAuthorization))
+}
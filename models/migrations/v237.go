@@ -0,0 +1,24 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoTrafficTable(x *xorm.Engine) error {
+	type RepoTraffic struct {
+		ID      int64              `xorm:"pk autoincr"`
+		RepoID  int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Kind    int                `xorm:"UNIQUE(s) NOT NULL"`
+		Day     timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		Visitor string             `xorm:"VARCHAR(80) UNIQUE(s) NOT NULL"`
+		Count   int64              `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(RepoTraffic))
+}
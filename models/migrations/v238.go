@@ -0,0 +1,31 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+// SCIMIdentity here is a snapshot of user.SCIMIdentity for this version of the database
+type SCIMIdentity struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UserID      int64              `xorm:"UNIQUE NOT NULL"`
+	UserName    string             `xorm:"INDEX"`
+	ExternalID  string             `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the database table name to be the correct one, as the
+// autogenerated table name for this struct is "s_c_i_m_identity".
+func (identity *SCIMIdentity) TableName() string {
+	return "scim_identity"
+}
+
+func addSCIMIdentityTable(x *xorm.Engine) error {
+	return x.Sync2(new(SCIMIdentity))
+}
@@ -0,0 +1,19 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addSignInRestrictionsToLoginSource(x *xorm.Engine) error {
+	type LoginSource struct {
+		AllowedCIDRs      string `xorm:"TEXT"`
+		DeniedCIDRs       string `xorm:"TEXT"`
+		AccessTimeWindows string `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(LoginSource))
+}
@@ -0,0 +1,18 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addEditorFilePatternsToRepository(x *xorm.Engine) error {
+	type Repository struct {
+		EditorLockedFilePatterns      string `xorm:"TEXT"`
+		EditorPullRequestFilePatterns string `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(Repository))
+}
@@ -0,0 +1,21 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addAllowedCIDRsToAccessTokenAndDeployKey(x *xorm.Engine) error {
+	type AccessToken struct {
+		AllowedCIDRs string `xorm:"TEXT"`
+	}
+
+	type DeployKey struct {
+		AllowedCIDRs string `xorm:"TEXT"`
+	}
+
+	return x.Sync2(new(AccessToken), new(DeployKey))
+}
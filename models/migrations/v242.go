@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createSecurityEventTable(x *xorm.Engine) error {
+	type SecurityEvent struct {
+		ID                int64  `xorm:"pk autoincr"`
+		UserID            int64  `xorm:"INDEX NOT NULL"`
+		Type              string `xorm:"VARCHAR(30) NOT NULL"`
+		DeviceFingerprint string `xorm:"VARCHAR(64) INDEX"`
+		IP                string `xorm:"VARCHAR(50)"`
+		UserAgent         string `xorm:"TEXT"`
+		Detail            string `xorm:"TEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+	}
+
+	return x.Sync2(new(SecurityEvent))
+}
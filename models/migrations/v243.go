@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addPasswdChangedUnixToUser(x *xorm.Engine) error {
+	type User struct {
+		Passwd            string             `xorm:"NOT NULL"`
+		PasswdChangedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(User)); err != nil {
+		return err
+	}
+
+	// the time the existing passwords were set at is unknown, their rotation interval starts now
+	_, err := x.Where("passwd <> ''").Cols("passwd_changed_unix").Update(&User{PasswdChangedUnix: timeutil.TimeStampNow()})
+	return err
+}
@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createPreviewEnvironmentTable(x *xorm.Engine) error {
+	type PreviewEnvironment struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX NOT NULL"`
+		IssueID     int64  `xorm:"UNIQUE(s) NOT NULL"`
+		Name        string `xorm:"UNIQUE(s) VARCHAR(255) NOT NULL"`
+		URL         string `xorm:"TEXT"`
+		Status      string `xorm:"VARCHAR(20) NOT NULL"`
+		CreatorID   int64
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync2(new(PreviewEnvironment))
+}
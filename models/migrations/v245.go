@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createDeployTokenTable(x *xorm.Engine) error {
+	type DeployToken struct {
+		ID             int64  `xorm:"pk autoincr"`
+		RepoID         int64  `xorm:"INDEX NOT NULL"`
+		Name           string `xorm:"NOT NULL"`
+		TokenHash      string `xorm:"UNIQUE"`
+		TokenSalt      string
+		TokenLastEight string `xorm:"INDEX token_last_eight"`
+		Scopes         string `xorm:"TEXT"`
+		CreatorID      int64
+		ExpiresUnix    timeutil.TimeStamp `xorm:"INDEX"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix    timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	return x.Sync2(new(DeployToken))
+}
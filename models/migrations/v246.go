@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createIssueIntakeRuleTable(x *xorm.Engine) error {
+	type IssueIntakeRule struct {
+		ID           int64  `xorm:"pk autoincr"`
+		OrgID        int64  `xorm:"INDEX NOT NULL"`
+		Name         string `xorm:"NOT NULL"`
+		RepoPattern  string
+		TemplateFile string
+		Labels       []string `xorm:"JSON TEXT"`
+		ProjectTitle string
+		BoardTitle   string
+		TeamID       int64
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix  timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	return x.Sync2(new(IssueIntakeRule))
+}
@@ -0,0 +1,25 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createRepoMaintenanceTable(x *xorm.Engine) error {
+	type RepoMaintenance struct {
+		ID                  int64 `xorm:"pk autoincr"`
+		RepoID              int64 `xorm:"UNIQUE NOT NULL"`
+		PushCount           int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+		LastDuration        int64
+		LastError           string `xorm:"TEXT"`
+		LastMaintenanceUnix timeutil.TimeStamp
+		LastPushUnix        timeutil.TimeStamp
+	}
+
+	return x.Sync2(new(RepoMaintenance))
+}
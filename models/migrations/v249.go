@@ -0,0 +1,37 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createRecoveryCodeAndBackupEmailTables(x *xorm.Engine) error {
+	type RecoveryCode struct {
+		ID          int64              `xorm:"pk autoincr"`
+		UID         int64              `xorm:"INDEX NOT NULL"`
+		Salt        string             `xorm:"NOT NULL"`
+		Hash        string             `xorm:"NOT NULL"`
+		UsedUnix    timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	type BackupEmail struct {
+		ID          int64              `xorm:"pk autoincr"`
+		UID         int64              `xorm:"UNIQUE NOT NULL"`
+		Email       string             `xorm:"NOT NULL"`
+		LowerEmail  string             `xorm:"INDEX NOT NULL"`
+		IsActivated bool               `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	if err := x.Table("two_factor_recovery_code").Sync2(new(RecoveryCode)); err != nil {
+		return err
+	}
+	return x.Sync2(new(BackupEmail))
+}
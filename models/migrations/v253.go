@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createOrgKeyPolicyTable(x *xorm.Engine) error {
+	type OrgKeyPolicy struct {
+		ID                int64              `xorm:"pk autoincr"`
+		OrgID             int64              `xorm:"UNIQUE NOT NULL"`
+		SSHKeyMaxAgeYears int                `xorm:"NOT NULL DEFAULT 0"`
+		MinRSAKeySize     int                `xorm:"NOT NULL DEFAULT 0"`
+		ForbidDSAKeys     bool               `xorm:"NOT NULL DEFAULT false"`
+		RequireGPGKey     bool               `xorm:"NOT NULL DEFAULT false"`
+		CreatedUnix       timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix       timeutil.TimeStamp `xorm:"updated NOT NULL"`
+	}
+
+	return x.Sync2(new(OrgKeyPolicy))
+}
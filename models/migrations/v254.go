@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createCustomEmojiAndAllowedReactionTables(x *xorm.Engine) error {
+	type CustomEmoji struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Name        string             `xorm:"VARCHAR(50) UNIQUE NOT NULL"`
+		Size        int64              `xorm:"NOT NULL DEFAULT 0"`
+		UploaderID  int64              `xorm:"INDEX"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	}
+
+	type AllowedReaction struct {
+		ID   int64  `xorm:"pk autoincr"`
+		Name string `xorm:"VARCHAR(50) UNIQUE NOT NULL"`
+		Sort int    `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(CustomEmoji), new(AllowedReaction))
+}
@@ -0,0 +1,25 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addOwnerIDToCustomEmoji(x *xorm.Engine) error {
+	// Sync2 replaces the unique index on the name by the one on the owner and the name
+	type CustomEmoji struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OwnerID     int64              `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+		Name        string             `xorm:"VARCHAR(50) UNIQUE(s) NOT NULL"`
+		Size        int64              `xorm:"NOT NULL DEFAULT 0"`
+		UploaderID  int64              `xorm:"INDEX"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	}
+
+	return x.Sync2(new(CustomEmoji))
+}
@@ -0,0 +1,29 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createDownloadTokenTable(x *xorm.Engine) error {
+	type DownloadToken struct {
+		ID             int64  `xorm:"pk autoincr"`
+		UID            int64  `xorm:"INDEX"`
+		Name           string `xorm:"NOT NULL"`
+		TokenHash      string `xorm:"UNIQUE"`
+		TokenSalt      string
+		TokenLastEight string `xorm:"INDEX token_last_eight"`
+		Scopes         string `xorm:"TEXT NOT NULL"`
+		NumDownloads   int64  `xorm:"NOT NULL DEFAULT 0"`
+
+		CreatedUnix  timeutil.TimeStamp `xorm:"created"`
+		LastUsedUnix timeutil.TimeStamp
+	}
+
+	return x.Sync2(new(DownloadToken))
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createDiscussionTables(x *xorm.Engine) error {
+	type DiscussionCategory struct {
+		ID             int64  `xorm:"pk autoincr"`
+		RepoID         int64  `xorm:"INDEX NOT NULL"`
+		Name           string `xorm:"NOT NULL"`
+		Description    string `xorm:"TEXT"`
+		IsAnswerable   bool   `xorm:"NOT NULL DEFAULT false"`
+		NumDiscussions int    `xorm:"NOT NULL DEFAULT 0"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type Discussion struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"INDEX UNIQUE(repo_index)"`
+		Index       int64  `xorm:"UNIQUE(repo_index)"`
+		CategoryID  int64  `xorm:"INDEX NOT NULL"`
+		PosterID    int64  `xorm:"INDEX"`
+		Title       string `xorm:"name"`
+		Content     string `xorm:"LONGTEXT"`
+		NumComments int    `xorm:"NOT NULL DEFAULT 0"`
+		AnswerID    int64  `xorm:"NOT NULL DEFAULT 0"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	}
+
+	type DiscussionIndex struct {
+		GroupID  int64 `xorm:"pk"`
+		MaxIndex int64 `xorm:"index"`
+	}
+
+	type DiscussionComment struct {
+		ID           int64  `xorm:"pk autoincr"`
+		DiscussionID int64  `xorm:"INDEX NOT NULL"`
+		ParentID     int64  `xorm:"INDEX NOT NULL DEFAULT 0"`
+		PosterID     int64  `xorm:"INDEX"`
+		Content      string `xorm:"LONGTEXT"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type Repository struct {
+		NumDiscussions int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	type Notification struct {
+		DiscussionID int64 `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	return x.Sync2(new(DiscussionCategory), new(Discussion), new(DiscussionIndex), new(DiscussionComment), new(Repository), new(Notification))
+}
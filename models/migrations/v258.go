@@ -0,0 +1,32 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createResumableUploadTable(x *xorm.Engine) error {
+	type ResumableUpload struct {
+		ID             string `xorm:"pk"`
+		DoerID         int64  `xorm:"INDEX NOT NULL"`
+		TargetType     int    `xorm:"NOT NULL"`
+		RepoID         int64  `xorm:"NOT NULL DEFAULT 0"`
+		ReleaseID      int64  `xorm:"NOT NULL DEFAULT 0"`
+		OwnerID        int64  `xorm:"NOT NULL DEFAULT 0"`
+		PackageName    string `xorm:"NOT NULL DEFAULT ''"`
+		PackageVersion string `xorm:"NOT NULL DEFAULT ''"`
+		Filename       string `xorm:"NOT NULL"`
+		Size           int64  `xorm:"NOT NULL"`
+		BytesReceived  int64  `xorm:"NOT NULL DEFAULT 0"`
+
+		CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated INDEX NOT NULL"`
+	}
+
+	return x.Sync2(new(ResumableUpload))
+}
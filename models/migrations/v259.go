@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"xorm.io/xorm"
+)
+
+func addRepoReleaseCounts(x *xorm.Engine) error {
+	type Repository struct {
+		ID          int64
+		NumReleases int `xorm:"NOT NULL DEFAULT 0"`
+		NumTags     int `xorm:"NOT NULL DEFAULT 0"`
+	}
+
+	if err := x.Sync2(new(Repository)); err != nil {
+		return err
+	}
+
+	if _, err := x.Exec("UPDATE `repository` SET num_releases=(SELECT COUNT(*) FROM `release` WHERE `release`.repo_id=`repository`.id AND `release`.is_draft=? AND `release`.is_tag=?)", false, false); err != nil {
+		return err
+	}
+	_, err := x.Exec("UPDATE `repository` SET num_tags=(SELECT COUNT(*) FROM `release` WHERE `release`.repo_id=`repository`.id AND `release`.is_draft=?)", false)
+	return err
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createReleaseSigningKeyTable(x *xorm.Engine) error {
+	type ReleaseSigningKey struct {
+		ID          int64  `xorm:"pk autoincr"`
+		RepoID      int64  `xorm:"UNIQUE NOT NULL"`
+		KeyID       string `xorm:"CHAR(16) NOT NULL"`
+		Fingerprint string `xorm:"VARCHAR(40) NOT NULL"`
+		Identity    string
+		PublicKey   string             `xorm:"TEXT NOT NULL"`
+		PrivateKey  string             `xorm:"TEXT NOT NULL"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(new(ReleaseSigningKey))
+}
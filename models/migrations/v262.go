@@ -0,0 +1,36 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createRepoCodeStatsTable(x *xorm.Engine) error {
+	type CodeFrequencyWeek struct {
+		Week      int64
+		Additions int64
+		Deletions int64
+	}
+
+	type PunchCardHour struct {
+		Day     int
+		Hour    int
+		Commits int64
+	}
+
+	type RepoCodeStats struct {
+		ID            int64                `xorm:"pk autoincr"`
+		RepoID        int64                `xorm:"UNIQUE NOT NULL"`
+		CommitID      string               `xorm:"VARCHAR(40) NOT NULL"`
+		CodeFrequency []*CodeFrequencyWeek `xorm:"LONGTEXT JSON"`
+		PunchCard     []*PunchCardHour     `xorm:"TEXT JSON"`
+		UpdatedUnix   timeutil.TimeStamp   `xorm:"INDEX updated"`
+	}
+
+	return x.Sync2(new(RepoCodeStats))
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createAnnouncementTables(x *xorm.Engine) error {
+	type Announcement struct {
+		ID          int64              `xorm:"pk autoincr"`
+		OrgID       int64              `xorm:"INDEX NOT NULL DEFAULT 0"`
+		Content     string             `xorm:"TEXT NOT NULL"`
+		Severity    string             `xorm:"VARCHAR(20) NOT NULL DEFAULT 'info'"`
+		Dismissible bool               `xorm:"NOT NULL DEFAULT false"`
+		StartUnix   timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		EndUnix     timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+		UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+	}
+
+	type AnnouncementDismissal struct {
+		ID             int64              `xorm:"pk autoincr"`
+		AnnouncementID int64              `xorm:"UNIQUE(s) NOT NULL"`
+		UserID         int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+		CreatedUnix    timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(new(Announcement), new(AnnouncementDismissal))
+}
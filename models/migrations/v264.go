@@ -0,0 +1,22 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createMaintenanceModeTable(x *xorm.Engine) error {
+	type MaintenanceMode struct {
+		ID          int64              `xorm:"pk autoincr"`
+		Message     string             `xorm:"TEXT"`
+		DoerID      int64              `xorm:"NOT NULL DEFAULT 0"`
+		CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	}
+
+	return x.Sync2(new(MaintenanceMode))
+}
@@ -0,0 +1,26 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func createPendingOperationTable(x *xorm.Engine) error {
+	type PendingOperation struct {
+		ID            int64              `xorm:"pk autoincr"`
+		Type          string             `xorm:"VARCHAR(20) UNIQUE(s) NOT NULL"`
+		TargetID      int64              `xorm:"UNIQUE(s) NOT NULL"`
+		TargetName    string             `xorm:"NOT NULL"`
+		RequesterID   int64              `xorm:"INDEX NOT NULL"`
+		RequesterName string             `xorm:"NOT NULL"`
+		CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+		ExpiresUnix   timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	}
+
+	return x.Sync2(new(PendingOperation))
+}
@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package migrations
+
+import (
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/xorm"
+)
+
+func addRepoTransferScheduleColumns(x *xorm.Engine) error {
+	type RepoTransfer struct {
+		ScheduledUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+		RedirectDays  int                `xorm:"NOT NULL DEFAULT 0"`
+		Accepted      bool               `xorm:"NOT NULL DEFAULT false"`
+	}
+	if err := x.Sync2(new(RepoTransfer)); err != nil {
+		return err
+	}
+
+	type RepoRedirect struct {
+		ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	}
+	return x.Sync2(new(RepoRedirect))
+}
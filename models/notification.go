@@ -9,8 +9,10 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
+	discussion_model "code.gitea.io/gitea/models/discussion"
 	"code.gitea.io/gitea/models/organization"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
@@ -38,6 +40,8 @@ const (
 	NotificationStatusRead
 	// NotificationStatusPinned represents a pinned notification
 	NotificationStatusPinned
+	// NotificationStatusArchived represents an archived notification
+	NotificationStatusArchived
 )
 
 const (
@@ -49,6 +53,10 @@ const (
 	NotificationSourceCommit
 	// NotificationSourceRepository is a notification for a repository
 	NotificationSourceRepository
+	// NotificationSourceSecurityEvent is a notification of an event of the security log of the user, it has no repository
+	NotificationSourceSecurityEvent
+	// NotificationSourceDiscussion is a notification of a discussion
+	NotificationSourceDiscussion
 )
 
 // Notification represents a notification
@@ -64,12 +72,17 @@ type Notification struct {
 	CommitID  string `xorm:"INDEX"`
 	CommentID int64
 
+	SecurityEventID int64 `xorm:"NOT NULL DEFAULT 0"`
+	DiscussionID    int64 `xorm:"NOT NULL DEFAULT 0"`
+
 	UpdatedBy int64 `xorm:"INDEX NOT NULL"`
 
-	Issue      *Issue                 `xorm:"-"`
-	Repository *repo_model.Repository `xorm:"-"`
-	Comment    *Comment               `xorm:"-"`
-	User       *user_model.User       `xorm:"-"`
+	Issue         *Issue                       `xorm:"-"`
+	Repository    *repo_model.Repository       `xorm:"-"`
+	Comment       *Comment                     `xorm:"-"`
+	User          *user_model.User             `xorm:"-"`
+	SecurityEvent *user_model.SecurityEvent    `xorm:"-"`
+	Discussion    *discussion_model.Discussion `xorm:"-"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"created INDEX NOT NULL"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"updated INDEX NOT NULL"`
@@ -79,6 +92,13 @@ func init() {
 	db.RegisterModel(new(Notification))
 }
 
+// BeforeInsert will be invoked by XORM before inserting a record
+func (n *Notification) BeforeInsert() {
+	if n.ID == 0 {
+		n.ID = db.NextID()
+	}
+}
+
 // FindNotificationOptions represent the filters for notifications. If an ID is 0 it will be ignored.
 type FindNotificationOptions struct {
 	db.ListOptions
@@ -179,6 +199,95 @@ func CreateRepoTransferNotification(doer, newOwner *user_model.User, repo *repo_
 	return committer.Commit()
 }
 
+// CreateSecurityEventNotification creates a notification of an event of the security log for its user
+func CreateSecurityEventNotification(ctx context.Context, event *user_model.SecurityEvent) error {
+	return db.Insert(ctx, &Notification{
+		UserID:          event.UserID,
+		Status:          NotificationStatusUnread,
+		Source:          NotificationSourceSecurityEvent,
+		SecurityEventID: event.ID,
+		UpdatedBy:       event.UserID,
+	})
+}
+
+// CreateOrUpdateDiscussionNotifications creates a notification of a discussion for the watchers of its repository
+// and its participants who can read the discussions, or marks their existing notification of the discussion as unread
+func CreateOrUpdateDiscussionNotifications(d *discussion_model.Discussion, notificationAuthorID int64) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, d.RepoID)
+	if err != nil {
+		return err
+	}
+
+	toNotify := make(map[int64]struct{}, 32)
+	repoWatches, err := repo_model.GetRepoWatchersIDs(ctx, d.RepoID)
+	if err != nil {
+		return err
+	}
+	for _, id := range repoWatches {
+		toNotify[id] = struct{}{}
+	}
+	participants, err := discussion_model.GetParticipantIDs(ctx, d)
+	if err != nil {
+		return err
+	}
+	for _, id := range participants {
+		toNotify[id] = struct{}{}
+	}
+	// dont notify user who cause notification
+	delete(toNotify, notificationAuthorID)
+
+	for userID := range toNotify {
+		repo.Units = nil
+		user, err := user_model.GetUserByIDCtx(ctx, userID)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if !checkRepoUnitUser(ctx, repo, user, unit.TypeDiscussions) {
+			continue
+		}
+
+		notification := new(Notification)
+		has, err := db.GetEngine(ctx).Where("user_id = ? AND discussion_id = ?", userID, d.ID).Get(notification)
+		if err != nil {
+			return err
+		}
+		if !has {
+			if err := db.Insert(ctx, &Notification{
+				UserID:       userID,
+				RepoID:       d.RepoID,
+				Status:       NotificationStatusUnread,
+				Source:       NotificationSourceDiscussion,
+				DiscussionID: d.ID,
+				UpdatedBy:    notificationAuthorID,
+			}); err != nil {
+				return err
+			}
+			continue
+		}
+		notification.Status = NotificationStatusUnread
+		notification.UpdatedBy = notificationAuthorID
+		if _, err := db.GetEngine(ctx).ID(notification.ID).Cols("status", "updated_by").Update(notification); err != nil {
+			return err
+		}
+	}
+	return committer.Commit()
+}
+
+// DeleteDiscussionNotifications deletes the notifications of a deleted discussion
+func DeleteDiscussionNotifications(ctx context.Context, discussionID int64) error {
+	_, err := db.GetEngine(ctx).Where("discussion_id = ?", discussionID).Delete(&Notification{})
+	return err
+}
+
 // CreateOrUpdateIssueNotifications creates an issue notification
 // for each watcher, or updates it if already exists
 // receiverID > 0 just send to receiver, else send to all watcher
@@ -400,11 +509,17 @@ func (n *Notification) loadAttributes(ctx context.Context) (err error) {
 	if err = n.loadComment(ctx); err != nil {
 		return
 	}
+	if err = n.loadSecurityEvent(ctx); err != nil {
+		return
+	}
+	if err = n.loadDiscussion(ctx); err != nil {
+		return
+	}
 	return
 }
 
 func (n *Notification) loadRepo(ctx context.Context) (err error) {
-	if n.Repository == nil {
+	if n.Repository == nil && n.RepoID != 0 {
 		n.Repository, err = repo_model.GetRepositoryByIDCtx(ctx, n.RepoID)
 		if err != nil {
 			return fmt.Errorf("getRepositoryByID [%d]: %v", n.RepoID, err)
@@ -440,6 +555,27 @@ func (n *Notification) loadComment(ctx context.Context) (err error) {
 	return nil
 }
 
+func (n *Notification) loadSecurityEvent(ctx context.Context) (err error) {
+	if n.SecurityEvent == nil && n.SecurityEventID != 0 {
+		n.SecurityEvent, err = user_model.GetSecurityEventByID(ctx, n.SecurityEventID)
+		if err != nil {
+			return fmt.Errorf("GetSecurityEventByID [%d]: %v", n.SecurityEventID, err)
+		}
+	}
+	return nil
+}
+
+func (n *Notification) loadDiscussion(ctx context.Context) (err error) {
+	if n.Discussion == nil && n.DiscussionID != 0 {
+		n.Discussion, err = discussion_model.GetDiscussionByID(ctx, n.DiscussionID)
+		if err != nil {
+			return fmt.Errorf("GetDiscussionByID [%d]: %v", n.DiscussionID, err)
+		}
+		n.Discussion.Repo = n.Repository
+	}
+	return nil
+}
+
 func (n *Notification) loadUser(ctx context.Context) (err error) {
 	if n.User == nil {
 		n.User, err = user_model.GetUserByIDCtx(ctx, n.UserID)
@@ -472,6 +608,10 @@ func (n *Notification) HTMLURL() string {
 		return n.Repository.HTMLURL() + "/commit/" + url.PathEscape(n.CommitID)
 	case NotificationSourceRepository:
 		return n.Repository.HTMLURL()
+	case NotificationSourceSecurityEvent:
+		return setting.AppURL + "user/settings/security"
+	case NotificationSourceDiscussion:
+		return n.Repository.HTMLURL() + "/discussions/" + strconv.FormatInt(n.Discussion.Index, 10)
 	}
 	return ""
 }
@@ -499,7 +639,7 @@ func (nl NotificationList) LoadAttributes() error {
 func (nl NotificationList) getPendingRepoIDs() []int64 {
 	ids := make(map[int64]struct{}, len(nl))
 	for _, notification := range nl {
-		if notification.Repository != nil {
+		if notification.Repository != nil || notification.RepoID == 0 {
 			continue
 		}
 		if _, ok := ids[notification.RepoID]; !ok {
@@ -550,6 +690,9 @@ func (nl NotificationList) LoadRepos() (RepositoryList, []int, error) {
 
 	reposList := make(RepositoryList, 0, len(repoIDs))
 	for i, notification := range nl {
+		if notification.Source == NotificationSourceSecurityEvent {
+			continue
+		}
 		if notification.Repository == nil {
 			notification.Repository = repos[notification.RepoID]
 		}
@@ -658,6 +801,80 @@ func (nl NotificationList) Without(failures []int) NotificationList {
 	return remaining
 }
 
+func (nl NotificationList) getPendingSecurityEventIDs() []int64 {
+	ids := make(map[int64]struct{}, len(nl))
+	for _, notification := range nl {
+		if notification.SecurityEventID == 0 || notification.SecurityEvent != nil {
+			continue
+		}
+		ids[notification.SecurityEventID] = struct{}{}
+	}
+	return container.KeysInt64(ids)
+}
+
+// LoadSecurityEvents loads the events of the security log of the notifications from database
+func (nl NotificationList) LoadSecurityEvents(ctx context.Context) ([]int, error) {
+	eventIDs := nl.getPendingSecurityEventIDs()
+	if len(eventIDs) == 0 {
+		return []int{}, nil
+	}
+	events, err := user_model.GetSecurityEventsByIDs(ctx, eventIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	failures := []int{}
+	for i, notification := range nl {
+		if notification.SecurityEventID == 0 || notification.SecurityEvent != nil {
+			continue
+		}
+		notification.SecurityEvent = events[notification.SecurityEventID]
+		if notification.SecurityEvent == nil {
+			log.Error("Notification[%d]: SecurityEventID: %d not found", notification.ID, notification.SecurityEventID)
+			failures = append(failures, i)
+		}
+	}
+	return failures, nil
+}
+
+func (nl NotificationList) getPendingDiscussionIDs() []int64 {
+	ids := make(map[int64]struct{}, len(nl))
+	for _, notification := range nl {
+		if notification.DiscussionID == 0 || notification.Discussion != nil {
+			continue
+		}
+		ids[notification.DiscussionID] = struct{}{}
+	}
+	return container.KeysInt64(ids)
+}
+
+// LoadDiscussions loads the discussions of the notifications from database
+func (nl NotificationList) LoadDiscussions(ctx context.Context) ([]int, error) {
+	discussionIDs := nl.getPendingDiscussionIDs()
+	if len(discussionIDs) == 0 {
+		return []int{}, nil
+	}
+	discussions := make(map[int64]*discussion_model.Discussion, len(discussionIDs))
+	if err := db.GetEngine(ctx).In("id", discussionIDs).Find(&discussions); err != nil {
+		return nil, err
+	}
+
+	failures := []int{}
+	for i, notification := range nl {
+		if notification.DiscussionID == 0 || notification.Discussion != nil {
+			continue
+		}
+		notification.Discussion = discussions[notification.DiscussionID]
+		if notification.Discussion == nil {
+			log.Error("Notification[%d]: DiscussionID: %d not found", notification.ID, notification.DiscussionID)
+			failures = append(failures, i)
+			continue
+		}
+		notification.Discussion.Repo = notification.Repository
+	}
+	return failures, nil
+}
+
 func (nl NotificationList) getPendingCommentIDs() []int64 {
 	ids := make(map[int64]struct{}, len(nl))
 	for _, notification := range nl {
@@ -822,3 +1039,41 @@ func UpdateNotificationStatuses(user *user_model.User, currentStatus, desiredSta
 		Update(n)
 	return err
 }
+
+// ArchiveOldReadNotifications archives all read notifications that have not been updated for longer than olderThan
+func ArchiveOldReadNotifications(ctx context.Context, olderThan time.Duration) error {
+	if olderThan <= 0 {
+		return nil
+	}
+
+	_, err := db.GetEngine(ctx).
+		Where("status = ? AND updated_unix < ?", NotificationStatusRead, time.Now().Add(-olderThan).Unix()).
+		Cols("status").
+		NoAutoTime().
+		Update(&Notification{Status: NotificationStatusArchived})
+	return err
+}
+
+// DeleteOldReadNotifications deletes all read and archived notifications that have not been updated for longer than olderThan
+func DeleteOldReadNotifications(ctx context.Context, olderThan time.Duration) error {
+	if olderThan <= 0 {
+		return nil
+	}
+
+	_, err := db.GetEngine(ctx).
+		Where(builder.In("status", NotificationStatusRead, NotificationStatusArchived)).
+		And("updated_unix < ?", time.Now().Add(-olderThan).Unix()).
+		Delete(&Notification{})
+	return err
+}
+
+// ArchiveAllNotifications archives all of a user's read and unread notifications, pinned notifications are kept
+func ArchiveAllNotifications(user *user_model.User) error {
+	n := &Notification{Status: NotificationStatusArchived, UpdatedBy: user.ID}
+	_, err := db.GetEngine(db.DefaultContext).
+		Where("user_id = ?", user.ID).
+		And(builder.In("status", NotificationStatusUnread, NotificationStatusRead)).
+		Cols("status", "updated_by", "updated_unix").
+		Update(n)
+	return err
+}
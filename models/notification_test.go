@@ -6,10 +6,12 @@ package models
 
 import (
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -108,3 +110,60 @@ func TestUpdateNotificationStatuses(t *testing.T) {
 	unittest.AssertExistsAndLoadBean(t,
 		&Notification{ID: notfPinned.ID, Status: NotificationStatusPinned})
 }
+
+func TestArchiveOldReadNotifications(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	assert.NoError(t, ArchiveOldReadNotifications(db.DefaultContext, time.Hour))
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 2, Status: NotificationStatusArchived})
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 1, Status: NotificationStatusUnread})
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 3, Status: NotificationStatusPinned})
+}
+
+func TestDeleteOldReadNotifications(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	assert.NoError(t, DeleteOldReadNotifications(db.DefaultContext, time.Hour))
+	unittest.AssertNotExistsBean(t, &Notification{ID: 2})
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 1})
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 3})
+}
+
+func TestArchiveAllNotifications(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	assert.NoError(t, ArchiveAllNotifications(user))
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 2, Status: NotificationStatusArchived})
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 4, Status: NotificationStatusArchived})
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 3, Status: NotificationStatusPinned})
+	unittest.AssertExistsAndLoadBean(t, &Notification{ID: 1, Status: NotificationStatusUnread})
+}
+
+func TestCreateSecurityEventNotification(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	event := &user_model.SecurityEvent{UserID: user.ID, Type: user_model.SecurityEventTokenCreate, Detail: "token"}
+	assert.NoError(t, user_model.InsertSecurityEvent(db.DefaultContext, event))
+	assert.NoError(t, CreateSecurityEventNotification(db.DefaultContext, event))
+
+	notfs, err := NotificationsForUser(db.DefaultContext, user, []NotificationStatus{NotificationStatusUnread}, 1, 10)
+	assert.NoError(t, err)
+	// security event notifications have no repository and aren't dropped while loading the repositories
+	_, failures, err := notfs.LoadRepos()
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+	failures, err = notfs.LoadSecurityEvents(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.Empty(t, failures)
+
+	var found *Notification
+	for _, n := range notfs {
+		if n.Source == NotificationSourceSecurityEvent {
+			found = n
+		}
+	}
+	if assert.NotNil(t, found) {
+		assert.Nil(t, found.Repository)
+		assert.Equal(t, event.ID, found.SecurityEvent.ID)
+		assert.Equal(t, setting.AppURL+"user/settings/security", found.HTMLURL())
+	}
+}
@@ -0,0 +1,191 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/gobwas/glob"
+)
+
+// ErrOrgProtectedBranchNotExist represents a "OrgProtectedBranchNotExist" kind of error.
+type ErrOrgProtectedBranchNotExist struct {
+	ID    int64
+	OrgID int64
+}
+
+// IsErrOrgProtectedBranchNotExist checks if an error is a ErrOrgProtectedBranchNotExist.
+func IsErrOrgProtectedBranchNotExist(err error) bool {
+	_, ok := err.(ErrOrgProtectedBranchNotExist)
+	return ok
+}
+
+func (err ErrOrgProtectedBranchNotExist) Error() string {
+	return fmt.Sprintf("organization branch protection does not exist [id: %d, org_id: %d]", err.ID, err.OrgID)
+}
+
+// ErrInvalidBranchPattern represents a "InvalidBranchPattern" kind of error.
+type ErrInvalidBranchPattern struct {
+	Pattern string
+}
+
+// IsErrInvalidBranchPattern checks if an error is a ErrInvalidBranchPattern.
+func IsErrInvalidBranchPattern(err error) bool {
+	_, ok := err.(ErrInvalidBranchPattern)
+	return ok
+}
+
+func (err ErrInvalidBranchPattern) Error() string {
+	return fmt.Sprintf("invalid branch pattern [pattern: %s]", err.Pattern)
+}
+
+// OrgProtectedBranch represents a set of branch protection rules defined by an organization.
+// It is inherited by all branches matching its pattern in the repositories of the organization.
+type OrgProtectedBranch struct {
+	ID    int64 `xorm:"pk autoincr"`
+	OrgID int64 `xorm:"INDEX NOT NULL"`
+	// BranchPattern is a glob matched against branch names, e.g. "main" or "release/*"
+	BranchPattern string `xorm:"NOT NULL"`
+	// ApplyToExisting applies the rules to all repositories, otherwise only to repositories created afterwards
+	ApplyToExisting bool `xorm:"NOT NULL DEFAULT true"`
+	// AllowOverride lets repository administrators replace the rules with their own branch protection
+	AllowOverride bool `xorm:"NOT NULL DEFAULT false"`
+
+	CanPush                bool     `xorm:"NOT NULL DEFAULT false"`
+	EnableStatusCheck      bool     `xorm:"NOT NULL DEFAULT false"`
+	StatusCheckContexts    []string `xorm:"JSON TEXT"`
+	RequiredApprovals      int64    `xorm:"NOT NULL DEFAULT 0"`
+	BlockOnRejectedReviews bool     `xorm:"NOT NULL DEFAULT false"`
+	DismissStaleApprovals  bool     `xorm:"NOT NULL DEFAULT false"`
+	RequireSignedCommits   bool     `xorm:"NOT NULL DEFAULT false"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(OrgProtectedBranch))
+}
+
+func compileBranchPattern(pattern string) (glob.Glob, error) {
+	g, err := glob.Compile(pattern, '/')
+	if err != nil {
+		return nil, ErrInvalidBranchPattern{Pattern: pattern}
+	}
+	return g, nil
+}
+
+// Match returns true if the branch name matches the pattern of the rules
+func (rule *OrgProtectedBranch) Match(branchName string) bool {
+	g, err := compileBranchPattern(rule.BranchPattern)
+	if err != nil {
+		return false
+	}
+	return g.Match(branchName)
+}
+
+// AppliesTo returns true if the rules are inherited by the repository
+func (rule *OrgProtectedBranch) AppliesTo(repo *repo_model.Repository) bool {
+	return repo.OwnerID == rule.OrgID && (rule.ApplyToExisting || repo.CreatedUnix >= rule.CreatedUnix)
+}
+
+// ToProtectedBranch returns the protection the rules impose on a branch of a repository
+func (rule *OrgProtectedBranch) ToProtectedBranch(repoID int64, branchName string) *ProtectedBranch {
+	return &ProtectedBranch{
+		RepoID:                 repoID,
+		BranchName:             branchName,
+		CanPush:                rule.CanPush,
+		EnableStatusCheck:      rule.EnableStatusCheck,
+		StatusCheckContexts:    rule.StatusCheckContexts,
+		RequiredApprovals:      rule.RequiredApprovals,
+		BlockOnRejectedReviews: rule.BlockOnRejectedReviews,
+		DismissStaleApprovals:  rule.DismissStaleApprovals,
+		RequireSignedCommits:   rule.RequireSignedCommits,
+		OrgRule:                rule,
+	}
+}
+
+// CreateOrgProtectedBranch saves new branch protection rules of an organization
+func CreateOrgProtectedBranch(ctx context.Context, rule *OrgProtectedBranch) error {
+	rule.BranchPattern = strings.TrimSpace(rule.BranchPattern)
+	if _, err := compileBranchPattern(rule.BranchPattern); err != nil {
+		return err
+	}
+	return db.Insert(ctx, rule)
+}
+
+// GetOrgProtectedBranchByID returns the branch protection rules of an organization by id
+func GetOrgProtectedBranchByID(ctx context.Context, orgID, id int64) (*OrgProtectedBranch, error) {
+	rule := new(OrgProtectedBranch)
+	has, err := db.GetEngine(ctx).Where("id = ? AND org_id = ?", id, orgID).Get(rule)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrOrgProtectedBranchNotExist{ID: id, OrgID: orgID}
+	}
+	return rule, nil
+}
+
+// GetOrgProtectedBranches returns all branch protection rules of an organization
+func GetOrgProtectedBranches(ctx context.Context, orgID int64) ([]*OrgProtectedBranch, error) {
+	rules := make([]*OrgProtectedBranch, 0, 5)
+	return rules, db.GetEngine(ctx).Where("org_id = ?", orgID).Asc("id").Find(&rules)
+}
+
+// GetOrgProtectedBranchesForRepo returns the branch protection rules inherited by a repository
+func GetOrgProtectedBranchesForRepo(ctx context.Context, repo *repo_model.Repository) ([]*OrgProtectedBranch, error) {
+	rules, err := GetOrgProtectedBranches(ctx, repo.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	inherited := rules[:0]
+	for _, rule := range rules {
+		if rule.AppliesTo(repo) {
+			inherited = append(inherited, rule)
+		}
+	}
+	return inherited, nil
+}
+
+// GetOrgProtectedBranchFor returns the first organization rules protecting a branch of a repository, or nil
+func GetOrgProtectedBranchFor(ctx context.Context, repo *repo_model.Repository, branchName string) (*OrgProtectedBranch, error) {
+	rules, err := GetOrgProtectedBranchesForRepo(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	for _, rule := range rules {
+		if rule.Match(branchName) {
+			return rule, nil
+		}
+	}
+	return nil, nil
+}
+
+// UpdateOrgProtectedBranch updates branch protection rules of an organization
+func UpdateOrgProtectedBranch(ctx context.Context, rule *OrgProtectedBranch) error {
+	rule.BranchPattern = strings.TrimSpace(rule.BranchPattern)
+	if _, err := compileBranchPattern(rule.BranchPattern); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).ID(rule.ID).AllCols().Update(rule)
+	return err
+}
+
+// DeleteOrgProtectedBranch deletes branch protection rules of an organization
+func DeleteOrgProtectedBranch(ctx context.Context, orgID, id int64) error {
+	affected, err := db.GetEngine(ctx).Where("id = ? AND org_id = ?", id, orgID).Delete(new(OrgProtectedBranch))
+	if err != nil {
+		return err
+	} else if affected == 0 {
+		return ErrOrgProtectedBranchNotExist{ID: id, OrgID: orgID}
+	}
+	return nil
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOrgProtectedBranch_Match(t *testing.T) {
+	rule := &OrgProtectedBranch{BranchPattern: "release/*"}
+	assert.True(t, rule.Match("release/1.17"))
+	assert.False(t, rule.Match("release/1.17/fix"))
+	assert.False(t, rule.Match("main"))
+
+	rule.BranchPattern = "**"
+	assert.True(t, rule.Match("release/1.17/fix"))
+}
+
+func TestOrgProtectedBranches(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	err := CreateOrgProtectedBranch(db.DefaultContext, &OrgProtectedBranch{OrgID: 3, BranchPattern: "[main"})
+	assert.True(t, IsErrInvalidBranchPattern(err))
+
+	rule := &OrgProtectedBranch{OrgID: 3, BranchPattern: " master ", ApplyToExisting: true, RequiredApprovals: 2}
+	assert.NoError(t, CreateOrgProtectedBranch(db.DefaultContext, rule))
+	assert.EqualValues(t, "master", rule.BranchPattern)
+	assert.NoError(t, CreateOrgProtectedBranch(db.DefaultContext, &OrgProtectedBranch{OrgID: 3, BranchPattern: "**"}))
+	assert.NoError(t, CreateOrgProtectedBranch(db.DefaultContext, &OrgProtectedBranch{OrgID: 2, BranchPattern: "**", ApplyToExisting: true}))
+
+	rules, err := GetOrgProtectedBranches(db.DefaultContext, 3)
+	assert.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	// only rules applying to existing repositories are inherited by repositories created before them
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
+	rules, err = GetOrgProtectedBranchesForRepo(db.DefaultContext, repo)
+	assert.NoError(t, err)
+	if assert.Len(t, rules, 1) {
+		assert.EqualValues(t, rule.ID, rules[0].ID)
+	}
+
+	found, err := GetOrgProtectedBranchFor(db.DefaultContext, repo, "master")
+	assert.NoError(t, err)
+	if assert.NotNil(t, found) {
+		assert.EqualValues(t, rule.ID, found.ID)
+	}
+	found, err = GetOrgProtectedBranchFor(db.DefaultContext, repo, "develop")
+	assert.NoError(t, err)
+	assert.Nil(t, found)
+
+	rule.BranchPattern = "ma*"
+	assert.NoError(t, UpdateOrgProtectedBranch(db.DefaultContext, rule))
+	rule, err = GetOrgProtectedBranchByID(db.DefaultContext, 3, rule.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "ma*", rule.BranchPattern)
+
+	_, err = GetOrgProtectedBranchByID(db.DefaultContext, 2, rule.ID)
+	assert.True(t, IsErrOrgProtectedBranchNotExist(err))
+	assert.True(t, IsErrOrgProtectedBranchNotExist(DeleteOrgProtectedBranch(db.DefaultContext, 2, rule.ID)))
+	assert.NoError(t, DeleteOrgProtectedBranch(db.DefaultContext, 3, rule.ID))
+	unittest.AssertNotExistsBean(t, &OrgProtectedBranch{ID: rule.ID})
+}
+
+func TestGetEffectiveProtectedBranch(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
+	bp, err := GetEffectiveProtectedBranch(db.DefaultContext, repo.ID, "master")
+	assert.NoError(t, err)
+	assert.Nil(t, bp)
+
+	rule := &OrgProtectedBranch{OrgID: 3, BranchPattern: "master", ApplyToExisting: true, RequiredApprovals: 2, RequireSignedCommits: true}
+	assert.NoError(t, CreateOrgProtectedBranch(db.DefaultContext, rule))
+
+	bp, err = GetEffectiveProtectedBranch(db.DefaultContext, repo.ID, "master")
+	assert.NoError(t, err)
+	if assert.NotNil(t, bp) {
+		assert.True(t, bp.IsProtected())
+		assert.EqualValues(t, rule.ID, bp.OrgRule.ID)
+		assert.EqualValues(t, 2, bp.RequiredApprovals)
+		assert.True(t, bp.RequireSignedCommits)
+	}
+	protected, err := IsProtectedBranch(repo.ID, "master")
+	assert.NoError(t, err)
+	assert.True(t, protected)
+
+	// the protected branch of the repository is ignored unless the rules allow overrides
+	assert.NoError(t, UpdateProtectBranch(db.DefaultContext, repo, &ProtectedBranch{RepoID: repo.ID, BranchName: "master", RequiredApprovals: 1}, WhitelistOptions{}))
+	bp, err = GetEffectiveProtectedBranch(db.DefaultContext, repo.ID, "master")
+	assert.NoError(t, err)
+	assert.NotNil(t, bp.OrgRule)
+	assert.EqualValues(t, 2, bp.RequiredApprovals)
+
+	rule.AllowOverride = true
+	assert.NoError(t, UpdateOrgProtectedBranch(db.DefaultContext, rule))
+	bp, err = GetEffectiveProtectedBranch(db.DefaultContext, repo.ID, "master")
+	assert.NoError(t, err)
+	assert.Nil(t, bp.OrgRule)
+	assert.EqualValues(t, 1, bp.RequiredApprovals)
+}
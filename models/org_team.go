@@ -348,7 +348,7 @@ func UpdateTeam(t *organization.Team, authChanged, includeAllChanged bool) (err
 	}
 
 	if _, err = sess.ID(t.ID).Cols("name", "lower_name", "description",
-		"can_create_org_repo", "authorize", "includes_all_repositories").Update(t); err != nil {
+		"can_create_org_repo", "authorize", "includes_all_repositories", "restricted_release_actions").Update(t); err != nil {
 		return fmt.Errorf("update: %v", err)
 	}
 
@@ -0,0 +1,68 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package organization
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// OrgKeyPolicy represents the requirements an organization places on the SSH and GPG keys of its members.
+// A zero value of a rule disables it.
+type OrgKeyPolicy struct {
+	ID    int64 `xorm:"pk autoincr"`
+	OrgID int64 `xorm:"UNIQUE NOT NULL"`
+	// SSHKeyMaxAgeYears is the age in years members need at least one SSH key younger than,
+	// older keys can't be used to access the repositories of the organization
+	SSHKeyMaxAgeYears int `xorm:"NOT NULL DEFAULT 0"`
+	// MinRSAKeySize is the minimum size in bits of RSA keys
+	MinRSAKeySize int  `xorm:"NOT NULL DEFAULT 0"`
+	ForbidDSAKeys bool `xorm:"NOT NULL DEFAULT false"`
+	// RequireGPGKey requires members to have a verified GPG key to sign their commits with
+	RequireGPGKey bool               `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix   timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+func init() {
+	db.RegisterModel(new(OrgKeyPolicy))
+}
+
+// IsEnabled returns true if any rule of the policy is enabled
+func (p *OrgKeyPolicy) IsEnabled() bool {
+	return p.SSHKeyMaxAgeYears > 0 || p.MinRSAKeySize > 0 || p.ForbidDSAKeys || p.RequireGPGKey
+}
+
+// HasSSHKeyRules returns true if the policy restricts the SSH keys of the members
+func (p *OrgKeyPolicy) HasSSHKeyRules() bool {
+	return p.SSHKeyMaxAgeYears > 0 || p.MinRSAKeySize > 0 || p.ForbidDSAKeys
+}
+
+// GetKeyPolicyByOrgID returns the key policy of the organization, a policy without any rule if it has none
+func GetKeyPolicyByOrgID(ctx context.Context, orgID int64) (*OrgKeyPolicy, error) {
+	policy := &OrgKeyPolicy{OrgID: orgID}
+	if _, err := db.GetEngine(ctx).Where("org_id = ?", orgID).Get(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// SetKeyPolicy inserts or updates the key policy of an organization
+func SetKeyPolicy(ctx context.Context, policy *OrgKeyPolicy) error {
+	existing, err := GetKeyPolicyByOrgID(ctx, policy.OrgID)
+	if err != nil {
+		return err
+	}
+	if existing.ID == 0 {
+		return db.Insert(ctx, policy)
+	}
+	policy.ID = existing.ID
+	_, err = db.GetEngine(ctx).ID(policy.ID).
+		Cols("ssh_key_max_age_years", "min_rsa_key_size", "forbid_dsa_keys", "require_gpg_key").
+		Update(policy)
+	return err
+}
@@ -361,6 +361,7 @@ func DeleteOrganization(ctx context.Context, org *Organization) error {
 		&OrgUser{OrgID: org.ID},
 		&TeamUser{OrgID: org.ID},
 		&TeamUnit{OrgID: org.ID},
+		&OrgKeyPolicy{OrgID: org.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
@@ -491,6 +492,17 @@ func GetOrgsCanCreateRepoByUserID(userID int64) ([]*Organization, error) {
 		Find(&orgs)
 }
 
+// GetOwnedOrgIDsByUserID returns the ids of the organizations owned by the user
+func GetOwnedOrgIDsByUserID(ctx context.Context, userID int64) ([]int64, error) {
+	orgIDs := make([]int64, 0, 10)
+	return orgIDs, db.GetEngine(ctx).Table("team_user").
+		Join("INNER", "`team`", "`team`.id = `team_user`.team_id").
+		Where(builder.Eq{"`team_user`.uid": userID}).
+		And(builder.Eq{"`team`.authorize": perm.AccessModeOwner}).
+		Distinct("`team_user`.org_id").
+		Find(&orgIDs)
+}
+
 // GetOrgUsersByUserID returns all organization-user relations by user ID.
 func GetOrgUsersByUserID(uid int64, opts *SearchOrganizationsOptions) ([]*OrgUser, error) {
 	ous := make([]*OrgUser, 0, 10)
@@ -78,6 +78,8 @@ type Team struct {
 	Units                   []*TeamUnit `xorm:"-"`
 	IncludesAllRepositories bool        `xorm:"NOT NULL DEFAULT false"`
 	CanCreateOrgRepo        bool        `xorm:"NOT NULL DEFAULT false"`
+	// RestrictedReleaseActions are the release actions members are not allowed to do even with write access to releases
+	RestrictedReleaseActions perm.ReleaseAction `xorm:"NOT NULL DEFAULT 0"`
 }
 
 func init() {
@@ -237,6 +239,17 @@ func (t *Team) GetMembersCtx(ctx context.Context) (err error) {
 	return err
 }
 
+// AllowedReleaseActions returns the release actions members can do if the team has write access to releases
+func (t *Team) AllowedReleaseActions() perm.ReleaseAction {
+	return perm.ReleaseActionAll &^ t.RestrictedReleaseActions
+}
+
+// CanDoReleaseAction returns true if the team is allowed to do the release action.
+// It is called in templates.
+func (t *Team) CanDoReleaseAction(action perm.ReleaseAction) bool {
+	return t.AllowedReleaseActions().Has(action)
+}
+
 // UnitEnabled returns if the team has the given unit type enabled
 func (t *Team) UnitEnabled(tp unit.Type) bool {
 	return t.UnitAccessMode(tp) > perm.AccessModeNone
@@ -10,6 +10,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/perm"
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"xorm.io/builder"
 )
@@ -20,6 +21,9 @@ type TeamRepo struct {
 	OrgID  int64 `xorm:"INDEX"`
 	TeamID int64 `xorm:"UNIQUE(s)"`
 	RepoID int64 `xorm:"UNIQUE(s)"`
+	// ExpiresUnix is the time the team loses access to the repository, zero means it never expires
+	ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	GrantedByID int64              `xorm:"NOT NULL DEFAULT 0"`
 }
 
 // HasTeamRepo returns true if given repository belongs to team.
@@ -83,3 +87,29 @@ func GetTeamsWithAccessToRepo(ctx context.Context, orgID, repoID int64, mode per
 		And("team_repo.repo_id = ?", repoID).
 		Find(&teams)
 }
+
+// SetTeamRepoExpiry sets the time the team loses access to the repository, a zero expiry makes the access permanent.
+func SetTeamRepoExpiry(ctx context.Context, teamID, repoID int64, expires timeutil.TimeStamp, doerID int64) error {
+	_, err := db.GetEngine(ctx).
+		Where("team_id = ? AND repo_id = ?", teamID, repoID).
+		Cols("expires_unix", "granted_by_id").
+		Update(&TeamRepo{ExpiresUnix: expires, GrantedByID: doerID})
+	return err
+}
+
+// FindExpiredTeamRepos returns the team repositories which have expired before `now`
+func FindExpiredTeamRepos(ctx context.Context, now timeutil.TimeStamp) ([]*TeamRepo, error) {
+	teamRepos := make([]*TeamRepo, 0, 10)
+	return teamRepos, db.GetEngine(ctx).
+		Where("expires_unix > 0 AND expires_unix <= ?", now).
+		Find(&teamRepos)
+}
+
+// FindExpiringTeamRepos returns the team repositories of a repository which have an expiry
+func FindExpiringTeamRepos(ctx context.Context, repoID int64) ([]*TeamRepo, error) {
+	teamRepos := make([]*TeamRepo, 0, 10)
+	return teamRepos, db.GetEngine(ctx).
+		Where("repo_id = ? AND expires_unix > 0", repoID).
+		Asc("expires_unix").
+		Find(&teamRepos)
+}
@@ -24,6 +24,7 @@ type BlobSearchOptions struct {
 	Image      string
 	Digest     string
 	Tag        string
+	Subject    string
 	IsManifest bool
 }
 
@@ -44,6 +45,16 @@ func (opts *BlobSearchOptions) toConds() builder.Cond {
 	if opts.IsManifest {
 		cond = cond.And(builder.Eq{"package_file.lower_name": ManifestFilename})
 	}
+	if opts.Subject != "" {
+		var propsCond builder.Cond = builder.Eq{
+			"package_property.ref_type": packages.PropertyTypeVersion,
+			"package_property.name":     container_module.PropertyManifestSubject,
+			"package_property.value":    opts.Subject,
+		}
+
+		cond = cond.And(builder.Eq{"package_version.is_internal": false})
+		cond = cond.And(builder.In("package_version.id", builder.Select("package_property.ref_id").Where(propsCond).From("package_property")))
+	}
 	if opts.Digest != "" {
 		var propsCond builder.Cond = builder.Eq{
 			"package_property.ref_type": packages.PropertyTypeFile,
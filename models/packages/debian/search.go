@@ -0,0 +1,73 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/packages"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+
+	"xorm.io/builder"
+)
+
+// GetDistributions gets all available distributions of the owner
+func GetDistributions(ctx context.Context, ownerID int64) ([]string, error) {
+	return getDistinctPropertyValues(ctx, ownerID, "", debian_module.PropertyDistribution)
+}
+
+// GetComponents gets all available components of the distribution
+func GetComponents(ctx context.Context, ownerID int64, distribution string) ([]string, error) {
+	return getDistinctPropertyValues(ctx, ownerID, distribution, debian_module.PropertyComponent)
+}
+
+// GetArchitectures gets all available architectures of the distribution
+func GetArchitectures(ctx context.Context, ownerID int64, distribution string) ([]string, error) {
+	return getDistinctPropertyValues(ctx, ownerID, distribution, debian_module.PropertyArchitecture)
+}
+
+// GetOwnerIDs gets the ids of all owners which have Debian packages
+func GetOwnerIDs(ctx context.Context) ([]int64, error) {
+	ownerIDs := make([]int64, 0, 10)
+	return ownerIDs, db.GetEngine(ctx).
+		Table("package").
+		Distinct("owner_id").
+		Where(builder.Eq{"type": packages.TypeDebian}).
+		Find(&ownerIDs)
+}
+
+func getDistinctPropertyValues(ctx context.Context, ownerID int64, distribution, propName string) ([]string, error) {
+	var cond builder.Cond = builder.Eq{
+		"package_property.ref_type":   packages.PropertyTypeFile,
+		"package_property.name":       propName,
+		"package.type":                packages.TypeDebian,
+		"package.owner_id":            ownerID,
+		"package_version.is_internal": false,
+	}
+	if distribution != "" {
+		cond = cond.And(builder.In(
+			"package_file.id",
+			builder.Select("pp.ref_id").
+				From("package_property", "pp").
+				Where(builder.Eq{
+					"pp.ref_type": packages.PropertyTypeFile,
+					"pp.name":     debian_module.PropertyDistribution,
+					"pp.value":    distribution,
+				}),
+		))
+	}
+
+	values := make([]string, 0, 5)
+	return values, db.GetEngine(ctx).
+		Table("package_property").
+		Distinct("package_property.value").
+		Join("INNER", "package_file", "package_file.id = package_property.ref_id").
+		Join("INNER", "package_version", "package_version.id = package_file.version_id").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where(cond).
+		OrderBy("package_property.value").
+		Find(&values)
+}
@@ -31,9 +31,11 @@ type Type string
 
 // List of supported packages
 const (
+	TypeCargo     Type = "cargo"
 	TypeComposer  Type = "composer"
 	TypeConan     Type = "conan"
 	TypeContainer Type = "container"
+	TypeDebian    Type = "debian"
 	TypeGeneric   Type = "generic"
 	TypeHelm      Type = "helm"
 	TypeMaven     Type = "maven"
@@ -41,17 +43,22 @@ const (
 	TypeNuGet     Type = "nuget"
 	TypePyPI      Type = "pypi"
 	TypeRubyGems  Type = "rubygems"
+	TypeTerraform Type = "terraform"
 )
 
 // Name gets the name of the package type
 func (pt Type) Name() string {
 	switch pt {
+	case TypeCargo:
+		return "Cargo"
 	case TypeComposer:
 		return "Composer"
 	case TypeConan:
 		return "Conan"
 	case TypeContainer:
 		return "Container"
+	case TypeDebian:
+		return "Debian"
 	case TypeGeneric:
 		return "Generic"
 	case TypeHelm:
@@ -66,6 +73,8 @@ func (pt Type) Name() string {
 		return "PyPI"
 	case TypeRubyGems:
 		return "RubyGems"
+	case TypeTerraform:
+		return "Terraform"
 	}
 	panic(fmt.Sprintf("unknown package type: %s", string(pt)))
 }
@@ -73,12 +82,16 @@ func (pt Type) Name() string {
 // SVGName gets the name of the package type svg image
 func (pt Type) SVGName() string {
 	switch pt {
+	case TypeCargo:
+		return "gitea-cargo"
 	case TypeComposer:
 		return "gitea-composer"
 	case TypeConan:
 		return "gitea-conan"
 	case TypeContainer:
 		return "octicon-container"
+	case TypeDebian:
+		return "gitea-debian"
 	case TypeGeneric:
 		return "octicon-package"
 	case TypeHelm:
@@ -93,6 +106,8 @@ func (pt Type) SVGName() string {
 		return "gitea-python"
 	case TypeRubyGems:
 		return "gitea-rubygems"
+	case TypeTerraform:
+		return "gitea-terraform"
 	}
 	panic(fmt.Sprintf("unknown package type: %s", string(pt)))
 }
@@ -192,6 +207,14 @@ func GetPackagesByType(ctx context.Context, ownerID int64, packageType Type) ([]
 		Find(&ps)
 }
 
+// GetPackagesByOwner gets all packages of an owner
+func GetPackagesByOwner(ctx context.Context, ownerID int64) ([]*Package, error) {
+	ps := make([]*Package, 0, 10)
+	return ps, db.GetEngine(ctx).
+		Where("owner_id = ?", ownerID).
+		Find(&ps)
+}
+
 // DeletePackagesIfUnreferenced deletes a package if there are no associated versions
 func DeletePackagesIfUnreferenced(ctx context.Context) error {
 	in := builder.
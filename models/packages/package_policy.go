@@ -0,0 +1,104 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+func init() {
+	db.RegisterModel(new(PackagePolicy))
+}
+
+// PackagePolicy represents the quota and the retention rules for the packages of an owner.
+// A value of 0 disables the specific limit or rule.
+type PackagePolicy struct {
+	ID                    int64              `xorm:"pk autoincr"`
+	OwnerID               int64              `xorm:"UNIQUE NOT NULL"`
+	MaxCount              int64              `xorm:"NOT NULL DEFAULT 0"`
+	MaxSize               int64              `xorm:"NOT NULL DEFAULT 0"`
+	KeepLastVersions      int                `xorm:"NOT NULL DEFAULT 0"`
+	UntaggedRetentionDays int                `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix           timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix           timeutil.TimeStamp `xorm:"updated NOT NULL"`
+}
+
+// HasRetentionRules tests if the policy contains rules to remove package versions
+func (p *PackagePolicy) HasRetentionRules() bool {
+	return p.KeepLastVersions > 0 || p.UntaggedRetentionDays > 0
+}
+
+// GetPolicyByOwnerID gets the policy of the owner. If the owner has no policy, an empty policy is returned
+func GetPolicyByOwnerID(ctx context.Context, ownerID int64) (*PackagePolicy, error) {
+	p := &PackagePolicy{OwnerID: ownerID}
+	if _, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Get(p); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// SetPolicy inserts or updates the policy of the owner
+func SetPolicy(ctx context.Context, p *PackagePolicy) error {
+	e := db.GetEngine(ctx)
+
+	existing := &PackagePolicy{}
+	has, err := e.Where("owner_id = ?", p.OwnerID).Get(existing)
+	if err != nil {
+		return err
+	}
+	if !has {
+		_, err = e.Insert(p)
+		return err
+	}
+	p.ID = existing.ID
+	_, err = e.ID(p.ID).AllCols().Update(p)
+	return err
+}
+
+// DeletePolicyByOwnerID deletes the policy of the owner
+func DeletePolicyByOwnerID(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(&PackagePolicy{})
+	return err
+}
+
+// FindPoliciesWithRetentionRules gets all policies which contain rules to remove package versions
+func FindPoliciesWithRetentionRules(ctx context.Context) ([]*PackagePolicy, error) {
+	ps := make([]*PackagePolicy, 0, 10)
+	return ps, db.GetEngine(ctx).
+		Where(builder.Gt{"keep_last_versions": 0}.Or(builder.Gt{"untagged_retention_days": 0})).
+		Find(&ps)
+}
+
+// CountOwnerVersions counts the package versions of the owner
+func CountOwnerVersions(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Table("package_version").
+		Join("INNER", "package", "package.id = package_version.package_id").
+		Where(builder.Eq{
+			"package.owner_id":            ownerID,
+			"package_version.is_internal": false,
+		}).
+		Count(&PackageVersion{})
+}
+
+// CalculateOwnerBlobSize calculates the size of all blobs referenced by the packages of the owner.
+// A blob used by multiple files is counted once.
+func CalculateOwnerBlobSize(ctx context.Context, ownerID int64) (int64, error) {
+	in := builder.
+		Select("package_file.blob_id").
+		From("package_file").
+		InnerJoin("package_version", "package_version.id = package_file.version_id").
+		InnerJoin("package", "package.id = package_version.package_id").
+		Where(builder.Eq{"package.owner_id": ownerID})
+
+	return db.GetEngine(ctx).
+		Where(builder.In("id", in)).
+		SumInt(&PackageBlob{}, "size")
+}
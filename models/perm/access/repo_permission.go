@@ -22,6 +22,8 @@ type Permission struct {
 	AccessMode perm_model.AccessMode
 	Units      []*repo_model.RepoUnit
 	UnitsMode  map[unit.Type]perm_model.AccessMode
+	// RestrictedReleaseActions are the release actions which are denied despite the write access to releases
+	RestrictedReleaseActions perm_model.ReleaseAction
 }
 
 // IsOwner returns true if current user is the owner of repository.
@@ -103,6 +105,11 @@ func (p *Permission) CanWriteIssuesOrPulls(isPull bool) bool {
 	return p.CanWrite(unit.TypeIssues)
 }
 
+// CanDoReleaseAction returns true if user could write to releases and the action is not restricted
+func (p *Permission) CanDoReleaseAction(action perm_model.ReleaseAction) bool {
+	return p.CanWrite(unit.TypeReleases) && p.RestrictedReleaseActions&action == 0
+}
+
 // ColorFormat writes a colored string for these Permissions
 func (p *Permission) ColorFormat(s fmt.State) {
 	noColor := log.ColorBytes(log.Reset)
@@ -263,6 +270,29 @@ func GetUserRepoPermission(ctx context.Context, repo *repo_model.Repository, use
 		}
 	}
 
+	// release actions can only be restricted if the write access to releases is granted by teams alone
+	if perm.UnitsMode[unit.TypeReleases] >= perm_model.AccessModeWrite {
+		if is {
+			var collaboration *repo_model.Collaboration
+			collaboration, err = repo_model.GetCollaboration(ctx, repo.ID, user.ID)
+			if err != nil {
+				return
+			}
+			if collaboration != nil && collaboration.Mode >= perm_model.AccessModeWrite {
+				teams = nil
+			}
+		}
+		if len(teams) > 0 {
+			allowed := perm_model.ReleaseActionNone
+			for _, team := range teams {
+				if team.UnitAccessModeCtx(ctx, unit.TypeReleases) >= perm_model.AccessModeWrite {
+					allowed |= team.AllowedReleaseActions()
+				}
+			}
+			perm.RestrictedReleaseActions = perm_model.ReleaseActionAll &^ allowed
+		}
+	}
+
 	// remove no permission units
 	perm.Units = make([]*repo_model.RepoUnit, 0, len(repo.Units))
 	for t := range perm.UnitsMode {
@@ -423,6 +453,11 @@ func GetRepoWriters(repo *repo_model.Repository) (_ []*user_model.User, err erro
 	return getUsersWithAccessMode(db.DefaultContext, repo, perm_model.AccessModeWrite)
 }
 
+// GetRepoAdmins returns all users that have admin access to the repository.
+func GetRepoAdmins(ctx context.Context, repo *repo_model.Repository) (_ []*user_model.User, err error) {
+	return getUsersWithAccessMode(ctx, repo, perm_model.AccessModeAdmin)
+}
+
 // IsRepoReader returns true if user has explicit read access or higher to the repository.
 func IsRepoReader(ctx context.Context, repo *repo_model.Repository, userID int64) (bool, error) {
 	if repo.OwnerID == userID {
@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package perm
+
+// ReleaseAction is a set of actions on releases which can be restricted for users with write access to releases
+type ReleaseAction int
+
+const (
+	// ReleaseActionCreate allows to create new releases
+	ReleaseActionCreate ReleaseAction = 1 << iota // 1
+	// ReleaseActionEdit allows to edit and delete existing releases and their assets
+	ReleaseActionEdit // 2
+	// ReleaseActionUploadAssets allows to upload assets to releases
+	ReleaseActionUploadAssets // 4
+
+	// ReleaseActionNone contains no action
+	ReleaseActionNone ReleaseAction = 0
+	// ReleaseActionAll contains all actions
+	ReleaseActionAll = ReleaseActionCreate | ReleaseActionEdit | ReleaseActionUploadAssets
+)
+
+// Has returns true if the set contains all of the given actions
+func (a ReleaseAction) Has(action ReleaseAction) bool {
+	return a&action == action
+}
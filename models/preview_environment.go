@@ -0,0 +1,199 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/validation"
+)
+
+// PreviewEnvironmentStatus is the state of the deployment of a preview environment
+type PreviewEnvironmentStatus string
+
+// enumerates all preview environment statuses
+const (
+	PreviewEnvironmentPending PreviewEnvironmentStatus = "pending"
+	PreviewEnvironmentReady   PreviewEnvironmentStatus = "ready"
+	PreviewEnvironmentFailed  PreviewEnvironmentStatus = "failed"
+)
+
+// IsValid returns true if the status is known
+func (s PreviewEnvironmentStatus) IsValid() bool {
+	switch s {
+	case PreviewEnvironmentPending, PreviewEnvironmentReady, PreviewEnvironmentFailed:
+		return true
+	}
+	return false
+}
+
+// ErrPreviewEnvironmentNotExist represents a "PreviewEnvironmentNotExist" kind of error.
+type ErrPreviewEnvironmentNotExist struct {
+	ID int64
+}
+
+// IsErrPreviewEnvironmentNotExist checks if an error is a ErrPreviewEnvironmentNotExist.
+func IsErrPreviewEnvironmentNotExist(err error) bool {
+	_, ok := err.(ErrPreviewEnvironmentNotExist)
+	return ok
+}
+
+func (err ErrPreviewEnvironmentNotExist) Error() string {
+	return fmt.Sprintf("preview environment does not exist [id: %d]", err.ID)
+}
+
+// ErrPreviewEnvironmentAlreadyExist represents a "PreviewEnvironmentAlreadyExist" kind of error.
+type ErrPreviewEnvironmentAlreadyExist struct {
+	Name string
+}
+
+// IsErrPreviewEnvironmentAlreadyExist checks if an error is a ErrPreviewEnvironmentAlreadyExist.
+func IsErrPreviewEnvironmentAlreadyExist(err error) bool {
+	_, ok := err.(ErrPreviewEnvironmentAlreadyExist)
+	return ok
+}
+
+func (err ErrPreviewEnvironmentAlreadyExist) Error() string {
+	return fmt.Sprintf("preview environment already exists [name: %s]", err.Name)
+}
+
+// ErrInvalidPreviewEnvironment represents a "InvalidPreviewEnvironment" kind of error.
+type ErrInvalidPreviewEnvironment struct {
+	Reason string
+}
+
+// IsErrInvalidPreviewEnvironment checks if an error is a ErrInvalidPreviewEnvironment.
+func IsErrInvalidPreviewEnvironment(err error) bool {
+	_, ok := err.(ErrInvalidPreviewEnvironment)
+	return ok
+}
+
+func (err ErrInvalidPreviewEnvironment) Error() string {
+	return fmt.Sprintf("invalid preview environment: %s", err.Reason)
+}
+
+// PreviewEnvironment is a deployment of the head of a pull request registered by deployment tooling.
+// It is shown as a link in the header of the pull request until the pull request is closed or the environment expires.
+type PreviewEnvironment struct {
+	ID          int64                    `xorm:"pk autoincr"`
+	RepoID      int64                    `xorm:"INDEX NOT NULL"`
+	IssueID     int64                    `xorm:"UNIQUE(s) NOT NULL"`
+	Name        string                   `xorm:"UNIQUE(s) VARCHAR(255) NOT NULL"`
+	URL         string                   `xorm:"TEXT"`
+	Status      PreviewEnvironmentStatus `xorm:"VARCHAR(20) NOT NULL"`
+	CreatorID   int64
+	Creator     *user_model.User   `xorm:"-"`
+	ExpiresUnix timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(PreviewEnvironment))
+}
+
+// LoadCreator loads the user who registered the preview environment
+func (env *PreviewEnvironment) LoadCreator(ctx context.Context) error {
+	if env.Creator != nil || env.CreatorID == 0 {
+		return nil
+	}
+	var err error
+	if env.Creator, err = user_model.GetUserByIDCtx(ctx, env.CreatorID); err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			return err
+		}
+		env.Creator = user_model.NewGhostUser()
+	}
+	return nil
+}
+
+// IsExpired returns true if the expiry of the preview environment has passed
+func (env *PreviewEnvironment) IsExpired() bool {
+	return env.ExpiresUnix > 0 && env.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// Validate checks the name, url and status of the preview environment, a missing status is pending
+func (env *PreviewEnvironment) Validate() error {
+	if env.Name == "" {
+		return ErrInvalidPreviewEnvironment{Reason: "name is required"}
+	}
+	if env.Status == "" {
+		env.Status = PreviewEnvironmentPending
+	}
+	if !env.Status.IsValid() {
+		return ErrInvalidPreviewEnvironment{Reason: fmt.Sprintf("unknown status %q", env.Status)}
+	}
+	if env.URL != "" && !validation.IsValidURL(env.URL) {
+		return ErrInvalidPreviewEnvironment{Reason: fmt.Sprintf("invalid url %q", env.URL)}
+	}
+	if env.Status == PreviewEnvironmentReady && env.URL == "" {
+		return ErrInvalidPreviewEnvironment{Reason: "a ready preview environment needs a url"}
+	}
+	return nil
+}
+
+// CreatePreviewEnvironment inserts a preview environment, its name has to be unique within the pull request
+func CreatePreviewEnvironment(ctx context.Context, env *PreviewEnvironment) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	has, err := db.GetEngine(ctx).Where("issue_id = ? AND name = ?", env.IssueID, env.Name).Exist(new(PreviewEnvironment))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrPreviewEnvironmentAlreadyExist{Name: env.Name}
+	}
+	if err := db.Insert(ctx, env); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// UpdatePreviewEnvironment updates the columns of a preview environment
+func UpdatePreviewEnvironment(ctx context.Context, env *PreviewEnvironment, cols ...string) error {
+	_, err := db.GetEngine(ctx).ID(env.ID).Cols(cols...).Update(env)
+	return err
+}
+
+// DeletePreviewEnvironment deletes a preview environment
+func DeletePreviewEnvironment(ctx context.Context, env *PreviewEnvironment) error {
+	_, err := db.GetEngine(ctx).ID(env.ID).Delete(new(PreviewEnvironment))
+	return err
+}
+
+// GetPreviewEnvironmentByID returns the preview environment of a pull request with the given id
+func GetPreviewEnvironmentByID(ctx context.Context, issueID, id int64) (*PreviewEnvironment, error) {
+	env := &PreviewEnvironment{}
+	has, err := db.GetEngine(ctx).Where("id = ? AND issue_id = ?", id, issueID).Get(env)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrPreviewEnvironmentNotExist{ID: id}
+	}
+	return env, nil
+}
+
+// GetPreviewEnvironments returns the preview environments of a pull request ordered by name
+func GetPreviewEnvironments(ctx context.Context, issueID int64) ([]*PreviewEnvironment, error) {
+	envs := make([]*PreviewEnvironment, 0, 2)
+	return envs, db.GetEngine(ctx).Where("issue_id = ?", issueID).OrderBy("name ASC").Find(&envs)
+}
+
+// GetExpiredPreviewEnvironments returns at most limit preview environments whose expiry has passed
+func GetExpiredPreviewEnvironments(ctx context.Context, limit int) ([]*PreviewEnvironment, error) {
+	envs := make([]*PreviewEnvironment, 0, limit)
+	return envs, db.GetEngine(ctx).
+		Where("expires_unix > 0 AND expires_unix <= ?", timeutil.TimeStampNow()).
+		OrderBy("expires_unix ASC").
+		Limit(limit).
+		Find(&envs)
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPreviewEnvironmentValidate(t *testing.T) {
+	env := &PreviewEnvironment{Name: "staging"}
+	assert.NoError(t, env.Validate())
+	assert.Equal(t, PreviewEnvironmentPending, env.Status)
+
+	assert.NoError(t, (&PreviewEnvironment{Name: "staging", URL: "https://pr-2.example.com", Status: PreviewEnvironmentReady}).Validate())
+	assert.True(t, IsErrInvalidPreviewEnvironment((&PreviewEnvironment{}).Validate()))
+	assert.True(t, IsErrInvalidPreviewEnvironment((&PreviewEnvironment{Name: "staging", Status: "deployed"}).Validate()))
+	assert.True(t, IsErrInvalidPreviewEnvironment((&PreviewEnvironment{Name: "staging", Status: PreviewEnvironmentReady}).Validate()))
+	assert.True(t, IsErrInvalidPreviewEnvironment((&PreviewEnvironment{Name: "staging", URL: "javascript:alert(1)"}).Validate()))
+}
+
+func TestPreviewEnvironments(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	env := &PreviewEnvironment{RepoID: 1, IssueID: 2, Name: "staging", Status: PreviewEnvironmentPending}
+	assert.NoError(t, CreatePreviewEnvironment(db.DefaultContext, env))
+	assert.True(t, IsErrPreviewEnvironmentAlreadyExist(CreatePreviewEnvironment(db.DefaultContext, &PreviewEnvironment{RepoID: 1, IssueID: 2, Name: "staging"})))
+	expired := &PreviewEnvironment{RepoID: 1, IssueID: 2, Name: "docs", Status: PreviewEnvironmentReady, URL: "https://docs.example.com", ExpiresUnix: now - 60}
+	assert.NoError(t, CreatePreviewEnvironment(db.DefaultContext, expired))
+	assert.True(t, expired.IsExpired())
+	assert.False(t, env.IsExpired())
+
+	envs, err := GetPreviewEnvironments(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, envs, 2) {
+		assert.Equal(t, "docs", envs[0].Name)
+		assert.Equal(t, "staging", envs[1].Name)
+	}
+
+	envs, err = GetExpiredPreviewEnvironments(db.DefaultContext, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, envs, 1) {
+		assert.Equal(t, expired.ID, envs[0].ID)
+	}
+
+	env.Status = PreviewEnvironmentReady
+	env.URL = "https://pr-2.example.com"
+	assert.NoError(t, UpdatePreviewEnvironment(db.DefaultContext, env, "status", "url"))
+	unittest.AssertExistsAndLoadBean(t, &PreviewEnvironment{ID: env.ID, Status: PreviewEnvironmentReady, URL: "https://pr-2.example.com"})
+
+	_, err = GetPreviewEnvironmentByID(db.DefaultContext, 3, env.ID)
+	assert.True(t, IsErrPreviewEnvironmentNotExist(err))
+
+	assert.NoError(t, DeletePreviewEnvironment(db.DefaultContext, expired))
+	unittest.AssertNotExistsBean(t, &PreviewEnvironment{ID: expired.ID})
+}
@@ -107,22 +107,49 @@ func IsReleaseExist(repoID int64, tagName string) (bool, error) {
 	return db.GetEngine(db.DefaultContext).Get(&Release{RepoID: repoID, LowerTagName: strings.ToLower(tagName)})
 }
 
+// UpdateRepoReleaseCounts updates the numbers of published releases and tags of a repository
+func UpdateRepoReleaseCounts(ctx context.Context, repoID int64) error {
+	if _, err := db.GetEngine(ctx).Exec("UPDATE `repository` SET num_releases=(SELECT COUNT(*) FROM `release` WHERE repo_id=? AND is_draft=? AND is_tag=?) WHERE id=?", repoID, false, false, repoID); err != nil {
+		return err
+	}
+	_, err := db.GetEngine(ctx).Exec("UPDATE `repository` SET num_tags=(SELECT COUNT(*) FROM `release` WHERE repo_id=? AND is_draft=?) WHERE id=?", repoID, false, repoID)
+	return err
+}
+
 // InsertRelease inserts a release
 func InsertRelease(rel *Release) error {
-	_, err := db.GetEngine(db.DefaultContext).Insert(rel)
-	return err
+	return db.WithTx(func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Insert(rel); err != nil {
+			return err
+		}
+		return UpdateRepoReleaseCounts(ctx, rel.RepoID)
+	})
 }
 
 // InsertReleasesContext insert releases
 func InsertReleasesContext(ctx context.Context, rels []*Release) error {
-	_, err := db.GetEngine(ctx).Insert(rels)
-	return err
+	if _, err := db.GetEngine(ctx).Insert(rels); err != nil {
+		return err
+	}
+	repoIDs := make(map[int64]bool, 1)
+	for _, rel := range rels {
+		if repoIDs[rel.RepoID] {
+			continue
+		}
+		repoIDs[rel.RepoID] = true
+		if err := UpdateRepoReleaseCounts(ctx, rel.RepoID); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // UpdateRelease updates all columns of a release
 func UpdateRelease(ctx context.Context, rel *Release) error {
-	_, err := db.GetEngine(ctx).ID(rel.ID).AllCols().Update(rel)
-	return err
+	if _, err := db.GetEngine(ctx).ID(rel.ID).AllCols().Update(rel); err != nil {
+		return err
+	}
+	return UpdateRepoReleaseCounts(ctx, rel.RepoID)
 }
 
 // AddReleaseAttachments adds a release attachments
@@ -350,8 +377,19 @@ func SortReleases(rels []*Release) {
 
 // DeleteReleaseByID deletes a release from database by given ID.
 func DeleteReleaseByID(id int64) error {
-	_, err := db.GetEngine(db.DefaultContext).ID(id).Delete(new(Release))
-	return err
+	return db.WithTx(func(ctx context.Context) error {
+		rel := new(Release)
+		has, err := db.GetEngine(ctx).ID(id).Get(rel)
+		if err != nil {
+			return err
+		} else if !has {
+			return nil
+		}
+		if _, err := db.GetEngine(ctx).ID(id).Delete(new(Release)); err != nil {
+			return err
+		}
+		return UpdateRepoReleaseCounts(ctx, rel.RepoID)
+	})
 }
 
 // UpdateReleasesMigrationsByType updates all migrated repositories' releases from gitServiceType to replace originalAuthorID to posterID
@@ -394,7 +432,7 @@ func PushUpdateDeleteTagsContext(ctx context.Context, repo *repo_model.Repositor
 		return fmt.Errorf("Update: %v", err)
 	}
 
-	return nil
+	return UpdateRepoReleaseCounts(ctx, repo.ID)
 }
 
 // PushUpdateDeleteTag must be called for any push actions to delete tag
@@ -419,7 +457,7 @@ func PushUpdateDeleteTag(repo *repo_model.Repository, tagName string) error {
 		}
 	}
 
-	return nil
+	return UpdateRepoReleaseCounts(db.DefaultContext, repo.ID)
 }
 
 // SaveOrUpdateTag must be called for any push actions to add tag
@@ -446,7 +484,7 @@ func SaveOrUpdateTag(repo *repo_model.Repository, newRel *Release) error {
 			return fmt.Errorf("Update: %v", err)
 		}
 	}
-	return nil
+	return UpdateRepoReleaseCounts(db.DefaultContext, repo.ID)
 }
 
 // RemapExternalUser ExternalUserRemappable interface
@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package models
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReleaseCounts(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assertCounts := func(numReleases, numTags int) {
+		repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+		assert.Equal(t, numReleases, repo.NumReleases)
+		assert.Equal(t, numTags, repo.NumTags)
+	}
+	assertCounts(2, 3)
+
+	rel := &Release{
+		RepoID:       1,
+		PublisherID:  2,
+		TagName:      "v1.2",
+		LowerTagName: "v1.2",
+		IsDraft:      true,
+	}
+	assert.NoError(t, InsertRelease(rel))
+	assertCounts(2, 3)
+
+	rel.IsDraft = false
+	assert.NoError(t, UpdateRelease(db.DefaultContext, rel))
+	assertCounts(3, 4)
+
+	assert.NoError(t, DeleteReleaseByID(rel.ID))
+	assertCounts(2, 3)
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	assert.NoError(t, PushUpdateDeleteTagsContext(db.DefaultContext, repo, []string{"v1.0"}))
+	unittest.CheckConsistencyFor(t, &repo_model.Repository{})
+}
@@ -16,15 +16,18 @@ import (
 
 	_ "image/jpeg" // Needed for jpeg support
 
+	actions_model "code.gitea.io/gitea/models/actions"
 	admin_model "code.gitea.io/gitea/models/admin"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/db"
+	discussion_model "code.gitea.io/gitea/models/discussion"
 	issues_model "code.gitea.io/gitea/models/issues"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	project_model "code.gitea.io/gitea/models/project"
 	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
@@ -222,11 +225,12 @@ func UpdateRepoSize(ctx context.Context, repo *repo_model.Repository) error {
 }
 
 // CanUserForkRepo returns true if specified user can fork repository.
+// The owner of a repository can always fork it into the same namespace under a new name.
 func CanUserForkRepo(user *user_model.User, repo *repo_model.Repository) (bool, error) {
 	if user == nil {
 		return false, nil
 	}
-	if repo.OwnerID != user.ID && !repo_model.HasForkedRepo(user.ID, repo.ID) {
+	if repo.OwnerID == user.ID || !repo_model.HasForkedRepo(user.ID, repo.ID) {
 		return true, nil
 	}
 	ownedOrgs, err := organization.GetOrgsCanCreateRepoByUserID(user.ID)
@@ -234,7 +238,7 @@ func CanUserForkRepo(user *user_model.User, repo *repo_model.Repository) (bool,
 		return false, err
 	}
 	for _, org := range ownedOrgs {
-		if repo.OwnerID != org.ID && !repo_model.HasForkedRepo(org.ID, repo.ID) {
+		if repo.OwnerID == org.ID || !repo_model.HasForkedRepo(org.ID, repo.ID) {
 			return true, nil
 		}
 	}
@@ -438,6 +442,12 @@ func CreateRepository(ctx context.Context, doer, u *user_model.User, repo *repo_
 		return fmt.Errorf("copyDefaultWebhooksToRepo: %v", err)
 	}
 
+	if u.IsOrganization() {
+		if err = webhook.CopyOrgTemplateWebhooksToRepo(ctx, u.ID, repo.ID); err != nil {
+			return fmt.Errorf("copyOrgTemplateWebhooksToRepo: %v", err)
+		}
+	}
+
 	return nil
 }
 
@@ -627,6 +637,12 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		}
 	}
 
+	// the files of expired attachments may have been moved to the cold storage
+	coldAttachments := make([]*repo_model.Attachment, 0, 20)
+	if err = sess.Where("repo_id = ? AND is_cold = ?", repoID, true).Find(&coldAttachments); err != nil {
+		return err
+	}
+
 	attachments := make([]*repo_model.Attachment, 0, 20)
 	if err = sess.Join("INNER", "`release`", "`release`.id = `attachment`.release_id").
 		Where("`release`.repo_id = ?", repoID).
@@ -646,21 +662,33 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		&access_model.Access{RepoID: repo.ID},
 		&Action{RepoID: repo.ID},
 		&repo_model.Collaboration{RepoID: repoID},
+		&repo_model.AccessRequest{RepoID: repoID},
+		&repo_model.ScheduledVisibilityChange{RepoID: repoID},
 		&Comment{RefRepoID: repoID},
 		&CommitStatus{RepoID: repoID},
+		&CheckRun{RepoID: repoID},
+		&CheckRunAnnotation{RepoID: repoID},
+		&PreviewEnvironment{RepoID: repoID},
+		&repo_model.DeployToken{RepoID: repoID},
 		&DeletedBranch{RepoID: repoID},
 		&webhook.HookTask{RepoID: repoID},
 		&LFSLock{RepoID: repoID},
+		&repo_model.AuditLog{RepoID: repoID},
+		&repo_model.RepoCodeStats{RepoID: repoID},
 		&repo_model.LanguageStat{RepoID: repoID},
 		&issues_model.Milestone{RepoID: repoID},
+		&issues_model.IssueFilter{RepoID: repoID},
 		&repo_model.Mirror{RepoID: repoID},
 		&Notification{RepoID: repoID},
 		&ProtectedBranch{RepoID: repoID},
 		&ProtectedTag{RepoID: repoID},
 		&repo_model.PushMirror{RepoID: repoID},
 		&Release{RepoID: repoID},
+		&asymkey_model.ReleaseSigningKey{RepoID: repoID},
 		&repo_model.RepoIndexerStatus{RepoID: repoID},
 		&repo_model.Redirect{RedirectRepoID: repoID},
+		&repo_model.RepoTraffic{RepoID: repoID},
+		&repo_model.RepoMaintenance{RepoID: repoID},
 		&repo_model.RepoUnit{RepoID: repoID},
 		&repo_model.Star{RepoID: repoID},
 		&Task{RepoID: repoID},
@@ -670,6 +698,19 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
 
+	if err := secret_model.DeleteScope(ctx, secret_model.RepoScope(repoID)); err != nil {
+		return fmt.Errorf("DeleteScope: %v", err)
+	}
+
+	artifactPaths, err := actions_model.DeleteRepoActions(ctx, repoID)
+	if err != nil {
+		return fmt.Errorf("DeleteRepoActions: %v", err)
+	}
+
+	if err := discussion_model.DeleteRepoDiscussions(ctx, repoID); err != nil {
+		return fmt.Errorf("DeleteRepoDiscussions: %v", err)
+	}
+
 	// Delete Labels and related objects
 	if err := deleteLabelsByRepoID(ctx, repoID); err != nil {
 		return err
@@ -832,6 +873,16 @@ func DeleteRepository(doer *user_model.User, uid, repoID int64) error {
 		admin_model.RemoveStorageWithNotice(db.DefaultContext, storage.Attachments, "Delete issue attachment", newAttachment)
 	}
 
+	// Remove attachment files in the cold storage.
+	for _, coldAttachment := range coldAttachments {
+		admin_model.RemoveStorageWithNotice(db.DefaultContext, storage.AttachmentsCold, "Delete cold attachment", coldAttachment.RelativePath())
+	}
+
+	// Remove actions artifacts
+	for _, artifact := range artifactPaths {
+		admin_model.RemoveStorageWithNotice(db.DefaultContext, storage.Actions, "Delete actions artifact", artifact)
+	}
+
 	if len(repo.Avatar) > 0 {
 		if err := storage.RepoAvatars.Delete(repo.CustomAvatarRelativePath()); err != nil {
 			return fmt.Errorf("Failed to remove %s: %v", repo.Avatar, err)
@@ -882,6 +933,10 @@ func repoStatsCorrectNumStars(ctx context.Context, id int64) error {
 	return StatsCorrectSQL(ctx, "UPDATE `repository` SET num_stars=(SELECT COUNT(*) FROM `star` WHERE repo_id=?) WHERE id=?", id)
 }
 
+func repoStatsCorrectNumReleases(ctx context.Context, id int64) error {
+	return UpdateRepoReleaseCounts(ctx, id)
+}
+
 func labelStatsCorrectNumIssues(ctx context.Context, id int64) error {
 	return StatsCorrectSQL(ctx, "UPDATE `label` SET num_issues=(SELECT COUNT(*) FROM `issue_label` WHERE label_id=?) WHERE id=?", id)
 }
@@ -988,6 +1043,12 @@ func CheckRepoStats(ctx context.Context) error {
 			repoStatsCorrectNumClosedPulls,
 			"repository count 'num_closed_pulls'",
 		},
+		// Repository.Num{Releases,Tags}
+		{
+			statsQuery("SELECT repo.id FROM `repository` repo WHERE repo.num_releases!=(SELECT COUNT(*) FROM `release` WHERE repo_id=repo.id AND is_draft=? AND is_tag=?) OR repo.num_tags!=(SELECT COUNT(*) FROM `release` WHERE repo_id=repo.id AND is_draft=?)", false, false, false),
+			repoStatsCorrectNumReleases,
+			"repository count 'num_releases' and 'num_tags'",
+		},
 		// Label.NumIssues
 		{
 			statsQuery("SELECT label.id FROM `label` WHERE label.num_issues!=(SELECT COUNT(*) FROM `issue_label` WHERE label_id=label.id)"),
@@ -1079,6 +1140,7 @@ func UpdateRepoStats(ctx context.Context, id int64) error {
 		repoStatsCorrectNumPulls,
 		repoStatsCorrectNumClosedIssues,
 		repoStatsCorrectNumClosedPulls,
+		repoStatsCorrectNumReleases,
 		labelStatsCorrectNumIssuesRepo,
 		labelStatsCorrectNumClosedIssuesRepo,
 		milestoneStatsCorrectNumIssuesRepo,
@@ -0,0 +1,180 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// AccessRequestStatus is the state of an access request
+type AccessRequestStatus int
+
+// enumerates all access request statuses
+const (
+	AccessRequestStatusPending AccessRequestStatus = iota
+	AccessRequestStatusApproved
+	AccessRequestStatusDenied
+)
+
+// String returns the name of the status
+func (s AccessRequestStatus) String() string {
+	switch s {
+	case AccessRequestStatusApproved:
+		return "approved"
+	case AccessRequestStatusDenied:
+		return "denied"
+	default:
+		return "pending"
+	}
+}
+
+// AccessRequestDenialCooldown is the time after a denial during which the request cannot be reopened
+const AccessRequestDenialCooldown = 7 * 24 * time.Hour
+
+// ErrAccessRequestNotExist represents a "AccessRequestNotExist" kind of error.
+type ErrAccessRequestNotExist struct {
+	ID int64
+}
+
+// IsErrAccessRequestNotExist checks if an error is a ErrAccessRequestNotExist.
+func IsErrAccessRequestNotExist(err error) bool {
+	_, ok := err.(ErrAccessRequestNotExist)
+	return ok
+}
+
+func (err ErrAccessRequestNotExist) Error() string {
+	return fmt.Sprintf("access request does not exist [id: %d]", err.ID)
+}
+
+// AccessRequest represents a request of a user to get access to a repository they cannot see.
+// A user has at most one request per repository, asking again reopens it.
+type AccessRequest struct {
+	ID          int64               `xorm:"pk autoincr"`
+	RepoID      int64               `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	UserID      int64               `xorm:"UNIQUE(s) NOT NULL"`
+	User        *user_model.User    `xorm:"-"`
+	Message     string              `xorm:"TEXT"`
+	Status      AccessRequestStatus `xorm:"INDEX NOT NULL DEFAULT 0"`
+	ReviewerID  int64               `xorm:"NOT NULL DEFAULT 0"`
+	Reviewer    *user_model.User    `xorm:"-"`
+	CreatedUnix timeutil.TimeStamp  `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp  `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(AccessRequest))
+}
+
+// IsPending returns true if the request has not been decided yet
+func (r *AccessRequest) IsPending() bool {
+	return r.Status == AccessRequestStatusPending
+}
+
+// LoadAttributes loads the requesting user and the reviewer of the request
+func (r *AccessRequest) LoadAttributes(ctx context.Context) error {
+	var err error
+	if r.User == nil {
+		if r.User, err = user_model.GetUserByIDCtx(ctx, r.UserID); err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				return err
+			}
+			r.User = user_model.NewGhostUser()
+		}
+	}
+	if r.Reviewer == nil && r.ReviewerID > 0 {
+		if r.Reviewer, err = user_model.GetUserByIDCtx(ctx, r.ReviewerID); err != nil {
+			if !user_model.IsErrUserNotExist(err) {
+				return err
+			}
+			r.Reviewer = user_model.NewGhostUser()
+		}
+	}
+	return nil
+}
+
+// CreateAccessRequest creates a pending access request of the user for the repository, a decided request is reopened.
+// It returns false if the user already has a pending request, or if the request was denied less than
+// AccessRequestDenialCooldown ago.
+func CreateAccessRequest(ctx context.Context, repoID, userID int64, message string) (*AccessRequest, bool, error) {
+	e := db.GetEngine(ctx)
+
+	req := &AccessRequest{}
+	has, err := e.Where("repo_id = ? AND user_id = ?", repoID, userID).Get(req)
+	if err != nil {
+		return nil, false, err
+	}
+	if !has {
+		req = &AccessRequest{
+			RepoID:  repoID,
+			UserID:  userID,
+			Message: message,
+			Status:  AccessRequestStatusPending,
+		}
+		_, err = e.Insert(req)
+		return req, err == nil, err
+	}
+	if req.IsPending() {
+		return req, false, nil
+	}
+	if req.Status == AccessRequestStatusDenied && time.Since(req.UpdatedUnix.AsTime()) < AccessRequestDenialCooldown {
+		return req, false, nil
+	}
+
+	req.Message = message
+	req.Status = AccessRequestStatusPending
+	req.ReviewerID = 0
+	_, err = e.ID(req.ID).Cols("message", "status", "reviewer_id").Update(req)
+	return req, err == nil, err
+}
+
+// GetAccessRequestByID returns the access request of the repository with the given id
+func GetAccessRequestByID(ctx context.Context, repoID, id int64) (*AccessRequest, error) {
+	req := &AccessRequest{}
+	has, err := db.GetEngine(ctx).Where("id = ? AND repo_id = ?", id, repoID).Get(req)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrAccessRequestNotExist{ID: id}
+	}
+	return req, nil
+}
+
+// GetAccessRequests returns the access requests of a repository, pending requests first
+func GetAccessRequests(ctx context.Context, repoID int64) ([]*AccessRequest, error) {
+	reqs := make([]*AccessRequest, 0, 10)
+	if err := db.GetEngine(ctx).
+		Where("repo_id = ?", repoID).
+		OrderBy("status ASC, updated_unix DESC").
+		Find(&reqs); err != nil {
+		return nil, err
+	}
+	for _, req := range reqs {
+		if err := req.LoadAttributes(ctx); err != nil {
+			return nil, err
+		}
+	}
+	return reqs, nil
+}
+
+// CountPendingAccessRequests returns the number of undecided access requests of a repository
+func CountPendingAccessRequests(ctx context.Context, repoID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Where("repo_id = ? AND status = ?", repoID, AccessRequestStatusPending).
+		Count(new(AccessRequest))
+}
+
+// DecideAccessRequest stores the decision of the reviewer on a request
+func DecideAccessRequest(ctx context.Context, req *AccessRequest, status AccessRequestStatus, reviewerID int64) error {
+	req.Status = status
+	req.ReviewerID = reviewerID
+	_, err := db.GetEngine(ctx).ID(req.ID).Cols("status", "reviewer_id").Update(req)
+	return err
+}
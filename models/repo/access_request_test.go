@@ -0,0 +1,69 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAccessRequest(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	req, created, err := CreateAccessRequest(db.DefaultContext, 2, 4, "please")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.True(t, req.IsPending())
+
+	// a pending request is not created again
+	again, created, err := CreateAccessRequest(db.DefaultContext, 2, 4, "please again")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, req.ID, again.ID)
+	assert.Equal(t, "please", again.Message)
+
+	count, err := CountPendingAccessRequests(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+
+	assert.NoError(t, DecideAccessRequest(db.DefaultContext, req, AccessRequestStatusDenied, 2))
+	count, err = CountPendingAccessRequests(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	// a denied request is not reopened right away
+	again, created, err = CreateAccessRequest(db.DefaultContext, 2, 4, "please again")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	assert.Equal(t, AccessRequestStatusDenied, again.Status)
+
+	// but once the cooldown is over
+	_, err = db.GetEngine(db.DefaultContext).ID(req.ID).NoAutoTime().
+		Cols("updated_unix").
+		Update(&AccessRequest{UpdatedUnix: timeutil.TimeStamp(time.Now().Add(-AccessRequestDenialCooldown).Unix() - 1)})
+	assert.NoError(t, err)
+	reopened, created, err := CreateAccessRequest(db.DefaultContext, 2, 4, "please again")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.Equal(t, req.ID, reopened.ID)
+
+	reqs, err := GetAccessRequests(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	if assert.Len(t, reqs, 1) {
+		assert.True(t, reqs[0].IsPending())
+		assert.Equal(t, "please again", reqs[0].Message)
+		assert.EqualValues(t, 0, reqs[0].ReviewerID)
+		assert.EqualValues(t, "user4", reqs[0].User.Name)
+	}
+
+	_, err = GetAccessRequestByID(db.DefaultContext, 1, req.ID)
+	assert.True(t, IsErrAccessRequestNotExist(err))
+}
@@ -86,11 +86,15 @@ func DeleteAllRepoArchives() error {
 // FindRepoArchiversOption represents an archiver options
 type FindRepoArchiversOption struct {
 	db.ListOptions
+	RepoID    int64
 	OlderThan time.Duration
 }
 
 func (opts FindRepoArchiversOption) toConds() builder.Cond {
 	cond := builder.NewCond()
+	if opts.RepoID > 0 {
+		cond = cond.And(builder.Eq{"repo_id": opts.RepoID})
+	}
 	if opts.OlderThan > 0 {
 		cond = cond.And(builder.Lt{"created_unix": time.Now().Add(-opts.OlderThan).Unix()})
 	}
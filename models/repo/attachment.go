@@ -14,6 +14,8 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
 )
 
 // Attachment represent a attachment of issue/comment/release.
@@ -28,6 +30,9 @@ type Attachment struct {
 	Name          string
 	DownloadCount int64              `xorm:"DEFAULT 0"`
 	Size          int64              `xorm:"DEFAULT 0"`
+	ExternalURL   string             `xorm:"TEXT"` // the file is hosted elsewhere, e.g. on a CDN or a mirror
+	Checksum      string             // optional checksum of an external file as "<algorithm>:<hex digest>"
+	IsCold        bool               `xorm:"NOT NULL DEFAULT false"` // the file has been moved to the cold storage
 	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
 }
 
@@ -55,11 +60,24 @@ func (a *Attachment) RelativePath() string {
 	return AttachmentRelativePath(a.UUID)
 }
 
+// Storage returns the storage the file of the attachment is kept in
+func (a *Attachment) Storage() storage.ObjectStorage {
+	if a.IsCold {
+		return storage.AttachmentsCold
+	}
+	return storage.Attachments
+}
+
 // DownloadURL returns the download url of the attached file
 func (a *Attachment) DownloadURL() string {
 	return setting.AppURL + "attachments/" + url.PathEscape(a.UUID)
 }
 
+// IsExternal returns true if the attached file isn't stored by Gitea but linked by its external URL
+func (a *Attachment) IsExternal() bool {
+	return a.ExternalURL != ""
+}
+
 //    _____   __    __                .__                           __
 //   /  _  \_/  |__/  |______    ____ |  |__   _____   ____   _____/  |_
 //  /  /_\  \   __\   __\__  \ _/ ___\|  |  \ /     \_/ __ \ /    \   __\
@@ -170,7 +188,7 @@ func DeleteAttachments(ctx context.Context, attachments []*Attachment, remove bo
 
 	if remove {
 		for i, a := range attachments {
-			if err := storage.Attachments.Delete(a.RelativePath()); err != nil {
+			if err := a.Storage().Delete(a.RelativePath()); err != nil {
 				return i, err
 			}
 		}
@@ -248,6 +266,11 @@ func IterateAttachment(f func(attach *Attachment) error) error {
 	}
 }
 
+// CountAttachmentsByRepoID returns the number of attachments of the issues, comments and releases of a repository
+func CountAttachmentsByRepoID(ctx context.Context, repoID int64) (int64, error) {
+	return db.GetEngine(ctx).Where("repo_id = ?", repoID).Count(new(Attachment))
+}
+
 // CountOrphanedAttachments returns the number of bad attachments
 func CountOrphanedAttachments() (int64, error) {
 	return db.GetEngine(db.DefaultContext).Where("(issue_id > 0 and issue_id not in (select id from issue)) or (release_id > 0 and release_id not in (select id from `release`))").
@@ -260,3 +283,130 @@ func DeleteOrphanedAttachments() error {
 		Delete(new(Attachment))
 	return err
 }
+
+// SumOwnerAttachmentSize returns the size of the attachments stored for the repositories of the owner,
+// attachments hosted elsewhere don't count
+func SumOwnerAttachmentSize(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Where(builder.In("repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": ownerID}))).
+		And(storedAttachmentCond()).
+		SumInt(new(Attachment), "size")
+}
+
+// storedAttachmentCond matches the attachments whose files are stored by Gitea
+func storedAttachmentCond() builder.Cond {
+	return builder.Eq{"external_url": ""}.Or(builder.IsNull{"external_url"})
+}
+
+// orphanedAttachmentCond matches the attachments which have never been linked to an issue, comment or release
+func orphanedAttachmentCond() builder.Cond {
+	return builder.Eq{"issue_id": 0, "release_id": 0}
+}
+
+// SumRepoAttachmentSize returns the size of the attachments stored for the repository,
+// attachments hosted elsewhere don't count
+func SumRepoAttachmentSize(ctx context.Context, repoID int64) (int64, error) {
+	return db.GetEngine(ctx).
+		Where(builder.Eq{"repo_id": repoID}.And(storedAttachmentCond())).
+		SumInt(new(Attachment), "size")
+}
+
+// FindExpiredOrphanedAttachments returns at most limit attachments which have been uploaded before olderThan
+// but have never been linked to an issue, comment or release
+func FindExpiredOrphanedAttachments(ctx context.Context, olderThan timeutil.TimeStamp, limit int) ([]*Attachment, error) {
+	attachments := make([]*Attachment, 0, limit)
+	return attachments, db.GetEngine(ctx).
+		Where(orphanedAttachmentCond().And(builder.Lt{"created_unix": olderThan})).
+		OrderBy("id").Limit(limit).Find(&attachments)
+}
+
+// FindExpiredAttachments returns at most limit attachments of issues, comments and releases which have been
+// uploaded before olderThan and are stored in the primary storage. Attachments hosted elsewhere never expire.
+func FindExpiredAttachments(ctx context.Context, olderThan timeutil.TimeStamp, limit int) ([]*Attachment, error) {
+	attachments := make([]*Attachment, 0, limit)
+	return attachments, db.GetEngine(ctx).
+		Where(builder.Neq{"issue_id": 0}.Or(builder.Neq{"release_id": 0})).
+		And(builder.Lt{"created_unix": olderThan}).
+		And(builder.Eq{"is_cold": false}).
+		And(storedAttachmentCond()).
+		OrderBy("id").Limit(limit).Find(&attachments)
+}
+
+// MarkAttachmentCold records that the file of the attachment has been moved to the cold storage
+func MarkAttachmentCold(ctx context.Context, a *Attachment) error {
+	a.IsCold = true
+	_, err := db.GetEngine(ctx).ID(a.ID).Cols("is_cold").Update(a)
+	return err
+}
+
+// RepoAttachmentUsage is the number and size of the attachments stored for a repository
+type RepoAttachmentUsage struct {
+	RepoID        int64
+	Count         int64
+	Size          int64
+	ColdCount     int64 `xorm:"-"`
+	ColdSize      int64 `xorm:"-"`
+	OrphanedCount int64 `xorm:"-"`
+	OrphanedSize  int64 `xorm:"-"`
+}
+
+func sumAttachmentsByRepo(ctx context.Context, cond builder.Cond) (map[int64]*RepoAttachmentUsage, error) {
+	usages := make([]*RepoAttachmentUsage, 0, 10)
+	if err := db.GetEngine(ctx).Table("attachment").Where(cond).
+		Select("repo_id, COUNT(*) AS count, SUM(size) AS size").
+		GroupBy("repo_id").Find(&usages); err != nil {
+		return nil, err
+	}
+	usageMap := make(map[int64]*RepoAttachmentUsage, len(usages))
+	for _, usage := range usages {
+		usageMap[usage.RepoID] = usage
+	}
+	return usageMap, nil
+}
+
+// GetRepoAttachmentUsages returns the attachment usage of the repositories which store attachments,
+// the repositories using the most space first, and the total number of these repositories
+func GetRepoAttachmentUsages(ctx context.Context, opts db.ListOptions) ([]*RepoAttachmentUsage, int64, error) {
+	cond := storedAttachmentCond()
+	total, err := db.GetEngine(ctx).Table("attachment").Where(cond).Distinct("repo_id").Count()
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sess := db.GetEngine(ctx).Table("attachment").Where(cond).
+		Select("repo_id, COUNT(*) AS count, SUM(size) AS size").
+		GroupBy("repo_id").OrderBy("SUM(size) DESC, repo_id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	usages := make([]*RepoAttachmentUsage, 0, opts.PageSize)
+	if err := sess.Find(&usages); err != nil {
+		return nil, 0, err
+	}
+	if len(usages) == 0 {
+		return usages, total, nil
+	}
+
+	repoIDs := make([]int64, 0, len(usages))
+	for _, usage := range usages {
+		repoIDs = append(repoIDs, usage.RepoID)
+	}
+	cond = cond.And(builder.In("repo_id", repoIDs))
+	cold, err := sumAttachmentsByRepo(ctx, cond.And(builder.Eq{"is_cold": true}))
+	if err != nil {
+		return nil, 0, err
+	}
+	orphaned, err := sumAttachmentsByRepo(ctx, cond.And(orphanedAttachmentCond()))
+	if err != nil {
+		return nil, 0, err
+	}
+	for _, usage := range usages {
+		if c, ok := cold[usage.RepoID]; ok {
+			usage.ColdCount, usage.ColdSize = c.Count, c.Size
+		}
+		if o, ok := orphaned[usage.RepoID]; ok {
+			usage.OrphanedCount, usage.OrphanedSize = o.Count, o.Size
+		}
+	}
+	return usages, total, nil
+}
@@ -102,3 +102,31 @@ func TestGetAttachmentsByUUIDs(t *testing.T) {
 	assert.Equal(t, int64(1), attachList[0].IssueID)
 	assert.Equal(t, int64(5), attachList[1].IssueID)
 }
+
+func TestGetRepoAttachmentUsages(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, err := db.GetEngine(db.DefaultContext).ID(1).Cols("size").Update(&Attachment{Size: 100})
+	assert.NoError(t, err)
+	_, err = db.GetEngine(db.DefaultContext).ID(9).Cols("size", "is_cold").Update(&Attachment{Size: 20, IsCold: true})
+	assert.NoError(t, err)
+
+	usages, total, err := GetRepoAttachmentUsages(db.DefaultContext, db.ListOptions{Page: 1, PageSize: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, total)
+	if assert.Len(t, usages, 2) {
+		assert.EqualValues(t, 1, usages[0].RepoID)
+		assert.EqualValues(t, 6, usages[0].Count)
+		assert.EqualValues(t, 120, usages[0].Size)
+		assert.EqualValues(t, 1, usages[0].ColdCount)
+		assert.EqualValues(t, 20, usages[0].ColdSize)
+		assert.EqualValues(t, 0, usages[0].OrphanedCount)
+
+		assert.EqualValues(t, 0, usages[1].RepoID)
+		assert.EqualValues(t, 1, usages[1].OrphanedCount)
+	}
+
+	size, err := SumRepoAttachmentSize(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 120, size)
+}
@@ -0,0 +1,160 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// AuditAction represents a security relevant change of a repository,
+// it consists of the category of the change and what was done, e.g. "webhook.add"
+type AuditAction string
+
+// The changes recorded in the audit log of a repository
+const (
+	AuditActionSettingsUpdate          AuditAction = "settings.update"
+	AuditActionCollaboratorAdd         AuditAction = "collaborator.add"
+	AuditActionCollaboratorUpdate      AuditAction = "collaborator.update"
+	AuditActionCollaboratorRemove      AuditAction = "collaborator.remove"
+	AuditActionBranchProtectionAdd     AuditAction = "branch_protection.add"
+	AuditActionBranchProtectionUpdate  AuditAction = "branch_protection.update"
+	AuditActionBranchProtectionRemove  AuditAction = "branch_protection.remove"
+	AuditActionDeployKeyAdd            AuditAction = "deploy_key.add"
+	AuditActionDeployKeyRemove         AuditAction = "deploy_key.remove"
+	AuditActionDeployTokenAdd          AuditAction = "deploy_token.add"
+	AuditActionDeployTokenRemove       AuditAction = "deploy_token.remove"
+	AuditActionWebhookAdd              AuditAction = "webhook.add"
+	AuditActionWebhookUpdate           AuditAction = "webhook.update"
+	AuditActionWebhookRemove           AuditAction = "webhook.remove"
+	AuditActionReleaseSigningKeyAdd    AuditAction = "release_signing_key.add"
+	AuditActionReleaseSigningKeyRemove AuditAction = "release_signing_key.remove"
+)
+
+// AuditActions are all the changes recorded in the audit log of a repository
+var AuditActions = []AuditAction{
+	AuditActionSettingsUpdate,
+	AuditActionCollaboratorAdd,
+	AuditActionCollaboratorUpdate,
+	AuditActionCollaboratorRemove,
+	AuditActionBranchProtectionAdd,
+	AuditActionBranchProtectionUpdate,
+	AuditActionBranchProtectionRemove,
+	AuditActionDeployKeyAdd,
+	AuditActionDeployKeyRemove,
+	AuditActionDeployTokenAdd,
+	AuditActionDeployTokenRemove,
+	AuditActionWebhookAdd,
+	AuditActionWebhookUpdate,
+	AuditActionWebhookRemove,
+	AuditActionReleaseSigningKeyAdd,
+	AuditActionReleaseSigningKeyRemove,
+}
+
+// Category returns the category of the change, e.g. "webhook"
+func (a AuditAction) Category() string {
+	if i := strings.IndexByte(string(a), '.'); i >= 0 {
+		return string(a)[:i]
+	}
+	return string(a)
+}
+
+// AuditLog records who made a security relevant change of a repository
+type AuditLog struct {
+	ID     int64            `xorm:"pk autoincr"`
+	RepoID int64            `xorm:"INDEX NOT NULL"`
+	DoerID int64            `xorm:"INDEX NOT NULL"`
+	Doer   *user_model.User `xorm:"-"`
+	Action AuditAction      `xorm:"VARCHAR(50) INDEX NOT NULL"`
+	// Target is the changed settings section, collaborator, branch protection rule, deploy key, deploy token or webhook
+	Target string `xorm:"NOT NULL DEFAULT ''"`
+	Detail string `xorm:"TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// TableName sets the table name of the audit log
+func (AuditLog) TableName() string {
+	return "repo_audit_log"
+}
+
+func init() {
+	db.RegisterModel(new(AuditLog))
+}
+
+// AddAuditLog records a change in the audit log of a repository
+func AddAuditLog(ctx context.Context, repoID, doerID int64, action AuditAction, target, detail string) error {
+	return db.Insert(ctx, &AuditLog{
+		RepoID: repoID,
+		DoerID: doerID,
+		Action: action,
+		Target: target,
+		Detail: detail,
+	})
+}
+
+// FindAuditLogsOptions are the options to find the audit log of a repository
+type FindAuditLogsOptions struct {
+	db.ListOptions
+	RepoID int64
+	// Action is either an action like "webhook.add" or the category of actions like "webhook"
+	Action string
+	DoerID int64
+	Since  timeutil.TimeStamp
+	Before timeutil.TimeStamp
+}
+
+func (opts *FindAuditLogsOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"repo_id": opts.RepoID})
+	if opts.Action != "" {
+		if strings.Contains(opts.Action, ".") {
+			cond = cond.And(builder.Eq{"action": opts.Action})
+		} else {
+			cond = cond.And(builder.Like{"action", opts.Action + ".%"})
+		}
+	}
+	if opts.DoerID != 0 {
+		cond = cond.And(builder.Eq{"doer_id": opts.DoerID})
+	}
+	if opts.Since != 0 {
+		cond = cond.And(builder.Gte{"created_unix": opts.Since})
+	}
+	if opts.Before != 0 {
+		cond = cond.And(builder.Lt{"created_unix": opts.Before})
+	}
+	return cond
+}
+
+// FindAuditLogs returns the audit log of a repository, newest first.
+// The users who made the changes are loaded, deleted users are replaced by a ghost.
+func FindAuditLogs(ctx context.Context, opts *FindAuditLogsOptions) ([]*AuditLog, int64, error) {
+	sess := db.GetEngine(ctx).
+		Where(opts.toConds()).
+		OrderBy("created_unix DESC, id DESC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	logs := make([]*AuditLog, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&logs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, entry := range logs {
+		entry.Doer, err = user_model.GetUserByIDCtx(ctx, entry.DoerID)
+		if user_model.IsErrUserNotExist(err) {
+			entry.Doer = user_model.NewGhostUser()
+		} else if err != nil {
+			return nil, 0, err
+		}
+	}
+	return logs, count, nil
+}
@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditLog(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.NoError(t, AddAuditLog(db.DefaultContext, 1, 2, AuditActionWebhookAdd, "1", "gitea"))
+	assert.NoError(t, AddAuditLog(db.DefaultContext, 1, 2, AuditActionWebhookRemove, "1", "gitea"))
+	assert.NoError(t, AddAuditLog(db.DefaultContext, 1, 1, AuditActionCollaboratorAdd, "user4", "access: write"))
+	assert.NoError(t, AddAuditLog(db.DefaultContext, 2, 2, AuditActionDeployKeyAdd, "key", ""))
+
+	logs, total, err := FindAuditLogs(db.DefaultContext, &FindAuditLogsOptions{RepoID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 3, total)
+	if assert.Len(t, logs, 3) {
+		// newest first
+		assert.Equal(t, AuditActionCollaboratorAdd, logs[0].Action)
+		assert.EqualValues(t, 1, logs[0].Doer.ID)
+		assert.Equal(t, AuditActionWebhookAdd, logs[2].Action)
+	}
+
+	// a category matches all its actions
+	_, total, err = FindAuditLogs(db.DefaultContext, &FindAuditLogsOptions{RepoID: 1, Action: "webhook"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, total)
+
+	logs, total, err = FindAuditLogs(db.DefaultContext, &FindAuditLogsOptions{RepoID: 1, Action: string(AuditActionWebhookRemove)})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+	if assert.Len(t, logs, 1) {
+		assert.Equal(t, AuditActionWebhookRemove, logs[0].Action)
+	}
+
+	_, total, err = FindAuditLogs(db.DefaultContext, &FindAuditLogsOptions{RepoID: 1, DoerID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	assert.Equal(t, "branch_protection", AuditActionBranchProtectionAdd.Category())
+}
@@ -0,0 +1,57 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// RepoCodeStats stores the code frequency and the punch card of the default branch of a repository
+type RepoCodeStats struct { //revive:disable-line:exported
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"UNIQUE NOT NULL"`
+	// CommitID is the commit of the default branch the statistics were generated for
+	CommitID      string                   `xorm:"VARCHAR(40) NOT NULL"`
+	CodeFrequency []*git.CodeFrequencyWeek `xorm:"LONGTEXT JSON"`
+	PunchCard     []*git.PunchCardHour     `xorm:"TEXT JSON"`
+	UpdatedUnix   timeutil.TimeStamp       `xorm:"INDEX updated"`
+}
+
+func init() {
+	db.RegisterModel(new(RepoCodeStats))
+}
+
+// GetRepoCodeStats returns the stored code statistics of a repository, nil if there are none
+func GetRepoCodeStats(ctx context.Context, repoID int64) (*RepoCodeStats, error) {
+	stats := &RepoCodeStats{}
+	has, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Get(stats)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, nil
+	}
+	return stats, nil
+}
+
+// SaveRepoCodeStats stores the code statistics of a repository generated for a commit of its default branch
+func SaveRepoCodeStats(ctx context.Context, repoID int64, commitID string, codeStats *git.CodeStats) error {
+	return db.WithTx(func(ctx context.Context) error {
+		e := db.GetEngine(ctx)
+		if _, err := e.Delete(&RepoCodeStats{RepoID: repoID}); err != nil {
+			return err
+		}
+		_, err := e.Insert(&RepoCodeStats{
+			RepoID:        repoID,
+			CommitID:      commitID,
+			CodeFrequency: codeStats.CodeFrequency,
+			PunchCard:     codeStats.PunchCard,
+		})
+		return err
+	}, ctx)
+}
@@ -23,6 +23,9 @@ type Collaboration struct {
 	Mode        perm.AccessMode    `xorm:"DEFAULT 2 NOT NULL"`
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	// ExpiresUnix is the time the collaboration is revoked automatically, zero means it never expires
+	ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	GrantedByID int64              `xorm:"NOT NULL DEFAULT 0"`
 }
 
 func init() {
@@ -149,3 +152,29 @@ func ChangeCollaborationAccessMode(repo *Repository, uid int64, mode perm.Access
 
 	return committer.Commit()
 }
+
+// SetCollaborationExpiry sets the time the collaboration of the user is revoked, a zero expiry makes it permanent.
+func SetCollaborationExpiry(ctx context.Context, repoID, uid int64, expires timeutil.TimeStamp, doerID int64) error {
+	_, err := db.GetEngine(ctx).
+		Where("repo_id = ? AND user_id = ?", repoID, uid).
+		Cols("expires_unix", "granted_by_id").
+		Update(&Collaboration{ExpiresUnix: expires, GrantedByID: doerID})
+	return err
+}
+
+// FindExpiredCollaborations returns the collaborations which have expired before `now`
+func FindExpiredCollaborations(ctx context.Context, now timeutil.TimeStamp) ([]*Collaboration, error) {
+	collaborations := make([]*Collaboration, 0, 10)
+	return collaborations, db.GetEngine(ctx).
+		Where("expires_unix > 0 AND expires_unix <= ?", now).
+		Find(&collaborations)
+}
+
+// FindExpiringCollaborations returns the collaborations of a repository which have an expiry
+func FindExpiringCollaborations(ctx context.Context, repoID int64) ([]*Collaboration, error) {
+	collaborations := make([]*Collaboration, 0, 10)
+	return collaborations, db.GetEngine(ctx).
+		Where("repo_id = ? AND expires_unix > 0", repoID).
+		Asc("expires_unix").
+		Find(&collaborations)
+}
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -47,3 +48,33 @@ func TestRepository_IsCollaborator(t *testing.T) {
 	test(4, 2, false)
 	test(4, 4, true)
 }
+
+func TestSetCollaborationExpiry(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	assert.NoError(t, SetCollaborationExpiry(db.DefaultContext, 4, 4, now.Add(-60), 2))
+	assert.NoError(t, SetCollaborationExpiry(db.DefaultContext, 4, 29, now.Add(3600), 2))
+
+	collaboration := unittest.AssertExistsAndLoadBean(t, &Collaboration{RepoID: 4, UserID: 4}).(*Collaboration)
+	assert.EqualValues(t, now.Add(-60), collaboration.ExpiresUnix)
+	assert.EqualValues(t, 2, collaboration.GrantedByID)
+
+	expiring, err := FindExpiringCollaborations(db.DefaultContext, 4)
+	assert.NoError(t, err)
+	if assert.Len(t, expiring, 2) {
+		assert.EqualValues(t, 4, expiring[0].UserID)
+		assert.EqualValues(t, 29, expiring[1].UserID)
+	}
+
+	expired, err := FindExpiredCollaborations(db.DefaultContext, now)
+	assert.NoError(t, err)
+	if assert.Len(t, expired, 1) {
+		assert.EqualValues(t, collaboration.ID, expired[0].ID)
+	}
+
+	assert.NoError(t, SetCollaborationExpiry(db.DefaultContext, 4, 4, 0, 2))
+	expired, err = FindExpiredCollaborations(db.DefaultContext, now)
+	assert.NoError(t, err)
+	assert.Empty(t, expired)
+}
@@ -0,0 +1,287 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+
+	gouuid "github.com/google/uuid"
+)
+
+// DeployTokenPrefix is prepended to every deploy token, it keeps them apart from personal access tokens
+const DeployTokenPrefix = "gdt_"
+
+// DeployTokenScope is a read-only permission granted by a deploy token
+type DeployTokenScope string
+
+// enumerates all deploy token scopes
+const (
+	DeployTokenScopeReadRepository DeployTokenScope = "read_repository"
+	DeployTokenScopeReadLFS        DeployTokenScope = "read_lfs"
+	DeployTokenScopeReadPackage    DeployTokenScope = "read_package"
+)
+
+// DeployTokenScopes are all the scopes a deploy token can be granted
+var DeployTokenScopes = []DeployTokenScope{
+	DeployTokenScopeReadRepository,
+	DeployTokenScopeReadLFS,
+	DeployTokenScopeReadPackage,
+}
+
+// IsValid returns true if the scope is known
+func (s DeployTokenScope) IsValid() bool {
+	for _, scope := range DeployTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// ErrDeployTokenNotExist represents a "DeployTokenNotExist" kind of error.
+type ErrDeployTokenNotExist struct {
+	ID     int64
+	RepoID int64
+}
+
+// IsErrDeployTokenNotExist checks if an error is a ErrDeployTokenNotExist.
+func IsErrDeployTokenNotExist(err error) bool {
+	_, ok := err.(ErrDeployTokenNotExist)
+	return ok
+}
+
+func (err ErrDeployTokenNotExist) Error() string {
+	return fmt.Sprintf("deploy token does not exist [id: %d, repo_id: %d]", err.ID, err.RepoID)
+}
+
+// ErrDeployTokenNameAlreadyUsed represents a "DeployTokenNameAlreadyUsed" kind of error.
+type ErrDeployTokenNameAlreadyUsed struct {
+	RepoID int64
+	Name   string
+}
+
+// IsErrDeployTokenNameAlreadyUsed checks if an error is a ErrDeployTokenNameAlreadyUsed.
+func IsErrDeployTokenNameAlreadyUsed(err error) bool {
+	_, ok := err.(ErrDeployTokenNameAlreadyUsed)
+	return ok
+}
+
+func (err ErrDeployTokenNameAlreadyUsed) Error() string {
+	return fmt.Sprintf("deploy token with name already exists [repo_id: %d, name: %s]", err.RepoID, err.Name)
+}
+
+// ErrInvalidDeployToken represents a "InvalidDeployToken" kind of error.
+type ErrInvalidDeployToken struct {
+	Reason string
+}
+
+// IsErrInvalidDeployToken checks if an error is a ErrInvalidDeployToken.
+func IsErrInvalidDeployToken(err error) bool {
+	_, ok := err.(ErrInvalidDeployToken)
+	return ok
+}
+
+func (err ErrInvalidDeployToken) Error() string {
+	return fmt.Sprintf("invalid deploy token: %s", err.Reason)
+}
+
+// DeployToken grants read-only HTTP access to a repository, its LFS objects or the packages of its owner
+// without belonging to a user. It is sent as the password of HTTP basic authentication.
+type DeployToken struct {
+	ID             int64  `xorm:"pk autoincr"`
+	RepoID         int64  `xorm:"INDEX NOT NULL"`
+	Name           string `xorm:"NOT NULL"`
+	Token          string `xorm:"-"`
+	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
+	TokenSalt      string
+	TokenLastEight string `xorm:"INDEX token_last_eight"`
+	// Scopes is a comma separated list of the granted scopes
+	Scopes    string `xorm:"TEXT"`
+	CreatorID int64
+
+	ExpiresUnix       timeutil.TimeStamp `xorm:"INDEX"`
+	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
+	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"`
+	HasRecentActivity bool               `xorm:"-"`
+	HasUsed           bool               `xorm:"-"`
+}
+
+func init() {
+	db.RegisterModel(new(DeployToken))
+}
+
+// AfterLoad is invoked from XORM after setting the values of all fields of this object.
+func (t *DeployToken) AfterLoad() {
+	t.HasUsed = t.UpdatedUnix > t.CreatedUnix
+	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
+}
+
+// ScopeList returns the granted scopes
+func (t *DeployToken) ScopeList() []DeployTokenScope {
+	if t.Scopes == "" {
+		return nil
+	}
+	parts := strings.Split(t.Scopes, ",")
+	scopes := make([]DeployTokenScope, 0, len(parts))
+	for _, part := range parts {
+		scopes = append(scopes, DeployTokenScope(part))
+	}
+	return scopes
+}
+
+// HasScope returns true if the deploy token grants the scope
+func (t *DeployToken) HasScope(scope DeployTokenScope) bool {
+	for _, s := range t.ScopeList() {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// IsExpired returns true if the expiry of the deploy token has passed
+func (t *DeployToken) IsExpired() bool {
+	return t.ExpiresUnix > 0 && t.ExpiresUnix <= timeutil.TimeStampNow()
+}
+
+// SetScopes validates the scopes and stores them in their canonical order, at least one scope is required
+func (t *DeployToken) SetScopes(scopes []string) error {
+	granted := make(map[DeployTokenScope]bool, len(scopes))
+	for _, s := range scopes {
+		scope := DeployTokenScope(strings.TrimSpace(s))
+		if !scope.IsValid() {
+			return ErrInvalidDeployToken{Reason: fmt.Sprintf("unknown scope %q", s)}
+		}
+		granted[scope] = true
+	}
+	if len(granted) == 0 {
+		return ErrInvalidDeployToken{Reason: "at least one scope is required"}
+	}
+
+	list := make([]string, 0, len(granted))
+	for _, scope := range DeployTokenScopes {
+		if granted[scope] {
+			list = append(list, string(scope))
+		}
+	}
+	t.Scopes = strings.Join(list, ",")
+	return nil
+}
+
+// IsDeployToken returns true if the value has the form of a deploy token
+func IsDeployToken(token string) bool {
+	return strings.HasPrefix(token, DeployTokenPrefix) && len(token) == len(DeployTokenPrefix)+40
+}
+
+// NewDeployToken generates the token of a deploy token and inserts it, its name has to be unique within the repository.
+// The plain token is only available in Token afterwards.
+func NewDeployToken(ctx context.Context, t *DeployToken) error {
+	if t.Name == "" {
+		return ErrInvalidDeployToken{Reason: "name is required"}
+	}
+	if t.Scopes == "" {
+		return ErrInvalidDeployToken{Reason: "at least one scope is required"}
+	}
+
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	has, err := db.GetEngine(ctx).Where("repo_id = ? AND name = ?", t.RepoID, t.Name).Exist(new(DeployToken))
+	if err != nil {
+		return err
+	} else if has {
+		return ErrDeployTokenNameAlreadyUsed{RepoID: t.RepoID, Name: t.Name}
+	}
+
+	salt, err := util.CryptoRandomString(10)
+	if err != nil {
+		return err
+	}
+	t.TokenSalt = salt
+	t.Token = DeployTokenPrefix + base.EncodeSha1(gouuid.New().String())
+	t.TokenHash = auth.HashToken(t.Token, t.TokenSalt)
+	t.TokenLastEight = t.Token[len(t.Token)-8:]
+	if err := db.Insert(ctx, t); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// GetDeployTokenByID returns the deploy token of a repository with the given id
+func GetDeployTokenByID(ctx context.Context, repoID, id int64) (*DeployToken, error) {
+	t := &DeployToken{}
+	has, err := db.GetEngine(ctx).Where("id = ? AND repo_id = ?", id, repoID).Get(t)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrDeployTokenNotExist{ID: id, RepoID: repoID}
+	}
+	return t, nil
+}
+
+// GetDeployTokenByToken returns the deploy token with the given value, expired tokens are not returned
+func GetDeployTokenByToken(ctx context.Context, token string) (*DeployToken, error) {
+	if !IsDeployToken(token) {
+		return nil, ErrDeployTokenNotExist{}
+	}
+
+	var tokens []*DeployToken
+	if err := db.GetEngine(ctx).Where("token_last_eight = ?", token[len(token)-8:]).Find(&tokens); err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		tempHash := auth.HashToken(token, t.TokenSalt)
+		if subtle.ConstantTimeCompare([]byte(t.TokenHash), []byte(tempHash)) == 1 {
+			if t.IsExpired() {
+				break
+			}
+			return t, nil
+		}
+	}
+	return nil, ErrDeployTokenNotExist{}
+}
+
+// UpdateDeployTokenLastUsed records that the deploy token has just been used
+func UpdateDeployTokenLastUsed(ctx context.Context, t *DeployToken) error {
+	t.UpdatedUnix = timeutil.TimeStampNow()
+	_, err := db.GetEngine(ctx).ID(t.ID).Cols("updated_unix").NoAutoTime().Update(t)
+	return err
+}
+
+// FindDeployTokens returns the deploy tokens of a repository ordered by name
+func FindDeployTokens(ctx context.Context, repoID int64) ([]*DeployToken, error) {
+	tokens := make([]*DeployToken, 0, 5)
+	return tokens, db.GetEngine(ctx).Where("repo_id = ?", repoID).OrderBy("name ASC").Find(&tokens)
+}
+
+// DeleteDeployTokenByName deletes the deploy token of a repository with the given name, if it exists
+func DeleteDeployTokenByName(ctx context.Context, repoID int64, name string) error {
+	_, err := db.GetEngine(ctx).Where("repo_id = ? AND name = ?", repoID, name).Delete(new(DeployToken))
+	return err
+}
+
+// DeleteDeployToken deletes the deploy token of a repository with the given id
+func DeleteDeployToken(ctx context.Context, repoID, id int64) error {
+	cnt, err := db.GetEngine(ctx).Where("id = ? AND repo_id = ?", id, repoID).Delete(new(DeployToken))
+	if err != nil {
+		return err
+	} else if cnt == 0 {
+		return ErrDeployTokenNotExist{ID: id, RepoID: repoID}
+	}
+	return nil
+}
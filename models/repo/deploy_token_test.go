@@ -0,0 +1,62 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeployTokenSetScopes(t *testing.T) {
+	token := &DeployToken{}
+	assert.NoError(t, token.SetScopes([]string{"read_package", "read_repository", "read_package"}))
+	assert.Equal(t, "read_repository,read_package", token.Scopes)
+	assert.True(t, token.HasScope(DeployTokenScopeReadPackage))
+	assert.False(t, token.HasScope(DeployTokenScopeReadLFS))
+
+	assert.True(t, IsErrInvalidDeployToken(token.SetScopes([]string{"write_repository"})))
+	assert.True(t, IsErrInvalidDeployToken(token.SetScopes(nil)))
+}
+
+func TestDeployToken(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	token := &DeployToken{RepoID: 1, Name: "ci"}
+	assert.NoError(t, token.SetScopes([]string{"read_repository", "read_lfs"}))
+	assert.NoError(t, NewDeployToken(db.DefaultContext, token))
+	assert.True(t, IsDeployToken(token.Token))
+
+	err := NewDeployToken(db.DefaultContext, &DeployToken{RepoID: 1, Name: "ci", Scopes: "read_lfs"})
+	assert.True(t, IsErrDeployTokenNameAlreadyUsed(err))
+
+	found, err := GetDeployTokenByToken(db.DefaultContext, token.Token)
+	assert.NoError(t, err)
+	assert.Equal(t, token.ID, found.ID)
+	assert.True(t, found.HasScope(DeployTokenScopeReadLFS))
+
+	_, err = GetDeployTokenByToken(db.DefaultContext, DeployTokenPrefix+"0000000000000000000000000000000000000000")
+	assert.True(t, IsErrDeployTokenNotExist(err))
+
+	// expired tokens are not accepted anymore
+	found.ExpiresUnix = timeutil.TimeStampNow().Add(-1)
+	_, err = db.GetEngine(db.DefaultContext).ID(found.ID).Cols("expires_unix").Update(found)
+	assert.NoError(t, err)
+	_, err = GetDeployTokenByToken(db.DefaultContext, token.Token)
+	assert.True(t, IsErrDeployTokenNotExist(err))
+
+	tokens, err := FindDeployTokens(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.Len(t, tokens, 1)
+
+	assert.True(t, IsErrDeployTokenNotExist(DeleteDeployToken(db.DefaultContext, 2, token.ID)))
+	assert.NoError(t, DeleteDeployToken(db.DefaultContext, 1, token.ID))
+	_, err = GetDeployTokenByID(db.DefaultContext, 1, token.ID)
+	assert.True(t, IsErrDeployTokenNotExist(err))
+}
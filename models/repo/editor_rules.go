@@ -0,0 +1,55 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"strings"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/gobwas/glob"
+)
+
+// parseFilePatterns parses a semicolon separated list of file patterns like the protected file patterns of branches
+func parseFilePatterns(filePatterns string) []glob.Glob {
+	globs := make([]glob.Glob, 0, 10)
+	for _, expr := range strings.Split(strings.ToLower(filePatterns), ";") {
+		expr = strings.TrimSpace(expr)
+		if expr != "" {
+			if g, err := glob.Compile(expr, '.', '/'); err != nil {
+				log.Info("Invalid glob expression '%s' (skipped): %v", expr, err)
+			} else {
+				globs = append(globs, g)
+			}
+		}
+	}
+	return globs
+}
+
+func matchFilePatterns(filePatterns, treePath string) bool {
+	treePath = strings.ToLower(strings.TrimPrefix(treePath, "/"))
+	for _, g := range parseFilePatterns(filePatterns) {
+		if g.Match(treePath) {
+			return true
+		}
+	}
+	return false
+}
+
+// HasEditorFileRules returns true if the web editor and the contents API are restricted for some files
+func (repo *Repository) HasEditorFileRules() bool {
+	return strings.TrimSpace(repo.EditorLockedFilePatterns) != "" || strings.TrimSpace(repo.EditorPullRequestFilePatterns) != ""
+}
+
+// IsEditorLockedFile returns true if the file can't be changed by the web editor and the contents API
+func (repo *Repository) IsEditorLockedFile(treePath string) bool {
+	return matchFilePatterns(repo.EditorLockedFilePatterns, treePath)
+}
+
+// IsEditorPullRequestFile returns true if the file can only be changed by the web editor and the contents API
+// on a new branch, so the change is merged through a pull request
+func (repo *Repository) IsEditorPullRequestFile(treePath string) bool {
+	return matchFilePatterns(repo.EditorPullRequestFilePatterns, treePath)
+}
@@ -0,0 +1,31 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepository_EditorFileRules(t *testing.T) {
+	repo := &Repository{}
+	assert.False(t, repo.HasEditorFileRules())
+	assert.False(t, repo.IsEditorLockedFile(".gitea/workflows/build.yml"))
+
+	repo.EditorLockedFilePatterns = ".gitea/workflows/**; LICENSE"
+	repo.EditorPullRequestFilePatterns = "*.lock"
+	assert.True(t, repo.HasEditorFileRules())
+
+	assert.True(t, repo.IsEditorLockedFile(".gitea/workflows/build.yml"))
+	assert.True(t, repo.IsEditorLockedFile("/.gitea/workflows/ci/test.yaml"))
+	assert.True(t, repo.IsEditorLockedFile("license"))
+	assert.False(t, repo.IsEditorLockedFile(".gitea/issue_template.md"))
+	assert.False(t, repo.IsEditorLockedFile("docs/LICENSE"))
+
+	assert.True(t, repo.IsEditorPullRequestFile("yarn.lock"))
+	assert.False(t, repo.IsEditorPullRequestFile("web/yarn.lock"))
+	assert.False(t, repo.IsEditorPullRequestFile("README.md"))
+}
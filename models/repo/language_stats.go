@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/go-enry/go-enry/v2"
@@ -177,7 +178,27 @@ func UpdateLanguageStats(repo *Repository, commitID string, stats map[string]int
 		return err
 	}
 
-	return committer.Commit()
+	if err := committer.Commit(); err != nil {
+		return err
+	}
+	cache.InvalidateFragments(cache.FragmentRepo, repo.ID)
+	return nil
+}
+
+// ClearLanguageStats deletes the language statistics of the repository, so the stats indexer computes them again
+func ClearLanguageStats(ctx context.Context, repo *Repository) error {
+	if err := db.WithTx(func(ctx context.Context) error {
+		if _, err := db.GetEngine(ctx).Where("`repo_id` = ?", repo.ID).Delete(&LanguageStat{}); err != nil {
+			return err
+		}
+		_, err := db.GetEngine(ctx).Where("`repo_id` = ? AND `indexer_type` = ?", repo.ID, RepoIndexerTypeStats).Delete(&RepoIndexerStatus{})
+		return err
+	}, ctx); err != nil {
+		return err
+	}
+	repo.StatsIndexerStatus = nil
+	cache.InvalidateFragments(cache.FragmentRepo, repo.ID)
+	return nil
 }
 
 // CopyLanguageStat Copy originalRepo language stat information to destRepo (use for forked repo)
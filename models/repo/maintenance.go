@@ -0,0 +1,124 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// RepoMaintenance counts the pushes to a repository since its git data has last been repacked
+// and records the outcome of the last maintenance
+type RepoMaintenance struct { //revive:disable-line:exported
+	ID     int64 `xorm:"pk autoincr"`
+	RepoID int64 `xorm:"UNIQUE NOT NULL"`
+	// PushCount is the number of pushes since the last maintenance
+	PushCount int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// LastDuration is the duration of the last maintenance in milliseconds
+	LastDuration int64
+	// LastError is the error of the last maintenance, empty if it succeeded
+	LastError string `xorm:"TEXT"`
+
+	LastMaintenanceUnix timeutil.TimeStamp
+	LastPushUnix        timeutil.TimeStamp
+
+	Repo *Repository `xorm:"-"`
+}
+
+func init() {
+	db.RegisterModel(new(RepoMaintenance))
+}
+
+// HasFailed returns true if the last maintenance of the repository has failed
+func (m *RepoMaintenance) HasFailed() bool {
+	return m.LastError != ""
+}
+
+// IncreaseMaintenancePushCount counts a push to the repository
+func IncreaseMaintenancePushCount(ctx context.Context, repoID int64) error {
+	now := timeutil.TimeStampNow()
+	e := db.GetEngine(ctx)
+	if n, err := e.Where("repo_id = ?", repoID).Incr("push_count").Cols("last_push_unix").Update(&RepoMaintenance{LastPushUnix: now}); err != nil || n > 0 {
+		return err
+	}
+	_, err := e.Insert(&RepoMaintenance{RepoID: repoID, PushCount: 1, LastPushUnix: now})
+	return err
+}
+
+// GetRepoMaintenance returns the maintenance status of a repository, a repository which has never been pushed to has none
+func GetRepoMaintenance(ctx context.Context, repoID int64) (*RepoMaintenance, error) {
+	m := new(RepoMaintenance)
+	has, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Get(m)
+	if err != nil || !has {
+		return nil, err
+	}
+	return m, nil
+}
+
+// FindReposNeedingMaintenance returns the maintenance status of at most limit repositories
+// with at least minPushes pushes since their last maintenance, the busiest first
+func FindReposNeedingMaintenance(ctx context.Context, minPushes int64, limit int) ([]*RepoMaintenance, error) {
+	if minPushes < 1 {
+		minPushes = 1
+	}
+	ms := make([]*RepoMaintenance, 0, limit)
+	sess := db.GetEngine(ctx).Where("push_count >= ?", minPushes).Desc("push_count").Asc("id")
+	if limit > 0 {
+		sess = sess.Limit(limit)
+	}
+	return ms, sess.Find(&ms)
+}
+
+// FinishRepoMaintenance records the outcome of a maintenance of the repository which started with pushCount pushes to count.
+// The pushes during the maintenance are counted for the next one, a failed maintenance isn't retried before the next pushes either.
+func FinishRepoMaintenance(ctx context.Context, repoID, pushCount, durationMillis int64, maintenanceErr error) error {
+	m := &RepoMaintenance{
+		LastDuration:        durationMillis,
+		LastMaintenanceUnix: timeutil.TimeStampNow(),
+	}
+	if maintenanceErr != nil {
+		m.LastError = maintenanceErr.Error()
+	}
+	_, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).
+		Decr("push_count", pushCount).
+		Cols("last_duration", "last_error", "last_maintenance_unix").
+		Update(m)
+	return err
+}
+
+// FindRepoMaintenancesOptions represents the options to list the maintenance status of repositories
+type FindRepoMaintenancesOptions struct {
+	db.ListOptions
+	OnlyFailed bool
+}
+
+// FindRepoMaintenances returns the maintenance status of repositories, the busiest first
+func FindRepoMaintenances(ctx context.Context, opts *FindRepoMaintenancesOptions) ([]*RepoMaintenance, int64, error) {
+	cond := builder.NewCond()
+	if opts.OnlyFailed {
+		cond = cond.And(builder.Neq{"last_error": ""})
+	}
+	sess := db.GetEngine(ctx).Where(cond).Desc("push_count").Asc("id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	ms := make([]*RepoMaintenance, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&ms)
+	return ms, count, err
+}
+
+// CountReposPendingMaintenance returns the number of repositories which have been pushed to since their last maintenance
+// and the number of repositories whose last maintenance has failed
+func CountReposPendingMaintenance(ctx context.Context) (pending, failed int64, err error) {
+	if pending, err = db.GetEngine(ctx).Where("push_count > 0").Count(new(RepoMaintenance)); err != nil {
+		return 0, 0, err
+	}
+	failed, err = db.GetEngine(ctx).Where(builder.Neq{"last_error": ""}).Count(new(RepoMaintenance))
+	return pending, failed, err
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"errors"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRepoMaintenance(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	m, err := GetRepoMaintenance(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.Nil(t, m)
+
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, IncreaseMaintenancePushCount(db.DefaultContext, 1))
+	}
+	assert.NoError(t, IncreaseMaintenancePushCount(db.DefaultContext, 2))
+
+	ms, err := FindReposNeedingMaintenance(db.DefaultContext, 2, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, ms, 1) {
+		assert.EqualValues(t, 1, ms[0].RepoID)
+		assert.EqualValues(t, 3, ms[0].PushCount)
+	}
+
+	// a push during the maintenance is counted for the next one
+	assert.NoError(t, IncreaseMaintenancePushCount(db.DefaultContext, 1))
+	assert.NoError(t, FinishRepoMaintenance(db.DefaultContext, 1, 3, 42, nil))
+	m, err = GetRepoMaintenance(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, m.PushCount)
+	assert.EqualValues(t, 42, m.LastDuration)
+	assert.False(t, m.HasFailed())
+	assert.NotZero(t, m.LastMaintenanceUnix)
+
+	assert.NoError(t, FinishRepoMaintenance(db.DefaultContext, 2, 1, 7, errors.New("repack failed")))
+	pending, failed, err := CountReposPendingMaintenance(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, pending)
+	assert.EqualValues(t, 1, failed)
+
+	ms, count, err := FindRepoMaintenances(db.DefaultContext, &FindRepoMaintenancesOptions{OnlyFailed: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, ms, 1) {
+		assert.EqualValues(t, 2, ms[0].RepoID)
+		assert.Equal(t, "repack failed", ms[0].LastError)
+	}
+}
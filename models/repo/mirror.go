@@ -14,6 +14,8 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
 )
 
 // ErrMirrorNotExist mirror does not exist error
@@ -117,6 +119,7 @@ func MirrorsIterate(limit int, f func(idx int, bean interface{}) error) error {
 	return db.GetEngine(db.DefaultContext).
 		Where("next_update_unix<=?", time.Now().Unix()).
 		And("next_update_unix!=0").
+		And(builder.NotIn("repo_id", builder.Select("id").From("repository").Where(builder.Neq{"trashed_unix": 0}))).
 		OrderBy("updated_unix ASC").
 		Limit(limit).
 		Iterate(new(Mirror), f)
@@ -11,6 +11,8 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
 )
 
 // ErrPushMirrorNotExist mirror does not exist error
@@ -98,6 +100,7 @@ func PushMirrorsIterate(limit int, f func(idx int, bean interface{}) error) erro
 	return db.GetEngine(db.DefaultContext).
 		Where("last_update + (`interval` / ?) <= ?", time.Second, time.Now().Unix()).
 		And("`interval` != 0").
+		And(builder.NotIn("repo_id", builder.Select("id").From("repository").Where(builder.Neq{"trashed_unix": 0}))).
 		OrderBy("last_update ASC").
 		Limit(limit).
 		Iterate(new(PushMirror), f)
@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 // ErrRedirectNotExist represents a "RedirectNotExist" kind of error.
@@ -34,6 +35,8 @@ type Redirect struct {
 	OwnerID        int64  `xorm:"UNIQUE(s)"`
 	LowerName      string `xorm:"UNIQUE(s) INDEX NOT NULL"`
 	RedirectRepoID int64  // repoID to redirect to
+	// ExpiresUnix is when the redirect stops working, 0 if it never expires
+	ExpiresUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
 }
 
 // TableName represents real table name in database
@@ -51,7 +54,7 @@ func LookupRedirect(ownerID int64, repoName string) (int64, error) {
 	redirect := &Redirect{OwnerID: ownerID, LowerName: repoName}
 	if has, err := db.GetEngine(db.DefaultContext).Get(redirect); err != nil {
 		return 0, err
-	} else if !has {
+	} else if !has || (redirect.ExpiresUnix > 0 && redirect.ExpiresUnix <= timeutil.TimeStampNow()) {
 		return 0, ErrRedirectNotExist{OwnerID: ownerID, RepoName: repoName}
 	}
 	return redirect.RedirectRepoID, nil
@@ -59,6 +62,11 @@ func LookupRedirect(ownerID int64, repoName string) (int64, error) {
 
 // NewRedirect create a new repo redirect
 func NewRedirect(ctx context.Context, ownerID, repoID int64, oldRepoName, newRepoName string) error {
+	return NewExpiringRedirect(ctx, ownerID, repoID, oldRepoName, newRepoName, 0)
+}
+
+// NewExpiringRedirect create a new repo redirect which stops working at the given time, it never expires if it is 0
+func NewExpiringRedirect(ctx context.Context, ownerID, repoID int64, oldRepoName, newRepoName string, expires timeutil.TimeStamp) error {
 	oldRepoName = strings.ToLower(oldRepoName)
 	newRepoName = strings.ToLower(newRepoName)
 
@@ -70,6 +78,7 @@ func NewRedirect(ctx context.Context, ownerID, repoID int64, oldRepoName, newRep
 		OwnerID:        ownerID,
 		LowerName:      oldRepoName,
 		RedirectRepoID: repoID,
+		ExpiresUnix:    expires,
 	})
 }
 
@@ -80,3 +89,9 @@ func DeleteRedirect(ctx context.Context, ownerID int64, repoName string) error {
 	_, err := db.GetEngine(ctx).Delete(&Redirect{OwnerID: ownerID, LowerName: repoName})
 	return err
 }
+
+// DeleteExpiredRedirects deletes the redirects which have expired at the given time
+func DeleteExpiredRedirects(ctx context.Context, now timeutil.TimeStamp) error {
+	_, err := db.GetEngine(ctx).Where("expires_unix > 0 AND expires_unix <= ?", now).Delete(&Redirect{})
+	return err
+}
@@ -9,6 +9,7 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -75,3 +76,23 @@ func TestNewRedirect3(t *testing.T) {
 		RedirectRepoID: repo.ID,
 	})
 }
+
+func TestExpiringRedirect(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	now := timeutil.TimeStampNow()
+	assert.NoError(t, NewExpiringRedirect(db.DefaultContext, 3, repo.ID, "expired", "expired", now-60))
+	assert.NoError(t, NewExpiringRedirect(db.DefaultContext, 3, repo.ID, "expiring", "expiring", now+3600))
+
+	_, err := LookupRedirect(3, "expired")
+	assert.True(t, IsErrRedirectNotExist(err))
+	repoID, err := LookupRedirect(3, "expiring")
+	assert.NoError(t, err)
+	assert.EqualValues(t, repo.ID, repoID)
+
+	assert.NoError(t, DeleteExpiredRedirects(db.DefaultContext, now))
+	unittest.AssertNotExistsBean(t, &Redirect{OwnerID: 3, LowerName: "expired"})
+	unittest.AssertExistsAndLoadBean(t, &Redirect{OwnerID: 3, LowerName: "expiring"})
+	unittest.AssertExistsAndLoadBean(t, &Redirect{OwnerID: 2, LowerName: "oldrepo1"})
+}
@@ -48,6 +48,8 @@ const (
 	CommitterTrustModel
 	CollaboratorTrustModel
 	CollaboratorCommitterTrustModel
+	AllValidKeysTrustModel
+	WebOfTrustTrustModel
 )
 
 // String converts a TrustModelType to a string
@@ -61,6 +63,10 @@ func (t TrustModelType) String() string {
 		return "collaborator"
 	case CollaboratorCommitterTrustModel:
 		return "collaboratorcommitter"
+	case AllValidKeysTrustModel:
+		return "allvalidkeys"
+	case WebOfTrustTrustModel:
+		return "weboftrust"
 	}
 	return "default"
 }
@@ -76,6 +82,10 @@ func ToTrustModel(model string) TrustModelType {
 		return CommitterTrustModel
 	case "collaboratorcommitter":
 		return CollaboratorCommitterTrustModel
+	case "allvalidkeys":
+		return AllValidKeysTrustModel
+	case "weboftrust":
+		return WebOfTrustTrustModel
 	}
 	return DefaultTrustModel
 }
@@ -120,6 +130,9 @@ type Repository struct {
 	NumProjects         int `xorm:"NOT NULL DEFAULT 0"`
 	NumClosedProjects   int `xorm:"NOT NULL DEFAULT 0"`
 	NumOpenProjects     int `xorm:"-"`
+	NumDiscussions      int `xorm:"NOT NULL DEFAULT 0"`
+	NumReleases         int `xorm:"NOT NULL DEFAULT 0"`
+	NumTags             int `xorm:"NOT NULL DEFAULT 0"`
 
 	IsPrivate  bool `xorm:"INDEX"`
 	IsEmpty    bool `xorm:"INDEX"`
@@ -137,6 +150,7 @@ type Repository struct {
 	ForkID                          int64              `xorm:"INDEX"`
 	BaseRepo                        *Repository        `xorm:"-"`
 	IsTemplate                      bool               `xorm:"INDEX NOT NULL DEFAULT false"`
+	NoIndex                         bool               `xorm:"NOT NULL DEFAULT false"`
 	TemplateID                      int64              `xorm:"INDEX"`
 	Size                            int64              `xorm:"NOT NULL DEFAULT 0"`
 	CodeIndexerStatus               *RepoIndexerStatus `xorm:"-"`
@@ -144,14 +158,25 @@ type Repository struct {
 	IsFsckEnabled                   bool               `xorm:"NOT NULL DEFAULT true"`
 	CloseIssuesViaCommitInAnyBranch bool               `xorm:"NOT NULL DEFAULT false"`
 	Topics                          []string           `xorm:"TEXT JSON"`
+	// License is the license the repository was initialized with or the one set afterwards
+	License string `xorm:"VARCHAR(255) INDEX"`
 
 	TrustModel TrustModelType
 
+	// EditorLockedFilePatterns and EditorPullRequestFilePatterns are semicolon separated globs of the files which
+	// can't be changed by the web editor and the contents API at all, or only on a new branch for a pull request
+	EditorLockedFilePatterns      string `xorm:"TEXT"`
+	EditorPullRequestFilePatterns string `xorm:"TEXT"`
+
 	// Avatar: ID(10-20)-md5(32) - must fit into 64 symbols
 	Avatar string `xorm:"VARCHAR(64)"`
 
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
+	PushedUnix  timeutil.TimeStamp `xorm:"INDEX"`
+	// TrashedUnix is set while the deleted repository waits in the trash to be purged
+	TrashedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	TrashedByID int64              `xorm:"NOT NULL DEFAULT 0"`
 }
 
 func init() {
@@ -635,6 +660,7 @@ func GetRepositoryByOwnerAndNameCtx(ctx context.Context, ownerName, repoName str
 		Join("INNER", "`user`", "`user`.id = repository.owner_id").
 		Where("repository.lower_name = ?", strings.ToLower(repoName)).
 		And("`user`.lower_name = ?", strings.ToLower(ownerName)).
+		And("repository.trashed_unix = 0").
 		Get(&repo)
 	if err != nil {
 		return nil, err
@@ -650,7 +676,7 @@ func GetRepositoryByName(ownerID int64, name string) (*Repository, error) {
 		OwnerID:   ownerID,
 		LowerName: strings.ToLower(name),
 	}
-	has, err := db.GetEngine(db.DefaultContext).Get(repo)
+	has, err := db.GetEngine(db.DefaultContext).Where("trashed_unix = 0").Get(repo)
 	if err != nil {
 		return nil, err
 	} else if !has {
@@ -740,3 +766,8 @@ func CountRepositories(ctx context.Context, opts CountRepositoryOptions) (int64,
 	}
 	return count, nil
 }
+
+// SumOwnerRepoSize returns the size of all repositories of the owner including their LFS objects
+func SumOwnerRepoSize(ctx context.Context, ownerID int64) (int64, error) {
+	return db.GetEngine(ctx).Where("owner_id = ?", ownerID).SumInt(new(Repository), "size")
+}
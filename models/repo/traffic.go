@@ -0,0 +1,153 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// TrafficDays is the number of days the traffic of a repository is kept for
+const TrafficDays = 14
+
+const secondsPerDay = 24 * 60 * 60
+
+// TrafficKind is the kind of git operation or download recorded in the traffic of a repository
+type TrafficKind int
+
+// The git operations and downloads recorded in the traffic of a repository
+const (
+	TrafficKindClone TrafficKind = iota + 1
+	TrafficKindFetch
+	TrafficKindArchive
+	TrafficKindRaw
+)
+
+// Name returns the name of the kind of git operation or download
+func (kind TrafficKind) Name() string {
+	switch kind {
+	case TrafficKindClone:
+		return "clone"
+	case TrafficKindFetch:
+		return "fetch"
+	case TrafficKindArchive:
+		return "archive"
+	case TrafficKindRaw:
+		return "raw"
+	}
+	return ""
+}
+
+// IsDownload returns true for the archive downloads and raw file hits
+func (kind TrafficKind) IsDownload() bool {
+	return kind == TrafficKindArchive || kind == TrafficKindRaw
+}
+
+// RepoTraffic counts the git operations or downloads of one visitor on a repository on one day
+type RepoTraffic struct { //revive:disable-line:exported
+	ID     int64              `xorm:"pk autoincr"`
+	RepoID int64              `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	Kind   TrafficKind        `xorm:"UNIQUE(s) NOT NULL"`
+	Day    timeutil.TimeStamp `xorm:"UNIQUE(s) INDEX NOT NULL"`
+	// Visitor identifies a signed in user by its id and an anonymous visitor by a salted hash of its IP address
+	Visitor string `xorm:"VARCHAR(80) UNIQUE(s) NOT NULL"`
+	Count   int64  `xorm:"NOT NULL DEFAULT 0"`
+}
+
+func init() {
+	db.RegisterModel(new(RepoTraffic))
+}
+
+// trafficDay returns the start of the UTC day of the time stamp
+func trafficDay(t timeutil.TimeStamp) timeutil.TimeStamp {
+	return t - t%secondsPerDay
+}
+
+// RecordTraffic counts a git operation or download of the visitor on the repository
+func RecordTraffic(ctx context.Context, repoID int64, kind TrafficKind, visitor string) error {
+	day := trafficDay(timeutil.TimeStampNow())
+	cond := builder.Eq{"repo_id": repoID, "kind": kind, "day": day, "visitor": visitor}
+
+	e := db.GetEngine(ctx)
+	if n, err := e.Where(cond).Incr("count").Update(new(RepoTraffic)); err != nil || n > 0 {
+		return err
+	}
+	if err := db.Insert(ctx, &RepoTraffic{
+		RepoID:  repoID,
+		Kind:    kind,
+		Day:     day,
+		Visitor: visitor,
+		Count:   1,
+	}); err == nil {
+		return nil
+	}
+	// another operation of the visitor has inserted the row in the meantime
+	_, err := e.Where(cond).Incr("count").Update(new(RepoTraffic))
+	return err
+}
+
+// TrafficDay are the git operations or downloads of a kind on a repository on one day
+type TrafficDay struct {
+	Day     timeutil.TimeStamp
+	Count   int64
+	Uniques int64
+}
+
+// Traffic are the git operations or downloads of a kind on a repository over the last TrafficDays days
+type Traffic struct {
+	Kind    TrafficKind
+	Count   int64
+	Uniques int64
+	Days    []*TrafficDay
+}
+
+// GetTraffic returns the git operations or downloads of a kind on the repository over the last TrafficDays days,
+// the days are sorted from the oldest to today and include the days without any operation.
+func GetTraffic(ctx context.Context, repoID int64, kind TrafficKind) (*Traffic, error) {
+	today := trafficDay(timeutil.TimeStampNow())
+	since := today - (TrafficDays-1)*secondsPerDay
+	cond := builder.Eq{"repo_id": repoID, "kind": kind}.And(builder.Gte{"day": since})
+
+	// every row belongs to another visitor of the day, so the rows of a day are its unique visitors
+	days := make([]*TrafficDay, 0, TrafficDays)
+	if err := db.GetEngine(ctx).Table("repo_traffic").Where(cond).
+		Select("day, SUM(count) AS count, COUNT(*) AS uniques").
+		GroupBy("day").Find(&days); err != nil {
+		return nil, err
+	}
+	uniques, err := db.GetEngine(ctx).Distinct("visitor").Table("repo_traffic").Where(cond).Count()
+	if err != nil {
+		return nil, err
+	}
+
+	traffic := &Traffic{
+		Kind:    kind,
+		Uniques: uniques,
+		Days:    make([]*TrafficDay, TrafficDays),
+	}
+	for i := range traffic.Days {
+		traffic.Days[i] = &TrafficDay{Day: since + timeutil.TimeStamp(i*secondsPerDay)}
+	}
+	for _, day := range days {
+		i := int((day.Day - since) / secondsPerDay)
+		if i < 0 || i >= TrafficDays {
+			continue
+		}
+		traffic.Days[i] = day
+		traffic.Count += day.Count
+	}
+	return traffic, nil
+}
+
+// DeleteOldTraffic deletes the traffic of all repositories which is older than TrafficDays days
+func DeleteOldTraffic(ctx context.Context) error {
+	since := trafficDay(timeutil.TimeStampNow()) - (TrafficDays-1)*secondsPerDay
+	_, err := db.GetEngine(ctx).Where(builder.Lt{"day": since}).Delete(new(RepoTraffic))
+	return err
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTraffic(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer timeutil.Unset()
+
+	now := time.Date(2022, 8, 20, 15, 30, 0, 0, time.UTC)
+	record := func(daysAgo int, kind TrafficKind, visitor string) {
+		timeutil.Set(now.AddDate(0, 0, -daysAgo))
+		assert.NoError(t, RecordTraffic(db.DefaultContext, 1, kind, visitor))
+	}
+	record(20, TrafficKindClone, "user:1")
+	record(13, TrafficKindClone, "user:1")
+	record(1, TrafficKindClone, "user:1")
+	record(1, TrafficKindClone, "user:1")
+	record(1, TrafficKindClone, "user:2")
+	record(0, TrafficKindClone, "ip:abc")
+	record(0, TrafficKindFetch, "user:1")
+	timeutil.Set(now)
+
+	clones, err := GetTraffic(db.DefaultContext, 1, TrafficKindClone)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 5, clones.Count)
+	assert.EqualValues(t, 3, clones.Uniques)
+	if assert.Len(t, clones.Days, TrafficDays) {
+		assert.EqualValues(t, time.Date(2022, 8, 7, 0, 0, 0, 0, time.UTC).Unix(), clones.Days[0].Day)
+		assert.EqualValues(t, 1, clones.Days[0].Count)
+		assert.EqualValues(t, 0, clones.Days[1].Count)
+		assert.EqualValues(t, 3, clones.Days[12].Count)
+		assert.EqualValues(t, 2, clones.Days[12].Uniques)
+		assert.EqualValues(t, time.Date(2022, 8, 20, 0, 0, 0, 0, time.UTC).Unix(), clones.Days[13].Day)
+		assert.EqualValues(t, 1, clones.Days[13].Count)
+	}
+
+	fetches, err := GetTraffic(db.DefaultContext, 1, TrafficKindFetch)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, fetches.Count)
+	assert.EqualValues(t, 1, fetches.Uniques)
+
+	assert.NoError(t, DeleteOldTraffic(db.DefaultContext))
+	unittest.AssertCount(t, &RepoTraffic{RepoID: 1}, 5)
+	unittest.AssertNotExistsBean(t, &RepoTraffic{RepoID: 1, Day: timeutil.TimeStamp(time.Date(2022, 7, 31, 0, 0, 0, 0, time.UTC).Unix())})
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// IsTrashed returns true if the repository was deleted and waits in the trash to be purged
+func (repo *Repository) IsTrashed() bool {
+	return repo.TrashedUnix != 0
+}
+
+// TrashExpiryUnix returns the time the trashed repository gets purged
+func (repo *Repository) TrashExpiryUnix() timeutil.TimeStamp {
+	return repo.TrashedUnix.AddDuration(setting.Repository.TrashRetentionPeriod)
+}
+
+// TrashRepository moves the repository into the trash, it is hidden until it gets restored or purged
+func TrashRepository(ctx context.Context, repo *Repository, doerID int64) error {
+	repo.TrashedUnix = timeutil.TimeStampNow()
+	repo.TrashedByID = doerID
+	_, err := db.GetEngine(ctx).ID(repo.ID).Cols("trashed_unix", "trashed_by_id").NoAutoTime().Update(repo)
+	return err
+}
+
+// RestoreTrashedRepository takes the repository out of the trash
+func RestoreTrashedRepository(ctx context.Context, repo *Repository) error {
+	repo.TrashedUnix = 0
+	repo.TrashedByID = 0
+	_, err := db.GetEngine(ctx).ID(repo.ID).Cols("trashed_unix", "trashed_by_id").NoAutoTime().Update(repo)
+	return err
+}
+
+// FindTrashedRepositoriesOptions are the options to find repositories in the trash
+type FindTrashedRepositoriesOptions struct {
+	db.ListOptions
+	OwnerIDs      []int64
+	TrashedBefore timeutil.TimeStamp
+}
+
+func (opts *FindTrashedRepositoriesOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Neq{"trashed_unix": 0})
+	if len(opts.OwnerIDs) != 0 {
+		cond = cond.And(builder.In("owner_id", opts.OwnerIDs))
+	}
+	if opts.TrashedBefore != 0 {
+		cond = cond.And(builder.Lt{"trashed_unix": opts.TrashedBefore})
+	}
+	return cond
+}
+
+// FindTrashedRepositories returns the repositories in the trash, the oldest first
+func FindTrashedRepositories(ctx context.Context, opts *FindTrashedRepositoriesOptions) ([]*Repository, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).OrderBy("trashed_unix ASC")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	repos := make([]*Repository, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&repos)
+	return repos, count, err
+}
+
+// GetTrashedRepositoryByID returns the repository with the given id if it is in the trash
+func GetTrashedRepositoryByID(ctx context.Context, id int64) (*Repository, error) {
+	repo := new(Repository)
+	has, err := db.GetEngine(ctx).ID(id).Where("trashed_unix <> 0").Get(repo)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrRepoNotExist{id, 0, "", ""}
+	}
+	return repo, nil
+}
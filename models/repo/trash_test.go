@@ -0,0 +1,42 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrashRepository(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &Repository{ID: 1}).(*Repository)
+	assert.NoError(t, TrashRepository(db.DefaultContext, repo, 2))
+	assert.True(t, repo.IsTrashed())
+
+	_, err := GetRepositoryByOwnerAndName("user2", "repo1")
+	assert.True(t, IsErrRepoNotExist(err))
+
+	repos, count, err := FindTrashedRepositories(db.DefaultContext, &FindTrashedRepositoriesOptions{OwnerIDs: []int64{2}})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, repos, 1) {
+		assert.EqualValues(t, 1, repos[0].ID)
+		assert.EqualValues(t, 2, repos[0].TrashedByID)
+	}
+
+	trashed, err := GetTrashedRepositoryByID(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.NoError(t, RestoreTrashedRepository(db.DefaultContext, trashed))
+
+	_, err = GetTrashedRepositoryByID(db.DefaultContext, 1)
+	assert.True(t, IsErrRepoNotExist(err))
+	_, err = GetRepositoryByOwnerAndName("user2", "repo1")
+	assert.NoError(t, err)
+}
@@ -36,9 +36,9 @@ func UpdateRepositoryOwnerNames(ownerID int64, ownerName string) error {
 	return committer.Commit()
 }
 
-// UpdateRepositoryUpdatedTime updates a repository's updated time
-func UpdateRepositoryUpdatedTime(repoID int64, updateTime time.Time) error {
-	_, err := db.GetEngine(db.DefaultContext).Exec("UPDATE repository SET updated_unix = ? WHERE id = ?", updateTime.Unix(), repoID)
+// UpdateRepositoryPushedTime updates a repository's updated and pushed time
+func UpdateRepositoryPushedTime(repoID int64, pushTime time.Time) error {
+	_, err := db.GetEngine(db.DefaultContext).Exec("UPDATE repository SET updated_unix = ?, pushed_unix = ? WHERE id = ?", pushTime.Unix(), pushTime.Unix(), repoID)
 	return err
 }
 
@@ -0,0 +1,86 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ScheduledVisibilityChange represents a change of the visibility of a repository planned for a later time.
+// A repository has at most one scheduled change.
+type ScheduledVisibilityChange struct {
+	ID            int64              `xorm:"pk autoincr"`
+	RepoID        int64              `xorm:"UNIQUE NOT NULL"`
+	MakePrivate   bool               `xorm:"NOT NULL DEFAULT false"`
+	DoerID        int64              `xorm:"NOT NULL"`
+	Doer          *user_model.User   `xorm:"-"`
+	ScheduledUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created"`
+}
+
+func init() {
+	db.RegisterModel(new(ScheduledVisibilityChange))
+}
+
+// LoadDoer loads the user who scheduled the change
+func (c *ScheduledVisibilityChange) LoadDoer(ctx context.Context) error {
+	if c.Doer != nil {
+		return nil
+	}
+	var err error
+	if c.Doer, err = user_model.GetUserByIDCtx(ctx, c.DoerID); err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			return err
+		}
+		c.Doer = user_model.NewGhostUser()
+	}
+	return nil
+}
+
+// ScheduleVisibilityChange stores the change, replacing a change already scheduled for the repository
+func ScheduleVisibilityChange(ctx context.Context, change *ScheduledVisibilityChange) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if _, err := db.GetEngine(ctx).Where("repo_id = ?", change.RepoID).Delete(new(ScheduledVisibilityChange)); err != nil {
+		return err
+	}
+	if err := db.Insert(ctx, change); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// GetScheduledVisibilityChange returns the change scheduled for the repository, or nil if there is none
+func GetScheduledVisibilityChange(ctx context.Context, repoID int64) (*ScheduledVisibilityChange, error) {
+	change := &ScheduledVisibilityChange{}
+	has, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Get(change)
+	if err != nil || !has {
+		return nil, err
+	}
+	return change, nil
+}
+
+// CancelScheduledVisibilityChange removes the change scheduled for the repository
+func CancelScheduledVisibilityChange(ctx context.Context, repoID int64) error {
+	_, err := db.GetEngine(ctx).Where("repo_id = ?", repoID).Delete(new(ScheduledVisibilityChange))
+	return err
+}
+
+// FindDueVisibilityChanges returns the changes scheduled until the given time
+func FindDueVisibilityChanges(ctx context.Context, until timeutil.TimeStamp) ([]*ScheduledVisibilityChange, error) {
+	changes := make([]*ScheduledVisibilityChange, 0, 10)
+	return changes, db.GetEngine(ctx).
+		Where("scheduled_unix <= ?", until).
+		OrderBy("scheduled_unix ASC").
+		Find(&changes)
+}
@@ -8,6 +8,7 @@ package models
 import (
 	"context"
 	"fmt"
+	"sort"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
@@ -59,42 +60,117 @@ func AddCollaborator(repo *repo_model.Repository, u *user_model.User) error {
 
 // DeleteCollaboration removes collaboration relation between the user and repository.
 func DeleteCollaboration(repo *repo_model.Repository, uid int64) (err error) {
-	collaboration := &repo_model.Collaboration{
-		RepoID: repo.ID,
-		UserID: uid,
-	}
-
 	ctx, committer, err := db.TxContext()
 	if err != nil {
 		return err
 	}
 	defer committer.Close()
 
+	if err := deleteCollaboration(ctx, repo, uid); err != nil {
+		return err
+	}
+
+	return committer.Commit()
+}
+
+func deleteCollaboration(ctx context.Context, repo *repo_model.Repository, uid int64) error {
+	collaboration := &repo_model.Collaboration{
+		RepoID: repo.ID,
+		UserID: uid,
+	}
+
 	if has, err := db.GetEngine(ctx).Delete(collaboration); err != nil || has == 0 {
 		return err
 	} else if err = access_model.RecalculateAccesses(ctx, repo); err != nil {
 		return err
 	}
 
-	if err = repo_model.WatchRepo(ctx, uid, repo.ID, false); err != nil {
+	if err := repo_model.WatchRepo(ctx, uid, repo.ID, false); err != nil {
 		return err
 	}
 
-	if err = reconsiderWatches(ctx, repo, uid); err != nil {
+	if err := reconsiderWatches(ctx, repo, uid); err != nil {
 		return err
 	}
 
 	// Unassign a user from any issue (s)he has been assigned to in the repository
-	if err := reconsiderRepoIssuesAssignee(ctx, repo, uid); err != nil {
-		return err
+	return reconsiderRepoIssuesAssignee(ctx, repo, uid)
+}
+
+// CollaboratorAccess is a user with the access mode it is supposed to have as a collaborator of a repository.
+// AccessModeNone keeps the access mode of an existing collaborator and gives write access to a new one.
+type CollaboratorAccess struct {
+	User *user_model.User
+	Mode perm.AccessMode
+}
+
+// CollaboratorChange is a change of the access mode of a collaborator of a repository,
+// OldMode is AccessModeNone for an added collaborator and NewMode is AccessModeNone for a removed one
+type CollaboratorChange struct {
+	User    *user_model.User
+	OldMode perm.AccessMode
+	NewMode perm.AccessMode
+}
+
+// SetCollaborators replaces the collaborators of a repository with the given ones in one transaction:
+// missing users are added, the access modes of existing ones are changed and the other collaborators are removed.
+// It returns the changes sorted by user name, including the unchanged collaborators.
+func SetCollaborators(ctx context.Context, repo *repo_model.Repository, accesses []*CollaboratorAccess) ([]*CollaboratorChange, error) {
+	changes := make([]*CollaboratorChange, 0, len(accesses))
+	err := db.WithTx(func(ctx context.Context) error {
+		collaborators, err := repo_model.GetCollaborators(ctx, repo.ID, db.ListOptions{})
+		if err != nil {
+			return err
+		}
+		current := make(map[int64]*repo_model.Collaborator, len(collaborators))
+		for _, collaborator := range collaborators {
+			current[collaborator.ID] = collaborator
+		}
+
+		for _, access := range accesses {
+			change := &CollaboratorChange{User: access.User, NewMode: access.Mode}
+			if collaborator, ok := current[access.User.ID]; ok {
+				change.OldMode = collaborator.Collaboration.Mode
+				delete(current, access.User.ID)
+			} else if err := addCollaborator(ctx, repo, access.User); err != nil {
+				return err
+			}
+			if change.NewMode == perm.AccessModeNone {
+				change.NewMode = change.OldMode
+				if change.NewMode == perm.AccessModeNone {
+					change.NewMode = perm.AccessModeWrite
+				}
+			}
+			if err := repo_model.ChangeCollaborationAccessModeCtx(ctx, repo, access.User.ID, change.NewMode); err != nil {
+				return err
+			}
+			changes = append(changes, change)
+		}
+
+		for _, collaborator := range current {
+			if err := deleteCollaboration(ctx, repo, collaborator.ID); err != nil {
+				return err
+			}
+			changes = append(changes, &CollaboratorChange{User: collaborator.User, OldMode: collaborator.Collaboration.Mode})
+		}
+		return nil
+	}, ctx)
+	if err != nil {
+		return nil, err
 	}
 
-	return committer.Commit()
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].User.LowerName < changes[j].User.LowerName
+	})
+	return changes, nil
 }
 
 func reconsiderRepoIssuesAssignee(ctx context.Context, repo *repo_model.Repository, uid int64) error {
 	user, err := user_model.GetUserByIDCtx(ctx, uid)
-	if err != nil {
+	if user_model.IsErrUserNotExist(err) {
+		// the assignments of deleted users are already gone
+		return nil
+	} else if err != nil {
 		return err
 	}
 
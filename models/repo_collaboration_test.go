@@ -64,3 +64,59 @@ func TestRepository_DeleteCollaboration(t *testing.T) {
 
 	unittest.CheckConsistencyFor(t, &repo_model.Repository{ID: repo.ID})
 }
+
+func TestRepository_SetCollaborators(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 4}).(*repo_model.Repository)
+	assert.NoError(t, repo.GetOwner(db.DefaultContext))
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	user4 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+
+	changes, err := SetCollaborators(db.DefaultContext, repo, []*CollaboratorAccess{
+		{User: user4, Mode: perm.AccessModeAdmin},
+		{User: user2, Mode: perm.AccessModeRead},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 3) {
+		// sorted by user name
+		assert.EqualValues(t, 2, changes[0].User.ID)
+		assert.Equal(t, perm.AccessModeNone, changes[0].OldMode)
+		assert.Equal(t, perm.AccessModeRead, changes[0].NewMode)
+		assert.EqualValues(t, 29, changes[1].User.ID)
+		assert.Equal(t, perm.AccessModeWrite, changes[1].OldMode)
+		assert.Equal(t, perm.AccessModeNone, changes[1].NewMode)
+		assert.EqualValues(t, 4, changes[2].User.ID)
+		assert.Equal(t, perm.AccessModeWrite, changes[2].OldMode)
+		assert.Equal(t, perm.AccessModeAdmin, changes[2].NewMode)
+	}
+
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Collaboration{RepoID: repo.ID, UserID: 2, Mode: perm.AccessModeRead})
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Collaboration{RepoID: repo.ID, UserID: 4, Mode: perm.AccessModeAdmin})
+	unittest.AssertNotExistsBean(t, &repo_model.Collaboration{RepoID: repo.ID, UserID: 29})
+	unittest.AssertNotExistsBean(t, &access_model.Access{RepoID: repo.ID, UserID: 29})
+	unittest.CheckConsistencyFor(t, &repo_model.Repository{ID: repo.ID})
+
+	// without access mode existing collaborators keep theirs and new ones get write access
+	user29 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 29}).(*user_model.User)
+	changes, err = SetCollaborators(db.DefaultContext, repo, []*CollaboratorAccess{
+		{User: user4},
+		{User: user2, Mode: perm.AccessModeRead},
+		{User: user29},
+	})
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 3) {
+		assert.Equal(t, perm.AccessModeNone, changes[1].OldMode)
+		assert.Equal(t, perm.AccessModeWrite, changes[1].NewMode)
+		assert.Equal(t, perm.AccessModeAdmin, changes[2].OldMode)
+		assert.Equal(t, perm.AccessModeAdmin, changes[2].NewMode)
+	}
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Collaboration{RepoID: repo.ID, UserID: 4, Mode: perm.AccessModeAdmin})
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Collaboration{RepoID: repo.ID, UserID: 29, Mode: perm.AccessModeWrite})
+
+	// an empty list removes all collaborators
+	changes, err = SetCollaborators(db.DefaultContext, repo, nil)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 3)
+	unittest.AssertCount(t, &repo_model.Collaboration{RepoID: repo.ID}, 0)
+}
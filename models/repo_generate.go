@@ -76,6 +76,10 @@ func GenerateWebhooks(ctx context.Context, templateRepo, generateRepo *repo_mode
 	}
 
 	for _, templateWebhook := range templateWebhooks {
+		// inherited webhooks are copied from the templates of the owner of the generated repository
+		if templateWebhook.IsInherited() {
+			continue
+		}
 		generateWebhook := &webhook.Webhook{
 			RepoID:      generateRepo.ID,
 			URL:         templateWebhook.URL,
@@ -148,8 +148,13 @@ type SearchRepoOptions struct {
 	Archived util.OptionalBool
 	// only search topic name
 	TopicOnly bool
+	// only search repositories with all (TopicsMatchAll) or any of the topics
+	Topics         []string
+	TopicsMatchAll bool
 	// only search repositories with specified primary language
 	Language string
+	// only search repositories with specified license
+	License string
 	// include description in keyword search
 	IncludeDescription bool
 	// None -> include has milestones AND has no milestone
@@ -158,6 +163,10 @@ type SearchRepoOptions struct {
 	HasMilestones util.OptionalBool
 	// LowerNames represents valid lower names to restrict to
 	LowerNames []string
+	// include repositories waiting in the trash to be purged
+	IncludeTrashed bool
+	// exclude repositories asking search engines not to index them or their owner
+	ExcludeNoIndex bool
 }
 
 // SearchOrderBy is used to sort the result
@@ -377,6 +386,15 @@ func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
 			)))
 	}
 
+	// the repositories of suspended users are hidden from everyone but the users themselves and site admins
+	if opts.Actor == nil || !opts.Actor.IsAdmin {
+		suspendedCond := builder.NewCond().And(builder.Eq{"is_suspended": true})
+		if opts.Actor != nil {
+			suspendedCond = suspendedCond.And(builder.Neq{"id": opts.Actor.ID})
+		}
+		cond = cond.And(builder.NotIn("owner_id", builder.Select("id").From("`user`").Where(suspendedCond)))
+	}
+
 	if opts.IsPrivate != util.OptionalBoolNone {
 		cond = cond.And(builder.Eq{"is_private": opts.IsPrivate.IsTrue()})
 	}
@@ -484,6 +502,29 @@ func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
 			Where(builder.Eq{"language": opts.Language}).And(builder.Eq{"is_primary": true})))
 	}
 
+	if len(opts.Topics) > 0 {
+		topicCond := func(names ...string) builder.Cond {
+			return builder.In("id", builder.Select("repo_topic.repo_id").From("repo_topic").
+				Join("INNER", "topic", "topic.id = repo_topic.topic_id").
+				Where(builder.In("topic.name", names)))
+		}
+		names := make([]string, 0, len(opts.Topics))
+		for _, topic := range opts.Topics {
+			names = append(names, strings.ToLower(strings.TrimSpace(topic)))
+		}
+		if opts.TopicsMatchAll {
+			for _, name := range names {
+				cond = cond.And(topicCond(name))
+			}
+		} else {
+			cond = cond.And(topicCond(names...))
+		}
+	}
+
+	if opts.License != "" {
+		cond = cond.And(builder.Eq{"license": opts.License})
+	}
+
 	if opts.Fork != util.OptionalBoolNone {
 		cond = cond.And(builder.Eq{"is_fork": opts.Fork == util.OptionalBoolTrue})
 	}
@@ -507,6 +548,15 @@ func SearchRepositoryCondition(opts *SearchRepoOptions) builder.Cond {
 		cond = cond.And(builder.Eq{"num_milestones": 0}.Or(builder.IsNull{"num_milestones"}))
 	}
 
+	if !opts.IncludeTrashed {
+		cond = cond.And(builder.Eq{"trashed_unix": 0})
+	}
+
+	if opts.ExcludeNoIndex {
+		cond = cond.And(builder.Eq{"repository.no_index": false},
+			builder.NotIn("repository.owner_id", builder.Select("id").From("`user`").Where(builder.Eq{"no_index": true})))
+	}
+
 	return cond
 }
 
@@ -687,6 +737,9 @@ func GetUserRepositories(opts *SearchRepoOptions) (RepositoryList, int64, error)
 	if opts.LowerNames != nil && len(opts.LowerNames) > 0 {
 		cond = cond.And(builder.In("lower_name", opts.LowerNames))
 	}
+	if !opts.IncludeTrashed {
+		cond = cond.And(builder.Eq{"trashed_unix": 0})
+	}
 
 	sess := db.GetEngine(db.DefaultContext)
 
@@ -10,6 +10,7 @@ import (
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
@@ -349,6 +350,32 @@ func TestSearchRepository(t *testing.T) {
 	}
 }
 
+func TestSearchRepositorySuspendedOwner(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 14}).(*user_model.User)
+	assert.NoError(t, user_model.SuspendUser(db.DefaultContext, owner, ""))
+
+	for _, c := range []struct {
+		actor *user_model.User
+		count int64
+	}{
+		{nil, 0},
+		{unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User), 0},
+		{owner, 1},
+		{unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User), 1},
+	} {
+		_, count, err := SearchRepositoryByName(&SearchRepoOptions{
+			ListOptions: db.ListOptions{Page: 1, PageSize: 10},
+			Keyword:     "repo_12",
+			Actor:       c.actor,
+			Collaborate: util.OptionalBoolFalse,
+		})
+		assert.NoError(t, err)
+		assert.Equal(t, c.count, count)
+	}
+}
+
 func TestSearchRepositoryByTopicName(t *testing.T) {
 	assert.NoError(t, unittest.PrepareTestDatabase())
 
@@ -372,6 +399,26 @@ func TestSearchRepositoryByTopicName(t *testing.T) {
 			opts:  &SearchRepoOptions{OwnerID: 21, AllPublic: true, Keyword: "graphql,golang", TopicOnly: true},
 			count: 2,
 		},
+		{
+			name:  "AllPublic/SearchPublicRepositoriesWithAnyTopic",
+			opts:  &SearchRepoOptions{OwnerID: 21, AllPublic: true, Topics: []string{"graphql", "database"}},
+			count: 2,
+		},
+		{
+			name:  "AllPublic/SearchPublicRepositoriesWithAllTopics",
+			opts:  &SearchRepoOptions{OwnerID: 21, AllPublic: true, Topics: []string{"golang", "graphql"}, TopicsMatchAll: true},
+			count: 1,
+		},
+		{
+			name:  "AllPublic/SearchPublicRepositoriesWithAllTopicsNoMatch",
+			opts:  &SearchRepoOptions{OwnerID: 21, AllPublic: true, Topics: []string{"graphql", "database"}, TopicsMatchAll: true},
+			count: 0,
+		},
+		{
+			name:  "AllPublic/SearchPublicRepositoriesWithTopicAndLicense",
+			opts:  &SearchRepoOptions{OwnerID: 21, AllPublic: true, Topics: []string{"golang"}, License: "MIT"},
+			count: 1,
+		},
 	}
 
 	for _, testCase := range testCases {
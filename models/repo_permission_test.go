@@ -264,3 +264,40 @@ func TestRepoPermissionPrivateOrgRepo(t *testing.T) {
 		assert.True(t, perm.CanWrite(unit.Type))
 	}
 }
+
+func TestRepoPermissionRestrictedReleaseActions(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	// private organization repo with write access to releases by team1
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
+	team := unittest.AssertExistsAndLoadBean(t, &organization.Team{ID: 2}).(*organization.Team)
+
+	member := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	perm, err := access_model.GetUserRepoPermission(db.DefaultContext, repo, member)
+	assert.NoError(t, err)
+	assert.True(t, perm.CanDoReleaseAction(perm_model.ReleaseActionCreate))
+	assert.True(t, perm.CanDoReleaseAction(perm_model.ReleaseActionEdit))
+	assert.True(t, perm.CanDoReleaseAction(perm_model.ReleaseActionUploadAssets))
+
+	team.RestrictedReleaseActions = perm_model.ReleaseActionEdit
+	assert.NoError(t, UpdateTeam(team, false, false))
+
+	perm, err = access_model.GetUserRepoPermission(db.DefaultContext, repo, member)
+	assert.NoError(t, err)
+	assert.True(t, perm.CanWrite(unit.TypeReleases))
+	assert.True(t, perm.CanDoReleaseAction(perm_model.ReleaseActionCreate))
+	assert.False(t, perm.CanDoReleaseAction(perm_model.ReleaseActionEdit))
+	assert.True(t, perm.CanDoReleaseAction(perm_model.ReleaseActionUploadAssets))
+
+	// collaborators with write access are not restricted
+	collaborator := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	perm, err = access_model.GetUserRepoPermission(db.DefaultContext, repo, collaborator)
+	assert.NoError(t, err)
+	assert.True(t, perm.CanDoReleaseAction(perm_model.ReleaseActionEdit))
+
+	// restrictions do not grant access to releases
+	reader := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 10}).(*user_model.User)
+	perm, err = access_model.GetUserRepoPermission(db.DefaultContext, repo, reader)
+	assert.NoError(t, err)
+	assert.False(t, perm.CanDoReleaseAction(perm_model.ReleaseActionCreate))
+}
@@ -8,12 +8,14 @@ import (
 	"context"
 	"fmt"
 	"os"
+	"time"
 
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
@@ -30,10 +32,47 @@ type RepoTransfer struct {
 	TeamIDs     []int64
 	Teams       []*organization.Team `xorm:"-"`
 
+	// ScheduledUnix is the time of the cutover, 0 if the transfer is carried out as soon as it is accepted
+	ScheduledUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+	// RedirectDays is how many days the old location redirects to the new one, 0 if the redirect is kept forever
+	RedirectDays int `xorm:"NOT NULL DEFAULT 0"`
+	// Accepted is true once the transfer doesn't need to be accepted anymore and only waits for the cutover
+	Accepted bool `xorm:"NOT NULL DEFAULT false"`
+
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL updated"`
 }
 
+// RepoTransferSchedule holds when a transfer is carried out and how long the old location keeps redirecting
+type RepoTransferSchedule struct {
+	// ScheduledUnix is the time of the cutover, 0 carries out the transfer as soon as possible
+	ScheduledUnix timeutil.TimeStamp
+	// RedirectDays is how many days the old location redirects to the new one, 0 keeps the redirect forever
+	RedirectDays int
+}
+
+// IsScheduled returns whether the cutover of the transfer is still ahead
+func (s RepoTransferSchedule) IsScheduled() bool {
+	return s.ScheduledUnix > timeutil.TimeStampNow()
+}
+
+// RedirectExpiry returns when the redirect from the old location expires if the transfer is carried out now,
+// 0 if it never expires
+func (s RepoTransferSchedule) RedirectExpiry() timeutil.TimeStamp {
+	if s.RedirectDays <= 0 {
+		return 0
+	}
+	return timeutil.TimeStampNow().AddDuration(time.Duration(s.RedirectDays) * 24 * time.Hour)
+}
+
+// Schedule returns when the transfer is carried out and how long the old location keeps redirecting
+func (r *RepoTransfer) Schedule() RepoTransferSchedule {
+	return RepoTransferSchedule{
+		ScheduledUnix: r.ScheduledUnix,
+		RedirectDays:  r.RedirectDays,
+	}
+}
+
 func init() {
 	db.RegisterModel(new(RepoTransfer))
 }
@@ -141,6 +180,22 @@ func CancelRepositoryTransfer(repo *repo_model.Repository) error {
 	return committer.Commit()
 }
 
+// AcceptScheduledRepositoryTransfer marks the transfer as accepted, it is carried out at its scheduled time
+func AcceptScheduledRepositoryTransfer(ctx context.Context, transfer *RepoTransfer) error {
+	transfer.Accepted = true
+	_, err := db.GetEngine(ctx).ID(transfer.ID).Cols("accepted").Update(transfer)
+	return err
+}
+
+// FindDueRepositoryTransfers returns the accepted transfers whose cutover is scheduled until the given time
+func FindDueRepositoryTransfers(ctx context.Context, until timeutil.TimeStamp) ([]*RepoTransfer, error) {
+	transfers := make([]*RepoTransfer, 0, 10)
+	return transfers, db.GetEngine(ctx).
+		Where("accepted = ? AND scheduled_unix <= ?", true, until).
+		OrderBy("scheduled_unix ASC").
+		Find(&transfers)
+}
+
 // TestRepositoryReadyForTransfer make sure repo is ready to transfer
 func TestRepositoryReadyForTransfer(status repo_model.RepositoryStatus) error {
 	switch status {
@@ -153,8 +208,8 @@ func TestRepositoryReadyForTransfer(status repo_model.RepositoryStatus) error {
 }
 
 // CreatePendingRepositoryTransfer transfer a repo from one owner to a new one.
-// it marks the repository transfer as "pending"
-func CreatePendingRepositoryTransfer(doer, newOwner *user_model.User, repoID int64, teams []*organization.Team) error {
+// it marks the repository transfer as "pending", an accepted transfer only waits for its scheduled cutover
+func CreatePendingRepositoryTransfer(doer, newOwner *user_model.User, repoID int64, teams []*organization.Team, schedule RepoTransferSchedule, accepted bool) error {
 	ctx, committer, err := db.TxContext()
 	if err != nil {
 		return err
@@ -187,12 +242,15 @@ func CreatePendingRepositoryTransfer(doer, newOwner *user_model.User, repoID int
 	}
 
 	transfer := &RepoTransfer{
-		RepoID:      repo.ID,
-		RecipientID: newOwner.ID,
-		CreatedUnix: timeutil.TimeStampNow(),
-		UpdatedUnix: timeutil.TimeStampNow(),
-		DoerID:      doer.ID,
-		TeamIDs:     make([]int64, 0, len(teams)),
+		RepoID:        repo.ID,
+		RecipientID:   newOwner.ID,
+		CreatedUnix:   timeutil.TimeStampNow(),
+		UpdatedUnix:   timeutil.TimeStampNow(),
+		DoerID:        doer.ID,
+		TeamIDs:       make([]int64, 0, len(teams)),
+		ScheduledUnix: schedule.ScheduledUnix,
+		RedirectDays:  schedule.RedirectDays,
+		Accepted:      accepted,
 	}
 
 	for k := range teams {
@@ -207,7 +265,8 @@ func CreatePendingRepositoryTransfer(doer, newOwner *user_model.User, repoID int
 }
 
 // TransferOwnership transfers all corresponding repository items from old user to new one.
-func TransferOwnership(doer *user_model.User, newOwnerName string, repo *repo_model.Repository) (err error) {
+// The old location redirects to the new one until redirectExpiry, forever if it is 0.
+func TransferOwnership(doer *user_model.User, newOwnerName string, repo *repo_model.Repository, redirectExpiry timeutil.TimeStamp) (err error) {
 	repoRenamed := false
 	wikiRenamed := false
 	oldOwnerName := doer.Name
@@ -381,6 +440,18 @@ func TransferOwnership(doer *user_model.User, newOwnerName string, repo *repo_mo
 		}
 	}
 
+	// Replace the webhooks inherited from the templates of the old organization
+	if oldOwner.IsOrganization() {
+		if err := webhook.DeleteInheritedWebhooks(ctx, repo.ID); err != nil {
+			return fmt.Errorf("deleteInheritedWebhooks: %v", err)
+		}
+	}
+	if newOwner.IsOrganization() {
+		if err := webhook.CopyOrgTemplateWebhooksToRepo(ctx, newOwner.ID, repo.ID); err != nil {
+			return fmt.Errorf("copyOrgTemplateWebhooksToRepo: %v", err)
+		}
+	}
+
 	// Rename remote repository to new path and delete local copy.
 	dir := user_model.UserPath(newOwner.Name)
 
@@ -419,8 +490,8 @@ func TransferOwnership(doer *user_model.User, newOwnerName string, repo *repo_mo
 		return fmt.Errorf("delete repo redirect: %v", err)
 	}
 
-	if err := repo_model.NewRedirect(ctx, oldOwner.ID, repo.ID, repo.Name, repo.Name); err != nil {
-		return fmt.Errorf("repo_model.NewRedirect: %v", err)
+	if err := repo_model.NewExpiringRedirect(ctx, oldOwner.ID, repo.ID, repo.Name, repo.Name, redirectExpiry); err != nil {
+		return fmt.Errorf("repo_model.NewExpiringRedirect: %v", err)
 	}
 
 	return committer.Commit()
@@ -7,9 +7,11 @@ package models
 import (
 	"testing"
 
+	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -34,7 +36,7 @@ func TestRepositoryTransfer(t *testing.T) {
 
 	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
 
-	assert.NoError(t, CreatePendingRepositoryTransfer(doer, user2, repo.ID, nil))
+	assert.NoError(t, CreatePendingRepositoryTransfer(doer, user2, repo.ID, nil, RepoTransferSchedule{}, false))
 
 	transfer, err = GetPendingRepositoryTransfer(repo)
 	assert.Nil(t, err)
@@ -44,14 +46,48 @@ func TestRepositoryTransfer(t *testing.T) {
 	user6 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
 
 	// Only transfer can be started at any given time
-	err = CreatePendingRepositoryTransfer(doer, user6, repo.ID, nil)
+	err = CreatePendingRepositoryTransfer(doer, user6, repo.ID, nil, RepoTransferSchedule{}, false)
 	assert.Error(t, err)
 	assert.True(t, IsErrRepoTransferInProgress(err))
 
 	// Unknown user
-	err = CreatePendingRepositoryTransfer(doer, &user_model.User{ID: 1000, LowerName: "user1000"}, repo.ID, nil)
+	err = CreatePendingRepositoryTransfer(doer, &user_model.User{ID: 1000, LowerName: "user1000"}, repo.ID, nil, RepoTransferSchedule{}, false)
 	assert.Error(t, err)
 
 	// Cancel transfer
 	assert.NoError(t, CancelRepositoryTransfer(repo))
 }
+
+func TestFindDueRepositoryTransfers(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	recipient := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+
+	now := timeutil.TimeStampNow()
+	schedule := RepoTransferSchedule{ScheduledUnix: now + 3600, RedirectDays: 7}
+	assert.True(t, schedule.IsScheduled())
+	assert.NoError(t, CreatePendingRepositoryTransfer(doer, recipient, repo.ID, nil, schedule, false))
+
+	// the fixture and the new transfer haven't been accepted yet
+	transfers, err := FindDueRepositoryTransfers(db.DefaultContext, now+7200)
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 0)
+
+	transfer, err := GetPendingRepositoryTransfer(repo)
+	assert.NoError(t, err)
+	assert.Equal(t, schedule, transfer.Schedule())
+	assert.NoError(t, AcceptScheduledRepositoryTransfer(db.DefaultContext, transfer))
+
+	transfers, err = FindDueRepositoryTransfers(db.DefaultContext, now)
+	assert.NoError(t, err)
+	assert.Len(t, transfers, 0)
+
+	transfers, err = FindDueRepositoryTransfers(db.DefaultContext, now+7200)
+	assert.NoError(t, err)
+	if assert.Len(t, transfers, 1) {
+		assert.Equal(t, transfer.ID, transfers[0].ID)
+		assert.True(t, transfers[0].Accepted)
+	}
+}
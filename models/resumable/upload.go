@@ -0,0 +1,108 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package resumable
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ErrUploadNotExist indicates a resumable upload not exist error
+var ErrUploadNotExist = errors.New("Resumable upload does not exist")
+
+// TargetType is the kind of object created by a resumable upload once it's complete
+type TargetType int
+
+// The targets of resumable uploads
+const (
+	TargetReleaseAttachment TargetType = iota + 1
+	TargetGenericPackage
+)
+
+func init() {
+	db.RegisterModel(new(Upload))
+}
+
+// Upload represents an upload which is received in chunks over several requests
+type Upload struct {
+	ID         string     `xorm:"pk"`
+	DoerID     int64      `xorm:"INDEX NOT NULL"`
+	TargetType TargetType `xorm:"NOT NULL"`
+	// RepoID and ReleaseID identify the release of a release attachment
+	RepoID    int64 `xorm:"NOT NULL DEFAULT 0"`
+	ReleaseID int64 `xorm:"NOT NULL DEFAULT 0"`
+	// OwnerID, PackageName and PackageVersion identify the version of a generic package
+	OwnerID        int64  `xorm:"NOT NULL DEFAULT 0"`
+	PackageName    string `xorm:"NOT NULL DEFAULT ''"`
+	PackageVersion string `xorm:"NOT NULL DEFAULT ''"`
+	Filename       string `xorm:"NOT NULL"`
+	// Size is the announced size of the complete upload, BytesReceived the number of bytes received so far
+	Size          int64              `xorm:"NOT NULL"`
+	BytesReceived int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix   timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix   timeutil.TimeStamp `xorm:"updated INDEX NOT NULL"`
+}
+
+// TableName returns the table name of resumable uploads
+func (u *Upload) TableName() string {
+	return "resumable_upload"
+}
+
+// IsComplete returns true if all bytes of the upload were received
+func (u *Upload) IsComplete() bool {
+	return u.BytesReceived >= u.Size
+}
+
+// CreateUpload inserts an upload with a new random id
+func CreateUpload(ctx context.Context, u *Upload) error {
+	id, err := util.CryptoRandomString(32)
+	if err != nil {
+		return err
+	}
+	u.ID = strings.ToLower(id)
+	u.BytesReceived = 0
+
+	_, err = db.GetEngine(ctx).Insert(u)
+	return err
+}
+
+// GetUploadByID gets an upload by id
+func GetUploadByID(ctx context.Context, id string) (*Upload, error) {
+	u := &Upload{}
+	has, err := db.GetEngine(ctx).ID(id).Get(u)
+	if err != nil {
+		return nil, err
+	}
+	if !has {
+		return nil, ErrUploadNotExist
+	}
+	return u, nil
+}
+
+// UpdateUploadOffset stores the number of bytes received so far
+func UpdateUploadOffset(ctx context.Context, u *Upload) error {
+	_, err := db.GetEngine(ctx).ID(u.ID).Cols("bytes_received", "updated_unix").Update(u)
+	return err
+}
+
+// DeleteUploadByID deletes an upload
+func DeleteUploadByID(ctx context.Context, id string) error {
+	_, err := db.GetEngine(ctx).ID(id).Delete(&Upload{})
+	return err
+}
+
+// FindExpiredUploads gets all uploads which didn't receive data for longer than olderThan
+func FindExpiredUploads(ctx context.Context, olderThan time.Duration) ([]*Upload, error) {
+	uploads := make([]*Upload, 0, 10)
+	return uploads, db.GetEngine(ctx).
+		Where("updated_unix < ?", time.Now().Add(-olderThan).Unix()).
+		Find(&uploads)
+}
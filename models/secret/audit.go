@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// AuditAction represents a change of a secret or variable
+type AuditAction string
+
+// The changes recorded in the audit log
+const (
+	AuditActionCreate AuditAction = "create"
+	AuditActionUpdate AuditAction = "update"
+	AuditActionDelete AuditAction = "delete"
+)
+
+// AuditLog records who changed a secret or variable, the values are never recorded
+type AuditLog struct {
+	ID      int64            `xorm:"pk autoincr"`
+	OwnerID int64            `xorm:"INDEX NOT NULL DEFAULT 0"`
+	RepoID  int64            `xorm:"INDEX NOT NULL DEFAULT 0"`
+	DoerID  int64            `xorm:"NOT NULL"`
+	Doer    *user_model.User `xorm:"-"`
+	Type    Type             `xorm:"NOT NULL DEFAULT 0"`
+	Name    string           `xorm:"NOT NULL"`
+	Action  AuditAction      `xorm:"NOT NULL"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+// TableName sets the table name of the audit log
+func (AuditLog) TableName() string {
+	return "secret_audit_log"
+}
+
+func init() {
+	db.RegisterModel(new(AuditLog))
+}
+
+func addAuditLog(ctx context.Context, doerID int64, scope Scope, tp Type, name string, action AuditAction) error {
+	return db.Insert(ctx, &AuditLog{
+		OwnerID: scope.OwnerID,
+		RepoID:  scope.RepoID,
+		DoerID:  doerID,
+		Type:    tp,
+		Name:    name,
+		Action:  action,
+	})
+}
+
+// FindAuditLogs returns the changes of the secrets and variables of a scope, newest first.
+// The users who made the changes are loaded, deleted users are replaced by a ghost.
+func FindAuditLogs(ctx context.Context, scope Scope, listOptions db.ListOptions) ([]*AuditLog, int64, error) {
+	sess := db.GetEngine(ctx).
+		Where("owner_id = ? AND repo_id = ?", scope.OwnerID, scope.RepoID).
+		OrderBy("created_unix DESC, id DESC")
+	if listOptions.Page > 0 {
+		sess = db.SetSessionPagination(sess, &listOptions)
+	}
+	logs := make([]*AuditLog, 0, listOptions.PageSize)
+	count, err := sess.FindAndCount(&logs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	for _, entry := range logs {
+		entry.Doer, err = user_model.GetUserByIDCtx(ctx, entry.DoerID)
+		if user_model.IsErrUserNotExist(err) {
+			entry.Doer = user_model.NewGhostUser()
+		} else if err != nil {
+			return nil, 0, err
+		}
+	}
+	return logs, count, nil
+}
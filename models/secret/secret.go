@@ -0,0 +1,239 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	secret_module "code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrSecretNotExist represents a "SecretNotExist" kind of error.
+type ErrSecretNotExist struct {
+	Type Type
+	Name string
+}
+
+// IsErrSecretNotExist checks if an error is a ErrSecretNotExist.
+func IsErrSecretNotExist(err error) bool {
+	_, ok := err.(ErrSecretNotExist)
+	return ok
+}
+
+func (err ErrSecretNotExist) Error() string {
+	return fmt.Sprintf("%s does not exist [name: %s]", err.Type, err.Name)
+}
+
+// ErrInvalidName represents a "InvalidName" kind of error.
+type ErrInvalidName struct {
+	Name string
+}
+
+// IsErrInvalidName checks if an error is a ErrInvalidName.
+func IsErrInvalidName(err error) bool {
+	_, ok := err.(ErrInvalidName)
+	return ok
+}
+
+func (err ErrInvalidName) Error() string {
+	return fmt.Sprintf("invalid secret or variable name [name: %s]", err.Name)
+}
+
+// Type represents whether a value is a secret or a variable
+type Type int
+
+// The types of values
+const (
+	// TypeSecret values are never shown after they are saved
+	TypeSecret Type = iota
+	// TypeVariable values can be read by the administrators of the repository or organization
+	TypeVariable
+)
+
+func (t Type) String() string {
+	if t == TypeVariable {
+		return "variable"
+	}
+	return "secret"
+}
+
+// Secret represents a secret or variable of a repository or organization.
+// Values of both types are encrypted at rest with the master key of the server.
+type Secret struct {
+	ID int64 `xorm:"pk autoincr"`
+	// OwnerID is the organization of the secret, 0 for secrets of a repository
+	OwnerID int64  `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	RepoID  int64  `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	Type    Type   `xorm:"UNIQUE(s) NOT NULL DEFAULT 0"`
+	Name    string `xorm:"UNIQUE(s) NOT NULL"`
+	Data    string `xorm:"LONGTEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(Secret))
+}
+
+var namePattern = regexp.MustCompile("^[A-Z_][A-Z0-9_]*$")
+
+// ValidateName returns the upper case name of a secret or variable,
+// names can only contain letters, digits and underscores and can't start with a digit or GITEA_
+func ValidateName(name string) (string, error) {
+	upper := strings.ToUpper(strings.TrimSpace(name))
+	if len(upper) > 255 || !namePattern.MatchString(upper) || strings.HasPrefix(upper, "GITEA_") {
+		return "", ErrInvalidName{Name: name}
+	}
+	return upper, nil
+}
+
+// Value decrypts the value of the secret
+func (s *Secret) Value() (string, error) {
+	return secret_module.DecryptAtRest(s.Data)
+}
+
+// Scope represents the repository or organization owning secrets
+type Scope struct {
+	OwnerID int64
+	RepoID  int64
+}
+
+// RepoScope returns the scope of the secrets of a repository
+func RepoScope(repoID int64) Scope {
+	return Scope{RepoID: repoID}
+}
+
+// OrgScope returns the scope of the secrets of an organization
+func OrgScope(orgID int64) Scope {
+	return Scope{OwnerID: orgID}
+}
+
+// GetSecret returns a secret or variable by name
+func GetSecret(ctx context.Context, scope Scope, tp Type, name string) (*Secret, error) {
+	s := new(Secret)
+	has, err := db.GetEngine(ctx).
+		Where("owner_id = ? AND repo_id = ? AND type = ? AND name = ?", scope.OwnerID, scope.RepoID, tp, strings.ToUpper(name)).
+		Get(s)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrSecretNotExist{Type: tp, Name: name}
+	}
+	return s, nil
+}
+
+// FindSecrets returns the secrets or variables of a scope ordered by name
+func FindSecrets(ctx context.Context, scope Scope, tp Type) ([]*Secret, error) {
+	secrets := make([]*Secret, 0, 10)
+	return secrets, db.GetEngine(ctx).
+		Where("owner_id = ? AND repo_id = ? AND type = ?", scope.OwnerID, scope.RepoID, tp).
+		OrderBy("name ASC").
+		Find(&secrets)
+}
+
+// SetSecret creates or updates a secret or variable and records the change in the audit log.
+// It returns true if the secret was created.
+func SetSecret(ctx context.Context, doerID int64, scope Scope, tp Type, name, value string) (bool, error) {
+	name, err := ValidateName(name)
+	if err != nil {
+		return false, err
+	}
+	data, err := secret_module.EncryptAtRest(value)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return false, err
+	}
+	defer committer.Close()
+
+	s, err := GetSecret(ctx, scope, tp, name)
+	created := IsErrSecretNotExist(err)
+	if err != nil && !created {
+		return false, err
+	}
+
+	action := AuditActionUpdate
+	if created {
+		action = AuditActionCreate
+		s = &Secret{OwnerID: scope.OwnerID, RepoID: scope.RepoID, Type: tp, Name: name, Data: data}
+		err = db.Insert(ctx, s)
+	} else {
+		s.Data = data
+		_, err = db.GetEngine(ctx).ID(s.ID).Cols("data").Update(s)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := addAuditLog(ctx, doerID, scope, tp, name, action); err != nil {
+		return false, err
+	}
+	return created, committer.Commit()
+}
+
+// DeleteSecret deletes a secret or variable and records the change in the audit log
+func DeleteSecret(ctx context.Context, doerID int64, scope Scope, tp Type, name string) error {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	s, err := GetSecret(ctx, scope, tp, name)
+	if err != nil {
+		return err
+	}
+	if _, err := db.GetEngine(ctx).ID(s.ID).Delete(new(Secret)); err != nil {
+		return err
+	}
+	if err := addAuditLog(ctx, doerID, scope, tp, s.Name, AuditActionDelete); err != nil {
+		return err
+	}
+	return committer.Commit()
+}
+
+// DeleteScope deletes the secrets, variables and audit log of a deleted repository or organization
+func DeleteScope(ctx context.Context, scope Scope) error {
+	if scope.OwnerID == 0 && scope.RepoID == 0 {
+		return nil
+	}
+	return db.DeleteBeans(ctx,
+		&Secret{OwnerID: scope.OwnerID, RepoID: scope.RepoID},
+		&AuditLog{OwnerID: scope.OwnerID, RepoID: scope.RepoID},
+	)
+}
+
+// GetValues returns the decrypted secrets or variables available in a repository by name,
+// the values of the repository override the ones of the organization owning it.
+// The ownerID is 0 if the repository isn't owned by an organization.
+func GetValues(ctx context.Context, repoID, ownerID int64, tp Type) (map[string]string, error) {
+	values := make(map[string]string)
+	for _, scope := range []Scope{OrgScope(ownerID), RepoScope(repoID)} {
+		if scope.OwnerID == 0 && scope.RepoID == 0 {
+			continue
+		}
+		secrets, err := FindSecrets(ctx, scope, tp)
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range secrets {
+			value, err := s.Value()
+			if err != nil {
+				return nil, fmt.Errorf("unable to decrypt %s %s: %v", tp, s.Name, err)
+			}
+			values[s.Name] = value
+		}
+	}
+	return values, nil
+}
@@ -0,0 +1,100 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateName(t *testing.T) {
+	for name, expected := range map[string]string{
+		"token":       "TOKEN",
+		" Deploy_Key": "DEPLOY_KEY",
+		"_A1":         "_A1",
+	} {
+		actual, err := ValidateName(name)
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, actual)
+	}
+	for _, name := range []string{"", "1TOKEN", "MY-TOKEN", "GITEA_TOKEN", "gitea_token"} {
+		_, err := ValidateName(name)
+		assert.True(t, IsErrInvalidName(err), name)
+	}
+}
+
+func TestSetAndDeleteSecret(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	created, err := SetSecret(db.DefaultContext, 2, RepoScope(1), TypeSecret, "token", "s3cr3t")
+	assert.NoError(t, err)
+	assert.True(t, created)
+	created, err = SetSecret(db.DefaultContext, 2, RepoScope(1), TypeSecret, "TOKEN", "changed")
+	assert.NoError(t, err)
+	assert.False(t, created)
+	_, err = SetSecret(db.DefaultContext, 2, RepoScope(1), TypeVariable, "token", "variable")
+	assert.NoError(t, err)
+	_, err = SetSecret(db.DefaultContext, 2, RepoScope(1), TypeSecret, "bad-name", "")
+	assert.True(t, IsErrInvalidName(err))
+
+	s, err := GetSecret(db.DefaultContext, RepoScope(1), TypeSecret, "token")
+	assert.NoError(t, err)
+	assert.NotContains(t, s.Data, "changed")
+	value, err := s.Value()
+	assert.NoError(t, err)
+	assert.EqualValues(t, "changed", value)
+
+	secrets, err := FindSecrets(db.DefaultContext, RepoScope(1), TypeVariable)
+	assert.NoError(t, err)
+	assert.Len(t, secrets, 1)
+
+	assert.NoError(t, DeleteSecret(db.DefaultContext, 2, RepoScope(1), TypeSecret, "token"))
+	assert.True(t, IsErrSecretNotExist(DeleteSecret(db.DefaultContext, 2, RepoScope(1), TypeSecret, "token")))
+	_, err = GetSecret(db.DefaultContext, RepoScope(1), TypeVariable, "token")
+	assert.NoError(t, err)
+
+	logs, count, err := FindAuditLogs(db.DefaultContext, RepoScope(1), db.ListOptions{})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, count)
+	if assert.Len(t, logs, 4) {
+		assert.EqualValues(t, AuditActionDelete, logs[0].Action)
+		assert.EqualValues(t, "TOKEN", logs[0].Name)
+		assert.EqualValues(t, "user2", logs[0].Doer.Name)
+		assert.EqualValues(t, AuditActionCreate, logs[3].Action)
+	}
+
+	assert.NoError(t, DeleteScope(db.DefaultContext, RepoScope(1)))
+	unittest.AssertNotExistsBean(t, &Secret{RepoID: 1})
+	unittest.AssertNotExistsBean(t, &AuditLog{RepoID: 1})
+}
+
+func TestGetValues(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, s := range []struct {
+		scope       Scope
+		name, value string
+	}{
+		{OrgScope(3), "SHARED", "org"},
+		{OrgScope(3), "ORG_ONLY", "org"},
+		{RepoScope(3), "SHARED", "repo"},
+		{RepoScope(1), "OTHER", "repo"},
+	} {
+		_, err := SetSecret(db.DefaultContext, 2, s.scope, TypeSecret, s.name, s.value)
+		assert.NoError(t, err)
+	}
+
+	values, err := GetValues(db.DefaultContext, 3, 3, TypeSecret)
+	assert.NoError(t, err)
+	assert.EqualValues(t, map[string]string{"SHARED": "repo", "ORG_ONLY": "org"}, values)
+
+	values, err = GetValues(db.DefaultContext, 3, 3, TypeVariable)
+	assert.NoError(t, err)
+	assert.Empty(t, values)
+}
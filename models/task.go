@@ -149,6 +149,97 @@ func (task *Task) MigrateConfig() (*migration.MigrateOptions, error) {
 	return nil, fmt.Errorf("Task type is %s, not Migrate Repo", task.Type.Name())
 }
 
+// BulkReposManifest returns the manifest of a bulk repository creation task
+func (task *Task) BulkReposManifest() (*structs.BulkRepoManifest, error) {
+	if task.Type != structs.TaskTypeBulkCreateRepos {
+		return nil, fmt.Errorf("Task type is %s, not Bulk Create Repositories", task.Type.Name())
+	}
+	var manifest structs.BulkRepoManifest
+	if err := json.Unmarshal([]byte(task.PayloadContent), &manifest); err != nil {
+		return nil, err
+	}
+
+	// decrypt credentials
+	for _, entry := range manifest.Repos {
+		for _, field := range []*string{&entry.CloneAddr, &entry.AuthPassword, &entry.AuthToken} {
+			if *field == "" {
+				continue
+			}
+			decrypted, err := secret.DecryptSecret(setting.SecretKey, *field)
+			if err != nil {
+				return nil, err
+			}
+			*field = decrypted
+		}
+	}
+	return &manifest, nil
+}
+
+// BulkReposResults returns the per repository progress of a bulk repository creation task
+func (task *Task) BulkReposResults() ([]*structs.BulkRepoResult, error) {
+	var results []*structs.BulkRepoResult
+	if task.Message == "" {
+		return results, nil
+	}
+	err := json.Unmarshal([]byte(task.Message), &results)
+	return results, err
+}
+
+// GetBulkCreateReposTask returns the bulk repository creation task of an owner by id
+func GetBulkCreateReposTask(ownerID, id int64) (*Task, error) {
+	task := Task{
+		ID:      id,
+		OwnerID: ownerID,
+		Type:    structs.TaskTypeBulkCreateRepos,
+	}
+	has, err := db.GetEngine(db.DefaultContext).Get(&task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{id, 0, task.Type}
+	}
+	return &task, nil
+}
+
+// RepoHousekeepingOperations returns the operations of a repository housekeeping task
+func (task *Task) RepoHousekeepingOperations() ([]string, error) {
+	if task.Type != structs.TaskTypeRepoHousekeeping {
+		return nil, fmt.Errorf("Task type is %s, not Repository Housekeeping", task.Type.Name())
+	}
+	var operations []string
+	err := json.Unmarshal([]byte(task.PayloadContent), &operations)
+	return operations, err
+}
+
+// GetRepoHousekeepingTask returns the housekeeping task of a repository by id
+func GetRepoHousekeepingTask(repoID, id int64) (*Task, error) {
+	task := Task{
+		ID:     id,
+		RepoID: repoID,
+		Type:   structs.TaskTypeRepoHousekeeping,
+	}
+	has, err := db.GetEngine(db.DefaultContext).Get(&task)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrTaskDoesNotExist{id, repoID, task.Type}
+	}
+	return &task, nil
+}
+
+// GetLatestRepoHousekeepingTask returns the latest housekeeping task of a repository, or nil if there is none
+func GetLatestRepoHousekeepingTask(repoID int64) (*Task, error) {
+	var task Task
+	has, err := db.GetEngine(db.DefaultContext).
+		Where("repo_id = ? AND type = ?", repoID, structs.TaskTypeRepoHousekeeping).
+		Desc("id").
+		Get(&task)
+	if err != nil || !has {
+		return nil, err
+	}
+	return &task, nil
+}
+
 // ErrTaskDoesNotExist represents a "TaskDoesNotExist" kind of error.
 type ErrTaskDoesNotExist struct {
 	ID     int64
@@ -8,11 +8,13 @@ package models
 import (
 	"crypto/subtle"
 	"fmt"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/hostmatcher"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
@@ -32,6 +34,8 @@ type AccessToken struct {
 	TokenHash      string `xorm:"UNIQUE"` // sha256 of token
 	TokenSalt      string
 	TokenLastEight string `xorm:"token_last_eight"`
+	// AllowedCIDRs is a comma separated list of the networks the token can be used from, it can be used from everywhere if it's empty
+	AllowedCIDRs string `xorm:"TEXT"`
 
 	CreatedUnix       timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix       timeutil.TimeStamp `xorm:"INDEX updated"`
@@ -45,6 +49,14 @@ func (t *AccessToken) AfterLoad() {
 	t.HasRecentActivity = t.UpdatedUnix.AddDuration(7*24*time.Hour) > timeutil.TimeStampNow()
 }
 
+// IsAllowedFrom returns true if the token can be used from the remote address
+func (t *AccessToken) IsAllowedFrom(remoteAddr string) bool {
+	if strings.TrimSpace(t.AllowedCIDRs) == "" {
+		return true
+	}
+	return hostmatcher.ParseHostMatchList("AllowedCIDRs", t.AllowedCIDRs).MatchHostName(remoteAddr)
+}
+
 func init() {
 	db.RegisterModel(new(AccessToken), func() error {
 		if setting.SuccessfulTokensCacheSize > 0 {
@@ -129,3 +129,15 @@ func TestDeleteAccessTokenByID(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, IsErrAccessTokenNotExist(err))
 }
+
+func TestAccessToken_IsAllowedFrom(t *testing.T) {
+	token := &AccessToken{}
+	assert.True(t, token.IsAllowedFrom("203.0.113.7:1234"))
+
+	token.AllowedCIDRs = "192.168.0.0/16, 2001:db8::/32, 203.0.113.7"
+	assert.True(t, token.IsAllowedFrom("192.168.1.10:1234"))
+	assert.True(t, token.IsAllowedFrom("[2001:db8::1]:1234"))
+	assert.True(t, token.IsAllowedFrom("203.0.113.7"))
+	assert.False(t, token.IsAllowedFrom("10.0.0.1:1234"))
+	assert.False(t, token.IsAllowedFrom(""))
+}
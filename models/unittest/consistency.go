@@ -109,6 +109,14 @@ func init() {
 		assert.EqualValues(t, repo.int("NumClosedPulls"), actual,
 			"Unexpected number of closed pulls for repo id: %d", repo.int("ID"))
 
+		actual = GetCountByCond(t, "release", builder.Eq{"is_draft": false, "is_tag": false, "repo_id": repo.int("ID")})
+		assert.EqualValues(t, repo.int("NumReleases"), actual,
+			"Unexpected number of releases for repo id: %d", repo.int("ID"))
+
+		actual = GetCountByCond(t, "release", builder.Eq{"is_draft": false, "repo_id": repo.int("ID")})
+		assert.EqualValues(t, repo.int("NumTags"), actual,
+			"Unexpected number of tags for repo id: %d", repo.int("ID"))
+
 		actual = GetCountByCond(t, "milestone", builder.Eq{"is_closed": true, "repo_id": repo.int("ID")})
 		assert.EqualValues(t, repo.int("NumClosedMilestones"), actual,
 			"Unexpected number of closed milestones for repo id: %d", repo.int("ID"))
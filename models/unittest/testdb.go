@@ -95,6 +95,7 @@ func MainTest(m *testing.M, testOpts *TestOptions) {
 		fatalTestError("url.Parse: %v\n", err)
 	}
 	setting.Attachment.Storage.Path = filepath.Join(setting.AppDataPath, "attachments")
+	setting.Attachment.ColdStorage.Path = filepath.Join(setting.AppDataPath, "attachments_cold")
 
 	setting.LFS.Storage.Path = filepath.Join(setting.AppDataPath, "lfs")
 
@@ -106,6 +107,10 @@ func MainTest(m *testing.M, testOpts *TestOptions) {
 
 	setting.Packages.Storage.Path = filepath.Join(setting.AppDataPath, "packages")
 
+	setting.Actions.Storage.Path = filepath.Join(setting.AppDataPath, "actions_artifacts")
+
+	setting.CustomEmoji.Storage.Path = filepath.Join(setting.AppDataPath, "custom_emojis")
+
 	if err = storage.Init(); err != nil {
 		fatalTestError("storage.Init: %v\n", err)
 	}
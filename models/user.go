@@ -12,6 +12,7 @@ import (
 
 	_ "image/jpeg" // Needed for jpeg support
 
+	admin_model "code.gitea.io/gitea/models/admin"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	auth_model "code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
@@ -71,6 +72,7 @@ func DeleteUser(ctx context.Context, u *user_model.User) (err error) {
 	if err = db.DeleteBeans(ctx,
 		&AccessToken{UID: u.ID},
 		&repo_model.Collaboration{UserID: u.ID},
+		&repo_model.AccessRequest{UserID: u.ID},
 		&access_model.Access{UserID: u.ID},
 		&repo_model.Watch{UserID: u.ID},
 		&repo_model.Star{UID: u.ID},
@@ -81,11 +83,17 @@ func DeleteUser(ctx context.Context, u *user_model.User) (err error) {
 		&user_model.EmailAddress{UID: u.ID},
 		&user_model.UserOpenID{UID: u.ID},
 		&issues.Reaction{UserID: u.ID},
+		&issues.IssueFilter{UserID: u.ID},
 		&organization.TeamUser{UID: u.ID},
 		&Stopwatch{UserID: u.ID},
 		&user_model.Setting{UserID: u.ID},
+		&user_model.SCIMIdentity{UserID: u.ID},
+		&user_model.SecurityEvent{UserID: u.ID},
+		&user_model.BackupEmail{UID: u.ID},
+		&auth_model.RecoveryCode{UID: u.ID},
 		&pull_model.AutoMerge{DoerID: u.ID},
 		&pull_model.ReviewState{UserID: u.ID},
+		&admin_model.AnnouncementDismissal{UserID: u.ID},
 	); err != nil {
 		return fmt.Errorf("deleteBeans: %v", err)
 	}
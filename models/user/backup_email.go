@@ -0,0 +1,139 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// BackupEmail is an additional e-mail address of a user which is only used to recover the account.
+// Unlike the addresses in EmailAddress it can't be used to sign in, to receive notifications or to match commits.
+type BackupEmail struct {
+	ID          int64              `xorm:"pk autoincr"`
+	UID         int64              `xorm:"UNIQUE NOT NULL"`
+	Email       string             `xorm:"NOT NULL"`
+	LowerEmail  string             `xorm:"INDEX NOT NULL"`
+	IsActivated bool               `xorm:"NOT NULL DEFAULT false"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+func init() {
+	db.RegisterModel(new(BackupEmail))
+}
+
+// GetBackupEmail returns the backup e-mail address of the user, nil if the user has none
+func GetBackupEmail(ctx context.Context, uid int64) (*BackupEmail, error) {
+	email := new(BackupEmail)
+	has, err := db.GetEngine(ctx).Where("uid = ?", uid).Get(email)
+	if err != nil || !has {
+		return nil, err
+	}
+	return email, nil
+}
+
+// SetBackupEmail replaces the backup e-mail address of the user by a new one which has to be activated.
+// The address must not be in use as the e-mail address or backup e-mail address of any user.
+func SetBackupEmail(ctx context.Context, uid int64, email string) (*BackupEmail, error) {
+	email = strings.TrimSpace(email)
+	if err := ValidateEmail(email); err != nil {
+		return nil, err
+	}
+	if email == "" {
+		return nil, ErrEmailInvalid{email}
+	}
+
+	used, err := IsEmailUsed(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		used, err = db.GetEngine(ctx).Where("lower_email = ? AND uid <> ?", strings.ToLower(email), uid).Exist(new(BackupEmail))
+		if err != nil {
+			return nil, err
+		}
+	}
+	if used {
+		return nil, ErrEmailAlreadyUsed{email}
+	}
+
+	backup := &BackupEmail{UID: uid, Email: email, LowerEmail: strings.ToLower(email)}
+	if err := db.WithTx(func(ctx context.Context) error {
+		if err := DeleteBackupEmail(ctx, uid); err != nil {
+			return err
+		}
+		return db.Insert(ctx, backup)
+	}, ctx); err != nil {
+		return nil, err
+	}
+	return backup, nil
+}
+
+// DeleteBackupEmail removes the backup e-mail address of the user
+func DeleteBackupEmail(ctx context.Context, uid int64) error {
+	_, err := db.GetEngine(ctx).Where("uid = ?", uid).Delete(new(BackupEmail))
+	return err
+}
+
+// ActivateBackupEmail marks the backup e-mail address as verified
+func ActivateBackupEmail(ctx context.Context, email *BackupEmail) error {
+	email.IsActivated = true
+	_, err := db.GetEngine(ctx).ID(email.ID).Cols("is_activated").Update(email)
+	return err
+}
+
+// GetUserByBackupEmail returns the user whose activated backup e-mail address is the given one
+func GetUserByBackupEmail(ctx context.Context, email string) (*User, error) {
+	if len(email) == 0 {
+		return nil, ErrUserNotExist{0, email, 0}
+	}
+
+	backup := new(BackupEmail)
+	has, err := db.GetEngine(ctx).Where("lower_email = ? AND is_activated = ?", strings.ToLower(email), true).Get(backup)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrUserNotExist{0, email, 0}
+	}
+	return GetUserByIDCtx(ctx, backup.UID)
+}
+
+// backupEmailCodeData prefixes the address so an activation code of a backup e-mail address
+// can't activate an ordinary e-mail address and vice versa
+func backupEmailCodeData(email string) string {
+	return "backup:" + email
+}
+
+// GenerateBackupEmailActivateCode generates an activation code for the backup e-mail address of the user
+func (u *User) GenerateBackupEmailActivateCode(email string) string {
+	return u.GenerateEmailActivateCode(backupEmailCodeData(email))
+}
+
+// VerifyBackupEmailCode returns the backup e-mail address the code has been generated for, nil if the code is invalid
+func VerifyBackupEmailCode(ctx context.Context, code, email string) *BackupEmail {
+	user := GetVerifyUser(code)
+	if user == nil {
+		return nil
+	}
+
+	prefix := code[:base.TimeLimitCodeLength]
+	data := fmt.Sprintf("%d%s%s%s%s", user.ID, backupEmailCodeData(email), user.LowerName, user.Passwd, user.Rands)
+	if !base.VerifyTimeLimitCode(data, setting.Service.ActiveCodeLives, prefix) {
+		return nil
+	}
+
+	backup, err := GetBackupEmail(ctx, user.ID)
+	if err != nil || backup == nil || backup.Email != email {
+		return nil
+	}
+	return backup
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBackupEmail(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+	defer func(lives int) {
+		setting.Service.ActiveCodeLives = lives
+	}(setting.Service.ActiveCodeLives)
+	setting.Service.ActiveCodeLives = 180
+	u := unittest.AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+
+	// addresses of users can't be used
+	_, err := SetBackupEmail(ctx, u.ID, "user3@example.com")
+	assert.True(t, IsErrEmailAlreadyUsed(err))
+	_, err = SetBackupEmail(ctx, u.ID, "not an address")
+	assert.Error(t, err)
+
+	email, err := SetBackupEmail(ctx, u.ID, "Backup@example.com")
+	assert.NoError(t, err)
+	assert.False(t, email.IsActivated)
+
+	// another user can't use the same backup address
+	_, err = SetBackupEmail(ctx, 4, "backup@example.com")
+	assert.True(t, IsErrEmailAlreadyUsed(err))
+
+	// only an activated address can be used to recover the account
+	_, err = GetUserByBackupEmail(ctx, "backup@example.com")
+	assert.True(t, IsErrUserNotExist(err))
+
+	// an activation code of an ordinary address doesn't activate the backup address
+	assert.Nil(t, VerifyBackupEmailCode(ctx, u.GenerateEmailActivateCode(email.Email), email.Email))
+	verified := VerifyBackupEmailCode(ctx, u.GenerateBackupEmailActivateCode(email.Email), email.Email)
+	if assert.NotNil(t, verified) {
+		assert.NoError(t, ActivateBackupEmail(ctx, verified))
+	}
+
+	recovered, err := GetUserByBackupEmail(ctx, "backup@example.com")
+	assert.NoError(t, err)
+	assert.EqualValues(t, u.ID, recovered.ID)
+
+	// a new address replaces the old one and has to be activated again
+	email, err = SetBackupEmail(ctx, u.ID, "other@example.com")
+	assert.NoError(t, err)
+	assert.False(t, email.IsActivated)
+	unittest.AssertCount(t, &BackupEmail{UID: u.ID}, 1)
+
+	assert.NoError(t, DeleteBackupEmail(ctx, u.ID))
+	email, err = GetBackupEmail(ctx, u.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, email)
+}
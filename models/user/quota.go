@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// Quota overrides the default quota of the disk space used by the repositories, LFS objects,
+// attachments and packages of an owner. A MaxSize of 0 means unlimited.
+type Quota struct {
+	ID          int64              `xorm:"pk autoincr"`
+	OwnerID     int64              `xorm:"UNIQUE NOT NULL"`
+	MaxSize     int64              `xorm:"NOT NULL DEFAULT 0"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created NOT NULL"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated NOT NULL"`
+
+	Owner *User `xorm:"-"`
+}
+
+func init() {
+	db.RegisterModel(new(Quota))
+}
+
+// GetQuotaByOwnerID returns the quota of the owner, nil if the default quota applies to the owner
+func GetQuotaByOwnerID(ctx context.Context, ownerID int64) (*Quota, error) {
+	q := new(Quota)
+	has, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Get(q)
+	if err != nil || !has {
+		return nil, err
+	}
+	return q, nil
+}
+
+// SetQuota inserts or updates the quota of the owner
+func SetQuota(ctx context.Context, ownerID, maxSize int64) error {
+	q, err := GetQuotaByOwnerID(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if q == nil {
+		return db.Insert(ctx, &Quota{OwnerID: ownerID, MaxSize: maxSize})
+	}
+	q.MaxSize = maxSize
+	_, err = db.GetEngine(ctx).ID(q.ID).Cols("max_size").Update(q)
+	return err
+}
+
+// DeleteQuotaByOwnerID deletes the quota of the owner, the default quota applies to the owner afterwards
+func DeleteQuotaByOwnerID(ctx context.Context, ownerID int64) error {
+	_, err := db.GetEngine(ctx).Where("owner_id = ?", ownerID).Delete(new(Quota))
+	return err
+}
+
+// FindQuotas returns the quotas which override the default quota with their owners
+func FindQuotas(ctx context.Context, opts db.ListOptions) ([]*Quota, int64, error) {
+	sess := db.GetEngine(ctx).Asc("id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, &opts)
+	}
+	qs := make([]*Quota, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&qs)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ownerIDs := make([]int64, 0, len(qs))
+	for _, q := range qs {
+		ownerIDs = append(ownerIDs, q.OwnerID)
+	}
+	owners := make(map[int64]*User, len(ownerIDs))
+	if err := db.GetEngine(ctx).In("id", ownerIDs).Find(&owners); err != nil {
+		return nil, 0, err
+	}
+	for _, q := range qs {
+		q.Owner = owners[q.OwnerID]
+	}
+	return qs, count, nil
+}
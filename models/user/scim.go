@@ -0,0 +1,115 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"xorm.io/builder"
+)
+
+// SCIMIdentity links a user to the identity of the identity provider which provisions it through SCIM
+type SCIMIdentity struct {
+	ID     int64 `xorm:"pk autoincr"`
+	UserID int64 `xorm:"UNIQUE NOT NULL"`
+	// UserName is the userName of the identity, the username of the user is only derived from it on creation
+	UserName    string             `xorm:"INDEX"`
+	ExternalID  string             `xorm:"INDEX"`
+	CreatedUnix timeutil.TimeStamp `xorm:"created"`
+	UpdatedUnix timeutil.TimeStamp `xorm:"updated"`
+}
+
+// TableName sets the table name of the SCIM identities
+func (identity *SCIMIdentity) TableName() string {
+	return "scim_identity"
+}
+
+func init() {
+	db.RegisterModel(new(SCIMIdentity))
+}
+
+// GetSCIMIdentities returns the SCIM identities of the users by their ids, users without one are left out
+func GetSCIMIdentities(ctx context.Context, userIDs []int64) (map[int64]*SCIMIdentity, error) {
+	identities := make(map[int64]*SCIMIdentity, len(userIDs))
+	if len(userIDs) == 0 {
+		return identities, nil
+	}
+	list := make([]*SCIMIdentity, 0, len(userIDs))
+	if err := db.GetEngine(ctx).In("user_id", userIDs).Find(&list); err != nil {
+		return nil, err
+	}
+	for _, identity := range list {
+		identities[identity.UserID] = identity
+	}
+	return identities, nil
+}
+
+// SetSCIMIdentity creates or updates the SCIM identity of the user
+func SetSCIMIdentity(ctx context.Context, identity *SCIMIdentity) error {
+	existing := &SCIMIdentity{}
+	has, err := db.GetEngine(ctx).Where("user_id = ?", identity.UserID).Get(existing)
+	if err != nil {
+		return err
+	}
+	if !has {
+		return db.Insert(ctx, identity)
+	}
+	identity.ID = existing.ID
+	_, err = db.GetEngine(ctx).ID(identity.ID).Cols("user_name", "external_id").Update(identity)
+	return err
+}
+
+// SearchSCIMUsersOptions are the options to search the users provisioned through SCIM
+type SearchSCIMUsersOptions struct {
+	// Offset and Limit page the users, which are ordered by their ids
+	Offset int
+	Limit  int
+	// UserName matches the userName of the identities, or the username of users without an identity by Name
+	UserName string
+	Name     string
+	// ExternalID matches the externalId of the identities
+	ExternalID string
+}
+
+func (opts *SearchSCIMUsersOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"`user`.type": UserTypeIndividual})
+	if opts.UserName != "" {
+		cond = cond.And(builder.Or(
+			builder.Eq{"scim_identity.user_name": opts.UserName},
+			builder.IsNull{"scim_identity.id"}.And(builder.Eq{"`user`.lower_name": strings.ToLower(opts.Name)}),
+		))
+	}
+	if opts.ExternalID != "" {
+		cond = cond.And(builder.Eq{"scim_identity.external_id": opts.ExternalID})
+	}
+	return cond
+}
+
+// SearchSCIMUsers returns the individual users matching the options and the total count of them
+func SearchSCIMUsers(ctx context.Context, opts *SearchSCIMUsersOptions) ([]*User, int64, error) {
+	count, err := db.GetEngine(ctx).
+		Join("LEFT OUTER", "scim_identity", "scim_identity.user_id = `user`.id").
+		Where(opts.toConds()).
+		Count(new(User))
+	if err != nil {
+		return nil, 0, err
+	}
+
+	users := make([]*User, 0, opts.Limit)
+	if opts.Limit <= 0 {
+		return users, count, nil
+	}
+	return users, count, db.GetEngine(ctx).
+		Join("LEFT OUTER", "scim_identity", "scim_identity.user_id = `user`.id").
+		Where(opts.toConds()).
+		Select("`user`.*").
+		OrderBy("`user`.id").
+		Limit(opts.Limit, opts.Offset).
+		Find(&users)
+}
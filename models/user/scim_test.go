@@ -0,0 +1,56 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSCIMIdentity(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.NoError(t, SetSCIMIdentity(db.DefaultContext, &SCIMIdentity{UserID: 4, UserName: "user4@example.com", ExternalID: "ext-4"}))
+	assert.NoError(t, SetSCIMIdentity(db.DefaultContext, &SCIMIdentity{UserID: 4, UserName: "user4@example.com", ExternalID: "ext-4b"}))
+	unittest.AssertCount(t, &SCIMIdentity{UserID: 4}, 1)
+
+	identities, err := GetSCIMIdentities(db.DefaultContext, []int64{2, 4})
+	assert.NoError(t, err)
+	assert.Len(t, identities, 1)
+	assert.Equal(t, "ext-4b", identities[4].ExternalID)
+
+	// the userName matches the identity or the username of users which don't have one
+	users, count, err := SearchSCIMUsers(db.DefaultContext, &SearchSCIMUsersOptions{UserName: "user4@example.com", Limit: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, 4, users[0].ID)
+	}
+	users, _, err = SearchSCIMUsers(db.DefaultContext, &SearchSCIMUsersOptions{UserName: "user2@example.com", Name: "user2", Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, users, 1) {
+		assert.EqualValues(t, 2, users[0].ID)
+	}
+	_, count, err = SearchSCIMUsers(db.DefaultContext, &SearchSCIMUsersOptions{UserName: "user4", Name: "user4", Limit: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+
+	users, count, err = SearchSCIMUsers(db.DefaultContext, &SearchSCIMUsersOptions{ExternalID: "ext-4b"})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, count)
+	assert.Empty(t, users)
+
+	// all the individual users are paged by their ids
+	users, count, err = SearchSCIMUsers(db.DefaultContext, &SearchSCIMUsersOptions{Offset: 1, Limit: 2})
+	assert.NoError(t, err)
+	assert.EqualValues(t, unittest.GetCount(t, &User{}, unittest.Cond("type = ?", UserTypeIndividual)), count)
+	if assert.Len(t, users, 2) {
+		assert.Less(t, users[0].ID, users[1].ID)
+	}
+}
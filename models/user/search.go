@@ -34,6 +34,8 @@ type SearchUserOptions struct {
 	IsRestricted       util.OptionalBool
 	IsTwoFactorEnabled util.OptionalBool
 	IsProhibitLogin    util.OptionalBool
+	IsSuspended        util.OptionalBool
+	NoIndex            util.OptionalBool
 
 	ExtraParamStrings map[string]string
 }
@@ -76,12 +78,15 @@ func (opts *SearchUserOptions) toSearchQueryBase() *xorm.Session {
 			// Don't forget about self
 			accessCond = accessCond.Or(builder.Eq{"id": opts.Actor.ID})
 			cond = cond.And(accessCond)
+			// suspended users are hidden from everyone but themselves
+			cond = cond.And(builder.Or(builder.Eq{"is_suspended": false}, builder.Eq{"id": opts.Actor.ID}))
 		}
 
 	} else {
 		// Force visibility for privacy
 		// Not logged in - only public users
 		cond = cond.And(builder.In("visibility", structs.VisibleTypePublic))
+		cond = cond.And(builder.Eq{"is_suspended": false})
 	}
 
 	if opts.UID > 0 {
@@ -104,6 +109,14 @@ func (opts *SearchUserOptions) toSearchQueryBase() *xorm.Session {
 		cond = cond.And(builder.Eq{"prohibit_login": opts.IsProhibitLogin.IsTrue()})
 	}
 
+	if !opts.IsSuspended.IsNone() {
+		cond = cond.And(builder.Eq{"is_suspended": opts.IsSuspended.IsTrue()})
+	}
+
+	if !opts.NoIndex.IsNone() {
+		cond = cond.And(builder.Eq{"no_index": opts.NoIndex.IsTrue()})
+	}
+
 	e := db.GetEngine(db.DefaultContext)
 	if opts.IsTwoFactorEnabled.IsNone() {
 		return e.Where(cond)
@@ -0,0 +1,190 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// SecurityEventType is the type of an event of the security log of a user
+type SecurityEventType string
+
+// The events recorded in the security log of a user
+const (
+	SecurityEventNewDeviceSignIn SecurityEventType = "new_device_signin"
+	SecurityEventNewIPSignIn     SecurityEventType = "new_ip_signin"
+	SecurityEventPasswordChange  SecurityEventType = "password_change"
+	SecurityEventTokenCreate     SecurityEventType = "token_create"
+	SecurityEventSSHKeyAdd       SecurityEventType = "ssh_key_add"
+	SecurityEventRecoveryCodeUse SecurityEventType = "recovery_code_use"
+	SecurityEventBackupEmailSet  SecurityEventType = "backup_email_set"
+)
+
+// ErrSecurityEventNotExist represents a "SecurityEventNotExist" kind of error.
+type ErrSecurityEventNotExist struct {
+	ID int64
+}
+
+// IsErrSecurityEventNotExist checks if an error is a ErrSecurityEventNotExist.
+func IsErrSecurityEventNotExist(err error) bool {
+	_, ok := err.(ErrSecurityEventNotExist)
+	return ok
+}
+
+func (err ErrSecurityEventNotExist) Error() string {
+	return fmt.Sprintf("security event does not exist [id: %d]", err.ID)
+}
+
+// SecurityEvent is an event of the security log of a user
+type SecurityEvent struct {
+	ID     int64             `xorm:"pk autoincr"`
+	UserID int64             `xorm:"INDEX NOT NULL"`
+	Type   SecurityEventType `xorm:"VARCHAR(30) NOT NULL"`
+	// DeviceFingerprint identifies the device the event happened from, see DeviceFingerprint
+	DeviceFingerprint string `xorm:"VARCHAR(64) INDEX"`
+	IP                string `xorm:"VARCHAR(50)"`
+	UserAgent         string `xorm:"TEXT"`
+	// Detail is the name of the created token or key
+	Detail string `xorm:"TEXT"`
+
+	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
+}
+
+func init() {
+	db.RegisterModel(new(SecurityEvent))
+}
+
+var versionPattern = regexp.MustCompile(`\d+([._]\d+)*`)
+
+// DeviceFingerprint returns the fingerprint of the device a request with the user agent comes from.
+// The version numbers are removed from the user agent, so updating the browser doesn't make a new device.
+func DeviceFingerprint(userAgent string) string {
+	normalized := strings.Join(strings.Fields(versionPattern.ReplaceAllString(strings.ToLower(userAgent), "")), " ")
+	hash := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(hash[:])
+}
+
+// InsertSecurityEvent inserts an event into the security log of its user
+func InsertSecurityEvent(ctx context.Context, event *SecurityEvent) error {
+	return db.Insert(ctx, event)
+}
+
+// IsKnownDevice returns true if the user has signed in from the device with the fingerprint before
+func IsKnownDevice(ctx context.Context, userID int64, fingerprint string) (bool, error) {
+	return db.GetEngine(ctx).Exist(&SecurityEvent{
+		UserID:            userID,
+		Type:              SecurityEventNewDeviceSignIn,
+		DeviceFingerprint: fingerprint,
+	})
+}
+
+// IsKnownSignInNetwork returns true if the user has signed in from the network of the IP address before.
+// Addresses are compared by their anonymized form, so a new address in the same network or an address
+// which has been anonymized since isn't reported as new.
+func IsKnownSignInNetwork(ctx context.Context, userID int64, ip string) (bool, error) {
+	network := util.AnonymizeIP(ip)
+	if network == "" {
+		return false, nil
+	}
+
+	ips := make([]string, 0, 10)
+	if err := db.GetEngine(ctx).Table("security_event").
+		Where("user_id = ? AND ip <> ''", userID).
+		In("type", SecurityEventNewDeviceSignIn, SecurityEventNewIPSignIn).
+		Distinct("ip").
+		Find(&ips); err != nil {
+		return false, err
+	}
+	for _, known := range ips {
+		if util.AnonymizeIP(known) == network {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// HasKnownDevices returns true if any sign-in of the user has been recorded
+func HasKnownDevices(ctx context.Context, userID int64) (bool, error) {
+	return db.GetEngine(ctx).Exist(&SecurityEvent{
+		UserID: userID,
+		Type:   SecurityEventNewDeviceSignIn,
+	})
+}
+
+// GetSecurityEventByID returns an event of a security log by id
+func GetSecurityEventByID(ctx context.Context, id int64) (*SecurityEvent, error) {
+	event := new(SecurityEvent)
+	has, err := db.GetEngine(ctx).ID(id).Get(event)
+	if err != nil {
+		return nil, err
+	} else if !has {
+		return nil, ErrSecurityEventNotExist{ID: id}
+	}
+	return event, nil
+}
+
+// GetSecurityEventsByIDs returns the events of security logs with the given ids
+func GetSecurityEventsByIDs(ctx context.Context, ids []int64) (map[int64]*SecurityEvent, error) {
+	events := make(map[int64]*SecurityEvent, len(ids))
+	return events, db.GetEngine(ctx).In("id", ids).Find(&events)
+}
+
+// FindSecurityEvents returns the events of the security log of the user, newest first
+func FindSecurityEvents(ctx context.Context, userID int64, listOptions db.ListOptions) ([]*SecurityEvent, int64, error) {
+	sess := db.GetEngine(ctx).
+		Where("user_id = ?", userID).
+		OrderBy("created_unix DESC, id DESC")
+	if listOptions.Page > 0 {
+		sess = db.SetSessionPagination(sess, &listOptions)
+	}
+	events := make([]*SecurityEvent, 0, listOptions.PageSize)
+	count, err := sess.FindAndCount(&events)
+	return events, count, err
+}
+
+// AnonymizeOldSecurityEventIPs replaces the IP addresses of the events of all security logs
+// which are older than the given duration with the result of anonymize
+func AnonymizeOldSecurityEventIPs(ctx context.Context, olderThan time.Duration, anonymize func(ip string) string) error {
+	if olderThan <= 0 {
+		return nil
+	}
+
+	cutoff := time.Now().Add(-olderThan).Unix()
+	events := make([]*SecurityEvent, 0, 50)
+	for lastID := int64(0); ; {
+		events = events[:0]
+		if err := db.GetEngine(ctx).
+			Where("created_unix < ? AND id > ? AND ip <> ''", cutoff, lastID).
+			Asc("id").
+			Limit(50).
+			Cols("id", "ip").
+			Find(&events); err != nil {
+			return err
+		}
+		if len(events) == 0 {
+			return nil
+		}
+		for _, event := range events {
+			lastID = event.ID
+			ip := anonymize(event.IP)
+			if ip == event.IP {
+				continue
+			}
+			if _, err := db.GetEngine(ctx).ID(event.ID).Cols("ip").Update(&SecurityEvent{IP: ip}); err != nil {
+				return err
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDeviceFingerprint(t *testing.T) {
+	firefox := "Mozilla/5.0 (X11; Linux x86_64; rv:101.0) Gecko/20100101 Firefox/101.0"
+	firefoxUpdated := "Mozilla/5.0 (X11; Linux x86_64; rv:102.0) Gecko/20100101 Firefox/102.0.1"
+	chrome := "Mozilla/5.0 (X11; Linux x86_64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/102.0.0.0 Safari/537.36"
+
+	assert.Len(t, DeviceFingerprint(firefox), 64)
+	assert.Equal(t, DeviceFingerprint(firefox), DeviceFingerprint(firefoxUpdated))
+	assert.NotEqual(t, DeviceFingerprint(firefox), DeviceFingerprint(chrome))
+}
+
+func TestSecurityEvents(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	has, err := HasKnownDevices(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	assert.False(t, has)
+
+	fingerprint := DeviceFingerprint("curl/7.83.1")
+	assert.NoError(t, InsertSecurityEvent(db.DefaultContext, &SecurityEvent{UserID: 2, Type: SecurityEventNewDeviceSignIn, DeviceFingerprint: fingerprint}))
+	assert.NoError(t, InsertSecurityEvent(db.DefaultContext, &SecurityEvent{UserID: 2, Type: SecurityEventTokenCreate, DeviceFingerprint: DeviceFingerprint("other"), Detail: "token"}))
+
+	has, err = HasKnownDevices(db.DefaultContext, 2)
+	assert.NoError(t, err)
+	assert.True(t, has)
+	known, err := IsKnownDevice(db.DefaultContext, 2, fingerprint)
+	assert.NoError(t, err)
+	assert.True(t, known)
+	// only sign-ins make a device known
+	known, err = IsKnownDevice(db.DefaultContext, 2, DeviceFingerprint("other"))
+	assert.NoError(t, err)
+	assert.False(t, known)
+	known, err = IsKnownDevice(db.DefaultContext, 4, fingerprint)
+	assert.NoError(t, err)
+	assert.False(t, known)
+
+	events, count, err := FindSecurityEvents(db.DefaultContext, 2, db.ListOptions{Page: 1, PageSize: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, events, 1) {
+		assert.Equal(t, SecurityEventTokenCreate, events[0].Type)
+	}
+}
+
+func TestAnonymizeOldSecurityEventIPs(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	old := &SecurityEvent{UserID: 2, Type: SecurityEventPasswordChange, IP: "2001:db8::1"}
+	assert.NoError(t, InsertSecurityEvent(db.DefaultContext, old))
+	_, err := db.Exec(db.DefaultContext, "UPDATE security_event SET created_unix = ? WHERE id = ?", time.Now().Add(-48*time.Hour).Unix(), old.ID)
+	assert.NoError(t, err)
+	recent := &SecurityEvent{UserID: 2, Type: SecurityEventPasswordChange, IP: "2001:db8::1"}
+	assert.NoError(t, InsertSecurityEvent(db.DefaultContext, recent))
+
+	assert.NoError(t, AnonymizeOldSecurityEventIPs(db.DefaultContext, 24*time.Hour, func(string) string { return "" }))
+	assert.Empty(t, unittest.AssertExistsAndLoadBean(t, &SecurityEvent{ID: old.ID}).(*SecurityEvent).IP)
+	assert.Equal(t, "2001:db8::1", unittest.AssertExistsAndLoadBean(t, &SecurityEvent{ID: recent.ID}).(*SecurityEvent).IP)
+}
+
+func TestIsKnownSignInNetwork(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	assert.NoError(t, InsertSecurityEvent(db.DefaultContext, &SecurityEvent{UserID: 5, Type: SecurityEventNewDeviceSignIn, IP: "192.0.2.1:1234"}))
+	assert.NoError(t, InsertSecurityEvent(db.DefaultContext, &SecurityEvent{UserID: 5, Type: SecurityEventPasswordChange, IP: "198.51.100.1"}))
+
+	known, err := IsKnownSignInNetwork(db.DefaultContext, 5, "192.0.2.99:4321")
+	assert.NoError(t, err)
+	assert.True(t, known)
+	// only sign-ins make a network known
+	known, err = IsKnownSignInNetwork(db.DefaultContext, 5, "198.51.100.1")
+	assert.NoError(t, err)
+	assert.False(t, known)
+	known, err = IsKnownSignInNetwork(db.DefaultContext, 4, "192.0.2.1")
+	assert.NoError(t, err)
+	assert.False(t, known)
+}
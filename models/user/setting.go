@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/cache"
 
 	"xorm.io/builder"
 )
@@ -72,23 +73,36 @@ func validateUserSettingKey(key string) error {
 	return nil
 }
 
+// errUserSettingNotExist is returned by the cache callback of GetUserSetting so that missing settings are not cached
+var errUserSettingNotExist = fmt.Errorf("user setting does not exist")
+
+func genSettingCacheKey(userID int64, key string) string {
+	return fmt.Sprintf("user_%d.setting.%s", userID, key)
+}
+
 // GetUserSetting gets a specific setting for a user
 func GetUserSetting(userID int64, key string, def ...string) (string, error) {
 	if err := validateUserSettingKey(key); err != nil {
 		return "", err
 	}
-	setting := &Setting{UserID: userID, SettingKey: key}
-	has, err := db.GetEngine(db.DefaultContext).Get(setting)
-	if err != nil {
-		return "", err
-	}
-	if !has {
+	value, err := cache.GetString(genSettingCacheKey(userID, key), func() (string, error) {
+		setting := &Setting{UserID: userID, SettingKey: key}
+		has, err := db.GetEngine(db.DefaultContext).Get(setting)
+		if err != nil {
+			return "", err
+		}
+		if !has {
+			return "", errUserSettingNotExist
+		}
+		return setting.SettingValue, nil
+	})
+	if err == errUserSettingNotExist {
 		if len(def) == 1 {
 			return def[0], nil
 		}
 		return "", nil
 	}
-	return setting.SettingValue, nil
+	return value, err
 }
 
 // DeleteUserSetting deletes a specific setting for a user
@@ -96,8 +110,24 @@ func DeleteUserSetting(userID int64, key string) error {
 	if err := validateUserSettingKey(key); err != nil {
 		return err
 	}
-	_, err := db.GetEngine(db.DefaultContext).Delete(&Setting{UserID: userID, SettingKey: key})
-	return err
+	if _, err := db.GetEngine(db.DefaultContext).Delete(&Setting{UserID: userID, SettingKey: key}); err != nil {
+		return err
+	}
+	cache.Remove(genSettingCacheKey(userID, key))
+	return nil
+}
+
+// GetUserSettingKeys returns the keys of all settings of a user
+func GetUserSettingKeys(ctx context.Context, userID int64) ([]string, error) {
+	keys := make([]string, 0, 5)
+	return keys, db.GetEngine(ctx).Table("user_setting").Where("user_id=?", userID).Cols("setting_key").Find(&keys)
+}
+
+// RemoveUserSettingsCache removes the cached values of the settings of a user, e.g. after the user has been deleted
+func RemoveUserSettingsCache(userID int64, keys []string) {
+	for _, key := range keys {
+		cache.Remove(genSettingCacheKey(userID, key))
+	}
 }
 
 // SetUserSetting updates a users' setting for a specific key
@@ -105,7 +135,11 @@ func SetUserSetting(userID int64, key, value string) error {
 	if err := validateUserSettingKey(key); err != nil {
 		return err
 	}
-	return upsertUserSettingValue(userID, key, value)
+	if err := upsertUserSettingValue(userID, key, value); err != nil {
+		return err
+	}
+	cache.Remove(genSettingCacheKey(userID, key))
+	return nil
 }
 
 func upsertUserSettingValue(userID int64, key, value string) error {
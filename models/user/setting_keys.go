@@ -9,4 +9,8 @@ const (
 	SettingsKeyHiddenCommentTypes = "issue.hidden_comment_types"
 	// SettingsKeyDiffWhitespaceBehavior is the setting key for whitespace behavior of diff
 	SettingsKeyDiffWhitespaceBehavior = "diff.whitespace_behaviour"
+	// SettingsKeySecurityNotifyDisabled is the setting key for opting out of the notifications of the security log
+	SettingsKeySecurityNotifyDisabled = "security.notify_disabled"
+	// SettingsKeySuspensionNotice is the setting key for the notice shown instead of the content of a suspended user
+	SettingsKeySuspensionNotice = "suspension.notice"
 )
@@ -7,6 +7,7 @@ package user
 import (
 	"testing"
 
+	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
 
 	"github.com/stretchr/testify/assert"
@@ -50,10 +51,17 @@ func TestSettings(t *testing.T) {
 	assert.Len(t, settings, 1)
 	assert.EqualValues(t, updatedSetting.SettingValue, settings[updatedSetting.SettingKey].SettingValue)
 
+	keys, err := GetUserSettingKeys(db.DefaultContext, 99)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{keyName}, keys)
+
 	// delete setting
 	err = DeleteUserSetting(99, keyName)
 	assert.NoError(t, err)
 	settings, err = GetUserAllSettings(99)
 	assert.NoError(t, err)
 	assert.Len(t, settings, 0)
+	settingValue, err = GetUserSetting(99, keyName)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "", settingValue)
 }
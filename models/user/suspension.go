@@ -0,0 +1,53 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// IsSuspendedFor returns true if the content of the user is hidden from the doer because the user is suspended.
+// The suspended user and site admins can still see it.
+func (u *User) IsSuspendedFor(doer *User) bool {
+	if !u.IsSuspended {
+		return false
+	}
+	return doer == nil || (!doer.IsAdmin && doer.ID != u.ID)
+}
+
+// SuspendUser suspends the user, the notice is shown to visitors instead of the content of the user.
+// If the notice is empty the default notice is shown.
+func SuspendUser(ctx context.Context, u *User, notice string) error {
+	u.IsSuspended = true
+	u.SuspendedUnix = timeutil.TimeStampNow()
+	if err := UpdateUserCols(ctx, u, "is_suspended", "suspended_unix"); err != nil {
+		return err
+	}
+
+	if notice = strings.TrimSpace(notice); notice == "" {
+		return DeleteUserSetting(u.ID, SettingsKeySuspensionNotice)
+	}
+	return SetUserSetting(u.ID, SettingsKeySuspensionNotice, notice)
+}
+
+// UnsuspendUser lifts the suspension of the user, the content of the user is shown again
+func UnsuspendUser(ctx context.Context, u *User) error {
+	u.IsSuspended = false
+	u.SuspendedUnix = 0
+	if err := UpdateUserCols(ctx, u, "is_suspended", "suspended_unix"); err != nil {
+		return err
+	}
+	return DeleteUserSetting(u.ID, SettingsKeySuspensionNotice)
+}
+
+// GetSuspensionNotice returns the notice shown instead of the content of the suspended user,
+// an empty string if neither the user nor the instance has a notice
+func GetSuspensionNotice(u *User) (string, error) {
+	return GetUserSetting(u.ID, SettingsKeySuspensionNotice, setting.Admin.SuspensionNotice)
+}
@@ -0,0 +1,64 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSuspendUser(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	ctx := db.DefaultContext
+	defer func(notice string) {
+		setting.Admin.SuspensionNotice = notice
+	}(setting.Admin.SuspensionNotice)
+	setting.Admin.SuspensionNotice = "default notice"
+
+	admin := unittest.AssertExistsAndLoadBean(t, &User{ID: 1}).(*User)
+	u := unittest.AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	other := unittest.AssertExistsAndLoadBean(t, &User{ID: 4}).(*User)
+	assert.False(t, u.IsSuspendedFor(nil))
+
+	assert.NoError(t, SuspendUser(ctx, u, "  custom notice  "))
+	u = unittest.AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.True(t, u.IsSuspended)
+	assert.NotZero(t, u.SuspendedUnix)
+	assert.True(t, u.IsSuspendedFor(nil))
+	assert.True(t, u.IsSuspendedFor(other))
+	assert.False(t, u.IsSuspendedFor(u))
+	assert.False(t, u.IsSuspendedFor(admin))
+
+	// suspended users are only found by themselves and site admins
+	for _, c := range []struct {
+		actor *User
+		count int
+	}{{nil, 0}, {other, 0}, {u, 1}, {admin, 1}} {
+		users, _, err := SearchUsers(&SearchUserOptions{UID: u.ID, Actor: c.actor})
+		assert.NoError(t, err)
+		assert.Len(t, users, c.count)
+	}
+
+	notice, err := GetSuspensionNotice(u)
+	assert.NoError(t, err)
+	assert.Equal(t, "custom notice", notice)
+
+	// without a notice of its own the default notice is shown
+	assert.NoError(t, SuspendUser(ctx, u, ""))
+	notice, err = GetSuspensionNotice(u)
+	assert.NoError(t, err)
+	assert.Equal(t, "default notice", notice)
+
+	assert.NoError(t, UnsuspendUser(ctx, u))
+	u = unittest.AssertExistsAndLoadBean(t, &User{ID: 2}).(*User)
+	assert.False(t, u.IsSuspended)
+	assert.Zero(t, u.SuspendedUnix)
+	assert.False(t, u.IsSuspendedFor(nil))
+}
@@ -84,6 +84,8 @@ type User struct {
 	EmailNotificationsPreference string `xorm:"VARCHAR(20) NOT NULL DEFAULT 'enabled'"`
 	Passwd                       string `xorm:"NOT NULL"`
 	PasswdHashAlgo               string `xorm:"NOT NULL DEFAULT 'argon2'"`
+	// PasswdChangedUnix is the time the password was set at, the password expires after the rotation interval
+	PasswdChangedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
 
 	// MustChangePassword is an attribute that determines if a user
 	// is to change his/her password after registration.
@@ -124,6 +126,10 @@ type User struct {
 
 	// true: the user is not allowed to log in Web UI. Git/SSH access could still be allowed (please refer to Git/SSH access related code/documents)
 	ProhibitLogin bool `xorm:"NOT NULL DEFAULT false"`
+	// true: the content of the user is hidden from everybody but the user and site admins, nothing can be pushed to it
+	// and the user can't change anything. Unlike ProhibitLogin the user can still sign in.
+	IsSuspended   bool               `xorm:"NOT NULL DEFAULT false"`
+	SuspendedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
 
 	// Avatar
 	Avatar          string `xorm:"VARCHAR(2048) NOT NULL"`
@@ -146,6 +152,8 @@ type User struct {
 	DiffViewStyle       string `xorm:"NOT NULL DEFAULT ''"`
 	Theme               string `xorm:"NOT NULL DEFAULT ''"`
 	KeepActivityPrivate bool   `xorm:"NOT NULL DEFAULT false"`
+	// NoIndex asks search engines not to index the profile and the repositories of the user or organization
+	NoIndex bool `xorm:"NOT NULL DEFAULT false"`
 }
 
 func init() {
@@ -395,7 +403,7 @@ func hashPassword(passwd, salt, algo string) (string, error) {
 }
 
 // SetPassword hashes a password using the algorithm defined in the config value of PASSWORD_HASH_ALGO
-// change passwd, salt and passwd_hash_algo fields
+// change passwd, salt, passwd_hash_algo and passwd_changed_unix fields
 func (u *User) SetPassword(passwd string) (err error) {
 	if len(passwd) == 0 {
 		u.Passwd = ""
@@ -411,10 +419,17 @@ func (u *User) SetPassword(passwd string) (err error) {
 		return err
 	}
 	u.PasswdHashAlgo = setting.PasswordHashAlgo
+	u.PasswdChangedUnix = timeutil.TimeStampNow()
 
 	return nil
 }
 
+// IsPasswordExpired returns true if the password of the user is older than the password rotation interval
+func (u *User) IsPasswordExpired() bool {
+	return setting.PasswordRotationInterval > 0 && u.IsLocal() && u.IsPasswordSet() &&
+		u.PasswdChangedUnix.AddDuration(setting.PasswordRotationInterval) <= timeutil.TimeStampNow()
+}
+
 // ValidatePassword checks if given password matches the one belongs to the user.
 func (u *User) ValidatePassword(passwd string) bool {
 	tempHash, err := hashPassword(passwd, u.Salt, u.PasswdHashAlgo)
@@ -1094,6 +1109,26 @@ func GetUsersBySource(s *auth.Source) ([]*User, error) {
 	return users, err
 }
 
+// GetUsersBySourceAndLowerNames returns the users of the login source with the lower names
+func GetUsersBySourceAndLowerNames(ctx context.Context, s *auth.Source, lowerNames []string) ([]*User, error) {
+	users := make([]*User, 0, len(lowerNames))
+	if len(lowerNames) == 0 {
+		return users, nil
+	}
+	return users, db.GetEngine(ctx).
+		Where("login_type = ? AND login_source = ?", s.Type, s.ID).
+		In("lower_name", lowerNames).
+		Find(&users)
+}
+
+// IterateActiveUsersBySource calls f with every active user of the login source
+func IterateActiveUsersBySource(ctx context.Context, s *auth.Source, f func(*User) error) error {
+	cond := builder.Eq{"login_type": s.Type, "login_source": s.ID, "is_active": true}
+	return db.Iterate(ctx, new(User), cond, func(_ int, bean interface{}) error {
+		return f(bean.(*User))
+	})
+}
+
 // UserCommit represents a commit with validation of user.
 type UserCommit struct { //revive:disable-line:exported
 	User *User
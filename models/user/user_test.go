@@ -8,12 +8,14 @@ import (
 	"math/rand"
 	"strings"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
@@ -367,3 +369,28 @@ func TestGetUserByOpenID(t *testing.T) {
 		assert.Equal(t, int64(2), user.ID)
 	}
 }
+
+func TestUser_IsPasswordExpired(t *testing.T) {
+	defer func(interval time.Duration) {
+		setting.PasswordRotationInterval = interval
+	}(setting.PasswordRotationInterval)
+
+	u := &User{}
+	assert.NoError(t, u.SetPassword("password"))
+	assert.False(t, u.IsPasswordExpired())
+
+	setting.PasswordRotationInterval = 24 * time.Hour
+	assert.False(t, u.IsPasswordExpired())
+
+	u.PasswdChangedUnix = timeutil.TimeStampNow().Add(-25 * 60 * 60)
+	assert.True(t, u.IsPasswordExpired())
+
+	// setting a new password restarts the rotation interval
+	assert.NoError(t, u.SetPassword("new password"))
+	assert.False(t, u.IsPasswordExpired())
+
+	// users without a password have nothing to rotate
+	u.Passwd = ""
+	u.PasswdChangedUnix = 0
+	assert.False(t, u.IsPasswordExpired())
+}
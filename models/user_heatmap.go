@@ -5,6 +5,8 @@
 package models
 
 import (
+	"time"
+
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	user_model "code.gitea.io/gitea/models/user"
@@ -70,3 +72,31 @@ func getUserHeatmapData(user *user_model.User, team *organization.Team, doer *us
 		OrderBy("timestamp").
 		Find(&hdata)
 }
+
+// UserContributionsOnDay represents the contributions of a user on one day
+type UserContributionsOnDay struct {
+	// Date is the day in the requested location formatted as YYYY-MM-DD
+	Date          string
+	Contributions int64
+}
+
+// GetUserContributionsByDay returns the contributions of the user during the last year on every day with contributions,
+// the days are those of the given location.
+func GetUserContributionsByDay(user, doer *user_model.User, loc *time.Location) ([]*UserContributionsOnDay, error) {
+	hdata, err := getUserHeatmapData(user, nil, doer)
+	if err != nil {
+		return nil, err
+	}
+
+	// the heatmap data is grouped by 15 minutes and ordered by time, so every interval belongs to a single day
+	// of every time zone and the days are in ascending order
+	days := make([]*UserContributionsOnDay, 0, len(hdata))
+	for _, data := range hdata {
+		date := data.Timestamp.AsTimeInLocation(loc).Format("2006-01-02")
+		if len(days) == 0 || days[len(days)-1].Date != date {
+			days = append(days, &UserContributionsOnDay{Date: date})
+		}
+		days[len(days)-1].Contributions += data.Contributions
+	}
+	return days, nil
+}
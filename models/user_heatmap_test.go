@@ -98,3 +98,30 @@ func TestGetUserHeatmapDataByUser(t *testing.T) {
 		assert.Equal(t, tc.JSONResult, string(jsonData))
 	}
 }
+
+func TestGetUserContributionsByDay(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	timeutil.Set(time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC))
+	defer timeutil.Unset()
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 10}).(*user_model.User)
+	days, err := GetUserContributionsByDay(user, user, time.UTC)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*UserContributionsOnDay{{Date: "2020-10-18", Contributions: 3}}, days)
+
+	// the action at 2020-10-20 21:00 UTC happened on the next day in Tokyo
+	user = unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	tokyo, err := time.LoadLocation("Asia/Tokyo")
+	assert.NoError(t, err)
+	days, err = GetUserContributionsByDay(user, user, time.UTC)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*UserContributionsOnDay{{Date: "2020-10-20", Contributions: 1}}, days)
+	days, err = GetUserContributionsByDay(user, user, tokyo)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*UserContributionsOnDay{{Date: "2020-10-21", Contributions: 1}}, days)
+
+	days, err = GetUserContributionsByDay(user, nil, time.UTC)
+	assert.NoError(t, err)
+	assert.Empty(t, days)
+}
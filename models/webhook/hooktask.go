@@ -13,8 +13,10 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 
 	gouuid "github.com/google/uuid"
+	"xorm.io/builder"
 )
 
 //   ___ ___                __   ___________              __
@@ -50,6 +52,7 @@ const (
 	HookEventRepository                HookEventType = "repository"
 	HookEventRelease                   HookEventType = "release"
 	HookEventPackage                   HookEventType = "package"
+	HookEventPreviewEnvironment        HookEventType = "preview_environment"
 )
 
 // Event returns the HookEventType as an event string
@@ -80,10 +83,23 @@ func (h HookEventType) Event() string {
 		return "repository"
 	case HookEventRelease:
 		return "release"
+	case HookEventPreviewEnvironment:
+		return "preview_environment"
 	}
 	return ""
 }
 
+// GitHubEvent returns the name GitHub uses for the event of the HookEventType
+func (h HookEventType) GitHubEvent() string {
+	switch h {
+	case HookEventPullRequestReviewApproved, HookEventPullRequestReviewRejected, HookEventPullRequestReviewComment:
+		return "pull_request_review"
+	case HookEventPackage:
+		return "package"
+	}
+	return h.Event()
+}
+
 // HookRequest represents hook task request information.
 type HookRequest struct {
 	URL        string            `json:"url"`
@@ -107,12 +123,18 @@ type HookTask struct {
 	api.Payloader   `xorm:"-"`
 	PayloadContent  string `xorm:"TEXT"`
 	EventType       HookEventType
+	PayloadVersion  int `xorm:"NOT NULL DEFAULT 1"`
 	IsDelivered     bool
 	Delivered       int64
 	DeliveredString string `xorm:"-"`
 
+	// Retry info, a failed delivery stays undelivered until the next attempt is due.
+	Attempts      int                `xorm:"NOT NULL DEFAULT 0"`
+	NextRetryUnix timeutil.TimeStamp `xorm:"INDEX NOT NULL DEFAULT 0"`
+
 	// History info.
 	IsSucceed       bool
+	Latency         int64         `xorm:"NOT NULL DEFAULT 0"` // milliseconds of the last attempt
 	RequestContent  string        `xorm:"TEXT"`
 	RequestInfo     *HookRequest  `xorm:"-"`
 	ResponseContent string        `xorm:"TEXT"`
@@ -155,6 +177,16 @@ func (t *HookTask) AfterLoad() {
 	}
 }
 
+// IsRetrying returns true if the delivery failed and will be attempted again
+func (t *HookTask) IsRetrying() bool {
+	return !t.IsDelivered && t.Attempts > 0
+}
+
+// IsFailed returns true if the delivery failed and won't be attempted again
+func (t *HookTask) IsFailed() bool {
+	return t.IsDelivered && !t.IsSucceed
+}
+
 func (t *HookTask) simpleMarshalJSON(v interface{}) string {
 	p, err := json.Marshal(v)
 	if err != nil {
@@ -173,6 +205,61 @@ func HookTasks(hookID int64, page int) ([]*HookTask, error) {
 		Find(&tasks)
 }
 
+// FindHookTasksOptions represents the options to find the deliveries of a webhook
+type FindHookTasksOptions struct {
+	db.ListOptions
+	HookID     int64
+	OnlyFailed bool
+}
+
+func (opts *FindHookTasksOptions) toConds() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"hook_id": opts.HookID})
+	if opts.OnlyFailed {
+		cond = cond.And(builder.Eq{"is_delivered": true}, builder.Eq{"is_succeed": false}.Or(builder.IsNull{"is_succeed"}))
+	}
+	return cond
+}
+
+// FindHookTasks returns the deliveries of a webhook matching the options, newest first, and their total count
+func FindHookTasks(ctx context.Context, opts *FindHookTasksOptions) ([]*HookTask, int64, error) {
+	sess := db.GetEngine(ctx).Where(opts.toConds()).Desc("id")
+	if opts.Page > 0 {
+		sess = db.SetSessionPagination(sess, opts)
+	}
+	tasks := make([]*HookTask, 0, opts.PageSize)
+	count, err := sess.FindAndCount(&tasks)
+	return tasks, count, err
+}
+
+// CountFailedHookTasks returns the number of deliveries of a webhook which failed and won't be attempted again
+func CountFailedHookTasks(ctx context.Context, hookID int64) (int64, error) {
+	return db.GetEngine(ctx).Where((&FindHookTasksOptions{HookID: hookID, OnlyFailed: true}).toConds()).Count(new(HookTask))
+}
+
+// ResetFailedHookTasks marks the failed deliveries of a webhook as undelivered to attempt them again,
+// it returns the IDs of the repositories the deliveries belong to.
+func ResetFailedHookTasks(ctx context.Context, hookID int64) ([]int64, error) {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return nil, err
+	}
+	defer committer.Close()
+
+	cond := (&FindHookTasksOptions{HookID: hookID, OnlyFailed: true}).toConds()
+	repoIDs := make([]int64, 0, 5)
+	if err := db.GetEngine(ctx).Table("hook_task").Where(cond).Distinct("repo_id").Find(&repoIDs); err != nil {
+		return nil, err
+	}
+	if len(repoIDs) == 0 {
+		return repoIDs, nil
+	}
+	if _, err := db.GetEngine(ctx).Where(cond).Cols("is_delivered", "attempts", "next_retry_unix").
+		Update(&HookTask{IsDelivered: false, Attempts: 0, NextRetryUnix: 0}); err != nil {
+		return nil, err
+	}
+	return repoIDs, committer.Commit()
+}
+
 // CreateHookTask creates a new hook task,
 // it handles conversion from Payload to PayloadContent.
 func CreateHookTask(t *HookTask) error {
@@ -182,6 +269,9 @@ func CreateHookTask(t *HookTask) error {
 	}
 	t.UUID = gouuid.New().String()
 	t.PayloadContent = string(data)
+	if t.PayloadVersion == 0 {
+		t.PayloadVersion = 1
+	}
 	return db.Insert(db.DefaultContext, t)
 }
 
@@ -216,6 +306,7 @@ func ReplayHookTask(hookID int64, uuid string) (*HookTask, error) {
 			HookID:         task.HookID,
 			PayloadContent: task.PayloadContent,
 			EventType:      task.EventType,
+			PayloadVersion: task.PayloadVersion,
 		}
 		return db.Insert(ctx, newTask)
 	})
@@ -223,19 +314,19 @@ func ReplayHookTask(hookID int64, uuid string) (*HookTask, error) {
 	return newTask, err
 }
 
-// FindUndeliveredHookTasks represents find the undelivered hook tasks
+// FindUndeliveredHookTasks represents find the undelivered hook tasks which are due
 func FindUndeliveredHookTasks() ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 10)
-	if err := db.GetEngine(db.DefaultContext).Where("is_delivered=?", false).Find(&tasks); err != nil {
+	if err := db.GetEngine(db.DefaultContext).Where("is_delivered=? AND next_retry_unix<=?", false, timeutil.TimeStampNow()).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
 }
 
-// FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository
+// FindRepoUndeliveredHookTasks represents find the undelivered hook tasks of one repository which are due
 func FindRepoUndeliveredHookTasks(repoID int64) ([]*HookTask, error) {
 	tasks := make([]*HookTask, 0, 5)
-	if err := db.GetEngine(db.DefaultContext).Where("repo_id=? AND is_delivered=?", repoID, false).Find(&tasks); err != nil {
+	if err := db.GetEngine(db.DefaultContext).Where("repo_id=? AND is_delivered=? AND next_retry_unix<=?", repoID, false, timeutil.TimeStampNow()).Find(&tasks); err != nil {
 		return nil, err
 	}
 	return tasks, nil
@@ -8,6 +8,7 @@ import (
 	"path/filepath"
 	"testing"
 
+	_ "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 )
 
@@ -17,6 +18,7 @@ func TestMain(m *testing.M) {
 		FixtureFiles: []string{
 			"webhook.yml",
 			"hook_task.yml",
+			"repository.yml",
 		},
 	})
 }
@@ -0,0 +1,33 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+// LatestPayloadVersion is the newest schema version of any webhook payload
+const LatestPayloadVersion = 1
+
+// payloadVersions are the current schema versions of the payloads of the events whose payload changed
+// incompatibly since the first version, all other events are still sent in version 1.
+// When the payload of an event changes incompatibly, its version is bumped here together with
+// LatestPayloadVersion, and the older format keeps being produced for webhooks pinned to an older version.
+var payloadVersions = map[HookEventType]int{}
+
+// IsValidPayloadVersion returns true if webhooks can be pinned to the given payload schema version,
+// 0 means the latest version
+func IsValidPayloadVersion(version int) bool {
+	return version >= 0 && version <= LatestPayloadVersion
+}
+
+// PayloadVersionFor returns the schema version of the payload of the event sent to the webhook,
+// which is the newest version of the event not newer than the version the webhook is pinned to
+func (w *Webhook) PayloadVersionFor(event HookEventType) int {
+	current, ok := payloadVersions[event]
+	if !ok {
+		current = 1
+	}
+	if w.HookEvent != nil && w.PayloadVersion > 0 && w.PayloadVersion < current {
+		return w.PayloadVersion
+	}
+	return current
+}
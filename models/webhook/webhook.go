@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
@@ -135,6 +136,7 @@ type HookEvents struct {
 	Repository           bool `json:"repository"`
 	Release              bool `json:"release"`
 	Package              bool `json:"package"`
+	PreviewEnvironment   bool `json:"preview_environment"`
 }
 
 // HookEvent represents events that will delivery hook.
@@ -143,6 +145,11 @@ type HookEvent struct {
 	SendEverything bool   `json:"send_everything"`
 	ChooseEvents   bool   `json:"choose_events"`
 	BranchFilter   string `json:"branch_filter"`
+	PayloadFilter  string `json:"payload_filter"`
+	// RepoFilter is a glob pattern of the full names of the repositories a system webhook is triggered for
+	RepoFilter string `json:"repo_filter"`
+	// PayloadVersion pins the payload schema version sent to the webhook, 0 always sends the latest version
+	PayloadVersion int `json:"payload_version"`
 
 	HookEvents `json:"events"`
 }
@@ -192,6 +199,23 @@ type Webhook struct {
 	Meta            string     `xorm:"TEXT"` // store hook-specific attributes
 	LastStatus      HookStatus // Last delivery status
 
+	// PreviousSecret still signs the payloads for a grace period after the secret was rotated
+	PreviousSecret    string             `xorm:"TEXT"`
+	SecretRotatedUnix timeutil.TimeStamp `xorm:"NOT NULL DEFAULT 0"`
+	// ClientCert and ClientKey are the PEM encoded certificate presented to the target for mutual TLS
+	ClientCert string `xorm:"TEXT"`
+	ClientKey  string `xorm:"TEXT"`
+
+	// IsTemplate marks an organization webhook which is copied to the repositories of the organization
+	// instead of being triggered itself, IsLocked prevents the copies from being edited or deleted.
+	IsTemplate bool `xorm:"NOT NULL DEFAULT false"`
+	IsLocked   bool `xorm:"NOT NULL DEFAULT false"`
+	// TemplateID is the ID of the organization webhook template a repository webhook was copied from
+	TemplateID int64 `xorm:"INDEX NOT NULL DEFAULT 0"`
+
+	// IsGitHubCompatible makes a Gitea webhook send its payloads in the format of GitHub webhooks
+	IsGitHubCompatible bool `xorm:"NOT NULL DEFAULT false"`
+
 	CreatedUnix timeutil.TimeStamp `xorm:"INDEX created"`
 	UpdatedUnix timeutil.TimeStamp `xorm:"INDEX updated"`
 }
@@ -213,6 +237,45 @@ func (w *Webhook) History(page int) ([]*HookTask, error) {
 	return HookTasks(w.ID, page)
 }
 
+// SetSecret changes the secret of the webhook. If keepPrevious is set, payloads are signed with
+// the previous secret as well until the rotation grace period is over.
+func (w *Webhook) SetSecret(secret string, keepPrevious bool) {
+	if secret == w.Secret {
+		return
+	}
+	if keepPrevious && w.Secret != "" {
+		w.PreviousSecret = w.Secret
+		w.SecretRotatedUnix = timeutil.TimeStampNow()
+	} else {
+		w.PreviousSecret = ""
+		w.SecretRotatedUnix = 0
+	}
+	w.Secret = secret
+}
+
+// PreviousSecretExpiresUnix returns when the previous secret stops signing the payloads
+func (w *Webhook) PreviousSecretExpiresUnix() timeutil.TimeStamp {
+	return w.SecretRotatedUnix.AddDuration(setting.Webhook.SecretRotation)
+}
+
+// ActivePreviousSecret returns the previous secret if it is still within the rotation grace period
+func (w *Webhook) ActivePreviousSecret() string {
+	if w.PreviousSecret == "" || w.PreviousSecretExpiresUnix() <= timeutil.TimeStampNow() {
+		return ""
+	}
+	return w.PreviousSecret
+}
+
+// HasClientCert returns true if the webhook presents a client certificate to its target
+func (w *Webhook) HasClientCert() bool {
+	return w.ClientCert != ""
+}
+
+// IsInherited returns true if the webhook was copied from a webhook template of the organization
+func (w *Webhook) IsInherited() bool {
+	return w.TemplateID > 0
+}
+
 // UpdateEvent handles conversion from HookEvent to Events.
 func (w *Webhook) UpdateEvent() error {
 	data, err := json.Marshal(w.HookEvent)
@@ -346,6 +409,12 @@ func (w *Webhook) HasPackageEvent() bool {
 		(w.ChooseEvents && w.HookEvents.Package)
 }
 
+// HasPreviewEnvironmentEvent returns if hook enabled preview environment event.
+func (w *Webhook) HasPreviewEnvironmentEvent() bool {
+	return w.SendEverything ||
+		(w.ChooseEvents && w.HookEvents.PreviewEnvironment)
+}
+
 // EventCheckers returns event checkers
 func (w *Webhook) EventCheckers() []struct {
 	Has  func() bool
@@ -376,6 +445,7 @@ func (w *Webhook) EventCheckers() []struct {
 		{w.HasRepositoryEvent, HookEventRepository},
 		{w.HasReleaseEvent, HookEventRelease},
 		{w.HasPackageEvent, HookEventPackage},
+		{w.HasPreviewEnvironmentEvent, HookEventPreviewEnvironment},
 	}
 }
 
@@ -435,9 +505,10 @@ func GetWebhookByOrgID(orgID, id int64) (*Webhook, error) {
 // ListWebhookOptions are options to filter webhooks on ListWebhooksByOpts
 type ListWebhookOptions struct {
 	db.ListOptions
-	RepoID   int64
-	OrgID    int64
-	IsActive util.OptionalBool
+	RepoID     int64
+	OrgID      int64
+	IsActive   util.OptionalBool
+	IsTemplate util.OptionalBool
 }
 
 func (opts *ListWebhookOptions) toCond() builder.Cond {
@@ -451,6 +522,9 @@ func (opts *ListWebhookOptions) toCond() builder.Cond {
 	if !opts.IsActive.IsNone() {
 		cond = cond.And(builder.Eq{"webhook.is_active": opts.IsActive.IsTrue()})
 	}
+	if !opts.IsTemplate.IsNone() {
+		cond = cond.And(builder.Eq{"webhook.is_template": opts.IsTemplate.IsTrue()})
+	}
 	return cond
 }
 
@@ -510,10 +584,25 @@ func GetSystemWebhooks(ctx context.Context, isActive util.OptionalBool) ([]*Webh
 		Find(&webhooks)
 }
 
-// UpdateWebhook updates information of webhook.
+// UpdateWebhook updates information of webhook, the changes of an organization webhook template
+// are applied to its locked copies.
 func UpdateWebhook(w *Webhook) error {
-	_, err := db.GetEngine(db.DefaultContext).ID(w.ID).AllCols().Update(w)
-	return err
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if _, err := db.GetEngine(ctx).ID(w.ID).AllCols().Update(w); err != nil {
+		return err
+	}
+	if w.OrgID > 0 {
+		if err := syncTemplateWebhookCopies(ctx, w); err != nil {
+			return err
+		}
+	}
+
+	return committer.Commit()
 }
 
 // UpdateWebhookLastStatus updates last status of webhook.
@@ -524,13 +613,7 @@ func UpdateWebhookLastStatus(w *Webhook) error {
 
 // deleteWebhook uses argument bean as query condition,
 // ID must be specified and do not assign unnecessary fields.
-func deleteWebhook(bean *Webhook) (err error) {
-	ctx, committer, err := db.TxContext()
-	if err != nil {
-		return err
-	}
-	defer committer.Close()
-
+func deleteWebhook(ctx context.Context, bean *Webhook) error {
 	if count, err := db.DeleteByBean(ctx, bean); err != nil {
 		return err
 	} else if count == 0 {
@@ -538,24 +621,57 @@ func deleteWebhook(bean *Webhook) (err error) {
 	} else if _, err = db.DeleteByBean(ctx, &HookTask{HookID: bean.ID}); err != nil {
 		return err
 	}
-
-	return committer.Commit()
+	return nil
 }
 
 // DeleteWebhookByRepoID deletes webhook of repository by given ID.
 func DeleteWebhookByRepoID(repoID, id int64) error {
-	return deleteWebhook(&Webhook{
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if err := deleteWebhook(ctx, &Webhook{
 		ID:     id,
 		RepoID: repoID,
-	})
+	}); err != nil {
+		return err
+	}
+
+	return committer.Commit()
 }
 
 // DeleteWebhookByOrgID deletes webhook of organization by given ID.
+// The locked copies of a webhook template are deleted too, the others are kept as ordinary repository webhooks.
 func DeleteWebhookByOrgID(orgID, id int64) error {
-	return deleteWebhook(&Webhook{
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return err
+	}
+	defer committer.Close()
+
+	if err := deleteWebhook(ctx, &Webhook{
 		ID:    id,
 		OrgID: orgID,
-	})
+	}); err != nil {
+		return err
+	}
+
+	copies := make([]*Webhook, 0, 10)
+	if err := db.GetEngine(ctx).Where("template_id=? AND is_locked=?", id, true).Find(&copies); err != nil {
+		return err
+	}
+	for _, w := range copies {
+		if err := deleteWebhook(ctx, &Webhook{ID: w.ID}); err != nil {
+			return err
+		}
+	}
+	if _, err := db.GetEngine(ctx).Where("template_id=?", id).Cols("template_id", "is_locked").Update(&Webhook{}); err != nil {
+		return err
+	}
+
+	return committer.Commit()
 }
 
 // DeleteDefaultSystemWebhook deletes an admin-configured default or system webhook (where Org and Repo ID both 0)
@@ -598,3 +714,102 @@ func CopyDefaultWebhooksToRepo(ctx context.Context, repoID int64) error {
 	}
 	return nil
 }
+
+// copyToRepo returns a copy of an organization webhook template for a repository
+func (w *Webhook) copyToRepo(repoID int64) *Webhook {
+	return &Webhook{
+		RepoID:             repoID,
+		URL:                w.URL,
+		HTTPMethod:         w.HTTPMethod,
+		ContentType:        w.ContentType,
+		Secret:             w.Secret,
+		Events:             w.Events,
+		HookEvent:          w.HookEvent,
+		IsActive:           w.IsActive,
+		Type:               w.Type,
+		Meta:               w.Meta,
+		PreviousSecret:     w.PreviousSecret,
+		SecretRotatedUnix:  w.SecretRotatedUnix,
+		ClientCert:         w.ClientCert,
+		ClientKey:          w.ClientKey,
+		IsLocked:           w.IsLocked,
+		TemplateID:         w.ID,
+		IsGitHubCompatible: w.IsGitHubCompatible,
+	}
+}
+
+// CopyOrgTemplateWebhooksToRepo creates copies of the webhook templates of an organization in a repo
+func CopyOrgTemplateWebhooksToRepo(ctx context.Context, orgID, repoID int64) error {
+	ws, err := ListWebhooksByOpts(ctx, &ListWebhookOptions{OrgID: orgID, IsTemplate: util.OptionalBoolTrue})
+	if err != nil {
+		return fmt.Errorf("ListWebhooksByOpts: %v", err)
+	}
+
+	for _, w := range ws {
+		if err := CreateWebhook(ctx, w.copyToRepo(repoID)); err != nil {
+			return fmt.Errorf("CreateWebhook: %v", err)
+		}
+	}
+	return nil
+}
+
+// ApplyTemplateWebhookToRepos creates copies of an organization webhook template in all repositories
+// of the organization which don't have one yet and returns the number of created copies.
+func ApplyTemplateWebhookToRepos(ctx context.Context, template *Webhook) (int, error) {
+	if !template.IsTemplate || template.OrgID == 0 {
+		return 0, nil
+	}
+
+	repoIDs := make([]int64, 0, 10)
+	if err := db.GetEngine(ctx).Table("repository").
+		Where(builder.Eq{"owner_id": template.OrgID}.
+			And(builder.NotIn("id", builder.Select("repo_id").From("webhook").Where(builder.Eq{"template_id": template.ID})))).
+		Cols("id").
+		Find(&repoIDs); err != nil {
+		return 0, err
+	}
+
+	for _, repoID := range repoIDs {
+		if err := CreateWebhook(ctx, template.copyToRepo(repoID)); err != nil {
+			return 0, fmt.Errorf("CreateWebhook: %v", err)
+		}
+	}
+	return len(repoIDs), nil
+}
+
+// syncTemplateWebhookCopies applies the changes of an organization webhook to its copies,
+// the copies of a webhook which is no template anymore are detached from it.
+func syncTemplateWebhookCopies(ctx context.Context, w *Webhook) error {
+	e := db.GetEngine(ctx)
+	if !w.IsTemplate {
+		_, err := e.Where("template_id=?", w.ID).Cols("template_id", "is_locked").Update(&Webhook{})
+		return err
+	}
+
+	if _, err := e.Where("template_id=?", w.ID).Cols("is_locked").Update(&Webhook{IsLocked: w.IsLocked}); err != nil {
+		return err
+	}
+	if !w.IsLocked {
+		return nil
+	}
+	copied := w.copyToRepo(0)
+	_, err := e.Where("template_id=?", w.ID).
+		Cols("url", "http_method", "content_type", "secret", "events", "is_active", "type", "meta",
+			"previous_secret", "secret_rotated_unix", "client_cert", "client_key", "is_github_compatible").
+		Update(copied)
+	return err
+}
+
+// DeleteInheritedWebhooks deletes the webhooks a repository has inherited from the templates of its organization
+func DeleteInheritedWebhooks(ctx context.Context, repoID int64) error {
+	ws := make([]*Webhook, 0, 5)
+	if err := db.GetEngine(ctx).Where("repo_id=? AND template_id>?", repoID, 0).Find(&ws); err != nil {
+		return err
+	}
+	for _, w := range ws {
+		if err := deleteWebhook(ctx, &Webhook{ID: w.ID}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
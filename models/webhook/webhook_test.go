@@ -12,6 +12,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 
@@ -65,6 +66,25 @@ func TestWebhook_UpdateEvent(t *testing.T) {
 	assert.Equal(t, *hookEvent, *actualHookEvent)
 }
 
+func TestWebhook_SetSecret(t *testing.T) {
+	w := &Webhook{Secret: "old"}
+	w.SetSecret("new", true)
+	assert.Equal(t, "new", w.Secret)
+	assert.Equal(t, "old", w.ActivePreviousSecret())
+
+	// an unchanged secret keeps the rotation going
+	w.SetSecret("new", false)
+	assert.Equal(t, "old", w.ActivePreviousSecret())
+
+	// the previous secret expires after the grace period
+	w.SecretRotatedUnix = w.SecretRotatedUnix.AddDuration(-setting.Webhook.SecretRotation)
+	assert.Empty(t, w.ActivePreviousSecret())
+
+	w.SetSecret("newer", false)
+	assert.Equal(t, "newer", w.Secret)
+	assert.Empty(t, w.PreviousSecret)
+}
+
 func TestWebhook_EventsArray(t *testing.T) {
 	assert.Equal(t, []string{
 		"create", "delete", "fork", "push",
@@ -72,7 +92,7 @@ func TestWebhook_EventsArray(t *testing.T) {
 		"pull_request", "pull_request_assign", "pull_request_label", "pull_request_milestone",
 		"pull_request_comment", "pull_request_review_approved", "pull_request_review_rejected",
 		"pull_request_review_comment", "pull_request_sync", "repository", "release",
-		"package",
+		"package", "preview_environment",
 	},
 		(&Webhook{
 			HookEvent: &HookEvent{SendEverything: true},
@@ -192,6 +212,75 @@ func TestDeleteWebhookByOrgID(t *testing.T) {
 	assert.True(t, IsErrWebhookNotExist(err))
 }
 
+func TestWebhookTemplate(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	template := &Webhook{
+		OrgID:       3,
+		URL:         "www.example.com/template",
+		ContentType: ContentTypeJSON,
+		Events:      `{"push_only":true}`,
+		IsActive:    true,
+		IsTemplate:  true,
+		IsLocked:    true,
+	}
+	assert.NoError(t, CreateWebhook(db.DefaultContext, template))
+
+	// templates are not delivered themselves
+	hooks, err := ListWebhooksByOpts(db.DefaultContext, &ListWebhookOptions{OrgID: 3, IsTemplate: util.OptionalBoolFalse})
+	assert.NoError(t, err)
+	if assert.Len(t, hooks, 1) {
+		assert.EqualValues(t, 3, hooks[0].ID)
+	}
+
+	assert.NoError(t, CopyOrgTemplateWebhooksToRepo(db.DefaultContext, 3, 3))
+	copied := unittest.AssertExistsAndLoadBean(t, &Webhook{RepoID: 3, TemplateID: template.ID}).(*Webhook)
+	assert.True(t, copied.IsInherited())
+	assert.True(t, copied.IsLocked)
+	assert.False(t, copied.IsTemplate)
+	assert.EqualValues(t, 0, copied.OrgID)
+	assert.Equal(t, template.URL, copied.URL)
+
+	count, err := ApplyTemplateWebhookToRepos(db.DefaultContext, template)
+	assert.NoError(t, err)
+	assert.Positive(t, count)
+	unittest.AssertCount(t, &Webhook{TemplateID: template.ID}, count+1)
+	count, err = ApplyTemplateWebhookToRepos(db.DefaultContext, template)
+	assert.NoError(t, err)
+	assert.Zero(t, count)
+
+	template.URL = "www.example.com/changed"
+	assert.NoError(t, UpdateWebhook(template))
+	unittest.AssertExistsAndLoadBean(t, &Webhook{ID: copied.ID, URL: template.URL})
+
+	assert.NoError(t, DeleteWebhookByOrgID(3, template.ID))
+	unittest.AssertNotExistsBean(t, &Webhook{ID: copied.ID})
+	unittest.AssertCount(t, &Webhook{TemplateID: template.ID}, 0)
+}
+
+func TestWebhookTemplate_Unlocked(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	template := &Webhook{
+		OrgID:       3,
+		URL:         "www.example.com/template",
+		ContentType: ContentTypeJSON,
+		Events:      `{"push_only":true}`,
+		IsTemplate:  true,
+	}
+	assert.NoError(t, CreateWebhook(db.DefaultContext, template))
+	assert.NoError(t, CopyOrgTemplateWebhooksToRepo(db.DefaultContext, 3, 3))
+	copied := unittest.AssertExistsAndLoadBean(t, &Webhook{RepoID: 3, TemplateID: template.ID}).(*Webhook)
+
+	// unlocked copies keep their own configuration
+	template.URL = "www.example.com/changed"
+	assert.NoError(t, UpdateWebhook(template))
+	unittest.AssertExistsAndLoadBean(t, &Webhook{ID: copied.ID, URL: "www.example.com/template"})
+
+	// and are kept as ordinary repository webhooks when the template is deleted
+	assert.NoError(t, DeleteWebhookByOrgID(3, template.ID))
+	copied = unittest.AssertExistsAndLoadBean(t, &Webhook{ID: copied.ID}).(*Webhook)
+	assert.False(t, copied.IsInherited())
+}
+
 func TestHookTasks(t *testing.T) {
 	assert.NoError(t, unittest.PrepareTestDatabase())
 	hookTasks, err := HookTasks(1, 1)
@@ -205,6 +294,41 @@ func TestHookTasks(t *testing.T) {
 	assert.Len(t, hookTasks, 0)
 }
 
+func TestFindHookTasks(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	failed := &HookTask{
+		RepoID:      1,
+		HookID:      1,
+		Payloader:   &api.PushPayload{},
+		IsDelivered: true,
+		Attempts:    3,
+	}
+	assert.NoError(t, CreateHookTask(failed))
+
+	tasks, count, err := FindHookTasks(db.DefaultContext, &FindHookTasksOptions{HookID: 1})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	assert.Len(t, tasks, 2)
+
+	tasks, count, err = FindHookTasks(db.DefaultContext, &FindHookTasksOptions{HookID: 1, OnlyFailed: true})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 2, count)
+	if assert.Len(t, tasks, 2) {
+		assert.Equal(t, failed.ID, tasks[0].ID)
+	}
+
+	repoIDs, err := ResetFailedHookTasks(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []int64{1}, repoIDs)
+	failed = unittest.AssertExistsAndLoadBean(t, &HookTask{ID: failed.ID}).(*HookTask)
+	assert.False(t, failed.IsDelivered)
+	assert.Equal(t, 0, failed.Attempts)
+
+	count, err = CountFailedHookTasks(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, count)
+}
+
 func TestCreateHookTask(t *testing.T) {
 	assert.NoError(t, unittest.PrepareTestDatabase())
 	hookTask := &HookTask{
@@ -328,3 +452,16 @@ func TestCleanupHookTaskTable_OlderThan_LeavesTaskEarlierThanAgeToDelete(t *test
 	assert.NoError(t, CleanupHookTaskTable(context.Background(), OlderThan, 168*time.Hour, 0))
 	unittest.AssertExistsAndLoadBean(t, hookTask)
 }
+
+func TestWebhook_PayloadVersionFor(t *testing.T) {
+	w := &Webhook{HookEvent: &HookEvent{}}
+	assert.Equal(t, 1, w.PayloadVersionFor(HookEventPush))
+
+	w.PayloadVersion = LatestPayloadVersion
+	assert.Equal(t, 1, w.PayloadVersionFor(HookEventPush))
+
+	assert.True(t, IsValidPayloadVersion(0))
+	assert.True(t, IsValidPayloadVersion(LatestPayloadVersion))
+	assert.False(t, IsValidPayloadVersion(LatestPayloadVersion+1))
+	assert.False(t, IsValidPayloadVersion(-1))
+}
@@ -0,0 +1,292 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"fmt"
+	"io"
+	"path"
+	"sort"
+	"strings"
+
+	"code.gitea.io/gitea/modules/git"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// WorkflowsDir is the directory containing the workflows of a repository
+const WorkflowsDir = ".gitea/workflows"
+
+// The events which trigger workflows
+const (
+	EventPush        = "push"
+	EventPullRequest = "pull_request"
+)
+
+// StringList is a list of strings which can also be written as a single string in YAML
+type StringList []string
+
+// UnmarshalYAML implements yaml.Unmarshaler
+func (l *StringList) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var single string
+	if err := unmarshal(&single); err == nil {
+		*l = StringList{single}
+		return nil
+	}
+	var list []string
+	if err := unmarshal(&list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// EventFilter restricts the refs and activity types an event triggers a workflow for
+type EventFilter struct {
+	Branches StringList `yaml:"branches"`
+	Tags     StringList `yaml:"tags"`
+	Types    StringList `yaml:"types"`
+}
+
+// Events are the events triggering a workflow by name.
+// The filter of an event is nil if the event isn't restricted.
+type Events map[string]*EventFilter
+
+// UnmarshalYAML implements yaml.Unmarshaler, events can be a single name, a list of names or a map of filters
+func (e *Events) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var names StringList
+	if err := unmarshal(&names); err == nil {
+		*e = make(Events, len(names))
+		for _, name := range names {
+			(*e)[name] = nil
+		}
+		return nil
+	}
+	filters := make(map[string]*EventFilter)
+	if err := unmarshal(&filters); err != nil {
+		return err
+	}
+	*e = filters
+	return nil
+}
+
+// Step is a step of a job, either a shell command or an action
+type Step struct {
+	Name string            `yaml:"name" json:"name,omitempty"`
+	Run  string            `yaml:"run" json:"run,omitempty"`
+	Uses string            `yaml:"uses" json:"uses,omitempty"`
+	With map[string]string `yaml:"with" json:"with,omitempty"`
+	Env  map[string]string `yaml:"env" json:"env,omitempty"`
+}
+
+// Job is a job of a workflow
+type Job struct {
+	Name   string            `yaml:"name" json:"name,omitempty"`
+	RunsOn StringList        `yaml:"runs-on" json:"runs_on"`
+	Needs  StringList        `yaml:"needs" json:"needs,omitempty"`
+	Env    map[string]string `yaml:"env" json:"env,omitempty"`
+	Steps  []*Step           `yaml:"steps" json:"steps"`
+}
+
+// Workflow is a workflow file of a repository
+type Workflow struct {
+	Name string            `yaml:"name"`
+	On   Events            `yaml:"on"`
+	Env  map[string]string `yaml:"env"`
+	Jobs map[string]*Job   `yaml:"jobs"`
+}
+
+// ErrInvalidWorkflow represents an invalid workflow file
+type ErrInvalidWorkflow struct {
+	Reason string
+}
+
+// IsErrInvalidWorkflow checks if an error is a ErrInvalidWorkflow.
+func IsErrInvalidWorkflow(err error) bool {
+	_, ok := err.(ErrInvalidWorkflow)
+	return ok
+}
+
+func (err ErrInvalidWorkflow) Error() string {
+	return fmt.Sprintf("invalid workflow: %s", err.Reason)
+}
+
+// Parse parses and validates a workflow
+func Parse(content []byte) (*Workflow, error) {
+	w := new(Workflow)
+	if err := yaml.Unmarshal(content, w); err != nil {
+		return nil, ErrInvalidWorkflow{Reason: err.Error()}
+	}
+	if len(w.On) == 0 {
+		return nil, ErrInvalidWorkflow{Reason: "no events defined in \"on\""}
+	}
+	if len(w.Jobs) == 0 {
+		return nil, ErrInvalidWorkflow{Reason: "no jobs defined"}
+	}
+	for id, job := range w.Jobs {
+		if job == nil {
+			return nil, ErrInvalidWorkflow{Reason: fmt.Sprintf("job %q is empty", id)}
+		}
+		if len(job.RunsOn) == 0 {
+			return nil, ErrInvalidWorkflow{Reason: fmt.Sprintf("job %q has no runs-on labels", id)}
+		}
+		if len(job.Steps) == 0 {
+			return nil, ErrInvalidWorkflow{Reason: fmt.Sprintf("job %q has no steps", id)}
+		}
+		for i, step := range job.Steps {
+			if step == nil || (step.Run == "") == (step.Uses == "") {
+				return nil, ErrInvalidWorkflow{Reason: fmt.Sprintf("step %d of job %q must either run a command or use an action", i+1, id)}
+			}
+		}
+		for _, need := range job.Needs {
+			if _, ok := w.Jobs[need]; !ok || need == id {
+				return nil, ErrInvalidWorkflow{Reason: fmt.Sprintf("job %q needs unknown job %q", id, need)}
+			}
+		}
+	}
+	if err := checkCycles(w.Jobs); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// checkCycles makes sure the jobs don't need each other in a cycle
+func checkCycles(jobs map[string]*Job) error {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+	state := make(map[string]int, len(jobs))
+	var visit func(id string) error
+	visit = func(id string) error {
+		switch state[id] {
+		case visiting:
+			return ErrInvalidWorkflow{Reason: fmt.Sprintf("job %q needs itself", id)}
+		case visited:
+			return nil
+		}
+		state[id] = visiting
+		for _, need := range jobs[id].Needs {
+			if err := visit(need); err != nil {
+				return err
+			}
+		}
+		state[id] = visited
+		return nil
+	}
+	for _, id := range JobIDs(jobs) {
+		if err := visit(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// JobIDs returns the ids of the jobs in a stable order
+func JobIDs(jobs map[string]*Job) []string {
+	ids := make([]string, 0, len(jobs))
+	for id := range jobs {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// DisplayName returns the name of the job, or its id if it has no name
+func (j *Job) DisplayName(id string) string {
+	if j.Name != "" {
+		return j.Name
+	}
+	return id
+}
+
+// Matches returns true if the event triggers the workflow.
+// The ref is the full ref name which was pushed, or the base branch of a pull request.
+// The action is the activity type of pull request events, e.g. "opened".
+func (w *Workflow) Matches(event, ref, action string) bool {
+	filter, ok := w.On[event]
+	if !ok {
+		return false
+	}
+
+	if event == EventPullRequest {
+		types := StringList{"opened", "synchronize", "reopened"}
+		if filter != nil && len(filter.Types) > 0 {
+			types = filter.Types
+		}
+		if !contains(types, action) {
+			return false
+		}
+	}
+
+	if filter == nil || (len(filter.Branches) == 0 && len(filter.Tags) == 0) {
+		return true
+	}
+	switch {
+	case strings.HasPrefix(ref, git.BranchPrefix):
+		return matchesAny(filter.Branches, strings.TrimPrefix(ref, git.BranchPrefix))
+	case strings.HasPrefix(ref, git.TagPrefix):
+		return matchesAny(filter.Tags, strings.TrimPrefix(ref, git.TagPrefix))
+	}
+	return false
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func matchesAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		g, err := glob.Compile(pattern, '/')
+		if err != nil {
+			continue
+		}
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// ListWorkflows returns the content of the workflow files of a commit by file name
+func ListWorkflows(commit *git.Commit) (map[string][]byte, error) {
+	tree, err := commit.SubTree(WorkflowsDir)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	entries, err := tree.ListEntries()
+	if err != nil {
+		return nil, err
+	}
+
+	workflows := make(map[string][]byte)
+	for _, entry := range entries {
+		ext := path.Ext(entry.Name())
+		if !entry.IsRegular() || (ext != ".yml" && ext != ".yaml") {
+			continue
+		}
+		reader, err := entry.Blob().DataAsync()
+		if err != nil {
+			return nil, err
+		}
+		content, err := io.ReadAll(reader)
+		reader.Close()
+		if err != nil {
+			return nil, err
+		}
+		workflows[entry.Name()] = content
+	}
+	return workflows, nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	w, err := Parse([]byte(`
+name: CI
+on:
+  push:
+    branches: [main, "release/*"]
+    tags: ["v*"]
+  pull_request:
+env:
+  GOFLAGS: -mod=mod
+jobs:
+  lint:
+    runs-on: ubuntu-latest
+    steps:
+      - run: make lint
+  test:
+    name: Unit Tests
+    runs-on: [self-hosted, linux]
+    needs: lint
+    steps:
+      - uses: actions/checkout@v3
+      - name: Test
+        run: make test
+`))
+	assert.NoError(t, err)
+	assert.Equal(t, "CI", w.Name)
+	assert.Equal(t, []string{"lint", "test"}, JobIDs(w.Jobs))
+	assert.Equal(t, StringList{"ubuntu-latest"}, w.Jobs["lint"].RunsOn)
+	assert.Equal(t, StringList{"self-hosted", "linux"}, w.Jobs["test"].RunsOn)
+	assert.Equal(t, StringList{"lint"}, w.Jobs["test"].Needs)
+	assert.Equal(t, "Unit Tests", w.Jobs["test"].DisplayName("test"))
+	assert.Equal(t, "lint", w.Jobs["lint"].DisplayName("lint"))
+
+	assert.True(t, w.Matches(EventPush, "refs/heads/main", ""))
+	assert.True(t, w.Matches(EventPush, "refs/heads/release/1.17", ""))
+	assert.True(t, w.Matches(EventPush, "refs/tags/v1.0.0", ""))
+	assert.False(t, w.Matches(EventPush, "refs/heads/feature", ""))
+	assert.False(t, w.Matches(EventPush, "refs/tags/1.0.0", ""))
+	assert.True(t, w.Matches(EventPullRequest, "refs/heads/feature", "opened"))
+	assert.False(t, w.Matches(EventPullRequest, "refs/heads/feature", "closed"))
+
+	w, err = Parse([]byte("on: [push]\njobs:\n  build:\n    runs-on: linux\n    steps:\n      - run: make\n"))
+	assert.NoError(t, err)
+	assert.True(t, w.Matches(EventPush, "refs/heads/anything", ""))
+	assert.False(t, w.Matches(EventPullRequest, "refs/heads/anything", "opened"))
+
+	for _, content := range []string{
+		"jobs:\n  build:\n    runs-on: linux\n    steps:\n      - run: make\n",
+		"on: push\n",
+		"on: push\njobs:\n  build:\n    steps:\n      - run: make\n",
+		"on: push\njobs:\n  build:\n    runs-on: linux\n",
+		"on: push\njobs:\n  build:\n    runs-on: linux\n    steps:\n      - name: nothing\n",
+		"on: push\njobs:\n  build:\n    runs-on: linux\n    needs: missing\n    steps:\n      - run: make\n",
+		"on: push\njobs:\n  a:\n    runs-on: linux\n    needs: b\n    steps:\n      - run: make\n  b:\n    runs-on: linux\n    needs: a\n    steps:\n      - run: make\n",
+		"on: [push",
+	} {
+		_, err := Parse([]byte(content))
+		assert.True(t, IsErrInvalidWorkflow(err), content)
+	}
+}
@@ -0,0 +1,61 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package activitypub
+
+import (
+	"net/http"
+	"net/url"
+	"strings"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// IsInstanceAllowed returns true if this instance may federate with the remote instance of the host.
+// Blocked domains always take precedence, an empty allow list allows every instance which is not blocked.
+func IsInstanceAllowed(host string) bool {
+	if !setting.Federation.Enabled {
+		return false
+	}
+
+	if setting.Federation.BlockedDomainsList.MatchHostName(host) {
+		return false
+	}
+	allowList := setting.Federation.AllowedDomainsList
+	return allowList.IsEmpty() || allowList.MatchHostName(host)
+}
+
+// RequestInstanceHost returns the host of the remote instance which signed the request with an HTTP signature.
+// It returns an empty string for unsigned requests.
+func RequestInstanceHost(req *http.Request) string {
+	signature := req.Header.Get("Signature")
+	if signature == "" {
+		// the signature of a request using the Authorization header has the form `Signature keyId="...",...`
+		if auth := req.Header.Get("Authorization"); strings.HasPrefix(auth, "Signature ") {
+			signature = auth[len("Signature "):]
+		}
+	}
+
+	for _, param := range strings.Split(signature, ",") {
+		parts := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(parts) != 2 || parts[0] != "keyId" {
+			continue
+		}
+		keyID, err := url.Parse(strings.Trim(parts[1], `"`))
+		if err != nil {
+			return ""
+		}
+		return keyID.Host
+	}
+	return ""
+}
+
+// IsRequestAllowed returns true if the remote instance sending the request may federate with this instance.
+// Unsigned requests can't be attributed to an instance, they are only allowed if there is no allow list.
+func IsRequestAllowed(req *http.Request) bool {
+	if host := RequestInstanceHost(req); host != "" {
+		return IsInstanceAllowed(host)
+	}
+	return setting.Federation.Enabled && setting.Federation.AllowedDomainsList.IsEmpty()
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package activitypub
+
+import (
+	"net/http"
+	"testing"
+
+	"code.gitea.io/gitea/modules/hostmatcher"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func setDomainLists(allowed, blocked string) {
+	setting.Federation.AllowedDomainsList = hostmatcher.ParseSimpleMatchList("federation.ALLOWED_DOMAINS", allowed)
+	setting.Federation.BlockedDomainsList = hostmatcher.ParseSimpleMatchList("federation.BLOCKED_DOMAINS", blocked)
+}
+
+func TestIsInstanceAllowed(t *testing.T) {
+	oldFederation := setting.Federation
+	defer func() {
+		setting.Federation = oldFederation
+	}()
+
+	setting.Federation.Enabled = true
+	setDomainLists("", "*.spam.example.com, bad.example.org")
+	assert.True(t, IsInstanceAllowed("gitea.example.com"))
+	assert.False(t, IsInstanceAllowed("a.spam.example.com"))
+	assert.False(t, IsInstanceAllowed("BAD.example.org:443"))
+
+	setDomainLists("*.example.com", "*.spam.example.com, bad.example.org")
+	assert.True(t, IsInstanceAllowed("gitea.example.com"))
+	assert.False(t, IsInstanceAllowed("gitea.example.net"))
+	assert.False(t, IsInstanceAllowed("a.spam.example.com"))
+
+	setting.Federation.Enabled = false
+	assert.False(t, IsInstanceAllowed("gitea.example.com"))
+}
+
+func TestIsRequestAllowed(t *testing.T) {
+	oldFederation := setting.Federation
+	defer func() {
+		setting.Federation = oldFederation
+	}()
+
+	newRequest := func(signature string) *http.Request {
+		req, _ := http.NewRequest("GET", "https://gitea.example.com/.well-known/nodeinfo", nil)
+		if signature != "" {
+			req.Header.Set("Signature", signature)
+		}
+		return req
+	}
+	signed := newRequest(`keyId="https://social.example.net/users/alice#main-key",algorithm="rsa-sha256",signature="abc="`)
+	assert.Equal(t, "social.example.net", RequestInstanceHost(signed))
+	assert.Empty(t, RequestInstanceHost(newRequest("")))
+
+	setting.Federation.Enabled = true
+	setDomainLists("", "")
+	assert.True(t, IsRequestAllowed(signed))
+	assert.True(t, IsRequestAllowed(newRequest("")))
+
+	setDomainLists("", "*.example.net")
+	assert.False(t, IsRequestAllowed(signed))
+	assert.True(t, IsRequestAllowed(newRequest("")))
+
+	setDomainLists("social.example.net", "")
+	assert.True(t, IsRequestAllowed(signed))
+	assert.False(t, IsRequestAllowed(newRequest("")))
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Event is an event of the instance-wide audit log as it is sent to external collectors
+type Event struct {
+	ID       int64     `json:"id"`
+	Time     time.Time `json:"time"`
+	Category string    `json:"category"`
+	Action   string    `json:"action"`
+	DoerID   int64     `json:"doer_id"`
+	DoerName string    `json:"doer_name"`
+	IP       string    `json:"ip"`
+	Target   string    `json:"target"`
+	Detail   string    `json:"detail"`
+}
+
+// String returns a single line representation of the event
+func (e *Event) String() string {
+	return fmt.Sprintf("action=%s doer=%q doer_id=%d ip=%q target=%q detail=%q", e.Action, e.DoerName, e.DoerID, e.IP, e.Target, e.Detail)
+}
+
+// Sink forwards audit events to an external collector
+type Sink interface {
+	Name() string
+	Send(ctx context.Context, event *Event) error
+}
+
+var (
+	sinks        []Sink
+	forwardQueue queue.Queue
+)
+
+func handle(data ...queue.Data) []queue.Data {
+	ctx := graceful.GetManager().ShutdownContext()
+	for _, datum := range data {
+		event := datum.(*Event)
+		for _, sink := range sinks {
+			if err := sink.Send(ctx, event); err != nil {
+				log.Error("Unable to forward audit event %d to %s: %v", event.ID, sink.Name(), err)
+			}
+		}
+	}
+	return nil
+}
+
+// Init creates the sinks configured in the audit settings
+func Init() error {
+	if !setting.Audit.Enabled || len(setting.Audit.Forwarders) == 0 {
+		return nil
+	}
+
+	sinks = make([]Sink, 0, len(setting.Audit.Forwarders))
+	for _, name := range setting.Audit.Forwarders {
+		var sink Sink
+		var err error
+		switch name {
+		case "syslog":
+			sink, err = NewSyslogSink(setting.Audit.Syslog.Network, setting.Audit.Syslog.Address, setting.Audit.Syslog.Tag)
+		case "http":
+			sink, err = NewHTTPSink(setting.Audit.HTTP.URL, setting.Audit.HTTP.AuthorizationHeader, setting.Audit.HTTP.Timeout)
+		default:
+			err = fmt.Errorf("unknown audit forwarder %q", name)
+		}
+		if err != nil {
+			return err
+		}
+		sinks = append(sinks, sink)
+	}
+
+	forwardQueue = queue.CreateQueue("audit_forward", handle, &Event{})
+	if forwardQueue == nil {
+		return fmt.Errorf("Unable to create audit_forward Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(forwardQueue.Run)
+
+	return nil
+}
+
+// Forward sends the event to all configured sinks in the background
+func Forward(event *Event) {
+	if forwardQueue == nil {
+		return
+	}
+	if err := forwardQueue.Push(event); err != nil {
+		log.Error("Unable to queue audit event %d for forwarding: %v", event.ID, err)
+	}
+}
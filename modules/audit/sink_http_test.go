@@ -0,0 +1,57 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPSink(t *testing.T) {
+	var received Event
+	var authorization string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		authorization = r.Header.Get("Authorization")
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	_, err := NewHTTPSink("", "", time.Second)
+	assert.Error(t, err)
+
+	sink, err := NewHTTPSink(server.URL, "Bearer secret", time.Second)
+	assert.NoError(t, err)
+
+	event := &Event{
+		ID:       1,
+		Category: "auth",
+		Action:   "auth.signin_failed",
+		IP:       "127.0.0.1",
+		Target:   "user2",
+	}
+	assert.NoError(t, sink.Send(context.Background(), event))
+	assert.Equal(t, "Bearer secret", authorization)
+	assert.Equal(t, event.Action, received.Action)
+	assert.Equal(t, event.Target, received.Target)
+	assert.Equal(t, event.IP, received.IP)
+
+	failing := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer failing.Close()
+
+	sink, err = NewHTTPSink(failing.URL, "", time.Second)
+	assert.NoError(t, err)
+	assert.Error(t, sink.Send(context.Background(), event))
+}
@@ -0,0 +1,14 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+//go:build windows || plan9
+
+package audit
+
+import "fmt"
+
+// NewSyslogSink is not supported on this platform
+func NewSyslogSink(network, address, tag string) (Sink, error) {
+	return nil, fmt.Errorf("syslog is not supported on this platform")
+}
@@ -0,0 +1,229 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"strconv"
+	"time"
+
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/nosql"
+	"code.gitea.io/gitea/modules/util"
+
+	mc "gitea.com/go-chi/cache"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+// TwoTierCache represents a cache adapter keeping the recently used items of a redis cache in a per-process LRU,
+// the local copies of changed items are invalidated on every instance by messages on a redis channel.
+type TwoTierCache struct {
+	RedisCacher
+	local    *lru.Cache
+	localTTL int64
+	channel  string
+	origin   string
+}
+
+// twoTierInvalidation is the message published when an item is changed
+type twoTierInvalidation struct {
+	Origin string `json:"origin"`
+	Key    string `json:"key,omitempty"`
+	Flush  bool   `json:"flush,omitempty"`
+}
+
+var _ mc.Cache = &TwoTierCache{}
+
+func (c *TwoTierCache) getLocal(key string) (interface{}, bool) {
+	cached, ok := c.local.Get(key)
+	if !ok {
+		return nil, false
+	}
+	item, ok := cached.(*MemoryItem)
+	if !ok || item.hasExpired() {
+		c.local.Remove(key)
+		return nil, false
+	}
+	return item.Val, true
+}
+
+func (c *TwoTierCache) putLocal(key string, val interface{}, expire int64) {
+	timeout := c.localTTL
+	if expire > 0 && (timeout <= 0 || expire < timeout) {
+		timeout = expire
+	}
+	c.local.Add(key, &MemoryItem{
+		Val:     val,
+		Created: time.Now().Unix(),
+		Timeout: timeout,
+	})
+}
+
+// invalidate removes the local copy of the key and tells the other instances to remove theirs
+func (c *TwoTierCache) invalidate(key string, flush bool) {
+	if flush {
+		c.local.Purge()
+	} else {
+		c.local.Remove(key)
+	}
+	msg, err := json.Marshal(&twoTierInvalidation{Origin: c.origin, Key: key, Flush: flush})
+	if err != nil {
+		log.Error("Unable to marshal cache invalidation: %v", err)
+		return
+	}
+	if err := c.c.Publish(graceful.GetManager().HammerContext(), c.channel, msg).Err(); err != nil {
+		log.Error("Unable to publish cache invalidation of %q: %v", key, err)
+	}
+}
+
+// handleInvalidation removes the local copies named by a message of another instance
+func (c *TwoTierCache) handleInvalidation(payload string) {
+	var msg twoTierInvalidation
+	if err := json.Unmarshal([]byte(payload), &msg); err != nil {
+		log.Error("Unable to unmarshal cache invalidation %q: %v", payload, err)
+		return
+	}
+	if msg.Origin == c.origin {
+		return
+	}
+	if msg.Flush {
+		c.local.Purge()
+		return
+	}
+	c.local.Remove(msg.Key)
+}
+
+func (c *TwoTierCache) subscribe() {
+	ctx := graceful.GetManager().ShutdownContext()
+	pubsub := c.c.Subscribe(ctx, c.channel)
+	defer pubsub.Close()
+
+	ch := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case msg, ok := <-ch:
+			if !ok {
+				return
+			}
+			c.handleInvalidation(msg.Payload)
+		}
+	}
+}
+
+// Put puts value into redis and the local cache with key and expire time.
+func (c *TwoTierCache) Put(key string, val interface{}, expire int64) error {
+	if err := c.RedisCacher.Put(key, val, expire); err != nil {
+		return err
+	}
+	c.invalidate(key, false)
+	// redis returns every value as string, so keep the same in the local cache
+	c.putLocal(key, toStr(val), expire)
+	return nil
+}
+
+// Get gets cached value by given key, from the local cache if possible.
+func (c *TwoTierCache) Get(key string) interface{} {
+	if val, ok := c.getLocal(key); ok {
+		return val
+	}
+	val := c.RedisCacher.Get(key)
+	if val == nil {
+		return nil
+	}
+	expire := int64(0)
+	if ttl, err := c.c.TTL(graceful.GetManager().HammerContext(), c.prefix+key).Result(); err == nil && ttl > 0 {
+		expire = int64(ttl.Seconds())
+	}
+	c.putLocal(key, val, expire)
+	return val
+}
+
+// Delete deletes cached value by given key.
+func (c *TwoTierCache) Delete(key string) error {
+	if err := c.RedisCacher.Delete(key); err != nil {
+		return err
+	}
+	c.invalidate(key, false)
+	return nil
+}
+
+// Incr increases cached int-type value by given key as a counter.
+func (c *TwoTierCache) Incr(key string) error {
+	if err := c.RedisCacher.Incr(key); err != nil {
+		return err
+	}
+	c.invalidate(key, false)
+	return nil
+}
+
+// Decr decreases cached int-type value by given key as a counter.
+func (c *TwoTierCache) Decr(key string) error {
+	if err := c.RedisCacher.Decr(key); err != nil {
+		return err
+	}
+	c.invalidate(key, false)
+	return nil
+}
+
+// IsExist returns true if cached value exists.
+func (c *TwoTierCache) IsExist(key string) bool {
+	if _, ok := c.getLocal(key); ok {
+		return true
+	}
+	return c.RedisCacher.IsExist(key)
+}
+
+// Flush deletes all cached data.
+func (c *TwoTierCache) Flush() error {
+	if err := c.RedisCacher.Flush(); err != nil {
+		return err
+	}
+	c.invalidate("", true)
+	return nil
+}
+
+// StartAndGC starts GC routine based on config string settings.
+// AdapterConfig is the same as for the redis adapter with the additional options:
+// local_size=10000,local_ttl=60,channel=gitea:cache:invalidate
+func (c *TwoTierCache) StartAndGC(opts mc.Options) error {
+	size := 10000
+	c.localTTL = 60
+	c.channel = "gitea:cache:invalidate"
+
+	query := nosql.ToRedisURI(opts.AdapterConfig).Query()
+	var err error
+	if v := query.Get("local_size"); v != "" {
+		if size, err = strconv.Atoi(v); err != nil {
+			return err
+		}
+	}
+	if v := query.Get("local_ttl"); v != "" {
+		if c.localTTL, err = strconv.ParseInt(v, 10, 64); err != nil {
+			return err
+		}
+	}
+	if v := query.Get("channel"); v != "" {
+		c.channel = v
+	}
+
+	if c.local, err = lru.New(size); err != nil {
+		return err
+	}
+	if c.origin, err = util.CryptoRandomString(16); err != nil {
+		return err
+	}
+	if err = c.RedisCacher.StartAndGC(opts); err != nil {
+		return err
+	}
+	go c.subscribe()
+	return nil
+}
+
+func init() {
+	mc.Register("twotier", &TwoTierCache{})
+}
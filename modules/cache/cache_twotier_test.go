@@ -0,0 +1,75 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+
+	lru "github.com/hashicorp/golang-lru"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTwoTierCache_Local(t *testing.T) {
+	local, err := lru.New(2)
+	assert.NoError(t, err)
+	c := &TwoTierCache{local: local, localTTL: 60, origin: "self"}
+
+	c.putLocal("a", "1", 0)
+	c.putLocal("b", "2", 0)
+	val, ok := c.getLocal("a")
+	assert.True(t, ok)
+	assert.Equal(t, "1", val)
+
+	// the least recently used item is evicted
+	c.putLocal("c", "3", 0)
+	_, ok = c.getLocal("b")
+	assert.False(t, ok)
+
+	// items expire with the shorter of the local ttl and their own expiry
+	c.putLocal("d", "4", 0)
+	item, _ := c.local.Peek("d")
+	assert.EqualValues(t, 60, item.(*MemoryItem).Timeout)
+	c.putLocal("d", "4", 10)
+	item, _ = c.local.Peek("d")
+	assert.EqualValues(t, 10, item.(*MemoryItem).Timeout)
+	item.(*MemoryItem).Created -= 10
+	_, ok = c.getLocal("d")
+	assert.False(t, ok)
+}
+
+func TestTwoTierCache_HandleInvalidation(t *testing.T) {
+	local, err := lru.New(10)
+	assert.NoError(t, err)
+	c := &TwoTierCache{local: local, localTTL: 60, origin: "self"}
+
+	invalidation := func(msg twoTierInvalidation) string {
+		payload, err := json.Marshal(&msg)
+		assert.NoError(t, err)
+		return string(payload)
+	}
+
+	c.putLocal("a", "1", 0)
+	c.putLocal("b", "2", 0)
+
+	// messages of this instance are ignored
+	c.handleInvalidation(invalidation(twoTierInvalidation{Origin: "self", Key: "a"}))
+	_, ok := c.getLocal("a")
+	assert.True(t, ok)
+
+	c.handleInvalidation(invalidation(twoTierInvalidation{Origin: "other", Key: "a"}))
+	_, ok = c.getLocal("a")
+	assert.False(t, ok)
+	_, ok = c.getLocal("b")
+	assert.True(t, ok)
+
+	c.handleInvalidation("not json")
+	_, ok = c.getLocal("b")
+	assert.True(t, ok)
+
+	c.handleInvalidation(invalidation(twoTierInvalidation{Origin: "other", Flush: true}))
+	assert.Zero(t, c.local.Len())
+}
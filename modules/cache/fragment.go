@@ -0,0 +1,65 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Kinds of the entities shown by cached fragments
+const (
+	FragmentRepo = "repo"
+	FragmentUser = "user"
+)
+
+func fragmentVersionKey(kind string, id int64) string {
+	return fmt.Sprintf("fragment_version.%s_%d", kind, id)
+}
+
+// FragmentVersion returns the version of the fragments showing an entity, which is part of the keys of the fragments.
+// A new version is started whenever InvalidateFragments is called for the entity.
+func FragmentVersion(kind string, id int64) int64 {
+	if conn == nil || !setting.CacheService.Fragment.Enabled {
+		return 0
+	}
+	// versions are never reused, so fragments of an evicted version can't be returned again
+	version, err := GetInt64(fragmentVersionKey(kind, id), func() (int64, error) {
+		return time.Now().UnixNano(), nil
+	})
+	if err != nil {
+		log.Error("Unable to get fragment version of %s %d: %v", kind, id, err)
+		return time.Now().UnixNano()
+	}
+	return version
+}
+
+// InvalidateFragments invalidates all cached fragments showing an entity
+func InvalidateFragments(kind string, id int64) {
+	Remove(fragmentVersionKey(kind, id))
+}
+
+// GetFragment returns the fragment cached under key, it is rendered with getFunc and cached if it doesn't exist.
+// The key must contain the versions of all entities shown by the fragment.
+func GetFragment(key string, getFunc func() (string, error)) (string, error) {
+	if conn == nil || !setting.CacheService.Fragment.Enabled || setting.CacheService.Fragment.TTL <= 0 {
+		return getFunc()
+	}
+	key = "fragment." + key
+	if v, ok := conn.Get(key).(string); ok {
+		return v, nil
+	}
+	value, err := getFunc()
+	if err != nil {
+		return "", err
+	}
+	if err := conn.Put(key, value, setting.FragmentCacheTTLSeconds()); err != nil {
+		log.Error("Unable to cache fragment %s: %v", key, err)
+	}
+	return value, nil
+}
@@ -0,0 +1,55 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cache
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFragments(t *testing.T) {
+	createTestCache()
+	oldTTL := setting.CacheService.TTL
+	setting.CacheService.TTL = time.Minute
+	defer func() {
+		setting.CacheService.TTL = oldTTL
+	}()
+
+	version := FragmentVersion(FragmentRepo, 1)
+	assert.NotZero(t, version)
+	assert.Equal(t, version, FragmentVersion(FragmentRepo, 1))
+	assert.NotEqual(t, version, FragmentVersion(FragmentUser, 1))
+
+	renders := 0
+	render := func() (string, error) {
+		renders++
+		return fmt.Sprintf("fragment %d", renders), nil
+	}
+	key := fmt.Sprintf("test.repo_1.%d", version)
+
+	fragment, err := GetFragment(key, render)
+	assert.NoError(t, err)
+	assert.Equal(t, "fragment 1", fragment)
+	fragment, err = GetFragment(key, render)
+	assert.NoError(t, err)
+	assert.Equal(t, "fragment 1", fragment)
+
+	_, err = GetFragment("test.error", func() (string, error) {
+		return "", fmt.Errorf("some error")
+	})
+	assert.Error(t, err)
+
+	InvalidateFragments(FragmentRepo, 1)
+	newVersion := FragmentVersion(FragmentRepo, 1)
+	assert.NotEqual(t, version, newVersion)
+	fragment, err = GetFragment(fmt.Sprintf("test.repo_1.%d", newVersion), render)
+	assert.NoError(t, err)
+	assert.Equal(t, "fragment 2", fragment)
+}
@@ -39,6 +39,12 @@ func Toggle(options *ToggleOptions) func(ctx *Context) {
 				return
 			}
 
+			// suspended users can look around but not change anything, git and LFS requests are checked by their handlers
+			if ctx.Doer.IsSuspended && !options.DisableCSRF && !isReadOnlyRequest(ctx.Req) && ctx.Req.URL.Path != "/user/logout" {
+				ctx.Suspended(ctx.Doer)
+				return
+			}
+
 			if ctx.Doer.MustChangePassword {
 				if ctx.Req.URL.Path != "/user/settings/change_password" {
 					ctx.Data["Title"] = ctx.Tr("auth.must_change_password")
@@ -124,6 +130,13 @@ func ToggleAPI(options *ToggleOptions) func(ctx *APIContext) {
 				return
 			}
 
+			if ctx.Doer.IsSuspended && !isReadOnlyRequest(ctx.Req) {
+				ctx.JSON(http.StatusForbidden, map[string]string{
+					"message": "This account has been suspended, please contact your site administrator.",
+				})
+				return
+			}
+
 			if ctx.Doer.MustChangePassword {
 				ctx.JSON(http.StatusForbidden, map[string]string{
 					"message": "You must change your password. Change it at: " + setting.AppURL + "/user/change_password",
@@ -188,3 +201,7 @@ func ToggleAPI(options *ToggleOptions) func(ctx *APIContext) {
 		}
 	}
 }
+
+func isReadOnlyRequest(req *http.Request) bool {
+	return req.Method == http.MethodGet || req.Method == http.MethodHead || req.Method == http.MethodOptions
+}
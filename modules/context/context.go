@@ -239,6 +239,28 @@ func (ctx *Context) RenderToString(name base.TplName, data map[string]interface{
 	return buf.String(), err
 }
 
+// RenderFragment returns the fragment rendered from the template name, it is cached under key for the language of the user.
+// The template gets the data of the context and the values returned by data, which is only called if the fragment isn't cached.
+// The key must contain the versions of all entities shown by the fragment, see cache.FragmentVersion.
+func (ctx *Context) RenderFragment(name base.TplName, key string, data func() (map[string]interface{}, error)) (template.HTML, error) {
+	fragment, err := mc.GetFragment(fmt.Sprintf("%s.%s.%s", name, ctx.Locale.Language(), key), func() (string, error) {
+		values, err := data()
+		if err != nil {
+			return "", err
+		}
+		fragmentData := make(map[string]interface{}, len(ctx.Data)+len(values))
+		for k, v := range ctx.Data {
+			fragmentData[k] = v
+		}
+		for k, v := range values {
+			fragmentData[k] = v
+		}
+		fragment, err := ctx.RenderToString(name, fragmentData)
+		return strings.TrimSpace(fragment), err
+	})
+	return template.HTML(fragment), err
+}
+
 // RenderWithErr used for page has form validation but need to prompt error to users.
 func (ctx *Context) RenderWithErr(msg string, tpl base.TplName, form interface{}) {
 	if form != nil {
@@ -595,6 +617,12 @@ func (ctx *Context) SetTotalCountHeader(total int64) {
 	ctx.AppendAccessControlExposeHeaders("X-Total-Count")
 }
 
+// SetNoIndex asks search engines not to index the page with the "X-Robots-Tag" header and the robots meta tag
+func (ctx *Context) SetNoIndex() {
+	ctx.RespHeader().Set("X-Robots-Tag", "noindex")
+	ctx.Data["NoIndex"] = true
+}
+
 // AppendAccessControlExposeHeaders append headers by name to "Access-Control-Expose-Headers" header
 func (ctx *Context) AppendAccessControlExposeHeaders(names ...string) {
 	val := ctx.RespHeader().Get("Access-Control-Expose-Headers")
@@ -786,6 +814,8 @@ func Contexter() func(next http.Handler) http.Handler {
 			ctx.Data["EnableOpenIDSignIn"] = setting.Service.EnableOpenIDSignIn
 			ctx.Data["DisableMigrations"] = setting.Repository.DisableMigrations
 			ctx.Data["DisableStars"] = setting.Repository.DisableStars
+			ctx.Data["EnableActions"] = setting.Actions.Enabled
+			ctx.Data["EnableAccessRequests"] = setting.Repository.EnableAccessRequests
 
 			ctx.Data["ManifestData"] = setting.ManifestData
 
@@ -821,6 +851,7 @@ var SearchOrderByMap = map[string]map[string]db.SearchOrderBy{
 		"updated": db.SearchOrderByLeastUpdated,
 		"size":    db.SearchOrderBySize,
 		"id":      db.SearchOrderByID,
+		"pushed":  db.SearchOrderByLeastPushed,
 	},
 	"desc": {
 		"alpha":   db.SearchOrderByAlphabeticallyReverse,
@@ -828,5 +859,6 @@ var SearchOrderByMap = map[string]map[string]db.SearchOrderBy{
 		"updated": db.SearchOrderByRecentUpdated,
 		"size":    db.SearchOrderBySizeReverse,
 		"id":      db.SearchOrderByIDReverse,
+		"pushed":  db.SearchOrderByRecentPushed,
 	},
 }
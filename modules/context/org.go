@@ -71,6 +71,14 @@ func HandleOrgAssignment(ctx *Context, args ...bool) {
 	org := ctx.Org.Organization
 	ctx.ContextUser = org.AsUser()
 	ctx.Data["Org"] = org
+	if org.NoIndex {
+		ctx.SetNoIndex()
+	}
+
+	if ctx.ContextUser.IsSuspendedFor(ctx.Doer) {
+		ctx.Suspended(ctx.ContextUser)
+		return
+	}
 
 	// Admin has super access.
 	if ctx.IsSigned && ctx.Doer.IsAdmin {
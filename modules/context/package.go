@@ -11,8 +11,10 @@ import (
 	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/auth"
 )
 
 // Package contains owner, access mode and optional the package descriptor
@@ -98,6 +100,15 @@ func packageAssignment(ctx *Context, errCb func(int, string, interface{})) {
 			return
 		}
 	}
+
+	// Deploy tokens grant read access to the packages linked to their repository, so they only apply
+	// to requests for a single package version
+	if ctx.Doer == nil && ctx.Package.AccessMode < perm.AccessModeRead && ctx.Package.Descriptor != nil {
+		if token := auth.DeployTokenFromRequest(ctx.Req, ctx.Data); token != nil && token.HasScope(repo_model.DeployTokenScopeReadPackage) &&
+			ctx.Package.Descriptor.Package.RepoID == token.RepoID {
+			ctx.Package.AccessMode = perm.AccessModeRead
+		}
+	}
 }
 
 // PackageContexter initializes a package context for a request.
@@ -5,6 +5,7 @@
 package context
 
 import (
+	perm_model "code.gitea.io/gitea/models/perm"
 	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/log"
 )
@@ -29,6 +30,16 @@ func RequireRepoWriter(unitType unit.Type) func(ctx *Context) {
 	}
 }
 
+// RequireReleaseAction returns a middleware for requiring write access to releases which allows the action
+func RequireReleaseAction(action perm_model.ReleaseAction) func(ctx *Context) {
+	return func(ctx *Context) {
+		if !ctx.Repo.CanDoReleaseAction(action) {
+			ctx.NotFound(ctx.Req.URL.RequestURI(), nil)
+			return
+		}
+	}
+}
+
 // CanEnableEditor checks if the user is allowed to write to the branch of the repo
 func CanEnableEditor() func(ctx *Context) {
 	return func(ctx *Context) {
@@ -117,7 +117,7 @@ type CanCommitToBranchResults struct {
 // CanCommitToBranch returns true if repository is editable and user has proper access level
 //   and branch is not protected for push
 func (r *Repository) CanCommitToBranch(ctx context.Context, doer *user_model.User) (CanCommitToBranchResults, error) {
-	protectedBranch, err := models.GetProtectedBranchBy(ctx, r.Repository.ID, r.BranchName)
+	protectedBranch, err := models.GetEffectiveProtectedBranch(ctx, r.Repository.ID, r.BranchName)
 	if err != nil {
 		return CanCommitToBranchResults{}, err
 	}
@@ -449,6 +449,12 @@ func RepoAssignment(ctx *Context) (cancel context.CancelFunc) {
 	repoName = strings.TrimSuffix(repoName, ".rss")
 	repoName = strings.TrimSuffix(repoName, ".atom")
 
+	// Let the "not found" page offer to request access, whether the repository exists or not
+	if setting.Repository.EnableAccessRequests && ctx.IsSigned {
+		ctx.Data["AccessRequestOwner"] = userName
+		ctx.Data["AccessRequestRepo"] = repoName
+	}
+
 	// Check if the user is the same as the repository owner
 	if ctx.IsSigned && ctx.Doer.LowerName == strings.ToLower(userName) {
 		owner = ctx.Doer
@@ -471,6 +477,11 @@ func RepoAssignment(ctx *Context) (cancel context.CancelFunc) {
 	ctx.ContextUser = owner
 	ctx.Data["Username"] = ctx.Repo.Owner.Name
 
+	if owner.IsSuspendedFor(ctx.Doer) {
+		ctx.Suspended(owner)
+		return
+	}
+
 	// redirect link to wiki
 	if strings.HasSuffix(repoName, ".wiki") {
 		// ctx.Req.URL.Path does not have the preceding appSubURL - any redirect must have this added
@@ -518,6 +529,8 @@ func RepoAssignment(ctx *Context) (cancel context.CancelFunc) {
 	if ctx.Written() {
 		return
 	}
+	delete(ctx.Data, "AccessRequestOwner")
+	delete(ctx.Data, "AccessRequestRepo")
 
 	ctx.Repo.RepoLink = repo.Link()
 	ctx.Data["RepoLink"] = ctx.Repo.RepoLink
@@ -528,21 +541,14 @@ func RepoAssignment(ctx *Context) (cancel context.CancelFunc) {
 		ctx.Data["RepoExternalIssuesLink"] = unit.ExternalTrackerConfig().ExternalTrackerURL
 	}
 
-	ctx.Data["NumTags"], err = models.GetReleaseCountByRepoID(ctx.Repo.Repository.ID, models.FindReleasesOptions{
-		IncludeTags: true,
-	})
-	if err != nil {
-		ctx.ServerError("GetReleaseCountByRepoID", err)
-		return
-	}
-	ctx.Data["NumReleases"], err = models.GetReleaseCountByRepoID(ctx.Repo.Repository.ID, models.FindReleasesOptions{})
-	if err != nil {
-		ctx.ServerError("GetReleaseCountByRepoID", err)
-		return
-	}
+	ctx.Data["NumTags"] = repo.NumTags
+	ctx.Data["NumReleases"] = repo.NumReleases
 
 	ctx.Data["Title"] = owner.Name + "/" + repo.Name
 	ctx.Data["Repository"] = repo
+	if repo.NoIndex || owner.NoIndex {
+		ctx.SetNoIndex()
+	}
 	ctx.Data["Owner"] = ctx.Repo.Repository.Owner
 	ctx.Data["IsRepositoryOwner"] = ctx.Repo.IsOwner()
 	ctx.Data["IsRepositoryAdmin"] = ctx.Repo.IsAdmin()
@@ -1028,6 +1034,7 @@ func UnitTypes() func(ctx *Context) {
 		ctx.Data["UnitTypeExternalTracker"] = unit_model.TypeExternalTracker
 		ctx.Data["UnitTypeProjects"] = unit_model.TypeProjects
 		ctx.Data["UnitTypePackages"] = unit_model.TypePackages
+		ctx.Data["UnitTypeDiscussions"] = unit_model.TypeDiscussions
 	}
 }
 
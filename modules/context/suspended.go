@@ -0,0 +1,28 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package context
+
+import (
+	"net/http"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+)
+
+const tplSuspended base.TplName = "status/suspended"
+
+// Suspended renders the notice shown instead of the content of a suspended user or organization
+func (ctx *Context) Suspended(owner *user_model.User) {
+	notice, err := user_model.GetSuspensionNotice(owner)
+	if err != nil {
+		ctx.ServerError("GetSuspensionNotice", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("error.suspended_title")
+	ctx.Data["SuspendedOwner"] = owner
+	ctx.Data["SuspensionNotice"] = notice
+	ctx.HTML(http.StatusForbidden, tplSuspended)
+}
@@ -0,0 +1,82 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"fmt"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+func optionalTime(ts timeutil.TimeStamp) *time.Time {
+	if ts.IsZero() {
+		return nil
+	}
+	return ts.AsTimePtr()
+}
+
+// ToActionRun converts an actions_model.ActionRun to api.ActionRun, the attributes of the run have to be loaded
+func ToActionRun(run *actions_model.ActionRun, doer *user_model.User) *api.ActionRun {
+	return &api.ActionRun{
+		ID:           run.ID,
+		Index:        run.Index,
+		Title:        run.Title,
+		WorkflowID:   run.WorkflowID,
+		WorkflowName: run.WorkflowName,
+		Event:        run.Event,
+		Ref:          run.Ref,
+		CommitSHA:    run.CommitSHA,
+		Status:       run.Status.String(),
+		TriggerUser:  ToUser(run.TriggerUser, doer),
+		HTMLURL:      run.HTMLURL(),
+		Created:      run.Created.AsTime(),
+		Started:      optionalTime(run.Started),
+		Stopped:      optionalTime(run.Stopped),
+	}
+}
+
+// ToActionRunJob converts an actions_model.ActionRunJob to api.ActionRunJob
+func ToActionRunJob(job *actions_model.ActionRunJob) *api.ActionRunJob {
+	return &api.ActionRunJob{
+		ID:        job.ID,
+		RunID:     job.RunID,
+		JobID:     job.JobID,
+		Name:      job.Name,
+		Needs:     job.Needs,
+		RunsOn:    job.RunsOn,
+		Status:    job.Status.String(),
+		RunnerID:  job.RunnerID,
+		LogLength: job.LogLength,
+		Started:   optionalTime(job.Started),
+		Stopped:   optionalTime(job.Stopped),
+	}
+}
+
+// ToActionArtifact converts an actions_model.ActionArtifact of the run to api.ActionArtifact
+func ToActionArtifact(run *actions_model.ActionRun, artifact *actions_model.ActionArtifact) *api.ActionArtifact {
+	return &api.ActionArtifact{
+		ID:          artifact.ID,
+		JobID:       artifact.JobID,
+		Name:        artifact.Name,
+		Size:        artifact.FileSize,
+		DownloadURL: fmt.Sprintf("%s/artifacts/%d", run.HTMLURL(), artifact.ID),
+		Created:     artifact.Created.AsTime(),
+	}
+}
+
+// ToActionRunner converts an actions_model.ActionRunner to api.ActionRunner
+func ToActionRunner(runner *actions_model.ActionRunner) *api.ActionRunner {
+	return &api.ActionRunner{
+		ID:         runner.ID,
+		Name:       runner.Name,
+		Labels:     runner.Labels,
+		Online:     runner.IsOnline(),
+		LastOnline: optionalTime(runner.LastOnline),
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToAttachmentUsage converts repo_model.RepoAttachmentUsage to api.AttachmentUsage,
+// repo is nil if the repository doesn't exist anymore
+func ToAttachmentUsage(usage *repo_model.RepoAttachmentUsage, repo *repo_model.Repository) *api.AttachmentUsage {
+	apiUsage := &api.AttachmentUsage{
+		RepoID:        usage.RepoID,
+		Count:         usage.Count,
+		Size:          usage.Size,
+		ColdCount:     usage.ColdCount,
+		ColdSize:      usage.ColdSize,
+		OrphanedCount: usage.OrphanedCount,
+		OrphanedSize:  usage.OrphanedSize,
+		MaxSize:       setting.Attachment.MaxRepoSize,
+	}
+	if repo != nil {
+		apiUsage.RepoFullName = repo.FullName()
+	}
+	return apiUsage
+}
@@ -0,0 +1,52 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToCheckRun converts a models.CheckRun to api.CheckRun, the attributes of the run have to be loaded
+func ToCheckRun(run *models.CheckRun, annotationsCount int64, doer *user_model.User) *api.CheckRun {
+	apiRun := &api.CheckRun{
+		ID:         run.ID,
+		HeadSHA:    run.SHA,
+		Name:       run.Name,
+		Status:     string(run.Status),
+		Conclusion: string(run.Conclusion),
+		DetailsURL: run.DetailsURL,
+		ExternalID: run.ExternalID,
+		HTMLURL:    run.HTMLURL(),
+		Attempt:    run.Attempt,
+		Output: &api.CheckRunOutput{
+			Title:            run.Title,
+			Summary:          run.Summary,
+			Text:             run.Text,
+			AnnotationsCount: annotationsCount,
+		},
+		Started:   optionalTime(run.StartedUnix),
+		Completed: optionalTime(run.CompletedUnix),
+		Created:   run.CreatedUnix.AsTime(),
+		Updated:   run.UpdatedUnix.AsTime(),
+	}
+	if run.Creator != nil {
+		apiRun.Creator = ToUser(run.Creator, doer)
+	}
+	return apiRun
+}
+
+// ToCheckRunAnnotation converts a models.CheckRunAnnotation to api.CheckRunAnnotation
+func ToCheckRunAnnotation(annotation *models.CheckRunAnnotation) *api.CheckRunAnnotation {
+	return &api.CheckRunAnnotation{
+		Path:            annotation.Path,
+		StartLine:       annotation.StartLine,
+		EndLine:         annotation.EndLine,
+		AnnotationLevel: string(annotation.Level),
+		Title:           annotation.Title,
+		Message:         annotation.Message,
+	}
+}
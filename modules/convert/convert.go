@@ -12,6 +12,7 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
@@ -19,6 +20,7 @@ import (
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
@@ -94,6 +96,20 @@ func ToBranch(repo *repo_model.Repository, b *git.Branch, c *git.Commit, bp *mod
 	return branch, nil
 }
 
+// ToDeletedBranch convert a models.DeletedBranch to an api.DeletedBranch
+func ToDeletedBranch(deletedBranch *models.DeletedBranch, doer *user_model.User) *api.DeletedBranch {
+	if deletedBranch.DeletedBy == nil {
+		deletedBranch.LoadUser()
+	}
+	return &api.DeletedBranch{
+		ID:        deletedBranch.ID,
+		Name:      deletedBranch.Name,
+		CommitID:  deletedBranch.Commit,
+		DeletedBy: ToUser(deletedBranch.DeletedBy, doer),
+		Deleted:   deletedBranch.DeletedUnix.AsTime(),
+	}
+}
+
 // ToBranchProtection convert a ProtectedBranch to api.BranchProtection
 func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 	pushWhitelistUsernames, err := user_model.GetUserNamesByIDs(bp.WhitelistUserIDs)
@@ -149,6 +165,25 @@ func ToBranchProtection(bp *models.ProtectedBranch) *api.BranchProtection {
 	}
 }
 
+// ToOrgBranchProtection convert an OrgProtectedBranch to api.OrgBranchProtection
+func ToOrgBranchProtection(rule *models.OrgProtectedBranch) *api.OrgBranchProtection {
+	return &api.OrgBranchProtection{
+		ID:                     rule.ID,
+		BranchPattern:          rule.BranchPattern,
+		ApplyToExisting:        rule.ApplyToExisting,
+		AllowOverride:          rule.AllowOverride,
+		EnablePush:             rule.CanPush,
+		EnableStatusCheck:      rule.EnableStatusCheck,
+		StatusCheckContexts:    rule.StatusCheckContexts,
+		RequiredApprovals:      rule.RequiredApprovals,
+		BlockOnRejectedReviews: rule.BlockOnRejectedReviews,
+		DismissStaleApprovals:  rule.DismissStaleApprovals,
+		RequireSignedCommits:   rule.RequireSignedCommits,
+		Created:                rule.CreatedUnix.AsTime(),
+		Updated:                rule.UpdatedUnix.AsTime(),
+	}
+}
+
 // ToTag convert a git.Tag to an api.Tag
 func ToTag(repo *repo_model.Repository, t *git.Tag) *api.Tag {
 	return &api.Tag{
@@ -162,11 +197,18 @@ func ToTag(repo *repo_model.Repository, t *git.Tag) *api.Tag {
 }
 
 // ToVerification convert a git.Commit.Signature to an api.PayloadCommitVerification
-func ToVerification(c *git.Commit) *api.PayloadCommitVerification {
+// with the trust status of the signature in the repository
+func ToVerification(repo *repo_model.Repository, c *git.Commit) *api.PayloadCommitVerification {
 	verif := asymkey_model.ParseCommitWithSignature(c)
+	if err := asymkey_model.CalculateTrustStatus(verif, repo.GetTrustModel(), func(user *user_model.User) (bool, error) {
+		return models.IsOwnerMemberCollaborator(repo, user.ID)
+	}, nil); err != nil {
+		log.Error("CalculateTrustStatus: %v", err)
+	}
 	commitVerification := &api.PayloadCommitVerification{
-		Verified: verif.Verified,
-		Reason:   verif.Reason,
+		Verified:    verif.Verified,
+		TrustStatus: verif.TrustStatus,
+		Reason:      verif.Reason,
 	}
 	if c.Signature != nil {
 		commitVerification.Signature = c.Signature.Signature
@@ -255,14 +297,19 @@ func ToHook(repoLink string, w *webhook.Webhook) *api.Hook {
 	}
 
 	return &api.Hook{
-		ID:      w.ID,
-		Type:    string(w.Type),
-		URL:     fmt.Sprintf("%s/settings/hooks/%d", repoLink, w.ID),
-		Active:  w.IsActive,
-		Config:  config,
-		Events:  w.EventsArray(),
-		Updated: w.UpdatedUnix.AsTime(),
-		Created: w.CreatedUnix.AsTime(),
+		ID:               w.ID,
+		Type:             string(w.Type),
+		URL:              fmt.Sprintf("%s/settings/hooks/%d", repoLink, w.ID),
+		Active:           w.IsActive,
+		Config:           config,
+		Events:           w.EventsArray(),
+		PayloadFilter:    w.PayloadFilter,
+		IsTemplate:       w.IsTemplate,
+		IsLocked:         w.IsLocked,
+		TemplateID:       w.TemplateID,
+		GitHubCompatible: w.IsGitHubCompatible,
+		Updated:          w.UpdatedUnix.AsTime(),
+		Created:          w.CreatedUnix.AsTime(),
 	}
 }
 
@@ -278,14 +325,15 @@ func ToGitHook(h *git.Hook) *api.GitHook {
 // ToDeployKey convert asymkey_model.DeployKey to api.DeployKey
 func ToDeployKey(apiLink string, key *asymkey_model.DeployKey) *api.DeployKey {
 	return &api.DeployKey{
-		ID:          key.ID,
-		KeyID:       key.KeyID,
-		Key:         key.Content,
-		Fingerprint: key.Fingerprint,
-		URL:         fmt.Sprintf("%s%d", apiLink, key.ID),
-		Title:       key.Name,
-		Created:     key.CreatedUnix.AsTime(),
-		ReadOnly:    key.Mode == perm.AccessModeRead, // All deploy keys are read-only.
+		ID:           key.ID,
+		KeyID:        key.KeyID,
+		Key:          key.Content,
+		Fingerprint:  key.Fingerprint,
+		URL:          fmt.Sprintf("%s%d", apiLink, key.ID),
+		Title:        key.Name,
+		Created:      key.CreatedUnix.AsTime(),
+		ReadOnly:     key.Mode == perm.AccessModeRead, // All deploy keys are read-only.
+		AllowedCIDRs: key.AllowedCIDRs,
 	}
 }
 
@@ -335,6 +383,9 @@ func ToTeams(teams []*organization.Team, loadOrgs bool) ([]*api.Team, error) {
 			Permission:              teams[i].AccessMode.String(),
 			Units:                   teams[i].GetUnitNames(),
 			UnitsMap:                teams[i].GetUnitsMap(),
+			CanCreateReleases:       teams[i].CanDoReleaseAction(perm.ReleaseActionCreate),
+			CanEditReleases:         teams[i].CanDoReleaseAction(perm.ReleaseActionEdit),
+			CanUploadReleaseAssets:  teams[i].CanDoReleaseAction(perm.ReleaseActionUploadAssets),
 		}
 
 		if loadOrgs {
@@ -362,7 +413,7 @@ func ToAnnotatedTag(repo *repo_model.Repository, t *git.Tag, c *git.Commit) *api
 		Message:      t.Message,
 		URL:          util.URLJoin(repo.APIURL(), "git/tags", t.ID.String()),
 		Tagger:       ToCommitUser(t.Tagger),
-		Verification: ToVerification(c),
+		Verification: ToVerification(repo, c),
 	}
 }
 
@@ -413,3 +464,44 @@ func ToLFSLock(l *models.LFSLock) *api.LFSLock {
 		},
 	}
 }
+
+// ToSecret convert a secret_model.Secret to api.Secret
+func ToSecret(s *secret_model.Secret) *api.Secret {
+	return &api.Secret{
+		Name:    s.Name,
+		Created: s.CreatedUnix.AsTime(),
+		Updated: s.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToAnnouncement convert an admin_model.Announcement to api.Announcement
+func ToAnnouncement(a *admin_model.Announcement) *api.Announcement {
+	var end *time.Time
+	if a.EndUnix != 0 {
+		end = a.EndUnix.AsTimePtr()
+	}
+	return &api.Announcement{
+		ID:          a.ID,
+		Content:     a.Content,
+		Severity:    string(a.Severity),
+		Dismissible: a.Dismissible,
+		Start:       a.StartUnix.AsTime(),
+		End:         end,
+		Created:     a.CreatedUnix.AsTime(),
+		Updated:     a.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToVariable convert a secret_model.Secret of type variable to api.Variable
+func ToVariable(s *secret_model.Secret) (*api.Variable, error) {
+	value, err := s.Value()
+	if err != nil {
+		return nil, err
+	}
+	return &api.Variable{
+		Name:    s.Name,
+		Value:   value,
+		Created: s.CreatedUnix.AsTime(),
+		Updated: s.UpdatedUnix.AsTime(),
+	}, nil
+}
@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	repo_model "code.gitea.io/gitea/models/repo"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToDeployToken converts a repo_model.DeployToken to api.DeployToken, the token is only set right after creation
+func ToDeployToken(t *repo_model.DeployToken) *api.DeployToken {
+	scopes := t.ScopeList()
+	apiToken := &api.DeployToken{
+		ID:             t.ID,
+		Name:           t.Name,
+		Token:          t.Token,
+		TokenLastEight: t.TokenLastEight,
+		Scopes:         make([]string, 0, len(scopes)),
+		Created:        t.CreatedUnix.AsTime(),
+	}
+	for _, scope := range scopes {
+		apiToken.Scopes = append(apiToken.Scopes, string(scope))
+	}
+	if t.ExpiresUnix > 0 {
+		apiToken.Expires = t.ExpiresUnix.AsTimePtr()
+	}
+	return apiToken
+}
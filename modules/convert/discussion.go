@@ -0,0 +1,56 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"fmt"
+
+	discussion_model "code.gitea.io/gitea/models/discussion"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToDiscussionCategory converts a discussion_model.Category to api.DiscussionCategory
+func ToDiscussionCategory(c *discussion_model.Category) *api.DiscussionCategory {
+	return &api.DiscussionCategory{
+		ID:             c.ID,
+		Name:           c.Name,
+		Description:    c.Description,
+		IsAnswerable:   c.IsAnswerable,
+		NumDiscussions: c.NumDiscussions,
+	}
+}
+
+// ToDiscussion converts a discussion_model.Discussion to api.Discussion, the attributes of the discussion have to be loaded
+func ToDiscussion(d *discussion_model.Discussion, doer *user_model.User) *api.Discussion {
+	return &api.Discussion{
+		ID:       d.ID,
+		Index:    d.Index,
+		Title:    d.Title,
+		Body:     d.Content,
+		Category: ToDiscussionCategory(d.Category),
+		Poster:   ToUser(d.Poster, doer),
+		Comments: d.NumComments,
+		AnswerID: d.AnswerID,
+		URL:      d.APIURL(),
+		HTMLURL:  d.HTMLURL(),
+		Created:  d.CreatedUnix.AsTime(),
+		Updated:  d.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToDiscussionComment converts a discussion_model.Comment of the discussion to api.DiscussionComment, the poster has to be loaded
+func ToDiscussionComment(d *discussion_model.Discussion, c *discussion_model.Comment, doer *user_model.User) *api.DiscussionComment {
+	return &api.DiscussionComment{
+		ID:       c.ID,
+		ParentID: c.ParentID,
+		Body:     c.Content,
+		Poster:   ToUser(c.Poster, doer),
+		IsAnswer: d.AnswerID == c.ID,
+		HTMLURL:  fmt.Sprintf("%s#%s", d.HTMLURL(), c.HashTag()),
+		Created:  c.CreatedUnix.AsTime(),
+		Updated:  c.UpdatedUnix.AsTime(),
+	}
+}
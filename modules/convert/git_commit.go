@@ -68,7 +68,7 @@ func ToPayloadCommit(repo *repo_model.Repository, c *git.Commit) *api.PayloadCom
 			UserName: committerUsername,
 		},
 		Timestamp:    c.Author.When,
-		Verification: ToVerification(c),
+		Verification: ToVerification(repo, c),
 	}
 }
 
@@ -183,7 +183,7 @@ func ToCommit(repo *repo_model.Repository, gitRepo *git.Repository, commit *git.
 				SHA:     commit.ID.String(),
 				Created: commit.Committer.When,
 			},
-			Verification: ToVerification(commit),
+			Verification: ToVerification(repo, commit),
 		},
 		Author:    apiAuthor,
 		Committer: apiCommitter,
@@ -196,3 +196,66 @@ func ToCommit(repo *repo_model.Repository, gitRepo *git.Repository, commit *git.
 		},
 	}, nil
 }
+
+// ToContributorStats convert the contributor statistics of a repository to api.ContributorStats
+func ToContributorStats(contributors []*git.ContributorStats, doer *user_model.User) ([]*api.ContributorStats, error) {
+	result := make([]*api.ContributorStats, 0, len(contributors))
+	for _, contributor := range contributors {
+		var apiAuthor *api.User
+		if contributor.Email != "" {
+			author, err := user_model.GetUserByEmail(contributor.Email)
+			if err != nil && !user_model.IsErrUserNotExist(err) {
+				return nil, err
+			} else if err == nil {
+				apiAuthor = ToUser(author, doer)
+			}
+		}
+
+		weeks := make([]*api.ContributorWeek, 0, len(contributor.Weeks))
+		for _, week := range contributor.Weeks {
+			weeks = append(weeks, &api.ContributorWeek{
+				Week:      week.Week,
+				Commits:   week.Commits,
+				Additions: week.Additions,
+				Deletions: week.Deletions,
+			})
+		}
+
+		result = append(result, &api.ContributorStats{
+			Author:    apiAuthor,
+			Name:      contributor.Name,
+			Email:     contributor.Email,
+			Commits:   contributor.Commits,
+			Additions: contributor.Additions,
+			Deletions: contributor.Deletions,
+			Weeks:     weeks,
+		})
+	}
+	return result, nil
+}
+
+// ToCodeFrequency converts the weeks of the code frequency of a repository to their API format
+func ToCodeFrequency(weeks []*git.CodeFrequencyWeek) []*api.CodeFrequencyWeek {
+	result := make([]*api.CodeFrequencyWeek, 0, len(weeks))
+	for _, week := range weeks {
+		result = append(result, &api.CodeFrequencyWeek{
+			Week:      week.Week,
+			Additions: week.Additions,
+			Deletions: week.Deletions,
+		})
+	}
+	return result
+}
+
+// ToPunchCard converts the hours of the punch card of a repository to their API format
+func ToPunchCard(hours []*git.PunchCardHour) []*api.PunchCardHour {
+	result := make([]*api.PunchCardHour, 0, len(hours))
+	for _, hour := range hours {
+		result = append(result, &api.PunchCardHour{
+			Day:     hour.Day,
+			Hour:    hour.Hour,
+			Commits: hour.Commits,
+		})
+	}
+	return result
+}
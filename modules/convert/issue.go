@@ -7,6 +7,7 @@ package convert
 import (
 	"fmt"
 	"net/url"
+	"sort"
 	"strings"
 
 	"code.gitea.io/gitea/models"
@@ -234,3 +235,68 @@ func ToAPIMilestone(m *issues_model.Milestone) *api.Milestone {
 	}
 	return apiMilestone
 }
+
+// ToIssueFilter converts an issue filter to API format
+func ToIssueFilter(f *issues_model.IssueFilter) *api.IssueFilter {
+	return &api.IssueFilter{
+		ID:          f.ID,
+		Name:        f.Name,
+		RepoID:      f.RepoID,
+		Keyword:     f.Keyword,
+		Type:        f.ViewType,
+		Sort:        f.SortType,
+		State:       f.State,
+		Labels:      f.LabelIDs(),
+		MilestoneID: f.MilestoneID,
+		AssigneeID:  f.AssigneeID,
+		PosterID:    f.PosterID,
+		Query:       f.QueryString(),
+		Created:     f.CreatedUnix.AsTime(),
+		Updated:     f.UpdatedUnix.AsTime(),
+	}
+}
+
+// ToIssueDependencyGraph converts an issue dependency graph to its API format
+func ToIssueDependencyGraph(graph *models.IssueDependencyGraph) (*api.IssueDependencyGraph, error) {
+	result := &api.IssueDependencyGraph{
+		IssueID:   graph.Issue.ID,
+		Nodes:     make([]*api.IssueDependencyGraphNode, 0, len(graph.Issues)),
+		Edges:     make([]*api.IssueDependencyGraphEdge, 0, len(graph.Edges)),
+		BlockedBy: graph.BlockedBy,
+		Blocking:  graph.Blocking,
+		Cycles:    graph.Cycles,
+		Truncated: graph.Truncated,
+	}
+
+	issues := make([]*models.Issue, 0, len(graph.Issues))
+	for _, issue := range graph.Issues {
+		issues = append(issues, issue)
+	}
+	sort.Slice(issues, func(i, j int) bool { return issues[i].ID < issues[j].ID })
+	for _, issue := range issues {
+		if err := issue.LoadRepo(db.DefaultContext); err != nil {
+			return nil, err
+		}
+		result.Nodes = append(result.Nodes, &api.IssueDependencyGraphNode{
+			ID:         issue.ID,
+			Index:      issue.Index,
+			Title:      issue.Title,
+			State:      issue.State(),
+			IsPull:     issue.IsPull,
+			Repository: issue.Repo.FullName(),
+			HTMLURL:    issue.HTMLURL(),
+		})
+	}
+
+	for _, edge := range graph.Edges {
+		result.Edges = append(result.Edges, &api.IssueDependencyGraphEdge{
+			IssueID:      edge.IssueID,
+			DependencyID: edge.DependencyID,
+		})
+	}
+	if result.Cycles == nil {
+		result.Cycles = [][]int64{}
+	}
+
+	return result, nil
+}
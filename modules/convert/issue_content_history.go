@@ -0,0 +1,54 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	issues_model "code.gitea.io/gitea/models/issues"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// ToContentHistory converts an issues_model.ContentHistory to the api.ContentHistory format
+func ToContentHistory(history *issues_model.ContentHistory, editor, doer *user_model.User) *api.ContentHistory {
+	return &api.ContentHistory{
+		ID:             history.ID,
+		Editor:         ToUser(editor, doer),
+		IsFirstCreated: history.IsFirstCreated,
+		IsDeleted:      history.IsDeleted,
+		Edited:         history.EditedUnix.AsTime(),
+	}
+}
+
+// ToContentHistoryDetail converts an issues_model.ContentHistory and its changes since the previous revision
+// to the api.ContentHistoryDetail format
+func ToContentHistoryDetail(history, prevHistory *issues_model.ContentHistory, diff []diffmatchpatch.Diff, editor, doer *user_model.User) *api.ContentHistoryDetail {
+	detail := &api.ContentHistoryDetail{
+		ID:             history.ID,
+		Editor:         ToUser(editor, doer),
+		IsFirstCreated: history.IsFirstCreated,
+		IsDeleted:      history.IsDeleted,
+		Content:        history.ContentText,
+		Edited:         history.EditedUnix.AsTime(),
+		Changes:        make([]*api.ContentHistoryChange, 0, len(diff)),
+	}
+	if prevHistory != nil {
+		detail.PreviousID = prevHistory.ID
+	}
+	for _, it := range diff {
+		change := &api.ContentHistoryChange{Text: it.Text}
+		switch it.Type {
+		case diffmatchpatch.DiffInsert:
+			change.Type = "insert"
+		case diffmatchpatch.DiffDelete:
+			change.Type = "delete"
+		default:
+			change.Type = "equal"
+		}
+		detail.Changes = append(detail.Changes, change)
+	}
+	return detail
+}
@@ -81,6 +81,23 @@ func ToNotificationThread(n *models.Notification) *api.NotificationThread {
 			URL:     n.Repository.Link(),
 			HTMLURL: n.Repository.HTMLURL(),
 		}
+	case models.NotificationSourceSecurityEvent:
+		result.Subject = &api.NotificationSubject{
+			Type:    api.NotifySubjectSecurityEvent,
+			HTMLURL: n.HTMLURL(),
+		}
+		if n.SecurityEvent != nil {
+			result.Subject.Title = string(n.SecurityEvent.Type)
+		}
+	case models.NotificationSourceDiscussion:
+		result.Subject = &api.NotificationSubject{
+			Type: api.NotifySubjectDiscussion,
+		}
+		if n.Discussion != nil {
+			result.Subject.Title = n.Discussion.Title
+			result.Subject.URL = n.Discussion.APIURL()
+			result.Subject.HTMLURL = n.Discussion.HTMLURL()
+		}
 	}
 
 	return result
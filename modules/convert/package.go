@@ -51,3 +51,15 @@ func ToPackageFile(pfd *packages.PackageFileDescriptor) *api.PackageFile {
 		HashSHA512: pfd.Blob.HashSHA512,
 	}
 }
+
+// ToPackagePolicy convert a packages.PackagePolicy and the usage of the owner to api.PackagePolicy
+func ToPackagePolicy(policy *packages.PackagePolicy, usedCount, usedSize int64) *api.PackagePolicy {
+	return &api.PackagePolicy{
+		MaxCount:              policy.MaxCount,
+		MaxSize:               policy.MaxSize,
+		KeepLastVersions:      policy.KeepLastVersions,
+		UntaggedRetentionDays: policy.UntaggedRetentionDays,
+		UsedCount:             usedCount,
+		UsedSize:              usedSize,
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	"code.gitea.io/gitea/models"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToPreviewEnvironment converts a models.PreviewEnvironment to api.PreviewEnvironment, the creator has to be loaded
+func ToPreviewEnvironment(env *models.PreviewEnvironment, doer *user_model.User) *api.PreviewEnvironment {
+	apiEnv := &api.PreviewEnvironment{
+		ID:      env.ID,
+		Name:    env.Name,
+		URL:     env.URL,
+		Status:  string(env.Status),
+		Created: env.CreatedUnix.AsTime(),
+		Updated: env.UpdatedUnix.AsTime(),
+	}
+	if env.ExpiresUnix > 0 {
+		apiEnv.Expires = env.ExpiresUnix.AsTimePtr()
+	}
+	if env.Creator != nil {
+		apiEnv.Creator = ToUser(env.Creator, doer)
+	}
+	return apiEnv
+}
@@ -45,5 +45,7 @@ func ToReleaseAttachment(a *repo_model.Attachment) *api.Attachment {
 		Size:          a.Size,
 		UUID:          a.UUID,
 		DownloadURL:   a.DownloadURL(),
+		ExternalURL:   a.ExternalURL,
+		Checksum:      a.Checksum,
 	}
 }
@@ -0,0 +1,23 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToRepoAuditLog converts a repo_model.AuditLog to the api.RepoAuditLog format
+func ToRepoAuditLog(entry *repo_model.AuditLog, doer *user_model.User) *api.RepoAuditLog {
+	return &api.RepoAuditLog{
+		ID:      entry.ID,
+		Action:  string(entry.Action),
+		Target:  entry.Target,
+		Detail:  entry.Detail,
+		Doer:    ToUser(entry.Doer, doer),
+		Created: entry.CreatedUnix.AsTime(),
+	}
+}
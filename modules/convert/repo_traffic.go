@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package convert
+
+import (
+	repo_model "code.gitea.io/gitea/models/repo"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ToRepoTraffic converts a repo_model.Traffic to the api.RepoTraffic format
+func ToRepoTraffic(traffic *repo_model.Traffic) *api.RepoTraffic {
+	days := make([]*api.RepoTrafficDay, len(traffic.Days))
+	for i, day := range traffic.Days {
+		days[i] = &api.RepoTrafficDay{
+			Timestamp: day.Day.AsTime().UTC(),
+			Count:     day.Count,
+			Uniques:   day.Uniques,
+		}
+	}
+	return &api.RepoTraffic{
+		Count:   traffic.Count,
+		Uniques: traffic.Uniques,
+		Days:    days,
+	}
+}
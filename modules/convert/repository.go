@@ -98,8 +98,6 @@ func innerToRepo(repo *repo_model.Repository, mode perm.AccessMode, isParent boo
 		return nil
 	}
 
-	numReleases, _ := models.GetReleaseCountByRepoID(repo.ID, models.FindReleasesOptions{IncludeDrafts: false, IncludeTags: false})
-
 	mirrorInterval := ""
 	var mirrorUpdated time.Time
 	if repo.IsMirror {
@@ -151,6 +149,7 @@ func innerToRepo(repo *repo_model.Repository, mode perm.AccessMode, isParent boo
 		CloneURL:                  cloneLink.HTTPS,
 		OriginalURL:               repo.SanitizedOriginalURL(),
 		Website:                   repo.Website,
+		License:                   repo.License,
 		Language:                  language,
 		LanguagesURL:              repoAPIURL + "/languages",
 		Stars:                     repo.NumStars,
@@ -158,7 +157,7 @@ func innerToRepo(repo *repo_model.Repository, mode perm.AccessMode, isParent boo
 		Watchers:                  repo.NumWatches,
 		OpenIssues:                repo.NumOpenIssues,
 		OpenPulls:                 repo.NumOpenPulls,
-		Releases:                  int(numReleases),
+		Releases:                  repo.NumReleases,
 		DefaultBranch:             repo.DefaultBranch,
 		Created:                   repo.CreatedUnix.AsTime(),
 		Updated:                   repo.UpdatedUnix.AsTime(),
@@ -176,6 +175,7 @@ func innerToRepo(repo *repo_model.Repository, mode perm.AccessMode, isParent boo
 		AllowRebaseMerge:          allowRebaseMerge,
 		AllowSquash:               allowSquash,
 		DefaultMergeStyle:         string(defaultMergeStyle),
+		TrustModel:                repo.TrustModel.String(),
 		AvatarURL:                 repo.AvatarLink(),
 		Internal:                  !repo.IsPrivate && repo.Owner.Visibility == api.VisibleTypePrivate,
 		MirrorInterval:            mirrorInterval,
@@ -188,9 +188,16 @@ func innerToRepo(repo *repo_model.Repository, mode perm.AccessMode, isParent boo
 func ToRepoTransfer(t *models.RepoTransfer) *api.RepoTransfer {
 	teams, _ := ToTeams(t.Teams, false)
 
-	return &api.RepoTransfer{
-		Doer:      ToUser(t.Doer, nil),
-		Recipient: ToUser(t.Recipient, nil),
-		Teams:     teams,
+	transfer := &api.RepoTransfer{
+		Doer:         ToUser(t.Doer, nil),
+		Recipient:    ToUser(t.Recipient, nil),
+		Teams:        teams,
+		RedirectDays: t.RedirectDays,
+		Accepted:     t.Accepted,
+	}
+	if t.ScheduledUnix > 0 {
+		scheduledAt := t.ScheduledUnix.AsTime()
+		transfer.ScheduledAt = &scheduledAt
 	}
+	return transfer
 }
@@ -0,0 +1,159 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package emoji
+
+import (
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// UploadedEmoji is an emoji uploaded by a site admin or by the owners of an organization
+type UploadedEmoji struct {
+	// Alias is the name of the emoji, <orgname>-<name> for an emoji of an organization
+	Alias string
+	// Owner is the lower name of the organization, it is empty for an emoji of the instance
+	Owner string
+	// Path is the path of the image in the custom emoji storage
+	Path string
+}
+
+// The emoji uploaded and the reactions allowed by site admins at runtime,
+// they extend the CUSTOM_EMOJIS and replace the REACTIONS of [ui]
+var (
+	customMu  sync.RWMutex
+	uploaded  = map[string]UploadedEmoji{}
+	reactions []string
+)
+
+// SetUploaded replaces the custom emoji uploaded by site admins and by the owners of organizations
+func SetUploaded(emojis []UploadedEmoji) {
+	m := make(map[string]UploadedEmoji, len(emojis))
+	for _, e := range emojis {
+		m[e.Alias] = e
+	}
+	customMu.Lock()
+	uploaded = m
+	customMu.Unlock()
+}
+
+// SetReactions replaces the allowed reactions, an empty list allows the REACTIONS of [ui]
+func SetReactions(names []string) {
+	customMu.Lock()
+	reactions = names
+	customMu.Unlock()
+}
+
+func getUploaded(name string) (UploadedEmoji, bool) {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	e, ok := uploaded[name]
+	return e, ok
+}
+
+// IsUploaded returns true if name is a custom emoji uploaded by a site admin or by the owners of an organization
+func IsUploaded(name string) bool {
+	_, ok := getUploaded(name)
+	return ok
+}
+
+// IsCustom returns true if name is a custom emoji of the instance, configured in CUSTOM_EMOJIS or uploaded
+func IsCustom(name string) bool {
+	if _, ok := setting.UI.CustomEmojisMap[name]; ok {
+		return true
+	}
+	e, ok := getUploaded(name)
+	return ok && e.Owner == ""
+}
+
+// IsCustomIn returns true if name is a custom emoji of the instance or of the organization owner
+func IsCustomIn(name, owner string) bool {
+	if IsCustom(name) {
+		return true
+	}
+	e, ok := getUploaded(name)
+	return ok && e.Owner != "" && e.Owner == strings.ToLower(owner)
+}
+
+// CustomURL returns the URL of the image of a custom emoji
+func CustomURL(name string) string {
+	if e, ok := getUploaded(name); ok {
+		return setting.AppSubURL + "/custom-emojis/" + e.Path
+	}
+	return setting.StaticURLPrefix + "/assets/img/emoji/" + url.PathEscape(name) + ".png"
+}
+
+// UploadedAbsoluteURL returns the absolute URL of the image of an uploaded custom emoji
+func UploadedAbsoluteURL(name string) string {
+	e, _ := getUploaded(name)
+	return setting.AppURL + "custom-emojis/" + e.Path
+}
+
+// CustomEmojis returns the names of all custom emoji, configured or uploaded
+func CustomEmojis() []string {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	names := make([]string, 0, len(setting.UI.CustomEmojis)+len(uploaded))
+	names = append(names, setting.UI.CustomEmojis...)
+	for name, e := range uploaded {
+		if _, ok := setting.UI.CustomEmojisMap[name]; !ok && e.Owner == "" {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names[len(setting.UI.CustomEmojis):])
+	return names
+}
+
+// Uploaded returns the sorted names of the custom emoji uploaded by site admins
+func Uploaded() []string {
+	customMu.RLock()
+	names := make([]string, 0, len(uploaded))
+	for name, e := range uploaded {
+		if e.Owner == "" {
+			names = append(names, name)
+		}
+	}
+	customMu.RUnlock()
+	sort.Strings(names)
+	return names
+}
+
+// UploadedURLs returns the URLs of the images of the uploaded custom emoji by their names
+func UploadedURLs() map[string]string {
+	names := Uploaded()
+	urls := make(map[string]string, len(names))
+	for _, name := range names {
+		urls[name] = CustomURL(name)
+	}
+	return urls
+}
+
+// Reactions returns the allowed reactions
+func Reactions() []string {
+	customMu.RLock()
+	defer customMu.RUnlock()
+	if len(reactions) == 0 {
+		return setting.UI.Reactions
+	}
+	return reactions
+}
+
+// IsAllowedReaction returns true if name is an allowed reaction
+func IsAllowedReaction(name string) bool {
+	for _, reaction := range Reactions() {
+		if reaction == name {
+			return true
+		}
+	}
+	return false
+}
+
+// OrgAlias returns the name of an emoji of an organization as it is used in :<orgname>-<name>:
+func OrgAlias(org, name string) string {
+	return strings.ToLower(org) + "-" + name
+}
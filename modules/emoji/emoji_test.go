@@ -9,6 +9,8 @@ import (
 	"reflect"
 	"testing"
 
+	"code.gitea.io/gitea/modules/setting"
+
 	"github.com/stretchr/testify/assert"
 )
 
@@ -98,3 +100,23 @@ func TestFindEmojiSubmatchIndex(t *testing.T) {
 		assert.Equal(t, kase.expected, actual)
 	}
 }
+
+func TestIsCustomIn(t *testing.T) {
+	defer SetUploaded(nil)
+	SetUploaded([]UploadedEmoji{
+		{Alias: "shipit", Path: "shipit.png"},
+		{Alias: OrgAlias("MyOrg", "party"), Owner: "myorg", Path: "3/party.png"},
+	})
+
+	assert.True(t, IsCustom("shipit"))
+	assert.False(t, IsCustom("myorg-party"))
+	assert.True(t, IsUploaded("myorg-party"))
+
+	assert.True(t, IsCustomIn("shipit", "other"))
+	assert.True(t, IsCustomIn("myorg-party", "MyOrg"))
+	assert.False(t, IsCustomIn("myorg-party", "other"))
+	assert.False(t, IsCustomIn("myorg-party", ""))
+
+	assert.Equal(t, []string{"shipit"}, Uploaded())
+	assert.Equal(t, setting.AppSubURL+"/custom-emojis/3/party.png", CustomURL("myorg-party"))
+}
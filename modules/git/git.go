@@ -39,6 +39,9 @@ var (
 
 	// SupportProcReceive version >= 2.29.0
 	SupportProcReceive bool
+
+	// SupportPartialClone is true if partial clones are served, version >= 2.22.0 and not disabled
+	SupportPartialClone bool
 )
 
 // LocalVersion returns current Git version from shell.
@@ -157,7 +160,8 @@ func Init(ctx context.Context) error {
 	}
 
 	// By default partial clones are disabled, enable them from git v2.22
-	if !setting.Git.DisablePartialClone && CheckGitVersionAtLeast("2.22") == nil {
+	SupportPartialClone = !setting.Git.DisablePartialClone && CheckGitVersionAtLeast("2.22") == nil
+	if SupportPartialClone {
 		globalCommandArgs = append(globalCommandArgs, "-c", "uploadpack.allowfilter=true", "-c", "uploadpack.allowAnySHA1InWant=true")
 	}
 
@@ -178,6 +182,13 @@ func Init(ctx context.Context) error {
 		return err
 	}
 
+	// Hide the refs of deleted branches from clients, they are only kept to restore the branches
+	for _, key := range []string{"uploadpack.hideRefs", "receive.hideRefs"} {
+		if err := checkAndAddConfig(key, strings.TrimSuffix(DeletedBranchPrefix, "/")); err != nil {
+			return err
+		}
+	}
+
 	if CheckGitVersionAtLeast("2.10") == nil {
 		if err := checkAndSetConfig("receive.advertisePushOptions", "true", true); err != nil {
 			return err
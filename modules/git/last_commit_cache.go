@@ -7,6 +7,8 @@ package git
 import (
 	"crypto/sha256"
 	"fmt"
+	"strconv"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -19,8 +21,28 @@ type Cache interface {
 	Get(key string) interface{}
 }
 
+func getCacheGenerationKey(repoPath string) string {
+	return "last_commit_generation:" + repoPath
+}
+
+func getCacheGeneration(cache Cache, repoPath string) string {
+	generation, _ := cache.Get(getCacheGenerationKey(repoPath)).(string)
+	return generation
+}
+
+// ClearLastCommitCache invalidates the cached last commits of a repository. The cached entries aren't removed,
+// but the cache keys of the repository change, so the ttl must not be shorter than the one of the entries.
+func ClearLastCommitCache(cache Cache, repoPath string, ttl int64) error {
+	return cache.Put(getCacheGenerationKey(repoPath), strconv.FormatInt(time.Now().UnixNano(), 10), ttl)
+}
+
 func (c *LastCommitCache) getCacheKey(repoPath, ref, entryPath string) string {
-	hashBytes := sha256.Sum256([]byte(fmt.Sprintf("%s:%s:%s", repoPath, ref, entryPath)))
+	key := fmt.Sprintf("%s:%s:%s", repoPath, ref, entryPath)
+	if c.generation != "" {
+		// the keys change when the cache of the repository is cleared
+		key = c.generation + ":" + key
+	}
+	hashBytes := sha256.Sum256([]byte(key))
 	return fmt.Sprintf("last_commit:%x", hashBytes)
 }
 
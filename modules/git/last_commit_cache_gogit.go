@@ -18,6 +18,7 @@ import (
 // LastCommitCache represents a cache to store last commit
 type LastCommitCache struct {
 	repoPath    string
+	generation  string
 	ttl         func() int64
 	repo        *Repository
 	commitCache map[string]*object.Commit
@@ -31,6 +32,7 @@ func NewLastCommitCache(repoPath string, gitRepo *Repository, ttl func() int64,
 	}
 	return &LastCommitCache{
 		repoPath:    repoPath,
+		generation:  getCacheGeneration(cache, repoPath),
 		repo:        gitRepo,
 		commitCache: make(map[string]*object.Commit),
 		ttl:         ttl,
@@ -11,6 +11,8 @@ const (
 	RemotePrefix = "refs/remotes/"
 	// PullPrefix is the base directory of the pull information of git.
 	PullPrefix = "refs/pull/"
+	// DeletedBranchPrefix is the base directory of the hidden refs which keep the commits of deleted branches.
+	DeletedBranchPrefix = "refs/deleted-branches/"
 
 	pullLen = len(PullPrefix)
 )
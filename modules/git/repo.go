@@ -192,6 +192,10 @@ type PushOptions struct {
 	Mirror  bool
 	Env     []string
 	Timeout time.Duration
+
+	// ForceWithLease is the `<refname>:<expect>` of a push which only succeeds if the remote ref is still at the expected
+	// commit, an empty expect requires the remote ref not to exist
+	ForceWithLease string
 }
 
 // Push pushs local commits to given remote branch.
@@ -203,6 +207,9 @@ func Push(ctx context.Context, repoPath string, opts PushOptions) error {
 	if opts.Mirror {
 		cmd.AddArguments("--mirror")
 	}
+	if opts.ForceWithLease != "" {
+		cmd.AddArguments("--force-with-lease=" + opts.ForceWithLease)
+	}
 	cmd.AddArguments("--", opts.Remote)
 	if len(opts.Branch) > 0 {
 		cmd.AddArguments(opts.Branch)
@@ -226,7 +233,7 @@ func Push(ctx context.Context, repoPath string, opts PushOptions) error {
 		Stderr:  &errbuf,
 	})
 	if err != nil {
-		if strings.Contains(errbuf.String(), "non-fast-forward") {
+		if strings.Contains(errbuf.String(), "non-fast-forward") || strings.Contains(errbuf.String(), "stale info") {
 			return &ErrPushOutOfDate{
 				StdOut: outbuf.String(),
 				StdErr: errbuf.String(),
@@ -146,3 +146,303 @@ func (repo *Repository) GetCodeActivityStats(fromTime time.Time, branch string)
 
 	return stats, nil
 }
+
+// ContributorWeek represents the commits of an author in one week
+type ContributorWeek struct {
+	// Week is the unix timestamp of the start of the week (Sunday 00:00 UTC)
+	Week      int64
+	Commits   int64
+	Additions int64
+	Deletions int64
+}
+
+// ContributorStats represents the commits of an author grouped by week
+type ContributorStats struct {
+	Name      string
+	Email     string
+	Commits   int64
+	Additions int64
+	Deletions int64
+	// Weeks contains only the weeks with commits in ascending order
+	Weeks []*ContributorWeek
+}
+
+// weekStart returns the unix timestamp of the Sunday starting the week of the given time
+func weekStart(unix int64) int64 {
+	const day = 24 * 60 * 60
+	days := unix / day
+	// 1970-01-01 was a Thursday
+	return (days - (days+4)%7) * day
+}
+
+// GetContributorStats returns the commits, additions and deletions of every author of the revision by week
+func (repo *Repository) GetContributorStats(revision string) ([]*ContributorStats, error) {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stdoutReader.Close()
+		_ = stdoutWriter.Close()
+	}()
+
+	var contributors []*ContributorStats
+	stderr := new(strings.Builder)
+	err = NewCommand(repo.Ctx, "log", "--numstat", "--no-merges", "--pretty=format:---%n%aN%n%aE%n%at", revision, "--").Run(&RunOpts{
+		Env:    []string{},
+		Dir:    repo.Path,
+		Stdout: stdoutWriter,
+		Stderr: stderr,
+		PipelineFunc: func(ctx context.Context, cancel context.CancelFunc) error {
+			_ = stdoutWriter.Close()
+			scanner := bufio.NewScanner(stdoutReader)
+			scanner.Split(bufio.ScanLines)
+			authors := make(map[string]*ContributorStats)
+			weeks := make(map[string]map[int64]*ContributorWeek)
+			var name, email string
+			var week *ContributorWeek
+			p := 0
+			for scanner.Scan() {
+				l := strings.TrimSpace(scanner.Text())
+				if l == "---" {
+					p = 1
+				} else if p == 0 {
+					continue
+				} else {
+					p++
+				}
+				if p > 4 && len(l) == 0 {
+					continue
+				}
+				switch p {
+				case 1: // Separator
+				case 2: // Author
+					name = l
+				case 3: // E-mail
+					email = strings.ToLower(l)
+				case 4: // Author date
+					unix, err := strconv.ParseInt(l, 10, 64)
+					if err != nil {
+						return err
+					}
+					author, ok := authors[email]
+					if !ok {
+						author = &ContributorStats{Name: name, Email: email}
+						authors[email] = author
+						weeks[email] = make(map[int64]*ContributorWeek)
+					}
+					author.Commits++
+					start := weekStart(unix)
+					if week, ok = weeks[email][start]; !ok {
+						week = &ContributorWeek{Week: start}
+						weeks[email][start] = week
+						author.Weeks = append(author.Weeks, week)
+					}
+					week.Commits++
+				default: // Changed file
+					if parts := strings.Fields(l); len(parts) >= 3 && week != nil {
+						if c, err := strconv.ParseInt(parts[0], 10, 64); err == nil {
+							week.Additions += c
+							authors[email].Additions += c
+						}
+						if c, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+							week.Deletions += c
+							authors[email].Deletions += c
+						}
+					}
+				}
+			}
+			contributors = make([]*ContributorStats, 0, len(authors))
+			for _, author := range authors {
+				sort.Slice(author.Weeks, func(i, j int) bool {
+					return author.Weeks[i].Week < author.Weeks[j].Week
+				})
+				contributors = append(contributors, author)
+			}
+			// Sort authors descending depending on commit count
+			sort.Slice(contributors, func(i, j int) bool {
+				if contributors[i].Commits != contributors[j].Commits {
+					return contributors[i].Commits > contributors[j].Commits
+				}
+				return contributors[i].Email < contributors[j].Email
+			})
+			_ = stdoutReader.Close()
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get GetContributorStats for repository.\nError: %w\nStderr: %s", err, stderr)
+	}
+
+	return contributors, nil
+}
+
+// CommitsOnDay represents the number of commits authored on one day
+type CommitsOnDay struct {
+	// Date is the day in the requested location formatted as YYYY-MM-DD
+	Date    string
+	Commits int64
+}
+
+// GetCommitsByDay returns the number of commits of the revision authored on every day with commits between since and until,
+// the days are those of the given location. Zero times don't limit the range.
+func (repo *Repository) GetCommitsByDay(revision string, since, until time.Time, loc *time.Location) ([]*CommitsOnDay, error) {
+	args := []string{"log", "--no-merges", "--pretty=format:%at"}
+	if !since.IsZero() {
+		args = append(args, "--since="+since.Format(time.RFC3339))
+	}
+	if !until.IsZero() {
+		args = append(args, "--until="+until.Format(time.RFC3339))
+	}
+	args = append(args, revision, "--")
+
+	stdout, _, err := NewCommand(repo.Ctx, args...).RunStdString(&RunOpts{Dir: repo.Path})
+	if err != nil {
+		return nil, err
+	}
+
+	days := make(map[string]*CommitsOnDay)
+	for _, l := range strings.Split(stdout, "\n") {
+		l = strings.TrimSpace(l)
+		if len(l) == 0 {
+			continue
+		}
+		unix, err := strconv.ParseInt(l, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		date := time.Unix(unix, 0).In(loc).Format("2006-01-02")
+		day, ok := days[date]
+		if !ok {
+			day = &CommitsOnDay{Date: date}
+			days[date] = day
+		}
+		day.Commits++
+	}
+
+	result := make([]*CommitsOnDay, 0, len(days))
+	for _, day := range days {
+		result = append(result, day)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].Date < result[j].Date
+	})
+	return result, nil
+}
+
+// CodeFrequencyWeek represents the lines added and deleted in one week
+type CodeFrequencyWeek struct {
+	// Week is the unix timestamp of the start of the week (Sunday 00:00 UTC)
+	Week      int64
+	Additions int64
+	Deletions int64
+}
+
+// PunchCardHour represents the number of commits authored in one hour of a day of the week
+type PunchCardHour struct {
+	// Day is the day of the week, 0 is Sunday
+	Day int
+	// Hour is the hour of the day in the time zone of the author
+	Hour    int
+	Commits int64
+}
+
+// CodeStats represents the code frequency and the punch card of a revision
+type CodeStats struct {
+	// CodeFrequency contains only the weeks with commits in ascending order
+	CodeFrequency []*CodeFrequencyWeek
+	// PunchCard contains every hour of every day of the week, ordered by day and hour
+	PunchCard []*PunchCardHour
+}
+
+// NewPunchCard returns a punch card without commits
+func NewPunchCard() []*PunchCardHour {
+	punchCard := make([]*PunchCardHour, 0, 7*24)
+	for day := 0; day < 7; day++ {
+		for hour := 0; hour < 24; hour++ {
+			punchCard = append(punchCard, &PunchCardHour{Day: day, Hour: hour})
+		}
+	}
+	return punchCard
+}
+
+// GetCodeStats returns the lines added and deleted by week and the commits by hour of the day of the week of the revision
+func (repo *Repository) GetCodeStats(revision string) (*CodeStats, error) {
+	stdoutReader, stdoutWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = stdoutReader.Close()
+		_ = stdoutWriter.Close()
+	}()
+
+	stats := &CodeStats{
+		CodeFrequency: []*CodeFrequencyWeek{},
+		PunchCard:     NewPunchCard(),
+	}
+
+	stderr := new(strings.Builder)
+	err = NewCommand(repo.Ctx, "log", "--numstat", "--no-merges", "--pretty=format:---%n%aI", revision, "--").Run(&RunOpts{
+		Env:    []string{},
+		Dir:    repo.Path,
+		Stdout: stdoutWriter,
+		Stderr: stderr,
+		PipelineFunc: func(ctx context.Context, cancel context.CancelFunc) error {
+			_ = stdoutWriter.Close()
+			scanner := bufio.NewScanner(stdoutReader)
+			scanner.Split(bufio.ScanLines)
+			weeks := make(map[int64]*CodeFrequencyWeek)
+			var week *CodeFrequencyWeek
+			p := 0
+			for scanner.Scan() {
+				l := strings.TrimSpace(scanner.Text())
+				if l == "---" {
+					p = 1
+				} else if p == 0 {
+					continue
+				} else {
+					p++
+				}
+				if p > 2 && len(l) == 0 {
+					continue
+				}
+				switch p {
+				case 1: // Separator
+				case 2: // Author date
+					when, err := time.Parse(time.RFC3339, l)
+					if err != nil {
+						return err
+					}
+					stats.PunchCard[int(when.Weekday())*24+when.Hour()].Commits++
+					start := weekStart(when.Unix())
+					var ok bool
+					if week, ok = weeks[start]; !ok {
+						week = &CodeFrequencyWeek{Week: start}
+						weeks[start] = week
+						stats.CodeFrequency = append(stats.CodeFrequency, week)
+					}
+				default: // Changed file
+					if parts := strings.Fields(l); len(parts) >= 3 && week != nil {
+						if c, err := strconv.ParseInt(parts[0], 10, 64); err == nil {
+							week.Additions += c
+						}
+						if c, err := strconv.ParseInt(parts[1], 10, 64); err == nil {
+							week.Deletions += c
+						}
+					}
+				}
+			}
+			sort.Slice(stats.CodeFrequency, func(i, j int) bool {
+				return stats.CodeFrequency[i].Week < stats.CodeFrequency[j].Week
+			})
+			_ = stdoutReader.Close()
+			return nil
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("Failed to get GetCodeStats for repository.\nError: %w\nStderr: %s", err, stderr)
+	}
+
+	return stats, nil
+}
@@ -35,3 +35,81 @@ func TestRepository_GetCodeActivityStats(t *testing.T) {
 	assert.EqualValues(t, 3, code.Authors[1].Commits)
 	assert.EqualValues(t, 5, code.Authors[0].Commits)
 }
+
+func TestRepository_GetContributorStats(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := openRepositoryWithDefaultContext(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	contributors, err := bareRepo1.GetContributorStats("master")
+	assert.NoError(t, err)
+	if assert.Len(t, contributors, 3) {
+		assert.EqualValues(t, &ContributorStats{
+			Name:      "Tris Forster",
+			Email:     "tris.git@shoddynet.org",
+			Commits:   3,
+			Additions: 5,
+			Weeks:     []*ContributorWeek{{Week: 1523750400, Commits: 3, Additions: 5}},
+		}, contributors[0])
+		assert.EqualValues(t, "Example User", contributors[1].Name)
+		assert.EqualValues(t, []*ContributorWeek{{Week: 1513468800, Commits: 2, Additions: 2}}, contributors[1].Weeks)
+		assert.EqualValues(t, "me@silverwind.io", contributors[2].Email)
+		assert.EqualValues(t, 1, contributors[2].Commits)
+	}
+}
+
+func TestRepository_GetCommitsByDay(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := openRepositoryWithDefaultContext(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	days, err := bareRepo1.GetCommitsByDay("master", time.Time{}, time.Time{}, time.UTC)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*CommitsOnDay{
+		{Date: "2017-12-20", Commits: 2},
+		{Date: "2018-04-18", Commits: 2},
+		{Date: "2018-04-20", Commits: 1},
+		{Date: "2019-07-21", Commits: 1},
+	}, days)
+
+	loc, err := time.LoadLocation("America/Los_Angeles")
+	assert.NoError(t, err)
+	days, err = bareRepo1.GetCommitsByDay("master", time.Date(2018, 1, 1, 0, 0, 0, 0, time.UTC), time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC), loc)
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*CommitsOnDay{
+		{Date: "2018-04-17", Commits: 2},
+		{Date: "2018-04-19", Commits: 1},
+	}, days)
+}
+
+func TestRepository_GetCodeStats(t *testing.T) {
+	bareRepo1Path := filepath.Join(testReposDir, "repo1_bare")
+	bareRepo1, err := openRepositoryWithDefaultContext(bareRepo1Path)
+	assert.NoError(t, err)
+	defer bareRepo1.Close()
+
+	stats, err := bareRepo1.GetCodeStats("master")
+	assert.NoError(t, err)
+	assert.EqualValues(t, []*CodeFrequencyWeek{
+		{Week: 1513468800, Additions: 2},
+		{Week: 1523750400, Additions: 5},
+		{Week: 1563667200},
+	}, stats.CodeFrequency)
+
+	assert.Len(t, stats.PunchCard, 7*24)
+	commits := make(map[[2]int]int64)
+	for _, hour := range stats.PunchCard {
+		if hour.Commits > 0 {
+			commits[[2]int{hour.Day, hour.Hour}] = hour.Commits
+		}
+	}
+	// the hours are those of the time zones of the authors
+	assert.EqualValues(t, map[[2]int]int64{
+		{0, 22}: 1,
+		{2, 22}: 2,
+		{3, 14}: 2,
+		{5, 10}: 1,
+	}, commits)
+}
@@ -9,6 +9,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"os"
 	"strings"
 
 	"code.gitea.io/gitea/modules/git/foreachref"
@@ -35,6 +36,16 @@ func (repo *Repository) CreateAnnotatedTag(name, message, revision string) error
 	return err
 }
 
+// CreateSignedTag create one annotated tag signed with the given key in the repository
+func (repo *Repository) CreateSignedTag(name, message, revision, keyID string, tagger *Signature) error {
+	env := append(os.Environ(),
+		"GIT_COMMITTER_NAME="+tagger.Name,
+		"GIT_COMMITTER_EMAIL="+tagger.Email,
+	)
+	_, _, err := NewCommand(repo.Ctx, "tag", "-s", "-u", keyID, "-m", message, "--", name, revision).RunStdString(&RunOpts{Dir: repo.Path, Env: env})
+	return err
+}
+
 // GetTagNameBySHA returns the name of a tag from its tag object SHA or commit SHA
 func (repo *Repository) GetTagNameBySHA(sha string) (string, error) {
 	if len(sha) < 5 {
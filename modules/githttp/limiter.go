@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package githttp
+
+import (
+	"sync"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// The limits an upload-pack can be rejected by
+const (
+	RejectedByUser = "user"
+	RejectedByRepo = "repo"
+)
+
+// Limiter caps the number of concurrent upload-packs per user and per repository.
+// A limit of 0 or less means unlimited.
+type Limiter struct {
+	maxPerUser int
+	maxPerRepo int
+
+	mu       sync.Mutex
+	perUser  map[string]int
+	perRepo  map[string]int
+	active   int64
+	rejected map[string]int64
+}
+
+// NewLimiter creates a new limiter
+func NewLimiter(maxPerUser, maxPerRepo int) *Limiter {
+	return &Limiter{
+		maxPerUser: maxPerUser,
+		maxPerRepo: maxPerRepo,
+		perUser:    make(map[string]int),
+		perRepo:    make(map[string]int),
+		rejected:   make(map[string]int64),
+	}
+}
+
+// Acquire reserves a slot for an upload-pack of the user in the repository.
+// If a limit is reached it returns the limit, otherwise release must be called once the upload-pack is done.
+func (l *Limiter) Acquire(userKey, repoKey string) (release func(), rejectedBy string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.maxPerUser > 0 && l.perUser[userKey] >= l.maxPerUser {
+		l.rejected[RejectedByUser]++
+		return nil, RejectedByUser
+	}
+	if l.maxPerRepo > 0 && l.perRepo[repoKey] >= l.maxPerRepo {
+		l.rejected[RejectedByRepo]++
+		return nil, RejectedByRepo
+	}
+	l.perUser[userKey]++
+	l.perRepo[repoKey]++
+	l.active++
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			l.mu.Lock()
+			defer l.mu.Unlock()
+			l.active--
+			if l.perUser[userKey]--; l.perUser[userKey] <= 0 {
+				delete(l.perUser, userKey)
+			}
+			if l.perRepo[repoKey]--; l.perRepo[repoKey] <= 0 {
+				delete(l.perRepo, repoKey)
+			}
+		})
+	}, ""
+}
+
+// Active returns the number of upload-packs which are running
+func (l *Limiter) Active() int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.active
+}
+
+// Rejected returns the number of upload-packs which have been rejected by the limit
+func (l *Limiter) Rejected(rejectedBy string) int64 {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.rejected[rejectedBy]
+}
+
+var (
+	defaultLimiter     *Limiter
+	defaultLimiterOnce sync.Once
+)
+
+// DefaultLimiter returns the limiter configured by the [git.http_limits] settings
+func DefaultLimiter() *Limiter {
+	defaultLimiterOnce.Do(func() {
+		defaultLimiter = NewLimiter(setting.Git.HTTPLimits.MaxUploadPacksPerUser, setting.Git.HTTPLimits.MaxUploadPacksPerRepo)
+	})
+	return defaultLimiter
+}
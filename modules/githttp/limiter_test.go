@@ -0,0 +1,56 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package githttp
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter(t *testing.T) {
+	l := NewLimiter(2, 3)
+
+	release1, rejectedBy := l.Acquire("user:1", "repo1")
+	assert.NotNil(t, release1)
+	assert.Empty(t, rejectedBy)
+	release2, _ := l.Acquire("user:1", "repo2")
+	assert.NotNil(t, release2)
+
+	// the user has reached the limit
+	release, rejectedBy := l.Acquire("user:1", "repo1")
+	assert.Nil(t, release)
+	assert.Equal(t, RejectedByUser, rejectedBy)
+
+	// other users can still clone until the repository has reached its limit
+	release3, _ := l.Acquire("user:2", "repo1")
+	assert.NotNil(t, release3)
+	release4, _ := l.Acquire("ip:127.0.0.1", "repo1")
+	assert.NotNil(t, release4)
+	release, rejectedBy = l.Acquire("user:3", "repo1")
+	assert.Nil(t, release)
+	assert.Equal(t, RejectedByRepo, rejectedBy)
+
+	assert.EqualValues(t, 4, l.Active())
+	assert.EqualValues(t, 1, l.Rejected(RejectedByUser))
+	assert.EqualValues(t, 1, l.Rejected(RejectedByRepo))
+
+	// releasing twice doesn't free another slot
+	release1()
+	release1()
+	assert.EqualValues(t, 3, l.Active())
+	release, _ = l.Acquire("user:1", "repo1")
+	assert.NotNil(t, release)
+	release, rejectedBy = l.Acquire("user:1", "repo3")
+	assert.Nil(t, release)
+	assert.Equal(t, RejectedByUser, rejectedBy)
+
+	// no limits
+	l = NewLimiter(0, 0)
+	for i := 0; i < 10; i++ {
+		release, _ = l.Acquire("user:1", "repo1")
+		assert.NotNil(t, release)
+	}
+}
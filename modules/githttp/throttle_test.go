@@ -0,0 +1,35 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package githttp
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestThrottledWriter(t *testing.T) {
+	buf := &bytes.Buffer{}
+	assert.Equal(t, buf, NewThrottledWriter(context.Background(), buf, 0))
+
+	data := bytes.Repeat([]byte("a"), 3*minBurst)
+	w := NewThrottledWriter(context.Background(), buf, 2*minBurst)
+	start := time.Now()
+	n, err := w.Write(data)
+	assert.NoError(t, err)
+	assert.Equal(t, len(data), n)
+	assert.Equal(t, data, buf.Bytes())
+	// the burst is sent at once, the rest takes half a second
+	assert.GreaterOrEqual(t, time.Since(start), 400*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	w = NewThrottledWriter(ctx, &bytes.Buffer{}, minBurst)
+	_, err = w.Write(data)
+	assert.Error(t, err)
+}
@@ -0,0 +1,179 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+// Package hookfilter implements the expressions deciding whether a webhook payload is delivered.
+//
+// An expression compares fields of the JSON payload with literals, e.g.
+//
+//	ref startsWith "refs/heads/release/" && !(pusher.login == "bot")
+//	action == "labeled" && issue.labels.name == "bug"
+//
+// Fields are dotted paths into the payload. A path crossing an array yields the values of all its
+// elements, and a comparison holds if it holds for any of them. The operators are ==, !=, startsWith,
+// endsWith, contains and matches (a glob pattern), combined with &&, || and ! and grouped with
+// parentheses. A field on its own holds if it has a value which isn't empty, zero or false.
+package hookfilter
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+// Filter is a compiled filter expression
+type Filter struct {
+	expr string
+	root node
+}
+
+// Compile parses the expression into a filter
+func Compile(expr string) (*Filter, error) {
+	tokens, err := tokenize(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if tok := p.peek(); tok.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %s at position %d", tok, tok.pos)
+	}
+	return &Filter{expr: expr, root: root}, nil
+}
+
+// String returns the source of the filter
+func (f *Filter) String() string {
+	return f.expr
+}
+
+// Match evaluates the filter against a payload decoded from JSON
+func (f *Filter) Match(payload interface{}) bool {
+	return f.root.eval(payload)
+}
+
+type node interface {
+	eval(payload interface{}) bool
+}
+
+type (
+	orNode  struct{ left, right node }
+	andNode struct{ left, right node }
+	notNode struct{ operand node }
+	// fieldNode holds if the field has a value which isn't empty, zero or false
+	fieldNode struct{ path []string }
+	// compareNode holds if a value of the field compares to the literal
+	compareNode struct {
+		path    []string
+		op      string
+		literal interface{}
+		pattern glob.Glob
+	}
+)
+
+func (n *orNode) eval(payload interface{}) bool {
+	return n.left.eval(payload) || n.right.eval(payload)
+}
+
+func (n *andNode) eval(payload interface{}) bool {
+	return n.left.eval(payload) && n.right.eval(payload)
+}
+
+func (n *notNode) eval(payload interface{}) bool {
+	return !n.operand.eval(payload)
+}
+
+func (n *fieldNode) eval(payload interface{}) bool {
+	for _, v := range resolve(payload, n.path) {
+		switch v := v.(type) {
+		case nil:
+		case bool:
+			if v {
+				return true
+			}
+		case float64:
+			if v != 0 {
+				return true
+			}
+		case string:
+			if v != "" {
+				return true
+			}
+		case map[string]interface{}:
+			if len(v) > 0 {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func (n *compareNode) eval(payload interface{}) bool {
+	if n.op == "!=" {
+		return !(&compareNode{path: n.path, op: "==", literal: n.literal}).eval(payload)
+	}
+
+	for _, v := range resolve(payload, n.path) {
+		if n.op == "==" {
+			if v == n.literal {
+				return true
+			}
+			continue
+		}
+
+		s, ok := v.(string)
+		if !ok {
+			continue
+		}
+		literal, _ := n.literal.(string)
+		switch n.op {
+		case "startsWith":
+			ok = strings.HasPrefix(s, literal)
+		case "endsWith":
+			ok = strings.HasSuffix(s, literal)
+		case "contains":
+			ok = strings.Contains(s, literal)
+		case "matches":
+			ok = n.pattern.Match(s)
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+// resolve returns the values of the path in the payload, arrays on the way are flattened
+func resolve(payload interface{}, path []string) []interface{} {
+	values := []interface{}{payload}
+	for _, key := range path {
+		next := make([]interface{}, 0, len(values))
+		for _, v := range flatten(values) {
+			if m, ok := v.(map[string]interface{}); ok {
+				if child, has := m[key]; has {
+					next = append(next, child)
+				}
+			}
+		}
+		values = next
+	}
+	return flatten(values)
+}
+
+func flatten(values []interface{}) []interface{} {
+	flat := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		if list, ok := v.([]interface{}); ok {
+			flat = append(flat, flatten(list)...)
+		} else {
+			flat = append(flat, v)
+		}
+	}
+	return flat
+}
@@ -0,0 +1,74 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package hookfilter
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const testPayload = `{
+	"ref": "refs/heads/release/1.17",
+	"action": "labeled",
+	"forced": false,
+	"total_commits": 2,
+	"pusher": {"login": "user2"},
+	"issue": {"number": 7, "labels": [{"name": "bug"}, {"name": "kind/docs"}], "milestone": null}
+}`
+
+func TestFilterMatch(t *testing.T) {
+	var payload interface{}
+	assert.NoError(t, json.Unmarshal([]byte(testPayload), &payload))
+
+	for expr, expected := range map[string]bool{
+		`ref startsWith "refs/heads/release/"`:                true,
+		`ref startsWith "refs/tags/"`:                         false,
+		`ref endsWith "1.17" && pusher.login == "user2"`:      true,
+		`ref contains "main" || action == "labeled"`:          true,
+		`!(action == "labeled")`:                              false,
+		`issue.labels.name == "bug"`:                          true,
+		`issue.labels.name != "bug"`:                          false,
+		`issue.labels.name matches "kind/*"`:                  true,
+		`issue.labels.name matches "area/*"`:                  false,
+		`issue.number == 7 && total_commits != 3`:             true,
+		`forced == false`:                                     true,
+		`forced`:                                              false,
+		`issue.milestone`:                                     false,
+		`issue.milestone == null`:                             true,
+		`pusher`:                                              true,
+		`missing.field == "x"`:                                false,
+		`ref == 1`:                                            false,
+		`(action == "opened" || action == "labeled") && ref`:  true,
+		`action == "opened" || action == "labeled" && forced`: false,
+	} {
+		f, err := Compile(expr)
+		if assert.NoError(t, err, expr) {
+			assert.Equal(t, expected, f.Match(payload), expr)
+		}
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	for _, expr := range []string{
+		``,
+		`ref ==`,
+		`ref startsWith 1`,
+		`ref == "a" &&`,
+		`(ref == "a"`,
+		`ref == "a")`,
+		`"a" == ref`,
+		`ref == "unterminated`,
+		`ref = "a"`,
+		`ref matches "[a"`,
+		`ref.. == "a"`,
+		`ref "a"`,
+	} {
+		_, err := Compile(expr)
+		assert.Error(t, err, expr)
+	}
+}
@@ -0,0 +1,241 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package hookfilter
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gobwas/glob"
+)
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenIdent
+	tokenString
+	tokenNumber
+	tokenSymbol
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+func (t token) String() string {
+	switch t.kind {
+	case tokenEOF:
+		return "end of expression"
+	case tokenString:
+		return strconv.Quote(t.value)
+	}
+	return fmt.Sprintf("%q", t.value)
+}
+
+// operators compare a field with a literal
+var operators = map[string]bool{
+	"==":         true,
+	"!=":         true,
+	"startsWith": true,
+	"endsWith":   true,
+	"contains":   true,
+	"matches":    true,
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentPart(c byte) bool {
+	return isIdentStart(c) || (c >= '0' && c <= '9') || c == '.'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func tokenize(expr string) ([]token, error) {
+	tokens := make([]token, 0, 8)
+	for i := 0; i < len(expr); {
+		c := expr[i]
+		switch {
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			i++
+		case isIdentStart(c):
+			start := i
+			for i < len(expr) && isIdentPart(expr[i]) {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenIdent, value: expr[start:i], pos: start})
+		case isDigit(c) || (c == '-' && i+1 < len(expr) && isDigit(expr[i+1])):
+			start := i
+			i++
+			for i < len(expr) && (isDigit(expr[i]) || expr[i] == '.') {
+				i++
+			}
+			tokens = append(tokens, token{kind: tokenNumber, value: expr[start:i], pos: start})
+		case c == '"':
+			start := i
+			i++
+			for i < len(expr) && expr[i] != '"' {
+				if expr[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			if i >= len(expr) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			value, err := strconv.Unquote(expr[start:i])
+			if err != nil {
+				return nil, fmt.Errorf("invalid string at position %d: %v", start, err)
+			}
+			tokens = append(tokens, token{kind: tokenString, value: value, pos: start})
+		default:
+			symbol := ""
+			for _, s := range []string{"&&", "||", "==", "!=", "!", "(", ")"} {
+				if strings.HasPrefix(expr[i:], s) {
+					symbol = s
+					break
+				}
+			}
+			if symbol == "" {
+				return nil, fmt.Errorf("unexpected character %q at position %d", c, i)
+			}
+			tokens = append(tokens, token{kind: tokenSymbol, value: symbol, pos: i})
+			i += len(symbol)
+		}
+	}
+	return append(tokens, token{kind: tokenEOF, pos: len(expr)}), nil
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokenEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) acceptSymbol(symbol string) bool {
+	if tok := p.peek(); tok.kind == tokenSymbol && tok.value == symbol {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) parseOr() (node, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptSymbol("||") {
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseAnd() (node, error) {
+	left, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+	for p.acceptSymbol("&&") {
+		right, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *parser) parseUnary() (node, error) {
+	if p.acceptSymbol("!") {
+		operand, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	if p.acceptSymbol("(") {
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if !p.acceptSymbol(")") {
+			tok := p.peek()
+			return nil, fmt.Errorf("expected \")\" instead of %s at position %d", tok, tok.pos)
+		}
+		return inner, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *parser) parseComparison() (node, error) {
+	tok := p.next()
+	if tok.kind != tokenIdent || tok.value == "true" || tok.value == "false" || operators[tok.value] {
+		return nil, fmt.Errorf("expected a field instead of %s at position %d", tok, tok.pos)
+	}
+	path := strings.Split(tok.value, ".")
+	for _, key := range path {
+		if key == "" {
+			return nil, fmt.Errorf("invalid field %q at position %d", tok.value, tok.pos)
+		}
+	}
+
+	op := p.peek()
+	if (op.kind != tokenSymbol && op.kind != tokenIdent) || !operators[op.value] {
+		return &fieldNode{path: path}, nil
+	}
+	p.next()
+
+	lit := p.next()
+	n := &compareNode{path: path, op: op.value}
+	switch {
+	case lit.kind == tokenString:
+		n.literal = lit.value
+	case lit.kind == tokenNumber && (op.value == "==" || op.value == "!="):
+		f, err := strconv.ParseFloat(lit.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid number %s at position %d", lit, lit.pos)
+		}
+		n.literal = f
+	case lit.kind == tokenIdent && (lit.value == "true" || lit.value == "false") && (op.value == "==" || op.value == "!="):
+		n.literal = lit.value == "true"
+	case lit.kind == tokenIdent && lit.value == "null" && (op.value == "==" || op.value == "!="):
+		n.literal = nil
+	default:
+		return nil, fmt.Errorf("expected a literal for %s instead of %s at position %d", op.value, lit, lit.pos)
+	}
+
+	if op.value == "matches" {
+		pattern, err := glob.Compile(n.literal.(string))
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %s at position %d: %v", lit, lit.pos, err)
+		}
+		n.pattern = pattern
+	}
+	return n, nil
+}
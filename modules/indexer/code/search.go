@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/modules/highlight"
+	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 )
@@ -23,6 +24,7 @@ type Result struct {
 	Language       string
 	Color          string
 	LineNumbers    []int
+	Lines          string
 	FormattedLines string
 }
 
@@ -102,6 +104,7 @@ func searchResult(result *SearchResult, startIndex, endIndex int) (*Result, erro
 		Language:       result.Language,
 		Color:          result.Color,
 		LineNumbers:    lineNumbers,
+		Lines:          formattedLinesBuffer.String(),
 		FormattedLines: highlight.Code(result.Filename, "", formattedLinesBuffer.String()),
 	}, nil
 }
@@ -116,7 +119,30 @@ func PerformSearch(ctx context.Context, repoIDs []int64, language, keyword strin
 	if err != nil {
 		return 0, nil, nil, err
 	}
+	return toDisplayResults(total, results, resultLanguages)
+}
+
+// SupportsAdvancedSearch returns whether the configured indexer can search
+// non-default branches and regular expressions
+func SupportsAdvancedSearch() bool {
+	return setting.Indexer.RepoType == "trigram"
+}
+
+// PerformAdvancedSearch perform a branch-aware substring or regular expression search on repositories
+func PerformAdvancedSearch(ctx context.Context, opts *SearchOptions) (int, []*Result, []*SearchResultLanguages, error) {
+	if len(opts.Keyword) == 0 {
+		return 0, nil, nil, nil
+	}
+
+	total, results, resultLanguages, err := indexer.AdvancedSearch(ctx, opts)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	return toDisplayResults(total, results, resultLanguages)
+}
 
+func toDisplayResults(total int64, results []*SearchResult, resultLanguages []*SearchResultLanguages) (int, []*Result, []*SearchResultLanguages, error) {
+	var err error
 	displayResults := make([]*Result, len(results))
 
 	for i, result := range results {
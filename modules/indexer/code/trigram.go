@@ -0,0 +1,705 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"bufio"
+	"context"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/analyze"
+	"code.gitea.io/gitea/modules/charset"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/typesniffer"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/go-enry/go-enry/v2"
+	lru "github.com/hashicorp/golang-lru"
+)
+
+const (
+	trigramIndexerLatestVersion = 1
+	trigramDefaultBranchKey     = "default"
+	trigramFileExt              = ".gob"
+)
+
+// ErrAdvancedSearchNotSupported is returned if the configured code indexer cannot
+// search non-default branches or regular expressions
+var ErrAdvancedSearchNotSupported = fmt.Errorf("The code indexer does not support branch or regular expression searches")
+
+// SearchOptions represents the options of a branch-aware code search
+type SearchOptions struct {
+	RepoIDs  []int64
+	Branch   string
+	Language string
+	Keyword  string
+	IsRegexp bool
+	Page     int
+	PageSize int
+}
+
+// AdvancedSearcher is implemented by code indexers which can search non-default
+// branches and match regular expressions
+type AdvancedSearcher interface {
+	AdvancedSearch(ctx context.Context, opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error)
+}
+
+// trigramDocument represents a file stored in the trigram indexer
+type trigramDocument struct {
+	BlobSha     string
+	Content     string
+	Language    string
+	UpdatedUnix timeutil.TimeStamp
+}
+
+// trigramIndex holds the indexed files of one branch of a repository
+type trigramIndex struct {
+	Version  int
+	Branch   string
+	CommitID string
+	Docs     map[string]*trigramDocument
+
+	postings map[uint32][]string
+}
+
+func newTrigramIndex(branch string) *trigramIndex {
+	return &trigramIndex{
+		Version: trigramIndexerLatestVersion,
+		Branch:  branch,
+		Docs:    make(map[string]*trigramDocument),
+	}
+}
+
+// clone returns a copy of the index which can be modified without affecting
+// concurrent searches. Documents themselves are never modified in place.
+func (idx *trigramIndex) clone() *trigramIndex {
+	c := newTrigramIndex(idx.Branch)
+	c.CommitID = idx.CommitID
+	for filename, doc := range idx.Docs {
+		c.Docs[filename] = doc
+	}
+	return c
+}
+
+func (idx *trigramIndex) buildPostings() {
+	idx.postings = make(map[uint32][]string)
+	filenames := make([]string, 0, len(idx.Docs))
+	for filename := range idx.Docs {
+		filenames = append(filenames, filename)
+	}
+	sort.Strings(filenames)
+	for _, filename := range filenames {
+		for tri := range trigramsOf(idx.Docs[filename].Content) {
+			idx.postings[tri] = append(idx.postings[tri], filename)
+		}
+	}
+}
+
+// candidates returns the sorted filenames of the documents containing all given trigrams
+func (idx *trigramIndex) candidates(trigrams []uint32) []string {
+	if len(trigrams) == 0 {
+		filenames := make([]string, 0, len(idx.Docs))
+		for filename := range idx.Docs {
+			filenames = append(filenames, filename)
+		}
+		sort.Strings(filenames)
+		return filenames
+	}
+
+	// start with the rarest trigram to keep the intersection small
+	sort.Slice(trigrams, func(i, j int) bool {
+		return len(idx.postings[trigrams[i]]) < len(idx.postings[trigrams[j]])
+	})
+	result := idx.postings[trigrams[0]]
+	for _, tri := range trigrams[1:] {
+		if len(result) == 0 {
+			break
+		}
+		result = intersectSorted(result, idx.postings[tri])
+	}
+	return result
+}
+
+func intersectSorted(a, b []string) []string {
+	result := make([]string, 0, util.Min(len(a), len(b)))
+	for i, j := 0, 0; i < len(a) && j < len(b); {
+		switch {
+		case a[i] == b[j]:
+			result = append(result, a[i])
+			i++
+			j++
+		case a[i] < b[j]:
+			i++
+		default:
+			j++
+		}
+	}
+	return result
+}
+
+func trigramOf(s string, i int) uint32 {
+	return uint32(toLowerASCII(s[i]))<<16 | uint32(toLowerASCII(s[i+1]))<<8 | uint32(toLowerASCII(s[i+2]))
+}
+
+func toLowerASCII(c byte) byte {
+	if 'A' <= c && c <= 'Z' {
+		return c + 'a' - 'A'
+	}
+	return c
+}
+
+// trigramsOf returns the set of case-insensitive trigrams of the content
+func trigramsOf(content string) map[uint32]struct{} {
+	set := make(map[uint32]struct{})
+	for i := 0; i+3 <= len(content); i++ {
+		set[trigramOf(content, i)] = struct{}{}
+	}
+	return set
+}
+
+// queryTrigrams returns the trigrams every match of the given literals must contain.
+// Trigrams with non-ASCII bytes are skipped as their case folding is not byte-wise.
+func queryTrigrams(literals []string) []uint32 {
+	set := make(map[uint32]struct{})
+	for _, lit := range literals {
+		for i := 0; i+3 <= len(lit); i++ {
+			if lit[i] >= 0x80 || lit[i+1] >= 0x80 || lit[i+2] >= 0x80 {
+				continue
+			}
+			set[trigramOf(lit, i)] = struct{}{}
+		}
+	}
+	trigrams := make([]uint32, 0, len(set))
+	for tri := range set {
+		trigrams = append(trigrams, tri)
+	}
+	return trigrams
+}
+
+// requiredLiterals returns literal strings which must be contained in any text matched by re
+func requiredLiterals(re *syntax.Regexp) []string {
+	switch re.Op {
+	case syntax.OpLiteral:
+		return []string{string(re.Rune)}
+	case syntax.OpCapture, syntax.OpPlus:
+		return requiredLiterals(re.Sub[0])
+	case syntax.OpRepeat:
+		if re.Min > 0 {
+			return requiredLiterals(re.Sub[0])
+		}
+	case syntax.OpConcat:
+		var literals []string
+		var current strings.Builder
+		for _, sub := range re.Sub {
+			if sub.Op == syntax.OpLiteral {
+				current.WriteString(string(sub.Rune))
+				continue
+			}
+			if current.Len() > 0 {
+				literals = append(literals, current.String())
+				current.Reset()
+			}
+			literals = append(literals, requiredLiterals(sub)...)
+		}
+		if current.Len() > 0 {
+			literals = append(literals, current.String())
+		}
+		return literals
+	}
+	return nil
+}
+
+// compileSearchQuery returns the regular expression matching the keyword and the trigrams it requires
+func compileSearchQuery(keyword string, isRegexp bool) (*regexp.Regexp, []uint32, error) {
+	if !isRegexp {
+		re, err := regexp.Compile("(?i)" + regexp.QuoteMeta(keyword))
+		return re, queryTrigrams([]string{keyword}), err
+	}
+
+	parsed, err := syntax.Parse(keyword, syntax.Perl)
+	if err != nil {
+		return nil, nil, err
+	}
+	re, err := regexp.Compile(keyword)
+	if err != nil {
+		return nil, nil, err
+	}
+	// trigrams are case-insensitive, so they may be used for case-sensitive expressions as well
+	return re, queryTrigrams(requiredLiterals(parsed.Simplify())), nil
+}
+
+// TrigramIndexer implements Indexer interface using a built-in trigram index.
+// Besides the default branch it indexes the branches matching REPO_INDEXER_BRANCHES
+// and supports substring and regular expression searches.
+type TrigramIndexer struct {
+	indexDir string
+	// indexes holds the most recently used branch indexes, the others are read from disk when needed
+	indexes *lru.Cache
+}
+
+// trigramCacheKey identifies the index of a branch of a repository in the cache
+type trigramCacheKey struct {
+	repoID int64
+	key    string
+}
+
+// NewTrigramIndexer creates a new trigram indexer keeping up to cacheSize branch indexes in memory
+func NewTrigramIndexer(indexDir string, cacheSize int) (*TrigramIndexer, bool, error) {
+	created := false
+	if _, err := os.Stat(indexDir); os.IsNotExist(err) {
+		created = true
+	} else if err != nil {
+		return nil, false, err
+	}
+	if err := os.MkdirAll(indexDir, os.ModePerm); err != nil {
+		return nil, false, err
+	}
+	indexes, err := lru.New(cacheSize)
+	if err != nil {
+		return nil, false, err
+	}
+	return &TrigramIndexer{
+		indexDir: indexDir,
+		indexes:  indexes,
+	}, created, nil
+}
+
+func trigramBranchKey(branch string) string {
+	if branch == "" {
+		return trigramDefaultBranchKey
+	}
+	return hex.EncodeToString([]byte(branch))
+}
+
+func (t *TrigramIndexer) repoDir(repoID int64) string {
+	return filepath.Join(t.indexDir, strconv.FormatInt(repoID, 10))
+}
+
+// load returns the stored index of the branch, or nil if it has not been indexed
+func (t *TrigramIndexer) load(repoID int64, key string) (*trigramIndex, error) {
+	if cached, ok := t.indexes.Get(trigramCacheKey{repoID, key}); ok {
+		return cached.(*trigramIndex), nil
+	}
+
+	f, err := os.Open(filepath.Join(t.repoDir(repoID), key+trigramFileExt))
+	if os.IsNotExist(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	idx := &trigramIndex{}
+	if err := gob.NewDecoder(bufio.NewReader(f)).Decode(idx); err != nil || idx.Version != trigramIndexerLatestVersion {
+		// An outdated or corrupted index is simply rebuilt
+		log.Warn("Ignoring unreadable trigram index %s for repo %d: %v", key, repoID, err)
+		return nil, nil
+	}
+	idx.buildPostings()
+	t.indexes.Add(trigramCacheKey{repoID, key}, idx)
+	return idx, nil
+}
+
+func (t *TrigramIndexer) save(repoID int64, key string, idx *trigramIndex) error {
+	dir := t.repoDir(repoID)
+	if err := os.MkdirAll(dir, os.ModePerm); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(dir, key+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer util.Remove(tmp.Name())
+
+	w := bufio.NewWriter(tmp)
+	if err := gob.NewEncoder(w).Encode(idx); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := w.Flush(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := util.Rename(tmp.Name(), filepath.Join(dir, key+trigramFileExt)); err != nil {
+		return err
+	}
+
+	idx.buildPostings()
+	t.indexes.Add(trigramCacheKey{repoID, key}, idx)
+	return nil
+}
+
+func (t *TrigramIndexer) remove(repoID int64, key string) error {
+	t.indexes.Remove(trigramCacheKey{repoID, key})
+	return util.Remove(filepath.Join(t.repoDir(repoID), key+trigramFileExt))
+}
+
+// SetAvailabilityChangeCallback does nothing
+func (t *TrigramIndexer) SetAvailabilityChangeCallback(callback func(bool)) {}
+
+// Ping does nothing
+func (t *TrigramIndexer) Ping() bool {
+	return true
+}
+
+// Close does nothing as every change is written to disk immediately
+func (t *TrigramIndexer) Close() {}
+
+// Index indexes the changes of the default branch and refreshes the other indexed branches
+func (t *TrigramIndexer) Index(ctx context.Context, repo *repo_model.Repository, sha string, changes *repoChanges) error {
+	old, err := t.load(repo.ID, trigramDefaultBranchKey)
+	if err != nil {
+		return err
+	}
+	idx := newTrigramIndex(repo.DefaultBranch)
+	if old != nil {
+		idx = old.clone()
+		idx.Branch = repo.DefaultBranch
+	}
+	if err := t.applyChanges(ctx, repo, idx, sha, changes); err != nil {
+		return err
+	}
+	if err := t.save(repo.ID, trigramDefaultBranchKey, idx); err != nil {
+		return err
+	}
+	return t.indexBranches(ctx, repo)
+}
+
+func (t *TrigramIndexer) applyChanges(ctx context.Context, repo *repo_model.Repository, idx *trigramIndex, sha string, changes *repoChanges) error {
+	for _, filename := range changes.RemovedFilenames {
+		delete(idx.Docs, filename)
+	}
+	idx.CommitID = sha
+
+	if len(changes.Updates) == 0 {
+		return nil
+	}
+
+	// Now because of some insanity with git cat-file not immediately failing if not run in a valid git directory we need to run git rev-parse first!
+	if err := git.EnsureValidGitRepository(ctx, repo.RepoPath()); err != nil {
+		log.Error("Unable to open git repo: %s for %-v: %v", repo.RepoPath(), repo, err)
+		return err
+	}
+
+	batchWriter, batchReader, cancel := git.CatFileBatch(ctx, repo.RepoPath())
+	defer cancel()
+
+	for _, update := range changes.Updates {
+		if doc, ok := idx.Docs[update.Filename]; ok && doc.BlobSha == update.BlobSha {
+			continue
+		}
+		doc, err := t.readDocument(ctx, batchWriter, batchReader, repo, update)
+		if err != nil {
+			return err
+		}
+		if doc == nil {
+			delete(idx.Docs, update.Filename)
+			continue
+		}
+		idx.Docs[update.Filename] = doc
+	}
+	return nil
+}
+
+// readDocument reads the file of the update, or returns nil if it should not be indexed
+func (t *TrigramIndexer) readDocument(ctx context.Context, batchWriter git.WriteCloserError, batchReader *bufio.Reader,
+	repo *repo_model.Repository, update fileUpdate,
+) (*trigramDocument, error) {
+	// Ignore vendored files in code search
+	if setting.Indexer.ExcludeVendored && analyze.IsVendor(update.Filename) {
+		return nil, nil
+	}
+
+	size := update.Size
+
+	var err error
+	if !update.Sized {
+		var stdout string
+		stdout, _, err = git.NewCommand(ctx, "cat-file", "-s", update.BlobSha).RunStdString(&git.RunOpts{Dir: repo.RepoPath()})
+		if err != nil {
+			return nil, err
+		}
+		if size, err = strconv.ParseInt(strings.TrimSpace(stdout), 10, 64); err != nil {
+			return nil, fmt.Errorf("Misformatted git cat-file output: %v", err)
+		}
+	}
+
+	if size > setting.Indexer.MaxIndexerFileSize {
+		return nil, nil
+	}
+
+	if _, err := batchWriter.Write([]byte(update.BlobSha + "\n")); err != nil {
+		return nil, err
+	}
+
+	_, _, size, err = git.ReadBatchLine(batchReader)
+	if err != nil {
+		return nil, err
+	}
+
+	fileContents, err := io.ReadAll(io.LimitReader(batchReader, size))
+	if err != nil {
+		return nil, err
+	}
+	if _, err = batchReader.Discard(1); err != nil {
+		return nil, err
+	}
+	if !typesniffer.DetectContentType(fileContents).IsText() {
+		// FIXME: UTF-16 files will probably fail here
+		return nil, nil
+	}
+
+	return &trigramDocument{
+		BlobSha:     update.BlobSha,
+		Content:     string(charset.ToUTF8DropErrors(fileContents)),
+		Language:    analyze.GetCodeLanguage(update.Filename, fileContents),
+		UpdatedUnix: timeutil.TimeStampNow(),
+	}, nil
+}
+
+// IsIndexedBranch returns whether the given non-default branch is indexed in addition to the default branch
+func IsIndexedBranch(branch string) bool {
+	name := strings.ToLower(branch)
+	for _, g := range setting.Indexer.RepoBranches {
+		if g.Match(name) {
+			return true
+		}
+	}
+	return false
+}
+
+// indexBranches indexes the non-default branches matching REPO_INDEXER_BRANCHES
+// and removes the indexes of branches which no longer exist or match
+func (t *TrigramIndexer) indexBranches(ctx context.Context, repo *repo_model.Repository) error {
+	wanted := make(map[string]bool)
+	if len(setting.Indexer.RepoBranches) > 0 {
+		stdout, _, err := git.NewCommand(ctx, "for-each-ref", "--format=%(objectname) %(refname)", git.BranchPrefix).RunStdString(&git.RunOpts{Dir: repo.RepoPath()})
+		if err != nil {
+			return err
+		}
+		for _, line := range strings.Split(stdout, "\n") {
+			fields := strings.SplitN(strings.TrimSpace(line), " ", 2)
+			if len(fields) != 2 {
+				continue
+			}
+			sha, branch := fields[0], strings.TrimPrefix(fields[1], git.BranchPrefix)
+			if branch == repo.DefaultBranch || !IsIndexedBranch(branch) {
+				continue
+			}
+			key := trigramBranchKey(branch)
+			wanted[key] = true
+			if err := t.indexBranch(ctx, repo, key, branch, sha); err != nil {
+				return err
+			}
+		}
+	}
+
+	entries, err := os.ReadDir(t.repoDir(repo.ID))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		key := strings.TrimSuffix(entry.Name(), trigramFileExt)
+		if entry.IsDir() || key == entry.Name() || key == trigramDefaultBranchKey || wanted[key] {
+			continue
+		}
+		if err := t.remove(repo.ID, key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (t *TrigramIndexer) indexBranch(ctx context.Context, repo *repo_model.Repository, key, branch, sha string) error {
+	old, err := t.load(repo.ID, key)
+	if err != nil {
+		return err
+	}
+	if old != nil && old.CommitID == sha {
+		return nil
+	}
+
+	changes, err := genesisChanges(ctx, repo, sha)
+	if err != nil {
+		return err
+	}
+
+	// Start from an empty index but keep the documents of unchanged blobs
+	idx := newTrigramIndex(branch)
+	if old != nil {
+		present := make(map[string]bool, len(changes.Updates))
+		for _, update := range changes.Updates {
+			present[update.Filename] = true
+		}
+		for filename, doc := range old.Docs {
+			if present[filename] {
+				idx.Docs[filename] = doc
+			}
+		}
+	}
+	if err := t.applyChanges(ctx, repo, idx, sha, changes); err != nil {
+		return err
+	}
+	return t.save(repo.ID, key, idx)
+}
+
+// Delete deletes the indexes of all branches of a repository
+func (t *TrigramIndexer) Delete(repoID int64) error {
+	for _, k := range t.indexes.Keys() {
+		if k.(trigramCacheKey).repoID == repoID {
+			t.indexes.Remove(k)
+		}
+	}
+	return util.RemoveAll(t.repoDir(repoID))
+}
+
+// Search searches for files in the default branch of the specified repos.
+// Both query types are treated as case-insensitive substring searches.
+func (t *TrigramIndexer) Search(ctx context.Context, repoIDs []int64, language, keyword string, page, pageSize int, isMatch bool) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	return t.AdvancedSearch(ctx, &SearchOptions{
+		RepoIDs:  repoIDs,
+		Language: language,
+		Keyword:  keyword,
+		Page:     page,
+		PageSize: pageSize,
+	})
+}
+
+func (t *TrigramIndexer) indexedRepoIDs() ([]int64, error) {
+	entries, err := os.ReadDir(t.indexDir)
+	if err != nil {
+		return nil, err
+	}
+	repoIDs := make([]int64, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		if id, err := strconv.ParseInt(entry.Name(), 10, 64); err == nil {
+			repoIDs = append(repoIDs, id)
+		}
+	}
+	return repoIDs, nil
+}
+
+// AdvancedSearch searches for files in the given branch of the specified repos.
+// If no branch is given the default branch of each repo is searched.
+func (t *TrigramIndexer) AdvancedSearch(ctx context.Context, opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	re, trigrams, err := compileSearchQuery(opts.Keyword, opts.IsRegexp)
+	if err != nil {
+		return 0, nil, nil, err
+	}
+
+	repoIDs := opts.RepoIDs
+	if len(repoIDs) == 0 {
+		if repoIDs, err = t.indexedRepoIDs(); err != nil {
+			return 0, nil, nil, err
+		}
+	}
+	repoIDs = append([]int64(nil), repoIDs...)
+	sort.Slice(repoIDs, func(i, j int) bool { return repoIDs[i] < repoIDs[j] })
+
+	var (
+		total         int64
+		from          = (opts.Page - 1) * opts.PageSize
+		searchResults = make([]*SearchResult, 0, opts.PageSize)
+		languageCount = make(map[string]int)
+	)
+	for _, repoID := range repoIDs {
+		idx, err := t.branchIndex(repoID, opts.Branch)
+		if err != nil {
+			return 0, nil, nil, err
+		} else if idx == nil {
+			continue
+		}
+
+		for _, filename := range idx.candidates(trigrams) {
+			select {
+			case <-ctx.Done():
+				return 0, nil, nil, ctx.Err()
+			default:
+			}
+
+			doc := idx.Docs[filename]
+			loc := re.FindStringIndex(doc.Content)
+			if loc == nil {
+				continue
+			}
+			languageCount[doc.Language]++
+			if len(opts.Language) > 0 && !strings.EqualFold(doc.Language, opts.Language) {
+				continue
+			}
+			if total >= int64(from) && len(searchResults) < opts.PageSize {
+				searchResults = append(searchResults, &SearchResult{
+					RepoID:      repoID,
+					StartIndex:  loc[0],
+					EndIndex:    loc[1],
+					Filename:    filename,
+					Content:     doc.Content,
+					CommitID:    idx.CommitID,
+					UpdatedUnix: doc.UpdatedUnix,
+					Language:    doc.Language,
+					Color:       enry.GetColor(doc.Language),
+				})
+			}
+			total++
+		}
+	}
+
+	searchResultLanguages := make([]*SearchResultLanguages, 0, len(languageCount))
+	if len(opts.Language) == 0 {
+		for language, count := range languageCount {
+			if len(language) == 0 {
+				continue
+			}
+			searchResultLanguages = append(searchResultLanguages, &SearchResultLanguages{
+				Language: language,
+				Color:    enry.GetColor(language),
+				Count:    count,
+			})
+		}
+		sort.Slice(searchResultLanguages, func(i, j int) bool {
+			if searchResultLanguages[i].Count != searchResultLanguages[j].Count {
+				return searchResultLanguages[i].Count > searchResultLanguages[j].Count
+			}
+			return searchResultLanguages[i].Language < searchResultLanguages[j].Language
+		})
+		if len(searchResultLanguages) > 10 {
+			searchResultLanguages = searchResultLanguages[:10]
+		}
+	}
+
+	return total, searchResults, searchResultLanguages, nil
+}
+
+// branchIndex returns the index of the branch, or of the default branch if none is given.
+// It returns nil if the branch has not been indexed.
+func (t *TrigramIndexer) branchIndex(repoID int64, branch string) (*trigramIndex, error) {
+	idx, err := t.load(repoID, trigramDefaultBranchKey)
+	if err != nil || idx == nil || branch == "" || branch == idx.Branch {
+		return idx, err
+	}
+	return t.load(repoID, trigramBranchKey(branch))
+}
@@ -0,0 +1,95 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package code
+
+import (
+	"context"
+	"os"
+	"regexp/syntax"
+	"testing"
+
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrigramIndexAndSearch(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	dir, err := os.MkdirTemp("", "trigram.index")
+	assert.NoError(t, err)
+	if err != nil {
+		assert.Fail(t, "Unable to create temporary directory")
+		return
+	}
+	defer util.RemoveAll(dir)
+
+	idx, _, err := NewTrigramIndexer(dir, 2)
+	if err != nil {
+		assert.Fail(t, "Unable to create trigram indexer Error: %v", err)
+		return
+	}
+	defer idx.Close()
+
+	testIndexer("trigram", t, idx)
+
+	assert.NoError(t, index(git.DefaultContext, idx, 1))
+	for _, kw := range []struct {
+		Keyword  string
+		IsRegexp bool
+		Branch   string
+		Total    int64
+	}{
+		{Keyword: "description for", Total: 1},
+		{Keyword: "Desc.*repo[0-9]", IsRegexp: true, Total: 1},
+		{Keyword: "^# repo1$", IsRegexp: true, Total: 0},
+		{Keyword: "(?m)^# repo1$", IsRegexp: true, Total: 1},
+		{Keyword: "description", Branch: "master", Total: 1},
+		{Keyword: "description", Branch: "not-indexed", Total: 0},
+	} {
+		t.Run(kw.Keyword, func(t *testing.T) {
+			total, res, _, err := idx.AdvancedSearch(context.TODO(), &SearchOptions{
+				Keyword:  kw.Keyword,
+				IsRegexp: kw.IsRegexp,
+				Branch:   kw.Branch,
+				Page:     1,
+				PageSize: 10,
+			})
+			assert.NoError(t, err)
+			assert.EqualValues(t, kw.Total, total)
+			assert.Len(t, res, int(kw.Total))
+		})
+	}
+
+	_, _, _, err = idx.AdvancedSearch(context.TODO(), &SearchOptions{Keyword: "(", IsRegexp: true, Page: 1, PageSize: 10})
+	assert.Error(t, err)
+
+	// indexes dropped from the cache are read from disk again
+	assert.LessOrEqual(t, idx.indexes.Len(), 2)
+	idx.indexes.Purge()
+	total, _, _, err := idx.AdvancedSearch(context.TODO(), &SearchOptions{Keyword: "description for", Page: 1, PageSize: 10})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, total)
+
+	assert.NoError(t, idx.Delete(1))
+	assert.Zero(t, idx.indexes.Len())
+}
+
+func TestRequiredLiterals(t *testing.T) {
+	for expr, expected := range map[string][]string{
+		"foobar":         {"foobar"},
+		"foo.*bar":       {"foo", "bar"},
+		"(foo)+bar":      {"foo", "bar"},
+		"foo|bar":        nil,
+		"(?i)FooBar":     {"FOOBAR"},
+		"fo(o|x)?bar{2}": {"fo", "ba", "rr"},
+	} {
+		re, err := syntax.Parse(expr, syntax.Perl)
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, requiredLiterals(re.Simplify()), expr)
+	}
+}
@@ -101,6 +101,18 @@ func (w *wrappedIndexer) Search(ctx context.Context, repoIDs []int64, language,
 	return indexer.Search(ctx, repoIDs, language, keyword, page, pageSize, isMatch)
 }
 
+func (w *wrappedIndexer) AdvancedSearch(ctx context.Context, opts *SearchOptions) (int64, []*SearchResult, []*SearchResultLanguages, error) {
+	indexer, err := w.get()
+	if err != nil {
+		return 0, nil, nil, err
+	}
+	searcher, ok := indexer.(AdvancedSearcher)
+	if !ok {
+		return 0, nil, nil, ErrAdvancedSearchNotSupported
+	}
+	return searcher.AdvancedSearch(ctx, opts)
+}
+
 func (w *wrappedIndexer) Close() {
 	w.lock.Lock()
 	defer w.lock.Unlock()
@@ -0,0 +1,61 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussions
+
+import (
+	"context"
+	"os"
+	"testing"
+
+	"code.gitea.io/gitea/modules/util"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBleveIndexAndSearch(t *testing.T) {
+	dir, err := os.MkdirTemp("", "bleve.index")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer util.RemoveAll(dir)
+	indexer := NewBleveIndexer(dir)
+	defer indexer.Close()
+
+	if _, err := indexer.Init(); err != nil {
+		assert.Fail(t, "Unable to initialise bleve indexer: %v", err)
+		return
+	}
+
+	assert.NoError(t, indexer.Index([]*IndexerData{
+		{ID: 1, RepoID: 2, Title: "Roadmap for the next release", Content: "What should we focus on?", Comments: []string{"Packages"}},
+		{ID: 2, RepoID: 2, Title: "Build fails on windows", Content: "The tests time out", Comments: []string{"Which release do you use?"}},
+		{ID: 3, RepoID: 3, Title: "Release party", Content: "Let's celebrate"},
+	}))
+
+	keywords := []struct {
+		Keyword string
+		IDs     []int64
+	}{
+		{Keyword: "roadmap", IDs: []int64{1}},
+		{Keyword: "release", IDs: []int64{1, 2}},
+		{Keyword: "packages", IDs: []int64{1}},
+		{Keyword: "party", IDs: []int64{}},
+	}
+	for _, kw := range keywords {
+		res, err := indexer.Search(context.TODO(), kw.Keyword, []int64{2}, 10, 0)
+		assert.NoError(t, err)
+
+		ids := make([]int64, 0, len(res.Hits))
+		for _, hit := range res.Hits {
+			ids = append(ids, hit.ID)
+		}
+		assert.ElementsMatch(t, kw.IDs, ids, "keyword %q", kw.Keyword)
+	}
+
+	assert.NoError(t, indexer.Delete(1))
+	res, err := indexer.Search(context.TODO(), "roadmap", []int64{2}, 10, 0)
+	assert.NoError(t, err)
+	assert.Empty(t, res.Hits)
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussions
+
+import (
+	"context"
+
+	discussion_model "code.gitea.io/gitea/models/discussion"
+)
+
+var _ Indexer = &DBIndexer{}
+
+// DBIndexer implements Indexer interface to use database's like search
+type DBIndexer struct{}
+
+// Init dummy function
+func (i *DBIndexer) Init() (bool, error) {
+	return false, nil
+}
+
+// Index dummy function
+func (i *DBIndexer) Index(discussions []*IndexerData) error {
+	return nil
+}
+
+// Delete dummy function
+func (i *DBIndexer) Delete(ids ...int64) error {
+	return nil
+}
+
+// Close dummy function
+func (i *DBIndexer) Close() {
+}
+
+// Search searches the discussions in the database
+func (i *DBIndexer) Search(ctx context.Context, kw string, repoIDs []int64, limit, start int) (*SearchResult, error) {
+	total, ids, err := discussion_model.SearchDiscussionIDsByKeyword(ctx, kw, repoIDs, limit, start)
+	if err != nil {
+		return nil, err
+	}
+	result := SearchResult{
+		Total: total,
+		Hits:  make([]Match, 0, len(ids)),
+	}
+	for _, id := range ids {
+		result.Hits = append(result.Hits, Match{
+			ID: id,
+		})
+	}
+	return &result, nil
+}
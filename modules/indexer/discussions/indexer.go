@@ -0,0 +1,240 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussions
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	"code.gitea.io/gitea/models/db"
+	discussion_model "code.gitea.io/gitea/models/discussion"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// IndexerData data stored in the discussion indexer
+type IndexerData struct {
+	ID       int64    `json:"id"`
+	RepoID   int64    `json:"repo_id"`
+	Title    string   `json:"title"`
+	Content  string   `json:"content"`
+	Comments []string `json:"comments"`
+	IsDelete bool     `json:"is_delete"`
+	IDs      []int64  `json:"ids"`
+}
+
+// Match represents on search result
+type Match struct {
+	ID    int64   `json:"id"`
+	Score float64 `json:"score"`
+}
+
+// SearchResult represents search results
+type SearchResult struct {
+	Total int64
+	Hits  []Match
+}
+
+// Indexer defines an interface to index the contents of discussions
+type Indexer interface {
+	Init() (bool, error)
+	Index(discussions []*IndexerData) error
+	Delete(ids ...int64) error
+	Search(ctx context.Context, kw string, repoIDs []int64, limit, start int) (*SearchResult, error)
+	Close()
+}
+
+var (
+	// discussionIndexerQueue queue of discussions to be updated
+	discussionIndexerQueue queue.Queue = &queue.DummyQueue{}
+
+	indexerMutex sync.RWMutex
+	indexer      Indexer
+)
+
+func getIndexer() Indexer {
+	indexerMutex.RLock()
+	defer indexerMutex.RUnlock()
+	return indexer
+}
+
+func setIndexer(i Indexer) {
+	indexerMutex.Lock()
+	defer indexerMutex.Unlock()
+	indexer = i
+}
+
+func handle(data ...queue.Data) []queue.Data {
+	idx := getIndexer()
+	if idx == nil {
+		log.Error("Discussion indexer handler: unable to get indexer!")
+		return data
+	}
+
+	dData := make([]*IndexerData, 0, len(data))
+	for _, datum := range data {
+		indexerData, ok := datum.(*IndexerData)
+		if !ok {
+			log.Error("Unable to process provided datum: %v - not possible to cast to IndexerData", datum)
+			continue
+		}
+		if indexerData.IsDelete {
+			if err := idx.Delete(indexerData.IDs...); err != nil {
+				log.Error("Error whilst deleting from index: %v Error: %v", indexerData.IDs, err)
+			}
+			continue
+		}
+		dData = append(dData, indexerData)
+	}
+	if err := idx.Index(dData); err != nil {
+		log.Error("Error whilst indexing: %v Error: %v", dData, err)
+	}
+	return nil
+}
+
+// InitDiscussionIndexer initializes the discussion indexer, a new bleve index is populated in the background
+func InitDiscussionIndexer() {
+	log.Info("PID %d: Initializing Discussion Indexer: %s", os.Getpid(), setting.Indexer.DiscussionType)
+
+	var populate bool
+	switch setting.Indexer.DiscussionType {
+	case "bleve":
+		_, _, finished := process.GetManager().AddTypedContext(context.Background(), "Service: DiscussionIndexer", process.SystemProcessType, false)
+		bleveIndexer := NewBleveIndexer(setting.Indexer.DiscussionPath)
+		exist, err := bleveIndexer.Init()
+		if err != nil {
+			log.Fatal("Unable to initialize Bleve Discussion Indexer at path: %s Error: %v", setting.Indexer.DiscussionPath, err)
+		}
+		populate = !exist
+		setIndexer(bleveIndexer)
+
+		discussionIndexerQueue = queue.CreateQueue("discussion_indexer", handle, &IndexerData{})
+		if discussionIndexerQueue == nil {
+			log.Fatal("Unable to create discussion indexer queue")
+		}
+		go graceful.GetManager().RunWithShutdownFns(discussionIndexerQueue.Run)
+
+		graceful.GetManager().RunAtTerminate(func() {
+			log.Debug("Closing discussion indexer")
+			bleveIndexer.Close()
+			finished()
+			log.Info("PID: %d Discussion Indexer closed", os.Getpid())
+		})
+	case "db":
+		setIndexer(&DBIndexer{})
+	default:
+		log.Fatal("Unknown discussion indexer type: %s", setting.Indexer.DiscussionType)
+	}
+
+	if populate {
+		go graceful.GetManager().RunWithShutdownContext(populateDiscussionIndexer)
+	}
+}
+
+// populateDiscussionIndexer populates the discussion indexer with all the discussions
+func populateDiscussionIndexer(ctx context.Context) {
+	ctx, _, finished := process.GetManager().AddTypedContext(ctx, "Service: PopulateDiscussionIndexer", process.SystemProcessType, true)
+	defer finished()
+	for page := 1; ; page++ {
+		select {
+		case <-ctx.Done():
+			log.Warn("Discussion Indexer population shutdown before completion")
+			return
+		default:
+		}
+		discussions, _, err := discussion_model.FindDiscussions(ctx, &discussion_model.FindDiscussionOptions{
+			ListOptions: db.ListOptions{Page: page, PageSize: 50},
+		})
+		if err != nil {
+			log.Error("FindDiscussions: %v", err)
+			return
+		}
+		if len(discussions) == 0 {
+			log.Debug("Discussion Indexer population complete")
+			return
+		}
+		for _, d := range discussions {
+			UpdateDiscussionIndexer(d)
+		}
+	}
+}
+
+// UpdateDiscussionIndexer adds or updates a discussion with its comments in the discussion indexer
+func UpdateDiscussionIndexer(d *discussion_model.Discussion) {
+	comments, err := discussion_model.GetComments(db.DefaultContext, d.ID)
+	if err != nil {
+		log.Error("GetComments [%d]: %v", d.ID, err)
+		return
+	}
+	var contents []string
+	for _, c := range comments {
+		contents = append(contents, c.Content)
+		for _, r := range c.Replies {
+			contents = append(contents, r.Content)
+		}
+	}
+	indexerData := &IndexerData{
+		ID:       d.ID,
+		RepoID:   d.RepoID,
+		Title:    d.Title,
+		Content:  d.Content,
+		Comments: contents,
+	}
+	if err := discussionIndexerQueue.Push(indexerData); err != nil {
+		log.Error("Unable to push to discussion indexer: %v: Error: %v", indexerData, err)
+	}
+}
+
+// DeleteDiscussionIndexer removes discussions from the discussion indexer
+func DeleteDiscussionIndexer(ids ...int64) {
+	if len(ids) == 0 {
+		return
+	}
+	indexerData := &IndexerData{
+		IDs:      ids,
+		IsDelete: true,
+	}
+	if err := discussionIndexerQueue.Push(indexerData); err != nil {
+		log.Error("Unable to push to discussion indexer: %v: Error: %v", indexerData, err)
+	}
+}
+
+// DeleteRepoDiscussionIndexer removes the discussions of a repository from the discussion indexer
+func DeleteRepoDiscussionIndexer(repo *repo_model.Repository) {
+	discussions, _, err := discussion_model.FindDiscussions(db.DefaultContext, &discussion_model.FindDiscussionOptions{RepoID: repo.ID})
+	if err != nil {
+		log.Error("FindDiscussions [%d]: %v", repo.ID, err)
+		return
+	}
+	ids := make([]int64, 0, len(discussions))
+	for _, d := range discussions {
+		ids = append(ids, d.ID)
+	}
+	DeleteDiscussionIndexer(ids...)
+}
+
+// SearchDiscussionsByKeyword searches the ids of the discussions of the repositories by the keyword
+// WARNING: You have to ensure user have permission to visit the discussions of the repositories
+func SearchDiscussionsByKeyword(ctx context.Context, repoIDs []int64, keyword string, limit, start int) (int64, []int64, error) {
+	idx := getIndexer()
+	if idx == nil {
+		return 0, nil, fmt.Errorf("unable to get discussion indexer")
+	}
+	res, err := idx.Search(ctx, keyword, repoIDs, limit, start)
+	if err != nil {
+		return 0, nil, err
+	}
+	ids := make([]int64, 0, len(res.Hits))
+	for _, r := range res.Hits {
+		ids = append(ids, r.ID)
+	}
+	return res.Total, ids, nil
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+)
+
+// codeStatsQueue represents a queue to generate the code frequency and punch card of repositories
+var codeStatsQueue queue.UniqueQueue
+
+func handleCodeStats(data ...queue.Data) []queue.Data {
+	for _, datum := range data {
+		id := datum.(int64)
+		if err := generateCodeStats(id); err != nil {
+			log.Error("generateCodeStats(%d) failed: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func initCodeStatsQueue() error {
+	codeStatsQueue = queue.CreateUniqueQueue("repo_code_stats", handleCodeStats, int64(0))
+	if codeStatsQueue == nil {
+		return fmt.Errorf("Unable to create repo_code_stats Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(codeStatsQueue.Run)
+
+	return nil
+}
+
+// GetCodeStats returns the code frequency and punch card of a repository at a commit of its default branch.
+// The statistics are stored in the database, if they are missing or were generated for another commit
+// their generation is queued and ErrAwaitGeneration is returned.
+func GetCodeStats(repo *repo_model.Repository, commitID string) (*repo_model.RepoCodeStats, error) {
+	stats, err := repo_model.GetRepoCodeStats(db.DefaultContext, repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if stats != nil && stats.CommitID == commitID {
+		return stats, nil
+	}
+
+	if err := codeStatsQueue.Push(repo.ID); err != nil && err != queue.ErrAlreadyInQueue {
+		return nil, err
+	}
+	return nil, ErrAwaitGeneration
+}
+
+// generateCodeStats generates the code frequency and punch card of the default branch of a repository and stores them
+func generateCodeStats(id int64) error {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().ShutdownContext(), fmt.Sprintf("Stats.Code Repo[%d]", id))
+	defer finished()
+
+	repo, err := repo_model.GetRepositoryByID(id)
+	if err != nil {
+		return err
+	}
+	if repo.IsEmpty {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	commitID, err := gitRepo.GetBranchCommitID(repo.DefaultBranch)
+	if err != nil {
+		if git.IsErrBranchNotExist(err) || git.IsErrNotExist(err) {
+			log.Debug("Unable to get commit ID for default branch %s in %s ... skipping this repository", repo.DefaultBranch, repo.RepoPath())
+			return nil
+		}
+		return err
+	}
+
+	stored, err := repo_model.GetRepoCodeStats(ctx, repo.ID)
+	if err != nil {
+		return err
+	}
+	if stored != nil && stored.CommitID == commitID {
+		return nil
+	}
+
+	codeStats, err := gitRepo.GetCodeStats(commitID)
+	if err != nil {
+		return err
+	}
+	return repo_model.SaveRepoCodeStats(ctx, repo.ID, commitID, codeStats)
+}
@@ -0,0 +1,113 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package stats
+
+import (
+	"errors"
+	"fmt"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrAwaitGeneration is returned while the contributor or code statistics of a repository are being generated
+var ErrAwaitGeneration = errors.New("repository statistics are being generated")
+
+// contributorStatsQueue represents a queue to generate the contributor statistics of repositories
+var contributorStatsQueue queue.UniqueQueue
+
+func handleContributorStats(data ...queue.Data) []queue.Data {
+	for _, datum := range data {
+		id := datum.(int64)
+		if err := generateContributorStats(id); err != nil {
+			log.Error("generateContributorStats(%d) failed: %v", id, err)
+		}
+	}
+	return nil
+}
+
+func initContributorStatsQueue() error {
+	contributorStatsQueue = queue.CreateUniqueQueue("repo_contributor_stats", handleContributorStats, int64(0))
+	if contributorStatsQueue == nil {
+		return fmt.Errorf("Unable to create repo_contributor_stats Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(contributorStatsQueue.Run)
+
+	return nil
+}
+
+func contributorStatsCacheKey(repoID int64, commitID string) string {
+	return fmt.Sprintf("repo_contributor_stats:%d:%s", repoID, commitID)
+}
+
+// GetContributorStats returns the contributor statistics of a repository at a commit of its default branch.
+// If they are not cached, their generation is queued and ErrAwaitGeneration is returned.
+// Without a cache the statistics are generated immediately.
+func GetContributorStats(gitRepo *git.Repository, repo *repo_model.Repository, commitID string) ([]*git.ContributorStats, error) {
+	conn := cache.GetCache()
+	if conn == nil || setting.CacheService.TTL == 0 {
+		return gitRepo.GetContributorStats(commitID)
+	}
+
+	if value, ok := conn.Get(contributorStatsCacheKey(repo.ID, commitID)).(string); ok {
+		var contributors []*git.ContributorStats
+		if err := json.Unmarshal([]byte(value), &contributors); err != nil {
+			return nil, err
+		}
+		return contributors, nil
+	}
+
+	if err := contributorStatsQueue.Push(repo.ID); err != nil && err != queue.ErrAlreadyInQueue {
+		return nil, err
+	}
+	return nil, ErrAwaitGeneration
+}
+
+// generateContributorStats generates the contributor statistics of the default branch of a repository and caches them
+func generateContributorStats(id int64) error {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().ShutdownContext(), fmt.Sprintf("Stats.Contributors Repo[%d]", id))
+	defer finished()
+
+	repo, err := repo_model.GetRepositoryByID(id)
+	if err != nil {
+		return err
+	}
+	if repo.IsEmpty {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	commitID, err := gitRepo.GetBranchCommitID(repo.DefaultBranch)
+	if err != nil {
+		if git.IsErrBranchNotExist(err) || git.IsErrNotExist(err) {
+			log.Debug("Unable to get commit ID for default branch %s in %s ... skipping this repository", repo.DefaultBranch, repo.RepoPath())
+			return nil
+		}
+		return err
+	}
+
+	contributors, err := gitRepo.GetContributorStats(commitID)
+	if err != nil {
+		return err
+	}
+	value, err := json.Marshal(contributors)
+	if err != nil {
+		return err
+	}
+	return cache.GetCache().Put(contributorStatsCacheKey(repo.ID, commitID), string(value), setting.CacheService.TTLSeconds())
+}
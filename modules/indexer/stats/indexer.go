@@ -28,6 +28,14 @@ func Init() error {
 		return err
 	}
 
+	if err := initContributorStatsQueue(); err != nil {
+		return err
+	}
+
+	if err := initCodeStatsQueue(); err != nil {
+		return err
+	}
+
 	go populateRepoIndexer()
 
 	return nil
@@ -13,6 +13,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
@@ -57,3 +58,74 @@ func TestRepoStatsIndex(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Empty(t, langs)
 }
+
+func TestContributorStats(t *testing.T) {
+	if err := git.Init(context.Background()); !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	setting.Cfg = ini.Empty()
+
+	setting.NewQueueService()
+
+	if contributorStatsQueue == nil {
+		assert.NoError(t, initContributorStatsQueue())
+	}
+	assert.NoError(t, cache.NewContext())
+
+	repo, err := repo_model.GetRepositoryByID(1)
+	assert.NoError(t, err)
+	gitRepo, err := git.OpenRepository(context.Background(), repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	// the statistics are generated in the background
+	_, err = GetContributorStats(gitRepo, repo, "65f1bf27bc3bf70f64657658635e66094edbcb4d")
+	assert.Equal(t, ErrAwaitGeneration, err)
+
+	queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+	contributors, err := GetContributorStats(gitRepo, repo, "65f1bf27bc3bf70f64657658635e66094edbcb4d")
+	assert.NoError(t, err)
+	if assert.Len(t, contributors, 1) {
+		assert.EqualValues(t, "address1@example.com", contributors[0].Email)
+		assert.EqualValues(t, 1, contributors[0].Commits)
+		assert.Len(t, contributors[0].Weeks, 1)
+	}
+}
+
+func TestCodeStats(t *testing.T) {
+	if err := git.Init(context.Background()); !assert.NoError(t, err) {
+		return
+	}
+
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	setting.Cfg = ini.Empty()
+
+	setting.NewQueueService()
+
+	if codeStatsQueue == nil {
+		assert.NoError(t, initCodeStatsQueue())
+	}
+
+	repo, err := repo_model.GetRepositoryByID(1)
+	assert.NoError(t, err)
+
+	// the statistics are generated in the background
+	_, err = GetCodeStats(repo, "65f1bf27bc3bf70f64657658635e66094edbcb4d")
+	assert.Equal(t, ErrAwaitGeneration, err)
+
+	queue.GetManager().FlushAll(context.Background(), 5*time.Second)
+
+	stats, err := GetCodeStats(repo, "65f1bf27bc3bf70f64657658635e66094edbcb4d")
+	assert.NoError(t, err)
+	if assert.Len(t, stats.CodeFrequency, 1) {
+		assert.EqualValues(t, 3, stats.CodeFrequency[0].Additions)
+	}
+	assert.Len(t, stats.PunchCard, 7*24)
+
+	// statistics of another commit are generated again
+	_, err = GetCodeStats(repo, "0000000000000000000000000000000000000000")
+	assert.Equal(t, ErrAwaitGeneration, err)
+}
@@ -14,6 +14,9 @@ import (
 	jsoniter "github.com/json-iterator/go"
 )
 
+// RawMessage is a raw encoded JSON value, which delays decoding it
+type RawMessage = json.RawMessage
+
 // Encoder represents an encoder for json
 type Encoder interface {
 	Encode(v interface{}) error
@@ -487,7 +487,7 @@ func createCustomEmoji(alias string) *html.Node {
 		Attr:     []html.Attribute{},
 	}
 	img.Attr = append(img.Attr, html.Attribute{Key: "alt", Val: ":" + alias + ":"})
-	img.Attr = append(img.Attr, html.Attribute{Key: "src", Val: setting.StaticURLPrefix + "/assets/img/emoji/" + alias + ".png"})
+	img.Attr = append(img.Attr, html.Attribute{Key: "src", Val: emoji.CustomURL(alias)})
 
 	span.AppendChild(img)
 	return span
@@ -1012,8 +1012,8 @@ func emojiShortCodeProcessor(ctx *RenderContext, node *html.Node) {
 		alias = strings.ReplaceAll(alias, ":", "")
 		converted := emoji.FromAlias(alias)
 		if converted == nil {
-			// check if this is a custom reaction
-			if _, exist := setting.UI.CustomEmojisMap[alias]; exist {
+			// check if this is a custom emoji of the instance or of the organization owning the repository
+			if emoji.IsCustomIn(alias, ctx.Metas["user"]) {
 				replaceContent(node, m[0], m[1], createCustomEmoji(alias))
 				node = node.NextSibling.NextSibling
 				start = 0
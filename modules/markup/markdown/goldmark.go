@@ -27,13 +27,6 @@ import (
 
 var byteMailto = []byte("mailto:")
 
-// Header holds the data about a header.
-type Header struct {
-	Level int
-	Text  string
-	ID    string
-}
-
 // ASTTransformer is a default transformer of the goldmark tree.
 type ASTTransformer struct{}
 
@@ -42,7 +35,7 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 	metaData := meta.GetItems(pc)
 	firstChild := node.FirstChild()
 	createTOC := false
-	toc := []Header{}
+	toc := make([]markup.Header, 0, 100)
 	rc := &RenderConfig{
 		Meta: "table",
 		Icon: "table",
@@ -56,7 +49,6 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 			node.InsertBefore(node, firstChild, metaNode)
 		}
 		createTOC = rc.TOC
-		toc = make([]Header, 0, 100)
 	}
 
 	_ = ast.Walk(node, func(n ast.Node, entering bool) (ast.WalkStatus, error) {
@@ -66,23 +58,20 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 
 		switch v := n.(type) {
 		case *ast.Heading:
-			if createTOC {
-				text := n.Text(reader.Source())
-				header := Header{
-					Text:  util.BytesToReadOnlyString(text),
-					Level: v.Level,
-				}
-				if id, found := v.AttributeString("id"); found {
-					header.ID = util.BytesToReadOnlyString(id.([]byte))
-				}
-				toc = append(toc, header)
-			} else {
-				for _, attr := range v.Attributes() {
-					if _, ok := attr.Value.([]byte); !ok {
-						v.SetAttribute(attr.Name, []byte(fmt.Sprintf("%v", attr.Value)))
-					}
+			for _, attr := range v.Attributes() {
+				if _, ok := attr.Value.([]byte); !ok {
+					v.SetAttribute(attr.Name, []byte(fmt.Sprintf("%v", attr.Value)))
 				}
 			}
+			// the headers outlive the source of the document in the render context
+			header := markup.Header{
+				Text:  string(n.Text(reader.Source())),
+				Level: v.Level,
+			}
+			if id, found := v.AttributeString("id"); found {
+				header.ID = string(id.([]byte))
+			}
+			toc = append(toc, header)
 		case *ast.Image:
 			// Images need two things:
 			//
@@ -199,6 +188,10 @@ func (g *ASTTransformer) Transform(node *ast.Document, reader text.Reader, pc pa
 		return ast.WalkContinue, nil
 	})
 
+	if renderCtx, ok := pc.Get(renderCtxKey).(*markup.RenderContext); ok {
+		renderCtx.Headers = toc
+	}
+
 	if createTOC && len(toc) > 0 {
 		lang := rc.Lang
 		if len(lang) == 0 {
@@ -418,3 +418,27 @@ func TestRenderEmojiInLinks_Issue12331(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expected, res)
 }
+
+func TestRenderHeaders(t *testing.T) {
+	testcase := `# Title
+## Install
+### From *source*
+#### Requirements
+## Usage
+`
+	ctx := &markup.RenderContext{}
+	_, err := RenderString(ctx, testcase)
+	assert.NoError(t, err)
+	assert.Equal(t, []markup.Header{
+		{Level: 1, Text: "Title", ID: "user-content-title"},
+		{Level: 2, Text: "Install", ID: "user-content-install"},
+		{Level: 3, Text: "From source", ID: "user-content-from-source"},
+		{Level: 4, Text: "Requirements", ID: "user-content-requirements"},
+		{Level: 2, Text: "Usage", ID: "user-content-usage"},
+	}, ctx.Headers)
+
+	toc := markup.TableOfContents(ctx.Headers, 2)
+	assert.Len(t, toc, 3)
+	assert.Equal(t, "Usage", toc[2].Text)
+	assert.Empty(t, markup.TableOfContents(ctx.Headers, 0))
+}
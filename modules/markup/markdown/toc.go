@@ -8,12 +8,13 @@ import (
 	"fmt"
 	"net/url"
 
+	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/translation/i18n"
 
 	"github.com/yuin/goldmark/ast"
 )
 
-func createTOCNode(toc []Header, lang string) ast.Node {
+func createTOCNode(toc []markup.Header, lang string) ast.Node {
 	details := NewDetails()
 	summary := NewSummary()
 
@@ -21,12 +22,7 @@ func createTOCNode(toc []Header, lang string) ast.Node {
 	details.AppendChild(details, summary)
 	ul := ast.NewList('-')
 	details.AppendChild(details, ul)
-	currentLevel := 6
-	for _, header := range toc {
-		if header.Level < currentLevel {
-			currentLevel = header.Level
-		}
-	}
+	currentLevel := markup.TopLevel(toc)
 	for _, header := range toc {
 		for currentLevel > header.Level {
 			ul = ul.Parent().(*ast.List)
@@ -44,7 +44,9 @@ type RenderContext struct {
 	DefaultLink   string
 	GitRepo       *git.Repository
 	ShaExistCache map[string]bool
-	cancelFn      func()
+	// Headers are the headers of the rendered document, they are only collected by the renderers supporting it
+	Headers  []Header
+	cancelFn func()
 }
 
 // Cancel runs any cleanup functions that have been registered for this Ctx
@@ -0,0 +1,40 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package markup
+
+// Header holds the data about a header of a rendered document
+type Header struct {
+	Level int
+	Text  string
+	// ID is the id of the element of the header, which the anchors of the header link to
+	ID string
+}
+
+// TopLevel returns the highest level of the headers, which is the lowest number
+func TopLevel(headers []Header) int {
+	topLevel := 6
+	for _, header := range headers {
+		if header.Level < topLevel {
+			topLevel = header.Level
+		}
+	}
+	return topLevel
+}
+
+// TableOfContents returns the headers of the table of contents with depth levels of headers,
+// starting at the top level of the headers. A depth of 0 or less results in no table of contents.
+func TableOfContents(headers []Header, depth int) []Header {
+	if depth <= 0 || len(headers) == 0 {
+		return nil
+	}
+	maxLevel := TopLevel(headers) + depth - 1
+	toc := make([]Header, 0, len(headers))
+	for _, header := range headers {
+		if header.Level <= maxLevel {
+			toc = append(toc, header)
+		}
+	}
+	return toc
+}
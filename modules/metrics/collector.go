@@ -6,6 +6,8 @@ package metrics
 
 import (
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/githttp"
 
 	"github.com/prometheus/client_golang/prometheus"
 )
@@ -20,6 +22,8 @@ type Collector struct {
 	Attachments        *prometheus.Desc
 	Comments           *prometheus.Desc
 	Follows            *prometheus.Desc
+	GitHTTPUploadPacks *prometheus.Desc
+	GitHTTPRejected    *prometheus.Desc
 	HookTasks          *prometheus.Desc
 	Issues             *prometheus.Desc
 	IssuesOpen         *prometheus.Desc
@@ -37,6 +41,8 @@ type Collector struct {
 	PublicKeys         *prometheus.Desc
 	Releases           *prometheus.Desc
 	Repositories       *prometheus.Desc
+	ReposMaintenance   *prometheus.Desc
+	PartialClone       *prometheus.Desc
 	Stars              *prometheus.Desc
 	Teams              *prometheus.Desc
 	UpdateTasks        *prometheus.Desc
@@ -73,6 +79,16 @@ func NewCollector() Collector {
 			"Number of Follows",
 			nil, nil,
 		),
+		GitHTTPUploadPacks: prometheus.NewDesc(
+			namespace+"git_http_upload_packs",
+			"Number of running clones and fetches over HTTP",
+			nil, nil,
+		),
+		GitHTTPRejected: prometheus.NewDesc(
+			namespace+"git_http_upload_packs_rejected_total",
+			"Number of clones and fetches over HTTP rejected by a concurrency limit",
+			[]string{"limit"}, nil,
+		),
 		HookTasks: prometheus.NewDesc(
 			namespace+"hooktasks",
 			"Number of HookTasks",
@@ -158,6 +174,16 @@ func NewCollector() Collector {
 			"Number of Repositories",
 			nil, nil,
 		),
+		ReposMaintenance: prometheus.NewDesc(
+			namespace+"repositories_maintenance",
+			"Number of Repositories pushed to since their last maintenance (pending) and whose last maintenance has failed (failed)",
+			[]string{"state"}, nil,
+		),
+		PartialClone: prometheus.NewDesc(
+			namespace+"partial_clone_enabled",
+			"Whether partial clones are served, 1 if they are",
+			nil, nil,
+		),
 		Stars: prometheus.NewDesc(
 			namespace+"stars",
 			"Number of Stars",
@@ -198,6 +224,8 @@ func (c Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.Attachments
 	ch <- c.Comments
 	ch <- c.Follows
+	ch <- c.GitHTTPUploadPacks
+	ch <- c.GitHTTPRejected
 	ch <- c.HookTasks
 	ch <- c.Issues
 	ch <- c.IssuesByLabel
@@ -215,6 +243,8 @@ func (c Collector) Describe(ch chan<- *prometheus.Desc) {
 	ch <- c.PublicKeys
 	ch <- c.Releases
 	ch <- c.Repositories
+	ch <- c.ReposMaintenance
+	ch <- c.PartialClone
 	ch <- c.Stars
 	ch <- c.Teams
 	ch <- c.UpdateTasks
@@ -252,6 +282,20 @@ func (c Collector) Collect(ch chan<- prometheus.Metric) {
 		prometheus.GaugeValue,
 		float64(stats.Counter.Follow),
 	)
+	limiter := githttp.DefaultLimiter()
+	ch <- prometheus.MustNewConstMetric(
+		c.GitHTTPUploadPacks,
+		prometheus.GaugeValue,
+		float64(limiter.Active()),
+	)
+	for _, limit := range []string{githttp.RejectedByUser, githttp.RejectedByRepo} {
+		ch <- prometheus.MustNewConstMetric(
+			c.GitHTTPRejected,
+			prometheus.CounterValue,
+			float64(limiter.Rejected(limit)),
+			limit,
+		)
+	}
 	ch <- prometheus.MustNewConstMetric(
 		c.HookTasks,
 		prometheus.GaugeValue,
@@ -343,6 +387,27 @@ func (c Collector) Collect(ch chan<- prometheus.Metric) {
 		prometheus.GaugeValue,
 		float64(stats.Counter.Repo),
 	)
+	ch <- prometheus.MustNewConstMetric(
+		c.ReposMaintenance,
+		prometheus.GaugeValue,
+		float64(stats.Counter.RepoMaintenancePending),
+		"pending",
+	)
+	ch <- prometheus.MustNewConstMetric(
+		c.ReposMaintenance,
+		prometheus.GaugeValue,
+		float64(stats.Counter.RepoMaintenanceFailed),
+		"failed",
+	)
+	partialClone := 0
+	if git.SupportPartialClone {
+		partialClone = 1
+	}
+	ch <- prometheus.MustNewConstMetric(
+		c.PartialClone,
+		prometheus.GaugeValue,
+		float64(partialClone),
+	)
 	ch <- prometheus.MustNewConstMetric(
 		c.Stars,
 		prometheus.GaugeValue,
@@ -181,6 +181,23 @@ func (a *actionNotifier) NotifyTransferRepository(doer *user_model.User, repo *r
 	}
 }
 
+func (a *actionNotifier) NotifyChangeRepositoryVisibility(doer *user_model.User, repo *repo_model.Repository) {
+	opType := models.ActionPublicizeRepo
+	if repo.IsPrivate {
+		opType = models.ActionPrivatizeRepo
+	}
+	if err := models.NotifyWatchers(&models.Action{
+		ActUserID: doer.ID,
+		ActUser:   doer,
+		OpType:    opType,
+		RepoID:    repo.ID,
+		Repo:      repo,
+		IsPrivate: repo.IsPrivate,
+	}); err != nil {
+		log.Error("NotifyWatchers: %v", err)
+	}
+}
+
 func (a *actionNotifier) NotifyCreateRepository(doer, u *user_model.User, repo *repo_model.Repository) {
 	if err := models.NotifyWatchers(&models.Action{
 		ActUserID: doer.ID,
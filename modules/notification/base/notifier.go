@@ -6,6 +6,7 @@ package base
 
 import (
 	"code.gitea.io/gitea/models"
+	discussion_model "code.gitea.io/gitea/models/discussion"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -21,6 +22,7 @@ type Notifier interface {
 	NotifyForkRepository(doer *user_model.User, oldRepo, repo *repo_model.Repository)
 	NotifyRenameRepository(doer *user_model.User, repo *repo_model.Repository, oldRepoName string)
 	NotifyTransferRepository(doer *user_model.User, repo *repo_model.Repository, oldOwnerName string)
+	NotifyChangeRepositoryVisibility(doer *user_model.User, repo *repo_model.Repository)
 	NotifyNewIssue(issue *models.Issue, mentions []*user_model.User)
 	NotifyIssueChangeStatus(*user_model.User, *models.Issue, *models.Comment, bool)
 	NotifyDeleteIssue(*user_model.User, *models.Issue)
@@ -55,6 +57,14 @@ type Notifier interface {
 	NotifySyncCreateRef(doer *user_model.User, repo *repo_model.Repository, refType, refFullName, refID string)
 	NotifySyncDeleteRef(doer *user_model.User, repo *repo_model.Repository, refType, refFullName string)
 	NotifyRepoPendingTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository)
+	NotifyRepoTransferScheduled(doer *user_model.User, repo *repo_model.Repository, transfer *models.RepoTransfer)
 	NotifyPackageCreate(doer *user_model.User, pd *packages_model.PackageDescriptor)
 	NotifyPackageDelete(doer *user_model.User, pd *packages_model.PackageDescriptor)
+	NotifyPreviewEnvironmentCreate(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment)
+	NotifyPreviewEnvironmentUpdate(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment)
+	NotifyPreviewEnvironmentDelete(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment)
+	NotifyNewDiscussion(doer *user_model.User, d *discussion_model.Discussion)
+	NotifyUpdateDiscussion(doer *user_model.User, d *discussion_model.Discussion)
+	NotifyDeleteDiscussion(doer *user_model.User, d *discussion_model.Discussion)
+	NotifyNewDiscussionComment(doer *user_model.User, d *discussion_model.Discussion, c *discussion_model.Comment)
 }
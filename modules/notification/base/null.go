@@ -6,6 +6,7 @@ package base
 
 import (
 	"code.gitea.io/gitea/models"
+	discussion_model "code.gitea.io/gitea/models/discussion"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -159,6 +160,10 @@ func (*NullNotifier) NotifyRenameRepository(doer *user_model.User, repo *repo_mo
 func (*NullNotifier) NotifyTransferRepository(doer *user_model.User, repo *repo_model.Repository, oldOwnerName string) {
 }
 
+// NotifyChangeRepositoryVisibility places a place holder function
+func (*NullNotifier) NotifyChangeRepositoryVisibility(doer *user_model.User, repo *repo_model.Repository) {
+}
+
 // NotifySyncPushCommits places a place holder function
 func (*NullNotifier) NotifySyncPushCommits(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
 }
@@ -175,6 +180,10 @@ func (*NullNotifier) NotifySyncDeleteRef(doer *user_model.User, repo *repo_model
 func (*NullNotifier) NotifyRepoPendingTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository) {
 }
 
+// NotifyRepoTransferScheduled places a place holder function
+func (*NullNotifier) NotifyRepoTransferScheduled(doer *user_model.User, repo *repo_model.Repository, transfer *models.RepoTransfer) {
+}
+
 // NotifyPackageCreate places a place holder function
 func (*NullNotifier) NotifyPackageCreate(doer *user_model.User, pd *packages_model.PackageDescriptor) {
 }
@@ -182,3 +191,31 @@ func (*NullNotifier) NotifyPackageCreate(doer *user_model.User, pd *packages_mod
 // NotifyPackageDelete places a place holder function
 func (*NullNotifier) NotifyPackageDelete(doer *user_model.User, pd *packages_model.PackageDescriptor) {
 }
+
+// NotifyPreviewEnvironmentCreate places a place holder function
+func (*NullNotifier) NotifyPreviewEnvironmentCreate(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+}
+
+// NotifyPreviewEnvironmentUpdate places a place holder function
+func (*NullNotifier) NotifyPreviewEnvironmentUpdate(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+}
+
+// NotifyPreviewEnvironmentDelete places a place holder function
+func (*NullNotifier) NotifyPreviewEnvironmentDelete(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+}
+
+// NotifyNewDiscussion places a place holder function
+func (*NullNotifier) NotifyNewDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+}
+
+// NotifyUpdateDiscussion places a place holder function
+func (*NullNotifier) NotifyUpdateDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+}
+
+// NotifyDeleteDiscussion places a place holder function
+func (*NullNotifier) NotifyDeleteDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+}
+
+// NotifyNewDiscussionComment places a place holder function
+func (*NullNotifier) NotifyNewDiscussionComment(doer *user_model.User, d *discussion_model.Discussion, c *discussion_model.Comment) {
+}
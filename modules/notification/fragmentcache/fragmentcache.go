@@ -0,0 +1,33 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package fragmentcache
+
+import (
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/cache"
+	"code.gitea.io/gitea/modules/notification/base"
+)
+
+type fragmentCacheNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &fragmentCacheNotifier{}
+
+// NewNotifier create a new fragmentCacheNotifier notifier, which invalidates the cached fragments showing changed entities
+func NewNotifier() base.Notifier {
+	return &fragmentCacheNotifier{}
+}
+
+func (*fragmentCacheNotifier) NotifyDeleteRepository(doer *user_model.User, repo *repo_model.Repository) {
+	cache.InvalidateFragments(cache.FragmentRepo, repo.ID)
+}
+
+func (*fragmentCacheNotifier) NotifyIssueChangeTitle(doer *user_model.User, issue *models.Issue, oldTitle string) {
+	// the titles of issues are shown by the feed entries of the repository
+	cache.InvalidateFragments(cache.FragmentRepo, issue.RepoID)
+}
@@ -5,11 +5,15 @@
 package indexer
 
 import (
+	"strings"
+
 	"code.gitea.io/gitea/models"
+	discussion_model "code.gitea.io/gitea/models/discussion"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	discussion_indexer "code.gitea.io/gitea/modules/indexer/discussions"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	stats_indexer "code.gitea.io/gitea/modules/indexer/stats"
 	"code.gitea.io/gitea/modules/log"
@@ -109,6 +113,7 @@ func (r *indexerNotifier) NotifyDeleteComment(doer *user_model.User, comment *mo
 
 func (r *indexerNotifier) NotifyDeleteRepository(doer *user_model.User, repo *repo_model.Repository) {
 	issue_indexer.DeleteRepoIssueIndexer(repo)
+	discussion_indexer.DeleteRepoDiscussionIndexer(repo)
 	if setting.Indexer.RepoIndexerEnabled {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
@@ -124,8 +129,18 @@ func (r *indexerNotifier) NotifyMigrateRepository(doer, u *user_model.User, repo
 	}
 }
 
+// isIndexedRef returns whether a push to the ref changes the code index, i.e. whether
+// it is the default branch or one of the branches matching REPO_INDEXER_BRANCHES
+func isIndexedRef(repo *repo_model.Repository, refFullName string) bool {
+	if !strings.HasPrefix(refFullName, git.BranchPrefix) {
+		return false
+	}
+	branch := strings.TrimPrefix(refFullName, git.BranchPrefix)
+	return branch == repo.DefaultBranch || code_indexer.IsIndexedBranch(branch)
+}
+
 func (r *indexerNotifier) NotifyPushCommits(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
-	if setting.Indexer.RepoIndexerEnabled && opts.RefFullName == git.BranchPrefix+repo.DefaultBranch {
+	if setting.Indexer.RepoIndexerEnabled && isIndexedRef(repo, opts.RefFullName) {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
 	if err := stats_indexer.UpdateRepoIndexer(repo); err != nil {
@@ -133,8 +148,15 @@ func (r *indexerNotifier) NotifyPushCommits(pusher *user_model.User, repo *repo_
 	}
 }
 
+func (r *indexerNotifier) NotifyDeleteRef(doer *user_model.User, repo *repo_model.Repository, refType, refFullName string) {
+	// the index of a deleted branch is dropped when the repository is reindexed
+	if setting.Indexer.RepoIndexerEnabled && refType == "branch" && isIndexedRef(repo, refFullName) {
+		code_indexer.UpdateRepoIndexer(repo)
+	}
+}
+
 func (r *indexerNotifier) NotifySyncPushCommits(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
-	if setting.Indexer.RepoIndexerEnabled && opts.RefFullName == git.BranchPrefix+repo.DefaultBranch {
+	if setting.Indexer.RepoIndexerEnabled && isIndexedRef(repo, opts.RefFullName) {
 		code_indexer.UpdateRepoIndexer(repo)
 	}
 	if err := stats_indexer.UpdateRepoIndexer(repo); err != nil {
@@ -153,3 +175,19 @@ func (r *indexerNotifier) NotifyIssueChangeTitle(doer *user_model.User, issue *m
 func (r *indexerNotifier) NotifyIssueChangeRef(doer *user_model.User, issue *models.Issue, oldRef string) {
 	issue_indexer.UpdateIssueIndexer(issue)
 }
+
+func (r *indexerNotifier) NotifyNewDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+	discussion_indexer.UpdateDiscussionIndexer(d)
+}
+
+func (r *indexerNotifier) NotifyUpdateDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+	discussion_indexer.UpdateDiscussionIndexer(d)
+}
+
+func (r *indexerNotifier) NotifyDeleteDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+	discussion_indexer.DeleteDiscussionIndexer(d.ID)
+}
+
+func (r *indexerNotifier) NotifyNewDiscussionComment(doer *user_model.User, d *discussion_model.Discussion, c *discussion_model.Comment) {
+	discussion_indexer.UpdateDiscussionIndexer(d)
+}
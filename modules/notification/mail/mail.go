@@ -209,3 +209,7 @@ func (m *mailNotifier) NotifyRepoPendingTransfer(doer, newOwner *user_model.User
 		log.Error("NotifyRepoPendingTransfer: %v", err)
 	}
 }
+
+func (m *mailNotifier) NotifyRepoTransferScheduled(doer *user_model.User, repo *repo_model.Repository, transfer *models.RepoTransfer) {
+	mailer.SendRepoTransferScheduledMail(doer, repo, transfer)
+}
@@ -6,11 +6,13 @@ package notification
 
 import (
 	"code.gitea.io/gitea/models"
+	discussion_model "code.gitea.io/gitea/models/discussion"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/notification/action"
 	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/notification/fragmentcache"
 	"code.gitea.io/gitea/modules/notification/indexer"
 	"code.gitea.io/gitea/modules/notification/mail"
 	"code.gitea.io/gitea/modules/notification/ui"
@@ -36,6 +38,9 @@ func NewContext() {
 	RegisterNotifier(indexer.NewNotifier())
 	RegisterNotifier(webhook.NewNotifier())
 	RegisterNotifier(action.NewNotifier())
+	if setting.CacheService.Fragment.Enabled {
+		RegisterNotifier(fragmentcache.NewNotifier())
+	}
 }
 
 // NotifyCreateIssueComment notifies issue comment related message to notifiers
@@ -238,6 +243,13 @@ func NotifyTransferRepository(doer *user_model.User, repo *repo_model.Repository
 	}
 }
 
+// NotifyChangeRepositoryVisibility notifies that a repository has been made public or private
+func NotifyChangeRepositoryVisibility(doer *user_model.User, repo *repo_model.Repository) {
+	for _, notifier := range notifiers {
+		notifier.NotifyChangeRepositoryVisibility(doer, repo)
+	}
+}
+
 // NotifyDeleteRepository notifies delete repository to notifiers
 func NotifyDeleteRepository(doer *user_model.User, repo *repo_model.Repository) {
 	for _, notifier := range notifiers {
@@ -308,6 +320,13 @@ func NotifyRepoPendingTransfer(doer, newOwner *user_model.User, repo *repo_model
 	}
 }
 
+// NotifyRepoTransferScheduled notifies that the cutover of an accepted transfer has been scheduled to notifiers
+func NotifyRepoTransferScheduled(doer *user_model.User, repo *repo_model.Repository, transfer *models.RepoTransfer) {
+	for _, notifier := range notifiers {
+		notifier.NotifyRepoTransferScheduled(doer, repo, transfer)
+	}
+}
+
 // NotifyPackageCreate notifies creation of a package to notifiers
 func NotifyPackageCreate(doer *user_model.User, pd *packages_model.PackageDescriptor) {
 	for _, notifier := range notifiers {
@@ -321,3 +340,52 @@ func NotifyPackageDelete(doer *user_model.User, pd *packages_model.PackageDescri
 		notifier.NotifyPackageDelete(doer, pd)
 	}
 }
+
+// NotifyPreviewEnvironmentCreate notifies the registration of a preview environment of a pull request to notifiers
+func NotifyPreviewEnvironmentCreate(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPreviewEnvironmentCreate(doer, pr, env)
+	}
+}
+
+// NotifyPreviewEnvironmentUpdate notifies a change of a preview environment of a pull request to notifiers
+func NotifyPreviewEnvironmentUpdate(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPreviewEnvironmentUpdate(doer, pr, env)
+	}
+}
+
+// NotifyPreviewEnvironmentDelete notifies the removal of a preview environment of a pull request to notifiers
+func NotifyPreviewEnvironmentDelete(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyPreviewEnvironmentDelete(doer, pr, env)
+	}
+}
+
+// NotifyNewDiscussion notifies the creation of a discussion to notifiers
+func NotifyNewDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+	for _, notifier := range notifiers {
+		notifier.NotifyNewDiscussion(doer, d)
+	}
+}
+
+// NotifyUpdateDiscussion notifies a change of a discussion or its comments to notifiers
+func NotifyUpdateDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+	for _, notifier := range notifiers {
+		notifier.NotifyUpdateDiscussion(doer, d)
+	}
+}
+
+// NotifyDeleteDiscussion notifies the deletion of a discussion to notifiers
+func NotifyDeleteDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+	for _, notifier := range notifiers {
+		notifier.NotifyDeleteDiscussion(doer, d)
+	}
+}
+
+// NotifyNewDiscussionComment notifies a new comment of a discussion to notifiers
+func NotifyNewDiscussionComment(doer *user_model.User, d *discussion_model.Discussion, c *discussion_model.Comment) {
+	for _, notifier := range notifiers {
+		notifier.NotifyNewDiscussionComment(doer, d, c)
+	}
+}
@@ -7,6 +7,7 @@ package ui
 import (
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
+	discussion_model "code.gitea.io/gitea/models/discussion"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/graceful"
@@ -240,3 +241,15 @@ func (ns *notificationService) NotifyRepoPendingTransfer(doer, newOwner *user_mo
 		log.Error("NotifyRepoPendingTransfer: %v", err)
 	}
 }
+
+func (ns *notificationService) NotifyNewDiscussion(doer *user_model.User, d *discussion_model.Discussion) {
+	if err := models.CreateOrUpdateDiscussionNotifications(d, doer.ID); err != nil {
+		log.Error("CreateOrUpdateDiscussionNotifications: %v", err)
+	}
+}
+
+func (ns *notificationService) NotifyNewDiscussionComment(doer *user_model.User, d *discussion_model.Discussion, c *discussion_model.Comment) {
+	if err := models.CreateOrUpdateDiscussionNotifications(d, doer.ID); err != nil {
+		log.Error("CreateOrUpdateDiscussionNotifications: %v", err)
+	}
+}
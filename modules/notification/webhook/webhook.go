@@ -135,6 +135,22 @@ func (m *webhookNotifier) NotifyDeleteRepository(doer *user_model.User, repo *re
 	}
 }
 
+func (m *webhookNotifier) NotifyChangeRepositoryVisibility(doer *user_model.User, repo *repo_model.Repository) {
+	action := api.HookRepoPublicized
+	if repo.IsPrivate {
+		action = api.HookRepoPrivatized
+	}
+
+	if err := webhook_services.PrepareWebhooks(repo, webhook.HookEventRepository, &api.RepositoryPayload{
+		Action:       action,
+		Repository:   convert.ToRepo(repo, perm.AccessModeOwner),
+		Organization: convert.ToUser(repo.MustOwner(), nil),
+		Sender:       convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", repo.ID, err)
+	}
+}
+
 func (m *webhookNotifier) NotifyMigrateRepository(doer, u *user_model.User, repo *repo_model.Repository) {
 	// Add to hook queue for created repo after session commit.
 	if err := webhook_services.PrepareWebhooks(repo, webhook.HookEventRepository, &api.RepositoryPayload{
@@ -890,3 +906,43 @@ func notifyPackage(sender *user_model.User, pd *packages_model.PackageDescriptor
 		log.Error("PrepareWebhooks: %v", err)
 	}
 }
+
+func (m *webhookNotifier) NotifyPreviewEnvironmentCreate(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+	notifyPreviewEnvironment(doer, pr, env, api.HookPreviewEnvironmentCreated)
+}
+
+func (m *webhookNotifier) NotifyPreviewEnvironmentUpdate(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+	notifyPreviewEnvironment(doer, pr, env, api.HookPreviewEnvironmentUpdated)
+}
+
+func (m *webhookNotifier) NotifyPreviewEnvironmentDelete(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) {
+	notifyPreviewEnvironment(doer, pr, env, api.HookPreviewEnvironmentDeleted)
+}
+
+func notifyPreviewEnvironment(doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment, action api.HookPreviewEnvironmentAction) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("webhook.notifyPreviewEnvironment Pull[%d] #%d in [%d]: %s", pr.ID, pr.Index, pr.BaseRepoID, env.Name))
+	defer finished()
+
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		log.Error("LoadIssue: %v", err)
+		return
+	}
+	if err := pr.Issue.LoadRepo(ctx); err != nil {
+		log.Error("pr.Issue.LoadRepo: %v", err)
+		return
+	}
+	if err := env.LoadCreator(ctx); err != nil {
+		log.Error("LoadCreator: %v", err)
+		return
+	}
+
+	if err := webhook_services.PrepareWebhooks(pr.Issue.Repo, webhook.HookEventPreviewEnvironment, &api.PreviewEnvironmentPayload{
+		Action:             action,
+		PreviewEnvironment: convert.ToPreviewEnvironment(env, nil),
+		PullRequest:        convert.ToAPIPullRequest(ctx, pr, nil),
+		Repository:         convert.ToRepo(pr.Issue.Repo, perm.AccessModeNone),
+		Sender:             convert.ToUser(doer, nil),
+	}); err != nil {
+		log.Error("PrepareWebhooks [repo_id: %d]: %v", pr.BaseRepoID, err)
+	}
+}
@@ -0,0 +1,217 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/hashicorp/go-version"
+)
+
+// YankedProperty is set if a package version is yanked
+const YankedProperty = "cargo.yanked"
+
+var (
+	// ErrInvalidName indicates an invalid package name
+	ErrInvalidName = errors.New("package name is invalid")
+	// ErrInvalidVersion indicates an invalid package version
+	ErrInvalidVersion = errors.New("package version is invalid")
+	// ErrInvalidUpload indicates an invalid publish request body
+	ErrInvalidUpload = errors.New("upload is invalid")
+)
+
+// maxMetadataSize is the maximum size of the JSON metadata of a publish request
+const maxMetadataSize = 1 << 20
+
+var namePattern = regexp.MustCompile(`\A[a-zA-Z][a-zA-Z0-9-_]{0,63}\z`)
+
+// Package represents a Cargo package
+type Package struct {
+	Name        string
+	Version     string
+	Metadata    *Metadata
+	Content     io.Reader
+	ContentSize int64
+}
+
+// Metadata represents the metadata of a Cargo package
+type Metadata struct {
+	Description      string              `json:"description,omitempty"`
+	Authors          []string            `json:"authors,omitempty"`
+	Keywords         []string            `json:"keywords,omitempty"`
+	Categories       []string            `json:"categories,omitempty"`
+	License          string              `json:"license,omitempty"`
+	ProjectURL       string              `json:"project_url,omitempty"`
+	RepositoryURL    string              `json:"repository_url,omitempty"`
+	DocumentationURL string              `json:"documentation_url,omitempty"`
+	Readme           string              `json:"readme,omitempty"`
+	Dependencies     []*Dependency       `json:"dependencies,omitempty"`
+	Features         map[string][]string `json:"features,omitempty"`
+	Links            string              `json:"links,omitempty"`
+}
+
+// Dependency represents a dependency of a Cargo package as it is stored in the index
+type Dependency struct {
+	Name            string   `json:"name"`
+	Req             string   `json:"req"`
+	Features        []string `json:"features"`
+	Optional        bool     `json:"optional"`
+	DefaultFeatures bool     `json:"default_features"`
+	Target          *string  `json:"target"`
+	Kind            string   `json:"kind"`
+	Registry        *string  `json:"registry"`
+	Package         *string  `json:"package"`
+}
+
+// FullName returns the name of the dependency and the name of the renamed package if set
+func (d *Dependency) FullName() string {
+	if d.Package == nil {
+		return d.Name
+	}
+	return *d.Package + " (" + d.Name + ")"
+}
+
+// publishMetadata is the metadata sent by "cargo publish"
+// https://doc.rust-lang.org/cargo/reference/registries.html#publish
+type publishMetadata struct {
+	Name          string              `json:"name"`
+	Vers          string              `json:"vers"`
+	Deps          []publishDependency `json:"deps"`
+	Features      map[string][]string `json:"features"`
+	Authors       []string            `json:"authors"`
+	Description   string              `json:"description"`
+	Documentation string              `json:"documentation"`
+	Homepage      string              `json:"homepage"`
+	Readme        string              `json:"readme"`
+	Keywords      []string            `json:"keywords"`
+	Categories    []string            `json:"categories"`
+	License       string              `json:"license"`
+	Repository    string              `json:"repository"`
+	Links         string              `json:"links"`
+}
+
+type publishDependency struct {
+	Name               string   `json:"name"`
+	VersionReq         string   `json:"version_req"`
+	Features           []string `json:"features"`
+	Optional           bool     `json:"optional"`
+	DefaultFeatures    bool     `json:"default_features"`
+	Target             *string  `json:"target"`
+	Kind               string   `json:"kind"`
+	Registry           *string  `json:"registry"`
+	ExplicitNameInToml string   `json:"explicit_name_in_toml"`
+}
+
+// ParsePackage reads the metadata of a publish request and returns a reader for the .crate file.
+// The body consists of the length of the JSON metadata, the metadata, the length of the .crate file and the file itself.
+// The lengths are 32 bit little endian integers.
+func ParsePackage(r io.Reader) (*Package, error) {
+	var size uint32
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, ErrInvalidUpload
+	}
+	if size > maxMetadataSize {
+		return nil, ErrInvalidUpload
+	}
+
+	var meta publishMetadata
+	if err := json.NewDecoder(io.LimitReader(r, int64(size))).Decode(&meta); err != nil {
+		return nil, ErrInvalidUpload
+	}
+
+	if !namePattern.MatchString(meta.Name) {
+		return nil, ErrInvalidName
+	}
+	v, err := version.NewSemver(meta.Vers)
+	if err != nil {
+		return nil, ErrInvalidVersion
+	}
+
+	if err := binary.Read(r, binary.LittleEndian, &size); err != nil {
+		return nil, ErrInvalidUpload
+	}
+
+	deps := make([]*Dependency, 0, len(meta.Deps))
+	for _, dep := range meta.Deps {
+		d := &Dependency{
+			Name:            dep.Name,
+			Req:             dep.VersionReq,
+			Features:        dep.Features,
+			Optional:        dep.Optional,
+			DefaultFeatures: dep.DefaultFeatures,
+			Target:          dep.Target,
+			Kind:            dep.Kind,
+			Registry:        dep.Registry,
+		}
+		if d.Features == nil {
+			d.Features = []string{}
+		}
+		// a renamed dependency is listed with the local name, the real name is stored in "package"
+		if dep.ExplicitNameInToml != "" {
+			name := dep.Name
+			d.Name = dep.ExplicitNameInToml
+			d.Package = &name
+		}
+		deps = append(deps, d)
+	}
+
+	m := &Metadata{
+		Description:   meta.Description,
+		Authors:       meta.Authors,
+		Keywords:      meta.Keywords,
+		Categories:    meta.Categories,
+		License:       meta.License,
+		Readme:        meta.Readme,
+		Dependencies:  deps,
+		Features:      meta.Features,
+		Links:         meta.Links,
+		RepositoryURL: meta.Repository,
+	}
+	if validation.IsValidURL(meta.Homepage) {
+		m.ProjectURL = meta.Homepage
+	}
+	if validation.IsValidURL(meta.Documentation) {
+		m.DocumentationURL = meta.Documentation
+	}
+	if !validation.IsValidURL(m.RepositoryURL) {
+		m.RepositoryURL = ""
+	}
+	if m.Features == nil {
+		m.Features = map[string][]string{}
+	}
+
+	return &Package{
+		Name:        meta.Name,
+		Version:     v.String(),
+		Metadata:    m,
+		Content:     io.LimitReader(r, int64(size)),
+		ContentSize: int64(size),
+	}, nil
+}
+
+// IndexPath returns the path of the index file of the package relative to the index root
+// https://doc.rust-lang.org/cargo/reference/registry-index.html#index-files
+func IndexPath(name string) string {
+	name = strings.ToLower(name)
+	switch len(name) {
+	case 0:
+		return ""
+	case 1:
+		return "1/" + name
+	case 2:
+		return "2/" + name
+	case 3:
+		return "3/" + name[:1] + "/" + name
+	default:
+		return name[:2] + "/" + name[2:4] + "/" + name
+	}
+}
@@ -0,0 +1,136 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	description = "Package Description"
+	author      = "KN4CK3R"
+	homepage    = "https://gitea.io/"
+	license     = "MIT"
+)
+
+func TestParsePackage(t *testing.T) {
+	createPackage := func(name, version string) io.Reader {
+		metadata := `{
+   "name":"` + name + `",
+   "vers":"` + version + `",
+   "description":"` + description + `",
+   "authors": ["` + author + `"],
+   "deps":[
+      {
+         "name":"dep",
+         "version_req":"1.0",
+         "features":null,
+         "optional":false,
+         "default_features":true,
+         "kind":"normal"
+      },
+      {
+         "name":"original",
+         "version_req":"^2",
+         "optional":true,
+         "default_features":false,
+         "kind":"dev",
+         "explicit_name_in_toml":"renamed"
+      }
+   ],
+   "homepage":"` + homepage + `",
+   "license":"` + license + `",
+   "features":{"default":["dep"]}
+}`
+
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+		buf.WriteString(metadata)
+		binary.Write(&buf, binary.LittleEndian, uint32(4))
+		buf.WriteString("test")
+		return &buf
+	}
+
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "0test", "test.test", "test test"} {
+			cp, err := ParsePackage(createPackage(name, "1.0.0"))
+			assert.Nil(t, cp)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "1.", "-1.0", "1.0.0/1"} {
+			cp, err := ParsePackage(createPackage("test", version))
+			assert.Nil(t, cp)
+			assert.ErrorIs(t, err, ErrInvalidVersion)
+		}
+	})
+
+	t.Run("InvalidUpload", func(t *testing.T) {
+		cp, err := ParsePackage(bytes.NewReader([]byte{1, 0}))
+		assert.Nil(t, cp)
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+
+		metadata := `{"name":"test","vers":"1.0.0"}`
+		var buf bytes.Buffer
+		binary.Write(&buf, binary.LittleEndian, uint32(len(metadata)))
+		buf.WriteString(metadata)
+		cp, err = ParsePackage(&buf)
+		assert.Nil(t, cp)
+		assert.ErrorIs(t, err, ErrInvalidUpload)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		cp, err := ParsePackage(createPackage("test-package", "1.0.0"))
+		assert.NoError(t, err)
+		assert.NotNil(t, cp)
+
+		assert.Equal(t, "test-package", cp.Name)
+		assert.Equal(t, "1.0.0", cp.Version)
+		assert.Equal(t, description, cp.Metadata.Description)
+		assert.Equal(t, []string{author}, cp.Metadata.Authors)
+		assert.Equal(t, homepage, cp.Metadata.ProjectURL)
+		assert.Equal(t, license, cp.Metadata.License)
+		assert.Equal(t, map[string][]string{"default": {"dep"}}, cp.Metadata.Features)
+		assert.Len(t, cp.Metadata.Dependencies, 2)
+		dep := cp.Metadata.Dependencies[0]
+		assert.Equal(t, "dep", dep.Name)
+		assert.Equal(t, "1.0", dep.Req)
+		assert.Equal(t, "normal", dep.Kind)
+		assert.Empty(t, dep.Features)
+		assert.NotNil(t, dep.Features)
+		assert.Nil(t, dep.Package)
+		dep = cp.Metadata.Dependencies[1]
+		assert.Equal(t, "renamed", dep.Name)
+		assert.Equal(t, "original", *dep.Package)
+		assert.Equal(t, "original (renamed)", dep.FullName())
+		assert.True(t, dep.Optional)
+		assert.Equal(t, int64(4), cp.ContentSize)
+
+		content, err := io.ReadAll(cp.Content)
+		assert.NoError(t, err)
+		assert.Equal(t, "test", string(content))
+	})
+}
+
+func TestIndexPath(t *testing.T) {
+	cases := map[string]string{
+		"a":     "1/a",
+		"ab":    "2/ab",
+		"abc":   "3/a/abc",
+		"Abcd":  "ab/cd/abcd",
+		"abcde": "ab/cd/abcde",
+	}
+	for name, expected := range cases {
+		assert.Equal(t, expected, IndexPath(name), fmt.Sprintf("name: %s", name))
+	}
+}
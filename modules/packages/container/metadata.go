@@ -20,6 +20,7 @@ const (
 	PropertyMediaType         = "container.mediatype"
 	PropertyManifestTagged    = "container.manifest.tagged"
 	PropertyManifestReference = "container.manifest.reference"
+	PropertyManifestSubject   = "container.manifest.subject"
 
 	DefaultPlatform = "linux/amd64"
 
@@ -34,8 +35,9 @@ const (
 type ImageType string
 
 const (
-	TypeOCI  ImageType = "oci"
-	TypeHelm ImageType = "helm"
+	TypeOCI      ImageType = "oci"
+	TypeHelm     ImageType = "helm"
+	TypeArtifact ImageType = "artifact"
 )
 
 // Name gets the name of the image type
@@ -43,6 +45,8 @@ func (it ImageType) Name() string {
 	switch it {
 	case TypeHelm:
 		return "Helm Chart"
+	case TypeArtifact:
+		return "OCI Artifact"
 	default:
 		return "OCI / Docker"
 	}
@@ -62,6 +66,8 @@ type Metadata struct {
 	Labels           map[string]string `json:"labels,omitempty"`
 	ImageLayers      []string          `json:"layer_creation,omitempty"`
 	MultiArch        map[string]string `json:"multiarch,omitempty"`
+	ArtifactType     string            `json:"artifact_type,omitempty"`
+	Annotations      map[string]string `json:"annotations,omitempty"`
 }
 
 // ParseImageConfig parses the metadata of an image config
@@ -70,10 +76,29 @@ func ParseImageConfig(mediaType oci.MediaType, r io.Reader) (*Metadata, error) {
 		return parseHelmConfig(r)
 	}
 
+	// artifacts may use any media type for the config, the content is not interpreted
+	if mediaType != "" && !mediaType.IsImageConfig() {
+		return &Metadata{
+			Type:         TypeArtifact,
+			ArtifactType: string(mediaType),
+		}, nil
+	}
+
 	// fallback to OCI Image Config
 	return parseOCIImageConfig(r)
 }
 
+// SetManifestInfo sets the artifact type and the annotations of the manifest which references the config
+func (m *Metadata) SetManifestInfo(artifactType string, annotations map[string]string) {
+	if artifactType != "" {
+		m.ArtifactType = artifactType
+	}
+	m.Annotations = annotations
+	if m.Description == "" {
+		m.Description = annotations[labelDescription]
+	}
+}
+
 func parseOCIImageConfig(r io.Reader) (*Metadata, error) {
 	var image oci.Image
 	if err := json.NewDecoder(r).Decode(&image); err != nil {
@@ -24,7 +24,7 @@ func TestParseImageConfig(t *testing.T) {
 
 	configOCI := `{"config": {"labels": {"` + labelAuthors + `": "` + author + `", "` + labelLicenses + `": "` + license + `", "` + labelURL + `": "` + projectURL + `", "` + labelSource + `": "` + repositoryURL + `", "` + labelDocumentation + `": "` + documentationURL + `", "` + labelDescription + `": "` + description + `"}}, "history": [{"created_by": "do it 1"}, {"created_by": "dummy #(nop) do it 2"}]}`
 
-	metadata, err := ParseImageConfig(oci.MediaType(oci.MediaTypeImageManifest), strings.NewReader(configOCI))
+	metadata, err := ParseImageConfig(oci.MediaType(oci.MediaTypeImageConfig), strings.NewReader(configOCI))
 	assert.NoError(t, err)
 
 	assert.Equal(t, TypeOCI, metadata.Type)
@@ -59,4 +59,17 @@ func TestParseImageConfig(t *testing.T) {
 	assert.ElementsMatch(t, []string{author}, metadata.Authors)
 	assert.Equal(t, projectURL, metadata.ProjectURL)
 	assert.Equal(t, repositoryURL, metadata.RepositoryURL)
+
+	artifactType := "application/vnd.example.sbom.v1+json"
+
+	metadata, err = ParseImageConfig(oci.MediaType(artifactType), strings.NewReader("not json"))
+	assert.NoError(t, err)
+
+	assert.Equal(t, TypeArtifact, metadata.Type)
+	assert.Equal(t, artifactType, metadata.ArtifactType)
+	assert.Empty(t, metadata.Platform)
+
+	metadata.SetManifestInfo("", map[string]string{labelDescription: description})
+	assert.Equal(t, artifactType, metadata.ArtifactType)
+	assert.Equal(t, description, metadata.Description)
 }
@@ -13,6 +13,8 @@ const (
 	MediaTypeImageIndex         = "application/vnd.oci.image.index.v1+json"
 	MediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
 	MediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	MediaTypeImageConfig        = "application/vnd.oci.image.config.v1+json"
+	MediaTypeDockerImageConfig  = "application/vnd.docker.container.image.v1+json"
 )
 
 type MediaType string
@@ -34,3 +36,10 @@ func (m MediaType) IsImageIndex() bool {
 	s := string(m)
 	return strings.EqualFold(s, MediaTypeDockerManifestList) || strings.EqualFold(s, MediaTypeImageIndex)
 }
+
+// IsImageConfig tests if the media type is an image config
+// Artifacts use other (arbitrary) media types for their config.
+func (m MediaType) IsImageConfig() bool {
+	s := string(m)
+	return strings.EqualFold(s, MediaTypeDockerImageConfig) || strings.EqualFold(s, MediaTypeImageConfig)
+}
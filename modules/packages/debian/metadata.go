@@ -0,0 +1,286 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"net/mail"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/validation"
+
+	"github.com/klauspost/compress/zstd"
+	"github.com/ulikunitz/xz"
+)
+
+const (
+	PropertyDistribution = "debian.distribution"
+	PropertyComponent    = "debian.component"
+	PropertyArchitecture = "debian.architecture"
+	PropertyControl      = "debian.control"
+
+	SettingKeyPrivate = "debian.key.private"
+	SettingKeyPublic  = "debian.key.public"
+
+	// RepositoryPackage and RepositoryVersion name the internal package version which stores the repository index files
+	RepositoryPackage = "_debian"
+	RepositoryVersion = "_repository"
+
+	controlTar = "control.tar"
+	arMagic    = "!<arch>\n"
+)
+
+var (
+	// ErrInvalidArchive indicates an invalid .deb archive
+	ErrInvalidArchive = errors.New("package archive is invalid")
+	// ErrMissingControlFile indicates a missing control file
+	ErrMissingControlFile = errors.New("control file is missing")
+	// ErrUnsupportedCompression indicates an unsupported compression of the control archive
+	ErrUnsupportedCompression = errors.New("unsupported compression algorithm")
+	// ErrInvalidName indicates an invalid package name
+	ErrInvalidName = errors.New("package name is invalid")
+	// ErrInvalidVersion indicates an invalid package version
+	ErrInvalidVersion = errors.New("package version is invalid")
+	// ErrInvalidArchitecture indicates an invalid package architecture
+	ErrInvalidArchitecture = errors.New("package architecture is invalid")
+)
+
+var (
+	// https://www.debian.org/doc/debian-policy/ch-controlfields.html#source
+	namePattern = regexp.MustCompile(`\A[a-z0-9][a-z0-9+\-.]+\z`)
+	// https://www.debian.org/doc/debian-policy/ch-controlfields.html#version
+	versionPattern = regexp.MustCompile(`\A(?:[0-9]:)?[a-zA-Z0-9.+~]+(?:-[a-zA-Z0-9.+\-~]+)?\z`)
+	// distributions and components are used as path segments of the repository
+	repositoryNamePattern = regexp.MustCompile(`\A[a-zA-Z0-9][a-zA-Z0-9._\-]*\z`)
+)
+
+// Package represents a Debian package
+type Package struct {
+	Name         string
+	Version      string
+	Architecture string
+	Control      string
+	Metadata     *Metadata
+}
+
+// Metadata represents the metadata of a Debian package
+type Metadata struct {
+	Maintainer   string   `json:"maintainer,omitempty"`
+	ProjectURL   string   `json:"project_url,omitempty"`
+	Description  string   `json:"description,omitempty"`
+	Dependencies []string `json:"dependencies,omitempty"`
+}
+
+// IsValidRepositoryName checks if the name can be used as distribution or component
+func IsValidRepositoryName(name string) bool {
+	return repositoryNamePattern.MatchString(name)
+}
+
+// PackageCompositeKey returns the composite key of a package file in the pool of a component
+func PackageCompositeKey(distribution, component string) string {
+	return distribution + "|" + component
+}
+
+// IndexCompositeKey returns the composite key of the index files of an architecture
+func IndexCompositeKey(distribution, component, architecture string) string {
+	return distribution + "|" + component + "|" + architecture
+}
+
+// PackageFilename returns the name of the package file in the pool
+func PackageFilename(name, version, architecture string) string {
+	return name + "_" + version + "_" + architecture + ".deb"
+}
+
+// ParsePackage parses the Debian package file
+// https://manpages.debian.org/bullseye/dpkg-dev/deb.5.en.html
+func ParsePackage(r io.Reader) (*Package, error) {
+	magic := make([]byte, len(arMagic))
+	if _, err := io.ReadFull(r, magic); err != nil || string(magic) != arMagic {
+		return nil, ErrInvalidArchive
+	}
+
+	for {
+		name, size, err := readArHeader(r)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if strings.HasPrefix(name, controlTar) {
+			var inner io.Reader
+			switch name[len(controlTar):] {
+			case "":
+				inner = r
+			case ".gz":
+				gzr, err := gzip.NewReader(r)
+				if err != nil {
+					return nil, err
+				}
+				defer gzr.Close()
+
+				inner = gzr
+			case ".xz":
+				xzr, err := xz.NewReader(r)
+				if err != nil {
+					return nil, err
+				}
+
+				inner = xzr
+			case ".zst":
+				zr, err := zstd.NewReader(r)
+				if err != nil {
+					return nil, err
+				}
+				defer zr.Close()
+
+				inner = zr
+			default:
+				return nil, ErrUnsupportedCompression
+			}
+
+			tr := tar.NewReader(inner)
+			for {
+				hd, err := tr.Next()
+				if err == io.EOF {
+					break
+				}
+				if err != nil {
+					return nil, err
+				}
+
+				if hd.Typeflag != tar.TypeReg {
+					continue
+				}
+
+				if hd.FileInfo().Name() == "control" {
+					return ParseControlFile(tr)
+				}
+			}
+			return nil, ErrMissingControlFile
+		}
+
+		// entries are aligned to even offsets
+		if _, err := io.CopyN(io.Discard, r, size+size%2); err != nil {
+			return nil, ErrInvalidArchive
+		}
+	}
+
+	return nil, ErrMissingControlFile
+}
+
+// readArHeader reads the 60 bytes header of an ar archive entry and returns the name and the size of the entry
+func readArHeader(r io.Reader) (string, int64, error) {
+	header := make([]byte, 60)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if err == io.EOF {
+			return "", 0, io.EOF
+		}
+		return "", 0, ErrInvalidArchive
+	}
+	if string(header[58:60]) != "`\n" {
+		return "", 0, ErrInvalidArchive
+	}
+
+	name := strings.TrimSuffix(strings.TrimSpace(string(header[0:16])), "/")
+	size, err := strconv.ParseInt(strings.TrimSpace(string(header[48:58])), 10, 64)
+	if err != nil || size < 0 {
+		return "", 0, ErrInvalidArchive
+	}
+	return name, size, nil
+}
+
+// ParseControlFile parses a Debian control file to retrieve the metadata
+func ParseControlFile(r io.Reader) (*Package, error) {
+	p := &Package{
+		Metadata: &Metadata{},
+	}
+
+	key := ""
+	var depends strings.Builder
+	var control strings.Builder
+
+	s := bufio.NewScanner(io.TeeReader(r, &control))
+	for s.Scan() {
+		line := s.Text()
+
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" {
+			continue
+		}
+
+		if line[0] == ' ' || line[0] == '\t' {
+			switch key {
+			case "Description":
+				p.Metadata.Description += "\n" + trimmed
+			case "Depends":
+				depends.WriteString(trimmed)
+			}
+		} else {
+			parts := strings.SplitN(trimmed, ":", 2)
+			if len(parts) < 2 {
+				continue
+			}
+
+			key = parts[0]
+			value := strings.TrimSpace(parts[1])
+			switch key {
+			case "Package":
+				p.Name = value
+			case "Version":
+				p.Version = value
+			case "Architecture":
+				p.Architecture = value
+			case "Maintainer":
+				a, err := mail.ParseAddress(value)
+				if err != nil || a.Name == "" {
+					p.Metadata.Maintainer = value
+				} else {
+					p.Metadata.Maintainer = a.Name
+				}
+			case "Description":
+				p.Metadata.Description = value
+			case "Depends":
+				depends.WriteString(value)
+			case "Homepage":
+				if validation.IsValidURL(value) {
+					p.Metadata.ProjectURL = value
+				}
+			}
+		}
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+
+	if !namePattern.MatchString(p.Name) {
+		return nil, ErrInvalidName
+	}
+	if !versionPattern.MatchString(p.Version) {
+		return nil, ErrInvalidVersion
+	}
+	if p.Architecture == "" || strings.ContainsAny(p.Architecture, " \t/") {
+		return nil, ErrInvalidArchitecture
+	}
+
+	dependencies := strings.Split(depends.String(), ",")
+	for i := range dependencies {
+		dependencies[i] = strings.TrimSpace(dependencies[i])
+	}
+	if len(dependencies) > 1 || dependencies[0] != "" {
+		p.Metadata.Dependencies = dependencies
+	}
+
+	p.Control = strings.TrimSpace(control.String())
+
+	return p, nil
+}
@@ -0,0 +1,156 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	packageName         = "gitea"
+	packageVersion      = "0:1.0.1-te~st"
+	packageArchitecture = "amd64"
+	packageAuthor       = "KN4CK3R"
+	description         = "Description with multiple lines."
+	projectURL          = "https://gitea.io"
+)
+
+func createControlFile(name, version, architecture string) string {
+	return `Package: ` + name + `
+Version: ` + version + `
+Architecture: ` + architecture + `
+Maintainer: ` + packageAuthor + ` <kn4ck3r@gitea.io>
+Homepage: ` + projectURL + `
+Depends: a,
+ b
+Description: Description
+ with multiple
+ lines.
+`
+}
+
+func createArchive(files map[string][]byte) io.Reader {
+	var buf bytes.Buffer
+	buf.WriteString(arMagic)
+	for name, content := range files {
+		fmt.Fprintf(&buf, "%-16s%-12d%-6d%-6d%-8s%-10d`\n", name, 0, 0, 0, "100644", len(content))
+		buf.Write(content)
+		if len(content)%2 != 0 {
+			buf.WriteByte('\n')
+		}
+	}
+	return &buf
+}
+
+func createControlArchive(control string) []byte {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	tw.WriteHeader(&tar.Header{
+		Name: "./control",
+		Mode: 0o600,
+		Size: int64(len(control)),
+	})
+	tw.Write([]byte(control))
+	tw.Close()
+	zw.Close()
+	return buf.Bytes()
+}
+
+func TestParsePackage(t *testing.T) {
+	t.Run("InvalidArchive", func(t *testing.T) {
+		p, err := ParsePackage(bytes.NewReader([]byte("not an ar archive")))
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrInvalidArchive)
+	})
+
+	t.Run("MissingControlFile", func(t *testing.T) {
+		data := createArchive(map[string][]byte{"debian-binary": []byte("2.0\n")})
+
+		p, err := ParsePackage(data)
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrMissingControlFile)
+	})
+
+	t.Run("UnsupportedCompression", func(t *testing.T) {
+		data := createArchive(map[string][]byte{"control.tar.foo": {}})
+
+		p, err := ParsePackage(data)
+		assert.Nil(t, p)
+		assert.ErrorIs(t, err, ErrUnsupportedCompression)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		data := createArchive(map[string][]byte{
+			"debian-binary":  []byte("2.0\n"),
+			"control.tar.gz": createControlArchive(createControlFile(packageName, packageVersion, packageArchitecture)),
+		})
+
+		p, err := ParsePackage(data)
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, packageArchitecture, p.Architecture)
+	})
+}
+
+func TestParseControlFile(t *testing.T) {
+	t.Run("InvalidName", func(t *testing.T) {
+		for _, name := range []string{"", "-cd", "ab_cd"} {
+			p, err := ParseControlFile(strings.NewReader(createControlFile(name, packageVersion, packageArchitecture)))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidName)
+		}
+	})
+
+	t.Run("InvalidVersion", func(t *testing.T) {
+		for _, version := range []string{"", "_1.0", "1.0 2"} {
+			p, err := ParseControlFile(strings.NewReader(createControlFile(packageName, version, packageArchitecture)))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidVersion)
+		}
+	})
+
+	t.Run("InvalidArchitecture", func(t *testing.T) {
+		for _, architecture := range []string{"", "amd 64", "amd/64"} {
+			p, err := ParseControlFile(strings.NewReader(createControlFile(packageName, packageVersion, architecture)))
+			assert.Nil(t, p)
+			assert.ErrorIs(t, err, ErrInvalidArchitecture)
+		}
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		content := createControlFile(packageName, packageVersion, packageArchitecture)
+		p, err := ParseControlFile(strings.NewReader(content))
+		assert.NoError(t, err)
+		assert.NotNil(t, p)
+
+		assert.Equal(t, packageName, p.Name)
+		assert.Equal(t, packageVersion, p.Version)
+		assert.Equal(t, packageArchitecture, p.Architecture)
+		assert.Equal(t, "Description\nwith multiple\nlines.", p.Metadata.Description)
+		assert.Equal(t, projectURL, p.Metadata.ProjectURL)
+		assert.Equal(t, packageAuthor, p.Metadata.Maintainer)
+		assert.Equal(t, []string{"a", "b"}, p.Metadata.Dependencies)
+		assert.Equal(t, strings.TrimSpace(content), p.Control)
+	})
+}
+
+func TestIsValidRepositoryName(t *testing.T) {
+	assert.True(t, IsValidRepositoryName("bullseye"))
+	assert.True(t, IsValidRepositoryName("stable-updates"))
+	assert.False(t, IsValidRepositoryName(""))
+	assert.False(t, IsValidRepositoryName(".hidden"))
+	assert.False(t, IsValidRepositoryName("a/b"))
+}
@@ -0,0 +1,139 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package terraform
+
+import (
+	"archive/tar"
+	"bufio"
+	"compress/gzip"
+	"errors"
+	"io"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/hashicorp/go-version"
+)
+
+var (
+	// ErrMissingModuleFile indicates a module archive without Terraform files in its root
+	ErrMissingModuleFile = errors.New("module archive contains no .tf file in its root")
+	// ErrInvalidName indicates an invalid module name
+	ErrInvalidName = errors.New("module name is invalid")
+	// ErrInvalidSystem indicates an invalid module target system
+	ErrInvalidSystem = errors.New("module system is invalid")
+	// ErrInvalidVersion indicates an invalid module version
+	ErrInvalidVersion = errors.New("module version is invalid")
+)
+
+// maxReadmeSize is the maximum size of a README file which gets stored in the metadata
+const maxReadmeSize = 1 << 20
+
+var (
+	// https://developer.hashicorp.com/terraform/internals/module-registry-protocol#module-addresses
+	namePattern   = regexp.MustCompile(`\A[0-9A-Za-z](?:[0-9A-Za-z-_]{0,62}[0-9A-Za-z])?\z`)
+	systemPattern = regexp.MustCompile(`\A[0-9a-z]{1,64}\z`)
+)
+
+// Metadata represents the metadata of a Terraform module version
+type Metadata struct {
+	Name          string `json:"name"`
+	System        string `json:"system"`
+	Description   string `json:"description,omitempty"`
+	Readme        string `json:"readme,omitempty"`
+	RepositoryURL string `json:"repository_url,omitempty"`
+	Tag           string `json:"tag,omitempty"`
+}
+
+// ValidateModuleAddress checks the name and the target system of a module
+func ValidateModuleAddress(name, system string) error {
+	if !namePattern.MatchString(name) {
+		return ErrInvalidName
+	}
+	if !systemPattern.MatchString(system) {
+		return ErrInvalidSystem
+	}
+	return nil
+}
+
+// PackageName returns the name of the package which stores the module
+func PackageName(name, system string) string {
+	return name + "/" + system
+}
+
+// ParseVersion parses the module version of an upload or a git tag.
+// A leading "v" is removed and only the last path element of a tag is used,
+// so "v1.2.0" and "modules/network/v1.2.0" both result in "1.2.0".
+func ParseVersion(s string) (string, error) {
+	s = strings.TrimPrefix(path.Base(s), "v")
+
+	v, err := version.NewSemver(s)
+	if err != nil {
+		return "", ErrInvalidVersion
+	}
+	return v.String(), nil
+}
+
+// ParseModuleArchive parses a .tar.gz module archive. The Terraform files must be in the root of the archive.
+func ParseModuleArchive(r io.Reader) (*Metadata, error) {
+	gzr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, err
+	}
+	defer gzr.Close()
+
+	metadata := &Metadata{}
+	hasModuleFile := false
+
+	tr := tar.NewReader(gzr)
+	for {
+		hd, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if hd.Typeflag != tar.TypeReg {
+			continue
+		}
+
+		name := strings.TrimPrefix(hd.Name, "./")
+		if strings.Contains(name, "/") {
+			continue
+		}
+
+		if strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tf.json") {
+			hasModuleFile = true
+		} else if strings.EqualFold(name, "README.md") {
+			readme, err := io.ReadAll(io.LimitReader(tr, maxReadmeSize))
+			if err != nil {
+				return nil, err
+			}
+			metadata.Readme = string(readme)
+			metadata.Description = parseDescription(metadata.Readme)
+		}
+	}
+
+	if !hasModuleFile {
+		return nil, ErrMissingModuleFile
+	}
+
+	return metadata, nil
+}
+
+// parseDescription returns the first paragraph line of the README which is no heading
+func parseDescription(readme string) string {
+	scanner := bufio.NewScanner(strings.NewReader(readme))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "[![") {
+			continue
+		}
+		return line
+	}
+	return ""
+}
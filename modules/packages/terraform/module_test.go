@@ -0,0 +1,93 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package terraform
+
+import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
+	"io"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	description = "Creates a network with subnets."
+	readme      = "# Network\n\n" + description + "\n"
+)
+
+func createArchive(files map[string]string) io.Reader {
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(zw)
+	for name, content := range files {
+		hdr := &tar.Header{
+			Name: name,
+			Mode: 0o600,
+			Size: int64(len(content)),
+		}
+		tw.WriteHeader(hdr)
+		tw.Write([]byte(content))
+	}
+	tw.Close()
+	zw.Close()
+	return &buf
+}
+
+func TestParseModuleArchive(t *testing.T) {
+	t.Run("MissingModuleFile", func(t *testing.T) {
+		data := createArchive(map[string]string{
+			"README.md":       readme,
+			"modules/main.tf": "",
+		})
+
+		metadata, err := ParseModuleArchive(data)
+		assert.Nil(t, metadata)
+		assert.ErrorIs(t, err, ErrMissingModuleFile)
+	})
+
+	t.Run("Valid", func(t *testing.T) {
+		data := createArchive(map[string]string{
+			"./main.tf":  `resource "null_resource" "test" {}`,
+			"README.md":  readme,
+			"outputs.tf": "",
+		})
+
+		metadata, err := ParseModuleArchive(data)
+		assert.NoError(t, err)
+		assert.NotNil(t, metadata)
+		assert.Equal(t, readme, metadata.Readme)
+		assert.Equal(t, description, metadata.Description)
+	})
+}
+
+func TestParseVersion(t *testing.T) {
+	cases := map[string]string{
+		"1.2.0":                  "1.2.0",
+		"v1.2.0":                 "1.2.0",
+		"modules/network/v2.0.1": "2.0.1",
+		"1.0.0-rc.1":             "1.0.0-rc.1",
+	}
+	for s, expected := range cases {
+		v, err := ParseVersion(s)
+		assert.NoError(t, err, s)
+		assert.Equal(t, expected, v)
+	}
+
+	for _, s := range []string{"", "latest", "release-1"} {
+		_, err := ParseVersion(s)
+		assert.ErrorIs(t, err, ErrInvalidVersion, s)
+	}
+}
+
+func TestValidateModuleAddress(t *testing.T) {
+	assert.NoError(t, ValidateModuleAddress("network", "aws"))
+	assert.NoError(t, ValidateModuleAddress("my-network_1", "azurerm"))
+	assert.ErrorIs(t, ValidateModuleAddress("-network", "aws"), ErrInvalidName)
+	assert.ErrorIs(t, ValidateModuleAddress("net.work", "aws"), ErrInvalidName)
+	assert.ErrorIs(t, ValidateModuleAddress("network", "AWS"), ErrInvalidSystem)
+	assert.ErrorIs(t, ValidateModuleAddress("network", "aws-east"), ErrInvalidSystem)
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/translation"
+)
+
+// The rules of the password policy a password can violate
+var (
+	ErrMinLength  = errors.New("password is too short")
+	ErrComplexity = errors.New("password does not meet the complexity requirements")
+	ErrIsPwned    = errors.New("password has been exposed in a data breach, see https://haveibeenpwned.com/Passwords")
+)
+
+// ErrIsPwnedRequest is returned if it couldn't be checked whether a password has been exposed in a data breach,
+// such a password is rejected until it can be checked
+type ErrIsPwnedRequest struct {
+	err error
+}
+
+// IsErrIsPwnedRequest checks if an error is a ErrIsPwnedRequest.
+func IsErrIsPwnedRequest(err error) bool {
+	_, ok := err.(ErrIsPwnedRequest)
+	return ok
+}
+
+func (err ErrIsPwnedRequest) Error() string {
+	return fmt.Sprintf("using the breached password check failed: %v", err.err)
+}
+
+func (err ErrIsPwnedRequest) Unwrap() error {
+	return err.err
+}
+
+// Validate checks that a new password complies with the password policy: its minimum length, its required
+// character classes and, if it is enabled, that it hasn't been exposed in a data breach
+func Validate(ctx context.Context, pwd string) error {
+	if len(pwd) < setting.MinPasswordLength {
+		return fmt.Errorf("%w, it must be at least %d characters", ErrMinLength, setting.MinPasswordLength)
+	}
+	if !IsComplexEnough(pwd) {
+		return ErrComplexity
+	}
+	pwned, err := IsPwned(ctx, pwd)
+	if err != nil {
+		log.Error("Unable to check whether the password has been exposed in a data breach: %v", err)
+		return ErrIsPwnedRequest{err}
+	}
+	if pwned {
+		return ErrIsPwned
+	}
+	return nil
+}
+
+// PolicyErrorMessage returns the translated message of the rule of the password policy which the error of Validate
+// is about, it may contain HTML
+func PolicyErrorMessage(locale translation.Locale, err error) string {
+	switch {
+	case errors.Is(err, ErrMinLength):
+		return locale.Tr("auth.password_too_short", setting.MinPasswordLength)
+	case errors.Is(err, ErrComplexity):
+		return BuildComplexityError(locale)
+	case errors.Is(err, ErrIsPwned):
+		return locale.Tr("auth.password_pwned")
+	case IsErrIsPwnedRequest(err):
+		return locale.Tr("auth.password_pwned_err")
+	}
+	return err.Error()
+}
@@ -0,0 +1,33 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package password
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	matchComplexityOnce.Do(func() {})
+	defer func(minLength int) {
+		setting.MinPasswordLength = minLength
+	}(setting.MinPasswordLength)
+	setting.MinPasswordLength = 8
+
+	testComplextity([]string{"lower", "digit"})
+	defer testComplextity([]string{"off"})
+
+	err := Validate(context.Background(), "abc123")
+	assert.True(t, errors.Is(err, ErrMinLength))
+	assert.EqualError(t, err, "password is too short, it must be at least 8 characters")
+
+	assert.ErrorIs(t, Validate(context.Background(), "abcdefgh"), ErrComplexity)
+	assert.NoError(t, Validate(context.Background(), "abcd1234"))
+}
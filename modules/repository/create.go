@@ -56,6 +56,9 @@ func CreateRepository(doer, u *user_model.User, opts models.CreateRepoOptions) (
 		TrustModel:                      opts.TrustModel,
 		IsMirror:                        opts.IsMirror,
 	}
+	if opts.AutoInit {
+		repo.License = opts.License
+	}
 
 	var rollbackRepo *repo_model.Repository
 
@@ -0,0 +1,61 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// EncryptAtRest encrypts a value stored in the database with the master key of the server.
+// The value is authenticated with AES-GCM and returned as a hex string.
+func EncryptAtRest(value string) (string, error) {
+	gcm, err := masterKeyCipher()
+	if err != nil {
+		return "", err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(gcm.Seal(nonce, nonce, []byte(value), nil)), nil
+}
+
+// DecryptAtRest decrypts a value encrypted by EncryptAtRest
+func DecryptAtRest(cipherhex string) (string, error) {
+	gcm, err := masterKeyCipher()
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := hex.DecodeString(cipherhex)
+	if err != nil {
+		return "", err
+	}
+	if len(ciphertext) < gcm.NonceSize() {
+		return "", errors.New("ciphertext too short")
+	}
+	nonce, ciphertext := ciphertext[:gcm.NonceSize()], ciphertext[gcm.NonceSize():]
+	plaintext, err := gcm.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+func masterKeyCipher() (cipher.AEAD, error) {
+	keyHash := sha256.Sum256([]byte(setting.MasterKey))
+	block, err := aes.NewCipher(keyHash[:])
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
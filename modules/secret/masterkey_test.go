@@ -0,0 +1,33 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secret
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncryptDecryptAtRest(t *testing.T) {
+	defer func(masterKey string) {
+		setting.MasterKey = masterKey
+	}(setting.MasterKey)
+	setting.MasterKey = "foo"
+
+	hex, err := EncryptAtRest("baz")
+	assert.NoError(t, err)
+	str, err := DecryptAtRest(hex)
+	assert.NoError(t, err)
+	assert.Equal(t, "baz", str)
+
+	// a different master key fails the authentication
+	setting.MasterKey = "bar"
+	_, err = DecryptAtRest(hex)
+	assert.Error(t, err)
+	_, err = DecryptAtRest("ab")
+	assert.Error(t, err)
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Actions settings
+var (
+	Actions = struct {
+		Storage
+		Enabled                   bool
+		ZombieTimeout             time.Duration
+		JobTokenLifetime          time.Duration
+		SecretsForInstanceRunners bool
+		MaxArtifactSize           int64
+		MaxLogLinesPerRequest     int
+	}{
+		Enabled:               false,
+		ZombieTimeout:         10 * time.Minute,
+		JobTokenLifetime:      3 * time.Hour,
+		MaxArtifactSize:       1 << 30,
+		MaxLogLinesPerRequest: 1000,
+	}
+)
+
+func newActions() {
+	sec := Cfg.Section("actions")
+	if err := sec.MapTo(&Actions); err != nil {
+		log.Fatal("Failed to map Actions settings: %v", err)
+	}
+
+	Actions.Storage = getStorage("actions_artifacts", "", nil)
+	Actions.ZombieTimeout = sec.Key("ZOMBIE_TIMEOUT").MustDuration(10 * time.Minute)
+	Actions.JobTokenLifetime = sec.Key("JOB_TOKEN_LIFETIME").MustDuration(3 * time.Hour)
+	Actions.SecretsForInstanceRunners = sec.Key("SECRETS_FOR_INSTANCE_RUNNERS").MustBool(false)
+
+	Actions.MaxArtifactSize = 1 << 30
+	if maxArtifactSize := sec.Key("MAX_ARTIFACT_SIZE").MustString(""); maxArtifactSize != "" {
+		size, err := humanize.ParseBytes(maxArtifactSize)
+		if err != nil {
+			log.Fatal("Invalid [actions] MAX_ARTIFACT_SIZE %q: %v", maxArtifactSize, err)
+		}
+		Actions.MaxArtifactSize = int64(size)
+	}
+	Actions.MaxLogLinesPerRequest = sec.Key("MAX_LOG_LINES_PER_REQUEST").MustInt(1000)
+}
@@ -4,6 +4,20 @@
 
 package setting
 
+import (
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/dustin/go-humanize"
+)
+
+// The actions the expire_attachments cron task can apply to expired attachments
+const (
+	AttachmentExpiredActionDelete = "delete"
+	AttachmentExpiredActionCold   = "cold"
+)
+
 // Attachment settings
 var Attachment = struct {
 	Storage
@@ -11,14 +25,31 @@ var Attachment = struct {
 	MaxSize      int64
 	MaxFiles     int
 	Enabled      bool
+
+	// ColdStorage keeps the files of the attachments the expire_attachments cron task moved out of Storage
+	ColdStorage   Storage
+	OrphanMaxAge  time.Duration
+	MaxAge        time.Duration
+	ExpiredAction string
+	MaxRepoSize   int64
+
+	// ImageOptimization re-encodes the images pasted into comments in the background,
+	// this strips their metadata and shrinks them to fit into MaxWidth x MaxHeight if these aren't 0
+	ImageOptimization struct {
+		Enabled     bool
+		MaxWidth    int
+		MaxHeight   int
+		JPEGQuality int
+	}
 }{
 	Storage: Storage{
 		ServeDirect: false,
 	},
-	AllowedTypes: "image/jpeg,image/png,application/zip,application/gzip",
-	MaxSize:      4,
-	MaxFiles:     5,
-	Enabled:      true,
+	AllowedTypes:  "image/jpeg,image/png,application/zip,application/gzip",
+	MaxSize:       4,
+	MaxFiles:      5,
+	Enabled:       true,
+	ExpiredAction: AttachmentExpiredActionDelete,
 }
 
 func newAttachmentService() {
@@ -26,9 +57,30 @@ func newAttachmentService() {
 	storageType := sec.Key("STORAGE_TYPE").MustString("")
 
 	Attachment.Storage = getStorage("attachments", storageType, sec)
+	Attachment.ColdStorage = getStorage("attachments_cold", "", nil)
 
 	Attachment.AllowedTypes = sec.Key("ALLOWED_TYPES").MustString(".docx,.gif,.gz,.jpeg,.jpg,.mp4,.log,.pdf,.png,.pptx,.txt,.xlsx,.zip")
 	Attachment.MaxSize = sec.Key("MAX_SIZE").MustInt64(4)
 	Attachment.MaxFiles = sec.Key("MAX_FILES").MustInt(5)
 	Attachment.Enabled = sec.Key("ENABLED").MustBool(true)
+
+	Attachment.OrphanMaxAge = sec.Key("ORPHAN_MAX_AGE").MustDuration(0)
+	Attachment.MaxAge = sec.Key("MAX_AGE").MustDuration(0)
+	Attachment.ExpiredAction = sec.Key("EXPIRED_ACTION").In(AttachmentExpiredActionDelete, []string{AttachmentExpiredActionDelete, AttachmentExpiredActionCold})
+	Attachment.MaxRepoSize = 0
+	if maxRepoSize := sec.Key("MAX_REPO_SIZE").MustString(""); maxRepoSize != "" {
+		size, err := humanize.ParseBytes(maxRepoSize)
+		if err != nil {
+			log.Fatal("Invalid [attachment] MAX_REPO_SIZE %q: %v", maxRepoSize, err)
+		}
+		Attachment.MaxRepoSize = int64(size)
+	}
+
+	Attachment.ImageOptimization.Enabled = sec.Key("IMAGE_OPTIMIZATION_ENABLED").MustBool(false)
+	Attachment.ImageOptimization.MaxWidth = sec.Key("IMAGE_OPTIMIZATION_MAX_WIDTH").MustInt(0)
+	Attachment.ImageOptimization.MaxHeight = sec.Key("IMAGE_OPTIMIZATION_MAX_HEIGHT").MustInt(0)
+	Attachment.ImageOptimization.JPEGQuality = sec.Key("IMAGE_OPTIMIZATION_JPEG_QUALITY").MustInt(85)
+	if q := Attachment.ImageOptimization.JPEGQuality; q < 1 || q > 100 {
+		log.Fatal("Invalid [attachment] IMAGE_OPTIMIZATION_JPEG_QUALITY %d, it must be between 1 and 100", q)
+	}
 }
@@ -0,0 +1,58 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Audit settings
+var Audit = struct {
+	Enabled       bool
+	RetentionDays int
+	Forwarders    []string
+	Syslog        struct {
+		Network string
+		Address string
+		Tag     string
+	}
+	HTTP struct {
+		URL                 string
+		AuthorizationHeader string
+		Timeout             time.Duration
+	}
+}{
+	Enabled:       false,
+	RetentionDays: 365,
+}
+
+func newAuditService() {
+	sec := Cfg.Section("audit")
+	Audit.Enabled = sec.Key("ENABLED").MustBool(false)
+	Audit.RetentionDays = sec.Key("RETENTION_DAYS").MustInt(365)
+
+	Audit.Forwarders = Audit.Forwarders[:0]
+	for _, name := range sec.Key("FORWARDERS").Strings(",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "":
+		case "syslog", "http":
+			Audit.Forwarders = append(Audit.Forwarders, name)
+		default:
+			log.Error("Unknown audit forwarder %q, supported forwarders are syslog and http", name)
+		}
+	}
+
+	Audit.Syslog.Network = sec.Key("SYSLOG_NETWORK").MustString("")
+	Audit.Syslog.Address = sec.Key("SYSLOG_ADDRESS").MustString("")
+	Audit.Syslog.Tag = sec.Key("SYSLOG_TAG").MustString("gitea")
+
+	Audit.HTTP.URL = sec.Key("HTTP_URL").MustString("")
+	Audit.HTTP.AuthorizationHeader = sec.Key("HTTP_AUTHORIZATION_HEADER").MustString("")
+	Audit.HTTP.Timeout = sec.Key("HTTP_TIMEOUT").MustDuration(10 * time.Second)
+}
@@ -29,6 +29,11 @@ var CacheService = struct {
 		TTL          time.Duration `ini:"ITEM_TTL"`
 		CommitsCount int64
 	} `ini:"cache.last_commit"`
+
+	Fragment struct {
+		Enabled bool
+		TTL     time.Duration `ini:"ITEM_TTL"`
+	} `ini:"cache.fragment"`
 }{
 	Cache: Cache{
 		Enabled:  true,
@@ -45,6 +50,13 @@ var CacheService = struct {
 		TTL:          8760 * time.Hour,
 		CommitsCount: 1000,
 	},
+	Fragment: struct {
+		Enabled bool
+		TTL     time.Duration `ini:"ITEM_TTL"`
+	}{
+		Enabled: true,
+		TTL:     time.Hour,
+	},
 }
 
 // MemcacheMaxTTL represents the maximum memcache TTL
@@ -56,10 +68,10 @@ func newCacheService() {
 		log.Fatal("Failed to map Cache settings: %v", err)
 	}
 
-	CacheService.Adapter = sec.Key("ADAPTER").In("memory", []string{"memory", "redis", "memcache", "twoqueue"})
+	CacheService.Adapter = sec.Key("ADAPTER").In("memory", []string{"memory", "redis", "memcache", "twoqueue", "twotier"})
 	switch CacheService.Adapter {
 	case "memory":
-	case "redis", "memcache":
+	case "redis", "memcache", "twotier":
 		CacheService.Conn = strings.Trim(sec.Key("HOST").String(), "\" ")
 	case "twoqueue":
 		CacheService.Conn = strings.TrimSpace(sec.Key("HOST").String())
@@ -90,6 +102,13 @@ func newCacheService() {
 	if CacheService.LastCommit.Enabled {
 		log.Info("Last Commit Cache Service Enabled")
 	}
+
+	if !CacheService.Enabled {
+		CacheService.Fragment.Enabled = false
+	}
+	if CacheService.Fragment.Enabled {
+		log.Info("Fragment Cache Service Enabled")
+	}
 }
 
 // TTLSeconds returns the TTLSeconds or unix timestamp for memcache
@@ -107,3 +126,11 @@ func LastCommitCacheTTLSeconds() int64 {
 	}
 	return int64(CacheService.LastCommit.TTL.Seconds())
 }
+
+// FragmentCacheTTLSeconds returns the TTLSeconds or unix timestamp for memcache
+func FragmentCacheTTLSeconds() int64 {
+	if CacheService.Adapter == "memcache" && CacheService.Fragment.TTL > MemcacheMaxTTL {
+		return time.Now().Add(CacheService.Fragment.TTL).Unix()
+	}
+	return int64(CacheService.Fragment.TTL.Seconds())
+}
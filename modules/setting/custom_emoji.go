@@ -0,0 +1,32 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/modules/log"
+)
+
+// CustomEmoji settings, the emoji which site admins upload in addition to the CUSTOM_EMOJIS of [ui]
+var (
+	CustomEmoji = struct {
+		Storage
+		MaxFileSize int64
+		MaxWidth    int
+		MaxHeight   int
+	}{
+		MaxFileSize: 262144,
+		MaxWidth:    256,
+		MaxHeight:   256,
+	}
+)
+
+func newCustomEmoji() {
+	sec := Cfg.Section("custom_emoji")
+	if err := sec.MapTo(&CustomEmoji); err != nil {
+		log.Fatal("Failed to map CustomEmoji settings: %v", err)
+	}
+
+	CustomEmoji.Storage = getStorage("custom_emojis", "", nil)
+}
@@ -13,6 +13,10 @@ import (
 	"path/filepath"
 	"strings"
 	"time"
+
+	"code.gitea.io/gitea/modules/log"
+
+	ini "gopkg.in/ini.v1"
 )
 
 var (
@@ -47,6 +51,8 @@ var (
 		MaxOpenConns      int
 		ConnMaxLifetime   time.Duration
 		IterateBufferSize int
+		IDGenerator       string
+		IDGeneratorNodeID int64
 	}{
 		Timeout:           500,
 		IterateBufferSize: 50,
@@ -97,6 +103,28 @@ func InitDBConfig() {
 	Database.LogSQL = sec.Key("LOG_SQL").MustBool(true)
 	Database.DBConnectRetries = sec.Key("DB_RETRIES").MustInt(10)
 	Database.DBConnectBackoff = sec.Key("DB_RETRY_BACKOFF").MustDuration(3 * time.Second)
+
+	Database.IDGenerator = sec.Key("ID_GENERATOR").In("autoincrement", []string{"autoincrement", "snowflake"})
+	if Database.IDGenerator == "snowflake" && Database.UseMSSQL {
+		log.Warn("ID_GENERATOR = snowflake is not supported by MSSQL, falling back to autoincrement")
+		Database.IDGenerator = "autoincrement"
+	}
+	nodeID, err := parseIDGeneratorNodeID(sec)
+	if err != nil {
+		log.Fatal("Invalid [database] config: %v", err)
+	}
+	Database.IDGeneratorNodeID = nodeID
+}
+
+// maxIDGeneratorNodeID is the largest node ID fitting into the 5 bits reserved for it in snowflake IDs
+const maxIDGeneratorNodeID = 31
+
+func parseIDGeneratorNodeID(sec *ini.Section) (int64, error) {
+	nodeID := sec.Key("ID_GENERATOR_NODE_ID").MustInt64(0)
+	if nodeID < 0 || nodeID > maxIDGeneratorNodeID {
+		return 0, fmt.Errorf("ID_GENERATOR_NODE_ID must be between 0 and %d, got %d", maxIDGeneratorNodeID, nodeID)
+	}
+	return nodeID, nil
 }
 
 // DBConnStr returns database connection string
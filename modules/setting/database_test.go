@@ -8,6 +8,7 @@ import (
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	ini "gopkg.in/ini.v1"
 )
 
 func Test_parsePostgreSQLHostPort(t *testing.T) {
@@ -92,3 +93,24 @@ func Test_getPostgreSQLConnectionString(t *testing.T) {
 		assert.Equal(t, test.Output, connStr)
 	}
 }
+
+func Test_parseIDGeneratorNodeID(t *testing.T) {
+	for iniStr, expected := range map[string]int64{
+		"":                            0,
+		"ID_GENERATOR_NODE_ID = 0\n":  0,
+		"ID_GENERATOR_NODE_ID = 31\n": 31,
+	} {
+		cfg, err := ini.Load([]byte("[database]\n" + iniStr))
+		assert.NoError(t, err)
+		nodeID, err := parseIDGeneratorNodeID(cfg.Section("database"))
+		assert.NoError(t, err)
+		assert.EqualValues(t, expected, nodeID)
+	}
+
+	for _, iniStr := range []string{"ID_GENERATOR_NODE_ID = -1\n", "ID_GENERATOR_NODE_ID = 32\n"} {
+		cfg, err := ini.Load([]byte("[database]\n" + iniStr))
+		assert.NoError(t, err)
+		_, err = parseIDGeneratorNodeID(cfg.Section("database"))
+		assert.Error(t, err, iniStr)
+	}
+}
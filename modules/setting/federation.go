@@ -4,16 +4,29 @@
 
 package setting
 
-import "code.gitea.io/gitea/modules/log"
+import (
+	"code.gitea.io/gitea/modules/hostmatcher"
+	"code.gitea.io/gitea/modules/log"
+)
 
 // Federation settings
 var (
 	Federation = struct {
 		Enabled             bool
 		ShareUserStatistics bool
+		NodeInfoEnabled     bool
+		WebfingerEnabled    bool
+		DeliveryEnabled     bool
+		AllowedDomains      string
+		BlockedDomains      string
+		AllowedDomainsList  *hostmatcher.HostMatchList `ini:"-"`
+		BlockedDomainsList  *hostmatcher.HostMatchList `ini:"-"`
 	}{
 		Enabled:             true,
 		ShareUserStatistics: true,
+		NodeInfoEnabled:     true,
+		WebfingerEnabled:    true,
+		DeliveryEnabled:     true,
 	}
 )
 
@@ -21,4 +34,6 @@ func newFederationService() {
 	if err := Cfg.Section("federation").MapTo(&Federation); err != nil {
 		log.Fatal("Failed to map Federation settings: %v", err)
 	}
+	Federation.AllowedDomainsList = hostmatcher.ParseSimpleMatchList("federation.ALLOWED_DOMAINS", Federation.AllowedDomains)
+	Federation.BlockedDomainsList = hostmatcher.ParseSimpleMatchList("federation.BLOCKED_DOMAINS", Federation.BlockedDomains)
 }
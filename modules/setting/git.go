@@ -35,6 +35,12 @@ var Git = struct {
 		Pull    int
 		GC      int `ini:"GC"`
 	} `ini:"git.timeout"`
+	HTTPLimits struct {
+		MaxUploadPacksPerUser int
+		MaxUploadPacksPerRepo int
+		BandwidthLimit        int64
+		RetryAfter            int
+	} `ini:"git.http_limits"`
 }{
 	DisableDiffHighlight:      false,
 	MaxGitDiffLines:           1000,
@@ -64,6 +70,17 @@ var Git = struct {
 		Pull:    300,
 		GC:      60,
 	},
+	HTTPLimits: struct {
+		MaxUploadPacksPerUser int
+		MaxUploadPacksPerRepo int
+		BandwidthLimit        int64
+		RetryAfter            int
+	}{
+		MaxUploadPacksPerUser: 0,
+		MaxUploadPacksPerRepo: 0,
+		BandwidthLimit:        0,
+		RetryAfter:            60,
+	},
 }
 
 func newGit() {
@@ -22,6 +22,9 @@ var Indexer = struct {
 	IssueIndexerName string
 	StartupTimeout   time.Duration
 
+	DiscussionType string
+	DiscussionPath string
+
 	RepoIndexerEnabled bool
 	RepoType           string
 	RepoPath           string
@@ -31,12 +34,17 @@ var Indexer = struct {
 	IncludePatterns    []glob.Glob
 	ExcludePatterns    []glob.Glob
 	ExcludeVendored    bool
+	RepoBranches       []glob.Glob
+	TrigramCacheSize   int
 }{
 	IssueType:        "bleve",
 	IssuePath:        "indexers/issues.bleve",
 	IssueConnStr:     "",
 	IssueIndexerName: "gitea_issues",
 
+	DiscussionType: "bleve",
+	DiscussionPath: "indexers/discussions.bleve",
+
 	RepoIndexerEnabled: false,
 	RepoType:           "bleve",
 	RepoPath:           "indexers/repos.bleve",
@@ -44,6 +52,7 @@ var Indexer = struct {
 	RepoIndexerName:    "gitea_codes",
 	MaxIndexerFileSize: 1024 * 1024,
 	ExcludeVendored:    true,
+	TrigramCacheSize:   64,
 }
 
 func newIndexerService() {
@@ -64,9 +73,19 @@ func newIndexerService() {
 	deprecatedSetting("indexer", "ISSUE_INDEXER_QUEUE_BATCH_NUMBER", "queue.issue_indexer", "BATCH_LENGTH")
 	deprecatedSetting("indexer", "UPDATE_BUFFER_LEN", "queue.issue_indexer", "LENGTH")
 
+	Indexer.DiscussionType = sec.Key("DISCUSSION_INDEXER_TYPE").In("bleve", []string{"bleve", "db"})
+	Indexer.DiscussionPath = filepath.ToSlash(sec.Key("DISCUSSION_INDEXER_PATH").MustString(filepath.ToSlash(filepath.Join(AppDataPath, "indexers/discussions.bleve"))))
+	if !filepath.IsAbs(Indexer.DiscussionPath) {
+		Indexer.DiscussionPath = filepath.ToSlash(filepath.Join(AppWorkPath, Indexer.DiscussionPath))
+	}
+
 	Indexer.RepoIndexerEnabled = sec.Key("REPO_INDEXER_ENABLED").MustBool(false)
 	Indexer.RepoType = sec.Key("REPO_INDEXER_TYPE").MustString("bleve")
-	Indexer.RepoPath = filepath.ToSlash(sec.Key("REPO_INDEXER_PATH").MustString(filepath.ToSlash(filepath.Join(AppDataPath, "indexers/repos.bleve"))))
+	defaultRepoPath := "indexers/repos.bleve"
+	if Indexer.RepoType == "trigram" {
+		defaultRepoPath = "indexers/repos.trigram"
+	}
+	Indexer.RepoPath = filepath.ToSlash(sec.Key("REPO_INDEXER_PATH").MustString(filepath.ToSlash(filepath.Join(AppDataPath, defaultRepoPath))))
 	if !filepath.IsAbs(Indexer.RepoPath) {
 		Indexer.RepoPath = filepath.ToSlash(filepath.Join(AppWorkPath, Indexer.RepoPath))
 	}
@@ -76,6 +95,12 @@ func newIndexerService() {
 	Indexer.IncludePatterns = IndexerGlobFromString(sec.Key("REPO_INDEXER_INCLUDE").MustString(""))
 	Indexer.ExcludePatterns = IndexerGlobFromString(sec.Key("REPO_INDEXER_EXCLUDE").MustString(""))
 	Indexer.ExcludeVendored = sec.Key("REPO_INDEXER_EXCLUDE_VENDORED").MustBool(true)
+	Indexer.RepoBranches = IndexerGlobFromString(sec.Key("REPO_INDEXER_BRANCHES").MustString(""))
+	Indexer.TrigramCacheSize = sec.Key("REPO_INDEXER_TRIGRAM_CACHE_SIZE").MustInt(64)
+	if Indexer.TrigramCacheSize <= 0 {
+		log.Warn("REPO_INDEXER_TRIGRAM_CACHE_SIZE must be positive, using the default of 64")
+		Indexer.TrigramCacheSize = 64
+	}
 	Indexer.MaxIndexerFileSize = sec.Key("MAX_FILE_SIZE").MustInt64(1024 * 1024)
 	Indexer.StartupTimeout = sec.Key("STARTUP_TIMEOUT").MustDuration(30 * time.Second)
 }
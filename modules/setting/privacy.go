@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/modules/log"
+)
+
+// IP anonymization modes
+const (
+	IPAnonymizationTruncate = "truncate"
+	IPAnonymizationRemove   = "remove"
+)
+
+// Privacy settings
+var Privacy = struct {
+	IPRetentionDays int
+	IPAnonymization string
+}{
+	IPRetentionDays: 0,
+	IPAnonymization: IPAnonymizationTruncate,
+}
+
+func newPrivacyService() {
+	sec := Cfg.Section("privacy")
+	Privacy.IPRetentionDays = sec.Key("IP_RETENTION_DAYS").MustInt(0)
+	Privacy.IPAnonymization = sec.Key("IP_ANONYMIZATION").In(IPAnonymizationTruncate, []string{IPAnonymizationTruncate, IPAnonymizationRemove})
+	if Privacy.IPRetentionDays < 0 {
+		log.Warn("[privacy] IP_RETENTION_DAYS must not be negative, IP addresses are kept forever")
+		Privacy.IPRetentionDays = 0
+	}
+}
@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/modules/log"
+
+	"github.com/dustin/go-humanize"
+)
+
+// Quota settings
+var Quota = struct {
+	DefaultMaxSize int64
+}{
+	DefaultMaxSize: 0,
+}
+
+func newQuotaService() {
+	sec := Cfg.Section("quota")
+	Quota.DefaultMaxSize = 0
+	if maxSize := sec.Key("DEFAULT_MAX_SIZE").MustString(""); maxSize != "" {
+		size, err := humanize.ParseBytes(maxSize)
+		if err != nil {
+			log.Fatal("Invalid [quota] DEFAULT_MAX_SIZE %q: %v", maxSize, err)
+		}
+		Quota.DefaultMaxSize = int64(size)
+	}
+}
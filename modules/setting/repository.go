@@ -9,6 +9,7 @@ import (
 	"path"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -42,9 +43,13 @@ var (
 		PrefixArchiveFiles                      bool
 		DisableMigrations                       bool
 		DisableStars                            bool `ini:"DISABLE_STARS"`
+		EnableAccessRequests                    bool
 		DefaultBranch                           string
 		AllowAdoptionOfUnadoptedRepositories    bool
 		AllowDeleteOfUnadoptedRepositories      bool
+		TrashRetentionPeriod                    time.Duration
+		EnableDownloadStatistics                bool
+		HousekeepingMinInterval                 time.Duration
 
 		// Repository editor settings
 		Editor struct {
@@ -82,12 +87,14 @@ var (
 
 		// Issue Setting
 		Issue struct {
-			LockReasons []string
+			LockReasons                []string
+			MaxContentHistoryRevisions int
 		} `ini:"repository.issue"`
 
 		Release struct {
 			AllowedTypes     string
 			DefaultPagingNum int
+			EnableSigning    bool
 		} `ini:"repository.release"`
 
 		Signing struct {
@@ -154,7 +161,10 @@ var (
 		PrefixArchiveFiles:                      true,
 		DisableMigrations:                       false,
 		DisableStars:                            false,
+		EnableAccessRequests:                    false,
 		DefaultBranch:                           "main",
+		EnableDownloadStatistics:                false,
+		HousekeepingMinInterval:                 time.Hour,
 
 		// Repository editor settings
 		Editor: struct {
@@ -216,17 +226,21 @@ var (
 
 		// Issue settings
 		Issue: struct {
-			LockReasons []string
+			LockReasons                []string
+			MaxContentHistoryRevisions int
 		}{
-			LockReasons: strings.Split("Too heated,Off-topic,Spam,Resolved", ","),
+			LockReasons:                strings.Split("Too heated,Off-topic,Spam,Resolved", ","),
+			MaxContentHistoryRevisions: 0,
 		},
 
 		Release: struct {
 			AllowedTypes     string
 			DefaultPagingNum int
+			EnableSigning    bool
 		}{
 			AllowedTypes:     "",
 			DefaultPagingNum: 10,
+			EnableSigning:    false,
 		},
 
 		// Signing settings
@@ -0,0 +1,42 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+)
+
+// ResumableUpload settings of the uploads of release assets and generic packages via the tus protocol
+var ResumableUpload = struct {
+	Enabled           bool
+	ChunkedUploadPath string
+	MaxAge            time.Duration
+}{
+	Enabled: true,
+	MaxAge:  24 * time.Hour,
+}
+
+func newResumableUpload() {
+	sec := Cfg.Section("resumable_upload")
+	if err := sec.MapTo(&ResumableUpload); err != nil {
+		log.Fatal("Failed to map Resumable Upload settings: %v", err)
+	}
+
+	ResumableUpload.ChunkedUploadPath = filepath.ToSlash(sec.Key("CHUNKED_UPLOAD_PATH").MustString("tmp/resumable-upload"))
+	if !filepath.IsAbs(ResumableUpload.ChunkedUploadPath) {
+		ResumableUpload.ChunkedUploadPath = filepath.ToSlash(filepath.Join(AppDataPath, ResumableUpload.ChunkedUploadPath))
+	}
+
+	if !ResumableUpload.Enabled {
+		return
+	}
+	if err := os.MkdirAll(ResumableUpload.ChunkedUploadPath, os.ModePerm); err != nil {
+		log.Error("Unable to create resumable upload directory: %s (%v)", ResumableUpload.ChunkedUploadPath, err)
+	}
+}
@@ -0,0 +1,42 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/modules/log"
+)
+
+// SCIM settings
+var (
+	SCIM = struct {
+		Enabled      bool
+		Token        string
+		Organization string
+		AuthSource   string
+		// UsernameAttribute is the attribute of a provisioned user its username is derived from
+		UsernameAttribute string
+		// FullNameAttribute is the attribute of a provisioned user its full name is taken from
+		FullNameAttribute string
+	}{
+		Enabled:           false,
+		UsernameAttribute: "userName",
+		FullNameAttribute: "displayName",
+	}
+)
+
+func newSCIM() {
+	sec := Cfg.Section("scim")
+	if err := sec.MapTo(&SCIM); err != nil {
+		log.Fatal("Failed to map SCIM settings: %v", err)
+	}
+
+	SCIM.UsernameAttribute = sec.Key("USERNAME_ATTRIBUTE").In("userName", []string{"userName", "email", "externalId"})
+	SCIM.FullNameAttribute = sec.Key("FULL_NAME_ATTRIBUTE").In("displayName", []string{"displayName", "name.formatted", "name"})
+
+	if SCIM.Enabled && SCIM.Token == "" {
+		log.Error("SCIM is enabled but no TOKEN is set, SCIM will be disabled")
+		SCIM.Enabled = false
+	}
+}
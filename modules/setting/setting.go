@@ -180,6 +180,7 @@ var (
 	// Security settings
 	InstallLock                        bool
 	SecretKey                          string
+	MasterKey                          string
 	LogInRememberDays                  int
 	CookieUserName                     string
 	CookieRememberName                 string
@@ -195,6 +196,7 @@ var (
 	PasswordComplexity                 []string
 	PasswordHashAlgo                   string
 	PasswordCheckPwn                   bool
+	PasswordRotationInterval           time.Duration
 	SuccessfulTokensCacheSize          int
 
 	Camo = struct {
@@ -213,6 +215,7 @@ var (
 		FeedMaxCommitNum      int
 		FeedPagingNum         int
 		PackagesPagingNum     int
+		SitemapPagingNum      int
 		GraphMaxCommitNum     int
 		CodeCommentLines      int
 		ReactionMaxUserNum    int
@@ -266,6 +269,7 @@ var (
 		FeedMaxCommitNum:    5,
 		FeedPagingNum:       20,
 		PackagesPagingNum:   20,
+		SitemapPagingNum:    20,
 		GraphMaxCommitNum:   100,
 		CodeCommentLines:    4,
 		ReactionMaxUserNum:  10,
@@ -330,16 +334,21 @@ var (
 		EnableHardLineBreakInDocuments bool
 		CustomURLSchemes               []string `ini:"CUSTOM_URL_SCHEMES"`
 		FileExtensions                 []string
+		TOCDepth                       int `ini:"TOC_DEPTH"`
 	}{
 		EnableHardLineBreakInComments:  true,
 		EnableHardLineBreakInDocuments: false,
 		FileExtensions:                 strings.Split(".md,.markdown,.mdown,.mkd", ","),
+		TOCDepth:                       3,
 	}
 
 	// Admin settings
 	Admin struct {
 		DisableRegularOrgCreation bool
 		DefaultEmailNotification  string
+		SuspensionNotice          string
+		RequireSecondApproval     bool
+		SecondApprovalWindow      time.Duration
 	}
 
 	// Log settings
@@ -372,6 +381,8 @@ var (
 		DefaultPagingNum       int
 		DefaultGitTreesPerPage int
 		DefaultMaxBlobSize     int64
+		MaxBatchItems          int
+		MaxBatchBlobsSize      int64
 	}{
 		EnableSwagger:          true,
 		SwaggerURL:             "",
@@ -379,6 +390,8 @@ var (
 		DefaultPagingNum:       30,
 		DefaultGitTreesPerPage: 1000,
 		DefaultMaxBlobSize:     10485760,
+		MaxBatchItems:          100,
+		MaxBatchBlobsSize:      52428800,
 	}
 
 	OAuth2 = struct {
@@ -390,10 +403,14 @@ var (
 		JWTSecretBase64            string `ini:"JWT_SECRET"`
 		JWTSigningPrivateKeyFile   string `ini:"JWT_SIGNING_PRIVATE_KEY_FILE"`
 		MaxTokenLength             int
+		DeviceCodeExpirationTime   int64
+		DevicePollingInterval      int64
 	}{
 		Enable:                     true,
 		AccessTokenExpirationTime:  3600,
 		RefreshTokenExpirationTime: 730,
+		DeviceCodeExpirationTime:   900,
+		DevicePollingInterval:      5,
 		InvalidateRefreshTokens:    false,
 		JWTSigningAlgorithm:        "RS256",
 		JWTSigningPrivateKeyFile:   "jwt/private.pem",
@@ -428,6 +445,7 @@ var (
 	ShowFooterBranding         bool
 	ShowFooterVersion          bool
 	ShowFooterTemplateLoadTime bool
+	EnableSitemap              bool
 
 	// Global setting objects
 	Cfg           *ini.File
@@ -900,10 +918,13 @@ func loadFromConf(allowEmpty bool, extraConfig string) {
 
 	sec = Cfg.Section("admin")
 	Admin.DefaultEmailNotification = sec.Key("DEFAULT_EMAIL_NOTIFICATIONS").MustString("enabled")
+	Admin.RequireSecondApproval = sec.Key("REQUIRE_SECOND_APPROVAL").MustBool(false)
+	Admin.SecondApprovalWindow = sec.Key("SECOND_APPROVAL_WINDOW").MustDuration(24 * time.Hour)
 
 	sec = Cfg.Section("security")
 	InstallLock = sec.Key("INSTALL_LOCK").MustBool(false)
 	SecretKey = sec.Key("SECRET_KEY").MustString("!#@FDEWREWR&*(")
+	MasterKey = sec.Key("MASTER_KEY").MustString(SecretKey)
 	LogInRememberDays = sec.Key("LOGIN_REMEMBER_DAYS").MustInt(7)
 	CookieUserName = sec.Key("COOKIE_USERNAME").MustString("gitea_awesome")
 	CookieRememberName = sec.Key("COOKIE_REMEMBER_NAME").MustString("gitea_incredible")
@@ -925,6 +946,7 @@ func loadFromConf(allowEmpty bool, extraConfig string) {
 	PasswordHashAlgo = sec.Key("PASSWORD_HASH_ALGO").MustString("pbkdf2")
 	CSRFCookieHTTPOnly = sec.Key("CSRF_COOKIE_HTTP_ONLY").MustBool(true)
 	PasswordCheckPwn = sec.Key("PASSWORD_CHECK_PWN").MustBool(false)
+	PasswordRotationInterval = sec.Key("PASSWORD_ROTATION_INTERVAL").MustDuration(0)
 	SuccessfulTokensCacheSize = sec.Key("SUCCESSFUL_TOKENS_CACHE_SIZE").MustInt(20)
 
 	InternalToken = loadInternalToken(sec)
@@ -1024,6 +1046,14 @@ func loadFromConf(allowEmpty bool, extraConfig string) {
 
 	newPackages()
 
+	newResumableUpload()
+
+	newActions()
+
+	newCustomEmoji()
+
+	newSCIM()
+
 	if err = Cfg.Section("ui").MapTo(&UI); err != nil {
 		log.Fatal("Failed to map UI settings: %v", err)
 	} else if err = Cfg.Section("markdown").MapTo(&Markdown); err != nil {
@@ -1064,6 +1094,7 @@ func loadFromConf(allowEmpty bool, extraConfig string) {
 	ShowFooterBranding = Cfg.Section("other").Key("SHOW_FOOTER_BRANDING").MustBool(false)
 	ShowFooterVersion = Cfg.Section("other").Key("SHOW_FOOTER_VERSION").MustBool(true)
 	ShowFooterTemplateLoadTime = Cfg.Section("other").Key("SHOW_FOOTER_TEMPLATE_LOAD_TIME").MustBool(true)
+	EnableSitemap = Cfg.Section("other").Key("ENABLE_SITEMAP").MustBool(true)
 
 	UI.ShowUserEmail = Cfg.Section("ui").Key("SHOW_USER_EMAIL").MustBool(true)
 	UI.DefaultShowFullName = Cfg.Section("ui").Key("DEFAULT_SHOW_FULL_NAME").MustBool(false)
@@ -1297,6 +1328,9 @@ func NewServices() {
 	newProject()
 	newMimeTypeMap()
 	newFederationService()
+	newAuditService()
+	newPrivacyService()
+	newQuotaService()
 }
 
 // NewServicesForInstall initializes the services for install
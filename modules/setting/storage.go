@@ -86,6 +86,7 @@ func getStorage(name, typ string, targetSec *ini.Section) Storage {
 		storage.Section.Key("PATH").SetValue(storage.Path)
 	}
 	storage.Section.Key("MINIO_BASE_PATH").MustString(name + "/")
+	storage.Section.Key("WEBDAV_BASE_PATH").MustString(name + "/")
 
 	return storage
 }
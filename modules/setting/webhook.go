@@ -6,6 +6,7 @@ package setting
 
 import (
 	"net/url"
+	"time"
 
 	"code.gitea.io/gitea/modules/log"
 )
@@ -14,6 +15,9 @@ import (
 var Webhook = struct {
 	QueueLength     int
 	DeliverTimeout  int
+	MaxRetries      int
+	RetryBackoff    time.Duration
+	SecretRotation  time.Duration
 	SkipTLSVerify   bool
 	AllowedHostList string
 	Types           []string
@@ -24,6 +28,9 @@ var Webhook = struct {
 }{
 	QueueLength:    1000,
 	DeliverTimeout: 5,
+	MaxRetries:     5,
+	RetryBackoff:   time.Minute,
+	SecretRotation: 24 * time.Hour,
 	SkipTLSVerify:  false,
 	PagingNum:      10,
 	ProxyURL:       "",
@@ -34,6 +41,9 @@ func newWebhookService() {
 	sec := Cfg.Section("webhook")
 	Webhook.QueueLength = sec.Key("QUEUE_LENGTH").MustInt(1000)
 	Webhook.DeliverTimeout = sec.Key("DELIVER_TIMEOUT").MustInt(5)
+	Webhook.MaxRetries = sec.Key("MAX_RETRIES").MustInt(5)
+	Webhook.RetryBackoff = sec.Key("RETRY_BACKOFF").MustDuration(time.Minute)
+	Webhook.SecretRotation = sec.Key("SECRET_ROTATION_GRACE_PERIOD").MustDuration(24 * time.Hour)
 	Webhook.SkipTLSVerify = sec.Key("SKIP_TLS_VERIFY").MustBool()
 	Webhook.AllowedHostList = sec.Key("ALLOWED_HOST_LIST").MustString("")
 	Webhook.Types = []string{"gitea", "gogs", "slack", "discord", "dingtalk", "telegram", "msteams", "feishu", "matrix", "wechatwork", "packagist"}
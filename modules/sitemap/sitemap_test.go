@@ -0,0 +1,80 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package sitemap
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestOk(t *testing.T) {
+	testReal := func(s *Sitemap, name string, urls []URL, expected string) {
+		for _, url := range urls {
+			s.Add(url)
+		}
+		buf := &bytes.Buffer{}
+		_, err := s.WriteTo(buf)
+		assert.NoError(t, err)
+		assert.Equal(t, xml.Header+"<"+name+" xmlns=\"http://www.sitemaps.org/schemas/sitemap/0.9\">"+expected+"</"+name+">\n", buf.String())
+	}
+	test := func(urls []URL, expected string) {
+		testReal(NewSitemap(), "urlset", urls, expected)
+		testReal(NewSitemapIndex(), "sitemapindex", urls, strings.ReplaceAll(expected, "url>", "sitemap>"))
+	}
+
+	ts := time.Unix(1651322008, 0).UTC()
+
+	test(
+		[]URL{},
+		"",
+	)
+	test(
+		[]URL{
+			{URL: "https://gitea.io/test1", LastMod: &ts},
+		},
+		"<url><loc>https://gitea.io/test1</loc><lastmod>2022-04-30T12:33:28Z</lastmod></url>",
+	)
+	test(
+		[]URL{
+			{URL: "https://gitea.io/test2", LastMod: nil},
+		},
+		"<url><loc>https://gitea.io/test2</loc></url>",
+	)
+	test(
+		[]URL{
+			{URL: "https://gitea.io/test1", LastMod: &ts},
+			{URL: "https://gitea.io/test2", LastMod: nil},
+		},
+		"<url><loc>https://gitea.io/test1</loc><lastmod>2022-04-30T12:33:28Z</lastmod></url>"+
+			"<url><loc>https://gitea.io/test2</loc></url>",
+	)
+}
+
+func TestTooManyURLs(t *testing.T) {
+	s := NewSitemap()
+	for i := 0; i < 50000; i++ {
+		assert.False(t, s.IsFull())
+		s.Add(URL{URL: fmt.Sprintf("https://gitea.io/test%d", i)})
+	}
+	assert.True(t, s.IsFull())
+	s.Add(URL{URL: "https://gitea.io/test50000"})
+	buf := &bytes.Buffer{}
+	_, err := s.WriteTo(buf)
+	assert.EqualError(t, err, "The sitemap contains 50001 URLs, but only 50000 are allowed")
+}
+
+func TestSitemapTooBig(t *testing.T) {
+	s := NewSitemap()
+	s.Add(URL{URL: strings.Repeat("b", sitemapFileLimit)})
+	buf := &bytes.Buffer{}
+	_, err := s.WriteTo(buf)
+	assert.EqualError(t, err, "The sitemap has 52428931 bytes, but only 52428800 are allowed")
+}
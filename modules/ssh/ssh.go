@@ -80,6 +80,11 @@ func sessionHandler(session ssh.Session) {
 		"SSH_ORIGINAL_COMMAND="+command,
 		"SKIP_MINWINSVC=1",
 	)
+	if remote, ok := session.RemoteAddr().(*net.TCPAddr); ok {
+		if local, ok := session.LocalAddr().(*net.TCPAddr); ok {
+			cmd.Env = append(cmd.Env, fmt.Sprintf("SSH_CONNECTION=%s %d %s %d", remote.IP, remote.Port, local.IP, local.Port))
+		}
+	}
 
 	stdout, err := cmd.StdoutPipe()
 	if err != nil {
@@ -0,0 +1,125 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+)
+
+// ErrChunkOffsetMismatch is returned if a chunk doesn't start at the end of the data received so far
+var ErrChunkOffsetMismatch = errors.New("chunk offset does not match the size of the upload")
+
+// ErrChunkTooLarge is returned if a chunk would make an upload exceed its announced size
+var ErrChunkTooLarge = errors.New("chunk exceeds the size of the upload")
+
+var chunkedUploadIDPattern = regexp.MustCompile(`\A[a-zA-Z0-9\-_]+\z`)
+
+// ChunkedUploads assembles the chunks of uploads which are received over several requests in files on the local disk,
+// the complete files can then be stored by an ObjectStorage of any type
+type ChunkedUploads struct {
+	dir string
+}
+
+// NewChunkedUploads returns the chunked uploads stored in dir
+func NewChunkedUploads(dir string) *ChunkedUploads {
+	return &ChunkedUploads{dir: dir}
+}
+
+func (c *ChunkedUploads) path(id string) (string, error) {
+	if !chunkedUploadIDPattern.MatchString(id) {
+		return "", fmt.Errorf("invalid chunked upload id %q", id)
+	}
+	return filepath.Join(c.dir, id), nil
+}
+
+// Create starts an empty upload
+func (c *ChunkedUploads) Create(id string) error {
+	p, err := c.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(c.dir, os.ModePerm); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	return f.Close()
+}
+
+// Append writes a chunk starting at offset to an upload, which must be the number of bytes received so far.
+// At most limit bytes are accepted. It returns the new size of the upload, which includes the bytes written before an error.
+func (c *ChunkedUploads) Append(id string, offset, limit int64, r io.Reader) (int64, error) {
+	p, err := c.path(id)
+	if err != nil {
+		return 0, err
+	}
+	f, err := os.OpenFile(p, os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, err
+	}
+	defer f.Close()
+
+	size, err := f.Seek(0, io.SeekEnd)
+	if err != nil {
+		return 0, err
+	}
+	if size != offset {
+		return size, ErrChunkOffsetMismatch
+	}
+
+	// read one byte more than allowed to detect chunks which are too large
+	n, err := io.Copy(f, io.LimitReader(r, limit-offset+1))
+	if err != nil {
+		return size + n, err
+	}
+	if size+n > limit {
+		if err := f.Truncate(limit); err != nil {
+			return size + n, err
+		}
+		return limit, ErrChunkTooLarge
+	}
+	return size + n, nil
+}
+
+// Size returns the number of bytes received so far
+func (c *ChunkedUploads) Size(id string) (int64, error) {
+	p, err := c.path(id)
+	if err != nil {
+		return 0, err
+	}
+	fi, err := os.Stat(p)
+	if err != nil {
+		return 0, err
+	}
+	return fi.Size(), nil
+}
+
+// Open opens the data of an upload for reading
+func (c *ChunkedUploads) Open(id string) (*os.File, error) {
+	p, err := c.path(id)
+	if err != nil {
+		return nil, err
+	}
+	return os.Open(p)
+}
+
+// Remove deletes the data of an upload
+func (c *ChunkedUploads) Remove(id string) error {
+	p, err := c.path(id)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(p); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
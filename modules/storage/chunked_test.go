@@ -0,0 +1,50 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChunkedUploads(t *testing.T) {
+	uploads := NewChunkedUploads(t.TempDir())
+
+	assert.Error(t, uploads.Create("../escape"))
+
+	assert.NoError(t, uploads.Create("upload"))
+	assert.Error(t, uploads.Create("upload"))
+
+	size, err := uploads.Append("upload", 0, 10, strings.NewReader("0123"))
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4, size)
+
+	size, err = uploads.Append("upload", 2, 10, strings.NewReader("23456"))
+	assert.Equal(t, ErrChunkOffsetMismatch, err)
+	assert.EqualValues(t, 4, size)
+
+	size, err = uploads.Append("upload", 4, 10, strings.NewReader("456789abc"))
+	assert.Equal(t, ErrChunkTooLarge, err)
+	assert.EqualValues(t, 10, size)
+
+	size, err = uploads.Size("upload")
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, size)
+
+	f, err := uploads.Open("upload")
+	assert.NoError(t, err)
+	content, err := io.ReadAll(f)
+	assert.NoError(t, err)
+	assert.NoError(t, f.Close())
+	assert.Equal(t, "0123456789", string(content))
+
+	assert.NoError(t, uploads.Remove("upload"))
+	assert.NoError(t, uploads.Remove("upload"))
+	_, err = uploads.Size("upload")
+	assert.Error(t, err)
+}
@@ -15,11 +15,12 @@ type Mappable interface {
 	MapTo(v interface{}) error
 }
 
-// toConfig will attempt to convert a given configuration cfg into the provided exemplar type.
+// ToConfig will attempt to convert a given configuration cfg into the provided exemplar type.
 //
 // It will tolerate the cfg being passed as a []byte or string of a json representation of the
-// exemplar or the correct type of the exemplar itself
-func toConfig(exemplar, cfg interface{}) (interface{}, error) {
+// exemplar or the correct type of the exemplar itself.
+// Storage types registered by RegisterStorageType use it to read their configuration.
+func ToConfig(exemplar, cfg interface{}) (interface{}, error) {
 	// First of all check if we've got the same type as the exemplar - if so it's all fine.
 	if reflect.TypeOf(cfg).AssignableTo(reflect.TypeOf(exemplar)) {
 		return cfg, nil
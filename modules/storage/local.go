@@ -37,7 +37,7 @@ type LocalStorage struct {
 
 // NewLocalStorage returns a local files
 func NewLocalStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error) {
-	configInterface, err := toConfig(LocalStorageConfig{}, cfg)
+	configInterface, err := ToConfig(LocalStorageConfig{}, cfg)
 	if err != nil {
 		return nil, err
 	}
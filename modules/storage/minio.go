@@ -6,6 +6,9 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
 	"io"
 	"net/url"
 	"os"
@@ -17,6 +20,7 @@ import (
 
 	"github.com/minio/minio-go/v7"
 	"github.com/minio/minio-go/v7/pkg/credentials"
+	"github.com/minio/minio-go/v7/pkg/encrypt"
 )
 
 var (
@@ -50,14 +54,71 @@ type MinioStorageConfig struct {
 	Location        string `ini:"MINIO_LOCATION"`
 	BasePath        string `ini:"MINIO_BASE_PATH"`
 	UseSSL          bool   `ini:"MINIO_USE_SSL"`
+
+	// MultipartPartSize is the size of the parts of multipart uploads in bytes, 0 uses the default of the client
+	MultipartPartSize uint64 `ini:"MINIO_MULTIPART_PART_SIZE"`
+	MultipartThreads  uint   `ini:"MINIO_MULTIPART_THREADS"`
+	DisableMultipart  bool   `ini:"MINIO_DISABLE_MULTIPART"`
+	// ServerSideEncryption is empty, SSE-S3 or SSE-KMS
+	ServerSideEncryption string `ini:"MINIO_SERVER_SIDE_ENCRYPTION"`
+	SSEKMSKeyID          string `ini:"MINIO_SSE_KMS_KEY_ID"`
+	StorageClass         string `ini:"MINIO_STORAGE_CLASS"`
+	// ShardPrefixLength is the number of hex characters of the hash of a path prepended to its object key
+	ShardPrefixLength int `ini:"MINIO_SHARD_PREFIX_LENGTH"`
 }
 
 // MinioStorage returns a minio bucket storage
 type MinioStorage struct {
-	ctx      context.Context
-	client   *minio.Client
-	bucket   string
-	basePath string
+	ctx               context.Context
+	client            *minio.Client
+	bucket            string
+	basePath          string
+	putOptions        minio.PutObjectOptions
+	shardPrefixLength int
+}
+
+const (
+	minioSSES3  = "SSE-S3"
+	minioSSEKMS = "SSE-KMS"
+
+	// minioMinPartSize is the smallest part size accepted by S3 for multipart uploads
+	minioMinPartSize = 5 * 1024 * 1024
+)
+
+// serverSideEncryption returns the server side encryption of the objects of the configuration
+func (config *MinioStorageConfig) serverSideEncryption() (encrypt.ServerSide, error) {
+	switch strings.ToUpper(config.ServerSideEncryption) {
+	case "":
+		return nil, nil
+	case minioSSES3:
+		return encrypt.NewSSE(), nil
+	case minioSSEKMS:
+		if config.SSEKMSKeyID == "" {
+			return nil, fmt.Errorf("MINIO_SSE_KMS_KEY_ID is required for %s", minioSSEKMS)
+		}
+		return encrypt.NewSSEKMS(config.SSEKMSKeyID, nil)
+	}
+	return nil, fmt.Errorf("unknown MINIO_SERVER_SIDE_ENCRYPTION: %s", config.ServerSideEncryption)
+}
+
+// putObjectOptions returns the options to upload the objects of the configuration
+func (config *MinioStorageConfig) putObjectOptions() (minio.PutObjectOptions, error) {
+	opts := minio.PutObjectOptions{
+		ContentType:      "application/octet-stream",
+		PartSize:         config.MultipartPartSize,
+		NumThreads:       config.MultipartThreads,
+		DisableMultipart: config.DisableMultipart,
+		StorageClass:     config.StorageClass,
+	}
+	if opts.PartSize != 0 && opts.PartSize < minioMinPartSize {
+		return opts, fmt.Errorf("MINIO_MULTIPART_PART_SIZE must be at least %d", minioMinPartSize)
+	}
+	sse, err := config.serverSideEncryption()
+	if err != nil {
+		return opts, err
+	}
+	opts.ServerSideEncryption = sse
+	return opts, nil
 }
 
 func convertMinioErr(err error) error {
@@ -82,12 +143,20 @@ func convertMinioErr(err error) error {
 
 // NewMinioStorage returns a minio storage
 func NewMinioStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error) {
-	configInterface, err := toConfig(MinioStorageConfig{}, cfg)
+	configInterface, err := ToConfig(MinioStorageConfig{}, cfg)
 	if err != nil {
 		return nil, convertMinioErr(err)
 	}
 	config := configInterface.(MinioStorageConfig)
 
+	putOptions, err := config.putObjectOptions()
+	if err != nil {
+		return nil, err
+	}
+	if config.ShardPrefixLength < 0 || config.ShardPrefixLength > sha256.Size*2 {
+		return nil, fmt.Errorf("MINIO_SHARD_PREFIX_LENGTH must be between 0 and %d", sha256.Size*2)
+	}
+
 	log.Info("Creating Minio storage at %s:%s with base path %s", config.Endpoint, config.Bucket, config.BasePath)
 
 	minioClient, err := minio.New(config.Endpoint, &minio.Options{
@@ -109,15 +178,34 @@ func NewMinioStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error
 	}
 
 	return &MinioStorage{
-		ctx:      ctx,
-		client:   minioClient,
-		bucket:   config.Bucket,
-		basePath: config.BasePath,
+		ctx:               ctx,
+		client:            minioClient,
+		bucket:            config.Bucket,
+		basePath:          config.BasePath,
+		putOptions:        putOptions,
+		shardPrefixLength: config.ShardPrefixLength,
 	}, nil
 }
 
 func (m *MinioStorage) buildMinioPath(p string) string {
-	return strings.TrimPrefix(path.Join(m.basePath, path.Clean("/" + strings.ReplaceAll(p, "\\", "/"))[1:]), "/")
+	p = path.Clean("/" + strings.ReplaceAll(p, "\\", "/"))[1:]
+	if m.shardPrefixLength > 0 {
+		// spread the objects over many prefixes, the request rate limits of S3 apply per prefix
+		hash := sha256.Sum256([]byte(p))
+		p = path.Join(hex.EncodeToString(hash[:])[:m.shardPrefixLength], p)
+	}
+	return strings.TrimPrefix(path.Join(m.basePath, p), "/")
+}
+
+// pathOfMinioKey returns the path of an object key, it is the reverse of buildMinioPath
+func (m *MinioStorage) pathOfMinioKey(key string) string {
+	p := strings.TrimPrefix(key, m.basePath)
+	if m.shardPrefixLength > 0 {
+		if idx := strings.IndexByte(p, '/'); idx >= 0 {
+			p = p[idx+1:]
+		}
+	}
+	return p
 }
 
 // Open open a file
@@ -138,7 +226,7 @@ func (m *MinioStorage) Save(path string, r io.Reader, size int64) (int64, error)
 		m.buildMinioPath(path),
 		r,
 		size,
-		minio.PutObjectOptions{ContentType: "application/octet-stream"},
+		m.putOptions,
 	)
 	if err != nil {
 		return 0, convertMinioErr(err)
@@ -219,7 +307,7 @@ func (m *MinioStorage) IterateObjects(fn func(path string, obj Object) error) er
 		}
 		if err := func(object *minio.Object, fn func(path string, obj Object) error) error {
 			defer object.Close()
-			return fn(strings.TrimPrefix(mObjInfo.Key, m.basePath), &minioObject{object})
+			return fn(m.pathOfMinioKey(mObjInfo.Key), &minioObject{object})
 		}(object, fn); err != nil {
 			return convertMinioErr(err)
 		}
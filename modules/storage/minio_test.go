@@ -0,0 +1,60 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"testing"
+
+	"github.com/minio/minio-go/v7/pkg/encrypt"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBuildMinioPath(t *testing.T) {
+	m := &MinioStorage{basePath: "lfs/"}
+	assert.Equal(t, "lfs/ab/cd/efgh", m.buildMinioPath("ab/cd/efgh"))
+	assert.Equal(t, "lfs/ab/cd/efgh", m.buildMinioPath("../ab\\cd/efgh"))
+	assert.Equal(t, "ab/cd/efgh", m.pathOfMinioKey("lfs/ab/cd/efgh"))
+
+	m.shardPrefixLength = 4
+	key := m.buildMinioPath("ab/cd/efgh")
+	assert.Regexp(t, "^lfs/[0-9a-f]{4}/ab/cd/efgh$", key)
+	assert.Equal(t, key, m.buildMinioPath("/ab/cd/efgh"))
+	assert.NotEqual(t, key[:9], m.buildMinioPath("ab/cd/efgi")[:9])
+	assert.Equal(t, "ab/cd/efgh", m.pathOfMinioKey(key))
+}
+
+func TestMinioPutObjectOptions(t *testing.T) {
+	opts, err := (&MinioStorageConfig{}).putObjectOptions()
+	assert.NoError(t, err)
+	assert.Nil(t, opts.ServerSideEncryption)
+	assert.EqualValues(t, 0, opts.PartSize)
+
+	opts, err = (&MinioStorageConfig{
+		MultipartPartSize:    64 * 1024 * 1024,
+		MultipartThreads:     8,
+		StorageClass:         "STANDARD_IA",
+		ServerSideEncryption: "sse-s3",
+	}).putObjectOptions()
+	assert.NoError(t, err)
+	assert.EqualValues(t, 64*1024*1024, opts.PartSize)
+	assert.EqualValues(t, 8, opts.NumThreads)
+	assert.Equal(t, "STANDARD_IA", opts.StorageClass)
+	if assert.NotNil(t, opts.ServerSideEncryption) {
+		assert.Equal(t, encrypt.S3, opts.ServerSideEncryption.Type())
+	}
+
+	opts, err = (&MinioStorageConfig{ServerSideEncryption: "SSE-KMS", SSEKMSKeyID: "my-key"}).putObjectOptions()
+	assert.NoError(t, err)
+	if assert.NotNil(t, opts.ServerSideEncryption) {
+		assert.Equal(t, encrypt.KMS, opts.ServerSideEncryption.Type())
+	}
+
+	_, err = (&MinioStorageConfig{ServerSideEncryption: "SSE-KMS"}).putObjectOptions()
+	assert.Error(t, err)
+	_, err = (&MinioStorageConfig{ServerSideEncryption: "SSE-C"}).putObjectOptions()
+	assert.Error(t, err)
+	_, err = (&MinioStorageConfig{MultipartPartSize: 1024}).putObjectOptions()
+	assert.Error(t, err)
+}
@@ -11,6 +11,8 @@ import (
 	"io"
 	"net/url"
 	"os"
+	"sort"
+	"strings"
 
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -46,11 +48,23 @@ type NewStorageFunc func(ctx context.Context, cfg interface{}) (ObjectStorage, e
 
 var storageMap = map[Type]NewStorageFunc{}
 
-// RegisterStorageType registers a provided storage type with a function to create it
+// RegisterStorageType registers a provided storage type with a function to create it.
+// Storage backends register themselves in the init function of their file or package,
+// the function gets the storage section of the app.ini which can be read with ToConfig.
 func RegisterStorageType(typ Type, fn func(ctx context.Context, cfg interface{}) (ObjectStorage, error)) {
 	storageMap[typ] = fn
 }
 
+// RegisteredStorageTypes returns the sorted registered storage types
+func RegisteredStorageTypes() []string {
+	types := make([]string, 0, len(storageMap))
+	for typ := range storageMap {
+		types = append(types, string(typ))
+	}
+	sort.Strings(types)
+	return types
+}
+
 // Object represents the object on the storage
 type Object interface {
 	io.ReadCloser
@@ -58,13 +72,17 @@ type Object interface {
 	Stat() (os.FileInfo, error)
 }
 
-// ObjectStorage represents an object storage to handle a bucket and files
+// ObjectStorage represents an object storage to handle a bucket and files.
+// Paths are slash separated and relative to the root of the storage.
 type ObjectStorage interface {
+	// Open opens an object, it returns os.ErrNotExist if there is none
 	Open(path string) (Object, error)
 	// Save store a object, if size is unknown set -1
 	Save(path string, r io.Reader, size int64) (int64, error)
+	// Stat returns the information of an object, it returns os.ErrNotExist if there is none
 	Stat(path string) (os.FileInfo, error)
 	Delete(path string) error
+	// URL returns an URL to download an object directly, or ErrURLNotSupported
 	URL(path, name string) (*url.URL, error)
 	IterateObjects(func(path string, obj Object) error) error
 }
@@ -112,6 +130,8 @@ func SaveFrom(objStorage ObjectStorage, p string, callback func(w io.Writer) err
 var (
 	// Attachments represents attachments storage
 	Attachments ObjectStorage
+	// AttachmentsCold represents the storage of expired attachments
+	AttachmentsCold ObjectStorage
 
 	// LFS represents lfs storage
 	LFS ObjectStorage
@@ -126,6 +146,12 @@ var (
 
 	// Packages represents packages storage
 	Packages ObjectStorage
+
+	// Actions represents the storage of the artifacts of actions jobs
+	Actions ObjectStorage
+
+	// CustomEmojis represents the storage of the emoji uploaded by site admins
+	CustomEmojis ObjectStorage
 )
 
 // Init init the stoarge
@@ -150,7 +176,15 @@ func Init() error {
 		return err
 	}
 
-	return initPackages()
+	if err := initPackages(); err != nil {
+		return err
+	}
+
+	if err := initActions(); err != nil {
+		return err
+	}
+
+	return initCustomEmojis()
 }
 
 // NewStorage takes a storage type and some config and returns an ObjectStorage or an error
@@ -160,7 +194,7 @@ func NewStorage(typStr string, cfg interface{}) (ObjectStorage, error) {
 	}
 	fn, ok := storageMap[Type(typStr)]
 	if !ok {
-		return nil, fmt.Errorf("Unsupported storage type: %s", typStr)
+		return nil, fmt.Errorf("Unsupported storage type: %s, supported types: %s", typStr, strings.Join(RegisteredStorageTypes(), ", "))
 	}
 
 	return fn(context.Background(), cfg)
@@ -175,6 +209,12 @@ func initAvatars() (err error) {
 func initAttachments() (err error) {
 	log.Info("Initialising Attachment storage with type: %s", setting.Attachment.Storage.Type)
 	Attachments, err = NewStorage(setting.Attachment.Storage.Type, &setting.Attachment.Storage)
+	if err != nil {
+		return
+	}
+
+	log.Info("Initialising cold Attachment storage with type: %s", setting.Attachment.ColdStorage.Type)
+	AttachmentsCold, err = NewStorage(setting.Attachment.ColdStorage.Type, &setting.Attachment.ColdStorage)
 	return
 }
 
@@ -201,3 +241,15 @@ func initPackages() (err error) {
 	Packages, err = NewStorage(setting.Packages.Storage.Type, &setting.Packages.Storage)
 	return
 }
+
+func initActions() (err error) {
+	log.Info("Initialising Actions storage with type: %s", setting.Actions.Storage.Type)
+	Actions, err = NewStorage(setting.Actions.Storage.Type, &setting.Actions.Storage)
+	return
+}
+
+func initCustomEmojis() (err error) {
+	log.Info("Initialising CustomEmoji storage with type: %s", setting.CustomEmoji.Storage.Type)
+	CustomEmojis, err = NewStorage(setting.CustomEmoji.Storage.Type, &setting.CustomEmoji.Storage)
+	return
+}
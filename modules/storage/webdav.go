@@ -0,0 +1,426 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/proxy"
+)
+
+var _ ObjectStorage = &WebDAVStorage{}
+
+// WebDAVStorageType is the type descriptor for WebDAV storage
+const WebDAVStorageType Type = "webdav"
+
+// WebDAVStorageConfig represents the configuration for a WebDAV storage
+type WebDAVStorageConfig struct {
+	URL           string `ini:"WEBDAV_URL"`
+	Username      string `ini:"WEBDAV_USERNAME"`
+	Password      string `ini:"WEBDAV_PASSWORD"`
+	BasePath      string `ini:"WEBDAV_BASE_PATH"`
+	SkipTLSVerify bool   `ini:"WEBDAV_SKIP_TLS_VERIFY"`
+}
+
+// WebDAVStorage represents a storage in a collection of a WebDAV server
+type WebDAVStorage struct {
+	ctx      context.Context
+	client   *http.Client
+	base     *url.URL
+	username string
+	password string
+}
+
+// NewWebDAVStorage returns a WebDAV storage
+func NewWebDAVStorage(ctx context.Context, cfg interface{}) (ObjectStorage, error) {
+	configInterface, err := ToConfig(WebDAVStorageConfig{}, cfg)
+	if err != nil {
+		return nil, err
+	}
+	config := configInterface.(WebDAVStorageConfig)
+
+	base, err := url.Parse(config.URL)
+	if err != nil || (base.Scheme != "http" && base.Scheme != "https") {
+		return nil, fmt.Errorf("invalid WEBDAV_URL: %q", config.URL)
+	}
+	base.Path = path.Join("/", base.Path, config.BasePath) + "/"
+	base.RawPath = ""
+
+	log.Info("Creating WebDAV storage at %s", base.Redacted())
+
+	w := &WebDAVStorage{
+		ctx: ctx,
+		client: &http.Client{
+			Transport: &http.Transport{
+				Proxy:           proxy.Proxy(),
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: config.SkipTLSVerify},
+			},
+		},
+		base:     base,
+		username: config.Username,
+		password: config.Password,
+	}
+	if err := w.mkcolAll(""); err != nil {
+		return nil, err
+	}
+	return w, nil
+}
+
+// buildURL returns the URL of a path in the base collection, collections end with a slash
+func (w *WebDAVStorage) buildURL(p string, isCollection bool) string {
+	p = path.Clean("/" + strings.ReplaceAll(p, "\\", "/"))[1:]
+	u := *w.base
+	u.Path = path.Join(u.Path, p)
+	if isCollection && !strings.HasSuffix(u.Path, "/") {
+		u.Path += "/"
+	}
+	return u.String()
+}
+
+func (w *WebDAVStorage) do(method, u string, header http.Header, body io.Reader, size int64) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(w.ctx, method, u, body)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	if size >= 0 && body != nil {
+		req.ContentLength = size
+	}
+	if w.username != "" || w.password != "" {
+		req.SetBasicAuth(w.username, w.password)
+	}
+	return w.client.Do(req)
+}
+
+// webdavError converts the status of an unsuccessful response to an error
+func webdavError(resp *http.Response) error {
+	switch resp.StatusCode {
+	case http.StatusNotFound:
+		return os.ErrNotExist
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return os.ErrPermission
+	}
+	return fmt.Errorf("unexpected status of WebDAV %s %s: %s", resp.Request.Method, resp.Request.URL.Redacted(), resp.Status)
+}
+
+// mkcolAll creates a collection with all its missing parents
+func (w *WebDAVStorage) mkcolAll(dir string) error {
+	dir = path.Clean("/" + dir)
+	collections := []string{""}
+	if dir != "/" {
+		parts := strings.Split(dir[1:], "/")
+		for i := range parts {
+			collections = append(collections, strings.Join(parts[:i+1], "/"))
+		}
+	}
+
+	for _, collection := range collections {
+		resp, err := w.do("MKCOL", w.buildURL(collection, true), nil, nil, -1)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		// 405 Method Not Allowed means the collection exists already
+		if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusMethodNotAllowed {
+			return webdavError(resp)
+		}
+	}
+	return nil
+}
+
+type webdavFileInfo struct {
+	name    string
+	size    int64
+	modTime time.Time
+}
+
+func (f *webdavFileInfo) Name() string {
+	return f.name
+}
+
+func (f *webdavFileInfo) Size() int64 {
+	return f.size
+}
+
+func (f *webdavFileInfo) ModTime() time.Time {
+	return f.modTime
+}
+
+func (f *webdavFileInfo) IsDir() bool {
+	return false
+}
+
+func (f *webdavFileInfo) Mode() os.FileMode {
+	return os.ModePerm
+}
+
+func (f *webdavFileInfo) Sys() interface{} {
+	return nil
+}
+
+// webdavObject reads an object with range requests, so it can be seeked
+type webdavObject struct {
+	storage *WebDAVStorage
+	url     string
+	info    *webdavFileInfo
+	offset  int64
+	body    io.ReadCloser
+}
+
+func (o *webdavObject) Read(p []byte) (int, error) {
+	if o.offset >= o.info.size {
+		return 0, io.EOF
+	}
+	if o.body == nil {
+		header := http.Header{}
+		header.Set("Range", fmt.Sprintf("bytes=%d-", o.offset))
+		resp, err := o.storage.do(http.MethodGet, o.url, header, nil, -1)
+		if err != nil {
+			return 0, err
+		}
+		switch {
+		case resp.StatusCode == http.StatusPartialContent:
+		case resp.StatusCode == http.StatusOK:
+			// the server ignores the range
+			if _, err := io.CopyN(io.Discard, resp.Body, o.offset); err != nil {
+				resp.Body.Close()
+				return 0, err
+			}
+		default:
+			resp.Body.Close()
+			return 0, webdavError(resp)
+		}
+		o.body = resp.Body
+	}
+	n, err := o.body.Read(p)
+	o.offset += int64(n)
+	return n, err
+}
+
+func (o *webdavObject) Seek(offset int64, whence int) (int64, error) {
+	switch whence {
+	case io.SeekStart:
+	case io.SeekCurrent:
+		offset += o.offset
+	case io.SeekEnd:
+		offset += o.info.size
+	default:
+		return 0, errors.New("Seek: invalid whence")
+	}
+	if offset < 0 {
+		return 0, errors.New("Seek: invalid offset")
+	}
+	if offset != o.offset && o.body != nil {
+		o.body.Close()
+		o.body = nil
+	}
+	o.offset = offset
+	return offset, nil
+}
+
+func (o *webdavObject) Close() error {
+	if o.body == nil {
+		return nil
+	}
+	err := o.body.Close()
+	o.body = nil
+	return err
+}
+
+func (o *webdavObject) Stat() (os.FileInfo, error) {
+	return o.info, nil
+}
+
+// Open opens an object
+func (w *WebDAVStorage) Open(p string) (Object, error) {
+	info, err := w.stat(p)
+	if err != nil {
+		return nil, err
+	}
+	return &webdavObject{storage: w, url: w.buildURL(p, false), info: info}, nil
+}
+
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// Save saves an object, creating the missing collections of its path
+func (w *WebDAVStorage) Save(p string, r io.Reader, size int64) (int64, error) {
+	p = path.Clean("/" + strings.ReplaceAll(p, "\\", "/"))[1:]
+	if err := w.mkcolAll(path.Dir(p)); err != nil {
+		return 0, err
+	}
+
+	body := &countingReader{r: r}
+	resp, err := w.do(http.MethodPut, w.buildURL(p, false), nil, body, size)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated && resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return 0, webdavError(resp)
+	}
+	return body.n, nil
+}
+
+func (w *WebDAVStorage) stat(p string) (*webdavFileInfo, error) {
+	resp, err := w.do(http.MethodHead, w.buildURL(p, false), nil, nil, -1)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, webdavError(resp)
+	}
+
+	info := &webdavFileInfo{
+		name: path.Base(p),
+		size: resp.ContentLength,
+	}
+	if modTime, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		info.modTime = modTime
+	}
+	return info, nil
+}
+
+// Stat returns the information of an object
+func (w *WebDAVStorage) Stat(p string) (os.FileInfo, error) {
+	return w.stat(p)
+}
+
+// Delete deletes an object, it is not an error if it doesn't exist
+func (w *WebDAVStorage) Delete(p string) error {
+	resp, err := w.do(http.MethodDelete, w.buildURL(p, false), nil, nil, -1)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNotFound {
+		return webdavError(resp)
+	}
+	return nil
+}
+
+// URL isn't supported, the objects are served by Gitea
+func (w *WebDAVStorage) URL(path, name string) (*url.URL, error) {
+	return nil, ErrURLNotSupported
+}
+
+type webdavMultistatus struct {
+	Responses []struct {
+		Href     string `xml:"DAV: href"`
+		Propstat []struct {
+			Prop struct {
+				ResourceType struct {
+					Collection *struct{} `xml:"DAV: collection"`
+				} `xml:"DAV: resourcetype"`
+			} `xml:"DAV: prop"`
+			Status string `xml:"DAV: status"`
+		} `xml:"DAV: propstat"`
+	} `xml:"DAV: response"`
+}
+
+const webdavPropfindBody = `<?xml version="1.0" encoding="utf-8"?><propfind xmlns="DAV:"><prop><resourcetype/></prop></propfind>`
+
+// list returns the members of a collection, the names of collections end with a slash
+func (w *WebDAVStorage) list(dir string) ([]string, error) {
+	header := http.Header{}
+	header.Set("Depth", "1")
+	header.Set("Content-Type", "application/xml; charset=utf-8")
+	collectionURL := w.buildURL(dir, true)
+	resp, err := w.do("PROPFIND", collectionURL, header, strings.NewReader(webdavPropfindBody), int64(len(webdavPropfindBody)))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusMultiStatus {
+		return nil, webdavError(resp)
+	}
+
+	var ms webdavMultistatus
+	if err := xml.NewDecoder(resp.Body).Decode(&ms); err != nil {
+		return nil, err
+	}
+
+	collectionPath := path.Clean(resp.Request.URL.Path)
+	names := make([]string, 0, len(ms.Responses))
+	for _, r := range ms.Responses {
+		href, err := url.Parse(r.Href)
+		if err != nil {
+			return nil, err
+		}
+		memberPath := path.Clean(href.Path)
+		if memberPath == collectionPath || path.Dir(memberPath) != collectionPath {
+			continue
+		}
+		name := path.Base(memberPath)
+		for _, propstat := range r.Propstat {
+			if strings.Contains(propstat.Status, " 200 ") && propstat.Prop.ResourceType.Collection != nil {
+				name += "/"
+				break
+			}
+		}
+		names = append(names, name)
+	}
+	return names, nil
+}
+
+// IterateObjects iterates across the objects of the storage, walking the collections one level at a time
+func (w *WebDAVStorage) IterateObjects(fn func(path string, obj Object) error) error {
+	return w.iterateObjects("", fn)
+}
+
+func (w *WebDAVStorage) iterateObjects(dir string, fn func(path string, obj Object) error) error {
+	names, err := w.list(dir)
+	if err != nil {
+		return err
+	}
+	for _, name := range names {
+		if strings.HasSuffix(name, "/") {
+			if err := w.iterateObjects(path.Join(dir, name), fn); err != nil {
+				return err
+			}
+			continue
+		}
+
+		p := path.Join(dir, name)
+		obj, err := w.Open(p)
+		if err != nil {
+			return err
+		}
+		if err := func() error {
+			defer obj.Close()
+			return fn(p, obj)
+		}(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func init() {
+	RegisterStorageType(WebDAVStorageType, NewWebDAVStorage)
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package storage
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/webdav"
+)
+
+func TestWebDAVStorage(t *testing.T) {
+	handler := &webdav.Handler{
+		FileSystem: webdav.NewMemFS(),
+		LockSystem: webdav.NewMemLS(),
+	}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, password, ok := r.BasicAuth(); !ok || user != "gitea" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+
+	_, err := NewWebDAVStorage(context.Background(), WebDAVStorageConfig{URL: server.URL, BasePath: "lfs"})
+	assert.ErrorIs(t, err, os.ErrPermission)
+
+	s, err := NewWebDAVStorage(context.Background(), WebDAVStorageConfig{
+		URL:      server.URL,
+		Username: "gitea",
+		Password: "secret",
+		BasePath: "lfs/",
+	})
+	assert.NoError(t, err)
+
+	content := "0123456789"
+	n, err := s.Save("ab/cd/object", strings.NewReader(content), int64(len(content)))
+	assert.NoError(t, err)
+	assert.EqualValues(t, len(content), n)
+	_, err = s.Save("../other", strings.NewReader("other"), -1)
+	assert.NoError(t, err)
+
+	info, err := s.Stat("ab/cd/object")
+	assert.NoError(t, err)
+	assert.Equal(t, "object", info.Name())
+	assert.EqualValues(t, len(content), info.Size())
+
+	obj, err := s.Open("ab/cd/object")
+	if assert.NoError(t, err) {
+		_, err = obj.Seek(4, io.SeekStart)
+		assert.NoError(t, err)
+		buf := make([]byte, 3)
+		_, err = io.ReadFull(obj, buf)
+		assert.NoError(t, err)
+		assert.Equal(t, "456", string(buf))
+		_, err = obj.Seek(-2, io.SeekEnd)
+		assert.NoError(t, err)
+		rest, err := io.ReadAll(obj)
+		assert.NoError(t, err)
+		assert.Equal(t, "89", string(rest))
+		assert.NoError(t, obj.Close())
+	}
+
+	var paths []string
+	assert.NoError(t, s.IterateObjects(func(path string, obj Object) error {
+		paths = append(paths, path)
+		return nil
+	}))
+	sort.Strings(paths)
+	assert.Equal(t, []string{"ab/cd/object", "other"}, paths)
+
+	_, err = s.URL("other", "other")
+	assert.ErrorIs(t, err, ErrURLNotSupported)
+
+	assert.NoError(t, s.Delete("ab/cd/object"))
+	assert.NoError(t, s.Delete("ab/cd/object"))
+	_, err = s.Stat("ab/cd/object")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+	_, err = s.Open("missing")
+	assert.ErrorIs(t, err, os.ErrNotExist)
+}
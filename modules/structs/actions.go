@@ -0,0 +1,122 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// ActionRun represents a run of a workflow
+type ActionRun struct {
+	ID           int64  `json:"id"`
+	Index        int64  `json:"index"`
+	Title        string `json:"title"`
+	WorkflowID   string `json:"workflow_id"`
+	WorkflowName string `json:"workflow_name"`
+	Event        string `json:"event"`
+	Ref          string `json:"ref"`
+	CommitSHA    string `json:"commit_sha"`
+	// enum: waiting,running,success,failure,cancelled,skipped
+	Status      string `json:"status"`
+	TriggerUser *User  `json:"trigger_user"`
+	HTMLURL     string `json:"html_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Stopped *time.Time `json:"stopped_at"`
+}
+
+// ActionRunJob represents a job of a run
+type ActionRunJob struct {
+	ID     int64    `json:"id"`
+	RunID  int64    `json:"run_id"`
+	JobID  string   `json:"job_id"`
+	Name   string   `json:"name"`
+	Needs  []string `json:"needs"`
+	RunsOn []string `json:"runs_on"`
+	// enum: waiting,running,success,failure,cancelled,skipped
+	Status    string `json:"status"`
+	RunnerID  int64  `json:"runner_id"`
+	LogLength int64  `json:"log_length"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Stopped *time.Time `json:"stopped_at"`
+}
+
+// ActionArtifact represents a file uploaded by a job
+type ActionArtifact struct {
+	ID          int64  `json:"id"`
+	JobID       int64  `json:"job_id"`
+	Name        string `json:"name"`
+	Size        int64  `json:"size"`
+	DownloadURL string `json:"download_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// ActionRunner represents a runner executing jobs
+type ActionRunner struct {
+	ID     int64    `json:"id"`
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+	Online bool     `json:"online"`
+	// swagger:strfmt date-time
+	LastOnline *time.Time `json:"last_online"`
+}
+
+// ActionRunnerRegistrationToken represents a token to register runners
+type ActionRunnerRegistrationToken struct {
+	Token string `json:"token"`
+}
+
+// The types below are exchanged with runners and aren't part of the API documentation
+
+// RegisterRunnerOption is sent by a runner to register itself
+type RegisterRunnerOption struct {
+	Token  string   `json:"token" binding:"Required"`
+	Name   string   `json:"name" binding:"Required;MaxSize(255)"`
+	Labels []string `json:"labels"`
+}
+
+// RegisteredRunner is returned to a registered runner, it authenticates with the uuid and token
+type RegisteredRunner struct {
+	ID    int64  `json:"id"`
+	UUID  string `json:"uuid"`
+	Token string `json:"token"`
+}
+
+// ActionTask is a job sent to a runner
+type ActionTask struct {
+	JobID      int64             `json:"job_id"`
+	RunID      int64             `json:"run_id"`
+	Repository string            `json:"repository"`
+	CloneURL   string            `json:"clone_url"`
+	Event      string            `json:"event"`
+	Ref        string            `json:"ref"`
+	CommitSHA  string            `json:"commit_sha"`
+	Workflow   string            `json:"workflow"`
+	Job        string            `json:"job"`
+	Payload    string            `json:"payload"`
+	Token      string            `json:"token"` // deploy token to clone the repository, revoked when the job finishes
+	Secrets    map[string]string `json:"secrets"`
+	Variables  map[string]string `json:"variables"`
+}
+
+// AppendJobLogsOption is sent by a runner to append lines to the log of a job
+type AppendJobLogsOption struct {
+	Lines []string `json:"lines"`
+}
+
+// UpdateJobStatusOption is sent by a runner when a job has finished
+type UpdateJobStatusOption struct {
+	// enum: success,failure,cancelled
+	Status string `json:"status" binding:"Required"`
+}
+
+// ActionJobState is returned to a runner after updating a job, runners stop cancelled jobs
+type ActionJobState struct {
+	Status string `json:"status"`
+}
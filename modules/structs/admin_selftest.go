@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// SelfTestReport represents the results of the self-tests of the instance
+type SelfTestReport struct {
+	// Status is "fail" if any of the checks failed, "pass" otherwise
+	Status string `json:"status"`
+	// swagger:strfmt date-time
+	Started time.Time        `json:"started"`
+	Checks  []*SelfTestCheck `json:"checks"`
+}
+
+// SelfTestCheck represents the result of a single self-test
+type SelfTestCheck struct {
+	Name string `json:"name"`
+	// Status is one of "pass", "fail" or "skip"
+	Status  string            `json:"status"`
+	Output  string            `json:"output,omitempty"`
+	Details map[string]string `json:"details,omitempty"`
+	// Duration of the check in milliseconds
+	Duration int64 `json:"duration"`
+}
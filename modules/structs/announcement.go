@@ -0,0 +1,55 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Announcement represents an announcement shown as a banner on the pages of the instance or of an organization
+type Announcement struct {
+	ID int64 `json:"id"`
+	// Content is markdown
+	Content string `json:"content"`
+	// enum: info,warning,critical
+	Severity    string `json:"severity"`
+	Dismissible bool   `json:"dismissible"`
+	// swagger:strfmt date-time
+	Start time.Time `json:"start"`
+	// End is null if the announcement doesn't expire
+	// swagger:strfmt date-time
+	End *time.Time `json:"end"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateAnnouncementOption options for publishing an announcement
+type CreateAnnouncementOption struct {
+	// required: true
+	Content string `json:"content" binding:"Required"`
+	// enum: info,warning,critical
+	Severity    string `json:"severity" binding:"Required;In(info,warning,critical)"`
+	Dismissible bool   `json:"dismissible"`
+	// Start defaults to now
+	// swagger:strfmt date-time
+	Start *time.Time `json:"start"`
+	// swagger:strfmt date-time
+	End *time.Time `json:"end"`
+}
+
+// EditAnnouncementOption options for editing an announcement
+type EditAnnouncementOption struct {
+	Content *string `json:"content"`
+	// enum: info,warning,critical
+	Severity    *string `json:"severity" binding:"OmitEmpty;In(info,warning,critical)"`
+	Dismissible *bool   `json:"dismissible"`
+	// swagger:strfmt date-time
+	Start *time.Time `json:"start"`
+	// End removes the end of the announcement if it is the zero time
+	// swagger:strfmt date-time
+	End *time.Time `json:"end"`
+}
@@ -19,6 +19,21 @@ type Attachment struct {
 	Created     time.Time `json:"created_at"`
 	UUID        string    `json:"uuid"`
 	DownloadURL string    `json:"browser_download_url"`
+	// url of a file which is hosted elsewhere, downloads are redirected to it
+	ExternalURL string `json:"external_url"`
+	// checksum of an external file as `algorithm:digest` with a hex encoded digest
+	Checksum string `json:"checksum"`
+}
+
+// CreateExternalAttachmentOption options for linking a file which is hosted elsewhere as attachment
+// swagger:model
+type CreateExternalAttachmentOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required"`
+	// required: true
+	ExternalURL string `json:"external_url" binding:"Required;ValidUrl"`
+	// checksum of the file as `algorithm:digest` with a hex encoded digest, the algorithm is one of md5, sha1, sha256 or sha512
+	Checksum string `json:"checksum"`
 }
 
 // EditAttachmentOptions options for editing attachments
@@ -26,3 +41,21 @@ type Attachment struct {
 type EditAttachmentOptions struct {
 	Name string `json:"name"`
 }
+
+// AttachmentUsage the number and size of the attachments stored for a repository
+// swagger:model
+type AttachmentUsage struct {
+	RepoID int64 `json:"repo_id"`
+	// full name of the repository, empty if the repository doesn't exist anymore
+	RepoFullName string `json:"repo_full_name"`
+	Count        int64  `json:"count"`
+	Size         int64  `json:"size"`
+	// number of the attachments which have been moved to the cold storage
+	ColdCount int64 `json:"cold_count"`
+	ColdSize  int64 `json:"cold_size"`
+	// number of the attachments which have never been linked to an issue, comment or release
+	OrphanedCount int64 `json:"orphaned_count"`
+	OrphanedSize  int64 `json:"orphaned_size"`
+	// maximum size of the attachments of a repository in bytes, 0 if it's unlimited
+	MaxSize int64 `json:"max_size"`
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// CheckRun represents a check of a commit reported by an external service
+type CheckRun struct {
+	ID      int64  `json:"id"`
+	HeadSHA string `json:"head_sha"`
+	Name    string `json:"name"`
+	// enum: queued,in_progress,completed
+	Status string `json:"status"`
+	// enum: success,failure,neutral,cancelled,skipped,timed_out,action_required
+	Conclusion string          `json:"conclusion"`
+	DetailsURL string          `json:"details_url"`
+	ExternalID string          `json:"external_id"`
+	HTMLURL    string          `json:"html_url"`
+	Attempt    int             `json:"attempt"`
+	Output     *CheckRunOutput `json:"output"`
+	Creator    *User           `json:"creator"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Completed *time.Time `json:"completed_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CheckRunOutput represents the output of a check run
+type CheckRunOutput struct {
+	Title            string `json:"title"`
+	Summary          string `json:"summary"`
+	Text             string `json:"text"`
+	AnnotationsCount int64  `json:"annotations_count"`
+}
+
+// CheckRunAnnotation represents a message of a check run about a range of lines of a file
+type CheckRunAnnotation struct {
+	Path      string `json:"path" binding:"Required"`
+	StartLine int    `json:"start_line" binding:"Required"`
+	EndLine   int    `json:"end_line"`
+	// enum: notice,warning,failure
+	AnnotationLevel string `json:"annotation_level"`
+	Title           string `json:"title" binding:"MaxSize(255)"`
+	Message         string `json:"message"`
+}
+
+// CheckRunOutputOption holds the output of a check run to create or update
+type CheckRunOutputOption struct {
+	Title   string `json:"title" binding:"MaxSize(255)"`
+	Summary string `json:"summary"`
+	Text    string `json:"text"`
+	// annotations are appended to the ones already reported, at most 50 per request
+	Annotations []*CheckRunAnnotation `json:"annotations"`
+}
+
+// CreateCheckRunOption holds the information needed to create a check run
+type CreateCheckRunOption struct {
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// required: true
+	HeadSHA string `json:"head_sha" binding:"Required;MaxSize(64)"`
+	// enum: queued,in_progress,completed
+	Status string `json:"status"`
+	// setting a conclusion completes the check run
+	// enum: success,failure,neutral,cancelled,skipped,timed_out,action_required
+	Conclusion string                `json:"conclusion"`
+	DetailsURL string                `json:"details_url"`
+	ExternalID string                `json:"external_id" binding:"MaxSize(255)"`
+	Output     *CheckRunOutputOption `json:"output"`
+}
+
+// EditCheckRunOption holds the information to change a check run
+type EditCheckRunOption struct {
+	Name *string `json:"name" binding:"MaxSize(255)"`
+	// enum: queued,in_progress,completed
+	Status *string `json:"status"`
+	// setting a conclusion completes the check run
+	// enum: success,failure,neutral,cancelled,skipped,timed_out,action_required
+	Conclusion *string               `json:"conclusion"`
+	DetailsURL *string               `json:"details_url"`
+	ExternalID *string               `json:"external_id" binding:"MaxSize(255)"`
+	Output     *CheckRunOutputOption `json:"output"`
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// DeployToken represents a token granting read-only HTTP access to a repository, its LFS objects or packages
+type DeployToken struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// the token, it is only returned when the deploy token is created
+	Token          string   `json:"token,omitempty"`
+	TokenLastEight string   `json:"token_last_eight"`
+	Scopes         []string `json:"scopes"`
+	// swagger:strfmt date-time
+	Expires *time.Time `json:"expires_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
+
+// CreateDeployTokenOption options when creating a deploy token
+type CreateDeployTokenOption struct {
+	// name of the token, unique within the repository
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// the granted scopes, any of read_repository, read_lfs and read_package
+	// required: true
+	Scopes []string `json:"scopes"`
+	// the token can't be used anymore after this time
+	// swagger:strfmt date-time
+	Expires *time.Time `json:"expires_at"`
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// DiscussionCategory represents a category of the discussions of a repository
+type DiscussionCategory struct {
+	ID             int64  `json:"id"`
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	IsAnswerable   bool   `json:"is_answerable"`
+	NumDiscussions int    `json:"discussions"`
+}
+
+// Discussion represents a discussion of a repository
+type Discussion struct {
+	ID       int64               `json:"id"`
+	Index    int64               `json:"number"`
+	Title    string              `json:"title"`
+	Body     string              `json:"body"`
+	Category *DiscussionCategory `json:"category"`
+	Poster   *User               `json:"user"`
+	Comments int                 `json:"comments"`
+	AnswerID int64               `json:"answer_id"`
+	URL      string              `json:"url"`
+	HTMLURL  string              `json:"html_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// DiscussionComment represents a comment of a discussion, or a reply to a comment
+type DiscussionComment struct {
+	ID       int64  `json:"id"`
+	ParentID int64  `json:"parent_id"`
+	Body     string `json:"body"`
+	Poster   *User  `json:"user"`
+	IsAnswer bool   `json:"is_answer"`
+	HTMLURL  string `json:"html_url"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateDiscussionOption options to start a discussion
+type CreateDiscussionOption struct {
+	// required:true
+	Title string `json:"title" binding:"Required;MaxSize(255)"`
+	// required:true
+	Body string `json:"body" binding:"Required"`
+	// required:true
+	CategoryID int64 `json:"category_id" binding:"Required"`
+}
+
+// CreateDiscussionCommentOption options to comment a discussion
+type CreateDiscussionCommentOption struct {
+	// required:true
+	Body string `json:"body" binding:"Required"`
+	// id of the comment to reply to
+	ParentID int64 `json:"parent_id"`
+}
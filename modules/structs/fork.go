@@ -8,6 +8,18 @@ package structs
 type CreateForkOption struct {
 	// organization name, if forking into an organization
 	Organization *string `json:"organization"`
-	// name of the forked repository
+	// name of the forked repository, must differ from the original name when
+	// forking into the owner of the repository. Defaults to the original name,
+	// or to the first unused name with a "-fork" suffix in the owner of the repository
 	Name *string `json:"name"`
 }
+
+// AttachForkOption options for declaring a repository a fork of another repository
+type AttachForkOption struct {
+	// owner of the parent repository
+	// required: true
+	Owner string `json:"owner" binding:"Required"`
+	// name of the parent repository
+	// required: true
+	Repo string `json:"repo" binding:"Required"`
+}
@@ -11,4 +11,25 @@ type GitBlobResponse struct {
 	URL      string `json:"url"`
 	SHA      string `json:"sha"`
 	Size     int64  `json:"size"`
+	// Path is the path of the blob if it was requested by its path
+	Path string `json:"path,omitempty"`
+}
+
+// GetGitBlobsOptions options for getting multiple blobs of a repository at once
+type GetGitBlobsOptions struct {
+	// SHAs of the blobs
+	SHAs []string `json:"shas"`
+	// paths of the blobs at the ref
+	Paths []string `json:"paths"`
+	// branch, tag or commit the paths are looked up at, the default branch if empty
+	Ref string `json:"ref"`
+}
+
+// GitBlobsResponse represents multiple git blobs
+type GitBlobsResponse struct {
+	Blobs []*GitBlobResponse `json:"blobs"`
+	// the requested SHAs and paths which don't exist
+	NotFound []string `json:"not_found"`
+	// true if the content of some blobs was left out because the response reached its size limit
+	Truncated bool `json:"truncated"`
 }
@@ -24,6 +24,16 @@ type Hook struct {
 	Config map[string]string `json:"config"`
 	Events []string          `json:"events"`
 	Active bool              `json:"active"`
+	// expression a payload has to match to be delivered
+	PayloadFilter string `json:"payload_filter"`
+	// whether the organization webhook is copied to the repositories of the organization instead of being triggered
+	IsTemplate bool `json:"is_template"`
+	// whether the copies of the organization webhook template can't be changed in the repositories
+	IsLocked bool `json:"is_locked"`
+	// the ID of the organization webhook template the repository webhook was copied from
+	TemplateID int64 `json:"template_id"`
+	// whether the gitea webhook sends its payloads in the format of GitHub webhooks
+	GitHubCompatible bool `json:"github_compatible"`
 	// swagger:strfmt date-time
 	Updated time.Time `json:"updated_at"`
 	// swagger:strfmt date-time
@@ -35,6 +45,8 @@ type HookList []*Hook
 
 // CreateHookOptionConfig has all config options in it
 // required are "content_type" and "url" Required
+// gitea and gogs hooks accept "secret" and the PEM encoded "client_cert" and "client_key" for mutual TLS,
+// on edit "keep_previous_secret" set to "true" keeps signing with the previous secret for a grace period
 type CreateHookOptionConfig map[string]string
 
 // CreateHookOption options when create a hook
@@ -46,8 +58,16 @@ type CreateHookOption struct {
 	Config       CreateHookOptionConfig `json:"config" binding:"Required"`
 	Events       []string               `json:"events"`
 	BranchFilter string                 `json:"branch_filter" binding:"GlobPattern"`
+	// expression a payload has to match to be delivered, e.g. `ref startsWith "refs/heads/release/"`
+	PayloadFilter string `json:"payload_filter" binding:"PayloadFilter"`
 	// default: false
 	Active bool `json:"active"`
+	// only for organization webhooks, copy the webhook to new repositories of the organization
+	IsTemplate bool `json:"is_template"`
+	// only for organization webhook templates, prevent the copies from being changed
+	IsLocked bool `json:"is_locked"`
+	// only for gitea webhooks, send the payloads in the format of GitHub webhooks
+	GitHubCompatible bool `json:"github_compatible"`
 }
 
 // EditHookOption options when modify one hook
@@ -55,7 +75,15 @@ type EditHookOption struct {
 	Config       map[string]string `json:"config"`
 	Events       []string          `json:"events"`
 	BranchFilter string            `json:"branch_filter" binding:"GlobPattern"`
-	Active       *bool             `json:"active"`
+	// expression a payload has to match to be delivered, e.g. `ref startsWith "refs/heads/release/"`
+	PayloadFilter string `json:"payload_filter" binding:"PayloadFilter"`
+	Active        *bool  `json:"active"`
+	// only for organization webhooks
+	IsTemplate *bool `json:"is_template"`
+	// only for organization webhook templates
+	IsLocked *bool `json:"is_locked"`
+	// only for gitea webhooks
+	GitHubCompatible *bool `json:"github_compatible"`
 }
 
 // Payloader payload is some part of one hook
@@ -91,13 +119,15 @@ type PayloadCommit struct {
 	Modified  []string  `json:"modified"`
 }
 
-// PayloadCommitVerification represents the GPG verification of a commit
+// PayloadCommitVerification represents the GPG or SSH verification of a commit
 type PayloadCommitVerification struct {
 	Verified  bool         `json:"verified"`
 	Reason    string       `json:"reason"`
 	Signature string       `json:"signature"`
 	Signer    *PayloadUser `json:"signer"`
 	Payload   string       `json:"payload"`
+	// trust of a verified signature according to the trust model of the repository: trusted, untrusted or unmatched
+	TrustStatus string `json:"trust_status,omitempty"`
 }
 
 var (
@@ -111,6 +141,7 @@ var (
 	_ Payloader = &RepositoryPayload{}
 	_ Payloader = &ReleasePayload{}
 	_ Payloader = &PackagePayload{}
+	_ Payloader = &PreviewEnvironmentPayload{}
 )
 
 // _________                        __
@@ -412,6 +443,10 @@ const (
 	HookRepoCreated HookRepoAction = "created"
 	// HookRepoDeleted deleted
 	HookRepoDeleted HookRepoAction = "deleted"
+	// HookRepoPublicized made public
+	HookRepoPublicized HookRepoAction = "publicized"
+	// HookRepoPrivatized made private
+	HookRepoPrivatized HookRepoAction = "privatized"
 )
 
 // RepositoryPayload payload for repository webhooks
@@ -450,3 +485,29 @@ type PackagePayload struct {
 func (p *PackagePayload) JSONPayload() ([]byte, error) {
 	return json.MarshalIndent(p, "", "  ")
 }
+
+// HookPreviewEnvironmentAction an action that happens to a preview environment
+type HookPreviewEnvironmentAction string
+
+const (
+	// HookPreviewEnvironmentCreated created
+	HookPreviewEnvironmentCreated HookPreviewEnvironmentAction = "created"
+	// HookPreviewEnvironmentUpdated updated
+	HookPreviewEnvironmentUpdated HookPreviewEnvironmentAction = "updated"
+	// HookPreviewEnvironmentDeleted deleted, the pull request has been closed or the environment has expired
+	HookPreviewEnvironmentDeleted HookPreviewEnvironmentAction = "deleted"
+)
+
+// PreviewEnvironmentPayload represents a preview environment payload
+type PreviewEnvironmentPayload struct {
+	Action             HookPreviewEnvironmentAction `json:"action"`
+	PreviewEnvironment *PreviewEnvironment          `json:"preview_environment"`
+	PullRequest        *PullRequest                 `json:"pull_request"`
+	Repository         *Repository                  `json:"repository"`
+	Sender             *User                        `json:"sender"`
+}
+
+// JSONPayload implements Payload
+func (p *PreviewEnvironmentPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
@@ -0,0 +1,41 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// ContentHistory represents a revision of the content of an issue or a comment
+type ContentHistory struct {
+	ID             int64 `json:"id"`
+	Editor         *User `json:"editor"`
+	IsFirstCreated bool  `json:"is_first_created"`
+	IsDeleted      bool  `json:"is_deleted"`
+	// swagger:strfmt date-time
+	Edited time.Time `json:"edited_at"`
+}
+
+// ContentHistoryDetail represents a revision of the content of an issue or a comment
+// together with the changes since the previous revision
+type ContentHistoryDetail struct {
+	ID             int64  `json:"id"`
+	Editor         *User  `json:"editor"`
+	IsFirstCreated bool   `json:"is_first_created"`
+	IsDeleted      bool   `json:"is_deleted"`
+	Content        string `json:"content"`
+	// swagger:strfmt date-time
+	Edited time.Time `json:"edited_at"`
+	// the previous revision the changes are based on, 0 if there is none
+	PreviousID int64                   `json:"previous_id"`
+	Changes    []*ContentHistoryChange `json:"changes"`
+}
+
+// ContentHistoryChange represents a change between two revisions of a content
+type ContentHistoryChange struct {
+	// enum: equal,insert,delete
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
@@ -0,0 +1,40 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// IssueDependencyGraph represents the dependency relationships reachable from an issue
+type IssueDependencyGraph struct {
+	// id of the issue the graph was requested for
+	IssueID int64                       `json:"issue_id"`
+	Nodes   []*IssueDependencyGraphNode `json:"nodes"`
+	Edges   []*IssueDependencyGraphEdge `json:"edges"`
+	// ids of all issues the issue is transitively blocked by
+	BlockedBy []int64 `json:"blocked_by"`
+	// ids of all issues transitively blocked by the issue
+	Blocking []int64 `json:"blocking"`
+	// dependency cycles, each given as a list of issue ids
+	Cycles [][]int64 `json:"cycles"`
+	// whether the graph was cut off because too many issues are connected to the issue
+	Truncated bool `json:"truncated"`
+}
+
+// IssueDependencyGraphNode represents an issue in a dependency graph
+type IssueDependencyGraphNode struct {
+	ID         int64     `json:"id"`
+	Index      int64     `json:"number"`
+	Title      string    `json:"title"`
+	State      StateType `json:"state"`
+	IsPull     bool      `json:"is_pull"`
+	Repository string    `json:"repository"`
+	HTMLURL    string    `json:"html_url"`
+}
+
+// IssueDependencyGraphEdge represents an issue being blocked by another issue
+type IssueDependencyGraphEdge struct {
+	// id of the blocked issue
+	IssueID int64 `json:"issue_id"`
+	// id of the issue blocking it
+	DependencyID int64 `json:"dependency_id"`
+}
@@ -0,0 +1,66 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// IssueFilter represents a named combination of issue list filters saved by a user
+type IssueFilter struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// id of the repository the filter belongs to, 0 if it applies to all repositories
+	RepoID  int64  `json:"repo_id"`
+	Keyword string `json:"keyword"`
+	// enum: all,your_repositories,assigned,created_by,mentioned,review_requested
+	Type string `json:"type"`
+	Sort string `json:"sort"`
+	// enum: open,closed,all
+	State string `json:"state"`
+	// ids of the labels to filter by, negative ids exclude a label
+	Labels      []int64 `json:"labels"`
+	MilestoneID int64   `json:"milestone_id"`
+	AssigneeID  int64   `json:"assignee_id"`
+	PosterID    int64   `json:"poster_id"`
+	// query string selecting the filter in an issue or pull request list
+	Query string `json:"query"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateIssueFilterOption options for saving an issue filter
+type CreateIssueFilterOption struct {
+	// required: true
+	Name    string `json:"name" binding:"Required;MaxSize(255)"`
+	RepoID  int64  `json:"repo_id"`
+	Keyword string `json:"keyword"`
+	// enum: all,your_repositories,assigned,created_by,mentioned,review_requested
+	Type string `json:"type"`
+	Sort string `json:"sort"`
+	// enum: open,closed,all
+	State       string  `json:"state"`
+	Labels      []int64 `json:"labels"`
+	MilestoneID int64   `json:"milestone_id"`
+	AssigneeID  int64   `json:"assignee_id"`
+	PosterID    int64   `json:"poster_id"`
+}
+
+// EditIssueFilterOption options for editing an issue filter
+type EditIssueFilterOption struct {
+	Name    *string `json:"name" binding:"MaxSize(255)"`
+	Keyword *string `json:"keyword"`
+	// enum: all,your_repositories,assigned,created_by,mentioned,review_requested
+	Type *string `json:"type"`
+	Sort *string `json:"sort"`
+	// enum: open,closed,all
+	State       *string `json:"state"`
+	Labels      []int64 `json:"labels"`
+	MilestoneID *int64  `json:"milestone_id"`
+	AssigneeID  *int64  `json:"assignee_id"`
+	PosterID    *int64  `json:"poster_id"`
+}
@@ -36,3 +36,25 @@ type TrackedTime struct {
 
 // TrackedTimeList represents a list of tracked times
 type TrackedTimeList []*TrackedTime
+
+// TimeReportEntry represents the tracked time of one user, label or milestone
+type TimeReportEntry struct {
+	// id of the user, label or milestone, 0 for issues without label or milestone
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	// full name of the repository of the label or milestone
+	Repo   string `json:"repo,omitempty"`
+	Issues int64  `json:"issues"`
+	// Time in seconds
+	Time int64 `json:"time"`
+}
+
+// TimeReport represents tracked times aggregated by user, label or milestone
+type TimeReport struct {
+	// enum: user,label,milestone
+	GroupBy string             `json:"group_by"`
+	Entries []*TimeReportEntry `json:"entries"`
+	Issues  int64              `json:"issues"`
+	// Time in seconds
+	Time int64 `json:"time"`
+}
@@ -40,6 +40,14 @@ type MarkdownOption struct {
 // swagger:response MarkdownRender
 type MarkdownRender string
 
+// MarkdownHeader is a header of the table of contents of a rendered markdown document
+type MarkdownHeader struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	// Anchor is the id of the element of the header in the rendered document
+	Anchor string `json:"anchor"`
+}
+
 // ServerVersion wraps the version of the server
 type ServerVersion struct {
 	Version string `json:"version"`
@@ -26,7 +26,7 @@ type NotificationSubject struct {
 	LatestCommentURL     string            `json:"latest_comment_url"`
 	HTMLURL              string            `json:"html_url"`
 	LatestCommentHTMLURL string            `json:"latest_comment_html_url"`
-	Type                 NotifySubjectType `json:"type" binding:"In(Issue,Pull,Commit,Repository)"`
+	Type                 NotifySubjectType `json:"type" binding:"In(Issue,Pull,Commit,Repository,SecurityEvent,Discussion)"`
 	State                StateType         `json:"state"`
 }
 
@@ -47,4 +47,8 @@ const (
 	NotifySubjectCommit NotifySubjectType = "Commit"
 	// NotifySubjectRepository an repository is subject of an notification
 	NotifySubjectRepository NotifySubjectType = "Repository"
+	// NotifySubjectSecurityEvent an event of the security log of the user is subject of an notification
+	NotifySubjectSecurityEvent NotifySubjectType = "SecurityEvent"
+	// NotifySubjectDiscussion a discussion is subject of an notification
+	NotifySubjectDiscussion NotifySubjectType = "Discussion"
 )
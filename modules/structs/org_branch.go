@@ -0,0 +1,59 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// OrgBranchProtection represents branch protection rules inherited by the repositories of an organization
+type OrgBranchProtection struct {
+	ID                     int64    `json:"id"`
+	BranchPattern          string   `json:"branch_pattern"`
+	ApplyToExisting        bool     `json:"apply_to_existing"`
+	AllowOverride          bool     `json:"allow_override"`
+	EnablePush             bool     `json:"enable_push"`
+	EnableStatusCheck      bool     `json:"enable_status_check"`
+	StatusCheckContexts    []string `json:"status_check_contexts"`
+	RequiredApprovals      int64    `json:"required_approvals"`
+	BlockOnRejectedReviews bool     `json:"block_on_rejected_reviews"`
+	DismissStaleApprovals  bool     `json:"dismiss_stale_approvals"`
+	RequireSignedCommits   bool     `json:"require_signed_commits"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreateOrgBranchProtectionOption options for creating branch protection rules of an organization
+type CreateOrgBranchProtectionOption struct {
+	// glob matched against the branch names, e.g. "release/*"
+	// required: true
+	BranchPattern string `json:"branch_pattern" binding:"Required"`
+	// apply the rules to all repositories, otherwise only to repositories created afterwards
+	ApplyToExisting        bool     `json:"apply_to_existing"`
+	AllowOverride          bool     `json:"allow_override"`
+	EnablePush             bool     `json:"enable_push"`
+	EnableStatusCheck      bool     `json:"enable_status_check"`
+	StatusCheckContexts    []string `json:"status_check_contexts"`
+	RequiredApprovals      int64    `json:"required_approvals"`
+	BlockOnRejectedReviews bool     `json:"block_on_rejected_reviews"`
+	DismissStaleApprovals  bool     `json:"dismiss_stale_approvals"`
+	RequireSignedCommits   bool     `json:"require_signed_commits"`
+}
+
+// EditOrgBranchProtectionOption options for editing branch protection rules of an organization
+type EditOrgBranchProtectionOption struct {
+	BranchPattern          *string  `json:"branch_pattern"`
+	ApplyToExisting        *bool    `json:"apply_to_existing"`
+	AllowOverride          *bool    `json:"allow_override"`
+	EnablePush             *bool    `json:"enable_push"`
+	EnableStatusCheck      *bool    `json:"enable_status_check"`
+	StatusCheckContexts    []string `json:"status_check_contexts"`
+	RequiredApprovals      *int64   `json:"required_approvals"`
+	BlockOnRejectedReviews *bool    `json:"block_on_rejected_reviews"`
+	DismissStaleApprovals  *bool    `json:"dismiss_stale_approvals"`
+	RequireSignedCommits   *bool    `json:"require_signed_commits"`
+}
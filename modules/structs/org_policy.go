@@ -0,0 +1,70 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// OrgPolicy represents the declared settings of an organization.
+// Only the sections and entries present in the policy are managed, everything else is left untouched.
+type OrgPolicy struct {
+	Settings *OrgPolicySettings `json:"settings,omitempty" yaml:"settings,omitempty"`
+	// require all members of the organization to enable two-factor authentication,
+	// members without it are reported but can't be fixed
+	RequireTwoFactor bool                   `json:"require_two_factor,omitempty" yaml:"require_two_factor,omitempty"`
+	Teams            []*OrgPolicyTeam       `json:"teams,omitempty" yaml:"teams,omitempty"`
+	RepoDefaults     *OrgPolicyRepoDefaults `json:"repo_defaults,omitempty" yaml:"repo_defaults,omitempty"`
+}
+
+// OrgPolicySettings represents the declared settings of an organization
+type OrgPolicySettings struct {
+	FullName    *string `json:"full_name,omitempty" yaml:"full_name,omitempty"`
+	Description *string `json:"description,omitempty" yaml:"description,omitempty"`
+	Website     *string `json:"website,omitempty" yaml:"website,omitempty"`
+	Location    *string `json:"location,omitempty" yaml:"location,omitempty"`
+	// possible values are `public`, `limited` or `private`
+	// enum: public,limited,private
+	Visibility                *string `json:"visibility,omitempty" yaml:"visibility,omitempty"`
+	RepoAdminChangeTeamAccess *bool   `json:"repo_admin_change_team_access,omitempty" yaml:"repo_admin_change_team_access,omitempty"`
+}
+
+// OrgPolicyTeam represents a declared team, identified by its name
+type OrgPolicyTeam struct {
+	Name        string `json:"name" yaml:"name"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	// ignored for the owners team
+	// enum: read,write,admin
+	Permission              string   `json:"permission,omitempty" yaml:"permission,omitempty"`
+	IncludesAllRepositories bool     `json:"includes_all_repositories,omitempty" yaml:"includes_all_repositories,omitempty"`
+	CanCreateOrgRepo        bool     `json:"can_create_org_repo,omitempty" yaml:"can_create_org_repo,omitempty"`
+	Units                   []string `json:"units,omitempty" yaml:"units,omitempty"`
+	// user names of the members, the members are not managed if omitted
+	Members []string `json:"members" yaml:"members"`
+}
+
+// OrgPolicyRepoDefaults represents the declared settings of all repositories of an organization
+type OrgPolicyRepoDefaults struct {
+	Private         *bool `json:"private,omitempty" yaml:"private,omitempty"`
+	HasIssues       *bool `json:"has_issues,omitempty" yaml:"has_issues,omitempty"`
+	HasWiki         *bool `json:"has_wiki,omitempty" yaml:"has_wiki,omitempty"`
+	HasPullRequests *bool `json:"has_pull_requests,omitempty" yaml:"has_pull_requests,omitempty"`
+	HasProjects     *bool `json:"has_projects,omitempty" yaml:"has_projects,omitempty"`
+}
+
+// OrgPolicyChange represents a difference between the policy and the settings of an organization
+type OrgPolicyChange struct {
+	// section of the policy: settings, require_two_factor, teams or repo_defaults
+	Section string `json:"section"`
+	// setting, team name, user name or repository name and setting
+	Name string `json:"name"`
+	// create, update, add, remove or report
+	Action string `json:"action"`
+	// reason why the change could not be applied, if any
+	Error string `json:"error,omitempty"`
+}
+
+// OrgPolicyReport represents the drift of an organization from a policy
+type OrgPolicyReport struct {
+	// whether the changes were applied
+	Applied bool               `json:"applied"`
+	Changes []*OrgPolicyChange `json:"changes"`
+}
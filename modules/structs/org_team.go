@@ -18,8 +18,11 @@ type Team struct {
 	// Deprecated: This variable should be replaced by UnitsMap and will be dropped in later versions.
 	Units []string `json:"units"`
 	// example: {"repo.code":"read","repo.issues":"write","repo.ext_issues":"none","repo.wiki":"admin","repo.pulls":"owner","repo.releases":"none","repo.projects":"none","repo.ext_wiki":"none"]
-	UnitsMap         map[string]string `json:"units_map"`
-	CanCreateOrgRepo bool              `json:"can_create_org_repo"`
+	UnitsMap               map[string]string `json:"units_map"`
+	CanCreateOrgRepo       bool              `json:"can_create_org_repo"`
+	CanCreateReleases      bool              `json:"can_create_releases"`
+	CanEditReleases        bool              `json:"can_edit_releases"`
+	CanUploadReleaseAssets bool              `json:"can_upload_release_assets"`
 }
 
 // CreateTeamOption options for creating a team
@@ -36,6 +39,12 @@ type CreateTeamOption struct {
 	// example: {"repo.code":"read","repo.issues":"write","repo.ext_issues":"none","repo.wiki":"admin","repo.pulls":"owner","repo.releases":"none","repo.projects":"none","repo.ext_wiki":"none"]
 	UnitsMap         map[string]string `json:"units_map"`
 	CanCreateOrgRepo bool              `json:"can_create_org_repo"`
+	// defaults to true
+	CanCreateReleases *bool `json:"can_create_releases"`
+	// defaults to true
+	CanEditReleases *bool `json:"can_edit_releases"`
+	// defaults to true
+	CanUploadReleaseAssets *bool `json:"can_upload_release_assets"`
 }
 
 // EditTeamOption options for editing a team
@@ -50,6 +59,9 @@ type EditTeamOption struct {
 	// Deprecated: This variable should be replaced by UnitsMap and will be dropped in later versions.
 	Units []string `json:"units"`
 	// example: {"repo.code":"read","repo.issues":"write","repo.ext_issues":"none","repo.wiki":"admin","repo.pulls":"owner","repo.releases":"none","repo.projects":"none","repo.ext_wiki":"none"]
-	UnitsMap         map[string]string `json:"units_map"`
-	CanCreateOrgRepo *bool             `json:"can_create_org_repo"`
+	UnitsMap               map[string]string `json:"units_map"`
+	CanCreateOrgRepo       *bool             `json:"can_create_org_repo"`
+	CanCreateReleases      *bool             `json:"can_create_releases"`
+	CanEditReleases        *bool             `json:"can_edit_releases"`
+	CanUploadReleaseAssets *bool             `json:"can_upload_release_assets"`
 }
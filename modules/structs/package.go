@@ -31,3 +31,29 @@ type PackageFile struct {
 	HashSHA256 string `json:"sha256"`
 	HashSHA512 string `json:"sha512"`
 }
+
+// PackagePolicy represents the quota and retention policy of the packages of an owner
+// A value of 0 disables the specific limit or rule.
+type PackagePolicy struct {
+	// maximum number of package versions
+	MaxCount int64 `json:"max_count"`
+	// maximum total size of the package files in bytes
+	MaxSize int64 `json:"max_size"`
+	// number of versions to keep for every package
+	KeepLastVersions int `json:"keep_last_versions"`
+	// days after which untagged container manifests are deleted
+	UntaggedRetentionDays int `json:"untagged_retention_days"`
+	// number of package versions currently stored
+	UsedCount int64 `json:"used_count"`
+	// size of the package files currently stored in bytes
+	UsedSize int64 `json:"used_size"`
+}
+
+// EditPackagePolicyOption options for editing the package policy of an owner
+// The quota can only be changed by site administrators.
+type EditPackagePolicyOption struct {
+	MaxCount              *int64 `json:"max_count"`
+	MaxSize               *int64 `json:"max_size"`
+	KeepLastVersions      *int   `json:"keep_last_versions"`
+	UntaggedRetentionDays *int   `json:"untagged_retention_days"`
+}
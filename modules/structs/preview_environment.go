@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// PreviewEnvironment represents a deployment of the head of a pull request
+type PreviewEnvironment struct {
+	ID   int64  `json:"id"`
+	Name string `json:"name"`
+	URL  string `json:"url"`
+	// enum: pending,ready,failed
+	Status  string `json:"status"`
+	Creator *User  `json:"creator"`
+	// swagger:strfmt date-time
+	Expires *time.Time `json:"expires_at"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// CreatePreviewEnvironmentOption holds the information needed to register a preview environment
+type CreatePreviewEnvironmentOption struct {
+	// name of the environment, unique within the pull request
+	// required: true
+	Name string `json:"name" binding:"Required;MaxSize(255)"`
+	// url of the environment, required once it is ready
+	URL string `json:"url"`
+	// enum: pending,ready,failed
+	Status string `json:"status"`
+	// the environment is removed after this time
+	// swagger:strfmt date-time
+	Expires *time.Time `json:"expires_at"`
+}
+
+// EditPreviewEnvironmentOption holds the information to change a preview environment
+type EditPreviewEnvironmentOption struct {
+	URL *string `json:"url"`
+	// enum: pending,ready,failed
+	Status *string `json:"status"`
+	// the environment is removed after this time
+	// swagger:strfmt date-time
+	Expires *time.Time `json:"expires_at"`
+}
@@ -95,3 +95,33 @@ type EditPullRequestOption struct {
 	RemoveDeadline      *bool      `json:"unset_due_date"`
 	AllowMaintainerEdit *bool      `json:"allow_maintainer_edit"`
 }
+
+// RewritePullRequestStep applies an action to a commit when rewriting the head branch of a pull request
+type RewritePullRequestStep struct {
+	// the full SHA of the commit
+	// required: true
+	SHA string `json:"sha" binding:"Required"`
+	// pick keeps the commit, reword changes its message, squash melds it into the previous commit and drop removes it
+	// enum: pick,reword,squash,drop
+	// required: true
+	Action string `json:"action" binding:"Required"`
+	// the new message of a reworded commit, or of a commit squashed into the previous one.
+	// The messages of both commits are joined if it is empty.
+	Message string `json:"message"`
+}
+
+// RewritePullRequestOption options to rewrite the head branch of a pull request like an interactive rebase
+type RewritePullRequestOption struct {
+	// the SHA of the head commit the steps were made for, the rewrite is refused if the branch has changed since
+	// required: true
+	HeadCommitID string `json:"head_commit_id" binding:"Required"`
+	// every commit of the pull request exactly once, in the new order
+	// required: true
+	Steps []*RewritePullRequestStep `json:"steps" binding:"Required"`
+}
+
+// PullRequestRewriteResult is the result of rewriting the head branch of a pull request
+type PullRequestRewriteResult struct {
+	// the SHA of the new head commit
+	HeadCommitID string `json:"head_commit_id"`
+}
@@ -49,3 +49,22 @@ type EditReleaseOption struct {
 	IsDraft      *bool  `json:"draft"`
 	IsPrerelease *bool  `json:"prerelease"`
 }
+
+// ReleaseVerification represents the verification of the signed checksums of the assets of a release
+type ReleaseVerification struct {
+	// Verified is true if the checksums are signed by a known key and match all assets of the release
+	Verified bool `json:"verified"`
+	// Reason describes why the verification failed
+	Reason      string                     `json:"reason"`
+	KeyID       string                     `json:"key_id"`
+	Fingerprint string                     `json:"fingerprint"`
+	Files       []*ReleaseVerificationFile `json:"files"`
+}
+
+// ReleaseVerificationFile represents the verification of the checksum of a file of a release
+type ReleaseVerificationFile struct {
+	Name string `json:"name"`
+	// SHA256 is the signed checksum of the file, it is empty if the file isn't listed in the signed checksums
+	SHA256   string `json:"sha256"`
+	Verified bool   `json:"verified"`
+}
@@ -66,6 +66,7 @@ type Repository struct {
 	CloneURL      string      `json:"clone_url"`
 	OriginalURL   string      `json:"original_url"`
 	Website       string      `json:"website"`
+	License       string      `json:"license"`
 	Stars         int         `json:"stars_count"`
 	Forks         int         `json:"forks_count"`
 	Watchers      int         `json:"watchers_count"`
@@ -92,6 +93,7 @@ type Repository struct {
 	AllowRebaseMerge          bool             `json:"allow_rebase_explicit"`
 	AllowSquash               bool             `json:"allow_squash_merge"`
 	DefaultMergeStyle         string           `json:"default_merge_style"`
+	TrustModel                string           `json:"trust_model"`
 	AvatarURL                 string           `json:"avatar_url"`
 	Internal                  bool             `json:"internal"`
 	MirrorInterval            string           `json:"mirror_interval"`
@@ -127,7 +129,7 @@ type CreateRepoOption struct {
 	// DefaultBranch of the repository (used when initializes and in template)
 	DefaultBranch string `json:"default_branch" binding:"GitRefName;MaxSize(100)"`
 	// TrustModel of the repository
-	// enum: default,collaborator,committer,collaboratorcommitter
+	// enum: default,collaborator,committer,collaboratorcommitter,allvalidkeys,weboftrust
 	TrustModel string `json:"trust_model"`
 }
 
@@ -141,6 +143,8 @@ type EditRepoOption struct {
 	Description *string `json:"description,omitempty" binding:"MaxSize(255)"`
 	// a URL with more information about the repository.
 	Website *string `json:"website,omitempty" binding:"MaxSize(255)"`
+	// the license of the repository, e.g. `MIT`.
+	License *string `json:"license,omitempty" binding:"MaxSize(255)"`
 	// either `true` to make the repository private or `false` to make it public.
 	// Note: you will get a 422 error if the organization restricts changing repository visibility to organization
 	// owners and a non-owner tries to change the value of private.
@@ -183,6 +187,9 @@ type EditRepoOption struct {
 	DefaultDeleteBranchAfterMerge *bool `json:"default_delete_branch_after_merge,omitempty"`
 	// set to a merge style to be used by this repository: "merge", "rebase", "rebase-merge", or "squash". `has_pull_requests` must be `true`.
 	DefaultMergeStyle *string `json:"default_merge_style,omitempty"`
+	// set the trust model used to verify the signatures of commits
+	// enum: default,collaborator,committer,collaboratorcommitter,allvalidkeys,weboftrust
+	TrustModel *string `json:"trust_model,omitempty"`
 	// set to `true` to archive this repository.
 	Archived *bool `json:"archived,omitempty"`
 	// set to a string like `8h30m0s` to set the mirror interval time
@@ -245,6 +252,11 @@ type TransferRepoOption struct {
 	NewOwner string `json:"new_owner"`
 	// ID of the team or teams to add to the repository. Teams can only be added to organization-owned repositories.
 	TeamIDs *[]int64 `json:"team_ids"`
+	// Time of the cutover, the repository is transferred as soon as possible if it is not set.
+	// swagger:strfmt date-time
+	ScheduledAt *time.Time `json:"scheduled_at"`
+	// Number of days the old location redirects to the new one, it redirects forever if it is 0.
+	RedirectDays int `json:"redirect_days"`
 }
 
 // GitServiceType represents a git service
@@ -350,4 +362,9 @@ type RepoTransfer struct {
 	Doer      *User   `json:"doer"`
 	Recipient *User   `json:"recipient"`
 	Teams     []*Team `json:"teams"`
+	// swagger:strfmt date-time
+	ScheduledAt  *time.Time `json:"scheduled_at,omitempty"`
+	RedirectDays int        `json:"redirect_days"`
+	// Accepted is true if the transfer only waits for its scheduled cutover
+	Accepted bool `json:"accepted"`
 }
@@ -0,0 +1,22 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// RepoAuditLog represents a security relevant change of a repository
+type RepoAuditLog struct {
+	ID int64 `json:"id"`
+	// enum: settings.update,collaborator.add,collaborator.update,collaborator.remove,branch_protection.add,branch_protection.update,branch_protection.remove,deploy_key.add,deploy_key.remove,webhook.add,webhook.update,webhook.remove
+	Action string `json:"action"`
+	// the changed settings section, collaborator, branch protection rule, deploy key or webhook
+	Target string `json:"target"`
+	Detail string `json:"detail"`
+	Doer   *User  `json:"doer"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+}
@@ -21,6 +21,16 @@ type Branch struct {
 	EffectiveBranchProtectionName string         `json:"effective_branch_protection_name"`
 }
 
+// DeletedBranch represents a deleted branch which can still be restored
+type DeletedBranch struct {
+	ID        int64  `json:"id"`
+	Name      string `json:"name"`
+	CommitID  string `json:"commit_id"`
+	DeletedBy *User  `json:"deleted_by"`
+	// swagger:strfmt date-time
+	Deleted time.Time `json:"deleted_at"`
+}
+
 // BranchProtection represents a branch protection for a repository
 type BranchProtection struct {
 	BranchName                    string   `json:"branch_name"`
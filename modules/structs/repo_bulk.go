@@ -0,0 +1,70 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// BulkRepoManifest describes repositories to create or import in an organization in one job
+// swagger:model
+type BulkRepoManifest struct {
+	// required: true
+	Repos []*BulkRepoEntry `json:"repos" yaml:"repos"`
+}
+
+// BulkRepoEntry describes one repository of a BulkRepoManifest
+type BulkRepoEntry struct {
+	// Name of the repository to create
+	//
+	// required: true
+	Name string `json:"name" yaml:"name"`
+	// Description of the repository to create
+	Description string `json:"description" yaml:"description"`
+	// Whether the repository is private
+	Private bool `json:"private" yaml:"private"`
+	// DefaultBranch of the repository (used when initializes and in template)
+	DefaultBranch string `json:"default_branch" yaml:"default_branch"`
+	// Whether the repository should be auto-initialized, ignored for templates and imports
+	AutoInit bool `json:"auto_init" yaml:"auto_init"`
+	// Full name (owner/name) of a template repository to generate the repository from
+	Template string `json:"template" yaml:"template"`
+	// URL of a git repository to import, the import runs as a separate migration task
+	CloneAddr    string `json:"clone_addr" yaml:"clone_addr"`
+	AuthUsername string `json:"auth_username" yaml:"auth_username"`
+	AuthPassword string `json:"auth_password" yaml:"auth_password"`
+	AuthToken    string `json:"auth_token" yaml:"auth_token"`
+	// Whether the imported repository is a pull mirror
+	Mirror bool `json:"mirror" yaml:"mirror"`
+	// Names of the organization teams to give access to the repository
+	Teams []string `json:"teams" yaml:"teams"`
+}
+
+// BulkRepoJob represents the progress of a bulk repository creation
+type BulkRepoJob struct {
+	ID int64 `json:"id"`
+	// enum: queued,running,stopped,failed,finished
+	Status string `json:"status"`
+	// Number of repositories in the manifest
+	Total int `json:"total"`
+	// Number of repositories which have been processed
+	Done int `json:"done"`
+	// Number of repositories which could not be created
+	Failed int               `json:"failed"`
+	Repos  []*BulkRepoResult `json:"repos"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Finished *time.Time `json:"finished_at"`
+}
+
+// BulkRepoResult represents the outcome for one repository of a BulkRepoJob
+type BulkRepoResult struct {
+	Name string `json:"name"`
+	// enum: pending,created,importing,imported,failed
+	Status string `json:"status"`
+	RepoID int64  `json:"repo_id"`
+	Error  string `json:"error,omitempty"`
+}
@@ -4,9 +4,21 @@
 
 package structs
 
+import "time"
+
 // AddCollaboratorOption options when adding a user as a collaborator of a repository
 type AddCollaboratorOption struct {
 	Permission *string `json:"permission"`
+	// time the collaborator is removed automatically, omit it for a permanent collaborator
+	// swagger:strfmt date-time
+	Expires *time.Time `json:"expires"`
+}
+
+// AddRepoTeamOption options when adding a team to a repository
+type AddRepoTeamOption struct {
+	// time the team loses access to the repository automatically, omit it for a permanent access
+	// swagger:strfmt date-time
+	Expires *time.Time `json:"expires"`
 }
 
 // RepoCollaboratorPermission to get repository permission for a collaborator
@@ -14,4 +26,48 @@ type RepoCollaboratorPermission struct {
 	Permission string `json:"permission"`
 	RoleName   string `json:"role_name"`
 	User       *User  `json:"user"`
+	// swagger:strfmt date-time
+	Expires *time.Time `json:"expires,omitempty"`
+}
+
+// TemporaryAccess represents a collaborator or a team whose access to a repository expires
+type TemporaryAccess struct {
+	// set if the access is granted to a collaborator
+	User *User `json:"user,omitempty"`
+	// set if the access is granted to a team
+	Team *Team `json:"team,omitempty"`
+	// swagger:strfmt date-time
+	Expires time.Time `json:"expires"`
+}
+
+// SetCollaboratorsOption options to replace all collaborators of a repository
+type SetCollaboratorsOption struct {
+	// the complete list of collaborators, the collaborators who aren't listed are removed
+	Collaborators []*CollaboratorAccessOption `json:"collaborators"`
+}
+
+// CollaboratorAccessOption a user and the permission the user is supposed to have as a collaborator
+type CollaboratorAccessOption struct {
+	// required: true
+	Username string `json:"username" binding:"Required"`
+	// if omitted, an existing collaborator keeps their permission and a new one gets write
+	// enum: read,write,admin
+	Permission string `json:"permission"`
+}
+
+// CollaboratorChanges represents the changes made to the collaborators of a repository
+type CollaboratorChanges struct {
+	Added     []*CollaboratorChange `json:"added"`
+	Updated   []*CollaboratorChange `json:"updated"`
+	Removed   []*CollaboratorChange `json:"removed"`
+	Unchanged []*CollaboratorChange `json:"unchanged"`
+}
+
+// CollaboratorChange represents a change of the permission of a collaborator
+type CollaboratorChange struct {
+	User *User `json:"user"`
+	// empty for added collaborators
+	OldPermission string `json:"old_permission,omitempty"`
+	// empty for removed collaborators
+	NewPermission string `json:"new_permission,omitempty"`
 }
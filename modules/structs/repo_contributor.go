@@ -0,0 +1,52 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// ContributorWeek represents the commits of a contributor in one week
+type ContributorWeek struct {
+	// unix timestamp of the start of the week (Sunday 00:00 UTC)
+	Week      int64 `json:"week"`
+	Commits   int64 `json:"commits"`
+	Additions int64 `json:"additions"`
+	Deletions int64 `json:"deletions"`
+}
+
+// ContributorStats represents the commits of a contributor to the default branch of a repository
+type ContributorStats struct {
+	// the user owning the email address of the commits, if any
+	Author    *User  `json:"author"`
+	Name      string `json:"name"`
+	Email     string `json:"email"`
+	Commits   int64  `json:"commits"`
+	Additions int64  `json:"additions"`
+	Deletions int64  `json:"deletions"`
+	// weeks with commits in ascending order
+	Weeks []*ContributorWeek `json:"weeks"`
+}
+
+// CommitActivityDay represents the commits to a branch of a repository authored on one day
+type CommitActivityDay struct {
+	// the day in the requested time zone
+	// example: 2022-05-01
+	Date    string `json:"date"`
+	Commits int64  `json:"commits"`
+}
+
+// CodeFrequencyWeek represents the lines added and deleted on the default branch of a repository in one week
+type CodeFrequencyWeek struct {
+	// unix timestamp of the start of the week (Sunday 00:00 UTC)
+	Week      int64 `json:"week"`
+	Additions int64 `json:"additions"`
+	Deletions int64 `json:"deletions"`
+}
+
+// PunchCardHour represents the commits to the default branch of a repository authored in one hour of a day of the week
+type PunchCardHour struct {
+	// day of the week, 0 is Sunday
+	Day int `json:"day"`
+	// hour of the day in the time zone of the authors
+	Hour    int   `json:"hour"`
+	Commits int64 `json:"commits"`
+}
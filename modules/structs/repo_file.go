@@ -25,9 +25,9 @@ type FileOptions struct {
 // Note: `author` and `committer` are optional (if only one is given, it will be used for the other, otherwise the authenticated user will be used)
 type CreateFileOptions struct {
 	FileOptions
-	// content must be base64 encoded
-	// required: true
+	// content must be base64 encoded, it isn't used for symlinks and submodules
 	Content string `json:"content"`
+	FileEntryOptions
 }
 
 // Branch returns branch name
@@ -53,11 +53,11 @@ func (o *DeleteFileOptions) Branch() string {
 // Note: `author` and `committer` are optional (if only one is given, it will be used for the other, otherwise the authenticated user will be used)
 type UpdateFileOptions struct {
 	DeleteFileOptions
-	// content must be base64 encoded
-	// required: true
+	// content must be base64 encoded, it isn't used for symlinks and submodules
 	Content string `json:"content"`
 	// from_path (optional) is the path of the original file which will be moved/renamed to the path in the URL
 	FromPath string `json:"from_path" binding:"MaxSize(500)"`
+	FileEntryOptions
 }
 
 // Branch returns branch name
@@ -65,6 +65,20 @@ func (o *UpdateFileOptions) Branch() string {
 	return o.FileOptions.BranchName
 }
 
+// FileEntryOptions options for the type and the mode of the entry of created or updated files
+type FileEntryOptions struct {
+	// type (optional) of the entry, `file`, `symlink` or `submodule`, `file` if not given
+	Type string `json:"type" binding:"In(,file,symlink,submodule)"`
+	// executable (optional) sets the executable bit of a file, an updated file keeps its executable bit if not given
+	Executable *bool `json:"executable"`
+	// target of the symlink, required if `type` is `symlink`
+	Target string `json:"target"`
+	// submodule_sha is the commit of the submodule, required if `type` is `submodule`
+	SubmoduleSHA string `json:"submodule_sha"`
+	// submodule_git_url is the URL of the submodule written to .gitmodules, required for new submodules
+	SubmoduleGitURL string `json:"submodule_git_url"`
+}
+
 // FileOptionInterface provides a unified interface for the different file options
 type FileOptionInterface interface {
 	Branch() string
@@ -78,6 +92,75 @@ type ApplyDiffPatchFileOptions struct {
 	Content string `json:"content"`
 }
 
+// CreateCommitOptions options for creating a commit from a set of file changes
+// Note: `author` and `committer` are optional (if only one is given, it will be used for the other, otherwise the authenticated user will be used)
+type CreateCommitOptions struct {
+	// base is the SHA of the parent commit of the new commit
+	// required: true
+	Base string `json:"base" binding:"Required;MaxSize(40)"`
+	// branch (optional) to point to the new commit, it must point to `base` if it exists. if not given, the default branch is used
+	BranchName string `json:"branch" binding:"GitRefName;MaxSize(100)"`
+	// required: true
+	Message   string            `json:"message" binding:"Required"`
+	Author    Identity          `json:"author"`
+	Committer Identity          `json:"committer"`
+	Dates     CommitDateOptions `json:"dates"`
+	// Add a Signed-off-by trailer by the committer at the end of the commit log message, it isn't used for signed commits.
+	Signoff bool `json:"signoff"`
+	// signature (optional) is the armored signature of the commit object, the `dates` are required for signed commits
+	Signature string `json:"signature"`
+	// required: true
+	Files []*CommitFileOptions `json:"files" binding:"Required"`
+}
+
+// CommitFileOptions options for a change of a file in a created commit
+type CommitFileOptions struct {
+	// operation on the file, `create`, `update` or `delete`
+	// required: true
+	Operation string `json:"operation" binding:"Required;In(create,update,delete)"`
+	// path of the changed file
+	// required: true
+	Path string `json:"path" binding:"Required;MaxSize(500)"`
+	// content must be base64 encoded, it isn't used for deleted files
+	Content string `json:"content"`
+	// sha (optional) is the SHA of the updated or deleted file in `base`
+	SHA string `json:"sha"`
+	// from_path (optional) is the path of the original file which will be moved/renamed to `path` by an update
+	FromPath string `json:"from_path" binding:"MaxSize(500)"`
+	// executable (optional) sets the executable bit of the file, an updated file keeps its executable bit if not given
+	Executable *bool `json:"executable"`
+}
+
+// CherryPickCommitOptions options for cherry-picking or reverting a commit onto a branch
+type CherryPickCommitOptions struct {
+	// sha of the commit to cherry-pick or revert
+	// required: true
+	SHA string `json:"sha" binding:"Required;MaxSize(40)"`
+	// branch (optional) the commit is applied onto. if not given, the default branch is used
+	BranchName string `json:"branch" binding:"GitRefName;MaxSize(100)"`
+	// new_branch (optional) will make a new branch from `branch` for the new commit
+	NewBranchName string `json:"new_branch" binding:"GitRefName;MaxSize(100)"`
+	// create_pull_request (optional) opens a pull request from `new_branch` into `branch`
+	CreatePullRequest bool `json:"create_pull_request"`
+	// message (optional) for the new commit. if not supplied, a default message referring to the commit is used
+	Message   string            `json:"message"`
+	Author    Identity          `json:"author"`
+	Committer Identity          `json:"committer"`
+	Dates     CommitDateOptions `json:"dates"`
+	// Add a Signed-off-by trailer by the committer at the end of the commit log message.
+	Signoff bool `json:"signoff"`
+}
+
+// CherryPickCommitResponse contains the commit created by a cherry-pick or revert
+type CherryPickCommitResponse struct {
+	Commit       *FileCommitResponse        `json:"commit"`
+	Verification *PayloadCommitVerification `json:"verification"`
+	// branch the new commit has been pushed to
+	Branch string `json:"branch"`
+	// pull_request opened from `branch`, if any
+	PullRequest *PullRequest `json:"pull_request,omitempty"`
+}
+
 // FileLinksResponse contains the links for a repo's file
 type FileLinksResponse struct {
 	Self    *string `json:"self"`
@@ -92,7 +175,11 @@ type ContentsResponse struct {
 	SHA  string `json:"sha"`
 	// `type` will be `file`, `dir`, `symlink`, or `submodule`
 	Type string `json:"type"`
-	Size int64  `json:"size"`
+	// `mode` is the git file mode of the entry, `100644`, `100755`, `120000`, `160000` or `040000`
+	Mode string `json:"mode"`
+	// `executable` is true if `type` is `file` and the file has the executable bit set
+	Executable bool  `json:"executable"`
+	Size       int64 `json:"size"`
 	// `encoding` is populated when `type` is `file`, otherwise null
 	Encoding *string `json:"encoding"`
 	// `content` is populated when `type` is `file`, otherwise null
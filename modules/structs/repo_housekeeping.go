@@ -0,0 +1,32 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import "time"
+
+// RepoHousekeepingOptions options for running maintenance operations on a repository
+type RepoHousekeepingOptions struct {
+	// Operations to run, `gc` packs the git repository, `clear_last_commit_cache` and `clear_language_stats`
+	// recompute the cached last commits of the files and the language statistics, `regenerate_archives` deletes
+	// the generated archives and generates the ones of the default branch again
+	// required: true
+	Operations []string `json:"operations" binding:"Required"`
+}
+
+// RepoHousekeepingJob represents the progress of maintenance operations on a repository
+type RepoHousekeepingJob struct {
+	ID int64 `json:"id"`
+	// enum: queued,running,stopped,failed,finished
+	Status     string   `json:"status"`
+	Operations []string `json:"operations"`
+	// Error of the failed operation, if the job has failed
+	Error string `json:"error,omitempty"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Started *time.Time `json:"started_at"`
+	// swagger:strfmt date-time
+	Finished *time.Time `json:"finished_at"`
+}
@@ -20,6 +20,8 @@ type DeployKey struct {
 	Created    time.Time   `json:"created_at"`
 	ReadOnly   bool        `json:"read_only"`
 	Repository *Repository `json:"repository,omitempty"`
+	// comma separated list of the networks in CIDR notation the key can be used from, it can be used from everywhere if it's empty
+	AllowedCIDRs string `json:"allowed_cidrs"`
 }
 
 // CreateKeyOption options when creating a key
@@ -38,4 +40,8 @@ type CreateKeyOption struct {
 	//
 	// required: false
 	ReadOnly bool `json:"read_only"`
+	// Comma separated list of the networks in CIDR notation a deploy key can be used from, it's ignored for other keys
+	//
+	// required: false
+	AllowedCIDRs string `json:"allowed_cidrs"`
 }
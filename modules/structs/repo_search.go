@@ -0,0 +1,19 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// CodeSearchResult represents a file matching a code search
+type CodeSearchResult struct {
+	Filename string `json:"filename"`
+	// the branch the file was found in
+	Branch   string `json:"branch"`
+	CommitID string `json:"commit_id"`
+	Language string `json:"language"`
+	HTMLURL  string `json:"html_url"`
+	// line number of the first returned line
+	StartLine int `json:"start_line"`
+	// lines around the first match
+	Content string `json:"content"`
+}
@@ -0,0 +1,24 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+// RepoSettingsChange represents a difference between the settings file and the settings of a repository
+type RepoSettingsChange struct {
+	// section of the settings file: repository, labels, branch_protections or webhooks
+	Section string `json:"section"`
+	// option, label name, branch name or webhook url
+	Name string `json:"name"`
+	// create or update
+	Action string `json:"action"`
+	// reason why the change could not be applied, if any
+	Error string `json:"error,omitempty"`
+}
+
+// RepoSettingsReport represents the changes declared by the settings file of a repository
+type RepoSettingsReport struct {
+	Path     string                `json:"path"`
+	CommitID string                `json:"commit_id"`
+	Changes  []*RepoSettingsChange `json:"changes"`
+}
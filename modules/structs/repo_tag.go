@@ -36,6 +36,9 @@ type AnnotatedTagObject struct {
 type CreateTagOption struct {
 	// required: true
 	TagName string `json:"tag_name" binding:"Required"`
+	// message of the annotated tag, a lightweight tag is created if it is empty and the tag is not signed
 	Message string `json:"message"`
 	Target  string `json:"target"`
+	// sign the tag with the key of the instance
+	Sign bool `json:"sign"`
 }
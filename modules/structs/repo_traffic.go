@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// RepoTraffic represents the clones, fetches or downloads of a repository over the last 14 days
+type RepoTraffic struct {
+	// the number of clones, fetches or downloads
+	Count int64 `json:"count"`
+	// the number of unique users or addresses
+	Uniques int64             `json:"uniques"`
+	Days    []*RepoTrafficDay `json:"days"`
+}
+
+// RepoTrafficDay represents the clones, fetches or downloads of a repository on one day
+type RepoTrafficDay struct {
+	// the start of the day in UTC
+	// swagger:strfmt date-time
+	Timestamp time.Time `json:"timestamp"`
+	Count     int64     `json:"count"`
+	Uniques   int64     `json:"uniques"`
+}
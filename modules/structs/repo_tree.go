@@ -22,4 +22,25 @@ type GitTreeResponse struct {
 	Truncated  bool       `json:"truncated"`
 	Page       int        `json:"page"`
 	TotalCount int        `json:"total_count"`
+	// Path is the path of the tree if it was requested by its path
+	Path string `json:"path,omitempty"`
+}
+
+// GetGitTreesOptions options for getting multiple trees of a repository at once
+type GetGitTreesOptions struct {
+	// SHAs of the trees
+	SHAs []string `json:"shas"`
+	// paths of the trees at the ref
+	Paths []string `json:"paths"`
+	// branch, tag or commit the paths are looked up at, the default branch if empty
+	Ref string `json:"ref"`
+	// list the entries of the subtrees too
+	Recursive bool `json:"recursive"`
+}
+
+// GitTreesResponse represents multiple git trees, each contains at most one page of entries
+type GitTreesResponse struct {
+	Trees []*GitTreeResponse `json:"trees"`
+	// the requested SHAs and paths which don't exist
+	NotFound []string `json:"not_found"`
 }
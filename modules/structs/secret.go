@@ -0,0 +1,33 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package structs
+
+import (
+	"time"
+)
+
+// Secret represents a secret of a repository or organization, its value is never returned
+type Secret struct {
+	Name string `json:"name"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// Variable represents a variable of a repository or organization
+type Variable struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+	// swagger:strfmt date-time
+	Created time.Time `json:"created_at"`
+	// swagger:strfmt date-time
+	Updated time.Time `json:"updated_at"`
+}
+
+// SetSecretOption options for creating or updating a secret or variable
+type SetSecretOption struct {
+	Value string `json:"value"`
+}
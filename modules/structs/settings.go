@@ -19,6 +19,8 @@ type GeneralUISettings struct {
 	DefaultTheme     string   `json:"default_theme"`
 	AllowedReactions []string `json:"allowed_reactions"`
 	CustomEmojis     []string `json:"custom_emojis"`
+	// CustomEmojiURLs are the URLs of the images of the custom emoji uploaded by site admins
+	CustomEmojiURLs map[string]string `json:"custom_emoji_urls"`
 }
 
 // GeneralAPISettings contains global api settings exposed by it
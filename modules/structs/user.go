@@ -91,3 +91,11 @@ type UserSettingsOptions struct {
 	HideEmail    *bool `json:"hide_email"`
 	HideActivity *bool `json:"hide_activity"`
 }
+
+// UserContributionDay represents the contributions of a user on one day
+type UserContributionDay struct {
+	// the day in the requested time zone
+	// example: 2022-05-01
+	Date          string `json:"date"`
+	Contributions int64  `json:"contributions"`
+}
@@ -16,6 +16,8 @@ type AccessToken struct {
 	Name           string `json:"name"`
 	Token          string `json:"sha1"`
 	TokenLastEight string `json:"token_last_eight"`
+	// comma separated list of the networks in CIDR notation the token can be used from, it can be used from everywhere if it's empty
+	AllowedCIDRs string `json:"allowed_cidrs"`
 }
 
 // AccessTokenList represents a list of API access token.
@@ -26,6 +28,8 @@ type AccessTokenList []*AccessToken
 // swagger:parameters userCreateToken
 type CreateAccessTokenOption struct {
 	Name string `json:"name" binding:"Required"`
+	// comma separated list of the networks in CIDR notation the token can be used from, it can be used from everywhere if it's empty
+	AllowedCIDRs string `json:"allowed_cidrs"`
 }
 
 // CreateOAuth2ApplicationOptions holds options to create an oauth2 application
@@ -91,10 +91,18 @@ func NewFuncMap() []template.FuncMap {
 			return fmt.Sprint(time.Since(startTime).Nanoseconds()/1e6) + "ms"
 		},
 		"AllowedReactions": func() []string {
-			return setting.UI.Reactions
+			return emoji.Reactions()
 		},
 		"CustomEmojis": func() map[string]string {
-			return setting.UI.CustomEmojisMap
+			names := emoji.CustomEmojis()
+			m := make(map[string]string, len(names))
+			for _, name := range names {
+				m[name] = ":" + name + ":"
+			}
+			return m
+		},
+		"CustomEmojiURLs": func() map[string]string {
+			return emoji.UploadedURLs()
 		},
 		"Safe":          Safe,
 		"SafeJS":        SafeJS,
@@ -764,7 +772,7 @@ func ReactionToEmoji(reaction string) template.HTML {
 	if val != nil {
 		return template.HTML(val.Emoji)
 	}
-	return template.HTML(fmt.Sprintf(`<img alt=":%s:" src="%s/assets/img/emoji/%s.png"></img>`, reaction, setting.StaticURLPrefix, url.PathEscape(reaction)))
+	return template.HTML(fmt.Sprintf(`<img alt=":%s:" src="%s"></img>`, reaction, emoji.CustomURL(reaction)))
 }
 
 // RenderNote renders the contents of a git-notes file as a commit message.
@@ -806,6 +814,10 @@ func ActionIcon(opType models.ActionType) string {
 	switch opType {
 	case models.ActionCreateRepo, models.ActionTransferRepo, models.ActionRenameRepo:
 		return "repo"
+	case models.ActionPublicizeRepo:
+		return "eye"
+	case models.ActionPrivatizeRepo:
+		return "lock"
 	case models.ActionCommitRepo, models.ActionPushTag, models.ActionDeleteTag, models.ActionDeleteBranch:
 		return "git-commit"
 	case models.ActionCreateIssue:
@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package util
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAnonymizeIP(t *testing.T) {
+	cases := []struct {
+		input    string
+		expected string
+	}{
+		{"192.168.1.42", "192.168.1.0"},
+		{"192.168.1.42:3000", "192.168.1.0"},
+		{"192.168.1.0", "192.168.1.0"},
+		{"2001:db8:85a3:8d3:1319:8a2e:370:7348", "2001:db8:85a3::"},
+		{"[2001:db8:85a3:8d3:1319:8a2e:370:7348]:3000", "2001:db8:85a3::"},
+		{"::ffff:10.0.0.7", "10.0.0.0"},
+		{"", ""},
+		{"not an ip", ""},
+	}
+	for _, c := range cases {
+		assert.Equal(t, c.expected, AnonymizeIP(c.input), c.input)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"regexp"
 	"strings"
 
+	"code.gitea.io/gitea/modules/hookfilter"
+
 	"gitea.com/go-chi/binding"
 	"github.com/gobwas/glob"
 )
@@ -22,6 +24,9 @@ const (
 
 	// ErrRegexPattern is returned when a regex pattern is invalid
 	ErrRegexPattern = "RegexPattern"
+
+	// ErrPayloadFilter is returned when a webhook payload filter expression is invalid
+	ErrPayloadFilter = "PayloadFilter"
 )
 
 // GitRefNamePatternInvalid is regular expression with unallowed characters in git reference name
@@ -56,6 +61,7 @@ func AddBindingRules() {
 	addGlobPatternRule()
 	addRegexPatternRule()
 	addGlobOrRegexPatternRule()
+	addPayloadFilterRule()
 }
 
 func addGitRefNameBindingRule() {
@@ -176,6 +182,26 @@ func addGlobOrRegexPatternRule() {
 	})
 }
 
+func addPayloadFilterRule() {
+	binding.AddRule(&binding.Rule{
+		IsMatch: func(rule string) bool {
+			return rule == "PayloadFilter"
+		},
+		IsValid: func(errs binding.Errors, name string, val interface{}) (bool, binding.Errors) {
+			str := strings.TrimSpace(fmt.Sprintf("%v", val))
+
+			if len(str) != 0 {
+				if _, err := hookfilter.Compile(str); err != nil {
+					errs.Add([]string{name}, ErrPayloadFilter, err.Error())
+					return false, errs
+				}
+			}
+
+			return true, errs
+		},
+	})
+}
+
 func portOnly(hostport string) string {
 	colon := strings.IndexByte(hostport, ':')
 	if colon == -1 {
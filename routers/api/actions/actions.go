@@ -0,0 +1,112 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"fmt"
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+const (
+	runnerUUIDHeader  = "X-Runner-UUID"
+	runnerTokenHeader = "X-Runner-Token"
+)
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	var message string
+	if err, ok := obj.(error); ok {
+		message = err.Error()
+	} else if obj != nil {
+		message = fmt.Sprintf("%s", obj)
+	}
+	if status == http.StatusInternalServerError {
+		log.ErrorWithSkip(1, message)
+
+		if setting.IsProd {
+			message = ""
+		}
+	} else {
+		log.Debug(message)
+	}
+
+	ctx.JSON(status, map[string]string{
+		"message": message,
+	})
+}
+
+// Routes returns the routes used by runners to register themselves, fetch jobs and report their results
+func Routes() *web.Route {
+	r := web.NewRoute()
+
+	r.Use(context.PackageContexter())
+
+	r.Group("/runner", func() {
+		r.Post("/register", Register)
+		r.Group("", func() {
+			r.Post("/fetch", FetchTask)
+			r.Group("/jobs/{id}", func() {
+				r.Post("/logs", AppendLogs)
+				r.Post("/status", UpdateStatus)
+				r.Put("/artifacts/{name}", UploadArtifact)
+			}, reqRunnerJob)
+		}, reqRunner)
+	})
+
+	return r
+}
+
+// reqRunner authenticates the runner sending the request
+func reqRunner(ctx *context.Context) {
+	uuid := ctx.Req.Header.Get(runnerUUIDHeader)
+	token := ctx.Req.Header.Get(runnerTokenHeader)
+	if uuid == "" || token == "" {
+		apiError(ctx, http.StatusUnauthorized, "runner credentials are required")
+		return
+	}
+
+	runner, err := actions_model.GetRunnerByUUID(ctx, uuid)
+	if err != nil {
+		if actions_model.IsErrRunnerNotExist(err) {
+			apiError(ctx, http.StatusUnauthorized, "invalid runner credentials")
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	if !runner.VerifyToken(token) {
+		apiError(ctx, http.StatusUnauthorized, "invalid runner credentials")
+		return
+	}
+	ctx.Data["ActionsRunner"] = runner
+}
+
+// reqRunnerJob loads the job of the request, which has to be assigned to the runner
+func reqRunnerJob(ctx *context.Context) {
+	job, err := actions_service.GetRunnerJob(ctx, getRunner(ctx), ctx.ParamsInt64(":id"))
+	if err != nil {
+		if actions_model.IsErrJobNotExist(err) {
+			apiError(ctx, http.StatusNotFound, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	ctx.Data["ActionsJob"] = job
+}
+
+func getRunner(ctx *context.Context) *actions_model.ActionRunner {
+	return ctx.Data["ActionsRunner"].(*actions_model.ActionRunner)
+}
+
+func getJob(ctx *context.Context) *actions_model.ActionRunJob {
+	return ctx.Data["ActionsJob"].(*actions_model.ActionRunJob)
+}
@@ -0,0 +1,162 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"encoding/json"
+	"net/http"
+	"regexp"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+var artifactNameRegex = regexp.MustCompile(`\A[A-Za-z0-9\.\_\-\+]+\z`)
+
+// maxAppendLogsRequestSize is the maximum size of the body of a request appending log lines
+const maxAppendLogsRequestSize = 16 << 20
+
+// Register registers a runner with a registration token
+func Register(ctx *context.Context) {
+	var opts api.RegisterRunnerOption
+	if err := json.NewDecoder(ctx.Req.Body).Decode(&opts); err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	if opts.Token == "" || opts.Name == "" || len(opts.Name) > 255 {
+		apiError(ctx, http.StatusBadRequest, "a registration token and a name of at most 255 characters are required")
+		return
+	}
+
+	token, err := actions_model.GetRunnerToken(ctx, opts.Token)
+	if err != nil {
+		if actions_model.IsErrRunnerTokenNotExist(err) {
+			apiError(ctx, http.StatusUnauthorized, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	runner := &actions_model.ActionRunner{
+		Name:    opts.Name,
+		OwnerID: token.OwnerID,
+		RepoID:  token.RepoID,
+		Labels:  opts.Labels,
+	}
+	if err := actions_model.CreateRunner(ctx, runner); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, &api.RegisteredRunner{
+		ID:    runner.ID,
+		UUID:  runner.UUID,
+		Token: runner.Token,
+	})
+}
+
+// FetchTask assigns the next job to the runner, it responds with 204 if there is no job to run
+func FetchTask(ctx *context.Context) {
+	task, err := actions_service.PickTask(ctx, getRunner(ctx))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if task == nil {
+		ctx.Status(http.StatusNoContent)
+		return
+	}
+	ctx.JSON(http.StatusOK, task)
+}
+
+// jobNotRunning tells the runner that it should stop the job
+func jobNotRunning(ctx *context.Context, job *actions_model.ActionRunJob) {
+	ctx.JSON(http.StatusConflict, &api.ActionJobState{Status: job.Status.String()})
+}
+
+// AppendLogs appends lines to the log of a running job
+func AppendLogs(ctx *context.Context) {
+	var opts api.AppendJobLogsOption
+	if err := json.NewDecoder(http.MaxBytesReader(ctx.Resp, ctx.Req.Body, maxAppendLogsRequestSize)).Decode(&opts); err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	job := getJob(ctx)
+	if err := actions_service.AppendLogs(ctx, job, opts.Lines); err != nil {
+		if err == actions_service.ErrJobNotRunning {
+			jobNotRunning(ctx, job)
+		} else if err == actions_service.ErrTooManyLogLines {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.ActionJobState{Status: job.Status.String()})
+}
+
+// UpdateStatus records the result of a finished job
+func UpdateStatus(ctx *context.Context) {
+	var opts api.UpdateJobStatusOption
+	if err := json.NewDecoder(ctx.Req.Body).Decode(&opts); err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	status, ok := actions_model.StatusFromString(opts.Status)
+	if !ok {
+		apiError(ctx, http.StatusBadRequest, "unknown status")
+		return
+	}
+
+	job := getJob(ctx)
+	if job.Status != actions_model.StatusRunning {
+		jobNotRunning(ctx, job)
+		return
+	}
+	if !status.IsDone() || status == actions_model.StatusSkipped {
+		apiError(ctx, http.StatusBadRequest, "the status of a finished job has to be success, failure or cancelled")
+		return
+	}
+	if err := actions_service.FinishJob(ctx, job, status); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.ActionJobState{Status: job.Status.String()})
+}
+
+// UploadArtifact stores a file uploaded by a running job
+func UploadArtifact(ctx *context.Context) {
+	name := ctx.Params("name")
+	if len(name) > 255 || !artifactNameRegex.MatchString(name) {
+		apiError(ctx, http.StatusBadRequest, "invalid artifact name")
+		return
+	}
+
+	if ctx.Req.ContentLength > setting.Actions.MaxArtifactSize {
+		apiError(ctx, http.StatusRequestEntityTooLarge, actions_service.ErrArtifactTooLarge)
+		return
+	}
+
+	job := getJob(ctx)
+	upload := ctx.Req.Body
+	defer upload.Close()
+
+	if _, err := actions_service.UploadArtifact(ctx, job, name, upload); err != nil {
+		if err == actions_service.ErrJobNotRunning {
+			jobNotRunning(ctx, job)
+		} else if err == actions_service.ErrArtifactTooLarge {
+			apiError(ctx, http.StatusRequestEntityTooLarge, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+	ctx.Status(http.StatusCreated)
+}
@@ -13,9 +13,11 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/packages/cargo"
 	"code.gitea.io/gitea/routers/api/packages/composer"
 	"code.gitea.io/gitea/routers/api/packages/conan"
 	"code.gitea.io/gitea/routers/api/packages/container"
+	"code.gitea.io/gitea/routers/api/packages/debian"
 	"code.gitea.io/gitea/routers/api/packages/generic"
 	"code.gitea.io/gitea/routers/api/packages/helm"
 	"code.gitea.io/gitea/routers/api/packages/maven"
@@ -23,8 +25,10 @@ import (
 	"code.gitea.io/gitea/routers/api/packages/nuget"
 	"code.gitea.io/gitea/routers/api/packages/pypi"
 	"code.gitea.io/gitea/routers/api/packages/rubygems"
+	"code.gitea.io/gitea/routers/api/packages/terraform"
 	"code.gitea.io/gitea/services/auth"
 	context_service "code.gitea.io/gitea/services/context"
+	"code.gitea.io/gitea/services/maintenance"
 )
 
 func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
@@ -34,6 +38,12 @@ func reqPackageAccess(accessMode perm.AccessMode) func(ctx *context.Context) {
 			ctx.Error(http.StatusUnauthorized, "reqPackageAccess", "user should have specific permission or be a site admin")
 			return
 		}
+		if accessMode > perm.AccessModeRead && !ctx.IsUserSiteAdmin() {
+			if mode := maintenance.Get(ctx); mode != nil {
+				ctx.Error(http.StatusServiceUnavailable, maintenance.Message(mode))
+				return
+			}
+		}
 	}
 }
 
@@ -57,6 +67,22 @@ func Routes() *web.Route {
 	})
 
 	r.Group("/{username}", func() {
+		r.Group("/cargo", func() {
+			r.Get("/config.json", cargo.RepositoryConfig)
+			r.Get("/1/{package}", cargo.EnumeratePackageVersions)
+			r.Get("/2/{package}", cargo.EnumeratePackageVersions)
+			r.Get("/3/{_}/{package}", cargo.EnumeratePackageVersions)
+			r.Get("/{_}/{__}/{package}", cargo.EnumeratePackageVersions)
+			r.Group("/api/v1/crates", func() {
+				r.Get("", cargo.SearchPackages)
+				r.Put("/new", reqPackageAccess(perm.AccessModeWrite), cargo.UploadPackage)
+				r.Group("/{package}/{version}", func() {
+					r.Get("/download", cargo.DownloadPackageFile)
+					r.Delete("/yank", reqPackageAccess(perm.AccessModeWrite), cargo.YankPackage)
+					r.Put("/unyank", reqPackageAccess(perm.AccessModeWrite), cargo.UnyankPackage)
+				})
+			})
+		})
 		r.Group("/composer", func() {
 			r.Get("/packages.json", composer.ServiceIndex)
 			r.Get("/search.json", composer.SearchPackages)
@@ -154,12 +180,33 @@ func Routes() *web.Route {
 				})
 			})
 		})
+		r.Group("/debian", func() {
+			r.Get("/repository.key", debian.GetRepositoryKey)
+			r.Group("/dists/{distribution}", func() {
+				r.Get("/{filename}", debian.GetRepositoryFile)
+				r.Get("/{component}/{architecture}/{filename}", debian.GetPackagesIndexFile)
+			}, debian.VerifyRepositoryName)
+			r.Group("/pool/{distribution}/{component}", func() {
+				r.Put("/upload", reqPackageAccess(perm.AccessModeWrite), debian.UploadPackageFile)
+				r.Get("/{name}_{version}_{architecture}.deb", debian.DownloadPackageFile)
+				r.Delete("/{name}/{version}/{architecture}", reqPackageAccess(perm.AccessModeWrite), debian.DeletePackageFile)
+			}, debian.VerifyRepositoryName)
+		})
 		r.Group("/generic", func() {
 			r.Group("/{packagename}/{packageversion}/{filename}", func() {
 				r.Get("", generic.DownloadPackageFile)
 				r.Group("", func() {
 					r.Put("", generic.UploadPackage)
 					r.Delete("", generic.DeletePackage)
+					if setting.ResumableUpload.Enabled {
+						r.Group("/uploads", func() {
+							r.Options("", generic.UploadPackageOptions)
+							r.Post("", generic.CreatePackageUpload)
+							r.Head("/{upload_id}", generic.GetPackageUpload)
+							r.Patch("/{upload_id}", generic.PatchPackageUpload)
+							r.Delete("/{upload_id}", generic.DeletePackageUpload)
+						})
+					}
 				}, reqPackageAccess(perm.AccessModeWrite))
 			})
 		})
@@ -232,8 +279,23 @@ func Routes() *web.Route {
 				r.Delete("/yank", rubygems.DeletePackage)
 			}, reqPackageAccess(perm.AccessModeWrite))
 		})
+		r.Group("/terraform/modules/{name}/{system}", func() {
+			r.Post("/publish", reqPackageAccess(perm.AccessModeWrite), terraform.PublishFromTag)
+			r.Group("/{version}", func() {
+				r.Put("", reqPackageAccess(perm.AccessModeWrite), terraform.UploadPackage)
+				r.Delete("", reqPackageAccess(perm.AccessModeWrite), terraform.DeletePackage)
+				r.Get("/{filename}", terraform.DownloadPackageFile)
+			})
+		}, terraform.VerifyModuleAddress)
 	}, context_service.UserAssignmentWeb(), context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead))
 
+	// The Terraform module registry protocol appends the module address to a fixed base URL,
+	// so the owner is the namespace of the module address
+	r.Group("/terraform/modules/v1/{username}/{name}/{system}", func() {
+		r.Get("/versions", terraform.EnumerateModuleVersions)
+		r.Get("/{version}/download", terraform.DownloadModuleVersion)
+	}, context_service.UserAssignmentWeb(), context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead), terraform.VerifyModuleAddress)
+
 	return r
 }
 
@@ -278,12 +340,14 @@ func ContainerRoutes() *web.Route {
 				r.Delete("", reqPackageAccess(perm.AccessModeWrite), container.DeleteManifest)
 			})
 			r.Get("/tags/list", container.GetTagList)
+			r.Get("/referrers/{digest}", container.GetReferrers)
 		}, container.VerifyImageName)
 
 		var (
 			blobsUploadsPattern = regexp.MustCompile(`\A(.+)/blobs/uploads/([a-zA-Z0-9-_.=]+)\z`)
 			blobsPattern        = regexp.MustCompile(`\A(.+)/blobs/([^/]+)\z`)
 			manifestsPattern    = regexp.MustCompile(`\A(.+)/manifests/([^/]+)\z`)
+			referrersPattern    = regexp.MustCompile(`\A(.+)/referrers/([^/]+)\z`)
 		)
 
 		// Manual mapping of routes because {image} can contain slashes which chi does not support
@@ -395,6 +459,20 @@ func ContainerRoutes() *web.Route {
 				return
 			}
 
+			m = referrersPattern.FindStringSubmatch(path)
+			if len(m) == 3 && isGet {
+				ctx.SetParams("image", m[1])
+				container.VerifyImageName(ctx)
+				if ctx.Written() {
+					return
+				}
+
+				ctx.SetParams("digest", m[2])
+
+				container.GetReferrers(ctx)
+				return
+			}
+
 			ctx.Status(http.StatusNotFound)
 		})
 	}, container.ReqContainerAccess, context_service.UserAssignmentWeb(), context.PackageAssignment(), reqPackageAccess(perm.AccessModeRead))
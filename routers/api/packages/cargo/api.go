@@ -0,0 +1,121 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package cargo
+
+import (
+	packages_model "code.gitea.io/gitea/models/packages"
+	cargo_module "code.gitea.io/gitea/modules/packages/cargo"
+)
+
+// RepositoryConfigResponse contains the registry configuration
+// https://doc.rust-lang.org/cargo/reference/registry-index.html#index-configuration
+type RepositoryConfigResponse struct {
+	DownloadURL  string `json:"dl"`
+	APIURL       string `json:"api"`
+	AuthRequired bool   `json:"auth-required"`
+}
+
+func createRepositoryConfigResponse(registryURL string, authRequired bool) *RepositoryConfigResponse {
+	return &RepositoryConfigResponse{
+		DownloadURL:  registryURL + "/api/v1/crates",
+		APIURL:       registryURL,
+		AuthRequired: authRequired,
+	}
+}
+
+// IndexEntry is a line of the index file of a package
+// https://doc.rust-lang.org/cargo/reference/registry-index.html#json-schema
+type IndexEntry struct {
+	Name         string                     `json:"name"`
+	Version      string                     `json:"vers"`
+	Dependencies []*cargo_module.Dependency `json:"deps"`
+	FileChecksum string                     `json:"cksum"`
+	Features     map[string][]string        `json:"features"`
+	Yanked       bool                       `json:"yanked"`
+	Links        string                     `json:"links,omitempty"`
+}
+
+func createIndexEntry(pd *packages_model.PackageDescriptor) *IndexEntry {
+	metadata := pd.Metadata.(*cargo_module.Metadata)
+
+	deps := metadata.Dependencies
+	if deps == nil {
+		deps = []*cargo_module.Dependency{}
+	}
+	features := metadata.Features
+	if features == nil {
+		features = map[string][]string{}
+	}
+
+	var checksum string
+	if len(pd.Files) != 0 {
+		checksum = pd.Files[0].Blob.HashSHA256
+	}
+
+	return &IndexEntry{
+		Name:         pd.Package.Name,
+		Version:      pd.Version.Version,
+		Dependencies: deps,
+		FileChecksum: checksum,
+		Features:     features,
+		Yanked:       pd.Properties.GetByName(cargo_module.YankedProperty) == "true",
+		Links:        metadata.Links,
+	}
+}
+
+// SearchResultResponse contains search results
+// https://doc.rust-lang.org/cargo/reference/registries.html#search
+type SearchResultResponse struct {
+	Crates []*SearchResult `json:"crates"`
+	Meta   SearchMeta      `json:"meta"`
+}
+
+// SearchResult contains a search result
+type SearchResult struct {
+	Name          string `json:"name"`
+	LatestVersion string `json:"max_version"`
+	Description   string `json:"description"`
+}
+
+// SearchMeta contains the total number of search results
+type SearchMeta struct {
+	Total int64 `json:"total"`
+}
+
+func createSearchResultResponse(total int64, pds []*packages_model.PackageDescriptor) *SearchResultResponse {
+	crates := make([]*SearchResult, 0, len(pds))
+	for _, pd := range pds {
+		crates = append(crates, &SearchResult{
+			Name:          pd.Package.Name,
+			LatestVersion: pd.Version.Version,
+			Description:   pd.Metadata.(*cargo_module.Metadata).Description,
+		})
+	}
+
+	return &SearchResultResponse{
+		Crates: crates,
+		Meta: SearchMeta{
+			Total: total,
+		},
+	}
+}
+
+// PublishResponse is returned after a package got published
+// https://doc.rust-lang.org/cargo/reference/registries.html#publish
+type PublishResponse struct {
+	Warnings PublishWarnings `json:"warnings"`
+}
+
+// PublishWarnings contains the warnings of a publish request
+type PublishWarnings struct {
+	InvalidCategories []string `json:"invalid_categories"`
+	InvalidBadges     []string `json:"invalid_badges"`
+	Other             []string `json:"other"`
+}
+
+// StatusResponse is returned by the yank and unyank endpoints
+type StatusResponse struct {
+	OK bool `json:"ok"`
+}
@@ -63,6 +63,9 @@ func saveAsPackageBlob(hsr packages_module.HashedSizeReader, pi *packages_servic
 			log.Error("Error inserting package blob: %v", err)
 			return err
 		}
+		if err := packages_service.CheckSizeQuotaExceeded(ctx, pi.Owner.ID, hsr.Size()); err != nil {
+			return err
+		}
 		if !exists {
 			if err := contentStore.Save(packages_module.BlobHash256Key(pb.HashSHA256), hsr, hsr.Size()); err != nil {
 				log.Error("Error saving package blob in content store: %v", err)
@@ -189,6 +189,10 @@ func InitiateUploadBlob(ctx *context.Context) {
 		}
 
 		if _, err := saveAsPackageBlob(buf, &packages_service.PackageInfo{Owner: ctx.Package.Owner, Name: image}); err != nil {
+			if err == packages_service.ErrQuotaTotalSize {
+				apiErrorDefined(ctx, errDenied.WithMessage(err.Error()))
+				return
+			}
 			apiError(ctx, http.StatusInternalServerError, err)
 			return
 		}
@@ -299,6 +303,10 @@ func EndUploadBlob(ctx *context.Context) {
 	}
 
 	if _, err := saveAsPackageBlob(uploader, &packages_service.PackageInfo{Owner: ctx.Package.Owner, Name: image}); err != nil {
+		if err == packages_service.ErrQuotaTotalSize {
+			apiErrorDefined(ctx, errDenied.WithMessage(err.Error()))
+			return
+		}
 		apiError(ctx, http.StatusInternalServerError, err)
 		return
 	}
@@ -441,12 +449,19 @@ func UploadManifest(ctx *context.Context) {
 			apiErrorDefined(ctx, namedError)
 		} else if errors.Is(err, container_model.ErrContainerBlobNotExist) {
 			apiErrorDefined(ctx, errBlobUnknown)
+		} else if errors.Is(err, packages_service.ErrQuotaTotalCount) {
+			apiErrorDefined(ctx, errDenied.WithMessage(err.Error()))
 		} else {
 			apiError(ctx, http.StatusInternalServerError, err)
 		}
 		return
 	}
 
+	// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#pushing-manifests-with-subject
+	if mci.Subject != nil {
+		ctx.Resp.Header().Set("OCI-Subject", string(mci.Subject.Digest))
+	}
+
 	setResponseHeaders(ctx.Resp, &containerHeaders{
 		Location:      fmt.Sprintf("/v2/%s/%s/manifests/%s", ctx.Package.Owner.LowerName, mci.Image, reference),
 		ContentDigest: digest,
@@ -610,3 +625,69 @@ func GetTagList(ctx *context.Context) {
 		Tags: tags,
 	})
 }
+
+// https://github.com/opencontainers/distribution-spec/blob/main/spec.md#listing-referrers
+func GetReferrers(ctx *context.Context) {
+	digest := oci.Digest(ctx.Params("digest"))
+	if !digest.Validate() {
+		apiErrorDefined(ctx, errDigestInvalid)
+		return
+	}
+
+	pfds, err := container_model.GetContainerBlobs(ctx, &container_model.BlobSearchOptions{
+		OwnerID:    ctx.Package.Owner.ID,
+		Image:      ctx.Params("image"),
+		Subject:    string(digest),
+		IsManifest: true,
+	})
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	artifactType := ctx.FormTrim("artifactType")
+
+	index := &oci.Index{
+		SchemaMediaBase: oci.SchemaMediaBase{
+			SchemaVersion: 2,
+			MediaType:     oci.MediaTypeImageIndex,
+		},
+		Manifests: make([]oci.Descriptor, 0, len(pfds)),
+	}
+	for _, pfd := range pfds {
+		pv, err := packages_model.GetVersionByID(ctx, pfd.File.VersionID)
+		if err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		metadata := &container_module.Metadata{}
+		if err := json.Unmarshal([]byte(pv.MetadataJSON), metadata); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+
+		if artifactType != "" && metadata.ArtifactType != artifactType {
+			continue
+		}
+
+		index.Manifests = append(index.Manifests, oci.Descriptor{
+			MediaType:    oci.MediaType(pfd.Properties.GetByName(container_module.PropertyMediaType)),
+			Digest:       oci.Digest(pfd.Properties.GetByName(container_module.PropertyDigest)),
+			Size:         pfd.Blob.Size,
+			Annotations:  metadata.Annotations,
+			ArtifactType: metadata.ArtifactType,
+		})
+	}
+
+	if artifactType != "" {
+		ctx.Resp.Header().Set("OCI-Filters-Applied", "artifactType")
+	}
+	setResponseHeaders(ctx.Resp, &containerHeaders{
+		Status:      http.StatusOK,
+		ContentType: oci.MediaTypeImageIndex,
+	})
+	if err := json.NewEncoder(ctx.Resp).Encode(index); err != nil {
+		log.Error("JSON encode: %v", err)
+	}
+}
@@ -30,6 +30,7 @@ type manifestCreationInfo struct {
 	Image      string
 	Reference  string
 	IsTagged   bool
+	Subject    *oci.Descriptor
 	Properties map[string]string
 }
 
@@ -102,6 +103,12 @@ func processImageManifest(mci *manifestCreationInfo, buf *packages_module.Hashed
 		if err != nil {
 			return err
 		}
+		metadata.SetManifestInfo(manifest.ArtifactType, manifest.Annotations)
+
+		mci.Subject = manifest.Subject
+		if mci.Subject != nil && !mci.Subject.Digest.Validate() {
+			return errManifestInvalid.WithMessage("Subject digest is invalid")
+		}
 
 		blobReferences := make([]*blobReference, 0, 1+len(manifest.Layers))
 
@@ -200,6 +207,12 @@ func processImageManifestIndex(mci *manifestCreationInfo, buf *packages_module.H
 			Type:      container_module.TypeOCI,
 			MultiArch: make(map[string]string),
 		}
+		metadata.SetManifestInfo(index.ArtifactType, index.Annotations)
+
+		mci.Subject = index.Subject
+		if mci.Subject != nil && !mci.Subject.Digest.Validate() {
+			return errManifestInvalid.WithMessage("Subject digest is invalid")
+		}
 
 		for _, manifest := range index.Manifests {
 			if !manifest.MediaType.IsImageManifest() {
@@ -312,12 +325,22 @@ func createPackageAndVersion(ctx context.Context, mci *manifestCreationInfo, met
 		}
 	}
 
+	if err := packages_service.CheckCountQuotaExceeded(ctx, mci.Owner.ID); err != nil {
+		return nil, err
+	}
+
 	if mci.IsTagged {
 		if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, container_module.PropertyManifestTagged, ""); err != nil {
 			log.Error("Error setting package version property: %v", err)
 			return nil, err
 		}
 	}
+	if mci.Subject != nil {
+		if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, container_module.PropertyManifestSubject, string(mci.Subject.Digest)); err != nil {
+			log.Error("Error setting package version property: %v", err)
+			return nil, err
+		}
+	}
 	for _, digest := range metadata.MultiArch {
 		if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, container_module.PropertyManifestReference, digest); err != nil {
 			log.Error("Error setting package version property: %v", err)
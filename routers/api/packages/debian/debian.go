@@ -0,0 +1,286 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	stdctx "context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/notification"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+	debian_service "code.gitea.io/gitea/services/packages/debian"
+)
+
+// errInvalidRepositoryName indicates an invalid distribution or component name
+var errInvalidRepositoryName = errors.New("distribution or component is invalid")
+
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.PlainText(status, message)
+	})
+}
+
+// VerifyRepositoryName checks the distribution and the component of the request
+func VerifyRepositoryName(ctx *context.Context) {
+	if !debian_module.IsValidRepositoryName(ctx.Params("distribution")) {
+		apiError(ctx, http.StatusBadRequest, errInvalidRepositoryName)
+		return
+	}
+	if component := ctx.Params("component"); component != "" && !debian_module.IsValidRepositoryName(component) {
+		apiError(ctx, http.StatusBadRequest, errInvalidRepositoryName)
+	}
+}
+
+// GetRepositoryKey serves the public key which is used to sign the repository files
+func GetRepositoryKey(ctx *context.Context) {
+	_, pub, err := debian_service.GetOrCreateKeyPair(ctx.Package.Owner.ID)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.ServeContent("repository.key", strings.NewReader(pub))
+}
+
+// GetRepositoryFile serves the Release files of a distribution
+// https://wiki.debian.org/DebianRepository/Format#A.22Release.22_files
+func GetRepositoryFile(ctx *context.Context) {
+	serveRepositoryFile(ctx, ctx.Params("distribution"))
+}
+
+// GetPackagesIndexFile serves the Packages index files of a component and architecture
+// https://wiki.debian.org/DebianRepository/Format#A.22Packages.22_Indices
+func GetPackagesIndexFile(ctx *context.Context) {
+	architecture := ctx.Params("architecture")
+	if !strings.HasPrefix(architecture, "binary-") {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageFileNotExist)
+		return
+	}
+
+	serveRepositoryFile(ctx, debian_module.IndexCompositeKey(ctx.Params("distribution"), ctx.Params("component"), strings.TrimPrefix(architecture, "binary-")))
+}
+
+func serveRepositoryFile(ctx *context.Context, compositeKey string) {
+	pv, err := packages_model.GetInternalVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeDebian, debian_module.RepositoryPackage, debian_module.RepositoryVersion)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	s, pf, err := packages_service.GetFileStreamByPackageVersion(
+		ctx,
+		pv,
+		&packages_service.PackageFileInfo{
+			Filename:     ctx.Params("filename"),
+			CompositeKey: compositeKey,
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// UploadPackageFile adds a .deb file to a component of a distribution
+func UploadPackageFile(ctx *context.Context) {
+	distribution := ctx.Params("distribution")
+	component := ctx.Params("component")
+
+	upload, needToClose, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if needToClose {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	pck, err := debian_module.ParsePackage(buf)
+	if err != nil {
+		if errors.Is(err, debian_module.ErrInvalidArchive) ||
+			errors.Is(err, debian_module.ErrMissingControlFile) ||
+			errors.Is(err, debian_module.ErrUnsupportedCompression) ||
+			errors.Is(err, debian_module.ErrInvalidName) ||
+			errors.Is(err, debian_module.ErrInvalidVersion) ||
+			errors.Is(err, debian_module.ErrInvalidArchitecture) {
+			apiError(ctx, http.StatusBadRequest, err)
+		} else {
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	_, _, err = packages_service.CreatePackageOrAddFileToExisting(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeDebian,
+				Name:        pck.Name,
+				Version:     pck.Version,
+			},
+			Creator:  ctx.Doer,
+			Metadata: pck.Metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename:     debian_module.PackageFilename(pck.Name, pck.Version, pck.Architecture),
+				CompositeKey: debian_module.PackageCompositeKey(distribution, component),
+			},
+			Data:   buf,
+			IsLead: true,
+			Properties: map[string]string{
+				debian_module.PropertyDistribution: distribution,
+				debian_module.PropertyComponent:    component,
+				debian_module.PropertyArchitecture: pck.Architecture,
+				debian_module.PropertyControl:      pck.Control,
+			},
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageFile:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if err := debian_service.BuildSpecificRepositoryFiles(ctx, ctx.Package.Owner.ID, distribution, component, pck.Architecture); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DownloadPackageFile serves a .deb file of the pool
+func DownloadPackageFile(ctx *context.Context) {
+	name := ctx.Params("name")
+	version := ctx.Params("version")
+
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeDebian,
+			Name:        name,
+			Version:     version,
+		},
+		&packages_service.PackageFileInfo{
+			Filename:     debian_module.PackageFilename(name, version, ctx.Params("architecture")),
+			CompositeKey: debian_module.PackageCompositeKey(ctx.Params("distribution"), ctx.Params("component")),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// DeletePackageFile removes a .deb file from a component of a distribution.
+// The package version gets deleted if it has no files left.
+func DeletePackageFile(ctx *context.Context) {
+	distribution := ctx.Params("distribution")
+	component := ctx.Params("component")
+	name := ctx.Params("name")
+	version := ctx.Params("version")
+	architecture := ctx.Params("architecture")
+
+	var pd *packages_model.PackageDescriptor
+	err := db.WithTx(func(tx stdctx.Context) error {
+		pv, err := packages_model.GetVersionByNameAndVersion(tx, ctx.Package.Owner.ID, packages_model.TypeDebian, name, version)
+		if err != nil {
+			return err
+		}
+
+		pf, err := packages_model.GetFileForVersionByName(tx, pv.ID, debian_module.PackageFilename(name, version, architecture), debian_module.PackageCompositeKey(distribution, component))
+		if err != nil {
+			return err
+		}
+
+		if err := packages_service.DeletePackageFile(tx, pf); err != nil {
+			return err
+		}
+
+		has, err := packages_model.HasVersionFileReferences(tx, pv.ID)
+		if err != nil {
+			return err
+		}
+		if !has {
+			pd, err = packages_model.GetPackageDescriptor(tx, pv)
+			if err != nil {
+				return err
+			}
+
+			if err := packages_service.DeletePackageVersionAndReferences(tx, pv); err != nil {
+				return fmt.Errorf("DeletePackageVersionAndReferences: %w", err)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	if pd != nil {
+		notification.NotifyPackageDelete(ctx.Doer, pd)
+	}
+
+	if err := debian_service.BuildSpecificRepositoryFiles(ctx, ctx.Package.Owner.ID, distribution, component, architecture); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
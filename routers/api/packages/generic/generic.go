@@ -6,6 +6,7 @@ package generic
 
 import (
 	"errors"
+	"io"
 	"net/http"
 	"regexp"
 
@@ -81,11 +82,18 @@ func UploadPackage(ctx *context.Context) {
 		defer upload.Close()
 	}
 
-	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if addPackageFile(ctx, packageName, packageVersion, filename, upload) {
+		ctx.Status(http.StatusCreated)
+	}
+}
+
+// addPackageFile creates the package version if needed and adds the file to it, it returns false if it failed
+func addPackageFile(ctx *context.Context, packageName, packageVersion, filename string, r io.Reader) bool {
+	buf, err := packages_module.CreateHashedBufferFromReader(r, 32*1024*1024)
 	if err != nil {
 		log.Error("Error creating hashed buffer: %v", err)
 		apiError(ctx, http.StatusInternalServerError, err)
-		return
+		return false
 	}
 	defer buf.Close()
 
@@ -109,15 +117,17 @@ func UploadPackage(ctx *context.Context) {
 		},
 	)
 	if err != nil {
-		if err == packages_model.ErrDuplicatePackageVersion {
+		switch err {
+		case packages_model.ErrDuplicatePackageVersion:
 			apiError(ctx, http.StatusBadRequest, err)
-			return
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
 		}
-		apiError(ctx, http.StatusInternalServerError, err)
-		return
+		return false
 	}
-
-	ctx.Status(http.StatusCreated)
+	return true
 }
 
 // DeletePackage deletes the specific generic package.
@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package generic
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+
+	resumable_model "code.gitea.io/gitea/models/resumable"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	resumable_service "code.gitea.io/gitea/services/resumable"
+)
+
+// getPackageFileUpload returns the resumable upload of the request to the package file of the request
+func getPackageFileUpload(ctx *context.Context) *resumable_model.Upload {
+	packageName, packageVersion, filename, err := sanitizeParameters(ctx)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return nil
+	}
+	return resumable_service.GetUpload(ctx, ctx.Params("upload_id"), func(u *resumable_model.Upload) bool {
+		return u.TargetType == resumable_model.TargetGenericPackage && u.OwnerID == ctx.Package.Owner.ID &&
+			u.PackageName == packageName && u.PackageVersion == packageVersion && u.Filename == filename
+	})
+}
+
+// finishPackageFileUpload adds the file of a complete upload to the package
+func finishPackageFileUpload(ctx *context.Context) resumable_service.Finisher {
+	return func(u *resumable_model.Upload, file *os.File) bool {
+		return addPackageFile(ctx, u.PackageName, u.PackageVersion, u.Filename, file)
+	}
+}
+
+// UploadPackageOptions describes the capabilities of the resumable uploads of generic packages
+func UploadPackageOptions(ctx *context.Context) {
+	resumable_service.Options(ctx, 0)
+}
+
+// CreatePackageUpload starts a resumable upload of a generic package file using the tus protocol
+func CreatePackageUpload(ctx *context.Context) {
+	packageName, packageVersion, filename, err := sanitizeParameters(ctx)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	resumable_service.Create(ctx, &resumable_model.Upload{
+		TargetType:     resumable_model.TargetGenericPackage,
+		OwnerID:        ctx.Package.Owner.ID,
+		PackageName:    packageName,
+		PackageVersion: packageVersion,
+		Filename:       filename,
+	}, 0,
+		fmt.Sprintf("%sapi/packages/%s/generic/%s/%s/%s/uploads", setting.AppURL,
+			url.PathEscape(ctx.Package.Owner.Name), url.PathEscape(packageName), url.PathEscape(packageVersion), url.PathEscape(filename)),
+		finishPackageFileUpload(ctx))
+}
+
+// GetPackageUpload returns the progress of a resumable upload of a generic package file
+func GetPackageUpload(ctx *context.Context) {
+	if u := getPackageFileUpload(ctx); u != nil {
+		resumable_service.Head(ctx, u)
+	}
+}
+
+// PatchPackageUpload appends data to a resumable upload of a generic package file
+func PatchPackageUpload(ctx *context.Context) {
+	if u := getPackageFileUpload(ctx); u != nil {
+		resumable_service.Patch(ctx, u, finishPackageFileUpload(ctx))
+	}
+}
+
+// DeletePackageUpload aborts a resumable upload of a generic package file
+func DeletePackageUpload(ctx *context.Context) {
+	if u := getPackageFileUpload(ctx); u != nil {
+		resumable_service.Terminate(ctx, u)
+	}
+}
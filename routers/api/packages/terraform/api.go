@@ -0,0 +1,42 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package terraform
+
+import (
+	packages_model "code.gitea.io/gitea/models/packages"
+)
+
+// ModuleVersionsResponse lists the available versions of a module
+// https://developer.hashicorp.com/terraform/internals/module-registry-protocol#list-available-versions-for-a-specific-module
+type ModuleVersionsResponse struct {
+	Modules []*ModuleVersions `json:"modules"`
+}
+
+// ModuleVersions contains the versions of a module
+type ModuleVersions struct {
+	Versions []*ModuleVersion `json:"versions"`
+}
+
+// ModuleVersion is a single version of a module
+type ModuleVersion struct {
+	Version string `json:"version"`
+}
+
+func createModuleVersionsResponse(pvs []*packages_model.PackageVersion) *ModuleVersionsResponse {
+	versions := make([]*ModuleVersion, 0, len(pvs))
+	for _, pv := range pvs {
+		versions = append(versions, &ModuleVersion{
+			Version: pv.Version,
+		})
+	}
+
+	return &ModuleVersionsResponse{
+		Modules: []*ModuleVersions{
+			{
+				Versions: versions,
+			},
+		},
+	}
+}
@@ -0,0 +1,297 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package terraform
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	terraform_module "code.gitea.io/gitea/modules/packages/terraform"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/routers/api/packages/helper"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+// https://developer.hashicorp.com/terraform/internals/module-registry-protocol
+func apiError(ctx *context.Context, status int, obj interface{}) {
+	helper.LogAndProcessError(ctx, status, obj, func(message string) {
+		ctx.JSON(status, struct {
+			Errors []string `json:"errors"`
+		}{
+			Errors: []string{message},
+		})
+	})
+}
+
+// VerifyModuleAddress checks the name and the system of the requested module
+func VerifyModuleAddress(ctx *context.Context) {
+	if err := terraform_module.ValidateModuleAddress(ctx.Params("name"), ctx.Params("system")); err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+	}
+}
+
+func packageName(ctx *context.Context) string {
+	return terraform_module.PackageName(ctx.Params("name"), ctx.Params("system"))
+}
+
+// EnumerateModuleVersions lists the versions of a module
+// https://developer.hashicorp.com/terraform/internals/module-registry-protocol#list-available-versions-for-a-specific-module
+func EnumerateModuleVersions(ctx *context.Context) {
+	pvs, err := packages_model.GetVersionsByPackageName(ctx, ctx.Package.Owner.ID, packages_model.TypeTerraform, packageName(ctx))
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if len(pvs) == 0 {
+		apiError(ctx, http.StatusNotFound, packages_model.ErrPackageNotExist)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, createModuleVersionsResponse(pvs))
+}
+
+// DownloadModuleVersion tells Terraform where to download the archive of a module version
+// https://developer.hashicorp.com/terraform/internals/module-registry-protocol#download-source-code-for-a-specific-module-version
+func DownloadModuleVersion(ctx *context.Context) {
+	pv, err := packages_model.GetVersionByNameAndVersion(ctx, ctx.Package.Owner.ID, packages_model.TypeTerraform, packageName(ctx), ctx.Params("version"))
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Resp.Header().Set("X-Terraform-Get", fmt.Sprintf(
+		"%sapi/packages/%s/terraform/modules/%s/%s/%s/%s",
+		setting.AppURL,
+		url.PathEscape(ctx.Package.Owner.Name),
+		url.PathEscape(ctx.Params("name")),
+		url.PathEscape(ctx.Params("system")),
+		url.PathEscape(pv.Version),
+		url.PathEscape(createFilename(ctx.Params("name"), ctx.Params("system"), pv.Version)),
+	))
+	ctx.Status(http.StatusNoContent)
+}
+
+// DownloadPackageFile serves the archive of a module version
+func DownloadPackageFile(ctx *context.Context) {
+	s, pf, err := packages_service.GetFileStreamByPackageNameAndVersion(
+		ctx,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeTerraform,
+			Name:        packageName(ctx),
+			Version:     ctx.Params("version"),
+		},
+		&packages_service.PackageFileInfo{
+			Filename: ctx.Params("filename"),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist || err == packages_model.ErrPackageFileNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer s.Close()
+
+	ctx.ServeStream(s, pf.Name)
+}
+
+// UploadPackage creates a module version from an uploaded .tar.gz archive
+func UploadPackage(ctx *context.Context) {
+	moduleVersion, err := terraform_module.ParseVersion(ctx.Params("version"))
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	upload, needToClose, err := ctx.UploadStream()
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if needToClose {
+		defer upload.Close()
+	}
+
+	buf, err := packages_module.CreateHashedBufferFromReader(upload, 32*1024*1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	createModuleVersion(ctx, buf, moduleVersion, nil, "")
+}
+
+// PublishFromTag creates a module version from the content of a repository tag.
+// The module version is parsed from the tag name.
+func PublishFromTag(ctx *context.Context) {
+	tag := ctx.FormString("tag")
+
+	moduleVersion, err := terraform_module.ParseVersion(tag)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+
+	repo, err := repo_model.GetRepositoryByName(ctx.Package.Owner.ID, ctx.FormString("repository"))
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	permission, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	if !permission.CanRead(unit.TypeCode) {
+		apiError(ctx, http.StatusNotFound, repo_model.ErrRepoNotExist{ID: repo.ID})
+		return
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer gitRepo.Close()
+
+	commitID, err := gitRepo.GetTagCommitID(tag)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	buf, err := packages_module.NewHashedBuffer(32 * 1024 * 1024)
+	if err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+	defer buf.Close()
+
+	if err := gitRepo.CreateArchive(ctx, git.TARGZ, buf, false, commitID); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	createModuleVersion(ctx, buf, moduleVersion, repo, tag)
+}
+
+func createModuleVersion(ctx *context.Context, buf *packages_module.HashedBuffer, moduleVersion string, repo *repo_model.Repository, tag string) {
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	metadata, err := terraform_module.ParseModuleArchive(buf)
+	if err != nil {
+		apiError(ctx, http.StatusBadRequest, err)
+		return
+	}
+	metadata.Name = ctx.Params("name")
+	metadata.System = ctx.Params("system")
+	if repo != nil {
+		metadata.RepositoryURL = repo.HTMLURL()
+		metadata.Tag = tag
+	}
+
+	if _, err := buf.Seek(0, io.SeekStart); err != nil {
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	pv, _, err := packages_service.CreatePackageAndAddFile(
+		&packages_service.PackageCreationInfo{
+			PackageInfo: packages_service.PackageInfo{
+				Owner:       ctx.Package.Owner,
+				PackageType: packages_model.TypeTerraform,
+				Name:        packageName(ctx),
+				Version:     moduleVersion,
+			},
+			SemverCompatible: true,
+			Creator:          ctx.Doer,
+			Metadata:         metadata,
+		},
+		&packages_service.PackageFileCreationInfo{
+			PackageFileInfo: packages_service.PackageFileInfo{
+				Filename: createFilename(ctx.Params("name"), ctx.Params("system"), moduleVersion),
+			},
+			Data:   buf,
+			IsLead: true,
+		},
+	)
+	if err != nil {
+		switch err {
+		case packages_model.ErrDuplicatePackageVersion:
+			apiError(ctx, http.StatusConflict, err)
+		case packages_service.ErrQuotaTotalCount, packages_service.ErrQuotaTotalSize:
+			apiError(ctx, http.StatusForbidden, err)
+		default:
+			apiError(ctx, http.StatusInternalServerError, err)
+		}
+		return
+	}
+
+	if repo != nil {
+		if err := packages_model.SetRepositoryLink(ctx, pv.PackageID, repo.ID); err != nil {
+			apiError(ctx, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	ctx.Status(http.StatusCreated)
+}
+
+// DeletePackage deletes a module version
+func DeletePackage(ctx *context.Context) {
+	err := packages_service.RemovePackageVersionByNameAndVersion(
+		ctx.Doer,
+		&packages_service.PackageInfo{
+			Owner:       ctx.Package.Owner,
+			PackageType: packages_model.TypeTerraform,
+			Name:        packageName(ctx),
+			Version:     ctx.Params("version"),
+		},
+	)
+	if err != nil {
+		if err == packages_model.ErrPackageNotExist {
+			apiError(ctx, http.StatusNotFound, err)
+			return
+		}
+		apiError(ctx, http.StatusInternalServerError, err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func createFilename(name, system, version string) string {
+	return strings.ToLower(fmt.Sprintf("%s-%s-%s.tar.gz", name, system, version))
+}
@@ -0,0 +1,78 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/json"
+)
+
+// parseFilter parses the filters of the form `attribute eq "value"` identity providers look up resources with,
+// the attribute is returned in lower case as attribute names are case insensitive.
+func parseFilter(filter string) (attribute, value string, err error) {
+	fields := strings.SplitN(strings.TrimSpace(filter), " ", 3)
+	if len(fields) != 3 || !strings.EqualFold(fields[1], "eq") {
+		return "", "", fmt.Errorf("unsupported filter %q, only the eq operator is supported", filter)
+	}
+	value = strings.TrimSpace(fields[2])
+	if strings.HasPrefix(value, `"`) {
+		if value, err = strconv.Unquote(value); err != nil {
+			return "", "", fmt.Errorf("invalid value of filter %q: %v", filter, err)
+		}
+	}
+	return strings.ToLower(fields[0]), value, nil
+}
+
+// patchRequest is the body of the PATCH requests, see RFC 7644 section 3.5.2
+type patchRequest struct {
+	Schemas    []string          `json:"schemas"`
+	Operations []*patchOperation `json:"Operations"`
+}
+
+type patchOperation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value"`
+}
+
+// attributes returns the attributes the operation sets with their values, an operation without a path
+// sets all the attributes of its value.
+func (op *patchOperation) attributes() (map[string]json.RawMessage, error) {
+	if op.Path != "" {
+		return map[string]json.RawMessage{op.Path: op.Value}, nil
+	}
+	attributes := make(map[string]json.RawMessage)
+	if err := json.Unmarshal(op.Value, &attributes); err != nil {
+		return nil, fmt.Errorf("the value of a %s operation without a path must be an object: %v", op.Op, err)
+	}
+	return attributes, nil
+}
+
+// parseString parses a string value of a PATCH operation, a missing value is an empty string
+func parseString(value json.RawMessage) (string, error) {
+	if len(value) == 0 || string(value) == "null" {
+		return "", nil
+	}
+	var s string
+	err := json.Unmarshal(value, &s)
+	return s, err
+}
+
+// parseBool parses a boolean value of a PATCH operation,
+// some identity providers send booleans as strings like "False".
+func parseBool(value json.RawMessage) (bool, error) {
+	var b bool
+	if err := json.Unmarshal(value, &b); err == nil {
+		return b, nil
+	}
+	s, err := parseString(value)
+	if err != nil {
+		return false, err
+	}
+	return strconv.ParseBool(strings.ToLower(s))
+}
@@ -0,0 +1,437 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+type scimMember struct {
+	Value   string `json:"value"`
+	Display string `json:"display,omitempty"`
+	Ref     string `json:"$ref,omitempty"`
+}
+
+// scimGroup is the Group resource of RFC 7643 section 4.2, the groups are the teams of the organization
+type scimGroup struct {
+	Schemas     []string     `json:"schemas"`
+	ID          string       `json:"id,omitempty"`
+	DisplayName string       `json:"displayName"`
+	Members     []scimMember `json:"members,omitempty"`
+	Meta        *scimMeta    `json:"meta,omitempty"`
+}
+
+// toSCIMGroup converts the team, the members are only included if they have been loaded
+func toSCIMGroup(team *organization.Team) *scimGroup {
+	group := &scimGroup{
+		Schemas:     []string{schemaGroup},
+		ID:          strconv.FormatInt(team.ID, 10),
+		DisplayName: team.Name,
+		Meta: &scimMeta{
+			ResourceType: "Group",
+			Location:     resourceLocation("Groups", team.ID),
+		},
+	}
+	for _, member := range team.Members {
+		group.Members = append(group.Members, scimMember{
+			Value:   strconv.FormatInt(member.ID, 10),
+			Display: member.Name,
+			Ref:     resourceLocation("Users", member.ID),
+		})
+	}
+	return group
+}
+
+// memberValues returns the ids of the members as they are identified by the SCIM requests
+func memberValues(members []scimMember) map[string]bool {
+	values := make(map[string]bool, len(members))
+	for _, member := range members {
+		values[member.Value] = true
+	}
+	return values
+}
+
+// applyGroupPatch applies the operations of a PATCH request to the name and the member ids of a group
+func applyGroupPatch(name *string, members map[string]bool, ops []*patchOperation) error {
+	for _, op := range ops {
+		switch opName := strings.ToLower(op.Op); opName {
+		case "add", "replace":
+			attributes, err := op.attributes()
+			if err != nil {
+				return err
+			}
+			for path, value := range attributes {
+				switch strings.ToLower(path) {
+				case "displayname":
+					if *name, err = parseString(value); err != nil {
+						return fmt.Errorf("invalid value of %s: %v", path, err)
+					}
+				case "members":
+					var added []scimMember
+					if err := json.Unmarshal(value, &added); err != nil {
+						return fmt.Errorf("invalid value of %s: %v", path, err)
+					}
+					if opName == "replace" {
+						for value := range members {
+							delete(members, value)
+						}
+					}
+					for value := range memberValues(added) {
+						members[value] = true
+					}
+				}
+			}
+		case "remove":
+			path := strings.ToLower(op.Path)
+			switch {
+			case path == "members":
+				if len(op.Value) == 0 {
+					for value := range members {
+						delete(members, value)
+					}
+					continue
+				}
+				var removed []scimMember
+				if err := json.Unmarshal(op.Value, &removed); err != nil {
+					return fmt.Errorf("invalid value of %s: %v", op.Path, err)
+				}
+				for value := range memberValues(removed) {
+					delete(members, value)
+				}
+			case strings.HasPrefix(path, "members[") && strings.HasSuffix(path, "]"):
+				attribute, value, err := parseFilter(op.Path[len("members[") : len(op.Path)-1])
+				if err != nil {
+					return err
+				}
+				if attribute != "value" {
+					return fmt.Errorf("unsupported filter attribute %q of the members", attribute)
+				}
+				delete(members, value)
+			default:
+				return fmt.Errorf("unable to remove %q", op.Path)
+			}
+		default:
+			return fmt.Errorf("unsupported operation %q", op.Op)
+		}
+	}
+	return nil
+}
+
+// reqOrganization loads the organization of the configuration whose teams are the groups
+func reqOrganization(ctx *context.Context) {
+	if setting.SCIM.Organization == "" {
+		scimError(ctx, http.StatusNotFound, "", "groups are not provisioned as no organization is configured")
+		return
+	}
+	org, err := organization.GetOrgByName(setting.SCIM.Organization)
+	if err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", fmt.Errorf("organization %q of SCIM: %v", setting.SCIM.Organization, err))
+		return
+	}
+	ctx.Data["SCIMOrganization"] = org
+}
+
+func getOrganization(ctx *context.Context) *organization.Organization {
+	return ctx.Data["SCIMOrganization"].(*organization.Organization)
+}
+
+// reqGroup loads the team of the request with its members
+func reqGroup(ctx *context.Context) {
+	team, err := organization.GetTeamByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if organization.IsErrTeamNotExist(err) {
+			scimError(ctx, http.StatusNotFound, "", err)
+		} else {
+			scimError(ctx, http.StatusInternalServerError, "", err)
+		}
+		return
+	}
+	if team.OrgID != getOrganization(ctx).ID {
+		scimError(ctx, http.StatusNotFound, "", organization.ErrTeamNotExist{TeamID: team.ID})
+		return
+	}
+	if err := team.GetMembersCtx(ctx); err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+	ctx.Data["SCIMTeam"] = team
+}
+
+func getTeam(ctx *context.Context) *organization.Team {
+	return ctx.Data["SCIMTeam"].(*organization.Team)
+}
+
+// excludesMembers returns true if the members are excluded from the response, which saves loading them
+func excludesMembers(ctx *context.Context) bool {
+	for _, attribute := range strings.Split(ctx.FormString("excludedAttributes"), ",") {
+		if strings.EqualFold(strings.TrimSpace(attribute), "members") {
+			return true
+		}
+	}
+	return false
+}
+
+// ListGroups lists the teams of the organization, filtered by their displayName
+func ListGroups(ctx *context.Context) {
+	org := getOrganization(ctx)
+	startIndex, count := listOptions(ctx)
+
+	var teams []*organization.Team
+	if filter := ctx.FormString("filter"); filter != "" {
+		attribute, value, err := parseFilter(filter)
+		if err != nil {
+			scimError(ctx, http.StatusBadRequest, errorInvalidFilter, err)
+			return
+		}
+		if attribute != "displayname" {
+			scimError(ctx, http.StatusBadRequest, errorInvalidFilter, fmt.Sprintf("unsupported filter attribute %q", attribute))
+			return
+		}
+		team, err := organization.GetTeam(ctx, org.ID, value)
+		if err != nil && !organization.IsErrTeamNotExist(err) {
+			scimError(ctx, http.StatusInternalServerError, "", err)
+			return
+		}
+		if team != nil {
+			teams = append(teams, team)
+		}
+	} else {
+		var err error
+		if teams, _, err = organization.SearchTeam(&organization.SearchTeamOptions{
+			OrgID:       org.ID,
+			ListOptions: db.ListOptions{PageSize: -1},
+		}); err != nil {
+			scimError(ctx, http.StatusInternalServerError, "", err)
+			return
+		}
+	}
+
+	// an organization has few teams, so they are paged after loading all of them
+	total := int64(len(teams))
+	if startIndex > len(teams) {
+		teams = nil
+	} else {
+		teams = teams[startIndex-1:]
+	}
+	if len(teams) > count {
+		teams = teams[:count]
+	}
+
+	withMembers := !excludesMembers(ctx)
+	resources := make([]interface{}, 0, len(teams))
+	for _, team := range teams {
+		if withMembers {
+			if err := team.GetMembersCtx(ctx); err != nil {
+				scimError(ctx, http.StatusInternalServerError, "", err)
+				return
+			}
+		}
+		resources = append(resources, toSCIMGroup(team))
+	}
+	writeJSON(ctx, http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// GetGroup returns the team
+func GetGroup(ctx *context.Context) {
+	team := getTeam(ctx)
+	if excludesMembers(ctx) {
+		team.Members = nil
+	}
+	writeJSON(ctx, http.StatusOK, toSCIMGroup(team))
+}
+
+// CreateGroup creates a team of the organization, its members have read access to the default units
+func CreateGroup(ctx *context.Context) {
+	group := &scimGroup{}
+	if !readJSON(ctx, group) {
+		return
+	}
+	if group.DisplayName == "" {
+		scimError(ctx, http.StatusBadRequest, errorInvalidValue, "displayName is required")
+		return
+	}
+	team := &organization.Team{
+		OrgID:      getOrganization(ctx).ID,
+		Name:       group.DisplayName,
+		AccessMode: perm.AccessModeRead,
+	}
+	for _, tp := range unit.DefaultRepoUnits {
+		team.Units = append(team.Units, &organization.TeamUnit{
+			OrgID:      team.OrgID,
+			Type:       tp,
+			AccessMode: team.AccessMode,
+		})
+	}
+	if err := models.NewTeam(team); err != nil {
+		switch {
+		case organization.IsErrTeamAlreadyExist(err):
+			scimError(ctx, http.StatusConflict, errorUniqueness, err)
+		case db.IsErrNameReserved(err),
+			db.IsErrNamePatternNotAllowed(err),
+			db.IsErrNameCharsNotAllowed(err):
+			scimError(ctx, http.StatusBadRequest, errorInvalidValue, err)
+		default:
+			scimError(ctx, http.StatusInternalServerError, "", err)
+		}
+		return
+	}
+	if !updateGroup(ctx, team, group.DisplayName, memberValues(group.Members)) {
+		return
+	}
+	writeJSON(ctx, http.StatusCreated, toSCIMGroup(team))
+}
+
+// updateGroup renames the team and adds and removes its members to match the member ids
+func updateGroup(ctx *context.Context, team *organization.Team, name string, members map[string]bool) bool {
+	if name != team.Name {
+		if name == "" {
+			scimError(ctx, http.StatusBadRequest, errorInvalidValue, "displayName is required")
+			return false
+		}
+		if team.IsOwnerTeam() {
+			scimError(ctx, http.StatusBadRequest, errorMutability, "the owners team can't be renamed")
+			return false
+		}
+		team.Name = name
+		if err := models.UpdateTeam(team, false, false); err != nil {
+			if organization.IsErrTeamAlreadyExist(err) {
+				scimError(ctx, http.StatusConflict, errorUniqueness, err)
+			} else {
+				scimError(ctx, http.StatusInternalServerError, "", err)
+			}
+			return false
+		}
+	}
+
+	org := getOrganization(ctx)
+	current := make(map[int64]*user_model.User, len(team.Members))
+	for _, member := range team.Members {
+		current[member.ID] = member
+	}
+	wanted := make(map[int64]*user_model.User, len(members))
+	for value := range members {
+		id, err := strconv.ParseInt(value, 10, 64)
+		if err != nil {
+			scimError(ctx, http.StatusBadRequest, errorInvalidValue, fmt.Sprintf("invalid member %q", value))
+			return false
+		}
+		if member, ok := current[id]; ok {
+			wanted[id] = member
+			continue
+		}
+		member, err := user_model.GetUserByID(id)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				scimError(ctx, http.StatusBadRequest, errorInvalidValue, err)
+			} else {
+				scimError(ctx, http.StatusInternalServerError, "", err)
+			}
+			return false
+		}
+		if member.IsOrganization() {
+			scimError(ctx, http.StatusBadRequest, errorInvalidValue, fmt.Sprintf("member %q is not a user", value))
+			return false
+		}
+		wanted[id] = member
+	}
+
+	for id, member := range wanted {
+		if _, ok := current[id]; ok {
+			continue
+		}
+		if err := models.AddTeamMember(team, id); err != nil {
+			scimError(ctx, http.StatusInternalServerError, "", err)
+			return false
+		}
+		audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionTeamMemberAdd, org.Name+"/"+team.Name, member.Name)
+	}
+	for id, member := range current {
+		if _, ok := wanted[id]; ok {
+			continue
+		}
+		if err := models.RemoveTeamMember(team, id); err != nil {
+			if organization.IsErrLastOrgOwner(err) {
+				scimError(ctx, http.StatusBadRequest, errorMutability, err)
+			} else {
+				scimError(ctx, http.StatusInternalServerError, "", err)
+			}
+			return false
+		}
+		audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionTeamMemberRemove, org.Name+"/"+team.Name, member.Name)
+	}
+
+	team.Members = make([]*user_model.User, 0, len(wanted))
+	for _, member := range wanted {
+		team.Members = append(team.Members, member)
+	}
+	return true
+}
+
+// ReplaceGroup replaces the name and the members of the team
+func ReplaceGroup(ctx *context.Context) {
+	team := getTeam(ctx)
+	group := &scimGroup{}
+	if !readJSON(ctx, group) {
+		return
+	}
+	if !updateGroup(ctx, team, group.DisplayName, memberValues(group.Members)) {
+		return
+	}
+	writeJSON(ctx, http.StatusOK, toSCIMGroup(team))
+}
+
+// PatchGroup renames the team or adds and removes its members
+func PatchGroup(ctx *context.Context) {
+	team := getTeam(ctx)
+	req := &patchRequest{}
+	if !readJSON(ctx, req) {
+		return
+	}
+	name := team.Name
+	members := memberValues(toSCIMGroup(team).Members)
+	if err := applyGroupPatch(&name, members, req.Operations); err != nil {
+		scimError(ctx, http.StatusBadRequest, errorInvalidValue, err)
+		return
+	}
+	if !updateGroup(ctx, team, name, members) {
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// DeleteGroup deletes the team, the owners team can't be deleted
+func DeleteGroup(ctx *context.Context) {
+	team := getTeam(ctx)
+	if team.IsOwnerTeam() {
+		scimError(ctx, http.StatusBadRequest, errorMutability, "the owners team can't be deleted")
+		return
+	}
+	if err := models.DeleteTeam(team); err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
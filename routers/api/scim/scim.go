@@ -0,0 +1,188 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+)
+
+const (
+	contentType = "application/scim+json"
+
+	schemaUser                  = "urn:ietf:params:scim:schemas:core:2.0:User"
+	schemaGroup                 = "urn:ietf:params:scim:schemas:core:2.0:Group"
+	schemaServiceProviderConfig = "urn:ietf:params:scim:schemas:core:2.0:ServiceProviderConfig"
+	schemaListResponse          = "urn:ietf:params:scim:api:messages:2.0:ListResponse"
+	schemaError                 = "urn:ietf:params:scim:api:messages:2.0:Error"
+
+	// the scimType of the errors, see RFC 7644 section 3.12
+	errorInvalidFilter = "invalidFilter"
+	errorInvalidSyntax = "invalidSyntax"
+	errorInvalidValue  = "invalidValue"
+	errorUniqueness    = "uniqueness"
+	errorMutability    = "mutability"
+)
+
+// Routes returns the SCIM 2.0 endpoints identity providers provision the users and the teams with
+func Routes() *web.Route {
+	r := web.NewRoute()
+
+	r.Use(context.PackageContexter())
+
+	r.Group("", func() {
+		r.Get("/ServiceProviderConfig", ServiceProviderConfig)
+		r.Group("/Users", func() {
+			r.Get("", ListUsers)
+			r.Post("", CreateUser)
+			r.Group("/{id}", func() {
+				r.Get("", GetUser)
+				r.Put("", ReplaceUser)
+				r.Patch("", PatchUser)
+				r.Delete("", DeleteUser)
+			}, reqUser)
+		})
+		r.Group("/Groups", func() {
+			r.Get("", ListGroups)
+			r.Post("", CreateGroup)
+			r.Group("/{id}", func() {
+				r.Get("", GetGroup)
+				r.Put("", ReplaceGroup)
+				r.Patch("", PatchGroup)
+				r.Delete("", DeleteGroup)
+			}, reqGroup)
+		}, reqOrganization)
+	}, reqToken)
+
+	return r
+}
+
+// reqToken authenticates the identity provider by the bearer token of the configuration
+func reqToken(ctx *context.Context) {
+	auth := ctx.Req.Header.Get("Authorization")
+	if len(auth) <= 7 || !strings.EqualFold(auth[:7], "Bearer ") ||
+		subtle.ConstantTimeCompare([]byte(auth[7:]), []byte(setting.SCIM.Token)) != 1 {
+		ctx.Resp.Header().Set("WWW-Authenticate", `Bearer realm="SCIM"`)
+		scimError(ctx, http.StatusUnauthorized, "", "invalid bearer token")
+	}
+}
+
+// scimError writes an error response in the format of RFC 7644 section 3.12
+func scimError(ctx *context.Context, status int, scimType string, obj interface{}) {
+	var message string
+	if err, ok := obj.(error); ok {
+		message = err.Error()
+	} else if obj != nil {
+		message = fmt.Sprintf("%s", obj)
+	}
+	if status == http.StatusInternalServerError {
+		log.ErrorWithSkip(1, message)
+
+		if setting.IsProd {
+			message = ""
+		}
+	} else {
+		log.Debug(message)
+	}
+
+	resp := map[string]interface{}{
+		"schemas": []string{schemaError},
+		"status":  strconv.Itoa(status),
+		"detail":  message,
+	}
+	if scimType != "" {
+		resp["scimType"] = scimType
+	}
+	writeJSON(ctx, status, resp)
+}
+
+func writeJSON(ctx *context.Context, status int, obj interface{}) {
+	ctx.Resp.Header().Set("Content-Type", contentType+";charset=utf-8")
+	ctx.Resp.WriteHeader(status)
+	if err := json.NewEncoder(ctx.Resp).Encode(obj); err != nil {
+		log.Error("Unable to encode the SCIM response: %v", err)
+	}
+}
+
+// readJSON decodes the body of the request, it responds with an error if the body is invalid
+func readJSON(ctx *context.Context, obj interface{}) bool {
+	if err := json.NewDecoder(ctx.Req.Body).Decode(obj); err != nil {
+		scimError(ctx, http.StatusBadRequest, errorInvalidSyntax, err)
+		return false
+	}
+	return true
+}
+
+// resourceLocation returns the URL of the resource of the type with the id
+func resourceLocation(resourceType string, id int64) string {
+	return fmt.Sprintf("%sapi/scim/v2/%s/%d", setting.AppURL, resourceType, id)
+}
+
+// listResponse is the response of the list endpoints, see RFC 7644 section 3.4.2
+type listResponse struct {
+	Schemas      []string      `json:"schemas"`
+	TotalResults int64         `json:"totalResults"`
+	StartIndex   int           `json:"startIndex"`
+	ItemsPerPage int           `json:"itemsPerPage"`
+	Resources    []interface{} `json:"Resources"`
+}
+
+// listOptions returns the 1-based start index and the count of the resources to list
+func listOptions(ctx *context.Context) (startIndex, count int) {
+	startIndex = ctx.FormInt("startIndex")
+	if startIndex < 1 {
+		startIndex = 1
+	}
+	count = setting.API.MaxResponseItems
+	if ctx.FormString("count") != "" {
+		if count = ctx.FormInt("count"); count < 0 {
+			count = 0
+		} else if count > setting.API.MaxResponseItems {
+			count = setting.API.MaxResponseItems
+		}
+	}
+	return startIndex, count
+}
+
+// ServiceProviderConfig describes the SCIM features which are supported
+func ServiceProviderConfig(ctx *context.Context) {
+	writeJSON(ctx, http.StatusOK, map[string]interface{}{
+		"schemas": []string{schemaServiceProviderConfig},
+		"patch":   map[string]bool{"supported": true},
+		"bulk": map[string]interface{}{
+			"supported":      false,
+			"maxOperations":  0,
+			"maxPayloadSize": 0,
+		},
+		"filter": map[string]interface{}{
+			"supported":  true,
+			"maxResults": setting.API.MaxResponseItems,
+		},
+		"changePassword": map[string]bool{"supported": false},
+		"sort":           map[string]bool{"supported": false},
+		"etag":           map[string]bool{"supported": false},
+		"authenticationSchemes": []map[string]interface{}{
+			{
+				"type":        "oauthbearertoken",
+				"name":        "OAuth Bearer Token",
+				"description": "Authentication with the token of the SCIM configuration",
+				"primary":     true,
+			},
+		},
+		"meta": map[string]string{
+			"resourceType": "ServiceProviderConfig",
+			"location":     setting.AppURL + "api/scim/v2/ServiceProviderConfig",
+		},
+	})
+}
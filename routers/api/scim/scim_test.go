@@ -0,0 +1,126 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseFilter(t *testing.T) {
+	attribute, value, err := parseFilter(`userName eq "alice@example.com"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "username", attribute)
+	assert.Equal(t, "alice@example.com", value)
+
+	attribute, value, err = parseFilter(`displayName EQ "Dev Ops"`)
+	assert.NoError(t, err)
+	assert.Equal(t, "displayname", attribute)
+	assert.Equal(t, "Dev Ops", value)
+
+	_, _, err = parseFilter(`userName sw "alice"`)
+	assert.Error(t, err)
+	_, _, err = parseFilter(`userName`)
+	assert.Error(t, err)
+	_, _, err = parseFilter(`userName eq "alice`)
+	assert.Error(t, err)
+}
+
+func patchOperations(t *testing.T, body string) []*patchOperation {
+	req := &patchRequest{}
+	assert.NoError(t, json.Unmarshal([]byte(body), req))
+	return req.Operations
+}
+
+func TestUserApplyPatch(t *testing.T) {
+	active := true
+	su := &scimUser{
+		UserName:    "alice@example.com",
+		DisplayName: "Alice",
+		Emails:      []scimEmail{{Value: "alice@example.com", Primary: true}},
+		Active:      &active,
+	}
+
+	// Azure AD sends booleans as strings and filters the emails
+	assert.NoError(t, su.applyPatch(patchOperations(t, `{"Operations": [
+		{"op": "Replace", "path": "active", "value": "False"},
+		{"op": "Replace", "path": "emails[type eq \"work\"].value", "value": "alice@corp.example.com"},
+		{"op": "Add", "path": "name.givenName", "value": "Alice"},
+		{"op": "Add", "path": "urn:ietf:params:scim:schemas:extension:enterprise:2.0:User:department", "value": "R&D"}
+	]}`)))
+	assert.False(t, su.isActive())
+	assert.Equal(t, "alice@corp.example.com", su.primaryEmail())
+	assert.Equal(t, "Alice", su.Name.GivenName)
+
+	// Okta sends the attributes as value without a path
+	assert.NoError(t, su.applyPatch(patchOperations(t, `{"Operations": [
+		{"op": "replace", "value": {"active": true, "displayName": "Alice Liddell", "externalId": "00u1"}}
+	]}`)))
+	assert.True(t, su.isActive())
+	assert.Equal(t, "Alice Liddell", su.DisplayName)
+	assert.Equal(t, "00u1", su.loginName())
+
+	assert.NoError(t, su.applyPatch(patchOperations(t, `{"Operations": [{"op": "remove", "path": "externalId"}]}`)))
+	assert.Equal(t, "alice@example.com", su.loginName())
+
+	assert.Error(t, su.applyPatch(patchOperations(t, `{"Operations": [{"op": "remove", "path": "active"}]}`)))
+	assert.Error(t, su.applyPatch(patchOperations(t, `{"Operations": [{"op": "move", "path": "active"}]}`)))
+	assert.Error(t, su.applyPatch(patchOperations(t, `{"Operations": [{"op": "replace", "path": "active", "value": "maybe"}]}`)))
+}
+
+func TestUserDerivedAttributes(t *testing.T) {
+	defer func(username, fullName string) {
+		setting.SCIM.UsernameAttribute, setting.SCIM.FullNameAttribute = username, fullName
+	}(setting.SCIM.UsernameAttribute, setting.SCIM.FullNameAttribute)
+
+	su := &scimUser{
+		UserName:    "alice@example.com",
+		ExternalID:  "00u1",
+		Name:        &scimName{Formatted: "Ms. Alice Liddell", GivenName: "Alice", FamilyName: "Liddell"},
+		DisplayName: "Alice L.",
+		Emails:      []scimEmail{{Value: "other@example.com"}, {Value: "liddell@example.com", Primary: true}},
+	}
+
+	setting.SCIM.UsernameAttribute, setting.SCIM.FullNameAttribute = "userName", "displayName"
+	assert.Equal(t, "alice", su.username())
+	assert.Equal(t, "Alice L.", su.fullName())
+
+	setting.SCIM.UsernameAttribute, setting.SCIM.FullNameAttribute = "email", "name"
+	assert.Equal(t, "liddell", su.username())
+	assert.Equal(t, "Alice Liddell", su.fullName())
+
+	setting.SCIM.UsernameAttribute, setting.SCIM.FullNameAttribute = "externalId", "name.formatted"
+	assert.Equal(t, "00u1", su.username())
+	assert.Equal(t, "Ms. Alice Liddell", su.fullName())
+}
+
+func TestApplyGroupPatch(t *testing.T) {
+	name := "developers"
+	members := map[string]bool{"1": true, "2": true}
+
+	assert.NoError(t, applyGroupPatch(&name, members, patchOperations(t, `{"Operations": [
+		{"op": "add", "path": "members", "value": [{"value": "3"}, {"value": "4"}]},
+		{"op": "remove", "path": "members[value eq \"1\"]"},
+		{"op": "remove", "path": "members", "value": [{"value": "2"}]},
+		{"op": "replace", "value": {"displayName": "maintainers"}}
+	]}`)))
+	assert.Equal(t, "maintainers", name)
+	assert.Equal(t, map[string]bool{"3": true, "4": true}, members)
+
+	assert.NoError(t, applyGroupPatch(&name, members, patchOperations(t, `{"Operations": [
+		{"op": "replace", "path": "members", "value": [{"value": "5"}]}
+	]}`)))
+	assert.Equal(t, map[string]bool{"5": true}, members)
+
+	assert.NoError(t, applyGroupPatch(&name, members, patchOperations(t, `{"Operations": [{"op": "remove", "path": "members"}]}`)))
+	assert.Empty(t, members)
+
+	assert.Error(t, applyGroupPatch(&name, members, patchOperations(t, `{"Operations": [{"op": "remove", "path": "members[display eq \"alice\"]"}]}`)))
+	assert.Error(t, applyGroupPatch(&name, members, patchOperations(t, `{"Operations": [{"op": "remove", "path": "displayName"}]}`)))
+}
@@ -0,0 +1,522 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package scim
+
+import (
+	gocontext "context"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/util"
+	audit_service "code.gitea.io/gitea/services/audit"
+	user_service "code.gitea.io/gitea/services/user"
+)
+
+type scimName struct {
+	Formatted  string `json:"formatted,omitempty"`
+	GivenName  string `json:"givenName,omitempty"`
+	FamilyName string `json:"familyName,omitempty"`
+}
+
+type scimEmail struct {
+	Value   string `json:"value"`
+	Type    string `json:"type,omitempty"`
+	Primary bool   `json:"primary,omitempty"`
+}
+
+type scimMeta struct {
+	ResourceType string     `json:"resourceType"`
+	Created      *time.Time `json:"created,omitempty"`
+	LastModified *time.Time `json:"lastModified,omitempty"`
+	Location     string     `json:"location"`
+}
+
+// scimUser is the User resource of RFC 7643 section 4.1, the attributes which aren't stored are ignored
+type scimUser struct {
+	Schemas     []string    `json:"schemas"`
+	ID          string      `json:"id,omitempty"`
+	ExternalID  string      `json:"externalId,omitempty"`
+	UserName    string      `json:"userName"`
+	Name        *scimName   `json:"name,omitempty"`
+	DisplayName string      `json:"displayName,omitempty"`
+	Emails      []scimEmail `json:"emails,omitempty"`
+	Active      *bool       `json:"active,omitempty"`
+	Meta        *scimMeta   `json:"meta,omitempty"`
+}
+
+func toSCIMUser(u *user_model.User, identity *user_model.SCIMIdentity) *scimUser {
+	active := !u.ProhibitLogin
+	created, updated := u.CreatedUnix.AsTime(), u.UpdatedUnix.AsTime()
+	su := &scimUser{
+		Schemas:     []string{schemaUser},
+		ID:          strconv.FormatInt(u.ID, 10),
+		UserName:    u.Name,
+		DisplayName: u.FullName,
+		Emails:      []scimEmail{{Value: u.Email, Type: "work", Primary: true}},
+		Active:      &active,
+		Meta: &scimMeta{
+			ResourceType: "User",
+			Created:      &created,
+			LastModified: &updated,
+			Location:     resourceLocation("Users", u.ID),
+		},
+	}
+	if u.FullName != "" {
+		su.Name = &scimName{Formatted: u.FullName}
+	}
+	if identity != nil {
+		su.UserName = identity.UserName
+		su.ExternalID = identity.ExternalID
+	}
+	return su
+}
+
+func (su *scimUser) isActive() bool {
+	return su.Active == nil || *su.Active
+}
+
+// primaryEmail returns the primary email address of the user or its first one if none is primary
+func (su *scimUser) primaryEmail() string {
+	for _, email := range su.Emails {
+		if email.Primary {
+			return email.Value
+		}
+	}
+	if len(su.Emails) > 0 {
+		return su.Emails[0].Value
+	}
+	return ""
+}
+
+// usernameOf returns the username of a value, an email address uses its local part
+func usernameOf(value string) string {
+	if i := strings.IndexByte(value, '@'); i >= 0 {
+		return value[:i]
+	}
+	return value
+}
+
+// username derives the username of a new user from the attribute of the configuration
+func (su *scimUser) username() string {
+	switch setting.SCIM.UsernameAttribute {
+	case "email":
+		return usernameOf(su.primaryEmail())
+	case "externalId":
+		return usernameOf(su.ExternalID)
+	}
+	return usernameOf(su.UserName)
+}
+
+// fullName returns the full name of the user from the attribute of the configuration
+func (su *scimUser) fullName() string {
+	switch setting.SCIM.FullNameAttribute {
+	case "name.formatted":
+		if su.Name != nil {
+			return su.Name.Formatted
+		}
+		return ""
+	case "name":
+		if su.Name != nil {
+			return strings.TrimSpace(su.Name.GivenName + " " + su.Name.FamilyName)
+		}
+		return ""
+	}
+	return su.DisplayName
+}
+
+// loginName returns the name the user signs in to the authentication source with
+func (su *scimUser) loginName() string {
+	if su.ExternalID != "" {
+		return su.ExternalID
+	}
+	return su.UserName
+}
+
+// applyPatch applies the operations of a PATCH request to the attributes of the user
+func (su *scimUser) applyPatch(ops []*patchOperation) error {
+	for _, op := range ops {
+		switch strings.ToLower(op.Op) {
+		case "add", "replace":
+			attributes, err := op.attributes()
+			if err != nil {
+				return err
+			}
+			for path, value := range attributes {
+				if err := su.setAttribute(path, value); err != nil {
+					return fmt.Errorf("invalid value of %s: %v", path, err)
+				}
+			}
+		case "remove":
+			if op.Path == "" {
+				return fmt.Errorf("a remove operation requires a path")
+			}
+			if err := su.setAttribute(op.Path, nil); err != nil {
+				return fmt.Errorf("unable to remove %s: %v", op.Path, err)
+			}
+		default:
+			return fmt.Errorf("unsupported operation %q", op.Op)
+		}
+	}
+	return nil
+}
+
+// setAttribute sets the attribute of the path to the value of a PATCH operation, a nil value removes it.
+// The attributes which aren't stored, like the ones of the enterprise extension, are ignored.
+func (su *scimUser) setAttribute(path string, value json.RawMessage) (err error) {
+	path = strings.TrimPrefix(strings.ToLower(path), strings.ToLower(schemaUser)+":")
+	switch {
+	case path == "active":
+		if value == nil {
+			return fmt.Errorf("active is required")
+		}
+		var active bool
+		active, err = parseBool(value)
+		su.Active = &active
+	case path == "username":
+		su.UserName, err = parseString(value)
+	case path == "externalid":
+		su.ExternalID, err = parseString(value)
+	case path == "displayname":
+		su.DisplayName, err = parseString(value)
+	case path == "name":
+		su.Name = nil
+		if value != nil {
+			su.Name = &scimName{}
+			err = json.Unmarshal(value, su.Name)
+		}
+	case strings.HasPrefix(path, "name."):
+		if su.Name == nil {
+			su.Name = &scimName{}
+		}
+		switch path[len("name."):] {
+		case "formatted":
+			su.Name.Formatted, err = parseString(value)
+		case "givenname":
+			su.Name.GivenName, err = parseString(value)
+		case "familyname":
+			su.Name.FamilyName, err = parseString(value)
+		}
+	case path == "emails":
+		su.Emails = nil
+		if value != nil {
+			err = json.Unmarshal(value, &su.Emails)
+		}
+	case strings.HasPrefix(path, "emails") && strings.HasSuffix(path, ".value"):
+		// a user has only one email address, so a filter like emails[type eq "work"] always selects it
+		if value == nil {
+			return nil
+		}
+		var email string
+		if email, err = parseString(value); err != nil {
+			return err
+		}
+		if len(su.Emails) == 0 {
+			su.Emails = []scimEmail{{Primary: true}}
+		}
+		for i := range su.Emails {
+			if su.Emails[i].Primary || i == len(su.Emails)-1 {
+				su.Emails[i].Value = email
+				break
+			}
+		}
+	}
+	return err
+}
+
+// reqUser loads the user of the request
+func reqUser(ctx *context.Context) {
+	u, err := user_model.GetUserByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			scimError(ctx, http.StatusNotFound, "", err)
+		} else {
+			scimError(ctx, http.StatusInternalServerError, "", err)
+		}
+		return
+	}
+	if u.IsOrganization() {
+		scimError(ctx, http.StatusNotFound, "", user_model.ErrUserNotExist{UID: u.ID})
+		return
+	}
+	identities, err := user_model.GetSCIMIdentities(ctx, []int64{u.ID})
+	if err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+	ctx.Data["SCIMUser"] = u
+	ctx.Data["SCIMIdentity"] = identities[u.ID]
+}
+
+func getUser(ctx *context.Context) (*user_model.User, *user_model.SCIMIdentity) {
+	identity, _ := ctx.Data["SCIMIdentity"].(*user_model.SCIMIdentity)
+	return ctx.Data["SCIMUser"].(*user_model.User), identity
+}
+
+// loginSource returns the authentication source of the configuration the provisioned users sign in with,
+// it is nil if they are local users.
+func loginSource() (*auth_model.Source, error) {
+	if setting.SCIM.AuthSource == "" {
+		return nil, nil
+	}
+	sources, err := auth_model.Sources()
+	if err != nil {
+		return nil, err
+	}
+	for _, source := range sources {
+		if source.Name == setting.SCIM.AuthSource {
+			return source, nil
+		}
+	}
+	return nil, fmt.Errorf("authentication source %q of SCIM does not exist", setting.SCIM.AuthSource)
+}
+
+// ListUsers lists the users, filtered by their userName or externalId
+func ListUsers(ctx *context.Context) {
+	startIndex, count := listOptions(ctx)
+	opts := &user_model.SearchSCIMUsersOptions{
+		Offset: startIndex - 1,
+		Limit:  count,
+	}
+	if filter := ctx.FormString("filter"); filter != "" {
+		attribute, value, err := parseFilter(filter)
+		if err != nil {
+			scimError(ctx, http.StatusBadRequest, errorInvalidFilter, err)
+			return
+		}
+		switch attribute {
+		case "username":
+			// users which haven't been provisioned yet are matched by their username
+			opts.UserName = value
+			opts.Name = usernameOf(value)
+		case "externalid":
+			opts.ExternalID = value
+		default:
+			scimError(ctx, http.StatusBadRequest, errorInvalidFilter, fmt.Sprintf("unsupported filter attribute %q", attribute))
+			return
+		}
+	}
+
+	users, total, err := user_model.SearchSCIMUsers(ctx, opts)
+	if err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+	userIDs := make([]int64, 0, len(users))
+	for _, u := range users {
+		userIDs = append(userIDs, u.ID)
+	}
+	identities, err := user_model.GetSCIMIdentities(ctx, userIDs)
+	if err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	resources := make([]interface{}, 0, len(users))
+	for _, u := range users {
+		resources = append(resources, toSCIMUser(u, identities[u.ID]))
+	}
+	writeJSON(ctx, http.StatusOK, &listResponse{
+		Schemas:      []string{schemaListResponse},
+		TotalResults: total,
+		StartIndex:   startIndex,
+		ItemsPerPage: len(resources),
+		Resources:    resources,
+	})
+}
+
+// GetUser returns the user
+func GetUser(ctx *context.Context) {
+	writeJSON(ctx, http.StatusOK, toSCIMUser(getUser(ctx)))
+}
+
+// CreateUser provisions a new user
+func CreateUser(ctx *context.Context) {
+	su := &scimUser{}
+	if !readJSON(ctx, su) {
+		return
+	}
+	if su.UserName == "" {
+		scimError(ctx, http.StatusBadRequest, errorInvalidValue, "userName is required")
+		return
+	}
+	if _, count, err := user_model.SearchSCIMUsers(ctx, &user_model.SearchSCIMUsersOptions{UserName: su.UserName}); err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return
+	} else if count > 0 {
+		scimError(ctx, http.StatusConflict, errorUniqueness, fmt.Sprintf("user %q has already been provisioned", su.UserName))
+		return
+	}
+	source, err := loginSource()
+	if err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+
+	u := &user_model.User{
+		Name:          su.username(),
+		FullName:      su.fullName(),
+		Email:         su.primaryEmail(),
+		LoginType:     auth_model.Plain,
+		ProhibitLogin: !su.isActive(),
+	}
+	if source != nil {
+		u.LoginType = source.Type
+		u.LoginSource = source.ID
+		u.LoginName = su.loginName()
+	}
+	if err := user_model.CreateUser(u, &user_model.CreateUserOverwriteOptions{IsActive: util.OptionalBoolTrue}); err != nil {
+		switch {
+		case user_model.IsErrUserAlreadyExist(err),
+			user_model.IsErrEmailAlreadyUsed(err):
+			scimError(ctx, http.StatusConflict, errorUniqueness, err)
+		case db.IsErrNameReserved(err),
+			db.IsErrNamePatternNotAllowed(err),
+			db.IsErrNameCharsNotAllowed(err),
+			user_model.IsErrEmailCharIsNotSupported(err),
+			user_model.IsErrEmailInvalid(err):
+			scimError(ctx, http.StatusBadRequest, errorInvalidValue, err)
+		default:
+			scimError(ctx, http.StatusInternalServerError, "", err)
+		}
+		return
+	}
+	identity := &user_model.SCIMIdentity{UserID: u.ID, UserName: su.UserName, ExternalID: su.ExternalID}
+	if err := user_model.SetSCIMIdentity(ctx, identity); err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return
+	}
+	audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionUserCreate, u.Name, "scim")
+
+	writeJSON(ctx, http.StatusCreated, toSCIMUser(u, identity))
+}
+
+// updateUser replaces the attributes of the user by the ones of the provisioned user,
+// the username of the user is never changed.
+func updateUser(ctx *context.Context, u *user_model.User, su *scimUser) *user_model.SCIMIdentity {
+	if su.UserName == "" {
+		scimError(ctx, http.StatusBadRequest, errorInvalidValue, "userName is required")
+		return nil
+	}
+	email := strings.TrimSpace(su.primaryEmail())
+	if err := user_model.ValidateEmail(email); err != nil {
+		scimError(ctx, http.StatusBadRequest, errorInvalidValue, err)
+		return nil
+	}
+	emailChanged := !strings.EqualFold(u.Email, email)
+	if emailChanged {
+		if used, err := isEmailUsedByOthers(ctx, u, email); err != nil {
+			scimError(ctx, http.StatusInternalServerError, "", err)
+			return nil
+		} else if used {
+			scimError(ctx, http.StatusConflict, errorUniqueness, user_model.ErrEmailAlreadyUsed{Email: email})
+			return nil
+		}
+	}
+	source, err := loginSource()
+	if err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return nil
+	}
+
+	u.Email = email
+	// the full name isn't always derivable from the attributes, e.g. from a PATCH request of other attributes
+	if fullName := su.fullName(); fullName != "" {
+		u.FullName = fullName
+	}
+	u.ProhibitLogin = !su.isActive()
+	if source != nil && u.LoginSource == source.ID {
+		u.LoginName = su.loginName()
+	}
+	identity := &user_model.SCIMIdentity{UserID: u.ID, UserName: su.UserName, ExternalID: su.ExternalID}
+	if err := db.WithTx(func(ctx gocontext.Context) error {
+		if err := user_model.UpdateUser(ctx, u, emailChanged, "email", "full_name", "prohibit_login", "login_name"); err != nil {
+			return err
+		}
+		return user_model.SetSCIMIdentity(ctx, identity)
+	}, ctx); err != nil {
+		scimError(ctx, http.StatusInternalServerError, "", err)
+		return nil
+	}
+	audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionUserUpdate, u.Name, "scim")
+	return identity
+}
+
+// isEmailUsedByOthers returns true if the email address is used by another user than u
+func isEmailUsedByOthers(ctx *context.Context, u *user_model.User, email string) (bool, error) {
+	used, err := user_model.IsEmailUsed(ctx, email)
+	if err != nil || !used {
+		return false, err
+	}
+	emails, err := user_model.GetEmailAddresses(u.ID)
+	if err != nil {
+		return false, err
+	}
+	for _, e := range emails {
+		if strings.EqualFold(e.Email, email) {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// ReplaceUser replaces the attributes of the user
+func ReplaceUser(ctx *context.Context) {
+	u, _ := getUser(ctx)
+	su := &scimUser{}
+	if !readJSON(ctx, su) {
+		return
+	}
+	identity := updateUser(ctx, u, su)
+	if identity == nil {
+		return
+	}
+	writeJSON(ctx, http.StatusOK, toSCIMUser(u, identity))
+}
+
+// PatchUser updates some attributes of the user, identity providers deactivate users with it
+func PatchUser(ctx *context.Context) {
+	u, identity := getUser(ctx)
+	req := &patchRequest{}
+	if !readJSON(ctx, req) {
+		return
+	}
+	su := toSCIMUser(u, identity)
+	if err := su.applyPatch(req.Operations); err != nil {
+		scimError(ctx, http.StatusBadRequest, errorInvalidValue, err)
+		return
+	}
+	if identity = updateUser(ctx, u, su); identity == nil {
+		return
+	}
+	writeJSON(ctx, http.StatusOK, toSCIMUser(u, identity))
+}
+
+// DeleteUser deletes the user, it fails for users which still own repositories or belong to organizations
+func DeleteUser(ctx *context.Context) {
+	u, _ := getUser(ctx)
+	if err := user_service.DeleteUser(u); err != nil {
+		if models.IsErrUserOwnRepos(err) ||
+			models.IsErrUserHasOrgs(err) ||
+			models.IsErrUserOwnPackages(err) {
+			scimError(ctx, http.StatusConflict, "", err)
+		} else {
+			scimError(ctx, http.StatusInternalServerError, "", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionUserDelete, u.Name, "scim")
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListRunners list the global runners
+func ListRunners(ctx *context.APIContext) {
+	// swagger:operation GET /admin/actions/runners admin adminListRunners
+	// ---
+	// summary: List the runners running the jobs of all repositories
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunnerList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.ListRunners(ctx, 0, 0)
+}
+
+// GetRunnerRegistrationToken get the registration token of global runners
+func GetRunnerRegistrationToken(ctx *context.APIContext) {
+	// swagger:operation GET /admin/actions/runners/registration_token admin adminGetRunnerRegistrationToken
+	// ---
+	// summary: Get the token to register runners for all repositories, it is created if there is none
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunnerRegistrationToken"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.GetRunnerRegistrationToken(ctx, 0, 0)
+}
+
+// ResetRunnerRegistrationToken replace the registration token of global runners
+func ResetRunnerRegistrationToken(ctx *context.APIContext) {
+	// swagger:operation POST /admin/actions/runners/registration_token admin adminResetRunnerRegistrationToken
+	// ---
+	// summary: Replace the token to register runners for all repositories
+	// produces:
+	// - application/json
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ActionRunnerRegistrationToken"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.ResetRunnerRegistrationToken(ctx, 0, 0)
+}
+
+// DeleteRunner delete a global runner
+func DeleteRunner(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/actions/runners/{id} admin adminDeleteRunner
+	// ---
+	// summary: Delete a runner running the jobs of all repositories, the jobs it is running are failed
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the runner
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteRunner(ctx, 0, 0)
+}
@@ -0,0 +1,243 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"fmt"
+	"net/http"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+// ListAnnouncements lists all announcements of the instance
+func ListAnnouncements(ctx *context.APIContext) {
+	// swagger:operation GET /admin/announcements admin adminListAnnouncements
+	// ---
+	// summary: List all announcements of the instance, including the scheduled and ended ones
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AnnouncementList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	utils.ListAnnouncements(ctx, &admin_model.FindAnnouncementsOptions{})
+}
+
+// GetAnnouncement gets an announcement of the instance
+func GetAnnouncement(ctx *context.APIContext) {
+	// swagger:operation GET /admin/announcements/{id} admin adminGetAnnouncement
+	// ---
+	// summary: Get an announcement of the instance
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the announcement
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Announcement"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	announcement := getAnnouncementByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToAnnouncement(announcement))
+}
+
+// CreateAnnouncement publishes an announcement of the instance
+func CreateAnnouncement(ctx *context.APIContext) {
+	// swagger:operation POST /admin/announcements admin adminCreateAnnouncement
+	// ---
+	// summary: Publish an announcement of the instance
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateAnnouncementOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Announcement"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateAnnouncementOption)
+
+	announcement := &admin_model.Announcement{
+		Content:     form.Content,
+		Severity:    admin_model.AnnouncementSeverity(form.Severity),
+		Dismissible: form.Dismissible,
+		StartUnix:   timeutil.TimeStampNow(),
+	}
+	if form.Start != nil {
+		announcement.StartUnix = timeutil.TimeStamp(form.Start.Unix())
+	}
+	if form.End != nil && !form.End.IsZero() {
+		announcement.EndUnix = timeutil.TimeStamp(form.End.Unix())
+	}
+
+	if err := admin_model.CreateAnnouncement(ctx, announcement); err != nil {
+		if admin_model.IsErrInvalidAnnouncement(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateAnnouncement", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionAnnouncementCreate, fmt.Sprintf("#%d", announcement.ID), string(announcement.Severity))
+
+	ctx.JSON(http.StatusCreated, convert.ToAnnouncement(announcement))
+}
+
+// EditAnnouncement updates an announcement of the instance
+func EditAnnouncement(ctx *context.APIContext) {
+	// swagger:operation PATCH /admin/announcements/{id} admin adminEditAnnouncement
+	// ---
+	// summary: Edit an announcement of the instance
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the announcement
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditAnnouncementOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Announcement"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditAnnouncementOption)
+
+	announcement := getAnnouncementByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if form.Content != nil {
+		announcement.Content = *form.Content
+	}
+	if form.Severity != nil {
+		announcement.Severity = admin_model.AnnouncementSeverity(*form.Severity)
+	}
+	if form.Dismissible != nil {
+		announcement.Dismissible = *form.Dismissible
+	}
+	if form.Start != nil {
+		announcement.StartUnix = timeutil.TimeStamp(form.Start.Unix())
+	}
+	if form.End != nil {
+		if form.End.IsZero() {
+			announcement.EndUnix = 0
+		} else {
+			announcement.EndUnix = timeutil.TimeStamp(form.End.Unix())
+		}
+	}
+
+	if err := admin_model.UpdateAnnouncement(ctx, announcement); err != nil {
+		if admin_model.IsErrInvalidAnnouncement(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateAnnouncement", err)
+		}
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionAnnouncementUpdate, fmt.Sprintf("#%d", announcement.ID), string(announcement.Severity))
+
+	ctx.JSON(http.StatusOK, convert.ToAnnouncement(announcement))
+}
+
+// DeleteAnnouncement deletes an announcement of the instance
+func DeleteAnnouncement(ctx *context.APIContext) {
+	// swagger:operation DELETE /admin/announcements/{id} admin adminDeleteAnnouncement
+	// ---
+	// summary: Delete an announcement of the instance
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the announcement
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	announcement := getAnnouncementByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := admin_model.DeleteAnnouncement(ctx, announcement.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteAnnouncement", err)
+		return
+	}
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionAnnouncementDelete, fmt.Sprintf("#%d", announcement.ID), "")
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getAnnouncementByParams(ctx *context.APIContext) *admin_model.Announcement {
+	announcement, err := admin_model.GetAnnouncementByID(ctx, ctx.ParamsInt64(":id"))
+	if err == nil && announcement.OrgID != 0 {
+		err = admin_model.ErrAnnouncementNotExist{ID: announcement.ID}
+	}
+	if err != nil {
+		if admin_model.IsErrAnnouncementNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetAnnouncementByID", err)
+		}
+		return nil
+	}
+	return announcement
+}
@@ -0,0 +1,65 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListAttachmentUsages lists the number and size of the attachments stored for each repository
+func ListAttachmentUsages(ctx *context.APIContext) {
+	// swagger:operation GET /admin/attachments/usage admin adminListAttachmentUsages
+	// ---
+	// summary: List the number and size of the attachments stored for each repository, the largest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AttachmentUsageList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	listOptions := utils.GetListOptions(ctx)
+
+	usages, count, err := repo_model.GetRepoAttachmentUsages(ctx, listOptions)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepoAttachmentUsages", err)
+		return
+	}
+
+	repoIDs := make([]int64, 0, len(usages))
+	for _, usage := range usages {
+		repoIDs = append(repoIDs, usage.RepoID)
+	}
+	repos, err := repo_model.GetRepositoriesMapByIDs(repoIDs)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepositoriesMapByIDs", err)
+		return
+	}
+
+	apiUsages := make([]*api.AttachmentUsage, len(usages))
+	for i, usage := range usages {
+		apiUsages[i] = convert.ToAttachmentUsage(usage, repos[usage.RepoID])
+	}
+
+	ctx.SetLinkHeader(int(count), listOptions.PageSize)
+	ctx.SetTotalCountHeader(count)
+	ctx.JSON(http.StatusOK, apiUsages)
+}
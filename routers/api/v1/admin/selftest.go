@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	admin_service "code.gitea.io/gitea/services/admin"
+)
+
+// RunSelfTest runs the self-tests of the instance
+func RunSelfTest(ctx *context.APIContext) {
+	// swagger:operation GET /admin/selftest admin adminRunSelfTest
+	// ---
+	// summary: Run the self-tests of the instance
+	// description: Writes to and reads from each storage, checks the git binary and the connection
+	//   to the mailer and makes a round trip through a queue.
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SelfTestReport"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	report := admin_service.RunSelfTest(ctx)
+
+	res := &api.SelfTestReport{
+		Status:  string(report.Status),
+		Started: report.Started,
+		Checks:  make([]*api.SelfTestCheck, 0, len(report.Checks)),
+	}
+	for _, check := range report.Checks {
+		res.Checks = append(res.Checks, &api.SelfTestCheck{
+			Name:     check.Name,
+			Status:   string(check.Status),
+			Output:   check.Output,
+			Details:  check.Details,
+			Duration: check.Duration.Milliseconds(),
+		})
+	}
+	ctx.JSON(http.StatusOK, res)
+}
@@ -6,12 +6,12 @@
 package admin
 
 import (
-	"errors"
 	"fmt"
 	"net/http"
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
@@ -20,13 +20,13 @@ import (
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/password"
-	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/mailer"
 	user_service "code.gitea.io/gitea/services/user"
 )
@@ -93,18 +93,8 @@ func CreateUser(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
-	if !password.IsComplexEnough(form.Password) {
-		err := errors.New("PasswordComplexity")
-		ctx.Error(http.StatusBadRequest, "PasswordComplexity", err)
-		return
-	}
-	pwned, err := password.IsPwned(ctx, form.Password)
-	if pwned {
-		if err != nil {
-			log.Error(err.Error())
-		}
-		ctx.Data["Err_Password"] = true
-		ctx.Error(http.StatusBadRequest, "PasswordPwned", errors.New("PasswordPwned"))
+	if err := password.Validate(ctx, form.Password); err != nil {
+		ctx.Error(http.StatusBadRequest, "PasswordPolicy", err)
 		return
 	}
 
@@ -136,6 +126,7 @@ func CreateUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserCreate, u.Name, "")
 
 	// Send email notification.
 	if form.SendNotify {
@@ -179,24 +170,11 @@ func EditUser(ctx *context.APIContext) {
 	}
 
 	if len(form.Password) != 0 {
-		if len(form.Password) < setting.MinPasswordLength {
-			ctx.Error(http.StatusBadRequest, "PasswordTooShort", fmt.Errorf("password must be at least %d characters", setting.MinPasswordLength))
-			return
-		}
-		if !password.IsComplexEnough(form.Password) {
-			err := errors.New("PasswordComplexity")
-			ctx.Error(http.StatusBadRequest, "PasswordComplexity", err)
-			return
-		}
-		pwned, err := password.IsPwned(ctx, form.Password)
-		if pwned {
-			if err != nil {
-				log.Error(err.Error())
-			}
-			ctx.Data["Err_Password"] = true
-			ctx.Error(http.StatusBadRequest, "PasswordPwned", errors.New("PasswordPwned"))
+		if err := password.Validate(ctx, form.Password); err != nil {
+			ctx.Error(http.StatusBadRequest, "PasswordPolicy", err)
 			return
 		}
+		var err error
 		if ctx.ContextUser.Salt, err = user_model.GetUserSalt(); err != nil {
 			ctx.Error(http.StatusInternalServerError, "UpdateUser", err)
 			return
@@ -247,6 +225,7 @@ func EditUser(ctx *context.APIContext) {
 	if len(form.Visibility) != 0 {
 		ctx.ContextUser.Visibility = api.VisibilityModes[form.Visibility]
 	}
+	wasAdmin := ctx.ContextUser.IsAdmin
 	if form.Admin != nil {
 		ctx.ContextUser.IsAdmin = *form.Admin
 	}
@@ -280,6 +259,14 @@ func EditUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserUpdate, ctx.ContextUser.Name, "")
+	if ctx.ContextUser.IsAdmin != wasAdmin {
+		action := admin_model.AuditActionSiteAdminRevoke
+		if ctx.ContextUser.IsAdmin {
+			action = admin_model.AuditActionSiteAdminGrant
+		}
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), action, ctx.ContextUser.Name, "")
+	}
 
 	ctx.JSON(http.StatusOK, convert.ToUser(ctx.ContextUser, ctx.Doer))
 }
@@ -327,6 +314,7 @@ func DeleteUser(ctx *context.APIContext) {
 		return
 	}
 	log.Trace("Account deleted by admin(%s): %s", ctx.Doer.Name, ctx.ContextUser.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserDelete, ctx.ContextUser.Name, "")
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -76,6 +76,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/activitypub"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
@@ -92,6 +93,7 @@ import (
 	"code.gitea.io/gitea/services/auth"
 	context_service "code.gitea.io/gitea/services/context"
 	"code.gitea.io/gitea/services/forms"
+	"code.gitea.io/gitea/services/maintenance"
 
 	_ "code.gitea.io/gitea/routers/api/v1/swagger" // for swagger generation
 
@@ -162,6 +164,11 @@ func repoAssignment() func(ctx *context.APIContext) {
 		ctx.Repo.Owner = owner
 		ctx.ContextUser = owner
 
+		if owner.IsSuspendedFor(ctx.Doer) {
+			ctx.Error(http.StatusForbidden, "repoAssignment", "the owner of the repository has been suspended")
+			return
+		}
+
 		// Get repository.
 		repo, err := repo_model.GetRepositoryByName(owner.ID, repoName)
 		if err != nil {
@@ -222,6 +229,17 @@ func reqToken() func(ctx *context.APIContext) {
 	}
 }
 
+// maintenanceMode rejects the requests changing anything while the instance is in maintenance mode
+func maintenanceMode() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if mode := maintenance.Get(ctx); mode != nil && !maintenance.IsAllowed(ctx.Req, ctx.Doer) {
+			ctx.JSON(http.StatusServiceUnavailable, map[string]string{
+				"message": maintenance.Message(mode),
+			})
+		}
+	}
+}
+
 func reqExploreSignIn() func(ctx *context.APIContext) {
 	return func(ctx *context.APIContext) {
 		if setting.Service.Explore.RequireSigninView && !ctx.IsSigned {
@@ -230,6 +248,14 @@ func reqExploreSignIn() func(ctx *context.APIContext) {
 	}
 }
 
+func reqFederationAllowed() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if !activitypub.IsRequestAllowed(ctx.Req) {
+			ctx.Error(http.StatusForbidden, "reqFederationAllowed", "this instance is not allowed to federate")
+		}
+	}
+}
+
 func reqBasicOrRevProxyAuth() func(ctx *context.APIContext) {
 	return func(ctx *context.APIContext) {
 		if ctx.IsSigned && setting.Service.EnableReverseProxyAuth && ctx.Data["AuthedMethod"].(string) == auth.ReverseProxyMethodName {
@@ -283,6 +309,16 @@ func reqRepoWriter(unitTypes ...unit.Type) func(ctx *context.APIContext) {
 	}
 }
 
+// reqRepoReleaseAction user should have a permission to write to releases which allows the action, or be a site admin
+func reqRepoReleaseAction(action perm.ReleaseAction) func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if !ctx.Repo.CanDoReleaseAction(action) && !ctx.IsUserRepoAdmin() && !ctx.IsUserSiteAdmin() {
+			ctx.Error(http.StatusForbidden, "reqRepoReleaseAction", "user is not allowed to do this action on releases")
+			return
+		}
+	}
+}
+
 // reqRepoBranchWriter user should have a permission to write to a branch, or be a site admin
 func reqRepoBranchWriter(ctx *context.APIContext) {
 	options, ok := web.GetForm(ctx).(api.FileOptionInterface)
@@ -431,6 +467,24 @@ func reqWebhooksEnabled() func(ctx *context.APIContext) {
 	}
 }
 
+func reqActionsEnabled() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if !setting.Actions.Enabled {
+			ctx.NotFound()
+			return
+		}
+	}
+}
+
+func reqDiscussionsEnabled() func(ctx *context.APIContext) {
+	return func(ctx *context.APIContext) {
+		if unit.TypeDiscussions.UnitGlobalDisabled() {
+			ctx.NotFound()
+			return
+		}
+	}
+}
+
 func orgAssignment(args ...bool) func(ctx *context.APIContext) {
 	var (
 		assignOrg  bool
@@ -632,6 +686,8 @@ func Routes() *web.Route {
 		SignInRequired: setting.Service.RequireSignInView,
 	}))
 
+	m.Use(maintenanceMode())
+
 	m.Group("", func() {
 		// Miscellaneous
 		if setting.API.EnableSwagger {
@@ -640,12 +696,14 @@ func Routes() *web.Route {
 			})
 		}
 		m.Get("/version", misc.Version)
-		if setting.Federation.Enabled {
-			m.Get("/nodeinfo", misc.NodeInfo)
+		if setting.Federation.Enabled && setting.Federation.NodeInfoEnabled {
+			m.Get("/nodeinfo", reqFederationAllowed(), misc.NodeInfo)
 		}
 		m.Get("/signing-key.gpg", misc.SigningKey)
+		m.Get("/announcements", misc.ListAnnouncements)
 		m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 		m.Post("/markdown/raw", misc.MarkdownRaw)
+		m.Post("/markdown/toc", bind(api.MarkdownOption{}), misc.MarkdownTableOfContents)
 		m.Group("/settings", func() {
 			m.Get("/ui", settings.GetGeneralUISettings)
 			m.Get("/api", settings.GetGeneralAPISettings)
@@ -673,6 +731,7 @@ func Routes() *web.Route {
 
 				if setting.Service.EnableUserHeatmap {
 					m.Get("/heatmap", user.GetUserHeatmapData)
+					m.Get("/contributions", user.GetUserContributions)
 				}
 
 				m.Get("/repos", reqExploreSignIn(), user.ListUserRepos)
@@ -737,6 +796,14 @@ func Routes() *web.Route {
 					Get(user.GetOauth2Application)
 			}, reqToken())
 
+			m.Group("/filters", func() {
+				m.Combo("").Get(user.ListIssueFilters).
+					Post(bind(api.CreateIssueFilterOption{}), user.CreateIssueFilter)
+				m.Combo("/{id}").Get(user.GetIssueFilter).
+					Patch(bind(api.EditIssueFilterOption{}), user.EditIssueFilter).
+					Delete(user.DeleteIssueFilter)
+			}, reqToken())
+
 			m.Group("/gpg_keys", func() {
 				m.Combo("").Get(user.ListMyGPGKeys).
 					Post(bind(api.CreateGPGKeyOption{}), user.CreateGPGKey)
@@ -784,9 +851,17 @@ func Routes() *web.Route {
 					Delete(reqToken(), reqOwner(), repo.Delete).
 					Patch(reqToken(), reqAdmin(), bind(api.EditRepoOption{}), repo.Edit)
 				m.Post("/generate", reqToken(), reqRepoReader(unit.TypeCode), bind(api.GenerateRepoOption{}), repo.Generate)
+				m.Combo("/parent", reqOwner()).
+					Put(bind(api.AttachForkOption{}), repo.AttachFork).
+					Delete(repo.DetachFork)
 				m.Post("/transfer", reqOwner(), bind(api.TransferRepoOption{}), repo.Transfer)
 				m.Post("/transfer/accept", reqToken(), repo.AcceptTransfer)
 				m.Post("/transfer/reject", reqToken(), repo.RejectTransfer)
+				m.Post("/transfer/cancel", reqOwner(), repo.CancelTransfer)
+				m.Group("/housekeeping", func() {
+					m.Post("", bind(api.RepoHousekeepingOptions{}), repo.Housekeep)
+					m.Get("/{id}", repo.GetHousekeepingJob)
+				}, reqToken(), reqOwner())
 				m.Combo("/notifications").
 					Get(reqToken(), notify.ListRepoNotifications).
 					Put(reqToken(), notify.ReadRepoNotifications)
@@ -809,7 +884,8 @@ func Routes() *web.Route {
 					})
 				}, reqToken(), reqAdmin(), reqWebhooksEnabled())
 				m.Group("/collaborators", func() {
-					m.Get("", reqAnyRepoReader(), repo.ListCollaborators)
+					m.Combo("").Get(reqAnyRepoReader(), repo.ListCollaborators).
+						Put(reqAdmin(), bind(api.SetCollaboratorsOption{}), repo.SetCollaborators)
 					m.Group("/{collaborator}", func() {
 						m.Combo("").Get(reqAnyRepoReader(), repo.IsCollaborator).
 							Put(reqAdmin(), bind(api.AddCollaboratorOption{}), repo.AddCollaborator).
@@ -817,12 +893,20 @@ func Routes() *web.Route {
 						m.Get("/permission", repo.GetRepoPermissions)
 					}, reqToken())
 				}, reqToken())
+				m.Get("/temporary_access", reqToken(), reqAnyRepoReader(), repo.ListTemporaryAccess)
+				m.Get("/audit", reqToken(), reqAdmin(), repo.ListAuditLog)
+				m.Group("/traffic", func() {
+					m.Get("/clones", repo.GetCloneTraffic)
+					m.Get("/fetches", repo.GetFetchTraffic)
+					m.Get("/archives", repo.GetArchiveTraffic)
+					m.Get("/raw", repo.GetRawTraffic)
+				}, reqToken(), reqRepoWriter(unit.TypeCode))
 				m.Get("/assignees", reqToken(), reqAnyRepoReader(), repo.GetAssignees)
 				m.Get("/reviewers", reqToken(), reqAnyRepoReader(), repo.GetReviewers)
 				m.Group("/teams", func() {
 					m.Get("", reqAnyRepoReader(), repo.ListTeams)
 					m.Combo("/{team}").Get(reqAnyRepoReader(), repo.IsTeam).
-						Put(reqAdmin(), repo.AddTeam).
+						Put(reqAdmin(), bind(api.AddRepoTeamOption{}), repo.AddTeam).
 						Delete(reqAdmin(), repo.DeleteTeam)
 				}, reqToken())
 				m.Get("/raw/*", context.ReferencesGitRepo(), context.RepoRefForAPI, reqRepoReader(unit.TypeCode), repo.GetRawFile)
@@ -835,6 +919,10 @@ func Routes() *web.Route {
 					m.Delete("/*", reqRepoWriter(unit.TypeCode), repo.DeleteBranch)
 					m.Post("", reqRepoWriter(unit.TypeCode), bind(api.CreateBranchRepoOption{}), repo.CreateBranch)
 				}, context.ReferencesGitRepo(), reqRepoReader(unit.TypeCode))
+				m.Group("/deleted_branches", func() {
+					m.Get("", repo.ListDeletedBranches)
+					m.Post("/{id}/restore", reqRepoWriter(unit.TypeCode), repo.RestoreDeletedBranch)
+				}, context.ReferencesGitRepo(), reqRepoReader(unit.TypeCode))
 				m.Group("/branch_protections", func() {
 					m.Get("", repo.ListBranchProtections)
 					m.Post("", bind(api.CreateBranchProtectionOption{}), repo.CreateBranchProtection)
@@ -850,16 +938,65 @@ func Routes() *web.Route {
 					m.Post("", reqRepoWriter(unit.TypeCode), bind(api.CreateTagOption{}), repo.CreateTag)
 					m.Delete("/*", repo.DeleteTag)
 				}, reqRepoReader(unit.TypeCode), context.ReferencesGitRepo(true))
+				m.Group("/settings_file", func() {
+					m.Get("/drift", repo.GetSettingsFileDrift)
+					m.Post("/apply", repo.ApplySettingsFile)
+				}, reqToken(), reqAdmin(), context.ReferencesGitRepo())
+				m.Group("/secrets", func() {
+					m.Get("", repo.ListSecrets)
+					m.Combo("/{name}").Put(bind(api.SetSecretOption{}), repo.SetSecret).
+						Delete(repo.DeleteSecret)
+				}, reqToken(), reqAdmin())
+				m.Group("/variables", func() {
+					m.Get("", repo.ListVariables)
+					m.Combo("/{name}").Get(repo.GetVariable).
+						Put(bind(api.SetSecretOption{}), repo.SetVariable).
+						Delete(repo.DeleteVariable)
+				}, reqToken(), reqAdmin())
+				m.Group("/actions", func() {
+					m.Group("/runs", func() {
+						m.Get("", repo.ListActionRuns)
+						m.Group("/{index}", func() {
+							m.Get("", repo.GetActionRun)
+							m.Post("/cancel", reqToken(), reqRepoWriter(unit.TypeCode), repo.CancelActionRun)
+							m.Get("/jobs", repo.ListActionRunJobs)
+							m.Get("/artifacts", repo.ListActionRunArtifacts)
+						})
+					})
+					m.Get("/jobs/{id}/logs", repo.GetActionJobLogs)
+					m.Group("/runners", func() {
+						m.Get("", repo.ListRunners)
+						m.Combo("/registration_token").Get(repo.GetRunnerRegistrationToken).
+							Post(repo.ResetRunnerRegistrationToken)
+						m.Delete("/{id}", repo.DeleteRunner)
+					}, reqToken(), reqAdmin())
+				}, reqRepoReader(unit.TypeCode), reqActionsEnabled())
+				m.Group("/discussions", func() {
+					m.Combo("").Get(repo.ListDiscussions).
+						Post(reqToken(), mustNotBeArchived, bind(api.CreateDiscussionOption{}), repo.CreateDiscussion)
+					m.Get("/categories", repo.ListDiscussionCategories)
+					m.Group("/{index}", func() {
+						m.Get("", repo.GetDiscussion)
+						m.Combo("/comments").Get(repo.ListDiscussionComments).
+							Post(reqToken(), mustNotBeArchived, bind(api.CreateDiscussionCommentOption{}), repo.CreateDiscussionComment)
+					})
+				}, reqRepoReader(unit.TypeDiscussions), reqDiscussionsEnabled())
 				m.Group("/keys", func() {
 					m.Combo("").Get(repo.ListDeployKeys).
 						Post(bind(api.CreateKeyOption{}), repo.CreateDeployKey)
 					m.Combo("/{id}").Get(repo.GetDeployKey).
 						Delete(repo.DeleteDeploykey)
 				}, reqToken(), reqAdmin())
+				m.Group("/deploy_tokens", func() {
+					m.Combo("").Get(repo.ListDeployTokens).
+						Post(bind(api.CreateDeployTokenOption{}), repo.CreateDeployToken)
+					m.Delete("/{id}", repo.DeleteDeployToken)
+				}, reqToken(), reqAdmin())
 				m.Group("/times", func() {
 					m.Combo("").Get(repo.ListTrackedTimesByRepository)
 					m.Combo("/{timetrackingusername}").Get(repo.ListTrackedTimesByUser)
 				}, mustEnableIssues, reqToken())
+				m.Get("/time_report", mustEnableIssues, reqToken(), repo.GetTimeReport)
 				m.Group("/wiki", func() {
 					m.Combo("/page/{pageName}").
 						Get(repo.GetWikiPage).
@@ -883,6 +1020,13 @@ func Routes() *web.Route {
 								Get(repo.GetIssueCommentReactions).
 								Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueCommentReaction).
 								Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueCommentReaction)
+							m.Group("/content_history", func() {
+								m.Combo("").Get(repo.ListIssueCommentContentHistory).
+									Delete(reqToken(), reqSiteAdmin(), repo.PurgeIssueCommentContentHistory)
+								m.Combo("/{history_id}").Get(repo.GetIssueCommentContentHistory).
+									Delete(reqToken(), repo.DeleteIssueCommentContentHistory)
+								m.Delete("/{history_id}/purge", reqToken(), reqSiteAdmin(), repo.PurgeIssueCommentContentHistoryRevision)
+							})
 						})
 					})
 					m.Group("/{index}", func() {
@@ -896,6 +1040,7 @@ func Routes() *web.Route {
 								Delete(repo.DeleteIssueCommentDeprecated)
 						})
 						m.Get("/timeline", repo.ListIssueCommentsAndTimeline)
+						m.Get("/dependency-graph", repo.GetIssueDependencyGraph)
 						m.Group("/labels", func() {
 							m.Combo("").Get(repo.ListIssueLabels).
 								Post(reqToken(), bind(api.IssueLabelsOption{}), repo.AddIssueLabels).
@@ -926,6 +1071,13 @@ func Routes() *web.Route {
 							Get(repo.GetIssueReactions).
 							Post(reqToken(), bind(api.EditReactionOption{}), repo.PostIssueReaction).
 							Delete(reqToken(), bind(api.EditReactionOption{}), repo.DeleteIssueReaction)
+						m.Group("/content_history", func() {
+							m.Combo("").Get(repo.ListIssueContentHistory).
+								Delete(reqToken(), reqSiteAdmin(), repo.PurgeIssueContentHistory)
+							m.Combo("/{history_id}").Get(repo.GetIssueContentHistory).
+								Delete(reqToken(), repo.DeleteIssueContentHistory)
+							m.Delete("/{history_id}/purge", reqToken(), reqSiteAdmin(), repo.PurgeIssueContentHistoryRevision)
+						})
 					})
 				}, mustEnableIssuesOrPulls)
 				m.Group("/labels", func() {
@@ -937,6 +1089,7 @@ func Routes() *web.Route {
 				})
 				m.Post("/markdown", bind(api.MarkdownOption{}), misc.Markdown)
 				m.Post("/markdown/raw", misc.MarkdownRaw)
+				m.Post("/markdown/toc", bind(api.MarkdownOption{}), misc.MarkdownTableOfContents)
 				m.Group("/milestones", func() {
 					m.Combo("").Get(repo.ListMilestones).
 						Post(reqToken(), reqRepoWriter(unit.TypeIssues, unit.TypePullRequests), bind(api.CreateMilestoneOption{}), repo.CreateMilestone)
@@ -953,27 +1106,40 @@ func Routes() *web.Route {
 				})
 				m.Group("/releases", func() {
 					m.Combo("").Get(repo.ListReleases).
-						Post(reqToken(), reqRepoWriter(unit.TypeReleases), context.ReferencesGitRepo(), bind(api.CreateReleaseOption{}), repo.CreateRelease)
+						Post(reqToken(), reqRepoReleaseAction(perm.ReleaseActionCreate), context.ReferencesGitRepo(), bind(api.CreateReleaseOption{}), repo.CreateRelease)
+					m.Get("/signing-key.gpg", repo.GetReleaseSigningKey)
 					m.Group("/{id}", func() {
 						m.Combo("").Get(repo.GetRelease).
-							Patch(reqToken(), reqRepoWriter(unit.TypeReleases), context.ReferencesGitRepo(), bind(api.EditReleaseOption{}), repo.EditRelease).
-							Delete(reqToken(), reqRepoWriter(unit.TypeReleases), repo.DeleteRelease)
+							Patch(reqToken(), reqRepoReleaseAction(perm.ReleaseActionEdit), context.ReferencesGitRepo(), bind(api.EditReleaseOption{}), repo.EditRelease).
+							Delete(reqToken(), reqRepoReleaseAction(perm.ReleaseActionEdit), repo.DeleteRelease)
+						m.Get("/verification", context.ReferencesGitRepo(), repo.GetReleaseVerification)
 						m.Group("/assets", func() {
 							m.Combo("").Get(repo.ListReleaseAttachments).
-								Post(reqToken(), reqRepoWriter(unit.TypeReleases), repo.CreateReleaseAttachment)
+								Post(reqToken(), reqRepoReleaseAction(perm.ReleaseActionUploadAssets), repo.CreateReleaseAttachment)
+							m.Post("/external", reqToken(), reqRepoReleaseAction(perm.ReleaseActionUploadAssets), bind(api.CreateExternalAttachmentOption{}), repo.CreateReleaseExternalAttachment)
+							if setting.ResumableUpload.Enabled {
+								m.Group("/uploads", func() {
+									m.Options("", repo.ReleaseAttachmentUploadOptions)
+									m.Post("", repo.CreateReleaseAttachmentUpload)
+									m.Head("/{upload_id}", repo.GetReleaseAttachmentUpload)
+									m.Patch("/{upload_id}", repo.PatchReleaseAttachmentUpload)
+									m.Delete("/{upload_id}", repo.DeleteReleaseAttachmentUpload)
+								}, reqToken(), reqRepoReleaseAction(perm.ReleaseActionUploadAssets))
+							}
 							m.Combo("/{asset}").Get(repo.GetReleaseAttachment).
-								Patch(reqToken(), reqRepoWriter(unit.TypeReleases), bind(api.EditAttachmentOptions{}), repo.EditReleaseAttachment).
-								Delete(reqToken(), reqRepoWriter(unit.TypeReleases), repo.DeleteReleaseAttachment)
+								Patch(reqToken(), reqRepoReleaseAction(perm.ReleaseActionEdit), bind(api.EditAttachmentOptions{}), repo.EditReleaseAttachment).
+								Delete(reqToken(), reqRepoReleaseAction(perm.ReleaseActionEdit), repo.DeleteReleaseAttachment)
 						})
 					})
 					m.Group("/tags", func() {
 						m.Combo("/{tag}").
 							Get(repo.GetReleaseByTag).
-							Delete(reqToken(), reqRepoWriter(unit.TypeReleases), repo.DeleteReleaseByTag)
+							Delete(reqToken(), reqRepoReleaseAction(perm.ReleaseActionEdit), repo.DeleteReleaseByTag)
 					})
 				}, reqRepoReader(unit.TypeReleases))
 				m.Post("/mirror-sync", reqToken(), reqRepoWriter(unit.TypeCode), repo.MirrorSync)
 				m.Get("/editorconfig/{filename}", context.ReferencesGitRepo(), context.RepoRefForAPI, reqRepoReader(unit.TypeCode), repo.GetEditorconfig)
+				m.Get("/search/code", reqRepoReader(unit.TypeCode), repo.SearchCode)
 				m.Group("/pulls", func() {
 					m.Combo("").Get(repo.ListPullRequests).
 						Post(reqToken(), mustNotBeArchived, bind(api.CreatePullRequestOption{}), repo.CreatePullRequest)
@@ -982,6 +1148,7 @@ func Routes() *web.Route {
 							Patch(reqToken(), bind(api.EditPullRequestOption{}), repo.EditPullRequest)
 						m.Get(".{diffType:diff|patch}", repo.DownloadPullDiffOrPatch)
 						m.Post("/update", reqToken(), repo.UpdatePullRequest)
+						m.Post("/rewrite", reqToken(), mustNotBeArchived, bind(api.RewritePullRequestOption{}), repo.RewritePullRequest)
 						m.Get("/commits", repo.GetPullRequestCommits)
 						m.Combo("/merge").Get(repo.IsPullRequestMerged).
 							Post(reqToken(), mustNotBeArchived, bind(forms.MergePullRequestForm{}), repo.MergePullRequest).
@@ -1004,30 +1171,52 @@ func Routes() *web.Route {
 						m.Combo("/requested_reviewers").
 							Delete(reqToken(), bind(api.PullReviewRequestOptions{}), repo.DeleteReviewRequests).
 							Post(reqToken(), bind(api.PullReviewRequestOptions{}), repo.CreateReviewRequests)
+						m.Group("/preview_environments", func() {
+							m.Combo("").Get(repo.ListPreviewEnvironments).
+								Post(reqToken(), reqRepoWriter(unit.TypeCode), bind(api.CreatePreviewEnvironmentOption{}), repo.CreatePreviewEnvironment)
+							m.Combo("/{id}").Get(repo.GetPreviewEnvironment).
+								Patch(reqToken(), reqRepoWriter(unit.TypeCode), bind(api.EditPreviewEnvironmentOption{}), repo.EditPreviewEnvironment).
+								Delete(reqToken(), reqRepoWriter(unit.TypeCode), repo.DeletePreviewEnvironment)
+						})
 					})
 				}, mustAllowPulls, reqRepoReader(unit.TypeCode), context.ReferencesGitRepo())
 				m.Group("/statuses", func() {
 					m.Combo("/{sha}").Get(repo.GetCommitStatuses).
 						Post(reqToken(), bind(api.CreateStatusOption{}), repo.NewCommitStatus)
 				}, reqRepoReader(unit.TypeCode))
+				m.Group("/check_runs", func() {
+					m.Post("", reqToken(), reqRepoWriter(unit.TypeCode), bind(api.CreateCheckRunOption{}), repo.CreateCheckRun)
+					m.Group("/{id}", func() {
+						m.Combo("").Get(repo.GetCheckRun).
+							Patch(reqToken(), reqRepoWriter(unit.TypeCode), bind(api.EditCheckRunOption{}), repo.EditCheckRun)
+						m.Get("/annotations", repo.ListCheckRunAnnotations)
+						m.Post("/rerequest", reqToken(), reqRepoWriter(unit.TypeCode), repo.RerequestCheckRun)
+					})
+				}, reqRepoReader(unit.TypeCode))
 				m.Group("/commits", func() {
 					m.Get("", context.ReferencesGitRepo(), repo.GetAllCommits)
 					m.Group("/{ref}", func() {
 						m.Get("/status", repo.GetCombinedCommitStatusByRef)
 						m.Get("/statuses", repo.GetCommitStatusesByRef)
+						m.Get("/check_runs", repo.ListCheckRunsByRef)
 					}, context.ReferencesGitRepo())
 				}, reqRepoReader(unit.TypeCode))
 				m.Group("/git", func() {
 					m.Group("/commits", func() {
+						m.Post("", reqToken(), reqRepoWriter(unit.TypeCode), bind(api.CreateCommitOptions{}), repo.CreateCommit)
 						m.Get("/{sha}", repo.GetSingleCommit)
 						m.Get("/{sha}.{diffType:diff|patch}", repo.DownloadCommitDiffOrPatch)
 					})
 					m.Get("/refs", repo.GetGitAllRefs)
 					m.Get("/refs/*", repo.GetGitRefs)
 					m.Get("/trees/{sha}", repo.GetTree)
+					m.Post("/trees", bind(api.GetGitTreesOptions{}), repo.GetTrees)
 					m.Get("/blobs/{sha}", repo.GetBlob)
+					m.Post("/blobs", bind(api.GetGitBlobsOptions{}), repo.GetBlobs)
 					m.Get("/tags/{sha}", repo.GetAnnotatedTag)
 					m.Get("/notes/{sha}", repo.GetNote)
+					m.Post("/cherry-pick", reqToken(), reqRepoWriter(unit.TypeCode), mustNotBeArchived, bind(api.CherryPickCommitOptions{}), repo.CherryPickCommit)
+					m.Post("/revert", reqToken(), reqRepoWriter(unit.TypeCode), mustNotBeArchived, bind(api.CherryPickCommitOptions{}), repo.RevertCommit)
 				}, context.ReferencesGitRepo(), reqRepoReader(unit.TypeCode))
 				m.Post("/diffpatch", reqRepoWriter(unit.TypeCode), reqToken(), bind(api.ApplyDiffPatchFileOptions{}), repo.ApplyDiffPatch)
 				m.Group("/contents", func() {
@@ -1050,6 +1239,10 @@ func Routes() *web.Route {
 				}, reqAnyRepoReader())
 				m.Get("/issue_templates", context.ReferencesGitRepo(), repo.GetIssueTemplates)
 				m.Get("/languages", reqRepoReader(unit.TypeCode), repo.GetLanguages)
+				m.Get("/stats/contributors", reqRepoReader(unit.TypeCode), context.ReferencesGitRepo(), repo.GetContributorStats)
+				m.Get("/stats/commits", reqRepoReader(unit.TypeCode), context.ReferencesGitRepo(), repo.GetCommitActivity)
+				m.Get("/stats/code_frequency", reqRepoReader(unit.TypeCode), context.ReferencesGitRepo(), repo.GetCodeFrequency)
+				m.Get("/stats/punch_card", reqRepoReader(unit.TypeCode), context.ReferencesGitRepo(), repo.GetPunchCard)
 			}, repoAssignment())
 		})
 
@@ -1059,6 +1252,9 @@ func Routes() *web.Route {
 				m.Delete("", reqPackageAccess(perm.AccessModeWrite), packages.DeletePackage)
 				m.Get("/files", packages.ListPackageFiles)
 			})
+			m.Combo("/policy", reqPackageAccess(perm.AccessModeAdmin)).
+				Get(packages.GetPackagePolicy).
+				Patch(bind(api.EditPackagePolicyOption{}), packages.EditPackagePolicy)
 			m.Get("/", packages.ListPackages)
 		}, context_service.UserAssignmentAPI(), context.PackageAssignmentAPI(), reqPackageAccess(perm.AccessModeRead))
 
@@ -1076,6 +1272,10 @@ func Routes() *web.Route {
 				Delete(reqToken(), reqOrgOwnership(), org.Delete)
 			m.Combo("/repos").Get(user.ListOrgRepos).
 				Post(reqToken(), bind(api.CreateRepoOption{}), repo.CreateOrgRepo)
+			m.Group("/repos/bulk", func() {
+				m.Post("", repo.BulkCreateOrgRepos)
+				m.Get("/{id}", repo.GetBulkCreateOrgReposJob)
+			}, reqToken(), reqOrgMembership())
 			m.Group("/members", func() {
 				m.Get("", org.ListMembers)
 				m.Combo("/{username}").Get(org.IsMember).
@@ -1106,6 +1306,37 @@ func Routes() *web.Route {
 					Patch(bind(api.EditHookOption{}), org.EditHook).
 					Delete(org.DeleteHook)
 			}, reqToken(), reqOrgOwnership(), reqWebhooksEnabled())
+			m.Get("/time_report", reqToken(), reqOrgOwnership(), org.GetTimeReport)
+			m.Get("/announcements", org.ListAnnouncements)
+			m.Group("/branch_protections", func() {
+				m.Get("", org.ListBranchProtections)
+				m.Post("", bind(api.CreateOrgBranchProtectionOption{}), org.CreateBranchProtection)
+				m.Combo("/{id}").Get(org.GetBranchProtection).
+					Patch(bind(api.EditOrgBranchProtectionOption{}), org.EditBranchProtection).
+					Delete(org.DeleteBranchProtection)
+			}, reqToken(), reqOrgOwnership())
+			m.Group("/secrets", func() {
+				m.Get("", org.ListSecrets)
+				m.Combo("/{name}").Put(bind(api.SetSecretOption{}), org.SetSecret).
+					Delete(org.DeleteSecret)
+			}, reqToken(), reqOrgOwnership())
+			m.Group("/variables", func() {
+				m.Get("", org.ListVariables)
+				m.Combo("/{name}").Get(org.GetVariable).
+					Put(bind(api.SetSecretOption{}), org.SetVariable).
+					Delete(org.DeleteVariable)
+			}, reqToken(), reqOrgOwnership())
+			m.Group("/actions/runners", func() {
+				m.Get("", org.ListRunners)
+				m.Combo("/registration_token").Get(org.GetRunnerRegistrationToken).
+					Post(org.ResetRunnerRegistrationToken)
+				m.Delete("/{id}", org.DeleteRunner)
+			}, reqToken(), reqOrgOwnership(), reqActionsEnabled())
+			m.Group("/policy", func() {
+				m.Get("", org.GetPolicy)
+				m.Post("/drift", bind(api.OrgPolicy{}), org.GetPolicyDrift)
+				m.Post("/apply", bind(api.OrgPolicy{}), org.ApplyPolicy)
+			}, reqToken(), reqOrgOwnership())
 		}, orgAssignment(true))
 		m.Group("/teams/{teamid}", func() {
 			m.Combo("").Get(org.GetTeam).
@@ -1133,6 +1364,15 @@ func Routes() *web.Route {
 				m.Post("/{task}", admin.PostCronTask)
 			})
 			m.Get("/orgs", admin.GetAllOrgs)
+			m.Get("/selftest", admin.RunSelfTest)
+			m.Get("/attachments/usage", admin.ListAttachmentUsages)
+			m.Group("/announcements", func() {
+				m.Combo("").Get(admin.ListAnnouncements).
+					Post(bind(api.CreateAnnouncementOption{}), admin.CreateAnnouncement)
+				m.Combo("/{id}").Get(admin.GetAnnouncement).
+					Patch(bind(api.EditAnnouncementOption{}), admin.EditAnnouncement).
+					Delete(admin.DeleteAnnouncement)
+			})
 			m.Group("/users", func() {
 				m.Get("", admin.GetAllUsers)
 				m.Post("", bind(api.CreateUserOption{}), admin.CreateUser)
@@ -1153,6 +1393,12 @@ func Routes() *web.Route {
 				m.Post("/{username}/{reponame}", admin.AdoptRepository)
 				m.Delete("/{username}/{reponame}", admin.DeleteUnadoptedRepository)
 			})
+			m.Group("/actions/runners", func() {
+				m.Get("", admin.ListRunners)
+				m.Combo("/registration_token").Get(admin.GetRunnerRegistrationToken).
+					Post(admin.ResetRunnerRegistrationToken)
+				m.Delete("/{id}", admin.DeleteRunner)
+			}, reqActionsEnabled())
 		}, reqToken(), reqSiteAdmin())
 
 		m.Group("/topics", func() {
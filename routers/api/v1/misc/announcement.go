@@ -0,0 +1,35 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package misc
+
+import (
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListAnnouncements lists the announcements of the instance which are shown now
+func ListAnnouncements(ctx *context.APIContext) {
+	// swagger:operation GET /announcements miscellaneous listAnnouncements
+	// ---
+	// summary: List the active announcements of the instance, e.g. for status pages
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AnnouncementList"
+
+	utils.ListAnnouncements(ctx, &admin_model.FindAnnouncementsOptions{ActiveAt: timeutil.TimeStampNow()})
+}
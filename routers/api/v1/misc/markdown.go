@@ -5,6 +5,7 @@
 package misc
 
 import (
+	"io"
 	"net/http"
 	"strings"
 
@@ -51,6 +52,14 @@ func Markdown(ctx *context.APIContext) {
 		return
 	}
 
+	if _, err := renderMarkdown(ctx, form, ctx.Resp); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+}
+
+// renderMarkdown renders the markdown of the form to the output and returns the context it is rendered with
+func renderMarkdown(ctx *context.APIContext, form *api.MarkdownOption, output io.Writer) (*markup.RenderContext, error) {
 	switch form.Mode {
 	case "comment":
 		fallthrough
@@ -77,24 +86,80 @@ func Markdown(ctx *context.APIContext) {
 			meta["mode"] = "document"
 		}
 
-		if err := markdown.Render(&markup.RenderContext{
+		renderCtx := &markup.RenderContext{
 			Ctx:       ctx,
 			URLPrefix: urlPrefix,
 			Metas:     meta,
 			IsWiki:    form.Wiki,
-		}, strings.NewReader(form.Text), ctx.Resp); err != nil {
-			ctx.InternalServerError(err)
-			return
 		}
+		return renderCtx, markdown.Render(renderCtx, strings.NewReader(form.Text), output)
 	default:
-		if err := markdown.RenderRaw(&markup.RenderContext{
+		renderCtx := &markup.RenderContext{
 			Ctx:       ctx,
 			URLPrefix: form.Context,
-		}, strings.NewReader(form.Text), ctx.Resp); err != nil {
-			ctx.InternalServerError(err)
+		}
+		return renderCtx, markdown.RenderRaw(renderCtx, strings.NewReader(form.Text), output)
+	}
+}
+
+// MarkdownTableOfContents returns the table of contents of a markdown document
+func MarkdownTableOfContents(ctx *context.APIContext) {
+	// swagger:operation POST /markdown/toc miscellaneous renderMarkdownTableOfContents
+	// ---
+	// summary: Get the table of contents of a markdown document with the anchors of its headers
+	// parameters:
+	// - name: depth
+	//   in: query
+	//   description: number of header levels of the table of contents starting at the top level, defaults to the configured depth
+	//   type: integer
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/MarkdownOption"
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/MarkdownTableOfContents"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.MarkdownOption)
+
+	if ctx.HasAPIError() {
+		ctx.Error(http.StatusUnprocessableEntity, "", ctx.GetErrMsg())
+		return
+	}
+
+	depth := setting.Markdown.TOCDepth
+	if ctx.FormString("depth") != "" {
+		if depth = ctx.FormInt("depth"); depth < 1 || depth > 6 {
+			ctx.Error(http.StatusUnprocessableEntity, "", "depth must be between 1 and 6")
 			return
 		}
 	}
+
+	toc := []api.MarkdownHeader{}
+	if len(form.Text) == 0 {
+		ctx.JSON(http.StatusOK, toc)
+		return
+	}
+
+	renderCtx, err := renderMarkdown(ctx, form, io.Discard)
+	if err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+	for _, header := range markup.TableOfContents(renderCtx.Headers, depth) {
+		toc = append(toc, api.MarkdownHeader{
+			Level:  header.Level,
+			Text:   header.Text,
+			Anchor: header.ID,
+		})
+	}
+	ctx.JSON(http.StatusOK, toc)
 }
 
 // MarkdownRaw render raw markdown HTML
@@ -13,6 +13,7 @@ import (
 	"testing"
 
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/templates"
@@ -182,3 +183,35 @@ func TestAPI_RenderRaw(t *testing.T) {
 		resp.Body.Reset()
 	}
 }
+
+func TestAPI_RenderTableOfContents(t *testing.T) {
+	setting.AppURL = AppURL
+
+	options := api.MarkdownOption{
+		Mode: "gfm",
+		Text: `## Install
+### From source
+#### Requirements
+## Usage
+`,
+		Context: Repo,
+	}
+	requrl, _ := url.Parse(util.URLJoin(AppURL, "api", "v1", "markdown", "toc") + "?depth=2")
+	req := &http.Request{
+		Method: "POST",
+		URL:    requrl,
+	}
+	m, resp := createContext(req)
+	ctx := wrap(m)
+
+	web.SetForm(ctx, &options)
+	MarkdownTableOfContents(ctx)
+	assert.Equal(t, http.StatusOK, resp.Code)
+	var toc []api.MarkdownHeader
+	assert.NoError(t, json.Unmarshal(resp.Body.Bytes(), &toc))
+	assert.Equal(t, []api.MarkdownHeader{
+		{Level: 2, Text: "Install", Anchor: "user-content-install"},
+		{Level: 3, Text: "From source", Anchor: "user-content-from-source"},
+		{Level: 2, Text: "Usage", Anchor: "user-content-usage"},
+	}, toc)
+}
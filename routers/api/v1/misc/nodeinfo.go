@@ -27,6 +27,8 @@ func NodeInfo(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/NodeInfo"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 
 	nodeInfoUsage := structs.NodeInfoUsage{}
 	if setting.Federation.ShareUserStatistics {
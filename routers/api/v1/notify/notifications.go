@@ -61,6 +61,10 @@ func subjectToSource(value []string) (result []models.NotificationSource) {
 			result = append(result, models.NotificationSourceCommit)
 		case "repository":
 			result = append(result, models.NotificationSourceRepository)
+		case "securityevent":
+			result = append(result, models.NotificationSourceSecurityEvent)
+		case "discussion":
+			result = append(result, models.NotificationSourceDiscussion)
 		}
 	}
 	return
@@ -24,6 +24,8 @@ func statusStringToNotificationStatus(status string) models.NotificationStatus {
 		return models.NotificationStatusRead
 	case "pinned":
 		return models.NotificationStatusPinned
+	case "archived":
+		return models.NotificationStatusArchived
 	default:
 		return 0
 	}
@@ -69,7 +71,7 @@ func ListRepoNotifications(ctx *context.APIContext) {
 	//   type: boolean
 	// - name: status-types
 	//   in: query
-	//   description: "Show notifications with the provided status types. Options are: unread, read and/or pinned. Defaults to unread & pinned"
+	//   description: "Show notifications with the provided status types. Options are: unread, read, pinned and/or archived. Defaults to unread & pinned"
 	//   type: array
 	//   collectionFormat: multi
 	//   items:
@@ -81,7 +83,7 @@ func ListRepoNotifications(ctx *context.APIContext) {
 	//   collectionFormat: multi
 	//   items:
 	//     type: string
-	//     enum: [issue,pull,commit,repository]
+	//     enum: [issue,pull,commit,repository,discussion]
 	// - name: since
 	//   in: query
 	//   description: Only show notifications updated after the given time. This is a timestamp in RFC 3339 format
@@ -158,7 +160,7 @@ func ReadRepoNotifications(ctx *context.APIContext) {
 	//   required: false
 	// - name: status-types
 	//   in: query
-	//   description: "Mark notifications with the provided status types. Options are: unread, read and/or pinned. Defaults to unread."
+	//   description: "Mark notifications with the provided status types. Options are: unread, read, pinned and/or archived. Defaults to unread."
 	//   type: array
 	//   collectionFormat: multi
 	//   items:
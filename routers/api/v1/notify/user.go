@@ -30,7 +30,7 @@ func ListNotifications(ctx *context.APIContext) {
 	//   type: boolean
 	// - name: status-types
 	//   in: query
-	//   description: "Show notifications with the provided status types. Options are: unread, read and/or pinned. Defaults to unread & pinned."
+	//   description: "Show notifications with the provided status types. Options are: unread, read, pinned and/or archived. Defaults to unread & pinned."
 	//   type: array
 	//   collectionFormat: multi
 	//   items:
@@ -42,7 +42,7 @@ func ListNotifications(ctx *context.APIContext) {
 	//   collectionFormat: multi
 	//   items:
 	//     type: string
-	//     enum: [issue,pull,commit,repository]
+	//     enum: [issue,pull,commit,repository,securityevent,discussion]
 	// - name: since
 	//   in: query
 	//   description: Only show notifications updated after the given time. This is a timestamp in RFC 3339 format
@@ -113,7 +113,7 @@ func ReadNotifications(ctx *context.APIContext) {
 	//   required: false
 	// - name: status-types
 	//   in: query
-	//   description: "Mark notifications with the provided status types. Options are: unread, read and/or pinned. Defaults to unread."
+	//   description: "Mark notifications with the provided status types. Options are: unread, read, pinned and/or archived. Defaults to unread."
 	//   type: array
 	//   collectionFormat: multi
 	//   items:
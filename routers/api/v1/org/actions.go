@@ -0,0 +1,98 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListRunners list the runners of an organization
+func ListRunners(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/actions/runners organization orgListRunners
+	// ---
+	// summary: List the runners registered for an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunnerList"
+
+	utils.ListRunners(ctx, ctx.Org.Organization.ID, 0)
+}
+
+// GetRunnerRegistrationToken get the runner registration token of an organization
+func GetRunnerRegistrationToken(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/actions/runners/registration_token organization orgGetRunnerRegistrationToken
+	// ---
+	// summary: Get the token to register runners for an organization, it is created if there is none
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunnerRegistrationToken"
+
+	utils.GetRunnerRegistrationToken(ctx, ctx.Org.Organization.ID, 0)
+}
+
+// ResetRunnerRegistrationToken replace the runner registration token of an organization
+func ResetRunnerRegistrationToken(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/actions/runners/registration_token organization orgResetRunnerRegistrationToken
+	// ---
+	// summary: Replace the token to register runners for an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ActionRunnerRegistrationToken"
+
+	utils.ResetRunnerRegistrationToken(ctx, ctx.Org.Organization.ID, 0)
+}
+
+// DeleteRunner delete a runner of an organization
+func DeleteRunner(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/actions/runners/{id} organization orgDeleteRunner
+	// ---
+	// summary: Delete a runner of an organization, the jobs it is running are failed
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the runner
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteRunner(ctx, ctx.Org.Organization.ID, 0)
+}
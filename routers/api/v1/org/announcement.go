@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListAnnouncements lists the announcements of an organization which are shown now
+func ListAnnouncements(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/announcements organization orgListAnnouncements
+	// ---
+	// summary: List the active announcements of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/AnnouncementList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if !organization.HasOrgOrUserVisible(ctx, ctx.Org.Organization.AsUser(), ctx.Doer) {
+		ctx.NotFound("HasOrgOrUserVisible", nil)
+		return
+	}
+
+	utils.ListAnnouncements(ctx, &admin_model.FindAnnouncementsOptions{
+		OrgID:    ctx.Org.Organization.ID,
+		ActiveAt: timeutil.TimeStampNow(),
+	})
+}
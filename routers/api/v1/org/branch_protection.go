@@ -0,0 +1,268 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListBranchProtections list the branch protection rules of an organization
+func ListBranchProtections(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/branch_protections organization orgListBranchProtection
+	// ---
+	// summary: List the branch protection rules inherited by the repositories of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgBranchProtectionList"
+
+	rules, err := models.GetOrgProtectedBranches(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrgProtectedBranches", err)
+		return
+	}
+
+	apiRules := make([]*api.OrgBranchProtection, len(rules))
+	for i := range rules {
+		apiRules[i] = convert.ToOrgBranchProtection(rules[i])
+	}
+	ctx.JSON(http.StatusOK, apiRules)
+}
+
+// CreateBranchProtection creates branch protection rules of an organization
+func CreateBranchProtection(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/branch_protections organization orgCreateBranchProtection
+	// ---
+	// summary: Create branch protection rules inherited by the repositories of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateOrgBranchProtectionOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/OrgBranchProtection"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateOrgBranchProtectionOption)
+	rule := &models.OrgProtectedBranch{
+		OrgID:                  ctx.Org.Organization.ID,
+		BranchPattern:          form.BranchPattern,
+		ApplyToExisting:        form.ApplyToExisting,
+		AllowOverride:          form.AllowOverride,
+		CanPush:                form.EnablePush,
+		EnableStatusCheck:      form.EnableStatusCheck,
+		RequiredApprovals:      form.RequiredApprovals,
+		BlockOnRejectedReviews: form.BlockOnRejectedReviews,
+		DismissStaleApprovals:  form.DismissStaleApprovals,
+		RequireSignedCommits:   form.RequireSignedCommits,
+	}
+	if rule.EnableStatusCheck {
+		rule.StatusCheckContexts = form.StatusCheckContexts
+	}
+	if rule.RequiredApprovals < 0 {
+		rule.RequiredApprovals = 0
+	}
+
+	if err := models.CreateOrgProtectedBranch(ctx, rule); err != nil {
+		if models.IsErrInvalidBranchPattern(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateOrgProtectedBranch", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, convert.ToOrgBranchProtection(rule))
+}
+
+// GetBranchProtection gets branch protection rules of an organization
+func GetBranchProtection(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/branch_protections/{id} organization orgGetBranchProtection
+	// ---
+	// summary: Get branch protection rules of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the branch protection rules
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgBranchProtection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	rule := getOrgProtectedBranchByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToOrgBranchProtection(rule))
+}
+
+// EditBranchProtection edits branch protection rules of an organization
+func EditBranchProtection(ctx *context.APIContext) {
+	// swagger:operation PATCH /orgs/{org}/branch_protections/{id} organization orgEditBranchProtection
+	// ---
+	// summary: Edit branch protection rules of an organization. Only fields that are set will be changed
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the branch protection rules
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditOrgBranchProtectionOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgBranchProtection"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditOrgBranchProtectionOption)
+	rule := getOrgProtectedBranchByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.BranchPattern != nil {
+		rule.BranchPattern = *form.BranchPattern
+	}
+	if form.ApplyToExisting != nil {
+		rule.ApplyToExisting = *form.ApplyToExisting
+	}
+	if form.AllowOverride != nil {
+		rule.AllowOverride = *form.AllowOverride
+	}
+	if form.EnablePush != nil {
+		rule.CanPush = *form.EnablePush
+	}
+	if form.EnableStatusCheck != nil {
+		rule.EnableStatusCheck = *form.EnableStatusCheck
+	}
+	if form.StatusCheckContexts != nil {
+		rule.StatusCheckContexts = form.StatusCheckContexts
+	}
+	if !rule.EnableStatusCheck {
+		rule.StatusCheckContexts = nil
+	}
+	if form.RequiredApprovals != nil && *form.RequiredApprovals >= 0 {
+		rule.RequiredApprovals = *form.RequiredApprovals
+	}
+	if form.BlockOnRejectedReviews != nil {
+		rule.BlockOnRejectedReviews = *form.BlockOnRejectedReviews
+	}
+	if form.DismissStaleApprovals != nil {
+		rule.DismissStaleApprovals = *form.DismissStaleApprovals
+	}
+	if form.RequireSignedCommits != nil {
+		rule.RequireSignedCommits = *form.RequireSignedCommits
+	}
+
+	if err := models.UpdateOrgProtectedBranch(ctx, rule); err != nil {
+		if models.IsErrInvalidBranchPattern(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateOrgProtectedBranch", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToOrgBranchProtection(rule))
+}
+
+// DeleteBranchProtection deletes branch protection rules of an organization
+func DeleteBranchProtection(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/branch_protections/{id} organization orgDeleteBranchProtection
+	// ---
+	// summary: Delete branch protection rules of an organization
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the branch protection rules
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := models.DeleteOrgProtectedBranch(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id")); err != nil {
+		if models.IsErrOrgProtectedBranchNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteOrgProtectedBranch", err)
+		}
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func getOrgProtectedBranchByParams(ctx *context.APIContext) *models.OrgProtectedBranch {
+	rule, err := models.GetOrgProtectedBranchByID(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrOrgProtectedBranchNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetOrgProtectedBranchByID", err)
+		}
+		return nil
+	}
+	return rule
+}
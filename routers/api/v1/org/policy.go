@@ -0,0 +1,118 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/orgpolicy"
+)
+
+// GetPolicy exports the settings of an organization as a policy
+func GetPolicy(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/policy organization orgGetPolicy
+	// ---
+	// summary: Export the settings, teams and team members of an organization as a policy
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgPolicy"
+
+	policy, err := orgpolicy.Export(ctx, ctx.Org.Organization)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Export", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, policy)
+}
+
+// GetPolicyDrift returns the changes a policy would apply to an organization
+func GetPolicyDrift(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/policy/drift organization orgGetPolicyDrift
+	// ---
+	// summary: Get the differences between a policy and the settings of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/OrgPolicy"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgPolicyReport"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	reconcilePolicy(ctx, false)
+}
+
+// ApplyPolicy applies a policy to an organization
+func ApplyPolicy(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/policy/apply organization orgApplyPolicy
+	// ---
+	// summary: Apply a policy to an organization
+	// description: Changes which could not be applied are reported with their error.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/OrgPolicy"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/OrgPolicyReport"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	reconcilePolicy(ctx, true)
+}
+
+func reconcilePolicy(ctx *context.APIContext, apply bool) {
+	policy := web.GetForm(ctx).(*api.OrgPolicy)
+	if err := orgpolicy.Validate(policy); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	changes, err := orgpolicy.Drift(ctx, ctx.Org.Organization, policy)
+	if err != nil {
+		if orgpolicy.IsErrInvalidPolicy(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "Drift", err)
+		}
+		return
+	}
+	if apply {
+		orgpolicy.Apply(ctx, changes)
+	}
+	ctx.JSON(http.StatusOK, orgpolicy.ToReport(changes, apply))
+}
@@ -0,0 +1,202 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListSecrets list the secrets of an organization
+func ListSecrets(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/secrets organization orgListSecrets
+	// ---
+	// summary: List the secrets of an organization, their values are never returned
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SecretList"
+
+	utils.ListSecrets(ctx, secret_model.OrgScope(ctx.Org.Organization.ID))
+}
+
+// SetSecret creates or updates a secret of an organization
+func SetSecret(ctx *context.APIContext) {
+	// swagger:operation PUT /orgs/{org}/secrets/{name} organization orgSetSecret
+	// ---
+	// summary: Create or update a secret of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the secret
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetSecretOption"
+	// responses:
+	//   "201":
+	//     description: secret created
+	//   "204":
+	//     description: secret updated
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.SetSecret(ctx, secret_model.OrgScope(ctx.Org.Organization.ID), secret_model.TypeSecret)
+}
+
+// DeleteSecret deletes a secret of an organization
+func DeleteSecret(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/secrets/{name} organization orgDeleteSecret
+	// ---
+	// summary: Delete a secret of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the secret
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteSecret(ctx, secret_model.OrgScope(ctx.Org.Organization.ID), secret_model.TypeSecret)
+}
+
+// ListVariables list the variables of an organization
+func ListVariables(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/variables organization orgListVariables
+	// ---
+	// summary: List the variables of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/VariableList"
+
+	utils.ListVariables(ctx, secret_model.OrgScope(ctx.Org.Organization.ID))
+}
+
+// GetVariable get a variable of an organization
+func GetVariable(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/variables/{name} organization orgGetVariable
+	// ---
+	// summary: Get a variable of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Variable"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.GetVariable(ctx, secret_model.OrgScope(ctx.Org.Organization.ID))
+}
+
+// SetVariable creates or updates a variable of an organization
+func SetVariable(ctx *context.APIContext) {
+	// swagger:operation PUT /orgs/{org}/variables/{name} organization orgSetVariable
+	// ---
+	// summary: Create or update a variable of an organization
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetSecretOption"
+	// responses:
+	//   "201":
+	//     description: variable created
+	//   "204":
+	//     description: variable updated
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.SetSecret(ctx, secret_model.OrgScope(ctx.Org.Organization.ID), secret_model.TypeVariable)
+}
+
+// DeleteVariable deletes a variable of an organization
+func DeleteVariable(ctx *context.APIContext) {
+	// swagger:operation DELETE /orgs/{org}/variables/{name} organization orgDeleteVariable
+	// ---
+	// summary: Delete a variable of an organization
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteSecret(ctx, secret_model.OrgScope(ctx.Org.Organization.ID), secret_model.TypeVariable)
+}
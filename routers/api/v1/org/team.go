@@ -10,11 +10,13 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/log"
@@ -22,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/user"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 )
 
 // ListTeams list all the teams of an organization
@@ -165,6 +168,24 @@ func attachTeamUnitsMap(team *organization.Team, unitsMap map[string]string) {
 	}
 }
 
+// setTeamReleaseActions restricts or allows the release actions of the team, nil options are left unchanged
+func setTeamReleaseActions(team *organization.Team, canCreate, canEdit, canUploadAssets *bool) {
+	for action, allowed := range map[perm.ReleaseAction]*bool{
+		perm.ReleaseActionCreate:       canCreate,
+		perm.ReleaseActionEdit:         canEdit,
+		perm.ReleaseActionUploadAssets: canUploadAssets,
+	} {
+		if allowed == nil {
+			continue
+		}
+		if *allowed {
+			team.RestrictedReleaseActions &^= action
+		} else {
+			team.RestrictedReleaseActions |= action
+		}
+	}
+}
+
 // CreateTeam api for create a team
 func CreateTeam(ctx *context.APIContext) {
 	// swagger:operation POST /orgs/{org}/teams organization orgCreateTeam
@@ -202,6 +223,7 @@ func CreateTeam(ctx *context.APIContext) {
 		CanCreateOrgRepo:        form.CanCreateOrgRepo,
 		AccessMode:              p,
 	}
+	setTeamReleaseActions(team, form.CanCreateReleases, form.CanEditReleases, form.CanUploadReleaseAssets)
 
 	if team.AccessMode < perm.AccessModeAdmin {
 		if len(form.UnitsMap) > 0 {
@@ -293,6 +315,10 @@ func EditTeam(ctx *context.APIContext) {
 		}
 	}
 
+	if !team.IsOwnerTeam() {
+		setTeamReleaseActions(team, form.CanCreateReleases, form.CanEditReleases, form.CanUploadReleaseAssets)
+	}
+
 	if team.AccessMode < perm.AccessModeAdmin {
 		if len(form.UnitsMap) > 0 {
 			attachTeamUnitsMap(team, form.UnitsMap)
@@ -464,6 +490,7 @@ func AddTeamMember(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "AddMember", err)
 		return
 	}
+	auditTeamMember(ctx, admin_model.AuditActionTeamMemberAdd, u)
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -501,9 +528,19 @@ func RemoveTeamMember(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "RemoveTeamMember", err)
 		return
 	}
+	auditTeamMember(ctx, admin_model.AuditActionTeamMemberRemove, u)
 	ctx.Status(http.StatusNoContent)
 }
 
+// auditTeamMember records a change of the members of the current team in the audit log
+func auditTeamMember(ctx *context.APIContext, action admin_model.AuditAction, u *user_model.User) {
+	target := ctx.Org.Team.Name
+	if org, err := organization.GetOrgByID(ctx, ctx.Org.Team.OrgID); err == nil {
+		target = org.Name + "/" + target
+	}
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), action, target, u.Name)
+}
+
 // GetTeamRepos api for get a team's repos
 func GetTeamRepos(ctx *context.APIContext) {
 	// swagger:operation GET /teams/{id}/repos organization orgListTeamRepos
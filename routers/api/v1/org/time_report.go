@@ -0,0 +1,69 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/issue/timereport"
+)
+
+// GetTimeReport aggregates the tracked times of all repositories of an organization
+func GetTimeReport(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/time_report organization orgGetTimeReport
+	// ---
+	// summary: Get a report of an organization's tracked times grouped by user, label or milestone
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of the organization
+	//   type: string
+	//   required: true
+	// - name: group_by
+	//   in: query
+	//   description: how to group the tracked times, defaults to user
+	//   type: string
+	//   enum: [user, label, milestone]
+	// - name: user
+	//   in: query
+	//   description: optional filter by user
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only count times created after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only count times created before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TimeReport"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	opts := utils.GetTimeReportOptions(ctx)
+	if opts == nil {
+		return
+	}
+	opts.OwnerID = ctx.Org.Organization.ID
+
+	report, err := timereport.Generate(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Generate", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, timereport.ToAPIReport(report))
+}
@@ -40,7 +40,7 @@ func ListPackages(ctx *context.APIContext) {
 	//   in: query
 	//   description: package type filter
 	//   type: string
-	//   enum: [composer, conan, container, generic, helm, maven, npm, nuget, pypi, rubygems]
+	//   enum: [cargo, composer, conan, container, debian, generic, helm, maven, npm, nuget, pypi, rubygems, terraform]
 	// - name: q
 	//   in: query
 	//   description: name filter
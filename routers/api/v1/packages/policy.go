@@ -0,0 +1,122 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"errors"
+	"net/http"
+
+	"code.gitea.io/gitea/models/packages"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	packages_service "code.gitea.io/gitea/services/packages"
+)
+
+// GetPackagePolicy gets the quota and retention policy of the packages of an owner
+func GetPackagePolicy(ctx *context.APIContext) {
+	// swagger:operation GET /packages/{owner}/policy package getPackagePolicy
+	// ---
+	// summary: Gets the quota and retention policy of the packages of an owner
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackagePolicy"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	policy, err := packages.GetPolicyByOwnerID(ctx, ctx.Package.Owner.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPolicyByOwnerID", err)
+		return
+	}
+
+	writePackagePolicy(ctx, policy)
+}
+
+// EditPackagePolicy edits the quota and retention policy of the packages of an owner
+func EditPackagePolicy(ctx *context.APIContext) {
+	// swagger:operation PATCH /packages/{owner}/policy package editPackagePolicy
+	// ---
+	// summary: Edits the quota and retention policy of the packages of an owner. Only site administrators can change the quota.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the packages
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPackagePolicyOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PackagePolicy"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPackagePolicyOption)
+
+	policy, err := packages.GetPolicyByOwnerID(ctx, ctx.Package.Owner.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPolicyByOwnerID", err)
+		return
+	}
+
+	if (form.MaxCount != nil && *form.MaxCount != policy.MaxCount) || (form.MaxSize != nil && *form.MaxSize != policy.MaxSize) {
+		if !ctx.IsUserSiteAdmin() {
+			ctx.Error(http.StatusForbidden, "", "only site administrators can change the package quota")
+			return
+		}
+	}
+
+	if form.MaxCount != nil {
+		policy.MaxCount = *form.MaxCount
+	}
+	if form.MaxSize != nil {
+		policy.MaxSize = *form.MaxSize
+	}
+	if form.KeepLastVersions != nil {
+		policy.KeepLastVersions = *form.KeepLastVersions
+	}
+	if form.UntaggedRetentionDays != nil {
+		policy.UntaggedRetentionDays = *form.UntaggedRetentionDays
+	}
+	if policy.MaxCount < 0 || policy.MaxSize < 0 || policy.KeepLastVersions < 0 || policy.UntaggedRetentionDays < 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", errors.New("values must not be negative"))
+		return
+	}
+
+	if err := packages.SetPolicy(ctx, policy); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetPolicy", err)
+		return
+	}
+
+	writePackagePolicy(ctx, policy)
+}
+
+func writePackagePolicy(ctx *context.APIContext, policy *packages.PackagePolicy) {
+	usage, err := packages_service.GetOwnerUsage(ctx, policy.OwnerID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOwnerUsage", err)
+		return
+	}
+
+	ctx.JSON(http.StatusOK, convert.ToPackagePolicy(policy, usage.Count, usage.Size))
+}
@@ -0,0 +1,432 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+// ListActionRuns list the workflow runs of a repository
+func ListActionRuns(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs repository repoListActionRuns
+	// ---
+	// summary: List the workflow runs of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: workflow
+	//   in: query
+	//   description: file name of the workflow to filter by
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunList"
+
+	listOptions := utils.GetListOptions(ctx)
+	runs, total, err := actions_model.FindRuns(ctx, actions_model.FindRunOptions{
+		ListOptions: listOptions,
+		RepoID:      ctx.Repo.Repository.ID,
+		WorkflowID:  ctx.FormString("workflow"),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRuns", err)
+		return
+	}
+
+	apiRuns := make([]*api.ActionRun, len(runs))
+	for i, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		if err := run.LoadAttributes(ctx); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiRuns[i] = convert.ToActionRun(run, ctx.Doer)
+	}
+
+	ctx.SetLinkHeader(int(total), listOptions.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, apiRuns)
+}
+
+// getActionRun returns the run of the repository identified by the `index` parameter
+func getActionRun(ctx *context.APIContext) *actions_model.ActionRun {
+	run, err := actions_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if actions_model.IsErrRunNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunByIndex", err)
+		}
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	if err := run.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil
+	}
+	return run
+}
+
+// GetActionRun get a workflow run of a repository
+func GetActionRun(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{index} repository repoGetActionRun
+	// ---
+	// summary: Get a workflow run of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToActionRun(run, ctx.Doer))
+}
+
+// CancelActionRun cancels the unfinished jobs of a workflow run
+func CancelActionRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runs/{index}/cancel repository repoCancelActionRun
+	// ---
+	// summary: Cancel the unfinished jobs of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := actions_service.CancelRun(ctx, run); err != nil {
+		ctx.Error(http.StatusInternalServerError, "CancelRun", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToActionRun(run, ctx.Doer))
+}
+
+// ListActionRunJobs list the jobs of a workflow run
+func ListActionRunJobs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{index}/jobs repository repoListActionRunJobs
+	// ---
+	// summary: List the jobs of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunJobList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	jobs, err := actions_model.GetRunJobs(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunJobs", err)
+		return
+	}
+
+	apiJobs := make([]*api.ActionRunJob, len(jobs))
+	for i := range jobs {
+		apiJobs[i] = convert.ToActionRunJob(jobs[i])
+	}
+	ctx.JSON(http.StatusOK, apiJobs)
+}
+
+// ListActionRunArtifacts list the artifacts uploaded by the jobs of a workflow run
+func ListActionRunArtifacts(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runs/{index}/artifacts repository repoListActionRunArtifacts
+	// ---
+	// summary: List the artifacts uploaded by the jobs of a workflow run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionArtifactList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	artifacts, err := actions_model.GetRunArtifacts(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRunArtifacts", err)
+		return
+	}
+
+	apiArtifacts := make([]*api.ActionArtifact, len(artifacts))
+	for i := range artifacts {
+		apiArtifacts[i] = convert.ToActionArtifact(run, artifacts[i])
+	}
+	ctx.JSON(http.StatusOK, apiArtifacts)
+}
+
+// GetActionJobLogs get the log of a job
+func GetActionJobLogs(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/jobs/{id}/logs repository repoGetActionJobLogs
+	// ---
+	// summary: Get the log of a job
+	// produces:
+	// - text/plain
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: offset
+	//   in: query
+	//   description: number of lines to skip
+	//   type: integer
+	// responses:
+	//   "200":
+	//     description: the log lines of the job
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	job, err := actions_model.GetJobByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if actions_model.IsErrJobNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetJobByID", err)
+		}
+		return
+	}
+	if job.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+
+	lines, err := actions_model.ReadJobLogs(ctx, job.ID, ctx.FormInt64("offset"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ReadJobLogs", err)
+		return
+	}
+	ctx.PlainText(http.StatusOK, strings.Join(lines, "\n"))
+}
+
+// ListRunners list the runners of a repository
+func ListRunners(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runners repository repoListRunners
+	// ---
+	// summary: List the runners registered for a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunnerList"
+
+	utils.ListRunners(ctx, 0, ctx.Repo.Repository.ID)
+}
+
+// GetRunnerRegistrationToken get the runner registration token of a repository
+func GetRunnerRegistrationToken(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/actions/runners/registration_token repository repoGetRunnerRegistrationToken
+	// ---
+	// summary: Get the token to register runners for a repository, it is created if there is none
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ActionRunnerRegistrationToken"
+
+	utils.GetRunnerRegistrationToken(ctx, 0, ctx.Repo.Repository.ID)
+}
+
+// ResetRunnerRegistrationToken replace the runner registration token of a repository
+func ResetRunnerRegistrationToken(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/actions/runners/registration_token repository repoResetRunnerRegistrationToken
+	// ---
+	// summary: Replace the token to register runners for a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/ActionRunnerRegistrationToken"
+
+	utils.ResetRunnerRegistrationToken(ctx, 0, ctx.Repo.Repository.ID)
+}
+
+// DeleteRunner delete a runner of a repository
+func DeleteRunner(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/actions/runners/{id} repository repoDeleteRunner
+	// ---
+	// summary: Delete a runner of a repository, the jobs it is running are failed
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the runner
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteRunner(ctx, 0, ctx.Repo.Repository.ID)
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListAuditLog list the security relevant changes of a repository
+func ListAuditLog(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/audit repository repoListAuditLog
+	// ---
+	// summary: List the security relevant changes of a repository, newest first
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: action
+	//   in: query
+	//   description: filter by an action like `webhook.add` or a category of actions like `webhook`
+	//   type: string
+	// - name: doer
+	//   in: query
+	//   description: filter by the username of the user who made the changes
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only show changes made after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only show changes made before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoAuditLogList"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	before, since, err := context.GetQueryBeforeSince(ctx.Context)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+
+	opts := &repo_model.FindAuditLogsOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		RepoID:      ctx.Repo.Repository.ID,
+		Action:      ctx.FormTrim("action"),
+		Since:       timeutil.TimeStamp(since),
+		Before:      timeutil.TimeStamp(before),
+	}
+	if doerName := ctx.FormTrim("doer"); doerName != "" {
+		doer, err := user_model.GetUserByName(ctx, doerName)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		opts.DoerID = doer.ID
+	}
+
+	logs, total, err := repo_model.FindAuditLogs(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindAuditLogs", err)
+		return
+	}
+
+	apiLogs := make([]*api.RepoAuditLog, len(logs))
+	for i, entry := range logs {
+		apiLogs[i] = convert.ToRepoAuditLog(entry, ctx.Doer)
+	}
+
+	ctx.SetLinkHeader(int(total), opts.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, apiLogs)
+}
@@ -5,9 +5,14 @@
 package repo
 
 import (
+	"fmt"
 	"net/http"
 
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
 	files_service "code.gitea.io/gitea/services/repository/files"
 )
 
@@ -52,3 +57,75 @@ func GetBlob(ctx *context.APIContext) {
 		ctx.JSON(http.StatusOK, blob)
 	}
 }
+
+// GetBlobs get multiple blobs of a repository at once
+func GetBlobs(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/blobs repository GetBlobs
+	// ---
+	// summary: Gets multiple blobs of a repository by their SHAs or by their paths at a ref
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/GetGitBlobsOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/GitBlobsResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.GetGitBlobsOptions)
+	commit, ok := getBatchCommit(ctx, form.Ref, form.SHAs, form.Paths)
+	if !ok {
+		return
+	}
+
+	blobs, err := files_service.GetBlobs(ctx, ctx.Repo.Repository, ctx.Repo.GitRepo, commit, form.SHAs, form.Paths)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBlobs", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, blobs)
+}
+
+// getBatchCommit checks the number of items of a batch request and returns the commit of the ref the paths
+// are looked up at, which is nil if there are no paths. It responds with an error if it fails.
+func getBatchCommit(ctx *context.APIContext, ref string, shas, paths []string) (*git.Commit, bool) {
+	if len(shas)+len(paths) > setting.API.MaxBatchItems {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("at most %d SHAs and paths can be requested at once", setting.API.MaxBatchItems))
+		return nil, false
+	}
+	if len(paths) == 0 {
+		return nil, true
+	}
+
+	if ref == "" {
+		ref = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(ref)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound("GetCommit", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return nil, false
+	}
+	return commit, true
+}
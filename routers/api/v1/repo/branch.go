@@ -12,6 +12,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
@@ -70,7 +71,7 @@ func GetBranch(ctx *context.APIContext) {
 		return
 	}
 
-	branchProtection, err := models.GetProtectedBranchBy(ctx, ctx.Repo.Repository.ID, branchName)
+	branchProtection, err := models.GetEffectiveProtectedBranch(ctx, ctx.Repo.Repository.ID, branchName)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetBranchProtection", err)
 		return
@@ -206,7 +207,7 @@ func CreateBranch(ctx *context.APIContext) {
 		return
 	}
 
-	branchProtection, err := models.GetProtectedBranchBy(ctx, ctx.Repo.Repository.ID, branch.Name)
+	branchProtection, err := models.GetEffectiveProtectedBranch(ctx, ctx.Repo.Repository.ID, branch.Name)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, "GetBranchProtection", err)
 		return
@@ -271,7 +272,7 @@ func ListBranches(ctx *context.APIContext) {
 			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
 			return
 		}
-		branchProtection, err := models.GetProtectedBranchBy(ctx, ctx.Repo.Repository.ID, branches[i].Name)
+		branchProtection, err := models.GetEffectiveProtectedBranch(ctx, ctx.Repo.Repository.ID, branches[i].Name)
 		if err != nil {
 			ctx.Error(http.StatusInternalServerError, "GetBranchProtection", err)
 			return
@@ -289,6 +290,121 @@ func ListBranches(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, &apiBranches)
 }
 
+// ListDeletedBranches list the deleted branches of a repository which can still be restored
+func ListDeletedBranches(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/deleted_branches repository repoListDeletedBranches
+	// ---
+	// summary: List a repository's deleted branches which can still be restored
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeletedBranchList"
+
+	deletedBranches, err := models.GetDeletedBranches(ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDeletedBranches", err)
+		return
+	}
+
+	apiDeletedBranches := make([]*api.DeletedBranch, 0, len(deletedBranches))
+	for _, deletedBranch := range deletedBranches {
+		apiDeletedBranches = append(apiDeletedBranches, convert.ToDeletedBranch(deletedBranch, ctx.Doer))
+	}
+
+	ctx.SetTotalCountHeader(int64(len(apiDeletedBranches)))
+	ctx.JSON(http.StatusOK, &apiDeletedBranches)
+}
+
+// RestoreDeletedBranch restores a deleted branch of a repository
+func RestoreDeletedBranch(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/deleted_branches/{id}/restore repository repoRestoreDeletedBranch
+	// ---
+	// summary: Restore a deleted branch
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the deleted branch
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Branch"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     description: A branch with the same name already exists.
+
+	deletedBranch, err := models.GetDeletedBranchByID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetDeletedBranchByID", err)
+		return
+	}
+	if deletedBranch == nil {
+		ctx.NotFound()
+		return
+	}
+
+	if err := repo_service.RestoreBranch(ctx, ctx.Doer, ctx.Repo.Repository, deletedBranch); err != nil {
+		if models.IsErrBranchAlreadyExists(err) || git.IsErrPushOutOfDate(err) {
+			ctx.Error(http.StatusConflict, "", "The branch already exists.")
+		} else {
+			ctx.Error(http.StatusInternalServerError, "RestoreBranch", err)
+		}
+		return
+	}
+
+	branch, err := ctx.Repo.GitRepo.GetBranch(deletedBranch.Name)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBranch", err)
+		return
+	}
+
+	commit, err := branch.GetCommit()
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		return
+	}
+
+	branchProtection, err := models.GetEffectiveProtectedBranch(ctx, ctx.Repo.Repository.ID, branch.Name)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetBranchProtection", err)
+		return
+	}
+
+	br, err := convert.ToBranch(ctx.Repo.Repository, branch, commit, branchProtection, ctx.Doer, ctx.Repo.IsAdmin())
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "convert.ToBranch", err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, br)
+}
+
 // GetBranchProtection gets a branch protection
 func GetBranchProtection(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/branch_protections/{name} repository repoGetBranchProtection
@@ -420,6 +536,9 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusForbidden, "Create branch protection", "Branch protection already exist")
 		return
 	}
+	if !checkOrgProtectedBranchOverride(ctx, form.BranchName) {
+		return
+	}
 
 	var requiredApprovals int64
 	if form.RequiredApprovals > 0 {
@@ -517,6 +636,8 @@ func CreateBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionBranchProtectionAdd, protectBranch.BranchName, "")
+
 	if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
 		ctx.Error(http.StatusInternalServerError, "CheckPrsForBaseBranch", err)
 		return
@@ -568,6 +689,8 @@ func EditBranchProtection(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/BranchProtection"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
 	//   "422":
@@ -584,6 +707,9 @@ func EditBranchProtection(ctx *context.APIContext) {
 		ctx.NotFound()
 		return
 	}
+	if !checkOrgProtectedBranchOverride(ctx, bpName) {
+		return
+	}
 
 	if form.EnablePush != nil {
 		if !*form.EnablePush {
@@ -752,6 +878,8 @@ func EditBranchProtection(ctx *context.APIContext) {
 		return
 	}
 
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionBranchProtectionUpdate, protectBranch.BranchName, "")
+
 	if err = pull_service.CheckPrsForBaseBranch(ctx.Repo.Repository, protectBranch.BranchName); err != nil {
 		ctx.Error(http.StatusInternalServerError, "CheckPrsForBaseBranch", err)
 		return
@@ -771,6 +899,21 @@ func EditBranchProtection(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, convert.ToBranchProtection(bp))
 }
 
+// checkOrgProtectedBranchOverride returns false and responds with an error if the branch inherits
+// protection rules of the organization which can not be overridden by the repository
+func checkOrgProtectedBranchOverride(ctx *context.APIContext, branchName string) bool {
+	rule, err := models.GetOrgProtectedBranchFor(ctx, ctx.Repo.Repository, branchName)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrgProtectedBranchFor", err)
+		return false
+	}
+	if rule != nil && !rule.AllowOverride {
+		ctx.Error(http.StatusForbidden, "", "Branch protection is enforced by the organization")
+		return false
+	}
+	return true
+}
+
 // DeleteBranchProtection deletes a branch protection for a repo
 func DeleteBranchProtection(ctx *context.APIContext) {
 	// swagger:operation DELETE /repos/{owner}/{repo}/branch_protections/{name} repository repoDeleteBranchProtection
@@ -816,6 +959,7 @@ func DeleteBranchProtection(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteProtectedBranch", err)
 		return
 	}
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionBranchProtectionRemove, bp.BranchName, "")
 
 	ctx.Status(http.StatusNoContent)
 }
@@ -0,0 +1,450 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+// maxCheckRunAnnotations is the maximum number of annotations accepted per request
+const maxCheckRunAnnotations = 50
+
+// toCheckRunAnnotations converts the annotations of a check run output, it returns false if there are too many
+func toCheckRunAnnotations(ctx *context.APIContext, output *api.CheckRunOutputOption) ([]*models.CheckRunAnnotation, bool) {
+	if output == nil {
+		return nil, true
+	}
+	if len(output.Annotations) > maxCheckRunAnnotations {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("at most %d annotations can be added per request", maxCheckRunAnnotations))
+		return nil, false
+	}
+	annotations := make([]*models.CheckRunAnnotation, 0, len(output.Annotations))
+	for _, a := range output.Annotations {
+		annotations = append(annotations, &models.CheckRunAnnotation{
+			Path:      a.Path,
+			StartLine: a.StartLine,
+			EndLine:   a.EndLine,
+			Level:     models.CheckRunAnnotationLevel(a.AnnotationLevel),
+			Title:     a.Title,
+			Message:   a.Message,
+		})
+	}
+	return annotations, true
+}
+
+// respondCheckRun writes the check run with its number of annotations
+func respondCheckRun(ctx *context.APIContext, status int, run *models.CheckRun) {
+	if err := run.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+	count, err := models.CountCheckRunAnnotations(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CountCheckRunAnnotations", err)
+		return
+	}
+	ctx.JSON(status, convert.ToCheckRun(run, count, ctx.Doer))
+}
+
+// getCheckRun returns the check run of the repository identified by the `id` parameter
+func getCheckRun(ctx *context.APIContext) *models.CheckRun {
+	run, err := models.GetCheckRunByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCheckRunNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCheckRunByID", err)
+		}
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	return run
+}
+
+// CreateCheckRun creates a check run for a commit
+func CreateCheckRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/check_runs repository repoCreateCheckRun
+	// ---
+	// summary: Create a check run for a commit
+	// description: The check run is reported as commit status with the name of the check as context,
+	//   so branch protection can require it.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateCheckRunOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CheckRun"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateCheckRunOption)
+	annotations, ok := toCheckRunAnnotations(ctx, form.Output)
+	if !ok {
+		return
+	}
+
+	run := &models.CheckRun{
+		SHA:        form.HeadSHA,
+		Name:       form.Name,
+		Status:     models.CheckRunStatus(form.Status),
+		Conclusion: models.CheckRunConclusion(form.Conclusion),
+		DetailsURL: form.DetailsURL,
+		ExternalID: form.ExternalID,
+	}
+	if form.Output != nil {
+		run.Title = form.Output.Title
+		run.Summary = form.Output.Summary
+		run.Text = form.Output.Text
+	}
+
+	if err := files_service.CreateCheckRun(ctx, ctx.Repo.Repository, ctx.Doer, run, annotations); err != nil {
+		if models.IsErrInvalidCheckRun(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateCheckRun", err)
+		}
+		return
+	}
+
+	respondCheckRun(ctx, http.StatusCreated, run)
+}
+
+// GetCheckRun gets a check run of a repository
+func GetCheckRun(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/check_runs/{id} repository repoGetCheckRun
+	// ---
+	// summary: Get a check run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRun"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	respondCheckRun(ctx, http.StatusOK, run)
+}
+
+// EditCheckRun updates a check run, the annotations of the output are appended
+func EditCheckRun(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/check_runs/{id} repository repoEditCheckRun
+	// ---
+	// summary: Update a check run
+	// description: Annotations of the output are appended to the annotations already reported.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditCheckRunOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRun"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditCheckRunOption)
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	annotations, ok := toCheckRunAnnotations(ctx, form.Output)
+	if !ok {
+		return
+	}
+
+	var cols []string
+	if form.Name != nil && *form.Name != "" {
+		run.Name = *form.Name
+		cols = append(cols, "name")
+	}
+	if form.Status != nil {
+		run.Status = models.CheckRunStatus(*form.Status)
+		if run.Status != models.CheckRunStatusCompleted {
+			run.Conclusion = models.CheckRunConclusionNone
+		}
+	}
+	if form.Conclusion != nil {
+		run.Conclusion = models.CheckRunConclusion(*form.Conclusion)
+	}
+	if form.DetailsURL != nil {
+		run.DetailsURL = *form.DetailsURL
+		cols = append(cols, "details_url")
+	}
+	if form.ExternalID != nil {
+		run.ExternalID = *form.ExternalID
+		cols = append(cols, "external_id")
+	}
+	if form.Output != nil {
+		run.Title = form.Output.Title
+		run.Summary = form.Output.Summary
+		run.Text = form.Output.Text
+		cols = append(cols, "title", "summary", "text")
+	}
+
+	if err := files_service.UpdateCheckRun(ctx, ctx.Repo.Repository, ctx.Doer, run, annotations, cols...); err != nil {
+		if models.IsErrInvalidCheckRun(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateCheckRun", err)
+		}
+		return
+	}
+
+	respondCheckRun(ctx, http.StatusOK, run)
+}
+
+// ListCheckRunAnnotations lists the annotations of a check run
+func ListCheckRunAnnotations(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/check_runs/{id}/annotations repository repoListCheckRunAnnotations
+	// ---
+	// summary: List the annotations of a check run
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRunAnnotationList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	annotations, err := models.GetCheckRunAnnotations(ctx, run.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCheckRunAnnotations", err)
+		return
+	}
+	apiAnnotations := make([]*api.CheckRunAnnotation, len(annotations))
+	for i, annotation := range annotations {
+		apiAnnotations[i] = convert.ToCheckRunAnnotation(annotation)
+	}
+	ctx.JSON(http.StatusOK, apiAnnotations)
+}
+
+// RerequestCheckRun queues a check run for another attempt
+func RerequestCheckRun(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/check_runs/{id}/rerequest repository repoRerequestCheckRun
+	// ---
+	// summary: Request another attempt of a check run
+	// description: The check run is queued again and its conclusion, output and annotations are cleared.
+	//   The service reporting the check is expected to pick up queued check runs.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the check run
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRun"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := files_service.RerequestCheckRun(ctx, ctx.Repo.Repository, ctx.Doer, run); err != nil {
+		ctx.Error(http.StatusInternalServerError, "RerequestCheckRun", err)
+		return
+	}
+
+	respondCheckRun(ctx, http.StatusOK, run)
+}
+
+// ListCheckRunsByRef lists the check runs of a commit
+func ListCheckRunsByRef(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/commits/{ref}/check_runs repository repoListCheckRunsByRef
+	// ---
+	// summary: List the check runs of a commit, by branch/tag/commit reference
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: ref
+	//   in: path
+	//   description: name of branch/tag/commit
+	//   type: string
+	//   required: true
+	// - name: check_name
+	//   in: query
+	//   description: name of the checks to return
+	//   type: string
+	// - name: status
+	//   in: query
+	//   description: status of the checks to return
+	//   type: string
+	//   enum: [queued, in_progress, completed]
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CheckRunList"
+	//   "400":
+	//     "$ref": "#/responses/error"
+
+	sha := utils.ResolveRefOrSha(ctx, ctx.Params("ref"))
+	if ctx.Written() {
+		return
+	}
+	if len(sha) == 0 {
+		ctx.Error(http.StatusBadRequest, "ref/sha not given", nil)
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	runs, total, err := models.FindCheckRuns(ctx, &models.FindCheckRunsOptions{
+		ListOptions: listOptions,
+		RepoID:      ctx.Repo.Repository.ID,
+		SHA:         sha,
+		Name:        ctx.FormTrim("check_name"),
+		Status:      models.CheckRunStatus(ctx.FormTrim("status")),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindCheckRuns", err)
+		return
+	}
+
+	apiRuns := make([]*api.CheckRun, len(runs))
+	for i, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		if err := run.LoadAttributes(ctx); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		count, err := models.CountCheckRunAnnotations(ctx, run.ID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "CountCheckRunAnnotations", err)
+			return
+		}
+		apiRuns[i] = convert.ToCheckRun(run, count, ctx.Doer)
+	}
+
+	ctx.SetLinkHeader(int(total), listOptions.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, apiRuns)
+}
@@ -0,0 +1,286 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	pull_service "code.gitea.io/gitea/services/pull"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+// CherryPickCommit handles API call for cherry-picking a commit onto a branch
+func CherryPickCommit(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/cherry-pick repository repoCherryPickCommit
+	// ---
+	// summary: Cherry-pick a commit onto a branch
+	// description: If the user can't push to `branch`, the commit is created on a new branch and a pull request into `branch` is opened.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CherryPickCommitOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CherryPickCommitResponse"
+	//   "403":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/error"
+	cherryPickOrRevert(ctx, false)
+}
+
+// RevertCommit handles API call for reverting a commit on a branch
+func RevertCommit(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/revert repository repoRevertCommit
+	// ---
+	// summary: Revert a commit on a branch
+	// description: If the user can't push to `branch`, the commit is created on a new branch and a pull request into `branch` is opened.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CherryPickCommitOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/CherryPickCommitResponse"
+	//   "403":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/error"
+	cherryPickOrRevert(ctx, true)
+}
+
+func cherryPickOrRevert(ctx *context.APIContext, revert bool) {
+	form := web.GetForm(ctx).(*api.CherryPickCommitOptions)
+	repo := ctx.Repo.Repository
+	if repo.IsEmpty {
+		ctx.Error(http.StatusUnprocessableEntity, "RepoIsEmpty", fmt.Errorf("repo is empty"))
+		return
+	}
+
+	commit, err := ctx.Repo.GitRepo.GetCommit(form.SHA)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		}
+		return
+	}
+	sha := commit.ID.String()
+
+	branch := form.BranchName
+	if branch == "" {
+		branch = repo.DefaultBranch
+	}
+	baseCommitID, err := ctx.Repo.GitRepo.GetBranchCommitID(branch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.Error(http.StatusNotFound, "BranchDoesNotExist", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBranchCommitID", err)
+		}
+		return
+	}
+
+	newBranch := form.NewBranchName
+	createPullRequest := form.CreatePullRequest
+	if newBranch == "" || newBranch == branch {
+		if createPullRequest {
+			ctx.Error(http.StatusUnprocessableEntity, "", "a pull request needs a new branch")
+			return
+		}
+		// the commit goes to a new branch and is proposed by a pull request if the user can't push to the branch
+		if !canWriteFiles(ctx, branch) {
+			newBranch = uniqueCherryPickBranchName(ctx, sha, revert)
+			createPullRequest = true
+		}
+	}
+	if createPullRequest && !repo.UnitEnabled(unit.TypePullRequests) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "pull requests are disabled")
+		return
+	}
+
+	message := strings.TrimSpace(form.Message)
+	if message == "" {
+		if revert {
+			message = fmt.Sprintf("Revert: %s", sha)
+		} else {
+			message = fmt.Sprintf("Cherry-pick: %s", sha)
+		}
+	}
+
+	opts := &files_service.ApplyDiffPatchOptions{
+		LastCommitID: baseCommitID,
+		OldBranch:    branch,
+		NewBranch:    newBranch,
+		Message:      message,
+		Committer: &files_service.IdentityOptions{
+			Name:  form.Committer.Name,
+			Email: form.Committer.Email,
+		},
+		Author: &files_service.IdentityOptions{
+			Name:  form.Author.Name,
+			Email: form.Author.Email,
+		},
+		Dates: &files_service.CommitDateOptions{
+			Author:    form.Dates.Author,
+			Committer: form.Dates.Committer,
+		},
+		Signoff: form.Signoff,
+	}
+	if opts.Dates.Author.IsZero() {
+		opts.Dates.Author = time.Now()
+	}
+	if opts.Dates.Committer.IsZero() {
+		opts.Dates.Committer = time.Now()
+	}
+
+	fileResponse, err := applyCherryPick(ctx, sha, revert, opts)
+	if err != nil {
+		switch {
+		case models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) ||
+			models.IsErrFilePathLocked(err) || models.IsErrFilePathRequiresPullRequest(err):
+			ctx.Error(http.StatusForbidden, "Access", err)
+		case models.IsErrBranchAlreadyExists(err):
+			ctx.Error(http.StatusUnprocessableEntity, "Invalid", err)
+		case models.IsErrCherryPickConflict(err) || models.IsErrCommitIDDoesNotMatch(err):
+			ctx.Error(http.StatusConflict, "Conflict", err)
+		case git.IsErrBranchNotExist(err):
+			ctx.Error(http.StatusNotFound, "BranchDoesNotExist", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CherryPick", err)
+		}
+		return
+	}
+
+	result := &api.CherryPickCommitResponse{
+		Commit:       fileResponse.Commit,
+		Verification: fileResponse.Verification,
+		Branch:       opts.NewBranch,
+	}
+	if createPullRequest {
+		prIssue := &models.Issue{
+			RepoID:   repo.ID,
+			Title:    strings.SplitN(message, "\n", 2)[0],
+			PosterID: ctx.Doer.ID,
+			Poster:   ctx.Doer,
+			IsPull:   true,
+			Content:  message,
+		}
+		pr := &models.PullRequest{
+			HeadRepoID: repo.ID,
+			BaseRepoID: repo.ID,
+			HeadBranch: opts.NewBranch,
+			BaseBranch: branch,
+			HeadRepo:   repo,
+			BaseRepo:   repo,
+			MergeBase:  baseCommitID,
+			Type:       models.PullRequestGitea,
+		}
+		if err := pull_service.NewPullRequest(ctx, repo, prIssue, nil, nil, pr, nil); err != nil {
+			ctx.Error(http.StatusInternalServerError, "NewPullRequest", err)
+			return
+		}
+		log.Trace("Pull request created: %d/%d", repo.ID, prIssue.ID)
+		result.PullRequest = convert.ToAPIPullRequest(ctx, pr, ctx.Doer)
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+// applyCherryPick cherry-picks or reverts the commit with a three-way merge like the web editor,
+// and falls back to applying its diff if the merge has conflicts
+func applyCherryPick(ctx *context.APIContext, sha string, revert bool, opts *files_service.ApplyDiffPatchOptions) (*api.FileResponse, error) {
+	opts.Content = sha
+	fileResponse, err := files_service.CherryPick(ctx, ctx.Repo.Repository, ctx.Doer, revert, opts)
+	if err == nil || !models.IsErrCherryPickConflict(err) {
+		return fileResponse, err
+	}
+	conflictErr := err
+
+	buf := &bytes.Buffer{}
+	if revert {
+		err = git.GetReverseRawDiff(ctx, ctx.Repo.Repository.RepoPath(), sha, buf)
+	} else {
+		err = git.GetRawDiff(ctx.Repo.GitRepo, sha, git.RawDiffPatch, buf)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	opts.Content = buf.String()
+	fileResponse, err = files_service.ApplyDiffPatch(ctx, ctx.Repo.Repository, ctx.Doer, opts)
+	if err != nil {
+		log.Debug("ApplyDiffPatch of %s: %v", sha, err)
+		return nil, conflictErr
+	}
+	return fileResponse, nil
+}
+
+// uniqueCherryPickBranchName returns the name of a branch which doesn't exist yet for the new commit
+func uniqueCherryPickBranchName(ctx *context.APIContext, sha string, revert bool) string {
+	prefix := "cherry-pick-"
+	if revert {
+		prefix = "revert-"
+	}
+	name := prefix + base.ShortSha(sha)
+	for i := 1; ctx.Repo.GitRepo.IsBranchExist(name); i++ {
+		name = fmt.Sprintf("%s%s-%d", prefix, base.ShortSha(sha), i)
+	}
+	return name
+}
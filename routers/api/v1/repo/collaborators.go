@@ -7,9 +7,12 @@ package repo
 
 import (
 	"errors"
+	"fmt"
 	"net/http"
+	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
@@ -17,8 +20,10 @@ import (
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // ListCollaborators list a repository's collaborators
@@ -72,6 +77,114 @@ func ListCollaborators(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, users)
 }
 
+// SetCollaborators replaces all collaborators of a repository
+func SetCollaborators(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/collaborators repository repoSetCollaborators
+	// ---
+	// summary: Replace all collaborators of a repository
+	// description: The collaborators who aren't listed are removed, the missing ones are added and the permissions
+	//              of the others are changed. Nothing is changed if the list is invalid.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetCollaboratorsOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CollaboratorChanges"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.SetCollaboratorsOption)
+
+	accesses := make([]*models.CollaboratorAccess, 0, len(form.Collaborators))
+	seen := make(map[int64]bool, len(form.Collaborators))
+	for _, opt := range form.Collaborators {
+		collaborator, err := user_model.GetUserByName(ctx, opt.Username)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			}
+			return
+		}
+		if collaborator.IsOrganization() || collaborator.ID == ctx.Repo.Repository.OwnerID || !collaborator.IsActive {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("user %s can't be a collaborator of the repository", collaborator.Name))
+			return
+		}
+		if seen[collaborator.ID] {
+			ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("user %s is listed more than once", collaborator.Name))
+			return
+		}
+		seen[collaborator.ID] = true
+
+		// an omitted permission is resolved by SetCollaborators
+		mode := perm.AccessModeNone
+		if opt.Permission != "" {
+			mode = perm.ParseAccessMode(opt.Permission)
+			if mode == perm.AccessModeNone {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("invalid permission %q of user %s", opt.Permission, collaborator.Name))
+				return
+			}
+		}
+		accesses = append(accesses, &models.CollaboratorAccess{User: collaborator, Mode: mode})
+	}
+
+	changes, err := models.SetCollaborators(ctx, ctx.Repo.Repository, accesses)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetCollaborators", err)
+		return
+	}
+
+	report := &api.CollaboratorChanges{
+		Added:     []*api.CollaboratorChange{},
+		Updated:   []*api.CollaboratorChange{},
+		Removed:   []*api.CollaboratorChange{},
+		Unchanged: []*api.CollaboratorChange{},
+	}
+	for _, change := range changes {
+		apiChange := &api.CollaboratorChange{User: convert.ToUser(change.User, ctx.Doer)}
+		if change.OldMode != perm.AccessModeNone {
+			apiChange.OldPermission = change.OldMode.String()
+		}
+		if change.NewMode != perm.AccessModeNone {
+			apiChange.NewPermission = change.NewMode.String()
+		}
+
+		switch {
+		case change.OldMode == perm.AccessModeNone:
+			report.Added = append(report.Added, apiChange)
+			repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionCollaboratorAdd, change.User.Name, "access: "+apiChange.NewPermission)
+		case change.NewMode == perm.AccessModeNone:
+			report.Removed = append(report.Removed, apiChange)
+			repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionCollaboratorRemove, change.User.Name, "")
+		case change.OldMode != change.NewMode:
+			report.Updated = append(report.Updated, apiChange)
+			repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionCollaboratorUpdate, change.User.Name, "access: "+apiChange.NewPermission)
+		default:
+			report.Unchanged = append(report.Unchanged, apiChange)
+		}
+	}
+	ctx.JSON(http.StatusOK, report)
+}
+
 // IsCollaborator check if a user is a collaborator of a repository
 func IsCollaborator(ctx *context.APIContext) {
 	// swagger:operation GET /repos/{owner}/{repo}/collaborators/{collaborator} repository repoCheckCollaborator
@@ -174,6 +287,19 @@ func AddCollaborator(ctx *context.APIContext) {
 		return
 	}
 
+	expires, ok := parseAccessExpiry(ctx, form.Expires)
+	if !ok {
+		return
+	}
+
+	auditAction := repo_model.AuditActionCollaboratorAdd
+	if isCollaborator, err := repo_model.IsCollaborator(ctx, ctx.Repo.Repository.ID, collaborator.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsCollaborator", err)
+		return
+	} else if isCollaborator {
+		auditAction = repo_model.AuditActionCollaboratorUpdate
+	}
+
 	if err := models.AddCollaborator(ctx.Repo.Repository, collaborator); err != nil {
 		ctx.Error(http.StatusInternalServerError, "AddCollaborator", err)
 		return
@@ -186,6 +312,17 @@ func AddCollaborator(ctx *context.APIContext) {
 		}
 	}
 
+	if err := repo_model.SetCollaborationExpiry(ctx, ctx.Repo.Repository.ID, collaborator.ID, expires, ctx.Doer.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SetCollaborationExpiry", err)
+		return
+	}
+
+	var detail string
+	if form.Permission != nil {
+		detail = "access: " + perm.ParseAccessMode(*form.Permission).String()
+	}
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, auditAction, collaborator.Name, detail)
+
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -232,6 +369,7 @@ func DeleteCollaborator(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteCollaboration", err)
 		return
 	}
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionCollaboratorRemove, collaborator.Name, "")
 	ctx.Status(http.StatusNoContent)
 }
 
@@ -287,7 +425,93 @@ func GetRepoPermissions(ctx *context.APIContext) {
 		return
 	}
 
-	ctx.JSON(http.StatusOK, convert.ToUserAndPermission(collaborator, ctx.ContextUser, permission.AccessMode))
+	collaboration, err := repo_model.GetCollaboration(ctx, ctx.Repo.Repository.ID, collaborator.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCollaboration", err)
+		return
+	}
+
+	apiPermission := convert.ToUserAndPermission(collaborator, ctx.ContextUser, permission.AccessMode)
+	if collaboration != nil && collaboration.ExpiresUnix > 0 {
+		apiPermission.Expires = collaboration.ExpiresUnix.AsTimePtr()
+	}
+	ctx.JSON(http.StatusOK, apiPermission)
+}
+
+// ListTemporaryAccess list the collaborators and teams whose access to a repository expires
+func ListTemporaryAccess(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/temporary_access repository repoListTemporaryAccess
+	// ---
+	// summary: List the collaborators and teams whose access to a repository expires
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TemporaryAccessList"
+
+	collaborations, err := repo_model.FindExpiringCollaborations(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindExpiringCollaborations", err)
+		return
+	}
+	teamRepos, err := organization.FindExpiringTeamRepos(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindExpiringTeamRepos", err)
+		return
+	}
+
+	accesses := make([]*api.TemporaryAccess, 0, len(collaborations)+len(teamRepos))
+	for _, collaboration := range collaborations {
+		user, err := user_model.GetUserByIDCtx(ctx, collaboration.UserID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserByID", err)
+			return
+		}
+		accesses = append(accesses, &api.TemporaryAccess{
+			User:    convert.ToUser(user, ctx.Doer),
+			Expires: collaboration.ExpiresUnix.AsTime(),
+		})
+	}
+	for _, teamRepo := range teamRepos {
+		team, err := organization.GetTeamByID(ctx, teamRepo.TeamID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetTeamByID", err)
+			return
+		}
+		apiTeam, err := convert.ToTeam(team)
+		if err != nil {
+			ctx.InternalServerError(err)
+			return
+		}
+		accesses = append(accesses, &api.TemporaryAccess{
+			Team:    apiTeam,
+			Expires: teamRepo.ExpiresUnix.AsTime(),
+		})
+	}
+	ctx.JSON(http.StatusOK, accesses)
+}
+
+// parseAccessExpiry validates the optional expiry of a temporary access, it has to be in the future
+func parseAccessExpiry(ctx *context.APIContext, expires *time.Time) (timeutil.TimeStamp, bool) {
+	if expires == nil {
+		return 0, true
+	}
+	if !expires.After(time.Now()) {
+		ctx.Error(http.StatusUnprocessableEntity, "", errors.New("expiry must be in the future"))
+		return 0, false
+	}
+	return timeutil.TimeStamp(expires.Unix()), true
 }
 
 // GetReviewers return all users that can be requested to review in this repo
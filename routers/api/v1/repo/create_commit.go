@@ -0,0 +1,143 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+// CreateCommit handles API call for creating a commit from a set of file changes
+func CreateCommit(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/commits repository repoCreateCommit
+	// ---
+	// summary: Create a commit from a set of file changes and point a branch to it
+	// description: The branch is created if it doesn't exist, otherwise it must point to the base commit.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateCommitOptions"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/FileResponse"
+	//   "403":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/error"
+	apiOpts := web.GetForm(ctx).(*api.CreateCommitOptions)
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.Error(http.StatusUnprocessableEntity, "RepoIsEmpty", fmt.Errorf("repo is empty"))
+		return
+	}
+
+	if apiOpts.BranchName == "" {
+		apiOpts.BranchName = ctx.Repo.Repository.DefaultBranch
+	}
+	if !canWriteFiles(ctx, apiOpts.BranchName) {
+		ctx.Error(http.StatusForbidden, "Access", models.ErrUserDoesNotHaveAccessToRepo{
+			UserID:   ctx.Doer.ID,
+			RepoName: ctx.Repo.Repository.LowerName,
+		})
+		return
+	}
+	if apiOpts.Signature != "" && (apiOpts.Dates.Author.IsZero() || apiOpts.Dates.Committer.IsZero()) {
+		ctx.Error(http.StatusUnprocessableEntity, "Signature", fmt.Errorf("the dates of a signed commit are required"))
+		return
+	}
+
+	opts := &files_service.CreateCommitOptions{
+		BaseCommitID: apiOpts.Base,
+		Branch:       apiOpts.BranchName,
+		Message:      apiOpts.Message,
+		Files:        make([]*files_service.FileChange, 0, len(apiOpts.Files)),
+		Committer: &files_service.IdentityOptions{
+			Name:  apiOpts.Committer.Name,
+			Email: apiOpts.Committer.Email,
+		},
+		Author: &files_service.IdentityOptions{
+			Name:  apiOpts.Author.Name,
+			Email: apiOpts.Author.Email,
+		},
+		Dates: &files_service.CommitDateOptions{
+			Author:    apiOpts.Dates.Author,
+			Committer: apiOpts.Dates.Committer,
+		},
+		Signoff:   apiOpts.Signoff,
+		Signature: apiOpts.Signature,
+	}
+	if opts.Dates.Author.IsZero() {
+		opts.Dates.Author = time.Now()
+	}
+	if opts.Dates.Committer.IsZero() {
+		opts.Dates.Committer = time.Now()
+	}
+
+	for _, file := range apiOpts.Files {
+		content, err := base64.StdEncoding.DecodeString(file.Content)
+		if err != nil {
+			ctx.Error(http.StatusUnprocessableEntity, "Content", fmt.Errorf("invalid content of %s: %v", file.Path, err))
+			return
+		}
+		opts.Files = append(opts.Files, &files_service.FileChange{
+			Operation:    files_service.FileOperation(file.Operation),
+			TreePath:     file.Path,
+			FromTreePath: file.FromPath,
+			Content:      string(content),
+			SHA:          file.SHA,
+			Executable:   file.Executable,
+		})
+	}
+
+	fileResponse, err := files_service.CreateCommit(ctx, ctx.Repo.Repository, ctx.Doer, opts)
+	if err != nil {
+		switch {
+		case models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) ||
+			models.IsErrFilePathLocked(err) || models.IsErrFilePathRequiresPullRequest(err):
+			ctx.Error(http.StatusForbidden, "Access", err)
+		case git.IsErrPushRejected(err):
+			ctx.Error(http.StatusForbidden, "PushRejected", err)
+		case models.IsErrCommitIDDoesNotMatch(err) || git.IsErrPushOutOfDate(err):
+			ctx.Error(http.StatusConflict, "Conflict", err)
+		case models.IsErrFilenameInvalid(err) || models.IsErrSHADoesNotMatch(err) ||
+			models.IsErrFilePathInvalid(err) || models.IsErrRepoFileAlreadyExists(err):
+			ctx.Error(http.StatusUnprocessableEntity, "Invalid", err)
+		case git.IsErrNotExist(err):
+			ctx.NotFound(err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CreateCommit", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, fileResponse)
+}
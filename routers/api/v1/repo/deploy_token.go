@@ -0,0 +1,168 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// ListDeployTokens lists the deploy tokens of a repository
+func ListDeployTokens(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/deploy_tokens repository repoListDeployTokens
+	// ---
+	// summary: List a repository's deploy tokens
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DeployTokenList"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	tokens, err := repo_model.FindDeployTokens(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDeployTokens", err)
+		return
+	}
+
+	apiTokens := make([]*api.DeployToken, 0, len(tokens))
+	for _, t := range tokens {
+		apiTokens = append(apiTokens, convert.ToDeployToken(t))
+	}
+	ctx.JSON(http.StatusOK, apiTokens)
+}
+
+// CreateDeployToken creates a deploy token for a repository
+func CreateDeployToken(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/deploy_tokens repository repoCreateDeployToken
+	// ---
+	// summary: Create a deploy token for a repository
+	// description: The token is only returned in this response. It is sent as the password of HTTP basic
+	//   authentication to clone the repository, download its LFS objects or read the packages of its owner.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateDeployTokenOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/DeployToken"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateDeployTokenOption)
+
+	t := &repo_model.DeployToken{
+		RepoID:    ctx.Repo.Repository.ID,
+		Name:      form.Name,
+		CreatorID: ctx.Doer.ID,
+	}
+	if form.Expires != nil {
+		t.ExpiresUnix = timeutil.TimeStamp(form.Expires.Unix())
+	}
+
+	err := t.SetScopes(form.Scopes)
+	if err == nil {
+		err = repo_model.NewDeployToken(ctx, t)
+	}
+	if err != nil {
+		switch {
+		case repo_model.IsErrInvalidDeployToken(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		case repo_model.IsErrDeployTokenNameAlreadyUsed(err):
+			ctx.Error(http.StatusConflict, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "NewDeployToken", err)
+		}
+		return
+	}
+
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionDeployTokenAdd, t.Name, t.Scopes)
+	ctx.JSON(http.StatusCreated, convert.ToDeployToken(t))
+}
+
+// DeleteDeployToken deletes a deploy token of a repository
+func DeleteDeployToken(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/deploy_tokens/{id} repository repoDeleteDeployToken
+	// ---
+	// summary: Delete a deploy token of a repository
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the deploy token
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := repo_model.GetDeployTokenByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err == nil {
+		err = repo_model.DeleteDeployToken(ctx, ctx.Repo.Repository.ID, t.ID)
+	}
+	if err != nil {
+		if repo_model.IsErrDeployTokenNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteDeployToken", err)
+		}
+		return
+	}
+
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionDeployTokenRemove, t.Name, "")
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,325 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	discussion_model "code.gitea.io/gitea/models/discussion"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	discussion_service "code.gitea.io/gitea/services/discussion"
+)
+
+// ListDiscussionCategories list the discussion categories of a repository
+func ListDiscussionCategories(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/discussions/categories repository repoListDiscussionCategories
+	// ---
+	// summary: List the discussion categories of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DiscussionCategoryList"
+
+	categories, err := discussion_model.GetCategories(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCategories", err)
+		return
+	}
+
+	apiCategories := make([]*api.DiscussionCategory, len(categories))
+	for i, c := range categories {
+		apiCategories[i] = convert.ToDiscussionCategory(c)
+	}
+	ctx.JSON(http.StatusOK, apiCategories)
+}
+
+// ListDiscussions list the discussions of a repository
+func ListDiscussions(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/discussions repository repoListDiscussions
+	// ---
+	// summary: List the discussions of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: category
+	//   in: query
+	//   description: id of the category to filter by
+	//   type: integer
+	//   format: int64
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DiscussionList"
+
+	listOptions := utils.GetListOptions(ctx)
+	discussions, total, err := discussion_model.FindDiscussions(ctx, &discussion_model.FindDiscussionOptions{
+		ListOptions: listOptions,
+		RepoID:      ctx.Repo.Repository.ID,
+		CategoryID:  ctx.FormInt64("category"),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindDiscussions", err)
+		return
+	}
+
+	apiDiscussions := make([]*api.Discussion, len(discussions))
+	for i, d := range discussions {
+		d.Repo = ctx.Repo.Repository
+		if err := d.LoadAttributes(ctx); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+			return
+		}
+		apiDiscussions[i] = convert.ToDiscussion(d, ctx.Doer)
+	}
+
+	ctx.SetLinkHeader(int(total), listOptions.PageSize)
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, apiDiscussions)
+}
+
+// getDiscussion returns the discussion of the repository identified by the `index` parameter
+func getDiscussion(ctx *context.APIContext) *discussion_model.Discussion {
+	d, err := discussion_model.GetDiscussionByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if discussion_model.IsErrDiscussionNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetDiscussionByIndex", err)
+		}
+		return nil
+	}
+	d.Repo = ctx.Repo.Repository
+	if err := d.LoadAttributes(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return nil
+	}
+	return d
+}
+
+// GetDiscussion get a discussion of a repository
+func GetDiscussion(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/discussions/{index} repository repoGetDiscussion
+	// ---
+	// summary: Get a discussion of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Discussion"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToDiscussion(d, ctx.Doer))
+}
+
+// CreateDiscussion start a discussion in a repository
+func CreateDiscussion(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/discussions repository repoCreateDiscussion
+	// ---
+	// summary: Start a discussion in a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateDiscussionOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Discussion"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateDiscussionOption)
+	d := &discussion_model.Discussion{
+		Title:      form.Title,
+		Content:    form.Body,
+		CategoryID: form.CategoryID,
+	}
+	if err := discussion_service.NewDiscussion(ctx, ctx.Doer, ctx.Repo.Repository, d); err != nil {
+		if discussion_model.IsErrCategoryNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "NewDiscussion", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToDiscussion(d, ctx.Doer))
+}
+
+// ListDiscussionComments list the comments of a discussion
+func ListDiscussionComments(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/discussions/{index}/comments repository repoListDiscussionComments
+	// ---
+	// summary: List the comments of a discussion, each top level comment is followed by its replies
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/DiscussionCommentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	comments, err := discussion_model.GetComments(ctx, d.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetComments", err)
+		return
+	}
+
+	apiComments := make([]*api.DiscussionComment, 0, d.NumComments)
+	for _, c := range comments {
+		apiComments = append(apiComments, convert.ToDiscussionComment(d, c, ctx.Doer))
+		for _, r := range c.Replies {
+			apiComments = append(apiComments, convert.ToDiscussionComment(d, r, ctx.Doer))
+		}
+	}
+	ctx.JSON(http.StatusOK, apiComments)
+}
+
+// CreateDiscussionComment comment a discussion
+func CreateDiscussionComment(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/discussions/{index}/comments repository repoCreateDiscussionComment
+	// ---
+	// summary: Add a comment to a discussion, or a reply to one of its comments
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the discussion
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateDiscussionCommentOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/DiscussionComment"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateDiscussionCommentOption)
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	c, err := discussion_service.CreateComment(ctx, ctx.Doer, d, form.ParentID, form.Body)
+	if err != nil {
+		if discussion_model.IsErrCommentNotExist(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "CreateComment", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToDiscussionComment(d, c, ctx.Doer))
+}
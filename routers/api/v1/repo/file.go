@@ -10,6 +10,7 @@ import (
 	"fmt"
 	"net/http"
 	"path"
+	"strings"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -23,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/web/repo"
+	repo_service "code.gitea.io/gitea/services/repository"
 	files_service "code.gitea.io/gitea/services/repository/files"
 )
 
@@ -69,6 +71,7 @@ func GetRawFile(ctx *context.APIContext) {
 	if ctx.Written() {
 		return
 	}
+	repo_service.RecordTraffic(ctx, ctx.Repo.Repository.ID, repo_model.TrafficKindRaw, ctx.Doer, ctx.RemoteAddr())
 
 	if err := common.ServeBlob(ctx.Context, blob, lastModified); err != nil {
 		ctx.Error(http.StatusInternalServerError, "ServeBlob", err)
@@ -292,6 +295,11 @@ func CreateFile(ctx *context.APIContext) {
 	if opts.Dates.Committer.IsZero() {
 		opts.Dates.Committer = time.Now()
 	}
+	applyFileEntryOptions(opts, &apiOpts.FileEntryOptions)
+	if opts.Mode == git.EntryModeCommit && opts.SubmoduleURL == "" {
+		ctx.Error(http.StatusUnprocessableEntity, "SubmoduleGitURL", fmt.Errorf("a new submodule needs a submodule_git_url"))
+		return
+	}
 
 	if opts.Message == "" {
 		opts.Message = ctx.Tr("repo.editor.add", opts.TreePath)
@@ -381,6 +389,7 @@ func UpdateFile(ctx *context.APIContext) {
 	if opts.Dates.Committer.IsZero() {
 		opts.Dates.Committer = time.Now()
 	}
+	applyFileEntryOptions(opts, &apiOpts.FileEntryOptions)
 
 	if opts.Message == "" {
 		opts.Message = ctx.Tr("repo.editor.update", opts.TreePath)
@@ -393,8 +402,29 @@ func UpdateFile(ctx *context.APIContext) {
 	}
 }
 
+// applyFileEntryOptions sets the mode of the entry and replaces the content of symlinks and submodules
+func applyFileEntryOptions(opts *files_service.UpdateRepoFileOptions, entryOpts *api.FileEntryOptions) {
+	switch entryOpts.Type {
+	case "symlink":
+		opts.Mode = git.EntryModeSymlink
+		opts.Content = entryOpts.Target
+	case "submodule":
+		opts.Mode = git.EntryModeCommit
+		opts.Content = strings.ToLower(entryOpts.SubmoduleSHA)
+		opts.SubmoduleURL = entryOpts.SubmoduleGitURL
+	default:
+		if entryOpts.Executable != nil {
+			opts.Mode = git.EntryModeBlob
+			if *entryOpts.Executable {
+				opts.Mode = git.EntryModeExec
+			}
+		}
+	}
+}
+
 func handleCreateOrUpdateFileError(ctx *context.APIContext, err error) {
-	if models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) {
+	if models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) ||
+		models.IsErrFilePathLocked(err) || models.IsErrFilePathRequiresPullRequest(err) {
 		ctx.Error(http.StatusForbidden, "Access", err)
 		return
 	}
@@ -420,11 +450,14 @@ func createOrUpdateFile(ctx *context.APIContext, opts *files_service.UpdateRepoF
 		}
 	}
 
-	content, err := base64.StdEncoding.DecodeString(opts.Content)
-	if err != nil {
-		return nil, err
+	// the target of a symlink and the commit of a submodule aren't encoded
+	if opts.Mode != git.EntryModeSymlink && opts.Mode != git.EntryModeCommit {
+		content, err := base64.StdEncoding.DecodeString(opts.Content)
+		if err != nil {
+			return nil, err
+		}
+		opts.Content = string(content)
 	}
-	opts.Content = string(content)
 
 	return files_service.CreateOrUpdateRepoFile(ctx, ctx.Repo.Repository, ctx.Doer, opts)
 }
@@ -524,7 +557,8 @@ func DeleteFile(ctx *context.APIContext) {
 			models.IsErrSHAOrCommitIDNotProvided(err) {
 			ctx.Error(http.StatusBadRequest, "DeleteFile", err)
 			return
-		} else if models.IsErrUserCannotCommit(err) {
+		} else if models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) ||
+			models.IsErrFilePathLocked(err) || models.IsErrFilePathRequiresPullRequest(err) {
 			ctx.Error(http.StatusForbidden, "DeleteFile", err)
 			return
 		}
@@ -9,10 +9,13 @@ import (
 	"fmt"
 	"net/http"
 
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
@@ -99,7 +102,7 @@ func CreateFork(ctx *context.APIContext) {
 	//   "403":
 	//     "$ref": "#/responses/forbidden"
 	//   "409":
-	//     description: The repository with the same name already exists.
+	//     description: The repository with the same name already exists, or the owner has already forked the repository.
 	//   "422":
 	//     "$ref": "#/responses/validationError"
 
@@ -118,20 +121,27 @@ func CreateFork(ctx *context.APIContext) {
 			}
 			return
 		}
-		isMember, err := org.IsOrgMember(ctx.Doer.ID)
-		if err != nil {
-			ctx.Error(http.StatusInternalServerError, "IsOrgMember", err)
-			return
-		} else if !isMember {
-			ctx.Error(http.StatusForbidden, "isMemberNot", fmt.Sprintf("User is no Member of Organisation '%s'", org.Name))
-			return
+		if !ctx.Doer.IsAdmin {
+			canCreate, err := org.CanCreateOrgRepo(ctx.Doer.ID)
+			if err != nil {
+				ctx.Error(http.StatusInternalServerError, "CanCreateOrgRepo", err)
+				return
+			} else if !canCreate {
+				ctx.Error(http.StatusForbidden, "", fmt.Sprintf("User is not allowed to create repositories in organization '%s'", org.Name))
+				return
+			}
 		}
 		forker = org.AsUser()
 	}
 
 	var name string
 	if form.Name == nil {
-		name = repo.Name
+		var err error
+		name, err = repo_service.ForkName(ctx, forker, repo)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "ForkName", err)
+			return
+		}
 	} else {
 		name = *form.Name
 	}
@@ -142,8 +152,10 @@ func CreateFork(ctx *context.APIContext) {
 		Description: repo.Description,
 	})
 	if err != nil {
-		if repo_model.IsErrRepoAlreadyExist(err) {
+		if repo_model.IsErrRepoAlreadyExist(err) || models.IsErrForkAlreadyExist(err) {
 			ctx.Error(http.StatusConflict, "ForkRepository", err)
+		} else if db.IsErrNameReserved(err) || db.IsErrNamePatternNotAllowed(err) || db.IsErrNameCharsNotAllowed(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
 		} else {
 			ctx.Error(http.StatusInternalServerError, "ForkRepository", err)
 		}
@@ -153,3 +165,126 @@ func CreateFork(ctx *context.APIContext) {
 	// TODO change back to 201
 	ctx.JSON(http.StatusAccepted, convert.ToRepo(fork, perm.AccessModeOwner))
 }
+
+// AttachFork declares a repository a fork of another repository
+func AttachFork(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/parent repository repoAttachFork
+	// ---
+	// summary: Declare a repository a fork of another repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/AttachForkOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.AttachForkOption)
+
+	parent, err := repo_model.GetRepositoryByOwnerAndName(form.Owner, form.Repo)
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepositoryByOwnerAndName", err)
+		}
+		return
+	}
+	permission, err := access_model.GetUserRepoPermission(ctx, parent, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+		return
+	}
+	if !permission.CanRead(unit.TypeCode) {
+		ctx.NotFound()
+		return
+	}
+
+	if err := repo_service.AttachForkToRepository(ctx, ctx.Repo.Repository, parent); err != nil {
+		if repo_service.IsErrForkParentNotAllowed(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "AttachForkToRepository", err)
+		}
+		return
+	}
+
+	respondWithRepository(ctx, ctx.Repo.Repository.ID)
+}
+
+// DetachFork converts a fork into a regular repository
+func DetachFork(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/parent repository repoDetachFork
+	// ---
+	// summary: Detach a fork from its parent and convert it into a regular repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	repo := ctx.Repo.Repository
+	if !repo.IsFork {
+		ctx.Error(http.StatusUnprocessableEntity, "", "repository is not a fork")
+		return
+	}
+	if !ctx.Repo.Owner.CanCreateRepo() {
+		ctx.Error(http.StatusForbidden, "", fmt.Sprintf("%s has reached the limit of repositories", ctx.Repo.Owner.Name))
+		return
+	}
+
+	if err := repo_service.ConvertForkToNormalRepository(ctx, repo); err != nil {
+		ctx.Error(http.StatusInternalServerError, "ConvertForkToNormalRepository", err)
+		return
+	}
+
+	respondWithRepository(ctx, repo.ID)
+}
+
+func respondWithRepository(ctx *context.APIContext, repoID int64) {
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetRepositoryByID", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToRepo(repo, ctx.Repo.Permission.AccessMode))
+}
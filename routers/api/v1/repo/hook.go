@@ -7,8 +7,10 @@ package repo
 
 import (
 	"net/http"
+	"strconv"
 
 	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
@@ -16,6 +18,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	repo_service "code.gitea.io/gitea/services/repository"
 	webhook_service "code.gitea.io/gitea/services/webhook"
 )
 
@@ -244,6 +247,8 @@ func EditHook(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/Hook"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 	form := web.GetForm(ctx).(*api.EditHookOption)
 	hookID := ctx.ParamsInt64(":id")
 	utils.EditRepoHook(ctx, form, hookID)
@@ -276,8 +281,18 @@ func DeleteHook(ctx *context.APIContext) {
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
 	//   "404":
 	//     "$ref": "#/responses/notFound"
+	hook, err := utils.GetRepoHook(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		return
+	}
+	if hook.IsInherited() && hook.IsLocked {
+		ctx.Error(http.StatusForbidden, "", "webhook is locked by the organization")
+		return
+	}
 	if err := webhook.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id")); err != nil {
 		if webhook.IsErrWebhookNotExist(err) {
 			ctx.NotFound()
@@ -286,5 +301,6 @@ func DeleteHook(ctx *context.APIContext) {
 		}
 		return
 	}
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionWebhookRemove, strconv.FormatInt(hook.ID, 10), string(hook.Type))
 	ctx.Status(http.StatusNoContent)
 }
@@ -0,0 +1,155 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/task"
+)
+
+// Housekeep runs maintenance operations on a repository in the background
+func Housekeep(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/housekeeping repository repoHousekeep
+	// ---
+	// summary: Run maintenance operations on a repository
+	// description: The operations run in the background, use the returned job to follow the progress.
+	//   A repository can only be housekept once per `HOUSEKEEPING_MIN_INTERVAL`.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/RepoHousekeepingOptions"
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/RepoHousekeepingJob"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+	//   "429":
+	//     "$ref": "#/responses/error"
+
+	form := web.GetForm(ctx).(*api.RepoHousekeepingOptions)
+
+	t, err := task.CreateRepoHousekeepingTask(ctx.Doer, ctx.Repo.Repository, form.Operations)
+	if err != nil {
+		switch {
+		case task.IsErrInvalidHousekeepingOperation(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		case task.IsErrHousekeepingTooSoon(err):
+			ctx.Resp.Header().Set("Retry-After", strconv.Itoa(int(err.(task.ErrHousekeepingTooSoon).RetryAfter.Seconds())))
+			ctx.Error(http.StatusTooManyRequests, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "CreateRepoHousekeepingTask", err)
+		}
+		return
+	}
+
+	job, err := toRepoHousekeepingJob(t)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "toRepoHousekeepingJob", err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// GetHousekeepingJob returns the progress of a housekeeping job of a repository
+func GetHousekeepingJob(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/housekeeping/{id} repository repoGetHousekeepingJob
+	// ---
+	// summary: Get the progress of a housekeeping job of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoHousekeepingJob"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := models.GetRepoHousekeepingTask(ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRepoHousekeepingTask", err)
+		}
+		return
+	}
+
+	job, err := toRepoHousekeepingJob(t)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "toRepoHousekeepingJob", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+func toRepoHousekeepingJob(t *models.Task) (*api.RepoHousekeepingJob, error) {
+	operations, err := t.RepoHousekeepingOperations()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &api.RepoHousekeepingJob{
+		ID:         t.ID,
+		Status:     t.Status.Name(),
+		Operations: operations,
+		Created:    t.Created.AsTime(),
+	}
+	if t.Status == api.TaskStatusFailed {
+		job.Error = t.Message
+	}
+	if t.StartTime > 0 {
+		started := t.StartTime.AsTime()
+		job.Started = &started
+	}
+	if t.EndTime > 0 {
+		finished := t.EndTime.AsTime()
+		job.Finished = &finished
+	}
+	return job, nil
+}
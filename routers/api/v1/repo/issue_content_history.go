@@ -0,0 +1,618 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	issues_model "code.gitea.io/gitea/models/issues"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/log"
+	api "code.gitea.io/gitea/modules/structs"
+	issue_service "code.gitea.io/gitea/services/issue"
+)
+
+// ListIssueContentHistory list the history revisions of the description of an issue
+func ListIssueContentHistory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/content_history issue issueListContentHistory
+	// ---
+	// summary: List the history revisions of the description of an issue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContentHistoryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getContentHistoryIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	listContentHistory(ctx, issue.ID, 0)
+}
+
+// GetIssueContentHistory get a history revision of the description of an issue
+func GetIssueContentHistory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/content_history/{history_id} issue issueGetContentHistory
+	// ---
+	// summary: Get a history revision of the description of an issue including the changes since the previous revision
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: history_id
+	//   in: path
+	//   description: id of the history revision
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContentHistoryDetail"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getContentHistoryIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	getContentHistory(ctx, issue, nil)
+}
+
+// DeleteIssueContentHistory soft-delete a history revision of the description of an issue
+func DeleteIssueContentHistory(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index}/content_history/{history_id} issue issueDeleteContentHistory
+	// ---
+	// summary: Delete the content of a history revision of the description of an issue
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: history_id
+	//   in: path
+	//   description: id of the history revision
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getContentHistoryIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	softDeleteContentHistory(ctx, issue, nil)
+}
+
+// PurgeIssueContentHistory remove all history revisions of the description of an issue
+func PurgeIssueContentHistory(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index}/content_history issue issuePurgeContentHistory
+	// ---
+	// summary: Remove all history revisions of the description of an issue, requires site admin
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getContentHistoryIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	purgeContentHistory(ctx, issue, 0)
+}
+
+// PurgeIssueContentHistoryRevision remove a history revision of the description of an issue
+func PurgeIssueContentHistoryRevision(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/{index}/content_history/{history_id}/purge issue issuePurgeContentHistoryRevision
+	// ---
+	// summary: Remove a history revision of the description of an issue, e.g. if it contains a leaked secret, requires site admin
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: history_id
+	//   in: path
+	//   description: id of the history revision
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue := getContentHistoryIssue(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	purgeContentHistoryRevision(ctx, issue, nil)
+}
+
+// ListIssueCommentContentHistory list the history revisions of a comment
+func ListIssueCommentContentHistory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/comments/{id}/content_history issue issueListCommentContentHistory
+	// ---
+	// summary: List the history revisions of a comment
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContentHistoryList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getContentHistoryComment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	listContentHistory(ctx, comment.IssueID, comment.ID)
+}
+
+// GetIssueCommentContentHistory get a history revision of a comment
+func GetIssueCommentContentHistory(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/comments/{id}/content_history/{history_id} issue issueGetCommentContentHistory
+	// ---
+	// summary: Get a history revision of a comment including the changes since the previous revision
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: history_id
+	//   in: path
+	//   description: id of the history revision
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContentHistoryDetail"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getContentHistoryComment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	getContentHistory(ctx, comment.Issue, comment)
+}
+
+// DeleteIssueCommentContentHistory soft-delete a history revision of a comment
+func DeleteIssueCommentContentHistory(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/comments/{id}/content_history/{history_id} issue issueDeleteCommentContentHistory
+	// ---
+	// summary: Delete the content of a history revision of a comment
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: history_id
+	//   in: path
+	//   description: id of the history revision
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getContentHistoryComment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	softDeleteContentHistory(ctx, comment.Issue, comment)
+}
+
+// PurgeIssueCommentContentHistory remove all history revisions of a comment
+func PurgeIssueCommentContentHistory(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/comments/{id}/content_history issue issuePurgeCommentContentHistory
+	// ---
+	// summary: Remove all history revisions of a comment, requires site admin
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getContentHistoryComment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	purgeContentHistory(ctx, comment.Issue, comment.ID)
+}
+
+// PurgeIssueCommentContentHistoryRevision remove a history revision of a comment
+func PurgeIssueCommentContentHistoryRevision(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/issues/comments/{id}/content_history/{history_id}/purge issue issuePurgeCommentContentHistoryRevision
+	// ---
+	// summary: Remove a history revision of a comment, e.g. if it contains a leaked secret, requires site admin
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the comment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: history_id
+	//   in: path
+	//   description: id of the history revision
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	comment := getContentHistoryComment(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	purgeContentHistoryRevision(ctx, comment.Issue, comment)
+}
+
+func getContentHistoryIssue(ctx *context.APIContext) *models.Issue {
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return nil
+	}
+
+	if !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.NotFound()
+		return nil
+	}
+
+	return issue
+}
+
+func getContentHistoryComment(ctx *context.APIContext) *models.Comment {
+	comment, err := models.GetCommentByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCommentNotExist(err) {
+			ctx.NotFound(err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetCommentByID", err)
+		}
+		return nil
+	}
+
+	if err := comment.LoadIssue(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssue", err)
+		return nil
+	}
+
+	if comment.Issue.RepoID != ctx.Repo.Repository.ID || !ctx.Repo.CanReadIssuesOrPulls(comment.Issue.IsPull) {
+		ctx.NotFound()
+		return nil
+	}
+
+	return comment
+}
+
+// getContentHistoryEditor returns the editor of a history revision or a ghost user if the editor does not exist anymore
+func getContentHistoryEditor(ctx *context.APIContext, history *issues_model.ContentHistory) (*user_model.User, error) {
+	editor, err := user_model.GetUserByIDCtx(ctx, history.PosterID)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			return user_model.NewGhostUser(), nil
+		}
+		return nil, err
+	}
+	return editor, nil
+}
+
+// getContentHistoryByParam returns the history revision of the request if it belongs to the given issue or comment
+func getContentHistoryByParam(ctx *context.APIContext, issue *models.Issue, comment *models.Comment) *issues_model.ContentHistory {
+	history, err := issues_model.GetIssueContentHistoryByID(ctx, ctx.ParamsInt64(":history_id"))
+	if err != nil {
+		if _, ok := err.(issues_model.ErrIssueContentHistoryNotExist); ok {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueContentHistoryByID", err)
+		}
+		return nil
+	}
+
+	var commentID int64
+	if comment != nil {
+		commentID = comment.ID
+	}
+	if history.IssueID != issue.ID || history.CommentID != commentID {
+		ctx.NotFound()
+		return nil
+	}
+
+	return history
+}
+
+func listContentHistory(ctx *context.APIContext, issueID, commentID int64) {
+	histories, err := issues_model.FindIssueContentHistories(ctx, issueID, commentID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindIssueContentHistories", err)
+		return
+	}
+
+	editors := make(map[int64]*user_model.User)
+	apiHistories := make([]*api.ContentHistory, 0, len(histories))
+	for _, history := range histories {
+		editor, ok := editors[history.PosterID]
+		if !ok {
+			if editor, err = getContentHistoryEditor(ctx, history); err != nil {
+				ctx.Error(http.StatusInternalServerError, "GetUserByID", err)
+				return
+			}
+			editors[history.PosterID] = editor
+		}
+		apiHistories = append(apiHistories, convert.ToContentHistory(history, editor, ctx.Doer))
+	}
+
+	ctx.SetTotalCountHeader(int64(len(apiHistories)))
+	ctx.JSON(http.StatusOK, &apiHistories)
+}
+
+func getContentHistory(ctx *context.APIContext, issue *models.Issue, comment *models.Comment) {
+	history := getContentHistoryByParam(ctx, issue, comment)
+	if ctx.Written() {
+		return
+	}
+
+	history, prevHistory, err := issues_model.GetIssueContentHistoryAndPrev(ctx, history.ID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssueContentHistoryAndPrev", err)
+		return
+	}
+
+	editor, err := getContentHistoryEditor(ctx, history)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserByID", err)
+		return
+	}
+
+	diff := issue_service.DiffContentHistory(history, prevHistory)
+	ctx.JSON(http.StatusOK, convert.ToContentHistoryDetail(history, prevHistory, diff, editor, ctx.Doer))
+}
+
+func softDeleteContentHistory(ctx *context.APIContext, issue *models.Issue, comment *models.Comment) {
+	history := getContentHistoryByParam(ctx, issue, comment)
+	if ctx.Written() {
+		return
+	}
+
+	if !issue_service.CanSoftDeleteContentHistory(ctx.Repo.Permission, ctx.Doer, issue, comment, history) {
+		ctx.Error(http.StatusForbidden, "CanSoftDeleteContentHistory", "can not delete the content history")
+		return
+	}
+
+	if err := issues_model.SoftDeleteIssueContentHistory(ctx, history.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "SoftDeleteIssueContentHistory", err)
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func purgeContentHistory(ctx *context.APIContext, issue *models.Issue, commentID int64) {
+	if err := issues_model.PurgeIssueContentHistory(ctx, issue.ID, commentID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "PurgeIssueContentHistory", err)
+		return
+	}
+
+	log.Info("Content history of issue %d (comment %d) has been purged by %s", issue.ID, commentID, ctx.Doer.Name)
+
+	ctx.Status(http.StatusNoContent)
+}
+
+func purgeContentHistoryRevision(ctx *context.APIContext, issue *models.Issue, comment *models.Comment) {
+	history := getContentHistoryByParam(ctx, issue, comment)
+	if ctx.Written() {
+		return
+	}
+
+	if err := issues_model.PurgeIssueContentHistoryRevision(ctx, history.ID); err != nil {
+		ctx.Error(http.StatusInternalServerError, "PurgeIssueContentHistoryRevision", err)
+		return
+	}
+
+	log.Info("Content history revision %d of issue %d (comment %d) has been purged by %s", history.ID, issue.ID, history.CommentID, ctx.Doer.Name)
+
+	ctx.Status(http.StatusNoContent)
+}
@@ -0,0 +1,88 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+)
+
+// GetIssueDependencyGraph returns the dependency graph of an issue
+func GetIssueDependencyGraph(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/issues/{index}/dependency-graph issue issueGetDependencyGraph
+	// ---
+	// summary: Get the dependency graph of an issue, including the transitive closure and dependency cycles
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the issue
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueDependencyGraph"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	issue, err := models.GetIssueByIndex(ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrIssueNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueByIndex", err)
+		}
+		return
+	}
+
+	if !ctx.Repo.CanReadIssuesOrPulls(issue.IsPull) {
+		ctx.NotFound()
+		return
+	}
+
+	// Dependencies may cross repositories, only walk through the issues the doer is allowed to see
+	perms := map[int64]access_model.Permission{ctx.Repo.Repository.ID: ctx.Repo.Permission}
+	graph, err := models.GetIssueDependencyGraph(ctx, issue, func(dep *models.Issue) (bool, error) {
+		perm, ok := perms[dep.RepoID]
+		if !ok {
+			if err := dep.LoadRepo(ctx); err != nil {
+				return false, err
+			}
+			var err error
+			if perm, err = access_model.GetUserRepoPermission(ctx, dep.Repo, ctx.Doer); err != nil {
+				return false, err
+			}
+			perms[dep.RepoID] = perm
+		}
+		return perm.CanReadIssuesOrPulls(dep.IsPull), nil
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetIssueDependencyGraph", err)
+		return
+	}
+
+	apiGraph, err := convert.ToIssueDependencyGraph(graph)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToIssueDependencyGraph", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiGraph)
+}
@@ -0,0 +1,91 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+	"code.gitea.io/gitea/services/issue/timereport"
+)
+
+// GetTimeReport aggregates the tracked times of a repository
+func GetTimeReport(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/time_report repository repoGetTimeReport
+	// ---
+	// summary: Get a report of a repo's tracked times grouped by user, label or milestone
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: group_by
+	//   in: query
+	//   description: how to group the tracked times, defaults to user
+	//   type: string
+	//   enum: [user, label, milestone]
+	// - name: user
+	//   in: query
+	//   description: optional filter by user (available for issue managers)
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only count times created after the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only count times created before the given time. This is a timestamp in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/TimeReport"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
+		ctx.Error(http.StatusBadRequest, "", "time tracking disabled")
+		return
+	}
+
+	opts := utils.GetTimeReportOptions(ctx)
+	if opts == nil {
+		return
+	}
+	opts.RepoID = ctx.Repo.Repository.ID
+
+	if !ctx.Doer.IsAdmin && opts.UserID != ctx.Doer.ID && !ctx.IsUserRepoWriter([]unit.Type{unit.TypeIssues}) {
+		if opts.UserID != 0 {
+			ctx.Error(http.StatusForbidden, "", fmt.Errorf("query by user not allowed; not enough rights"))
+			return
+		}
+		opts.UserID = ctx.Doer.ID
+	}
+
+	report, err := timereport.Generate(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Generate", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, timereport.ToAPIReport(report))
+}
@@ -9,6 +9,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/url"
+	"strings"
 
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/db"
@@ -21,6 +22,8 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	auth_service "code.gitea.io/gitea/services/auth"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // appendPrivateInformation appends the owner and key type information to api.PublicKey
@@ -231,12 +234,19 @@ func CreateDeployKey(ctx *context.APIContext) {
 		return
 	}
 
-	key, err := asymkey_model.AddDeployKey(ctx.Repo.Repository.ID, form.Title, content, form.ReadOnly)
+	if err := auth_service.ValidateCIDRList(form.AllowedCIDRs); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "AllowedCIDRs", err)
+		return
+	}
+
+	key, err := asymkey_model.AddDeployKey(ctx.Repo.Repository.ID, form.Title, content, form.ReadOnly, strings.TrimSpace(form.AllowedCIDRs))
 	if err != nil {
 		HandleAddKeyError(ctx, err)
 		return
 	}
 
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionDeployKeyAdd, key.Name, key.Fingerprint)
+
 	key.Content = content
 	apiLink := composeDeployKeysAPILink(ctx.Repo.Owner.Name, ctx.Repo.Repository.Name)
 	ctx.JSON(http.StatusCreated, convert.ToDeployKey(apiLink, key))
@@ -270,6 +280,9 @@ func DeleteDeploykey(ctx *context.APIContext) {
 	//   "403":
 	//     "$ref": "#/responses/forbidden"
 
+	// the key is looked up for the audit log only, the deletion checks the access to it
+	key, _ := asymkey_model.GetDeployKeyByID(ctx, ctx.ParamsInt64(":id"))
+
 	if err := asymkey_service.DeleteDeployKey(ctx.Doer, ctx.ParamsInt64(":id")); err != nil {
 		if asymkey_model.IsErrKeyAccessDenied(err) {
 			ctx.Error(http.StatusForbidden, "", "You do not have access to this key")
@@ -279,5 +292,9 @@ func DeleteDeploykey(ctx *context.APIContext) {
 		return
 	}
 
+	if key != nil && key.RepoID == ctx.Repo.Repository.ID {
+		repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionDeployKeyRemove, key.Name, key.Fingerprint)
+	}
+
 	ctx.Status(http.StatusNoContent)
 }
@@ -87,7 +87,8 @@ func ApplyDiffPatch(ctx *context.APIContext) {
 
 	fileResponse, err := files.ApplyDiffPatch(ctx, ctx.Repo.Repository, ctx.Doer, opts)
 	if err != nil {
-		if models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) {
+		if models.IsErrUserCannotCommit(err) || models.IsErrFilePathProtected(err) ||
+			models.IsErrFilePathLocked(err) || models.IsErrFilePathRequiresPullRequest(err) {
 			ctx.Error(http.StatusForbidden, "Access", err)
 			return
 		}
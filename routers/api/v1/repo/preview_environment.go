@@ -0,0 +1,352 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
+	preview_service "code.gitea.io/gitea/services/preview"
+)
+
+// getPreviewPullRequest returns the pull request identified by the `index` parameter
+func getPreviewPullRequest(ctx *context.APIContext) *models.PullRequest {
+	pr, err := models.GetPullRequestByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return nil
+	}
+	return pr
+}
+
+// getPreviewEnvironment returns the pull request and its preview environment identified by the `index` and `id` parameters
+func getPreviewEnvironment(ctx *context.APIContext) (*models.PullRequest, *models.PreviewEnvironment) {
+	pr := getPreviewPullRequest(ctx)
+	if pr == nil {
+		return nil, nil
+	}
+	env, err := models.GetPreviewEnvironmentByID(ctx, pr.IssueID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrPreviewEnvironmentNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPreviewEnvironmentByID", err)
+		}
+		return nil, nil
+	}
+	return pr, env
+}
+
+// respondPreviewEnvironment writes the preview environment with its creator
+func respondPreviewEnvironment(ctx *context.APIContext, status int, env *models.PreviewEnvironment) {
+	if err := env.LoadCreator(ctx); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadCreator", err)
+		return
+	}
+	ctx.JSON(status, convert.ToPreviewEnvironment(env, ctx.Doer))
+}
+
+// handlePreviewEnvironmentError writes the response of an error of the preview service
+func handlePreviewEnvironmentError(ctx *context.APIContext, name string, err error) {
+	switch {
+	case models.IsErrInvalidPreviewEnvironment(err):
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+	case models.IsErrPreviewEnvironmentAlreadyExist(err):
+		ctx.Error(http.StatusConflict, "", err)
+	default:
+		ctx.Error(http.StatusInternalServerError, name, err)
+	}
+}
+
+// ListPreviewEnvironments lists the preview environments of a pull request
+func ListPreviewEnvironments(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/preview_environments repository repoListPreviewEnvironments
+	// ---
+	// summary: List the preview environments of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PreviewEnvironmentList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr := getPreviewPullRequest(ctx)
+	if pr == nil {
+		return
+	}
+	envs, err := models.GetPreviewEnvironments(ctx, pr.IssueID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetPreviewEnvironments", err)
+		return
+	}
+
+	apiEnvs := make([]*api.PreviewEnvironment, 0, len(envs))
+	for _, env := range envs {
+		if err := env.LoadCreator(ctx); err != nil {
+			ctx.Error(http.StatusInternalServerError, "LoadCreator", err)
+			return
+		}
+		apiEnvs = append(apiEnvs, convert.ToPreviewEnvironment(env, ctx.Doer))
+	}
+	ctx.JSON(http.StatusOK, apiEnvs)
+}
+
+// GetPreviewEnvironment gets a preview environment of a pull request
+func GetPreviewEnvironment(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/pulls/{index}/preview_environments/{id} repository repoGetPreviewEnvironment
+	// ---
+	// summary: Get a preview environment of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the preview environment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PreviewEnvironment"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	_, env := getPreviewEnvironment(ctx)
+	if env == nil {
+		return
+	}
+	respondPreviewEnvironment(ctx, http.StatusOK, env)
+}
+
+// CreatePreviewEnvironment registers a preview environment for a pull request
+func CreatePreviewEnvironment(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/preview_environments repository repoCreatePreviewEnvironment
+	// ---
+	// summary: Register a preview environment for a pull request
+	// description: The environment is shown as a link in the header of the pull request. It is removed when the
+	//   pull request is closed or when it expires, a `preview_environment` webhook event tells the deployment tooling to tear it down.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreatePreviewEnvironmentOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/PreviewEnvironment"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreatePreviewEnvironmentOption)
+	pr := getPreviewPullRequest(ctx)
+	if pr == nil {
+		return
+	}
+
+	env := &models.PreviewEnvironment{
+		Name:   form.Name,
+		URL:    form.URL,
+		Status: models.PreviewEnvironmentStatus(form.Status),
+	}
+	if form.Expires != nil {
+		env.ExpiresUnix = timeutil.TimeStamp(form.Expires.Unix())
+	}
+
+	if err := preview_service.CreatePreviewEnvironment(ctx, ctx.Doer, pr, env); err != nil {
+		handlePreviewEnvironmentError(ctx, "CreatePreviewEnvironment", err)
+		return
+	}
+	respondPreviewEnvironment(ctx, http.StatusCreated, env)
+}
+
+// EditPreviewEnvironment changes a preview environment of a pull request
+func EditPreviewEnvironment(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/pulls/{index}/preview_environments/{id} repository repoEditPreviewEnvironment
+	// ---
+	// summary: Change a preview environment of a pull request
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the preview environment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/EditPreviewEnvironmentOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PreviewEnvironment"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditPreviewEnvironmentOption)
+	pr, env := getPreviewEnvironment(ctx)
+	if env == nil {
+		return
+	}
+
+	cols := make([]string, 0, 3)
+	if form.URL != nil {
+		env.URL = *form.URL
+		cols = append(cols, "url")
+	}
+	if form.Status != nil {
+		env.Status = models.PreviewEnvironmentStatus(*form.Status)
+		cols = append(cols, "status")
+	}
+	if form.Expires != nil {
+		env.ExpiresUnix = timeutil.TimeStamp(form.Expires.Unix())
+		cols = append(cols, "expires_unix")
+	}
+
+	if err := preview_service.UpdatePreviewEnvironment(ctx, ctx.Doer, pr, env, cols...); err != nil {
+		handlePreviewEnvironmentError(ctx, "UpdatePreviewEnvironment", err)
+		return
+	}
+	respondPreviewEnvironment(ctx, http.StatusOK, env)
+}
+
+// DeletePreviewEnvironment removes a preview environment of a pull request
+func DeletePreviewEnvironment(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/pulls/{index}/preview_environments/{id} repository repoDeletePreviewEnvironment
+	// ---
+	// summary: Remove a preview environment of a pull request
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the preview environment
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	pr, env := getPreviewEnvironment(ctx)
+	if env == nil {
+		return
+	}
+	if err := preview_service.DeletePreviewEnvironment(ctx, ctx.Doer, pr, env); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeletePreviewEnvironment", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
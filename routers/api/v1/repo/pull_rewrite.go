@@ -0,0 +1,117 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+	pull_service "code.gitea.io/gitea/services/pull"
+)
+
+// RewritePullRequest rewrites the head branch of a pull request like an interactive rebase
+func RewritePullRequest(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/pulls/{index}/rewrite repository repoRewritePullRequest
+	// ---
+	// summary: Squash, reword, drop and reorder the commits of a pull request
+	// description: The head branch is rewritten on the server onto the merge base and force pushed.
+	//   It needs the permission to push to the head branch, which must not be protected.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: index
+	//   in: path
+	//   description: index of the pull request
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/RewritePullRequestOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PullRequestRewriteResult"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.RewritePullRequestOption)
+
+	pr, err := models.GetPullRequestByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if models.IsErrPullRequestNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetPullRequestByIndex", err)
+		}
+		return
+	}
+	if err = pr.LoadIssue(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadIssue", err)
+		return
+	}
+	if pr.HasMerged || pr.Issue.IsClosed {
+		ctx.Error(http.StatusUnprocessableEntity, "", "the pull request is closed")
+		return
+	}
+
+	allowed, err := pull_service.IsUserAllowedToRewrite(ctx, pr, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "IsUserAllowedToRewrite", err)
+		return
+	}
+	if !allowed {
+		ctx.Error(http.StatusForbidden, "", "you are not allowed to force push to the head branch")
+		return
+	}
+
+	steps := make([]*pull_service.RewriteStep, 0, len(form.Steps))
+	for _, step := range form.Steps {
+		steps = append(steps, &pull_service.RewriteStep{
+			CommitID: step.SHA,
+			Action:   pull_service.RewriteAction(step.Action),
+			Message:  step.Message,
+		})
+	}
+
+	headCommitID, err := pull_service.Rewrite(ctx, pr, ctx.Doer, form.HeadCommitID, steps)
+	if err != nil {
+		switch {
+		case pull_service.IsErrInvalidRewrite(err):
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		case pull_service.IsErrRewriteConflict(err):
+			ctx.Error(http.StatusConflict, "", err)
+		case git.IsErrPushRejected(err):
+			ctx.Error(http.StatusConflict, "", err)
+		default:
+			ctx.Error(http.StatusInternalServerError, "Rewrite", err)
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, &api.PullRequestRewriteResult{HeadCommitID: headCommitID})
+}
@@ -17,6 +17,7 @@ import (
 	"code.gitea.io/gitea/modules/upload"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/attachment"
+	releaseservice "code.gitea.io/gitea/services/release"
 )
 
 // GetReleaseAttachment gets a single attachment of the release
@@ -155,6 +156,8 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 	//     "$ref": "#/responses/Attachment"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "413":
+	//     "$ref": "#/responses/error"
 
 	// Check if attachments are enabled
 	if !setting.Attachment.Enabled {
@@ -190,9 +193,90 @@ func CreateReleaseAttachment(ctx *context.APIContext) {
 			ctx.Error(http.StatusBadRequest, "DetectContentType", err)
 			return
 		}
+		if attachment.IsErrRepoAttachmentsTooLarge(err) {
+			ctx.Error(http.StatusRequestEntityTooLarge, "", err)
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, "NewAttachment", err)
 		return
 	}
+	releaseservice.ScheduleSigning(release.ID)
+
+	ctx.JSON(http.StatusCreated, convert.ToReleaseAttachment(attach))
+}
+
+// CreateReleaseExternalAttachment creates an attachment linking to a file which is hosted elsewhere
+func CreateReleaseExternalAttachment(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/releases/{id}/assets/external repository repoCreateReleaseExternalAttachment
+	// ---
+	// summary: Create a release attachment linking to an external file
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/CreateExternalAttachmentOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/Attachment"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateExternalAttachmentOption)
+
+	releaseID := ctx.ParamsInt64(":id")
+	release, err := models.GetReleaseByID(releaseID)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		return
+	}
+	if release.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+
+	attach, err := attachment.NewExternalAttachment(&repo_model.Attachment{
+		RepoID:      release.RepoID,
+		ReleaseID:   release.ID,
+		UploaderID:  ctx.Doer.ID,
+		Name:        form.Name,
+		ExternalURL: form.ExternalURL,
+		Checksum:    form.Checksum,
+	})
+	if err != nil {
+		if attachment.IsErrInvalidExternalAttachment(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "NewExternalAttachment", err)
+		return
+	}
+	releaseservice.ScheduleSigning(release.ID)
 
 	ctx.JSON(http.StatusCreated, convert.ToReleaseAttachment(attach))
 }
@@ -260,6 +344,7 @@ func EditReleaseAttachment(ctx *context.APIContext) {
 	if err := repo_model.UpdateAttachment(ctx, attach); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateAttachment", attach)
 	}
+	releaseservice.ScheduleSigning(releaseID)
 	ctx.JSON(http.StatusCreated, convert.ToReleaseAttachment(attach))
 }
 
@@ -316,5 +401,6 @@ func DeleteReleaseAttachment(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "DeleteAttachment", err)
 		return
 	}
+	releaseservice.ScheduleSigning(releaseID)
 	ctx.Status(http.StatusNoContent)
 }
@@ -0,0 +1,322 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+
+	"code.gitea.io/gitea/models"
+	resumable_model "code.gitea.io/gitea/models/resumable"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/upload"
+	"code.gitea.io/gitea/services/attachment"
+	releaseservice "code.gitea.io/gitea/services/release"
+	resumable_service "code.gitea.io/gitea/services/resumable"
+)
+
+// getUploadRelease returns the release of the request if attachments are enabled
+func getUploadRelease(ctx *context.APIContext) *models.Release {
+	if !setting.Attachment.Enabled {
+		ctx.NotFound("Attachment is not enabled")
+		return nil
+	}
+
+	release, err := models.GetReleaseByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+			return nil
+		}
+		ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		return nil
+	}
+	if release.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return nil
+	}
+	return release
+}
+
+// getReleaseAttachmentUpload returns the upload of the request to the release of the request
+func getReleaseAttachmentUpload(ctx *context.APIContext) *resumable_model.Upload {
+	release := getUploadRelease(ctx)
+	if release == nil {
+		return nil
+	}
+	return resumable_service.GetUpload(ctx.Context, ctx.Params(":upload_id"), func(u *resumable_model.Upload) bool {
+		return u.TargetType == resumable_model.TargetReleaseAttachment && u.RepoID == release.RepoID && u.ReleaseID == release.ID
+	})
+}
+
+// finishReleaseAttachmentUpload creates the release attachment of a complete upload
+func finishReleaseAttachmentUpload(ctx *context.APIContext) resumable_service.Finisher {
+	return func(u *resumable_model.Upload, file *os.File) bool {
+		// the release may have been deleted while uploading
+		if _, err := models.GetReleaseByID(u.ReleaseID); err != nil {
+			if models.IsErrReleaseNotExist(err) {
+				ctx.NotFound()
+				return false
+			}
+			ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+			return false
+		}
+
+		if _, err := attachment.UploadAttachment(file, u.DoerID, u.RepoID, u.ReleaseID, u.Filename, setting.Repository.Release.AllowedTypes); err != nil {
+			if upload.IsErrFileTypeForbidden(err) {
+				ctx.Error(http.StatusBadRequest, "DetectContentType", err)
+				return false
+			}
+			if attachment.IsErrRepoAttachmentsTooLarge(err) {
+				ctx.Error(http.StatusRequestEntityTooLarge, "", err)
+				return false
+			}
+			ctx.Error(http.StatusInternalServerError, "NewAttachment", err)
+			return false
+		}
+		releaseservice.ScheduleSigning(u.ReleaseID)
+		return true
+	}
+}
+
+// ReleaseAttachmentUploadOptions describes the capabilities of the resumable uploads of release attachments
+func ReleaseAttachmentUploadOptions(ctx *context.APIContext) {
+	resumable_service.Options(ctx.Context, setting.Attachment.MaxSize<<20)
+}
+
+// CreateReleaseAttachmentUpload starts a resumable upload of a release attachment
+func CreateReleaseAttachmentUpload(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/releases/{id}/assets/uploads repository repoCreateReleaseAttachmentUpload
+	// ---
+	// summary: Start a resumable upload of a release attachment using the tus protocol
+	// description: The name of the attachment is taken from the `filename` key of the `Upload-Metadata` header
+	//   or the `name` query parameter. The returned `Location` header is the URL the data is sent to with `PATCH` requests,
+	//   the attachment is created once all data is received.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: name
+	//   in: query
+	//   description: name of the attachment
+	//   type: string
+	//   required: false
+	// - name: Tus-Resumable
+	//   in: header
+	//   description: version of the tus protocol, must be 1.0.0
+	//   type: string
+	//   required: true
+	// - name: Upload-Length
+	//   in: header
+	//   description: size of the attachment in bytes
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: Upload-Metadata
+	//   in: header
+	//   description: comma separated keys and base64 encoded values
+	//   type: string
+	//   required: false
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/empty"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "412":
+	//     "$ref": "#/responses/error"
+	//   "413":
+	//     "$ref": "#/responses/error"
+
+	release := getUploadRelease(ctx)
+	if release == nil {
+		return
+	}
+
+	metadata, err := resumable_service.ParseMetadata(ctx.Req.Header.Get("Upload-Metadata"))
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, "ParseMetadata", err)
+		return
+	}
+	filename := metadata["filename"]
+	if query := ctx.FormString("name"); query != "" {
+		filename = query
+	}
+	if filename == "" {
+		ctx.Error(http.StatusBadRequest, "", "the name of the attachment is missing")
+		return
+	}
+
+	resumable_service.Create(ctx.Context, &resumable_model.Upload{
+		TargetType: resumable_model.TargetReleaseAttachment,
+		RepoID:     release.RepoID,
+		ReleaseID:  release.ID,
+		Filename:   filename,
+	}, setting.Attachment.MaxSize<<20,
+		fmt.Sprintf("%s/releases/%d/assets/uploads", ctx.Repo.Repository.APIURL(), release.ID),
+		finishReleaseAttachmentUpload(ctx))
+}
+
+// GetReleaseAttachmentUpload returns the progress of a resumable upload of a release attachment
+func GetReleaseAttachmentUpload(ctx *context.APIContext) {
+	// swagger:operation HEAD /repos/{owner}/{repo}/releases/{id}/assets/uploads/{upload_id} repository repoGetReleaseAttachmentUpload
+	// ---
+	// summary: Get the number of bytes received by a resumable upload of a release attachment in the `Upload-Offset` header
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: upload_id
+	//   in: path
+	//   description: id of the upload
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if u := getReleaseAttachmentUpload(ctx); u != nil {
+		resumable_service.Head(ctx.Context, u)
+	}
+}
+
+// PatchReleaseAttachmentUpload appends data to a resumable upload of a release attachment
+func PatchReleaseAttachmentUpload(ctx *context.APIContext) {
+	// swagger:operation PATCH /repos/{owner}/{repo}/releases/{id}/assets/uploads/{upload_id} repository repoPatchReleaseAttachmentUpload
+	// ---
+	// summary: Append data to a resumable upload of a release attachment, the attachment is created once all data is received
+	// consumes:
+	// - application/offset+octet-stream
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: upload_id
+	//   in: path
+	//   description: id of the upload
+	//   type: string
+	//   required: true
+	// - name: Tus-Resumable
+	//   in: header
+	//   description: version of the tus protocol, must be 1.0.0
+	//   type: string
+	//   required: true
+	// - name: Upload-Offset
+	//   in: header
+	//   description: number of bytes received so far
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "400":
+	//     "$ref": "#/responses/error"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     "$ref": "#/responses/error"
+	//   "412":
+	//     "$ref": "#/responses/error"
+	//   "413":
+	//     "$ref": "#/responses/error"
+	//   "415":
+	//     "$ref": "#/responses/error"
+
+	if u := getReleaseAttachmentUpload(ctx); u != nil {
+		resumable_service.Patch(ctx.Context, u, finishReleaseAttachmentUpload(ctx))
+	}
+}
+
+// DeleteReleaseAttachmentUpload aborts a resumable upload of a release attachment
+func DeleteReleaseAttachmentUpload(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/releases/{id}/assets/uploads/{upload_id} repository repoDeleteReleaseAttachmentUpload
+	// ---
+	// summary: Abort a resumable upload of a release attachment
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: upload_id
+	//   in: path
+	//   description: id of the upload
+	//   type: string
+	//   required: true
+	// - name: Tus-Resumable
+	//   in: header
+	//   description: version of the tus protocol, must be 1.0.0
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "412":
+	//     "$ref": "#/responses/error"
+
+	if u := getReleaseAttachmentUpload(ctx); u != nil {
+		resumable_service.Terminate(ctx.Context, u)
+	}
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/modules/context"
+	releaseservice "code.gitea.io/gitea/services/release"
+)
+
+// GetReleaseVerification verifies the signed checksums of the assets of a release
+func GetReleaseVerification(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/releases/{id}/verification repository repoGetReleaseVerification
+	// ---
+	// summary: Verify the signed checksums of the assets and source archives of a release
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the release
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ReleaseVerification"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	release, err := models.GetReleaseByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetReleaseByID", err)
+		return
+	}
+	if release.IsTag || release.RepoID != ctx.Repo.Repository.ID {
+		ctx.NotFound()
+		return
+	}
+	if err := release.LoadAttributes(); err != nil {
+		ctx.Error(http.StatusInternalServerError, "LoadAttributes", err)
+		return
+	}
+
+	verification, err := releaseservice.VerifyRelease(ctx, ctx.Repo.GitRepo, release)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "VerifyRelease", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, verification)
+}
+
+// GetReleaseSigningKey returns the public key signing the releases of a repository
+func GetReleaseSigningKey(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/releases/signing-key.gpg repository repoGetReleaseSigningKey
+	// ---
+	// summary: Get the armored public key signing the checksums of the releases of a repository
+	// produces:
+	//     - text/plain
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     description: "GPG armored public key"
+	//     schema:
+	//       type: string
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	key, err := releaseservice.GetSigningKey(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		if asymkey_model.IsErrReleaseSigningKeyNotExist(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetSigningKey", err)
+		return
+	}
+	ctx.PlainText(http.StatusOK, key.PublicKey)
+}
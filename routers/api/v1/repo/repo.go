@@ -8,6 +8,7 @@ package repo
 import (
 	"fmt"
 	"net/http"
+	"reflect"
 	"strings"
 	"time"
 
@@ -49,6 +50,23 @@ func Search(ctx *context.APIContext) {
 	//   in: query
 	//   description: Limit search to repositories with keyword as topic
 	//   type: boolean
+	// - name: topics
+	//   in: query
+	//   description: Limit search to repositories with these comma separated topics
+	//   type: string
+	// - name: topics_match
+	//   in: query
+	//   description: whether the repositories must have "all" or "any" of the topics. Default is "any"
+	//   type: string
+	//   enum: [any, all]
+	// - name: language
+	//   in: query
+	//   description: Limit search to repositories with this primary language
+	//   type: string
+	// - name: license
+	//   in: query
+	//   description: Limit search to repositories with this license
+	//   type: string
 	// - name: includeDesc
 	//   in: query
 	//   description: include search of keyword within repository description
@@ -101,7 +119,7 @@ func Search(ctx *context.APIContext) {
 	// - name: sort
 	//   in: query
 	//   description: sort repos by attribute. Supported values are
-	//                "alpha", "created", "updated", "size", and "id".
+	//                "alpha", "created", "updated", "pushed", "size", and "id".
 	//                Default is "alpha"
 	//   type: string
 	// - name: order
@@ -136,6 +154,20 @@ func Search(ctx *context.APIContext) {
 		Template:           util.OptionalBoolNone,
 		StarredByID:        ctx.FormInt64("starredBy"),
 		IncludeDescription: ctx.FormBool("includeDesc"),
+		Language:           ctx.FormTrim("language"),
+		License:            ctx.FormTrim("license"),
+	}
+
+	if topics := ctx.FormTrim("topics"); topics != "" {
+		opts.Topics = strings.Split(topics, ",")
+	}
+	switch match := ctx.FormString("topics_match"); match {
+	case "all":
+		opts.TopicsMatchAll = true
+	case "", "any":
+	default:
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("Invalid topics match: \"%s\"", match))
+		return
 	}
 
 	if ctx.FormString("template") != "" {
@@ -621,6 +653,10 @@ func Edit(ctx *context.APIContext) {
 		}
 	}
 
+	if fields := editedFields(opts); fields != "" {
+		repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionSettingsUpdate, "edit", fields)
+	}
+
 	repo, err := repo_model.GetRepositoryByID(ctx.Repo.Repository.ID)
 	if err != nil {
 		ctx.InternalServerError(err)
@@ -630,6 +666,18 @@ func Edit(ctx *context.APIContext) {
 	ctx.JSON(http.StatusOK, convert.ToRepo(repo, ctx.Repo.AccessMode))
 }
 
+// editedFields returns the names of the properties set in the options for the audit log
+func editedFields(opts api.EditRepoOption) string {
+	v := reflect.ValueOf(opts)
+	fields := make([]string, 0, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		if f := v.Field(i); f.Kind() == reflect.Ptr && !f.IsNil() {
+			fields = append(fields, strings.Split(v.Type().Field(i).Tag.Get("json"), ",")[0])
+		}
+	}
+	return strings.Join(fields, ", ")
+}
+
 // updateBasicProperties updates the basic properties of a repo: Name, Description, Website and Visibility
 func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) error {
 	owner := ctx.Repo.Owner
@@ -668,6 +716,10 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 		repo.Website = *opts.Website
 	}
 
+	if opts.License != nil {
+		repo.License = strings.TrimSpace(*opts.License)
+	}
+
 	visibilityChanged := false
 	if opts.Private != nil {
 		// Visibility of forked repository is forced sync with base repository.
@@ -686,14 +738,22 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 			ctx.Error(http.StatusUnprocessableEntity, "Force Private enabled", err)
 			return err
 		}
-
-		repo.IsPrivate = *opts.Private
 	}
 
 	if opts.Template != nil {
 		repo.IsTemplate = *opts.Template
 	}
 
+	if opts.TrustModel != nil {
+		trustModel := repo_model.ToTrustModel(*opts.TrustModel)
+		if trustModel.String() != strings.ToLower(strings.TrimSpace(*opts.TrustModel)) {
+			err := fmt.Errorf("unknown trust model: %s", *opts.TrustModel)
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+			return err
+		}
+		repo.TrustModel = trustModel
+	}
+
 	if ctx.Repo.GitRepo == nil && !repo.IsEmpty {
 		var err error
 		ctx.Repo.GitRepo, err = git.OpenRepository(ctx, ctx.Repo.Repository.RepoPath())
@@ -717,10 +777,16 @@ func updateBasicProperties(ctx *context.APIContext, opts api.EditRepoOption) err
 		repo.DefaultBranch = *opts.DefaultBranch
 	}
 
-	if err := models.UpdateRepository(repo, visibilityChanged); err != nil {
+	if err := models.UpdateRepository(repo, false); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateRepository", err)
 		return err
 	}
+	if visibilityChanged {
+		if err := repo_service.ChangeVisibility(ctx, ctx.Doer, repo, *opts.Private); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ChangeVisibility", err)
+			return err
+		}
+	}
 
 	log.Trace("Repository basic settings updated: %s/%s", owner.Name, repo.Name)
 	return nil
@@ -1049,8 +1115,8 @@ func Delete(ctx *context.APIContext) {
 		ctx.Repo.GitRepo.Close()
 	}
 
-	if err := repo_service.DeleteRepository(ctx, ctx.Doer, repo, true); err != nil {
-		ctx.Error(http.StatusInternalServerError, "DeleteRepository", err)
+	if err := repo_service.TrashRepository(ctx, ctx.Doer, repo); err != nil {
+		ctx.Error(http.StatusInternalServerError, "TrashRepository", err)
 		return
 	}
 
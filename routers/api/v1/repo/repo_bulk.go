@@ -0,0 +1,206 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/task"
+
+	"gopkg.in/yaml.v2"
+)
+
+// maxBulkRepoManifestSize is the maximum size of the manifest of a bulk repository creation job
+const maxBulkRepoManifestSize = 4 << 20
+
+// BulkCreateOrgRepos creates or imports many repositories of an organization in one job
+func BulkCreateOrgRepos(ctx *context.APIContext) {
+	// swagger:operation POST /orgs/{org}/repos/bulk organization orgBulkCreateRepos
+	// ---
+	// summary: Create or import many repositories in an organization in one job
+	// description: The manifest may be sent as JSON or, with a YAML content type, as YAML.
+	//   Repositories are created in the background, use the returned job to follow the progress.
+	// consumes:
+	// - application/json
+	// - application/x-yaml
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of organization
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/BulkRepoManifest"
+	// responses:
+	//   "202":
+	//     "$ref": "#/responses/BulkRepoJob"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "413":
+	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	org := ctx.Org.Organization
+	if !ctx.Doer.IsAdmin {
+		canCreate, err := org.CanCreateOrgRepo(ctx.Doer.ID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "CanCreateOrgRepo", err)
+			return
+		} else if !canCreate {
+			ctx.Error(http.StatusForbidden, "", "Given user is not allowed to create repository in organization.")
+			return
+		}
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(ctx.Resp, ctx.Req.Body, maxBulkRepoManifestSize))
+	if err != nil {
+		ctx.Error(http.StatusRequestEntityTooLarge, "", fmt.Sprintf("the manifest cannot be read or is larger than %d bytes", maxBulkRepoManifestSize))
+		return
+	}
+	var manifest api.BulkRepoManifest
+	if strings.Contains(ctx.Req.Header.Get("Content-Type"), "yaml") {
+		err = yaml.Unmarshal(body, &manifest)
+	} else {
+		err = json.Unmarshal(body, &manifest)
+	}
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", err)
+		return
+	}
+
+	if err := task.ValidateBulkReposManifest(ctx, ctx.Doer, org, &manifest); err != nil {
+		if task.IsErrInvalidBulkReposManifest(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "ValidateBulkReposManifest", err)
+		}
+		return
+	}
+
+	t, err := task.CreateBulkReposTask(ctx.Doer, org, &manifest)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "CreateBulkReposTask", err)
+		return
+	}
+
+	job, err := toBulkRepoJob(t)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "toBulkRepoJob", err)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// GetBulkCreateOrgReposJob returns the progress of a bulk repository creation job
+func GetBulkCreateOrgReposJob(ctx *context.APIContext) {
+	// swagger:operation GET /orgs/{org}/repos/bulk/{id} organization orgGetBulkCreateReposJob
+	// ---
+	// summary: Get the progress of a bulk repository creation job
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: org
+	//   in: path
+	//   description: name of organization
+	//   type: string
+	//   required: true
+	// - name: id
+	//   in: path
+	//   description: id of the job
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/BulkRepoJob"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	t, err := models.GetBulkCreateReposTask(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrTaskDoesNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBulkCreateReposTask", err)
+		}
+		return
+	}
+
+	if t.DoerID != ctx.Doer.ID && !ctx.Doer.IsAdmin {
+		isOwner, err := ctx.Org.Organization.IsOwnedBy(ctx.Doer.ID)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "IsOwnedBy", err)
+			return
+		} else if !isOwner {
+			ctx.NotFound()
+			return
+		}
+	}
+
+	job, err := toBulkRepoJob(t)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "toBulkRepoJob", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, job)
+}
+
+func toBulkRepoJob(t *models.Task) (*api.BulkRepoJob, error) {
+	results, err := t.BulkReposResults()
+	if err != nil {
+		return nil, err
+	}
+
+	job := &api.BulkRepoJob{
+		ID:      t.ID,
+		Status:  t.Status.Name(),
+		Total:   len(results),
+		Repos:   results,
+		Created: t.Created.AsTime(),
+	}
+	if t.EndTime > 0 {
+		finished := t.EndTime.AsTime()
+		job.Finished = &finished
+	}
+	for _, result := range results {
+		if result.Status == task.BulkRepoStatusImporting {
+			// imports run as separate migration tasks, so look at the state of the repository
+			repo, err := repo_model.GetRepositoryByID(result.RepoID)
+			if repo_model.IsErrRepoNotExist(err) {
+				// failed migrations delete their repository
+				result.Status = task.BulkRepoStatusFailed
+				result.Error = "import failed"
+			} else if err != nil {
+				return nil, err
+			} else if repo.Status == repo_model.RepositoryReady {
+				result.Status = task.BulkRepoStatusImported
+			}
+		}
+		// only repositories in a final state are done, imports still running are not
+		switch result.Status {
+		case task.BulkRepoStatusCreated, task.BulkRepoStatusImported:
+			job.Done++
+		case task.BulkRepoStatusFailed:
+			job.Done++
+			job.Failed++
+		}
+	}
+	return job, nil
+}
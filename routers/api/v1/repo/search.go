@@ -0,0 +1,133 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"regexp/syntax"
+
+	"code.gitea.io/gitea/modules/context"
+	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// SearchCode searches the code of a repository
+func SearchCode(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/search/code repository repoSearchCode
+	// ---
+	// summary: Search the code of a repository
+	// description: Requires the code indexer to be enabled with the trigram indexer type
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: q
+	//   in: query
+	//   description: substring or regular expression to search for
+	//   type: string
+	//   required: true
+	// - name: mode
+	//   in: query
+	//   description: how to interpret the query
+	//   type: string
+	//   enum: [substring, regexp]
+	// - name: branch
+	//   in: query
+	//   description: branch to search, defaults to the default branch
+	//   type: string
+	// - name: language
+	//   in: query
+	//   description: only return files written in this language
+	//   type: string
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CodeSearchResultList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	if !setting.Indexer.RepoIndexerEnabled || !code_indexer.SupportsAdvancedSearch() {
+		ctx.NotFound()
+		return
+	}
+
+	keyword := ctx.FormTrim("q")
+	if len(keyword) == 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "", "q is required")
+		return
+	}
+
+	mode := ctx.FormTrim("mode")
+	if mode != "" && mode != "substring" && mode != "regexp" {
+		ctx.Error(http.StatusUnprocessableEntity, "", "mode must be substring or regexp")
+		return
+	}
+
+	branch := ctx.FormTrim("branch")
+	if branch == "" {
+		branch = ctx.Repo.Repository.DefaultBranch
+	} else if branch != ctx.Repo.Repository.DefaultBranch && !code_indexer.IsIndexedBranch(branch) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "branch is not indexed")
+		return
+	}
+
+	listOptions := utils.GetListOptions(ctx)
+	listOptions.SetDefaultValues()
+	total, results, _, err := code_indexer.PerformAdvancedSearch(ctx, &code_indexer.SearchOptions{
+		RepoIDs:  []int64{ctx.Repo.Repository.ID},
+		Branch:   branch,
+		Language: ctx.FormTrim("language"),
+		Keyword:  keyword,
+		IsRegexp: mode == "regexp",
+		Page:     listOptions.Page,
+		PageSize: listOptions.PageSize,
+	})
+	if err != nil {
+		if _, ok := err.(*syntax.Error); ok {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "PerformAdvancedSearch", err)
+		return
+	}
+
+	apiResults := make([]*api.CodeSearchResult, len(results))
+	for i, result := range results {
+		apiResults[i] = &api.CodeSearchResult{
+			Filename:  result.Filename,
+			Branch:    branch,
+			CommitID:  result.CommitID,
+			Language:  result.Language,
+			HTMLURL:   ctx.Repo.Repository.HTMLURL() + "/src/commit/" + util.PathEscapeSegments(result.CommitID) + "/" + util.PathEscapeSegments(result.Filename),
+			StartLine: result.LineNumbers[0],
+			Content:   result.Lines,
+		}
+	}
+
+	ctx.SetLinkHeader(total, listOptions.PageSize)
+	ctx.SetTotalCountHeader(int64(total))
+	ctx.JSON(http.StatusOK, apiResults)
+}
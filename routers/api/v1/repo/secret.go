@@ -0,0 +1,237 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// ListSecrets list the secrets of a repository
+func ListSecrets(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/secrets repository repoListSecrets
+	// ---
+	// summary: List the secrets of a repository, their values are never returned
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/SecretList"
+
+	utils.ListSecrets(ctx, secret_model.RepoScope(ctx.Repo.Repository.ID))
+}
+
+// SetSecret creates or updates a secret of a repository
+func SetSecret(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/secrets/{name} repository repoSetSecret
+	// ---
+	// summary: Create or update a secret of a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the secret
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetSecretOption"
+	// responses:
+	//   "201":
+	//     description: secret created
+	//   "204":
+	//     description: secret updated
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.SetSecret(ctx, secret_model.RepoScope(ctx.Repo.Repository.ID), secret_model.TypeSecret)
+}
+
+// DeleteSecret deletes a secret of a repository
+func DeleteSecret(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/secrets/{name} repository repoDeleteSecret
+	// ---
+	// summary: Delete a secret of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the secret
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteSecret(ctx, secret_model.RepoScope(ctx.Repo.Repository.ID), secret_model.TypeSecret)
+}
+
+// ListVariables list the variables of a repository
+func ListVariables(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/variables repository repoListVariables
+	// ---
+	// summary: List the variables of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/VariableList"
+
+	utils.ListVariables(ctx, secret_model.RepoScope(ctx.Repo.Repository.ID))
+}
+
+// GetVariable get a variable of a repository
+func GetVariable(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/variables/{name} repository repoGetVariable
+	// ---
+	// summary: Get a variable of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Variable"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.GetVariable(ctx, secret_model.RepoScope(ctx.Repo.Repository.ID))
+}
+
+// SetVariable creates or updates a variable of a repository
+func SetVariable(ctx *context.APIContext) {
+	// swagger:operation PUT /repos/{owner}/{repo}/variables/{name} repository repoSetVariable
+	// ---
+	// summary: Create or update a variable of a repository
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/SetSecretOption"
+	// responses:
+	//   "201":
+	//     description: variable created
+	//   "204":
+	//     description: variable updated
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	utils.SetSecret(ctx, secret_model.RepoScope(ctx.Repo.Repository.ID), secret_model.TypeVariable)
+}
+
+// DeleteVariable deletes a variable of a repository
+func DeleteVariable(ctx *context.APIContext) {
+	// swagger:operation DELETE /repos/{owner}/{repo}/variables/{name} repository repoDeleteVariable
+	// ---
+	// summary: Delete a variable of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: name
+	//   in: path
+	//   description: name of the variable
+	//   type: string
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	utils.DeleteSecret(ctx, secret_model.RepoScope(ctx.Repo.Repository.ID), secret_model.TypeVariable)
+}
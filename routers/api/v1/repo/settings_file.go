@@ -0,0 +1,147 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/services/reposettings"
+)
+
+// GetSettingsFileDrift returns the changes the settings file of the default branch would apply
+func GetSettingsFileDrift(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/settings_file/drift repository repoGetSettingsFileDrift
+	// ---
+	// summary: Get the differences between the settings file of the default branch and the settings of a repository
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoSettingsReport"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	commit, cfg := getSettingsFile(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	changes, err := reposettings.Drift(ctx, ctx.Repo.Repository, cfg)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Drift", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, toRepoSettingsReport(commit, changes))
+}
+
+// ApplySettingsFile applies the settings file of the default branch
+func ApplySettingsFile(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/settings_file/apply repository repoApplySettingsFile
+	// ---
+	// summary: Apply the settings file of the default branch to a repository
+	// description: Changes which could not be applied are reported with their error.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoSettingsReport"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	commit, cfg := getSettingsFile(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	changes, err := reposettings.Drift(ctx, ctx.Repo.Repository, cfg)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "Drift", err)
+		return
+	}
+	reposettings.Apply(ctx, changes)
+	ctx.JSON(http.StatusOK, toRepoSettingsReport(commit, changes))
+}
+
+// getSettingsFile returns the head commit of the default branch and its settings file,
+// it writes the error response if the settings file is missing or invalid
+func getSettingsFile(ctx *context.APIContext) (*git.Commit, *reposettings.Config) {
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.NotFound()
+		return nil, nil
+	}
+
+	commit, err := ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
+	if err != nil {
+		if git.IsErrBranchNotExist(err) || git.IsErrNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetBranchCommit", err)
+		}
+		return nil, nil
+	}
+
+	cfg, err := reposettings.GetConfig(commit)
+	if err != nil {
+		if reposettings.IsErrInvalidSettings(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetConfig", err)
+		}
+		return nil, nil
+	} else if cfg == nil {
+		ctx.NotFound()
+		return nil, nil
+	}
+	return commit, cfg
+}
+
+func toRepoSettingsReport(commit *git.Commit, changes []*reposettings.Change) *api.RepoSettingsReport {
+	report := &api.RepoSettingsReport{
+		Path:     reposettings.FilePath,
+		CommitID: commit.ID.String(),
+		Changes:  make([]*api.RepoSettingsChange, 0, len(changes)),
+	}
+	for _, change := range changes {
+		apiChange := &api.RepoSettingsChange{
+			Section: string(change.Section),
+			Name:    change.Name,
+			Action:  string(change.Action),
+		}
+		if change.Err != nil {
+			apiChange.Error = change.Err.Error()
+		}
+		report.Changes = append(report.Changes, apiChange)
+	}
+	return report
+}
@@ -0,0 +1,280 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/indexer/stats"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+// GetContributorStats returns the weekly commits, additions and deletions of every contributor
+func GetContributorStats(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/contributors repository repoGetContributorStats
+	// ---
+	// summary: Get the weekly commits, additions and deletions of every contributor to the default branch
+	// description: The statistics are generated in the background. While they are being generated
+	//   the response is empty with status 202 and the request should be repeated later.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/ContributorStatsList"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.JSON(http.StatusOK, []*api.ContributorStats{})
+		return
+	}
+
+	commitID, err := ctx.Repo.GitRepo.GetBranchCommitID(ctx.Repo.Repository.DefaultBranch)
+	if err != nil {
+		if git.IsErrBranchNotExist(err) || git.IsErrNotExist(err) {
+			ctx.JSON(http.StatusOK, []*api.ContributorStats{})
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetBranchCommitID", err)
+		return
+	}
+
+	contributors, err := stats.GetContributorStats(ctx.Repo.GitRepo, ctx.Repo.Repository, commitID)
+	if err == stats.ErrAwaitGeneration {
+		ctx.Status(http.StatusAccepted)
+		return
+	} else if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetContributorStats", err)
+		return
+	}
+
+	apiContributors, err := convert.ToContributorStats(contributors, ctx.Doer)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToContributorStats", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiContributors)
+}
+
+// getCodeStats returns the code statistics of the default branch, nil if the repository is empty.
+// It writes the response if the statistics are being generated or can't be returned.
+func getCodeStats(ctx *context.APIContext) *repo_model.RepoCodeStats {
+	if ctx.Repo.Repository.IsEmpty {
+		return nil
+	}
+
+	commitID, err := ctx.Repo.GitRepo.GetBranchCommitID(ctx.Repo.Repository.DefaultBranch)
+	if err != nil {
+		if git.IsErrBranchNotExist(err) || git.IsErrNotExist(err) {
+			return nil
+		}
+		ctx.Error(http.StatusInternalServerError, "GetBranchCommitID", err)
+		return nil
+	}
+
+	codeStats, err := stats.GetCodeStats(ctx.Repo.Repository, commitID)
+	if err == stats.ErrAwaitGeneration {
+		ctx.Status(http.StatusAccepted)
+		return nil
+	} else if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCodeStats", err)
+		return nil
+	}
+	return codeStats
+}
+
+// GetCodeFrequency returns the weekly additions and deletions of the default branch
+func GetCodeFrequency(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/code_frequency repository repoGetCodeFrequency
+	// ---
+	// summary: Get the weekly additions and deletions of the default branch
+	// description: The statistics are generated in the background. While they are being generated
+	//   the response is empty with status 202 and the request should be repeated later.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CodeFrequencyWeekList"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	codeStats := getCodeStats(ctx)
+	if ctx.Written() {
+		return
+	}
+	if codeStats == nil {
+		ctx.JSON(http.StatusOK, []*api.CodeFrequencyWeek{})
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToCodeFrequency(codeStats.CodeFrequency))
+}
+
+// GetPunchCard returns the number of commits to the default branch by hour of the day of the week
+func GetPunchCard(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/punch_card repository repoGetPunchCard
+	// ---
+	// summary: Get the number of commits to the default branch by hour of the day of the week
+	// description: The hours are those of the time zones of the authors. The statistics are generated
+	//   in the background. While they are being generated the response is empty with status 202 and
+	//   the request should be repeated later.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/PunchCardHourList"
+	//   "202":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	codeStats := getCodeStats(ctx)
+	if ctx.Written() {
+		return
+	}
+	if codeStats == nil {
+		ctx.JSON(http.StatusOK, convert.ToPunchCard(git.NewPunchCard()))
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToPunchCard(codeStats.PunchCard))
+}
+
+// GetCommitActivity returns the number of commits to a branch of a repository by day
+func GetCommitActivity(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/stats/commits repository repoGetCommitActivity
+	// ---
+	// summary: Get the number of commits authored on every day with commits, by default during the last year
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: sha
+	//   in: query
+	//   description: branch, tag or commit to count the commits of (default branch of the repository)
+	//   type: string
+	// - name: timezone
+	//   in: query
+	//   description: IANA name of the time zone of the days, e.g. Europe/Berlin (default UTC)
+	//   type: string
+	// - name: since
+	//   in: query
+	//   description: Only count commits authored after this time, in RFC 3339 format (default one year ago)
+	//   type: string
+	//   format: date-time
+	// - name: before
+	//   in: query
+	//   description: Only count commits authored before this time, in RFC 3339 format
+	//   type: string
+	//   format: date-time
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/CommitActivityDayList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	loc := utils.GetTimezone(ctx)
+	if ctx.Written() {
+		return
+	}
+	before, since, err := context.GetQueryBeforeSince(ctx.Context)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return
+	}
+	if since == 0 {
+		since = int64(timeutil.TimeStampNow()) - 31536000
+	}
+	var until time.Time
+	if before != 0 {
+		until = time.Unix(before, 0)
+	}
+
+	if ctx.Repo.Repository.IsEmpty {
+		ctx.JSON(http.StatusOK, []*api.CommitActivityDay{})
+		return
+	}
+
+	sha := ctx.FormString("sha")
+	if len(sha) == 0 {
+		sha = ctx.Repo.Repository.DefaultBranch
+	}
+	commit, err := ctx.Repo.GitRepo.GetCommit(sha)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			ctx.NotFound(err)
+			return
+		}
+		ctx.Error(http.StatusInternalServerError, "GetCommit", err)
+		return
+	}
+
+	days, err := ctx.Repo.GitRepo.GetCommitsByDay(commit.ID.String(), time.Unix(since, 0), until, loc)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetCommitsByDay", err)
+		return
+	}
+	apiDays := make([]*api.CommitActivityDay, 0, len(days))
+	for _, day := range days {
+		apiDays = append(apiDays, &api.CommitActivityDay{
+			Date:    day.Date,
+			Commits: day.Commits,
+		})
+	}
+	ctx.JSON(http.StatusOK, apiDays)
+}
@@ -12,6 +12,9 @@ import (
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
 )
 
 // ListTeams list a repository's teams
@@ -133,6 +136,10 @@ func AddTeam(ctx *context.APIContext) {
 	//   description: team name
 	//   type: string
 	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/AddRepoTeamOption"
 	// responses:
 	//   "204":
 	//     "$ref": "#/responses/empty"
@@ -141,7 +148,12 @@ func AddTeam(ctx *context.APIContext) {
 	//   "405":
 	//     "$ref": "#/responses/error"
 
-	changeRepoTeam(ctx, true)
+	form := web.GetForm(ctx).(*api.AddRepoTeamOption)
+	expires, ok := parseAccessExpiry(ctx, form.Expires)
+	if !ok {
+		return
+	}
+	changeRepoTeam(ctx, true, expires)
 }
 
 // DeleteTeam delete a team from a repository
@@ -175,10 +187,10 @@ func DeleteTeam(ctx *context.APIContext) {
 	//   "405":
 	//     "$ref": "#/responses/error"
 
-	changeRepoTeam(ctx, false)
+	changeRepoTeam(ctx, false, 0)
 }
 
-func changeRepoTeam(ctx *context.APIContext, add bool) {
+func changeRepoTeam(ctx *context.APIContext, add bool, expires timeutil.TimeStamp) {
 	if !ctx.Repo.Owner.IsOrganization() {
 		ctx.Error(http.StatusMethodNotAllowed, "noOrg", "repo is not owned by an organization")
 	}
@@ -199,7 +211,13 @@ func changeRepoTeam(ctx *context.APIContext, add bool) {
 			ctx.Error(http.StatusUnprocessableEntity, "alreadyAdded", fmt.Errorf("team '%s' is already added to repo", team.Name))
 			return
 		}
-		err = models.AddRepository(team, ctx.Repo.Repository)
+		if expires > 0 && team.IncludesAllRepositories {
+			ctx.Error(http.StatusUnprocessableEntity, "includesAllRepositories", fmt.Errorf("team '%s' has access to all repositories", team.Name))
+			return
+		}
+		if err = models.AddRepository(team, ctx.Repo.Repository); err == nil && expires > 0 {
+			err = organization.SetTeamRepoExpiry(ctx, team.ID, ctx.Repo.Repository.ID, expires, ctx.Doer.ID)
+		}
 	} else {
 		if !repoHasTeam {
 			ctx.Error(http.StatusUnprocessableEntity, "notAdded", fmt.Errorf("team '%s' was not added to repo", team.Name))
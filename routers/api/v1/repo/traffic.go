@@ -0,0 +1,141 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// GetCloneTraffic returns the clones of a repository over the last 14 days
+func GetCloneTraffic(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/traffic/clones repository repoGetCloneTraffic
+	// ---
+	// summary: Get the clones of a repository over the last 14 days
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoTraffic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	getTraffic(ctx, repo_model.TrafficKindClone)
+}
+
+// GetFetchTraffic returns the fetches of a repository over the last 14 days
+func GetFetchTraffic(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/traffic/fetches repository repoGetFetchTraffic
+	// ---
+	// summary: Get the fetches of a repository over the last 14 days
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoTraffic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+
+	getTraffic(ctx, repo_model.TrafficKindFetch)
+}
+
+func getTraffic(ctx *context.APIContext, kind repo_model.TrafficKind) {
+	if kind.IsDownload() && !setting.Repository.EnableDownloadStatistics {
+		ctx.NotFound()
+		return
+	}
+	traffic, err := repo_model.GetTraffic(ctx, ctx.Repo.Repository.ID, kind)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetTraffic", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToRepoTraffic(traffic))
+}
+
+// GetArchiveTraffic returns the archive downloads of a repository over the last 14 days
+func GetArchiveTraffic(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/traffic/archives repository repoGetArchiveTraffic
+	// ---
+	// summary: Get the archive downloads of a repository over the last 14 days
+	// description: Only available if the download statistics are enabled on the instance.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoTraffic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	getTraffic(ctx, repo_model.TrafficKindArchive)
+}
+
+// GetRawTraffic returns the raw file hits of a repository over the last 14 days
+func GetRawTraffic(ctx *context.APIContext) {
+	// swagger:operation GET /repos/{owner}/{repo}/traffic/raw repository repoGetRawTraffic
+	// ---
+	// summary: Get the raw file hits of a repository over the last 14 days
+	// description: Only available if the download statistics are enabled on the instance.
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/RepoTraffic"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	getTraffic(ctx, repo_model.TrafficKindRaw)
+}
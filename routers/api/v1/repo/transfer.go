@@ -7,6 +7,7 @@ package repo
 import (
 	"fmt"
 	"net/http"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/organization"
@@ -17,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/convert"
 	"code.gitea.io/gitea/modules/log"
 	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
@@ -99,6 +101,20 @@ func Transfer(ctx *context.APIContext) {
 		}
 	}
 
+	var schedule models.RepoTransferSchedule
+	if opts.ScheduledAt != nil {
+		if !opts.ScheduledAt.After(time.Now()) {
+			ctx.Error(http.StatusUnprocessableEntity, "repoTransfer", "The cutover must be scheduled in the future")
+			return
+		}
+		schedule.ScheduledUnix = timeutil.TimeStamp(opts.ScheduledAt.Unix())
+	}
+	if opts.RedirectDays < 0 {
+		ctx.Error(http.StatusUnprocessableEntity, "repoTransfer", "The number of days of the redirect must not be negative")
+		return
+	}
+	schedule.RedirectDays = opts.RedirectDays
+
 	if ctx.Repo.GitRepo != nil {
 		ctx.Repo.GitRepo.Close()
 		ctx.Repo.GitRepo = nil
@@ -106,7 +122,7 @@ func Transfer(ctx *context.APIContext) {
 
 	oldFullname := ctx.Repo.Repository.FullName()
 
-	if err := repo_service.StartRepositoryTransfer(ctx.Doer, newOwner, ctx.Repo.Repository, teams); err != nil {
+	if err := repo_service.StartRepositoryTransfer(ctx.Doer, newOwner, ctx.Repo.Repository, teams, schedule); err != nil {
 		if models.IsErrRepoTransferInProgress(err) {
 			ctx.Error(http.StatusConflict, "StartRepositoryTransfer", err)
 			return
@@ -131,6 +147,50 @@ func Transfer(ctx *context.APIContext) {
 	ctx.JSON(http.StatusAccepted, convert.ToRepo(ctx.Repo.Repository, perm.AccessModeAdmin))
 }
 
+// CancelTransfer cancel a pending or scheduled repo transfer
+func CancelTransfer(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/transfer/cancel repository cancelRepoTransfer
+	// ---
+	// summary: Cancel a pending or scheduled repo transfer
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo to transfer
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo to transfer
+	//   type: string
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/Repository"
+	//   "403":
+	//     "$ref": "#/responses/forbidden"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if _, err := models.GetPendingRepositoryTransfer(ctx.Repo.Repository); err != nil {
+		if models.IsErrNoPendingTransfer(err) {
+			ctx.NotFound()
+			return
+		}
+		ctx.InternalServerError(err)
+		return
+	}
+
+	if err := models.CancelRepositoryTransfer(ctx.Repo.Repository); err != nil {
+		ctx.InternalServerError(err)
+		return
+	}
+
+	log.Trace("Repository transfer cancelled: %s", ctx.Repo.Repository.FullName())
+	ctx.JSON(http.StatusOK, convert.ToRepo(ctx.Repo.Repository, ctx.Repo.AccessMode))
+}
+
 // AcceptTransfer accept a repo transfer
 func AcceptTransfer(ctx *context.APIContext) {
 	// swagger:operation POST /repos/{owner}/{repo}/transfer/accept repository acceptRepoTransfer
@@ -227,7 +287,7 @@ func acceptOrRejectRepoTransfer(ctx *context.APIContext, accept bool) error {
 	}
 
 	if accept {
-		return repo_service.TransferOwnership(repoTransfer.Doer, repoTransfer.Recipient, ctx.Repo.Repository, repoTransfer.Teams)
+		return repo_service.AcceptRepositoryTransfer(ctx, ctx.Doer, ctx.Repo.Repository, repoTransfer)
 	}
 
 	return models.CancelRepositoryTransfer(ctx.Repo.Repository)
@@ -8,6 +8,8 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/modules/context"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
 	files_service "code.gitea.io/gitea/services/repository/files"
 )
 
@@ -67,3 +69,50 @@ func GetTree(ctx *context.APIContext) {
 		ctx.JSON(http.StatusOK, tree)
 	}
 }
+
+// GetTrees get multiple trees of a repository at once
+func GetTrees(ctx *context.APIContext) {
+	// swagger:operation POST /repos/{owner}/{repo}/git/trees repository GetTrees
+	// ---
+	// summary: Gets multiple trees of a repository by their SHAs or by their paths at a ref
+	// description: Each tree contains the first page of its entries, the 'truncated' field of a tree is true if it has more entries.
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: owner
+	//   in: path
+	//   description: owner of the repo
+	//   type: string
+	//   required: true
+	// - name: repo
+	//   in: path
+	//   description: name of the repo
+	//   type: string
+	//   required: true
+	// - name: body
+	//   in: body
+	//   schema:
+	//     "$ref": "#/definitions/GetGitTreesOptions"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/GitTreesResponse"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.GetGitTreesOptions)
+	commit, ok := getBatchCommit(ctx, form.Ref, form.SHAs, form.Paths)
+	if !ok {
+		return
+	}
+
+	trees, err := files_service.GetTrees(ctx, ctx.Repo.Repository, ctx.Repo.GitRepo, commit, form.SHAs, form.Paths, form.Recursive)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetTrees", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, trees)
+}
@@ -8,6 +8,7 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/emoji"
 	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 )
@@ -22,10 +23,16 @@ func GetGeneralUISettings(ctx *context.APIContext) {
 	// responses:
 	//   "200":
 	//     "$ref": "#/responses/GeneralUISettings"
+	uploaded := emoji.Uploaded()
+	customEmojiURLs := make(map[string]string, len(uploaded))
+	for _, name := range uploaded {
+		customEmojiURLs[name] = emoji.UploadedAbsoluteURL(name)
+	}
 	ctx.JSON(http.StatusOK, api.GeneralUISettings{
 		DefaultTheme:     setting.UI.DefaultTheme,
-		AllowedReactions: setting.UI.Reactions,
-		CustomEmojis:     setting.UI.CustomEmojis,
+		AllowedReactions: emoji.Reactions(),
+		CustomEmojis:     emoji.CustomEmojis(),
+		CustomEmojiURLs:  customEmojiURLs,
 	})
 }
 
@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ActionRun
+// swagger:response ActionRun
+type swaggerResponseActionRun struct {
+	// in:body
+	Body api.ActionRun `json:"body"`
+}
+
+// ActionRunList
+// swagger:response ActionRunList
+type swaggerResponseActionRunList struct {
+	// in:body
+	Body []api.ActionRun `json:"body"`
+}
+
+// ActionRunJobList
+// swagger:response ActionRunJobList
+type swaggerResponseActionRunJobList struct {
+	// in:body
+	Body []api.ActionRunJob `json:"body"`
+}
+
+// ActionArtifactList
+// swagger:response ActionArtifactList
+type swaggerResponseActionArtifactList struct {
+	// in:body
+	Body []api.ActionArtifact `json:"body"`
+}
+
+// ActionRunnerList
+// swagger:response ActionRunnerList
+type swaggerResponseActionRunnerList struct {
+	// in:body
+	Body []api.ActionRunner `json:"body"`
+}
+
+// ActionRunnerRegistrationToken
+// swagger:response ActionRunnerRegistrationToken
+type swaggerResponseActionRunnerRegistrationToken struct {
+	// in:body
+	Body api.ActionRunnerRegistrationToken `json:"body"`
+}
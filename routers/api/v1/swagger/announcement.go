@@ -0,0 +1,23 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Announcement
+// swagger:response Announcement
+type swaggerResponseAnnouncement struct {
+	// in:body
+	Body api.Announcement `json:"body"`
+}
+
+// AnnouncementList
+// swagger:response AnnouncementList
+type swaggerResponseAnnouncementList struct {
+	// in:body
+	Body []api.Announcement `json:"body"`
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Discussion
+// swagger:response Discussion
+type swaggerResponseDiscussion struct {
+	// in:body
+	Body api.Discussion `json:"body"`
+}
+
+// DiscussionList
+// swagger:response DiscussionList
+type swaggerResponseDiscussionList struct {
+	// in:body
+	Body []api.Discussion `json:"body"`
+}
+
+// DiscussionCategoryList
+// swagger:response DiscussionCategoryList
+type swaggerResponseDiscussionCategoryList struct {
+	// in:body
+	Body []api.DiscussionCategory `json:"body"`
+}
+
+// DiscussionComment
+// swagger:response DiscussionComment
+type swaggerResponseDiscussionComment struct {
+	// in:body
+	Body api.DiscussionComment `json:"body"`
+}
+
+// DiscussionCommentList
+// swagger:response DiscussionCommentList
+type swaggerResponseDiscussionCommentList struct {
+	// in:body
+	Body []api.DiscussionComment `json:"body"`
+}
@@ -36,6 +36,20 @@ type swaggerResponseCommentList struct {
 	Body []api.Comment `json:"body"`
 }
 
+// ContentHistoryList
+// swagger:response ContentHistoryList
+type swaggerResponseContentHistoryList struct {
+	// in:body
+	Body []api.ContentHistory `json:"body"`
+}
+
+// ContentHistoryDetail
+// swagger:response ContentHistoryDetail
+type swaggerResponseContentHistoryDetail struct {
+	// in:body
+	Body api.ContentHistoryDetail `json:"body"`
+}
+
 // TimelineList
 // swagger:response TimelineList
 type swaggerResponseTimelineList struct {
@@ -43,6 +57,13 @@ type swaggerResponseTimelineList struct {
 	Body []api.TimelineComment `json:"body"`
 }
 
+// IssueDependencyGraph
+// swagger:response IssueDependencyGraph
+type swaggerResponseIssueDependencyGraph struct {
+	// in:body
+	Body api.IssueDependencyGraph `json:"body"`
+}
+
 // Label
 // swagger:response Label
 type swaggerResponseLabel struct {
@@ -85,6 +106,13 @@ type swaggerResponseTrackedTimeList struct {
 	Body []api.TrackedTime `json:"body"`
 }
 
+// TimeReport
+// swagger:response TimeReport
+type swaggerResponseTimeReport struct {
+	// in:body
+	Body api.TimeReport `json:"body"`
+}
+
 // IssueDeadline
 // swagger:response IssueDeadline
 type swaggerIssueDeadline struct {
@@ -126,3 +154,17 @@ type swaggerReactionList struct {
 	// in:body
 	Body []api.Reaction `json:"body"`
 }
+
+// IssueFilter
+// swagger:response IssueFilter
+type swaggerIssueFilter struct {
+	// in:body
+	Body api.IssueFilter `json:"body"`
+}
+
+// IssueFilterList
+// swagger:response IssueFilterList
+type swaggerIssueFilterList struct {
+	// in:body
+	Body []api.IssueFilter `json:"body"`
+}
@@ -21,3 +21,10 @@ type swaggerResponseStringSlice struct {
 	// in:body
 	Body []string `json:"body"`
 }
+
+// MarkdownTableOfContents
+// swagger:response MarkdownTableOfContents
+type swaggerResponseMarkdownTableOfContents struct {
+	// in:body
+	Body []api.MarkdownHeader `json:"body"`
+}
@@ -18,6 +18,12 @@ type swaggerParameterBodies struct {
 	// in:body
 	AddCollaboratorOption api.AddCollaboratorOption
 
+	// in:body
+	SetCollaboratorsOption api.SetCollaboratorsOption
+
+	// in:body
+	AddRepoTeamOption api.AddRepoTeamOption
+
 	// in:body
 	CreateEmailOption api.CreateEmailOption
 	// in:body
@@ -113,6 +119,9 @@ type swaggerParameterBodies struct {
 	// in:body
 	EditAttachmentOptions api.EditAttachmentOptions
 
+	// in:body
+	CreateExternalAttachmentOption api.CreateExternalAttachmentOption
+
 	// in:body
 	CreateFileOptions api.CreateFileOptions
 
@@ -158,6 +167,9 @@ type swaggerParameterBodies struct {
 	// in:body
 	MigrateRepoOptions api.MigrateRepoOptions
 
+	// in:body
+	BulkRepoManifest api.BulkRepoManifest
+
 	// in:body
 	PullReviewRequestOptions api.PullReviewRequestOptions
 
@@ -172,4 +184,73 @@ type swaggerParameterBodies struct {
 
 	// in:body
 	CreateWikiPageOptions api.CreateWikiPageOptions
+
+	// in:body
+	CreateIssueFilterOption api.CreateIssueFilterOption
+
+	// in:body
+	EditIssueFilterOption api.EditIssueFilterOption
+
+	// in:body
+	AttachForkOption api.AttachForkOption
+
+	// in:body
+	CreateOrgBranchProtectionOption api.CreateOrgBranchProtectionOption
+
+	// in:body
+	EditOrgBranchProtectionOption api.EditOrgBranchProtectionOption
+
+	// in:body
+	OrgPolicy api.OrgPolicy
+
+	// in:body
+	SetSecretOption api.SetSecretOption
+
+	// in:body
+	CreateAnnouncementOption api.CreateAnnouncementOption
+
+	// in:body
+	EditAnnouncementOption api.EditAnnouncementOption
+
+	// in:body
+	CreateCheckRunOption api.CreateCheckRunOption
+
+	// in:body
+	EditCheckRunOption api.EditCheckRunOption
+
+	// in:body
+	EditPackagePolicyOption api.EditPackagePolicyOption
+
+	// in:body
+	GetGitBlobsOptions api.GetGitBlobsOptions
+
+	// in:body
+	GetGitTreesOptions api.GetGitTreesOptions
+
+	// in:body
+	CreateCommitOptions api.CreateCommitOptions
+
+	// in:body
+	CherryPickCommitOptions api.CherryPickCommitOptions
+
+	// in:body
+	RepoHousekeepingOptions api.RepoHousekeepingOptions
+
+	// in:body
+	CreatePreviewEnvironmentOption api.CreatePreviewEnvironmentOption
+
+	// in:body
+	EditPreviewEnvironmentOption api.EditPreviewEnvironmentOption
+
+	// in:body
+	CreateDeployTokenOption api.CreateDeployTokenOption
+
+	// in:body
+	RewritePullRequestOption api.RewritePullRequestOption
+
+	// in:body
+	CreateDiscussionOption api.CreateDiscussionOption
+
+	// in:body
+	CreateDiscussionCommentOption api.CreateDiscussionCommentOption
 }
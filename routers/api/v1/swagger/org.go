@@ -42,3 +42,31 @@ type swaggerResponseOrganizationPermissions struct {
 	// in:body
 	Body api.OrganizationPermissions `json:"body"`
 }
+
+// OrgBranchProtection
+// swagger:response OrgBranchProtection
+type swaggerResponseOrgBranchProtection struct {
+	// in:body
+	Body api.OrgBranchProtection `json:"body"`
+}
+
+// OrgBranchProtectionList
+// swagger:response OrgBranchProtectionList
+type swaggerResponseOrgBranchProtectionList struct {
+	// in:body
+	Body []api.OrgBranchProtection `json:"body"`
+}
+
+// OrgPolicy
+// swagger:response OrgPolicy
+type swaggerResponseOrgPolicy struct {
+	// in:body
+	Body api.OrgPolicy `json:"body"`
+}
+
+// OrgPolicyReport
+// swagger:response OrgPolicyReport
+type swaggerResponseOrgPolicyReport struct {
+	// in:body
+	Body api.OrgPolicyReport `json:"body"`
+}
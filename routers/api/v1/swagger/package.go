@@ -28,3 +28,10 @@ type swaggerResponsePackageFileList struct {
 	// in:body
 	Body []api.PackageFile `json:"body"`
 }
+
+// PackagePolicy
+// swagger:response PackagePolicy
+type swaggerResponsePackagePolicy struct {
+	// in:body
+	Body api.PackagePolicy `json:"body"`
+}
@@ -36,6 +36,27 @@ type swaggerResponseBranchList struct {
 	Body []api.Branch `json:"body"`
 }
 
+// DeletedBranchList
+// swagger:response DeletedBranchList
+type swaggerResponseDeletedBranchList struct {
+	// in:body
+	Body []api.DeletedBranch `json:"body"`
+}
+
+// RepoAuditLogList
+// swagger:response RepoAuditLogList
+type swaggerResponseRepoAuditLogList struct {
+	// in:body
+	Body []api.RepoAuditLog `json:"body"`
+}
+
+// RepoTraffic
+// swagger:response RepoTraffic
+type swaggerResponseRepoTraffic struct {
+	// in:body
+	Body api.RepoTraffic `json:"body"`
+}
+
 // BranchProtection
 // swagger:response BranchProtection
 type swaggerResponseBranchProtection struct {
@@ -127,6 +148,13 @@ type swaggerResponseReleaseList struct {
 	Body []api.Release `json:"body"`
 }
 
+// ReleaseVerification
+// swagger:response ReleaseVerification
+type swaggerResponseReleaseVerification struct {
+	// in:body
+	Body api.ReleaseVerification `json:"body"`
+}
+
 // PullRequest
 // swagger:response PullRequest
 type swaggerResponsePullRequest struct {
@@ -141,6 +169,13 @@ type swaggerResponsePullRequestList struct {
 	Body []api.PullRequest `json:"body"`
 }
 
+// PullRequestRewriteResult
+// swagger:response PullRequestRewriteResult
+type swaggerResponsePullRequestRewriteResult struct {
+	// in:body
+	Body api.PullRequestRewriteResult `json:"body"`
+}
+
 // PullReview
 // swagger:response PullReview
 type swaggerResponsePullReview struct {
@@ -183,6 +218,55 @@ type swaggerResponseCommitStatusList struct {
 	Body []api.CommitStatus `json:"body"`
 }
 
+// CheckRun
+// swagger:response CheckRun
+type swaggerResponseCheckRun struct {
+	// in:body
+	Body api.CheckRun `json:"body"`
+}
+
+// CheckRunList
+// swagger:response CheckRunList
+type swaggerResponseCheckRunList struct {
+	// in:body
+	Body []api.CheckRun `json:"body"`
+}
+
+// CheckRunAnnotationList
+// swagger:response CheckRunAnnotationList
+type swaggerResponseCheckRunAnnotationList struct {
+	// in:body
+	Body []api.CheckRunAnnotation `json:"body"`
+}
+
+// PreviewEnvironment
+// swagger:response PreviewEnvironment
+type swaggerResponsePreviewEnvironment struct {
+	// in:body
+	Body api.PreviewEnvironment `json:"body"`
+}
+
+// PreviewEnvironmentList
+// swagger:response PreviewEnvironmentList
+type swaggerResponsePreviewEnvironmentList struct {
+	// in:body
+	Body []api.PreviewEnvironment `json:"body"`
+}
+
+// DeployToken
+// swagger:response DeployToken
+type swaggerResponseDeployToken struct {
+	// in:body
+	Body api.DeployToken `json:"body"`
+}
+
+// DeployTokenList
+// swagger:response DeployTokenList
+type swaggerResponseDeployTokenList struct {
+	// in:body
+	Body []api.DeployToken `json:"body"`
+}
+
 // WatchInfo
 // swagger:response WatchInfo
 type swaggerResponseWatchInfo struct {
@@ -211,6 +295,13 @@ type swaggerResponseAttachment struct {
 	Body api.Attachment `json:"body"`
 }
 
+// AttachmentUsageList
+// swagger:response AttachmentUsageList
+type swaggerResponseAttachmentUsageList struct {
+	// in: body
+	Body []api.AttachmentUsage `json:"body"`
+}
+
 // GitTreeResponse
 // swagger:response GitTreeResponse
 type swaggerGitTreeResponse struct {
@@ -218,6 +309,13 @@ type swaggerGitTreeResponse struct {
 	Body api.GitTreeResponse `json:"body"`
 }
 
+// GitTreesResponse
+// swagger:response GitTreesResponse
+type swaggerGitTreesResponse struct {
+	// in: body
+	Body api.GitTreesResponse `json:"body"`
+}
+
 // GitBlobResponse
 // swagger:response GitBlobResponse
 type swaggerGitBlobResponse struct {
@@ -225,6 +323,13 @@ type swaggerGitBlobResponse struct {
 	Body api.GitBlobResponse `json:"body"`
 }
 
+// GitBlobsResponse
+// swagger:response GitBlobsResponse
+type swaggerGitBlobsResponse struct {
+	// in: body
+	Body api.GitBlobsResponse `json:"body"`
+}
+
 // Commit
 // swagger:response Commit
 type swaggerCommit struct {
@@ -275,6 +380,13 @@ type swaggerFileResponse struct {
 	Body api.FileResponse `json:"body"`
 }
 
+// CherryPickCommitResponse
+// swagger:response CherryPickCommitResponse
+type swaggerCherryPickCommitResponse struct {
+	// in: body
+	Body api.CherryPickCommitResponse `json:"body"`
+}
+
 // ContentsResponse
 // swagger:response ContentsResponse
 type swaggerContentsResponse struct {
@@ -317,6 +429,34 @@ type swaggerLanguageStatistics struct {
 	Body map[string]int64 `json:"body"`
 }
 
+// ContributorStatsList
+// swagger:response ContributorStatsList
+type swaggerContributorStatsList struct {
+	// in: body
+	Body []api.ContributorStats `json:"body"`
+}
+
+// CodeFrequencyWeekList
+// swagger:response CodeFrequencyWeekList
+type swaggerCodeFrequencyWeekList struct {
+	// in: body
+	Body []api.CodeFrequencyWeek `json:"body"`
+}
+
+// PunchCardHourList
+// swagger:response PunchCardHourList
+type swaggerPunchCardHourList struct {
+	// in: body
+	Body []api.PunchCardHour `json:"body"`
+}
+
+// CommitActivityDayList
+// swagger:response CommitActivityDayList
+type swaggerCommitActivityDayList struct {
+	// in: body
+	Body []api.CommitActivityDay `json:"body"`
+}
+
 // CombinedStatus
 // swagger:response CombinedStatus
 type swaggerCombinedStatus struct {
@@ -351,3 +491,45 @@ type swaggerRepoCollaboratorPermission struct {
 	// in:body
 	Body api.RepoCollaboratorPermission `json:"body"`
 }
+
+// CollaboratorChanges
+// swagger:response CollaboratorChanges
+type swaggerCollaboratorChanges struct {
+	// in:body
+	Body api.CollaboratorChanges `json:"body"`
+}
+
+// TemporaryAccessList
+// swagger:response TemporaryAccessList
+type swaggerTemporaryAccessList struct {
+	// in:body
+	Body []api.TemporaryAccess `json:"body"`
+}
+
+// CodeSearchResultList
+// swagger:response CodeSearchResultList
+type swaggerCodeSearchResultList struct {
+	// in:body
+	Body []api.CodeSearchResult `json:"body"`
+}
+
+// BulkRepoJob
+// swagger:response BulkRepoJob
+type swaggerBulkRepoJob struct {
+	// in:body
+	Body api.BulkRepoJob `json:"body"`
+}
+
+// RepoHousekeepingJob
+// swagger:response RepoHousekeepingJob
+type swaggerRepoHousekeepingJob struct {
+	// in:body
+	Body api.RepoHousekeepingJob `json:"body"`
+}
+
+// RepoSettingsReport
+// swagger:response RepoSettingsReport
+type swaggerRepoSettingsReport struct {
+	// in:body
+	Body api.RepoSettingsReport `json:"body"`
+}
@@ -0,0 +1,16 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package swagger
+
+import (
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// SelfTestReport
+// swagger:response SelfTestReport
+type swaggerResponseSelfTestReport struct {
+	// in:body
+	Body api.SelfTestReport `json:"body"`
+}
@@ -43,6 +43,13 @@ type swaggerResponseUserHeatmapData struct {
 	Body []models.UserHeatmapData `json:"body"`
 }
 
+// UserContributionDayList
+// swagger:response UserContributionDayList
+type swaggerResponseUserContributionDayList struct {
+	// in:body
+	Body []api.UserContributionDay `json:"body"`
+}
+
 // UserSettings
 // swagger:response UserSettings
 type swaggerResponseUserSettings struct {
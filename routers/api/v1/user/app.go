@@ -10,14 +10,18 @@ import (
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/auth"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/api/v1/utils"
+	auth_service "code.gitea.io/gitea/services/auth"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 // ListAccessTokens list all the access tokens
@@ -64,6 +68,7 @@ func ListAccessTokens(ctx *context.APIContext) {
 			ID:             tokens[i].ID,
 			Name:           tokens[i].Name,
 			TokenLastEight: tokens[i].TokenLastEight,
+			AllowedCIDRs:   tokens[i].AllowedCIDRs,
 		}
 	}
 
@@ -95,12 +100,20 @@ func CreateAccessToken(ctx *context.APIContext) {
 	//     "$ref": "#/responses/AccessToken"
 	//   "400":
 	//     "$ref": "#/responses/error"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
 
 	form := web.GetForm(ctx).(*api.CreateAccessTokenOption)
 
+	if err := auth_service.ValidateCIDRList(form.AllowedCIDRs); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "AllowedCIDRs", err)
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.Doer.ID,
-		Name: form.Name,
+		UID:          ctx.Doer.ID,
+		Name:         form.Name,
+		AllowedCIDRs: strings.TrimSpace(form.AllowedCIDRs),
 	}
 
 	exist, err := models.AccessTokenByNameExists(t)
@@ -117,11 +130,13 @@ func CreateAccessToken(ctx *context.APIContext) {
 		ctx.Error(http.StatusInternalServerError, "NewAccessToken", err)
 		return
 	}
+	user_service.RecordSecurityEvent(ctx, ctx.Doer, user_model.SecurityEventTokenCreate, ctx.Req, t.Name)
 	ctx.JSON(http.StatusCreated, &api.AccessToken{
 		Name:           t.Name,
 		Token:          t.Token,
 		ID:             t.ID,
 		TokenLastEight: t.TokenLastEight,
+		AllowedCIDRs:   t.AllowedCIDRs,
 	})
 }
 
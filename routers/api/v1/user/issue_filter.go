@@ -0,0 +1,308 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/routers/api/v1/utils"
+)
+
+var (
+	issueFilterTypes  = []string{"", "all", "your_repositories", "assigned", "created_by", "mentioned", "review_requested"}
+	issueFilterStates = []string{"", "open", "closed", "all"}
+)
+
+// ListIssueFilters list the authenticated user's saved issue filters
+func ListIssueFilters(ctx *context.APIContext) {
+	// swagger:operation GET /user/filters user userListIssueFilters
+	// ---
+	// summary: List the authenticated user's saved issue and pull request filters
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: repo_id
+	//   in: query
+	//   description: only list the filters usable in this repository
+	//   type: integer
+	//   format: int64
+	// - name: page
+	//   in: query
+	//   description: page number of results to return (1-based)
+	//   type: integer
+	// - name: limit
+	//   in: query
+	//   description: page size of results
+	//   type: integer
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueFilterList"
+
+	filters, total, err := issues_model.FindIssueFilters(ctx, &issues_model.FindIssueFiltersOptions{
+		ListOptions: utils.GetListOptions(ctx),
+		UserID:      ctx.Doer.ID,
+		RepoID:      ctx.FormInt64("repo_id"),
+	})
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindIssueFilters", err)
+		return
+	}
+
+	apiFilters := make([]*api.IssueFilter, len(filters))
+	for i := range filters {
+		apiFilters[i] = convert.ToIssueFilter(filters[i])
+	}
+
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, &apiFilters)
+}
+
+// GetIssueFilter get a saved issue filter of the authenticated user
+func GetIssueFilter(ctx *context.APIContext) {
+	// swagger:operation GET /user/filters/{id} user userGetIssueFilter
+	// ---
+	// summary: Get a saved issue or pull request filter
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the filter
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueFilter"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	f := getIssueFilter(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueFilter(f))
+}
+
+// CreateIssueFilter save a new issue filter for the authenticated user
+func CreateIssueFilter(ctx *context.APIContext) {
+	// swagger:operation POST /user/filters user userCreateIssueFilter
+	// ---
+	// summary: Save an issue or pull request filter
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/CreateIssueFilterOption"
+	// responses:
+	//   "201":
+	//     "$ref": "#/responses/IssueFilter"
+	//   "409":
+	//     description: A filter with the same name already exists.
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.CreateIssueFilterOption)
+
+	f := &issues_model.IssueFilter{
+		UserID:      ctx.Doer.ID,
+		Name:        form.Name,
+		RepoID:      form.RepoID,
+		Keyword:     form.Keyword,
+		ViewType:    form.Type,
+		SortType:    form.Sort,
+		State:       form.State,
+		MilestoneID: form.MilestoneID,
+		AssigneeID:  form.AssigneeID,
+		PosterID:    form.PosterID,
+	}
+	f.SetLabelIDs(form.Labels)
+
+	if !validateIssueFilter(ctx, f) {
+		return
+	}
+
+	if err := issues_model.CreateIssueFilter(ctx, f); err != nil {
+		if issues_model.IsErrIssueFilterAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "CreateIssueFilter", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusCreated, convert.ToIssueFilter(f))
+}
+
+// EditIssueFilter edit a saved issue filter of the authenticated user
+func EditIssueFilter(ctx *context.APIContext) {
+	// swagger:operation PATCH /user/filters/{id} user userEditIssueFilter
+	// ---
+	// summary: Edit a saved issue or pull request filter
+	// consumes:
+	// - application/json
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the filter
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// - name: body
+	//   in: body
+	//   required: true
+	//   schema:
+	//     "$ref": "#/definitions/EditIssueFilterOption"
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/IssueFilter"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "409":
+	//     description: A filter with the same name already exists.
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	form := web.GetForm(ctx).(*api.EditIssueFilterOption)
+	f := getIssueFilter(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if form.Name != nil {
+		f.Name = *form.Name
+	}
+	if form.Keyword != nil {
+		f.Keyword = *form.Keyword
+	}
+	if form.Type != nil {
+		f.ViewType = *form.Type
+	}
+	if form.Sort != nil {
+		f.SortType = *form.Sort
+	}
+	if form.State != nil {
+		f.State = *form.State
+	}
+	if form.Labels != nil {
+		f.SetLabelIDs(form.Labels)
+	}
+	if form.MilestoneID != nil {
+		f.MilestoneID = *form.MilestoneID
+	}
+	if form.AssigneeID != nil {
+		f.AssigneeID = *form.AssigneeID
+	}
+	if form.PosterID != nil {
+		f.PosterID = *form.PosterID
+	}
+
+	if !validateIssueFilter(ctx, f) {
+		return
+	}
+
+	if err := issues_model.UpdateIssueFilter(ctx, f); err != nil {
+		if issues_model.IsErrIssueFilterAlreadyExist(err) {
+			ctx.Error(http.StatusConflict, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "UpdateIssueFilter", err)
+		}
+		return
+	}
+	ctx.JSON(http.StatusOK, convert.ToIssueFilter(f))
+}
+
+// DeleteIssueFilter delete a saved issue filter of the authenticated user
+func DeleteIssueFilter(ctx *context.APIContext) {
+	// swagger:operation DELETE /user/filters/{id} user userDeleteIssueFilter
+	// ---
+	// summary: Delete a saved issue or pull request filter
+	// parameters:
+	// - name: id
+	//   in: path
+	//   description: id of the filter
+	//   type: integer
+	//   format: int64
+	//   required: true
+	// responses:
+	//   "204":
+	//     "$ref": "#/responses/empty"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+
+	if err := issues_model.DeleteIssueFilter(ctx, ctx.Doer.ID, ctx.ParamsInt64(":id")); err != nil {
+		if issues_model.IsErrIssueFilterNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteIssueFilter", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+func getIssueFilter(ctx *context.APIContext) *issues_model.IssueFilter {
+	f, err := issues_model.GetIssueFilterByID(ctx, ctx.Doer.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if issues_model.IsErrIssueFilterNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetIssueFilterByID", err)
+		}
+		return nil
+	}
+	return f
+}
+
+// validateIssueFilter checks the filter and writes an error response if it is invalid
+func validateIssueFilter(ctx *context.APIContext, f *issues_model.IssueFilter) bool {
+	if f.Name == "" {
+		ctx.Error(http.StatusUnprocessableEntity, "", "name is required")
+		return false
+	}
+	if !util.IsStringInSlice(f.ViewType, issueFilterTypes) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "invalid type")
+		return false
+	}
+	if !util.IsStringInSlice(f.State, issueFilterStates) {
+		ctx.Error(http.StatusUnprocessableEntity, "", "invalid state")
+		return false
+	}
+	if f.RepoID > 0 {
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, f.RepoID)
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				ctx.Error(http.StatusUnprocessableEntity, "", err)
+			} else {
+				ctx.Error(http.StatusInternalServerError, "GetRepositoryByID", err)
+			}
+			return false
+		}
+		perm, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
+		if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserRepoPermission", err)
+			return false
+		}
+		if !perm.CanReadIssuesOrPulls(false) && !perm.CanReadIssuesOrPulls(true) {
+			ctx.Error(http.StatusUnprocessableEntity, "", repo_model.ErrRepoNotExist{ID: f.RepoID})
+			return false
+		}
+	}
+	return true
+}
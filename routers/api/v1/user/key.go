@@ -18,6 +18,7 @@ import (
 	"code.gitea.io/gitea/routers/api/v1/repo"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 // appendPrivateInformation appends the owner and key type information to api.PublicKey
@@ -205,6 +206,13 @@ func CreateUserPublicKey(ctx *context.APIContext, form api.CreateKeyOption, uid
 		repo.HandleAddKeyError(ctx, err)
 		return
 	}
+	owner := ctx.Doer
+	if uid != ctx.Doer.ID {
+		// an admin adds the key of another user
+		owner = ctx.ContextUser
+	}
+	user_service.RecordSecurityEvent(ctx, owner, user_model.SecurityEventSSHKeyAdd, ctx.Req, form.Title)
+
 	apiLink := composePublicKeysAPILink()
 	apiKey := convert.ToPublicKey(apiLink, key)
 	if ctx.Doer.IsAdmin || ctx.Doer.ID == key.OwnerID {
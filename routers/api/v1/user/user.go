@@ -12,6 +12,7 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/routers/api/v1/utils"
 )
 
@@ -146,3 +147,48 @@ func GetUserHeatmapData(ctx *context.APIContext) {
 	}
 	ctx.JSON(http.StatusOK, heatmap)
 }
+
+// GetUserContributions returns the contributions of a user during the last year by day
+func GetUserContributions(ctx *context.APIContext) {
+	// swagger:operation GET /users/{username}/contributions user userGetContributions
+	// ---
+	// summary: Get the number of contributions of a user on every day with contributions during the last year
+	// produces:
+	// - application/json
+	// parameters:
+	// - name: username
+	//   in: path
+	//   description: username of user to get
+	//   type: string
+	//   required: true
+	// - name: timezone
+	//   in: query
+	//   description: IANA name of the time zone of the days, e.g. Europe/Berlin (default UTC)
+	//   type: string
+	// responses:
+	//   "200":
+	//     "$ref": "#/responses/UserContributionDayList"
+	//   "404":
+	//     "$ref": "#/responses/notFound"
+	//   "422":
+	//     "$ref": "#/responses/validationError"
+
+	loc := utils.GetTimezone(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	days, err := models.GetUserContributionsByDay(ctx.ContextUser, ctx.Doer, loc)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetUserContributionsByDay", err)
+		return
+	}
+	apiDays := make([]*api.UserContributionDay, 0, len(days))
+	for _, day := range days {
+		apiDays = append(apiDays, &api.UserContributionDay{
+			Date:          day.Date,
+			Contributions: day.Contributions,
+		})
+	}
+	ctx.JSON(http.StatusOK, apiDays)
+}
@@ -0,0 +1,73 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+// ListRunners writes the runners of a repository, an organization or the global runners to `ctx`
+func ListRunners(ctx *context.APIContext, ownerID, repoID int64) {
+	runners, err := actions_model.FindRunners(ctx, ownerID, repoID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindRunners", err)
+		return
+	}
+
+	apiRunners := make([]*api.ActionRunner, len(runners))
+	for i := range runners {
+		apiRunners[i] = convert.ToActionRunner(runners[i])
+	}
+	ctx.JSON(http.StatusOK, apiRunners)
+}
+
+// GetRunnerRegistrationToken writes the active runner registration token of the scope to `ctx`, it is created if there is none
+func GetRunnerRegistrationToken(ctx *context.APIContext, ownerID, repoID int64) {
+	token, err := actions_model.GetOrCreateRunnerToken(ctx, ownerID, repoID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "GetOrCreateRunnerToken", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, &api.ActionRunnerRegistrationToken{Token: token.Token})
+}
+
+// ResetRunnerRegistrationToken replaces the runner registration token of the scope and writes the new one to `ctx`
+func ResetRunnerRegistrationToken(ctx *context.APIContext, ownerID, repoID int64) {
+	token, err := actions_model.ResetRunnerToken(ctx, ownerID, repoID)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ResetRunnerToken", err)
+		return
+	}
+	ctx.JSON(http.StatusCreated, &api.ActionRunnerRegistrationToken{Token: token.Token})
+}
+
+// DeleteRunner deletes the runner of the scope identified by the `id` parameter
+func DeleteRunner(ctx *context.APIContext, ownerID, repoID int64) {
+	runner, err := actions_model.GetRunnerByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if actions_model.IsErrRunnerNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetRunnerByID", err)
+		}
+		return
+	}
+	if runner.OwnerID != ownerID || runner.RepoID != repoID {
+		ctx.NotFound()
+		return
+	}
+
+	if err := actions_service.DeleteRunner(ctx, runner); err != nil {
+		ctx.Error(http.StatusInternalServerError, "DeleteRunner", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
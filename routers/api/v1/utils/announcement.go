@@ -0,0 +1,31 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// ListAnnouncements writes the announcements of the instance or of an organization found with the options to `ctx`
+func ListAnnouncements(ctx *context.APIContext, opts *admin_model.FindAnnouncementsOptions) {
+	opts.ListOptions = GetListOptions(ctx)
+	announcements, total, err := admin_model.FindAnnouncements(ctx, opts)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindAnnouncements", err)
+		return
+	}
+
+	apiAnnouncements := make([]*api.Announcement, len(announcements))
+	for i := range announcements {
+		apiAnnouncements[i] = convert.ToAnnouncement(announcements[i])
+	}
+	ctx.SetTotalCountHeader(total)
+	ctx.JSON(http.StatusOK, apiAnnouncements)
+}
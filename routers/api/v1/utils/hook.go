@@ -7,8 +7,10 @@ package utils
 import (
 	"fmt"
 	"net/http"
+	"strconv"
 	"strings"
 
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/convert"
@@ -16,6 +18,7 @@ import (
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/utils"
+	repo_service "code.gitea.io/gitea/services/repository"
 	webhook_service "code.gitea.io/gitea/services/webhook"
 )
 
@@ -83,6 +86,7 @@ func AddRepoHook(ctx *context.APIContext, form *api.CreateHookOption) {
 	repo := ctx.Repo
 	hook, ok := addHook(ctx, form, 0, repo.Repository.ID)
 	if ok {
+		repo_service.AddAuditLog(ctx, ctx.Doer, repo.Repository, repo_model.AuditActionWebhookAdd, strconv.FormatInt(hook.ID, 10), string(hook.Type))
 		ctx.JSON(http.StatusCreated, convert.ToHook(repo.RepoLink, hook))
 	}
 }
@@ -107,6 +111,8 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 		URL:         form.Config["url"],
 		ContentType: webhook.ToHookContentType(form.Config["content_type"]),
 		Secret:      form.Config["secret"],
+		ClientCert:  strings.TrimSpace(form.Config["client_cert"]),
+		ClientKey:   strings.TrimSpace(form.Config["client_key"]),
 		HTTPMethod:  "POST",
 		HookEvent: &webhook.HookEvent{
 			ChooseEvents: true,
@@ -129,12 +135,25 @@ func addHook(ctx *context.APIContext, form *api.CreateHookOption, orgID, repoID
 				PullRequestSync:      pullHook(form.Events, string(webhook.HookEventPullRequestSync)),
 				Repository:           util.IsStringInSlice(string(webhook.HookEventRepository), form.Events, true),
 				Release:              util.IsStringInSlice(string(webhook.HookEventRelease), form.Events, true),
+				PreviewEnvironment:   util.IsStringInSlice(string(webhook.HookEventPreviewEnvironment), form.Events, true),
 			},
-			BranchFilter: form.BranchFilter,
+			BranchFilter:  form.BranchFilter,
+			PayloadFilter: strings.TrimSpace(form.PayloadFilter),
 		},
 		IsActive: form.Active,
 		Type:     webhook.HookType(form.Type),
 	}
+	if orgID > 0 {
+		w.IsTemplate = form.IsTemplate
+		w.IsLocked = form.IsTemplate && form.IsLocked
+	}
+	if w.Type == webhook.GITEA {
+		w.IsGitHubCompatible = form.GitHubCompatible
+	}
+	if err := webhook_service.CheckClientCert(w.ClientCert, w.ClientKey); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Invalid client certificate: %v", err))
+		return nil, false
+	}
 	if w.Type == webhook.SLACK {
 		channel, ok := form.Config["channel"]
 		if !ok {
@@ -194,9 +213,14 @@ func EditRepoHook(ctx *context.APIContext, form *api.EditHookOption, hookID int6
 	if err != nil {
 		return
 	}
+	if hook.IsInherited() && hook.IsLocked {
+		ctx.Error(http.StatusForbidden, "", "webhook is locked by the organization")
+		return
+	}
 	if !editHook(ctx, form, hook) {
 		return
 	}
+	repo_service.AddAuditLog(ctx, ctx.Doer, repo.Repository, repo_model.AuditActionWebhookUpdate, strconv.FormatInt(hook.ID, 10), string(hook.Type))
 	updated, err := GetRepoHook(ctx, repo.Repository.ID, hookID)
 	if err != nil {
 		return
@@ -218,6 +242,22 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 			}
 			w.ContentType = webhook.ToHookContentType(ct)
 		}
+		if secret, ok := form.Config["secret"]; ok {
+			w.SetSecret(secret, form.Config["keep_previous_secret"] == "true")
+		}
+		if cert, ok := form.Config["client_cert"]; ok {
+			cert = strings.TrimSpace(cert)
+			key := strings.TrimSpace(form.Config["client_key"])
+			if cert != "" && key == "" && cert == w.ClientCert {
+				key = w.ClientKey
+			}
+			if err := webhook_service.CheckClientCert(cert, key); err != nil {
+				ctx.Error(http.StatusUnprocessableEntity, "", fmt.Sprintf("Invalid client certificate: %v", err))
+				return false
+			}
+			w.ClientCert = cert
+			w.ClientKey = key
+		}
 
 		if w.Type == webhook.SLACK {
 			if channel, ok := form.Config["channel"]; ok {
@@ -250,7 +290,9 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 	w.Fork = util.IsStringInSlice(string(webhook.HookEventFork), form.Events, true)
 	w.Repository = util.IsStringInSlice(string(webhook.HookEventRepository), form.Events, true)
 	w.Release = util.IsStringInSlice(string(webhook.HookEventRelease), form.Events, true)
+	w.PreviewEnvironment = util.IsStringInSlice(string(webhook.HookEventPreviewEnvironment), form.Events, true)
 	w.BranchFilter = form.BranchFilter
+	w.PayloadFilter = strings.TrimSpace(form.PayloadFilter)
 
 	// Issues
 	w.Issues = issuesHook(form.Events, "issues_only")
@@ -276,6 +318,18 @@ func editHook(ctx *context.APIContext, form *api.EditHookOption, w *webhook.Webh
 	if form.Active != nil {
 		w.IsActive = *form.Active
 	}
+	if w.OrgID > 0 {
+		if form.IsTemplate != nil {
+			w.IsTemplate = *form.IsTemplate
+		}
+		if form.IsLocked != nil {
+			w.IsLocked = *form.IsLocked
+		}
+		w.IsLocked = w.IsTemplate && w.IsLocked
+	}
+	if form.GitHubCompatible != nil && w.Type == webhook.GITEA {
+		w.IsGitHubCompatible = *form.GitHubCompatible
+	}
 
 	if err := webhook.UpdateWebhook(w); err != nil {
 		ctx.Error(http.StatusInternalServerError, "UpdateWebhook", err)
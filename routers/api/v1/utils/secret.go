@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/convert"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/web"
+)
+
+// ListSecrets writes the secrets of a repository or organization to `ctx`
+func ListSecrets(ctx *context.APIContext, scope secret_model.Scope) {
+	secrets, err := secret_model.FindSecrets(ctx, scope, secret_model.TypeSecret)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindSecrets", err)
+		return
+	}
+
+	apiSecrets := make([]*api.Secret, len(secrets))
+	for i := range secrets {
+		apiSecrets[i] = convert.ToSecret(secrets[i])
+	}
+	ctx.JSON(http.StatusOK, apiSecrets)
+}
+
+// ListVariables writes the variables of a repository or organization to `ctx`
+func ListVariables(ctx *context.APIContext, scope secret_model.Scope) {
+	variables, err := secret_model.FindSecrets(ctx, scope, secret_model.TypeVariable)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "FindSecrets", err)
+		return
+	}
+
+	apiVariables := make([]*api.Variable, len(variables))
+	for i := range variables {
+		if apiVariables[i], err = convert.ToVariable(variables[i]); err != nil {
+			ctx.Error(http.StatusInternalServerError, "ToVariable", err)
+			return
+		}
+	}
+	ctx.JSON(http.StatusOK, apiVariables)
+}
+
+// GetVariable writes the variable named by the `name` parameter to `ctx`
+func GetVariable(ctx *context.APIContext, scope secret_model.Scope) {
+	variable, err := secret_model.GetSecret(ctx, scope, secret_model.TypeVariable, ctx.Params(":name"))
+	if err != nil {
+		if secret_model.IsErrSecretNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "GetSecret", err)
+		}
+		return
+	}
+
+	apiVariable, err := convert.ToVariable(variable)
+	if err != nil {
+		ctx.Error(http.StatusInternalServerError, "ToVariable", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, apiVariable)
+}
+
+// SetSecret creates or updates the secret or variable named by the `name` parameter.
+// Writes 201 if it was created and 204 if it was updated.
+func SetSecret(ctx *context.APIContext, scope secret_model.Scope, tp secret_model.Type) {
+	form := web.GetForm(ctx).(*api.SetSecretOption)
+	created, err := secret_model.SetSecret(ctx, ctx.Doer.ID, scope, tp, ctx.Params(":name"), form.Value)
+	if err != nil {
+		if secret_model.IsErrInvalidName(err) {
+			ctx.Error(http.StatusUnprocessableEntity, "", err)
+		} else {
+			ctx.Error(http.StatusInternalServerError, "SetSecret", err)
+		}
+		return
+	}
+
+	if created {
+		ctx.Status(http.StatusCreated)
+	} else {
+		ctx.Status(http.StatusNoContent)
+	}
+}
+
+// DeleteSecret deletes the secret or variable named by the `name` parameter
+func DeleteSecret(ctx *context.APIContext, scope secret_model.Scope, tp secret_model.Type) {
+	if err := secret_model.DeleteSecret(ctx, ctx.Doer.ID, scope, tp, ctx.Params(":name")); err != nil {
+		if secret_model.IsErrSecretNotExist(err) {
+			ctx.NotFound()
+		} else {
+			ctx.Error(http.StatusInternalServerError, "DeleteSecret", err)
+		}
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
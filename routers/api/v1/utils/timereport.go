@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"fmt"
+	"net/http"
+
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/issue/timereport"
+)
+
+// GetTimeReportOptions returns time report options using the group_by, user, since and before parameters.
+// It responds with an error and returns nil if a parameter is invalid.
+func GetTimeReportOptions(ctx *context.APIContext) *timereport.Options {
+	opts := &timereport.Options{
+		GroupBy: timereport.GroupBy(ctx.FormTrim("group_by")),
+	}
+	if opts.GroupBy == "" {
+		opts.GroupBy = timereport.GroupByUser
+	} else if !opts.GroupBy.IsValid() {
+		ctx.Error(http.StatusUnprocessableEntity, "", fmt.Errorf("unknown group_by %q", opts.GroupBy))
+		return nil
+	}
+
+	if qUser := ctx.FormTrim("user"); qUser != "" {
+		user, err := user_model.GetUserByName(ctx, qUser)
+		if user_model.IsErrUserNotExist(err) {
+			ctx.Error(http.StatusNotFound, "User does not exist", err)
+			return nil
+		} else if err != nil {
+			ctx.Error(http.StatusInternalServerError, "GetUserByName", err)
+			return nil
+		}
+		opts.UserID = user.ID
+	}
+
+	var err error
+	if opts.CreatedBeforeUnix, opts.CreatedAfterUnix, err = context.GetQueryBeforeSince(ctx.Context); err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "GetQueryBeforeSince", err)
+		return nil
+	}
+	return opts
+}
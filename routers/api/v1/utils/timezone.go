@@ -0,0 +1,27 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package utils
+
+import (
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/modules/context"
+)
+
+// GetTimezone returns the location named by the "timezone" query parameter, UTC if it is empty.
+// It writes an error response and returns nil if the name is unknown.
+func GetTimezone(ctx *context.APIContext) *time.Location {
+	name := ctx.FormTrim("timezone")
+	if name == "" {
+		return time.UTC
+	}
+	loc, err := time.LoadLocation(name)
+	if err != nil {
+		ctx.Error(http.StatusUnprocessableEntity, "timezone", err)
+		return nil
+	}
+	return loc
+}
@@ -14,11 +14,13 @@ import (
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/modules/appstate"
+	"code.gitea.io/gitea/modules/audit"
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/eventsource"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/highlight"
 	code_indexer "code.gitea.io/gitea/modules/indexer/code"
+	discussion_indexer "code.gitea.io/gitea/modules/indexer/discussions"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	stats_indexer "code.gitea.io/gitea/modules/indexer/stats"
 	"code.gitea.io/gitea/modules/log"
@@ -32,19 +34,29 @@ import (
 	"code.gitea.io/gitea/modules/translation"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	actions_router "code.gitea.io/gitea/routers/api/actions"
 	packages_router "code.gitea.io/gitea/routers/api/packages"
+	scim_router "code.gitea.io/gitea/routers/api/scim"
 	apiv1 "code.gitea.io/gitea/routers/api/v1"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/routers/private"
 	web_routers "code.gitea.io/gitea/routers/web"
+	actions_service "code.gitea.io/gitea/services/actions"
+	admin_service "code.gitea.io/gitea/services/admin"
+	attachment_service "code.gitea.io/gitea/services/attachment"
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/automerge"
 	"code.gitea.io/gitea/services/cron"
+	emoji_service "code.gitea.io/gitea/services/emoji"
+	"code.gitea.io/gitea/services/federation"
 	"code.gitea.io/gitea/services/mailer"
 	repo_migrations "code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
+	debian_service "code.gitea.io/gitea/services/packages/debian"
+	preview_service "code.gitea.io/gitea/services/preview"
 	pull_service "code.gitea.io/gitea/services/pull"
+	release_service "code.gitea.io/gitea/services/release"
 	repo_service "code.gitea.io/gitea/services/repository"
 	"code.gitea.io/gitea/services/repository/archiver"
 	"code.gitea.io/gitea/services/task"
@@ -145,6 +157,7 @@ func GlobalInitInstalled(ctx context.Context) {
 	// Booting long running goroutines.
 	cron.NewContext(ctx)
 	issue_indexer.InitIssueIndexer(false)
+	discussion_indexer.InitDiscussionIndexer()
 	code_indexer.Init()
 	mustInit(stats_indexer.Init)
 
@@ -152,8 +165,17 @@ func GlobalInitInstalled(ctx context.Context) {
 	mustInit(webhook.Init)
 	mustInit(pull_service.Init)
 	mustInit(automerge.Init)
+	mustInit(preview_service.Init)
 	mustInit(task.Init)
 	mustInit(repo_migrations.Init)
+	mustInit(actions_service.Init)
+	mustInit(debian_service.Init)
+	mustInit(audit.Init)
+	mustInit(federation.Init)
+	mustInit(emoji_service.Init)
+	mustInit(release_service.Init)
+	mustInit(attachment_service.Init)
+	mustInit(admin_service.InitSelfTest)
 	eventsource.GetManager().Init()
 
 	mustInitCtx(ctx, syncAppPathForGit)
@@ -184,5 +206,11 @@ func NormalRoutes() *web.Route {
 		r.Mount("/api/packages", packages_router.Routes())
 		r.Mount("/v2", packages_router.ContainerRoutes())
 	}
+	if setting.Actions.Enabled {
+		r.Mount("/api/actions", actions_router.Routes())
+	}
+	if setting.SCIM.Enabled {
+		r.Mount("/api/scim/v2", scim_router.Routes())
+	}
 	return r
 }
@@ -21,8 +21,10 @@ import (
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
+	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	pull_service "code.gitea.io/gitea/services/pull"
+	quota_service "code.gitea.io/gitea/services/quota"
 )
 
 type preReceiveContext struct {
@@ -112,6 +114,10 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		opts:           opts,
 	}
 
+	if !preReceiveSuspension(ourCtx) {
+		return
+	}
+
 	// Iterate across the provided old commit IDs
 	for i := range opts.OldCommitIDs {
 		oldCommitID := opts.OldCommitIDs[i]
@@ -133,9 +139,96 @@ func HookPreReceive(ctx *gitea_context.PrivateContext) {
 		}
 	}
 
+	if !preReceiveQuota(ourCtx) {
+		return
+	}
+
 	ctx.PlainText(http.StatusOK, "ok")
 }
 
+// preReceiveSuspension rejects pushes to the repositories of suspended users and organizations and pushes of suspended users
+func preReceiveSuspension(ctx *preReceiveContext) bool {
+	repo := ctx.Repo.Repository
+	if err := repo.GetOwner(ctx); err != nil {
+		log.Error("Unable to get the owner of %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return false
+	}
+	if repo.Owner.IsSuspended {
+		log.Warn("Forbidden: push to %-v whose owner is suspended", repo)
+		ctx.JSON(http.StatusForbidden, private.Response{
+			Err: fmt.Sprintf("%s has been suspended, nothing can be pushed to its repositories", repo.OwnerName),
+		})
+		return false
+	}
+
+	if ctx.opts.UserID <= 0 {
+		return true
+	}
+	if !ctx.loadPusherAndPermission() {
+		return false
+	}
+	if ctx.user.IsSuspended {
+		log.Warn("Forbidden: push to %-v by suspended user %-v", repo, ctx.user)
+		ctx.JSON(http.StatusForbidden, private.Response{
+			Err: "your account has been suspended",
+		})
+		return false
+	}
+	return true
+}
+
+// preReceiveQuota rejects the push if the objects it adds exceed the quota of the repository owner.
+// Pushes which don't add objects, like deleting refs, are always accepted.
+func preReceiveQuota(ctx *preReceiveContext) bool {
+	var pushSize int64
+	if ctx.opts.GitQuarantinePath != "" {
+		size, err := util.GetDirectorySize(ctx.opts.GitQuarantinePath)
+		if err != nil {
+			log.Error("Unable to get the size of the pushed objects in %s: %v", ctx.opts.GitQuarantinePath, err)
+			ctx.JSON(http.StatusInternalServerError, private.Response{
+				Err: err.Error(),
+			})
+			return false
+		}
+		if size == 0 {
+			return true
+		}
+		pushSize = size
+	} else if onlyDeletesRefs(ctx.opts) {
+		// without a quarantine the size of the push is unknown
+		return true
+	}
+
+	repo := ctx.Repo.Repository
+	if err := quota_service.CheckQuota(ctx, repo.OwnerID, pushSize); err != nil {
+		if quota_service.IsErrQuotaExceeded(err) {
+			log.Warn("Forbidden: push to %-v exceeds the quota of its owner: %v", repo, err)
+			ctx.JSON(http.StatusForbidden, private.Response{
+				Err: fmt.Sprintf("the push exceeds the disk quota of %s: %v", repo.OwnerName, err),
+			})
+			return false
+		}
+		log.Error("Unable to check the quota of the owner of %-v: %v", repo, err)
+		ctx.JSON(http.StatusInternalServerError, private.Response{
+			Err: err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+func onlyDeletesRefs(opts *private.HookOptions) bool {
+	for _, newCommitID := range opts.NewCommitIDs {
+		if newCommitID != git.EmptySHA {
+			return false
+		}
+	}
+	return true
+}
+
 func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullName string) {
 	branchName := strings.TrimPrefix(refFullName, git.BranchPrefix)
 	ctx.branchName = branchName
@@ -155,7 +248,7 @@ func preReceiveBranch(ctx *preReceiveContext, oldCommitID, newCommitID, refFullN
 		return
 	}
 
-	protectBranch, err := models.GetProtectedBranchBy(ctx, repo.ID, branchName)
+	protectBranch, err := models.GetEffectiveProtectedBranch(ctx, repo.ID, branchName)
 	if err != nil {
 		log.Error("Unable to get protected branch: %s in %-v Error: %v", branchName, repo, err)
 		ctx.JSON(http.StatusInternalServerError, private.Response{
@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strings"
 
+	admin_model "code.gitea.io/gitea/models/admin"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
@@ -21,6 +22,9 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/private"
 	"code.gitea.io/gitea/modules/setting"
+	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	audit_service "code.gitea.io/gitea/services/audit"
+	"code.gitea.io/gitea/services/maintenance"
 	repo_service "code.gitea.io/gitea/services/repository"
 	wiki_service "code.gitea.io/gitea/services/wiki"
 )
@@ -249,6 +253,17 @@ func ServCommand(ctx *context.PrivateContext) {
 		results.DeployKeyID = deployKey.ID
 		results.KeyName = deployKey.Name
 
+		remoteAddr := ctx.FormString("remote")
+		if !deployKey.IsAllowedFrom(remoteAddr) {
+			log.Warn("Failed authentication attempt with deploy key %s for %s/%s from %s: the address isn't allowed", deployKey.Name, results.OwnerName, results.RepoName, remoteAddr)
+			audit_service.Record(ctx, nil, remoteAddr, admin_model.AuditActionDeployKeyRejected, results.OwnerName+"/"+results.RepoName, deployKey.Name)
+			ctx.JSON(http.StatusUnauthorized, private.ErrServCommand{
+				Results: results,
+				Err:     fmt.Sprintf("Deploy Key: %d:%s is not allowed to be used from %s.", key.ID, key.Name, remoteAddr),
+			})
+			return
+		}
+
 		// FIXME: Deploy keys aren't really the owner of the repo pushing changes
 		// however we don't have good way of representing deploy keys in hook.go
 		// so for now use the owner of the repository
@@ -299,6 +314,54 @@ func ServCommand(ctx *context.PrivateContext) {
 		return
 	}
 
+	// Don't allow pushing to the repositories of suspended owners nor accessing them at all unless it is the owner or an admin
+	if owner.IsSuspended && (mode > perm.AccessModeRead || owner.IsSuspendedFor(user)) {
+		ctx.JSON(http.StatusForbidden, private.ErrServCommand{
+			Results: results,
+			Err:     fmt.Sprintf("The owner of %s/%s has been suspended.", results.OwnerName, results.RepoName),
+		})
+		return
+	}
+
+	// Don't allow suspended users to push
+	if user != nil && user.IsSuspended && mode > perm.AccessModeRead {
+		ctx.JSON(http.StatusForbidden, private.ErrServCommand{
+			Results: results,
+			Err:     "Your account has been suspended.",
+		})
+		return
+	}
+
+	// Only site admins can push while the instance is in maintenance mode
+	if mode > perm.AccessModeRead && (user == nil || !user.IsAdmin) {
+		if maintenanceMode := maintenance.Get(ctx); maintenanceMode != nil {
+			ctx.JSON(http.StatusServiceUnavailable, private.ErrServCommand{
+				Results: results,
+				Err:     maintenance.Message(maintenanceMode),
+			})
+			return
+		}
+	}
+
+	// Don't allow keys violating the key policy of an organization to access its repositories
+	if owner.IsOrganization() && key.Type == asymkey_model.KeyTypeUser {
+		if err := asymkey_service.CheckSSHKeyForOrg(ctx, owner, key); err != nil {
+			if asymkey_service.IsErrKeyPolicyViolation(err) {
+				ctx.JSON(http.StatusForbidden, private.ErrServCommand{
+					Results: results,
+					Err:     fmt.Sprintf("Your %v.", err),
+				})
+				return
+			}
+			log.Error("Unable to check key %d against the key policy of %s: %v", key.ID, owner.Name, err)
+			ctx.JSON(http.StatusInternalServerError, private.ErrServCommand{
+				Results: results,
+				Err:     fmt.Sprintf("Unable to check key %d against the key policy of %s: %v", key.ID, owner.Name, err),
+			})
+			return
+		}
+	}
+
 	// Permissions checking:
 	if repoExist &&
 		(mode > perm.AccessModeRead ||
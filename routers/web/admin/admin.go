@@ -28,7 +28,9 @@ import (
 	"code.gitea.io/gitea/modules/updatechecker"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	admin_service "code.gitea.io/gitea/services/admin"
 	"code.gitea.io/gitea/services/cron"
+	"code.gitea.io/gitea/services/federation"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 
@@ -134,6 +136,9 @@ func Dashboard(ctx *context.Context) {
 	updateSystemStatus()
 	ctx.Data["SysStatus"] = sysStatus
 	ctx.Data["SSH"] = setting.SSH
+	if ctx.FormBool("selftest") {
+		ctx.Data["SelfTest"] = admin_service.RunSelfTest(ctx)
+	}
 	ctx.HTML(http.StatusOK, tplDashboard)
 }
 
@@ -262,8 +267,15 @@ func Config(ctx *context.Context) {
 	ctx.Data["LFS"] = setting.LFS
 
 	ctx.Data["Service"] = setting.Service
+	ctx.Data["MinPasswordLength"] = setting.MinPasswordLength
+	ctx.Data["PasswordComplexity"] = strings.Join(setting.PasswordComplexity, ", ")
+	ctx.Data["PasswordCheckPwn"] = setting.PasswordCheckPwn
+	ctx.Data["PasswordRotationInterval"] = setting.PasswordRotationInterval
 	ctx.Data["DbCfg"] = setting.Database
 	ctx.Data["Webhook"] = setting.Webhook
+	ctx.Data["Federation"] = setting.Federation
+	ctx.Data["FederationDeliveryEnabled"] = federation.IsDeliveryEnabled()
+	ctx.Data["FederationDeliveryStats"] = federation.GetDeliveryStats()
 
 	ctx.Data["MailerEnabled"] = false
 	if setting.MailService != nil {
@@ -0,0 +1,128 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"encoding/csv"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+const tplAudit base.TplName = "admin/audit"
+
+// AuditLog show the instance-wide audit log
+func AuditLog(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.audit")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminAudit"] = true
+	ctx.Data["AuditEnabled"] = setting.Audit.Enabled
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	action := ctx.FormTrim("action")
+
+	events, total, err := admin_model.FindAuditEvents(ctx, &admin_model.FindAuditEventsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.Admin.NoticePagingNum,
+		},
+		Action: action,
+	})
+	if err != nil {
+		ctx.ServerError("FindAuditEvents", err)
+		return
+	}
+	ctx.Data["AuditEvents"] = events
+	ctx.Data["Total"] = total
+	ctx.Data["AuditCategories"] = admin_model.AuditCategories
+	ctx.Data["Action"] = action
+
+	pager := context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+	pager.AddParam(ctx, "action", "Action")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplAudit)
+}
+
+// AuditLogExport exports the instance-wide audit log as CSV or JSON
+func AuditLogExport(ctx *context.Context) {
+	opts := &admin_model.FindAuditEventsOptions{
+		Action: ctx.FormTrim("action"),
+	}
+
+	format := ctx.FormString("format")
+	filename := "audit-" + time.Now().Format("20060102150405")
+	switch format {
+	case "csv":
+		ctx.Resp.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.csv"`, filename))
+
+		w := csv.NewWriter(ctx.Resp)
+		_ = w.Write([]string{"id", "time", "category", "action", "doer_id", "doer_name", "ip", "target", "detail"})
+		err := admin_model.IterateAuditEvents(ctx, opts, func(event *admin_model.AuditEvent) error {
+			e := audit_service.ToEvent(event)
+			return w.Write([]string{
+				strconv.FormatInt(e.ID, 10),
+				e.Time.UTC().Format(time.RFC3339),
+				e.Category,
+				e.Action,
+				strconv.FormatInt(e.DoerID, 10),
+				e.DoerName,
+				e.IP,
+				e.Target,
+				e.Detail,
+			})
+		})
+		if err == nil {
+			w.Flush()
+			err = w.Error()
+		}
+		if err != nil {
+			// the response has already been started, so the export can only be aborted
+			log.Error("Unable to export the audit log: %v", err)
+		}
+	case "json":
+		ctx.Resp.Header().Set("Content-Type", "application/json")
+		ctx.Resp.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.json"`, filename))
+
+		_, err := ctx.Resp.Write([]byte("["))
+		first := true
+		if err == nil {
+			err = admin_model.IterateAuditEvents(ctx, opts, func(event *admin_model.AuditEvent) error {
+				data, err := json.Marshal(audit_service.ToEvent(event))
+				if err != nil {
+					return err
+				}
+				if !first {
+					data = append([]byte(","), data...)
+				}
+				first = false
+				_, err = ctx.Resp.Write(data)
+				return err
+			})
+		}
+		if err == nil {
+			_, err = ctx.Resp.Write([]byte("]"))
+		}
+		if err != nil {
+			log.Error("Unable to export the audit log: %v", err)
+		}
+	default:
+		ctx.Error(http.StatusBadRequest, "unsupported export format")
+	}
+}
@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 
+	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/modules/auth/pam"
 	"code.gitea.io/gitea/modules/base"
@@ -21,6 +22,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
 	auth_service "code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/ldap"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
@@ -205,6 +207,8 @@ func parseOAuth2Config(form forms.AuthenticationForm) *oauth2.Source {
 		GroupClaimName:                form.Oauth2GroupClaimName,
 		RestrictedGroup:               form.Oauth2RestrictedGroup,
 		AdminGroup:                    form.Oauth2AdminGroup,
+		GroupTeamMap:                  form.Oauth2GroupTeamMap,
+		GroupTeamMapRemoval:           form.Oauth2GroupTeamMapRemoval,
 	}
 }
 
@@ -232,6 +236,18 @@ func parseSSPIConfig(ctx *context.Context, form forms.AuthenticationForm) (*sspi
 	}, nil
 }
 
+// validateRestrictions checks the sign-in restrictions of the form
+func validateRestrictions(form forms.AuthenticationForm) error {
+	if err := auth_service.ValidateCIDRList(form.AllowedCIDRs); err != nil {
+		return err
+	}
+	if err := auth_service.ValidateCIDRList(form.DeniedCIDRs); err != nil {
+		return err
+	}
+	_, err := auth_service.ParseAccessTimeWindows(form.AccessTimeWindows)
+	return err
+}
+
 // NewAuthSourcePost response for adding an auth source
 func NewAuthSourcePost(ctx *context.Context) {
 	form := *web.GetForm(ctx).(*forms.AuthenticationForm)
@@ -294,12 +310,20 @@ func NewAuthSourcePost(ctx *context.Context) {
 		return
 	}
 
+	if err := validateRestrictions(form); err != nil {
+		ctx.RenderWithErr(ctx.Tr("admin.auths.invalid_restrictions", err.Error()), tplAuthNew, form)
+		return
+	}
+
 	if err := auth.CreateSource(&auth.Source{
-		Type:          auth.Type(form.Type),
-		Name:          form.Name,
-		IsActive:      form.IsActive,
-		IsSyncEnabled: form.IsSyncEnabled,
-		Cfg:           config,
+		Type:              auth.Type(form.Type),
+		Name:              form.Name,
+		IsActive:          form.IsActive,
+		IsSyncEnabled:     form.IsSyncEnabled,
+		Cfg:               config,
+		AllowedCIDRs:      form.AllowedCIDRs,
+		DeniedCIDRs:       form.DeniedCIDRs,
+		AccessTimeWindows: form.AccessTimeWindows,
 	}); err != nil {
 		if auth.IsErrSourceAlreadyExist(err) {
 			ctx.Data["Err_Name"] = true
@@ -311,6 +335,7 @@ func NewAuthSourcePost(ctx *context.Context) {
 	}
 
 	log.Trace("Authentication created by admin(%s): %s", ctx.Doer.Name, form.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionAuthSourceCreate, form.Name, "")
 
 	ctx.Flash.Success(ctx.Tr("admin.auths.new_success", form.Name))
 	ctx.Redirect(setting.AppSubURL + "/admin/auths")
@@ -399,10 +424,18 @@ func EditAuthSourcePost(ctx *context.Context) {
 		return
 	}
 
+	if err := validateRestrictions(form); err != nil {
+		ctx.RenderWithErr(ctx.Tr("admin.auths.invalid_restrictions", err.Error()), tplAuthEdit, form)
+		return
+	}
+
 	source.Name = form.Name
 	source.IsActive = form.IsActive
 	source.IsSyncEnabled = form.IsSyncEnabled
 	source.Cfg = config
+	source.AllowedCIDRs = form.AllowedCIDRs
+	source.DeniedCIDRs = form.DeniedCIDRs
+	source.AccessTimeWindows = form.AccessTimeWindows
 	// FIXME: if the name conflicts, it will result in 500: Error 1062: Duplicate entry 'aa' for key 'login_source.UQE_login_source_name'
 	if err := auth.UpdateSource(source); err != nil {
 		if oauth2.IsErrOpenIDConnectInitialize(err) {
@@ -414,6 +447,7 @@ func EditAuthSourcePost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Authentication changed by admin(%s): %d", ctx.Doer.Name, source.ID)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionAuthSourceUpdate, source.Name, "")
 
 	ctx.Flash.Success(ctx.Tr("admin.auths.update_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/auths/" + strconv.FormatInt(form.ID, 10))
@@ -439,6 +473,7 @@ func DeleteAuthSource(ctx *context.Context) {
 		return
 	}
 	log.Trace("Authentication deleted by admin(%s): %d", ctx.Doer.Name, source.ID)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionAuthSourceDelete, source.Name, "")
 
 	ctx.Flash.Success(ctx.Tr("admin.auths.deletion_success"))
 	ctx.JSON(http.StatusOK, map[string]interface{}{
@@ -0,0 +1,122 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/emoji"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	emoji_service "code.gitea.io/gitea/services/emoji"
+)
+
+const tplEmojis base.TplName = "admin/emojis"
+
+// Emojis shows the uploaded custom emoji and the allowed reactions
+func Emojis(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.emojis")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminEmojis"] = true
+
+	emojis, err := issues_model.GetCustomEmojis(ctx, 0)
+	if err != nil {
+		ctx.ServerError("GetCustomEmojis", err)
+		return
+	}
+	reactions, err := issues_model.GetAllowedReactions(ctx)
+	if err != nil {
+		ctx.ServerError("GetAllowedReactions", err)
+		return
+	}
+
+	ctx.Data["Emojis"] = emojis
+	ctx.Data["Reactions"] = strings.Join(reactions, ",")
+	ctx.Data["DefaultReactions"] = strings.Join(setting.UI.Reactions, ",")
+	ctx.Data["MaxFileSize"] = setting.CustomEmoji.MaxFileSize
+	ctx.Data["MaxWidth"] = setting.CustomEmoji.MaxWidth
+	ctx.Data["MaxHeight"] = setting.CustomEmoji.MaxHeight
+	ctx.HTML(http.StatusOK, tplEmojis)
+}
+
+// UploadEmoji uploads a new custom emoji
+func UploadEmoji(ctx *context.Context) {
+	name := strings.ToLower(ctx.FormTrim("name"))
+	file, _, err := ctx.Req.FormFile("image")
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("admin.emojis.image_required"))
+		ctx.Redirect(setting.AppSubURL + "/admin/emojis")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, setting.CustomEmoji.MaxFileSize+1))
+	if err != nil {
+		ctx.ServerError("ReadAll", err)
+		return
+	}
+
+	if err := emoji_service.UploadCustomEmoji(ctx, ctx.Doer, name, data); err != nil {
+		switch {
+		case emoji_service.IsErrInvalidCustomEmoji(err):
+			ctx.Flash.Error(ctx.Tr("admin.emojis.invalid", err.(emoji_service.ErrInvalidCustomEmoji).Reason))
+		case emoji_service.IsErrCustomEmojiAlreadyExist(err):
+			ctx.Flash.Error(ctx.Tr("admin.emojis.already_exists", name))
+		default:
+			ctx.ServerError("UploadCustomEmoji", err)
+			return
+		}
+		ctx.Redirect(setting.AppSubURL + "/admin/emojis")
+		return
+	}
+	log.Trace("Custom emoji %s uploaded by admin %s", name, ctx.Doer.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.emojis.upload_success", name))
+	ctx.Redirect(setting.AppSubURL + "/admin/emojis")
+}
+
+// DeleteEmoji deletes an uploaded custom emoji
+func DeleteEmoji(ctx *context.Context) {
+	name := ctx.FormString("id")
+	if err := emoji_service.DeleteCustomEmoji(ctx, name); err != nil && !issues_model.IsErrCustomEmojiNotExist(err) {
+		ctx.ServerError("DeleteCustomEmoji", err)
+		return
+	}
+	log.Trace("Custom emoji %s deleted by admin %s", name, ctx.Doer.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.emojis.delete_success", name))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/emojis",
+	})
+}
+
+// ReactionsPost sets the allowed reactions
+func ReactionsPost(ctx *context.Context) {
+	var names []string
+	for _, name := range strings.Split(ctx.FormString("reactions"), ",") {
+		if name = strings.Trim(strings.TrimSpace(name), ":"); name != "" {
+			names = append(names, name)
+		}
+	}
+
+	if err := emoji_service.SetAllowedReactions(ctx, names); err != nil {
+		if emoji_service.IsErrUnknownReaction(err) {
+			ctx.Flash.Error(ctx.Tr("admin.emojis.unknown_reaction", err.(emoji_service.ErrUnknownReaction).Name))
+			ctx.Redirect(setting.AppSubURL + "/admin/emojis")
+			return
+		}
+		ctx.ServerError("SetAllowedReactions", err)
+		return
+	}
+	log.Trace("Allowed reactions set to %v by admin %s", emoji.Reactions(), ctx.Doer.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.emojis.reactions_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/emojis")
+}
@@ -0,0 +1,54 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	audit_service "code.gitea.io/gitea/services/audit"
+	"code.gitea.io/gitea/services/maintenance"
+)
+
+const tplMaintenanceMode base.TplName = "admin/maintenance_mode"
+
+// MaintenanceMode shows whether the instance is in maintenance mode
+func MaintenanceMode(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.maintenance_mode")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminMaintenanceMode"] = true
+	ctx.HTML(http.StatusOK, tplMaintenanceMode)
+}
+
+// MaintenanceModePost enables or disables the maintenance mode of the instance
+func MaintenanceModePost(ctx *context.Context) {
+	switch ctx.FormString("action") {
+	case "enable":
+		message := ctx.FormString("message")
+		if err := maintenance.Enable(ctx, ctx.Doer, message); err != nil {
+			ctx.ServerError("EnableMaintenanceMode", err)
+			return
+		}
+		log.Trace("Maintenance mode enabled by admin %s", ctx.Doer.Name)
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionMaintenanceModeEnable, "", message)
+		ctx.Flash.Success(ctx.Tr("admin.maintenance_mode.enable_success"))
+	case "disable":
+		if err := maintenance.Disable(ctx); err != nil {
+			ctx.ServerError("DisableMaintenanceMode", err)
+			return
+		}
+		log.Trace("Maintenance mode disabled by admin %s", ctx.Doer.Name)
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionMaintenanceModeDisable, "", "")
+		ctx.Flash.Success(ctx.Tr("admin.maintenance_mode.disable_success"))
+	default:
+		ctx.NotFound("", nil)
+		return
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/maintenance-mode")
+}
@@ -0,0 +1,183 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+	"net/url"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	admin_service "code.gitea.io/gitea/services/admin"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+const tplOperations base.TplName = "admin/operations"
+
+// PurgeUser deletes a user together with its repositories and packages
+func PurgeUser(ctx *context.Context) {
+	u, err := user_model.GetUserByID(ctx.ParamsInt64(":userid"))
+	if err != nil {
+		ctx.ServerError("GetUserByID", err)
+		return
+	}
+	userLink := setting.AppSubURL + "/admin/users/" + url.PathEscape(ctx.Params(":userid"))
+
+	// admin should not purge themself
+	if u.ID == ctx.Doer.ID {
+		ctx.Flash.Error(ctx.Tr("admin.users.cannot_delete_self"))
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"redirect": userLink,
+		})
+		return
+	}
+
+	runOrRequestOperation(ctx, admin_model.PendingOperationPurgeUser, u, userLink, setting.AppSubURL+"/admin/users")
+}
+
+// PurgeOrganization deletes an organization together with its repositories and packages
+func PurgeOrganization(ctx *context.Context) {
+	org, err := user_model.GetUserByIDCtx(ctx, ctx.FormInt64("id"))
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			ctx.NotFound("GetUserByID", err)
+		} else {
+			ctx.ServerError("GetUserByID", err)
+		}
+		return
+	}
+	if !org.IsOrganization() {
+		ctx.NotFound("IsOrganization", nil)
+		return
+	}
+
+	orgsLink := setting.AppSubURL + "/admin/orgs"
+	runOrRequestOperation(ctx, admin_model.PendingOperationPurgeOrg, org, orgsLink, orgsLink)
+}
+
+// runOrRequestOperation carries out the operation right away, or records it for the approval
+// of a second site admin if REQUIRE_SECOND_APPROVAL is enabled
+func runOrRequestOperation(ctx *context.Context, opType admin_model.PendingOperationType, target *user_model.User, failedLink, doneLink string) {
+	if setting.Admin.RequireSecondApproval {
+		op, err := admin_service.RequestOperation(ctx, ctx.Doer, opType, target)
+		if err != nil {
+			if admin_model.IsErrPendingOperationAlreadyExist(err) {
+				ctx.Flash.Error(ctx.Tr("admin.operations.already_pending", target.Name))
+				ctx.JSON(http.StatusOK, map[string]interface{}{
+					"redirect": failedLink,
+				})
+				return
+			}
+			ctx.ServerError("RequestOperation", err)
+			return
+		}
+		log.Trace("Operation %s on %s requested by admin %s", opType, target.Name, ctx.Doer.Name)
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionOperationRequest, target.Name, string(opType))
+
+		ctx.Flash.Info(ctx.Tr("admin.operations.requested", op.ExpiresUnix.FormatLong()))
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"redirect": setting.AppSubURL + "/admin/operations",
+		})
+		return
+	}
+
+	if err := admin_service.ExecuteOperation(ctx, ctx.Doer, opType, target.ID); err != nil {
+		if organization.IsErrLastOrgOwner(err) {
+			ctx.Flash.Error(ctx.Tr("admin.users.purge_last_owner"))
+			ctx.JSON(http.StatusOK, map[string]interface{}{
+				"redirect": failedLink,
+			})
+			return
+		}
+		ctx.ServerError("ExecuteOperation", err)
+		return
+	}
+	recordOperation(ctx, opType, target.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.operations.done", target.Name))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": doneLink,
+	})
+}
+
+// recordOperation records an operation which has been carried out in the audit log
+func recordOperation(ctx *context.Context, opType admin_model.PendingOperationType, targetName string) {
+	log.Trace("Operation %s on %s carried out by admin %s", opType, targetName, ctx.Doer.Name)
+	switch opType {
+	case admin_model.PendingOperationPurgeUser:
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserDelete, targetName, "purge")
+	case admin_model.PendingOperationPurgeOrg:
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionOrgPurge, targetName, "")
+	}
+}
+
+// Operations shows the operations waiting for the approval of a second site admin
+func Operations(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.operations")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminOperations"] = true
+
+	ops, err := admin_service.FindOperations(ctx)
+	if err != nil {
+		ctx.ServerError("FindOperations", err)
+		return
+	}
+	ctx.Data["Operations"] = ops
+	ctx.Data["SecondApprovalWindow"] = setting.Admin.SecondApprovalWindow.String()
+
+	ctx.HTML(http.StatusOK, tplOperations)
+}
+
+// ApproveOperation approves and carries out an operation requested by another site admin
+func ApproveOperation(ctx *context.Context) {
+	op, err := admin_service.ApproveOperation(ctx, ctx.Doer, ctx.ParamsInt64(":id"))
+	if op != nil {
+		log.Trace("Operation %s on %s requested by %s approved by admin %s", op.Type, op.TargetName, op.RequesterName, ctx.Doer.Name)
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionOperationApprove, op.TargetName, string(op.Type)+" requested by "+op.RequesterName)
+	}
+	if err != nil {
+		switch {
+		case admin_model.IsErrPendingOperationNotExist(err):
+			ctx.Flash.Error(ctx.Tr("admin.operations.not_exist"))
+		case err == admin_service.ErrSelfApproval:
+			ctx.Flash.Error(ctx.Tr("admin.operations.self_approval"))
+		case organization.IsErrLastOrgOwner(err):
+			ctx.Flash.Error(ctx.Tr("admin.users.purge_last_owner"))
+		default:
+			ctx.ServerError("ApproveOperation", err)
+			return
+		}
+		ctx.Redirect(setting.AppSubURL + "/admin/operations")
+		return
+	}
+	recordOperation(ctx, op.Type, op.TargetName)
+
+	ctx.Flash.Success(ctx.Tr("admin.operations.done", op.TargetName))
+	ctx.Redirect(setting.AppSubURL + "/admin/operations")
+}
+
+// CancelOperation cancels an operation waiting for approval
+func CancelOperation(ctx *context.Context) {
+	op, err := admin_service.CancelOperation(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if admin_model.IsErrPendingOperationNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("admin.operations.not_exist"))
+			ctx.Redirect(setting.AppSubURL + "/admin/operations")
+			return
+		}
+		ctx.ServerError("CancelOperation", err)
+		return
+	}
+	log.Trace("Operation %s on %s cancelled by admin %s", op.Type, op.TargetName, ctx.Doer.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionOperationCancel, op.TargetName, string(op.Type))
+
+	ctx.Flash.Success(ctx.Tr("admin.operations.cancel_success"))
+	ctx.Redirect(setting.AppSubURL + "/admin/operations")
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	quota_service "code.gitea.io/gitea/services/quota"
+
+	"github.com/dustin/go-humanize"
+)
+
+const tplQuotas base.TplName = "admin/quota"
+
+// QuotaItem is a quota which overrides the default quota with the disk space used by its owner
+type QuotaItem struct {
+	*user_model.Quota
+	Usage *quota_service.Usage
+}
+
+// Quotas show the default quota and the quotas of the owners which override it
+func Quotas(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.quotas")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminQuotas"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+
+	quotas, count, err := user_model.FindQuotas(ctx, db.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.UserPagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("FindQuotas", err)
+		return
+	}
+
+	items := make([]*QuotaItem, 0, len(quotas))
+	for _, q := range quotas {
+		usage, err := quota_service.GetUsage(ctx, q.OwnerID)
+		if err != nil {
+			ctx.ServerError("GetUsage", err)
+			return
+		}
+		items = append(items, &QuotaItem{Quota: q, Usage: usage})
+	}
+
+	ctx.Data["DefaultMaxSize"] = setting.Quota.DefaultMaxSize
+	ctx.Data["Quotas"] = items
+	ctx.Data["Total"] = count
+	ctx.Data["Page"] = context.NewPagination(int(count), setting.UI.Admin.UserPagingNum, page, 5)
+
+	ctx.HTML(http.StatusOK, tplQuotas)
+}
+
+// QuotasPost sets the quota of a user or an organization
+func QuotasPost(ctx *context.Context) {
+	ownerName := ctx.FormTrim("owner")
+	owner, err := user_model.GetUserByName(ctx, ownerName)
+	if err != nil {
+		if user_model.IsErrUserNotExist(err) {
+			ctx.Flash.Error(ctx.Tr("form.user_not_exist"))
+			ctx.Redirect(setting.AppSubURL + "/admin/quotas")
+		} else {
+			ctx.ServerError("GetUserByName", err)
+		}
+		return
+	}
+
+	var maxSize uint64
+	if size := ctx.FormTrim("max_size"); size != "" {
+		if maxSize, err = humanize.ParseBytes(size); err != nil {
+			ctx.Flash.Error(ctx.Tr("admin.quotas.invalid_size", size))
+			ctx.Redirect(setting.AppSubURL + "/admin/quotas")
+			return
+		}
+	}
+
+	if err := user_model.SetQuota(ctx, owner.ID, int64(maxSize)); err != nil {
+		ctx.ServerError("SetQuota", err)
+		return
+	}
+	log.Trace("Quota of %s set to %d bytes by admin %s", owner.Name, maxSize, ctx.Doer.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.quotas.set_success", owner.Name))
+	ctx.Redirect(setting.AppSubURL + "/admin/quotas")
+}
+
+// DeleteQuota removes the quota of an owner so the default quota applies again
+func DeleteQuota(ctx *context.Context) {
+	if err := user_model.DeleteQuotaByOwnerID(ctx, ctx.FormInt64("id")); err != nil {
+		ctx.ServerError("DeleteQuotaByOwnerID", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.quotas.delete_success"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/admin/quotas",
+	})
+}
@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"net/http"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	audit_service "code.gitea.io/gitea/services/audit"
+	release_service "code.gitea.io/gitea/services/release"
+)
+
+const tplReleaseSigning base.TplName = "admin/release_signing"
+
+// ReleaseSigning shows the key of the instance signing the releases of repositories without a key of their own
+func ReleaseSigning(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.release_signing")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminReleaseSigning"] = true
+	ctx.Data["EnableReleaseSigning"] = setting.Repository.Release.EnableSigning
+
+	key, err := asymkey_model.GetReleaseSigningKey(ctx, 0)
+	if err != nil && !asymkey_model.IsErrReleaseSigningKeyNotExist(err) {
+		ctx.ServerError("GetReleaseSigningKey", err)
+		return
+	}
+	ctx.Data["SigningKey"] = key
+	ctx.HTML(http.StatusOK, tplReleaseSigning)
+}
+
+// ReleaseSigningPost generates, imports or deletes the release signing key of the instance
+func ReleaseSigningPost(ctx *context.Context) {
+	link := setting.AppSubURL + "/admin/release-signing"
+
+	var key *asymkey_model.ReleaseSigningKey
+	var err error
+	switch ctx.FormString("action") {
+	case "generate":
+		name := ctx.FormTrim("name")
+		if name == "" {
+			ctx.Flash.Error(ctx.Tr("repo.settings.release_signing_name_required"))
+			ctx.Redirect(link)
+			return
+		}
+		key, err = release_service.GenerateSigningKey(ctx, 0, name, ctx.FormTrim("email"))
+	case "import":
+		key, err = release_service.ImportSigningKey(ctx, 0, ctx.FormString("private_key"))
+	case "delete":
+		if err := asymkey_model.DeleteReleaseSigningKey(ctx, 0); err != nil && !asymkey_model.IsErrReleaseSigningKeyNotExist(err) {
+			ctx.ServerError("DeleteReleaseSigningKey", err)
+			return
+		}
+		log.Trace("Release signing key of the instance deleted by admin %s", ctx.Doer.Name)
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionReleaseSigningKeyDelete, "", "")
+		ctx.Flash.Success(ctx.Tr("repo.settings.release_signing_deleted"))
+		ctx.Redirect(link)
+		return
+	default:
+		ctx.NotFound("", nil)
+		return
+	}
+	if err != nil {
+		if release_service.IsErrInvalidSigningKey(err) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.release_signing_invalid_key", err.(release_service.ErrInvalidSigningKey).Reason))
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("SetReleaseSigningKey", err)
+		return
+	}
+
+	log.Trace("Release signing key %s of the instance added by admin %s", key.KeyID, ctx.Doer.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionReleaseSigningKeyUpdate, key.KeyID, key.Identity)
+	ctx.Flash.Success(ctx.Tr("repo.settings.release_signing_added", key.KeyID))
+	ctx.Redirect(link)
+}
@@ -5,11 +5,14 @@
 package admin
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
@@ -19,12 +22,16 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/routers/web/explore"
+	audit_service "code.gitea.io/gitea/services/audit"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 const (
-	tplRepos          base.TplName = "admin/repo/list"
-	tplUnadoptedRepos base.TplName = "admin/repo/unadopted"
+	tplRepos           base.TplName = "admin/repo/list"
+	tplUnadoptedRepos  base.TplName = "admin/repo/unadopted"
+	tplTrashedRepos    base.TplName = "admin/repo/trash"
+	tplRepoMaintenance base.TplName = "admin/repo/maintenance"
+	tplRepoConsole     base.TplName = "admin/repo/console"
 )
 
 // Repos show all the repositories
@@ -32,6 +39,7 @@ func Repos(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("admin.repositories")
 	ctx.Data["PageIsAdmin"] = true
 	ctx.Data["PageIsAdminRepositories"] = true
+	ctx.Data["IsTrashEnabled"] = repo_service.IsTrashEnabled()
 
 	explore.RenderRepoSearch(ctx, &explore.RepoSearchOptions{
 		Private:  true,
@@ -52,13 +60,18 @@ func DeleteRepo(ctx *context.Context) {
 		ctx.Repo.GitRepo.Close()
 	}
 
-	if err := repo_service.DeleteRepository(ctx, ctx.Doer, repo, true); err != nil {
-		ctx.ServerError("DeleteRepository", err)
+	if err := repo_service.TrashRepository(ctx, ctx.Doer, repo); err != nil {
+		ctx.ServerError("TrashRepository", err)
 		return
 	}
 	log.Trace("Repository deleted: %s", repo.FullName())
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionRepoDelete, repo.FullName(), "")
 
-	ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+	if repo_service.IsTrashEnabled() {
+		ctx.Flash.Success(ctx.Tr("repo.settings.trash_success", repo.TrashExpiryUnix().FormatDate()))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+	}
 	ctx.JSON(http.StatusOK, map[string]interface{}{
 		"redirect": setting.AppSubURL + "/admin/repos?page=" + url.QueryEscape(ctx.FormString("page")) + "&sort=" + url.QueryEscape(ctx.FormString("sort")),
 	})
@@ -165,3 +178,217 @@ func AdoptOrDeleteRepository(ctx *context.Context) {
 	}
 	ctx.Redirect(setting.AppSubURL + "/admin/repos/unadopted?search=true&q=" + url.QueryEscape(q) + "&page=" + url.QueryEscape(page))
 }
+
+// TrashedRepos lists the repositories in the trash
+func TrashedRepos(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.repositories")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+
+	repos, count, err := repo_model.FindTrashedRepositories(ctx, &repo_model.FindTrashedRepositoriesOptions{
+		ListOptions: db.ListOptions{
+			PageSize: setting.UI.Admin.RepoPagingNum,
+			Page:     page,
+		},
+	})
+	if err != nil {
+		ctx.ServerError("FindTrashedRepositories", err)
+		return
+	}
+	ctx.Data["Repos"] = repos
+	ctx.Data["Total"] = count
+	ctx.Data["CurrentPage"] = page
+
+	pager := context.NewPagination(int(count), setting.UI.Admin.RepoPagingNum, page, 5)
+	ctx.Data["Page"] = pager
+	ctx.HTML(http.StatusOK, tplTrashedRepos)
+}
+
+// RepoMaintenance shows the maintenance status of the repositories which have been pushed to
+func RepoMaintenance(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("admin.repositories")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	onlyFailed := ctx.FormBool("failed")
+
+	ms, count, err := repo_model.FindRepoMaintenances(ctx, &repo_model.FindRepoMaintenancesOptions{
+		ListOptions: db.ListOptions{
+			PageSize: setting.UI.Admin.RepoPagingNum,
+			Page:     page,
+		},
+		OnlyFailed: onlyFailed,
+	})
+	if err != nil {
+		ctx.ServerError("FindRepoMaintenances", err)
+		return
+	}
+	repoIDs := make([]int64, 0, len(ms))
+	for _, m := range ms {
+		repoIDs = append(repoIDs, m.RepoID)
+	}
+	repos, err := repo_model.GetRepositoriesMapByIDs(repoIDs)
+	if err != nil {
+		ctx.ServerError("GetRepositoriesMapByIDs", err)
+		return
+	}
+	for _, m := range ms {
+		m.Repo = repos[m.RepoID]
+	}
+
+	ctx.Data["Maintenances"] = ms
+	ctx.Data["Total"] = count
+	ctx.Data["OnlyFailed"] = onlyFailed
+
+	pager := context.NewPagination(int(count), setting.UI.Admin.RepoPagingNum, page, 5)
+	pager.AddParam(ctx, "failed", "OnlyFailed")
+	ctx.Data["Page"] = pager
+	ctx.HTML(http.StatusOK, tplRepoMaintenance)
+}
+
+// RunRepoMaintenance runs the maintenance of a repository immediately
+func RunRepoMaintenance(ctx *context.Context) {
+	repo, err := repo_model.GetRepositoryByID(ctx.FormInt64("id"))
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetRepositoryByID", err)
+		} else {
+			ctx.ServerError("GetRepositoryByID", err)
+		}
+		return
+	}
+	m, err := repo_model.GetRepoMaintenance(ctx, repo.ID)
+	if err != nil {
+		ctx.ServerError("GetRepoMaintenance", err)
+		return
+	}
+	var pushCount int64
+	if m != nil {
+		pushCount = m.PushCount
+	}
+
+	start := time.Now()
+	maintenanceErr := repo_service.MaintainRepo(ctx, repo, time.Duration(setting.Git.Timeout.GC)*time.Second)
+	if m != nil {
+		if err := repo_model.FinishRepoMaintenance(ctx, repo.ID, pushCount, time.Since(start).Milliseconds(), maintenanceErr); err != nil {
+			ctx.ServerError("FinishRepoMaintenance", err)
+			return
+		}
+	}
+	if maintenanceErr != nil {
+		log.Error("Repository maintenance failed for %v: %v", repo, maintenanceErr)
+		ctx.Flash.Error(ctx.Tr("admin.repos.maintenance_failed", repo.FullName()))
+	} else {
+		ctx.Flash.Success(ctx.Tr("admin.repos.maintenance_success", repo.FullName()))
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/repos/maintenance?page=" + url.QueryEscape(ctx.FormString("page")))
+}
+
+func getConsoleRepository(ctx *context.Context) *repo_model.Repository {
+	repo, err := repo_model.GetRepositoryByID(ctx.ParamsInt64(":id"))
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetRepositoryByID", err)
+		} else {
+			ctx.ServerError("GetRepositoryByID", err)
+		}
+		return nil
+	}
+	return repo
+}
+
+// RepoConsole shows the console to run maintenance commands on a repository
+func RepoConsole(ctx *context.Context) {
+	repo := getConsoleRepository(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("admin.repos.console")
+	ctx.Data["PageIsAdmin"] = true
+	ctx.Data["PageIsAdminRepositories"] = true
+	ctx.Data["Repo"] = repo
+	ctx.Data["ConsoleCommands"] = repo_service.ConsoleCommands
+	ctx.HTML(http.StatusOK, tplRepoConsole)
+}
+
+// consoleWriter sends the output of a console command to the browser as soon as it is written
+type consoleWriter struct {
+	resp context.ResponseWriter
+}
+
+// Write implements io.Writer
+func (w consoleWriter) Write(p []byte) (int, error) {
+	n, err := w.resp.Write(p)
+	w.resp.Flush()
+	return n, err
+}
+
+// RunRepoConsoleCommand runs a maintenance command on a repository and streams its output
+func RunRepoConsoleCommand(ctx *context.Context) {
+	repo := getConsoleRepository(ctx)
+	if ctx.Written() {
+		return
+	}
+	cmd, err := repo_service.GetConsoleCommand(ctx.FormString("command"))
+	if err != nil {
+		ctx.Error(http.StatusBadRequest, err.Error())
+		return
+	}
+
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionRepoConsole, repo.FullName(), cmd.Name)
+
+	ctx.Resp.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	ctx.Resp.Header().Set("X-Content-Type-Options", "nosniff")
+	ctx.Resp.WriteHeader(http.StatusOK)
+
+	w := consoleWriter{resp: ctx.Resp}
+	fmt.Fprintf(w, "$ %s\n", cmd.Line)
+	start := time.Now()
+	if err := cmd.Run(ctx, repo, w); err != nil {
+		// the response has already been started, so the failure can only be reported in the output
+		log.Warn("Console command %s failed for %v: %v", cmd.Name, repo, err)
+		fmt.Fprintf(w, "\n%s failed after %v: %v\n", cmd.Name, time.Since(start).Round(time.Millisecond), err)
+		return
+	}
+	fmt.Fprintf(w, "\n%s finished in %v\n", cmd.Name, time.Since(start).Round(time.Millisecond))
+}
+
+// RestoreOrPurgeTrashedRepository restores a repository from the trash or purges it
+func RestoreOrPurgeTrashedRepository(ctx *context.Context) {
+	repo, err := repo_model.GetTrashedRepositoryByID(ctx, ctx.FormInt64("id"))
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetTrashedRepositoryByID", err)
+		} else {
+			ctx.ServerError("GetTrashedRepositoryByID", err)
+		}
+		return
+	}
+
+	switch ctx.FormString("action") {
+	case "restore":
+		if err := repo_service.RestoreTrashedRepository(ctx, ctx.Doer, repo); err != nil {
+			ctx.ServerError("RestoreTrashedRepository", err)
+			return
+		}
+		ctx.Flash.Success(ctx.Tr("repo.settings.restore_success", repo.FullName()))
+	case "purge":
+		if err := repo_service.PurgeTrashedRepository(ctx, repo); err != nil {
+			ctx.ServerError("PurgeTrashedRepository", err)
+			return
+		}
+		log.Trace("Trashed repository purged: %s", repo.FullName())
+		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+	}
+	ctx.Redirect(setting.AppSubURL + "/admin/repos/trash?page=" + url.QueryEscape(ctx.FormString("page")))
+}
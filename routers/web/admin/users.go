@@ -12,6 +12,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -24,6 +25,7 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/web/explore"
 	user_setting "code.gitea.io/gitea/routers/web/user/setting"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	user_service "code.gitea.io/gitea/services/user"
@@ -42,7 +44,7 @@ func Users(ctx *context.Context) {
 	ctx.Data["PageIsAdminUsers"] = true
 
 	extraParamStrings := map[string]string{}
-	statusFilterKeys := []string{"is_active", "is_admin", "is_restricted", "is_2fa_enabled", "is_prohibit_login"}
+	statusFilterKeys := []string{"is_active", "is_admin", "is_restricted", "is_2fa_enabled", "is_prohibit_login", "is_suspended"}
 	statusFilterMap := map[string]string{}
 	for _, filterKey := range statusFilterKeys {
 		paramKey := "status_filter[" + filterKey + "]"
@@ -74,6 +76,7 @@ func Users(ctx *context.Context) {
 		IsRestricted:       util.OptionalBoolParse(statusFilterMap["is_restricted"]),
 		IsTwoFactorEnabled: util.OptionalBoolParse(statusFilterMap["is_2fa_enabled"]),
 		IsProhibitLogin:    util.OptionalBoolParse(statusFilterMap["is_prohibit_login"]),
+		IsSuspended:        util.OptionalBoolParse(statusFilterMap["is_suspended"]),
 		ExtraParamStrings:  extraParamStrings,
 	}, tplUsers)
 }
@@ -143,25 +146,9 @@ func NewUserPost(ctx *context.Context) {
 		}
 	}
 	if u.LoginType == auth.NoType || u.LoginType == auth.Plain {
-		if len(form.Password) < setting.MinPasswordLength {
+		if err := password.Validate(ctx, form.Password); err != nil {
 			ctx.Data["Err_Password"] = true
-			ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplUserNew, &form)
-			return
-		}
-		if !password.IsComplexEnough(form.Password) {
-			ctx.Data["Err_Password"] = true
-			ctx.RenderWithErr(password.BuildComplexityError(ctx), tplUserNew, &form)
-			return
-		}
-		pwned, err := password.IsPwned(ctx, form.Password)
-		if pwned {
-			ctx.Data["Err_Password"] = true
-			errMsg := ctx.Tr("auth.password_pwned")
-			if err != nil {
-				log.Error(err.Error())
-				errMsg = ctx.Tr("auth.password_pwned_err")
-			}
-			ctx.RenderWithErr(errMsg, tplUserNew, &form)
+			ctx.RenderWithErr(password.PolicyErrorMessage(ctx.Locale, err), tplUserNew, &form)
 			return
 		}
 		u.MustChangePassword = form.MustChangePassword
@@ -196,6 +183,7 @@ func NewUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account created by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserCreate, u.Name, "")
 
 	// Send email notification.
 	if form.SendNotify {
@@ -254,12 +242,22 @@ func EditUser(ctx *context.Context) {
 	ctx.Data["DisableRegularOrgCreation"] = setting.Admin.DisableRegularOrgCreation
 	ctx.Data["DisableMigrations"] = setting.Repository.DisableMigrations
 	ctx.Data["AllowedUserVisibilityModes"] = setting.Service.AllowedUserVisibilityModesSlice.ToVisibleTypeSlice()
+	ctx.Data["RequireSecondApproval"] = setting.Admin.RequireSecondApproval
 
-	prepareUserInfo(ctx)
+	u := prepareUserInfo(ctx)
 	if ctx.Written() {
 		return
 	}
 
+	if u.IsSuspended {
+		notice, err := user_model.GetUserSetting(u.ID, user_model.SettingsKeySuspensionNotice)
+		if err != nil {
+			ctx.ServerError("GetUserSetting", err)
+			return
+		}
+		ctx.Data["SuspensionNotice"] = notice
+	}
+
 	ctx.HTML(http.StatusOK, tplUserEdit)
 }
 
@@ -294,24 +292,9 @@ func EditUserPost(ctx *context.Context) {
 
 	if len(form.Password) > 0 && (u.IsLocal() || u.IsOAuth2()) {
 		var err error
-		if len(form.Password) < setting.MinPasswordLength {
-			ctx.Data["Err_Password"] = true
-			ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplUserEdit, &form)
-			return
-		}
-		if !password.IsComplexEnough(form.Password) {
-			ctx.RenderWithErr(password.BuildComplexityError(ctx), tplUserEdit, &form)
-			return
-		}
-		pwned, err := password.IsPwned(ctx, form.Password)
-		if pwned {
+		if err = password.Validate(ctx, form.Password); err != nil {
 			ctx.Data["Err_Password"] = true
-			errMsg := ctx.Tr("auth.password_pwned")
-			if err != nil {
-				log.Error(err.Error())
-				errMsg = ctx.Tr("auth.password_pwned_err")
-			}
-			ctx.RenderWithErr(errMsg, tplUserNew, &form)
+			ctx.RenderWithErr(password.PolicyErrorMessage(ctx.Locale, err), tplUserEdit, &form)
 			return
 		}
 
@@ -374,6 +357,7 @@ func EditUserPost(ctx *context.Context) {
 	u.Location = form.Location
 	u.MaxRepoCreation = form.MaxRepoCreation
 	u.IsActive = form.Active
+	wasAdmin := u.IsAdmin
 	u.IsAdmin = form.Admin
 	u.IsRestricted = form.Restricted
 	u.AllowGitHook = form.AllowGitHook
@@ -403,11 +387,22 @@ func EditUserPost(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account profile updated by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserUpdate, u.Name, "")
+	recordSiteAdminChange(ctx, u, wasAdmin)
 
 	ctx.Flash.Success(ctx.Tr("admin.users.update_profile_success"))
 	ctx.Redirect(setting.AppSubURL + "/admin/users/" + url.PathEscape(ctx.Params(":userid")))
 }
 
+// recordSiteAdminChange records in the audit log if the site admin permission of a user changed
+func recordSiteAdminChange(ctx *context.Context, u *user_model.User, wasAdmin bool) {
+	if u.IsAdmin && !wasAdmin {
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionSiteAdminGrant, u.Name, "")
+	} else if !u.IsAdmin && wasAdmin {
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionSiteAdminRevoke, u.Name, "")
+	}
+}
+
 // DeleteUser response for deleting a user
 func DeleteUser(ctx *context.Context) {
 	u, err := user_model.GetUserByID(ctx.ParamsInt64(":userid"))
@@ -448,6 +443,7 @@ func DeleteUser(ctx *context.Context) {
 		return
 	}
 	log.Trace("Account deleted by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserDelete, u.Name, "")
 
 	ctx.Flash.Success(ctx.Tr("admin.users.deletion_success"))
 	ctx.JSON(http.StatusOK, map[string]interface{}{
@@ -485,3 +481,46 @@ func DeleteAvatar(ctx *context.Context) {
 
 	ctx.Redirect(setting.AppSubURL + "/admin/users/" + strconv.FormatInt(u.ID, 10))
 }
+
+// SuspendUser hides the content of a user from everybody but the user and site admins and freezes it
+func SuspendUser(ctx *context.Context) {
+	u := prepareUserInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if u.ID == ctx.Doer.ID {
+		ctx.Flash.Error(ctx.Tr("admin.users.cannot_suspend_self"))
+		ctx.Redirect(setting.AppSubURL + "/admin/users/" + strconv.FormatInt(u.ID, 10))
+		return
+	}
+
+	notice := ctx.FormString("notice")
+	if err := user_model.SuspendUser(ctx, u, notice); err != nil {
+		ctx.ServerError("SuspendUser", err)
+		return
+	}
+	log.Trace("Account suspended by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserSuspend, u.Name, notice)
+
+	ctx.Flash.Success(ctx.Tr("admin.users.suspend_success", u.Name))
+	ctx.Redirect(setting.AppSubURL + "/admin/users/" + strconv.FormatInt(u.ID, 10))
+}
+
+// UnsuspendUser lifts the suspension of a user
+func UnsuspendUser(ctx *context.Context) {
+	u := prepareUserInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := user_model.UnsuspendUser(ctx, u); err != nil {
+		ctx.ServerError("UnsuspendUser", err)
+		return
+	}
+	log.Trace("Account suspension lifted by admin (%s): %s", ctx.Doer.Name, u.Name)
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionUserUnsuspend, u.Name, "")
+
+	ctx.Flash.Success(ctx.Tr("admin.users.unsuspend_success", u.Name))
+	ctx.Redirect(setting.AppSubURL + "/admin/users/" + strconv.FormatInt(u.ID, 10))
+}
@@ -8,14 +8,17 @@ import (
 	"errors"
 	"net/http"
 
+	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/auth"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/externalaccount"
 	"code.gitea.io/gitea/services/forms"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 var (
@@ -93,6 +96,9 @@ func TwoFactorPost(ctx *context.Context) {
 		return
 	}
 
+	if u, err := user_model.GetUserByID(id); err == nil {
+		audit_service.Record(ctx, u, ctx.RemoteAddr(), admin_model.AuditActionTwoFactorFailed, u.Name, "totp")
+	}
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_passcode_incorrect"), tplTwofa, forms.TwoFactorAuthForm{})
 }
 
@@ -162,5 +168,45 @@ func TwoFactorScratchPost(ctx *context.Context) {
 		return
 	}
 
+	// A recovery code can be used instead of the scratch token, unlike the token it isn't replaced after its use.
+	usedRecoveryCode, err := auth.UseRecoveryCode(ctx, id, form.Token)
+	if err != nil {
+		ctx.ServerError("UserSignIn", err)
+		return
+	}
+	if usedRecoveryCode {
+		remember := ctx.Session.Get("twofaRemember").(bool)
+		u, err := user_model.GetUserByID(id)
+		if err != nil {
+			ctx.ServerError("UserSignIn", err)
+			return
+		}
+		user_service.RecordSecurityEvent(ctx, u, user_model.SecurityEventRecoveryCodeUse, ctx.Req, "")
+
+		handleSignInFull(ctx, u, remember, false)
+		if ctx.Written() {
+			return
+		}
+		if !flashRecoveryCodesLeft(ctx, id) {
+			return
+		}
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+
+	if u, err := user_model.GetUserByID(id); err == nil {
+		audit_service.Record(ctx, u, ctx.RemoteAddr(), admin_model.AuditActionTwoFactorFailed, u.Name, "scratch")
+	}
 	ctx.RenderWithErr(ctx.Tr("auth.twofa_scratch_token_incorrect"), tplTwofaScratch, forms.TwoFactorScratchAuthForm{})
 }
+
+// flashRecoveryCodesLeft tells the user who has just used a recovery code how many are left
+func flashRecoveryCodesLeft(ctx *context.Context, uid int64) bool {
+	left, err := auth.CountUnusedRecoveryCodes(ctx, uid)
+	if err != nil {
+		ctx.ServerError("CountUnusedRecoveryCodes", err)
+		return false
+	}
+	ctx.Flash.Info(ctx.Tr("auth.twofa_recovery_code_used", left))
+	return true
+}
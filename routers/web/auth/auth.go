@@ -10,6 +10,7 @@ import (
 	"net/http"
 	"strings"
 
+	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
@@ -26,11 +27,13 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/routers/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 	auth_service "code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
 	"code.gitea.io/gitea/services/externalaccount"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
+	user_service "code.gitea.io/gitea/services/user"
 
 	"github.com/markbates/goth"
 )
@@ -193,16 +196,23 @@ func SignInPost(ctx *context.Context) {
 	}
 
 	form := web.GetForm(ctx).(*forms.SignInForm)
-	u, source, err := auth_service.UserSignIn(form.UserName, form.Password)
+	u, source, err := auth_service.UserSignIn(form.UserName, form.Password, ctx.RemoteAddr())
 	if err != nil {
-		if user_model.IsErrUserNotExist(err) || user_model.IsErrEmailAddressNotExist(err) {
+		if auth_service.IsErrSourceRestricted(err) {
+			ctx.RenderWithErr(ctx.Tr("auth.login_source_restricted"), tplSignIn, &form)
+			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionSignInFailed, form.UserName, err.Error())
+		} else if user_model.IsErrUserNotExist(err) || user_model.IsErrEmailAddressNotExist(err) {
 			ctx.RenderWithErr(ctx.Tr("form.username_password_incorrect"), tplSignIn, &form)
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionSignInFailed, form.UserName, err.Error())
 		} else if user_model.IsErrEmailAlreadyUsed(err) {
 			ctx.RenderWithErr(ctx.Tr("form.email_been_used"), tplSignIn, &form)
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionSignInFailed, form.UserName, err.Error())
 		} else if user_model.IsErrUserProhibitLogin(err) {
 			log.Info("Failed authentication attempt for %s from %s: %v", form.UserName, ctx.RemoteAddr(), err)
+			audit_service.Record(ctx, nil, ctx.RemoteAddr(), admin_model.AuditActionSignInFailed, form.UserName, err.Error())
 			ctx.Data["Title"] = ctx.Tr("auth.prohibit_login")
 			ctx.HTML(http.StatusOK, "user/auth/prohibit_login")
 		} else if user_model.IsErrUserInactive(err) {
@@ -348,12 +358,23 @@ func handleSignInFull(ctx *context.Context, u *user_model.User, remember, obeyRe
 	// Clear whatever CSRF cookie has right now, force to generate a new one
 	middleware.DeleteCSRFCookie(ctx.Resp)
 
+	// Make the user change an expired password, the user is redirected to change it by the sign-in middleware
+	if u.IsPasswordExpired() && !u.MustChangePassword {
+		u.MustChangePassword = true
+		if err := user_model.UpdateUserCols(ctx, u, "must_change_password"); err != nil {
+			ctx.ServerError("UpdateUserCols", err)
+			return setting.AppSubURL + "/"
+		}
+	}
+
 	// Register last login
 	u.SetLastLogin()
 	if err := user_model.UpdateUserCols(ctx, u, "last_login_unix"); err != nil {
 		ctx.ServerError("UpdateUserCols", err)
 		return setting.AppSubURL + "/"
 	}
+	audit_service.Record(ctx, u, ctx.RemoteAddr(), admin_model.AuditActionSignIn, u.Name, "")
+	user_service.RecordSignIn(ctx, u, ctx.Req)
 
 	if redirectTo := ctx.GetCookie("redirect_to"); len(redirectTo) > 0 && !utils.IsExternalURL(redirectTo) {
 		middleware.DeleteRedirectToCookie(ctx.Resp)
@@ -398,6 +419,7 @@ func SignOut(ctx *context.Context) {
 			Name: "logout",
 			Data: ctx.Session.ID(),
 		})
+		audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), admin_model.AuditActionSignOut, ctx.Doer.Name, "")
 	}
 	HandleSignOut(ctx)
 	ctx.Redirect(setting.AppSubURL + "/")
@@ -484,25 +506,9 @@ func SignUpPost(ctx *context.Context) {
 		ctx.RenderWithErr(ctx.Tr("form.password_not_match"), tplSignUp, &form)
 		return
 	}
-	if len(form.Password) < setting.MinPasswordLength {
-		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplSignUp, &form)
-		return
-	}
-	if !password.IsComplexEnough(form.Password) {
-		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(password.BuildComplexityError(ctx), tplSignUp, &form)
-		return
-	}
-	pwned, err := password.IsPwned(ctx, form.Password)
-	if pwned {
-		errMsg := ctx.Tr("auth.password_pwned")
-		if err != nil {
-			log.Error(err.Error())
-			errMsg = ctx.Tr("auth.password_pwned_err")
-		}
+	if err := password.Validate(ctx, form.Password); err != nil {
 		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(errMsg, tplSignUp, &form)
+		ctx.RenderWithErr(password.PolicyErrorMessage(ctx.Locale, err), tplSignUp, &form)
 		return
 	}
 
@@ -533,6 +539,10 @@ func createAndHandleCreatedUser(ctx *context.Context, tpl base.TplName, form int
 // createUserInContext creates a user and handles errors within a given context.
 // Optionally a template can be specified.
 func createUserInContext(ctx *context.Context, tpl base.TplName, form interface{}, u *user_model.User, overwrites *user_model.CreateUserOverwriteOptions, gothUser *goth.User, allowLink bool) (ok bool) {
+	// mails are sent in the language of the recipient, so remember the language the user signed up with
+	if u.Language == "" {
+		u.Language = ctx.Locale.Language()
+	}
 	if err := user_model.CreateUser(u, overwrites); err != nil {
 		if allowLink && (user_model.IsErrUserAlreadyExist(err) || user_model.IsErrEmailAlreadyUsed(err)) {
 			if setting.OAuth2Client.AccountLinking == setting.OAuth2AccountLinkingAuto {
@@ -625,7 +635,7 @@ func handleUserCreated(ctx *context.Context, u *user_model.User, gothUser *goth.
 			return
 		}
 
-		mailer.SendActivateAccountMail(ctx.Locale, u)
+		mailer.SendActivateAccountMail(u)
 
 		ctx.Data["IsSendRegisterMail"] = true
 		ctx.Data["Email"] = u.Email
@@ -659,7 +669,7 @@ func Activate(ctx *context.Context) {
 				ctx.Data["ResendLimited"] = true
 			} else {
 				ctx.Data["ActiveCodeLives"] = timeutil.MinutesToFriendly(setting.Service.ActiveCodeLives, ctx.Locale.Language())
-				mailer.SendActivateAccountMail(ctx.Locale, ctx.Doer)
+				mailer.SendActivateAccountMail(ctx.Doer)
 
 				if setting.CacheService.Enabled {
 					if err := ctx.Cache.Put("MailResendLimit_"+ctx.Doer.LowerName, ctx.Doer.LowerName, 180); err != nil {
@@ -804,3 +814,23 @@ func ActivateEmail(ctx *context.Context) {
 	// Should users be logged in automatically here? (consider 2FA requirements, etc.)
 	ctx.Redirect(setting.AppSubURL + "/user/settings/account")
 }
+
+// ActivateBackupEmail verifies the backup e-mail address of a user with the code sent to it
+func ActivateBackupEmail(ctx *context.Context) {
+	code := ctx.FormString("code")
+	emailStr := ctx.FormString("email")
+
+	if email := user_model.VerifyBackupEmailCode(ctx, code, emailStr); email != nil {
+		if err := user_model.ActivateBackupEmail(ctx, email); err != nil {
+			ctx.ServerError("ActivateBackupEmail", err)
+			return
+		}
+
+		log.Trace("Backup email activated: %s", email.Email)
+		ctx.Flash.Success(ctx.Tr("settings.backup_email_activated"))
+	} else {
+		ctx.Flash.Error(ctx.Tr("auth.invalid_code"))
+	}
+
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+}
@@ -14,6 +14,7 @@ import (
 	"net/http"
 	"net/url"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/auth"
@@ -94,6 +95,14 @@ const (
 	AccessTokenErrorCodeUnsupportedGrantType = "unsupported_grant_type"
 	// AccessTokenErrorCodeInvalidScope represents an error code specified in RFC 6749
 	AccessTokenErrorCodeInvalidScope = "invalid_scope"
+	// AccessTokenErrorCodeAuthorizationPending represents an error code specified in RFC 8628
+	AccessTokenErrorCodeAuthorizationPending = "authorization_pending"
+	// AccessTokenErrorCodeSlowDown represents an error code specified in RFC 8628
+	AccessTokenErrorCodeSlowDown = "slow_down"
+	// AccessTokenErrorCodeAccessDenied represents an error code specified in RFC 8628
+	AccessTokenErrorCodeAccessDenied = "access_denied"
+	// AccessTokenErrorCodeExpiredToken represents an error code specified in RFC 8628
+	AccessTokenErrorCodeExpiredToken = "expired_token"
 )
 
 // AccessTokenError represents an error response specified in RFC 6749
@@ -631,10 +640,12 @@ func AccessTokenOAuth(ctx *context.Context) {
 		handleRefreshToken(ctx, form, serverKey, clientKey)
 	case "authorization_code":
 		handleAuthorizationCode(ctx, form, serverKey, clientKey)
+	case GrantTypeDeviceCode:
+		handleDeviceCode(ctx, form, serverKey, clientKey)
 	default:
 		handleAccessTokenError(ctx, AccessTokenError{
 			ErrorCode:        AccessTokenErrorCodeUnsupportedGrantType,
-			ErrorDescription: "Only refresh_token or authorization_code grant type is supported",
+			ErrorDescription: "Only refresh_token, authorization_code or device_code grant type is supported",
 		})
 	}
 }
@@ -820,6 +831,13 @@ func SignInOAuthCallback(ctx *context.Context) {
 		return
 	}
 
+	if err := auth_service.CheckSourceRestrictions(authSource, ctx.RemoteAddr(), time.Now()); err != nil {
+		log.Info("Failed authentication attempt via %s from %s: %v", authSource.Name, ctx.RemoteAddr(), err)
+		ctx.Flash.Error(ctx.Tr("auth.login_source_restricted"))
+		ctx.Redirect(setting.AppSubURL + "/user/login")
+		return
+	}
+
 	u, gothUser, err := oAuth2UserLoginCallback(authSource, ctx.Req, ctx.Resp)
 	if err != nil {
 		if user_model.IsErrUserProhibitLogin(err) {
@@ -957,6 +975,21 @@ func setUserGroupClaims(loginSource *auth.Source, u *user_model.User, gothUser *
 	return wasAdmin != u.IsAdmin || wasRestricted != u.IsRestricted
 }
 
+// syncGroupsToTeams adds the user to and removes the user from the teams mapped by the group claim values
+func syncGroupsToTeams(loginSource *auth.Source, u *user_model.User, gothUser *goth.User) {
+	source := loginSource.Cfg.(*oauth2.Source)
+	if !source.IsGroupTeamSyncEnabled() {
+		return
+	}
+
+	groupClaims, has := gothUser.RawData[source.GroupClaimName]
+	if !has {
+		return
+	}
+
+	source.SyncGroupsToTeams(u, claimValueToStringSlice(groupClaims))
+}
+
 func showLinkingLogin(ctx *context.Context, gothUser goth.User) {
 	if _, err := session.RegenerateSession(ctx.Resp, ctx.Req); err != nil {
 		ctx.ServerError("RegenerateSession", err)
@@ -992,6 +1025,7 @@ func updateAvatarIfNeed(url string, u *user_model.User) {
 
 func handleOAuth2SignIn(ctx *context.Context, source *auth.Source, u *user_model.User, gothUser goth.User) {
 	updateAvatarIfNeed(gothUser.AvatarURL, u)
+	syncGroupsToTeams(source, u, &gothUser)
 
 	needs2FA := false
 	if !source.Cfg.(*oauth2.Source).SkipLocalTwoFA {
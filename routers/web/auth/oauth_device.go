@@ -0,0 +1,277 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"fmt"
+	"html"
+	"net/http"
+	"net/url"
+
+	"code.gitea.io/gitea/models/auth"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/auth/source/oauth2"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const tplDeviceAuthorize base.TplName = "user/auth/device"
+
+// GrantTypeDeviceCode is the grant type a device requests its access token with (RFC 8628)
+const GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+
+// DeviceAuthorizationResponse represents a successful device authorization response (RFC 8628)
+type DeviceAuthorizationResponse struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceAuthorizationOAuth starts the authorization of a device which the user approves on another device
+func DeviceAuthorizationOAuth(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.DeviceAuthorizationForm)
+	app, err := auth.GetOAuth2ApplicationByClientID(ctx, form.ClientID)
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: fmt.Sprintf("cannot load client with client id: '%s'", form.ClientID),
+		})
+		return
+	}
+	// devices usually can't keep a secret, so the client secret is only checked if it is sent
+	if form.ClientSecret != "" && !app.ValidateClientSecret([]byte(form.ClientSecret)) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "client is not authorized",
+		})
+		return
+	}
+
+	device, err := app.CreateDeviceAuthorization(ctx, form.Scope, setting.OAuth2.DeviceCodeExpirationTime)
+	if err != nil {
+		log.Error("CreateDeviceAuthorization: %v", err)
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot create device authorization",
+		})
+		return
+	}
+
+	verificationURI := setting.AppURL + "login/oauth/device"
+	ctx.JSON(http.StatusOK, &DeviceAuthorizationResponse{
+		DeviceCode:              device.DeviceCode,
+		UserCode:                device.FormattedUserCode(),
+		VerificationURI:         verificationURI,
+		VerificationURIComplete: verificationURI + "?user_code=" + url.QueryEscape(device.FormattedUserCode()),
+		ExpiresIn:               setting.OAuth2.DeviceCodeExpirationTime,
+		Interval:                setting.OAuth2.DevicePollingInterval,
+	})
+}
+
+// loadPendingDevice returns the pending device authorization of the user code and its application,
+// the device authorization is nil if the user code is unknown or can't be used anymore.
+func loadPendingDevice(ctx *context.Context, userCode string) (*auth.OAuth2DeviceAuthorization, *auth.OAuth2Application) {
+	device, err := auth.GetOAuth2DeviceAuthorizationByUserCode(ctx, userCode)
+	if err != nil {
+		ctx.ServerError("GetOAuth2DeviceAuthorizationByUserCode", err)
+		return nil, nil
+	}
+	if device == nil || device.IsExpired() || device.Status != auth.OAuth2DeviceStatusPending {
+		return nil, nil
+	}
+	app, err := auth.GetOAuth2ApplicationByID(ctx, device.ApplicationID)
+	if err != nil {
+		if auth.IsErrOAuthApplicationNotFound(err) {
+			return nil, nil
+		}
+		ctx.ServerError("GetOAuth2ApplicationByID", err)
+		return nil, nil
+	}
+	return device, app
+}
+
+// DeviceOAuth shows the page to enter the user code of a device and to approve its authorization
+func DeviceOAuth(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("auth.device_title")
+
+	userCode := ctx.FormTrim("user_code")
+	ctx.Data["user_code"] = userCode
+	if userCode == "" {
+		ctx.HTML(http.StatusOK, tplDeviceAuthorize)
+		return
+	}
+
+	device, app := loadPendingDevice(ctx, userCode)
+	if ctx.Written() {
+		return
+	}
+	if device == nil {
+		ctx.Data["Err_UserCode"] = true
+		ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceAuthorize, nil)
+		return
+	}
+
+	owner, err := user_model.GetUserByID(app.UID)
+	if err != nil {
+		ctx.ServerError("GetUserByID", err)
+		return
+	}
+	ctx.Data["Device"] = device
+	ctx.Data["Application"] = app
+	ctx.Data["ApplicationUserLinkHTML"] = "<a href=\"" + html.EscapeString(owner.HTMLURL()) + "\">@" + html.EscapeString(owner.Name) + "</a>"
+	ctx.HTML(http.StatusOK, tplDeviceAuthorize)
+}
+
+// GrantDeviceOAuth approves or denies the authorization of a device
+func GrantDeviceOAuth(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.GrantDeviceForm)
+	ctx.Data["Title"] = ctx.Tr("auth.device_title")
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplDeviceAuthorize)
+		return
+	}
+
+	device, app := loadPendingDevice(ctx, form.UserCode)
+	if ctx.Written() {
+		return
+	}
+	if device == nil {
+		ctx.Data["Err_UserCode"] = true
+		ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceAuthorize, form)
+		return
+	}
+
+	var updated bool
+	if form.Granted {
+		grant, err := app.GetGrantByUserID(ctx, ctx.Doer.ID)
+		if err != nil {
+			ctx.ServerError("GetGrantByUserID", err)
+			return
+		}
+		if grant == nil {
+			if grant, err = app.CreateGrant(ctx, ctx.Doer.ID, device.Scope); err != nil {
+				ctx.ServerError("CreateGrant", err)
+				return
+			}
+		}
+		if updated, err = device.Approve(ctx, grant.ID); err != nil {
+			ctx.ServerError("Approve", err)
+			return
+		}
+	} else {
+		var err error
+		if updated, err = device.Deny(ctx); err != nil {
+			ctx.ServerError("Deny", err)
+			return
+		}
+	}
+	if !updated {
+		ctx.Data["Err_UserCode"] = true
+		ctx.RenderWithErr(ctx.Tr("auth.device_code_invalid"), tplDeviceAuthorize, form)
+		return
+	}
+
+	ctx.Data["DeviceGranted"] = form.Granted
+	ctx.Data["DeviceDenied"] = !form.Granted
+	ctx.HTML(http.StatusOK, tplDeviceAuthorize)
+}
+
+func handleDeviceCode(ctx *context.Context, form forms.AccessTokenForm, serverKey, clientKey oauth2.JWTSigningKey) {
+	app, err := auth.GetOAuth2ApplicationByClientID(ctx, form.ClientID)
+	if err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidClient,
+			ErrorDescription: fmt.Sprintf("cannot load client with client id: '%s'", form.ClientID),
+		})
+		return
+	}
+	if form.ClientSecret != "" && !app.ValidateClientSecret([]byte(form.ClientSecret)) {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeUnauthorizedClient,
+			ErrorDescription: "client is not authorized",
+		})
+		return
+	}
+	device, err := auth.GetOAuth2DeviceAuthorizationByDeviceCode(ctx, form.DeviceCode)
+	if err != nil || device == nil || device.ApplicationID != app.ID {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "invalid device code",
+		})
+		return
+	}
+
+	if expired := device.IsExpired(); expired || device.Status == auth.OAuth2DeviceStatusDenied {
+		if err := device.Invalidate(ctx); err != nil {
+			log.Error("Unable to invalidate device authorization %d: %v", device.ID, err)
+		}
+		if expired {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeExpiredToken,
+				ErrorDescription: "the device code has expired",
+			})
+			return
+		}
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeAccessDenied,
+			ErrorDescription: "the authorization was denied",
+		})
+		return
+	}
+
+	if device.Status == auth.OAuth2DeviceStatusPending {
+		inTime, err := device.Poll(ctx, setting.OAuth2.DevicePollingInterval)
+		if err != nil {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+				ErrorDescription: "cannot proceed your request",
+			})
+			return
+		}
+		if !inTime {
+			handleAccessTokenError(ctx, AccessTokenError{
+				ErrorCode:        AccessTokenErrorCodeSlowDown,
+				ErrorDescription: fmt.Sprintf("poll at most every %d seconds", setting.OAuth2.DevicePollingInterval),
+			})
+			return
+		}
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeAuthorizationPending,
+			ErrorDescription: "the authorization is pending",
+		})
+		return
+	}
+
+	grant, err := auth.GetOAuth2GrantByID(ctx, device.GrantID)
+	if err != nil || grant == nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidGrant,
+			ErrorDescription: "grant does not exist",
+		})
+		return
+	}
+	// remove the device authorization from database to deny duplicate usage
+	if err := device.Invalidate(ctx); err != nil {
+		handleAccessTokenError(ctx, AccessTokenError{
+			ErrorCode:        AccessTokenErrorCodeInvalidRequest,
+			ErrorDescription: "cannot proceed your request",
+		})
+		return
+	}
+	resp, tokenErr := newAccessTokenResponse(ctx, grant, serverKey, clientKey)
+	if tokenErr != nil {
+		handleAccessTokenError(ctx, *tokenErr)
+		return
+	}
+	ctx.JSON(http.StatusOK, resp)
+}
@@ -21,6 +21,7 @@ import (
 	"code.gitea.io/gitea/routers/utils"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 var (
@@ -61,6 +62,12 @@ func ForgotPasswdPost(ctx *context.Context) {
 	ctx.Data["Email"] = email
 
 	u, err := user_model.GetUserByEmail(email)
+	toBackupEmail := false
+	if user_model.IsErrUserNotExist(err) {
+		// an activated backup e-mail address can be used to recover the account, but for nothing else
+		u, err = user_model.GetUserByBackupEmail(ctx, email)
+		toBackupEmail = err == nil
+	}
 	if err != nil {
 		if user_model.IsErrUserNotExist(err) {
 			ctx.Data["ResetPwdCodeLives"] = timeutil.MinutesToFriendly(setting.Service.ResetPwdCodeLives, ctx.Locale.Language())
@@ -85,7 +92,11 @@ func ForgotPasswdPost(ctx *context.Context) {
 		return
 	}
 
-	mailer.SendResetPasswordMail(u)
+	if toBackupEmail {
+		mailer.SendBackupEmailResetPasswordMail(u, email)
+	} else {
+		mailer.SendResetPasswordMail(u)
+	}
 
 	if setting.CacheService.Enabled {
 		if err = ctx.Cache.Put("MailResendLimit_"+u.LowerName, u.LowerName, 180); err != nil {
@@ -169,39 +180,32 @@ func ResetPasswdPost(ctx *context.Context) {
 
 	// Validate password length.
 	passwd := ctx.FormString("password")
-	if len(passwd) < setting.MinPasswordLength {
-		ctx.Data["IsResetForm"] = true
-		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplResetPassword, nil)
-		return
-	} else if !password.IsComplexEnough(passwd) {
-		ctx.Data["IsResetForm"] = true
-		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(password.BuildComplexityError(ctx), tplResetPassword, nil)
-		return
-	} else if pwned, err := password.IsPwned(ctx, passwd); pwned || err != nil {
-		errMsg := ctx.Tr("auth.password_pwned")
-		if err != nil {
-			log.Error(err.Error())
-			errMsg = ctx.Tr("auth.password_pwned_err")
-		}
+	if err := password.Validate(ctx, passwd); err != nil {
 		ctx.Data["IsResetForm"] = true
 		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(errMsg, tplResetPassword, nil)
+		ctx.RenderWithErr(password.PolicyErrorMessage(ctx.Locale, err), tplResetPassword, nil)
 		return
 	}
 
 	// Handle two-factor
 	regenerateScratchToken := false
+	usedRecoveryCode := false
 	if twofa != nil {
 		if ctx.FormBool("scratch_code") {
-			if !twofa.VerifyScratchToken(ctx.FormString("token")) {
+			token := ctx.FormString("token")
+			if twofa.VerifyScratchToken(token) {
+				regenerateScratchToken = true
+			} else if ok, err := auth.UseRecoveryCode(ctx, u.ID, token); err != nil {
+				ctx.ServerError("UseRecoveryCode", err)
+				return
+			} else if ok {
+				usedRecoveryCode = true
+			} else {
 				ctx.Data["IsResetForm"] = true
 				ctx.Data["Err_Token"] = true
 				ctx.RenderWithErr(ctx.Tr("auth.twofa_scratch_token_incorrect"), tplResetPassword, nil)
 				return
 			}
-			regenerateScratchToken = true
 		} else {
 			passcode := ctx.FormString("passcode")
 			ok, err := twofa.ValidateTOTP(passcode)
@@ -233,7 +237,7 @@ func ResetPasswdPost(ctx *context.Context) {
 		return
 	}
 	u.MustChangePassword = false
-	if err := user_model.UpdateUserCols(ctx, u, "must_change_password", "passwd", "passwd_hash_algo", "rands", "salt"); err != nil {
+	if err := user_model.UpdateUserCols(ctx, u, "must_change_password", "passwd", "passwd_hash_algo", "passwd_changed_unix", "rands", "salt"); err != nil {
 		ctx.ServerError("UpdateUser", err)
 		return
 	}
@@ -263,6 +267,20 @@ func ResetPasswdPost(ctx *context.Context) {
 		return
 	}
 
+	if usedRecoveryCode {
+		user_service.RecordSecurityEvent(ctx, u, user_model.SecurityEventRecoveryCodeUse, ctx.Req, "")
+
+		handleSignInFull(ctx, u, remember, false)
+		if ctx.Written() {
+			return
+		}
+		if !flashRecoveryCodesLeft(ctx, u.ID) {
+			return
+		}
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+
 	handleSignIn(ctx, u, remember)
 }
 
@@ -271,6 +289,7 @@ func MustChangePassword(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("auth.must_change_password")
 	ctx.Data["ChangePasscodeLink"] = setting.AppSubURL + "/user/settings/change_password"
 	ctx.Data["MustChangePassword"] = true
+	ctx.Data["PasswordExpired"] = ctx.Doer.IsPasswordExpired()
 	ctx.HTML(http.StatusOK, tplMustChangePassword)
 }
 
@@ -298,37 +317,20 @@ func MustChangePasswordPost(ctx *context.Context) {
 		return
 	}
 
-	if len(form.Password) < setting.MinPasswordLength {
-		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(ctx.Tr("auth.password_too_short", setting.MinPasswordLength), tplMustChangePassword, &form)
-		return
-	}
-
-	if !password.IsComplexEnough(form.Password) {
-		ctx.Data["Err_Password"] = true
-		ctx.RenderWithErr(password.BuildComplexityError(ctx), tplMustChangePassword, &form)
-		return
-	}
-	pwned, err := password.IsPwned(ctx, form.Password)
-	if pwned {
+	if err := password.Validate(ctx, form.Password); err != nil {
 		ctx.Data["Err_Password"] = true
-		errMsg := ctx.Tr("auth.password_pwned")
-		if err != nil {
-			log.Error(err.Error())
-			errMsg = ctx.Tr("auth.password_pwned_err")
-		}
-		ctx.RenderWithErr(errMsg, tplMustChangePassword, &form)
+		ctx.RenderWithErr(password.PolicyErrorMessage(ctx.Locale, err), tplMustChangePassword, &form)
 		return
 	}
 
-	if err = u.SetPassword(form.Password); err != nil {
+	if err := u.SetPassword(form.Password); err != nil {
 		ctx.ServerError("UpdateUser", err)
 		return
 	}
 
 	u.MustChangePassword = false
 
-	if err := user_model.UpdateUserCols(ctx, u, "must_change_password", "passwd", "passwd_hash_algo", "salt"); err != nil {
+	if err := user_model.UpdateUserCols(ctx, u, "must_change_password", "passwd", "passwd_hash_algo", "passwd_changed_unix", "salt"); err != nil {
 		ctx.ServerError("UpdateUser", err)
 		return
 	}
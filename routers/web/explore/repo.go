@@ -53,6 +53,10 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 		orderBy = db.SearchOrderByRecentUpdated
 	case "leastupdate":
 		orderBy = db.SearchOrderByLeastUpdated
+	case "recentpush":
+		orderBy = db.SearchOrderByRecentPushed
+	case "leastpush":
+		orderBy = db.SearchOrderByLeastPushed
 	case "reversealphabetically":
 		orderBy = db.SearchOrderByAlphabeticallyReverse
 	case "alphabetically":
@@ -81,6 +85,9 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 	language := ctx.FormTrim("language")
 	ctx.Data["Language"] = language
 
+	license := ctx.FormTrim("license")
+	ctx.Data["License"] = license
+
 	repos, count, err = models.SearchRepository(&models.SearchRepoOptions{
 		ListOptions: db.ListOptions{
 			Page:     page,
@@ -95,6 +102,7 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 		AllLimited:         true,
 		TopicOnly:          topicOnly,
 		Language:           language,
+		License:            license,
 		IncludeDescription: setting.UI.SearchRepoDescription,
 	})
 	if err != nil {
@@ -110,6 +118,7 @@ func RenderRepoSearch(ctx *context.Context, opts *RepoSearchOptions) {
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "topic", "TopicOnly")
 	pager.AddParam(ctx, "language", "Language")
+	pager.AddParam(ctx, "license", "License")
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(http.StatusOK, opts.TplName)
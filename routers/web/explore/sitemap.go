@@ -0,0 +1,190 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package explore
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sitemap"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/util"
+	wiki_service "code.gitea.io/gitea/services/wiki"
+)
+
+// SitemapUserSearchOptions returns the options to search the users or organizations listed by the sitemaps
+func SitemapUserSearchOptions(tp user_model.UserType, page int) *user_model.SearchUserOptions {
+	opts := &user_model.SearchUserOptions{
+		Type:        tp,
+		ListOptions: db.ListOptions{Page: page, PageSize: setting.UI.SitemapPagingNum},
+		OrderBy:     db.SearchOrderByID,
+		Visible:     []structs.VisibleType{structs.VisibleTypePublic},
+		NoIndex:     util.OptionalBoolFalse,
+	}
+	if tp == user_model.UserTypeIndividual {
+		opts.IsActive = util.OptionalBoolTrue
+	}
+	return opts
+}
+
+// SitemapRepoSearchOptions returns the options to search the repositories listed by the sitemaps
+func SitemapRepoSearchOptions(page int) *models.SearchRepoOptions {
+	return &models.SearchRepoOptions{
+		ListOptions:    db.ListOptions{Page: page, PageSize: setting.UI.SitemapPagingNum},
+		OrderBy:        db.SearchOrderByID,
+		AllPublic:      true,
+		ExcludeNoIndex: true,
+	}
+}
+
+func writeSitemap(ctx *context.Context, m *sitemap.Sitemap) {
+	ctx.Resp.Header().Set("Content-Type", "text/xml")
+	if _, err := m.WriteTo(ctx.Resp); err != nil {
+		ctx.ServerError("SitemapWriteTo", err)
+	}
+}
+
+func renderUserSitemap(ctx *context.Context, tp user_model.UserType) {
+	page := int(ctx.ParamsInt64("idx"))
+	if page <= 0 {
+		ctx.NotFound("", nil)
+		return
+	}
+
+	users, _, err := user_model.SearchUsers(SitemapUserSearchOptions(tp, page))
+	if err != nil {
+		ctx.ServerError("SearchUsers", err)
+		return
+	}
+
+	m := sitemap.NewSitemap()
+	for _, u := range users {
+		lastMod := u.UpdatedUnix.AsTime()
+		m.Add(sitemap.URL{URL: u.HTMLURL(), LastMod: &lastMod})
+	}
+	writeSitemap(ctx, m)
+}
+
+// UsersSitemap renders a page of the sitemap of the users
+func UsersSitemap(ctx *context.Context) {
+	if setting.Service.Explore.DisableUsersPage {
+		ctx.NotFound("", nil)
+		return
+	}
+	renderUserSitemap(ctx, user_model.UserTypeIndividual)
+}
+
+// OrganizationsSitemap renders a page of the sitemap of the organizations
+func OrganizationsSitemap(ctx *context.Context) {
+	renderUserSitemap(ctx, user_model.UserTypeOrganization)
+}
+
+// ReposSitemap renders a page of the sitemap of the repositories, their releases and their wiki pages
+func ReposSitemap(ctx *context.Context) {
+	page := int(ctx.ParamsInt64("idx"))
+	if page <= 0 {
+		ctx.NotFound("", nil)
+		return
+	}
+
+	repos, _, err := models.SearchRepository(SitemapRepoSearchOptions(page))
+	if err != nil {
+		ctx.ServerError("SearchRepository", err)
+		return
+	}
+
+	m := sitemap.NewSitemap()
+	for _, repo := range repos {
+		if m.IsFull() {
+			break
+		}
+		lastMod := repo.UpdatedUnix.AsTime()
+		m.Add(sitemap.URL{URL: repo.HTMLURL(), LastMod: &lastMod})
+
+		if repo.UnitEnabled(unit.TypeReleases) {
+			if err := addReleasesToSitemap(m, repo); err != nil {
+				ctx.ServerError("addReleasesToSitemap", err)
+				return
+			}
+		}
+		if repo.UnitEnabled(unit.TypeWiki) && repo.HasWiki() {
+			if err := addWikiPagesToSitemap(ctx, m, repo); err != nil {
+				ctx.ServerError("addWikiPagesToSitemap", err)
+				return
+			}
+		}
+	}
+	writeSitemap(ctx, m)
+}
+
+func addReleasesToSitemap(m *sitemap.Sitemap, repo *repo_model.Repository) error {
+	releases, err := models.GetReleasesByRepoID(repo.ID, models.FindReleasesOptions{})
+	if err != nil {
+		return err
+	}
+	if len(releases) == 0 || m.IsFull() {
+		return nil
+	}
+
+	// the releases are ordered by their creation, the newest first
+	lastMod := releases[0].CreatedUnix.AsTime()
+	m.Add(sitemap.URL{URL: repo.HTMLURL() + "/releases", LastMod: &lastMod})
+	for _, rel := range releases {
+		if m.IsFull() {
+			return nil
+		}
+		rel.Repo = repo
+		lastMod := rel.CreatedUnix.AsTime()
+		m.Add(sitemap.URL{URL: rel.HTMLURL(), LastMod: &lastMod})
+	}
+	return nil
+}
+
+func addWikiPagesToSitemap(ctx *context.Context, m *sitemap.Sitemap, repo *repo_model.Repository) error {
+	wikiRepo, err := git.OpenRepository(ctx, repo.WikiPath())
+	if err != nil {
+		return err
+	}
+	defer wikiRepo.Close()
+
+	commit, err := wikiRepo.GetBranchCommit("master")
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	entries, err := commit.ListEntries()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if m.IsFull() {
+			return nil
+		}
+		if !entry.IsRegular() {
+			continue
+		}
+		wikiName, err := wiki_service.FilenameToName(entry.Name())
+		if err != nil {
+			if models.IsErrWikiInvalidFileName(err) {
+				continue
+			}
+			return err
+		}
+		c, err := wikiRepo.GetCommitByPath(entry.Name())
+		if err != nil {
+			return err
+		}
+		lastMod := c.Author.When
+		m.Add(sitemap.URL{URL: repo.HTMLURL() + "/wiki/" + wiki_service.NameToSubURL(wikiName), LastMod: &lastMod})
+	}
+	return nil
+}
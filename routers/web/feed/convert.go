@@ -14,6 +14,7 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/markup"
 	"code.gitea.io/gitea/modules/markup/markdown"
 	"code.gitea.io/gitea/modules/setting"
@@ -47,6 +48,35 @@ func toReleaseLink(act *models.Action) string {
 	return act.GetRepoLink() + "/releases/tag/" + util.PathEscapeSegments(act.GetBranch())
 }
 
+// releaseAssetsDescription lists the links to the assets of a published release, external files are linked directly
+func releaseAssetsDescription(ctx *context.Context, act *models.Action) string {
+	rel, err := models.GetRelease(act.RepoID, act.GetBranch())
+	if err != nil {
+		// the release may have been deleted since it was published
+		return ""
+	}
+	if err := models.GetReleaseAttachments(ctx, rel); err != nil {
+		log.Error("GetReleaseAttachments: %v", err)
+		return ""
+	}
+
+	var desc strings.Builder
+	for _, attach := range rel.Attachments {
+		if desc.Len() != 0 {
+			desc.WriteString("\n")
+		}
+		link := attach.DownloadURL()
+		if attach.IsExternal() {
+			link = attach.ExternalURL
+		}
+		fmt.Fprintf(&desc, "<a href=\"%s\">%s</a>", html.EscapeString(link), html.EscapeString(attach.Name))
+		if attach.Checksum != "" {
+			fmt.Fprintf(&desc, " (%s)", html.EscapeString(attach.Checksum))
+		}
+	}
+	return desc.String()
+}
+
 // renderMarkdown creates a minimal markdown render context from an action.
 // If rendering fails, the original markdown text is returned
 func renderMarkdown(ctx *context.Context, act *models.Action, content string) string {
@@ -100,6 +130,12 @@ func feedActionsToFeedItems(ctx *context.Context, actions models.ActionList) (it
 		case models.ActionTransferRepo:
 			link.Href = act.GetRepoLink()
 			title += ctx.TrHTMLEscapeArgs("action.transfer_repo", act.GetContent(), act.GetRepoLink(), act.ShortRepoPath())
+		case models.ActionPublicizeRepo:
+			link.Href = act.GetRepoLink()
+			title += ctx.TrHTMLEscapeArgs("action.publicize_repo", act.GetRepoLink(), act.ShortRepoPath())
+		case models.ActionPrivatizeRepo:
+			link.Href = act.GetRepoLink()
+			title += ctx.TrHTMLEscapeArgs("action.privatize_repo", act.GetRepoLink(), act.ShortRepoPath())
 		case models.ActionPushTag:
 			link.Href = toTagLink(act)
 			title += ctx.TrHTMLEscapeArgs("action.push_tag", act.GetRepoLink(), link.Href, act.GetTag(), act.ShortRepoPath())
@@ -227,6 +263,8 @@ func feedActionsToFeedItems(ctx *context.Context, actions models.ActionList) (it
 				desc = act.GetIssueTitle()
 			case models.ActionPullReviewDismissed:
 				desc = ctx.Tr("action.review_dismissed_reason") + "\n\n" + act.GetIssueInfos()[2]
+			case models.ActionPublishRelease:
+				desc = releaseAssetsDescription(ctx, act)
 			}
 		}
 		if len(content) == 0 {
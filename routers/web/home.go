@@ -6,14 +6,21 @@
 package web
 
 import (
+	"fmt"
 	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	user_model "code.gitea.io/gitea/models/user"
 
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sitemap"
 	"code.gitea.io/gitea/modules/web/middleware"
 	"code.gitea.io/gitea/routers/web/auth"
+	"code.gitea.io/gitea/routers/web/explore"
 	"code.gitea.io/gitea/routers/web/user"
 )
 
@@ -59,6 +66,46 @@ func Home(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplHome)
 }
 
+// HomeSitemap renders the index of the sitemaps of the users, organizations and repositories
+func HomeSitemap(ctx *context.Context) {
+	m := sitemap.NewSitemapIndex()
+	addPages := func(link string, count int64) {
+		pages := (int(count) + setting.UI.SitemapPagingNum - 1) / setting.UI.SitemapPagingNum
+		for i := 1; i <= pages; i++ {
+			m.Add(sitemap.URL{URL: fmt.Sprintf("%s/explore/%s/sitemap-%d.xml", strings.TrimSuffix(setting.AppURL, "/"), link, i)})
+		}
+	}
+
+	if !setting.Service.Explore.DisableUsersPage {
+		_, count, err := user_model.SearchUsers(explore.SitemapUserSearchOptions(user_model.UserTypeIndividual, 1))
+		if err != nil {
+			ctx.ServerError("SearchUsers", err)
+			return
+		}
+		addPages("users", count)
+	}
+
+	_, count, err := user_model.SearchUsers(explore.SitemapUserSearchOptions(user_model.UserTypeOrganization, 1))
+	if err != nil {
+		ctx.ServerError("SearchUsers", err)
+		return
+	}
+	addPages("organizations", count)
+
+	repoOpts := explore.SitemapRepoSearchOptions(1)
+	_, count, err = models.SearchRepositoryByCondition(repoOpts, models.SearchRepositoryCondition(repoOpts), false)
+	if err != nil {
+		ctx.ServerError("SearchRepository", err)
+		return
+	}
+	addPages("repos", count)
+
+	ctx.Resp.Header().Set("Content-Type", "text/xml")
+	if _, err := m.WriteTo(ctx.Resp); err != nil {
+		ctx.ServerError("SitemapWriteTo", err)
+	}
+}
+
 // NotFound render 404 page
 func NotFound(ctx *context.Context) {
 	ctx.Data["Title"] = "Page Not Found"
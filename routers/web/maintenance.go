@@ -0,0 +1,36 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/services/maintenance"
+)
+
+const tplMaintenance base.TplName = "status/maintenance"
+
+// maintenanceMode rejects the requests changing anything while the instance is in maintenance mode,
+// the requests of LFS clients are checked by their handlers to answer them in the format they understand
+func maintenanceMode(ctx *context.Context) {
+	mode := maintenance.Get(ctx)
+	if mode == nil {
+		return
+	}
+	ctx.Data["MaintenanceMode"] = mode
+
+	if maintenance.IsAllowed(ctx.Req, ctx.Doer) || strings.Contains(ctx.Req.URL.Path, "/info/lfs/") {
+		return
+	}
+	if strings.HasSuffix(ctx.Req.URL.Path, "/info/refs") || strings.HasSuffix(ctx.Req.URL.Path, "/git-receive-pack") {
+		ctx.PlainText(http.StatusServiceUnavailable, maintenance.Message(mode))
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("error.maintenance_title")
+	ctx.HTML(http.StatusServiceUnavailable, tplMaintenance)
+}
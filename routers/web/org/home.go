@@ -72,6 +72,10 @@ func Home(ctx *context.Context) {
 		orderBy = db.SearchOrderByRecentUpdated
 	case "leastupdate":
 		orderBy = db.SearchOrderByLeastUpdated
+	case "recentpush":
+		orderBy = db.SearchOrderByRecentPushed
+	case "leastpush":
+		orderBy = db.SearchOrderByLeastPushed
 	case "reversealphabetically":
 		orderBy = db.SearchOrderByAlphabeticallyReverse
 	case "alphabetically":
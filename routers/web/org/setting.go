@@ -6,6 +6,8 @@
 package org
 
 import (
+	stdCtx "context"
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
@@ -22,6 +24,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
 	user_setting "code.gitea.io/gitea/routers/web/user/setting"
+	emoji_service "code.gitea.io/gitea/services/emoji"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/org"
 	user_service "code.gitea.io/gitea/services/user"
@@ -90,6 +93,10 @@ func SettingsPost(ctx *context.Context) {
 		// reset ctx.org.OrgLink with new name
 		ctx.Org.OrgLink = setting.AppSubURL + "/org/" + url.PathEscape(form.Name)
 		log.Trace("Organization name changed: %s -> %s", org.Name, form.Name)
+		// the names of the custom emoji of the organization start with its name
+		if err := emoji_service.Reload(ctx); err != nil {
+			log.Error("Unable to reload the custom emoji: %v", err)
+		}
 		nameChanged = false
 	}
 
@@ -106,6 +113,7 @@ func SettingsPost(ctx *context.Context) {
 	org.Website = form.Website
 	org.Location = form.Location
 	org.RepoAdminChangeTeamAccess = form.RepoAdminChangeTeamAccess
+	org.NoIndex = form.NoIndex
 
 	visibilityChanged := form.Visibility != org.Visibility
 	org.Visibility = form.Visibility
@@ -118,7 +126,7 @@ func SettingsPost(ctx *context.Context) {
 	// update forks visibility
 	if visibilityChanged {
 		repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
-			Actor: org.AsUser(), Private: true, ListOptions: db.ListOptions{Page: 1, PageSize: org.NumRepos},
+			Actor: org.AsUser(), Private: true, IncludeTrashed: true, ListOptions: db.ListOptions{Page: 1, PageSize: org.NumRepos},
 		})
 		if err != nil {
 			ctx.ServerError("GetRepositories", err)
@@ -230,6 +238,35 @@ func DeleteWebhook(ctx *context.Context) {
 	})
 }
 
+// ApplyTemplateWebhook copies a webhook template to the existing repositories of the organization
+func ApplyTemplateWebhook(ctx *context.Context) {
+	w, err := webhook.GetWebhookByOrgID(ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if webhook.IsErrWebhookNotExist(err) {
+			ctx.NotFound("GetWebhookByOrgID", nil)
+		} else {
+			ctx.ServerError("GetWebhookByOrgID", err)
+		}
+		return
+	}
+	if !w.IsTemplate {
+		ctx.NotFound("ApplyTemplateWebhook", nil)
+		return
+	}
+
+	var count int
+	if err := db.WithTx(func(ctx stdCtx.Context) error {
+		count, err = webhook.ApplyTemplateWebhookToRepos(ctx, w)
+		return err
+	}); err != nil {
+		ctx.ServerError("ApplyTemplateWebhookToRepos", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.hooks.template_applied", count))
+	ctx.Redirect(fmt.Sprintf("%s/settings/hooks/%d", ctx.Org.OrgLink, w.ID))
+}
+
 // Labels render organization labels page
 func Labels(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.labels")
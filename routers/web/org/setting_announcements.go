@@ -0,0 +1,169 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"fmt"
+	"net/http"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	// tplSettingsAnnouncements template path for render the announcements of an organization
+	tplSettingsAnnouncements base.TplName = "org/settings/announcements"
+	// tplSettingsAnnouncementEdit template path for render the edit page of announcements
+	tplSettingsAnnouncementEdit base.TplName = "org/settings/announcement_edit"
+)
+
+// SettingsAnnouncements render the announcements of the organization
+func SettingsAnnouncements(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsAnnouncements"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	announcements, total, err := admin_model.FindAnnouncements(ctx, &admin_model.FindAnnouncementsOptions{
+		ListOptions: db.ListOptions{Page: page, PageSize: setting.UI.Admin.NoticePagingNum},
+		OrgID:       ctx.Org.Organization.ID,
+	})
+	if err != nil {
+		ctx.ServerError("FindAnnouncements", err)
+		return
+	}
+
+	ctx.Data["Announcements"] = announcements
+	ctx.Data["Total"] = total
+	ctx.Data["Now"] = timeutil.TimeStampNow()
+	ctx.Data["Page"] = context.NewPagination(int(total), setting.UI.Admin.NoticePagingNum, page, 5)
+	ctx.HTML(http.StatusOK, tplSettingsAnnouncements)
+}
+
+// SettingsAnnouncementsPost publishes an announcement of the organization
+func SettingsAnnouncementsPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AnnouncementForm)
+	link := ctx.Org.OrgLink + "/settings/announcements"
+
+	announcement := &admin_model.Announcement{OrgID: ctx.Org.Organization.ID}
+	if !saveAnnouncement(ctx, form, announcement, link) {
+		return
+	}
+	log.Trace("Announcement %d of organization %s published by %s", announcement.ID, ctx.Org.Organization.Name, ctx.Doer.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.announcements.create_success"))
+	ctx.Redirect(link)
+}
+
+// EditAnnouncement render the page to edit an announcement of the organization
+func EditAnnouncement(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsAnnouncements"] = true
+
+	announcement := getAnnouncementByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Announcement"] = announcement
+	ctx.HTML(http.StatusOK, tplSettingsAnnouncementEdit)
+}
+
+// EditAnnouncementPost updates an announcement of the organization
+func EditAnnouncementPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.AnnouncementForm)
+
+	announcement := getAnnouncementByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !saveAnnouncement(ctx, form, announcement, fmt.Sprintf("%s/settings/announcements/%d", ctx.Org.OrgLink, announcement.ID)) {
+		return
+	}
+	log.Trace("Announcement %d of organization %s updated by %s", announcement.ID, ctx.Org.Organization.Name, ctx.Doer.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.announcements.update_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/announcements")
+}
+
+// DeleteAnnouncement deletes an announcement of the organization
+func DeleteAnnouncement(ctx *context.Context) {
+	id := ctx.FormInt64("id")
+	announcement, err := admin_model.GetAnnouncementByID(ctx, id)
+	if err == nil && announcement.OrgID == ctx.Org.Organization.ID {
+		if err := admin_model.DeleteAnnouncement(ctx, id); err != nil {
+			ctx.ServerError("DeleteAnnouncement", err)
+			return
+		}
+		log.Trace("Announcement %d of organization %s deleted by %s", id, ctx.Org.Organization.Name, ctx.Doer.Name)
+	} else if err != nil && !admin_model.IsErrAnnouncementNotExist(err) {
+		ctx.ServerError("GetAnnouncementByID", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("admin.announcements.delete_success"))
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/announcements",
+	})
+}
+
+func getAnnouncementByParams(ctx *context.Context) *admin_model.Announcement {
+	announcement, err := admin_model.GetAnnouncementByID(ctx, ctx.ParamsInt64(":id"))
+	if err == nil && announcement.OrgID != ctx.Org.Organization.ID {
+		err = admin_model.ErrAnnouncementNotExist{ID: announcement.ID}
+	}
+	if err != nil {
+		ctx.NotFoundOrServerError("GetAnnouncementByID", admin_model.IsErrAnnouncementNotExist, err)
+		return nil
+	}
+	return announcement
+}
+
+// saveAnnouncement creates or updates the announcement from the form,
+// it redirects to the link with an error message and returns false if the form is invalid
+func saveAnnouncement(ctx *context.Context, form *forms.AnnouncementForm, announcement *admin_model.Announcement, link string) bool {
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link)
+		return false
+	}
+	start, end, err := form.ParseSchedule(setting.DefaultUILocation)
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("admin.announcements.invalid_time"))
+		ctx.Redirect(link)
+		return false
+	}
+
+	announcement.Content = form.Content
+	announcement.Severity = admin_model.AnnouncementSeverity(form.Severity)
+	announcement.Dismissible = form.Dismissible
+	announcement.StartUnix = start
+	announcement.EndUnix = end
+	if announcement.ID == 0 {
+		err = admin_model.CreateAnnouncement(ctx, announcement)
+	} else {
+		err = admin_model.UpdateAnnouncement(ctx, announcement)
+	}
+	if err != nil {
+		if admin_model.IsErrInvalidAnnouncement(err) {
+			ctx.Flash.Error(ctx.Tr("admin.announcements.invalid", err.(admin_model.ErrInvalidAnnouncement).Reason))
+			ctx.Redirect(link)
+		} else {
+			ctx.ServerError("SaveAnnouncement", err)
+		}
+		return false
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"io"
+	"net/http"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/emoji"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	emoji_service "code.gitea.io/gitea/services/emoji"
+)
+
+// tplSettingsEmojis template path for render the custom emoji of an organization
+const tplSettingsEmojis base.TplName = "org/settings/emojis"
+
+// SettingsEmojis render the custom emoji of the organization
+func SettingsEmojis(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsEmojis"] = true
+
+	emojis, err := issues_model.GetCustomEmojis(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetCustomEmojis", err)
+		return
+	}
+	aliases := make(map[string]string, len(emojis))
+	for _, e := range emojis {
+		aliases[e.Name] = emoji.OrgAlias(ctx.Org.Organization.Name, e.Name)
+	}
+
+	ctx.Data["Emojis"] = emojis
+	ctx.Data["Aliases"] = aliases
+	ctx.Data["AliasPrefix"] = emoji.OrgAlias(ctx.Org.Organization.Name, "")
+	ctx.Data["MaxFileSize"] = setting.CustomEmoji.MaxFileSize
+	ctx.Data["MaxWidth"] = setting.CustomEmoji.MaxWidth
+	ctx.Data["MaxHeight"] = setting.CustomEmoji.MaxHeight
+	ctx.HTML(http.StatusOK, tplSettingsEmojis)
+}
+
+// SettingsEmojisPost uploads a new custom emoji for the organization
+func SettingsEmojisPost(ctx *context.Context) {
+	name := strings.ToLower(ctx.FormTrim("name"))
+	file, _, err := ctx.Req.FormFile("image")
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("admin.emojis.image_required"))
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/emojis")
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, setting.CustomEmoji.MaxFileSize+1))
+	if err != nil {
+		ctx.ServerError("ReadAll", err)
+		return
+	}
+
+	alias := emoji.OrgAlias(ctx.Org.Organization.Name, name)
+	if err := emoji_service.UploadOrgCustomEmoji(ctx, ctx.Doer, ctx.Org.Organization.AsUser(), name, data); err != nil {
+		switch {
+		case emoji_service.IsErrInvalidCustomEmoji(err):
+			ctx.Flash.Error(ctx.Tr("admin.emojis.invalid", err.(emoji_service.ErrInvalidCustomEmoji).Reason))
+		case emoji_service.IsErrCustomEmojiAlreadyExist(err):
+			ctx.Flash.Error(ctx.Tr("admin.emojis.already_exists", alias))
+		default:
+			ctx.ServerError("UploadOrgCustomEmoji", err)
+			return
+		}
+		ctx.Redirect(ctx.Org.OrgLink + "/settings/emojis")
+		return
+	}
+	log.Trace("Custom emoji %s uploaded for organization %s by %s", alias, ctx.Org.Organization.Name, ctx.Doer.Name)
+
+	ctx.Flash.Success(ctx.Tr("admin.emojis.upload_success", alias))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/emojis")
+}
+
+// DeleteEmoji deletes a custom emoji of the organization
+func DeleteEmoji(ctx *context.Context) {
+	name := ctx.FormString("id")
+	if err := emoji_service.DeleteOrgCustomEmoji(ctx, ctx.Org.Organization.AsUser(), name); err != nil && !issues_model.IsErrCustomEmojiNotExist(err) {
+		ctx.Flash.Error("DeleteOrgCustomEmoji: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.settings.emojis.deletion_success", emoji.OrgAlias(ctx.Org.Organization.Name, name)))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/emojis",
+	})
+}
@@ -0,0 +1,200 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	// tplSettingsIssueIntake template path for render issue intake settings
+	tplSettingsIssueIntake base.TplName = "org/settings/issue_intake"
+	// tplSettingsIssueIntakeRule template path for render the edit page of issue intake rules
+	tplSettingsIssueIntakeRule base.TplName = "org/settings/issue_intake_rule"
+)
+
+// IssueIntakeRules render the issue intake rules of an organization
+func IssueIntakeRules(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsIssueIntake"] = true
+
+	rules, err := models.GetIssueIntakeRules(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetIssueIntakeRules", err)
+		return
+	}
+	ctx.Data["Rules"] = rules
+
+	teams, err := organization.FindOrgTeams(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("FindOrgTeams", err)
+		return
+	}
+	teamNames := make(map[int64]string, len(teams))
+	for _, team := range teams {
+		teamNames[team.ID] = team.Name
+	}
+	ctx.Data["TeamNames"] = teamNames
+
+	ctx.HTML(http.StatusOK, tplSettingsIssueIntake)
+}
+
+// NewIssueIntakeRule render the page to create issue intake rules
+func NewIssueIntakeRule(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsIssueIntake"] = true
+	ctx.Data["Rule"] = &models.IssueIntakeRule{}
+
+	prepareIssueIntakeRuleTeams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplSettingsIssueIntakeRule)
+}
+
+// NewIssueIntakeRulePost response for creating issue intake rules
+func NewIssueIntakeRulePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.OrgIssueIntakeRuleForm)
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsIssueIntake"] = true
+
+	rule := &models.IssueIntakeRule{OrgID: ctx.Org.Organization.ID}
+	saveIssueIntakeRule(ctx, form, rule)
+}
+
+// EditIssueIntakeRule render the page to edit issue intake rules
+func EditIssueIntakeRule(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsIssueIntake"] = true
+
+	rule := getIssueIntakeRuleByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Rule"] = rule
+
+	prepareIssueIntakeRuleTeams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplSettingsIssueIntakeRule)
+}
+
+// EditIssueIntakeRulePost response for editing issue intake rules
+func EditIssueIntakeRulePost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.OrgIssueIntakeRuleForm)
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsIssueIntake"] = true
+
+	rule := getIssueIntakeRuleByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	saveIssueIntakeRule(ctx, form, rule)
+}
+
+// DeleteIssueIntakeRule response for deleting issue intake rules
+func DeleteIssueIntakeRule(ctx *context.Context) {
+	if err := models.DeleteIssueIntakeRule(ctx, ctx.Org.Organization.ID, ctx.FormInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteIssueIntakeRule: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.settings.issue_intake.deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/issue_intake",
+	})
+}
+
+func getIssueIntakeRuleByParams(ctx *context.Context) *models.IssueIntakeRule {
+	rule, err := models.GetIssueIntakeRuleByID(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrIssueIntakeRuleNotExist(err) {
+			ctx.NotFound("GetIssueIntakeRuleByID", err)
+		} else {
+			ctx.ServerError("GetIssueIntakeRuleByID", err)
+		}
+		return nil
+	}
+	return rule
+}
+
+func prepareIssueIntakeRuleTeams(ctx *context.Context) {
+	teams, err := organization.FindOrgTeams(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("FindOrgTeams", err)
+		return
+	}
+	ctx.Data["Teams"] = teams
+}
+
+func saveIssueIntakeRule(ctx *context.Context, form *forms.OrgIssueIntakeRuleForm, rule *models.IssueIntakeRule) {
+	rule.Name = form.Name
+	rule.RepoPattern = form.RepoPattern
+	rule.TemplateFile = form.TemplateFile
+	rule.Labels = nil
+	for _, label := range strings.Split(form.Labels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			rule.Labels = append(rule.Labels, label)
+		}
+	}
+	rule.ProjectTitle = form.ProjectTitle
+	rule.BoardTitle = form.BoardTitle
+	rule.TeamID = form.TeamID
+	ctx.Data["Rule"] = rule
+
+	prepareIssueIntakeRuleTeams(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplSettingsIssueIntakeRule)
+		return
+	}
+	if rule.TeamID > 0 {
+		team, err := organization.GetTeamByID(ctx, rule.TeamID)
+		if err != nil && !organization.IsErrTeamNotExist(err) {
+			ctx.ServerError("GetTeamByID", err)
+			return
+		}
+		if team == nil || team.OrgID != rule.OrgID {
+			ctx.Data["Err_TeamID"] = true
+			ctx.RenderWithErr(ctx.Tr("org.settings.issue_intake.invalid_team"), tplSettingsIssueIntakeRule, form)
+			return
+		}
+	}
+
+	var err error
+	if rule.ID == 0 {
+		err = models.CreateIssueIntakeRule(ctx, rule)
+	} else {
+		err = models.UpdateIssueIntakeRule(ctx, rule)
+	}
+	if err != nil {
+		if models.IsErrInvalidIssueIntakeRule(err) {
+			ctx.RenderWithErr(ctx.Tr("org.settings.issue_intake.invalid", err.(models.ErrInvalidIssueIntakeRule).Reason), tplSettingsIssueIntakeRule, form)
+		} else {
+			ctx.ServerError("SaveIssueIntakeRule", err)
+		}
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.issue_intake.update_success", rule.Name))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/issue_intake")
+}
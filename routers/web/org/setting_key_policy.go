@@ -0,0 +1,87 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	"code.gitea.io/gitea/services/forms"
+)
+
+// tplSettingsKeyPolicy template path for render the key policy of an organization
+const tplSettingsKeyPolicy base.TplName = "org/settings/key_policy"
+
+func prepareKeyPolicy(ctx *context.Context, policy *organization.OrgKeyPolicy) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsKeyPolicy"] = true
+	ctx.Data["KeyPolicy"] = policy
+
+	if !policy.IsEnabled() {
+		return
+	}
+	compliances, err := asymkey_service.GetKeyPolicyCompliance(ctx, ctx.Org.Organization, policy)
+	if err != nil {
+		ctx.ServerError("GetKeyPolicyCompliance", err)
+		return
+	}
+	nonCompliant := 0
+	for _, compliance := range compliances {
+		if !compliance.IsCompliant() {
+			nonCompliant++
+		}
+	}
+	ctx.Data["Compliances"] = compliances
+	ctx.Data["NonCompliantCount"] = nonCompliant
+}
+
+// SettingsKeyPolicy render the key policy of an organization and how its members comply with it
+func SettingsKeyPolicy(ctx *context.Context) {
+	policy, err := organization.GetKeyPolicyByOrgID(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetKeyPolicyByOrgID", err)
+		return
+	}
+	prepareKeyPolicy(ctx, policy)
+	if ctx.Written() {
+		return
+	}
+
+	ctx.HTML(http.StatusOK, tplSettingsKeyPolicy)
+}
+
+// SettingsKeyPolicyPost response for changing the key policy of an organization
+func SettingsKeyPolicyPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.OrgKeyPolicyForm)
+	policy := &organization.OrgKeyPolicy{
+		OrgID:             ctx.Org.Organization.ID,
+		SSHKeyMaxAgeYears: form.SSHKeyMaxAgeYears,
+		MinRSAKeySize:     form.MinRSAKeySize,
+		ForbidDSAKeys:     form.ForbidDSAKeys,
+		RequireGPGKey:     form.RequireGPGKey,
+	}
+
+	if ctx.HasError() {
+		prepareKeyPolicy(ctx, policy)
+		if ctx.Written() {
+			return
+		}
+		ctx.HTML(http.StatusOK, tplSettingsKeyPolicy)
+		return
+	}
+
+	if err := organization.SetKeyPolicy(ctx, policy); err != nil {
+		ctx.ServerError("SetKeyPolicy", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.key_policy.update_success"))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/key_policy")
+}
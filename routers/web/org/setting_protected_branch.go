@@ -0,0 +1,165 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	// tplSettingsProtectedBranches template path for render branch protection settings
+	tplSettingsProtectedBranches base.TplName = "org/settings/branches"
+	// tplSettingsProtectedBranch template path for render the edit page of branch protection rules
+	tplSettingsProtectedBranch base.TplName = "org/settings/protected_branch"
+)
+
+// ProtectedBranches render the branch protection rules of an organization
+func ProtectedBranches(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsBranches"] = true
+
+	rules, err := models.GetOrgProtectedBranches(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetOrgProtectedBranches", err)
+		return
+	}
+	ctx.Data["Rules"] = rules
+
+	ctx.HTML(http.StatusOK, tplSettingsProtectedBranches)
+}
+
+// NewProtectedBranch render the page to create branch protection rules
+func NewProtectedBranch(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsBranches"] = true
+	ctx.Data["Rule"] = &models.OrgProtectedBranch{ApplyToExisting: true}
+
+	ctx.HTML(http.StatusOK, tplSettingsProtectedBranch)
+}
+
+// NewProtectedBranchPost response for creating branch protection rules
+func NewProtectedBranchPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.OrgProtectBranchForm)
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsBranches"] = true
+
+	rule := &models.OrgProtectedBranch{OrgID: ctx.Org.Organization.ID}
+	saveProtectedBranch(ctx, form, rule)
+}
+
+// EditProtectedBranch render the page to edit branch protection rules
+func EditProtectedBranch(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsBranches"] = true
+
+	rule := getProtectedBranchByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Rule"] = rule
+
+	ctx.HTML(http.StatusOK, tplSettingsProtectedBranch)
+}
+
+// EditProtectedBranchPost response for editing branch protection rules
+func EditProtectedBranchPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.OrgProtectBranchForm)
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsBranches"] = true
+
+	rule := getProtectedBranchByParams(ctx)
+	if ctx.Written() {
+		return
+	}
+	saveProtectedBranch(ctx, form, rule)
+}
+
+// DeleteProtectedBranch response for deleting branch protection rules
+func DeleteProtectedBranch(ctx *context.Context) {
+	if err := models.DeleteOrgProtectedBranch(ctx, ctx.Org.Organization.ID, ctx.FormInt64("id")); err != nil {
+		ctx.Flash.Error("DeleteOrgProtectedBranch: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("org.settings.branches.deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Org.OrgLink + "/settings/branches",
+	})
+}
+
+func getProtectedBranchByParams(ctx *context.Context) *models.OrgProtectedBranch {
+	rule, err := models.GetOrgProtectedBranchByID(ctx, ctx.Org.Organization.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrOrgProtectedBranchNotExist(err) {
+			ctx.NotFound("GetOrgProtectedBranchByID", err)
+		} else {
+			ctx.ServerError("GetOrgProtectedBranchByID", err)
+		}
+		return nil
+	}
+	return rule
+}
+
+func saveProtectedBranch(ctx *context.Context, form *forms.OrgProtectBranchForm, rule *models.OrgProtectedBranch) {
+	rule.BranchPattern = form.BranchPattern
+	rule.ApplyToExisting = form.ApplyToExisting
+	rule.AllowOverride = form.AllowOverride
+	rule.CanPush = form.EnablePush
+	rule.EnableStatusCheck = form.EnableStatusCheck
+	rule.StatusCheckContexts = nil
+	if form.EnableStatusCheck {
+		for _, c := range strings.Split(form.StatusCheckContexts, ",") {
+			if c = strings.TrimSpace(c); c != "" {
+				rule.StatusCheckContexts = append(rule.StatusCheckContexts, c)
+			}
+		}
+	}
+	rule.RequiredApprovals = form.RequiredApprovals
+	rule.BlockOnRejectedReviews = form.BlockOnRejectedReviews
+	rule.DismissStaleApprovals = form.DismissStaleApprovals
+	rule.RequireSignedCommits = form.RequireSignedCommits
+	ctx.Data["Rule"] = rule
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplSettingsProtectedBranch)
+		return
+	}
+	if rule.RequiredApprovals < 0 {
+		ctx.Data["Err_RequiredApprovals"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.protected_branch_required_approvals_min"), tplSettingsProtectedBranch, form)
+		return
+	}
+
+	var err error
+	if rule.ID == 0 {
+		err = models.CreateOrgProtectedBranch(ctx, rule)
+	} else {
+		err = models.UpdateOrgProtectedBranch(ctx, rule)
+	}
+	if err != nil {
+		if models.IsErrInvalidBranchPattern(err) {
+			ctx.Data["Err_BranchPattern"] = true
+			ctx.RenderWithErr(ctx.Tr("org.settings.branches.invalid_pattern"), tplSettingsProtectedBranch, form)
+		} else {
+			ctx.ServerError("SaveOrgProtectedBranch", err)
+		}
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("org.settings.branches.update_success", rule.BranchPattern))
+	ctx.Redirect(ctx.Org.OrgLink + "/settings/branches")
+}
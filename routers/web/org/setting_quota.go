@@ -0,0 +1,32 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	quota_service "code.gitea.io/gitea/services/quota"
+)
+
+// tplSettingsQuota template path for render the disk space used by an organization
+const tplSettingsQuota base.TplName = "org/settings/quota"
+
+// SettingsQuota render the disk space used by the organization and its quota
+func SettingsQuota(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("org.settings")
+	ctx.Data["PageIsOrgSettings"] = true
+	ctx.Data["PageIsSettingsQuota"] = true
+
+	status, err := quota_service.GetStatus(ctx, ctx.Org.Organization.ID)
+	if err != nil {
+		ctx.ServerError("GetStatus", err)
+		return
+	}
+	ctx.Data["Quota"] = status
+
+	ctx.HTML(http.StatusOK, tplSettingsQuota)
+}
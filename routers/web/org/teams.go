@@ -13,6 +13,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
@@ -25,6 +26,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
+	audit_service "code.gitea.io/gitea/services/audit"
 	"code.gitea.io/gitea/services/forms"
 )
 
@@ -56,6 +58,15 @@ func Teams(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplTeams)
 }
 
+// auditTeamMember records a change of the members of the current team in the audit log
+func auditTeamMember(ctx *context.Context, action admin_model.AuditAction, uid int64) {
+	name := strconv.FormatInt(uid, 10)
+	if u, err := user_model.GetUserByID(uid); err == nil {
+		name = u.Name
+	}
+	audit_service.Record(ctx, ctx.Doer, ctx.RemoteAddr(), action, ctx.Org.Organization.Name+"/"+ctx.Org.Team.Name, name)
+}
+
 // TeamsAction response for join, leave, remove, add operations to team
 func TeamsAction(ctx *context.Context) {
 	uid := ctx.FormInt64("uid")
@@ -73,9 +84,14 @@ func TeamsAction(ctx *context.Context) {
 			return
 		}
 		err = models.AddTeamMember(ctx.Org.Team, ctx.Doer.ID)
+		if err == nil {
+			auditTeamMember(ctx, admin_model.AuditActionTeamMemberAdd, ctx.Doer.ID)
+		}
 	case "leave":
 		err = models.RemoveTeamMember(ctx.Org.Team, ctx.Doer.ID)
-		if err != nil {
+		if err == nil {
+			auditTeamMember(ctx, admin_model.AuditActionTeamMemberRemove, ctx.Doer.ID)
+		} else {
 			if organization.IsErrLastOrgOwner(err) {
 				ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
 			} else {
@@ -98,7 +114,9 @@ func TeamsAction(ctx *context.Context) {
 			return
 		}
 		err = models.RemoveTeamMember(ctx.Org.Team, uid)
-		if err != nil {
+		if err == nil {
+			auditTeamMember(ctx, admin_model.AuditActionTeamMemberRemove, uid)
+		} else {
 			if organization.IsErrLastOrgOwner(err) {
 				ctx.Flash.Error(ctx.Tr("form.last_org_owner"))
 			} else {
@@ -143,6 +161,9 @@ func TeamsAction(ctx *context.Context) {
 			ctx.Flash.Error(ctx.Tr("org.teams.add_duplicate_users"))
 		} else {
 			err = models.AddTeamMember(ctx.Org.Team, u.ID)
+			if err == nil {
+				auditTeamMember(ctx, admin_model.AuditActionTeamMemberAdd, u.ID)
+			}
 		}
 
 		page = "team"
@@ -242,6 +263,21 @@ func getUnitPerms(forms url.Values) map[unit_model.Type]perm.AccessMode {
 	return unitPerms
 }
 
+// getRestrictedReleaseActions returns the release actions which are not checked in the form
+func getRestrictedReleaseActions(form *forms.CreateTeamForm) perm.ReleaseAction {
+	restricted := perm.ReleaseActionNone
+	if !form.CanCreateReleases {
+		restricted |= perm.ReleaseActionCreate
+	}
+	if !form.CanEditReleases {
+		restricted |= perm.ReleaseActionEdit
+	}
+	if !form.CanUploadReleaseAssets {
+		restricted |= perm.ReleaseActionUploadAssets
+	}
+	return restricted
+}
+
 // NewTeamPost response for create new team
 func NewTeamPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.CreateTeamForm)
@@ -255,12 +291,13 @@ func NewTeamPost(ctx *context.Context) {
 	}
 
 	t := &organization.Team{
-		OrgID:                   ctx.Org.Organization.ID,
-		Name:                    form.TeamName,
-		Description:             form.Description,
-		AccessMode:              p,
-		IncludesAllRepositories: includesAllRepositories,
-		CanCreateOrgRepo:        form.CanCreateOrgRepo,
+		OrgID:                    ctx.Org.Organization.ID,
+		Name:                     form.TeamName,
+		Description:              form.Description,
+		AccessMode:               p,
+		IncludesAllRepositories:  includesAllRepositories,
+		CanCreateOrgRepo:         form.CanCreateOrgRepo,
+		RestrictedReleaseActions: getRestrictedReleaseActions(form),
 	}
 
 	if t.AccessMode < perm.AccessModeAdmin {
@@ -416,6 +453,7 @@ func EditTeamPost(ctx *context.Context) {
 			isIncludeAllChanged = true
 			t.IncludesAllRepositories = includesAllRepositories
 		}
+		t.RestrictedReleaseActions = getRestrictedReleaseActions(form)
 	}
 	t.Description = form.Description
 	if t.AccessMode < perm.AccessModeAdmin {
@@ -0,0 +1,44 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package org
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/routers/web/repo"
+)
+
+const tplTimeReport base.TplName = "org/time_report"
+
+// TimeReport renders the tracked times of all repositories of an organization
+func TimeReport(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.issues.time_report")
+	ctx.Data["PageIsOrgTimeReport"] = true
+	ctx.Data["CanFilterUser"] = true
+	ctx.Data["TimeReportLink"] = ctx.Org.OrgLink + "/time_report"
+
+	opts := repo.GetTimeReportOptions(ctx, true)
+	if ctx.Written() {
+		return
+	}
+	opts.OwnerID = ctx.Org.Organization.ID
+
+	if repo.RenderTimeReport(ctx, opts); ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplTimeReport)
+}
+
+// TimeReportExport serves the tracked times of all repositories of an organization as CSV or JSON file
+func TimeReportExport(ctx *context.Context) {
+	opts := repo.GetTimeReportOptions(ctx, true)
+	if ctx.Written() {
+		return
+	}
+	opts.OwnerID = ctx.Org.Organization.ID
+	repo.ServeTimeReport(ctx, opts, ctx.Org.Organization.Name)
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+const tplAccessRequests base.TplName = "repo/settings/access_requests"
+
+// MustEnableAccessRequests check if access requests are enabled in settings
+func MustEnableAccessRequests(ctx *context.Context) {
+	if !setting.Repository.EnableAccessRequests {
+		ctx.NotFound("MustEnableAccessRequests", nil)
+		return
+	}
+}
+
+// RequestAccessPost requests access to the repository offered on its "not found" page.
+// The response is the same whether the repository exists or not, so private repositories are not disclosed.
+func RequestAccessPost(ctx *context.Context) {
+	owner, err := user_model.GetUserByName(ctx, ctx.FormString("owner"))
+	if err == nil {
+		repo, err := repo_model.GetRepositoryByName(owner.ID, ctx.FormString("repo"))
+		if err == nil {
+			repo.Owner = owner
+			if err := repo_service.RequestAccess(ctx, ctx.Doer, repo, base.TruncateString(ctx.FormTrim("message"), 1000)); err != nil {
+				ctx.ServerError("RequestAccess", err)
+				return
+			}
+		} else if !repo_model.IsErrRepoNotExist(err) {
+			ctx.ServerError("GetRepositoryByName", err)
+			return
+		}
+	} else if !user_model.IsErrUserNotExist(err) {
+		ctx.ServerError("GetUserByName", err)
+		return
+	}
+
+	ctx.Flash.Info(ctx.Tr("repo.access_request.sent"))
+	ctx.Redirect(setting.AppSubURL + "/")
+}
+
+// AccessRequests render the access requests of a repository
+func AccessRequests(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.access_requests")
+	ctx.Data["PageIsSettingsAccessRequests"] = true
+
+	reqs, err := repo_model.GetAccessRequests(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetAccessRequests", err)
+		return
+	}
+	ctx.Data["AccessRequests"] = reqs
+
+	ctx.HTML(http.StatusOK, tplAccessRequests)
+}
+
+// getAccessRequest returns the pending access request identified by the `id` parameter
+func getAccessRequest(ctx *context.Context) *repo_model.AccessRequest {
+	req, err := repo_model.GetAccessRequestByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if repo_model.IsErrAccessRequestNotExist(err) {
+			ctx.NotFound("GetAccessRequestByID", err)
+		} else {
+			ctx.ServerError("GetAccessRequestByID", err)
+		}
+		return nil
+	}
+	if !req.IsPending() {
+		ctx.Flash.Error(ctx.Tr("repo.settings.access_requests.already_decided"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/access_requests")
+		return nil
+	}
+	return req
+}
+
+// ApproveAccessRequestPost adds the requester of an access request as collaborator
+func ApproveAccessRequestPost(ctx *context.Context) {
+	req := getAccessRequest(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	mode := perm.AccessMode(ctx.FormInt("mode"))
+	if mode < perm.AccessModeRead || mode > perm.AccessModeAdmin {
+		mode = perm.AccessModeRead
+	}
+	if err := repo_service.ApproveAccessRequest(ctx, ctx.Doer, ctx.Repo.Repository, req, mode); err != nil {
+		ctx.ServerError("ApproveAccessRequest", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.access_requests.approve_success", req.User.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/access_requests")
+}
+
+// DenyAccessRequestPost denies an access request
+func DenyAccessRequestPost(ctx *context.Context) {
+	req := getAccessRequest(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := repo_service.DenyAccessRequest(ctx, ctx.Doer, ctx.Repo.Repository, req); err != nil {
+		ctx.ServerError("DenyAccessRequest", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.access_requests.deny_success", req.User.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/access_requests")
+}
@@ -0,0 +1,229 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+const (
+	tplActions   base.TplName = "repo/actions/list"
+	tplActionRun base.TplName = "repo/actions/run"
+	tplActionJob base.TplName = "repo/actions/job"
+)
+
+// MustEnableActions check if actions are enabled in settings
+func MustEnableActions(ctx *context.Context) {
+	if !setting.Actions.Enabled {
+		ctx.NotFound("MustEnableActions", nil)
+		return
+	}
+}
+
+// Actions render the workflow runs of a repository
+func Actions(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.actions")
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	workflow := ctx.FormString("workflow")
+
+	runs, total, err := actions_model.FindRuns(ctx, actions_model.FindRunOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.IssuePagingNum,
+		},
+		RepoID:     ctx.Repo.Repository.ID,
+		WorkflowID: workflow,
+	})
+	if err != nil {
+		ctx.ServerError("FindRuns", err)
+		return
+	}
+	for _, run := range runs {
+		run.Repo = ctx.Repo.Repository
+		if err := run.LoadAttributes(ctx); err != nil {
+			ctx.ServerError("LoadAttributes", err)
+			return
+		}
+	}
+	ctx.Data["Runs"] = runs
+	ctx.Data["Workflow"] = workflow
+
+	pager := context.NewPagination(int(total), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "workflow", "Workflow")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplActions)
+}
+
+// getActionRun returns the run of the repository identified by the `index` parameter
+func getActionRun(ctx *context.Context) *actions_model.ActionRun {
+	run, err := actions_model.GetRunByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if actions_model.IsErrRunNotExist(err) {
+			ctx.NotFound("GetRunByIndex", err)
+		} else {
+			ctx.ServerError("GetRunByIndex", err)
+		}
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	if err := run.LoadAttributes(ctx); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return nil
+	}
+	ctx.Data["Run"] = run
+	ctx.Data["CanCancel"] = !run.Status.IsDone() && ctx.Repo.CanWrite(unit.TypeCode)
+	return run
+}
+
+// getActionRunJob returns the job of the run identified by the `id` parameter
+func getActionRunJob(ctx *context.Context, run *actions_model.ActionRun) *actions_model.ActionRunJob {
+	job, err := actions_model.GetJobByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if actions_model.IsErrJobNotExist(err) {
+			ctx.NotFound("GetJobByID", err)
+		} else {
+			ctx.ServerError("GetJobByID", err)
+		}
+		return nil
+	}
+	if job.RunID != run.ID {
+		ctx.NotFound("GetJobByID", nil)
+		return nil
+	}
+	job.Run = run
+	return job
+}
+
+// ActionRunView render a workflow run with its jobs and artifacts
+func ActionRunView(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = run.Title
+
+	jobs, err := actions_model.GetRunJobs(ctx, run.ID)
+	if err != nil {
+		ctx.ServerError("GetRunJobs", err)
+		return
+	}
+	ctx.Data["Jobs"] = jobs
+
+	artifacts, err := actions_model.GetRunArtifacts(ctx, run.ID)
+	if err != nil {
+		ctx.ServerError("GetRunArtifacts", err)
+		return
+	}
+	ctx.Data["Artifacts"] = artifacts
+
+	ctx.HTML(http.StatusOK, tplActionRun)
+}
+
+// ActionJobView render the log of a job
+func ActionJobView(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	job := getActionRunJob(ctx, run)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = job.Name + " - " + run.Title
+	ctx.Data["Job"] = job
+
+	lines, err := actions_model.ReadJobLogs(ctx, job.ID, 0)
+	if err != nil {
+		ctx.ServerError("ReadJobLogs", err)
+		return
+	}
+	ctx.Data["LogLines"] = lines
+	ctx.Data["LogOffset"] = len(lines)
+
+	ctx.HTML(http.StatusOK, tplActionJob)
+}
+
+// ActionJobLogs returns the log lines of a job after the `offset` line as JSON, it is polled while the job is running
+func ActionJobLogs(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	job := getActionRunJob(ctx, run)
+	if ctx.Written() {
+		return
+	}
+
+	offset := ctx.FormInt64("offset")
+	lines, err := actions_model.ReadJobLogs(ctx, job.ID, offset)
+	if err != nil {
+		ctx.ServerError("ReadJobLogs", err)
+		return
+	}
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"lines":  lines,
+		"offset": offset + int64(len(lines)),
+		"status": job.Status.String(),
+		"done":   job.Status.IsDone(),
+	})
+}
+
+// CancelActionRunPost cancels the unfinished jobs of a workflow run
+func CancelActionRunPost(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	if err := actions_service.CancelRun(ctx, run); err != nil {
+		ctx.ServerError("CancelRun", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.actions.run.cancel_success"))
+	ctx.Redirect(run.Link())
+}
+
+// ActionArtifactDownload serves an artifact of a workflow run
+func ActionArtifactDownload(ctx *context.Context) {
+	run := getActionRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	artifact, err := actions_model.GetArtifactByID(ctx, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if actions_model.IsErrArtifactNotExist(err) {
+			ctx.NotFound("GetArtifactByID", err)
+		} else {
+			ctx.ServerError("GetArtifactByID", err)
+		}
+		return
+	}
+	if artifact.RunID != run.ID {
+		ctx.NotFound("GetArtifactByID", nil)
+		return
+	}
+
+	fr, err := storage.Actions.Open(artifact.StoragePath)
+	if err != nil {
+		ctx.ServerError("Open", err)
+		return
+	}
+	defer fr.Close()
+
+	ctx.ServeContent(artifact.Name, fr, artifact.Created.AsTime())
+}
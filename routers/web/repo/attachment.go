@@ -9,13 +9,13 @@ import (
 	"net/http"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/perm"
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/httpcache"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
-	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/upload"
 	"code.gitea.io/gitea/routers/common"
 	"code.gitea.io/gitea/services/attachment"
@@ -51,11 +51,19 @@ func uploadAttachment(ctx *context.Context, repoID int64, allowedTypes string) {
 			ctx.Error(http.StatusBadRequest, err.Error())
 			return
 		}
+		if attachment.IsErrRepoAttachmentsTooLarge(err) {
+			ctx.Error(http.StatusRequestEntityTooLarge, ctx.Tr("repo.issues.attachment.repo_size_exceeded"))
+			return
+		}
 		ctx.Error(http.StatusInternalServerError, fmt.Sprintf("NewAttachment: %v", err))
 		return
 	}
 
 	log.Trace("New attachment uploaded: %s", attach.UUID)
+	if ctx.FormBool("optimize") {
+		// images pasted into comments are optimized in the background and replaced once done
+		attachment.ScheduleImageOptimization(attach)
+	}
 	ctx.JSON(http.StatusOK, map[string]string{
 		"uuid": attach.UUID,
 	})
@@ -73,6 +81,10 @@ func DeleteAttachment(ctx *context.Context) {
 		ctx.Error(http.StatusForbidden)
 		return
 	}
+	if attach.ReleaseID != 0 && !ctx.Repo.CanDoReleaseAction(perm.ReleaseActionEdit) {
+		ctx.Error(http.StatusForbidden)
+		return
+	}
 	err = repo_model.DeleteAttachment(attach, true)
 	if err != nil {
 		ctx.Error(http.StatusInternalServerError, fmt.Sprintf("DeleteAttachment: %v", err))
@@ -123,9 +135,14 @@ func GetAttachment(ctx *context.Context) {
 		return
 	}
 
+	if attach.IsExternal() {
+		ctx.Redirect(attach.ExternalURL)
+		return
+	}
+
 	if setting.Attachment.ServeDirect {
 		// If we have a signed url (S3, object storage), redirect to this directly.
-		u, err := storage.Attachments.URL(attach.RelativePath(), attach.Name)
+		u, err := attach.Storage().URL(attach.RelativePath(), attach.Name)
 
 		if u != nil && err == nil {
 			ctx.Redirect(u.String())
@@ -138,7 +155,7 @@ func GetAttachment(ctx *context.Context) {
 	}
 
 	// If we have matched and access to release or issue
-	fr, err := storage.Attachments.Open(attach.RelativePath())
+	fr, err := attach.Storage().Open(attach.RelativePath())
 	if err != nil {
 		ctx.ServerError("Open", err)
 		return
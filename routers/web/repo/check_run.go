@@ -0,0 +1,92 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+const tplCheckRun base.TplName = "repo/checks/view"
+
+// getCheckRun returns the check run of the repository identified by the `id` parameter
+func getCheckRun(ctx *context.Context) *models.CheckRun {
+	run, err := models.GetCheckRunByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if models.IsErrCheckRunNotExist(err) {
+			ctx.NotFound("GetCheckRunByID", err)
+		} else {
+			ctx.ServerError("GetCheckRunByID", err)
+		}
+		return nil
+	}
+	run.Repo = ctx.Repo.Repository
+	if err := run.LoadAttributes(ctx); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return nil
+	}
+	return run
+}
+
+// CheckRunView render the output and the annotations of a check run
+func CheckRunView(ctx *context.Context) {
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = run.Name
+	ctx.Data["CheckRun"] = run
+	ctx.Data["CanRerequest"] = ctx.Repo.CanWrite(unit.TypeCode)
+
+	renderCtx := &markup.RenderContext{
+		URLPrefix: ctx.Repo.RepoLink,
+		Metas:     ctx.Repo.Repository.ComposeMetas(),
+		Ctx:       ctx,
+	}
+	for key, content := range map[string]string{"RenderedSummary": run.Summary, "RenderedText": run.Text} {
+		if content == "" {
+			continue
+		}
+		rendered, err := markdown.RenderString(renderCtx, content)
+		if err != nil {
+			ctx.ServerError("RenderString", err)
+			return
+		}
+		ctx.Data[key] = rendered
+	}
+
+	annotations, err := models.GetCheckRunAnnotations(ctx, run.ID)
+	if err != nil {
+		ctx.ServerError("GetCheckRunAnnotations", err)
+		return
+	}
+	ctx.Data["Annotations"] = annotations
+
+	ctx.HTML(http.StatusOK, tplCheckRun)
+}
+
+// RerequestCheckRunPost queues a check run for another attempt
+func RerequestCheckRunPost(ctx *context.Context) {
+	run := getCheckRun(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := files_service.RerequestCheckRun(ctx, ctx.Repo.Repository, ctx.Doer, run); err != nil {
+		ctx.ServerError("RerequestCheckRun", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.checks.rerequested", run.Name))
+	ctx.Redirect(fmt.Sprintf("%s/checks/%d", ctx.Repo.RepoLink, run.ID))
+}
@@ -355,6 +355,7 @@ func Diff(ctx *context.Context) {
 		ctx.ServerError("CalculateTrustStatus", err)
 		return
 	}
+	ctx.Data["TrustModel"] = ctx.Repo.Repository.GetTrustModel().String()
 
 	note := &git.Note{}
 	err = git.GetNote(ctx, ctx.Repo.GitRepo, commitID, note)
@@ -0,0 +1,503 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"fmt"
+	"net/http"
+
+	"code.gitea.io/gitea/models/db"
+	discussion_model "code.gitea.io/gitea/models/discussion"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	discussion_indexer "code.gitea.io/gitea/modules/indexer/discussions"
+	"code.gitea.io/gitea/modules/markup"
+	"code.gitea.io/gitea/modules/markup/markdown"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/web"
+	discussion_service "code.gitea.io/gitea/services/discussion"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplDiscussions          base.TplName = "repo/discussion/list"
+	tplDiscussionNew        base.TplName = "repo/discussion/new"
+	tplDiscussionView       base.TplName = "repo/discussion/view"
+	tplDiscussionCategories base.TplName = "repo/discussion/categories"
+)
+
+// MustEnableDiscussions checks if the discussions unit is enabled globally
+func MustEnableDiscussions(ctx *context.Context) {
+	if unit.TypeDiscussions.UnitGlobalDisabled() {
+		ctx.NotFound("MustEnableDiscussions", nil)
+		return
+	}
+	ctx.Data["PageIsDiscussions"] = true
+	ctx.Data["CanWriteDiscussions"] = ctx.Repo.CanWrite(unit.TypeDiscussions)
+}
+
+// canModifyDiscussion returns true if the doer is the poster, or can moderate the discussions of the repository
+func canModifyDiscussion(ctx *context.Context, posterID int64) bool {
+	return ctx.Doer != nil && (ctx.Doer.ID == posterID || ctx.Repo.CanWrite(unit.TypeDiscussions))
+}
+
+func renderDiscussionMarkdown(ctx *context.Context, content string) (string, error) {
+	return markdown.RenderString(&markup.RenderContext{
+		URLPrefix: ctx.Repo.RepoLink,
+		Metas:     ctx.Repo.Repository.ComposeMetas(),
+		GitRepo:   ctx.Repo.GitRepo,
+		Ctx:       ctx,
+	}, content)
+}
+
+// Discussions renders the discussions of a repository, optionally of a category or matching a keyword
+func Discussions(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.discussions")
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	categoryID := ctx.FormInt64("category")
+	keyword := ctx.FormTrim("q")
+
+	categories, err := discussion_model.GetCategories(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetCategories", err)
+		return
+	}
+	categoriesByID := make(map[int64]*discussion_model.Category, len(categories))
+	for _, c := range categories {
+		categoriesByID[c.ID] = c
+	}
+
+	opts := &discussion_model.FindDiscussionOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.IssuePagingNum,
+		},
+		RepoID:     ctx.Repo.Repository.ID,
+		CategoryID: categoryID,
+	}
+	if keyword != "" {
+		_, ids, err := discussion_indexer.SearchDiscussionsByKeyword(ctx, []int64{ctx.Repo.Repository.ID}, keyword, 50, 0)
+		if err != nil {
+			ctx.ServerError("SearchDiscussionsByKeyword", err)
+			return
+		}
+		opts.IDs = ids
+	}
+	discussions, total, err := discussion_model.FindDiscussions(ctx, opts)
+	if err != nil {
+		ctx.ServerError("FindDiscussions", err)
+		return
+	}
+	for _, d := range discussions {
+		d.Repo = ctx.Repo.Repository
+		d.Category = categoriesByID[d.CategoryID]
+		if err := d.LoadPoster(ctx); err != nil {
+			ctx.ServerError("LoadPoster", err)
+			return
+		}
+	}
+
+	ctx.Data["Categories"] = categories
+	ctx.Data["CategoryID"] = categoryID
+	ctx.Data["Keyword"] = keyword
+	ctx.Data["Discussions"] = discussions
+
+	pager := context.NewPagination(int(total), setting.UI.IssuePagingNum, page, 5)
+	pager.AddParam(ctx, "category", "CategoryID")
+	pager.AddParam(ctx, "q", "Keyword")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplDiscussions)
+}
+
+func renderNewDiscussion(ctx *context.Context) bool {
+	categories, err := discussion_model.GetCategories(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetCategories", err)
+		return false
+	}
+	ctx.Data["Categories"] = categories
+	return true
+}
+
+// NewDiscussion renders the page to start a discussion
+func NewDiscussion(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.new")
+	if !renderNewDiscussion(ctx) {
+		return
+	}
+	ctx.Data["category_id"] = ctx.FormInt64("category")
+	ctx.HTML(http.StatusOK, tplDiscussionNew)
+}
+
+// NewDiscussionPost starts a discussion
+func NewDiscussionPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CreateDiscussionForm)
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.new")
+	if !renderNewDiscussion(ctx) {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplDiscussionNew)
+		return
+	}
+
+	d := &discussion_model.Discussion{
+		Title:      form.Title,
+		Content:    form.Content,
+		CategoryID: form.CategoryID,
+	}
+	if err := discussion_service.NewDiscussion(ctx, ctx.Doer, ctx.Repo.Repository, d); err != nil {
+		if discussion_model.IsErrCategoryNotExist(err) {
+			ctx.Data["Err_CategoryID"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.discussions.category_not_exist"), tplDiscussionNew, form)
+			return
+		}
+		ctx.ServerError("NewDiscussion", err)
+		return
+	}
+	ctx.Redirect(d.Link())
+}
+
+// getDiscussion returns the discussion of the repository identified by the `index` parameter
+func getDiscussion(ctx *context.Context) *discussion_model.Discussion {
+	d, err := discussion_model.GetDiscussionByIndex(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":index"))
+	if err != nil {
+		if discussion_model.IsErrDiscussionNotExist(err) {
+			ctx.NotFound("GetDiscussionByIndex", err)
+		} else {
+			ctx.ServerError("GetDiscussionByIndex", err)
+		}
+		return nil
+	}
+	d.Repo = ctx.Repo.Repository
+	if err := d.LoadAttributes(ctx); err != nil {
+		ctx.ServerError("LoadAttributes", err)
+		return nil
+	}
+	return d
+}
+
+// ViewDiscussion renders a discussion with its comments and replies
+func ViewDiscussion(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["Title"] = d.Title
+
+	var err error
+	if d.RenderedContent, err = renderDiscussionMarkdown(ctx, d.Content); err != nil {
+		ctx.ServerError("RenderString", err)
+		return
+	}
+	comments, err := discussion_model.GetComments(ctx, d.ID)
+	if err != nil {
+		ctx.ServerError("GetComments", err)
+		return
+	}
+	for _, c := range comments {
+		if c.RenderedContent, err = renderDiscussionMarkdown(ctx, c.Content); err != nil {
+			ctx.ServerError("RenderString", err)
+			return
+		}
+		for _, r := range c.Replies {
+			if r.RenderedContent, err = renderDiscussionMarkdown(ctx, r.Content); err != nil {
+				ctx.ServerError("RenderString", err)
+				return
+			}
+		}
+		if c.ID == d.AnswerID {
+			d.Answer = c
+		}
+	}
+
+	ctx.Data["Discussion"] = d
+	ctx.Data["Comments"] = comments
+	ctx.Data["CanModifyDiscussion"] = canModifyDiscussion(ctx, d.PosterID)
+	ctx.Data["CanMarkAnswer"] = d.Category.IsAnswerable && canModifyDiscussion(ctx, d.PosterID)
+	ctx.HTML(http.StatusOK, tplDiscussionView)
+}
+
+// EditDiscussion renders the page to edit a discussion
+func EditDiscussion(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canModifyDiscussion(ctx, d.PosterID) {
+		ctx.NotFound("canModifyDiscussion", nil)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.edit")
+	ctx.Data["PageIsEditDiscussion"] = true
+	ctx.Data["Discussion"] = d
+	if !renderNewDiscussion(ctx) {
+		return
+	}
+	ctx.Data["title"] = d.Title
+	ctx.Data["content"] = d.Content
+	ctx.Data["category_id"] = d.CategoryID
+	ctx.HTML(http.StatusOK, tplDiscussionNew)
+}
+
+// EditDiscussionPost changes the title, content and category of a discussion
+func EditDiscussionPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.CreateDiscussionForm)
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canModifyDiscussion(ctx, d.PosterID) {
+		ctx.NotFound("canModifyDiscussion", nil)
+		return
+	}
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.edit")
+	ctx.Data["PageIsEditDiscussion"] = true
+	ctx.Data["Discussion"] = d
+	if !renderNewDiscussion(ctx) {
+		return
+	}
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplDiscussionNew)
+		return
+	}
+
+	if err := discussion_service.UpdateDiscussion(ctx, ctx.Doer, d, form.Title, form.Content, form.CategoryID); err != nil {
+		if discussion_model.IsErrCategoryNotExist(err) {
+			ctx.Data["Err_CategoryID"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.discussions.category_not_exist"), tplDiscussionNew, form)
+			return
+		}
+		ctx.ServerError("UpdateDiscussion", err)
+		return
+	}
+	ctx.Redirect(d.Link())
+}
+
+// DeleteDiscussion deletes a discussion with its comments
+func DeleteDiscussion(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canModifyDiscussion(ctx, d.PosterID) {
+		ctx.NotFound("canModifyDiscussion", nil)
+		return
+	}
+	if err := discussion_service.DeleteDiscussion(ctx, ctx.Doer, d); err != nil {
+		ctx.ServerError("DeleteDiscussion", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.discussions.deletion_success"))
+	ctx.Redirect(ctx.Repo.RepoLink + "/discussions")
+}
+
+// NewDiscussionCommentPost adds a comment or a reply to a discussion
+func NewDiscussionCommentPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.DiscussionCommentForm)
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(d.Link())
+		return
+	}
+
+	c, err := discussion_service.CreateComment(ctx, ctx.Doer, d, form.ParentID, form.Content)
+	if err != nil {
+		if discussion_model.IsErrCommentNotExist(err) {
+			ctx.NotFound("CreateComment", err)
+			return
+		}
+		ctx.ServerError("CreateComment", err)
+		return
+	}
+	ctx.Redirect(fmt.Sprintf("%s#%s", d.Link(), c.HashTag()))
+}
+
+// getDiscussionComment returns the comment of the discussion identified by the `id` parameter,
+// the doer has to be allowed to modify it
+func getDiscussionComment(ctx *context.Context, d *discussion_model.Discussion) *discussion_model.Comment {
+	c, err := discussion_model.GetCommentByID(ctx, d.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if discussion_model.IsErrCommentNotExist(err) {
+			ctx.NotFound("GetCommentByID", err)
+		} else {
+			ctx.ServerError("GetCommentByID", err)
+		}
+		return nil
+	}
+	if !canModifyDiscussion(ctx, c.PosterID) {
+		ctx.NotFound("canModifyDiscussion", nil)
+		return nil
+	}
+	return c
+}
+
+// EditDiscussionCommentPost changes the content of a comment of a discussion
+func EditDiscussionCommentPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.DiscussionCommentForm)
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	c := getDiscussionComment(ctx, d)
+	if ctx.Written() {
+		return
+	}
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(d.Link())
+		return
+	}
+
+	if err := discussion_service.UpdateComment(ctx, ctx.Doer, d, c, form.Content); err != nil {
+		ctx.ServerError("UpdateComment", err)
+		return
+	}
+	ctx.Redirect(fmt.Sprintf("%s#%s", d.Link(), c.HashTag()))
+}
+
+// DeleteDiscussionComment deletes a comment of a discussion with its replies
+func DeleteDiscussionComment(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	c := getDiscussionComment(ctx, d)
+	if ctx.Written() {
+		return
+	}
+
+	if err := discussion_service.DeleteComment(ctx, ctx.Doer, d, c); err != nil {
+		ctx.ServerError("DeleteComment", err)
+		return
+	}
+	ctx.Redirect(d.Link())
+}
+
+// MarkDiscussionAnswer accepts the comment of the `comment_id` form value as the answer of a discussion,
+// or removes the answer if it is 0
+func MarkDiscussionAnswer(ctx *context.Context) {
+	d := getDiscussion(ctx)
+	if ctx.Written() {
+		return
+	}
+	if !canModifyDiscussion(ctx, d.PosterID) {
+		ctx.NotFound("canModifyDiscussion", nil)
+		return
+	}
+
+	commentID := ctx.FormInt64("comment_id")
+	if commentID == 0 {
+		if err := discussion_model.UnmarkAnswer(ctx, d); err != nil {
+			ctx.ServerError("UnmarkAnswer", err)
+			return
+		}
+		ctx.Redirect(d.Link())
+		return
+	}
+	if err := discussion_model.MarkAnswer(ctx, d, commentID); err != nil {
+		if discussion_model.IsErrCommentNotExist(err) || discussion_model.IsErrInvalidAnswer(err) {
+			ctx.Flash.Error(ctx.Tr("repo.discussions.answer.invalid"))
+			ctx.Redirect(d.Link())
+			return
+		}
+		ctx.ServerError("MarkAnswer", err)
+		return
+	}
+	ctx.Redirect(fmt.Sprintf("%s#discussioncomment-%d", d.Link(), commentID))
+}
+
+// DiscussionCategories renders the page to manage the discussion categories of a repository
+func DiscussionCategories(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.discussions.categories")
+	ctx.Data["PageIsDiscussionCategories"] = true
+
+	categories, err := discussion_model.GetCategories(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("GetCategories", err)
+		return
+	}
+	ctx.Data["Categories"] = categories
+	ctx.HTML(http.StatusOK, tplDiscussionCategories)
+}
+
+// NewDiscussionCategoryPost creates a discussion category
+func NewDiscussionCategoryPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.DiscussionCategoryForm)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(ctx.Repo.RepoLink + "/discussions/categories")
+		return
+	}
+
+	if err := discussion_model.NewCategory(ctx, &discussion_model.Category{
+		RepoID:       ctx.Repo.Repository.ID,
+		Name:         form.Name,
+		Description:  form.Description,
+		IsAnswerable: form.IsAnswerable,
+	}); err != nil {
+		ctx.ServerError("NewCategory", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.discussions.categories.create_success", form.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/discussions/categories")
+}
+
+// EditDiscussionCategoryPost changes a discussion category
+func EditDiscussionCategoryPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.DiscussionCategoryForm)
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.Data["ErrorMsg"].(string))
+		ctx.Redirect(ctx.Repo.RepoLink + "/discussions/categories")
+		return
+	}
+
+	c, err := discussion_model.GetCategoryByID(ctx, ctx.Repo.Repository.ID, ctx.ParamsInt64(":id"))
+	if err != nil {
+		if discussion_model.IsErrCategoryNotExist(err) {
+			ctx.NotFound("GetCategoryByID", err)
+		} else {
+			ctx.ServerError("GetCategoryByID", err)
+		}
+		return
+	}
+	c.Name = form.Name
+	c.Description = form.Description
+	c.IsAnswerable = form.IsAnswerable
+	if err := discussion_model.UpdateCategory(ctx, c); err != nil {
+		ctx.ServerError("UpdateCategory", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.discussions.categories.update_success", c.Name))
+	ctx.Redirect(ctx.Repo.RepoLink + "/discussions/categories")
+}
+
+// DeleteDiscussionCategory deletes a discussion category without discussions
+func DeleteDiscussionCategory(ctx *context.Context) {
+	if err := discussion_model.DeleteCategory(ctx, ctx.Repo.Repository.ID, ctx.FormInt64("id")); err != nil {
+		if discussion_model.IsErrCategoryNotEmpty(err) {
+			ctx.Flash.Error(ctx.Tr("repo.discussions.categories.deletion_not_empty"))
+		} else if !discussion_model.IsErrCategoryNotExist(err) {
+			ctx.ServerError("DeleteCategory", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.discussions.categories.deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/discussions/categories",
+	})
+}
@@ -10,6 +10,8 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	auth_model "code.gitea.io/gitea/models/auth"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
@@ -19,6 +21,7 @@ import (
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/routers/common"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // ServeBlobOrLFS download a git.Blob redirecting to LFS if necessary
@@ -119,12 +122,38 @@ func getBlobForEntry(ctx *context.Context) (blob *git.Blob, lastModified time.Ti
 	return
 }
 
+// recordRawTraffic counts a raw file hit of the repository
+// checkDownloadToken returns true if the request isn't authenticated by a download token or if the scopes of the token
+// allow the download, an empty tree path is an archive of the repository. Allowed downloads are counted for the token.
+func checkDownloadToken(ctx *context.Context, treePath string) bool {
+	t, ok := ctx.Data["DownloadToken"].(*auth_model.DownloadToken)
+	if !ok {
+		return true
+	}
+	if !t.Allows(ctx.Repo.Repository.FullName(), treePath) {
+		ctx.NotFound("DownloadToken", nil)
+		return false
+	}
+	if err := auth_model.IncreaseDownloadTokenUsage(ctx, t.ID); err != nil {
+		log.Error("IncreaseDownloadTokenUsage: %v", err)
+	}
+	return true
+}
+
+func recordRawTraffic(ctx *context.Context) {
+	repo_service.RecordTraffic(ctx, ctx.Repo.Repository.ID, repo_model.TrafficKindRaw, ctx.Doer, ctx.RemoteAddr())
+}
+
 // SingleDownload download a file by repos path
 func SingleDownload(ctx *context.Context) {
+	if !checkDownloadToken(ctx, ctx.Repo.TreePath) {
+		return
+	}
 	blob, lastModified := getBlobForEntry(ctx)
 	if blob == nil {
 		return
 	}
+	recordRawTraffic(ctx)
 
 	if err := common.ServeBlob(ctx, blob, lastModified); err != nil {
 		ctx.ServerError("ServeBlob", err)
@@ -133,10 +162,14 @@ func SingleDownload(ctx *context.Context) {
 
 // SingleDownloadOrLFS download a file by repos path redirecting to LFS if necessary
 func SingleDownloadOrLFS(ctx *context.Context) {
+	if !checkDownloadToken(ctx, ctx.Repo.TreePath) {
+		return
+	}
 	blob, lastModified := getBlobForEntry(ctx)
 	if blob == nil {
 		return
 	}
+	recordRawTraffic(ctx)
 
 	if err := ServeBlobOrLFS(ctx, blob, lastModified); err != nil {
 		ctx.ServerError("ServeBlobOrLFS", err)
@@ -145,6 +178,10 @@ func SingleDownloadOrLFS(ctx *context.Context) {
 
 // DownloadByID download a file by sha1 ID
 func DownloadByID(ctx *context.Context) {
+	// the path of a blob is unknown
+	if !checkDownloadToken(ctx, "") {
+		return
+	}
 	blob, err := ctx.Repo.GitRepo.GetBlob(ctx.Params("sha"))
 	if err != nil {
 		if git.IsErrNotExist(err) {
@@ -154,6 +191,7 @@ func DownloadByID(ctx *context.Context) {
 		}
 		return
 	}
+	recordRawTraffic(ctx)
 	if err = common.ServeBlob(ctx, blob, time.Time{}); err != nil {
 		ctx.ServerError("ServeBlob", err)
 	}
@@ -161,6 +199,10 @@ func DownloadByID(ctx *context.Context) {
 
 // DownloadByIDOrLFS download a file by sha1 ID taking account of LFS
 func DownloadByIDOrLFS(ctx *context.Context) {
+	// the path of a blob is unknown
+	if !checkDownloadToken(ctx, "") {
+		return
+	}
 	blob, err := ctx.Repo.GitRepo.GetBlob(ctx.Params("sha"))
 	if err != nil {
 		if git.IsErrNotExist(err) {
@@ -170,6 +212,7 @@ func DownloadByIDOrLFS(ctx *context.Context) {
 		}
 		return
 	}
+	recordRawTraffic(ctx)
 	if err = ServeBlobOrLFS(ctx, blob, time.Time{}); err != nil {
 		ctx.ServerError("ServeBlob", err)
 	}
@@ -278,6 +278,8 @@ func editFilePost(ctx *context.Context, form forms.EditRepoFileForm, isNewFile b
 		} else if models.IsErrRepoFileAlreadyExists(err) {
 			ctx.Data["Err_TreePath"] = true
 			ctx.RenderWithErr(ctx.Tr("repo.editor.file_already_exists", form.TreePath), tplEditFile, &form)
+		} else if models.IsErrFilePathLocked(err) || models.IsErrFilePathRequiresPullRequest(err) {
+			renderEditorFileRuleErr(ctx, err, tplEditFile, &form)
 		} else if git.IsErrBranchNotExist(err) {
 			// For when a user adds/updates a file to a branch that no longer exists
 			if branchErr, ok := err.(git.ErrBranchNotExist); ok {
@@ -334,6 +336,21 @@ func editFilePost(ctx *context.Context, form forms.EditRepoFileForm, isNewFile b
 	}
 }
 
+// renderEditorFileRuleErr renders an error of the editor file rules of the repository
+func renderEditorFileRuleErr(ctx *context.Context, err error, tpl base.TplName, form interface{}) {
+	switch ruleErr := err.(type) {
+	case models.ErrFilePathLocked:
+		ctx.Data["Err_TreePath"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.editor.file_path_locked", ruleErr.Path), tpl, form)
+	case models.ErrFilePathRequiresPullRequest:
+		ctx.Data["Err_NewBranchName"] = true
+		ctx.Data["commit_choice"] = frmCommitChoiceNewBranch
+		ctx.RenderWithErr(ctx.Tr("repo.editor.file_path_requires_pull_request", ruleErr.Path), tpl, form)
+	default:
+		ctx.ServerError("renderEditorFileRuleErr", err)
+	}
+}
+
 // EditFilePost response for editing file
 func EditFilePost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.EditRepoFileForm)
@@ -475,6 +492,8 @@ func DeleteFilePost(ctx *context.Context) {
 			} else {
 				ctx.ServerError("DeleteRepoFile", err)
 			}
+		} else if models.IsErrFilePathLocked(err) || models.IsErrFilePathRequiresPullRequest(err) {
+			renderEditorFileRuleErr(ctx, err, tplDeleteFile, &form)
 		} else if git.IsErrBranchNotExist(err) {
 			// For when a user deletes a file to a branch that no longer exists
 			if branchErr, ok := err.(git.ErrBranchNotExist); ok {
@@ -682,6 +701,8 @@ func UploadFilePost(ctx *context.Context) {
 		} else if models.IsErrRepoFileAlreadyExists(err) {
 			ctx.Data["Err_TreePath"] = true
 			ctx.RenderWithErr(ctx.Tr("repo.editor.file_already_exists", form.TreePath), tplUploadFile, &form)
+		} else if models.IsErrFilePathLocked(err) || models.IsErrFilePathRequiresPullRequest(err) {
+			renderEditorFileRuleErr(ctx, err, tplUploadFile, &form)
 		} else if git.IsErrBranchNotExist(err) {
 			branchErr := err.(git.ErrBranchNotExist)
 			ctx.RenderWithErr(ctx.Tr("repo.editor.branch_does_not_exist", branchErr.Name), tplUploadFile, &form)
@@ -10,6 +10,7 @@ import (
 	"compress/gzip"
 	gocontext "context"
 	"fmt"
+	"io"
 	"net/http"
 	"os"
 	"path"
@@ -24,13 +25,16 @@ import (
 	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/githttp"
 	"code.gitea.io/gitea/modules/log"
 	repo_module "code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/util"
+	auth_service "code.gitea.io/gitea/services/auth"
 	repo_service "code.gitea.io/gitea/services/repository"
 )
 
@@ -137,6 +141,12 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		return
 	}
 
+	// Don't allow pushing if the owner is suspended
+	if owner.IsSuspended && !isPull {
+		ctx.PlainText(http.StatusForbidden, "The owner of this repository has been suspended. You cannot push to it.")
+		return
+	}
+
 	// Only public pull don't need auth.
 	isPublicPull := repoExist && !repo.IsPrivate && isPull
 	var (
@@ -154,6 +164,14 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		askAuth = askAuth || (repo.Owner.Visibility != structs.VisibleTypePublic)
 	}
 
+	// deploy tokens grant read access to the code of their repository without signing in a user
+	if askAuth && !ctx.IsSigned && isPull && !isWiki && repoExist {
+		if token := auth_service.DeployTokenFromRequest(ctx.Req, ctx.Data); token != nil && token.RepoID == repo.ID &&
+			token.HasScope(repo_model.DeployTokenScopeReadRepository) && repo.UnitEnabledCtx(ctx, unit.TypeCode) {
+			askAuth = false
+		}
+	}
+
 	// check access
 	if askAuth {
 		// rely on the results of Contexter
@@ -181,6 +199,11 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 			return
 		}
 
+		if ctx.Doer.IsSuspended && !isPull {
+			ctx.PlainText(http.StatusForbidden, "Your account has been suspended. You cannot push.")
+			return
+		}
+
 		if repoExist {
 			p, err := access_model.GetUserRepoPermission(ctx, repo, ctx.Doer)
 			if err != nil {
@@ -287,7 +310,11 @@ func httpBase(ctx *context.Context) (h *serviceHandler) {
 		dir = repo_model.RepoPath(username, wikiRepoName)
 	}
 
-	return &serviceHandler{cfg, w, r, dir, cfg.Env}
+	h = &serviceHandler{cfg: cfg, w: w, r: r, dir: dir, environ: cfg.Env, doer: ctx.Doer, remoteAddr: ctx.RemoteAddr()}
+	if !isWiki {
+		h.repoID = repo.ID
+	}
+	return h
 }
 
 var (
@@ -344,6 +371,11 @@ type serviceHandler struct {
 	r       *http.Request
 	dir     string
 	environ []string
+
+	// repoID is the repository whose traffic is recorded, it is 0 for wikis
+	repoID     int64
+	doer       *user_model.User
+	remoteAddr string
 }
 
 func (h *serviceHandler) setHeaderNoCache() {
@@ -470,14 +502,27 @@ func serviceRPC(ctx gocontext.Context, h serviceHandler, service string) {
 		h.environ = append(h.environ, "GIT_PROTOCOL="+protocol)
 	}
 
+	var stdin io.Reader = reqBody
+	var stdout io.Writer = h.w
+	var request *uploadPackRequestScanner
+	var response *packDetector
+	if service == "upload-pack" && h.repoID > 0 {
+		request, response = &uploadPackRequestScanner{}, &packDetector{}
+		stdin = io.TeeReader(reqBody, request)
+		stdout = io.MultiWriter(h.w, response)
+	}
+	if service == "upload-pack" {
+		stdout = githttp.NewThrottledWriter(h.r.Context(), stdout, setting.Git.HTTPLimits.BandwidthLimit)
+	}
+
 	var stderr bytes.Buffer
 	cmd := git.NewCommand(h.r.Context(), service, "--stateless-rpc", h.dir)
 	cmd.SetDescription(fmt.Sprintf("%s %s %s [repo_path: %s]", git.GitExecutable, service, "--stateless-rpc", h.dir))
 	if err := cmd.Run(&git.RunOpts{
 		Dir:    h.dir,
 		Env:    append(os.Environ(), h.environ...),
-		Stdout: h.w,
-		Stdin:  reqBody,
+		Stdout: stdout,
+		Stdin:  stdin,
 		Stderr: &stderr,
 	}); err != nil {
 		if err.Error() != "signal: killed" {
@@ -485,12 +530,21 @@ func serviceRPC(ctx gocontext.Context, h serviceHandler, service string) {
 		}
 		return
 	}
+
+	if request != nil {
+		recordTraffic(ctx, h, request, response)
+	}
 }
 
 // ServiceUploadPack implements Git Smart HTTP protocol
 func ServiceUploadPack(ctx *context.Context) {
 	h := httpBase(ctx)
 	if h != nil {
+		release, ok := acquireUploadPack(ctx, h)
+		if !ok {
+			return
+		}
+		defer release()
 		serviceRPC(ctx, *h, "upload-pack")
 	}
 }
@@ -0,0 +1,42 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/githttp"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// uploadPackUserKey identifies the user an upload-pack counts against, anonymous clients are told apart by their IP address
+func uploadPackUserKey(h *serviceHandler) string {
+	if h.doer != nil {
+		return "user:" + strconv.FormatInt(h.doer.ID, 10)
+	}
+	host, _, err := net.SplitHostPort(h.remoteAddr)
+	if err != nil {
+		host = h.remoteAddr
+	}
+	return "ip:" + host
+}
+
+// acquireUploadPack reserves a slot for the upload-pack, it responds with 503 and returns false
+// if the user or the repository already has as many upload-packs running as allowed
+func acquireUploadPack(ctx *context.Context, h *serviceHandler) (release func(), ok bool) {
+	release, rejectedBy := githttp.DefaultLimiter().Acquire(uploadPackUserKey(h), h.dir)
+	if release != nil {
+		return release, true
+	}
+
+	log.Debug("Rejected upload-pack of %s in %s: too many concurrent upload-packs per %s", uploadPackUserKey(h), h.dir, rejectedBy)
+	ctx.Resp.Header().Set("Retry-After", strconv.Itoa(setting.Git.HTTPLimits.RetryAfter))
+	ctx.PlainText(http.StatusServiceUnavailable, "Too many concurrent clones and fetches, please try again later.")
+	return nil, false
+}
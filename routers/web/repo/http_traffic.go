@@ -0,0 +1,113 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"bytes"
+	gocontext "context"
+	"strconv"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+var packSignature = []byte("PACK")
+
+// uploadPackRequestScanner inspects the pkt-lines of an upload-pack request while they are passed to git,
+// a request which wants objects without having any is a clone, otherwise it is a fetch.
+type uploadPackRequestScanner struct {
+	buf  []byte
+	want bool
+	have bool
+	// stop is set once the negotiation is done or the request isn't made of pkt-lines
+	stop bool
+}
+
+// Write implements io.Writer
+func (s *uploadPackRequestScanner) Write(p []byte) (int, error) {
+	if s.stop {
+		return len(p), nil
+	}
+	s.buf = append(s.buf, p...)
+	for len(s.buf) >= 4 {
+		length, err := strconv.ParseUint(string(s.buf[:4]), 16, 16)
+		if err != nil {
+			s.stop = true
+			break
+		}
+		if length < 4 {
+			// flush, delimiter or response end packet
+			s.buf = s.buf[4:]
+			continue
+		}
+		if uint64(len(s.buf)) < length {
+			break
+		}
+		line := bytes.TrimSuffix(s.buf[4:length], []byte("\n"))
+		switch {
+		case bytes.HasPrefix(line, []byte("want ")), bytes.HasPrefix(line, []byte("want-ref ")):
+			s.want = true
+		case bytes.HasPrefix(line, []byte("have ")):
+			s.have = true
+		case bytes.Equal(line, []byte("done")):
+			s.stop = true
+		}
+		s.buf = s.buf[length:]
+	}
+	if s.stop {
+		s.buf = nil
+	}
+	return len(p), nil
+}
+
+// kind returns the git operation of the request, it is 0 if the request didn't want any objects
+func (s *uploadPackRequestScanner) kind() repo_model.TrafficKind {
+	if !s.want {
+		return 0
+	}
+	if s.have {
+		return repo_model.TrafficKindFetch
+	}
+	return repo_model.TrafficKindClone
+}
+
+// packDetector inspects the response of upload-pack for the signature of a packfile,
+// the negotiation rounds of a fetch which don't send a packfile yet are no operation on their own.
+type packDetector struct {
+	tail  []byte
+	found bool
+}
+
+// Write implements io.Writer
+func (d *packDetector) Write(p []byte) (int, error) {
+	if d.found {
+		return len(p), nil
+	}
+	if bytes.Contains(p, packSignature) {
+		d.found = true
+		return len(p), nil
+	}
+	// the signature may be split between two writes
+	n := len(packSignature) - 1
+	if len(p) < n {
+		n = len(p)
+	}
+	if bytes.Contains(append(d.tail, p[:n]...), packSignature) {
+		d.found = true
+		return len(p), nil
+	}
+	d.tail = append(d.tail, p...)
+	if len(d.tail) > len(packSignature)-1 {
+		d.tail = append([]byte(nil), d.tail[len(d.tail)-len(packSignature)+1:]...)
+	}
+	return len(p), nil
+}
+
+// recordTraffic counts the clone or fetch of the upload-pack request if it has sent a packfile
+func recordTraffic(ctx gocontext.Context, h serviceHandler, request *uploadPackRequestScanner, response *packDetector) {
+	if kind := request.kind(); kind != 0 && response.found {
+		repo_service.RecordTraffic(ctx, h.repoID, kind, h.doer, h.remoteAddr)
+	}
+}
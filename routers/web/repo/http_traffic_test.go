@@ -0,0 +1,81 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"testing"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadPackRequestScanner(t *testing.T) {
+	const oid = "a8a8c6d5d9f3e1c4b7a2f0e9d8c7b6a5f4e3d2c1"
+	tests := []struct {
+		name    string
+		request string
+		kind    repo_model.TrafficKind
+	}{
+		{
+			name:    "clone",
+			request: "0032want " + oid + "\n00000009done\n",
+			kind:    repo_model.TrafficKindClone,
+		},
+		{
+			name:    "fetch",
+			request: "0032want " + oid + "\n00000032have " + oid + "\n0009done\n",
+			kind:    repo_model.TrafficKindFetch,
+		},
+		{
+			name:    "protocol v2 fetch",
+			request: "0012command=fetch\n00010032want " + oid + "\n0032have " + oid + "\n0009done\n0000",
+			kind:    repo_model.TrafficKindFetch,
+		},
+		{
+			name:    "protocol v2 ls-refs",
+			request: "0014command=ls-refs\n00010009peel\n0000",
+		},
+		{
+			name:    "invalid",
+			request: "want " + oid + "\n",
+		},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// write the request in small pieces to split its pkt-lines
+			s := &uploadPackRequestScanner{}
+			for i := 0; i < len(test.request); i += 5 {
+				end := i + 5
+				if end > len(test.request) {
+					end = len(test.request)
+				}
+				n, err := s.Write([]byte(test.request[i:end]))
+				assert.NoError(t, err)
+				assert.Equal(t, end-i, n)
+			}
+			assert.Equal(t, test.kind, s.kind())
+		})
+	}
+}
+
+func TestPackDetector(t *testing.T) {
+	d := &packDetector{}
+	d.Write([]byte("0008NAK\n0023\x02Enumerating objects: 3, done.\n002c\x01PA"))
+	assert.False(t, d.found)
+	d.Write([]byte("CK\x00\x00\x00\x02"))
+	assert.True(t, d.found)
+
+	d = &packDetector{}
+	d.Write([]byte("0008NAK\n"))
+	d.Write([]byte("P"))
+	d.Write([]byte("A"))
+	d.Write([]byte("CK"))
+	assert.True(t, d.found)
+
+	d = &packDetector{}
+	d.Write([]byte("0033ACK " + "a8a8c6d5d9f3e1c4b7a2f0e9d8c7b6a5f4e3d2c1" + " ready\n0008NAK\n"))
+	assert.False(t, d.found)
+}
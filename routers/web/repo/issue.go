@@ -418,6 +418,17 @@ func Issues(ctx *context.Context) {
 
 	ctx.Data["CanWriteIssuesOrPulls"] = ctx.Repo.CanWriteIssuesOrPulls(isPullList)
 
+	if ctx.IsSigned {
+		ctx.Data["SavedFilters"], _, err = issues_model.FindIssueFilters(ctx, &issues_model.FindIssueFiltersOptions{
+			UserID: ctx.Doer.ID,
+			RepoID: ctx.Repo.Repository.ID,
+		})
+		if err != nil {
+			ctx.ServerError("FindIssueFilters", err)
+			return
+		}
+	}
+
 	ctx.HTML(http.StatusOK, tplIssues)
 }
 
@@ -838,6 +849,8 @@ func NewIssue(ctx *context.Context) {
 	if ctx.Written() {
 		return
 	}
+	// the intake rules of the organization can route issues by the template they are created from
+	ctx.Data["IssueTemplateFile"] = ctx.FormString("template")
 
 	ctx.Data["HasIssuesOrPullsWritePermission"] = ctx.Repo.CanWrite(unit.TypeIssues)
 
@@ -989,6 +1002,7 @@ func NewIssuePost(ctx *context.Context) {
 	ctx.Data["NewIssueChooseTemplate"] = len(ctx.IssueTemplatesFromDefaultBranch()) > 0
 	ctx.Data["PullRequestWorkInProgressPrefixes"] = setting.Repository.PullRequest.WorkInProgressPrefixes
 	ctx.Data["IsAttachmentEnabled"] = setting.Attachment.Enabled
+	ctx.Data["IssueTemplateFile"] = form.Template
 	upload.AddUploadContext(ctx, "comment")
 
 	var (
@@ -1042,6 +1056,10 @@ func NewIssuePost(ctx *context.Context) {
 		}
 	}
 
+	if err := issue_service.ApplyIntakeRules(ctx, issue, form.Template); err != nil {
+		log.Error("ApplyIntakeRules: %v", err)
+	}
+
 	log.Trace("Issue created: %d/%d", repo.ID, issue.ID)
 	if ctx.FormString("redirect_after_creation") == "project" {
 		ctx.Redirect(ctx.Repo.RepoLink + "/projects/" + strconv.FormatInt(form.ProjectID, 10))
@@ -1229,6 +1247,19 @@ func ViewIssue(ctx *context.Context) {
 		if ctx.Written() {
 			return
 		}
+
+		envs, err := models.GetPreviewEnvironments(ctx, issue.ID)
+		if err != nil {
+			ctx.ServerError("GetPreviewEnvironments", err)
+			return
+		}
+		previewEnvironments := make([]*models.PreviewEnvironment, 0, len(envs))
+		for _, env := range envs {
+			if !env.IsExpired() {
+				previewEnvironments = append(previewEnvironments, env)
+			}
+		}
+		ctx.Data["PreviewEnvironments"] = previewEnvironments
 	}
 
 	// Metas.
@@ -1663,6 +1694,18 @@ func ViewIssue(ctx *context.Context) {
 		}
 		ctx.Data["IsPullBranchDeletable"] = isPullBranchDeletable
 
+		if (pull.HasMerged || issue.IsClosed) && ctx.Data["CanWriteToHeadRepo"] == true && !pull.HeadRepo.IsArchived &&
+			!git.IsBranchExist(ctx, pull.HeadRepo.RepoPath(), pull.HeadBranch) {
+			deletedBranch, err := models.GetLatestDeletedBranchByName(pull.HeadRepo.ID, pull.HeadBranch)
+			if err != nil {
+				ctx.ServerError("GetLatestDeletedBranchByName", err)
+				return
+			}
+			if deletedBranch != nil {
+				ctx.Data["RestoreBranchLink"] = issue.Link() + "/restore_branch"
+			}
+		}
+
 		stillCanManualMerge := func() bool {
 			if pull.HasMerged || issue.IsClosed || !ctx.IsSigned {
 				return false
@@ -13,12 +13,12 @@ import (
 
 	"code.gitea.io/gitea/models"
 	issuesModel "code.gitea.io/gitea/models/issues"
-	"code.gitea.io/gitea/models/unit"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/translation/i18n"
+	issue_service "code.gitea.io/gitea/services/issue"
 
 	"github.com/sergi/go-diff/diffmatchpatch"
 )
@@ -37,6 +37,8 @@ func GetContentHistoryOverview(ctx *context.Context) {
 			"textEdited":                   i18n.Tr(lang, "repo.issues.content_history.edited"),
 			"textDeleteFromHistory":        i18n.Tr(lang, "repo.issues.content_history.delete_from_history"),
 			"textDeleteFromHistoryConfirm": i18n.Tr(lang, "repo.issues.content_history.delete_from_history_confirm"),
+			"textPurgeFromHistory":         i18n.Tr(lang, "repo.issues.content_history.purge_from_history"),
+			"textPurgeFromHistoryConfirm":  i18n.Tr(lang, "repo.issues.content_history.purge_from_history_confirm"),
 			"textOptions":                  i18n.Tr(lang, "repo.issues.content_history.options"),
 		},
 		"editedHistoryCountMap": editedHistoryCountMap,
@@ -87,29 +89,6 @@ func GetContentHistoryList(ctx *context.Context) {
 	})
 }
 
-// canSoftDeleteContentHistory checks whether current user can soft-delete a history revision
-// Admins or owners can always delete history revisions. Normal users can only delete own history revisions.
-func canSoftDeleteContentHistory(ctx *context.Context, issue *models.Issue, comment *models.Comment,
-	history *issuesModel.ContentHistory,
-) bool {
-	canSoftDelete := false
-	if ctx.Repo.IsOwner() {
-		canSoftDelete = true
-	} else if ctx.Repo.CanWrite(unit.TypeIssues) {
-		if comment == nil {
-			// the issue poster or the history poster can soft-delete
-			canSoftDelete = ctx.Doer.ID == issue.PosterID || ctx.Doer.ID == history.PosterID
-			canSoftDelete = canSoftDelete && (history.IssueID == issue.ID)
-		} else {
-			// the comment poster or the history poster can soft-delete
-			canSoftDelete = ctx.Doer.ID == comment.PosterID || ctx.Doer.ID == history.PosterID
-			canSoftDelete = canSoftDelete && (history.IssueID == issue.ID)
-			canSoftDelete = canSoftDelete && (history.CommentID == comment.ID)
-		}
-	}
-	return canSoftDelete
-}
-
 // GetContentHistoryDetail get detail
 func GetContentHistoryDetail(ctx *context.Context) {
 	issue := GetActionIssue(ctx)
@@ -138,17 +117,12 @@ func GetContentHistoryDetail(ctx *context.Context) {
 
 	// get the previous history revision (if exists)
 	var prevHistoryID int64
-	var prevHistoryContentText string
 	if prevHistory != nil {
 		prevHistoryID = prevHistory.ID
-		prevHistoryContentText = prevHistory.ContentText
 	}
 
 	// compare the current history revision with the previous one
-	dmp := diffmatchpatch.New()
-	// `checklines=false` makes better diff result
-	diff := dmp.DiffMain(prevHistoryContentText, history.ContentText, false)
-	diff = dmp.DiffCleanupEfficiency(diff)
+	diff := issue_service.DiffContentHistory(history, prevHistory)
 
 	// use chroma to render the diff html
 	diffHTMLBuf := bytes.Buffer{}
@@ -169,7 +143,8 @@ func GetContentHistoryDetail(ctx *context.Context) {
 	diffHTMLBuf.WriteString("</pre>")
 
 	ctx.JSON(http.StatusOK, map[string]interface{}{
-		"canSoftDelete": canSoftDeleteContentHistory(ctx, issue, comment, history),
+		"canSoftDelete": issue_service.CanSoftDeleteContentHistory(ctx.Repo.Permission, ctx.Doer, issue, comment, history),
+		"canPurge":      ctx.Doer != nil && ctx.Doer.IsAdmin,
 		"historyId":     historyID,
 		"prevHistoryId": prevHistoryID,
 		"diffHtml":      diffHTMLBuf.String(),
@@ -200,7 +175,7 @@ func SoftDeleteContentHistory(ctx *context.Context) {
 		return
 	}
 
-	canSoftDelete := canSoftDeleteContentHistory(ctx, issue, comment, history)
+	canSoftDelete := issue_service.CanSoftDeleteContentHistory(ctx.Repo.Permission, ctx.Doer, issue, comment, history)
 	if !canSoftDelete {
 		ctx.JSON(http.StatusForbidden, map[string]interface{}{
 			"message": "Can not delete the content history",
@@ -214,3 +189,32 @@ func SoftDeleteContentHistory(ctx *context.Context) {
 		"ok": err == nil,
 	})
 }
+
+// PurgeContentHistory hard deletes a history revision, only site admins can purge
+func PurgeContentHistory(ctx *context.Context) {
+	issue := GetActionIssue(ctx)
+	if issue == nil {
+		return
+	}
+
+	commentID := ctx.FormInt64("comment_id")
+	historyID := ctx.FormInt64("history_id")
+
+	history, err := issuesModel.GetIssueContentHistoryByID(ctx, historyID)
+	if err != nil {
+		log.Error("can not get issue content history %v. err=%v", historyID, err)
+		return
+	}
+	if history.IssueID != issue.ID || history.CommentID != commentID {
+		ctx.JSON(http.StatusNotFound, map[string]interface{}{
+			"message": "Can not find the content history",
+		})
+		return
+	}
+
+	err = issuesModel.PurgeIssueContentHistoryRevision(ctx, historyID)
+	log.Info("Content history revision %d of issue %d (comment %d) has been purged by %s", historyID, issue.ID, commentID, ctx.Doer.Name)
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"ok": err == nil,
+	})
+}
@@ -64,6 +64,9 @@ func AddDependency(ctx *context.Context) {
 		} else if models.IsErrCircularDependency(err) {
 			ctx.Flash.Error(ctx.Tr("repo.issues.dependency.add_error_cannot_create_circular"))
 			return
+		} else if models.IsErrDependencyGraphTooLarge(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.dependency.add_error_graph_too_large"))
+			return
 		} else {
 			ctx.ServerError("CreateOrUpdateIssueDependency", err)
 			return
@@ -0,0 +1,79 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"strings"
+
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+// SaveIssueFilter saves the current filters of the issue list under a name
+func SaveIssueFilter(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.SaveIssueFilterForm)
+	link := ctx.Repo.RepoLink + "/" + ctx.Params(":type")
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(link, http.StatusSeeOther)
+		return
+	}
+
+	f := &issues_model.IssueFilter{
+		UserID:      ctx.Doer.ID,
+		Name:        form.Name,
+		Keyword:     form.Keyword,
+		ViewType:    form.Type,
+		SortType:    form.Sort,
+		State:       form.State,
+		MilestoneID: form.Milestone,
+		AssigneeID:  form.Assignee,
+	}
+	if !form.AllRepos {
+		f.RepoID = ctx.Repo.Repository.ID
+	}
+	if len(form.Labels) > 0 && form.Labels != "0" {
+		labelIDs, err := base.StringsToInt64s(strings.Split(form.Labels, ","))
+		if err != nil {
+			ctx.ServerError("StringsToInt64s", err)
+			return
+		}
+		f.SetLabelIDs(labelIDs)
+	}
+
+	if err := issues_model.CreateIssueFilter(ctx, f); err != nil {
+		if issues_model.IsErrIssueFilterAlreadyExist(err) {
+			ctx.Flash.Error(ctx.Tr("repo.issues.filter_already_exists", f.Name))
+			ctx.Redirect(link+"?"+f.QueryString(), http.StatusSeeOther)
+			return
+		}
+		ctx.ServerError("CreateIssueFilter", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.issues.filter_save_success", f.Name))
+	ctx.Redirect(link+"?"+f.QueryString(), http.StatusSeeOther)
+}
+
+// DeleteIssueFilter deletes a saved filter of the issue list
+func DeleteIssueFilter(ctx *context.Context) {
+	if err := issues_model.DeleteIssueFilter(ctx, ctx.Doer.ID, ctx.ParamsInt64(":id")); err != nil {
+		if !issues_model.IsErrIssueFilterNotExist(err) {
+			ctx.ServerError("DeleteIssueFilter", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.issues.filter_delete_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/" + ctx.Params(":type"),
+	})
+}
@@ -0,0 +1,145 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/services/issue/timereport"
+)
+
+const tplTimeReport base.TplName = "repo/issue/time_report"
+
+// TimeReport renders the tracked times of a repository grouped by user, label or milestone
+func TimeReport(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.issues.time_report")
+	ctx.Data["PageIsIssueList"] = true
+	ctx.Data["PageIsTimeReport"] = true
+
+	opts := repoTimeReportOptions(ctx)
+	if ctx.Written() {
+		return
+	}
+	ctx.Data["TimeReportLink"] = ctx.Repo.RepoLink + "/time_report"
+
+	if RenderTimeReport(ctx, opts); ctx.Written() {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplTimeReport)
+}
+
+// TimeReportExport serves the tracked times of a repository as CSV or JSON file
+func TimeReportExport(ctx *context.Context) {
+	opts := repoTimeReportOptions(ctx)
+	if ctx.Written() {
+		return
+	}
+	ServeTimeReport(ctx, opts, ctx.Repo.Repository.Name)
+}
+
+func repoTimeReportOptions(ctx *context.Context) *timereport.Options {
+	if !ctx.Repo.Repository.IsTimetrackerEnabled() {
+		ctx.NotFound("IsTimetrackerEnabled", nil)
+		return nil
+	}
+
+	canFilterUser := ctx.Doer.IsAdmin || ctx.IsUserRepoWriter([]unit.Type{unit.TypeIssues})
+	ctx.Data["CanFilterUser"] = canFilterUser
+
+	opts := GetTimeReportOptions(ctx, canFilterUser)
+	if ctx.Written() {
+		return nil
+	}
+	opts.RepoID = ctx.Repo.Repository.ID
+	return opts
+}
+
+// GetTimeReportOptions reads the grouping, user and date range of a time report from the query
+// and fills them into the context data. Users who can not filter by user only see their own times.
+func GetTimeReportOptions(ctx *context.Context, canFilterUser bool) *timereport.Options {
+	opts := &timereport.Options{
+		GroupBy: timereport.GroupBy(ctx.FormTrim("group_by")),
+	}
+	if !opts.GroupBy.IsValid() {
+		opts.GroupBy = timereport.GroupByUser
+	}
+	ctx.Data["GroupBy"] = string(opts.GroupBy)
+
+	if since, err := time.ParseInLocation("2006-01-02", ctx.FormTrim("since"), time.Local); err == nil {
+		opts.CreatedAfterUnix = since.Unix()
+		ctx.Data["Since"] = since.Format("2006-01-02")
+	}
+	if until, err := time.ParseInLocation("2006-01-02", ctx.FormTrim("until"), time.Local); err == nil {
+		// the end date is inclusive
+		opts.CreatedBeforeUnix = until.AddDate(0, 0, 1).Unix() - 1
+		ctx.Data["Until"] = until.Format("2006-01-02")
+	}
+
+	if !canFilterUser {
+		opts.UserID = ctx.Doer.ID
+		return opts
+	}
+	if name := ctx.FormTrim("user"); name != "" {
+		user, err := user_model.GetUserByName(ctx, name)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				ctx.NotFound("GetUserByName", err)
+			} else {
+				ctx.ServerError("GetUserByName", err)
+			}
+			return nil
+		}
+		opts.UserID = user.ID
+		ctx.Data["User"] = user.Name
+	}
+	return opts
+}
+
+// RenderTimeReport generates the report and fills it into the context data
+func RenderTimeReport(ctx *context.Context, opts *timereport.Options) {
+	report, err := timereport.Generate(ctx, opts)
+	if err != nil {
+		ctx.ServerError("Generate", err)
+		return
+	}
+	ctx.Data["Report"] = report
+}
+
+// ServeTimeReport generates the report and serves it as a file in the format given by the query
+func ServeTimeReport(ctx *context.Context, opts *timereport.Options, name string) {
+	report, err := timereport.Generate(ctx, opts)
+	if err != nil {
+		ctx.ServerError("Generate", err)
+		return
+	}
+
+	var buf bytes.Buffer
+	name += "-time-report-" + string(opts.GroupBy)
+	switch ctx.FormTrim("format") {
+	case "json":
+		if err := json.NewEncoder(&buf).Encode(timereport.ToAPIReport(report)); err != nil {
+			ctx.ServerError("Encode", err)
+			return
+		}
+		name += ".json"
+	case "", "csv":
+		if err := timereport.WriteCSV(&buf, report); err != nil {
+			ctx.ServerError("WriteCSV", err)
+			return
+		}
+		name += ".csv"
+	default:
+		ctx.NotFound("ServeTimeReport", nil)
+		return
+	}
+	ctx.ServeContent(name, bytes.NewReader(buf.Bytes()))
+}
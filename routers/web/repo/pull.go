@@ -12,6 +12,7 @@ import (
 	"html"
 	"net/http"
 	"net/url"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -110,7 +111,8 @@ func getForkRepository(ctx *context.Context) *repo_model.Repository {
 	ctx.Data["repo_name"] = forkRepo.Name
 	ctx.Data["description"] = forkRepo.Description
 	ctx.Data["IsPrivate"] = forkRepo.IsPrivate || forkRepo.Owner.Visibility == structs.VisibleTypePrivate
-	canForkToUser := forkRepo.OwnerID != ctx.Doer.ID && !repo_model.HasForkedRepo(ctx.Doer.ID, forkRepo.ID)
+	// the owner of the repository may fork it into the same namespace under a new name
+	canForkToUser := forkRepo.OwnerID == ctx.Doer.ID || !repo_model.HasForkedRepo(ctx.Doer.ID, forkRepo.ID)
 
 	ctx.Data["ForkRepo"] = forkRepo
 
@@ -121,18 +123,20 @@ func getForkRepository(ctx *context.Context) *repo_model.Repository {
 	}
 	var orgs []*organization.Organization
 	for _, org := range ownedOrgs {
-		if forkRepo.OwnerID != org.ID && !repo_model.HasForkedRepo(org.ID, forkRepo.ID) {
+		if forkRepo.OwnerID == org.ID || !repo_model.HasForkedRepo(org.ID, forkRepo.ID) {
 			orgs = append(orgs, org)
 		}
 	}
 
+	// owners of a repository in the fork network can't fork it again, except the owner of the
+	// repository itself which may fork it into its own namespace under a new name
 	traverseParentRepo := forkRepo
 	for {
-		if ctx.Doer.ID == traverseParentRepo.OwnerID {
+		if ctx.Doer.ID == traverseParentRepo.OwnerID && ctx.Doer.ID != forkRepo.OwnerID {
 			canForkToUser = false
 		} else {
 			for i, org := range orgs {
-				if org.ID == traverseParentRepo.OwnerID {
+				if org.ID == traverseParentRepo.OwnerID && org.ID != forkRepo.OwnerID {
 					orgs = append(orgs[:i], orgs[i+1:]...)
 					break
 				}
@@ -152,11 +156,33 @@ func getForkRepository(ctx *context.Context) *repo_model.Repository {
 	ctx.Data["CanForkToUser"] = canForkToUser
 	ctx.Data["Orgs"] = orgs
 
+	// the original name is taken in the namespace of the repository itself, so the name
+	// suggested for the fork depends on the selected owner
+	owners := make([]*user_model.User, 0, len(orgs)+1)
+	if canForkToUser {
+		owners = append(owners, ctx.Doer)
+	}
+	for _, org := range orgs {
+		owners = append(owners, org.AsUser())
+	}
+	forkNames := make(map[int64]string, len(owners))
+	for _, owner := range owners {
+		forkNames[owner.ID], err = repo_service.ForkName(ctx, owner, forkRepo)
+		if err != nil {
+			ctx.ServerError("ForkName", err)
+			return nil
+		}
+	}
+	ctx.Data["ForkNames"] = forkNames
+
 	if canForkToUser {
 		ctx.Data["ContextUser"] = ctx.Doer
 	} else if len(orgs) > 0 {
 		ctx.Data["ContextUser"] = orgs[0]
 	}
+	if len(owners) > 0 {
+		ctx.Data["repo_name"] = forkNames[owners[0].ID]
+	}
 
 	return forkRepo
 }
@@ -196,24 +222,27 @@ func ForkPost(ctx *context.Context) {
 	}
 
 	var err error
-	traverseParentRepo := forkRepo
-	for {
-		if ctxUser.ID == traverseParentRepo.OwnerID {
-			ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplFork, &form)
-			return
-		}
-		repo := repo_model.GetForkedRepo(ctxUser.ID, traverseParentRepo.ID)
-		if repo != nil {
-			ctx.Redirect(ctxUser.HomeLink() + "/" + url.PathEscape(repo.Name))
-			return
-		}
-		if !traverseParentRepo.IsFork {
-			break
-		}
-		traverseParentRepo, err = repo_model.GetRepositoryByID(traverseParentRepo.ForkID)
-		if err != nil {
-			ctx.ServerError("GetRepositoryByID", err)
-			return
+	// forks into the namespace of the repository itself only need a new name
+	if ctxUser.ID != forkRepo.OwnerID {
+		traverseParentRepo := forkRepo
+		for {
+			if ctxUser.ID == traverseParentRepo.OwnerID {
+				ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplFork, &form)
+				return
+			}
+			repo := repo_model.GetForkedRepo(ctxUser.ID, traverseParentRepo.ID)
+			if repo != nil {
+				ctx.Redirect(ctxUser.HomeLink() + "/" + url.PathEscape(repo.Name))
+				return
+			}
+			if !traverseParentRepo.IsFork {
+				break
+			}
+			traverseParentRepo, err = repo_model.GetRepositoryByID(traverseParentRepo.ForkID)
+			if err != nil {
+				ctx.ServerError("GetRepositoryByID", err)
+				return
+			}
 		}
 	}
 
@@ -631,6 +660,24 @@ func ViewPullCommits(ctx *context.Context) {
 	ctx.Data["Commits"] = commits
 	ctx.Data["CommitCount"] = len(commits)
 
+	if ctx.IsSigned && !pull.HasMerged && !issue.IsClosed {
+		canRewrite, err := pull_service.IsUserAllowedToRewrite(ctx, pull, ctx.Doer)
+		if err != nil {
+			ctx.ServerError("IsUserAllowedToRewrite", err)
+			return
+		}
+		if canRewrite {
+			// the rewrite form lists the commits in the order they are applied
+			rewriteCommits := make([]*git.Commit, 0, len(prInfo.Commits))
+			for i := len(prInfo.Commits) - 1; i >= 0; i-- {
+				rewriteCommits = append(rewriteCommits, prInfo.Commits[i])
+			}
+			ctx.Data["CanRewrite"] = len(rewriteCommits) > 0
+			ctx.Data["RewriteCommits"] = rewriteCommits
+			ctx.Data["RewriteHeadCommitID"] = prInfo.HeadCommitID
+		}
+	}
+
 	getBranchData(ctx, issue)
 	ctx.HTML(http.StatusOK, tplPullCommits)
 }
@@ -881,6 +928,104 @@ func UpdatePullRequest(ctx *context.Context) {
 	ctx.Redirect(issue.Link())
 }
 
+// RewritePullRequest squashes, rewords, drops and reorders the commits of a pull request
+func RewritePullRequest(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+	if issue.IsClosed || issue.PullRequest.HasMerged {
+		ctx.NotFound("RewritePullRequest", nil)
+		return
+	}
+	pr := issue.PullRequest
+	commitsLink := issue.Link() + "/commits"
+
+	allowed, err := pull_service.IsUserAllowedToRewrite(ctx, pr, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("IsUserAllowedToRewrite", err)
+		return
+	}
+	if !allowed {
+		ctx.Flash.Error(ctx.Tr("repo.pulls.rewrite_not_allowed"))
+		ctx.Redirect(commitsLink)
+		return
+	}
+
+	// the form posts one row per commit, the rows are ordered by their position
+	shas := ctx.FormStrings("sha")
+	actions := ctx.FormStrings("action")
+	positions := ctx.FormStrings("position")
+	messages := ctx.FormStrings("message")
+	if len(actions) != len(shas) || len(positions) != len(shas) || len(messages) != len(shas) {
+		ctx.Error(http.StatusBadRequest)
+		return
+	}
+
+	type rewriteRow struct {
+		position int
+		step     *pull_service.RewriteStep
+	}
+	rows := make([]rewriteRow, 0, len(shas))
+	for i := range shas {
+		position, err := strconv.Atoi(strings.TrimSpace(positions[i]))
+		if err != nil {
+			ctx.Flash.Error(ctx.Tr("repo.pulls.rewrite_invalid_position"))
+			ctx.Redirect(commitsLink)
+			return
+		}
+		step := &pull_service.RewriteStep{
+			CommitID: shas[i],
+			Action:   pull_service.RewriteAction(actions[i]),
+		}
+		// squashed commits keep both messages
+		if step.Action == pull_service.RewriteActionReword {
+			step.Message = strings.TrimSpace(messages[i])
+		}
+		rows = append(rows, rewriteRow{position: position, step: step})
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		return rows[i].position < rows[j].position
+	})
+	steps := make([]*pull_service.RewriteStep, 0, len(rows))
+	for _, row := range rows {
+		steps = append(steps, row.step)
+	}
+
+	if _, err := pull_service.Rewrite(ctx, pr, ctx.Doer, ctx.FormString("head_commit_id"), steps); err != nil {
+		switch {
+		case pull_service.IsErrInvalidRewrite(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.rewrite_invalid", err.(pull_service.ErrInvalidRewrite).Reason))
+		case pull_service.IsErrRewriteConflict(err):
+			ctx.Flash.Error(ctx.Tr("repo.pulls.rewrite_conflict", err.(pull_service.ErrRewriteConflict).CommitID))
+		case git.IsErrPushRejected(err):
+			pushrejErr := err.(*git.ErrPushRejected)
+			if len(pushrejErr.Message) == 0 {
+				ctx.Flash.Error(ctx.Tr("repo.editor.push_rejected_no_message"))
+			} else {
+				flashError, err := ctx.RenderToString(tplAlertDetails, map[string]interface{}{
+					"Message": ctx.Tr("repo.editor.push_rejected"),
+					"Summary": ctx.Tr("repo.editor.push_rejected_summary"),
+					"Details": utils.SanitizeFlashErrorString(pushrejErr.Message),
+				})
+				if err != nil {
+					ctx.ServerError("RewritePullRequest.HTMLString", err)
+					return
+				}
+				ctx.Flash.Error(flashError)
+			}
+		default:
+			ctx.ServerError("Rewrite", err)
+			return
+		}
+		ctx.Redirect(commitsLink)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.pulls.rewrite_success"))
+	ctx.Redirect(commitsLink)
+}
+
 // MergePullRequest response for merging pull request
 func MergePullRequest(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.MergePullRequestForm)
@@ -1317,6 +1462,57 @@ func CleanUpPullRequest(ctx *context.Context) {
 	deleteBranch(ctx, pr, gitRepo)
 }
 
+// RestorePullRequestBranch restores the deleted head branch of a merged or closed pull request
+func RestorePullRequestBranch(ctx *context.Context) {
+	issue := checkPullInfo(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	pr := issue.PullRequest
+
+	if !pr.HasMerged && !issue.IsClosed {
+		ctx.NotFound("RestorePullRequestBranch", nil)
+		return
+	}
+
+	if err := pr.LoadHeadRepoCtx(ctx); err != nil {
+		ctx.ServerError("LoadHeadRepo", err)
+		return
+	} else if pr.HeadRepo == nil {
+		// Forked repository has already been deleted
+		ctx.NotFound("RestorePullRequestBranch", nil)
+		return
+	}
+
+	perm, err := access_model.GetUserRepoPermission(ctx, pr.HeadRepo, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return
+	}
+	if !perm.CanWrite(unit.TypeCode) || pr.HeadRepo.IsArchived {
+		ctx.NotFound("RestorePullRequestBranch", nil)
+		return
+	}
+
+	deletedBranch, err := models.GetLatestDeletedBranchByName(pr.HeadRepo.ID, pr.HeadBranch)
+	if err != nil {
+		ctx.ServerError("GetLatestDeletedBranchByName", err)
+		return
+	} else if deletedBranch == nil {
+		ctx.NotFound("RestorePullRequestBranch", nil)
+		return
+	}
+
+	defer func() {
+		ctx.JSON(http.StatusOK, map[string]interface{}{
+			"redirect": issue.Link(),
+		})
+	}()
+
+	restoreBranch(ctx, pr.HeadRepo, deletedBranch)
+}
+
 func deleteBranch(ctx *context.Context, pr *models.PullRequest, gitRepo *git.Repository) {
 	fullBranchName := pr.HeadRepo.Owner.Name + "/" + pr.HeadBranch
 	if err := repo_service.DeleteBranch(ctx.Doer, pr.HeadRepo, gitRepo, pr.HeadBranch); err != nil {
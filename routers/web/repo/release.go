@@ -12,6 +12,8 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
@@ -23,6 +25,8 @@ import (
 	"code.gitea.io/gitea/modules/upload"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
+	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	"code.gitea.io/gitea/services/attachment"
 	"code.gitea.io/gitea/services/forms"
 	releaseservice "code.gitea.io/gitea/services/release"
 )
@@ -106,7 +110,8 @@ func releasesOrTags(ctx *context.Context, isTagList bool) {
 	ctx.Data["Tags"] = tags
 
 	writeAccess := ctx.Repo.CanWrite(unit.TypeReleases)
-	ctx.Data["CanCreateRelease"] = writeAccess && !ctx.Repo.Repository.IsArchived
+	ctx.Data["CanCreateRelease"] = ctx.Repo.CanDoReleaseAction(perm.ReleaseActionCreate) && !ctx.Repo.Repository.IsArchived
+	ctx.Data["CanEditRelease"] = ctx.Repo.CanDoReleaseAction(perm.ReleaseActionEdit) && !ctx.Repo.Repository.IsArchived
 
 	opts := models.FindReleasesOptions{
 		ListOptions:   listOptions,
@@ -189,8 +194,8 @@ func SingleRelease(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.release.releases")
 	ctx.Data["PageIsReleaseList"] = true
 
-	writeAccess := ctx.Repo.CanWrite(unit.TypeReleases)
-	ctx.Data["CanCreateRelease"] = writeAccess && !ctx.Repo.Repository.IsArchived
+	ctx.Data["CanCreateRelease"] = ctx.Repo.CanDoReleaseAction(perm.ReleaseActionCreate) && !ctx.Repo.Repository.IsArchived
+	ctx.Data["CanEditRelease"] = ctx.Repo.CanDoReleaseAction(perm.ReleaseActionEdit) && !ctx.Repo.Repository.IsArchived
 
 	release, err := models.GetRelease(ctx.Repo.Repository.ID, ctx.Params("*"))
 	if err != nil {
@@ -288,16 +293,27 @@ func NewRelease(ctx *context.Context) {
 		}
 	}
 	ctx.Data["IsAttachmentEnabled"] = setting.Attachment.Enabled
+	ctx.Data["CanUploadReleaseAssets"] = ctx.Repo.CanDoReleaseAction(perm.ReleaseActionUploadAssets)
+	ctx.Data["CanSignTag"] = canSignTag(ctx)
 	upload.AddUploadContext(ctx, "release")
 	ctx.HTML(http.StatusOK, tplReleaseNew)
 }
 
+// canSignTag checks if tags of the repository can be signed with the key of the instance
+func canSignTag(ctx *context.Context) bool {
+	signingKey, _ := asymkey_service.SigningKey(ctx, ctx.Repo.Repository.RepoPath())
+	return signingKey != ""
+}
+
 // NewReleasePost response for creating a release
 func NewReleasePost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.NewReleaseForm)
 	ctx.Data["Title"] = ctx.Tr("repo.release.new_release")
 	ctx.Data["PageIsReleaseList"] = true
 	ctx.Data["RequireTribute"] = true
+	ctx.Data["CanSignTag"] = canSignTag(ctx)
+	canUploadAssets := ctx.Repo.CanDoReleaseAction(perm.ReleaseActionUploadAssets)
+	ctx.Data["CanUploadReleaseAssets"] = canUploadAssets
 
 	if ctx.HasError() {
 		ctx.HTML(http.StatusOK, tplReleaseNew)
@@ -310,9 +326,16 @@ func NewReleasePost(ctx *context.Context) {
 	}
 
 	var attachmentUUIDs []string
-	if setting.Attachment.Enabled {
+	if setting.Attachment.Enabled && canUploadAssets {
 		attachmentUUIDs = form.Files
 	}
+	if len(form.TagOnly) == 0 && canUploadAssets {
+		externalUUIDs := newExternalAssets(ctx, form, &form.ExternalAssetsForm)
+		if ctx.Written() {
+			return
+		}
+		attachmentUUIDs = append(attachmentUUIDs, externalUUIDs...)
+	}
 
 	rel, err := models.GetRelease(ctx.Repo.Repository.ID, form.TagName)
 	if err != nil {
@@ -327,7 +350,7 @@ func NewReleasePost(ctx *context.Context) {
 		}
 
 		if len(form.TagOnly) > 0 {
-			if err = releaseservice.CreateNewTag(ctx, ctx.Doer, ctx.Repo.Repository, form.Target, form.TagName, msg); err != nil {
+			if err = releaseservice.CreateNewTag(ctx, ctx.Doer, ctx.Repo.Repository, form.Target, form.TagName, msg, form.SignTag); err != nil {
 				if models.IsErrTagAlreadyExists(err) {
 					e := err.(models.ErrTagAlreadyExists)
 					ctx.Flash.Error(ctx.Tr("repo.branch.tag_collision", e.TagName))
@@ -347,6 +370,12 @@ func NewReleasePost(ctx *context.Context) {
 					return
 				}
 
+				if asymkey_service.IsErrWontSign(err) {
+					ctx.Flash.Error(ctx.Tr("repo.release.tag_sign_unavailable"))
+					ctx.Redirect(ctx.Repo.RepoLink + "/src/" + ctx.Repo.BranchNameSubURL())
+					return
+				}
+
 				ctx.ServerError("releaseservice.CreateNewTag", err)
 				return
 			}
@@ -370,7 +399,7 @@ func NewReleasePost(ctx *context.Context) {
 			IsTag:        false,
 		}
 
-		if err = releaseservice.CreateRelease(ctx.Repo.GitRepo, rel, attachmentUUIDs, msg); err != nil {
+		if err = releaseservice.CreateRelease(ctx.Repo.GitRepo, rel, attachmentUUIDs, msg, form.SignTag); err != nil {
 			ctx.Data["Err_TagName"] = true
 			switch {
 			case models.IsErrReleaseAlreadyExist(err):
@@ -379,6 +408,8 @@ func NewReleasePost(ctx *context.Context) {
 				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_invalid"), tplReleaseNew, &form)
 			case models.IsErrProtectedTagName(err):
 				ctx.RenderWithErr(ctx.Tr("repo.release.tag_name_protected"), tplReleaseNew, &form)
+			case asymkey_service.IsErrWontSign(err):
+				ctx.RenderWithErr(ctx.Tr("repo.release.tag_sign_unavailable"), tplReleaseNew, &form)
 			default:
 				ctx.ServerError("CreateRelease", err)
 			}
@@ -410,6 +441,48 @@ func NewReleasePost(ctx *context.Context) {
 	ctx.Redirect(ctx.Repo.RepoLink + "/releases")
 }
 
+// newExternalAssets creates the assets linking to external files entered in the form and returns their uuids,
+// rows without name and url are skipped. If an asset is invalid, the form is rendered again with an error.
+func newExternalAssets(ctx *context.Context, form interface{}, assetsForm *forms.ExternalAssetsForm) []string {
+	formValue := func(values []string, i int) string {
+		if i < len(values) {
+			return values[i]
+		}
+		return ""
+	}
+
+	attachments := make([]*repo_model.Attachment, 0, len(assetsForm.ExternalAssetURL))
+	for i, externalURL := range assetsForm.ExternalAssetURL {
+		name := formValue(assetsForm.ExternalAssetName, i)
+		if strings.TrimSpace(name) == "" && strings.TrimSpace(externalURL) == "" {
+			continue
+		}
+		attach := &repo_model.Attachment{
+			RepoID:      ctx.Repo.Repository.ID,
+			UploaderID:  ctx.Doer.ID,
+			Name:        name,
+			ExternalURL: externalURL,
+			Checksum:    formValue(assetsForm.ExternalAssetChecksum, i),
+		}
+		if err := attachment.ValidateExternalAttachment(attach); err != nil {
+			ctx.Data["Err_ExternalAssets"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.release.external_asset_invalid", attach.Name), tplReleaseNew, form)
+			return nil
+		}
+		attachments = append(attachments, attach)
+	}
+
+	uuids := make([]string, 0, len(attachments))
+	for _, attach := range attachments {
+		if _, err := attachment.NewExternalAttachment(attach); err != nil {
+			ctx.ServerError("NewExternalAttachment", err)
+			return nil
+		}
+		uuids = append(uuids, attach.UUID)
+	}
+	return uuids
+}
+
 // EditRelease render release edit page
 func EditRelease(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.release.edit_release")
@@ -417,6 +490,7 @@ func EditRelease(ctx *context.Context) {
 	ctx.Data["PageIsEditRelease"] = true
 	ctx.Data["RequireTribute"] = true
 	ctx.Data["IsAttachmentEnabled"] = setting.Attachment.Enabled
+	ctx.Data["CanUploadReleaseAssets"] = ctx.Repo.CanDoReleaseAction(perm.ReleaseActionUploadAssets)
 	upload.AddUploadContext(ctx, "release")
 
 	tagName := ctx.Params("*")
@@ -454,6 +528,8 @@ func EditReleasePost(ctx *context.Context) {
 	ctx.Data["PageIsReleaseList"] = true
 	ctx.Data["PageIsEditRelease"] = true
 	ctx.Data["RequireTribute"] = true
+	canUploadAssets := ctx.Repo.CanDoReleaseAction(perm.ReleaseActionUploadAssets)
+	ctx.Data["CanUploadReleaseAssets"] = canUploadAssets
 
 	tagName := ctx.Params("*")
 	rel, err := models.GetRelease(ctx.Repo.Repository.ID, tagName)
@@ -485,7 +561,9 @@ func EditReleasePost(ctx *context.Context) {
 	var addAttachmentUUIDs, delAttachmentUUIDs []string
 	editAttachments := make(map[string]string) // uuid -> new name
 	if setting.Attachment.Enabled {
-		addAttachmentUUIDs = form.Files
+		if canUploadAssets {
+			addAttachmentUUIDs = form.Files
+		}
 		for k, v := range ctx.Req.Form {
 			if strings.HasPrefix(k, delPrefix) && v[0] == "true" {
 				delAttachmentUUIDs = append(delAttachmentUUIDs, k[len(delPrefix):])
@@ -495,6 +573,14 @@ func EditReleasePost(ctx *context.Context) {
 		}
 	}
 
+	if canUploadAssets {
+		externalUUIDs := newExternalAssets(ctx, form, &form.ExternalAssetsForm)
+		if ctx.Written() {
+			return
+		}
+		addAttachmentUUIDs = append(addAttachmentUUIDs, externalUUIDs...)
+	}
+
 	rel.Title = form.Title
 	rel.Note = form.Content
 	rel.IsDraft = len(form.Draft) > 0
@@ -539,3 +625,28 @@ func deleteReleaseOrTag(ctx *context.Context, isDelTag bool) {
 		"redirect": ctx.Repo.RepoLink + "/releases",
 	})
 }
+
+// DownloadReleaseAssets streams a zip file combining the assets and the source archives of a release
+func DownloadReleaseAssets(ctx *context.Context) {
+	release, err := models.GetRelease(ctx.Repo.Repository.ID, ctx.Params("vTag"))
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			ctx.NotFound("GetRelease", err)
+			return
+		}
+		ctx.ServerError("GetRelease", err)
+		return
+	}
+	if release.IsDraft && !ctx.Repo.CanWrite(unit.TypeReleases) {
+		ctx.NotFound("GetRelease", nil)
+		return
+	}
+	release.Repo = ctx.Repo.Repository
+
+	ctx.SetServeHeaders(releaseservice.AssetsArchiveName(release))
+	ctx.Resp.Header().Set("Content-Type", "application/zip")
+	if err := releaseservice.WriteAssetsArchive(ctx, ctx.Repo.GitRepo, release, ctx.Repo.CanRead(unit.TypeCode), ctx.Resp); err != nil {
+		// the response has been started already, so the error can only be logged
+		log.Error("WriteAssetsArchive of release %d: %v", release.ID, err)
+	}
+}
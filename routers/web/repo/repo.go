@@ -335,10 +335,14 @@ func acceptOrRejectRepoTransfer(ctx *context.Context, accept bool) error {
 			ctx.Repo.GitRepo = nil
 		}
 
-		if err := repo_service.TransferOwnership(repoTransfer.Doer, repoTransfer.Recipient, ctx.Repo.Repository, repoTransfer.Teams); err != nil {
+		if err := repo_service.AcceptRepositoryTransfer(ctx, ctx.Doer, ctx.Repo.Repository, repoTransfer); err != nil {
 			return err
 		}
-		ctx.Flash.Success(ctx.Tr("repo.settings.transfer.success"))
+		if repoTransfer.Accepted {
+			ctx.Flash.Success(ctx.Tr("repo.settings.transfer.scheduled", repoTransfer.ScheduledUnix.FormatLong()))
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.settings.transfer.success"))
+		}
 	} else {
 		if err := models.CancelRepositoryTransfer(ctx.Repo.Repository); err != nil {
 			return err
@@ -384,6 +388,9 @@ func RedirectDownload(ctx *context.Context) {
 
 // Download an archive of a repository
 func Download(ctx *context.Context) {
+	if !checkDownloadToken(ctx, "") {
+		return
+	}
 	uri := ctx.Params("*")
 	aReq, err := archiver_service.NewRequest(ctx.Repo.Repository.ID, ctx.Repo.GitRepo, uri)
 	if err != nil {
@@ -451,6 +458,8 @@ func download(ctx *context.Context, archiveName string, archiver *repo_model.Rep
 		return
 	}
 
+	repo_service.RecordTraffic(ctx, ctx.Repo.Repository.ID, repo_model.TrafficKindArchive, ctx.Doer, ctx.RemoteAddr())
+
 	if setting.RepoArchive.ServeDirect {
 		// If we have a signed url (S3, object storage), redirect to this directly.
 		u, err := storage.RepoArchives.URL(rPath, downloadName)
@@ -6,6 +6,7 @@ package repo
 
 import (
 	"net/http"
+	"regexp/syntax"
 
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
@@ -29,9 +30,43 @@ func Search(ctx *context.Context) {
 	}
 	queryType := ctx.FormTrim("t")
 	isMatch := queryType == "match"
+	branch := ctx.FormTrim("branch")
 
-	total, searchResults, searchResultLanguages, err := code_indexer.PerformSearch(ctx, []int64{ctx.Repo.Repository.ID},
-		language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
+	var (
+		total                 int
+		searchResults         []*code_indexer.Result
+		searchResultLanguages []*code_indexer.SearchResultLanguages
+		err                   error
+	)
+	if code_indexer.SupportsAdvancedSearch() {
+		branches, branchErr := indexedBranches(ctx)
+		if branchErr != nil {
+			ctx.ServerError("indexedBranches", branchErr)
+			return
+		}
+		ctx.Data["Branches"] = branches
+		ctx.Data["Branch"] = branch
+		ctx.Data["SupportsAdvancedSearch"] = true
+
+		total, searchResults, searchResultLanguages, err = code_indexer.PerformAdvancedSearch(ctx, &code_indexer.SearchOptions{
+			RepoIDs:  []int64{ctx.Repo.Repository.ID},
+			Branch:   branch,
+			Language: language,
+			Keyword:  keyword,
+			IsRegexp: queryType == "regexp",
+			Page:     page,
+			PageSize: setting.UI.RepoSearchPagingNum,
+		})
+		if err != nil && queryType == "regexp" {
+			if _, ok := err.(*syntax.Error); ok {
+				ctx.Data["InvalidRegexp"] = err.Error()
+				err = nil
+			}
+		}
+	} else {
+		total, searchResults, searchResultLanguages, err = code_indexer.PerformSearch(ctx, []int64{ctx.Repo.Repository.ID},
+			language, keyword, page, setting.UI.RepoSearchPagingNum, isMatch)
+	}
 	if err != nil {
 		if code_indexer.IsAvailable() {
 			ctx.ServerError("SearchResults", err)
@@ -52,7 +87,26 @@ func Search(ctx *context.Context) {
 	pager := context.NewPagination(total, setting.UI.RepoSearchPagingNum, page, 5)
 	pager.SetDefaultParams(ctx)
 	pager.AddParam(ctx, "l", "Language")
+	pager.AddParam(ctx, "branch", "Branch")
 	ctx.Data["Page"] = pager
 
 	ctx.HTML(http.StatusOK, tplSearch)
 }
+
+// indexedBranches returns the default branch followed by the other branches of the repository searchable by the code indexer
+func indexedBranches(ctx *context.Context) ([]string, error) {
+	branches := []string{ctx.Repo.Repository.DefaultBranch}
+	if len(setting.Indexer.RepoBranches) == 0 || ctx.Repo.GitRepo == nil {
+		return branches, nil
+	}
+	names, _, err := ctx.Repo.GitRepo.GetBranchNames(0, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range names {
+		if name != ctx.Repo.Repository.DefaultBranch && code_indexer.IsIndexedBranch(name) {
+			branches = append(branches, name)
+		}
+	}
+	return branches, nil
+}
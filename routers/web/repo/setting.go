@@ -19,6 +19,7 @@ import (
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
 	repo_model "code.gitea.io/gitea/models/repo"
 	unit_model "code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
@@ -32,12 +33,15 @@ import (
 	"code.gitea.io/gitea/modules/repository"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/typesniffer"
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/validation"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/routers/utils"
 	asymkey_service "code.gitea.io/gitea/services/asymkey"
+	auth_service "code.gitea.io/gitea/services/auth"
+	discussion_service "code.gitea.io/gitea/services/discussion"
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	"code.gitea.io/gitea/services/migrations"
@@ -65,6 +69,7 @@ func Settings(ctx *context.Context) {
 	ctx.Data["MirrorsEnabled"] = setting.Mirror.Enabled
 	ctx.Data["DisableNewPushMirrors"] = setting.Mirror.DisableNewPush
 	ctx.Data["DefaultMirrorInterval"] = setting.Mirror.DefaultInterval
+	ctx.Data["IsTrashEnabled"] = repo_service.IsTrashEnabled()
 
 	signing, _ := asymkey_service.SigningKey(ctx, ctx.Repo.Repository.RepoPath())
 	ctx.Data["SigningKeyAvailable"] = len(signing) > 0
@@ -93,14 +98,65 @@ func Settings(ctx *context.Context) {
 	}
 	ctx.Data["PushMirrors"] = pushMirrors
 
+	prepareVisibilityData(ctx)
+	if ctx.Written() {
+		return
+	}
+
 	ctx.HTML(http.StatusOK, tplSettingsOptions)
 }
 
+// visibilityImpact lists what becomes visible to everyone when a private repository is made public
+type visibilityImpact struct {
+	NumIssues      int
+	NumPulls       int
+	NumReleases    int64
+	NumAttachments int64
+	HasWiki        bool
+}
+
+// prepareVisibilityData sets the impact of making the repository public and its scheduled visibility change
+func prepareVisibilityData(ctx *context.Context) {
+	repo := ctx.Repo.Repository
+	if repo.IsFork {
+		return
+	}
+
+	if repo.IsPrivate {
+		numAttachments, err := repo_model.CountAttachmentsByRepoID(ctx, repo.ID)
+		if err != nil {
+			ctx.ServerError("CountAttachmentsByRepoID", err)
+			return
+		}
+		ctx.Data["VisibilityImpact"] = &visibilityImpact{
+			NumIssues:      repo.NumIssues,
+			NumPulls:       repo.NumPulls,
+			NumReleases:    int64(repo.NumReleases),
+			NumAttachments: numAttachments,
+			HasWiki:        repo.HasWiki(),
+		}
+	}
+
+	change, err := repo_model.GetScheduledVisibilityChange(ctx, repo.ID)
+	if err != nil {
+		ctx.ServerError("GetScheduledVisibilityChange", err)
+		return
+	}
+	if change != nil {
+		if err := change.LoadDoer(ctx); err != nil {
+			ctx.ServerError("LoadDoer", err)
+			return
+		}
+	}
+	ctx.Data["ScheduledVisibilityChange"] = change
+}
+
 // SettingsPost response for changes of a repository
 func SettingsPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.RepoSettingForm)
 	ctx.Data["Title"] = ctx.Tr("repo.settings")
 	ctx.Data["PageIsSettingsOptions"] = true
+	ctx.Data["IsTrashEnabled"] = repo_service.IsTrashEnabled()
 
 	repo := ctx.Repo.Repository
 
@@ -111,6 +167,17 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		// making a repository public discloses all of its content, so the name has to be typed to confirm
+		if repo.IsPrivate && !form.Private && !repo.IsFork && form.ConfirmRepoName != repo.Name {
+			prepareVisibilityData(ctx)
+			if ctx.Written() {
+				return
+			}
+			ctx.Data["Err_ConfirmRepoName"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.visibility.confirm_name_mismatch"), tplSettingsOptions, &form)
+			return
+		}
+
 		newRepoName := form.RepoName
 		// Check if repository name has been changed.
 		if repo.LowerName != strings.ToLower(newRepoName) {
@@ -154,6 +221,7 @@ func SettingsPost(ctx *context.Context) {
 		repo.Description = form.Description
 		repo.Website = form.Website
 		repo.IsTemplate = form.Template
+		repo.NoIndex = form.NoIndex
 
 		// Visibility of forked repository is forced sync with base repository.
 		if repo.IsFork {
@@ -167,16 +235,70 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		repo.IsPrivate = form.Private
-		if err := models.UpdateRepository(repo, visibilityChanged); err != nil {
+		if err := models.UpdateRepository(repo, false); err != nil {
 			ctx.ServerError("UpdateRepository", err)
 			return
 		}
+		if visibilityChanged {
+			if err := repo_service.ChangeVisibility(ctx, ctx.Doer, repo, form.Private); err != nil {
+				ctx.ServerError("ChangeVisibility", err)
+				return
+			}
+		}
 		log.Trace("Repository basic settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
+		auditSettings(ctx, "update", visibilityDetail(visibilityChanged, form.Private))
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(repo.Link() + "/settings")
 
+	case "schedule_visibility":
+		if repo.IsFork {
+			ctx.NotFound("", nil)
+			return
+		}
+		// This section doesn't require repo_name/RepoName to be set in the form, don't show it
+		// as an error on the UI for this action
+		ctx.Data["Err_RepoName"] = nil
+
+		makePrivate := !repo.IsPrivate
+		if !makePrivate && form.ConfirmRepoName != repo.Name {
+			ctx.Flash.Error(ctx.Tr("repo.settings.visibility.confirm_name_mismatch"))
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+		when, err := time.ParseInLocation("2006-01-02T15:04", form.ScheduledTime, time.Local)
+		if err != nil || !when.After(time.Now()) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.visibility.schedule_invalid"))
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+
+		if err := repo_service.ScheduleVisibilityChange(ctx, ctx.Doer, repo, makePrivate, timeutil.TimeStamp(when.Unix())); err != nil {
+			if err == repo_service.ErrForcePrivate {
+				ctx.Flash.Error(ctx.Tr("form.repository_force_private"))
+				ctx.Redirect(repo.Link() + "/settings")
+				return
+			}
+			ctx.ServerError("ScheduleVisibilityChange", err)
+			return
+		}
+		log.Trace("Visibility change of repository scheduled: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		auditSettings(ctx, "schedule_visibility", "")
+		ctx.Flash.Success(ctx.Tr("repo.settings.visibility.schedule_success"))
+		ctx.Redirect(repo.Link() + "/settings")
+
+	case "cancel_visibility_schedule":
+		ctx.Data["Err_RepoName"] = nil
+		if err := repo_model.CancelScheduledVisibilityChange(ctx, repo.ID); err != nil {
+			ctx.ServerError("CancelScheduledVisibilityChange", err)
+			return
+		}
+
+		auditSettings(ctx, "cancel_visibility_schedule", "")
+		ctx.Flash.Success(ctx.Tr("repo.settings.visibility.schedule_cancelled"))
+		ctx.Redirect(repo.Link() + "/settings")
+
 	case "mirror":
 		if !setting.Mirror.Enabled || !repo.IsMirror {
 			ctx.NotFound("", nil)
@@ -246,6 +368,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		auditSettings(ctx, "mirror", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(repo.Link() + "/settings")
 
@@ -303,6 +426,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		auditSettings(ctx, "push-mirror-remove", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(repo.Link() + "/settings")
 
@@ -358,6 +482,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		auditSettings(ctx, "push-mirror-add", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(repo.Link() + "/settings")
 
@@ -465,6 +590,15 @@ func SettingsPost(ctx *context.Context) {
 			deleteUnitTypes = append(deleteUnitTypes, unit_model.TypePackages)
 		}
 
+		if form.EnableDiscussions && !unit_model.TypeDiscussions.UnitGlobalDisabled() {
+			units = append(units, repo_model.RepoUnit{
+				RepoID: repo.ID,
+				Type:   unit_model.TypeDiscussions,
+			})
+		} else if !unit_model.TypeDiscussions.UnitGlobalDisabled() {
+			deleteUnitTypes = append(deleteUnitTypes, unit_model.TypeDiscussions)
+		}
+
 		if form.EnablePulls && !unit_model.TypePullRequests.UnitGlobalDisabled() {
 			units = append(units, repo_model.RepoUnit{
 				RepoID: repo.ID,
@@ -490,6 +624,12 @@ func SettingsPost(ctx *context.Context) {
 			ctx.ServerError("UpdateRepositoryUnits", err)
 			return
 		}
+		if form.EnableDiscussions && !unit_model.TypeDiscussions.UnitGlobalDisabled() {
+			if err := discussion_service.CreateDefaultCategories(ctx, repo); err != nil {
+				ctx.ServerError("CreateDefaultCategories", err)
+				return
+			}
+		}
 		if repoChanged {
 			if err := models.UpdateRepository(repo, false); err != nil {
 				ctx.ServerError("UpdateRepository", err)
@@ -498,6 +638,7 @@ func SettingsPost(ctx *context.Context) {
 		}
 		log.Trace("Repository advanced settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
+		auditSettings(ctx, "advanced", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
@@ -517,6 +658,20 @@ func SettingsPost(ctx *context.Context) {
 		}
 		log.Trace("Repository signing settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
+		auditSettings(ctx, "signing", "")
+		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
+
+	case "editor":
+		repo.EditorLockedFilePatterns = strings.TrimSpace(form.EditorLockedFilePatterns)
+		repo.EditorPullRequestFilePatterns = strings.TrimSpace(form.EditorPullRequestFilePatterns)
+		if err := models.UpdateRepository(repo, false); err != nil {
+			ctx.ServerError("UpdateRepository", err)
+			return
+		}
+		log.Trace("Repository editor settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
+
+		auditSettings(ctx, "editor", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
@@ -537,6 +692,7 @@ func SettingsPost(ctx *context.Context) {
 
 		log.Trace("Repository admin settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
+		auditSettings(ctx, "admin", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
@@ -592,6 +748,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 		log.Trace("Repository converted from mirror to regular: %s", repo.FullName())
+		auditSettings(ctx, "convert", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.convert_succeed"))
 		ctx.Redirect(repo.Link())
 
@@ -622,16 +779,51 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
-		if err := repo_service.ConvertForkToNormalRepository(repo); err != nil {
+		if err := repo_service.ConvertForkToNormalRepository(ctx, repo); err != nil {
 			log.Error("Unable to convert repository %-v from fork. Error: %v", repo, err)
 			ctx.ServerError("Convert Fork", err)
 			return
 		}
 
 		log.Trace("Repository converted from fork to regular: %s", repo.FullName())
+		auditSettings(ctx, "convert_fork", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.convert_fork_succeed"))
 		ctx.Redirect(repo.Link())
 
+	case "attach_fork":
+		if !ctx.Repo.IsOwner() {
+			ctx.Error(http.StatusNotFound)
+			return
+		}
+		if repo.Name != form.RepoName {
+			ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_repo_name"), tplSettingsOptions, nil)
+			return
+		}
+
+		parent := getForkParent(ctx, form.ForkParent)
+		if ctx.Written() {
+			return
+		} else if parent == nil {
+			ctx.Flash.Error(ctx.Tr("repo.settings.attach_fork_parent_not_exist"))
+			ctx.Redirect(repo.Link() + "/settings")
+			return
+		}
+
+		if err := repo_service.AttachForkToRepository(ctx, repo, parent); err != nil {
+			if repo_service.IsErrForkParentNotAllowed(err) {
+				ctx.Flash.Error(ctx.Tr("repo.settings.attach_fork_not_allowed", err.(repo_service.ErrForkParentNotAllowed).Reason))
+				ctx.Redirect(repo.Link() + "/settings")
+				return
+			}
+			ctx.ServerError("AttachForkToRepository", err)
+			return
+		}
+
+		log.Trace("Repository %s declared a fork of %s", repo.FullName(), parent.FullName())
+		auditSettings(ctx, "attach_fork", "")
+		ctx.Flash.Success(ctx.Tr("repo.settings.attach_fork_succeed", parent.FullName()))
+		ctx.Redirect(repo.Link())
+
 	case "transfer":
 		if !ctx.Repo.IsOwner() {
 			ctx.Error(http.StatusNotFound)
@@ -660,13 +852,27 @@ func SettingsPost(ctx *context.Context) {
 			}
 		}
 
+		var schedule models.RepoTransferSchedule
+		if scheduledTime := ctx.FormString("scheduled_time"); scheduledTime != "" {
+			when, err := time.ParseInLocation("2006-01-02T15:04", scheduledTime, time.Local)
+			if err != nil || !when.After(time.Now()) {
+				ctx.RenderWithErr(ctx.Tr("repo.settings.transfer_schedule_invalid"), tplSettingsOptions, nil)
+				return
+			}
+			schedule.ScheduledUnix = timeutil.TimeStamp(when.Unix())
+		}
+		if schedule.RedirectDays = ctx.FormInt("redirect_days"); schedule.RedirectDays < 0 {
+			ctx.RenderWithErr(ctx.Tr("repo.settings.transfer_redirect_days_invalid"), tplSettingsOptions, nil)
+			return
+		}
+
 		// Close the GitRepo if open
 		if ctx.Repo.GitRepo != nil {
 			ctx.Repo.GitRepo.Close()
 			ctx.Repo.GitRepo = nil
 		}
 
-		if err := repo_service.StartRepositoryTransfer(ctx.Doer, newOwner, repo, nil); err != nil {
+		if err := repo_service.StartRepositoryTransfer(ctx.Doer, newOwner, repo, nil, schedule); err != nil {
 			if repo_model.IsErrRepoAlreadyExist(err) {
 				ctx.RenderWithErr(ctx.Tr("repo.settings.new_owner_has_same_repo"), tplSettingsOptions, nil)
 			} else if models.IsErrRepoTransferInProgress(err) {
@@ -679,7 +885,12 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		log.Trace("Repository transfer process was started: %s/%s -> %s", ctx.Repo.Owner.Name, repo.Name, newOwner)
-		ctx.Flash.Success(ctx.Tr("repo.settings.transfer_started", newOwner.DisplayName()))
+		auditSettings(ctx, "transfer", "")
+		if schedule.IsScheduled() {
+			ctx.Flash.Success(ctx.Tr("repo.settings.transfer_scheduled", newOwner.DisplayName(), schedule.ScheduledUnix.FormatLong()))
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.settings.transfer_started", newOwner.DisplayName()))
+		}
 		ctx.Redirect(repo.Link() + "/settings")
 
 	case "cancel_transfer":
@@ -711,6 +922,7 @@ func SettingsPost(ctx *context.Context) {
 		}
 
 		log.Trace("Repository transfer process was cancelled: %s/%s ", ctx.Repo.Owner.Name, repo.Name)
+		auditSettings(ctx, "cancel_transfer", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.transfer_abort_success", repoTransfer.Recipient.Name))
 		ctx.Redirect(repo.Link() + "/settings")
 
@@ -729,13 +941,18 @@ func SettingsPost(ctx *context.Context) {
 			ctx.Repo.GitRepo.Close()
 		}
 
-		if err := repo_service.DeleteRepository(ctx, ctx.Doer, ctx.Repo.Repository, true); err != nil {
-			ctx.ServerError("DeleteRepository", err)
+		if err := repo_service.TrashRepository(ctx, ctx.Doer, ctx.Repo.Repository); err != nil {
+			ctx.ServerError("TrashRepository", err)
 			return
 		}
 		log.Trace("Repository deleted: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
-		ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+		if repo_service.IsTrashEnabled() {
+			auditSettings(ctx, "delete", "")
+			ctx.Flash.Success(ctx.Tr("repo.settings.trash_success", repo.TrashExpiryUnix().FormatDate()))
+		} else {
+			ctx.Flash.Success(ctx.Tr("repo.settings.deletion_success"))
+		}
 		ctx.Redirect(ctx.Repo.Owner.DashboardLink())
 
 	case "delete-wiki":
@@ -754,6 +971,7 @@ func SettingsPost(ctx *context.Context) {
 		}
 		log.Trace("Repository wiki deleted: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
+		auditSettings(ctx, "delete-wiki", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.wiki_deletion_success"))
 		ctx.Redirect(ctx.Repo.RepoLink + "/settings")
 
@@ -776,6 +994,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		auditSettings(ctx, "archive", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.archive.success"))
 
 		log.Trace("Repository was archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
@@ -794,6 +1013,7 @@ func SettingsPost(ctx *context.Context) {
 			return
 		}
 
+		auditSettings(ctx, "unarchive", "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.unarchive.success"))
 
 		log.Trace("Repository was un-archived: %s/%s", ctx.Repo.Owner.Name, repo.Name)
@@ -804,6 +1024,20 @@ func SettingsPost(ctx *context.Context) {
 	}
 }
 
+// auditSettings records a change of a section of the repository settings in the audit log
+func auditSettings(ctx *context.Context, section, detail string) {
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionSettingsUpdate, section, detail)
+}
+
+func visibilityDetail(changed, private bool) string {
+	if !changed {
+		return ""
+	} else if private {
+		return "visibility: private"
+	}
+	return "visibility: public"
+}
+
 func handleSettingRemoteAddrError(ctx *context.Context, err error, form *forms.RepoSettingForm) {
 	if models.IsErrInvalidCloneAddr(err) {
 		addrErr := err.(*models.ErrInvalidCloneAddr)
@@ -846,6 +1080,17 @@ func Collaboration(ctx *context.Context) {
 		return
 	}
 	ctx.Data["Teams"] = teams
+
+	teamRepos, err := organization.FindExpiringTeamRepos(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("FindExpiringTeamRepos", err)
+		return
+	}
+	teamExpiries := make(map[int64]timeutil.TimeStamp, len(teamRepos))
+	for _, teamRepo := range teamRepos {
+		teamExpiries[teamRepo.TeamID] = teamRepo.ExpiresUnix
+	}
+	ctx.Data["TeamExpiries"] = teamExpiries
 	ctx.Data["Repo"] = ctx.Repo.Repository
 	ctx.Data["OrgID"] = ctx.Repo.Repository.OwnerID
 	ctx.Data["OrgName"] = ctx.Repo.Repository.OwnerName
@@ -855,6 +1100,26 @@ func Collaboration(ctx *context.Context) {
 	ctx.HTML(http.StatusOK, tplCollaboration)
 }
 
+// parseAccessExpiry parses the optional expiry date of a temporary access, the access lasts until the end of that day.
+// An error is flashed if the date is invalid or not in the future.
+func parseAccessExpiry(ctx *context.Context) (timeutil.TimeStamp, bool) {
+	expires := ctx.FormTrim("expires")
+	if len(expires) == 0 {
+		return 0, true
+	}
+	date, err := time.ParseInLocation("2006-01-02", expires, time.Local)
+	if err != nil {
+		ctx.Flash.Error(ctx.Tr("repo.settings.collaboration.expiry_invalid"))
+		return 0, false
+	}
+	date = time.Date(date.Year(), date.Month(), date.Day(), 23, 59, 59, 0, date.Location())
+	if !date.After(time.Now()) {
+		ctx.Flash.Error(ctx.Tr("repo.settings.collaboration.expiry_past"))
+		return 0, false
+	}
+	return timeutil.TimeStamp(date.Unix()), true
+}
+
 // CollaborationPost response for actions for a collaboration of a repository
 func CollaborationPost(ctx *context.Context) {
 	name := utils.RemoveUsernameParameterSuffix(strings.ToLower(ctx.FormString("collaborator")))
@@ -893,11 +1158,26 @@ func CollaborationPost(ctx *context.Context) {
 		return
 	}
 
+	expires, ok := parseAccessExpiry(ctx)
+	if !ok {
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/collaboration")
+		return
+	}
+
 	if err = models.AddCollaborator(ctx.Repo.Repository, u); err != nil {
 		ctx.ServerError("AddCollaborator", err)
 		return
 	}
 
+	if expires > 0 {
+		if err = repo_model.SetCollaborationExpiry(ctx, ctx.Repo.Repository.ID, u.ID, expires, ctx.Doer.ID); err != nil {
+			ctx.ServerError("SetCollaborationExpiry", err)
+			return
+		}
+	}
+
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionCollaboratorAdd, u.Name, "")
+
 	if setting.Service.EnableNotifyMail {
 		mailer.SendCollaboratorMail(u, ctx.Doer, ctx.Repo.Repository)
 	}
@@ -908,19 +1188,25 @@ func CollaborationPost(ctx *context.Context) {
 
 // ChangeCollaborationAccessMode response for changing access of a collaboration
 func ChangeCollaborationAccessMode(ctx *context.Context) {
+	mode := perm.AccessMode(ctx.FormInt("mode"))
 	if err := repo_model.ChangeCollaborationAccessMode(
 		ctx.Repo.Repository,
 		ctx.FormInt64("uid"),
-		perm.AccessMode(ctx.FormInt("mode"))); err != nil {
+		mode); err != nil {
 		log.Error("ChangeCollaborationAccessMode: %v", err)
+	} else {
+		repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionCollaboratorUpdate,
+			collaboratorName(ctx, ctx.FormInt64("uid")), "access: "+mode.String())
 	}
 }
 
 // DeleteCollaboration delete a collaboration for a repository
 func DeleteCollaboration(ctx *context.Context) {
+	name := collaboratorName(ctx, ctx.FormInt64("id"))
 	if err := models.DeleteCollaboration(ctx.Repo.Repository, ctx.FormInt64("id")); err != nil {
 		ctx.Flash.Error("DeleteCollaboration: " + err.Error())
 	} else {
+		repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionCollaboratorRemove, name, "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_collaborator_success"))
 	}
 
@@ -929,6 +1215,15 @@ func DeleteCollaboration(ctx *context.Context) {
 	})
 }
 
+// collaboratorName returns the name of a collaborator for the audit log
+func collaboratorName(ctx *context.Context, uid int64) string {
+	u, err := user_model.GetUserByIDCtx(ctx, uid)
+	if err != nil {
+		return strconv.FormatInt(uid, 10)
+	}
+	return u.Name
+}
+
 // AddTeamPost response for adding a team to a repository
 func AddTeamPost(ctx *context.Context) {
 	if !ctx.Repo.Owner.RepoAdminChangeTeamAccess && !ctx.Repo.IsOwner() {
@@ -966,11 +1261,29 @@ func AddTeamPost(ctx *context.Context) {
 		return
 	}
 
+	expires, ok := parseAccessExpiry(ctx)
+	if !ok {
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/collaboration")
+		return
+	}
+	if expires > 0 && team.IncludesAllRepositories {
+		ctx.Flash.Error(ctx.Tr("repo.settings.collaboration.expiry_all_repositories"))
+		ctx.Redirect(ctx.Repo.RepoLink + "/settings/collaboration")
+		return
+	}
+
 	if err = models.AddRepository(team, ctx.Repo.Repository); err != nil {
 		ctx.ServerError("team.AddRepository", err)
 		return
 	}
 
+	if expires > 0 {
+		if err = organization.SetTeamRepoExpiry(ctx, team.ID, ctx.Repo.Repository.ID, expires, ctx.Doer.ID); err != nil {
+			ctx.ServerError("SetTeamRepoExpiry", err)
+			return
+		}
+	}
+
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_team_success"))
 	ctx.Redirect(ctx.Repo.RepoLink + "/settings/collaboration")
 }
@@ -1104,7 +1417,13 @@ func DeployKeysPost(ctx *context.Context) {
 		return
 	}
 
-	key, err := asymkey_model.AddDeployKey(ctx.Repo.Repository.ID, form.Title, content, !form.IsWritable)
+	if err := auth_service.ValidateCIDRList(form.AllowedCIDRs); err != nil {
+		ctx.Data["Err_AllowedCIDRs"] = true
+		ctx.RenderWithErr(ctx.Tr("settings.allowed_cidrs_invalid", err.Error()), tplDeployKeys, &form)
+		return
+	}
+
+	key, err := asymkey_model.AddDeployKey(ctx.Repo.Repository.ID, form.Title, content, !form.IsWritable, strings.TrimSpace(form.AllowedCIDRs))
 	if err != nil {
 		ctx.Data["HasError"] = true
 		switch {
@@ -1127,15 +1446,24 @@ func DeployKeysPost(ctx *context.Context) {
 	}
 
 	log.Trace("Deploy key added: %d", ctx.Repo.Repository.ID)
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionDeployKeyAdd, key.Name, key.Fingerprint)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_key_success", key.Name))
 	ctx.Redirect(ctx.Repo.RepoLink + "/settings/keys")
 }
 
 // DeleteDeployKey response for deleting a deploy key
 func DeleteDeployKey(ctx *context.Context) {
-	if err := asymkey_service.DeleteDeployKey(ctx.Doer, ctx.FormInt64("id")); err != nil {
+	key, err := asymkey_model.GetDeployKeyByID(ctx, ctx.FormInt64("id"))
+	if err == nil && key.RepoID != ctx.Repo.Repository.ID {
+		err = asymkey_model.ErrDeployKeyNotExist{ID: key.ID, RepoID: ctx.Repo.Repository.ID}
+	}
+	if err == nil {
+		err = asymkey_service.DeleteDeployKey(ctx.Doer, key.ID)
+	}
+	if err != nil {
 		ctx.Flash.Error("DeleteDeployKey: " + err.Error())
 	} else {
+		repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionDeployKeyRemove, key.Name, key.Fingerprint)
 		ctx.Flash.Success(ctx.Tr("repo.settings.deploy_key_deletion_success"))
 	}
 
@@ -1222,3 +1550,27 @@ func selectPushMirrorByForm(form *forms.RepoSettingForm, repo *repo_model.Reposi
 
 	return nil, fmt.Errorf("PushMirror[%v] not associated to repository %v", id, repo)
 }
+
+// getForkParent returns the repository given as "owner/name" if the doer can read its code
+func getForkParent(ctx *context.Context, fullName string) *repo_model.Repository {
+	parts := strings.SplitN(strings.TrimSpace(fullName), "/", 2)
+	if len(parts) != 2 {
+		return nil
+	}
+	parent, err := repo_model.GetRepositoryByOwnerAndName(parts[0], parts[1])
+	if err != nil {
+		if !repo_model.IsErrRepoNotExist(err) {
+			ctx.ServerError("GetRepositoryByOwnerAndName", err)
+		}
+		return nil
+	}
+	permission, err := access_model.GetUserRepoPermission(ctx, parent, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("GetUserRepoPermission", err)
+		return nil
+	}
+	if !permission.CanRead(unit_model.TypeCode) {
+		return nil
+	}
+	return parent
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplSettingsAudit base.TplName = "repo/settings/audit"
+
+// AuditLog render the audit log of a repository
+func AuditLog(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.audit")
+	ctx.Data["PageIsSettingsAudit"] = true
+
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	action := ctx.FormTrim("action")
+
+	logs, total, err := repo_model.FindAuditLogs(ctx, &repo_model.FindAuditLogsOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.UI.FeedPagingNum,
+		},
+		RepoID: ctx.Repo.Repository.ID,
+		Action: action,
+	})
+	if err != nil {
+		ctx.ServerError("FindAuditLogs", err)
+		return
+	}
+	ctx.Data["AuditLogs"] = logs
+
+	categories := make([]string, 0, len(repo_model.AuditActions))
+	for _, a := range repo_model.AuditActions {
+		if len(categories) == 0 || categories[len(categories)-1] != a.Category() {
+			categories = append(categories, a.Category())
+		}
+	}
+	ctx.Data["AuditCategories"] = categories
+	ctx.Data["Action"] = action
+
+	pager := context.NewPagination(int(total), setting.UI.FeedPagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	pager.AddParam(ctx, "action", "Action")
+	ctx.Data["Page"] = pager
+
+	ctx.HTML(http.StatusOK, tplSettingsAudit)
+}
@@ -0,0 +1,111 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+const tplDeployTokens base.TplName = "repo/settings/deploy_tokens"
+
+func loadDeployTokensData(ctx *context.Context) bool {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.deploy_tokens")
+	ctx.Data["PageIsSettingsDeployTokens"] = true
+	ctx.Data["DeployTokenScopes"] = repo_model.DeployTokenScopes
+
+	tokens, err := repo_model.FindDeployTokens(ctx, ctx.Repo.Repository.ID)
+	if err != nil {
+		ctx.ServerError("FindDeployTokens", err)
+		return false
+	}
+	ctx.Data["DeployTokens"] = tokens
+	return true
+}
+
+// DeployTokens render the deploy tokens of a repository
+func DeployTokens(ctx *context.Context) {
+	if !loadDeployTokensData(ctx) {
+		return
+	}
+	ctx.HTML(http.StatusOK, tplDeployTokens)
+}
+
+// DeployTokensPost response for creating a deploy token of a repository
+func DeployTokensPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewDeployTokenForm)
+	if !loadDeployTokensData(ctx) {
+		return
+	}
+
+	if ctx.HasError() {
+		ctx.HTML(http.StatusOK, tplDeployTokens)
+		return
+	}
+
+	t := &repo_model.DeployToken{
+		RepoID:    ctx.Repo.Repository.ID,
+		Name:      form.Name,
+		CreatorID: ctx.Doer.ID,
+	}
+	if len(form.Expires) > 0 {
+		expires, err := time.ParseInLocation("2006-01-02", form.Expires, time.Local)
+		if err != nil {
+			ctx.Data["Err_Expires"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.deploy_token_invalid_expiry"), tplDeployTokens, form)
+			return
+		}
+		t.ExpiresUnix = timeutil.TimeStamp(time.Date(expires.Year(), expires.Month(), expires.Day(), 23, 59, 59, 0, expires.Location()).Unix())
+	}
+
+	if err := t.SetScopes(form.Scopes); err != nil {
+		ctx.Data["Err_Scopes"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.deploy_token_scope_required"), tplDeployTokens, form)
+		return
+	}
+
+	if err := repo_model.NewDeployToken(ctx, t); err != nil {
+		if repo_model.IsErrDeployTokenNameAlreadyUsed(err) {
+			ctx.Data["Err_Name"] = true
+			ctx.RenderWithErr(ctx.Tr("repo.settings.deploy_token_name_used"), tplDeployTokens, form)
+			return
+		}
+		ctx.ServerError("NewDeployToken", err)
+		return
+	}
+
+	log.Trace("Deploy token added: %d", ctx.Repo.Repository.ID)
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionDeployTokenAdd, t.Name, t.Scopes)
+	ctx.Flash.Success(ctx.Tr("repo.settings.deploy_token_add_success", t.Name))
+	ctx.Flash.Info(t.Token)
+	ctx.Redirect(ctx.Repo.RepoLink + "/settings/deploy_tokens")
+}
+
+// DeleteDeployToken response for deleting a deploy token of a repository
+func DeleteDeployToken(ctx *context.Context) {
+	t, err := repo_model.GetDeployTokenByID(ctx, ctx.Repo.Repository.ID, ctx.FormInt64("id"))
+	if err == nil {
+		err = repo_model.DeleteDeployToken(ctx, ctx.Repo.Repository.ID, t.ID)
+	}
+	if err != nil {
+		ctx.Flash.Error("DeleteDeployToken: " + err.Error())
+	} else {
+		repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionDeployTokenRemove, t.Name, "")
+		ctx.Flash.Success(ctx.Tr("repo.settings.deploy_token_deletion_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": ctx.Repo.RepoLink + "/settings/deploy_tokens",
+	})
+}
@@ -39,6 +39,13 @@ func ProtectedBranch(ctx *context.Context) {
 	}
 	ctx.Data["ProtectedBranches"] = protectedBranches
 
+	orgProtectedBranches, err := models.GetOrgProtectedBranchesForRepo(ctx, ctx.Repo.Repository)
+	if err != nil {
+		ctx.ServerError("GetOrgProtectedBranchesForRepo", err)
+		return
+	}
+	ctx.Data["OrgProtectedBranches"] = orgProtectedBranches
+
 	branches := ctx.Data["Branches"].([]string)
 	leftBranches := make([]string, 0, len(branches)-len(protectedBranches))
 	for _, b := range branches {
@@ -93,6 +100,7 @@ func ProtectedBranchPost(ctx *context.Context) {
 
 		log.Trace("Repository basic settings updated: %s/%s", ctx.Repo.Owner.Name, repo.Name)
 
+		repository.AddAuditLog(ctx, ctx.Doer, repo, repo_model.AuditActionSettingsUpdate, "default_branch", "default branch: "+branch)
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_settings_success"))
 		ctx.Redirect(setting.AppSubURL + ctx.Req.URL.EscapedPath())
 	default:
@@ -119,6 +127,13 @@ func SettingsProtectedBranch(c *context.Context) {
 		}
 	}
 
+	orgRule, err := models.GetOrgProtectedBranchFor(c, c.Repo.Repository, branch)
+	if err != nil {
+		c.ServerError("GetOrgProtectedBranchFor", err)
+		return
+	}
+	c.Data["OrgRule"] = orgRule
+
 	if protectBranch == nil {
 		// No options found, create defaults.
 		protectBranch = &models.ProtectedBranch{
@@ -193,6 +208,17 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 	}
 
 	if f.Protected {
+		orgRule, err := models.GetOrgProtectedBranchFor(ctx, ctx.Repo.Repository, branch)
+		if err != nil {
+			ctx.ServerError("GetOrgProtectedBranchFor", err)
+			return
+		}
+		if orgRule != nil && !orgRule.AllowOverride {
+			ctx.Flash.Error(ctx.Tr("repo.settings.protected_branch_org_rule_no_override"))
+			ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, util.PathEscapeSegments(branch)))
+			return
+		}
+
 		if protectBranch == nil {
 			// No options found, create defaults.
 			protectBranch = &models.ProtectedBranch{
@@ -262,6 +288,10 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 		protectBranch.UnprotectedFilePatterns = f.UnprotectedFilePatterns
 		protectBranch.BlockOnOutdatedBranch = f.BlockOnOutdatedBranch
 
+		auditAction := repo_model.AuditActionBranchProtectionUpdate
+		if protectBranch.ID == 0 {
+			auditAction = repo_model.AuditActionBranchProtectionAdd
+		}
 		err = models.UpdateProtectBranch(ctx, ctx.Repo.Repository, protectBranch, models.WhitelistOptions{
 			UserIDs:          whitelistUsers,
 			TeamIDs:          whitelistTeams,
@@ -278,6 +308,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 			ctx.ServerError("CheckPrsForBaseBranch", err)
 			return
 		}
+		repository.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, auditAction, branch, "")
 		ctx.Flash.Success(ctx.Tr("repo.settings.update_protect_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches/%s", ctx.Repo.RepoLink, util.PathEscapeSegments(branch)))
 	} else {
@@ -286,6 +317,7 @@ func SettingsProtectedBranchPost(ctx *context.Context) {
 				ctx.ServerError("DeleteProtectedBranch", err)
 				return
 			}
+			repository.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionBranchProtectionRemove, branch, "")
 		}
 		ctx.Flash.Success(ctx.Tr("repo.settings.remove_protected_branch_success", branch))
 		ctx.Redirect(fmt.Sprintf("%s/settings/branches", ctx.Repo.RepoLink))
@@ -0,0 +1,83 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	release_service "code.gitea.io/gitea/services/release"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+const tplReleaseSigning base.TplName = "repo/settings/release_signing"
+
+// ReleaseSigning shows the key signing the releases of a repository
+func ReleaseSigning(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.settings.release_signing")
+	ctx.Data["PageIsSettingsReleaseSigning"] = true
+	ctx.Data["EnableReleaseSigning"] = setting.Repository.Release.EnableSigning
+
+	key, err := release_service.GetSigningKey(ctx, ctx.Repo.Repository.ID)
+	if err != nil && !asymkey_model.IsErrReleaseSigningKeyNotExist(err) {
+		ctx.ServerError("GetSigningKey", err)
+		return
+	}
+	ctx.Data["SigningKey"] = key
+	ctx.HTML(http.StatusOK, tplReleaseSigning)
+}
+
+// ReleaseSigningPost generates, imports or deletes the key signing the releases of a repository
+func ReleaseSigningPost(ctx *context.Context) {
+	repo := ctx.Repo.Repository
+	link := ctx.Repo.RepoLink + "/settings/release_signing"
+
+	var key *asymkey_model.ReleaseSigningKey
+	var err error
+	switch ctx.FormString("action") {
+	case "generate":
+		name := ctx.FormTrim("name")
+		if name == "" {
+			ctx.Flash.Error(ctx.Tr("repo.settings.release_signing_name_required"))
+			ctx.Redirect(link)
+			return
+		}
+		key, err = release_service.GenerateSigningKey(ctx, repo.ID, name, ctx.FormTrim("email"))
+	case "import":
+		key, err = release_service.ImportSigningKey(ctx, repo.ID, ctx.FormString("private_key"))
+	case "delete":
+		if err := asymkey_model.DeleteReleaseSigningKey(ctx, repo.ID); err != nil && !asymkey_model.IsErrReleaseSigningKeyNotExist(err) {
+			ctx.ServerError("DeleteReleaseSigningKey", err)
+			return
+		}
+		log.Trace("Release signing key of repository %d deleted", repo.ID)
+		repo_service.AddAuditLog(ctx, ctx.Doer, repo, repo_model.AuditActionReleaseSigningKeyRemove, "", "")
+		ctx.Flash.Success(ctx.Tr("repo.settings.release_signing_deleted"))
+		ctx.Redirect(link)
+		return
+	default:
+		ctx.NotFound("", nil)
+		return
+	}
+	if err != nil {
+		if release_service.IsErrInvalidSigningKey(err) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.release_signing_invalid_key", err.(release_service.ErrInvalidSigningKey).Reason))
+			ctx.Redirect(link)
+			return
+		}
+		ctx.ServerError("SetReleaseSigningKey", err)
+		return
+	}
+
+	log.Trace("Release signing key %s of repository %d added", key.KeyID, repo.ID)
+	repo_service.AddAuditLog(ctx, ctx.Doer, repo, repo_model.AuditActionReleaseSigningKeyAdd, key.KeyID, key.Identity)
+	ctx.Flash.Success(ctx.Tr("repo.settings.release_signing_added", key.KeyID))
+	ctx.Redirect(link)
+}
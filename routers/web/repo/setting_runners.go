@@ -0,0 +1,111 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	actions_service "code.gitea.io/gitea/services/actions"
+)
+
+const (
+	tplRunners    base.TplName = "repo/settings/runners"
+	tplOrgRunners base.TplName = "org/settings/runners"
+)
+
+type runnersCtx struct {
+	IsOrg    bool
+	OwnerID  int64
+	RepoID   int64
+	Link     string
+	Template base.TplName
+}
+
+// getRunnersCtx determines whether the runners of a repository or an organization are managed
+func getRunnersCtx(ctx *context.Context) *runnersCtx {
+	if len(ctx.Repo.RepoLink) > 0 {
+		return &runnersCtx{
+			RepoID:   ctx.Repo.Repository.ID,
+			Link:     ctx.Repo.RepoLink + "/settings/runners",
+			Template: tplRunners,
+		}
+	}
+	return &runnersCtx{
+		IsOrg:    true,
+		OwnerID:  ctx.Org.Organization.ID,
+		Link:     ctx.Org.OrgLink + "/settings/runners",
+		Template: tplOrgRunners,
+	}
+}
+
+// Runners render the runners of a repository or organization and the token to register new ones
+func Runners(ctx *context.Context) {
+	rCtx := getRunnersCtx(ctx)
+	if rCtx.IsOrg {
+		ctx.Data["Title"] = ctx.Tr("org.settings")
+		ctx.Data["PageIsOrgSettings"] = true
+	} else {
+		ctx.Data["Title"] = ctx.Tr("repo.settings.runners")
+	}
+	ctx.Data["PageIsSettingsRunners"] = true
+	ctx.Data["RunnersLink"] = rCtx.Link
+
+	runners, err := actions_model.FindRunners(ctx, rCtx.OwnerID, rCtx.RepoID)
+	if err != nil {
+		ctx.ServerError("FindRunners", err)
+		return
+	}
+	ctx.Data["Runners"] = runners
+
+	token, err := actions_model.GetOrCreateRunnerToken(ctx, rCtx.OwnerID, rCtx.RepoID)
+	if err != nil {
+		ctx.ServerError("GetOrCreateRunnerToken", err)
+		return
+	}
+	ctx.Data["RegistrationToken"] = token.Token
+	ctx.Data["RegistrationURL"] = setting.AppURL + "api/actions/runner/register"
+
+	ctx.HTML(http.StatusOK, rCtx.Template)
+}
+
+// ResetRunnerTokenPost replaces the runner registration token of a repository or organization
+func ResetRunnerTokenPost(ctx *context.Context) {
+	rCtx := getRunnersCtx(ctx)
+	if _, err := actions_model.ResetRunnerToken(ctx, rCtx.OwnerID, rCtx.RepoID); err != nil {
+		ctx.ServerError("ResetRunnerToken", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.settings.runners.reset_token_success"))
+	ctx.Redirect(rCtx.Link)
+}
+
+// DeleteRunnerPost deletes a runner of a repository or organization
+func DeleteRunnerPost(ctx *context.Context) {
+	rCtx := getRunnersCtx(ctx)
+	runner, err := actions_model.GetRunnerByID(ctx, ctx.FormInt64("id"))
+	if err != nil {
+		if !actions_model.IsErrRunnerNotExist(err) {
+			ctx.ServerError("GetRunnerByID", err)
+			return
+		}
+		ctx.Redirect(rCtx.Link)
+		return
+	}
+	if runner.OwnerID != rCtx.OwnerID || runner.RepoID != rCtx.RepoID {
+		ctx.Redirect(rCtx.Link)
+		return
+	}
+
+	if err := actions_service.DeleteRunner(ctx, runner); err != nil {
+		ctx.ServerError("DeleteRunner", err)
+		return
+	}
+	ctx.Flash.Success(ctx.Tr("repo.settings.runners.deletion_success", runner.Name))
+	ctx.Redirect(rCtx.Link)
+}
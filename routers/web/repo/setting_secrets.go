@@ -0,0 +1,157 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/models/db"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/web"
+	"code.gitea.io/gitea/services/forms"
+)
+
+const (
+	tplSecrets    base.TplName = "repo/settings/secrets"
+	tplOrgSecrets base.TplName = "org/settings/secrets"
+
+	// secretsAuditLogSize is the number of audit log entries shown on the settings page
+	secretsAuditLogSize = 20
+)
+
+type secretsCtx struct {
+	IsOrg    bool
+	Scope    secret_model.Scope
+	Link     string
+	Template base.TplName
+}
+
+// getSecretsCtx determines whether the secrets of a repository or an organization are managed
+func getSecretsCtx(ctx *context.Context) *secretsCtx {
+	if len(ctx.Repo.RepoLink) > 0 {
+		return &secretsCtx{
+			Scope:    secret_model.RepoScope(ctx.Repo.Repository.ID),
+			Link:     ctx.Repo.RepoLink + "/settings",
+			Template: tplSecrets,
+		}
+	}
+	return &secretsCtx{
+		IsOrg:    true,
+		Scope:    secret_model.OrgScope(ctx.Org.Organization.ID),
+		Link:     ctx.Org.OrgLink + "/settings",
+		Template: tplOrgSecrets,
+	}
+}
+
+// Secrets render the secrets and variables of a repository or organization
+func Secrets(ctx *context.Context) {
+	sCtx := getSecretsCtx(ctx)
+	if sCtx.IsOrg {
+		ctx.Data["Title"] = ctx.Tr("org.settings")
+		ctx.Data["PageIsOrgSettings"] = true
+	} else {
+		ctx.Data["Title"] = ctx.Tr("repo.settings.secrets")
+	}
+	ctx.Data["PageIsSettingsSecrets"] = true
+	ctx.Data["SecretsLink"] = sCtx.Link
+
+	secrets, err := secret_model.FindSecrets(ctx, sCtx.Scope, secret_model.TypeSecret)
+	if err != nil {
+		ctx.ServerError("FindSecrets", err)
+		return
+	}
+	ctx.Data["Secrets"] = secrets
+
+	variables, err := secret_model.FindSecrets(ctx, sCtx.Scope, secret_model.TypeVariable)
+	if err != nil {
+		ctx.ServerError("FindSecrets", err)
+		return
+	}
+	values := make(map[string]string, len(variables))
+	for _, variable := range variables {
+		if values[variable.Name], err = variable.Value(); err != nil {
+			ctx.ServerError("Value", err)
+			return
+		}
+	}
+	ctx.Data["Variables"] = variables
+	ctx.Data["VariableValues"] = values
+
+	logs, _, err := secret_model.FindAuditLogs(ctx, sCtx.Scope, db.ListOptions{Page: 1, PageSize: secretsAuditLogSize})
+	if err != nil {
+		ctx.ServerError("FindAuditLogs", err)
+		return
+	}
+	ctx.Data["AuditLogs"] = logs
+
+	ctx.HTML(http.StatusOK, sCtx.Template)
+}
+
+// SecretsPost response for adding or updating a secret
+func SecretsPost(ctx *context.Context) {
+	setSecret(ctx, secret_model.TypeSecret)
+}
+
+// VariablesPost response for adding or updating a variable
+func VariablesPost(ctx *context.Context) {
+	setSecret(ctx, secret_model.TypeVariable)
+}
+
+func setSecret(ctx *context.Context, tp secret_model.Type) {
+	form := web.GetForm(ctx).(*forms.SecretForm)
+	sCtx := getSecretsCtx(ctx)
+	redirect := sCtx.Link + "/secrets"
+
+	if ctx.HasError() {
+		ctx.Flash.Error(ctx.GetErrMsg())
+		ctx.Redirect(redirect)
+		return
+	}
+
+	created, err := secret_model.SetSecret(ctx, ctx.Doer.ID, sCtx.Scope, tp, form.Name, form.Value)
+	if err != nil {
+		if secret_model.IsErrInvalidName(err) {
+			ctx.Flash.Error(ctx.Tr("repo.settings.secrets.invalid_name", form.Name))
+			ctx.Redirect(redirect)
+			return
+		}
+		ctx.ServerError("SetSecret", err)
+		return
+	}
+
+	if created {
+		ctx.Flash.Success(ctx.Tr("repo.settings.secrets.add_success", tp.String(), form.Name))
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.secrets.update_success", tp.String(), form.Name))
+	}
+	ctx.Redirect(redirect)
+}
+
+// DeleteSecretPost response for deleting a secret
+func DeleteSecretPost(ctx *context.Context) {
+	deleteSecret(ctx, secret_model.TypeSecret)
+}
+
+// DeleteVariablePost response for deleting a variable
+func DeleteVariablePost(ctx *context.Context) {
+	deleteSecret(ctx, secret_model.TypeVariable)
+}
+
+func deleteSecret(ctx *context.Context, tp secret_model.Type) {
+	sCtx := getSecretsCtx(ctx)
+	name := ctx.FormString("name")
+
+	if err := secret_model.DeleteSecret(ctx, ctx.Doer.ID, sCtx.Scope, tp, name); err != nil {
+		if !secret_model.IsErrSecretNotExist(err) {
+			ctx.ServerError("DeleteSecret", err)
+			return
+		}
+	} else {
+		ctx.Flash.Success(ctx.Tr("repo.settings.secrets.deletion_success", tp.String(), name))
+	}
+	ctx.Redirect(sCtx.Link + "/secrets")
+}
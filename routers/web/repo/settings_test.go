@@ -8,6 +8,7 @@ import (
 	"net/http"
 	"os"
 	"testing"
+	"time"
 
 	"code.gitea.io/gitea/models"
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
@@ -221,6 +222,45 @@ func TestCollaborationPost_NonExistentUser(t *testing.T) {
 	assert.NotEmpty(t, ctx.Flash.ErrorMsg)
 }
 
+func TestCollaborationPost_Expiry(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1/settings/collaboration")
+	test.LoadUser(t, ctx, 2)
+	test.LoadRepo(t, ctx, 1)
+
+	expires := time.Now().AddDate(0, 0, 7)
+	ctx.Req.Form.Set("collaborator", "user4")
+	ctx.Req.Form.Set("expires", expires.Format("2006-01-02"))
+
+	CollaborationPost(ctx)
+
+	assert.EqualValues(t, http.StatusSeeOther, ctx.Resp.Status())
+	collaboration, err := repo_model.GetCollaboration(ctx, 1, 4)
+	assert.NoError(t, err)
+	if assert.NotNil(t, collaboration) {
+		assert.Equal(t, expires.Format("2006-01-02"), collaboration.ExpiresUnix.AsTime().Format("2006-01-02"))
+		assert.EqualValues(t, 2, collaboration.GrantedByID)
+	}
+}
+
+func TestCollaborationPost_ExpiryInPast(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1/settings/collaboration")
+	test.LoadUser(t, ctx, 2)
+	test.LoadRepo(t, ctx, 1)
+
+	ctx.Req.Form.Set("collaborator", "user4")
+	ctx.Req.Form.Set("expires", time.Now().AddDate(0, 0, -1).Format("2006-01-02"))
+
+	CollaborationPost(ctx)
+
+	assert.EqualValues(t, http.StatusSeeOther, ctx.Resp.Status())
+	assert.NotEmpty(t, ctx.Flash.ErrorMsg)
+	exists, err := repo_model.IsCollaborator(ctx, 1, 4)
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
+
 func TestAddTeamPost(t *testing.T) {
 	unittest.PrepareTestEnv(t)
 	ctx := test.MockContext(t, "org26/repo43")
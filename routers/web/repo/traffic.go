@@ -0,0 +1,42 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repo
+
+import (
+	"net/http"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+const tplTraffic base.TplName = "repo/traffic"
+
+// Traffic render the clones, fetches and, if enabled, downloads of a repository over the last 14 days
+func Traffic(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("repo.traffic")
+	ctx.Data["PageIsActivity"] = true
+	ctx.Data["TrafficDays"] = repo_model.TrafficDays
+
+	kinds := []repo_model.TrafficKind{repo_model.TrafficKindClone, repo_model.TrafficKindFetch}
+	if setting.Repository.EnableDownloadStatistics {
+		kinds = append(kinds, repo_model.TrafficKindArchive, repo_model.TrafficKindRaw)
+	}
+	ctx.Data["EnableDownloadStatistics"] = setting.Repository.EnableDownloadStatistics
+
+	traffic := make([]*repo_model.Traffic, 0, len(kinds))
+	for _, kind := range kinds {
+		t, err := repo_model.GetTraffic(ctx, ctx.Repo.Repository.ID, kind)
+		if err != nil {
+			ctx.ServerError("GetTraffic", err)
+			return
+		}
+		traffic = append(traffic, t)
+	}
+	ctx.Data["Traffic"] = traffic
+
+	ctx.HTML(http.StatusOK, tplTraffic)
+}
@@ -48,6 +48,8 @@ const (
 	tplWatchers     base.TplName = "repo/watchers"
 	tplForks        base.TplName = "repo/forks"
 	tplMigrating    base.TplName = "repo/migrate/migrating"
+
+	tplLanguageStats base.TplName = "repo/sub_menu_languages"
 )
 
 type namedBlob struct {
@@ -263,6 +265,17 @@ func findReadmeFile(ctx *context.Context, entries git.Entries, treeLink string)
 	return readmeFile, readmeTreelink
 }
 
+// setTableOfContents sets the table of contents of the sidebar of a rendered markup file
+func setTableOfContents(ctx *context.Context, headers []markup.Header) {
+	toc := markup.TableOfContents(headers, setting.Markdown.TOCDepth)
+	// a single header doesn't need to be navigated to
+	if len(toc) < 2 {
+		return
+	}
+	ctx.Data["TableOfContents"] = toc
+	ctx.Data["TableOfContentsTopLevel"] = markup.TopLevel(toc)
+}
+
 func renderReadmeFile(ctx *context.Context, readmeFile *namedBlob, readmeTreelink string) {
 	ctx.Data["RawFileLink"] = ""
 	ctx.Data["ReadmeInList"] = true
@@ -353,13 +366,15 @@ func renderReadmeFile(ctx *context.Context, readmeFile *namedBlob, readmeTreelin
 		ctx.Data["IsMarkup"] = true
 		ctx.Data["MarkupType"] = string(markupType)
 		var result strings.Builder
-		err := markup.Render(&markup.RenderContext{
+		renderCtx := &markup.RenderContext{
 			Ctx:       ctx,
 			Filename:  readmeFile.name,
 			URLPrefix: readmeTreelink,
 			Metas:     ctx.Repo.Repository.ComposeDocumentMetas(),
 			GitRepo:   ctx.Repo.GitRepo,
-		}, rd, &result)
+		}
+		err := markup.Render(renderCtx, rd, &result)
+		setTableOfContents(ctx, renderCtx.Headers)
 		if err != nil {
 			log.Error("Render failed: %v then fallback", err)
 			buf := &bytes.Buffer{}
@@ -516,17 +531,19 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 			ctx.Data["IsMarkup"] = true
 			ctx.Data["MarkupType"] = markupType
 			var result strings.Builder
-			err := markup.Render(&markup.RenderContext{
+			renderCtx := &markup.RenderContext{
 				Ctx:       ctx,
 				Filename:  blob.Name(),
 				URLPrefix: path.Dir(treeLink),
 				Metas:     ctx.Repo.Repository.ComposeDocumentMetas(),
 				GitRepo:   ctx.Repo.GitRepo,
-			}, rd, &result)
+			}
+			err := markup.Render(renderCtx, rd, &result)
 			if err != nil {
 				ctx.ServerError("Render", err)
 				return
 			}
+			setTableOfContents(ctx, renderCtx.Headers)
 			ctx.Data["EscapeStatus"], ctx.Data["FileContent"] = charset.EscapeControlString(result.String())
 		} else if readmeExist && !shouldRenderSource {
 			buf := &bytes.Buffer{}
@@ -583,6 +600,9 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 				if lfsLock != nil && lfsLock.OwnerID != ctx.Doer.ID {
 					ctx.Data["CanEditFile"] = false
 					ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.this_file_locked")
+				} else if ctx.Repo.Repository.IsEditorLockedFile(ctx.Repo.TreePath) {
+					ctx.Data["CanEditFile"] = false
+					ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.this_file_locked_by_rules")
 				} else {
 					ctx.Data["CanEditFile"] = true
 					ctx.Data["EditFileTooltip"] = ctx.Tr("repo.editor.edit_this_file")
@@ -633,6 +653,9 @@ func renderFile(ctx *context.Context, entry *git.TreeEntry, treeLink, rawLink st
 		if lfsLock != nil && lfsLock.OwnerID != ctx.Doer.ID {
 			ctx.Data["CanDeleteFile"] = false
 			ctx.Data["DeleteFileTooltip"] = ctx.Tr("repo.editor.this_file_locked")
+		} else if ctx.Repo.Repository.IsEditorLockedFile(ctx.Repo.TreePath) {
+			ctx.Data["CanDeleteFile"] = false
+			ctx.Data["DeleteFileTooltip"] = ctx.Tr("repo.editor.this_file_locked_by_rules")
 		} else {
 			ctx.Data["CanDeleteFile"] = true
 			ctx.Data["DeleteFileTooltip"] = ctx.Tr("repo.editor.delete_this_file")
@@ -861,13 +884,20 @@ func renderDirectoryFiles(ctx *context.Context, timeout time.Duration) git.Entri
 }
 
 func renderLanguageStats(ctx *context.Context) {
-	langs, err := repo_model.GetTopLanguageStats(ctx.Repo.Repository, 5)
+	repo := ctx.Repo.Repository
+	fragment, err := ctx.RenderFragment(tplLanguageStats, fmt.Sprintf("repo_%d.%d", repo.ID, cache.FragmentVersion(cache.FragmentRepo, repo.ID)), func() (map[string]interface{}, error) {
+		langs, err := repo_model.GetTopLanguageStats(repo, 5)
+		if err != nil {
+			return nil, fmt.Errorf("GetTopLanguageStats: %v", err)
+		}
+		return map[string]interface{}{"LanguageStats": langs}, nil
+	})
 	if err != nil {
-		ctx.ServerError("Repo.GetTopLanguageStats", err)
+		ctx.ServerError("RenderFragment", err)
 		return
 	}
 
-	ctx.Data["LanguageStats"] = langs
+	ctx.Data["LanguageStatsFragment"] = fragment
 }
 
 func renderRepoTopics(ctx *context.Context) {
@@ -11,9 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"path"
+	"strconv"
 	"strings"
 
+	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/base"
@@ -26,6 +29,7 @@ import (
 	"code.gitea.io/gitea/modules/util"
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
+	repo_service "code.gitea.io/gitea/services/repository"
 	webhook_service "code.gitea.io/gitea/services/webhook"
 )
 
@@ -76,6 +80,7 @@ func getOrgRepoCtx(ctx *context.Context) (*orgRepoCtx, error) {
 	}
 
 	if len(ctx.Org.OrgLink) > 0 {
+		ctx.Data["IsOrgWebhook"] = true
 		return &orgRepoCtx{
 			OrgID:       ctx.Org.Organization.ID,
 			Link:        path.Join(ctx.Org.OrgLink, "settings/hooks"),
@@ -96,6 +101,11 @@ func getOrgRepoCtx(ctx *context.Context) (*orgRepoCtx, error) {
 		}
 
 		// Must be system webhooks instead
+		payloadVersions := make([]int, 0, webhook.LatestPayloadVersion)
+		for v := 1; v <= webhook.LatestPayloadVersion; v++ {
+			payloadVersions = append(payloadVersions, v)
+		}
+		ctx.Data["PayloadVersions"] = payloadVersions
 		return &orgRepoCtx{
 			IsAdmin:         true,
 			IsSystemWebhook: true,
@@ -156,6 +166,11 @@ func WebhooksNew(ctx *context.Context) {
 
 // ParseHookEvent convert web form content to webhook.HookEvent
 func ParseHookEvent(form forms.WebhookForm) *webhook.HookEvent {
+	payloadVersion := form.PayloadVersion
+	if !webhook.IsValidPayloadVersion(payloadVersion) {
+		// only the supported versions can be selected, fall back to the latest one
+		payloadVersion = 0
+	}
 	return &webhook.HookEvent{
 		PushOnly:       form.PushOnly(),
 		SendEverything: form.SendEverything(),
@@ -178,13 +193,36 @@ func ParseHookEvent(form forms.WebhookForm) *webhook.HookEvent {
 			PullRequestComment:   form.PullRequestComment,
 			PullRequestReview:    form.PullRequestReview,
 			PullRequestSync:      form.PullRequestSync,
+			PreviewEnvironment:   form.PreviewEnvironment,
 			Repository:           form.Repository,
 			Package:              form.Package,
 		},
-		BranchFilter: form.BranchFilter,
+		BranchFilter:   form.BranchFilter,
+		PayloadFilter:  strings.TrimSpace(form.PayloadFilter),
+		RepoFilter:     strings.TrimSpace(form.RepoFilter),
+		PayloadVersion: payloadVersion,
 	}
 }
 
+// setClientCert sets the client certificate of the webhook from the form, an empty key keeps the key
+// of an unchanged certificate. If the certificate is invalid, the form is rendered again with an error.
+func setClientCert(ctx *context.Context, tpl base.TplName, form interface{}, w *webhook.Webhook, secretForm forms.WebhookSecretForm) bool {
+	cert := strings.TrimSpace(secretForm.ClientCert)
+	key := strings.TrimSpace(secretForm.ClientKey)
+	if cert != "" && key == "" && cert == w.ClientCert {
+		key = w.ClientKey
+	}
+	if err := webhook_service.CheckClientCert(cert, key); err != nil {
+		ctx.Data["Webhook"] = w
+		ctx.Data["Err_ClientCert"] = true
+		ctx.RenderWithErr(ctx.Tr("repo.settings.client_cert_invalid", err), tpl, form)
+		return false
+	}
+	w.ClientCert = cert
+	w.ClientKey = key
+	return true
+}
+
 // GiteaHooksNewPost response for creating Gitea webhook
 func GiteaHooksNewPost(ctx *context.Context) {
 	form := web.GetForm(ctx).(*forms.NewWebhookForm)
@@ -212,17 +250,22 @@ func GiteaHooksNewPost(ctx *context.Context) {
 	}
 
 	w := &webhook.Webhook{
-		RepoID:          orCtx.RepoID,
-		URL:             form.PayloadURL,
-		HTTPMethod:      form.HTTPMethod,
-		ContentType:     contentType,
-		Secret:          form.Secret,
-		HookEvent:       ParseHookEvent(form.WebhookForm),
-		IsActive:        form.Active,
-		Type:            webhook.GITEA,
-		OrgID:           orCtx.OrgID,
-		IsSystemWebhook: orCtx.IsSystemWebhook,
-	}
+		RepoID:             orCtx.RepoID,
+		URL:                form.PayloadURL,
+		HTTPMethod:         form.HTTPMethod,
+		ContentType:        contentType,
+		Secret:             form.Secret,
+		HookEvent:          ParseHookEvent(form.WebhookForm),
+		IsActive:           form.Active,
+		Type:               webhook.GITEA,
+		OrgID:              orCtx.OrgID,
+		IsSystemWebhook:    orCtx.IsSystemWebhook,
+		IsGitHubCompatible: form.GitHubCompatible,
+	}
+	if !setClientCert(ctx, orCtx.NewTemplate, form, w, form.WebhookSecretForm) {
+		return
+	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -231,6 +274,7 @@ func GiteaHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -277,6 +321,10 @@ func newGogsWebhookPost(ctx *context.Context, form forms.NewGogshookForm, kind w
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	if !setClientCert(ctx, orCtx.NewTemplate, &form, w, form.WebhookSecretForm) {
+		return
+	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -285,6 +333,7 @@ func newGogsWebhookPost(ctx *context.Context, form forms.NewGogshookForm, kind w
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -329,6 +378,7 @@ func DiscordHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -337,6 +387,7 @@ func DiscordHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -372,6 +423,7 @@ func DingtalkHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -380,6 +432,7 @@ func DingtalkHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -424,6 +477,7 @@ func TelegramHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -432,6 +486,7 @@ func TelegramHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -479,6 +534,7 @@ func MatrixHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -487,6 +543,7 @@ func MatrixHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -522,6 +579,7 @@ func MSTeamsHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -530,6 +588,7 @@ func MSTeamsHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -582,6 +641,7 @@ func SlackHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -590,6 +650,7 @@ func SlackHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -625,6 +686,7 @@ func FeishuHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -633,6 +695,7 @@ func FeishuHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -669,6 +732,7 @@ func WechatworkHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -677,6 +741,7 @@ func WechatworkHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -722,6 +787,7 @@ func PackagistHooksNewPost(ctx *context.Context) {
 		OrgID:           orCtx.OrgID,
 		IsSystemWebhook: orCtx.IsSystemWebhook,
 	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -730,6 +796,7 @@ func PackagistHooksNewPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookAdd)
 	ctx.Flash.Success(ctx.Tr("repo.settings.add_hook_success"))
 	ctx.Redirect(orCtx.Link)
 }
@@ -773,13 +840,70 @@ func checkWebhook(ctx *context.Context) (*orgRepoCtx, *webhook.Webhook) {
 		ctx.Data["PackagistHook"] = webhook_service.GetPackagistHook(w)
 	}
 
-	ctx.Data["History"], err = w.History(1)
+	page := ctx.FormInt("page")
+	if page <= 1 {
+		page = 1
+	}
+	status := ctx.FormString("status")
+	history, count, err := webhook.FindHookTasks(ctx, &webhook.FindHookTasksOptions{
+		ListOptions: db.ListOptions{
+			Page:     page,
+			PageSize: setting.Webhook.PagingNum,
+		},
+		HookID:     w.ID,
+		OnlyFailed: status == "failed",
+	})
 	if err != nil {
-		ctx.ServerError("History", err)
+		ctx.ServerError("FindHookTasks", err)
+		return nil, nil
+	}
+	ctx.Data["History"] = history
+	ctx.Data["DeliveryStatus"] = status
+
+	ctx.Data["NumFailedDeliveries"], err = webhook.CountFailedHookTasks(ctx, w.ID)
+	if err != nil {
+		ctx.ServerError("CountFailedHookTasks", err)
+		return nil, nil
 	}
+
+	pager := context.NewPagination(int(count), setting.Webhook.PagingNum, page, 5)
+	pager.AddParam(ctx, "status", "DeliveryStatus")
+	ctx.Data["Page"] = pager
 	return orCtx, w
 }
 
+// checkEditableWebhook is checkWebhook for changing a webhook, the locked copies of
+// organization webhook templates can only be changed by the organization.
+func checkEditableWebhook(ctx *context.Context) (*orgRepoCtx, *webhook.Webhook) {
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return nil, nil
+	}
+	if w.IsInherited() && w.IsLocked {
+		ctx.Flash.Error(ctx.Tr("repo.settings.webhook.locked"))
+		ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+		return nil, nil
+	}
+	return orCtx, w
+}
+
+// setWebhookTemplate sets whether an organization webhook is a template for the repositories of the organization
+func setWebhookTemplate(orCtx *orgRepoCtx, w *webhook.Webhook, form forms.WebhookForm) {
+	if orCtx.OrgID == 0 {
+		return
+	}
+	w.IsTemplate = form.IsTemplate
+	w.IsLocked = form.IsTemplate && form.IsLocked
+}
+
+// auditWebhook records a change of a repository webhook in the audit log, the URL is left out as it may contain a token
+func auditWebhook(ctx *context.Context, orCtx *orgRepoCtx, w *webhook.Webhook, action repo_model.AuditAction) {
+	if orCtx.RepoID == 0 {
+		return
+	}
+	repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, action, strconv.FormatInt(w.ID, 10), string(w.Type))
+}
+
 // WebHooksEdit render editing web hook page
 func WebHooksEdit(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("repo.settings.update_webhook")
@@ -802,7 +926,7 @@ func WebHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -820,10 +944,15 @@ func WebHooksEditPost(ctx *context.Context) {
 
 	w.URL = form.PayloadURL
 	w.ContentType = contentType
-	w.Secret = form.Secret
+	w.SetSecret(form.Secret, form.KeepPreviousSecret)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
 	w.HTTPMethod = form.HTTPMethod
+	w.IsGitHubCompatible = form.GitHubCompatible
+	if !setClientCert(ctx, orCtx.NewTemplate, form, w, form.WebhookSecretForm) {
+		return
+	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -832,6 +961,7 @@ func WebHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -843,7 +973,7 @@ func GogsHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -861,9 +991,13 @@ func GogsHooksEditPost(ctx *context.Context) {
 
 	w.URL = form.PayloadURL
 	w.ContentType = contentType
-	w.Secret = form.Secret
+	w.SetSecret(form.Secret, form.KeepPreviousSecret)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	if !setClientCert(ctx, orCtx.NewTemplate, form, w, form.WebhookSecretForm) {
+		return
+	}
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -872,6 +1006,7 @@ func GogsHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -883,7 +1018,7 @@ func SlackHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -915,6 +1050,7 @@ func SlackHooksEditPost(ctx *context.Context) {
 	w.Meta = string(meta)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -923,6 +1059,7 @@ func SlackHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -934,7 +1071,7 @@ func DiscordHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -958,6 +1095,7 @@ func DiscordHooksEditPost(ctx *context.Context) {
 	w.Meta = string(meta)
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -966,6 +1104,7 @@ func DiscordHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -977,7 +1116,7 @@ func DingtalkHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -991,6 +1130,7 @@ func DingtalkHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -999,6 +1139,7 @@ func DingtalkHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1010,7 +1151,7 @@ func TelegramHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -1033,6 +1174,7 @@ func TelegramHooksEditPost(ctx *context.Context) {
 	w.URL = fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage?chat_id=%s", url.PathEscape(form.BotToken), url.QueryEscape(form.ChatID))
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1041,6 +1183,7 @@ func TelegramHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1052,7 +1195,7 @@ func MatrixHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -1078,6 +1221,7 @@ func MatrixHooksEditPost(ctx *context.Context) {
 
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1086,6 +1230,7 @@ func MatrixHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1097,7 +1242,7 @@ func MSTeamsHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -1111,6 +1256,7 @@ func MSTeamsHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1119,6 +1265,7 @@ func MSTeamsHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1130,7 +1277,7 @@ func FeishuHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -1144,6 +1291,7 @@ func FeishuHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1152,6 +1300,7 @@ func FeishuHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1163,7 +1312,7 @@ func WechatworkHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -1177,6 +1326,7 @@ func WechatworkHooksEditPost(ctx *context.Context) {
 	w.URL = form.PayloadURL
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1185,6 +1335,7 @@ func WechatworkHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1196,7 +1347,7 @@ func PackagistHooksEditPost(ctx *context.Context) {
 	ctx.Data["PageIsSettingsHooks"] = true
 	ctx.Data["PageIsSettingsHooksEdit"] = true
 
-	orCtx, w := checkWebhook(ctx)
+	orCtx, w := checkEditableWebhook(ctx)
 	if ctx.Written() {
 		return
 	}
@@ -1221,6 +1372,7 @@ func PackagistHooksEditPost(ctx *context.Context) {
 	w.URL = fmt.Sprintf("https://packagist.org/api/update-package?username=%s&apiToken=%s", url.QueryEscape(form.Username), url.QueryEscape(form.APIToken))
 	w.HookEvent = ParseHookEvent(form.WebhookForm)
 	w.IsActive = form.Active
+	setWebhookTemplate(orCtx, w, form.WebhookForm)
 	if err := w.UpdateEvent(); err != nil {
 		ctx.ServerError("UpdateEvent", err)
 		return
@@ -1229,6 +1381,7 @@ func PackagistHooksEditPost(ctx *context.Context) {
 		return
 	}
 
+	auditWebhook(ctx, orCtx, w, repo_model.AuditActionWebhookUpdate)
 	ctx.Flash.Success(ctx.Tr("repo.settings.update_hook_success"))
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
@@ -1312,11 +1465,33 @@ func ReplayWebhook(ctx *context.Context) {
 	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
 }
 
+// RedeliverFailedWebhookTasks attempts all failed deliveries of a webhook again
+func RedeliverFailedWebhookTasks(ctx *context.Context) {
+	orCtx, w := checkWebhook(ctx)
+	if ctx.Written() {
+		return
+	}
+
+	if err := webhook_service.RedeliverFailedHookTasks(ctx, w); err != nil {
+		ctx.ServerError("RedeliverFailedHookTasks", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.webhook.redeliver_failed.success"))
+	ctx.Redirect(fmt.Sprintf("%s/%d", orCtx.Link, w.ID))
+}
+
 // DeleteWebhook delete a webhook
 func DeleteWebhook(ctx *context.Context) {
-	if err := webhook.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, ctx.FormInt64("id")); err != nil {
+	w, err := webhook.GetWebhookByRepoID(ctx.Repo.Repository.ID, ctx.FormInt64("id"))
+	if err != nil {
+		ctx.Flash.Error("GetWebhookByRepoID: " + err.Error())
+	} else if w.IsInherited() && w.IsLocked {
+		ctx.Flash.Error(ctx.Tr("repo.settings.webhook.locked"))
+	} else if err := webhook.DeleteWebhookByRepoID(ctx.Repo.Repository.ID, w.ID); err != nil {
 		ctx.Flash.Error("DeleteWebhookByRepoID: " + err.Error())
 	} else {
+		repo_service.AddAuditLog(ctx, ctx.Doer, ctx.Repo.Repository, repo_model.AuditActionWebhookRemove, strconv.FormatInt(w.ID, 10), string(w.Type))
 		ctx.Flash.Success(ctx.Tr("repo.settings.webhook_deletion_success"))
 	}
 
@@ -0,0 +1,24 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package web
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+type terraformServices struct {
+	ModulesV1 string `json:"modules.v1"`
+}
+
+// TerraformServiceDiscovery points Terraform to the module registry of the package registry
+// https://developer.hashicorp.com/terraform/internals/remote-service-discovery
+func TerraformServiceDiscovery(ctx *context.Context) {
+	ctx.JSON(http.StatusOK, &terraformServices{
+		ModulesV1: setting.AppSubURL + "/api/packages/terraform/modules/v1/",
+	})
+}
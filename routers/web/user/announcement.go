@@ -0,0 +1,63 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	goctx "context"
+	"net/http"
+	"strings"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// GetActiveAnnouncements is the middleware that sets the announcements shown as banners in the context,
+// these are the announcements of the instance and of the organization of the page
+func GetActiveAnnouncements(c *context.Context) {
+	if strings.HasPrefix(c.Req.URL.Path, "/api") {
+		return
+	}
+
+	c.Data["ActiveAnnouncements"] = func() []*admin_model.Announcement {
+		var orgID, userID int64
+		if c.Org.Organization != nil {
+			orgID = c.Org.Organization.ID
+		} else if c.Repo.Repository != nil && c.Repo.Owner != nil && c.Repo.Owner.IsOrganization() {
+			orgID = c.Repo.Owner.ID
+		}
+		if c.IsSigned {
+			userID = c.Doer.ID
+		}
+
+		announcements, err := admin_model.GetActiveAnnouncements(c, orgID, userID)
+		if err != nil {
+			if err != goctx.Canceled {
+				log.Error("Unable to GetActiveAnnouncements for org %d: %v", orgID, err)
+			}
+			return nil
+		}
+		return announcements
+	}
+}
+
+// DismissAnnouncement hides a dismissible announcement for the signed in user
+func DismissAnnouncement(c *context.Context) {
+	announcement, err := admin_model.GetAnnouncementByID(c, c.ParamsInt64(":id"))
+	if err != nil {
+		c.NotFoundOrServerError("GetAnnouncementByID", admin_model.IsErrAnnouncementNotExist, err)
+		return
+	}
+	if !announcement.Dismissible {
+		c.Error(http.StatusBadRequest)
+		return
+	}
+
+	if err := admin_model.DismissAnnouncement(c, announcement.ID, c.Doer.ID); err != nil {
+		c.ServerError("DismissAnnouncement", err)
+		return
+	}
+	c.RedirectToFirst(c.FormString("redirect_to"))
+}
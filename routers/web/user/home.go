@@ -8,6 +8,7 @@ package user
 import (
 	"bytes"
 	"fmt"
+	"html/template"
 	"net/http"
 	"regexp"
 	"sort"
@@ -23,6 +24,7 @@ import (
 	"code.gitea.io/gitea/models/unit"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/cache"
 	"code.gitea.io/gitea/modules/context"
 	issue_indexer "code.gitea.io/gitea/modules/indexer/issues"
 	"code.gitea.io/gitea/modules/json"
@@ -44,6 +46,8 @@ const (
 	tplIssues     base.TplName = "user/dashboard/issues"
 	tplMilestones base.TplName = "user/dashboard/milestones"
 	tplProfile    base.TplName = "user/profile"
+
+	tplFeedContent base.TplName = "user/dashboard/feed_content"
 )
 
 // getDashboardContextUser finds out which context user dashboard is being viewed as .
@@ -132,7 +136,7 @@ func Dashboard(ctx *context.Context) {
 	ctx.Data["MirrorCount"] = len(mirrors)
 	ctx.Data["Mirrors"] = mirrors
 
-	ctx.Data["Feeds"], err = models.GetFeeds(ctx, models.GetFeedsOptions{
+	feeds, err := models.GetFeeds(ctx, models.GetFeedsOptions{
 		RequestedUser:   ctxUser,
 		RequestedTeam:   ctx.Org.Team,
 		Actor:           ctx.Doer,
@@ -145,10 +149,32 @@ func Dashboard(ctx *context.Context) {
 		ctx.ServerError("GetFeeds", err)
 		return
 	}
+	ctx.Data["Feeds"] = feeds
+	renderFeedContents(ctx, feeds)
+	if ctx.Written() {
+		return
+	}
 
 	ctx.HTML(http.StatusOK, tplDashboard)
 }
 
+// renderFeedContents renders the contents of the feed entries, which are cached until the repository or the user of the action change
+func renderFeedContents(ctx *context.Context, feeds models.ActionList) {
+	contents := make(map[int64]template.HTML, len(feeds))
+	for _, act := range feeds {
+		key := fmt.Sprintf("action_%d.%s.%d.%d", act.ID, act.Repo.FullName(), cache.FragmentVersion(cache.FragmentRepo, act.RepoID), act.ActUser.UpdatedUnix)
+		content, err := ctx.RenderFragment(tplFeedContent, key, func() (map[string]interface{}, error) {
+			return map[string]interface{}{"Feed": act}, nil
+		})
+		if err != nil {
+			ctx.ServerError("RenderFragment", err)
+			return
+		}
+		contents[act.ID] = content
+	}
+	ctx.Data["FeedContents"] = contents
+}
+
 // Milestones render the user milestones page
 func Milestones(ctx *context.Context) {
 	if unit.TypeIssues.UnitGlobalDisabled() && unit.TypePullRequests.UnitGlobalDisabled() {
@@ -79,6 +79,8 @@ func getNotifications(c *context.Context) {
 	switch keyword {
 	case "read":
 		status = models.NotificationStatusRead
+	case "archived":
+		status = models.NotificationStatusArchived
 	default:
 		status = models.NotificationStatusUnread
 	}
@@ -97,6 +99,9 @@ func getNotifications(c *context.Context) {
 	}
 
 	statuses := []models.NotificationStatus{status, models.NotificationStatusPinned}
+	if status == models.NotificationStatusArchived {
+		statuses = []models.NotificationStatus{status}
+	}
 	notifications, err := models.NotificationsForUser(c, c.Doer, statuses, page, perPage)
 	if err != nil {
 		c.ServerError("ErrNotificationsForUser", err)
@@ -133,6 +138,22 @@ func getNotifications(c *context.Context) {
 	notifications = notifications.Without(failures)
 	failCount += len(failures)
 
+	failures, err = notifications.LoadSecurityEvents(c)
+	if err != nil {
+		c.ServerError("LoadSecurityEvents", err)
+		return
+	}
+	notifications = notifications.Without(failures)
+	failCount += len(failures)
+
+	failures, err = notifications.LoadDiscussions(c)
+	if err != nil {
+		c.ServerError("LoadDiscussions", err)
+		return
+	}
+	notifications = notifications.Without(failures)
+	failCount += len(failures)
+
 	if failCount > 0 {
 		c.Flash.Error(fmt.Sprintf("ERROR: %d notifications were removed due to missing parts - check the logs", failCount))
 	}
@@ -161,6 +182,8 @@ func NotificationStatusPost(c *context.Context) {
 		status = models.NotificationStatusUnread
 	case "pinned":
 		status = models.NotificationStatusPinned
+	case "archived":
+		status = models.NotificationStatusArchived
 	default:
 		c.ServerError("InvalidNotificationStatus", errors.New("Invalid notification status"))
 		return
@@ -197,6 +220,16 @@ func NotificationPurgePost(c *context.Context) {
 	c.Redirect(setting.AppSubURL+"/notifications", http.StatusSeeOther)
 }
 
+// NotificationArchiveAllPost is a route for archiving all read and unread notifications of the user
+func NotificationArchiveAllPost(c *context.Context) {
+	if err := models.ArchiveAllNotifications(c.Doer); err != nil {
+		c.ServerError("ArchiveAllNotifications", err)
+		return
+	}
+
+	c.Redirect(setting.AppSubURL+"/notifications?q=archived", http.StatusSeeOther)
+}
+
 // NewAvailable returns the notification counts
 func NewAvailable(ctx *context.Context) {
 	ctx.JSON(http.StatusOK, structs.NotificationCount{New: models.CountUnread(ctx, ctx.Doer.ID)})
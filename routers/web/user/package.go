@@ -21,6 +21,8 @@ import (
 	"code.gitea.io/gitea/modules/web"
 	"code.gitea.io/gitea/services/forms"
 	packages_service "code.gitea.io/gitea/services/packages"
+
+	"github.com/dustin/go-humanize"
 )
 
 const (
@@ -28,6 +30,7 @@ const (
 	tplPackagesView       base.TplName = "package/view"
 	tplPackageVersionList base.TplName = "user/overview/package_versions"
 	tplPackagesSettings   base.TplName = "package/settings"
+	tplPackagesPolicy     base.TplName = "package/policy"
 )
 
 // ListPackages displays a list of all packages of the context user
@@ -91,6 +94,7 @@ func ListPackages(ctx *context.Context) {
 	ctx.Data["PackageDescriptors"] = pds
 	ctx.Data["Total"] = total
 	ctx.Data["RepositoryAccessMap"] = repositoryAccessMap
+	ctx.Data["CanManagePackagePolicy"] = ctx.Package.AccessMode >= perm.AccessModeAdmin || ctx.IsUserSiteAdmin()
 
 	pager := context.NewPagination(int(total), setting.UI.PackagesPagingNum, page, 5)
 	pager.AddParam(ctx, "q", "Query")
@@ -147,10 +151,10 @@ func ViewPackageVersion(ctx *context.Context) {
 		pvs   []*packages_model.PackageVersion
 		err   error
 	)
+	ctx.Data["RegistryHost"] = setting.Packages.RegistryHost
+
 	switch pd.Package.Type {
 	case packages_model.TypeContainer:
-		ctx.Data["RegistryHost"] = setting.Packages.RegistryHost
-
 		pvs, total, err = container_model.SearchImageTags(ctx, &container_model.ImageTagsSearchOptions{
 			Paginator: db.NewAbsoluteListOptions(0, 5),
 			PackageID: pd.Package.ID,
@@ -351,6 +355,76 @@ func PackageSettingsPost(ctx *context.Context) {
 	}
 }
 
+// PackagePolicySettings displays the quota and retention policy of the packages of the owner
+func PackagePolicySettings(ctx *context.Context) {
+	policy, err := packages_model.GetPolicyByOwnerID(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.ServerError("GetPolicyByOwnerID", err)
+		return
+	}
+	usage, err := packages_service.GetOwnerUsage(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.ServerError("GetOwnerUsage", err)
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("packages.policy")
+	ctx.Data["IsPackagesPage"] = true
+	ctx.Data["ContextUser"] = ctx.ContextUser
+	ctx.Data["Policy"] = policy
+	ctx.Data["Usage"] = usage
+	ctx.Data["CanEditQuota"] = ctx.IsUserSiteAdmin()
+
+	ctx.HTML(http.StatusOK, tplPackagesPolicy)
+}
+
+// PackagePolicySettingsPost updates the quota and retention policy of the packages of the owner
+func PackagePolicySettingsPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.PackagePolicyForm)
+	link := ctx.ContextUser.HTMLURL() + "/-/packages/settings"
+
+	policy, err := packages_model.GetPolicyByOwnerID(ctx, ctx.ContextUser.ID)
+	if err != nil {
+		ctx.ServerError("GetPolicyByOwnerID", err)
+		return
+	}
+
+	if form.KeepLastVersions < 0 || form.UntaggedRetentionDays < 0 {
+		ctx.Flash.Error(ctx.Tr("packages.policy.invalid"))
+		ctx.Redirect(link)
+		return
+	}
+	policy.KeepLastVersions = form.KeepLastVersions
+	policy.UntaggedRetentionDays = form.UntaggedRetentionDays
+
+	// only site administrators may change the quota
+	if ctx.IsUserSiteAdmin() {
+		var maxSize uint64
+		if form.MaxSize != "" {
+			if maxSize, err = humanize.ParseBytes(form.MaxSize); err != nil {
+				ctx.Flash.Error(ctx.Tr("packages.policy.invalid"))
+				ctx.Redirect(link)
+				return
+			}
+		}
+		if form.MaxCount < 0 {
+			ctx.Flash.Error(ctx.Tr("packages.policy.invalid"))
+			ctx.Redirect(link)
+			return
+		}
+		policy.MaxCount = form.MaxCount
+		policy.MaxSize = int64(maxSize)
+	}
+
+	if err := packages_model.SetPolicy(ctx, policy); err != nil {
+		ctx.ServerError("SetPolicy", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("packages.policy.success"))
+	ctx.Redirect(link)
+}
+
 // DownloadPackageFile serves the content of a package file
 func DownloadPackageFile(ctx *context.Context) {
 	pf, err := packages_model.GetFileForVersionByID(ctx, ctx.Package.Descriptor.Version.ID, ctx.ParamsInt64(":fileid"))
@@ -132,6 +132,10 @@ func Profile(ctx *context.Context) {
 		orderBy = db.SearchOrderByRecentUpdated
 	case "leastupdate":
 		orderBy = db.SearchOrderByLeastUpdated
+	case "recentpush":
+		orderBy = db.SearchOrderByRecentPushed
+	case "leastpush":
+		orderBy = db.SearchOrderByLeastPushed
 	case "reversealphabetically":
 		orderBy = db.SearchOrderByAlphabeticallyReverse
 	case "alphabetically":
@@ -181,7 +185,7 @@ func Profile(ctx *context.Context) {
 
 		total = ctx.ContextUser.NumFollowing
 	case "activity":
-		ctx.Data["Feeds"], err = models.GetFeeds(ctx, models.GetFeedsOptions{
+		feeds, err := models.GetFeeds(ctx, models.GetFeedsOptions{
 			RequestedUser:   ctx.ContextUser,
 			Actor:           ctx.Doer,
 			IncludePrivate:  showPrivate,
@@ -193,6 +197,11 @@ func Profile(ctx *context.Context) {
 			ctx.ServerError("GetFeeds", err)
 			return
 		}
+		ctx.Data["Feeds"] = feeds
+		renderFeedContents(ctx, feeds)
+		if ctx.Written() {
+			return
+		}
 	case "stars":
 		ctx.Data["PageIsProfileStarList"] = true
 		repos, count, err = models.SearchRepository(&models.SearchRepoOptions{
@@ -23,6 +23,7 @@ import (
 	"code.gitea.io/gitea/services/forms"
 	"code.gitea.io/gitea/services/mailer"
 	"code.gitea.io/gitea/services/user"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 const (
@@ -53,32 +54,24 @@ func AccountPost(ctx *context.Context) {
 		return
 	}
 
-	if len(form.Password) < setting.MinPasswordLength {
-		ctx.Flash.Error(ctx.Tr("auth.password_too_short", setting.MinPasswordLength))
-	} else if ctx.Doer.IsPasswordSet() && !ctx.Doer.ValidatePassword(form.OldPassword) {
+	if ctx.Doer.IsPasswordSet() && !ctx.Doer.ValidatePassword(form.OldPassword) {
 		ctx.Flash.Error(ctx.Tr("settings.password_incorrect"))
 	} else if form.Password != form.Retype {
 		ctx.Flash.Error(ctx.Tr("form.password_not_match"))
-	} else if !password.IsComplexEnough(form.Password) {
-		ctx.Flash.Error(password.BuildComplexityError(ctx))
-	} else if pwned, err := password.IsPwned(ctx, form.Password); pwned || err != nil {
-		errMsg := ctx.Tr("auth.password_pwned")
-		if err != nil {
-			log.Error(err.Error())
-			errMsg = ctx.Tr("auth.password_pwned_err")
-		}
-		ctx.Flash.Error(errMsg)
+	} else if err := password.Validate(ctx, form.Password); err != nil {
+		ctx.Flash.Error(password.PolicyErrorMessage(ctx.Locale, err))
 	} else {
 		var err error
 		if err = ctx.Doer.SetPassword(form.Password); err != nil {
 			ctx.ServerError("UpdateUser", err)
 			return
 		}
-		if err := user_model.UpdateUserCols(ctx, ctx.Doer, "salt", "passwd_hash_algo", "passwd"); err != nil {
+		if err := user_model.UpdateUserCols(ctx, ctx.Doer, "salt", "passwd_hash_algo", "passwd", "passwd_changed_unix"); err != nil {
 			ctx.ServerError("UpdateUser", err)
 			return
 		}
 		log.Trace("User password updated: %s", ctx.Doer.Name)
+		user_service.RecordSecurityEvent(ctx, ctx.Doer, user_model.SecurityEventPasswordChange, ctx.Req, "")
 		ctx.Flash.Success(ctx.Tr("settings.change_password_success"))
 	}
 
@@ -135,7 +128,7 @@ func EmailPost(ctx *context.Context) {
 				return
 			}
 			// Only fired when the primary email is inactive (Wrong state)
-			mailer.SendActivateAccountMail(ctx.Locale, ctx.Doer)
+			mailer.SendActivateAccountMail(ctx.Doer)
 		} else {
 			mailer.SendActivateEmailMail(ctx.Doer, email)
 		}
@@ -236,11 +229,15 @@ func DeleteAccount(ctx *context.Context) {
 	ctx.Data["Title"] = ctx.Tr("settings")
 	ctx.Data["PageIsSettingsAccount"] = true
 
-	if _, _, err := auth.UserSignIn(ctx.Doer.Name, ctx.FormString("password")); err != nil {
+	if _, _, err := auth.UserSignIn(ctx.Doer.Name, ctx.FormString("password"), ctx.RemoteAddr()); err != nil {
 		if user_model.IsErrUserNotExist(err) {
 			loadAccountData(ctx)
 
 			ctx.RenderWithErr(ctx.Tr("form.enterred_invalid_password"), tplSettingsAccount, nil)
+		} else if auth.IsErrSourceRestricted(err) {
+			loadAccountData(ctx)
+
+			ctx.RenderWithErr(ctx.Tr("auth.login_source_restricted"), tplSettingsAccount, nil)
 		} else {
 			ctx.ServerError("UserSignIn", err)
 		}
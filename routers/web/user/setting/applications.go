@@ -7,14 +7,18 @@ package setting
 
 import (
 	"net/http"
+	"strings"
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/auth"
+	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/web"
+	auth_service "code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/forms"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 const (
@@ -44,9 +48,16 @@ func ApplicationsPost(ctx *context.Context) {
 		return
 	}
 
+	if err := auth_service.ValidateCIDRList(form.AllowedCIDRs); err != nil {
+		ctx.Flash.Error(ctx.Tr("settings.allowed_cidrs_invalid", err.Error()))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+		return
+	}
+
 	t := &models.AccessToken{
-		UID:  ctx.Doer.ID,
-		Name: form.Name,
+		UID:          ctx.Doer.ID,
+		Name:         form.Name,
+		AllowedCIDRs: strings.TrimSpace(form.AllowedCIDRs),
 	}
 
 	exist, err := models.AccessTokenByNameExists(t)
@@ -64,6 +75,7 @@ func ApplicationsPost(ctx *context.Context) {
 		ctx.ServerError("NewAccessToken", err)
 		return
 	}
+	user_service.RecordSecurityEvent(ctx, ctx.Doer, user_model.SecurityEventTokenCreate, ctx.Req, t.Name)
 
 	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
 	ctx.Flash.Info(t.Token)
@@ -84,6 +96,59 @@ func DeleteApplication(ctx *context.Context) {
 	})
 }
 
+// DownloadTokensPost response for add user's download token
+func DownloadTokensPost(ctx *context.Context) {
+	form := web.GetForm(ctx).(*forms.NewDownloadTokenForm)
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsApplications"] = true
+
+	if ctx.HasError() {
+		loadApplicationsData(ctx)
+
+		ctx.HTML(http.StatusOK, tplSettingsApplications)
+		return
+	}
+
+	if _, err := auth.ParseDownloadTokenScopes(form.Scopes); err != nil {
+		if !auth.IsErrInvalidDownloadTokenScope(err) {
+			ctx.ServerError("ParseDownloadTokenScopes", err)
+			return
+		}
+		ctx.Flash.Error(ctx.Tr("settings.download_token_scope_invalid", err.(auth.ErrInvalidDownloadTokenScope).Scope))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+		return
+	}
+
+	t := &auth.DownloadToken{
+		UID:    ctx.Doer.ID,
+		Name:   form.Name,
+		Scopes: strings.TrimSpace(form.Scopes),
+	}
+	if err := auth.NewDownloadToken(ctx, t); err != nil {
+		ctx.ServerError("NewDownloadToken", err)
+		return
+	}
+	user_service.RecordSecurityEvent(ctx, ctx.Doer, user_model.SecurityEventTokenCreate, ctx.Req, t.Name)
+
+	ctx.Flash.Success(ctx.Tr("settings.generate_token_success"))
+	ctx.Flash.Info(t.Token)
+
+	ctx.Redirect(setting.AppSubURL + "/user/settings/applications")
+}
+
+// DeleteDownloadToken response for delete user download token
+func DeleteDownloadToken(ctx *context.Context) {
+	if err := auth.DeleteDownloadToken(ctx, ctx.FormInt64("id"), ctx.Doer.ID); err != nil {
+		ctx.Flash.Error("DeleteDownloadToken: " + err.Error())
+	} else {
+		ctx.Flash.Success(ctx.Tr("settings.delete_download_token_success"))
+	}
+
+	ctx.JSON(http.StatusOK, map[string]interface{}{
+		"redirect": setting.AppSubURL + "/user/settings/applications",
+	})
+}
+
 func loadApplicationsData(ctx *context.Context) {
 	tokens, err := models.ListAccessTokens(models.ListAccessTokensOptions{UserID: ctx.Doer.ID})
 	if err != nil {
@@ -91,6 +156,11 @@ func loadApplicationsData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["Tokens"] = tokens
+	ctx.Data["DownloadTokens"], err = auth.ListDownloadTokens(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("ListDownloadTokens", err)
+		return
+	}
 	ctx.Data["EnableOAuth2"] = setting.OAuth2.Enable
 	if setting.OAuth2.Enable {
 		ctx.Data["Applications"], err = auth.GetOAuth2ApplicationsByUserID(ctx, ctx.Doer.ID)
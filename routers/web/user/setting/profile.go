@@ -122,6 +122,7 @@ func ProfilePost(ctx *context.Context) {
 	ctx.Doer.Location = form.Location
 	ctx.Doer.Description = form.Description
 	ctx.Doer.KeepActivityPrivate = form.KeepActivityPrivate
+	ctx.Doer.NoIndex = form.NoIndex
 	ctx.Doer.Visibility = form.Visibility
 	if err := user_model.UpdateUserSetting(ctx.Doer); err != nil {
 		if _, ok := err.(user_model.ErrEmailAlreadyUsed); ok {
@@ -311,7 +312,8 @@ func Repos(ctx *context.Context) {
 				Page:     1,
 				PageSize: setting.UI.Admin.UserPagingNum,
 			},
-			LowerNames: repoNames,
+			LowerNames:     repoNames,
+			IncludeTrashed: true,
 		})
 		if err != nil {
 			ctx.ServerError("GetUserRepositories", err)
@@ -348,6 +350,21 @@ func Repos(ctx *context.Context) {
 		ctx.Data["Repos"] = repos
 	}
 	ctx.Data["Owner"] = ctxUser
+
+	ownerIDs, err := organization.GetOwnedOrgIDsByUserID(ctx, ctxUser.ID)
+	if err != nil {
+		ctx.ServerError("GetOwnedOrgIDsByUserID", err)
+		return
+	}
+	trashedRepos, _, err := repo_model.FindTrashedRepositories(ctx, &repo_model.FindTrashedRepositoriesOptions{
+		OwnerIDs: append(ownerIDs, ctxUser.ID),
+	})
+	if err != nil {
+		ctx.ServerError("FindTrashedRepositories", err)
+		return
+	}
+	ctx.Data["TrashedRepos"] = trashedRepos
+
 	pager := context.NewPagination(int(count), opts.PageSize, opts.Page, 5)
 	pager.SetDefaultParams(ctx)
 	ctx.Data["Page"] = pager
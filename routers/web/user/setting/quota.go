@@ -0,0 +1,30 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"net/http"
+
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	quota_service "code.gitea.io/gitea/services/quota"
+)
+
+const tplSettingsQuota base.TplName = "user/settings/quota"
+
+// Quota render the disk space used by the user and their quota
+func Quota(ctx *context.Context) {
+	ctx.Data["Title"] = ctx.Tr("settings.quota")
+	ctx.Data["PageIsSettingsQuota"] = true
+
+	status, err := quota_service.GetStatus(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("GetStatus", err)
+		return
+	}
+	ctx.Data["Quota"] = status
+
+	ctx.HTML(http.StatusOK, tplSettingsQuota)
+}
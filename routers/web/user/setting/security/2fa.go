@@ -79,6 +79,11 @@ func DisableTwoFactor(ctx *context.Context) {
 		return
 	}
 
+	if err = auth.DeleteRecoveryCodes(ctx, ctx.Doer.ID); err != nil {
+		ctx.ServerError("SettingsTwoFactor: Failed to DeleteRecoveryCodes", err)
+		return
+	}
+
 	ctx.Flash.Success(ctx.Tr("settings.twofa_disabled"))
 	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
 }
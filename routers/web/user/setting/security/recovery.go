@@ -0,0 +1,134 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package security
+
+import (
+	"net/http"
+	"strings"
+
+	"code.gitea.io/gitea/models/auth"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/mailer"
+	user_service "code.gitea.io/gitea/services/user"
+)
+
+const tplSettingsRecoveryCodes base.TplName = "user/settings/security/recovery_codes"
+
+// recoveryCodesSessionKey holds the recovery codes which have just been generated,
+// so they can be shown and downloaded until the user returns to the security settings
+const recoveryCodesSessionKey = "twofaRecoveryCodes"
+
+// GenerateRecoveryCodes replaces the 2FA recovery codes of the user by new ones
+func GenerateRecoveryCodes(ctx *context.Context) {
+	enrolled, err := auth.HasTwoFactorByUID(ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("HasTwoFactorByUID", err)
+		return
+	}
+	if !enrolled {
+		ctx.Flash.Error(ctx.Tr("settings.twofa_not_enrolled"))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+
+	codes, err := auth.GenerateRecoveryCodes(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("GenerateRecoveryCodes", err)
+		return
+	}
+	if err := ctx.Session.Set(recoveryCodesSessionKey, strings.Join(codes, "\n")); err != nil {
+		ctx.ServerError("Session.Set", err)
+		return
+	}
+
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security/two_factor/recovery_codes")
+}
+
+func generatedRecoveryCodes(ctx *context.Context) string {
+	codes, _ := ctx.Session.Get(recoveryCodesSessionKey).(string)
+	return codes
+}
+
+// RecoveryCodes shows the recovery codes which have just been generated
+func RecoveryCodes(ctx *context.Context) {
+	codes := generatedRecoveryCodes(ctx)
+	if codes == "" {
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+
+	ctx.Data["Title"] = ctx.Tr("settings")
+	ctx.Data["PageIsSettingsSecurity"] = true
+	ctx.Data["RecoveryCodes"] = strings.Split(codes, "\n")
+	ctx.HTML(http.StatusOK, tplSettingsRecoveryCodes)
+}
+
+// DownloadRecoveryCodes serves the recovery codes which have just been generated as a text file
+func DownloadRecoveryCodes(ctx *context.Context) {
+	codes := generatedRecoveryCodes(ctx)
+	if codes == "" {
+		ctx.NotFound("DownloadRecoveryCodes", nil)
+		return
+	}
+
+	content := ctx.Tr("settings.recovery_codes_file_header", setting.AppName, ctx.Doer.Name) + "\n\n" + codes + "\n"
+	ctx.ServeContent(setting.AppName+"-recovery-codes.txt", strings.NewReader(content))
+}
+
+// BackupEmailPost sets the backup e-mail address of the user and sends a mail to verify it
+func BackupEmailPost(ctx *context.Context) {
+	if setting.MailService == nil {
+		ctx.NotFound("BackupEmailPost", nil)
+		return
+	}
+	if setting.CacheService.Enabled && ctx.Cache.IsExist("MailResendLimit_"+ctx.Doer.LowerName) {
+		ctx.Flash.Error(ctx.Tr("auth.resent_limit_prompt"))
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+
+	email, err := user_model.SetBackupEmail(ctx, ctx.Doer.ID, ctx.FormString("email"))
+	if err != nil {
+		switch {
+		case user_model.IsErrEmailAlreadyUsed(err):
+			ctx.Flash.Error(ctx.Tr("form.email_been_used"))
+		case user_model.IsErrEmailCharIsNotSupported(err), user_model.IsErrEmailInvalid(err):
+			ctx.Flash.Error(ctx.Tr("form.email_invalid"))
+		default:
+			ctx.ServerError("SetBackupEmail", err)
+			return
+		}
+		ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+		return
+	}
+	log.Trace("Backup email set for user %s: %s", ctx.Doer.Name, email.Email)
+
+	user_service.RecordSecurityEvent(ctx, ctx.Doer, user_model.SecurityEventBackupEmailSet, ctx.Req, email.Email)
+	mailer.SendActivateBackupEmailMail(ctx.Doer, email)
+	if setting.CacheService.Enabled {
+		if err := ctx.Cache.Put("MailResendLimit_"+ctx.Doer.LowerName, ctx.Doer.LowerName, 180); err != nil {
+			log.Error("Set cache(MailResendLimit) fail: %v", err)
+		}
+	}
+
+	ctx.Flash.Info(ctx.Tr("settings.backup_email_activation_sent", email.Email, timeutil.MinutesToFriendly(setting.Service.ActiveCodeLives, ctx.Locale.Language())))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+}
+
+// DeleteBackupEmail removes the backup e-mail address of the user
+func DeleteBackupEmail(ctx *context.Context) {
+	if err := user_model.DeleteBackupEmail(ctx, ctx.Doer.ID); err != nil {
+		ctx.ServerError("DeleteBackupEmail", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("settings.backup_email_deleted"))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+}
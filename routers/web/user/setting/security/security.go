@@ -10,11 +10,13 @@ import (
 
 	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/services/auth/source/oauth2"
+	user_service "code.gitea.io/gitea/services/user"
 )
 
 const (
@@ -33,11 +35,31 @@ func Security(ctx *context.Context) {
 		return
 	}
 
+	// recovery codes are only shown once
+	_ = ctx.Session.Delete(recoveryCodesSessionKey)
+
 	loadSecurityData(ctx)
 
 	ctx.HTML(http.StatusOK, tplSettingsSecurity)
 }
 
+// SecurityNotifyPost opts the user in or out of the notifications of their security log
+func SecurityNotifyPost(ctx *context.Context) {
+	var err error
+	if ctx.FormBool("notify") {
+		err = user_model.DeleteUserSetting(ctx.Doer.ID, user_model.SettingsKeySecurityNotifyDisabled)
+	} else {
+		err = user_model.SetUserSetting(ctx.Doer.ID, user_model.SettingsKeySecurityNotifyDisabled, "true")
+	}
+	if err != nil {
+		ctx.ServerError("SetUserSetting", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("settings.security_notify_success"))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/security")
+}
+
 // DeleteAccountLink delete a single account link
 func DeleteAccountLink(ctx *context.Context) {
 	id := ctx.FormInt64("id")
@@ -64,6 +86,24 @@ func loadSecurityData(ctx *context.Context) {
 	}
 	ctx.Data["TOTPEnrolled"] = enrolled
 
+	if enrolled {
+		recoveryCodesLeft, err := auth.CountUnusedRecoveryCodes(ctx, ctx.Doer.ID)
+		if err != nil {
+			ctx.ServerError("CountUnusedRecoveryCodes", err)
+			return
+		}
+		ctx.Data["RecoveryCodesLeft"] = recoveryCodesLeft
+		ctx.Data["RecoveryCodeCount"] = auth.RecoveryCodeCount
+	}
+
+	backupEmail, err := user_model.GetBackupEmail(ctx, ctx.Doer.ID)
+	if err != nil {
+		ctx.ServerError("GetBackupEmail", err)
+		return
+	}
+	ctx.Data["BackupEmail"] = backupEmail
+	ctx.Data["EnableBackupEmail"] = setting.MailService != nil
+
 	credentials, err := auth.GetWebAuthnCredentialsByUID(ctx.Doer.ID)
 	if err != nil {
 		ctx.ServerError("GetWebAuthnCredentialsByUID", err)
@@ -124,4 +164,30 @@ func loadSecurityData(ctx *context.Context) {
 		return
 	}
 	ctx.Data["OpenIDs"] = openid
+
+	page := ctx.FormInt("page")
+	if page <= 0 {
+		page = 1
+	}
+	events, count, err := user_model.FindSecurityEvents(ctx, ctx.Doer.ID, db.ListOptions{
+		Page:     page,
+		PageSize: setting.UI.Admin.UserPagingNum,
+	})
+	if err != nil {
+		ctx.ServerError("FindSecurityEvents", err)
+		return
+	}
+	ctx.Data["SecurityEvents"] = events
+
+	notifyEnabled, err := user_service.IsSecurityNotifyEnabled(ctx.Doer)
+	if err != nil {
+		ctx.ServerError("IsSecurityNotifyEnabled", err)
+		return
+	}
+	ctx.Data["SecurityNotifyEnabled"] = notifyEnabled
+	ctx.Data["SecurityNotifyForced"] = setting.Service.ForceSecurityNotify
+
+	pager := context.NewPagination(int(count), setting.UI.Admin.UserPagingNum, page, 5)
+	pager.SetDefaultParams(ctx)
+	ctx.Data["Page"] = pager
 }
@@ -0,0 +1,43 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package setting
+
+import (
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/setting"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// RestoreTrashedRepository restores a repository of the user or of an organization owned by the user from the trash
+func RestoreTrashedRepository(ctx *context.Context) {
+	repo, err := repo_model.GetTrashedRepositoryByID(ctx, ctx.FormInt64("id"))
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			ctx.NotFound("GetTrashedRepositoryByID", err)
+		} else {
+			ctx.ServerError("GetTrashedRepositoryByID", err)
+		}
+		return
+	}
+
+	canRestore, err := models.CanUserDelete(repo, ctx.Doer)
+	if err != nil {
+		ctx.ServerError("CanUserDelete", err)
+		return
+	} else if !canRestore {
+		ctx.NotFound("CanUserDelete", nil)
+		return
+	}
+
+	if err := repo_service.RestoreTrashedRepository(ctx, ctx.Doer, repo); err != nil {
+		ctx.ServerError("RestoreTrashedRepository", err)
+		return
+	}
+
+	ctx.Flash.Success(ctx.Tr("repo.settings.restore_success", repo.FullName()))
+	ctx.Redirect(setting.AppSubURL + "/user/settings/repos")
+}
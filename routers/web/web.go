@@ -12,6 +12,7 @@ import (
 
 	"code.gitea.io/gitea/models/perm"
 	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/modules/activitypub"
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/httpcache"
@@ -87,6 +88,7 @@ func buildAuthGroup() *auth_service.Group {
 	group := auth_service.NewGroup(
 		&auth_service.OAuth2{}, // FIXME: this should be removed and only applied in download and oauth realted routers
 		&auth_service.Basic{},  // FIXME: this should be removed and only applied in download and git/lfs routers
+		&auth_service.DownloadToken{},
 		&auth_service.Session{},
 	)
 	if setting.Service.EnableReverseProxyAuth {
@@ -125,6 +127,7 @@ func Routes() *web.Route {
 	// We use r.Route here over r.Use because this prevents requests that are not for avatars having to go through this additional handler
 	routes.Route("/avatars/*", "GET, HEAD", storageHandler(setting.Avatar.Storage, "avatars", storage.Avatars))
 	routes.Route("/repo-avatars/*", "GET, HEAD", storageHandler(setting.RepoAvatar.Storage, "repo-avatars", storage.RepoAvatars))
+	routes.Route("/custom-emojis/*", "GET, HEAD", storageHandler(setting.CustomEmoji.Storage, "custom-emojis", storage.CustomEmojis))
 
 	// for health check - doeesn't need to be passed through gzip handler
 	routes.Head("/", func(w http.ResponseWriter, req *http.Request) {
@@ -208,6 +211,9 @@ func Routes() *web.Route {
 	// GetHead allows a HEAD request redirect to GET if HEAD method is not defined for that route
 	common = append(common, middleware.GetHead)
 
+	// Only site admins can change anything while the instance is in maintenance mode
+	common = append(common, maintenanceMode)
+
 	if setting.API.EnableSwagger {
 		// Note: The route moved from apiroutes because it's in fact want to render a web page
 		routes.Get("/api/swagger", append(common, misc.Swagger)...) // Render V1 by default
@@ -215,6 +221,7 @@ func Routes() *web.Route {
 
 	// TODO: These really seem like things that could be folded into Contexter or as helper functions
 	common = append(common, user.GetNotificationCount)
+	common = append(common, user.GetActiveAnnouncements)
 	common = append(common, repo.GetActiveStopwatch)
 	common = append(common, goGet)
 
@@ -287,6 +294,10 @@ func RegisterRoutes(m *web.Route) {
 			ctx.Error(http.StatusNotFound)
 			return
 		}
+		if !activitypub.IsRequestAllowed(ctx.Req) {
+			ctx.Error(http.StatusForbidden)
+			return
+		}
 	}
 
 	// FIXME: not all routes need go through same middleware.
@@ -294,15 +305,25 @@ func RegisterRoutes(m *web.Route) {
 	// Routers.
 	// for health check
 	m.Get("/", Home)
+	if setting.EnableSitemap {
+		m.Get("/sitemap.xml", ignExploreSignIn, HomeSitemap)
+	}
 	m.Group("/.well-known", func() {
 		m.Get("/openid-configuration", auth.OIDCWellKnown)
 		m.Group("", func() {
-			m.Get("/nodeinfo", NodeInfoLinks)
-			m.Get("/webfinger", WebfingerQuery)
+			if setting.Federation.NodeInfoEnabled {
+				m.Get("/nodeinfo", NodeInfoLinks)
+			}
+			if setting.Federation.WebfingerEnabled {
+				m.Get("/webfinger", WebfingerQuery)
+			}
 		}, federationEnabled)
 		m.Get("/change-password", func(w http.ResponseWriter, req *http.Request) {
 			http.Redirect(w, req, "/user/settings/account", http.StatusTemporaryRedirect)
 		})
+		if setting.Packages.Enabled {
+			m.Get("/terraform.json", TerraformServiceDiscovery)
+		}
 	})
 
 	m.Group("/explore", func() {
@@ -314,6 +335,11 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/organizations", explore.Organizations)
 		m.Get("/code", explore.Code)
 		m.Get("/topics/search", explore.TopicSearch)
+		if setting.EnableSitemap {
+			m.Get("/repos/sitemap-{idx}.xml", explore.ReposSitemap)
+			m.Get("/users/sitemap-{idx}.xml", explore.UsersSitemap)
+			m.Get("/organizations/sitemap-{idx}.xml", explore.OrganizationsSitemap)
+		}
 	}, ignExploreSignIn)
 	m.Group("/issues", func() {
 		m.Get("", user.Issues)
@@ -368,6 +394,11 @@ func RegisterRoutes(m *web.Route) {
 		// TODO manage redirection
 		m.Post("/authorize", bindIgnErr(forms.AuthorizationForm{}), auth.AuthorizeOAuth)
 	}, ignSignInAndCsrf, reqSignIn)
+	m.Group("/login/oauth/device", func() {
+		m.Get("", auth.DeviceOAuth)
+		m.Post("", bindIgnErr(forms.GrantDeviceForm{}), auth.GrantDeviceOAuth)
+	}, reqSignIn)
+	m.Post("/login/oauth/device_authorization", CorsHandler(), bindIgnErr(forms.DeviceAuthorizationForm{}), ignSignInAndCsrf, auth.DeviceAuthorizationOAuth)
 	m.Get("/login/oauth/userinfo", ignSignInAndCsrf, auth.InfoOAuth)
 	m.Post("/login/oauth/access_token", CorsHandler(), bindIgnErr(forms.AccessTokenForm{}), ignSignInAndCsrf, auth.AccessTokenOAuth)
 	m.Get("/login/oauth/keys", ignSignInAndCsrf, auth.OIDCKeys)
@@ -396,6 +427,8 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("", security.Security)
 			m.Group("/two_factor", func() {
 				m.Post("/regenerate_scratch", security.RegenerateScratchTwoFactor)
+				m.Combo("/recovery_codes").Get(security.RecoveryCodes).Post(security.GenerateRecoveryCodes)
+				m.Get("/recovery_codes/download", security.DownloadRecoveryCodes)
 				m.Post("/disable", security.DisableTwoFactor)
 				m.Get("/enroll", security.EnrollTwoFactor)
 				m.Post("/enroll", bindIgnErr(forms.TwoFactorAuthForm{}), security.EnrollTwoFactorPost)
@@ -411,6 +444,9 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/toggle_visibility", security.ToggleOpenIDVisibility)
 			}, openIDSignInEnabled)
 			m.Post("/account_link", linkAccountEnabled, security.DeleteAccountLink)
+			m.Post("/notify", security.SecurityNotifyPost)
+			m.Post("/backup_email", security.BackupEmailPost)
+			m.Post("/backup_email/delete", security.DeleteBackupEmail)
 		})
 		m.Group("/applications/oauth2", func() {
 			m.Get("/{id}", user_setting.OAuth2ApplicationShow)
@@ -423,12 +459,16 @@ func RegisterRoutes(m *web.Route) {
 		m.Combo("/applications").Get(user_setting.Applications).
 			Post(bindIgnErr(forms.NewAccessTokenForm{}), user_setting.ApplicationsPost)
 		m.Post("/applications/delete", user_setting.DeleteApplication)
+		m.Post("/applications/download_tokens", bindIgnErr(forms.NewDownloadTokenForm{}), user_setting.DownloadTokensPost)
+		m.Post("/applications/download_tokens/delete", user_setting.DeleteDownloadToken)
 		m.Combo("/keys").Get(user_setting.Keys).
 			Post(bindIgnErr(forms.AddKeyForm{}), user_setting.KeysPost)
 		m.Post("/keys/delete", user_setting.DeleteKey)
 		m.Get("/organization", user_setting.Organization)
 		m.Get("/repos", user_setting.Repos)
 		m.Post("/repos/unadopted", user_setting.AdoptOrDeleteRepository)
+		m.Post("/repos/trash/restore", user_setting.RestoreTrashedRepository)
+		m.Get("/quota", user_setting.Quota)
 	}, reqSignIn, func(ctx *context.Context) {
 		ctx.Data["PageIsUserSettings"] = true
 		ctx.Data["AllThemes"] = setting.UI.Themes
@@ -439,6 +479,7 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("/activate", auth.Activate)
 		m.Post("/activate", auth.ActivatePost)
 		m.Any("/activate_email", auth.ActivateEmail)
+		m.Get("/activate_backup_email", auth.ActivateBackupEmail)
 		m.Get("/avatar/{username}/{size}", user.AvatarByUserName)
 		m.Get("/recover_account", auth.ResetPasswd)
 		m.Post("/recover_account", auth.ResetPasswdPost)
@@ -485,6 +526,9 @@ func RegisterRoutes(m *web.Route) {
 			m.Combo("/new").Get(admin.NewUser).Post(bindIgnErr(forms.AdminCreateUserForm{}), admin.NewUserPost)
 			m.Combo("/{userid}").Get(admin.EditUser).Post(bindIgnErr(forms.AdminEditUserForm{}), admin.EditUserPost)
 			m.Post("/{userid}/delete", admin.DeleteUser)
+			m.Post("/{userid}/purge", admin.PurgeUser)
+			m.Post("/{userid}/suspend", admin.SuspendUser)
+			m.Post("/{userid}/unsuspend", admin.UnsuspendUser)
 			m.Post("/{userid}/avatar", bindIgnErr(forms.AvatarForm{}), admin.AvatarPost)
 			m.Post("/{userid}/avatar/delete", admin.DeleteAvatar)
 		})
@@ -496,14 +540,46 @@ func RegisterRoutes(m *web.Route) {
 
 		m.Group("/orgs", func() {
 			m.Get("", admin.Organizations)
+			m.Post("/purge", admin.PurgeOrganization)
 		})
 
 		m.Group("/repos", func() {
 			m.Get("", admin.Repos)
 			m.Combo("/unadopted").Get(admin.UnadoptedRepos).Post(admin.AdoptOrDeleteRepository)
+			m.Combo("/trash").Get(admin.TrashedRepos).Post(admin.RestoreOrPurgeTrashedRepository)
+			m.Combo("/maintenance").Get(admin.RepoMaintenance).Post(admin.RunRepoMaintenance)
+			m.Combo("/{id}/console").Get(admin.RepoConsole).Post(admin.RunRepoConsoleCommand)
 			m.Post("/delete", admin.DeleteRepo)
 		})
 
+		m.Group("/quotas", func() {
+			m.Get("", admin.Quotas)
+			m.Post("", admin.QuotasPost)
+			m.Post("/delete", admin.DeleteQuota)
+		})
+
+		m.Combo("/release-signing").Get(admin.ReleaseSigning).Post(admin.ReleaseSigningPost)
+		m.Combo("/maintenance-mode").Get(admin.MaintenanceMode).Post(admin.MaintenanceModePost)
+
+		m.Group("/operations", func() {
+			m.Get("", admin.Operations)
+			m.Post("/{id}/approve", admin.ApproveOperation)
+			m.Post("/{id}/cancel", admin.CancelOperation)
+		})
+
+		m.Group("/announcements", func() {
+			m.Combo("").Get(admin.Announcements).Post(bindIgnErr(forms.AnnouncementForm{}), admin.NewAnnouncementPost)
+			m.Post("/delete", admin.DeleteAnnouncement)
+			m.Combo("/{id}").Get(admin.EditAnnouncement).Post(bindIgnErr(forms.AnnouncementForm{}), admin.EditAnnouncementPost)
+		})
+
+		m.Group("/emojis", func() {
+			m.Get("", admin.Emojis)
+			m.Post("", admin.UploadEmoji)
+			m.Post("/delete", admin.DeleteEmoji)
+			m.Post("/reactions", admin.ReactionsPost)
+		})
+
 		if setting.Packages.Enabled {
 			m.Group("/packages", func() {
 				m.Get("", admin.Packages)
@@ -517,6 +593,7 @@ func RegisterRoutes(m *web.Route) {
 			m.Group("/{id}", func() {
 				m.Get("", repo.WebHooksEdit)
 				m.Post("/replay/{uuid}", repo.ReplayWebhook)
+				m.Post("/redeliver_failed", repo.RedeliverFailedWebhookTasks)
 			})
 			m.Post("/gitea/{id}", bindIgnErr(forms.NewWebhookForm{}), repo.WebHooksEditPost)
 			m.Post("/gogs/{id}", bindIgnErr(forms.NewGogshookForm{}), repo.GogsHooksEditPost)
@@ -559,6 +636,11 @@ func RegisterRoutes(m *web.Route) {
 			m.Post("/delete", admin.DeleteNotices)
 			m.Post("/empty", admin.EmptyNotices)
 		})
+
+		m.Group("/audit", func() {
+			m.Get("", admin.AuditLog)
+			m.Get("/export", admin.AuditLogExport)
+		})
 	}, adminReq)
 	// ***** END: Admin *****
 
@@ -597,6 +679,8 @@ func RegisterRoutes(m *web.Route) {
 	reqRepoIssuesOrPullsReader := context.RequireRepoReaderOr(unit.TypeIssues, unit.TypePullRequests)
 	reqRepoProjectsReader := context.RequireRepoReader(unit.TypeProjects)
 	reqRepoProjectsWriter := context.RequireRepoWriter(unit.TypeProjects)
+	reqRepoDiscussionsReader := context.RequireRepoReader(unit.TypeDiscussions)
+	reqRepoDiscussionsWriter := context.RequireRepoWriter(unit.TypeDiscussions)
 
 	reqPackageAccess := func(accessMode perm.AccessMode) func(ctx *context.Context) {
 		return func(ctx *context.Context) {
@@ -665,6 +749,8 @@ func RegisterRoutes(m *web.Route) {
 					m.Group("/{id}", func() {
 						m.Get("", repo.WebHooksEdit)
 						m.Post("/replay/{uuid}", repo.ReplayWebhook)
+						m.Post("/redeliver_failed", repo.RedeliverFailedWebhookTasks)
+						m.Post("/apply", org.ApplyTemplateWebhook)
 					})
 					m.Post("/gitea/{id}", bindIgnErr(forms.NewWebhookForm{}), repo.WebHooksEditPost)
 					m.Post("/gogs/{id}", bindIgnErr(forms.NewGogshookForm{}), repo.GogsHooksEditPost)
@@ -678,6 +764,21 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/wechatwork/{id}", bindIgnErr(forms.NewWechatWorkHookForm{}), repo.WechatworkHooksEditPost)
 				}, webhooksEnabled)
 
+				m.Group("/secrets", func() {
+					m.Get("", repo.Secrets)
+					m.Post("", bindIgnErr(forms.SecretForm{}), repo.SecretsPost)
+					m.Post("/delete", repo.DeleteSecretPost)
+				})
+				m.Group("/variables", func() {
+					m.Post("", bindIgnErr(forms.SecretForm{}), repo.VariablesPost)
+					m.Post("/delete", repo.DeleteVariablePost)
+				})
+				m.Group("/runners", func() {
+					m.Get("", repo.Runners)
+					m.Post("/reset_token", repo.ResetRunnerTokenPost)
+					m.Post("/delete", repo.DeleteRunnerPost)
+				}, repo.MustEnableActions)
+
 				m.Group("/labels", func() {
 					m.Get("", org.RetrieveLabels, org.Labels)
 					m.Post("/new", bindIgnErr(forms.CreateLabelForm{}), org.NewLabel)
@@ -686,8 +787,45 @@ func RegisterRoutes(m *web.Route) {
 					m.Post("/initialize", bindIgnErr(forms.InitializeLabelsForm{}), org.InitializeLabels)
 				})
 
+				m.Group("/branches", func() {
+					m.Get("", org.ProtectedBranches)
+					m.Combo("/new").Get(org.NewProtectedBranch).
+						Post(bindIgnErr(forms.OrgProtectBranchForm{}), org.NewProtectedBranchPost)
+					m.Post("/delete", org.DeleteProtectedBranch)
+					m.Combo("/{id}").Get(org.EditProtectedBranch).
+						Post(bindIgnErr(forms.OrgProtectBranchForm{}), org.EditProtectedBranchPost)
+				})
+
+				m.Group("/issue_intake", func() {
+					m.Get("", org.IssueIntakeRules)
+					m.Combo("/new").Get(org.NewIssueIntakeRule).
+						Post(bindIgnErr(forms.OrgIssueIntakeRuleForm{}), org.NewIssueIntakeRulePost)
+					m.Post("/delete", org.DeleteIssueIntakeRule)
+					m.Combo("/{id}").Get(org.EditIssueIntakeRule).
+						Post(bindIgnErr(forms.OrgIssueIntakeRuleForm{}), org.EditIssueIntakeRulePost)
+				})
+
+				m.Combo("/key_policy").Get(org.SettingsKeyPolicy).
+					Post(bindIgnErr(forms.OrgKeyPolicyForm{}), org.SettingsKeyPolicyPost)
+				m.Get("/quota", org.SettingsQuota)
+				m.Group("/emojis", func() {
+					m.Combo("").Get(org.SettingsEmojis).Post(org.SettingsEmojisPost)
+					m.Post("/delete", org.DeleteEmoji)
+				})
+				m.Group("/announcements", func() {
+					m.Combo("").Get(org.SettingsAnnouncements).Post(bindIgnErr(forms.AnnouncementForm{}), org.SettingsAnnouncementsPost)
+					m.Post("/delete", org.DeleteAnnouncement)
+					m.Combo("/{id}").Get(org.EditAnnouncement).Post(bindIgnErr(forms.AnnouncementForm{}), org.EditAnnouncementPost)
+				})
 				m.Route("/delete", "GET,POST", org.SettingsDelete)
 			})
+
+			m.Get("/traffic", context.RequireRepoWriter(unit.TypeCode), repo.Traffic)
+
+			m.Group("/time_report", func() {
+				m.Get("", org.TimeReport)
+				m.Get("/export", org.TimeReportExport)
+			})
 		}, context.OrgAssignment(true, true))
 	}, reqSignIn)
 	// ***** END: Organization *****
@@ -703,12 +841,17 @@ func RegisterRoutes(m *web.Route) {
 				Post(bindIgnErr(forms.CreateRepoForm{}), repo.ForkPost)
 		}, context.RepoIDAssignment(), context.UnitTypes(), reqRepoCodeReader)
 		m.Get("/search", repo.SearchRepo)
+		m.Post("/request_access", repo.MustEnableAccessRequests, repo.RequestAccessPost)
 	}, reqSignIn)
 
 	m.Group("/{username}/-", func() {
 		if setting.Packages.Enabled {
 			m.Group("/packages", func() {
 				m.Get("", user.ListPackages)
+				m.Group("/settings", func() {
+					m.Get("", user.PackagePolicySettings)
+					m.Post("", bindIgnErr(forms.PackagePolicyForm{}), user.PackagePolicySettingsPost)
+				}, reqPackageAccess(perm.AccessModeAdmin))
 				m.Group("/{type}/{name}", func() {
 					m.Get("", user.RedirectToLastVersion)
 					m.Get("/versions", user.ListPackageVersions)
@@ -745,6 +888,12 @@ func RegisterRoutes(m *web.Route) {
 				})
 			})
 
+			m.Group("/access_requests", func() {
+				m.Get("", repo.AccessRequests)
+				m.Post("/{id}/approve", repo.ApproveAccessRequestPost)
+				m.Post("/{id}/deny", repo.DenyAccessRequestPost)
+			}, repo.MustEnableAccessRequests)
+
 			m.Group("/branches", func() {
 				m.Combo("").Get(repo.ProtectedBranch).Post(repo.ProtectedBranchPost)
 				m.Combo("/*").Get(repo.SettingsProtectedBranch).
@@ -752,6 +901,21 @@ func RegisterRoutes(m *web.Route) {
 			}, repo.MustBeNotEmpty)
 			m.Post("/rename_branch", bindIgnErr(forms.RenameBranchForm{}), context.RepoMustNotBeArchived(), repo.RenameBranchPost)
 
+			m.Group("/secrets", func() {
+				m.Get("", repo.Secrets)
+				m.Post("", bindIgnErr(forms.SecretForm{}), repo.SecretsPost)
+				m.Post("/delete", repo.DeleteSecretPost)
+			})
+			m.Group("/variables", func() {
+				m.Post("", bindIgnErr(forms.SecretForm{}), repo.VariablesPost)
+				m.Post("/delete", repo.DeleteVariablePost)
+			})
+			m.Group("/runners", func() {
+				m.Get("", repo.Runners)
+				m.Post("/reset_token", repo.ResetRunnerTokenPost)
+				m.Post("/delete", repo.DeleteRunnerPost)
+			}, repo.MustEnableActions)
+
 			m.Group("/tags", func() {
 				m.Get("", repo.Tags)
 				m.Post("", bindIgnErr(forms.ProtectTagForm{}), context.RepoMustNotBeArchived(), repo.NewProtectedTagPost)
@@ -785,6 +949,7 @@ func RegisterRoutes(m *web.Route) {
 					m.Get("", repo.WebHooksEdit)
 					m.Post("/test", repo.TestWebhook)
 					m.Post("/replay/{uuid}", repo.ReplayWebhook)
+					m.Post("/redeliver_failed", repo.RedeliverFailedWebhookTasks)
 				})
 				m.Post("/gitea/{id}", bindIgnErr(forms.NewWebhookForm{}), repo.WebHooksEditPost)
 				m.Post("/gogs/{id}", bindIgnErr(forms.NewGogshookForm{}), repo.GogsHooksEditPost)
@@ -799,12 +964,22 @@ func RegisterRoutes(m *web.Route) {
 				m.Post("/packagist/{id}", bindIgnErr(forms.NewPackagistHookForm{}), repo.PackagistHooksEditPost)
 			}, webhooksEnabled)
 
+			m.Get("/audit", repo.AuditLog)
+
 			m.Group("/keys", func() {
 				m.Combo("").Get(repo.DeployKeys).
 					Post(bindIgnErr(forms.AddKeyForm{}), repo.DeployKeysPost)
 				m.Post("/delete", repo.DeleteDeployKey)
 			})
 
+			m.Combo("/release_signing").Get(repo.ReleaseSigning).Post(repo.ReleaseSigningPost)
+
+			m.Group("/deploy_tokens", func() {
+				m.Combo("").Get(repo.DeployTokens).
+					Post(bindIgnErr(forms.NewDeployTokenForm{}), repo.DeployTokensPost)
+				m.Post("/delete", repo.DeleteDeployToken)
+			})
+
 			m.Group("/lfs", func() {
 				m.Get("/", repo.LFSFiles)
 				m.Get("/show/{oid}", repo.LFSFileGet)
@@ -882,6 +1057,7 @@ func RegisterRoutes(m *web.Route) {
 			})
 			m.Group("/{index}", func() {
 				m.Post("/content-history/soft-delete", repo.SoftDeleteContentHistory)
+				m.Post("/content-history/purge", adminReq, repo.PurgeContentHistory)
 			})
 
 			m.Post("/labels", reqRepoIssuesOrPullsWriter, repo.UpdateIssueLabel)
@@ -903,6 +1079,10 @@ func RegisterRoutes(m *web.Route) {
 		m.Group("/comments/{id}", func() {
 			m.Get("/attachments", repo.GetCommentAttachments)
 		})
+		m.Group("/{type:issues|pulls}/filters", func() {
+			m.Post("", bindIgnErr(forms.SaveIssueFilterForm{}), repo.SaveIssueFilter)
+			m.Post("/{id}/delete", repo.DeleteIssueFilter)
+		}, reqRepoIssuesOrPullsReader)
 		m.Post("/markdown", bindIgnErr(structs.MarkdownOption{}), misc.Markdown)
 		m.Group("/labels", func() {
 			m.Post("/new", bindIgnErr(forms.CreateLabelForm{}), repo.NewLabel)
@@ -966,19 +1146,20 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/latest", repo.LatestRelease)
 		}, repo.MustBeNotEmpty, reqRepoReleaseReader, context.RepoRefByType(context.RepoRefTag, true))
 		m.Get("/releases/attachments/{uuid}", repo.GetAttachment, repo.MustBeNotEmpty, reqRepoReleaseReader)
+		m.Get("/releases/download-all/{vTag}", repo.MustBeNotEmpty, reqRepoReleaseReader, repo.DownloadReleaseAssets)
 		m.Group("/releases", func() {
-			m.Get("/new", repo.NewRelease)
-			m.Post("/new", bindIgnErr(forms.NewReleaseForm{}), repo.NewReleasePost)
-			m.Post("/delete", repo.DeleteRelease)
-			m.Post("/attachments", repo.UploadReleaseAttachment)
-			m.Post("/attachments/remove", repo.DeleteAttachment)
+			m.Get("/new", context.RequireReleaseAction(perm.ReleaseActionCreate), repo.NewRelease)
+			m.Post("/new", context.RequireReleaseAction(perm.ReleaseActionCreate), bindIgnErr(forms.NewReleaseForm{}), repo.NewReleasePost)
+			m.Post("/delete", context.RequireReleaseAction(perm.ReleaseActionEdit), repo.DeleteRelease)
+			m.Post("/attachments", context.RequireReleaseAction(perm.ReleaseActionUploadAssets), repo.UploadReleaseAttachment)
+			m.Post("/attachments/remove", context.RequireReleaseAction(perm.ReleaseActionUploadAssets), repo.DeleteAttachment)
 		}, reqSignIn, repo.MustBeNotEmpty, context.RepoMustNotBeArchived(), reqRepoReleaseWriter, context.RepoRef())
 		m.Post("/tags/delete", repo.DeleteTag, reqSignIn,
 			repo.MustBeNotEmpty, context.RepoMustNotBeArchived(), reqRepoCodeWriter, context.RepoRef())
 		m.Group("/releases", func() {
 			m.Get("/edit/*", repo.EditRelease)
 			m.Post("/edit/*", bindIgnErr(forms.EditReleaseForm{}), repo.EditReleasePost)
-		}, reqSignIn, repo.MustBeNotEmpty, context.RepoMustNotBeArchived(), reqRepoReleaseWriter, func(ctx *context.Context) {
+		}, reqSignIn, repo.MustBeNotEmpty, context.RepoMustNotBeArchived(), reqRepoReleaseWriter, context.RequireReleaseAction(perm.ReleaseActionEdit), func(ctx *context.Context) {
 			var err error
 			ctx.Repo.Commit, err = ctx.Repo.GitRepo.GetBranchCommit(ctx.Repo.Repository.DefaultBranch)
 			if err != nil {
@@ -1020,6 +1201,24 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/packages", repo.Packages)
 		}
 
+		m.Group("/actions", func() {
+			m.Get("", repo.Actions)
+			m.Group("/runs/{index}", func() {
+				m.Get("", repo.ActionRunView)
+				m.Post("/cancel", reqRepoCodeWriter, repo.CancelActionRunPost)
+				m.Get("/artifacts/{id}", repo.ActionArtifactDownload)
+				m.Get("/jobs/{id}", repo.ActionJobView)
+				m.Get("/jobs/{id}/logs", repo.ActionJobLogs)
+			})
+		}, reqRepoCodeReader, repo.MustEnableActions, func(ctx *context.Context) {
+			ctx.Data["PageIsActions"] = true
+		})
+
+		m.Group("/checks/{id}", func() {
+			m.Get("", repo.CheckRunView)
+			m.Post("/rerequest", reqRepoCodeWriter, repo.RerequestCheckRunPost)
+		}, reqRepoCodeReader)
+
 		m.Group("/projects", func() {
 			m.Get("", repo.Projects)
 			m.Get("/{id}", repo.ViewProject)
@@ -1045,6 +1244,32 @@ func RegisterRoutes(m *web.Route) {
 			}, reqRepoProjectsWriter, context.RepoMustNotBeArchived())
 		}, reqRepoProjectsReader, repo.MustEnableProjects)
 
+		m.Group("/discussions", func() {
+			m.Get("", repo.Discussions)
+			m.Group("", func() {
+				m.Get("/new", repo.NewDiscussion)
+				m.Post("/new", bindIgnErr(forms.CreateDiscussionForm{}), repo.NewDiscussionPost)
+				m.Group("/categories", func() {
+					m.Get("", repo.DiscussionCategories)
+					m.Post("/new", bindIgnErr(forms.DiscussionCategoryForm{}), repo.NewDiscussionCategoryPost)
+					m.Post("/{id}/edit", bindIgnErr(forms.DiscussionCategoryForm{}), repo.EditDiscussionCategoryPost)
+					m.Post("/delete", repo.DeleteDiscussionCategory)
+				}, reqRepoDiscussionsWriter)
+			}, reqSignIn, context.RepoMustNotBeArchived())
+			m.Group("/{index}", func() {
+				m.Get("", repo.ViewDiscussion)
+				m.Group("", func() {
+					m.Get("/edit", repo.EditDiscussion)
+					m.Post("/edit", bindIgnErr(forms.CreateDiscussionForm{}), repo.EditDiscussionPost)
+					m.Post("/delete", repo.DeleteDiscussion)
+					m.Post("/answer", repo.MarkDiscussionAnswer)
+					m.Post("/comments", bindIgnErr(forms.DiscussionCommentForm{}), repo.NewDiscussionCommentPost)
+					m.Post("/comments/{id}/edit", bindIgnErr(forms.DiscussionCommentForm{}), repo.EditDiscussionCommentPost)
+					m.Post("/comments/{id}/delete", repo.DeleteDiscussionComment)
+				}, reqSignIn, context.RepoMustNotBeArchived())
+			})
+		}, reqRepoDiscussionsReader, repo.MustEnableDiscussions)
+
 		m.Group("/wiki", func() {
 			m.Combo("/").
 				Get(repo.Wiki).
@@ -1076,6 +1301,11 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/{period}", repo.Activity)
 		}, context.RepoRef(), repo.MustBeNotEmpty, context.RequireRepoReaderOr(unit.TypePullRequests, unit.TypeIssues, unit.TypeReleases))
 
+		m.Group("/time_report", func() {
+			m.Get("", repo.TimeReport)
+			m.Get("/export", repo.TimeReportExport)
+		}, reqSignIn, context.RequireRepoReader(unit.TypeIssues))
+
 		m.Group("/activity_author_data", func() {
 			m.Get("", repo.ActivityAuthors)
 			m.Get("/{period}", repo.ActivityAuthors)
@@ -1118,8 +1348,10 @@ func RegisterRoutes(m *web.Route) {
 			m.Get("/commits", context.RepoRef(), repo.ViewPullCommits)
 			m.Post("/merge", context.RepoMustNotBeArchived(), bindIgnErr(forms.MergePullRequestForm{}), repo.MergePullRequest)
 			m.Post("/update", repo.UpdatePullRequest)
+			m.Post("/rewrite", context.RepoMustNotBeArchived(), repo.RewritePullRequest)
 			m.Post("/set_allow_maintainer_edit", bindIgnErr(forms.UpdateAllowEditsForm{}), repo.SetAllowEdits)
 			m.Post("/cleanup", context.RepoMustNotBeArchived(), context.RepoRef(), repo.CleanUpPullRequest)
+			m.Post("/restore_branch", repo.RestorePullRequestBranch)
 			m.Group("/files", func() {
 				m.Get("", context.RepoRef(), repo.SetEditorconfigIfExists, repo.SetDiffViewStyle, repo.SetWhitespaceBehavior, repo.ViewPullFiles)
 				m.Group("/reviews", func() {
@@ -1235,9 +1467,12 @@ func RegisterRoutes(m *web.Route) {
 		m.Get("", user.Notifications)
 		m.Post("/status", user.NotificationStatusPost)
 		m.Post("/purge", user.NotificationPurgePost)
+		m.Post("/archive_all", user.NotificationArchiveAllPost)
 		m.Get("/new", user.NewAvailable)
 	}, reqSignIn)
 
+	m.Post("/announcements/{id}/dismiss", reqSignIn, user.DismissAnnouncement)
+
 	if setting.API.EnableSwagger {
 		m.Get("/swagger.v1.json", SwaggerV1Json)
 	}
@@ -0,0 +1,177 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Init registers the notifier starting the workflows of repositories
+func Init() error {
+	if !setting.Actions.Enabled {
+		return nil
+	}
+	notification.RegisterNotifier(NewNotifier())
+	return nil
+}
+
+// TriggerOptions describes the event triggering the workflows of a repository
+type TriggerOptions struct {
+	Repo   *repo_model.Repository
+	Doer   *user_model.User
+	Commit *git.Commit
+	Event  string
+	// Ref is the pushed ref, or the ref of the head of a pull request
+	Ref string
+	// FilterRef is matched against the branch and tag filters of the workflows,
+	// it is the base branch of pull requests
+	FilterRef  string
+	Action     string
+	Title      string
+	IsForkPull bool
+}
+
+// TriggerWorkflows creates a run for each workflow of the commit matching the event
+func TriggerWorkflows(ctx context.Context, opts *TriggerOptions) ([]*actions_model.ActionRun, error) {
+	contents, err := actions.ListWorkflows(opts.Commit)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(contents))
+	for name := range contents {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	runs := make([]*actions_model.ActionRun, 0, len(names))
+	for _, name := range names {
+		workflow, err := actions.Parse(contents[name])
+		if err != nil {
+			log.Warn("Ignoring workflow %s of %s at %s: %v", name, opts.Repo.FullName(), opts.Commit.ID, err)
+			continue
+		}
+		if !workflow.Matches(opts.Event, opts.FilterRef, opts.Action) {
+			continue
+		}
+
+		run := &actions_model.ActionRun{
+			Title:         opts.Title,
+			RepoID:        opts.Repo.ID,
+			Repo:          opts.Repo,
+			WorkflowID:    name,
+			WorkflowName:  workflow.Name,
+			TriggerUserID: opts.Doer.ID,
+			TriggerUser:   opts.Doer,
+			Ref:           opts.Ref,
+			CommitSHA:     opts.Commit.ID.String(),
+			Event:         opts.Event,
+			IsForkPull:    opts.IsForkPull,
+		}
+		if run.WorkflowName == "" {
+			run.WorkflowName = name
+		}
+
+		jobs := make([]*actions_model.ActionRunJob, 0, len(workflow.Jobs))
+		for _, id := range actions.JobIDs(workflow.Jobs) {
+			job := workflow.Jobs[id]
+			payload, err := jobPayload(workflow, job)
+			if err != nil {
+				return nil, err
+			}
+			jobs = append(jobs, &actions_model.ActionRunJob{
+				Name:    job.DisplayName(id),
+				JobID:   id,
+				Needs:   job.Needs,
+				RunsOn:  job.RunsOn,
+				Payload: payload,
+			})
+		}
+
+		if err := actions_model.InsertRun(ctx, run, jobs); err != nil {
+			return nil, err
+		}
+		for _, job := range jobs {
+			job.Run = run
+			createCommitStatus(ctx, job)
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// jobPayload returns the definition of a job sent to runners, the environment of the workflow is merged into the job
+func jobPayload(workflow *actions.Workflow, job *actions.Job) (string, error) {
+	env := make(map[string]string, len(workflow.Env)+len(job.Env))
+	for k, v := range workflow.Env {
+		env[k] = v
+	}
+	for k, v := range job.Env {
+		env[k] = v
+	}
+	merged := *job
+	merged.Env = env
+
+	payload, err := json.Marshal(&merged)
+	if err != nil {
+		return "", fmt.Errorf("unable to encode job: %v", err)
+	}
+	return string(payload), nil
+}
+
+// UpdateJobStatus changes the status of a job and reports it, and the jobs skipped because of it, as commit statuses
+func UpdateJobStatus(ctx context.Context, job *actions_model.ActionRunJob, status actions_model.Status) error {
+	skipped, err := actions_model.UpdateJobStatus(ctx, job, status)
+	if err != nil {
+		return err
+	}
+	reportJobs(ctx, append([]*actions_model.ActionRunJob{job}, skipped...))
+	return nil
+}
+
+// CancelRun cancels the jobs of a run which haven't finished yet
+func CancelRun(ctx context.Context, run *actions_model.ActionRun) error {
+	cancelled, err := actions_model.CancelRun(ctx, run)
+	if err != nil {
+		return err
+	}
+	reportJobs(ctx, cancelled)
+	return nil
+}
+
+// DeleteRunner deletes a runner and fails the jobs it is running
+func DeleteRunner(ctx context.Context, runner *actions_model.ActionRunner) error {
+	changed, err := actions_model.DeleteRunner(ctx, runner)
+	if err != nil {
+		return err
+	}
+	reportJobs(ctx, changed)
+	return nil
+}
+
+func reportJobs(ctx context.Context, jobs []*actions_model.ActionRunJob) {
+	for _, job := range jobs {
+		if job.Status.IsDone() {
+			revokeJobToken(ctx, job)
+		}
+		if err := job.LoadRun(ctx); err != nil {
+			log.Error("LoadRun[%d]: %v", job.ID, err)
+			continue
+		}
+		createCommitStatus(ctx, job)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	actions_model "code.gitea.io/gitea/models/actions"
+	"code.gitea.io/gitea/modules/log"
+	files_service "code.gitea.io/gitea/services/repository/files"
+)
+
+// createCommitStatus reports the status of a job as a commit status, the run of the job has to be loaded
+func createCommitStatus(ctx context.Context, job *actions_model.ActionRunJob) {
+	run := job.Run
+
+	var description string
+	switch job.Status {
+	case actions_model.StatusWaiting:
+		description = "Waiting to run"
+	case actions_model.StatusRunning:
+		description = "Has started running"
+	case actions_model.StatusSuccess:
+		description = fmt.Sprintf("Successful in %s", job.Duration())
+	case actions_model.StatusFailure:
+		description = fmt.Sprintf("Failing after %s", job.Duration())
+	case actions_model.StatusCancelled:
+		description = "Has been cancelled"
+	case actions_model.StatusSkipped:
+		description = "Has been skipped"
+	}
+
+	if err := files_service.CreateCommitStatus(ctx, run.Repo, run.TriggerUser, run.CommitSHA, &models.CommitStatus{
+		State:       job.Status.CommitStatus(),
+		TargetURL:   fmt.Sprintf("%s/jobs/%d", run.HTMLURL(), job.ID),
+		Description: description,
+		Context:     fmt.Sprintf("%s / %s (%s)", run.WorkflowName, job.Name, run.Event),
+	}); err != nil {
+		log.Error("CreateCommitStatus[repo_id: %d, job_id: %d]: %v", run.RepoID, job.ID, err)
+	}
+}
@@ -0,0 +1,126 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/actions"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/repository"
+)
+
+type actionsNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &actionsNotifier{}
+
+// NewNotifier create a new actionsNotifier notifier
+func NewNotifier() base.Notifier {
+	return &actionsNotifier{}
+}
+
+func (a *actionsNotifier) NotifyPushCommits(pusher *user_model.User, repo *repo_model.Repository, opts *repository.PushUpdateOptions, commits *repository.PushCommits) {
+	if opts.IsDelRef() {
+		return
+	}
+
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("actions.NotifyPushCommits User: %s[%d] in %s[%d]", pusher.Name, pusher.ID, repo.FullName(), repo.ID))
+	defer finished()
+
+	title := opts.RefName()
+	if commits != nil && commits.HeadCommit != nil {
+		title = commits.HeadCommit.Message
+	}
+	triggerWorkflows(ctx, &TriggerOptions{
+		Repo:      repo,
+		Doer:      pusher,
+		Event:     actions.EventPush,
+		Ref:       opts.RefFullName,
+		FilterRef: opts.RefFullName,
+		Title:     title,
+	}, opts.NewCommitID)
+}
+
+func (a *actionsNotifier) NotifyNewPullRequest(pr *models.PullRequest, mentions []*user_model.User) {
+	notifyPullRequest(pr, nil, "opened")
+}
+
+func (a *actionsNotifier) NotifyPullRequestSynchronized(doer *user_model.User, pr *models.PullRequest) {
+	notifyPullRequest(pr, doer, "synchronize")
+}
+
+// notifyPullRequest triggers the workflows of the base repository of a pull request,
+// the poster of the pull request is the trigger user if doer is nil
+func notifyPullRequest(pr *models.PullRequest, doer *user_model.User, action string) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("actions.notifyPullRequest Pull: %d %s", pr.ID, action))
+	defer finished()
+
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		log.Error("LoadIssue[%d]: %v", pr.ID, err)
+		return
+	}
+	if doer == nil {
+		if err := pr.Issue.LoadPoster(); err != nil {
+			log.Error("LoadPoster[%d]: %v", pr.Issue.ID, err)
+			return
+		}
+		doer = pr.Issue.Poster
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		log.Error("LoadBaseRepo[%d]: %v", pr.ID, err)
+		return
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, pr.BaseRepo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", pr.BaseRepo.FullName(), err)
+		return
+	}
+	defer gitRepo.Close()
+	headCommitID, err := gitRepo.GetRefCommitID(pr.GetGitRefName())
+	if err != nil {
+		log.Error("GetRefCommitID[%s]: %v", pr.GetGitRefName(), err)
+		return
+	}
+
+	triggerWorkflows(ctx, &TriggerOptions{
+		Repo:       pr.BaseRepo,
+		Doer:       doer,
+		Event:      actions.EventPullRequest,
+		Ref:        pr.GetGitRefName(),
+		FilterRef:  git.BranchPrefix + pr.BaseBranch,
+		Action:     action,
+		Title:      pr.Issue.Title,
+		IsForkPull: pr.HeadRepoID != pr.BaseRepoID,
+	}, headCommitID)
+}
+
+func triggerWorkflows(ctx context.Context, opts *TriggerOptions, commitID string) {
+	gitRepo, err := git.OpenRepository(ctx, opts.Repo.RepoPath())
+	if err != nil {
+		log.Error("OpenRepository[%s]: %v", opts.Repo.FullName(), err)
+		return
+	}
+	defer gitRepo.Close()
+
+	opts.Commit, err = gitRepo.GetCommit(commitID)
+	if err != nil {
+		log.Error("GetCommit[%s]: %v", commitID, err)
+		return
+	}
+	if _, err := TriggerWorkflows(ctx, opts); err != nil {
+		log.Error("TriggerWorkflows[%s, %s]: %v", opts.Repo.FullName(), commitID, err)
+	}
+}
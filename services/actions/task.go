@@ -0,0 +1,204 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package actions
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	actions_model "code.gitea.io/gitea/models/actions"
+	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	gouuid "github.com/google/uuid"
+)
+
+// PickTask assigns the next job the runner can run to the runner and returns it as a task.
+// It returns nil if there is no job for the runner.
+func PickTask(ctx context.Context, runner *actions_model.ActionRunner) (*api.ActionTask, error) {
+	if err := actions_model.UpdateRunnerLastOnline(ctx, runner); err != nil {
+		return nil, err
+	}
+
+	job, err := actions_model.PickJob(ctx, runner)
+	if err != nil || job == nil {
+		return nil, err
+	}
+	if err := job.LoadRun(ctx); err != nil {
+		return nil, err
+	}
+	run := job.Run
+	createCommitStatus(ctx, job)
+
+	token, err := createJobToken(ctx, job)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &api.ActionTask{
+		JobID:      job.ID,
+		RunID:      run.ID,
+		Repository: run.Repo.FullName(),
+		CloneURL:   run.Repo.CloneLink().HTTPS,
+		Event:      run.Event,
+		Ref:        run.Ref,
+		CommitSHA:  run.CommitSHA,
+		Workflow:   run.WorkflowID,
+		Job:        job.JobID,
+		Payload:    job.Payload,
+		Token:      token.Token,
+		Secrets:    map[string]string{},
+	}
+
+	var ownerID int64
+	if err := run.Repo.GetOwner(ctx); err != nil {
+		return nil, err
+	}
+	if run.Repo.Owner.IsOrganization() {
+		ownerID = run.Repo.OwnerID
+	}
+	// the secrets aren't passed to pull requests from forks, they could be leaked by the changes,
+	// and runners of the whole instance only receive them if the site administrator allows it
+	if !run.IsForkPull && (!runner.IsInstanceRunner() || setting.Actions.SecretsForInstanceRunners) {
+		if task.Secrets, err = secret_model.GetValues(ctx, run.RepoID, ownerID, secret_model.TypeSecret); err != nil {
+			return nil, err
+		}
+	}
+	if task.Variables, err = secret_model.GetValues(ctx, run.RepoID, ownerID, secret_model.TypeVariable); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+func jobTokenName(job *actions_model.ActionRunJob) string {
+	return fmt.Sprintf("actions-job-%d", job.ID)
+}
+
+// createJobToken issues the deploy token the runner uses to clone the repository of the job
+func createJobToken(ctx context.Context, job *actions_model.ActionRunJob) (*repo_model.DeployToken, error) {
+	token := &repo_model.DeployToken{
+		RepoID:      job.RepoID,
+		Name:        jobTokenName(job),
+		Scopes:      string(repo_model.DeployTokenScopeReadRepository) + "," + string(repo_model.DeployTokenScopeReadLFS),
+		ExpiresUnix: timeutil.TimeStamp(time.Now().Add(setting.Actions.JobTokenLifetime).Unix()),
+	}
+	if err := repo_model.NewDeployToken(ctx, token); err != nil {
+		return nil, fmt.Errorf("unable to create the token of job %d: %v", job.ID, err)
+	}
+	return token, nil
+}
+
+// revokeJobToken deletes the deploy token of a finished job
+func revokeJobToken(ctx context.Context, job *actions_model.ActionRunJob) {
+	if err := repo_model.DeleteDeployTokenByName(ctx, job.RepoID, jobTokenName(job)); err != nil {
+		log.Error("Unable to revoke the token of job %d: %v", job.ID, err)
+	}
+}
+
+// ErrJobNotRunning is returned when a runner updates a job which isn't running anymore, e.g. because it was cancelled
+var ErrJobNotRunning = errors.New("job isn't running")
+
+// ErrTooManyLogLines is returned when a runner appends more log lines at once than allowed
+var ErrTooManyLogLines = errors.New("too many log lines")
+
+// ErrArtifactTooLarge is returned when an uploaded artifact exceeds the maximum size
+var ErrArtifactTooLarge = errors.New("artifact is too large")
+
+// GetRunnerJob returns a job assigned to the runner
+func GetRunnerJob(ctx context.Context, runner *actions_model.ActionRunner, jobID int64) (*actions_model.ActionRunJob, error) {
+	job, err := actions_model.GetJobByID(ctx, jobID)
+	if err != nil {
+		return nil, err
+	}
+	if job.RunnerID != runner.ID {
+		return nil, actions_model.ErrJobNotExist{ID: jobID}
+	}
+	return job, nil
+}
+
+// AppendLogs appends lines sent by the runner to the log of a running job
+func AppendLogs(ctx context.Context, job *actions_model.ActionRunJob, lines []string) error {
+	if job.Status != actions_model.StatusRunning {
+		return ErrJobNotRunning
+	}
+	if len(lines) > setting.Actions.MaxLogLinesPerRequest {
+		return ErrTooManyLogLines
+	}
+	return actions_model.AppendJobLogs(ctx, job, lines)
+}
+
+// FinishJob records the result of a running job sent by the runner
+func FinishJob(ctx context.Context, job *actions_model.ActionRunJob, status actions_model.Status) error {
+	if job.Status != actions_model.StatusRunning {
+		return ErrJobNotRunning
+	}
+	if !status.IsDone() || status == actions_model.StatusSkipped {
+		return fmt.Errorf("invalid status of a finished job: %s", status)
+	}
+	return UpdateJobStatus(ctx, job, status)
+}
+
+// UploadArtifact stores a file uploaded by a running job
+func UploadArtifact(ctx context.Context, job *actions_model.ActionRunJob, name string, r io.Reader) (*actions_model.ActionArtifact, error) {
+	if job.Status != actions_model.StatusRunning {
+		return nil, ErrJobNotRunning
+	}
+
+	artifact := &actions_model.ActionArtifact{
+		RunID:       job.RunID,
+		JobID:       job.ID,
+		RepoID:      job.RepoID,
+		Name:        name,
+		StoragePath: fmt.Sprintf("%d/%d/%s", job.RepoID, job.RunID, gouuid.New().String()),
+	}
+	// read one byte more than allowed to notice artifacts exceeding the limit
+	size, err := storage.Actions.Save(artifact.StoragePath, io.LimitReader(r, setting.Actions.MaxArtifactSize+1), -1)
+	if err != nil {
+		return nil, err
+	}
+	if size > setting.Actions.MaxArtifactSize {
+		if err := storage.Actions.Delete(artifact.StoragePath); err != nil {
+			log.Error("Unable to delete artifact %s: %v", artifact.StoragePath, err)
+		}
+		return nil, ErrArtifactTooLarge
+	}
+	artifact.FileSize = size
+
+	if err := actions_model.InsertArtifact(ctx, artifact); err != nil {
+		if err := storage.Actions.Delete(artifact.StoragePath); err != nil {
+			log.Error("Unable to delete artifact %s: %v", artifact.StoragePath, err)
+		}
+		return nil, err
+	}
+	return artifact, nil
+}
+
+// StopZombieJobs fails the running jobs which haven't been updated by their runner for too long
+func StopZombieJobs(ctx context.Context) error {
+	jobs, err := actions_model.FindZombieJobs(ctx, timeutil.TimeStamp(time.Now().Add(-setting.Actions.ZombieTimeout).Unix()))
+	if err != nil {
+		return err
+	}
+	for _, job := range jobs {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		log.Trace("Stopping zombie job %d of run %d", job.ID, job.RunID)
+		if err := UpdateJobStatus(ctx, job, actions_model.StatusFailure); err != nil {
+			return err
+		}
+	}
+	return nil
+}
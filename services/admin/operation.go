@@ -0,0 +1,106 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	org_service "code.gitea.io/gitea/services/org"
+	user_service "code.gitea.io/gitea/services/user"
+)
+
+// ErrSelfApproval is returned if a site admin tries to approve an operation the admin has requested
+var ErrSelfApproval = errors.New("an operation must be approved by another site admin than the one who requested it")
+
+// RequestOperation records an operation on the target which is carried out once a second site admin approves it
+// within the configured window
+func RequestOperation(ctx context.Context, doer *user_model.User, opType admin_model.PendingOperationType, target *user_model.User) (*admin_model.PendingOperation, error) {
+	op := &admin_model.PendingOperation{
+		Type:          opType,
+		TargetID:      target.ID,
+		TargetName:    target.Name,
+		RequesterID:   doer.ID,
+		RequesterName: doer.Name,
+		ExpiresUnix:   timeutil.TimeStampNow().AddDuration(setting.Admin.SecondApprovalWindow),
+	}
+	if err := admin_model.CreatePendingOperation(ctx, op); err != nil {
+		return nil, err
+	}
+	return op, nil
+}
+
+// FindOperations returns the operations waiting for approval and deletes the expired ones
+func FindOperations(ctx context.Context) ([]*admin_model.PendingOperation, error) {
+	now := timeutil.TimeStampNow()
+	if err := admin_model.DeleteExpiredPendingOperations(ctx, now); err != nil {
+		return nil, err
+	}
+	return admin_model.FindPendingOperations(ctx, now)
+}
+
+// ApproveOperation approves and carries out a pending operation,
+// the doer must not be the site admin who requested it
+func ApproveOperation(ctx context.Context, doer *user_model.User, id int64) (*admin_model.PendingOperation, error) {
+	op, err := admin_model.GetPendingOperationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if op.IsExpired(timeutil.TimeStampNow()) {
+		return nil, admin_model.ErrPendingOperationNotExist{ID: id}
+	}
+	if op.RequesterID == doer.ID {
+		return nil, ErrSelfApproval
+	}
+
+	// deleting the operation first makes sure it is only carried out once
+	// if two site admins approve it at the same time
+	if deleted, err := admin_model.DeletePendingOperation(ctx, id); err != nil {
+		return nil, err
+	} else if !deleted {
+		return nil, admin_model.ErrPendingOperationNotExist{ID: id}
+	}
+
+	return op, ExecuteOperation(ctx, doer, op.Type, op.TargetID)
+}
+
+// CancelOperation cancels a pending operation
+func CancelOperation(ctx context.Context, id int64) (*admin_model.PendingOperation, error) {
+	op, err := admin_model.GetPendingOperationByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if deleted, err := admin_model.DeletePendingOperation(ctx, id); err != nil {
+		return nil, err
+	} else if !deleted {
+		return nil, admin_model.ErrPendingOperationNotExist{ID: id}
+	}
+	return op, nil
+}
+
+// ExecuteOperation carries out an operation on the target
+func ExecuteOperation(ctx context.Context, doer *user_model.User, opType admin_model.PendingOperationType, targetID int64) error {
+	target, err := user_model.GetUserByIDCtx(ctx, targetID)
+	if err != nil {
+		return err
+	}
+
+	switch opType {
+	case admin_model.PendingOperationPurgeUser:
+		return user_service.PurgeUser(ctx, doer, target)
+	case admin_model.PendingOperationPurgeOrg:
+		if !target.IsOrganization() {
+			return fmt.Errorf("%s is a user not an organization", target.Name)
+		}
+		return org_service.PurgeOrganization(ctx, doer, organization.OrgFromUser(target))
+	}
+	return fmt.Errorf("unknown operation type: %s", opType)
+}
@@ -0,0 +1,103 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"testing"
+	"time"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApproveOperation(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	defer func(window time.Duration) {
+		setting.Admin.SecondApprovalWindow = window
+	}(setting.Admin.SecondApprovalWindow)
+	setting.Admin.SecondApprovalWindow = time.Hour
+
+	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+	secondAdmin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	org := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 23}).(*user_model.User)
+
+	op, err := RequestOperation(db.DefaultContext, admin, admin_model.PendingOperationPurgeOrg, org)
+	assert.NoError(t, err)
+	ops, err := FindOperations(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 1)
+
+	// the requester cannot approve the operation
+	_, err = ApproveOperation(db.DefaultContext, admin, op.ID)
+	assert.Equal(t, ErrSelfApproval, err)
+	unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: org.ID})
+
+	approved, err := ApproveOperation(db.DefaultContext, secondAdmin, op.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, op.ID, approved.ID)
+	unittest.AssertNotExistsBean(t, &user_model.User{ID: org.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.Repository{OwnerID: org.ID})
+	unittest.AssertNotExistsBean(t, &admin_model.PendingOperation{ID: op.ID})
+
+	// an operation is only carried out once
+	_, err = ApproveOperation(db.DefaultContext, secondAdmin, op.ID)
+	assert.True(t, admin_model.IsErrPendingOperationNotExist(err))
+}
+
+func TestExpiredOperation(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	defer func(window time.Duration) {
+		setting.Admin.SecondApprovalWindow = window
+	}(setting.Admin.SecondApprovalWindow)
+	setting.Admin.SecondApprovalWindow = -time.Minute
+
+	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+	secondAdmin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 10}).(*user_model.User)
+
+	op, err := RequestOperation(db.DefaultContext, admin, admin_model.PendingOperationPurgeUser, user)
+	assert.NoError(t, err)
+
+	_, err = ApproveOperation(db.DefaultContext, secondAdmin, op.ID)
+	assert.True(t, admin_model.IsErrPendingOperationNotExist(err))
+	unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: user.ID})
+
+	// expired operations are cleaned up when listing the pending ones
+	ops, err := FindOperations(db.DefaultContext)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 0)
+	unittest.AssertNotExistsBean(t, &admin_model.PendingOperation{ID: op.ID})
+}
+
+func TestCancelOperation(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	defer func(window time.Duration) {
+		setting.Admin.SecondApprovalWindow = window
+	}(setting.Admin.SecondApprovalWindow)
+	setting.Admin.SecondApprovalWindow = time.Hour
+
+	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 10}).(*user_model.User)
+
+	op, err := RequestOperation(db.DefaultContext, admin, admin_model.PendingOperationPurgeUser, user)
+	assert.NoError(t, err)
+	_, err = RequestOperation(db.DefaultContext, admin, admin_model.PendingOperationPurgeUser, user)
+	assert.True(t, admin_model.IsErrPendingOperationAlreadyExist(err))
+
+	cancelled, err := CancelOperation(db.DefaultContext, op.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, user.Name, cancelled.TargetName)
+	unittest.AssertNotExistsBean(t, &admin_model.PendingOperation{ID: op.ID})
+	unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: user.ID})
+}
@@ -0,0 +1,229 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// SelfTestStatus is the outcome of a self-test
+type SelfTestStatus string
+
+// The outcomes of a self-test
+const (
+	SelfTestPass SelfTestStatus = "pass"
+	SelfTestFail SelfTestStatus = "fail"
+	SelfTestSkip SelfTestStatus = "skip"
+)
+
+// selfTestTimeout bounds the time a self-test waits for a remote service or the queue
+const selfTestTimeout = 10 * time.Second
+
+// SelfTestCheck is the result of a single self-test
+type SelfTestCheck struct {
+	Name     string
+	Status   SelfTestStatus
+	Output   string
+	Details  map[string]string
+	Duration time.Duration
+}
+
+// SelfTestReport is the result of all self-tests, it fails if any of them failed
+type SelfTestReport struct {
+	Status  SelfTestStatus
+	Started time.Time
+	Checks  []*SelfTestCheck
+}
+
+// selfTestQueue represents a queue which hands the tokens pushed by the self-test back to it
+var (
+	selfTestQueue   queue.Queue
+	selfTestWaiters sync.Map
+)
+
+// InitSelfTest runs the queue used by the round trip self-test
+func InitSelfTest() error {
+	selfTestQueue = queue.CreateQueue("self_test", handleSelfTest, "")
+	if selfTestQueue == nil {
+		return fmt.Errorf("Unable to create self_test Queue")
+	}
+	go graceful.GetManager().RunWithShutdownFns(selfTestQueue.Run)
+	return nil
+}
+
+func handleSelfTest(data ...queue.Data) []queue.Data {
+	for _, d := range data {
+		if done, ok := selfTestWaiters.LoadAndDelete(d.(string)); ok {
+			close(done.(chan struct{}))
+		}
+	}
+	return nil
+}
+
+// RunSelfTest writes to and reads from each storage, checks the git binary and the connection
+// to the mailer and makes a round trip through a queue
+func RunSelfTest(ctx context.Context) *SelfTestReport {
+	report := &SelfTestReport{
+		Status:  SelfTestPass,
+		Started: time.Now(),
+	}
+
+	for _, s := range []struct {
+		name string
+		obj  storage.ObjectStorage
+	}{
+		{"attachments", storage.Attachments},
+		{"attachments_cold", storage.AttachmentsCold},
+		{"lfs", storage.LFS},
+		{"avatars", storage.Avatars},
+		{"repo_avatars", storage.RepoAvatars},
+		{"repo_archives", storage.RepoArchives},
+		{"packages", storage.Packages},
+		{"actions", storage.Actions},
+		{"custom_emojis", storage.CustomEmojis},
+	} {
+		obj := s.obj
+		report.run("storage:"+s.name, func(check *SelfTestCheck) error {
+			return selfTestStorage(obj)
+		})
+	}
+	report.run("git", func(check *SelfTestCheck) error {
+		return selfTestGit(ctx, check)
+	})
+	report.run("mailer", func(check *SelfTestCheck) error {
+		if setting.MailService == nil {
+			check.Status = SelfTestSkip
+			check.Output = "mailer is disabled"
+			return nil
+		}
+		check.Details = map[string]string{"type": setting.MailService.MailerType}
+		return mailer.CheckConnection(selfTestTimeout)
+	})
+	report.run("queue", func(check *SelfTestCheck) error {
+		return selfTestQueueRoundTrip(ctx)
+	})
+	return report
+}
+
+// run runs a single self-test and records its result
+func (report *SelfTestReport) run(name string, test func(check *SelfTestCheck) error) {
+	check := &SelfTestCheck{
+		Name:   name,
+		Status: SelfTestPass,
+	}
+	start := time.Now()
+	if err := test(check); err != nil {
+		log.Error("Self-test %s failed: %v", name, err)
+		check.Status = SelfTestFail
+		check.Output = err.Error()
+		report.Status = SelfTestFail
+	}
+	check.Duration = time.Since(start)
+	report.Checks = append(report.Checks, check)
+}
+
+// selfTestStorage writes a random object to the storage, reads it back and deletes it
+func selfTestStorage(obj storage.ObjectStorage) error {
+	if obj == nil {
+		return fmt.Errorf("storage is not initialized")
+	}
+
+	content, err := util.CryptoRandomBytes(64)
+	if err != nil {
+		return err
+	}
+	token, err := util.CryptoRandomString(16)
+	if err != nil {
+		return err
+	}
+	p := "self-test/" + token
+
+	if _, err := obj.Save(p, bytes.NewReader(content), int64(len(content))); err != nil {
+		return fmt.Errorf("write: %v", err)
+	}
+	defer func() {
+		if err := obj.Delete(p); err != nil {
+			log.Error("Unable to delete the object %s written by the self-test: %v", p, err)
+		}
+	}()
+
+	f, err := obj.Open(p)
+	if err != nil {
+		return fmt.Errorf("open: %v", err)
+	}
+	defer f.Close()
+	read, err := io.ReadAll(f)
+	if err != nil {
+		return fmt.Errorf("read: %v", err)
+	}
+	if !bytes.Equal(read, content) {
+		return fmt.Errorf("read %d bytes which differ from the %d bytes written", len(read), len(content))
+	}
+	return nil
+}
+
+// selfTestGit runs the git binary and records its version and the features depending on it
+func selfTestGit(ctx context.Context, check *SelfTestCheck) error {
+	check.Details = map[string]string{"path": git.GitExecutable}
+
+	stdout, _, err := git.NewCommand(ctx, "version").RunStdString(nil)
+	if err != nil {
+		return err
+	}
+	check.Details["version"] = strings.TrimSpace(strings.TrimPrefix(stdout, "git version"))
+
+	if err := git.CheckGitVersionAtLeast(git.GitVersionRequired); err != nil {
+		return err
+	}
+	check.Details["wire_protocol_v2"] = strconv.FormatBool(setting.Git.EnableAutoGitWireProtocol && git.CheckGitVersionAtLeast("2.18") == nil)
+	check.Details["commit_graph"] = strconv.FormatBool(git.CheckGitVersionAtLeast("2.18") == nil)
+	check.Details["partial_clone"] = strconv.FormatBool(git.SupportPartialClone)
+	check.Details["proc_receive"] = strconv.FormatBool(git.SupportProcReceive)
+	return nil
+}
+
+// selfTestQueueRoundTrip pushes a token to the self-test queue and waits for it to be handled
+func selfTestQueueRoundTrip(ctx context.Context) error {
+	if selfTestQueue == nil {
+		return fmt.Errorf("queue is not initialized")
+	}
+
+	token, err := util.CryptoRandomString(16)
+	if err != nil {
+		return err
+	}
+	done := make(chan struct{})
+	selfTestWaiters.Store(token, done)
+	defer selfTestWaiters.Delete(token)
+
+	if err := selfTestQueue.Push(token); err != nil {
+		return fmt.Errorf("push: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, selfTestTimeout)
+	defer cancel()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("the pushed data was not handled within %v", selfTestTimeout)
+	}
+}
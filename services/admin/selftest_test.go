@@ -0,0 +1,56 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package admin
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/modules/queue"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunSelfTest(t *testing.T) {
+	report := RunSelfTest(db.DefaultContext)
+	assert.Equal(t, SelfTestFail, report.Status)
+
+	checks := make(map[string]*SelfTestCheck, len(report.Checks))
+	for _, check := range report.Checks {
+		checks[check.Name] = check
+	}
+	assert.Equal(t, SelfTestPass, checks["storage:attachments"].Status, checks["storage:attachments"].Output)
+	assert.Equal(t, SelfTestPass, checks["storage:packages"].Status, checks["storage:packages"].Output)
+	assert.Equal(t, SelfTestPass, checks["git"].Status, checks["git"].Output)
+	assert.NotEmpty(t, checks["git"].Details["version"])
+	assert.Equal(t, SelfTestSkip, checks["mailer"].Status)
+	assert.Equal(t, SelfTestFail, checks["queue"].Status)
+	assert.Equal(t, "queue is not initialized", checks["queue"].Output)
+
+	q, err := queue.NewChannelQueue(handleSelfTest, queue.ChannelQueueConfiguration{
+		WorkerPoolConfiguration: queue.WorkerPoolConfiguration{
+			MaxWorkers:   1,
+			BlockTimeout: time.Second,
+			BoostTimeout: time.Minute,
+			BoostWorkers: 1,
+			Name:         "TestRunSelfTest",
+		},
+		Workers: 1,
+	}, "")
+	assert.NoError(t, err)
+	nilFn := func(_ func()) {}
+	go q.Run(nilFn, nilFn)
+	selfTestQueue = q
+	defer func() {
+		selfTestQueue = nil
+	}()
+
+	report = RunSelfTest(db.DefaultContext)
+	for _, check := range report.Checks {
+		assert.NotEqual(t, SelfTestFail, check.Status, "%s: %s", check.Name, check.Output)
+	}
+	assert.Equal(t, SelfTestPass, report.Status)
+}
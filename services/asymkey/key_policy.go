@@ -0,0 +1,197 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package asymkey
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// KeyPolicyViolation represents a way the keys of a user can violate the key policy of an organization
+type KeyPolicyViolation string
+
+// The violations of a key policy
+const (
+	KeyPolicyViolationTooOld         KeyPolicyViolation = "too_old"
+	KeyPolicyViolationDSAKey         KeyPolicyViolation = "dsa_key"
+	KeyPolicyViolationSmallRSAKey    KeyPolicyViolation = "small_rsa_key"
+	KeyPolicyViolationNoRecentSSHKey KeyPolicyViolation = "no_recent_ssh_key"
+	KeyPolicyViolationNoGPGKey       KeyPolicyViolation = "no_gpg_key"
+)
+
+// ErrKeyPolicyViolation represents a "KeyPolicyViolation" kind of error.
+type ErrKeyPolicyViolation struct {
+	KeyName   string
+	OrgName   string
+	Violation KeyPolicyViolation
+	Policy    *organization.OrgKeyPolicy
+}
+
+// IsErrKeyPolicyViolation checks if an error is a ErrKeyPolicyViolation.
+func IsErrKeyPolicyViolation(err error) bool {
+	_, ok := err.(ErrKeyPolicyViolation)
+	return ok
+}
+
+func (err ErrKeyPolicyViolation) Error() string {
+	var reason string
+	switch err.Violation {
+	case KeyPolicyViolationTooOld:
+		reason = fmt.Sprintf("it is older than %d years", err.Policy.SSHKeyMaxAgeYears)
+	case KeyPolicyViolationDSAKey:
+		reason = "DSA keys are forbidden"
+	case KeyPolicyViolationSmallRSAKey:
+		reason = fmt.Sprintf("RSA keys must have at least %d bits", err.Policy.MinRSAKeySize)
+	default:
+		reason = string(err.Violation)
+	}
+	return fmt.Sprintf("key %s violates the key policy of %s: %s", err.KeyName, err.OrgName, reason)
+}
+
+// CheckSSHKey returns how the SSH key violates the key policy, an empty string if it complies with it
+func CheckSSHKey(policy *organization.OrgKeyPolicy, key *asymkey_model.PublicKey) KeyPolicyViolation {
+	// principals are names, not keys
+	if key.Type == asymkey_model.KeyTypePrincipal {
+		return ""
+	}
+
+	if policy.SSHKeyMaxAgeYears > 0 {
+		oldest := timeutil.TimeStamp(time.Now().AddDate(-policy.SSHKeyMaxAgeYears, 0, 0).Unix())
+		if key.CreatedUnix < oldest {
+			return KeyPolicyViolationTooOld
+		}
+	}
+
+	if !policy.ForbidDSAKeys && policy.MinRSAKeySize <= 0 {
+		return ""
+	}
+	keyType, length, err := asymkey_model.SSHNativeParsePublicKey(key.Content)
+	if err != nil {
+		log.Warn("Unable to determine the type of key %d: %v", key.ID, err)
+		return ""
+	}
+	switch {
+	case keyType == "dsa" && policy.ForbidDSAKeys:
+		return KeyPolicyViolationDSAKey
+	case keyType == "rsa" && length < policy.MinRSAKeySize:
+		return KeyPolicyViolationSmallRSAKey
+	}
+	return ""
+}
+
+// CheckSSHKeyForOrg returns ErrKeyPolicyViolation if the key violates the key policy of the organization
+func CheckSSHKeyForOrg(ctx context.Context, org *user_model.User, key *asymkey_model.PublicKey) error {
+	policy, err := organization.GetKeyPolicyByOrgID(ctx, org.ID)
+	if err != nil {
+		return err
+	}
+	if violation := CheckSSHKey(policy, key); violation != "" {
+		return ErrKeyPolicyViolation{KeyName: key.Name, OrgName: org.Name, Violation: violation, Policy: policy}
+	}
+	return nil
+}
+
+// ViolatingKey is an SSH key which violates the key policy of an organization
+type ViolatingKey struct {
+	Key       *asymkey_model.PublicKey
+	Violation KeyPolicyViolation
+}
+
+// MemberKeyCompliance describes how the keys of a member comply with the key policy of an organization
+type MemberKeyCompliance struct {
+	Member *user_model.User
+	// Violations lists the missing keys of the member
+	Violations    []KeyPolicyViolation
+	ViolatingKeys []*ViolatingKey
+}
+
+// IsCompliant returns true if the keys of the member comply with the policy
+func (c *MemberKeyCompliance) IsCompliant() bool {
+	return len(c.Violations) == 0 && len(c.ViolatingKeys) == 0
+}
+
+// hasSigningGPGKey returns true if the user has a verified GPG key which can sign commits and hasn't expired
+func hasSigningGPGKey(ctx context.Context, userID int64) (bool, error) {
+	keys, err := asymkey_model.ListGPGKeys(ctx, userID, db.ListOptions{})
+	if err != nil {
+		return false, err
+	}
+	now := timeutil.TimeStampNow()
+	for _, key := range keys {
+		if !key.Verified || (key.ExpiredUnix > 0 && key.ExpiredUnix < now) {
+			continue
+		}
+		if key.CanSign {
+			return true, nil
+		}
+		for _, subKey := range key.SubsKey {
+			if subKey.CanSign && (subKey.ExpiredUnix == 0 || subKey.ExpiredUnix >= now) {
+				return true, nil
+			}
+		}
+	}
+	return false, nil
+}
+
+// GetMemberKeyCompliance checks the keys of the user against the key policy of an organization
+func GetMemberKeyCompliance(ctx context.Context, policy *organization.OrgKeyPolicy, member *user_model.User) (*MemberKeyCompliance, error) {
+	compliance := &MemberKeyCompliance{Member: member}
+
+	if policy.HasSSHKeyRules() {
+		keys, err := asymkey_model.ListPublicKeys(member.ID, db.ListOptions{})
+		if err != nil {
+			return nil, err
+		}
+		hasCompliantKey := false
+		for _, key := range keys {
+			violation := CheckSSHKey(policy, key)
+			if violation == "" {
+				hasCompliantKey = true
+				continue
+			}
+			compliance.ViolatingKeys = append(compliance.ViolatingKeys, &ViolatingKey{Key: key, Violation: violation})
+		}
+		if policy.SSHKeyMaxAgeYears > 0 && !hasCompliantKey {
+			compliance.Violations = append(compliance.Violations, KeyPolicyViolationNoRecentSSHKey)
+		}
+	}
+
+	if policy.RequireGPGKey {
+		has, err := hasSigningGPGKey(ctx, member.ID)
+		if err != nil {
+			return nil, err
+		}
+		if !has {
+			compliance.Violations = append(compliance.Violations, KeyPolicyViolationNoGPGKey)
+		}
+	}
+
+	return compliance, nil
+}
+
+// GetKeyPolicyCompliance checks the keys of all members of the organization against its key policy
+func GetKeyPolicyCompliance(ctx context.Context, org *organization.Organization, policy *organization.OrgKeyPolicy) ([]*MemberKeyCompliance, error) {
+	members, _, err := org.GetMembers()
+	if err != nil {
+		return nil, err
+	}
+	compliances := make([]*MemberKeyCompliance, 0, len(members))
+	for _, member := range members {
+		compliance, err := GetMemberKeyCompliance(ctx, policy, member)
+		if err != nil {
+			return nil, err
+		}
+		compliances = append(compliances, compliance)
+	}
+	return compliances, nil
+}
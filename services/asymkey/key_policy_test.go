@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package asymkey
+
+import (
+	"testing"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	testDSAKey     = "ssh-dss AAAAB3NzaC1kc3MAAACBAP06ejq3rC72qL88RHtYv1bisjWkXl1JfKT6BI+x98xBFqE4J0qGNcKTRuAqjC78gOcQ2FXxCEJ9LQ2tTO7JBrcgfDgGEk5knmxfHqj6ZTmzgNr1+pol2Xvh62PJznIO+8lP0xZbjk916Yqbotc04QZeDW6cf0wx9K0qug3NJtaRAAAAFQDCncJ33ICTk1t3Mzph0nfb0uwzpQAAAIEAwwxAmTB+yfarvMjsZ+jK3XeDzY/kJ8pIfzX7mXCo/O2JBPDcD4IboikWGrzyTDQrbCCwo9TBLR7n1CceCZrX7M/l55ZNhPhwP9iKIYsr49+/4FceHR7wVMeHIPj7WXwzOB+p/1hf8h5K07Roa31W3BeAuhG39vnxV1hccvvKiJEAAACANqbfWPzWSnQMEzrwmJi1D9Bi5QeJxCE6Z6Ddz4JdJgwx1dEbauL827pFWCni3nTcFb5pVOkAjy5QFm6k65+BKsY41qCeau3LK3XHl+CaVHQOQfpXtOm1FC55bCI6McI9B2bdwwF/yyVMo0d+QIkIjYAmNNgrivHSkyoIv2zJxj8="
+	testRSA1024Key = "ssh-rsa AAAAB3NzaC1yc2EAAAADAQABAAAAgQCkslfM98B3y6aA0gFsWr/X+cGDlZ8YpgokJHAZVd2wiTJXB1iXXTpNAFafjzjX86Ln6qS5pDrms4hWEQYGABR1wP4KiylS6CLDesUsTxYEPIne1mrGWQ53u65jBBisf8NqBfxzhZkAhfO3BW8N2+4gyhZcx/hpxJHdDE3wIxhgYw=="
+)
+
+func TestCheckSSHKey(t *testing.T) {
+	now := timeutil.TimeStampNow()
+	dsaKey := &asymkey_model.PublicKey{Content: testDSAKey, Type: asymkey_model.KeyTypeUser, CreatedUnix: now}
+	rsaKey := &asymkey_model.PublicKey{Content: testRSA1024Key, Type: asymkey_model.KeyTypeUser, CreatedUnix: now}
+	oldKey := &asymkey_model.PublicKey{Content: testRSA1024Key, Type: asymkey_model.KeyTypeUser, CreatedUnix: now.Add(-3 * 366 * 24 * 60 * 60)}
+	principal := &asymkey_model.PublicKey{Content: "user@example.com", Type: asymkey_model.KeyTypePrincipal}
+
+	policy := &organization.OrgKeyPolicy{}
+	assert.Empty(t, CheckSSHKey(policy, dsaKey))
+	assert.Empty(t, CheckSSHKey(policy, oldKey))
+
+	policy = &organization.OrgKeyPolicy{SSHKeyMaxAgeYears: 2, MinRSAKeySize: 2048, ForbidDSAKeys: true}
+	assert.Equal(t, KeyPolicyViolationDSAKey, CheckSSHKey(policy, dsaKey))
+	assert.Equal(t, KeyPolicyViolationSmallRSAKey, CheckSSHKey(policy, rsaKey))
+	assert.Equal(t, KeyPolicyViolationTooOld, CheckSSHKey(policy, oldKey))
+	assert.Empty(t, CheckSSHKey(policy, principal))
+
+	policy.MinRSAKeySize = 1024
+	assert.Empty(t, CheckSSHKey(policy, rsaKey))
+}
+
+func TestGetKeyPolicyCompliance(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	org := unittest.AssertExistsAndLoadBean(t, &organization.Organization{ID: 3}).(*organization.Organization)
+
+	policy := &organization.OrgKeyPolicy{OrgID: org.ID, MinRSAKeySize: 2048, RequireGPGKey: true}
+	assert.NoError(t, organization.SetKeyPolicy(db.DefaultContext, policy))
+	policy.MinRSAKeySize = 4096
+	assert.NoError(t, organization.SetKeyPolicy(db.DefaultContext, policy))
+	policy, err := organization.GetKeyPolicyByOrgID(db.DefaultContext, org.ID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4096, policy.MinRSAKeySize)
+	assert.True(t, policy.RequireGPGKey)
+
+	compliances, err := GetKeyPolicyCompliance(db.DefaultContext, org, policy)
+	assert.NoError(t, err)
+	for _, compliance := range compliances {
+		assert.False(t, compliance.IsCompliant())
+		assert.Contains(t, compliance.Violations, KeyPolicyViolationNoGPGKey)
+		if compliance.Member.ID == 2 {
+			// the 3072 bit RSA key of the fixtures is too small
+			if assert.Len(t, compliance.ViolatingKeys, 1) {
+				assert.Equal(t, KeyPolicyViolationSmallRSAKey, compliance.ViolatingKeys[0].Violation)
+			}
+		}
+	}
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	compliance, err := GetMemberKeyCompliance(db.DefaultContext, &organization.OrgKeyPolicy{MinRSAKeySize: 2048}, user)
+	assert.NoError(t, err)
+	assert.True(t, compliance.IsCompliant())
+
+	key := unittest.AssertExistsAndLoadBean(t, &asymkey_model.PublicKey{ID: 1}).(*asymkey_model.PublicKey)
+	err = CheckSSHKeyForOrg(db.DefaultContext, org.AsUser(), key)
+	assert.True(t, IsErrKeyPolicyViolation(err))
+	policy.MinRSAKeySize = 2048
+	assert.NoError(t, organization.SetKeyPolicy(db.DefaultContext, policy))
+	assert.NoError(t, CheckSSHKeyForOrg(db.DefaultContext, org.AsUser(), key))
+}
@@ -216,6 +216,15 @@ Loop:
 	return true, signingKey, sig, nil
 }
 
+// SignTag determines if a tag can be signed with the key of the instance
+func SignTag(ctx context.Context, repoPath string) (bool, string, *git.Signature, error) {
+	signingKey, sig := SigningKey(ctx, repoPath)
+	if signingKey == "" {
+		return false, "", nil, &ErrWontSign{noKey}
+	}
+	return true, signingKey, sig, nil
+}
+
 // SignCRUDAction determines if we should sign a CRUD commit to this repository
 func SignCRUDAction(ctx context.Context, repoPath string, u *user_model.User, tmpBasePath, parentCommit string) (bool, string, *git.Signature, error) {
 	rules := signingModeFromStrings(setting.Repository.Signing.CRUDActions)
@@ -310,7 +319,7 @@ Loop:
 				return false, "", nil, &ErrWontSign{twofa}
 			}
 		case approved:
-			protectedBranch, err := models.GetProtectedBranchBy(ctx, repo.ID, pr.BaseBranch)
+			protectedBranch, err := models.GetEffectiveProtectedBranch(ctx, repo.ID, pr.BaseBranch)
 			if err != nil {
 				return false, "", nil, err
 			}
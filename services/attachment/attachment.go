@@ -7,14 +7,18 @@ package attachment
 import (
 	"bytes"
 	"context"
+	"encoding/hex"
 	"fmt"
 	"io"
+	"strings"
 
 	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/upload"
 	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/modules/validation"
 
 	"github.com/google/uuid"
 )
@@ -33,6 +37,13 @@ func NewAttachment(attach *repo_model.Attachment, file io.Reader) (*repo_model.A
 		}
 		attach.Size = size
 
+		if err := checkRepoAttachmentSize(ctx, attach.RepoID, size); err != nil {
+			if err := storage.Attachments.Delete(attach.RelativePath()); err != nil {
+				log.Error("Unable to delete the file of attachment %s: %v", attach.UUID, err)
+			}
+			return err
+		}
+
 		return db.Insert(ctx, attach)
 	})
 
@@ -56,3 +67,78 @@ func UploadAttachment(file io.Reader, actorID, repoID, releaseID int64, fileName
 		Name:       fileName,
 	}, io.MultiReader(bytes.NewReader(buf), file))
 }
+
+// ErrInvalidExternalAttachment represents an external attachment with an invalid name, url or checksum
+type ErrInvalidExternalAttachment struct {
+	Name   string
+	Reason string
+}
+
+// IsErrInvalidExternalAttachment checks if an error is a ErrInvalidExternalAttachment.
+func IsErrInvalidExternalAttachment(err error) bool {
+	_, ok := err.(ErrInvalidExternalAttachment)
+	return ok
+}
+
+func (err ErrInvalidExternalAttachment) Error() string {
+	return fmt.Sprintf("invalid external attachment [name: %s]: %s", err.Name, err.Reason)
+}
+
+// checksumLengths are the lengths of the hex digests of the supported checksum algorithms
+var checksumLengths = map[string]int{
+	"md5":    32,
+	"sha1":   40,
+	"sha256": 64,
+	"sha512": 128,
+}
+
+// normalizeChecksum returns the checksum given as "<algorithm>:<hex digest>" in lower case, or false if it's invalid
+func normalizeChecksum(checksum string) (string, bool) {
+	checksum = strings.ToLower(strings.TrimSpace(checksum))
+	idx := strings.IndexByte(checksum, ':')
+	if idx < 0 {
+		return "", false
+	}
+	digest := checksum[idx+1:]
+	if length, ok := checksumLengths[checksum[:idx]]; !ok || len(digest) != length {
+		return "", false
+	}
+	if _, err := hex.DecodeString(digest); err != nil {
+		return "", false
+	}
+	return checksum, true
+}
+
+// ValidateExternalAttachment checks the name, url and checksum of an attachment linking to an external file
+// and normalizes them
+func ValidateExternalAttachment(attach *repo_model.Attachment) error {
+	attach.Name = strings.TrimSpace(attach.Name)
+	attach.ExternalURL = strings.TrimSpace(attach.ExternalURL)
+	if attach.Name == "" {
+		return ErrInvalidExternalAttachment{Name: attach.Name, Reason: "name is empty"}
+	}
+	if !validation.IsValidURL(attach.ExternalURL) {
+		return ErrInvalidExternalAttachment{Name: attach.Name, Reason: "url is not a valid http or https url"}
+	}
+	if attach.Checksum != "" {
+		checksum, ok := normalizeChecksum(attach.Checksum)
+		if !ok {
+			return ErrInvalidExternalAttachment{Name: attach.Name, Reason: "checksum must be md5, sha1, sha256 or sha512 given as algorithm:digest"}
+		}
+		attach.Checksum = checksum
+	}
+	return nil
+}
+
+// NewExternalAttachment creates a new attachment linking to a file which is hosted elsewhere
+func NewExternalAttachment(attach *repo_model.Attachment) (*repo_model.Attachment, error) {
+	if attach.RepoID == 0 {
+		return nil, fmt.Errorf("attachment %s should belong to a repository", attach.Name)
+	}
+	if err := ValidateExternalAttachment(attach); err != nil {
+		return nil, err
+	}
+
+	attach.UUID = uuid.New().String()
+	return attach, db.Insert(db.DefaultContext, attach)
+}
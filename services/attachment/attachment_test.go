@@ -7,6 +7,7 @@ package attachment
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"code.gitea.io/gitea/models/db"
@@ -45,3 +46,33 @@ func TestUploadAttachment(t *testing.T) {
 	assert.EqualValues(t, user.ID, attachment.UploaderID)
 	assert.Equal(t, int64(0), attachment.DownloadCount)
 }
+
+func TestNewExternalAttachment(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	attach, err := NewExternalAttachment(&repo_model.Attachment{
+		RepoID:      1,
+		UploaderID:  2,
+		Name:        " gitea-linux-amd64 ",
+		ExternalURL: "https://cdn.example.com/gitea-linux-amd64",
+		Checksum:    "SHA256:" + strings.Repeat("AB", 32),
+	})
+	assert.NoError(t, err)
+	assert.True(t, attach.IsExternal())
+
+	attachment, err := repo_model.GetAttachmentByUUID(db.DefaultContext, attach.UUID)
+	assert.NoError(t, err)
+	assert.Equal(t, "gitea-linux-amd64", attachment.Name)
+	assert.Equal(t, "sha256:"+strings.Repeat("ab", 32), attachment.Checksum)
+
+	for _, invalid := range []*repo_model.Attachment{
+		{RepoID: 1, Name: "", ExternalURL: "https://cdn.example.com/file"},
+		{RepoID: 1, Name: "file", ExternalURL: "ftp://cdn.example.com/file"},
+		{RepoID: 1, Name: "file", ExternalURL: "https://cdn.example.com/file", Checksum: "sha256:abc"},
+		{RepoID: 1, Name: "file", ExternalURL: "https://cdn.example.com/file", Checksum: "crc32:" + strings.Repeat("0", 8)},
+		{RepoID: 1, Name: "file", ExternalURL: "https://cdn.example.com/file", Checksum: "md5:" + strings.Repeat("x", 32)},
+	} {
+		_, err := NewExternalAttachment(invalid)
+		assert.True(t, IsErrInvalidExternalAttachment(err), invalid.Checksum)
+	}
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrRepoAttachmentsTooLarge represents a "RepoAttachmentsTooLarge" kind of error.
+type ErrRepoAttachmentsTooLarge struct {
+	RepoID     int64
+	Used       int64
+	Additional int64
+	MaxSize    int64
+}
+
+// IsErrRepoAttachmentsTooLarge checks if an error is a ErrRepoAttachmentsTooLarge.
+func IsErrRepoAttachmentsTooLarge(err error) bool {
+	_, ok := err.(ErrRepoAttachmentsTooLarge)
+	return ok
+}
+
+func (err ErrRepoAttachmentsTooLarge) Error() string {
+	return fmt.Sprintf("attachments of repository %d are too large: %s used of %s, %s more would be needed",
+		err.RepoID, base.FileSize(err.Used), base.FileSize(err.MaxSize), base.FileSize(err.Additional))
+}
+
+// checkRepoAttachmentSize returns ErrRepoAttachmentsTooLarge if storing additional bytes of attachments
+// exceeds the attachment size allowed per repository
+func checkRepoAttachmentSize(ctx context.Context, repoID, additional int64) error {
+	if setting.Attachment.MaxRepoSize <= 0 {
+		return nil
+	}
+	used, err := repo_model.SumRepoAttachmentSize(ctx, repoID)
+	if err != nil {
+		return err
+	}
+	if used+additional > setting.Attachment.MaxRepoSize {
+		return ErrRepoAttachmentsTooLarge{RepoID: repoID, Used: used, Additional: additional, MaxSize: setting.Attachment.MaxRepoSize}
+	}
+	return nil
+}
+
+// expireBatchSize is the number of attachments loaded at once while expiring attachments
+const expireBatchSize = 100
+
+// ExpireAttachments deletes the orphaned attachments older than ORPHAN_MAX_AGE and deletes or moves
+// the attachments of issues, comments and releases older than MAX_AGE to the cold storage
+func ExpireAttachments(ctx context.Context) error {
+	if setting.Attachment.OrphanMaxAge > 0 {
+		olderThan := timeutil.TimeStampNow().AddDuration(-setting.Attachment.OrphanMaxAge)
+		for {
+			attachments, err := repo_model.FindExpiredOrphanedAttachments(ctx, olderThan, expireBatchSize)
+			if err != nil {
+				return err
+			}
+			if len(attachments) == 0 {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return db.ErrCancelledf("before deleting %d orphaned attachments", len(attachments))
+			default:
+			}
+			if _, err := repo_model.DeleteAttachments(ctx, attachments, false); err != nil {
+				return err
+			}
+			for _, attach := range attachments {
+				deleteAttachmentFile(attach)
+			}
+			log.Trace("Deleted %d orphaned attachments", len(attachments))
+		}
+	}
+
+	if setting.Attachment.MaxAge > 0 {
+		olderThan := timeutil.TimeStampNow().AddDuration(-setting.Attachment.MaxAge)
+		for {
+			attachments, err := repo_model.FindExpiredAttachments(ctx, olderThan, expireBatchSize)
+			if err != nil {
+				return err
+			}
+			if len(attachments) == 0 {
+				break
+			}
+			select {
+			case <-ctx.Done():
+				return db.ErrCancelledf("before expiring %d attachments", len(attachments))
+			default:
+			}
+			if setting.Attachment.ExpiredAction == setting.AttachmentExpiredActionCold {
+				for _, attach := range attachments {
+					if err := moveAttachmentToColdStorage(ctx, attach); err != nil {
+						return fmt.Errorf("moveAttachmentToColdStorage [uuid: %s]: %v", attach.UUID, err)
+					}
+				}
+				log.Trace("Moved %d expired attachments to the cold storage", len(attachments))
+				continue
+			}
+			if _, err := repo_model.DeleteAttachments(ctx, attachments, false); err != nil {
+				return err
+			}
+			for _, attach := range attachments {
+				deleteAttachmentFile(attach)
+			}
+			log.Trace("Deleted %d expired attachments", len(attachments))
+		}
+	}
+
+	return nil
+}
+
+// deleteAttachmentFile removes the file of an attachment which has already been removed from the database,
+// a failure is only logged as the attachment is gone anyway
+func deleteAttachmentFile(attach *repo_model.Attachment) {
+	if attach.IsExternal() {
+		return
+	}
+	if err := attach.Storage().Delete(attach.RelativePath()); err != nil {
+		log.Error("Unable to delete the file of attachment %s: %v", attach.UUID, err)
+	}
+}
+
+// moveAttachmentToColdStorage copies the file of the attachment to the cold storage before it's removed
+// from the primary storage, so the attachment stays downloadable all the time
+func moveAttachmentToColdStorage(ctx context.Context, attach *repo_model.Attachment) error {
+	if _, err := storage.Copy(storage.AttachmentsCold, attach.RelativePath(), storage.Attachments, attach.RelativePath()); err != nil {
+		return err
+	}
+	if err := repo_model.MarkAttachmentCold(ctx, attach); err != nil {
+		return err
+	}
+	if err := storage.Attachments.Delete(attach.RelativePath()); err != nil {
+		log.Error("Unable to delete the file of attachment %s moved to the cold storage: %v", attach.UUID, err)
+	}
+	return nil
+}
@@ -0,0 +1,96 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUploadAttachmentMaxRepoSize(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	defer func(maxRepoSize int64) {
+		setting.Attachment.MaxRepoSize = maxRepoSize
+	}(setting.Attachment.MaxRepoSize)
+	setting.Attachment.MaxRepoSize = 10
+
+	attach, err := NewAttachment(&repo_model.Attachment{RepoID: 1, Name: "small.txt"}, strings.NewReader("small"))
+	assert.NoError(t, err)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{UUID: attach.UUID})
+
+	attach, err = NewAttachment(&repo_model.Attachment{RepoID: 1, Name: "large.txt"}, strings.NewReader("too large"))
+	assert.True(t, IsErrRepoAttachmentsTooLarge(err))
+	unittest.AssertNotExistsBean(t, &repo_model.Attachment{UUID: attach.UUID})
+	_, err = storage.Attachments.Stat(attach.RelativePath())
+	assert.Error(t, err)
+
+	// other repositories have their own limit
+	_, err = NewAttachment(&repo_model.Attachment{RepoID: 2, Name: "large.txt"}, strings.NewReader("too large"))
+	assert.NoError(t, err)
+}
+
+func TestExpireOrphanedAttachments(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	defer func(orphanMaxAge time.Duration) {
+		setting.Attachment.OrphanMaxAge = orphanMaxAge
+	}(setting.Attachment.OrphanMaxAge)
+	setting.Attachment.OrphanMaxAge = 24 * time.Hour
+
+	attach, err := NewAttachment(&repo_model.Attachment{RepoID: 1, Name: "new.txt"}, strings.NewReader("new"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, ExpireAttachments(db.DefaultContext))
+
+	unittest.AssertNotExistsBean(t, &repo_model.Attachment{ID: 10})
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{ID: 1})
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{ID: 9})
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{UUID: attach.UUID})
+}
+
+func TestExpireAttachmentsToColdStorage(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	defer func(maxAge time.Duration, action string) {
+		setting.Attachment.MaxAge = maxAge
+		setting.Attachment.ExpiredAction = action
+	}(setting.Attachment.MaxAge, setting.Attachment.ExpiredAction)
+	setting.Attachment.MaxAge = 24 * time.Hour
+	setting.Attachment.ExpiredAction = setting.AttachmentExpiredActionCold
+
+	// the files of the fixtures don't exist, so only an attachment with a file is left
+	_, err := db.GetEngine(db.DefaultContext).Where("1=1").Delete(new(repo_model.Attachment))
+	assert.NoError(t, err)
+
+	attach, err := NewAttachment(&repo_model.Attachment{RepoID: 1, IssueID: 1, Name: "old.txt"}, strings.NewReader("old"))
+	assert.NoError(t, err)
+	recent, err := NewAttachment(&repo_model.Attachment{RepoID: 1, IssueID: 1, Name: "recent.txt"}, strings.NewReader("recent"))
+	assert.NoError(t, err)
+	_, err = db.GetEngine(db.DefaultContext).Exec("UPDATE attachment SET created_unix = ? WHERE id = ?", timeutil.TimeStampNow().Add(-48*60*60), attach.ID)
+	assert.NoError(t, err)
+
+	assert.NoError(t, ExpireAttachments(db.DefaultContext))
+
+	attach = unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{ID: attach.ID}).(*repo_model.Attachment)
+	assert.True(t, attach.IsCold)
+	_, err = storage.Attachments.Stat(attach.RelativePath())
+	assert.Error(t, err)
+	_, err = storage.AttachmentsCold.Stat(attach.RelativePath())
+	assert.NoError(t, err)
+
+	recent = unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{ID: recent.ID}).(*repo_model.Attachment)
+	assert.False(t, recent.IsCold)
+}
@@ -0,0 +1,149 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/jpeg"
+	"image/png"
+	"io"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+
+	"github.com/nfnt/resize"
+)
+
+// maxOptimizedPixels is the number of pixels of the largest image which is optimized,
+// larger images are kept as they are to bound the memory used to decode them
+const maxOptimizedPixels = 50_000_000
+
+// imageOptimizationQueue represents a queue of the uuids of the attachments to optimize
+var imageOptimizationQueue queue.UniqueQueue
+
+// Init runs the task queue optimizing pasted images
+func Init() error {
+	imageOptimizationQueue = queue.CreateUniqueQueue("attachment_image_optimization", handleImageOptimization, "")
+	if imageOptimizationQueue == nil {
+		return fmt.Errorf("Unable to create attachment_image_optimization Queue")
+	}
+	go graceful.GetManager().RunWithShutdownFns(imageOptimizationQueue.Run)
+	return nil
+}
+
+func handleImageOptimization(data ...queue.Data) []queue.Data {
+	for _, d := range data {
+		uuid := d.(string)
+		if err := OptimizeImageAttachment(graceful.GetManager().ShutdownContext(), uuid); err != nil {
+			log.Error("Unable to optimize the image of attachment %s: %v", uuid, err)
+		}
+	}
+	return nil
+}
+
+// ScheduleImageOptimization adds an attachment to the queue optimizing images if image optimization is enabled
+func ScheduleImageOptimization(attach *repo_model.Attachment) {
+	if !setting.Attachment.ImageOptimization.Enabled || imageOptimizationQueue == nil || attach.IsExternal() {
+		return
+	}
+	if err := imageOptimizationQueue.Push(attach.UUID); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to add attachment %s to the attachment_image_optimization queue: %v", attach.UUID, err)
+	}
+}
+
+// OptimizeImageAttachment replaces the file of an attachment with its optimized image,
+// nothing is done if the attachment doesn't exist anymore or isn't a PNG or JPEG image
+func OptimizeImageAttachment(ctx context.Context, uuid string) error {
+	attach, err := repo_model.GetAttachmentByUUID(ctx, uuid)
+	if err != nil {
+		if repo_model.IsErrAttachmentNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if attach.IsExternal() || attach.IsCold {
+		return nil
+	}
+
+	f, err := storage.Attachments.Open(attach.RelativePath())
+	if err != nil {
+		return fmt.Errorf("Open: %v", err)
+	}
+	data, err := io.ReadAll(io.LimitReader(f, setting.Attachment.MaxSize<<20+1))
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("ReadAll: %v", err)
+	}
+
+	opts := setting.Attachment.ImageOptimization
+	optimized, err := OptimizeImage(data, opts.MaxWidth, opts.MaxHeight, opts.JPEGQuality)
+	if err != nil || optimized == nil {
+		return err
+	}
+
+	size, err := storage.Attachments.Save(attach.RelativePath(), bytes.NewReader(optimized), int64(len(optimized)))
+	if err != nil {
+		return fmt.Errorf("Save: %v", err)
+	}
+	attach.Size = size
+	if err := repo_model.UpdateAttachmentByUUID(ctx, attach, "size"); err != nil {
+		return err
+	}
+
+	// the attachment might have been deleted while its image was optimized
+	if _, err := repo_model.GetAttachmentByUUID(ctx, uuid); repo_model.IsErrAttachmentNotExist(err) {
+		return storage.Attachments.Delete(attach.RelativePath())
+	}
+	log.Trace("Image of attachment %s optimized from %d to %d bytes", uuid, len(data), size)
+	return nil
+}
+
+// OptimizeImage re-encodes a PNG or JPEG image, which strips its metadata, and shrinks it to fit into
+// maxWidth x maxHeight if these aren't 0. JPEG images are recompressed with the quality.
+// It returns nil if the data isn't a PNG or JPEG image or the image is too large to be optimized.
+func OptimizeImage(data []byte, maxWidth, maxHeight, quality int) ([]byte, error) {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil || (format != "png" && format != "jpeg") {
+		return nil, nil
+	}
+	if int64(cfg.Width)*int64(cfg.Height) > maxOptimizedPixels {
+		return nil, nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("Decode: %v", err)
+	}
+
+	width, height := cfg.Width, cfg.Height
+	if maxWidth <= 0 {
+		maxWidth = width
+	}
+	if maxHeight <= 0 {
+		maxHeight = height
+	}
+	if width > maxWidth || height > maxHeight {
+		img = resize.Thumbnail(uint(maxWidth), uint(maxHeight), img, resize.Lanczos3)
+	}
+
+	var buf bytes.Buffer
+	if format == "png" {
+		encoder := png.Encoder{CompressionLevel: png.BestCompression}
+		err = encoder.Encode(&buf, img)
+	} else {
+		err = jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("Encode: %v", err)
+	}
+	return buf.Bytes(), nil
+}
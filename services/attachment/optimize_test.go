@@ -0,0 +1,101 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package attachment
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func testImage(width, height int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	for x := 0; x < width; x++ {
+		for y := 0; y < height; y++ {
+			img.Set(x, y, color.RGBA{R: uint8(x), G: uint8(y), B: 128, A: 255})
+		}
+	}
+	return img
+}
+
+func TestOptimizeImage(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, testImage(200, 100)))
+
+	optimized, err := OptimizeImage(buf.Bytes(), 0, 0, 85)
+	assert.NoError(t, err)
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(optimized))
+	assert.NoError(t, err)
+	assert.Equal(t, "png", format)
+	assert.Equal(t, 200, cfg.Width)
+	assert.Equal(t, 100, cfg.Height)
+
+	// the aspect ratio is kept when shrinking
+	optimized, err = OptimizeImage(buf.Bytes(), 100, 0, 85)
+	assert.NoError(t, err)
+	cfg, _, err = image.DecodeConfig(bytes.NewReader(optimized))
+	assert.NoError(t, err)
+	assert.Equal(t, 100, cfg.Width)
+	assert.Equal(t, 50, cfg.Height)
+
+	buf.Reset()
+	assert.NoError(t, jpeg.Encode(&buf, testImage(200, 100), &jpeg.Options{Quality: 100}))
+	optimized, err = OptimizeImage(buf.Bytes(), 0, 80, 50)
+	assert.NoError(t, err)
+	cfg, format, err = image.DecodeConfig(bytes.NewReader(optimized))
+	assert.NoError(t, err)
+	assert.Equal(t, "jpeg", format)
+	assert.Equal(t, 160, cfg.Width)
+	assert.Equal(t, 80, cfg.Height)
+	assert.Less(t, len(optimized), buf.Len())
+
+	// other files are kept as they are
+	optimized, err = OptimizeImage([]byte("GIF89a not really"), 0, 0, 85)
+	assert.NoError(t, err)
+	assert.Nil(t, optimized)
+}
+
+func TestOptimizeImageAttachment(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	defer func(maxWidth int) {
+		setting.Attachment.ImageOptimization.MaxWidth = maxWidth
+	}(setting.Attachment.ImageOptimization.MaxWidth)
+	setting.Attachment.ImageOptimization.MaxWidth = 50
+
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, testImage(200, 100)))
+	attach, err := NewAttachment(&repo_model.Attachment{
+		RepoID:     1,
+		UploaderID: 2,
+		Name:       "image.png",
+	}, bytes.NewReader(buf.Bytes()))
+	assert.NoError(t, err)
+
+	assert.NoError(t, OptimizeImageAttachment(db.DefaultContext, attach.UUID))
+
+	attach = unittest.AssertExistsAndLoadBean(t, &repo_model.Attachment{UUID: attach.UUID}).(*repo_model.Attachment)
+	assert.Less(t, attach.Size, int64(buf.Len()))
+	f, err := attach.Storage().Open(attach.RelativePath())
+	assert.NoError(t, err)
+	defer f.Close()
+	cfg, _, err := image.DecodeConfig(f)
+	assert.NoError(t, err)
+	assert.Equal(t, 50, cfg.Width)
+	assert.Equal(t, 25, cfg.Height)
+
+	// attachments which don't exist anymore are skipped
+	assert.NoError(t, OptimizeImageAttachment(db.DefaultContext, "00000000-0000-0000-0000-000000000000"))
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package audit
+
+import (
+	"context"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/audit"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Record records an event in the instance-wide audit log and forwards it to the configured collectors.
+// The doer is nil if nobody is signed in, e.g. for failed sign in attempts.
+// Failures are logged but never interrupt the caller.
+func Record(ctx context.Context, doer *user_model.User, ip string, action admin_model.AuditAction, target, detail string) {
+	if !setting.Audit.Enabled {
+		return
+	}
+
+	event := &admin_model.AuditEvent{
+		Action: action,
+		IP:     ip,
+		Target: target,
+		Detail: detail,
+	}
+	if doer != nil {
+		event.DoerID = doer.ID
+		event.DoerName = doer.Name
+	}
+	if err := admin_model.InsertAuditEvent(ctx, event); err != nil {
+		log.Error("InsertAuditEvent [%s %s]: %v", action, target, err)
+		return
+	}
+
+	audit.Forward(ToEvent(event))
+}
+
+// ToEvent converts an event of the audit log into the representation sent to external collectors
+func ToEvent(event *admin_model.AuditEvent) *audit.Event {
+	return &audit.Event{
+		ID:       event.ID,
+		Time:     event.CreatedUnix.AsTime(),
+		Category: event.Action.Category(),
+		Action:   string(event.Action),
+		DoerID:   event.DoerID,
+		DoerName: event.DoerName,
+		IP:       event.IP,
+		Target:   event.Target,
+		Detail:   event.Detail,
+	}
+}
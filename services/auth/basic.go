@@ -10,6 +10,7 @@ import (
 	"strings"
 
 	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/base"
 	"code.gitea.io/gitea/modules/log"
@@ -71,6 +72,11 @@ func (b *Basic) Verify(req *http.Request, w http.ResponseWriter, store DataStore
 		log.Trace("Basic Authorization: Attempting login with username as token")
 	}
 
+	// Deploy tokens don't belong to a user, they are checked by the handlers they grant access to
+	if repo_model.IsDeployToken(authToken) {
+		return nil
+	}
+
 	uid := CheckOAuthAccessToken(authToken)
 	if uid != 0 {
 		log.Trace("Basic Authorization: Valid OAuthAccessToken for user[%d]", uid)
@@ -87,6 +93,9 @@ func (b *Basic) Verify(req *http.Request, w http.ResponseWriter, store DataStore
 
 	token, err := models.GetAccessTokenBySHA(authToken)
 	if err == nil {
+		if !isAccessTokenAllowed(req, token) {
+			return nil
+		}
 		log.Trace("Basic Authorization: Valid AccessToken for user[%d]", uid)
 		u, err := user_model.GetUserByID(token.UID)
 		if err != nil {
@@ -110,9 +119,11 @@ func (b *Basic) Verify(req *http.Request, w http.ResponseWriter, store DataStore
 	}
 
 	log.Trace("Basic Authorization: Attempting SignIn for %s", uname)
-	u, source, err := UserSignIn(uname, passwd)
+	u, source, err := UserSignIn(uname, passwd, req.RemoteAddr)
 	if err != nil {
-		if !user_model.IsErrUserNotExist(err) {
+		if IsErrSourceRestricted(err) {
+			log.Warn("UserSignIn: %v", err)
+		} else if !user_model.IsErrUserNotExist(err) {
 			log.Error("UserSignIn: %v", err)
 		}
 		return nil
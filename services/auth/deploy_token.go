@@ -0,0 +1,67 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// deployTokenDataKey is the key of the deploy token of a request in the data of its context
+const deployTokenDataKey = "DeployToken"
+
+// deployTokenLastUsedInterval is the minimum time between two updates of the last use of a deploy token
+const deployTokenLastUsedInterval = time.Minute
+
+// DeployTokenFromRequest returns the deploy token sent with HTTP basic authentication, either as the password
+// or as the username with an empty password. It returns nil if there is none or it is unknown or expired.
+// Deploy tokens don't sign in a user, the handlers of git HTTP, LFS and packages check their scopes themselves.
+// The token is resolved once per request and kept in the data of the request context.
+func DeployTokenFromRequest(req *http.Request, data map[string]interface{}) *repo_model.DeployToken {
+	if cached, ok := data[deployTokenDataKey]; ok {
+		token, _ := cached.(*repo_model.DeployToken)
+		return token
+	}
+	token := deployTokenFromRequest(req)
+	data[deployTokenDataKey] = token
+	return token
+}
+
+func deployTokenFromRequest(req *http.Request) *repo_model.DeployToken {
+	auths := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(auths) != 2 || strings.ToLower(auths[0]) != "basic" {
+		return nil
+	}
+
+	uname, passwd, _ := base.BasicAuthDecode(auths[1])
+	authToken := passwd
+	if len(passwd) == 0 || passwd == "x-oauth-basic" {
+		authToken = uname
+	}
+	if !repo_model.IsDeployToken(authToken) {
+		return nil
+	}
+
+	token, err := repo_model.GetDeployTokenByToken(req.Context(), authToken)
+	if err != nil {
+		if !repo_model.IsErrDeployTokenNotExist(err) {
+			log.Error("GetDeployTokenByToken: %v", err)
+		}
+		return nil
+	}
+	// git clients send several requests for one fetch, there's no need to record each of them
+	if !token.HasUsed || token.UpdatedUnix.AddDuration(deployTokenLastUsedInterval) <= timeutil.TimeStampNow() {
+		if err := repo_model.UpdateDeployTokenLastUsed(req.Context(), token); err != nil {
+			log.Error("UpdateDeployTokenLastUsed: %v", err)
+		}
+	}
+	return token
+}
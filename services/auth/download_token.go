@@ -0,0 +1,71 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"net/http"
+	"regexp"
+	"strings"
+
+	auth_model "code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// Ensure the struct implements the interface.
+var (
+	_ Method = &DownloadToken{}
+	_ Named  = &DownloadToken{}
+)
+
+var rawOrArchivePathRe = regexp.MustCompile(`^/[a-zA-Z0-9_.-]+/[a-zA-Z0-9_.-]+/(?:raw|media|archive)/`)
+
+// DownloadToken implements the Auth interface and authenticates raw file and archive downloads
+// by a download token in the "download_token" query parameter or the "Authorization" header.
+// The scopes of the token are checked by the download routes, which find it as "DownloadToken" in the data store.
+type DownloadToken struct{}
+
+// Name represents the name of auth method
+func (d *DownloadToken) Name() string {
+	return "download_token"
+}
+
+// Verify returns the owner of the download token of a raw file or archive download
+func (d *DownloadToken) Verify(req *http.Request, w http.ResponseWriter, store DataStore, sess SessionStore) *user_model.User {
+	if !db.HasEngine || (req.Method != http.MethodGet && req.Method != http.MethodHead) || !rawOrArchivePathRe.MatchString(req.URL.Path) {
+		return nil
+	}
+
+	token := req.URL.Query().Get("download_token")
+	if token == "" {
+		auths := strings.Fields(req.Header.Get("Authorization"))
+		if len(auths) == 2 && auths[0] == "token" {
+			token = auths[1]
+		}
+	}
+	if token == "" {
+		return nil
+	}
+
+	t, err := auth_model.GetDownloadTokenBySHA(req.Context(), token)
+	if err != nil {
+		if !auth_model.IsErrDownloadTokenNotExist(err) {
+			log.Error("GetDownloadTokenBySHA: %v", err)
+		}
+		return nil
+	}
+	u, err := user_model.GetUserByID(t.UID)
+	if err != nil {
+		log.Error("GetUserByID: %v", err)
+		return nil
+	}
+	if !u.IsActive || u.ProhibitLogin {
+		return nil
+	}
+
+	store.GetData()["DownloadToken"] = t
+	return u
+}
@@ -7,6 +7,7 @@ package auth
 import (
 	"context"
 	"net/http"
+	"time"
 
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/session"
@@ -59,7 +60,8 @@ type LocalTwoFASkipper interface {
 	IsSkipLocalTwoFA() bool
 }
 
-// SynchronizableSource represents a source that can synchronize users
+// SynchronizableSource represents a source that can synchronize users,
+// sources which can detect the changes of their users only synchronize those until a full sync is due
 type SynchronizableSource interface {
-	Sync(ctx context.Context, updateExisting bool) error
+	Sync(ctx context.Context, updateExisting bool, fullSyncInterval time.Duration) error
 }
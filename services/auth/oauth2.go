@@ -98,6 +98,9 @@ func (o *OAuth2) userIDFromToken(req *http.Request, store DataStore) int64 {
 		}
 		return 0
 	}
+	if !isAccessTokenAllowed(req, t) {
+		return 0
+	}
 	t.UpdatedUnix = timeutil.TimeStampNow()
 	if err = models.UpdateAccessToken(t); err != nil {
 		log.Error("UpdateAccessToken: %v", err)
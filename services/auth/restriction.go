@@ -0,0 +1,207 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/auth"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/hostmatcher"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	audit_service "code.gitea.io/gitea/services/audit"
+)
+
+// ErrSourceRestricted represents a sign-in which isn't allowed by the restrictions of the authentication source
+type ErrSourceRestricted struct {
+	SourceName string
+	RemoteAddr string
+	Reason     string
+}
+
+// IsErrSourceRestricted checks if an error is a ErrSourceRestricted.
+func IsErrSourceRestricted(err error) bool {
+	_, ok := err.(ErrSourceRestricted)
+	return ok
+}
+
+func (err ErrSourceRestricted) Error() string {
+	return fmt.Sprintf("sign-in via authentication source '%s' from '%s' is restricted: %s", err.SourceName, err.RemoteAddr, err.Reason)
+}
+
+var weekdayNames = []string{"sun", "mon", "tue", "wed", "thu", "fri", "sat"}
+
+// AccessTimeWindow is a time of the day on some days of the week the users of a source can sign in at
+type AccessTimeWindow struct {
+	Weekdays [7]bool
+	// Start and End are the minutes of the day, a window with an End before its Start spans midnight
+	Start, End int
+}
+
+// Contains returns true if the time is in the window, the day of a window spanning midnight is the day it starts at
+func (w *AccessTimeWindow) Contains(t time.Time) bool {
+	minute := t.Hour()*60 + t.Minute()
+	if w.Start <= w.End {
+		return w.Weekdays[t.Weekday()] && minute >= w.Start && minute < w.End
+	}
+	if minute >= w.Start {
+		return w.Weekdays[t.Weekday()]
+	}
+	return minute < w.End && w.Weekdays[(t.Weekday()+6)%7]
+}
+
+func parseWeekday(s string) (time.Weekday, error) {
+	for i, name := range weekdayNames {
+		if strings.EqualFold(s, name) {
+			return time.Weekday(i), nil
+		}
+	}
+	return 0, fmt.Errorf("invalid day of the week %q", s)
+}
+
+func parseTimeOfDay(s string) (int, error) {
+	t, err := time.Parse("15:04", s)
+	if err != nil {
+		if s == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, fmt.Errorf("invalid time of the day %q", s)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// ParseAccessTimeWindows parses a comma separated list of access time windows like "Mon-Fri 08:00-18:00, Sat 09:00-12:00",
+// a window without days applies to all the days of the week
+func ParseAccessTimeWindows(windows string) ([]*AccessTimeWindow, error) {
+	var result []*AccessTimeWindow
+	for _, s := range strings.Split(windows, ",") {
+		fields := strings.Fields(s)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("invalid access time window %q", strings.TrimSpace(s))
+		}
+
+		w := &AccessTimeWindow{}
+		if len(fields) == 1 {
+			w.Weekdays = [7]bool{true, true, true, true, true, true, true}
+		} else {
+			days := strings.SplitN(fields[0], "-", 2)
+			first, err := parseWeekday(days[0])
+			if err != nil {
+				return nil, err
+			}
+			last := first
+			if len(days) == 2 {
+				if last, err = parseWeekday(days[1]); err != nil {
+					return nil, err
+				}
+			}
+			for day := first; ; day = (day + 1) % 7 {
+				w.Weekdays[day] = true
+				if day == last {
+					break
+				}
+			}
+		}
+
+		times := strings.SplitN(fields[len(fields)-1], "-", 2)
+		if len(times) != 2 {
+			return nil, fmt.Errorf("invalid time range %q", fields[len(fields)-1])
+		}
+		var err error
+		if w.Start, err = parseTimeOfDay(times[0]); err != nil {
+			return nil, err
+		}
+		if w.End, err = parseTimeOfDay(times[1]); err != nil {
+			return nil, err
+		}
+		if w.Start == w.End {
+			return nil, fmt.Errorf("empty time range %q", fields[len(fields)-1])
+		}
+		result = append(result, w)
+	}
+	return result, nil
+}
+
+// ValidateCIDRList checks that a comma separated list only contains networks in CIDR notation, IP addresses
+// and the builtin networks of hostmatcher, which are the entries the restrictions of a source support
+func ValidateCIDRList(list string) error {
+	for _, s := range strings.Split(list, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		if s == "" || s == hostmatcher.MatchBuiltinExternal || s == hostmatcher.MatchBuiltinPrivate || s == hostmatcher.MatchBuiltinLoopback {
+			continue
+		}
+		if _, _, err := net.ParseCIDR(s); err == nil {
+			continue
+		}
+		if net.ParseIP(s) == nil {
+			return fmt.Errorf("invalid network %q", s)
+		}
+	}
+	return nil
+}
+
+// CheckSourceRestrictions returns an ErrSourceRestricted error if the restrictions of the source
+// don't allow to sign in from remoteAddr at the time now
+func CheckSourceRestrictions(source *auth.Source, remoteAddr string, now time.Time) error {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		host = remoteAddr
+	}
+	ip := net.ParseIP(host)
+	restricted := func(reason string) error {
+		return ErrSourceRestricted{SourceName: source.Name, RemoteAddr: remoteAddr, Reason: reason}
+	}
+
+	if strings.TrimSpace(source.DeniedCIDRs) != "" &&
+		hostmatcher.ParseHostMatchList("DeniedCIDRs", source.DeniedCIDRs).MatchIPAddr(ip) {
+		return restricted("the address is denied")
+	}
+	if strings.TrimSpace(source.AllowedCIDRs) != "" &&
+		!hostmatcher.ParseHostMatchList("AllowedCIDRs", source.AllowedCIDRs).MatchIPAddr(ip) {
+		return restricted("the address isn't allowed")
+	}
+
+	if strings.TrimSpace(source.AccessTimeWindows) == "" {
+		return nil
+	}
+	windows, err := ParseAccessTimeWindows(source.AccessTimeWindows)
+	if err != nil {
+		// the windows are validated when they are saved, a broken configuration must not allow every sign-in
+		return restricted(err.Error())
+	}
+	now = now.In(setting.DefaultUILocation)
+	for _, w := range windows {
+		if w.Contains(now) {
+			return nil
+		}
+	}
+	return restricted("the time is outside of the access time windows")
+}
+
+// isAccessTokenAllowed returns true if the access token can be used from the remote address of the request,
+// rejected attempts are recorded in the audit log
+func isAccessTokenAllowed(req *http.Request, token *models.AccessToken) bool {
+	if token.IsAllowedFrom(req.RemoteAddr) {
+		return true
+	}
+	log.Warn("Access token %d of user %d rejected from %s: the address isn't allowed", token.ID, token.UID, req.RemoteAddr)
+
+	target := fmt.Sprintf("%d", token.UID)
+	if u, err := user_model.GetUserByID(token.UID); err == nil {
+		target = u.Name
+	}
+	audit_service.Record(req.Context(), nil, req.RemoteAddr, admin_model.AuditActionTokenRejected, target, token.Name)
+	return false
+}
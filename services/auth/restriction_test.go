@@ -0,0 +1,89 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAccessTimeWindows(t *testing.T) {
+	windows, err := ParseAccessTimeWindows("Mon-Fri 08:00-18:00, sat 22:00-02:00,12:00-13:00")
+	assert.NoError(t, err)
+	if assert.Len(t, windows, 3) {
+		assert.Equal(t, [7]bool{false, true, true, true, true, true, false}, windows[0].Weekdays)
+		assert.Equal(t, 8*60, windows[0].Start)
+		assert.Equal(t, 18*60, windows[0].End)
+		assert.Equal(t, [7]bool{false, false, false, false, false, false, true}, windows[1].Weekdays)
+		assert.Equal(t, [7]bool{true, true, true, true, true, true, true}, windows[2].Weekdays)
+	}
+
+	windows, err = ParseAccessTimeWindows("Fri-Mon 00:00-24:00")
+	assert.NoError(t, err)
+	assert.Equal(t, [7]bool{true, true, false, false, false, true, true}, windows[0].Weekdays)
+
+	for _, invalid := range []string{"Mon-Fri", "Someday 08:00-18:00", "08:00-25:00", "08:00-08:00", "Mon 08:00-18:00 UTC"} {
+		_, err := ParseAccessTimeWindows(invalid)
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestAccessTimeWindowContains(t *testing.T) {
+	windows, err := ParseAccessTimeWindows("Mon-Fri 08:00-18:00, Sat 22:00-02:00")
+	assert.NoError(t, err)
+
+	// 2022-06-06 is a monday
+	date := func(day, hour, minute int) time.Time {
+		return time.Date(2022, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+	assert.True(t, windows[0].Contains(date(6, 8, 0)))
+	assert.True(t, windows[0].Contains(date(10, 17, 59)))
+	assert.False(t, windows[0].Contains(date(6, 18, 0)))
+	assert.False(t, windows[0].Contains(date(11, 12, 0)))
+
+	assert.True(t, windows[1].Contains(date(11, 23, 0)))
+	assert.True(t, windows[1].Contains(date(12, 1, 59)))
+	assert.False(t, windows[1].Contains(date(12, 23, 0)))
+	assert.False(t, windows[1].Contains(date(11, 1, 0)))
+}
+
+func TestValidateCIDRList(t *testing.T) {
+	assert.NoError(t, ValidateCIDRList(""))
+	assert.NoError(t, ValidateCIDRList("10.0.0.0/8, 192.168.1.5, ::1, private"))
+	assert.Error(t, ValidateCIDRList("10.0.0.0/33"))
+	assert.Error(t, ValidateCIDRList("*.example.com"))
+}
+
+func TestCheckSourceRestrictions(t *testing.T) {
+	oldLocation := setting.DefaultUILocation
+	setting.DefaultUILocation = time.UTC
+	defer func() {
+		setting.DefaultUILocation = oldLocation
+	}()
+
+	// a monday
+	now := time.Date(2022, 6, 6, 12, 0, 0, 0, time.UTC)
+
+	source := &auth.Source{Name: "ldap"}
+	assert.NoError(t, CheckSourceRestrictions(source, "203.0.113.1:1234", now))
+
+	source.AllowedCIDRs = "10.0.0.0/8, private"
+	assert.NoError(t, CheckSourceRestrictions(source, "10.1.2.3:1234", now))
+	assert.NoError(t, CheckSourceRestrictions(source, "192.168.1.1", now))
+	assert.True(t, IsErrSourceRestricted(CheckSourceRestrictions(source, "203.0.113.1:1234", now)))
+
+	source.DeniedCIDRs = "10.1.0.0/16"
+	assert.True(t, IsErrSourceRestricted(CheckSourceRestrictions(source, "10.1.2.3:1234", now)))
+	assert.NoError(t, CheckSourceRestrictions(source, "10.2.2.3:1234", now))
+
+	source.AccessTimeWindows = "Mon-Fri 08:00-18:00"
+	assert.NoError(t, CheckSourceRestrictions(source, "10.2.2.3:1234", now))
+	assert.True(t, IsErrSourceRestricted(CheckSourceRestrictions(source, "10.2.2.3:1234", now.Add(8*time.Hour))))
+}
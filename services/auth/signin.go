@@ -6,6 +6,7 @@ package auth
 
 import (
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
@@ -20,8 +21,8 @@ import (
 	_ "code.gitea.io/gitea/services/auth/source/sspi" // register the sspi source
 )
 
-// UserSignIn validates user name and password.
-func UserSignIn(username, password string) (*user_model.User, *auth.Source, error) {
+// UserSignIn validates user name and password, the sign-in from remoteAddr must be allowed by the restrictions of the source.
+func UserSignIn(username, password, remoteAddr string) (*user_model.User, *auth.Source, error) {
 	var user *user_model.User
 	isEmail := false
 	if strings.Contains(username, "@") {
@@ -65,6 +66,10 @@ func UserSignIn(username, password string) (*user_model.User, *auth.Source, erro
 				return nil, nil, oauth2.ErrAuthSourceNotActived
 			}
 
+			if err := CheckSourceRestrictions(source, remoteAddr, time.Now()); err != nil {
+				return nil, nil, err
+			}
+
 			authenticator, ok := source.Cfg.(PasswordAuthenticator)
 			if !ok {
 				return nil, nil, smtp.ErrUnsupportedLoginType
@@ -101,6 +106,11 @@ func UserSignIn(username, password string) (*user_model.User, *auth.Source, erro
 			continue
 		}
 
+		if err := CheckSourceRestrictions(source, remoteAddr, time.Now()); err != nil {
+			log.Debug("Skipping authentication source for '%s': %v", username, err)
+			continue
+		}
+
 		authUser, err := authenticator.Authenticate(nil, username, password)
 
 		if err == nil {
@@ -24,9 +24,12 @@ func Authenticate(user *user_model.User, login, password string) (*user_model.Us
 	// Or update the password when the salt length doesn't match the current
 	// recommended salt length, this in order to migrate user's salts to a more secure salt.
 	if user.PasswdHashAlgo != setting.PasswordHashAlgo || len(user.Salt) != user_model.SaltByteLength*2 {
+		// rehashing the password doesn't change it, so it doesn't restart the rotation interval
+		changedUnix := user.PasswdChangedUnix
 		if err := user.SetPassword(password); err != nil {
 			return nil, err
 		}
+		user.PasswdChangedUnix = changedUnix
 		if err := user_model.UpdateUserCols(db.DefaultContext, user, "passwd", "passwd_hash_algo", "salt"); err != nil {
 			return nil, err
 		}
@@ -32,8 +32,13 @@ type SearchResult struct {
 	Avatar         []byte
 	LdapTeamAdd    map[string][]string // organizations teams to add
 	LdapTeamRemove map[string][]string // organizations teams to remove
+	// ModifyTimestamp is the time the entry has been modified at in the generalized time format of LDAP
+	ModifyTimestamp string
 }
 
+// attributeModifyTimestamp is the operational attribute of the time an entry has been modified at
+const attributeModifyTimestamp = "modifyTimestamp"
+
 func (ls *Source) sanitizedUserQuery(username string) (string, bool) {
 	// See http://tools.ietf.org/search/rfc4515
 	badCharacters := "\x00()*\\"
@@ -473,11 +478,23 @@ func (ls *Source) UsePagedSearch() bool {
 
 // SearchEntries : search an LDAP source for all users matching userFilter
 func (ls *Source) SearchEntries() ([]*SearchResult, error) {
+	var result []*SearchResult
+	err := ls.IterateEntries("", ls.SearchPageSize, func(results []*SearchResult) error {
+		result = append(result, results...)
+		return nil
+	})
+	return result, err
+}
+
+// IterateEntries searches an LDAP source for all users matching userFilter, or only for the ones modified
+// at or after modifiedSince if it isn't empty, and calls f with the results of every page of the search.
+// The search isn't paged if pageSize is 0.
+func (ls *Source) IterateEntries(modifiedSince string, pageSize uint32, f func(results []*SearchResult) error) error {
 	l, err := dial(ls)
 	if err != nil {
 		log.Error("LDAP Connect error, %s:%v", ls.Host, err)
 		ls.Enabled = false
-		return nil, err
+		return err
 	}
 	defer l.Close()
 
@@ -485,7 +502,7 @@ func (ls *Source) SearchEntries() ([]*SearchResult, error) {
 		err := l.Bind(ls.BindDN, ls.BindPassword)
 		if err != nil {
 			log.Debug("Failed to bind as BindDN[%s]: %v", ls.BindDN, err)
-			return nil, err
+			return err
 		}
 		log.Trace("Bound as BindDN %s", ls.BindDN)
 	} else {
@@ -493,11 +510,14 @@ func (ls *Source) SearchEntries() ([]*SearchResult, error) {
 	}
 
 	userFilter := fmt.Sprintf(ls.Filter, "*")
+	if modifiedSince != "" {
+		userFilter = fmt.Sprintf("(&%s(%s>=%s))", userFilter, attributeModifyTimestamp, ldap.EscapeFilter(modifiedSince))
+	}
 
 	isAttributeSSHPublicKeySet := len(strings.TrimSpace(ls.AttributeSSHPublicKey)) > 0
 	isAtributeAvatarSet := len(strings.TrimSpace(ls.AttributeAvatar)) > 0
 
-	attribs := []string{ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail, ls.UserUID}
+	attribs := []string{ls.AttributeUsername, ls.AttributeName, ls.AttributeSurname, ls.AttributeMail, ls.UserUID, attributeModifyTimestamp}
 	if isAttributeSSHPublicKeySet {
 		attribs = append(attribs, ls.AttributeSSHPublicKey)
 	}
@@ -510,49 +530,78 @@ func (ls *Source) SearchEntries() ([]*SearchResult, error) {
 		ls.UserBase, ldap.ScopeWholeSubtree, ldap.NeverDerefAliases, 0, 0, false, userFilter,
 		attribs, nil)
 
-	var sr *ldap.SearchResult
-	if ls.UsePagedSearch() {
-		sr, err = l.SearchWithPaging(search, ls.SearchPageSize)
-	} else {
-		sr, err = l.Search(search)
-	}
-	if err != nil {
-		log.Error("LDAP Search failed unexpectedly! (%v)", err)
-		return nil, err
+	var pagingControl *ldap.ControlPaging
+	if pageSize > 0 {
+		pagingControl = ldap.NewControlPaging(pageSize)
+		search.Controls = append(search.Controls, pagingControl)
 	}
 
-	result := make([]*SearchResult, len(sr.Entries))
-
-	for i, v := range sr.Entries {
-		teamsToAdd := make(map[string][]string)
-		teamsToRemove := make(map[string][]string)
-		if ls.GroupsEnabled && (ls.GroupTeamMap != "" || ls.GroupTeamMapRemoval) {
-			userAttributeListedInGroup := v.GetAttributeValue(ls.UserUID)
-			if ls.UserUID == "dn" || ls.UserUID == "DN" {
-				userAttributeListedInGroup = v.DN
-			}
-			teamsToAdd, teamsToRemove = ls.getMappedMemberships(l, userAttributeListedInGroup)
+	for {
+		sr, err := l.Search(search)
+		if err != nil {
+			log.Error("LDAP Search failed unexpectedly! (%v)", err)
+			return err
 		}
-		result[i] = &SearchResult{
-			Username:       v.GetAttributeValue(ls.AttributeUsername),
-			Name:           v.GetAttributeValue(ls.AttributeName),
-			Surname:        v.GetAttributeValue(ls.AttributeSurname),
-			Mail:           v.GetAttributeValue(ls.AttributeMail),
-			IsAdmin:        checkAdmin(l, ls, v.DN),
-			LdapTeamAdd:    teamsToAdd,
-			LdapTeamRemove: teamsToRemove,
+
+		result := make([]*SearchResult, len(sr.Entries))
+		for i, v := range sr.Entries {
+			result[i] = ls.toSearchResult(l, v, isAttributeSSHPublicKeySet, isAtributeAvatarSet)
 		}
-		if !result[i].IsAdmin {
-			result[i].IsRestricted = checkRestricted(l, ls, v.DN)
+
+		var cookie []byte
+		if pagingControl != nil {
+			if control, ok := ldap.FindControl(sr.Controls, ldap.ControlTypePaging).(*ldap.ControlPaging); ok {
+				cookie = control.Cookie
+			}
 		}
-		if isAttributeSSHPublicKeySet {
-			result[i].SSHPublicKey = v.GetAttributeValues(ls.AttributeSSHPublicKey)
+
+		if err := f(result); err != nil {
+			if len(cookie) > 0 {
+				// abandon the paged search, so the server can free its resources
+				pagingControl.SetCookie(cookie)
+				pagingControl.PagingSize = 0
+				if _, err := l.Search(search); err != nil {
+					log.Debug("Failed to abandon the paged LDAP search: %v", err)
+				}
+			}
+			return err
 		}
-		if isAtributeAvatarSet {
-			result[i].Avatar = v.GetRawAttributeValue(ls.AttributeAvatar)
+		if len(cookie) == 0 {
+			return nil
 		}
-		result[i].LowerName = strings.ToLower(result[i].Username)
+		pagingControl.SetCookie(cookie)
 	}
+}
 
-	return result, nil
+func (ls *Source) toSearchResult(l *ldap.Conn, v *ldap.Entry, isAttributeSSHPublicKeySet, isAtributeAvatarSet bool) *SearchResult {
+	teamsToAdd := make(map[string][]string)
+	teamsToRemove := make(map[string][]string)
+	if ls.GroupsEnabled && (ls.GroupTeamMap != "" || ls.GroupTeamMapRemoval) {
+		userAttributeListedInGroup := v.GetAttributeValue(ls.UserUID)
+		if ls.UserUID == "dn" || ls.UserUID == "DN" {
+			userAttributeListedInGroup = v.DN
+		}
+		teamsToAdd, teamsToRemove = ls.getMappedMemberships(l, userAttributeListedInGroup)
+	}
+	result := &SearchResult{
+		Username:        v.GetAttributeValue(ls.AttributeUsername),
+		Name:            v.GetAttributeValue(ls.AttributeName),
+		Surname:         v.GetAttributeValue(ls.AttributeSurname),
+		Mail:            v.GetAttributeValue(ls.AttributeMail),
+		IsAdmin:         checkAdmin(l, ls, v.DN),
+		ModifyTimestamp: v.GetAttributeValue(attributeModifyTimestamp),
+		LdapTeamAdd:     teamsToAdd,
+		LdapTeamRemove:  teamsToRemove,
+	}
+	if !result.IsAdmin {
+		result.IsRestricted = checkRestricted(l, ls, v.DN)
+	}
+	if isAttributeSSHPublicKeySet {
+		result.SSHPublicKey = v.GetAttributeValues(ls.AttributeSSHPublicKey)
+	}
+	if isAtributeAvatarSet {
+		result.Avatar = v.GetRawAttributeValue(ls.AttributeAvatar)
+	}
+	result.LowerName = strings.ToLower(result.Username)
+	return result
 }
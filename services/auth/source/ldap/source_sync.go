@@ -7,208 +7,310 @@ package ldap
 import (
 	"context"
 	"fmt"
-	"sort"
 	"strings"
+	"time"
 
 	asymkey_model "code.gitea.io/gitea/models/asymkey"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/appstate"
 	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 	user_service "code.gitea.io/gitea/services/user"
 )
 
-// Sync causes this ldap source to synchronize its users with the db
-func (source *Source) Sync(ctx context.Context, updateExisting bool) error {
+// syncPageSize is the page size of the searches of the sync if no page size is configured,
+// so the entries of very large directories are processed in batches with bounded memory
+const syncPageSize = 500
+
+// syncState is the state of the sync of an LDAP source which is kept between the syncs
+type syncState struct {
+	sourceID int64
+	// LastModifyTimestamp is the latest modifyTimestamp of the entries found by the syncs
+	LastModifyTimestamp string `json:"last_modify_timestamp"`
+	// LastFullSyncUnix is the time of the last sync of all the entries
+	LastFullSyncUnix timeutil.TimeStamp `json:"last_full_sync_unix"`
+	// UserBase and Filter are the ones of the last full sync, incremental syncs would miss the entries a change adds
+	UserBase string `json:"user_base"`
+	Filter   string `json:"filter"`
+}
+
+// Name returns the item name
+func (state *syncState) Name() string {
+	return fmt.Sprintf("ldap-sync-state-%d", state.sourceID)
+}
+
+// isIncrementalSync returns true if only the entries modified since the last sync have to be synchronized
+func (source *Source) isIncrementalSync(state *syncState, fullSyncInterval time.Duration, now timeutil.TimeStamp) bool {
+	return fullSyncInterval > 0 &&
+		state.LastModifyTimestamp != "" &&
+		state.UserBase == source.UserBase &&
+		state.Filter == source.Filter &&
+		now < state.LastFullSyncUnix.AddDuration(fullSyncInterval)
+}
+
+// syncPageSize returns the page size of the searches of the sync
+func (source *Source) syncPageSize() uint32 {
+	if source.UsePagedSearch() {
+		return source.SearchPageSize
+	}
+	return syncPageSize
+}
+
+// userSync synchronizes the entries of an LDAP source with the users page by page
+type userSync struct {
+	ctx            context.Context
+	source         *Source
+	updateExisting bool
+
+	numEntries          int
+	lastModifyTimestamp string
+	// seenUserIDs are the ids of the existing users which have been found in the directory
+	seenUserIDs       map[int64]struct{}
+	sshKeysNeedUpdate bool
+	orgCache          map[string]*organization.Organization
+	teamCache         map[string]*organization.Team
+}
+
+// Sync causes this ldap source to synchronize its users with the db. Unless the last full sync is older than
+// fullSyncInterval, only the entries which have been modified since the last sync are synchronized and
+// users aren't deactivated, as deactivating them requires all the entries.
+func (source *Source) Sync(ctx context.Context, updateExisting bool, fullSyncInterval time.Duration) error {
 	log.Trace("Doing: SyncExternalUsers[%s]", source.authSource.Name)
 
-	var existingUsers []int
-	isAttributeSSHPublicKeySet := len(strings.TrimSpace(source.AttributeSSHPublicKey)) > 0
-	var sshKeysNeedUpdate bool
+	state := &syncState{sourceID: source.authSource.ID}
+	if err := appstate.AppState.Get(state); err != nil {
+		log.Error("SyncExternalUsers[%s]: Unable to get the sync state, doing a full sync: %v", source.authSource.Name, err)
+		state = &syncState{sourceID: source.authSource.ID}
+	}
+	now := timeutil.TimeStampNow()
+	incremental := source.isIncrementalSync(state, fullSyncInterval, now)
+	var modifiedSince string
+	if incremental {
+		modifiedSince = state.LastModifyTimestamp
+		log.Trace("SyncExternalUsers[%s]: Synchronizing the entries modified since %s", source.authSource.Name, modifiedSince)
+	}
+
+	s := &userSync{
+		ctx:                 ctx,
+		source:              source,
+		updateExisting:      updateExisting,
+		lastModifyTimestamp: modifiedSince,
+		seenUserIDs:         make(map[int64]struct{}),
+		orgCache:            make(map[string]*organization.Organization),
+		teamCache:           make(map[string]*organization.Team),
+	}
+	err := source.IterateEntries(modifiedSince, source.syncPageSize(), s.syncEntries)
+
+	// Rewrite authorized_keys file if LDAP Public SSH Key attribute is set and any key was added or removed
+	if s.sshKeysNeedUpdate {
+		if err := asymkey_model.RewriteAllPublicKeys(); err != nil {
+			log.Error("RewriteAllPublicKeys: %v", err)
+		}
+	}
 
-	// Find all users with this login type - FIXME: Should this be an iterator?
-	users, err := user_model.GetUsersBySource(source.authSource)
 	if err != nil {
-		log.Error("SyncExternalUsers: %v", err)
-		return err
+		if db.IsErrCancelled(err) {
+			log.Warn("SyncExternalUsers: Cancelled at update of %s before completed update of users", source.authSource.Name)
+			return err
+		}
+		log.Error("SyncExternalUsers LDAP source failure [%s], skipped", source.authSource.Name)
+		return nil
 	}
+
 	select {
 	case <-ctx.Done():
-		log.Warn("SyncExternalUsers: Cancelled before update of %s", source.authSource.Name)
-		return db.ErrCancelledf("Before update of %s", source.authSource.Name)
+		log.Warn("SyncExternalUsers: Cancelled during update of %s before delete users", source.authSource.Name)
+		return db.ErrCancelledf("During update of %s before delete users", source.authSource.Name)
 	default:
 	}
 
-	sort.Slice(users, func(i, j int) bool {
-		return users[i].LowerName < users[j].LowerName
-	})
+	if !incremental {
+		if s.numEntries == 0 {
+			if !source.AllowDeactivateAll {
+				log.Error("LDAP search found no entries but did not report an error. Refusing to deactivate all users")
+				return nil
+			}
+			log.Warn("LDAP search found no entries but did not report an error. All users will be deactivated as per settings")
+		}
 
-	sr, err := source.SearchEntries()
-	if err != nil {
-		log.Error("SyncExternalUsers LDAP source failure [%s], skipped", source.authSource.Name)
-		return nil
+		// Deactivate users not present in LDAP
+		if updateExisting {
+			if err := s.deactivateUnseenUsers(); err != nil {
+				return err
+			}
+		}
+
+		state.LastFullSyncUnix = now
+		state.UserBase = source.UserBase
+		state.Filter = source.Filter
 	}
 
-	if len(sr) == 0 {
-		if !source.AllowDeactivateAll {
-			log.Error("LDAP search found no entries but did not report an error. Refusing to deactivate all users")
-			return nil
-		}
-		log.Warn("LDAP search found no entries but did not report an error. All users will be deactivated as per settings")
+	state.LastModifyTimestamp = s.lastModifyTimestamp
+	if err := appstate.AppState.Set(state); err != nil {
+		log.Error("SyncExternalUsers[%s]: Unable to save the sync state: %v", source.authSource.Name, err)
 	}
+	return nil
+}
 
-	sort.Slice(sr, func(i, j int) bool {
-		return sr[i].LowerName < sr[j].LowerName
-	})
+// syncEntries synchronizes a page of entries with the users
+func (s *userSync) syncEntries(results []*SearchResult) error {
+	select {
+	case <-s.ctx.Done():
+		return db.ErrCancelledf("During update of %s before completed update of users", s.source.authSource.Name)
+	default:
+	}
 
-	userPos := 0
-	orgCache := make(map[string]*organization.Organization)
-	teamCache := make(map[string]*organization.Team)
+	lowerNames := make([]string, 0, len(results))
+	for _, su := range results {
+		if len(su.Username) > 0 {
+			lowerNames = append(lowerNames, su.LowerName)
+		}
+	}
+	users, err := user_model.GetUsersBySourceAndLowerNames(s.ctx, s.source.authSource, lowerNames)
+	if err != nil {
+		return err
+	}
+	existingUsers := make(map[string]*user_model.User, len(users))
+	for _, usr := range users {
+		existingUsers[usr.LowerName] = usr
+	}
 
-	for _, su := range sr {
-		select {
-		case <-ctx.Done():
-			log.Warn("SyncExternalUsers: Cancelled at update of %s before completed update of users", source.authSource.Name)
-			// Rewrite authorized_keys file if LDAP Public SSH Key attribute is set and any key was added or removed
-			if sshKeysNeedUpdate {
-				err = asymkey_model.RewriteAllPublicKeys()
-				if err != nil {
-					log.Error("RewriteAllPublicKeys: %v", err)
-				}
-			}
-			return db.ErrCancelledf("During update of %s before completed update of users", source.authSource.Name)
-		default:
+	for _, su := range results {
+		s.numEntries++
+		// the generalized times of a directory have the same format, so they compare like strings
+		if su.ModifyTimestamp > s.lastModifyTimestamp {
+			s.lastModifyTimestamp = su.ModifyTimestamp
 		}
 		if len(su.Username) == 0 {
 			continue
 		}
 
-		if len(su.Mail) == 0 {
-			su.Mail = fmt.Sprintf("%s@localhost", su.Username)
+		usr := existingUsers[su.LowerName]
+		if usr != nil {
+			s.seenUserIDs[usr.ID] = struct{}{}
 		}
+		s.syncUser(su, usr)
+	}
+	return nil
+}
 
-		var usr *user_model.User
-		for userPos < len(users) && users[userPos].LowerName < su.LowerName {
-			userPos++
+// syncUser creates the user of the entry or updates the existing user usr
+func (s *userSync) syncUser(su *SearchResult, usr *user_model.User) {
+	source := s.source
+	isAttributeSSHPublicKeySet := len(strings.TrimSpace(source.AttributeSSHPublicKey)) > 0
+
+	if len(su.Mail) == 0 {
+		su.Mail = fmt.Sprintf("%s@localhost", su.Username)
+	}
+
+	var err error
+	fullName := composeFullName(su.Name, su.Surname, su.Username)
+	// If no existing user found, create one
+	if usr == nil {
+		log.Trace("SyncExternalUsers[%s]: Creating user %s", source.authSource.Name, su.Username)
+
+		usr = &user_model.User{
+			LowerName:   su.LowerName,
+			Name:        su.Username,
+			FullName:    fullName,
+			LoginType:   source.authSource.Type,
+			LoginSource: source.authSource.ID,
+			LoginName:   su.Username,
+			Email:       su.Mail,
+			IsAdmin:     su.IsAdmin,
 		}
-		if userPos < len(users) && users[userPos].LowerName == su.LowerName {
-			usr = users[userPos]
-			existingUsers = append(existingUsers, userPos)
+		overwriteDefault := &user_model.CreateUserOverwriteOptions{
+			IsRestricted: util.OptionalBoolOf(su.IsRestricted),
+			IsActive:     util.OptionalBoolTrue,
 		}
 
-		fullName := composeFullName(su.Name, su.Surname, su.Username)
-		// If no existing user found, create one
-		if usr == nil {
-			log.Trace("SyncExternalUsers[%s]: Creating user %s", source.authSource.Name, su.Username)
+		err = user_model.CreateUser(usr, overwriteDefault)
+		if err != nil {
+			log.Error("SyncExternalUsers[%s]: Error creating user %s: %v", source.authSource.Name, su.Username, err)
+		} else {
+			// the user is known now, so the entries found by later pages don't deactivate it
+			s.seenUserIDs[usr.ID] = struct{}{}
+		}
 
-			usr = &user_model.User{
-				LowerName:   su.LowerName,
-				Name:        su.Username,
-				FullName:    fullName,
-				LoginType:   source.authSource.Type,
-				LoginSource: source.authSource.ID,
-				LoginName:   su.Username,
-				Email:       su.Mail,
-				IsAdmin:     su.IsAdmin,
-			}
-			overwriteDefault := &user_model.CreateUserOverwriteOptions{
-				IsRestricted: util.OptionalBoolOf(su.IsRestricted),
-				IsActive:     util.OptionalBoolTrue,
+		if err == nil && isAttributeSSHPublicKeySet {
+			log.Trace("SyncExternalUsers[%s]: Adding LDAP Public SSH Keys for user %s", source.authSource.Name, usr.Name)
+			if asymkey_model.AddPublicKeysBySource(usr, source.authSource, su.SSHPublicKey) {
+				s.sshKeysNeedUpdate = true
 			}
+		}
 
-			err = user_model.CreateUser(usr, overwriteDefault)
-			if err != nil {
-				log.Error("SyncExternalUsers[%s]: Error creating user %s: %v", source.authSource.Name, su.Username, err)
-			}
+		if err == nil && len(source.AttributeAvatar) > 0 {
+			_ = user_service.UploadAvatar(usr, su.Avatar)
+		}
+	} else if s.updateExisting {
+		// Synchronize SSH Public Key if that attribute is set
+		if isAttributeSSHPublicKeySet && asymkey_model.SynchronizePublicKeys(usr, source.authSource, su.SSHPublicKey) {
+			s.sshKeysNeedUpdate = true
+		}
 
-			if err == nil && isAttributeSSHPublicKeySet {
-				log.Trace("SyncExternalUsers[%s]: Adding LDAP Public SSH Keys for user %s", source.authSource.Name, usr.Name)
-				if asymkey_model.AddPublicKeysBySource(usr, source.authSource, su.SSHPublicKey) {
-					sshKeysNeedUpdate = true
-				}
-			}
+		// Check if user data has changed
+		if (len(source.AdminFilter) > 0 && usr.IsAdmin != su.IsAdmin) ||
+			(len(source.RestrictedFilter) > 0 && usr.IsRestricted != su.IsRestricted) ||
+			!strings.EqualFold(usr.Email, su.Mail) ||
+			usr.FullName != fullName ||
+			!usr.IsActive {
 
-			if err == nil && len(source.AttributeAvatar) > 0 {
-				_ = user_service.UploadAvatar(usr, su.Avatar)
+			log.Trace("SyncExternalUsers[%s]: Updating user %s", source.authSource.Name, usr.Name)
+
+			usr.FullName = fullName
+			emailChanged := usr.Email != su.Mail
+			usr.Email = su.Mail
+			// Change existing admin flag only if AdminFilter option is set
+			if len(source.AdminFilter) > 0 {
+				usr.IsAdmin = su.IsAdmin
 			}
-		} else if updateExisting {
-			// Synchronize SSH Public Key if that attribute is set
-			if isAttributeSSHPublicKeySet && asymkey_model.SynchronizePublicKeys(usr, source.authSource, su.SSHPublicKey) {
-				sshKeysNeedUpdate = true
+			// Change existing restricted flag only if RestrictedFilter option is set
+			if !usr.IsAdmin && len(source.RestrictedFilter) > 0 {
+				usr.IsRestricted = su.IsRestricted
 			}
+			usr.IsActive = true
 
-			// Check if user data has changed
-			if (len(source.AdminFilter) > 0 && usr.IsAdmin != su.IsAdmin) ||
-				(len(source.RestrictedFilter) > 0 && usr.IsRestricted != su.IsRestricted) ||
-				!strings.EqualFold(usr.Email, su.Mail) ||
-				usr.FullName != fullName ||
-				!usr.IsActive {
-
-				log.Trace("SyncExternalUsers[%s]: Updating user %s", source.authSource.Name, usr.Name)
-
-				usr.FullName = fullName
-				emailChanged := usr.Email != su.Mail
-				usr.Email = su.Mail
-				// Change existing admin flag only if AdminFilter option is set
-				if len(source.AdminFilter) > 0 {
-					usr.IsAdmin = su.IsAdmin
-				}
-				// Change existing restricted flag only if RestrictedFilter option is set
-				if !usr.IsAdmin && len(source.RestrictedFilter) > 0 {
-					usr.IsRestricted = su.IsRestricted
-				}
-				usr.IsActive = true
-
-				err = user_model.UpdateUser(ctx, usr, emailChanged, "full_name", "email", "is_admin", "is_restricted", "is_active")
-				if err != nil {
-					log.Error("SyncExternalUsers[%s]: Error updating user %s: %v", source.authSource.Name, usr.Name, err)
-				}
+			err = user_model.UpdateUser(s.ctx, usr, emailChanged, "full_name", "email", "is_admin", "is_restricted", "is_active")
+			if err != nil {
+				log.Error("SyncExternalUsers[%s]: Error updating user %s: %v", source.authSource.Name, usr.Name, err)
 			}
+		}
 
-			if usr.IsUploadAvatarChanged(su.Avatar) {
-				if err == nil && len(source.AttributeAvatar) > 0 {
-					_ = user_service.UploadAvatar(usr, su.Avatar)
-				}
+		if usr.IsUploadAvatarChanged(su.Avatar) {
+			if err == nil && len(source.AttributeAvatar) > 0 {
+				_ = user_service.UploadAvatar(usr, su.Avatar)
 			}
 		}
-		// Synchronize LDAP groups with organization and team memberships
-		if source.GroupsEnabled && (source.GroupTeamMap != "" || source.GroupTeamMapRemoval) {
-			source.SyncLdapGroupsToTeams(usr, su.LdapTeamAdd, su.LdapTeamRemove, orgCache, teamCache)
-		}
 	}
+	// Synchronize LDAP groups with organization and team memberships
+	if source.GroupsEnabled && (source.GroupTeamMap != "" || source.GroupTeamMapRemoval) {
+		source.SyncLdapGroupsToTeams(usr, su.LdapTeamAdd, su.LdapTeamRemove, s.orgCache, s.teamCache)
+	}
+}
 
-	// Rewrite authorized_keys file if LDAP Public SSH Key attribute is set and any key was added or removed
-	if sshKeysNeedUpdate {
-		err = asymkey_model.RewriteAllPublicKeys()
-		if err != nil {
-			log.Error("RewriteAllPublicKeys: %v", err)
+// deactivateUnseenUsers deactivates the active users of the source which haven't been found in the directory
+func (s *userSync) deactivateUnseenUsers() error {
+	// the users are collected first as deactivating them while iterating would shift the pages of the iteration
+	var unseenUsers []*user_model.User
+	if err := user_model.IterateActiveUsersBySource(s.ctx, s.source.authSource, func(usr *user_model.User) error {
+		if _, ok := s.seenUserIDs[usr.ID]; !ok {
+			unseenUsers = append(unseenUsers, usr)
 		}
+		return nil
+	}); err != nil {
+		log.Error("SyncExternalUsers[%s]: Error finding the users to deactivate: %v", s.source.authSource.Name, err)
+		return err
 	}
 
-	select {
-	case <-ctx.Done():
-		log.Warn("SyncExternalUsers: Cancelled during update of %s before delete users", source.authSource.Name)
-		return db.ErrCancelledf("During update of %s before delete users", source.authSource.Name)
-	default:
-	}
+	for _, usr := range unseenUsers {
+		log.Trace("SyncExternalUsers[%s]: Deactivating user %s", s.source.authSource.Name, usr.Name)
 
-	// Deactivate users not present in LDAP
-	if updateExisting {
-		existPos := 0
-		for i, usr := range users {
-			for existPos < len(existingUsers) && i > existingUsers[existPos] {
-				existPos++
-			}
-			if usr.IsActive && (existPos >= len(existingUsers) || i < existingUsers[existPos]) {
-				log.Trace("SyncExternalUsers[%s]: Deactivating user %s", source.authSource.Name, usr.Name)
-
-				usr.IsActive = false
-				err = user_model.UpdateUserCols(ctx, usr, "is_active")
-				if err != nil {
-					log.Error("SyncExternalUsers[%s]: Error deactivating user %s: %v", source.authSource.Name, usr.Name, err)
-				}
-			}
+		usr.IsActive = false
+		if err := user_model.UpdateUserCols(s.ctx, usr, "is_active"); err != nil {
+			log.Error("SyncExternalUsers[%s]: Error deactivating user %s: %v", s.source.authSource.Name, usr.Name, err)
 		}
 	}
 	return nil
@@ -0,0 +1,45 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package ldap
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource_isIncrementalSync(t *testing.T) {
+	source := &Source{UserBase: "ou=users,dc=example,dc=org", Filter: "(uid=%s)"}
+	now := timeutil.TimeStamp(1666000000)
+	state := &syncState{
+		LastModifyTimestamp: "20221017090000Z",
+		LastFullSyncUnix:    now.Add(-3600),
+		UserBase:            source.UserBase,
+		Filter:              source.Filter,
+	}
+
+	assert.True(t, source.isIncrementalSync(state, 24*time.Hour, now))
+	// every sync is a full one without an interval
+	assert.False(t, source.isIncrementalSync(state, 0, now))
+	// the full sync is due
+	assert.False(t, source.isIncrementalSync(state, time.Hour, now))
+
+	// the entries of the directory have no modifyTimestamp
+	state.LastModifyTimestamp = ""
+	assert.False(t, source.isIncrementalSync(state, 24*time.Hour, now))
+	state.LastModifyTimestamp = "20221017090000Z"
+
+	// the changed filter may match entries which haven't been modified
+	source.Filter = "(&(objectClass=person)(uid=%s))"
+	assert.False(t, source.isIncrementalSync(state, 24*time.Hour, now))
+}
+
+func TestSource_syncPageSize(t *testing.T) {
+	assert.EqualValues(t, syncPageSize, (&Source{}).syncPageSize())
+	assert.EqualValues(t, 1000, (&Source{SearchPageSize: 1000}).syncPageSize())
+}
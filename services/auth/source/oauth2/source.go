@@ -33,6 +33,9 @@ type Source struct {
 	RestrictedGroup    string
 	SkipLocalTwoFA     bool `json:",omitempty"`
 
+	GroupTeamMap        string // Map group claim values to teams
+	GroupTeamMapRemoval bool   // Remove user from teams which are synchronized and user is not in the corresponding group
+
 	// reference to the authSource
 	authSource *auth.Source
 }
@@ -0,0 +1,137 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// IsGroupTeamSyncEnabled returns true if the group claim values are mapped to teams
+func (source *Source) IsGroupTeamSyncEnabled() bool {
+	return source.GroupClaimName != "" && (source.GroupTeamMap != "" || source.GroupTeamMapRemoval)
+}
+
+// mapGroupsToTeams parses the group team map, e.g. {"group": {"MyOrganization": ["MyTeam1", "MyTeam2"]}}
+func (source *Source) mapGroupsToTeams() map[string]map[string][]string {
+	groupsToTeams := make(map[string]map[string][]string)
+	if source.GroupTeamMap == "" {
+		return groupsToTeams
+	}
+	if err := json.Unmarshal([]byte(source.GroupTeamMap), &groupsToTeams); err != nil {
+		log.Error("Failed to unmarshall OAuth2 group team map: %v", err)
+	}
+	return groupsToTeams
+}
+
+// getMappedMemberships returns the organizations and teams to add the user to and to remove the user from,
+// a team mapped by a group of the user is never removed even if it is mapped by another group as well.
+func (source *Source) getMappedMemberships(groups []string) (map[string][]string, map[string][]string) {
+	membershipsToAdd := map[string][]string{}
+	membershipsToRemove := map[string][]string{}
+	groupsToTeams := source.mapGroupsToTeams()
+	for group, memberships := range groupsToTeams {
+		if !util.IsStringInSlice(group, groups) {
+			continue
+		}
+		for org, teams := range memberships {
+			for _, team := range teams {
+				if !util.IsStringInSlice(team, membershipsToAdd[org]) {
+					membershipsToAdd[org] = append(membershipsToAdd[org], team)
+				}
+			}
+		}
+	}
+	for group, memberships := range groupsToTeams {
+		if util.IsStringInSlice(group, groups) {
+			continue
+		}
+		for org, teams := range memberships {
+			for _, team := range teams {
+				if !util.IsStringInSlice(team, membershipsToAdd[org]) && !util.IsStringInSlice(team, membershipsToRemove[org]) {
+					membershipsToRemove[org] = append(membershipsToRemove[org], team)
+				}
+			}
+		}
+	}
+	return membershipsToAdd, membershipsToRemove
+}
+
+// SyncGroupsToTeams maps the group claim values of the user to organization and team memberships
+func (source *Source) SyncGroupsToTeams(user *user_model.User, groups []string) {
+	membershipsToAdd, membershipsToRemove := source.getMappedMemberships(groups)
+	orgCache := map[string]*organization.Organization{}
+	teamCache := map[string]*organization.Team{}
+
+	if source.GroupTeamMapRemoval {
+		// when the user is not in a mapped group anymore, remove the memberships of its organizations/teams
+		for orgName, teamNames := range membershipsToRemove {
+			for _, teamName := range teamNames {
+				org, team := getOrgAndTeam(orgName, teamName, orgCache, teamCache)
+				if team == nil {
+					continue
+				}
+				if isMember, err := organization.IsTeamMember(db.DefaultContext, org.ID, team.ID, user.ID); !isMember || err != nil {
+					continue
+				}
+				log.Trace("OAuth2 group sync: removing user [%s] from team [%s] of [%s]", user.Name, team.Name, org.Name)
+				if err := models.RemoveTeamMember(team, user.ID); err != nil {
+					log.Error("OAuth2 group sync: Could not remove user from team: %v", err)
+				}
+			}
+		}
+	}
+
+	for orgName, teamNames := range membershipsToAdd {
+		for _, teamName := range teamNames {
+			org, team := getOrgAndTeam(orgName, teamName, orgCache, teamCache)
+			if team == nil {
+				continue
+			}
+			if isMember, err := organization.IsTeamMember(db.DefaultContext, org.ID, team.ID, user.ID); isMember || err != nil {
+				continue
+			}
+			log.Trace("OAuth2 group sync: adding user [%s] to team [%s] of [%s]", user.Name, team.Name, org.Name)
+			if err := models.AddTeamMember(team, user.ID); err != nil {
+				log.Error("OAuth2 group sync: Could not add user to team: %v", err)
+			}
+		}
+	}
+}
+
+// getOrgAndTeam returns the organization and team of the names, the team is nil if one of them doesn't exist
+func getOrgAndTeam(orgName, teamName string, orgCache map[string]*organization.Organization, teamCache map[string]*organization.Team) (*organization.Organization, *organization.Team) {
+	var err error
+	org, ok := orgCache[orgName]
+	if !ok {
+		org, err = organization.GetOrgByName(orgName)
+		if err != nil {
+			// organization must be created before OAuth2 group sync
+			log.Warn("OAuth2 group sync: Could not find organisation %s: %v", orgName, err)
+			org = nil
+		}
+		orgCache[orgName] = org
+	}
+	if org == nil {
+		return nil, nil
+	}
+
+	team, ok := teamCache[orgName+"/"+teamName]
+	if !ok {
+		team, err = org.GetTeam(teamName)
+		if err != nil {
+			// team must be created before OAuth2 group sync
+			log.Warn("OAuth2 group sync: Could not find team %s: %v", teamName, err)
+			team = nil
+		}
+		teamCache[orgName+"/"+teamName] = team
+	}
+	return org, team
+}
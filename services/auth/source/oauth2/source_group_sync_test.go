@@ -0,0 +1,47 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package oauth2
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSource_getMappedMemberships(t *testing.T) {
+	source := &Source{
+		GroupClaimName: "groups",
+		GroupTeamMap: `{
+			"dev": {"org1": ["developers", "readers"]},
+			"ops": {"org1": ["readers", "operators"], "org2": ["operators"]},
+			"qa": {"org2": ["testers"]}
+		}`,
+	}
+	assert.True(t, source.IsGroupTeamSyncEnabled())
+
+	add, remove := source.getMappedMemberships([]string{"dev", "qa", "unmapped"})
+	assert.Len(t, add, 2)
+	assert.ElementsMatch(t, []string{"developers", "readers"}, add["org1"])
+	assert.ElementsMatch(t, []string{"testers"}, add["org2"])
+	assert.Len(t, remove, 2)
+	// readers is kept as the dev group maps to it as well
+	assert.ElementsMatch(t, []string{"operators"}, remove["org1"])
+	assert.ElementsMatch(t, []string{"operators"}, remove["org2"])
+
+	add, remove = source.getMappedMemberships(nil)
+	assert.Empty(t, add)
+	assert.ElementsMatch(t, []string{"developers", "readers", "operators"}, remove["org1"])
+	assert.ElementsMatch(t, []string{"operators", "testers"}, remove["org2"])
+
+	source.GroupTeamMap = "invalid"
+	add, remove = source.getMappedMemberships([]string{"dev"})
+	assert.Empty(t, add)
+	assert.Empty(t, remove)
+
+	source.GroupTeamMap = ""
+	assert.False(t, source.IsGroupTeamSyncEnabled())
+	source.GroupTeamMapRemoval = true
+	assert.True(t, source.IsGroupTeamSyncEnabled())
+}
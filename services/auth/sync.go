@@ -6,14 +6,16 @@ package auth
 
 import (
 	"context"
+	"time"
 
 	"code.gitea.io/gitea/models/auth"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/modules/log"
 )
 
-// SyncExternalUsers is used to synchronize users with external authorization source
-func SyncExternalUsers(ctx context.Context, updateExisting bool) error {
+// SyncExternalUsers is used to synchronize users with external authorization source,
+// a full sync of all the users of a source is done at most once per fullSyncInterval if it isn't 0
+func SyncExternalUsers(ctx context.Context, updateExisting bool, fullSyncInterval time.Duration) error {
 	log.Trace("Doing: SyncExternalUsers")
 
 	ls, err := auth.Sources()
@@ -34,7 +36,7 @@ func SyncExternalUsers(ctx context.Context, updateExisting bool) error {
 		}
 
 		if syncable, ok := s.Cfg.(SynchronizableSource); ok {
-			err := syncable.Sync(ctx, updateExisting)
+			err := syncable.Sync(ctx, updateExisting, fullSyncInterval)
 			if err != nil {
 				return err
 			}
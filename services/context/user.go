@@ -27,6 +27,15 @@ func UserAssignmentWeb() func(ctx *context.Context) {
 				ctx.ServerError(title, err)
 			}
 		})
+		if ctx.Written() {
+			return
+		}
+		if ctx.ContextUser.NoIndex {
+			ctx.SetNoIndex()
+		}
+		if ctx.ContextUser.IsSuspendedFor(ctx.Doer) {
+			ctx.Suspended(ctx.ContextUser)
+		}
 	}
 }
 
@@ -34,6 +43,9 @@ func UserAssignmentWeb() func(ctx *context.Context) {
 func UserAssignmentAPI() func(ctx *context.APIContext) {
 	return func(ctx *context.APIContext) {
 		userAssignment(ctx.Context, ctx.Error)
+		if !ctx.Written() && ctx.ContextUser.IsSuspendedFor(ctx.Doer) {
+			ctx.Error(http.StatusForbidden, "UserAssignmentAPI", "the user has been suspended")
+		}
 	}
 }
 
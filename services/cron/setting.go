@@ -33,10 +33,11 @@ type OlderThanConfig struct {
 	OlderThan time.Duration
 }
 
-// UpdateExistingConfig represents a cron task with UpdateExisting setting
-type UpdateExistingConfig struct {
+// SyncExternalUsersConfig represents a cron task with UpdateExisting and FullSyncInterval settings
+type SyncExternalUsersConfig struct {
 	BaseConfig
-	UpdateExisting bool
+	UpdateExisting   bool
+	FullSyncInterval time.Duration
 }
 
 // CleanupHookTaskConfig represents a cron task with settings to cleanup hook_task
@@ -47,6 +48,14 @@ type CleanupHookTaskConfig struct {
 	NumberToKeep int
 }
 
+// DeleteOldActionsConfig represents a cron task with settings to prune the action table
+type DeleteOldActionsConfig struct {
+	BaseConfig
+	OlderThan         time.Duration
+	MaxEntriesPerFeed int
+	BatchSize         int
+}
+
 // GetSchedule returns the schedule for the base config
 func (b *BaseConfig) GetSchedule() string {
 	return b.Schedule
@@ -9,15 +9,21 @@ import (
 	"time"
 
 	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/setting"
+	actions_service "code.gitea.io/gitea/services/actions"
 	"code.gitea.io/gitea/services/auth"
 	"code.gitea.io/gitea/services/migrations"
 	mirror_service "code.gitea.io/gitea/services/mirror"
 	packages_service "code.gitea.io/gitea/services/packages"
+	debian_service "code.gitea.io/gitea/services/packages/debian"
+	preview_service "code.gitea.io/gitea/services/preview"
 	repo_service "code.gitea.io/gitea/services/repository"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+	resumable_service "code.gitea.io/gitea/services/resumable"
+	webhook_service "code.gitea.io/gitea/services/webhook"
 )
 
 func registerUpdateMirrorTask() {
@@ -86,16 +92,17 @@ func registerArchiveCleanup() {
 }
 
 func registerSyncExternalUsers() {
-	RegisterTaskFatal("sync_external_users", &UpdateExistingConfig{
+	RegisterTaskFatal("sync_external_users", &SyncExternalUsersConfig{
 		BaseConfig: BaseConfig{
 			Enabled:    true,
 			RunAtStart: false,
 			Schedule:   "@midnight",
 		},
-		UpdateExisting: true,
+		UpdateExisting:   true,
+		FullSyncInterval: 0,
 	}, func(ctx context.Context, _ *user_model.User, config Config) error {
-		realConfig := config.(*UpdateExistingConfig)
-		return auth.SyncExternalUsers(ctx, realConfig.UpdateExisting)
+		realConfig := config.(*SyncExternalUsersConfig)
+		return auth.SyncExternalUsers(ctx, realConfig.UpdateExisting, realConfig.FullSyncInterval)
 	})
 }
 
@@ -106,11 +113,10 @@ func registerDeletedBranchesCleanup() {
 			RunAtStart: true,
 			Schedule:   "@midnight",
 		},
-		OlderThan: 24 * time.Hour,
+		OlderThan: 7 * 24 * time.Hour,
 	}, func(ctx context.Context, _ *user_model.User, config Config) error {
 		realConfig := config.(*OlderThanConfig)
-		models.RemoveOldDeletedBranches(ctx, realConfig.OlderThan)
-		return nil
+		return repo_service.CleanupDeletedBranches(ctx, realConfig.OlderThan)
 	})
 }
 
@@ -140,6 +146,16 @@ func registerCleanupHookTaskTable() {
 	})
 }
 
+func registerDeliverWebhookRetries() {
+	RegisterTaskFatal("deliver_webhook_retries", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@every 1m",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return webhook_service.DeliverDueHookTasks(ctx)
+	})
+}
+
 func registerCleanupPackages() {
 	RegisterTaskFatal("cleanup_packages", &OlderThanConfig{
 		BaseConfig: BaseConfig{
@@ -154,6 +170,110 @@ func registerCleanupPackages() {
 	})
 }
 
+func registerDeleteExpiredResumableUploads() {
+	RegisterTaskFatal("delete_expired_resumable_uploads", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return resumable_service.CleanupExpiredUploads(ctx)
+	})
+}
+
+func registerApplyPackageRetentionPolicies() {
+	RegisterTaskFatal("apply_package_retention_policies", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: false,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		if err := packages_service.ApplyRetentionPolicies(ctx); err != nil {
+			return err
+		}
+		// the retention policies delete package versions without notifying, so the Debian indices are outdated
+		return debian_service.RebuildAllRepositories(ctx)
+	})
+}
+
+func registerStopZombieActionsJobs() {
+	RegisterTaskFatal("stop_zombie_actions_jobs", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 5m",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return actions_service.StopZombieJobs(ctx)
+	})
+}
+
+func registerRevokeExpiredAccess() {
+	RegisterTaskFatal("revoke_expired_access", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return repo_service.RevokeExpiredAccess(ctx)
+	})
+}
+
+func registerApplyScheduledVisibilityChanges() {
+	RegisterTaskFatal("apply_scheduled_visibility_changes", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 10m",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return repo_service.ApplyScheduledVisibilityChanges(ctx)
+	})
+}
+
+func registerPurgeTrashedRepositories() {
+	RegisterTaskFatal("purge_trashed_repositories", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 1h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return repo_service.PurgeTrashedRepositories(ctx)
+	})
+}
+
+func registerDeleteOldRepoTraffic() {
+	RegisterTaskFatal("delete_old_repo_traffic", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return repo_model.DeleteOldTraffic(ctx)
+	})
+}
+
+func registerDeleteExpiredPreviewEnvironments() {
+	RegisterTaskFatal("delete_expired_preview_environments", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 10m",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return preview_service.DeleteExpiredPreviewEnvironments(ctx)
+	})
+}
+
+func registerApplyScheduledRepoTransfers() {
+	RegisterTaskFatal("transfer_scheduled_repositories", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@every 10m",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return repo_service.ApplyScheduledTransfers(ctx)
+	})
+}
+
+func registerDeleteExpiredRepoRedirects() {
+	RegisterTaskFatal("delete_expired_repo_redirects", &BaseConfig{
+		Enabled:    true,
+		RunAtStart: true,
+		Schedule:   "@midnight",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return repo_service.DeleteExpiredRedirects(ctx)
+	})
+}
+
 func initBasicTasks() {
 	registerUpdateMirrorTask()
 	registerRepoHealthCheck()
@@ -165,7 +285,24 @@ func initBasicTasks() {
 		registerUpdateMigrationPosterID()
 	}
 	registerCleanupHookTaskTable()
+	if !setting.DisableWebhooks {
+		registerDeliverWebhookRetries()
+	}
+	registerRevokeExpiredAccess()
+	registerApplyScheduledVisibilityChanges()
+	registerPurgeTrashedRepositories()
+	registerDeleteOldRepoTraffic()
+	registerDeleteExpiredPreviewEnvironments()
+	registerApplyScheduledRepoTransfers()
+	registerDeleteExpiredRepoRedirects()
 	if setting.Packages.Enabled {
 		registerCleanupPackages()
+		registerApplyPackageRetentionPolicies()
+	}
+	if setting.Actions.Enabled {
+		registerStopZombieActionsJobs()
+	}
+	if setting.ResumableUpload.Enabled {
+		registerDeleteExpiredResumableUploads()
 	}
 }
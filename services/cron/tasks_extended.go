@@ -6,6 +6,7 @@ package cron
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"code.gitea.io/gitea/models"
@@ -15,6 +16,9 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/updatechecker"
+	"code.gitea.io/gitea/modules/util"
+	attachment_service "code.gitea.io/gitea/services/attachment"
+	issue_service "code.gitea.io/gitea/services/issue"
 	repo_service "code.gitea.io/gitea/services/repository"
 	archiver_service "code.gitea.io/gitea/services/repository/archiver"
 	user_service "code.gitea.io/gitea/services/user"
@@ -64,6 +68,28 @@ func registerGarbageCollectRepositories() {
 	})
 }
 
+func registerRepoMaintenance() {
+	type RepoMaintenanceConfig struct {
+		BaseConfig
+		Timeout   time.Duration
+		MinPushes int64
+		MaxRepos  int
+	}
+	RegisterTaskFatal("repo_maintenance", &RepoMaintenanceConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    true,
+			RunAtStart: false,
+			Schedule:   "@every 1h",
+		},
+		Timeout:   time.Duration(setting.Git.Timeout.GC) * time.Second,
+		MinPushes: 50,
+		MaxRepos:  20,
+	}, func(ctx context.Context, _ *user_model.User, config Config) error {
+		maintenanceConfig := config.(*RepoMaintenanceConfig)
+		return repo_service.MaintainBusyRepos(ctx, maintenanceConfig.Timeout, maintenanceConfig.MinPushes, maintenanceConfig.MaxRepos)
+	})
+}
+
 func registerRewriteAllPublicKeys() {
 	RegisterTaskFatal("resync_all_sshkeys", &BaseConfig{
 		Enabled:    false,
@@ -125,16 +151,21 @@ func registerRemoveRandomAvatars() {
 }
 
 func registerDeleteOldActions() {
-	RegisterTaskFatal("delete_old_actions", &OlderThanConfig{
+	RegisterTaskFatal("delete_old_actions", &DeleteOldActionsConfig{
 		BaseConfig: BaseConfig{
 			Enabled:    false,
 			RunAtStart: false,
 			Schedule:   "@every 168h",
 		},
-		OlderThan: 365 * 24 * time.Hour,
+		OlderThan:         365 * 24 * time.Hour,
+		MaxEntriesPerFeed: 0,
+		BatchSize:         10000,
 	}, func(ctx context.Context, _ *user_model.User, config Config) error {
-		olderThanConfig := config.(*OlderThanConfig)
-		return models.DeleteOldActions(olderThanConfig.OlderThan)
+		actionsConfig := config.(*DeleteOldActionsConfig)
+		if err := models.DeleteOldActions(ctx, actionsConfig.OlderThan, actionsConfig.BatchSize); err != nil {
+			return err
+		}
+		return models.PruneFeeds(ctx, actionsConfig.MaxEntriesPerFeed)
 	})
 }
 
@@ -170,10 +201,126 @@ func registerDeleteOldSystemNotices() {
 	})
 }
 
+func registerArchiveOldNotifications() {
+	RegisterTaskFatal("archive_old_notifications", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+		},
+		OlderThan: 30 * 24 * time.Hour,
+	}, func(ctx context.Context, _ *user_model.User, config Config) error {
+		olderThanConfig := config.(*OlderThanConfig)
+		return models.ArchiveOldReadNotifications(ctx, olderThanConfig.OlderThan)
+	})
+}
+
+func registerDeleteOldNotifications() {
+	RegisterTaskFatal("delete_old_notifications", &OlderThanConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@every 168h",
+		},
+		OlderThan: 365 * 24 * time.Hour,
+	}, func(ctx context.Context, _ *user_model.User, config Config) error {
+		olderThanConfig := config.(*OlderThanConfig)
+		return models.DeleteOldReadNotifications(ctx, olderThanConfig.OlderThan)
+	})
+}
+
+func registerDeleteOldAuditEvents() {
+	RegisterTaskFatal("delete_old_audit_events", &BaseConfig{
+		Enabled:    setting.Audit.Enabled && setting.Audit.RetentionDays > 0,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return admin.DeleteOldAuditEvents(ctx, time.Duration(setting.Audit.RetentionDays)*24*time.Hour)
+	})
+}
+
+func registerAnonymizeOldIPs() {
+	RegisterTaskFatal("anonymize_old_ips", &BaseConfig{
+		Enabled:    setting.Privacy.IPRetentionDays > 0,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		anonymize := util.AnonymizeIP
+		if setting.Privacy.IPAnonymization == setting.IPAnonymizationRemove {
+			anonymize = func(string) string { return "" }
+		}
+		olderThan := time.Duration(setting.Privacy.IPRetentionDays) * 24 * time.Hour
+		if err := admin.AnonymizeOldAuditEventIPs(ctx, olderThan, anonymize); err != nil {
+			return err
+		}
+		return user_model.AnonymizeOldSecurityEventIPs(ctx, olderThan, anonymize)
+	})
+}
+
+func registerExpireAttachments() {
+	RegisterTaskFatal("expire_attachments", &BaseConfig{
+		Enabled:    setting.Attachment.OrphanMaxAge > 0 || setting.Attachment.MaxAge > 0,
+		RunAtStart: false,
+		Schedule:   "@every 24h",
+	}, func(ctx context.Context, _ *user_model.User, _ Config) error {
+		return attachment_service.ExpireAttachments(ctx)
+	})
+}
+
+func registerStaleIssues() {
+	type StaleIssuesConfig struct {
+		BaseConfig
+		User           string
+		DaysUntilStale int
+		DaysUntilClose int
+		StaleLabel     string
+		ExemptLabels   []string `delim:","`
+		StaleComment   string
+		CloseComment   string
+		Issues         bool
+		PullRequests   bool
+	}
+	RegisterTaskFatal("stale_issues", &StaleIssuesConfig{
+		BaseConfig: BaseConfig{
+			Enabled:    false,
+			RunAtStart: false,
+			Schedule:   "@every 24h",
+		},
+		DaysUntilStale: 60,
+		DaysUntilClose: 7,
+		StaleLabel:     "stale",
+		ExemptLabels:   []string{"pinned", "security"},
+		StaleComment:   "This issue has been automatically marked as stale because it has not had recent activity. It will be closed if no further activity occurs.",
+		CloseComment:   "This issue has been automatically closed because it has not had any activity since it was marked as stale.",
+		Issues:         true,
+		PullRequests:   true,
+	}, func(ctx context.Context, _ *user_model.User, config Config) error {
+		staleConfig := config.(*StaleIssuesConfig)
+		if staleConfig.User == "" {
+			return errors.New("USER must be set to the name of the user the stale issues are processed by")
+		}
+		doer, err := user_model.GetUserByName(ctx, staleConfig.User)
+		if err != nil {
+			return err
+		}
+		return issue_service.MarkAndCloseStaleIssues(ctx, doer, issue_service.StaleOptions{
+			DaysUntilStale: staleConfig.DaysUntilStale,
+			DaysUntilClose: staleConfig.DaysUntilClose,
+			StaleLabel:     staleConfig.StaleLabel,
+			ExemptLabels:   staleConfig.ExemptLabels,
+			StaleComment:   staleConfig.StaleComment,
+			CloseComment:   staleConfig.CloseComment,
+			Issues:         staleConfig.Issues,
+			PullRequests:   staleConfig.PullRequests,
+		})
+	})
+}
+
 func initExtendedTasks() {
 	registerDeleteInactiveUsers()
 	registerDeleteRepositoryArchives()
 	registerGarbageCollectRepositories()
+	registerRepoMaintenance()
 	registerRewriteAllPublicKeys()
 	registerRewriteAllPrincipalKeys()
 	registerRepositoryUpdateHook()
@@ -183,4 +330,10 @@ func initExtendedTasks() {
 	registerDeleteOldActions()
 	registerUpdateGiteaChecker()
 	registerDeleteOldSystemNotices()
+	registerArchiveOldNotifications()
+	registerDeleteOldNotifications()
+	registerDeleteOldAuditEvents()
+	registerAnonymizeOldIPs()
+	registerExpireAttachments()
+	registerStaleIssues()
 }
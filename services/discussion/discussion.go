@@ -0,0 +1,139 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package discussion
+
+import (
+	"context"
+
+	"code.gitea.io/gitea/models"
+	discussion_model "code.gitea.io/gitea/models/discussion"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// defaultCategories are created when the discussions of a repository are enabled the first time
+var defaultCategories = []*discussion_model.Category{
+	{Name: "General", Description: "Chat about anything and everything here"},
+	{Name: "Ideas", Description: "Share ideas for new features"},
+	{Name: "Q&A", Description: "Ask the community for help", IsAnswerable: true},
+}
+
+// CreateDefaultCategories creates the default discussion categories of a repository if it has none
+func CreateDefaultCategories(ctx context.Context, repo *repo_model.Repository) error {
+	categories, err := discussion_model.GetCategories(ctx, repo.ID)
+	if err != nil || len(categories) > 0 {
+		return err
+	}
+	for _, c := range defaultCategories {
+		if err := discussion_model.NewCategory(ctx, &discussion_model.Category{
+			RepoID:       repo.ID,
+			Name:         c.Name,
+			Description:  c.Description,
+			IsAnswerable: c.IsAnswerable,
+		}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// NewDiscussion creates a discussion in a category of the repository
+func NewDiscussion(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, d *discussion_model.Discussion) error {
+	category, err := discussion_model.GetCategoryByID(ctx, repo.ID, d.CategoryID)
+	if err != nil {
+		return err
+	}
+
+	d.RepoID = repo.ID
+	d.Repo = repo
+	d.Category = category
+	d.PosterID = doer.ID
+	d.Poster = doer
+	if err := discussion_model.NewDiscussion(ctx, d); err != nil {
+		return err
+	}
+
+	notification.NotifyNewDiscussion(doer, d)
+	return nil
+}
+
+// UpdateDiscussion changes the title, content and category of a discussion,
+// its answer is unmarked if it is moved to a category which isn't answerable
+func UpdateDiscussion(ctx context.Context, doer *user_model.User, d *discussion_model.Discussion, title, content string, categoryID int64) error {
+	category, err := discussion_model.GetCategoryByID(ctx, d.RepoID, categoryID)
+	if err != nil {
+		return err
+	}
+
+	oldCategoryID := d.CategoryID
+	d.Title = title
+	d.Content = content
+	d.CategoryID = category.ID
+	d.Category = category
+	if err := discussion_model.UpdateDiscussion(ctx, d, oldCategoryID); err != nil {
+		return err
+	}
+	if !category.IsAnswerable && d.IsAnswered() {
+		if err := discussion_model.UnmarkAnswer(ctx, d); err != nil {
+			return err
+		}
+	}
+
+	notification.NotifyUpdateDiscussion(doer, d)
+	return nil
+}
+
+// DeleteDiscussion deletes a discussion with its comments and notifications
+func DeleteDiscussion(ctx context.Context, doer *user_model.User, d *discussion_model.Discussion) error {
+	if err := discussion_model.DeleteDiscussion(ctx, d); err != nil {
+		return err
+	}
+	if err := models.DeleteDiscussionNotifications(ctx, d.ID); err != nil {
+		return err
+	}
+
+	notification.NotifyDeleteDiscussion(doer, d)
+	return nil
+}
+
+// CreateComment adds a comment to a discussion, or a reply to a comment if the parent id isn't 0
+func CreateComment(ctx context.Context, doer *user_model.User, d *discussion_model.Discussion, parentID int64, content string) (*discussion_model.Comment, error) {
+	c := &discussion_model.Comment{
+		DiscussionID: d.ID,
+		ParentID:     parentID,
+		PosterID:     doer.ID,
+		Poster:       doer,
+		Content:      content,
+	}
+	if err := discussion_model.CreateComment(ctx, c); err != nil {
+		return nil, err
+	}
+	d.NumComments++
+
+	notification.NotifyNewDiscussionComment(doer, d, c)
+	return c, nil
+}
+
+// UpdateComment changes the content of a comment of a discussion
+func UpdateComment(ctx context.Context, doer *user_model.User, d *discussion_model.Discussion, c *discussion_model.Comment, content string) error {
+	c.Content = content
+	if err := discussion_model.UpdateComment(ctx, c); err != nil {
+		return err
+	}
+
+	notification.NotifyUpdateDiscussion(doer, d)
+	return nil
+}
+
+// DeleteComment deletes a comment of a discussion with its replies
+func DeleteComment(ctx context.Context, doer *user_model.User, d *discussion_model.Discussion, c *discussion_model.Comment) error {
+	if err := discussion_model.DeleteComment(ctx, d, c); err != nil {
+		return err
+	}
+
+	notification.NotifyUpdateDiscussion(doer, d)
+	return nil
+}
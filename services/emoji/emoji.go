@@ -0,0 +1,231 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package emoji
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image/png"
+	"regexp"
+
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/emoji"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+var nameRegexp = regexp.MustCompile(`^[a-z0-9_+-]{1,50}$`)
+
+// ErrInvalidCustomEmoji represents a "InvalidCustomEmoji" kind of error.
+type ErrInvalidCustomEmoji struct {
+	Name   string
+	Reason string
+}
+
+// IsErrInvalidCustomEmoji checks if an error is a ErrInvalidCustomEmoji.
+func IsErrInvalidCustomEmoji(err error) bool {
+	_, ok := err.(ErrInvalidCustomEmoji)
+	return ok
+}
+
+func (err ErrInvalidCustomEmoji) Error() string {
+	return fmt.Sprintf("invalid custom emoji %s: %s", err.Name, err.Reason)
+}
+
+// ErrCustomEmojiAlreadyExist represents a "CustomEmojiAlreadyExist" kind of error.
+type ErrCustomEmojiAlreadyExist struct {
+	Name string
+}
+
+// IsErrCustomEmojiAlreadyExist checks if an error is a ErrCustomEmojiAlreadyExist.
+func IsErrCustomEmojiAlreadyExist(err error) bool {
+	_, ok := err.(ErrCustomEmojiAlreadyExist)
+	return ok
+}
+
+func (err ErrCustomEmojiAlreadyExist) Error() string {
+	return fmt.Sprintf("emoji already exists [name: %s]", err.Name)
+}
+
+// ErrUnknownReaction represents a "UnknownReaction" kind of error.
+type ErrUnknownReaction struct {
+	Name string
+}
+
+// IsErrUnknownReaction checks if an error is a ErrUnknownReaction.
+func IsErrUnknownReaction(err error) bool {
+	_, ok := err.(ErrUnknownReaction)
+	return ok
+}
+
+func (err ErrUnknownReaction) Error() string {
+	return fmt.Sprintf("reaction is neither an emoji nor a custom emoji: %s", err.Name)
+}
+
+// Init loads the uploaded emoji and the allowed reactions
+func Init() error {
+	return Reload(db.DefaultContext)
+}
+
+// Reload reloads the uploaded emoji and the allowed reactions,
+// it has to be called after an organization has been renamed as the names of its emoji start with its name
+func Reload(ctx context.Context) error {
+	emojis, err := issues_model.GetAllCustomEmojis(ctx)
+	if err != nil {
+		return err
+	}
+	ownerIDs := make([]int64, 0, len(emojis))
+	for _, e := range emojis {
+		if e.OwnerID != 0 {
+			ownerIDs = append(ownerIDs, e.OwnerID)
+		}
+	}
+	owners := make(map[int64]*user_model.User, len(ownerIDs))
+	if len(ownerIDs) > 0 {
+		if err := db.GetEngine(ctx).In("id", ownerIDs).Find(&owners); err != nil {
+			return err
+		}
+	}
+
+	uploaded := make([]emoji.UploadedEmoji, 0, len(emojis))
+	for _, e := range emojis {
+		u := emoji.UploadedEmoji{Alias: e.Name, Path: e.RelativePath()}
+		if e.OwnerID != 0 {
+			owner, ok := owners[e.OwnerID]
+			if !ok {
+				log.Warn("Owner %d of custom emoji %s does not exist", e.OwnerID, e.Name)
+				continue
+			}
+			u.Alias = emoji.OrgAlias(owner.Name, e.Name)
+			u.Owner = owner.LowerName
+		}
+		uploaded = append(uploaded, u)
+	}
+	emoji.SetUploaded(uploaded)
+
+	reactions, err := issues_model.GetAllowedReactions(ctx)
+	if err != nil {
+		return err
+	}
+	emoji.SetReactions(reactions)
+	return nil
+}
+
+// UploadCustomEmoji stores a PNG image as a new custom emoji of the instance
+func UploadCustomEmoji(ctx context.Context, doer *user_model.User, name string, data []byte) error {
+	if !nameRegexp.MatchString(name) {
+		return ErrInvalidCustomEmoji{Name: name, Reason: "the name may only contain lowercase letters, digits, '_', '+' and '-'"}
+	}
+	return upload(ctx, doer, 0, name, name, data)
+}
+
+// UploadOrgCustomEmoji stores a PNG image as a new custom emoji of an organization, it is used as :<orgname>-<name>:
+func UploadOrgCustomEmoji(ctx context.Context, doer, org *user_model.User, name string, data []byte) error {
+	if !nameRegexp.MatchString(name) {
+		return ErrInvalidCustomEmoji{Name: name, Reason: "the name may only contain lowercase letters, digits, '_', '+' and '-'"}
+	}
+	alias := emoji.OrgAlias(org.Name, name)
+	if !nameRegexp.MatchString(alias) {
+		// e.g. the name of the organization contains a dot or is too long
+		return ErrInvalidCustomEmoji{Name: alias, Reason: "the name of the organization can't be used in the name of an emoji"}
+	}
+	return upload(ctx, doer, org.ID, alias, name, data)
+}
+
+func upload(ctx context.Context, doer *user_model.User, ownerID int64, alias, name string, data []byte) error {
+	if emoji.FromAlias(alias) != nil || emoji.IsCustom(alias) || emoji.IsUploaded(alias) {
+		return ErrCustomEmojiAlreadyExist{Name: alias}
+	}
+	if int64(len(data)) > setting.CustomEmoji.MaxFileSize {
+		return ErrInvalidCustomEmoji{Name: alias, Reason: fmt.Sprintf("the image is larger than %d bytes", setting.CustomEmoji.MaxFileSize)}
+	}
+	cfg, err := png.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return ErrInvalidCustomEmoji{Name: alias, Reason: "the image is not a PNG image"}
+	}
+	if cfg.Width > setting.CustomEmoji.MaxWidth || cfg.Height > setting.CustomEmoji.MaxHeight {
+		return ErrInvalidCustomEmoji{Name: alias, Reason: fmt.Sprintf("the image is larger than %dx%d pixels", setting.CustomEmoji.MaxWidth, setting.CustomEmoji.MaxHeight)}
+	}
+
+	e := &issues_model.CustomEmoji{OwnerID: ownerID, Name: name, Size: int64(len(data)), UploaderID: doer.ID}
+	if _, err := storage.CustomEmojis.Save(e.RelativePath(), bytes.NewReader(data), e.Size); err != nil {
+		return fmt.Errorf("Save: %v", err)
+	}
+	if err := issues_model.InsertCustomEmoji(ctx, e); err != nil {
+		if err := storage.CustomEmojis.Delete(e.RelativePath()); err != nil {
+			log.Error("Unable to delete the image of custom emoji %s: %v", alias, err)
+		}
+		return err
+	}
+	return Reload(ctx)
+}
+
+// DeleteCustomEmoji deletes an uploaded emoji of the instance, existing reactions with it are kept but can't be added anymore
+func DeleteCustomEmoji(ctx context.Context, name string) error {
+	return deleteCustomEmoji(ctx, 0, name)
+}
+
+// DeleteOrgCustomEmoji deletes an uploaded emoji of an organization
+func DeleteOrgCustomEmoji(ctx context.Context, org *user_model.User, name string) error {
+	return deleteCustomEmoji(ctx, org.ID, name)
+}
+
+func deleteCustomEmoji(ctx context.Context, ownerID int64, name string) error {
+	e, err := issues_model.GetCustomEmojiByName(ctx, ownerID, name)
+	if err != nil {
+		return err
+	}
+	if err := issues_model.DeleteCustomEmoji(ctx, e.OwnerID, e.Name); err != nil {
+		return err
+	}
+	if err := storage.CustomEmojis.Delete(e.RelativePath()); err != nil {
+		log.Error("Unable to delete the image of custom emoji %s: %v", name, err)
+	}
+	return Reload(ctx)
+}
+
+// DeleteOwnerCustomEmojis deletes all emoji of an organization, it is called when the organization is deleted
+func DeleteOwnerCustomEmojis(ctx context.Context, ownerID int64) error {
+	emojis, err := issues_model.GetCustomEmojis(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if len(emojis) == 0 {
+		return nil
+	}
+	if err := issues_model.DeleteCustomEmojisByOwnerID(ctx, ownerID); err != nil {
+		return err
+	}
+	for _, e := range emojis {
+		if err := storage.CustomEmojis.Delete(e.RelativePath()); err != nil {
+			log.Error("Unable to delete the image of custom emoji %s of owner %d: %v", e.Name, ownerID, err)
+		}
+	}
+	return Reload(ctx)
+}
+
+// SetAllowedReactions replaces the allowed reactions, an empty list allows the REACTIONS of [ui] again
+func SetAllowedReactions(ctx context.Context, names []string) error {
+	seen := make(map[string]bool, len(names))
+	reactions := make([]string, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		if emoji.FromAlias(name) == nil && emoji.FromCode(name) == nil && !emoji.IsCustom(name) {
+			return ErrUnknownReaction{Name: name}
+		}
+		seen[name] = true
+		reactions = append(reactions, name)
+	}
+	if err := issues_model.SetAllowedReactions(ctx, reactions); err != nil {
+		return err
+	}
+	return Reload(ctx)
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package emoji
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/emoji"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func pngImage(t *testing.T, width, height int) []byte {
+	var buf bytes.Buffer
+	assert.NoError(t, png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, width, height))))
+	return buf.Bytes()
+}
+
+func TestUploadCustomEmoji(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	assert.NoError(t, Init())
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+
+	err := UploadCustomEmoji(db.DefaultContext, doer, "Party Parrot", pngImage(t, 32, 32))
+	assert.True(t, IsErrInvalidCustomEmoji(err))
+	err = UploadCustomEmoji(db.DefaultContext, doer, "smile", pngImage(t, 32, 32))
+	assert.True(t, IsErrCustomEmojiAlreadyExist(err))
+	err = UploadCustomEmoji(db.DefaultContext, doer, "partyparrot", []byte("not an image"))
+	assert.True(t, IsErrInvalidCustomEmoji(err))
+	err = UploadCustomEmoji(db.DefaultContext, doer, "partyparrot", pngImage(t, setting.CustomEmoji.MaxWidth+1, 32))
+	assert.True(t, IsErrInvalidCustomEmoji(err))
+
+	assert.NoError(t, UploadCustomEmoji(db.DefaultContext, doer, "partyparrot", pngImage(t, 32, 32)))
+	unittest.AssertExistsAndLoadBean(t, &issues_model.CustomEmoji{Name: "partyparrot", UploaderID: doer.ID})
+	assert.True(t, emoji.IsUploaded("partyparrot"))
+	assert.Equal(t, setting.AppSubURL+"/custom-emojis/partyparrot.png", emoji.CustomURL("partyparrot"))
+	assert.Contains(t, emoji.CustomEmojis(), "partyparrot")
+
+	err = UploadCustomEmoji(db.DefaultContext, doer, "partyparrot", pngImage(t, 32, 32))
+	assert.True(t, IsErrCustomEmojiAlreadyExist(err))
+
+	assert.NoError(t, DeleteCustomEmoji(db.DefaultContext, "partyparrot"))
+	unittest.AssertNotExistsBean(t, &issues_model.CustomEmoji{Name: "partyparrot"})
+	assert.False(t, emoji.IsUploaded("partyparrot"))
+}
+
+func TestUploadOrgCustomEmoji(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	assert.NoError(t, Init())
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	org := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3}).(*user_model.User)
+
+	assert.NoError(t, UploadOrgCustomEmoji(db.DefaultContext, doer, org, "shipit", pngImage(t, 32, 32)))
+	unittest.AssertExistsAndLoadBean(t, &issues_model.CustomEmoji{OwnerID: org.ID, Name: "shipit"})
+	assert.True(t, emoji.IsCustomIn("user3-shipit", org.Name))
+	assert.False(t, emoji.IsCustomIn("user3-shipit", "user2"))
+	assert.False(t, emoji.IsCustom("user3-shipit"))
+	assert.Equal(t, setting.AppSubURL+"/custom-emojis/3/shipit.png", emoji.CustomURL("user3-shipit"))
+
+	// the name is only unique in the organization
+	assert.NoError(t, UploadCustomEmoji(db.DefaultContext, doer, "shipit", pngImage(t, 32, 32)))
+	err := UploadOrgCustomEmoji(db.DefaultContext, doer, org, "shipit", pngImage(t, 32, 32))
+	assert.True(t, IsErrCustomEmojiAlreadyExist(err))
+	err = UploadCustomEmoji(db.DefaultContext, doer, "user3-shipit", pngImage(t, 32, 32))
+	assert.True(t, IsErrCustomEmojiAlreadyExist(err))
+
+	assert.NoError(t, DeleteOrgCustomEmoji(db.DefaultContext, org, "shipit"))
+	assert.False(t, emoji.IsUploaded("user3-shipit"))
+	assert.True(t, emoji.IsCustom("shipit"))
+
+	assert.NoError(t, UploadOrgCustomEmoji(db.DefaultContext, doer, org, "party", pngImage(t, 32, 32)))
+	assert.NoError(t, DeleteOwnerCustomEmojis(db.DefaultContext, org.ID))
+	unittest.AssertNotExistsBean(t, &issues_model.CustomEmoji{OwnerID: org.ID})
+	assert.False(t, emoji.IsUploaded("user3-party"))
+	assert.NoError(t, DeleteCustomEmoji(db.DefaultContext, "shipit"))
+}
+
+func TestSetAllowedReactions(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	assert.NoError(t, Init())
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+
+	err := SetAllowedReactions(db.DefaultContext, []string{"+1", "not-an-emoji"})
+	assert.True(t, IsErrUnknownReaction(err))
+	assert.Equal(t, setting.UI.Reactions, emoji.Reactions())
+
+	assert.NoError(t, UploadCustomEmoji(db.DefaultContext, doer, "shipit", pngImage(t, 16, 16)))
+	assert.NoError(t, SetAllowedReactions(db.DefaultContext, []string{"+1", "shipit", "+1"}))
+	assert.Equal(t, []string{"+1", "shipit"}, emoji.Reactions())
+	assert.True(t, emoji.IsAllowedReaction("shipit"))
+	assert.False(t, emoji.IsAllowedReaction("laugh"))
+
+	// deleting a custom emoji removes it from the allowed reactions
+	assert.NoError(t, DeleteCustomEmoji(db.DefaultContext, "shipit"))
+	assert.Equal(t, []string{"+1"}, emoji.Reactions())
+
+	assert.NoError(t, SetAllowedReactions(db.DefaultContext, nil))
+	assert.Equal(t, setting.UI.Reactions, emoji.Reactions())
+}
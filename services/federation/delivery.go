@@ -0,0 +1,152 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package federation
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"code.gitea.io/gitea/modules/activitypub"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/hostmatcher"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/proxy"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ActivityContentType is the content type of ActivityPub activities
+const ActivityContentType = `application/ld+json; profile="https://www.w3.org/ns/activitystreams"`
+
+// deliveryTimeout is the time a remote inbox has to accept an activity
+const deliveryTimeout = 30 * time.Second
+
+// Delivery is an activity sent to the inbox of a remote instance
+type Delivery struct {
+	InboxURL string `json:"inbox_url"`
+	Activity []byte `json:"activity"`
+	// Headers are added to the request, e.g. the Date, Digest and Signature of the actor sending the activity
+	Headers map[string]string `json:"headers"`
+}
+
+// DeliveryStats counts the deliveries handled since the start of this instance
+type DeliveryStats struct {
+	Delivered int64
+	Failed    int64
+	Rejected  int64
+}
+
+var (
+	deliveryQueue  queue.Queue
+	deliveryClient *http.Client
+	stats          DeliveryStats
+)
+
+func handle(data ...queue.Data) []queue.Data {
+	for _, datum := range data {
+		delivery := datum.(*Delivery)
+		// the lists may have changed since the delivery was queued
+		if !isInboxAllowed(delivery.InboxURL) {
+			atomic.AddInt64(&stats.Rejected, 1)
+			continue
+		}
+		if err := deliver(delivery); err != nil {
+			atomic.AddInt64(&stats.Failed, 1)
+			log.Warn("Unable to deliver activity to %s: %v", delivery.InboxURL, err)
+			continue
+		}
+		atomic.AddInt64(&stats.Delivered, 1)
+	}
+	return nil
+}
+
+func isInboxAllowed(inboxURL string) bool {
+	u, err := url.Parse(inboxURL)
+	return err == nil && activitypub.IsInstanceAllowed(u.Host)
+}
+
+func deliver(delivery *Delivery) error {
+	req, err := http.NewRequestWithContext(graceful.GetManager().ShutdownContext(), http.MethodPost, delivery.InboxURL, bytes.NewReader(delivery.Activity))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", ActivityContentType)
+	req.Header.Set("User-Agent", "Gitea "+setting.AppVer)
+	for k, v := range delivery.Headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := deliveryClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("unexpected status %s", resp.Status)
+	}
+	return nil
+}
+
+// Init creates the queue delivering activities to remote instances
+func Init() error {
+	if !setting.Federation.Enabled || !setting.Federation.DeliveryEnabled {
+		return nil
+	}
+
+	deliveryClient = &http.Client{
+		Timeout: deliveryTimeout,
+		Transport: &http.Transport{
+			Proxy: proxy.Proxy(),
+			// activities are never delivered to the local network
+			DialContext: hostmatcher.NewDialContext("federation", hostmatcher.ParseHostMatchList("federation", hostmatcher.MatchBuiltinExternal), nil),
+		},
+	}
+
+	deliveryQueue = queue.CreateQueue("activitypub_delivery", handle, &Delivery{})
+	if deliveryQueue == nil {
+		return fmt.Errorf("Unable to create activitypub_delivery Queue")
+	}
+
+	go graceful.GetManager().RunWithShutdownFns(deliveryQueue.Run)
+
+	return nil
+}
+
+// Deliver queues the activity for delivery to the inbox of a remote instance.
+// Activities for instances which aren't allowed to federate with this instance are dropped.
+func Deliver(delivery *Delivery) error {
+	if deliveryQueue == nil {
+		return nil
+	}
+	if _, err := url.Parse(delivery.InboxURL); err != nil {
+		return err
+	}
+	if !isInboxAllowed(delivery.InboxURL) {
+		atomic.AddInt64(&stats.Rejected, 1)
+		return nil
+	}
+	return deliveryQueue.Push(delivery)
+}
+
+// GetDeliveryStats returns the deliveries handled since the start of this instance
+func GetDeliveryStats() DeliveryStats {
+	return DeliveryStats{
+		Delivered: atomic.LoadInt64(&stats.Delivered),
+		Failed:    atomic.LoadInt64(&stats.Failed),
+		Rejected:  atomic.LoadInt64(&stats.Rejected),
+	}
+}
+
+// IsDeliveryEnabled returns true if activities are delivered to remote instances
+func IsDeliveryEnabled() bool {
+	return deliveryQueue != nil
+}
@@ -6,9 +6,11 @@ package forms
 
 import (
 	"net/http"
+	"time"
 
 	"code.gitea.io/gitea/modules/context"
 	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/web/middleware"
 
 	"gitea.com/go-chi/binding"
@@ -71,3 +73,43 @@ func (f *AdminDashboardForm) Validate(req *http.Request, errs binding.Errors) bi
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// AnnouncementForm form for site admins and organization owners to publish announcements
+type AnnouncementForm struct {
+	Content     string `binding:"Required"`
+	Severity    string `binding:"Required;In(info,warning,critical)"`
+	Dismissible bool
+	// StartTime and EndTime are local times like "2006-01-02T15:04", the announcement starts now if StartTime is empty
+	// and never ends if EndTime is empty
+	StartTime string
+	EndTime   string
+}
+
+// Validate validates form fields
+func (f *AnnouncementForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// AnnouncementTimeLayout is the layout of the start and end times of the announcement form
+const AnnouncementTimeLayout = "2006-01-02T15:04"
+
+// ParseSchedule returns the start and end times of the announcement in the location
+func (f *AnnouncementForm) ParseSchedule(loc *time.Location) (start, end timeutil.TimeStamp, err error) {
+	start = timeutil.TimeStampNow()
+	if f.StartTime != "" {
+		t, err := time.ParseInLocation(AnnouncementTimeLayout, f.StartTime, loc)
+		if err != nil {
+			return 0, 0, err
+		}
+		start = timeutil.TimeStamp(t.Unix())
+	}
+	if f.EndTime != "" {
+		t, err := time.ParseInLocation(AnnouncementTimeLayout, f.EndTime, loc)
+		if err != nil {
+			return 0, 0, err
+		}
+		end = timeutil.TimeStamp(t.Unix())
+	}
+	return start, end, nil
+}
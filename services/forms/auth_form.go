@@ -73,6 +73,8 @@ type AuthenticationForm struct {
 	Oauth2GroupClaimName          string
 	Oauth2AdminGroup              string
 	Oauth2RestrictedGroup         string
+	Oauth2GroupTeamMap            string
+	Oauth2GroupTeamMapRemoval     bool
 	SkipLocalTwoFA                bool
 	SSPIAutoCreateUsers           bool
 	SSPIAutoActivateUsers         bool
@@ -81,6 +83,9 @@ type AuthenticationForm struct {
 	SSPIDefaultLanguage           string
 	GroupTeamMap                  string
 	GroupTeamMapRemoval           bool
+	AllowedCIDRs                  string `form:"allowed_cidrs"`
+	DeniedCIDRs                   string `form:"denied_cidrs"`
+	AccessTimeWindows             string
 }
 
 // Validate validates fields
@@ -45,6 +45,7 @@ type UpdateOrgSettingForm struct {
 	Visibility                structs.VisibleType
 	MaxRepoCreation           int
 	RepoAdminChangeTeamAccess bool
+	NoIndex                   bool
 }
 
 // Validate validates the fields
@@ -53,6 +54,57 @@ func (f *UpdateOrgSettingForm) Validate(req *http.Request, errs binding.Errors)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// OrgProtectBranchForm form for creating or editing branch protection rules of an organization
+type OrgProtectBranchForm struct {
+	BranchPattern          string `binding:"Required;MaxSize(255)"`
+	ApplyToExisting        bool
+	AllowOverride          bool
+	EnablePush             bool
+	EnableStatusCheck      bool
+	StatusCheckContexts    string // comma separated
+	RequiredApprovals      int64
+	BlockOnRejectedReviews bool
+	DismissStaleApprovals  bool
+	RequireSignedCommits   bool
+}
+
+// Validate validates the fields
+func (f *OrgProtectBranchForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// OrgIssueIntakeRuleForm form for creating or editing issue intake rules of an organization
+type OrgIssueIntakeRuleForm struct {
+	Name         string `binding:"Required;MaxSize(255)"`
+	RepoPattern  string `binding:"MaxSize(255)"`
+	TemplateFile string `binding:"MaxSize(255)"`
+	Labels       string // comma separated
+	ProjectTitle string `binding:"MaxSize(255)"`
+	BoardTitle   string `binding:"MaxSize(255)"`
+	TeamID       int64
+}
+
+// Validate validates the fields
+func (f *OrgIssueIntakeRuleForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// OrgKeyPolicyForm form for changing the key policy of an organization
+type OrgKeyPolicyForm struct {
+	SSHKeyMaxAgeYears int `binding:"Range(0,100)"`
+	MinRSAKeySize     int `binding:"Range(0,16384)"`
+	ForbidDSAKeys     bool
+	RequireGPGKey     bool
+}
+
+// Validate validates the fields
+func (f *OrgKeyPolicyForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // ___________
 // \__    ___/___ _____    _____
 //   |    |_/ __ \\__  \  /     \
@@ -62,11 +114,14 @@ func (f *UpdateOrgSettingForm) Validate(req *http.Request, errs binding.Errors)
 
 // CreateTeamForm form for creating team
 type CreateTeamForm struct {
-	TeamName         string `binding:"Required;AlphaDashDot;MaxSize(30)"`
-	Description      string `binding:"MaxSize(255)"`
-	Permission       string
-	RepoAccess       string
-	CanCreateOrgRepo bool
+	TeamName               string `binding:"Required;AlphaDashDot;MaxSize(30)"`
+	Description            string `binding:"MaxSize(255)"`
+	Permission             string
+	RepoAccess             string
+	CanCreateOrgRepo       bool
+	CanCreateReleases      bool
+	CanEditReleases        bool
+	CanUploadReleaseAssets bool
 }
 
 // Validate validates the fields
@@ -129,8 +129,12 @@ type RepoSettingForm struct {
 	PushMirrorPassword string
 	PushMirrorInterval string
 	Private            bool
+	ConfirmRepoName    string
+	ScheduledTime      string
 	Template           bool
+	NoIndex            bool
 	EnablePrune        bool
+	ForkParent         string
 
 	// Advanced settings
 	EnableWiki                            bool
@@ -144,6 +148,7 @@ type RepoSettingForm struct {
 	EnableCloseIssuesViaCommitInAnyBranch bool
 	EnableProjects                        bool
 	EnablePackages                        bool
+	EnableDiscussions                     bool
 	EnablePulls                           bool
 	PullsIgnoreWhitespace                 bool
 	PullsAllowMerge                       bool
@@ -163,6 +168,10 @@ type RepoSettingForm struct {
 	// Signing Settings
 	TrustModel string
 
+	// Editor Settings
+	EditorLockedFilePatterns      string
+	EditorPullRequestFilePatterns string
+
 	// Admin settings
 	EnableHealthCheck  bool
 	RequestReindexType string
@@ -239,10 +248,16 @@ type WebhookForm struct {
 	PullRequestComment   bool
 	PullRequestReview    bool
 	PullRequestSync      bool
+	PreviewEnvironment   bool
 	Repository           bool
 	Package              bool
 	Active               bool
 	BranchFilter         string `binding:"GlobPattern"`
+	PayloadFilter        string `binding:"PayloadFilter"`
+	RepoFilter           string `binding:"GlobPattern"`
+	PayloadVersion       int
+	IsTemplate           bool
+	IsLocked             bool
 }
 
 // PushOnly if the hook will be triggered when push
@@ -260,12 +275,21 @@ func (f WebhookForm) ChooseEvents() bool {
 	return f.Events == "choose_events"
 }
 
+// WebhookSecretForm rotation of the secret and client certificate of a signed web hook
+type WebhookSecretForm struct {
+	KeepPreviousSecret bool
+	ClientCert         string
+	ClientKey          string
+}
+
 // NewWebhookForm form for creating web hook
 type NewWebhookForm struct {
-	PayloadURL  string `binding:"Required;ValidUrl"`
-	HTTPMethod  string `binding:"Required;In(POST,GET)"`
-	ContentType int    `binding:"Required"`
-	Secret      string
+	PayloadURL       string `binding:"Required;ValidUrl"`
+	HTTPMethod       string `binding:"Required;In(POST,GET)"`
+	ContentType      int    `binding:"Required"`
+	Secret           string
+	GitHubCompatible bool `form:"github_compatible"`
+	WebhookSecretForm
 	WebhookForm
 }
 
@@ -280,6 +304,7 @@ type NewGogshookForm struct {
 	PayloadURL  string `binding:"Required;ValidUrl"`
 	ContentType int    `binding:"Required"`
 	Secret      string
+	WebhookSecretForm
 	WebhookForm
 }
 
@@ -433,6 +458,7 @@ type CreateIssueForm struct {
 	Content             string
 	Files               []string
 	AllowMaintainerEdit bool
+	Template            string `form:"template"`
 }
 
 // Validate validates the fields
@@ -522,6 +548,44 @@ type EditProjectBoardForm struct {
 	Color   string `binding:"MaxSize(7)"`
 }
 
+// CreateDiscussionForm form for creating or editing a discussion
+type CreateDiscussionForm struct {
+	Title      string `binding:"Required;MaxSize(255)"`
+	Content    string `binding:"Required"`
+	CategoryID int64  `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *CreateDiscussionForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// DiscussionCommentForm form for adding or editing a comment of a discussion
+type DiscussionCommentForm struct {
+	Content  string `binding:"Required"`
+	ParentID int64
+}
+
+// Validate validates the fields
+func (f *DiscussionCommentForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// DiscussionCategoryForm form for creating or editing a discussion category
+type DiscussionCategoryForm struct {
+	Name         string `binding:"Required;MaxSize(50)"`
+	Description  string `binding:"MaxSize(255)"`
+	IsAnswerable bool
+}
+
+// Validate validates the fields
+func (f *DiscussionCategoryForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 //    _____  .__.__                   __
 //   /     \ |__|  |   ____   _______/  |_  ____   ____   ____
 //  /  \ /  \|  |  | _/ __ \ /  ___/\   __\/  _ \ /    \_/ __ \
@@ -676,6 +740,13 @@ type UpdateAllowEditsForm struct {
 //  |____|_  /\___  >____/\___  >____  /____  >\___  >
 //         \/     \/          \/     \/     \/     \/
 
+// ExternalAssetsForm files hosted elsewhere which are linked as assets of a release
+type ExternalAssetsForm struct {
+	ExternalAssetName     []string
+	ExternalAssetURL      []string
+	ExternalAssetChecksum []string
+}
+
 // NewReleaseForm form for creating release
 type NewReleaseForm struct {
 	TagName    string `binding:"Required;GitRefName;MaxSize(255)"`
@@ -686,7 +757,9 @@ type NewReleaseForm struct {
 	TagOnly    string
 	Prerelease bool
 	AddTagMsg  bool
+	SignTag    bool
 	Files      []string
+	ExternalAssetsForm
 }
 
 // Validate validates the fields
@@ -702,6 +775,7 @@ type EditReleaseForm struct {
 	Draft      string `form:"draft"`
 	Prerelease bool   `form:"prerelease"`
 	Files      []string
+	ExternalAssetsForm
 }
 
 // Validate validates the fields
@@ -869,6 +943,25 @@ func (f *AddTimeManuallyForm) Validate(req *http.Request, errs binding.Errors) b
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// SaveIssueFilterForm form for saving the current issue list filters
+type SaveIssueFilterForm struct {
+	Name      string `binding:"Required;MaxSize(255)"`
+	AllRepos  bool   `form:"all_repos"`
+	Keyword   string `form:"q"`
+	Type      string `form:"type"`
+	Sort      string `form:"sort"`
+	State     string `form:"state"`
+	Labels    string `form:"labels"`
+	Milestone int64  `form:"milestone"`
+	Assignee  int64  `form:"assignee"`
+}
+
+// Validate validates the fields
+func (f *SaveIssueFilterForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // SaveTopicForm form for save topics for repository
 type SaveTopicForm struct {
 	Topics []string `binding:"topics;Required;"`
@@ -884,3 +977,17 @@ func (f *DeadlineForm) Validate(req *http.Request, errs binding.Errors) binding.
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// NewDeployTokenForm form for creating a deploy token of a repository
+type NewDeployTokenForm struct {
+	Name   string   `binding:"Required;MaxSize(255)"`
+	Scopes []string `form:"scopes"`
+	// Expires is an optional date in the format 2006-01-02
+	Expires string `form:"expires"`
+}
+
+// Validate validates the fields
+func (f *NewDeployTokenForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -212,6 +212,9 @@ type AccessTokenForm struct {
 
 	// PKCE support
 	CodeVerifier string `json:"code_verifier"`
+
+	// device authorization grant support
+	DeviceCode string `json:"device_code"`
 }
 
 // Validate validates the fields
@@ -220,6 +223,31 @@ func (f *AccessTokenForm) Validate(req *http.Request, errs binding.Errors) bindi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// DeviceAuthorizationForm for requesting the authorization of a device
+type DeviceAuthorizationForm struct {
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	Scope        string `json:"scope"`
+}
+
+// Validate validates the fields
+func (f *DeviceAuthorizationForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
+// GrantDeviceForm form for approving or denying the authorization of a device
+type GrantDeviceForm struct {
+	UserCode string `binding:"Required"`
+	Granted  bool
+}
+
+// Validate validates the fields
+func (f *GrantDeviceForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // IntrospectTokenForm for introspecting tokens
 type IntrospectTokenForm struct {
 	Token string `json:"token"`
@@ -248,6 +276,7 @@ type UpdateProfileForm struct {
 	Description         string `binding:"MaxSize(255)"`
 	Visibility          structs.VisibleType
 	KeepActivityPrivate bool
+	NoIndex             bool
 }
 
 // Validate validates the fields
@@ -356,6 +385,8 @@ type AddKeyForm struct {
 	KeyID       string `binding:"OmitEmpty"`
 	Fingerprint string `binding:"OmitEmpty"`
 	IsWritable  bool
+	// AllowedCIDRs is only used for deploy keys
+	AllowedCIDRs string `form:"allowed_cidrs"`
 }
 
 // Validate validates the fields
@@ -366,7 +397,8 @@ func (f *AddKeyForm) Validate(req *http.Request, errs binding.Errors) binding.Er
 
 // NewAccessTokenForm form for creating access token
 type NewAccessTokenForm struct {
-	Name string `binding:"Required;MaxSize(255)"`
+	Name         string `binding:"Required;MaxSize(255)"`
+	AllowedCIDRs string `form:"allowed_cidrs"`
 }
 
 // Validate validates the fields
@@ -375,6 +407,18 @@ func (f *NewAccessTokenForm) Validate(req *http.Request, errs binding.Errors) bi
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
 
+// NewDownloadTokenForm form for creating a download token
+type NewDownloadTokenForm struct {
+	Name   string `binding:"Required;MaxSize(255)"`
+	Scopes string `binding:"Required"`
+}
+
+// Validate validates the fields
+func (f *NewDownloadTokenForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
+
 // EditOAuth2ApplicationForm form for editing oauth2 applications
 type EditOAuth2ApplicationForm struct {
 	Name        string `binding:"Required;MaxSize(255)" form:"application_name"`
@@ -442,3 +486,17 @@ func (f *PackageSettingForm) Validate(req *http.Request, errs binding.Errors) bi
 	ctx := context.GetContext(req)
 	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
 }
+
+// PackagePolicyForm form for the package quota and retention policy of an owner
+type PackagePolicyForm struct {
+	MaxCount              int64
+	MaxSize               string
+	KeepLastVersions      int
+	UntaggedRetentionDays int
+}
+
+// Validate validates the fields
+func (f *PackagePolicyForm) Validate(req *http.Request, errs binding.Errors) binding.Errors {
+	ctx := context.GetContext(req)
+	return middleware.Validate(errs, ctx.Data, f, ctx.Locale)
+}
@@ -187,7 +187,10 @@ func UpdateIssuesCommit(doer *user_model.User, repo *repo_model.Repository, comm
 			if close != refIssue.IsClosed {
 				refIssue.Repo = refRepo
 				if err := ChangeStatus(refIssue, doer, close); err != nil {
-					return err
+					// Allow ErrDependenciesLeft, as for the issues closed by merged pull requests
+					if !models.IsErrDependenciesLeft(err) {
+						return err
+					}
 				}
 			}
 		}
@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"code.gitea.io/gitea/models"
+	issues_model "code.gitea.io/gitea/models/issues"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/sergi/go-diff/diffmatchpatch"
+)
+
+// CanSoftDeleteContentHistory checks whether the doer can soft-delete a history revision.
+// Admins or owners can always delete history revisions. Normal users can only delete own history revisions.
+func CanSoftDeleteContentHistory(perm access_model.Permission, doer *user_model.User, issue *models.Issue, comment *models.Comment,
+	history *issues_model.ContentHistory,
+) bool {
+	canSoftDelete := false
+	if perm.IsOwner() {
+		canSoftDelete = true
+	} else if perm.CanWrite(unit.TypeIssues) {
+		if comment == nil {
+			// the issue poster or the history poster can soft-delete
+			canSoftDelete = doer.ID == issue.PosterID || doer.ID == history.PosterID
+			canSoftDelete = canSoftDelete && (history.IssueID == issue.ID)
+		} else {
+			// the comment poster or the history poster can soft-delete
+			canSoftDelete = doer.ID == comment.PosterID || doer.ID == history.PosterID
+			canSoftDelete = canSoftDelete && (history.IssueID == issue.ID)
+			canSoftDelete = canSoftDelete && (history.CommentID == comment.ID)
+		}
+	}
+	return canSoftDelete
+}
+
+// DiffContentHistory compares a history revision with the previous one (if exists)
+func DiffContentHistory(history, prevHistory *issues_model.ContentHistory) []diffmatchpatch.Diff {
+	var prevHistoryContentText string
+	if prevHistory != nil {
+		prevHistoryContentText = prevHistory.ContentText
+	}
+
+	dmp := diffmatchpatch.New()
+	// `checklines=false` makes better diff result
+	diff := dmp.DiffMain(prevHistoryContentText, history.ContentText, false)
+	return dmp.DiffCleanupEfficiency(diff)
+}
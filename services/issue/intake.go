@@ -0,0 +1,135 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// ApplyIntakeRules routes a new issue with the first matching intake rule of the organization owning its repository.
+// templateFile is the file name of the issue template the issue has been created from, if any.
+// The issue is only added to the project of the rule if it hasn't been added to a project on creation.
+func ApplyIntakeRules(ctx context.Context, issue *models.Issue, templateFile string) error {
+	if issue.IsPull {
+		return nil
+	}
+	if err := issue.LoadRepo(ctx); err != nil {
+		return err
+	}
+	if err := issue.LoadPoster(); err != nil {
+		return err
+	}
+
+	rules, err := models.GetIssueIntakeRules(ctx, issue.Repo.OwnerID)
+	if err != nil || len(rules) == 0 {
+		return err
+	}
+	if err := issue.LoadLabels(ctx); err != nil {
+		return err
+	}
+	labelNames := make([]string, 0, len(issue.Labels))
+	for _, label := range issue.Labels {
+		labelNames = append(labelNames, label.Name)
+	}
+
+	for _, rule := range rules {
+		if !rule.Match(issue.Repo.Name, templateFile, labelNames) {
+			continue
+		}
+		log.Trace("Issue %d of %s is routed by intake rule %d", issue.Index, issue.Repo.FullName(), rule.ID)
+
+		if rule.ProjectTitle != "" && issue.ProjectID() == 0 {
+			if err := addIssueToIntakeProject(ctx, issue, rule); err != nil {
+				return err
+			}
+		}
+		if rule.TeamID > 0 {
+			if err := assignIntakeTeam(ctx, issue, rule); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// addIssueToIntakeProject adds the issue to the open project of its repository named by the rule, if there is one
+func addIssueToIntakeProject(ctx context.Context, issue *models.Issue, rule *models.IssueIntakeRule) error {
+	projects, _, err := project_model.GetProjects(ctx, project_model.SearchOptions{
+		RepoID:   issue.RepoID,
+		IsClosed: util.OptionalBoolFalse,
+		Type:     project_model.TypeRepository,
+	})
+	if err != nil {
+		return err
+	}
+	var project *project_model.Project
+	for _, p := range projects {
+		if strings.EqualFold(p.Title, rule.ProjectTitle) {
+			project = p
+			break
+		}
+	}
+	if project == nil {
+		return nil
+	}
+
+	if err := models.ChangeProjectAssign(issue, issue.Poster, project.ID); err != nil {
+		return err
+	}
+	if rule.BoardTitle == "" {
+		return nil
+	}
+
+	boards, err := project_model.GetBoards(ctx, project.ID)
+	if err != nil {
+		return err
+	}
+	for _, board := range boards {
+		if board.ID > 0 && strings.EqualFold(board.Title, rule.BoardTitle) {
+			return models.MoveIssueAcrossProjectBoards(issue, board)
+		}
+	}
+	return nil
+}
+
+// assignIntakeTeam assigns the members of the triage team of the rule who can be assigned to the issue
+func assignIntakeTeam(ctx context.Context, issue *models.Issue, rule *models.IssueIntakeRule) error {
+	team, err := organization.GetTeamByID(ctx, rule.TeamID)
+	if err != nil {
+		if organization.IsErrTeamNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if team.OrgID != rule.OrgID {
+		return nil
+	}
+
+	members, err := organization.GetTeamMembers(ctx, &organization.SearchMembersOptions{TeamID: team.ID})
+	if err != nil {
+		return err
+	}
+	for _, member := range members {
+		canBeAssigned, err := access_model.CanBeAssigned(ctx, member, issue.Repo, issue.IsPull)
+		if err != nil {
+			return err
+		} else if !canBeAssigned {
+			continue
+		}
+		if err := AddAssigneeIfNotAssigned(issue, issue.Poster, member.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
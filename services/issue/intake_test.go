@@ -0,0 +1,49 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	project_model "code.gitea.io/gitea/models/project"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyIntakeRules(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	board := &project_model.Board{ProjectID: 2, Title: "Triage", CreatorID: 2}
+	assert.NoError(t, project_model.NewBoard(board))
+
+	// the first matching rule is applied
+	assert.NoError(t, models.CreateIssueIntakeRule(db.DefaultContext, &models.IssueIntakeRule{
+		OrgID: 3, Name: "bugs", TemplateFile: "bug.md", ProjectTitle: "First project",
+	}))
+	assert.NoError(t, models.CreateIssueIntakeRule(db.DefaultContext, &models.IssueIntakeRule{
+		OrgID: 3, Name: "triage", RepoPattern: "repo*", ProjectTitle: "Second Project", BoardTitle: "triage", TeamID: 2,
+	}))
+
+	issue := unittest.AssertExistsAndLoadBean(t, &models.Issue{ID: 6}).(*models.Issue)
+	assert.NoError(t, ApplyIntakeRules(db.DefaultContext, issue, "feature.md"))
+
+	assert.EqualValues(t, 2, issue.ProjectID())
+	assert.EqualValues(t, board.ID, issue.ProjectBoardID())
+	for _, uid := range []int64{2, 4} {
+		user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: uid}).(*user_model.User)
+		isAssigned, err := models.IsUserAssignedToIssue(db.DefaultContext, issue, user)
+		assert.NoError(t, err)
+		assert.True(t, isAssigned)
+	}
+
+	// issues of repositories owned by users are not routed
+	issue = unittest.AssertExistsAndLoadBean(t, &models.Issue{ID: 4}).(*models.Issue)
+	assert.NoError(t, ApplyIntakeRules(db.DefaultContext, issue, "bug.md"))
+	assert.EqualValues(t, 0, issue.ProjectID())
+}
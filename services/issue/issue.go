@@ -13,6 +13,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/util"
 )
@@ -42,6 +43,12 @@ func NewIssue(repo *repo_model.Repository, issue *models.Issue, labelIDs []int64
 		notification.NotifyIssueChangeMilestone(issue.Poster, issue, 0)
 	}
 
+	if !repo.IsEmpty {
+		if err := triageNewIssue(db.DefaultContext, repo, issue); err != nil {
+			log.Error("ApplyTriageRules [%d]: %v", issue.ID, err)
+		}
+	}
+
 	return nil
 }
 
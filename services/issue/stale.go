@@ -0,0 +1,367 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+	comment_service "code.gitea.io/gitea/services/comments"
+
+	"gopkg.in/yaml.v2"
+	"xorm.io/builder"
+)
+
+// StaleFilePath is the path of the stale settings in the default branch of a repository
+const StaleFilePath = ".gitea/stale.yml"
+
+const (
+	staleLabelColor = "#cccccc"
+	secondsPerDay   = 24 * 60 * 60
+)
+
+// ErrInvalidStaleConfig represents a "InvalidStaleConfig" kind of error.
+type ErrInvalidStaleConfig struct {
+	Reason string
+}
+
+// IsErrInvalidStaleConfig checks if an error is a ErrInvalidStaleConfig.
+func IsErrInvalidStaleConfig(err error) bool {
+	_, ok := err.(ErrInvalidStaleConfig)
+	return ok
+}
+
+func (err ErrInvalidStaleConfig) Error() string {
+	return fmt.Sprintf("invalid stale settings %s: %s", StaleFilePath, err.Reason)
+}
+
+// StaleOptions represents how inactive issues and pull requests are marked as stale and closed
+type StaleOptions struct {
+	// DaysUntilStale is the number of days without activity after which an issue is marked as stale
+	DaysUntilStale int
+	// DaysUntilClose is the number of days after being marked as stale after which an issue is closed, 0 never closes
+	DaysUntilClose int
+	StaleLabel     string
+	// ExemptLabels are the labels of issues which are never marked as stale
+	ExemptLabels []string
+	StaleComment string
+	CloseComment string
+	Issues       bool
+	PullRequests bool
+}
+
+// StaleConfig represents the stale settings declared by a repository, unset fields keep the site wide settings
+type StaleConfig struct {
+	Enabled        *bool   `yaml:"enabled"`
+	DaysUntilStale *int    `yaml:"days_until_stale"`
+	DaysUntilClose *int    `yaml:"days_until_close"`
+	StaleLabel     *string `yaml:"stale_label"`
+	// ExemptLabels are added to the site wide exempt labels
+	ExemptLabels []string `yaml:"exempt_labels"`
+	StaleComment *string  `yaml:"stale_comment"`
+	CloseComment *string  `yaml:"close_comment"`
+	Issues       *bool    `yaml:"issues"`
+	PullRequests *bool    `yaml:"pull_requests"`
+}
+
+// ParseStaleConfig parses and validates the content of a stale settings file
+func ParseStaleConfig(content []byte) (*StaleConfig, error) {
+	cfg := new(StaleConfig)
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, ErrInvalidStaleConfig{Reason: err.Error()}
+	}
+	if cfg.DaysUntilStale != nil && *cfg.DaysUntilStale <= 0 {
+		return nil, ErrInvalidStaleConfig{Reason: "days_until_stale must be positive"}
+	}
+	if cfg.DaysUntilClose != nil && *cfg.DaysUntilClose < 0 {
+		return nil, ErrInvalidStaleConfig{Reason: "days_until_close must not be negative"}
+	}
+	if cfg.StaleLabel != nil && strings.TrimSpace(*cfg.StaleLabel) == "" {
+		return nil, ErrInvalidStaleConfig{Reason: "stale_label must not be empty"}
+	}
+	return cfg, nil
+}
+
+// GetStaleConfig reads the stale settings of a commit, it returns nil if the commit has no stale settings
+func GetStaleConfig(commit *git.Commit) (*StaleConfig, error) {
+	entry, err := commit.GetTreeEntryByPath(StaleFilePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	r, err := entry.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseStaleConfig(content)
+}
+
+// Apply returns the options overridden by the settings of a repository and whether the repository is processed at all
+func (cfg *StaleConfig) Apply(opts StaleOptions) (StaleOptions, bool) {
+	if cfg == nil {
+		return opts, true
+	}
+	if cfg.Enabled != nil && !*cfg.Enabled {
+		return opts, false
+	}
+	if cfg.DaysUntilStale != nil {
+		opts.DaysUntilStale = *cfg.DaysUntilStale
+	}
+	if cfg.DaysUntilClose != nil {
+		opts.DaysUntilClose = *cfg.DaysUntilClose
+	}
+	if cfg.StaleLabel != nil {
+		opts.StaleLabel = strings.TrimSpace(*cfg.StaleLabel)
+	}
+	if len(cfg.ExemptLabels) > 0 {
+		opts.ExemptLabels = append(append([]string{}, opts.ExemptLabels...), cfg.ExemptLabels...)
+	}
+	if cfg.StaleComment != nil {
+		opts.StaleComment = *cfg.StaleComment
+	}
+	if cfg.CloseComment != nil {
+		opts.CloseComment = *cfg.CloseComment
+	}
+	if cfg.Issues != nil {
+		opts.Issues = *cfg.Issues
+	}
+	if cfg.PullRequests != nil {
+		opts.PullRequests = *cfg.PullRequests
+	}
+	return opts, true
+}
+
+// MarkAndCloseStaleIssues marks the open issues and pull requests of all repositories which had no activity for
+// DaysUntilStale days as stale, and closes the ones still inactive DaysUntilClose days later.
+// Issues with new activity lose their stale label. The actions are done on behalf of doer.
+func MarkAndCloseStaleIssues(ctx context.Context, doer *user_model.User, opts StaleOptions) error {
+	repoIDs := make([]int64, 0, 10)
+	if err := db.GetEngine(ctx).Table("repository").Cols("id").
+		Where(builder.Eq{"is_archived": false}.
+			And(builder.Expr("num_issues > num_closed_issues OR num_pulls > num_closed_pulls"))).
+		Find(&repoIDs); err != nil {
+		return err
+	}
+
+	for _, repoID := range repoIDs {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before processing the stale issues of repository %d", repoID)
+		default:
+		}
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				continue
+			}
+			return err
+		}
+		if err := processStaleIssues(ctx, doer, repo, opts); err != nil {
+			log.Error("Unable to process the stale issues of %s: %v", repo.FullName(), err)
+		}
+	}
+	return nil
+}
+
+// getRepoStaleOptions returns the options overridden by the stale settings in the default branch of a repository
+func getRepoStaleOptions(ctx context.Context, repo *repo_model.Repository, opts StaleOptions) (StaleOptions, bool, error) {
+	if repo.IsEmpty {
+		return opts, true, nil
+	}
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return opts, false, err
+	}
+	defer gitRepo.Close()
+
+	commit, err := gitRepo.GetBranchCommit(repo.DefaultBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return opts, true, nil
+		}
+		return opts, false, err
+	}
+	cfg, err := GetStaleConfig(commit)
+	if err != nil {
+		return opts, false, err
+	}
+	opts, enabled := cfg.Apply(opts)
+	return opts, enabled, nil
+}
+
+func processStaleIssues(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, opts StaleOptions) error {
+	opts, enabled, err := getRepoStaleOptions(ctx, repo, opts)
+	if err != nil || !enabled || opts.DaysUntilStale <= 0 || opts.StaleLabel == "" {
+		return err
+	}
+
+	var kinds []util.OptionalBool
+	if opts.Issues && repo.UnitEnabledCtx(ctx, unit.TypeIssues) {
+		kinds = append(kinds, util.OptionalBoolFalse)
+	}
+	if opts.PullRequests && repo.UnitEnabledCtx(ctx, unit.TypePullRequests) {
+		kinds = append(kinds, util.OptionalBoolTrue)
+	}
+	if len(kinds) == 0 {
+		return nil
+	}
+
+	label, err := getOrCreateStaleLabel(ctx, repo, opts.StaleLabel)
+	if err != nil {
+		return err
+	}
+
+	now := timeutil.TimeStampNow()
+	for _, isPull := range kinds {
+		staleIssues, err := models.Issues(&models.IssuesOptions{
+			RepoID:   repo.ID,
+			IsClosed: util.OptionalBoolFalse,
+			IsPull:   isPull,
+			LabelIDs: []int64{label.ID},
+		})
+		if err != nil {
+			return err
+		}
+		for _, issue := range staleIssues {
+			issue.Repo = repo
+			if err := processStaleIssue(ctx, doer, issue, label, opts, now); err != nil {
+				return err
+			}
+		}
+
+		inactiveIssues, err := models.Issues(&models.IssuesOptions{
+			RepoID:             repo.ID,
+			IsClosed:           util.OptionalBoolFalse,
+			IsPull:             isPull,
+			UpdatedBeforeUnix:  int64(now.Add(-int64(opts.DaysUntilStale) * secondsPerDay)),
+			ExcludedLabelNames: append(append([]string{}, opts.ExemptLabels...), label.Name),
+		})
+		if err != nil {
+			return err
+		}
+		for _, issue := range inactiveIssues {
+			issue.Repo = repo
+			log.Trace("Issue %d of %s is marked as stale", issue.Index, repo.FullName())
+			if err := AddLabel(issue, doer, label); err != nil {
+				return err
+			}
+			if comment := strings.TrimSpace(opts.StaleComment); comment != "" {
+				if _, err := comment_service.CreateIssueComment(doer, repo, issue, comment, nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// processStaleIssue removes the stale label of an issue with activity since it was marked as stale, or closes it when it stayed inactive
+func processStaleIssue(ctx context.Context, doer *user_model.User, issue *models.Issue, label *models.Label, opts StaleOptions, now timeutil.TimeStamp) error {
+	markedUnix, err := getStaleMarkedUnix(ctx, issue, label)
+	if err != nil {
+		return err
+	}
+	if markedUnix == 0 {
+		// the label was added before the history of labels was kept, count from the last update
+		markedUnix = issue.UpdatedUnix
+	}
+
+	comments, err := models.FindComments(ctx, &models.FindCommentsOptions{
+		IssueID: issue.ID,
+		Since:   int64(markedUnix),
+	})
+	if err != nil {
+		return err
+	}
+	for _, comment := range comments {
+		// changing labels, including marking as stale by hand, is no activity
+		if comment.PosterID != doer.ID && comment.Type != models.CommentTypeLabel {
+			log.Trace("Issue %d of %s is no longer stale", issue.Index, issue.Repo.FullName())
+			return RemoveLabel(issue, doer, label)
+		}
+	}
+
+	if opts.DaysUntilClose <= 0 || markedUnix.Add(int64(opts.DaysUntilClose)*secondsPerDay) > now {
+		return nil
+	}
+	log.Trace("Stale issue %d of %s is closed", issue.Index, issue.Repo.FullName())
+	if comment := strings.TrimSpace(opts.CloseComment); comment != "" {
+		if _, err := comment_service.CreateIssueComment(doer, issue.Repo, issue, comment, nil); err != nil {
+			return err
+		}
+	}
+	return ChangeStatus(issue, doer, true)
+}
+
+// getStaleMarkedUnix returns when the stale label was last added to an issue, 0 if there is no record of it
+func getStaleMarkedUnix(ctx context.Context, issue *models.Issue, label *models.Label) (timeutil.TimeStamp, error) {
+	comments, err := models.FindComments(ctx, &models.FindCommentsOptions{
+		IssueID: issue.ID,
+		Type:    models.CommentTypeLabel,
+	})
+	if err != nil {
+		return 0, err
+	}
+	var markedUnix timeutil.TimeStamp
+	for _, comment := range comments {
+		if comment.LabelID == label.ID && comment.Content == "1" && comment.CreatedUnix > markedUnix {
+			markedUnix = comment.CreatedUnix
+		}
+	}
+	return markedUnix, nil
+}
+
+// getOrCreateStaleLabel returns the label of the repository, or of the organization owning it, with the given name.
+// The label is created in the repository if there is none.
+func getOrCreateStaleLabel(ctx context.Context, repo *repo_model.Repository, name string) (*models.Label, error) {
+	label, err := models.GetLabelInRepoByName(ctx, repo.ID, name)
+	if err == nil {
+		return label, nil
+	} else if !models.IsErrRepoLabelNotExist(err) {
+		return nil, err
+	}
+
+	if err := repo.GetOwner(ctx); err != nil {
+		return nil, err
+	}
+	if repo.Owner.IsOrganization() {
+		label, err = models.GetLabelInOrgByName(ctx, repo.OwnerID, name)
+		if err == nil {
+			return label, nil
+		} else if !models.IsErrOrgLabelNotExist(err) {
+			return nil, err
+		}
+	}
+
+	label = &models.Label{
+		RepoID: repo.ID,
+		Name:   name,
+		Color:  staleLabelColor,
+	}
+	if err := models.NewLabel(ctx, label); err != nil {
+		return nil, err
+	}
+	return label, nil
+}
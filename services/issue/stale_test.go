@@ -0,0 +1,97 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+	comment_service "code.gitea.io/gitea/services/comments"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseStaleConfig(t *testing.T) {
+	opts := StaleOptions{
+		DaysUntilStale: 60,
+		DaysUntilClose: 7,
+		StaleLabel:     "stale",
+		ExemptLabels:   []string{"pinned"},
+		Issues:         true,
+		PullRequests:   true,
+	}
+
+	cfg, err := ParseStaleConfig([]byte(`
+days_until_stale: 30
+days_until_close: 0
+exempt_labels: [security]
+pull_requests: false
+`))
+	assert.NoError(t, err)
+	repoOpts, enabled := cfg.Apply(opts)
+	assert.True(t, enabled)
+	assert.Equal(t, 30, repoOpts.DaysUntilStale)
+	assert.Equal(t, 0, repoOpts.DaysUntilClose)
+	assert.Equal(t, "stale", repoOpts.StaleLabel)
+	assert.Equal(t, []string{"pinned", "security"}, repoOpts.ExemptLabels)
+	assert.True(t, repoOpts.Issues)
+	assert.False(t, repoOpts.PullRequests)
+	assert.Equal(t, []string{"pinned"}, opts.ExemptLabels)
+
+	cfg, err = ParseStaleConfig([]byte("enabled: false\n"))
+	assert.NoError(t, err)
+	_, enabled = cfg.Apply(opts)
+	assert.False(t, enabled)
+
+	_, enabled = (*StaleConfig)(nil).Apply(opts)
+	assert.True(t, enabled)
+
+	for _, content := range []string{
+		"days_until_stale: 0\n",
+		"days_until_close: -1\n",
+		"stale_label: \" \"\n",
+		"unknown: true\n",
+	} {
+		_, err := ParseStaleConfig([]byte(content))
+		assert.True(t, IsErrInvalidStaleConfig(err), content)
+	}
+}
+
+func TestProcessStaleIssue(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	opts := StaleOptions{DaysUntilStale: 60, DaysUntilClose: 7, StaleLabel: "stale"}
+	later := timeutil.TimeStampNow().Add(8 * secondsPerDay)
+
+	loadIssue := func(id int64) *models.Issue {
+		issue := unittest.AssertExistsAndLoadBean(t, &models.Issue{ID: id}).(*models.Issue)
+		assert.NoError(t, issue.LoadRepo(db.DefaultContext))
+		return issue
+	}
+
+	issue := loadIssue(1)
+	label, err := getOrCreateStaleLabel(db.DefaultContext, issue.Repo, "stale")
+	assert.NoError(t, err)
+	unittest.AssertExistsAndLoadBean(t, &models.Label{ID: label.ID, RepoID: issue.RepoID, Name: "stale"})
+
+	// issues staying inactive are closed after DaysUntilClose
+	assert.NoError(t, AddLabel(issue, doer, label))
+	assert.NoError(t, processStaleIssue(db.DefaultContext, doer, issue, label, opts, timeutil.TimeStampNow()))
+	assert.False(t, loadIssue(1).IsClosed)
+	assert.NoError(t, processStaleIssue(db.DefaultContext, doer, issue, label, opts, later))
+	assert.True(t, loadIssue(1).IsClosed)
+
+	// activity of other users removes the stale label
+	_, err = comment_service.CreateIssueComment(user2, issue.Repo, issue, "still relevant", nil)
+	assert.NoError(t, err)
+	assert.NoError(t, processStaleIssue(db.DefaultContext, doer, issue, label, opts, later))
+	assert.False(t, models.HasIssueLabel(db.DefaultContext, issue.ID, label.ID))
+}
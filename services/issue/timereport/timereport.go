@@ -0,0 +1,277 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package timereport
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	issues_model "code.gitea.io/gitea/models/issues"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"xorm.io/builder"
+)
+
+// GroupBy represents how the tracked times of a report are grouped
+type GroupBy string
+
+// The ways tracked times can be grouped
+const (
+	GroupByUser      GroupBy = "user"
+	GroupByLabel     GroupBy = "label"
+	GroupByMilestone GroupBy = "milestone"
+)
+
+// IsValid returns true if the grouping is known
+func (g GroupBy) IsValid() bool {
+	switch g {
+	case GroupByUser, GroupByLabel, GroupByMilestone:
+		return true
+	}
+	return false
+}
+
+// Options represents the filters of a time tracking report. If an ID is 0 it will be ignored.
+type Options struct {
+	// RepoID restricts the report to the issues of one repository
+	RepoID int64
+	// OwnerID restricts the report to the issues of all repositories of a user or organization
+	OwnerID int64
+	// UserID restricts the report to the times tracked by one user
+	UserID            int64
+	CreatedAfterUnix  int64
+	CreatedBeforeUnix int64
+	GroupBy           GroupBy
+}
+
+func (opts *Options) toCond() builder.Cond {
+	cond := builder.NewCond().And(builder.Eq{"tracked_time.deleted": false})
+	if opts.RepoID != 0 {
+		cond = cond.And(builder.Eq{"issue.repo_id": opts.RepoID})
+	}
+	if opts.OwnerID != 0 {
+		cond = cond.And(builder.In("issue.repo_id", builder.Select("id").From("repository").Where(builder.Eq{"owner_id": opts.OwnerID})))
+	}
+	if opts.UserID != 0 {
+		cond = cond.And(builder.Eq{"tracked_time.user_id": opts.UserID})
+	}
+	if opts.CreatedAfterUnix != 0 {
+		cond = cond.And(builder.Gte{"tracked_time.created_unix": opts.CreatedAfterUnix})
+	}
+	if opts.CreatedBeforeUnix != 0 {
+		cond = cond.And(builder.Lte{"tracked_time.created_unix": opts.CreatedBeforeUnix})
+	}
+	return cond
+}
+
+// Entry represents the tracked time of one group of a report
+type Entry struct {
+	// ID is the id of the user, label or milestone, 0 for times of issues without label or milestone
+	ID   int64
+	Name string
+	// Repo is the full name of the repository of the label or milestone, empty for users and organization labels
+	Repo    string
+	Issues  int64
+	Seconds int64
+}
+
+// Report represents the tracked times of a repository or organization aggregated by a grouping
+type Report struct {
+	GroupBy GroupBy
+	Entries []*Entry
+	// Issues and Seconds count every tracked time once, even if it is part of several groups
+	Issues  int64
+	Seconds int64
+}
+
+type groupRow struct {
+	GroupID int64 `xorm:"group_id"`
+	Issues  int64 `xorm:"issues"`
+	Seconds int64 `xorm:"seconds"`
+}
+
+// Generate aggregates the tracked times matching the options into a report.
+// When grouping by label, times of issues with several labels count for each of them.
+func Generate(ctx context.Context, opts *Options) (*Report, error) {
+	if !opts.GroupBy.IsValid() {
+		return nil, fmt.Errorf("unknown grouping %q", opts.GroupBy)
+	}
+
+	var groupExpr string
+	sess := db.GetEngine(ctx).Table("tracked_time").
+		Join("INNER", "issue", "issue.id = tracked_time.issue_id")
+	switch opts.GroupBy {
+	case GroupByUser:
+		groupExpr = "tracked_time.user_id"
+	case GroupByMilestone:
+		groupExpr = "issue.milestone_id"
+	case GroupByLabel:
+		groupExpr = "COALESCE(issue_label.label_id, 0)"
+		sess = sess.Join("LEFT", "issue_label", "issue_label.issue_id = issue.id")
+	}
+
+	rows := make([]*groupRow, 0, 10)
+	if err := sess.Where(opts.toCond()).
+		Select(groupExpr + " AS group_id, COUNT(DISTINCT tracked_time.issue_id) AS issues, SUM(tracked_time.time) AS seconds").
+		GroupBy(groupExpr).
+		Find(&rows); err != nil {
+		return nil, err
+	}
+
+	report := &Report{
+		GroupBy: opts.GroupBy,
+		Entries: make([]*Entry, 0, len(rows)),
+	}
+	var total groupRow
+	if _, err := db.GetEngine(ctx).Table("tracked_time").
+		Join("INNER", "issue", "issue.id = tracked_time.issue_id").
+		Where(opts.toCond()).
+		Select("COUNT(DISTINCT tracked_time.issue_id) AS issues, COALESCE(SUM(tracked_time.time), 0) AS seconds").
+		Get(&total); err != nil {
+		return nil, err
+	}
+	report.Issues = total.Issues
+	report.Seconds = total.Seconds
+
+	ids := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		report.Entries = append(report.Entries, &Entry{
+			ID:      row.GroupID,
+			Issues:  row.Issues,
+			Seconds: row.Seconds,
+		})
+		if row.GroupID != 0 {
+			ids = append(ids, row.GroupID)
+		}
+	}
+
+	if err := loadEntryNames(ctx, report, ids); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(report.Entries, func(i, j int) bool {
+		if report.Entries[i].Seconds != report.Entries[j].Seconds {
+			return report.Entries[i].Seconds > report.Entries[j].Seconds
+		}
+		return report.Entries[i].Name < report.Entries[j].Name
+	})
+	return report, nil
+}
+
+func loadEntryNames(ctx context.Context, report *Report, ids []int64) error {
+	names := make(map[int64]string, len(ids))
+	repoIDs := make(map[int64]int64, len(ids))
+
+	switch report.GroupBy {
+	case GroupByUser:
+		users, err := user_model.GetUsersByIDs(ids)
+		if err != nil {
+			return err
+		}
+		for _, u := range users {
+			names[u.ID] = u.Name
+		}
+	case GroupByMilestone:
+		milestones := make([]*issues_model.Milestone, 0, len(ids))
+		if err := db.GetEngine(ctx).In("id", ids).Find(&milestones); err != nil {
+			return err
+		}
+		for _, m := range milestones {
+			names[m.ID] = m.Name
+			repoIDs[m.ID] = m.RepoID
+		}
+	case GroupByLabel:
+		labels := make([]*models.Label, 0, len(ids))
+		if err := db.GetEngine(ctx).In("id", ids).Find(&labels); err != nil {
+			return err
+		}
+		for _, l := range labels {
+			names[l.ID] = l.Name
+			repoIDs[l.ID] = l.RepoID
+		}
+	}
+
+	var repos map[int64]*repo_model.Repository
+	if report.GroupBy != GroupByUser {
+		ids := make([]int64, 0, len(repoIDs))
+		for _, id := range repoIDs {
+			if id != 0 {
+				ids = append(ids, id)
+			}
+		}
+		var err error
+		if repos, err = repo_model.GetRepositoriesMapByIDs(ids); err != nil {
+			return err
+		}
+	}
+
+	for _, entry := range report.Entries {
+		if entry.ID == 0 {
+			continue
+		}
+		name, ok := names[entry.ID]
+		if !ok {
+			// the user, label or milestone has been deleted since
+			if report.GroupBy == GroupByUser {
+				name = user_model.NewGhostUser().Name
+			} else {
+				name = strconv.FormatInt(entry.ID, 10)
+			}
+		}
+		entry.Name = name
+		if repo, ok := repos[repoIDs[entry.ID]]; ok {
+			entry.Repo = repo.FullName()
+		}
+	}
+	return nil
+}
+
+// WriteCSV writes the report as comma separated values with one line per entry
+func WriteCSV(w io.Writer, report *Report) error {
+	writer := csv.NewWriter(w)
+	if err := writer.Write([]string{string(report.GroupBy), "repository", "issues", "seconds"}); err != nil {
+		return err
+	}
+	for _, entry := range report.Entries {
+		if err := writer.Write([]string{
+			entry.Name,
+			entry.Repo,
+			strconv.FormatInt(entry.Issues, 10),
+			strconv.FormatInt(entry.Seconds, 10),
+		}); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
+
+// ToAPIReport converts a report to its API format
+func ToAPIReport(report *Report) *api.TimeReport {
+	result := &api.TimeReport{
+		GroupBy: string(report.GroupBy),
+		Entries: make([]*api.TimeReportEntry, 0, len(report.Entries)),
+		Issues:  report.Issues,
+		Time:    report.Seconds,
+	}
+	for _, entry := range report.Entries {
+		result.Entries = append(result.Entries, &api.TimeReportEntry{
+			ID:     entry.ID,
+			Name:   entry.Name,
+			Repo:   entry.Repo,
+			Issues: entry.Issues,
+			Time:   entry.Seconds,
+		})
+	}
+	return result
+}
@@ -0,0 +1,70 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package timereport
+
+import (
+	"bytes"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGenerate(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	report, err := Generate(db.DefaultContext, &Options{RepoID: 1, GroupBy: GroupByUser})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4083, report.Seconds)
+	assert.EqualValues(t, 3, report.Issues)
+	if assert.Len(t, report.Entries, 2) {
+		assert.EqualValues(t, &Entry{ID: 2, Name: "user2", Issues: 2, Seconds: 3663}, report.Entries[0])
+		assert.EqualValues(t, &Entry{ID: 1, Name: "user1", Issues: 2, Seconds: 420}, report.Entries[1])
+	}
+
+	// times of issues with several labels count for each label
+	report, err = Generate(db.DefaultContext, &Options{RepoID: 1, GroupBy: GroupByLabel})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 4083, report.Seconds)
+	if assert.Len(t, report.Entries, 3) {
+		assert.EqualValues(t, &Entry{ID: 1, Name: "label1", Repo: "user2/repo1", Issues: 2, Seconds: 4082}, report.Entries[0])
+		assert.EqualValues(t, &Entry{ID: 4, Name: "orglabel4", Issues: 1, Seconds: 3682}, report.Entries[1])
+		assert.EqualValues(t, &Entry{ID: 2, Name: "label2", Repo: "user2/repo1", Issues: 1, Seconds: 1}, report.Entries[2])
+	}
+
+	report, err = Generate(db.DefaultContext, &Options{RepoID: 1, GroupBy: GroupByMilestone})
+	assert.NoError(t, err)
+	if assert.Len(t, report.Entries, 2) {
+		assert.EqualValues(t, &Entry{ID: 1, Name: "milestone1", Repo: "user2/repo1", Issues: 1, Seconds: 3682}, report.Entries[0])
+		assert.EqualValues(t, &Entry{ID: 0, Issues: 2, Seconds: 401}, report.Entries[1])
+	}
+
+	report, err = Generate(db.DefaultContext, &Options{OwnerID: 2, UserID: 1, CreatedBeforeUnix: 946684811, GroupBy: GroupByUser})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 400, report.Seconds)
+	assert.Len(t, report.Entries, 1)
+
+	report, err = Generate(db.DefaultContext, &Options{RepoID: 1, CreatedAfterUnix: 947688815, GroupBy: GroupByUser})
+	assert.NoError(t, err)
+	assert.EqualValues(t, 0, report.Seconds)
+	assert.Empty(t, report.Entries)
+
+	_, err = Generate(db.DefaultContext, &Options{RepoID: 1, GroupBy: "repository"})
+	assert.Error(t, err)
+}
+
+func TestWriteCSV(t *testing.T) {
+	var buf bytes.Buffer
+	assert.NoError(t, WriteCSV(&buf, &Report{
+		GroupBy: GroupByLabel,
+		Entries: []*Entry{
+			{ID: 1, Name: "bug, critical", Repo: "user2/repo1", Issues: 2, Seconds: 60},
+			{ID: 0, Issues: 1, Seconds: 5},
+		},
+	}))
+	assert.EqualValues(t, "label,repository,issues,seconds\n\"bug, critical\",user2/repo1,2,60\n,,1,5\n", buf.String())
+}
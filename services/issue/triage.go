@@ -0,0 +1,366 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	comment_service "code.gitea.io/gitea/services/comments"
+
+	"github.com/gobwas/glob"
+	"gopkg.in/yaml.v2"
+)
+
+// TriageFilePath is the path of the triage rules in the default branch of a repository
+const TriageFilePath = ".gitea/triage.yml"
+
+// Author associations a triage rule can match
+const (
+	AuthorAssociationOwner                = "owner"
+	AuthorAssociationMember               = "member"
+	AuthorAssociationCollaborator         = "collaborator"
+	AuthorAssociationFirstTimeContributor = "first_time_contributor"
+	AuthorAssociationNone                 = "none"
+)
+
+// ErrInvalidTriageRules represents a "InvalidTriageRules" kind of error.
+type ErrInvalidTriageRules struct {
+	Reason string
+}
+
+// IsErrInvalidTriageRules checks if an error is a ErrInvalidTriageRules.
+func IsErrInvalidTriageRules(err error) bool {
+	_, ok := err.(ErrInvalidTriageRules)
+	return ok
+}
+
+func (err ErrInvalidTriageRules) Error() string {
+	return fmt.Sprintf("invalid triage rules %s: %s", TriageFilePath, err.Reason)
+}
+
+// TriageConfig represents the triage rules of a repository, every matching rule is applied
+type TriageConfig struct {
+	Rules []*TriageRule `yaml:"rules"`
+}
+
+// TriageRule labels, assigns or comments on new issues and pull requests matching all of its conditions
+type TriageRule struct {
+	Name string `yaml:"name"`
+	// On is any of issues and pull_requests, both if empty
+	On                []string `yaml:"on"`
+	Title             string   `yaml:"title"`
+	Body              string   `yaml:"body"`
+	Paths             []string `yaml:"paths"`
+	AuthorAssociation []string `yaml:"author_association"`
+
+	Labels    []string `yaml:"labels"`
+	Assignees []string `yaml:"assignees"`
+	Comment   string   `yaml:"comment"`
+
+	titleRegexp *regexp.Regexp
+	bodyRegexp  *regexp.Regexp
+	pathGlobs   []glob.Glob
+}
+
+// ParseTriageRules parses and validates the content of a triage rules file
+func ParseTriageRules(content []byte) (*TriageConfig, error) {
+	cfg := new(TriageConfig)
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, ErrInvalidTriageRules{Reason: err.Error()}
+	}
+
+	for i, rule := range cfg.Rules {
+		if rule.Name == "" {
+			rule.Name = fmt.Sprintf("#%d", i+1)
+		}
+		for _, on := range rule.On {
+			if on != "issues" && on != "pull_requests" {
+				return nil, ErrInvalidTriageRules{Reason: fmt.Sprintf("unknown event %q of rule %s", on, rule.Name)}
+			}
+		}
+		var err error
+		if rule.Title != "" {
+			if rule.titleRegexp, err = regexp.Compile(rule.Title); err != nil {
+				return nil, ErrInvalidTriageRules{Reason: fmt.Sprintf("bad title pattern of rule %s: %v", rule.Name, err)}
+			}
+		}
+		if rule.Body != "" {
+			if rule.bodyRegexp, err = regexp.Compile(rule.Body); err != nil {
+				return nil, ErrInvalidTriageRules{Reason: fmt.Sprintf("bad body pattern of rule %s: %v", rule.Name, err)}
+			}
+		}
+		for _, pattern := range rule.Paths {
+			g, err := glob.Compile(pattern, '/')
+			if err != nil {
+				return nil, ErrInvalidTriageRules{Reason: fmt.Sprintf("bad path pattern %q of rule %s: %v", pattern, rule.Name, err)}
+			}
+			rule.pathGlobs = append(rule.pathGlobs, g)
+		}
+		for _, association := range rule.AuthorAssociation {
+			switch association {
+			case AuthorAssociationOwner, AuthorAssociationMember, AuthorAssociationCollaborator,
+				AuthorAssociationFirstTimeContributor, AuthorAssociationNone:
+			default:
+				return nil, ErrInvalidTriageRules{Reason: fmt.Sprintf("unknown author association %q of rule %s", association, rule.Name)}
+			}
+		}
+		if len(rule.Labels) == 0 && len(rule.Assignees) == 0 && strings.TrimSpace(rule.Comment) == "" {
+			return nil, ErrInvalidTriageRules{Reason: fmt.Sprintf("rule %s has no labels, assignees or comment", rule.Name)}
+		}
+	}
+	return cfg, nil
+}
+
+// GetTriageRules reads the triage rules of a commit, it returns nil if the commit has no triage rules
+func GetTriageRules(commit *git.Commit) (*TriageConfig, error) {
+	entry, err := commit.GetTreeEntryByPath(TriageFilePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	r, err := entry.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTriageRules(content)
+}
+
+// Match returns true if the issue, the changed files of a pull request and the associations of its author satisfy all conditions of the rule
+func (rule *TriageRule) Match(issue *models.Issue, changedFiles, associations []string) bool {
+	if len(rule.On) > 0 {
+		event := "issues"
+		if issue.IsPull {
+			event = "pull_requests"
+		}
+		if !containsString(rule.On, event) {
+			return false
+		}
+	}
+	if rule.titleRegexp != nil && !rule.titleRegexp.MatchString(issue.Title) {
+		return false
+	}
+	if rule.bodyRegexp != nil && !rule.bodyRegexp.MatchString(issue.Content) {
+		return false
+	}
+	if len(rule.pathGlobs) > 0 {
+		if !issue.IsPull || !matchAnyPath(rule.pathGlobs, changedFiles) {
+			return false
+		}
+	}
+	if len(rule.AuthorAssociation) > 0 {
+		matched := false
+		for _, association := range associations {
+			if containsString(rule.AuthorAssociation, association) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func matchAnyPath(globs []glob.Glob, files []string) bool {
+	for _, file := range files {
+		if file == "" {
+			continue
+		}
+		for _, g := range globs {
+			if g.Match(file) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// getAuthorAssociations returns the associations of the poster of an issue with its repository
+func getAuthorAssociations(ctx context.Context, issue *models.Issue) ([]string, error) {
+	var associations []string
+	repo := issue.Repo
+	if repo.OwnerID == issue.PosterID {
+		associations = append(associations, AuthorAssociationOwner)
+	} else if repo.Owner.IsOrganization() {
+		isOwner, err := organization.IsOrganizationOwner(ctx, repo.OwnerID, issue.PosterID)
+		if err != nil {
+			return nil, err
+		}
+		if isOwner {
+			associations = append(associations, AuthorAssociationOwner)
+		}
+		isMember, err := organization.IsOrganizationMember(ctx, repo.OwnerID, issue.PosterID)
+		if err != nil {
+			return nil, err
+		}
+		if isMember {
+			associations = append(associations, AuthorAssociationMember)
+		}
+	}
+
+	isCollaborator, err := repo_model.IsCollaborator(ctx, repo.ID, issue.PosterID)
+	if err != nil {
+		return nil, err
+	}
+	if isCollaborator {
+		associations = append(associations, AuthorAssociationCollaborator)
+	}
+
+	if len(associations) == 0 {
+		count, err := models.CountIssues(&models.IssuesOptions{RepoID: repo.ID, PosterID: issue.PosterID})
+		if err != nil {
+			return nil, err
+		}
+		if count <= 1 {
+			associations = append(associations, AuthorAssociationFirstTimeContributor)
+		}
+		associations = append(associations, AuthorAssociationNone)
+	}
+	return associations, nil
+}
+
+// ApplyTriageRules applies every matching rule of the triage rules in the default branch of the repository to a new issue or pull request.
+// changedFiles are the files changed by a pull request, they are matched by the paths of the rules.
+// The actions are done on behalf of the poster of the issue.
+func ApplyTriageRules(ctx context.Context, gitRepo *git.Repository, issue *models.Issue, changedFiles []string) error {
+	if err := issue.LoadRepo(ctx); err != nil {
+		return err
+	}
+	if issue.Repo.IsEmpty {
+		return nil
+	}
+	if err := issue.LoadPoster(); err != nil {
+		return err
+	}
+
+	commit, err := gitRepo.GetBranchCommit(issue.Repo.DefaultBranch)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	cfg, err := GetTriageRules(commit)
+	if err != nil || cfg == nil || len(cfg.Rules) == 0 {
+		return err
+	}
+
+	if err := issue.Repo.GetOwner(ctx); err != nil {
+		return err
+	}
+	associations, err := getAuthorAssociations(ctx, issue)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range cfg.Rules {
+		if !rule.Match(issue, changedFiles, associations) {
+			continue
+		}
+		log.Trace("Issue %d of %s is triaged by rule %s", issue.Index, issue.Repo.FullName(), rule.Name)
+
+		if err := addTriageLabels(ctx, issue, rule.Labels); err != nil {
+			return err
+		}
+		if err := addTriageAssignees(ctx, issue, rule.Assignees); err != nil {
+			return err
+		}
+		if comment := strings.TrimSpace(rule.Comment); comment != "" {
+			if _, err := comment_service.CreateIssueComment(issue.Poster, issue.Repo, issue, comment, nil); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// triageNewIssue applies the triage rules of the repository to a new issue
+func triageNewIssue(ctx context.Context, repo *repo_model.Repository, issue *models.Issue) error {
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+	return ApplyTriageRules(ctx, gitRepo, issue, nil)
+}
+
+// addTriageLabels adds the labels of the repository, or of the organization owning it, named by a rule
+func addTriageLabels(ctx context.Context, issue *models.Issue, names []string) error {
+	if len(names) == 0 {
+		return nil
+	}
+	labelIDs, err := models.GetLabelIDsInRepoByNames(issue.RepoID, names)
+	if err != nil {
+		return err
+	}
+	if issue.Repo.Owner.IsOrganization() {
+		orgLabelIDs, err := models.GetLabelIDsInOrgByNames(issue.Repo.OwnerID, names)
+		if err != nil {
+			return err
+		}
+		labelIDs = append(labelIDs, orgLabelIDs...)
+	}
+	if len(labelIDs) == 0 {
+		return nil
+	}
+	labels, err := models.GetLabelsByIDs(labelIDs)
+	if err != nil {
+		return err
+	}
+	return AddLabels(issue, issue.Poster, labels)
+}
+
+// addTriageAssignees assigns the users named by a rule who can be assigned to the issue
+func addTriageAssignees(ctx context.Context, issue *models.Issue, names []string) error {
+	for _, name := range names {
+		assignee, err := user_model.GetUserByName(ctx, name)
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				continue
+			}
+			return err
+		}
+		canBeAssigned, err := access_model.CanBeAssigned(ctx, assignee, issue.Repo, issue.IsPull)
+		if err != nil {
+			return err
+		} else if !canBeAssigned {
+			continue
+		}
+		if err := AddAssigneeIfNotAssigned(issue, issue.Poster, assignee.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package issue
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseTriageRules(t *testing.T) {
+	cfg, err := ParseTriageRules([]byte(`
+rules:
+  - name: bugs
+    on: [issues]
+    title: "(?i)crash|panic"
+    labels: [kind/bug]
+  - paths: ["docs/**", "*.md"]
+    author_association: [first_time_contributor]
+    comment: Thanks for your first contribution!
+`))
+	assert.NoError(t, err)
+	if assert.Len(t, cfg.Rules, 2) {
+		assert.Equal(t, "bugs", cfg.Rules[0].Name)
+		assert.Equal(t, "#2", cfg.Rules[1].Name)
+	}
+
+	for _, content := range []string{
+		"rules:\n  - title: \"(\"\n    labels: [bug]\n",
+		"rules:\n  - paths: [\"docs/[\"]\n    labels: [docs]\n",
+		"rules:\n  - on: [pushes]\n    labels: [bug]\n",
+		"rules:\n  - author_association: [stranger]\n    labels: [bug]\n",
+		"rules:\n  - title: crash\n",
+		"rules:\n  - unknown: true\n",
+	} {
+		_, err := ParseTriageRules([]byte(content))
+		assert.True(t, IsErrInvalidTriageRules(err), content)
+	}
+}
+
+func TestTriageRuleMatch(t *testing.T) {
+	cfg, err := ParseTriageRules([]byte(`
+rules:
+  - on: [issues]
+    title: "(?i)crash"
+    labels: [bug]
+  - paths: ["docs/**"]
+    author_association: [first_time_contributor, none]
+    labels: [docs]
+`))
+	assert.NoError(t, err)
+	bugs, docs := cfg.Rules[0], cfg.Rules[1]
+
+	issue := &models.Issue{Title: "App Crashes on start"}
+	assert.True(t, bugs.Match(issue, nil, []string{AuthorAssociationOwner}))
+	assert.False(t, docs.Match(issue, nil, []string{AuthorAssociationNone}))
+
+	pull := &models.Issue{Title: "Fix crash in docs", IsPull: true}
+	assert.False(t, bugs.Match(pull, nil, nil))
+	assert.True(t, docs.Match(pull, []string{"docs/usage/install.md", ""}, []string{AuthorAssociationNone}))
+	assert.False(t, docs.Match(pull, []string{"docs/usage/install.md"}, []string{AuthorAssociationCollaborator}))
+	assert.False(t, docs.Match(pull, []string{"README.md", ""}, []string{AuthorAssociationNone}))
+}
+
+func TestTriageActions(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	issue := unittest.AssertExistsAndLoadBean(t, &models.Issue{ID: 1}).(*models.Issue)
+	assert.NoError(t, issue.LoadRepo(db.DefaultContext))
+	assert.NoError(t, issue.LoadPoster())
+	assert.NoError(t, issue.Repo.GetOwner(db.DefaultContext))
+
+	associations, err := getAuthorAssociations(db.DefaultContext, issue)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{AuthorAssociationNone}, associations)
+
+	assert.NoError(t, addTriageLabels(db.DefaultContext, issue, []string{"label2", "missing"}))
+	unittest.AssertExistsAndLoadBean(t, &models.IssueLabel{IssueID: 1, LabelID: 2})
+
+	assert.NoError(t, addTriageAssignees(db.DefaultContext, issue, []string{"user2", "missing"}))
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	isAssigned, err := models.IsUserAssignedToIssue(db.DefaultContext, issue, user2)
+	assert.NoError(t, err)
+	assert.True(t, isAssigned)
+}
@@ -154,6 +154,9 @@ func PostLockHandler(ctx *context.Context) {
 		})
 		return
 	}
+	if rejectedByMaintenanceMode(ctx) {
+		return
+	}
 
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
 
@@ -287,6 +290,9 @@ func UnLockHandler(ctx *context.Context) {
 		})
 		return
 	}
+	if rejectedByMaintenanceMode(ctx) {
+		return
+	}
 
 	ctx.Resp.Header().Set("Content-Type", lfs_module.MediaType)
 
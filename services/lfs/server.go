@@ -30,6 +30,8 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/storage"
+	auth_service "code.gitea.io/gitea/services/auth"
+	"code.gitea.io/gitea/services/maintenance"
 
 	"github.com/golang-jwt/jwt/v4"
 )
@@ -421,9 +423,27 @@ func getAuthenticatedRepository(ctx *context.Context, rc *requestContext, requir
 		return nil
 	}
 
+	if requireWrite && rejectedByMaintenanceMode(ctx) {
+		return nil
+	}
+
 	return repository
 }
 
+// rejectedByMaintenanceMode writes an error and returns true if the instance is in maintenance mode
+// and the authenticated user isn't a site admin, who are the only ones able to upload objects and change locks
+func rejectedByMaintenanceMode(ctx *context.Context) bool {
+	if ctx.Doer != nil && ctx.Doer.IsAdmin {
+		return false
+	}
+	mode := maintenance.Get(ctx)
+	if mode == nil {
+		return false
+	}
+	writeStatusMessage(ctx, http.StatusServiceUnavailable, maintenance.Message(mode))
+	return true
+}
+
 func buildObjectResponse(rc *requestContext, pointer lfs_module.Pointer, download, upload bool, err *lfs_module.ObjectError) *lfs_module.ObjectResponse {
 	rep := &lfs_module.ObjectResponse{Pointer: pointer}
 	if err != nil {
@@ -500,6 +520,14 @@ func authenticate(ctx *context.Context, repository *repo_model.Repository, autho
 		return true
 	}
 
+	// deploy tokens grant read access to the LFS objects of their repository without signing in a user
+	if !requireSigned && !requireWrite {
+		if token := auth_service.DeployTokenFromRequest(ctx.Req, ctx.Data); token != nil && token.RepoID == repository.ID &&
+			token.HasScope(repo_model.DeployTokenScopeReadLFS) && repository.UnitEnabledCtx(ctx, unit.TypeCode) {
+			return true
+		}
+	}
+
 	user, err := parseToken(authorization, repository, accessMode)
 	if err != nil {
 		// Most of these are Warn level - the true internal server errors are logged in parseToken already
@@ -37,12 +37,21 @@ import (
 const (
 	mailAuthActivate       base.TplName = "auth/activate"
 	mailAuthActivateEmail  base.TplName = "auth/activate_email"
+	mailAuthActivateBackup base.TplName = "auth/activate_backup_email"
 	mailAuthResetPassword  base.TplName = "auth/reset_passwd"
 	mailAuthRegisterNotify base.TplName = "auth/register_notify"
 
 	mailNotifyCollaborator base.TplName = "notify/collaborator"
 
-	mailRepoTransferNotify base.TplName = "notify/repo_transfer"
+	mailRepoTransferNotify    base.TplName = "notify/repo_transfer"
+	mailRepoTransferScheduled base.TplName = "notify/repo_transfer_scheduled"
+
+	mailNotifyAccessExpired base.TplName = "notify/access_expired"
+
+	mailNotifyAccessRequest         base.TplName = "notify/access_request"
+	mailNotifyAccessRequestDecision base.TplName = "notify/access_request_decision"
+
+	mailNotifySecurityEvent base.TplName = "notify/security_event"
 
 	// There's no actual limit for subject in RFC 5322
 	mailMaxSubjectRunes = 256
@@ -71,6 +80,11 @@ func SendTestMail(email string) error {
 
 // sendUserMail sends a mail to the user
 func sendUserMail(language string, u *user_model.User, tpl base.TplName, code, subject, info string) {
+	sendUserMailTo(u.Email, language, u, tpl, code, subject, info)
+}
+
+// sendUserMailTo sends a mail about the account of the user to another address than the user's e-mail address
+func sendUserMailTo(to, language string, u *user_model.User, tpl base.TplName, code, subject, info string) {
 	locale := translation.NewLocale(language)
 	data := map[string]interface{}{
 		"DisplayName":       u.DisplayName(),
@@ -91,19 +105,20 @@ func sendUserMail(language string, u *user_model.User, tpl base.TplName, code, s
 		return
 	}
 
-	msg := NewMessage([]string{u.Email}, subject, content.String())
+	msg := NewMessage([]string{to}, subject, content.String())
 	msg.Info = fmt.Sprintf("UID: %d, %s", u.ID, info)
 
 	SendAsync(msg)
 }
 
 // SendActivateAccountMail sends an activation mail to the user (new user registration)
-func SendActivateAccountMail(locale translation.Locale, u *user_model.User) {
+func SendActivateAccountMail(u *user_model.User) {
 	if setting.MailService == nil {
 		// No mail service configured
 		return
 	}
-	sendUserMail(locale.Language(), u, mailAuthActivate, u.GenerateEmailActivateCode(u.Email), locale.Tr("mail.activate_account"), "activate account")
+	locale := translation.NewLocale(u.Language)
+	sendUserMail(u.Language, u, mailAuthActivate, u.GenerateEmailActivateCode(u.Email), locale.Tr("mail.activate_account"), "activate account")
 }
 
 // SendResetPasswordMail sends a password reset mail to the user
@@ -116,6 +131,16 @@ func SendResetPasswordMail(u *user_model.User) {
 	sendUserMail(u.Language, u, mailAuthResetPassword, u.GenerateEmailActivateCode(u.Email), locale.Tr("mail.reset_password"), "recover account")
 }
 
+// SendBackupEmailResetPasswordMail sends a password reset mail to the backup e-mail address of the user
+func SendBackupEmailResetPasswordMail(u *user_model.User, email string) {
+	if setting.MailService == nil {
+		// No mail service configured
+		return
+	}
+	locale := translation.NewLocale(u.Language)
+	sendUserMailTo(email, u.Language, u, mailAuthResetPassword, u.GenerateEmailActivateCode(u.Email), locale.Tr("mail.reset_password"), "recover account with backup email")
+}
+
 // SendActivateEmailMail sends confirmation email to confirm new email address
 func SendActivateEmailMail(u *user_model.User, email *user_model.EmailAddress) {
 	if setting.MailService == nil {
@@ -148,6 +173,38 @@ func SendActivateEmailMail(u *user_model.User, email *user_model.EmailAddress) {
 	SendAsync(msg)
 }
 
+// SendActivateBackupEmailMail sends a mail to confirm the new backup e-mail address of the user
+func SendActivateBackupEmailMail(u *user_model.User, email *user_model.BackupEmail) {
+	if setting.MailService == nil {
+		// No mail service configured
+		return
+	}
+	locale := translation.NewLocale(u.Language)
+	data := map[string]interface{}{
+		"DisplayName":     u.DisplayName(),
+		"ActiveCodeLives": timeutil.MinutesToFriendly(setting.Service.ActiveCodeLives, locale.Language()),
+		"Code":            u.GenerateBackupEmailActivateCode(email.Email),
+		"Email":           email.Email,
+		"Language":        locale.Language(),
+		// helper
+		"i18n":      locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	var content bytes.Buffer
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailAuthActivateBackup), data); err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := NewMessage([]string{email.Email}, locale.Tr("mail.activate_backup_email"), content.String())
+	msg.Info = fmt.Sprintf("UID: %d, activate backup email", u.ID)
+
+	SendAsync(msg)
+}
+
 // SendRegisterNotifyMail triggers a notify e-mail by admin created a account.
 func SendRegisterNotifyMail(u *user_model.User) {
 	if setting.MailService == nil || !u.IsActive {
@@ -213,6 +270,44 @@ func SendCollaboratorMail(u, doer *user_model.User, repo *repo_model.Repository)
 	SendAsync(msg)
 }
 
+// SendSecurityEventMail notifies the user about an event of the security log of their account
+func SendSecurityEventMail(u *user_model.User, event *user_model.SecurityEvent) {
+	if setting.MailService == nil || !setting.Service.EnableSecurityNotifyMail || !u.IsActive {
+		// No mail service configured, the notifications are disabled OR the user is inactive
+		return
+	}
+	locale := translation.NewLocale(u.Language)
+
+	subject := locale.Tr("mail.security_event." + string(event.Type) + ".subject")
+	data := map[string]interface{}{
+		"Subject":     subject,
+		"DisplayName": u.DisplayName(),
+		"Type":        string(event.Type),
+		"Detail":      event.Detail,
+		"IP":          event.IP,
+		"UserAgent":   event.UserAgent,
+		"Time":        event.CreatedUnix.FormatLong(),
+		"Link":        setting.AppURL + "user/settings/security",
+		"Language":    locale.Language(),
+		// helper
+		"i18n":      locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	var content bytes.Buffer
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifySecurityEvent), data); err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := NewMessage([]string{u.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, security event %s", u.ID, event.Type)
+
+	SendAsync(msg)
+}
+
 func composeIssueCommentMessages(ctx *mailCommentContext, lang string, recipients []*user_model.User, fromMention bool, info string) ([]*Message, error) {
 	var (
 		subject string
@@ -8,10 +8,12 @@ import (
 	"bytes"
 	"fmt"
 
+	"code.gitea.io/gitea/models"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/setting"
 	"code.gitea.io/gitea/modules/templates"
 	"code.gitea.io/gitea/modules/translation"
@@ -89,3 +91,210 @@ func sendRepoTransferNotifyMailPerLang(lang string, newOwner, doer *user_model.U
 	SendAsync(msg)
 	return nil
 }
+
+// SendRepoTransferScheduledMail notifies the watchers of the repository when it is going to be transferred
+func SendRepoTransferScheduledMail(doer *user_model.User, repo *repo_model.Repository, transfer *models.RepoTransfer) {
+	if setting.MailService == nil {
+		// No mail service configured
+		return
+	}
+
+	watcherIDs, err := repo_model.GetRepoWatchersIDs(db.DefaultContext, repo.ID)
+	if err != nil {
+		log.Error("GetRepoWatchersIDs(%d): %v", repo.ID, err)
+		return
+	}
+	recipients, err := user_model.GetMaileableUsersByIDs(watcherIDs, false)
+	if err != nil {
+		log.Error("GetMaileableUsersByIDs: %v", err)
+		return
+	}
+
+	langMap := make(map[string][]string)
+	for _, user := range recipients {
+		if user.ID != doer.ID {
+			langMap[user.Language] = append(langMap[user.Language], user.Email)
+		}
+	}
+
+	for lang, tos := range langMap {
+		if err := sendRepoTransferScheduledMailPerLang(lang, tos, repo, transfer); err != nil {
+			log.Error("sendRepoTransferScheduledMailPerLang: %v", err)
+		}
+	}
+}
+
+func sendRepoTransferScheduledMailPerLang(lang string, emails []string, repo *repo_model.Repository, transfer *models.RepoTransfer) error {
+	var (
+		locale  = translation.NewLocale(lang)
+		content bytes.Buffer
+	)
+
+	subject := locale.Tr("mail.repo.transfer_scheduled.subject", repo.FullName(), transfer.Recipient.Name)
+	data := map[string]interface{}{
+		"Subject":      subject,
+		"RepoName":     repo.FullName(),
+		"NewOwner":     transfer.Recipient.Name,
+		"ScheduledAt":  transfer.ScheduledUnix.FormatLong(),
+		"RedirectDays": transfer.RedirectDays,
+		"Link":         repo.HTMLURL(),
+		"Language":     locale.Language(),
+		// helper
+		"i18n":      locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailRepoTransferScheduled), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage(emails, subject, content.String())
+	msg.Info = fmt.Sprintf("Repo: %d, scheduled transfer to %s", repo.ID, transfer.Recipient.Name)
+
+	SendAsync(msg)
+	return nil
+}
+
+// SendAccessExpiredMail notifies the recipients that the temporary access of the grantee, a user or a team, to the repository was revoked
+func SendAccessExpiredMail(recipients []*user_model.User, repo *repo_model.Repository, grantee string) {
+	if setting.MailService == nil {
+		// No mail service configured
+		return
+	}
+
+	langMap := make(map[string][]string)
+	for _, user := range recipients {
+		if !user.IsActive {
+			// don't send emails to inactive users
+			continue
+		}
+		langMap[user.Language] = append(langMap[user.Language], user.Email)
+	}
+
+	for lang, tos := range langMap {
+		if err := sendAccessExpiredMailPerLang(lang, tos, repo, grantee); err != nil {
+			log.Error("sendAccessExpiredMailPerLang: %v", err)
+		}
+	}
+}
+
+func sendAccessExpiredMailPerLang(lang string, emails []string, repo *repo_model.Repository, grantee string) error {
+	var (
+		locale  = translation.NewLocale(lang)
+		content bytes.Buffer
+	)
+
+	subject := locale.Tr("mail.repo.access_expired.subject", grantee, repo.FullName())
+	data := map[string]interface{}{
+		"Subject":  subject,
+		"Grantee":  grantee,
+		"RepoName": repo.FullName(),
+		"Link":     repo.HTMLURL(),
+		"Language": locale.Language(),
+		// helper
+		"i18n":      locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyAccessExpired), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage(emails, subject, content.String())
+	msg.Info = fmt.Sprintf("Repo: %d, temporary access of %s expired", repo.ID, grantee)
+
+	SendAsync(msg)
+	return nil
+}
+
+// SendAccessRequestMail notifies the administrators of a repository about a new access request
+func SendAccessRequestMail(admins []*user_model.User, requester *user_model.User, repo *repo_model.Repository, message string) {
+	if setting.MailService == nil {
+		// No mail service configured
+		return
+	}
+
+	langMap := make(map[string][]string)
+	for _, user := range admins {
+		if !user.IsActive {
+			// don't send emails to inactive users
+			continue
+		}
+		langMap[user.Language] = append(langMap[user.Language], user.Email)
+	}
+
+	for lang, tos := range langMap {
+		if err := sendAccessRequestMailPerLang(lang, tos, requester, repo, message); err != nil {
+			log.Error("sendAccessRequestMailPerLang: %v", err)
+		}
+	}
+}
+
+func sendAccessRequestMailPerLang(lang string, emails []string, requester *user_model.User, repo *repo_model.Repository, message string) error {
+	var (
+		locale  = translation.NewLocale(lang)
+		content bytes.Buffer
+	)
+
+	subject := locale.Tr("mail.repo.access_request.subject", requester.Name, repo.FullName())
+	data := map[string]interface{}{
+		"Subject":   subject,
+		"Requester": requester.Name,
+		"Message":   message,
+		"RepoName":  repo.FullName(),
+		"Link":      repo.HTMLURL() + "/settings/access_requests",
+		"Language":  locale.Language(),
+		// helper
+		"i18n":      locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyAccessRequest), data); err != nil {
+		return err
+	}
+
+	msg := NewMessage(emails, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, access request for repo %d", requester.ID, repo.ID)
+
+	SendAsync(msg)
+	return nil
+}
+
+// SendAccessRequestDecisionMail notifies the requester that the access request was approved or denied
+func SendAccessRequestDecisionMail(requester *user_model.User, repo *repo_model.Repository, approved bool) {
+	if setting.MailService == nil || !requester.IsActive {
+		// No mail service configured OR the user is inactive
+		return
+	}
+	locale := translation.NewLocale(requester.Language)
+
+	subject := locale.Tr("mail.repo.access_request.denied.subject", repo.FullName())
+	if approved {
+		subject = locale.Tr("mail.repo.access_request.approved.subject", repo.FullName())
+	}
+	data := map[string]interface{}{
+		"Subject":  subject,
+		"Approved": approved,
+		"RepoName": repo.FullName(),
+		"Link":     repo.HTMLURL(),
+		"Language": locale.Language(),
+		// helper
+		"i18n":      locale,
+		"Str2html":  templates.Str2html,
+		"DotEscape": templates.DotEscape,
+	}
+
+	var content bytes.Buffer
+	if err := bodyTemplates.ExecuteTemplate(&content, string(mailNotifyAccessRequestDecision), data); err != nil {
+		log.Error("Template: %v", err)
+		return
+	}
+
+	msg := NewMessage([]string{requester.Email}, subject, content.String())
+	msg.Info = fmt.Sprintf("UID: %d, access request decision for repo %d", requester.ID, repo.ID)
+
+	SendAsync(msg)
+}
@@ -123,6 +123,24 @@ func TestComposeIssueMessage(t *testing.T) {
 	assert.Equal(t, "<user2/repo1/issues/1@localhost>", messageID[0], "Message-ID header doesn't match")
 }
 
+func TestComposeIssueCommentMessageLanguage(t *testing.T) {
+	doer, _, issue, comment := prepareMailerTest(t)
+
+	stpl := texttmpl.Must(texttmpl.New("issue/comment").Parse(subjectTpl))
+	btpl := template.Must(template.New("issue/comment").Parse(`<html lang="{{.Language}}"><body>{{.i18n.Language}}</body></html>`))
+	InitMailRender(stpl, btpl)
+
+	recipients := []*user_model.User{{Name: "Test", Email: "test@gitea.com", Language: "de-DE"}}
+	msgs, err := composeIssueCommentMessages(&mailCommentContext{
+		Context: context.TODO(), // TODO: use a correct context
+		Issue:   issue, Doer: doer, ActionType: models.ActionCommentIssue,
+		Content: "test body", Comment: comment,
+	}, "de-DE", recipients, false, "issue comment")
+	assert.NoError(t, err)
+	assert.Len(t, msgs, 1)
+	assert.Equal(t, `<html lang="de-DE"><body>de-DE</body></html>`, msgs[0].Body)
+}
+
 func TestTemplateSelection(t *testing.T) {
 	doer, repo, issue, comment := prepareMailerTest(t)
 	recipients := []*user_model.User{{Name: "Test", Email: "test@gitea.com"}}
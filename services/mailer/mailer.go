@@ -140,16 +140,14 @@ func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
 	return nil, nil
 }
 
-// Sender SMTP mail sender
-type smtpSender struct{}
-
-// Send send email
-func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
+// dialSMTP connects to the SMTP server, says hello, upgrades the connection to TLS if possible
+// and authenticates, the connection does not time out if timeout is 0
+func dialSMTP(timeout time.Duration) (*smtp.Client, error) {
 	opts := setting.MailService
 
 	host, port, err := net.SplitHostPort(opts.Host)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	tlsconfig := &tls.Config{
@@ -160,16 +158,15 @@ func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
 	if opts.UseCertificate {
 		cert, err := tls.LoadX509KeyPair(opts.CertFile, opts.KeyFile)
 		if err != nil {
-			return err
+			return nil, err
 		}
 		tlsconfig.Certificates = []tls.Certificate{cert}
 	}
 
-	conn, err := net.Dial("tcp", net.JoinHostPort(host, port))
+	conn, err := net.DialTimeout("tcp", net.JoinHostPort(host, port), timeout)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	defer conn.Close()
 
 	isSecureConn := opts.IsTLSEnabled || (strings.HasSuffix(port, "465"))
 	// Start TLS directly if the port ends with 465 (SMTPS protocol)
@@ -179,9 +176,21 @@ func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
 
 	client, err := smtp.NewClient(conn, host)
 	if err != nil {
-		return fmt.Errorf("NewClient: %v", err)
+		_ = conn.Close()
+		return nil, fmt.Errorf("NewClient: %v", err)
 	}
 
+	if err := helloSMTP(client, host, isSecureConn, tlsconfig); err != nil {
+		_ = client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+// helloSMTP greets the SMTP server, uses STARTTLS if available and authenticates
+func helloSMTP(client *smtp.Client, host string, isSecureConn bool, tlsconfig *tls.Config) (err error) {
+	opts := setting.MailService
+
 	if !opts.DisableHelo {
 		hostname := opts.HeloHostname
 		if len(hostname) == 0 {
@@ -223,6 +232,21 @@ func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
 			}
 		}
 	}
+	return nil
+}
+
+// Sender SMTP mail sender
+type smtpSender struct{}
+
+// Send send email
+func (s *smtpSender) Send(from string, to []string, msg io.WriterTo) error {
+	opts := setting.MailService
+
+	client, err := dialSMTP(0)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
 
 	if opts.OverrideEnvelopeFrom {
 		if err = client.Mail(opts.EnvelopeFrom); err != nil {
@@ -323,6 +347,24 @@ func (s *dummySender) Send(from string, to []string, msg io.WriterTo) error {
 	return nil
 }
 
+// CheckConnection checks that mails can be handed over to the configured mailer,
+// without sending any
+func CheckConnection(timeout time.Duration) error {
+	switch setting.MailService.MailerType {
+	case "smtp":
+		client, err := dialSMTP(timeout)
+		if err != nil {
+			return err
+		}
+		defer client.Close()
+		return client.Quit()
+	case "sendmail":
+		_, err := exec.LookPath(setting.MailService.SendmailPath)
+		return err
+	}
+	return nil
+}
+
 var mailQueue queue.Queue
 
 // Sender sender for sending mail synchronously
@@ -0,0 +1,125 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maintenance
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	admin_model "code.gitea.io/gitea/models/admin"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// refreshInterval is how long the maintenance mode is cached, instances sharing the database
+// follow when another instance enables or disables the maintenance mode after at most this duration
+const refreshInterval = 10 * time.Second
+
+// signInPaths are the paths of the pages signing users in and out, which are needed
+// by site admins to sign in while the instance is in maintenance mode
+var signInPaths = []string{"/user/login", "/user/logout", "/user/two_factor", "/user/webauthn", "/login/oauth/access_token"}
+
+// stateChangingGetPaths are the paths of the pages which change something although they are GET requests,
+// e.g. the links sent by mail to activate accounts and the callbacks which can register users
+var stateChangingGetPaths = []string{"/user/activate", "/user/activate_email", "/user/activate_backup_email", "/user/oauth2"}
+
+// matchesPath returns whether the path of the request is one of the paths or below it, the paths are relative to the sub URL
+func matchesPath(req *http.Request, paths []string) bool {
+	for _, path := range paths {
+		path = setting.AppSubURL + path
+		if req.URL.Path == path || strings.HasPrefix(req.URL.Path, path+"/") {
+			return true
+		}
+	}
+	return false
+}
+
+var (
+	lock     sync.RWMutex
+	current  *admin_model.MaintenanceMode
+	loadedAt time.Time
+)
+
+// Get returns the maintenance mode of the instance or nil if it isn't in maintenance mode
+func Get(ctx context.Context) *admin_model.MaintenanceMode {
+	lock.RLock()
+	mode, loaded := current, loadedAt
+	lock.RUnlock()
+	if time.Since(loaded) < refreshInterval {
+		return mode
+	}
+
+	loadedMode, err := admin_model.GetMaintenanceMode(ctx)
+	if err != nil {
+		// keep the previous state rather than letting all requests fail
+		log.Error("GetMaintenanceMode: %v", err)
+		return mode
+	}
+	lock.Lock()
+	current, loadedAt = loadedMode, time.Now()
+	lock.Unlock()
+	return loadedMode
+}
+
+// Enable puts the instance into maintenance mode, only site admins can change anything until it is disabled
+func Enable(ctx context.Context, doer *user_model.User, message string) error {
+	mode := &admin_model.MaintenanceMode{Message: strings.TrimSpace(message), DoerID: doer.ID}
+	if err := admin_model.EnableMaintenanceMode(ctx, mode); err != nil {
+		return err
+	}
+	lock.Lock()
+	current, loadedAt = mode, time.Now()
+	lock.Unlock()
+	return nil
+}
+
+// Disable ends the maintenance mode of the instance
+func Disable(ctx context.Context) error {
+	if err := admin_model.DisableMaintenanceMode(ctx); err != nil {
+		return err
+	}
+	lock.Lock()
+	current, loadedAt = nil, time.Now()
+	lock.Unlock()
+	return nil
+}
+
+// IsAllowed returns whether the request may be handled while the instance is in maintenance mode:
+// site admins can do everything, other users can only read
+func IsAllowed(req *http.Request, doer *user_model.User) bool {
+	if doer != nil && doer.IsAdmin {
+		return true
+	}
+	return IsReadOnlyRequest(req) || matchesPath(req, signInPaths)
+}
+
+// IsReadOnlyRequest returns whether the request doesn't change anything.
+// Fetches and clones over HTTP are POST requests but only read, while asking for
+// the references to push to is a GET request which is the first step of a push.
+func IsReadOnlyRequest(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions:
+		if matchesPath(req, stateChangingGetPaths) {
+			return false
+		}
+		return !strings.HasSuffix(req.URL.Path, "/info/refs") || req.URL.Query().Get("service") != "git-receive-pack"
+	case http.MethodPost:
+		return strings.HasSuffix(req.URL.Path, "/git-upload-pack")
+	}
+	return false
+}
+
+// Message returns the message shown to users whose changes are rejected
+func Message(mode *admin_model.MaintenanceMode) string {
+	msg := "This instance is in maintenance mode, changes are not possible for the moment."
+	if mode.Message != "" {
+		msg += " " + mode.Message
+	}
+	return msg
+}
@@ -0,0 +1,80 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package maintenance
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMaintenanceMode(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+	assert.Nil(t, Get(db.DefaultContext))
+
+	assert.NoError(t, Enable(db.DefaultContext, admin, " Upgrading to the new version "))
+	mode := Get(db.DefaultContext)
+	if assert.NotNil(t, mode) {
+		assert.Equal(t, "Upgrading to the new version", mode.Message)
+		assert.Equal(t, "This instance is in maintenance mode, changes are not possible for the moment. Upgrading to the new version", Message(mode))
+	}
+
+	assert.NoError(t, Disable(db.DefaultContext))
+	assert.Nil(t, Get(db.DefaultContext))
+}
+
+func TestIsAllowed(t *testing.T) {
+	admin := &user_model.User{ID: 1, IsAdmin: true}
+	user := &user_model.User{ID: 2}
+
+	for _, c := range []struct {
+		method, target string
+		allowed        bool
+	}{
+		{"GET", "/user2/repo1", true},
+		{"HEAD", "/user2/repo1/raw/branch/master/README.md", true},
+		{"POST", "/user2/repo1/issues/new", false},
+		{"DELETE", "/api/v1/repos/user2/repo1", false},
+		{"PATCH", "/api/v1/user/settings", false},
+		{"GET", "/user2/repo1.git/info/refs?service=git-upload-pack", true},
+		{"POST", "/user2/repo1.git/git-upload-pack", true},
+		{"GET", "/user2/repo1.git/info/refs?service=git-receive-pack", false},
+		{"POST", "/user2/repo1.git/git-receive-pack", false},
+		{"POST", "/user/login", true},
+		{"POST", "/user/webauthn/assertion", true},
+		{"POST", "/user/login_source", false},
+		{"POST", "/user/sign_up", false},
+		{"GET", "/user/activate?code=abc", false},
+		{"GET", "/user/activate_email?code=abc&email=user2%40example.com", false},
+		{"GET", "/user/oauth2/github/callback?code=abc", false},
+	} {
+		req := httptest.NewRequest(c.method, c.target, nil)
+		assert.Equal(t, c.allowed, IsAllowed(req, user), "%s %s", c.method, c.target)
+		assert.Equal(t, c.allowed, IsAllowed(req, nil), "%s %s", c.method, c.target)
+		assert.True(t, IsAllowed(req, admin), "%s %s", c.method, c.target)
+	}
+}
+
+func TestIsAllowedSubURL(t *testing.T) {
+	oldAppSubURL := setting.AppSubURL
+	setting.AppSubURL = "/gitea"
+	defer func() {
+		setting.AppSubURL = oldAppSubURL
+	}()
+
+	user := &user_model.User{ID: 2}
+	assert.True(t, IsAllowed(httptest.NewRequest("POST", "/gitea/user/login", nil), user))
+	assert.False(t, IsAllowed(httptest.NewRequest("POST", "/user/login", nil), user))
+	assert.False(t, IsAllowed(httptest.NewRequest("GET", "/gitea/user/activate?code=abc", nil), user))
+	assert.True(t, IsAllowed(httptest.NewRequest("GET", "/gitea/user2/repo1", nil), user))
+}
@@ -513,8 +513,8 @@ func SyncPullMirror(ctx context.Context, repoID int64) bool {
 		return false
 	}
 
-	if err = repo_model.UpdateRepositoryUpdatedTime(m.RepoID, commitDate); err != nil {
-		log.Error("SyncMirrors [repo: %-v]: unable to update repository 'updated_unix': %v", m.Repo, err)
+	if err = repo_model.UpdateRepositoryPushedTime(m.RepoID, commitDate); err != nil {
+		log.Error("SyncMirrors [repo: %-v]: unable to update repository 'updated_unix' and 'pushed_unix': %v", m.Repo, err)
 		return false
 	}
 
@@ -5,16 +5,23 @@
 package org
 
 import (
+	"context"
 	"fmt"
 
 	"code.gitea.io/gitea/models"
+	actions_model "code.gitea.io/gitea/models/actions"
+	admin_model "code.gitea.io/gitea/models/admin"
 	"code.gitea.io/gitea/models/db"
 	"code.gitea.io/gitea/models/organization"
 	packages_model "code.gitea.io/gitea/models/packages"
 	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
+	emoji_service "code.gitea.io/gitea/services/emoji"
+	packages_service "code.gitea.io/gitea/services/packages"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // DeleteOrganization completely and permanently deletes everything of organization.
@@ -40,6 +47,34 @@ func DeleteOrganization(org *organization.Organization) error {
 		return models.ErrUserOwnPackages{UID: org.ID}
 	}
 
+	if err := packages_model.DeletePolicyByOwnerID(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeletePolicyByOwnerID: %v", err)
+	}
+
+	if err := user_model.DeleteQuotaByOwnerID(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteQuotaByOwnerID: %v", err)
+	}
+
+	if err := db.DeleteBeans(ctx, &models.OrgProtectedBranch{OrgID: org.ID}); err != nil {
+		return fmt.Errorf("DeleteBeans: %v", err)
+	}
+
+	if err := db.DeleteBeans(ctx, &models.IssueIntakeRule{OrgID: org.ID}); err != nil {
+		return fmt.Errorf("DeleteBeans: %v", err)
+	}
+
+	if err := secret_model.DeleteScope(ctx, secret_model.OrgScope(org.ID)); err != nil {
+		return fmt.Errorf("DeleteScope: %v", err)
+	}
+
+	if err := admin_model.DeleteAnnouncementsByOrgID(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteAnnouncementsByOrgID: %v", err)
+	}
+
+	if err := actions_model.DeleteOwnerRunners(ctx, org.ID); err != nil {
+		return fmt.Errorf("DeleteOwnerRunners: %v", err)
+	}
+
 	if err := organization.DeleteOrganization(ctx, org); err != nil {
 		return fmt.Errorf("DeleteOrganization: %v", err)
 	}
@@ -64,5 +99,22 @@ func DeleteOrganization(org *organization.Organization) error {
 		}
 	}
 
+	if err := emoji_service.DeleteOwnerCustomEmojis(db.DefaultContext, org.ID); err != nil {
+		return fmt.Errorf("DeleteOwnerCustomEmojis: %v", err)
+	}
+
 	return nil
 }
+
+// PurgeOrganization deletes an organization together with all its repositories and packages.
+func PurgeOrganization(ctx context.Context, doer *user_model.User, org *organization.Organization) error {
+	if err := repo_service.DeleteOwnerRepositories(ctx, doer, org.AsUser()); err != nil {
+		return err
+	}
+
+	if err := packages_service.RemoveAllPackagesOfOwner(doer, org.AsUser()); err != nil {
+		return fmt.Errorf("RemoveAllPackagesOfOwner: %v", err)
+	}
+
+	return DeleteOrganization(org)
+}
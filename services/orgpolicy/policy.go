@@ -0,0 +1,145 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package orgpolicy
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	unit_model "code.gitea.io/gitea/models/unit"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"gopkg.in/yaml.v2"
+)
+
+// ErrInvalidPolicy represents a "InvalidPolicy" kind of error.
+type ErrInvalidPolicy struct {
+	Reason string
+}
+
+// IsErrInvalidPolicy checks if an error is a ErrInvalidPolicy.
+func IsErrInvalidPolicy(err error) bool {
+	_, ok := err.(ErrInvalidPolicy)
+	return ok
+}
+
+func (err ErrInvalidPolicy) Error() string {
+	return fmt.Sprintf("invalid organization policy: %s", err.Reason)
+}
+
+// Parse parses and validates a policy in YAML (or JSON) format
+func Parse(content []byte) (*api.OrgPolicy, error) {
+	policy := new(api.OrgPolicy)
+	if err := yaml.UnmarshalStrict(content, policy); err != nil {
+		return nil, ErrInvalidPolicy{Reason: err.Error()}
+	}
+	if err := Validate(policy); err != nil {
+		return nil, err
+	}
+	return policy, nil
+}
+
+// Validate validates and normalizes a policy
+func Validate(policy *api.OrgPolicy) error {
+	if policy.Settings != nil && policy.Settings.Visibility != nil {
+		if _, ok := api.VisibilityModes[*policy.Settings.Visibility]; !ok {
+			return ErrInvalidPolicy{Reason: fmt.Sprintf("unknown visibility %q", *policy.Settings.Visibility)}
+		}
+	}
+
+	teams := make(map[string]bool, len(policy.Teams))
+	for _, team := range policy.Teams {
+		team.Name = strings.TrimSpace(team.Name)
+		if team.Name == "" {
+			return ErrInvalidPolicy{Reason: "team without name"}
+		} else if err := organization.IsUsableTeamName(team.Name); err != nil {
+			return ErrInvalidPolicy{Reason: fmt.Sprintf("unusable team name %q", team.Name)}
+		} else if teams[strings.ToLower(team.Name)] {
+			return ErrInvalidPolicy{Reason: fmt.Sprintf("duplicate team %q", team.Name)}
+		}
+		teams[strings.ToLower(team.Name)] = true
+
+		if isOwnerTeam(team) {
+			team.Permission = ""
+			team.Units = nil
+			continue
+		}
+
+		switch team.Permission {
+		case "read", "write":
+			if len(team.Units) == 0 {
+				return ErrInvalidPolicy{Reason: fmt.Sprintf("team %q without units", team.Name)}
+			}
+			for _, tp := range unit_model.FindUnitTypes(team.Units...) {
+				if tp == unit_model.TypeInvalid {
+					return ErrInvalidPolicy{Reason: fmt.Sprintf("unknown units %v of team %q", team.Units, team.Name)}
+				}
+			}
+		case "admin":
+			// administrators have access to all units
+			team.Units = nil
+		default:
+			return ErrInvalidPolicy{Reason: fmt.Sprintf("unknown permission %q of team %q", team.Permission, team.Name)}
+		}
+	}
+	return nil
+}
+
+// Export returns the policy describing the current settings of an organization
+func Export(ctx context.Context, org *organization.Organization) (*api.OrgPolicy, error) {
+	visibility := org.Visibility.String()
+	repoAdminChangeTeamAccess := org.RepoAdminChangeTeamAccess
+	policy := &api.OrgPolicy{
+		Settings: &api.OrgPolicySettings{
+			FullName:                  &org.FullName,
+			Description:               &org.Description,
+			Website:                   &org.Website,
+			Location:                  &org.Location,
+			Visibility:                &visibility,
+			RepoAdminChangeTeamAccess: &repoAdminChangeTeamAccess,
+		},
+	}
+
+	teams, err := organization.FindOrgTeams(ctx, org.ID)
+	if err != nil {
+		return nil, err
+	}
+	policy.Teams = make([]*api.OrgPolicyTeam, 0, len(teams))
+	for _, team := range teams {
+		if err := team.GetMembersCtx(ctx); err != nil {
+			return nil, err
+		}
+		members := make([]string, 0, len(team.Members))
+		for _, member := range team.Members {
+			members = append(members, member.Name)
+		}
+
+		declared := &api.OrgPolicyTeam{
+			Name:                    team.Name,
+			Description:             team.Description,
+			IncludesAllRepositories: team.IncludesAllRepositories,
+			CanCreateOrgRepo:        team.CanCreateOrgRepo,
+			Members:                 members,
+		}
+		if !team.IsOwnerTeam() {
+			declared.Permission = team.AccessMode.String()
+			if team.AccessMode < perm.AccessModeAdmin {
+				if err := team.GetUnits(); err != nil {
+					return nil, err
+				}
+				declared.Units = team.GetUnitNames()
+			}
+		}
+		policy.Teams = append(policy.Teams, declared)
+	}
+	return policy, nil
+}
+
+func isOwnerTeam(team *api.OrgPolicyTeam) bool {
+	return strings.EqualFold(team.Name, organization.OwnerTeamName)
+}
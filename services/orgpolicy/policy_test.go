@@ -0,0 +1,80 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package orgpolicy
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v2"
+)
+
+func TestParse(t *testing.T) {
+	policy, err := Parse([]byte(`
+settings:
+  visibility: limited
+require_two_factor: true
+teams:
+  - name: owners
+    permission: read
+    members: [user2]
+  - name: " devs "
+    permission: write
+    units: [repo.code, repo.issues]
+  - name: admins
+    permission: admin
+    units: [repo.code]
+`))
+	assert.NoError(t, err)
+	assert.True(t, policy.RequireTwoFactor)
+	assert.EqualValues(t, "limited", *policy.Settings.Visibility)
+	if assert.Len(t, policy.Teams, 3) {
+		assert.Empty(t, policy.Teams[0].Permission)
+		assert.EqualValues(t, []string{"user2"}, policy.Teams[0].Members)
+		assert.EqualValues(t, "devs", policy.Teams[1].Name)
+		assert.Nil(t, policy.Teams[1].Members)
+		assert.Nil(t, policy.Teams[2].Units)
+	}
+
+	for _, content := range []string{
+		"unknown: true",
+		"settings:\n  visibility: secret",
+		"teams:\n  - permission: read\n    units: [repo.code]",
+		"teams:\n  - name: new\n    permission: read\n    units: [repo.code]\n  - name: New\n    permission: read\n    units: [repo.code]",
+		"teams:\n  - name: devs\n    permission: owner",
+		"teams:\n  - name: devs\n    permission: read",
+		"teams:\n  - name: devs\n    permission: read\n    units: [repo.unknown]",
+	} {
+		_, err = Parse([]byte(content))
+		assert.True(t, IsErrInvalidPolicy(err), content)
+	}
+}
+
+func TestExport(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	org := unittest.AssertExistsAndLoadBean(t, &organization.Organization{ID: 3}).(*organization.Organization)
+	policy, err := Export(db.DefaultContext, org)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "public", *policy.Settings.Visibility)
+	if assert.NotEmpty(t, policy.Teams) {
+		assert.EqualValues(t, "Owners", policy.Teams[0].Name)
+		assert.Empty(t, policy.Teams[0].Permission)
+		assert.EqualValues(t, []string{"user2"}, policy.Teams[0].Members)
+	}
+
+	// the exported policy can be parsed and doesn't drift
+	content, err := yaml.Marshal(policy)
+	assert.NoError(t, err)
+	parsed, err := Parse(content)
+	assert.NoError(t, err)
+	changes, err := Drift(db.DefaultContext, org, parsed)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
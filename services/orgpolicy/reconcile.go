@@ -0,0 +1,437 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package orgpolicy
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/auth"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	unit_model "code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// Section represents the part of the policy a change belongs to
+type Section string
+
+// The sections of a policy
+const (
+	SectionSettings         Section = "settings"
+	SectionRequireTwoFactor Section = "require_two_factor"
+	SectionTeams            Section = "teams"
+	SectionRepoDefaults     Section = "repo_defaults"
+)
+
+// Action represents how a change is applied
+type Action string
+
+// The actions of a change
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+	ActionAdd    Action = "add"
+	ActionRemove Action = "remove"
+	// ActionReport marks drifts which can't be fixed automatically
+	ActionReport Action = "report"
+)
+
+// errNotFixable is recorded in reported changes which are applied
+var errNotFixable = errors.New("can't be fixed automatically")
+
+// Change represents a drift between the policy and the settings of an organization
+type Change struct {
+	Section Section
+	// Name identifies the changed entry: the setting, team name, user name or "repository: option".
+	// Team members are identified as "team: user".
+	Name   string
+	Action Action
+	// Err is set if the change could not be applied
+	Err error
+
+	apply func(ctx context.Context) error
+}
+
+// Drift returns the changes needed to make the settings of the organization match the policy
+func Drift(ctx context.Context, org *organization.Organization, policy *api.OrgPolicy) ([]*Change, error) {
+	changes := settingsDrift(org, policy.Settings)
+
+	for _, drift := range []func(context.Context, *organization.Organization, *api.OrgPolicy) ([]*Change, error){
+		twoFactorDrift,
+		teamsDrift,
+		repoDefaultsDrift,
+	} {
+		sectionChanges, err := drift(ctx, org, policy)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, sectionChanges...)
+	}
+	return changes, nil
+}
+
+// Apply applies the changes, the errors of changes which could not be applied are recorded in them
+func Apply(ctx context.Context, changes []*Change) {
+	for _, change := range changes {
+		if change.Action == ActionReport {
+			change.Err = errNotFixable
+			continue
+		}
+		if err := change.apply(ctx); err != nil {
+			change.Err = err
+		}
+	}
+}
+
+// ToReport converts the changes to their API format
+func ToReport(changes []*Change, applied bool) *api.OrgPolicyReport {
+	report := &api.OrgPolicyReport{
+		Applied: applied,
+		Changes: make([]*api.OrgPolicyChange, 0, len(changes)),
+	}
+	for _, change := range changes {
+		apiChange := &api.OrgPolicyChange{
+			Section: string(change.Section),
+			Name:    change.Name,
+			Action:  string(change.Action),
+		}
+		if change.Err != nil {
+			apiChange.Error = change.Err.Error()
+		}
+		report.Changes = append(report.Changes, apiChange)
+	}
+	return report
+}
+
+func settingsDrift(org *organization.Organization, settings *api.OrgPolicySettings) []*Change {
+	if settings == nil {
+		return nil
+	}
+
+	changes := make([]*Change, 0, 6)
+	update := func(name string, set func()) {
+		changes = append(changes, &Change{
+			Section: SectionSettings,
+			Name:    name,
+			Action:  ActionUpdate,
+			apply: func(ctx context.Context) error {
+				set()
+				return user_model.UpdateUserCols(ctx, org.AsUser(), name)
+			},
+		})
+	}
+	if settings.FullName != nil && *settings.FullName != org.FullName {
+		update("full_name", func() { org.FullName = *settings.FullName })
+	}
+	if settings.Description != nil && *settings.Description != org.Description {
+		update("description", func() { org.Description = *settings.Description })
+	}
+	if settings.Website != nil && *settings.Website != org.Website {
+		update("website", func() { org.Website = *settings.Website })
+	}
+	if settings.Location != nil && *settings.Location != org.Location {
+		update("location", func() { org.Location = *settings.Location })
+	}
+	if settings.Visibility != nil && api.VisibilityModes[*settings.Visibility] != org.Visibility {
+		update("visibility", func() { org.Visibility = api.VisibilityModes[*settings.Visibility] })
+	}
+	if settings.RepoAdminChangeTeamAccess != nil && *settings.RepoAdminChangeTeamAccess != org.RepoAdminChangeTeamAccess {
+		update("repo_admin_change_team_access", func() { org.RepoAdminChangeTeamAccess = *settings.RepoAdminChangeTeamAccess })
+	}
+	return changes
+}
+
+// twoFactorDrift reports the members without two-factor authentication,
+// they have to enable it themselves.
+func twoFactorDrift(ctx context.Context, org *organization.Organization, policy *api.OrgPolicy) ([]*Change, error) {
+	if !policy.RequireTwoFactor {
+		return nil, nil
+	}
+
+	members, _, err := org.GetMembers()
+	if err != nil {
+		return nil, err
+	}
+	changes := make([]*Change, 0, len(members))
+	for _, member := range members {
+		has, err := auth.HasTwoFactorByUID(member.ID)
+		if err != nil {
+			return nil, err
+		} else if !has {
+			changes = append(changes, &Change{
+				Section: SectionRequireTwoFactor,
+				Name:    member.Name,
+				Action:  ActionReport,
+			})
+		}
+	}
+	return changes, nil
+}
+
+func teamsDrift(ctx context.Context, org *organization.Organization, policy *api.OrgPolicy) ([]*Change, error) {
+	changes := make([]*Change, 0, len(policy.Teams))
+	for _, declared := range policy.Teams {
+		declared := declared
+		team, err := organization.GetTeam(ctx, org.ID, declared.Name)
+		if err != nil && !organization.IsErrTeamNotExist(err) {
+			return nil, err
+		}
+
+		if team == nil {
+			changes = append(changes, &Change{
+				Section: SectionTeams,
+				Name:    declared.Name,
+				Action:  ActionCreate,
+				apply: func(ctx context.Context) error {
+					team := &organization.Team{OrgID: org.ID, Name: declared.Name}
+					setTeam(team, declared)
+					return models.NewTeam(team)
+				},
+			})
+		} else if change, err := teamDrift(team, declared); err != nil {
+			return nil, err
+		} else if change != nil {
+			changes = append(changes, change)
+		}
+
+		memberChanges, err := teamMembersDrift(ctx, org, team, declared)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, memberChanges...)
+	}
+	return changes, nil
+}
+
+func teamDrift(team *organization.Team, declared *api.OrgPolicyTeam) (*Change, error) {
+	if err := team.GetUnits(); err != nil {
+		return nil, err
+	}
+
+	authChanged := false
+	includeAllChanged := false
+	changed := team.Description != declared.Description
+	if !team.IsOwnerTeam() {
+		authChanged = team.AccessMode != perm.ParseAccessMode(declared.Permission) || !hasUnits(team, declared.Units)
+		includeAllChanged = team.IncludesAllRepositories != declared.IncludesAllRepositories
+		changed = changed || authChanged || includeAllChanged || team.CanCreateOrgRepo != declared.CanCreateOrgRepo
+	}
+	if !changed {
+		return nil, nil
+	}
+
+	return &Change{
+		Section: SectionTeams,
+		Name:    team.Name,
+		Action:  ActionUpdate,
+		apply: func(ctx context.Context) error {
+			if team.IsOwnerTeam() {
+				team.Description = declared.Description
+			} else {
+				setTeam(team, declared)
+			}
+			return models.UpdateTeam(team, authChanged, includeAllChanged)
+		},
+	}, nil
+}
+
+// setTeam sets the declared options of a team which is not the owners team
+func setTeam(team *organization.Team, declared *api.OrgPolicyTeam) {
+	team.Description = declared.Description
+	team.AccessMode = perm.ParseAccessMode(declared.Permission)
+	team.IncludesAllRepositories = declared.IncludesAllRepositories
+	team.CanCreateOrgRepo = declared.CanCreateOrgRepo
+	team.Units = nil
+	if team.AccessMode < perm.AccessModeAdmin {
+		for _, tp := range unit_model.FindUnitTypes(declared.Units...) {
+			team.Units = append(team.Units, &organization.TeamUnit{
+				OrgID:      team.OrgID,
+				Type:       tp,
+				AccessMode: team.AccessMode,
+			})
+		}
+	}
+}
+
+// hasUnits returns true if the team has exactly the declared units, with the access mode of the team
+func hasUnits(team *organization.Team, units []string) bool {
+	if team.AccessMode >= perm.AccessModeAdmin {
+		return true
+	}
+	types := unit_model.FindUnitTypes(units...)
+	if len(types) != len(team.Units) {
+		return false
+	}
+	for _, u := range team.Units {
+		if u.AccessMode != team.AccessMode || !hasType(types, u.Type) {
+			return false
+		}
+	}
+	return true
+}
+
+func hasType(types []unit_model.Type, tp unit_model.Type) bool {
+	for _, t := range types {
+		if t == tp {
+			return true
+		}
+	}
+	return false
+}
+
+// teamMembersDrift returns the members to add to the team, followed by the members to remove from it.
+// The team is nil if it doesn't exist yet.
+func teamMembersDrift(ctx context.Context, org *organization.Organization, team *organization.Team, declared *api.OrgPolicyTeam) ([]*Change, error) {
+	if declared.Members == nil {
+		return nil, nil
+	}
+
+	current := make(map[int64]*user_model.User)
+	if team != nil {
+		if err := team.GetMembersCtx(ctx); err != nil {
+			return nil, err
+		}
+		for _, member := range team.Members {
+			current[member.ID] = member
+		}
+	}
+
+	memberChange := func(user *user_model.User, action Action) *Change {
+		return &Change{
+			Section: SectionTeams,
+			Name:    declared.Name + ": " + user.Name,
+			Action:  action,
+			apply: func(ctx context.Context) error {
+				// the team might have been created or changed by the previous changes
+				team, err := organization.GetTeam(ctx, org.ID, declared.Name)
+				if err != nil {
+					return err
+				}
+				if action == ActionAdd {
+					return models.AddTeamMember(team, user.ID)
+				}
+				return models.RemoveTeamMember(team, user.ID)
+			},
+		}
+	}
+
+	changes := make([]*Change, 0, len(declared.Members))
+	wanted := make(map[int64]bool, len(declared.Members))
+	for _, name := range declared.Members {
+		user, err := user_model.GetUserByName(ctx, strings.TrimSpace(name))
+		if err != nil {
+			if user_model.IsErrUserNotExist(err) {
+				return nil, ErrInvalidPolicy{Reason: fmt.Sprintf("unknown member %q of team %q", name, declared.Name)}
+			}
+			return nil, err
+		} else if user.IsOrganization() {
+			return nil, ErrInvalidPolicy{Reason: fmt.Sprintf("member %q of team %q is an organization", name, declared.Name)}
+		}
+		wanted[user.ID] = true
+		if current[user.ID] == nil {
+			changes = append(changes, memberChange(user, ActionAdd))
+		}
+	}
+	if team != nil {
+		for _, member := range team.Members {
+			if !wanted[member.ID] {
+				changes = append(changes, memberChange(member, ActionRemove))
+			}
+		}
+	}
+	return changes, nil
+}
+
+func repoDefaultsDrift(ctx context.Context, org *organization.Organization, policy *api.OrgPolicy) ([]*Change, error) {
+	defaults := policy.RepoDefaults
+	if defaults == nil {
+		return nil, nil
+	}
+
+	repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
+		Actor:       org.AsUser(),
+		Private:     true,
+		ListOptions: db.ListOptions{Page: 1, PageSize: org.NumRepos},
+		OrderBy:     db.SearchOrderByAlphabetically,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make([]*Change, 0, len(repos))
+	for _, repo := range repos {
+		repo := repo
+		if err := repo.LoadUnits(ctx); err != nil {
+			return nil, err
+		}
+
+		if defaults.Private != nil && *defaults.Private != repo.IsPrivate {
+			private := *defaults.Private
+			changes = append(changes, &Change{
+				Section: SectionRepoDefaults,
+				Name:    repo.Name + ": private",
+				Action:  ActionUpdate,
+				apply: func(ctx context.Context) error {
+					repo.IsPrivate = private
+					return models.UpdateRepository(repo, true)
+				},
+			})
+		}
+
+		for _, option := range []struct {
+			name     string
+			declared *bool
+			tp       unit_model.Type
+		}{
+			{"has_issues", defaults.HasIssues, unit_model.TypeIssues},
+			{"has_wiki", defaults.HasWiki, unit_model.TypeWiki},
+			{"has_pull_requests", defaults.HasPullRequests, unit_model.TypePullRequests},
+			{"has_projects", defaults.HasProjects, unit_model.TypeProjects},
+		} {
+			if option.declared == nil || *option.declared == repo.UnitEnabledCtx(ctx, option.tp) {
+				continue
+			}
+			enable, tp := *option.declared, option.tp
+			changes = append(changes, &Change{
+				Section: SectionRepoDefaults,
+				Name:    repo.Name + ": " + option.name,
+				Action:  ActionUpdate,
+				apply: func(ctx context.Context) error {
+					if !enable {
+						return repo_model.UpdateRepositoryUnits(repo, nil, []unit_model.Type{tp})
+					}
+					return repo_model.UpdateRepositoryUnits(repo, []repo_model.RepoUnit{defaultRepoUnit(repo, tp)}, nil)
+				},
+			})
+		}
+	}
+	return changes, nil
+}
+
+// defaultRepoUnit returns a unit with the configuration of new repositories
+func defaultRepoUnit(repo *repo_model.Repository, tp unit_model.Type) repo_model.RepoUnit {
+	u := repo_model.RepoUnit{RepoID: repo.ID, Type: tp}
+	switch tp {
+	case unit_model.TypeIssues:
+		u.Config = &repo_model.IssuesConfig{
+			EnableTimetracker:                setting.Service.DefaultEnableTimetracking,
+			AllowOnlyContributorsToTrackTime: setting.Service.DefaultAllowOnlyContributorsToTrackTime,
+			EnableDependencies:               setting.Service.DefaultEnableDependencies,
+		}
+	case unit_model.TypePullRequests:
+		u.Config = &repo_model.PullRequestsConfig{AllowMerge: true, AllowRebase: true, AllowRebaseMerge: true, AllowSquash: true, DefaultMergeStyle: repo_model.MergeStyleMerge, AllowRebaseUpdate: true}
+	default:
+		u.Config = new(repo_model.UnitConfig)
+	}
+	return u
+}
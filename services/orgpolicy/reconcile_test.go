@@ -0,0 +1,96 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package orgpolicy
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriftAndApply(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	policy, err := Parse([]byte(`
+settings:
+  description: managed by policy
+require_two_factor: true
+teams:
+  - name: team1
+    permission: read
+    units: [repo.code]
+    members: [user2, user5]
+  - name: reviewers
+    permission: read
+    units: [repo.pulls]
+    members: [user4]
+repo_defaults:
+  has_wiki: false
+`))
+	assert.NoError(t, err)
+
+	org := unittest.AssertExistsAndLoadBean(t, &organization.Organization{ID: 3}).(*organization.Organization)
+	changes, err := Drift(db.DefaultContext, org, policy)
+	assert.NoError(t, err)
+
+	type change struct {
+		Section Section
+		Name    string
+		Action  Action
+	}
+	actual := make([]change, 0, len(changes))
+	for _, c := range changes {
+		actual = append(actual, change{c.Section, c.Name, c.Action})
+	}
+	assert.Subset(t, actual, []change{
+		{SectionSettings, "description", ActionUpdate},
+		{SectionRequireTwoFactor, "user2", ActionReport},
+		{SectionTeams, "team1", ActionUpdate},
+		{SectionTeams, "team1: user5", ActionAdd},
+		{SectionTeams, "team1: user4", ActionRemove},
+		{SectionTeams, "reviewers", ActionCreate},
+		{SectionTeams, "reviewers: user4", ActionAdd},
+		{SectionRepoDefaults, "repo3: has_wiki", ActionUpdate},
+	})
+	assert.NotContains(t, actual, change{SectionTeams, "team1: user2", ActionAdd})
+
+	Apply(db.DefaultContext, changes)
+	for _, c := range changes {
+		if c.Action == ActionReport {
+			assert.Error(t, c.Err)
+		} else {
+			assert.NoError(t, c.Err, c.Name)
+		}
+	}
+
+	org = unittest.AssertExistsAndLoadBean(t, &organization.Organization{ID: 3}).(*organization.Organization)
+	assert.EqualValues(t, "managed by policy", org.Description)
+
+	team1 := unittest.AssertExistsAndLoadBean(t, &organization.Team{OrgID: 3, LowerName: "team1"}).(*organization.Team)
+	assert.EqualValues(t, perm.AccessModeRead, team1.AccessMode)
+	unittest.AssertExistsAndLoadBean(t, &organization.TeamUser{TeamID: team1.ID, UID: 5})
+	unittest.AssertNotExistsBean(t, &organization.TeamUser{TeamID: team1.ID, UID: 4})
+	reviewers := unittest.AssertExistsAndLoadBean(t, &organization.Team{OrgID: 3, LowerName: "reviewers"}).(*organization.Team)
+	unittest.AssertExistsAndLoadBean(t, &organization.TeamUser{TeamID: reviewers.ID, UID: 4})
+	unittest.AssertExistsAndLoadBean(t, &organization.TeamUnit{TeamID: reviewers.ID, Type: unit.TypePullRequests})
+	unittest.AssertNotExistsBean(t, &repo_model.RepoUnit{RepoID: 3, Type: unit.TypeWiki})
+
+	// only the reports are left
+	changes, err = Drift(db.DefaultContext, org, policy)
+	assert.NoError(t, err)
+	for _, c := range changes {
+		assert.EqualValues(t, ActionReport, c.Action)
+		user := unittest.AssertExistsAndLoadBean(t, &user_model.User{Name: c.Name}).(*user_model.User)
+		assert.True(t, org.HasMemberWithUserID(user.ID))
+	}
+}
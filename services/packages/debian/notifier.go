@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"fmt"
+
+	packages_model "code.gitea.io/gitea/models/packages"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// Init registers the notifier which rebuilds the repository files if a package gets deleted
+func Init() error {
+	if !setting.Packages.Enabled {
+		return nil
+	}
+	notification.RegisterNotifier(NewNotifier())
+	return nil
+}
+
+type debianNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &debianNotifier{}
+
+// NewNotifier create a new debianNotifier notifier
+func NewNotifier() base.Notifier {
+	return &debianNotifier{}
+}
+
+func (n *debianNotifier) NotifyPackageDelete(doer *user_model.User, pd *packages_model.PackageDescriptor) {
+	if pd.Package.Type != packages_model.TypeDebian {
+		return
+	}
+
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("debian.NotifyPackageDelete Owner: %s[%d]", pd.Owner.Name, pd.Owner.ID))
+	defer finished()
+
+	if err := BuildAllRepositoryFiles(ctx, pd.Owner.ID); err != nil {
+		log.Error("BuildAllRepositoryFiles [%d]: %v", pd.Owner.ID, err)
+	}
+}
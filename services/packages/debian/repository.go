@@ -0,0 +1,470 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package debian
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	debian_model "code.gitea.io/gitea/models/packages/debian"
+	user_model "code.gitea.io/gitea/models/user"
+	packages_module "code.gitea.io/gitea/modules/packages"
+	debian_module "code.gitea.io/gitea/modules/packages/debian"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/sync"
+	packages_service "code.gitea.io/gitea/services/packages"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/keybase/go-crypto/openpgp/clearsign"
+)
+
+// repositoryPool makes sure the repository files of an owner are built by one process at a time
+var repositoryPool = sync.NewExclusivePool()
+
+// GetOrCreateRepositoryVersion gets or creates the internal package version which stores the repository files
+func GetOrCreateRepositoryVersion(ownerID int64) (*packages_model.PackageVersion, error) {
+	var pv *packages_model.PackageVersion
+
+	return pv, db.WithTx(func(ctx context.Context) error {
+		p := &packages_model.Package{
+			OwnerID:   ownerID,
+			Type:      packages_model.TypeDebian,
+			Name:      debian_module.RepositoryPackage,
+			LowerName: debian_module.RepositoryPackage,
+		}
+		var err error
+		if p, err = packages_model.TryInsertPackage(ctx, p); err != nil {
+			if err != packages_model.ErrDuplicatePackage {
+				return err
+			}
+		}
+
+		pv = &packages_model.PackageVersion{
+			PackageID:    p.ID,
+			CreatorID:    ownerID,
+			Version:      debian_module.RepositoryVersion,
+			LowerVersion: debian_module.RepositoryVersion,
+			IsInternal:   true,
+			MetadataJSON: "null",
+		}
+		if pv, err = packages_model.GetOrInsertVersion(ctx, pv); err != nil {
+			if err != packages_model.ErrDuplicatePackageVersion {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// GetOrCreateKeyPair gets or creates the PGP keys used to sign the repository files
+func GetOrCreateKeyPair(ownerID int64) (string, string, error) {
+	priv, err := user_model.GetUserSetting(ownerID, debian_module.SettingKeyPrivate)
+	if err != nil {
+		return "", "", err
+	}
+
+	pub, err := user_model.GetUserSetting(ownerID, debian_module.SettingKeyPublic)
+	if err != nil {
+		return "", "", err
+	}
+
+	if priv == "" || pub == "" {
+		priv, pub, err = generateKeypair()
+		if err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, debian_module.SettingKeyPrivate, priv); err != nil {
+			return "", "", err
+		}
+
+		if err := user_model.SetUserSetting(ownerID, debian_module.SettingKeyPublic, pub); err != nil {
+			return "", "", err
+		}
+	}
+
+	return priv, pub, nil
+}
+
+func generateKeypair() (string, string, error) {
+	e, err := openpgp.NewEntity(setting.AppName, "Debian Registry", "", nil)
+	if err != nil {
+		return "", "", err
+	}
+
+	var priv strings.Builder
+	var pub strings.Builder
+
+	w, err := armor.Encode(&priv, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.SerializePrivate(w, nil); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	w, err = armor.Encode(&pub, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return "", "", err
+	}
+	if err := e.Serialize(w); err != nil {
+		return "", "", err
+	}
+	w.Close()
+
+	return priv.String(), pub.String(), nil
+}
+
+// BuildAllRepositoryFiles (re)builds all repository files of the owner
+func BuildAllRepositoryFiles(ctx context.Context, ownerID int64) error {
+	key := strconv.FormatInt(ownerID, 10)
+	repositoryPool.CheckIn(key)
+	defer repositoryPool.CheckOut(key)
+
+	pv, err := GetOrCreateRepositoryVersion(ownerID)
+	if err != nil {
+		return err
+	}
+
+	// 1. Delete all existing repository files
+	pfs, err := packages_model.GetFilesByVersionID(ctx, pv.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, pf := range pfs {
+		if err := packages_service.DeletePackageFile(ctx, pf); err != nil {
+			return err
+		}
+	}
+
+	// 2. (Re)Build repository files for existing packages
+	distributions, err := debian_model.GetDistributions(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	for _, distribution := range distributions {
+		components, err := debian_model.GetComponents(ctx, ownerID, distribution)
+		if err != nil {
+			return err
+		}
+		architectures, err := debian_model.GetArchitectures(ctx, ownerID, distribution)
+		if err != nil {
+			return err
+		}
+
+		for _, component := range components {
+			for _, architecture := range architectures {
+				if err := buildPackagesIndices(ctx, ownerID, pv, distribution, component, architecture); err != nil {
+					return fmt.Errorf("buildPackagesIndices failed: %w", err)
+				}
+			}
+		}
+		if err := buildReleaseFiles(ctx, ownerID, pv, distribution); err != nil {
+			return fmt.Errorf("buildReleaseFiles failed: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// BuildSpecificRepositoryFiles builds the index files of the architecture and the release files of the distribution
+func BuildSpecificRepositoryFiles(ctx context.Context, ownerID int64, distribution, component, architecture string) error {
+	key := strconv.FormatInt(ownerID, 10)
+	repositoryPool.CheckIn(key)
+	defer repositoryPool.CheckOut(key)
+
+	pv, err := GetOrCreateRepositoryVersion(ownerID)
+	if err != nil {
+		return err
+	}
+
+	if err := buildPackagesIndices(ctx, ownerID, pv, distribution, component, architecture); err != nil {
+		return err
+	}
+
+	return buildReleaseFiles(ctx, ownerID, pv, distribution)
+}
+
+// RebuildAllRepositories rebuilds the repository files of all owners which have Debian packages
+func RebuildAllRepositories(ctx context.Context) error {
+	ownerIDs, err := debian_model.GetOwnerIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, ownerID := range ownerIDs {
+		if err := BuildAllRepositoryFiles(ctx, ownerID); err != nil {
+			return fmt.Errorf("BuildAllRepositoryFiles [%d]: %w", ownerID, err)
+		}
+	}
+
+	return nil
+}
+
+// deleteRepositoryFiles removes the given repository files if they exist
+func deleteRepositoryFiles(ctx context.Context, pv *packages_model.PackageVersion, compositeKey string, filenames ...string) error {
+	for _, filename := range filenames {
+		pf, err := packages_model.GetFileForVersionByName(ctx, pv.ID, filename, compositeKey)
+		if err != nil {
+			if err == packages_model.ErrPackageFileNotExist {
+				continue
+			}
+			return err
+		}
+		if err := packages_service.DeletePackageFile(ctx, pf); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// https://wiki.debian.org/DebianRepository/Format#A.22Packages.22_Indices
+func buildPackagesIndices(ctx context.Context, ownerID int64, pv *packages_model.PackageVersion, distribution, component, architecture string) error {
+	compositeKey := debian_module.IndexCompositeKey(distribution, component, architecture)
+
+	pfs, _, err := packages_model.SearchFiles(ctx, &packages_model.PackageFileSearchOptions{
+		OwnerID:     ownerID,
+		PackageType: string(packages_model.TypeDebian),
+		Properties: map[string]string{
+			debian_module.PropertyDistribution: distribution,
+			debian_module.PropertyComponent:    component,
+			debian_module.PropertyArchitecture: architecture,
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Delete the index files if there are no packages
+	if len(pfs) == 0 {
+		return deleteRepositoryFiles(ctx, pv, compositeKey, "Packages", "Packages.gz")
+	}
+
+	packagesContent, err := packages_module.NewHashedBuffer(32 * 1024 * 1024)
+	if err != nil {
+		return err
+	}
+	defer packagesContent.Close()
+
+	packagesGzipContent, err := packages_module.NewHashedBuffer(32 * 1024 * 1024)
+	if err != nil {
+		return err
+	}
+	defer packagesGzipContent.Close()
+
+	gzw := gzip.NewWriter(packagesGzipContent)
+	w := io.MultiWriter(packagesContent, gzw)
+
+	for i, pf := range pfs {
+		pfd, err := packages_model.GetPackageFileDescriptor(ctx, pf)
+		if err != nil {
+			return err
+		}
+
+		if i > 0 {
+			fmt.Fprintf(w, "\n")
+		}
+		fmt.Fprintf(w, "%s\n", pfd.Properties.GetByName(debian_module.PropertyControl))
+		fmt.Fprintf(w, "Filename: pool/%s/%s/%s\n", distribution, component, pf.Name)
+		fmt.Fprintf(w, "Size: %d\n", pfd.Blob.Size)
+		fmt.Fprintf(w, "MD5sum: %s\n", pfd.Blob.HashMD5)
+		fmt.Fprintf(w, "SHA1: %s\n", pfd.Blob.HashSHA1)
+		fmt.Fprintf(w, "SHA256: %s\n", pfd.Blob.HashSHA256)
+		fmt.Fprintf(w, "SHA512: %s\n", pfd.Blob.HashSHA512)
+	}
+
+	if err := gzw.Close(); err != nil {
+		return err
+	}
+
+	for _, file := range []struct {
+		Name string
+		Data *packages_module.HashedBuffer
+	}{
+		{"Packages", packagesContent},
+		{"Packages.gz", packagesGzipContent},
+	} {
+		if _, err := file.Data.Seek(0, io.SeekStart); err != nil {
+			return err
+		}
+
+		_, err = packages_service.AddFileToPackageVersionInternal(
+			pv,
+			&packages_service.PackageFileCreationInfo{
+				PackageFileInfo: packages_service.PackageFileInfo{
+					Filename:     file.Name,
+					CompositeKey: compositeKey,
+				},
+				Data:              file.Data,
+				OverwriteExisting: true,
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// https://wiki.debian.org/DebianRepository/Format#A.22Release.22_files
+func buildReleaseFiles(ctx context.Context, ownerID int64, pv *packages_model.PackageVersion, distribution string) error {
+	pfs, _, err := packages_model.SearchFiles(ctx, &packages_model.PackageFileSearchOptions{
+		VersionID: pv.ID,
+	})
+	if err != nil {
+		return err
+	}
+
+	prefix := distribution + "|"
+
+	indexFiles := make([]*packages_model.PackageFile, 0, len(pfs))
+	for _, pf := range pfs {
+		if strings.HasPrefix(pf.CompositeKey, prefix) {
+			indexFiles = append(indexFiles, pf)
+		}
+	}
+
+	// Delete the release files if there are no index files
+	if len(indexFiles) == 0 {
+		return deleteRepositoryFiles(ctx, pv, distribution, "Release", "Release.gpg", "InRelease")
+	}
+
+	// The hash lists reference the index files by their path relative to the distribution
+	sort.Slice(indexFiles, func(i, j int) bool {
+		return indexFiles[i].CompositeKey+indexFiles[i].Name < indexFiles[j].CompositeKey+indexFiles[j].Name
+	})
+
+	components, err := debian_model.GetComponents(ctx, ownerID, distribution)
+	if err != nil {
+		return err
+	}
+	architectures, err := debian_model.GetArchitectures(ctx, ownerID, distribution)
+	if err != nil {
+		return err
+	}
+
+	owner, err := user_model.GetUserByID(ownerID)
+	if err != nil {
+		return err
+	}
+
+	type fileEntry struct {
+		Path string
+		Blob *packages_model.PackageBlob
+	}
+
+	entries := make([]*fileEntry, 0, len(indexFiles))
+	for _, pf := range indexFiles {
+		pb, err := packages_model.GetBlobByID(ctx, pf.BlobID)
+		if err != nil {
+			return err
+		}
+
+		parts := strings.SplitN(pf.CompositeKey, "|", 3)
+		entries = append(entries, &fileEntry{
+			Path: fmt.Sprintf("%s/binary-%s/%s", parts[1], parts[2], pf.Name),
+			Blob: pb,
+		})
+	}
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "Origin: %s\n", owner.Name)
+	fmt.Fprintf(&buf, "Label: %s\n", owner.Name)
+	fmt.Fprintf(&buf, "Suite: %s\n", distribution)
+	fmt.Fprintf(&buf, "Codename: %s\n", distribution)
+	fmt.Fprintf(&buf, "Components: %s\n", strings.Join(components, " "))
+	fmt.Fprintf(&buf, "Architectures: %s\n", strings.Join(architectures, " "))
+	fmt.Fprintf(&buf, "Date: %s\n", time.Now().UTC().Format(time.RFC1123))
+	for _, hash := range []struct {
+		Name  string
+		Value func(*packages_model.PackageBlob) string
+	}{
+		{"MD5Sum", func(pb *packages_model.PackageBlob) string { return pb.HashMD5 }},
+		{"SHA1", func(pb *packages_model.PackageBlob) string { return pb.HashSHA1 }},
+		{"SHA256", func(pb *packages_model.PackageBlob) string { return pb.HashSHA256 }},
+		{"SHA512", func(pb *packages_model.PackageBlob) string { return pb.HashSHA512 }},
+	} {
+		fmt.Fprintf(&buf, "%s:\n", hash.Name)
+		for _, e := range entries {
+			fmt.Fprintf(&buf, " %s %d %s\n", hash.Value(e.Blob), e.Blob.Size, e.Path)
+		}
+	}
+
+	release := buf.Bytes()
+
+	priv, _, err := GetOrCreateKeyPair(ownerID)
+	if err != nil {
+		return err
+	}
+
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(priv))
+	if err != nil {
+		return err
+	}
+	e := entities[0]
+
+	var detachedSignature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&detachedSignature, e, bytes.NewReader(release), nil); err != nil {
+		return err
+	}
+
+	var inlineSigned bytes.Buffer
+	sw, err := clearsign.Encode(&inlineSigned, e.PrivateKey, nil)
+	if err != nil {
+		return err
+	}
+	if _, err := sw.Write(release); err != nil {
+		return err
+	}
+	if err := sw.Close(); err != nil {
+		return err
+	}
+
+	for _, file := range []struct {
+		Name string
+		Data []byte
+	}{
+		{"Release", release},
+		{"Release.gpg", detachedSignature.Bytes()},
+		{"InRelease", inlineSigned.Bytes()},
+	} {
+		hb, err := packages_module.CreateHashedBufferFromReader(bytes.NewReader(file.Data), 32*1024*1024)
+		if err != nil {
+			return err
+		}
+
+		_, err = packages_service.AddFileToPackageVersionInternal(
+			pv,
+			&packages_service.PackageFileCreationInfo{
+				PackageFileInfo: packages_service.PackageFileInfo{
+					Filename:     file.Name,
+					CompositeKey: distribution,
+				},
+				Data:              hb,
+				OverwriteExisting: true,
+			},
+		)
+		hb.Close()
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -75,6 +75,10 @@ func createPackageAndAddFile(pvci *PackageCreationInfo, pfci *PackageFileCreatio
 		return nil, nil, err
 	}
 
+	if err := CheckSizeQuotaExceeded(ctx, pvci.Owner.ID, pfci.Data.Size()); err != nil {
+		return nil, nil, err
+	}
+
 	pf, pb, blobCreated, err := addFileToPackageVersion(ctx, pv, pfci)
 	removeBlob := false
 	defer func() {
@@ -149,6 +153,10 @@ func createPackageAndVersion(ctx context.Context, pvci *PackageCreationInfo, all
 	}
 
 	if created {
+		if err := CheckCountQuotaExceeded(ctx, pvci.Owner.ID); err != nil {
+			return nil, false, err
+		}
+
 		for name, value := range pvci.Properties {
 			if _, err := packages_model.InsertProperty(ctx, packages_model.PropertyTypeVersion, pv.ID, name, value); err != nil {
 				log.Error("Error setting package version property: %v", err)
@@ -173,6 +181,10 @@ func AddFileToExistingPackage(pvi *PackageInfo, pfci *PackageFileCreationInfo) (
 		return nil, nil, err
 	}
 
+	if err := CheckSizeQuotaExceeded(ctx, pvi.Owner.ID, pfci.Data.Size()); err != nil {
+		return nil, nil, err
+	}
+
 	pf, pb, blobCreated, err := addFileToPackageVersion(ctx, pv, pfci)
 	removeBlob := false
 	defer func() {
@@ -196,6 +208,38 @@ func AddFileToExistingPackage(pvi *PackageInfo, pfci *PackageFileCreationInfo) (
 	return pv, pf, nil
 }
 
+// AddFileToPackageVersionInternal adds a file to the package version without checking the quota.
+// It must only be used for files which are managed by Gitea itself, like repository index files.
+func AddFileToPackageVersionInternal(pv *packages_model.PackageVersion, pfci *PackageFileCreationInfo) (*packages_model.PackageFile, error) {
+	ctx, committer, err := db.TxContext()
+	if err != nil {
+		return nil, err
+	}
+	defer committer.Close()
+
+	pf, pb, blobCreated, err := addFileToPackageVersion(ctx, pv, pfci)
+	removeBlob := false
+	defer func() {
+		if removeBlob {
+			contentStore := packages_module.NewContentStore()
+			if err := contentStore.Delete(packages_module.BlobHash256Key(pb.HashSHA256)); err != nil {
+				log.Error("Error deleting package blob from content store: %v", err)
+			}
+		}
+	}()
+	if err != nil {
+		removeBlob = blobCreated
+		return nil, err
+	}
+
+	if err := committer.Commit(); err != nil {
+		removeBlob = blobCreated
+		return nil, err
+	}
+
+	return pf, nil
+}
+
 // NewPackageBlob creates a package blob instance
 func NewPackageBlob(hsr packages_module.HashedSizeReader) *packages_model.PackageBlob {
 	hashMD5, hashSHA1, hashSHA256, hashSHA512 := hsr.Sums()
@@ -308,6 +352,26 @@ func RemovePackageVersion(doer *user_model.User, pv *packages_model.PackageVersi
 	return nil
 }
 
+// RemoveAllPackagesOfOwner deletes all package versions, files and packages of the owner
+func RemoveAllPackagesOfOwner(doer, owner *user_model.User) error {
+	for _, isInternal := range []bool{false, true} {
+		pvs, _, err := packages_model.SearchVersions(db.DefaultContext, &packages_model.PackageSearchOptions{
+			OwnerID:    owner.ID,
+			IsInternal: isInternal,
+		})
+		if err != nil {
+			return err
+		}
+		for _, pv := range pvs {
+			if err := RemovePackageVersion(doer, pv); err != nil {
+				return err
+			}
+		}
+	}
+
+	return packages_model.DeletePackagesIfUnreferenced(db.DefaultContext)
+}
+
 // DeletePackageVersionAndReferences deletes the package version and its properties and files
 func DeletePackageVersionAndReferences(ctx context.Context, pv *packages_model.PackageVersion) error {
 	if err := packages_model.DeleteAllProperties(ctx, packages_model.PropertyTypeVersion, pv.ID); err != nil {
@@ -0,0 +1,202 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package packages
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	packages_model "code.gitea.io/gitea/models/packages"
+	container_model "code.gitea.io/gitea/models/packages/container"
+	"code.gitea.io/gitea/modules/log"
+	container_module "code.gitea.io/gitea/modules/packages/container"
+)
+
+var (
+	// ErrQuotaTotalCount indicates the owner has reached the maximum number of package versions
+	ErrQuotaTotalCount = errors.New("maximum number of package versions exceeded")
+	// ErrQuotaTotalSize indicates the owner has reached the maximum size of package files
+	ErrQuotaTotalSize = errors.New("maximum size of package files exceeded")
+)
+
+// OwnerUsage describes the package usage of an owner
+type OwnerUsage struct {
+	Count int64
+	Size  int64
+}
+
+// GetOwnerUsage calculates the number of package versions and the size of the package files of the owner
+func GetOwnerUsage(ctx context.Context, ownerID int64) (*OwnerUsage, error) {
+	count, err := packages_model.CountOwnerVersions(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	size, err := packages_model.CalculateOwnerBlobSize(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return &OwnerUsage{Count: count, Size: size}, nil
+}
+
+// CheckCountQuotaExceeded returns ErrQuotaTotalCount if the owner has more package versions than the policy allows.
+// It must be called after a new version got inserted.
+func CheckCountQuotaExceeded(ctx context.Context, ownerID int64) error {
+	policy, err := packages_model.GetPolicyByOwnerID(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if policy.MaxCount <= 0 {
+		return nil
+	}
+
+	count, err := packages_model.CountOwnerVersions(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if count > policy.MaxCount {
+		log.Trace("Package count quota of owner %d exceeded: %d > %d", ownerID, count, policy.MaxCount)
+		return ErrQuotaTotalCount
+	}
+	return nil
+}
+
+// CheckSizeQuotaExceeded returns ErrQuotaTotalSize if adding a file of the given size exceeds the size the policy allows
+func CheckSizeQuotaExceeded(ctx context.Context, ownerID, size int64) error {
+	policy, err := packages_model.GetPolicyByOwnerID(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if policy.MaxSize <= 0 {
+		return nil
+	}
+
+	used, err := packages_model.CalculateOwnerBlobSize(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if used+size > policy.MaxSize {
+		log.Trace("Package size quota of owner %d exceeded: %d + %d > %d", ownerID, used, size, policy.MaxSize)
+		return ErrQuotaTotalSize
+	}
+	return nil
+}
+
+// ApplyRetentionPolicies removes the package versions which are not retained by the policy of their owner
+func ApplyRetentionPolicies(ctx context.Context) error {
+	policies, err := packages_model.FindPoliciesWithRetentionRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, policy := range policies {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before applying the package retention policy of owner %d", policy.OwnerID)
+		default:
+		}
+		if err := db.WithTx(func(ctx context.Context) error {
+			return applyRetentionPolicy(ctx, policy)
+		}); err != nil {
+			log.Error("Unable to apply the package retention policy of owner %d: %v", policy.OwnerID, err)
+		}
+	}
+
+	return nil
+}
+
+func applyRetentionPolicy(ctx context.Context, policy *packages_model.PackagePolicy) error {
+	ps, err := packages_model.GetPackagesByOwner(ctx, policy.OwnerID)
+	if err != nil {
+		return err
+	}
+
+	for _, p := range ps {
+		if policy.KeepLastVersions > 0 {
+			if err := removeOutdatedVersions(ctx, p, policy.KeepLastVersions); err != nil {
+				return err
+			}
+		}
+		if policy.UntaggedRetentionDays > 0 && p.Type == packages_model.TypeContainer {
+			olderThan := time.Duration(policy.UntaggedRetentionDays) * 24 * time.Hour
+			if err := removeExpiredUntaggedManifests(ctx, p, olderThan); err != nil {
+				return err
+			}
+		}
+	}
+
+	return packages_model.DeletePackagesIfUnreferenced(ctx)
+}
+
+// removeOutdatedVersions removes all but the newest keep versions of the package.
+// Only tagged versions are considered for container images, untagged manifests are handled by removeExpiredUntaggedManifests.
+func removeOutdatedVersions(ctx context.Context, p *packages_model.Package, keep int) error {
+	var pvs []*packages_model.PackageVersion
+	var err error
+	if p.Type == packages_model.TypeContainer {
+		pvs, _, err = container_model.SearchImageTags(ctx, &container_model.ImageTagsSearchOptions{
+			PackageID: p.ID,
+			IsTagged:  true,
+		})
+	} else {
+		pvs, _, err = packages_model.SearchVersions(ctx, &packages_model.PackageSearchOptions{
+			PackageID: p.ID,
+		})
+	}
+	if err != nil {
+		return err
+	}
+
+	// versions are sorted newest first
+	if len(pvs) <= keep {
+		return nil
+	}
+	for _, pv := range pvs[keep:] {
+		log.Trace("Removing outdated package version %d of package %d", pv.ID, p.ID)
+		if err := DeletePackageVersionAndReferences(ctx, pv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// removeExpiredUntaggedManifests removes untagged manifests which are older than olderThan and not referenced by a manifest index
+func removeExpiredUntaggedManifests(ctx context.Context, p *packages_model.Package, olderThan time.Duration) error {
+	pvs, _, err := container_model.SearchImageTags(ctx, &container_model.ImageTagsSearchOptions{
+		PackageID: p.ID,
+		IsTagged:  false,
+	})
+	if err != nil {
+		return err
+	}
+
+	expired := time.Now().Add(-olderThan).Unix()
+	for _, pv := range pvs {
+		if int64(pv.CreatedUnix) >= expired {
+			continue
+		}
+
+		_, referenced, err := packages_model.SearchVersions(ctx, &packages_model.PackageSearchOptions{
+			PackageID: p.ID,
+			Properties: map[string]string{
+				container_module.PropertyManifestReference: pv.LowerVersion,
+			},
+			Paginator: db.NewAbsoluteListOptions(0, 1),
+		})
+		if err != nil {
+			return err
+		}
+		if referenced > 0 {
+			continue
+		}
+
+		log.Trace("Removing untagged manifest %d of package %d", pv.ID, p.ID)
+		if err := DeletePackageVersionAndReferences(ctx, pv); err != nil {
+			return err
+		}
+	}
+	return nil
+}
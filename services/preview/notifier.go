@@ -0,0 +1,51 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package preview
+
+import (
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification/base"
+	"code.gitea.io/gitea/modules/process"
+)
+
+type previewNotifier struct {
+	base.NullNotifier
+}
+
+var _ base.Notifier = &previewNotifier{}
+
+// NewNotifier create a new previewNotifier notifier
+func NewNotifier() base.Notifier {
+	return &previewNotifier{}
+}
+
+func (*previewNotifier) NotifyIssueChangeStatus(doer *user_model.User, issue *models.Issue, _ *models.Comment, isClosed bool) {
+	if !issue.IsPull || !isClosed {
+		return
+	}
+	if err := issue.LoadPullRequest(); err != nil {
+		log.Error("LoadPullRequest[%d]: %v", issue.ID, err)
+		return
+	}
+	deletePullPreviewEnvironments(doer, issue.PullRequest)
+}
+
+func (*previewNotifier) NotifyMergePullRequest(pr *models.PullRequest, doer *user_model.User) {
+	deletePullPreviewEnvironments(doer, pr)
+}
+
+func deletePullPreviewEnvironments(doer *user_model.User, pr *models.PullRequest) {
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().HammerContext(), fmt.Sprintf("preview.deletePullPreviewEnvironments Pull[%d] #%d in [%d]", pr.ID, pr.Index, pr.BaseRepoID))
+	defer finished()
+
+	if err := DeletePullPreviewEnvironments(ctx, doer, pr); err != nil {
+		log.Error("DeletePullPreviewEnvironments[%d]: %v", pr.ID, err)
+	}
+}
@@ -0,0 +1,125 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package preview
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+)
+
+// Init registers the notifier removing the preview environments of closed pull requests
+func Init() error {
+	notification.RegisterNotifier(NewNotifier())
+	return nil
+}
+
+// CreatePreviewEnvironment registers a preview environment for an open pull request
+func CreatePreviewEnvironment(ctx context.Context, doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) error {
+	if err := pr.LoadIssueCtx(ctx); err != nil {
+		return err
+	}
+	if pr.Issue.IsClosed {
+		return models.ErrInvalidPreviewEnvironment{Reason: "the pull request is closed"}
+	}
+	if err := env.Validate(); err != nil {
+		return err
+	}
+
+	env.RepoID = pr.BaseRepoID
+	env.IssueID = pr.IssueID
+	env.CreatorID = doer.ID
+	env.Creator = doer
+	if err := models.CreatePreviewEnvironment(ctx, env); err != nil {
+		return err
+	}
+
+	notification.NotifyPreviewEnvironmentCreate(doer, pr, env)
+	return nil
+}
+
+// UpdatePreviewEnvironment changes the columns of a preview environment
+func UpdatePreviewEnvironment(ctx context.Context, doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment, cols ...string) error {
+	if err := env.Validate(); err != nil {
+		return err
+	}
+	if err := models.UpdatePreviewEnvironment(ctx, env, append(cols, "updated_unix")...); err != nil {
+		return err
+	}
+
+	notification.NotifyPreviewEnvironmentUpdate(doer, pr, env)
+	return nil
+}
+
+// DeletePreviewEnvironment removes a preview environment, the deployment tooling is notified to tear it down
+func DeletePreviewEnvironment(ctx context.Context, doer *user_model.User, pr *models.PullRequest, env *models.PreviewEnvironment) error {
+	if err := models.DeletePreviewEnvironment(ctx, env); err != nil {
+		return err
+	}
+
+	notification.NotifyPreviewEnvironmentDelete(doer, pr, env)
+	return nil
+}
+
+// DeletePullPreviewEnvironments removes all preview environments of a pull request
+func DeletePullPreviewEnvironments(ctx context.Context, doer *user_model.User, pr *models.PullRequest) error {
+	envs, err := models.GetPreviewEnvironments(ctx, pr.IssueID)
+	if err != nil {
+		return err
+	}
+	for _, env := range envs {
+		if err := DeletePreviewEnvironment(ctx, doer, pr, env); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// DeleteExpiredPreviewEnvironments removes the preview environments whose expiry has passed
+func DeleteExpiredPreviewEnvironments(ctx context.Context) error {
+	for {
+		envs, err := models.GetExpiredPreviewEnvironments(ctx, 50)
+		if err != nil {
+			return err
+		}
+		if len(envs) == 0 {
+			return nil
+		}
+
+		for _, env := range envs {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+
+			pr, err := models.GetPullRequestByIssueID(ctx, env.IssueID)
+			if err != nil {
+				if !models.IsErrPullRequestNotExist(err) {
+					return fmt.Errorf("GetPullRequestByIssueID[%d]: %v", env.IssueID, err)
+				}
+				// nobody is left to be notified about environments of deleted pull requests
+				if err := models.DeletePreviewEnvironment(ctx, env); err != nil {
+					return err
+				}
+				continue
+			}
+			if err := env.LoadCreator(ctx); err != nil {
+				return err
+			}
+			if env.Creator == nil {
+				env.Creator = user_model.NewGhostUser()
+			}
+			log.Trace("Deleting expired preview environment %s of pull request %d", env.Name, pr.ID)
+			if err := DeletePreviewEnvironment(ctx, env.Creator, pr, env); err != nil {
+				return err
+			}
+		}
+	}
+}
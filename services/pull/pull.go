@@ -124,6 +124,13 @@ func NewPullRequest(ctx context.Context, repo *repo_model.Repository, pull *mode
 		_, _ = models.CreateComment(ops)
 	}
 
+	changedFiles, err := baseGitRepo.GetFilesChangedBetween(compareInfo.MergeBase, compareInfo.HeadCommitID)
+	if err != nil {
+		log.Error("GetFilesChangedBetween [%d]: %v", pr.ID, err)
+	} else if err := issue_service.ApplyTriageRules(prCtx, baseGitRepo, pull, changedFiles); err != nil {
+		log.Error("ApplyTriageRules [%d]: %v", pull.ID, err)
+	}
+
 	return nil
 }
 
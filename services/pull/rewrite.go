@@ -0,0 +1,319 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	repo_module "code.gitea.io/gitea/modules/repository"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// RewriteAction is what is done with a commit when the head branch of a pull request is rewritten
+type RewriteAction string
+
+// enumerates all rewrite actions, they behave like the commands of an interactive rebase
+const (
+	RewriteActionPick   RewriteAction = "pick"
+	RewriteActionReword RewriteAction = "reword"
+	RewriteActionSquash RewriteAction = "squash"
+	RewriteActionDrop   RewriteAction = "drop"
+)
+
+// IsValid returns true if the action is known
+func (a RewriteAction) IsValid() bool {
+	switch a {
+	case RewriteActionPick, RewriteActionReword, RewriteActionSquash, RewriteActionDrop:
+		return true
+	}
+	return false
+}
+
+// RewriteStep applies an action to a commit of the pull request, the order of the steps is the new order of the commits
+type RewriteStep struct {
+	CommitID string
+	Action   RewriteAction
+	// Message is the new message of a reworded commit, or of a commit squashed into the previous one.
+	// The messages of both commits are joined if it is empty.
+	Message string
+}
+
+// ErrInvalidRewrite represents an error if the steps of a rewrite don't fit the commits of the pull request
+type ErrInvalidRewrite struct {
+	Reason string
+}
+
+// IsErrInvalidRewrite checks if an error is a ErrInvalidRewrite.
+func IsErrInvalidRewrite(err error) bool {
+	_, ok := err.(ErrInvalidRewrite)
+	return ok
+}
+
+func (err ErrInvalidRewrite) Error() string {
+	return fmt.Sprintf("invalid rewrite: %s", err.Reason)
+}
+
+// ErrRewriteConflict represents an error if a commit doesn't apply in its new place
+type ErrRewriteConflict struct {
+	CommitID string
+}
+
+// IsErrRewriteConflict checks if an error is a ErrRewriteConflict.
+func IsErrRewriteConflict(err error) bool {
+	_, ok := err.(ErrRewriteConflict)
+	return ok
+}
+
+func (err ErrRewriteConflict) Error() string {
+	return fmt.Sprintf("commit %s does not apply", err.CommitID)
+}
+
+// IsUserAllowedToRewrite checks if the user may rewrite the head branch of the pull request.
+// Rewriting needs a force push, so it's not allowed for protected branches.
+func IsUserAllowedToRewrite(ctx context.Context, pr *models.PullRequest, user *user_model.User) (bool, error) {
+	if user == nil || pr.Flow == models.PullRequestFlowAGit || pr.HasMerged {
+		return false, nil
+	}
+	if err := pr.LoadHeadRepoCtx(ctx); err != nil {
+		return false, err
+	} else if pr.HeadRepo == nil {
+		return false, nil
+	}
+	if err := pr.LoadBaseRepoCtx(ctx); err != nil {
+		return false, err
+	}
+
+	protectedBranch, err := models.GetEffectiveProtectedBranch(ctx, pr.HeadRepoID, pr.HeadBranch)
+	if err != nil {
+		return false, err
+	} else if protectedBranch != nil {
+		return false, nil
+	}
+
+	headRepoPerm, err := access_model.GetUserRepoPermission(ctx, pr.HeadRepo, user)
+	if err != nil {
+		return false, err
+	}
+	if headRepoPerm.CanWrite(unit.TypeCode) {
+		return true, nil
+	}
+
+	if pr.AllowMaintainerEdit {
+		baseRepoPerm, err := access_model.GetUserRepoPermission(ctx, pr.BaseRepo, user)
+		if err != nil {
+			return false, err
+		}
+		return baseRepoPerm.CanWrite(unit.TypeCode), nil
+	}
+	return false, nil
+}
+
+// Rewrite rewrites the head branch of a pull request like an interactive rebase onto the merge base:
+// every commit of the pull request has to be listed in the steps exactly once, in the new order.
+// headCommitID is the head the steps were made for, the rewrite is refused if the branch has moved since.
+// The rewritten branch is force pushed and the new head commit id is returned.
+func Rewrite(ctx context.Context, pr *models.PullRequest, doer *user_model.User, headCommitID string, steps []*RewriteStep) (string, error) {
+	pullWorkingPool.CheckIn(fmt.Sprint(pr.ID))
+	defer pullWorkingPool.CheckOut(fmt.Sprint(pr.ID))
+
+	if pr.Flow == models.PullRequestFlowAGit {
+		return "", ErrInvalidRewrite{Reason: "the head branch of an agit pull request can't be rewritten"}
+	}
+	if pr.HasMerged {
+		return "", ErrInvalidRewrite{Reason: "the pull request has already been merged"}
+	}
+
+	tmpBasePath, err := createTemporaryRepo(ctx, pr)
+	if err != nil {
+		log.Error("CreateTemporaryRepo: %v", err)
+		return "", err
+	}
+	defer func() {
+		if err := repo_module.RemoveTemporaryPath(tmpBasePath); err != nil {
+			log.Error("Rewrite: RemoveTemporaryPath: %s", err)
+		}
+	}()
+
+	runGit := func(env []string, args ...string) (string, error) {
+		stdout, stderr, err := git.NewCommand(ctx, args...).RunStdString(&git.RunOpts{Dir: tmpBasePath, Env: env})
+		if err != nil {
+			return "", fmt.Errorf("git %s: %v\n%s", args[0], err, stderr)
+		}
+		return strings.TrimSpace(stdout), nil
+	}
+
+	trackingCommitID, err := runGit(nil, "rev-parse", "tracking")
+	if err != nil {
+		return "", err
+	}
+	if headCommitID != trackingCommitID {
+		return "", ErrInvalidRewrite{Reason: "the head branch has changed"}
+	}
+
+	mergeBase, err := runGit(nil, "merge-base", "base", "tracking")
+	if err != nil {
+		return "", err
+	}
+	revs, err := runGit(nil, "rev-list", "--reverse", "--parents", mergeBase+"..tracking")
+	if err != nil {
+		return "", err
+	}
+
+	commits := make(map[string]bool)
+	for _, line := range strings.Split(revs, "\n") {
+		if line == "" {
+			continue
+		}
+		fields := strings.Fields(line)
+		if len(fields) > 2 {
+			return "", ErrInvalidRewrite{Reason: "pull requests with merge commits can't be rewritten"}
+		}
+		commits[fields[0]] = true
+	}
+
+	if err := validateRewriteSteps(commits, steps); err != nil {
+		return "", err
+	}
+
+	sig := doer.NewGitSig()
+	commitTimeStr := time.Now().Format(time.RFC3339)
+	env := append(os.Environ(),
+		"GIT_COMMITTER_NAME="+sig.Name,
+		"GIT_COMMITTER_EMAIL="+sig.Email,
+		"GIT_COMMITTER_DATE="+commitTimeStr,
+	)
+
+	if _, err := runGit(nil, "checkout", "-q", "-b", "rewrite", mergeBase); err != nil {
+		return "", err
+	}
+	for _, step := range steps {
+		if step.Action == RewriteActionDrop {
+			continue
+		}
+
+		var message string
+		if step.Action == RewriteActionSquash && step.Message == "" {
+			previous, err := runGit(nil, "log", "-1", "--format=%B", "HEAD")
+			if err != nil {
+				return "", err
+			}
+			squashed, err := runGit(nil, "log", "-1", "--format=%B", step.CommitID)
+			if err != nil {
+				return "", err
+			}
+			message = previous + "\n\n" + squashed
+		} else {
+			message = step.Message
+		}
+
+		args := []string{"cherry-pick", "--allow-empty", "--keep-redundant-commits"}
+		if step.Action == RewriteActionSquash {
+			args = append(args, "--no-commit")
+		}
+		if _, err := runGit(env, append(args, step.CommitID)...); err != nil {
+			log.Debug("Rewrite of %-v: %v", pr, err)
+			return "", ErrRewriteConflict{CommitID: step.CommitID}
+		}
+
+		if step.Action == RewriteActionReword || step.Action == RewriteActionSquash {
+			if _, err := runGit(env, "commit", "--amend", "--allow-empty", "-m", message); err != nil {
+				return "", err
+			}
+		}
+	}
+
+	newHeadCommitID, err := runGit(nil, "rev-parse", "HEAD")
+	if err != nil {
+		return "", err
+	}
+
+	if setting.LFS.StartServer {
+		if err := LFSPush(ctx, tmpBasePath, newHeadCommitID, mergeBase, pr); err != nil {
+			return "", err
+		}
+	}
+
+	if err := pr.HeadRepo.GetOwner(ctx); err != nil {
+		return "", err
+	}
+	pushEnv := repo_module.FullPushingEnvironment(pr.HeadRepo.Owner, doer, pr.HeadRepo, pr.HeadRepo.Name, pr.ID)
+
+	// only replace the branch if it still is at the head the rewrite is based on,
+	// the post-receive hook updates the pull request
+	var outbuf, errbuf strings.Builder
+	if err := git.NewCommand(ctx, "push", "--force-with-lease="+git.BranchPrefix+pr.HeadBranch+":"+headCommitID,
+		"head_repo", "rewrite:"+git.BranchPrefix+pr.HeadBranch).
+		Run(&git.RunOpts{
+			Env:    pushEnv,
+			Dir:    tmpBasePath,
+			Stdout: &outbuf,
+			Stderr: &errbuf,
+		}); err != nil {
+		if strings.Contains(errbuf.String(), "stale info") {
+			return "", ErrInvalidRewrite{Reason: "the head branch has changed"}
+		} else if strings.Contains(errbuf.String(), "! [remote rejected]") {
+			err := &git.ErrPushRejected{
+				StdOut: outbuf.String(),
+				StdErr: errbuf.String(),
+				Err:    err,
+			}
+			err.GenerateMessage()
+			return "", err
+		}
+		return "", fmt.Errorf("git push: %s", errbuf.String())
+	}
+
+	return newHeadCommitID, nil
+}
+
+// validateRewriteSteps checks that the steps list every commit exactly once and keep at least one commit
+func validateRewriteSteps(commits map[string]bool, steps []*RewriteStep) error {
+	if len(steps) != len(commits) {
+		return ErrInvalidRewrite{Reason: fmt.Sprintf("the pull request has %d commits but %d steps are given", len(commits), len(steps))}
+	}
+
+	seen := make(map[string]bool, len(steps))
+	kept := false
+	for _, step := range steps {
+		if !step.Action.IsValid() {
+			return ErrInvalidRewrite{Reason: fmt.Sprintf("unknown action %q", step.Action)}
+		}
+		if !commits[step.CommitID] {
+			return ErrInvalidRewrite{Reason: fmt.Sprintf("commit %s is not part of the pull request", step.CommitID)}
+		}
+		if seen[step.CommitID] {
+			return ErrInvalidRewrite{Reason: fmt.Sprintf("commit %s is listed more than once", step.CommitID)}
+		}
+		seen[step.CommitID] = true
+
+		switch step.Action {
+		case RewriteActionSquash:
+			if !kept {
+				return ErrInvalidRewrite{Reason: fmt.Sprintf("commit %s has no previous commit to be squashed into", step.CommitID)}
+			}
+		case RewriteActionReword:
+			if strings.TrimSpace(step.Message) == "" {
+				return ErrInvalidRewrite{Reason: fmt.Sprintf("commit %s needs a message to be reworded", step.CommitID)}
+			}
+			kept = true
+		case RewriteActionPick:
+			kept = true
+		}
+	}
+	if !kept {
+		return ErrInvalidRewrite{Reason: "at least one commit has to be kept"}
+	}
+	return nil
+}
@@ -0,0 +1,72 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package pull
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateRewriteSteps(t *testing.T) {
+	commits := map[string]bool{"a": true, "b": true, "c": true}
+
+	valid := func(steps ...*RewriteStep) bool {
+		err := validateRewriteSteps(commits, steps)
+		assert.True(t, err == nil || IsErrInvalidRewrite(err), "unexpected error %v", err)
+		return err == nil
+	}
+
+	assert.True(t, valid(
+		&RewriteStep{CommitID: "c", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "a", Action: RewriteActionSquash},
+		&RewriteStep{CommitID: "b", Action: RewriteActionDrop},
+	))
+	assert.True(t, valid(
+		&RewriteStep{CommitID: "a", Action: RewriteActionReword, Message: "new message"},
+		&RewriteStep{CommitID: "b", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "c", Action: RewriteActionPick},
+	))
+
+	// every commit has to be listed exactly once
+	assert.False(t, valid(
+		&RewriteStep{CommitID: "a", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "b", Action: RewriteActionPick},
+	))
+	assert.False(t, valid(
+		&RewriteStep{CommitID: "a", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "a", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "c", Action: RewriteActionPick},
+	))
+	assert.False(t, valid(
+		&RewriteStep{CommitID: "a", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "b", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "d", Action: RewriteActionPick},
+	))
+
+	// a squashed commit needs a previous commit
+	assert.False(t, valid(
+		&RewriteStep{CommitID: "a", Action: RewriteActionDrop},
+		&RewriteStep{CommitID: "b", Action: RewriteActionSquash},
+		&RewriteStep{CommitID: "c", Action: RewriteActionPick},
+	))
+	// a reworded commit needs a message
+	assert.False(t, valid(
+		&RewriteStep{CommitID: "a", Action: RewriteActionReword},
+		&RewriteStep{CommitID: "b", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "c", Action: RewriteActionPick},
+	))
+	// at least one commit is kept
+	assert.False(t, valid(
+		&RewriteStep{CommitID: "a", Action: RewriteActionDrop},
+		&RewriteStep{CommitID: "b", Action: RewriteActionDrop},
+		&RewriteStep{CommitID: "c", Action: RewriteActionDrop},
+	))
+	assert.False(t, valid(
+		&RewriteStep{CommitID: "a", Action: "edit"},
+		&RewriteStep{CommitID: "b", Action: RewriteActionPick},
+		&RewriteStep{CommitID: "c", Action: RewriteActionPick},
+	))
+}
@@ -0,0 +1,132 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	packages_model "code.gitea.io/gitea/models/packages"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/base"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrQuotaExceeded represents a "QuotaExceeded" kind of error.
+type ErrQuotaExceeded struct {
+	OwnerID    int64
+	Used       int64
+	Additional int64
+	MaxSize    int64
+}
+
+// IsErrQuotaExceeded checks if an error is a ErrQuotaExceeded.
+func IsErrQuotaExceeded(err error) bool {
+	_, ok := err.(ErrQuotaExceeded)
+	return ok
+}
+
+func (err ErrQuotaExceeded) Error() string {
+	return fmt.Sprintf("quota exceeded: %s used of %s, %s more would be needed",
+		base.FileSize(err.Used), base.FileSize(err.MaxSize), base.FileSize(err.Additional))
+}
+
+// Usage is the disk space used by an owner in bytes
+type Usage struct {
+	Git         int64
+	LFS         int64
+	Attachments int64
+	Packages    int64
+}
+
+// Total returns the disk space used by the owner in bytes
+func (u *Usage) Total() int64 {
+	return u.Git + u.LFS + u.Attachments + u.Packages
+}
+
+// GetUsage calculates the disk space used by the repositories, LFS objects, attachments and packages of the owner
+func GetUsage(ctx context.Context, ownerID int64) (*Usage, error) {
+	repoSize, err := repo_model.SumOwnerRepoSize(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	u := &Usage{}
+	if u.LFS, err = models.SumOwnerLFSSize(ctx, ownerID); err != nil {
+		return nil, err
+	}
+	// the size of a repository includes its LFS objects
+	if u.Git = repoSize - u.LFS; u.Git < 0 {
+		u.Git = 0
+	}
+	if u.Attachments, err = repo_model.SumOwnerAttachmentSize(ctx, ownerID); err != nil {
+		return nil, err
+	}
+	if u.Packages, err = packages_model.CalculateOwnerBlobSize(ctx, ownerID); err != nil {
+		return nil, err
+	}
+	return u, nil
+}
+
+// GetMaxSize returns the quota of the owner in bytes, 0 if the disk space of the owner is unlimited
+func GetMaxSize(ctx context.Context, ownerID int64) (int64, error) {
+	q, err := user_model.GetQuotaByOwnerID(ctx, ownerID)
+	if err != nil {
+		return 0, err
+	}
+	if q == nil {
+		return setting.Quota.DefaultMaxSize, nil
+	}
+	return q.MaxSize, nil
+}
+
+// Status is the disk space used by an owner and their quota
+type Status struct {
+	Usage   *Usage
+	MaxSize int64
+}
+
+// IsLimited returns true if the disk space of the owner is limited
+func (s *Status) IsLimited() bool {
+	return s.MaxSize > 0
+}
+
+// Percent returns the part of the quota which is used in percent, it can be more than 100
+func (s *Status) Percent() int64 {
+	if s.MaxSize <= 0 {
+		return 0
+	}
+	return s.Usage.Total() * 100 / s.MaxSize
+}
+
+// GetStatus returns the disk space used by the owner and their quota
+func GetStatus(ctx context.Context, ownerID int64) (*Status, error) {
+	maxSize, err := GetMaxSize(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := GetUsage(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return &Status{Usage: usage, MaxSize: maxSize}, nil
+}
+
+// CheckQuota returns ErrQuotaExceeded if storing additional bytes exceeds the quota of the owner
+func CheckQuota(ctx context.Context, ownerID, additional int64) error {
+	maxSize, err := GetMaxSize(ctx, ownerID)
+	if err != nil || maxSize <= 0 {
+		return err
+	}
+	usage, err := GetUsage(ctx, ownerID)
+	if err != nil {
+		return err
+	}
+	if used := usage.Total(); used+additional > maxSize {
+		return ErrQuotaExceeded{OwnerID: ownerID, Used: used, Additional: additional, MaxSize: maxSize}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package quota
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckQuota(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	_, err := db.GetEngine(db.DefaultContext).Exec("UPDATE repository SET size = 0 WHERE owner_id = ?", repo.OwnerID)
+	assert.NoError(t, err)
+	repo.Size = 1000
+	assert.NoError(t, repo_model.UpdateRepositoryCols(db.DefaultContext, repo, "size"))
+
+	status, err := GetStatus(db.DefaultContext, repo.OwnerID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1000, status.Usage.Git)
+	assert.EqualValues(t, 1000, status.Usage.Total())
+	assert.False(t, status.IsLimited())
+
+	// unlimited by default
+	assert.NoError(t, CheckQuota(db.DefaultContext, repo.OwnerID, 1<<30))
+
+	defer func(old int64) {
+		setting.Quota.DefaultMaxSize = old
+	}(setting.Quota.DefaultMaxSize)
+	setting.Quota.DefaultMaxSize = 1500
+
+	assert.NoError(t, CheckQuota(db.DefaultContext, repo.OwnerID, 500))
+	err = CheckQuota(db.DefaultContext, repo.OwnerID, 501)
+	assert.True(t, IsErrQuotaExceeded(err))
+
+	// the quota of the owner overrides the default quota
+	assert.NoError(t, user_model.SetQuota(db.DefaultContext, repo.OwnerID, 2000))
+	assert.NoError(t, CheckQuota(db.DefaultContext, repo.OwnerID, 1000))
+	status, err = GetStatus(db.DefaultContext, repo.OwnerID)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 50, status.Percent())
+
+	assert.NoError(t, user_model.SetQuota(db.DefaultContext, repo.OwnerID, 0))
+	assert.NoError(t, CheckQuota(db.DefaultContext, repo.OwnerID, 1<<30))
+
+	assert.NoError(t, user_model.DeleteQuotaByOwnerID(db.DefaultContext, repo.OwnerID))
+	assert.True(t, IsErrQuotaExceeded(CheckQuota(db.DefaultContext, repo.OwnerID, 501)))
+}
@@ -0,0 +1,102 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"archive/zip"
+	"context"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// AssetsArchiveName returns the name of the zip file combining the assets of the release
+func AssetsArchiveName(rel *models.Release) string {
+	return rel.Repo.Name + "-" + strings.ReplaceAll(rel.TagName, "/", "-") + "-assets.zip"
+}
+
+// sourceArchiveName returns the name of a source archive of the release as it is downloaded
+func sourceArchiveName(rel *models.Release, format git.ArchiveType) string {
+	return rel.Repo.Name + "-" + strings.ReplaceAll(rel.TagName, "/", "-") + "." + format.String()
+}
+
+// WriteAssetsArchive streams a zip file combining the uploaded assets of the release and,
+// if withSource is set, the source archives of its tag to the writer.
+// The entries are stored without compression as they are copied, so memory usage doesn't depend on their size.
+func WriteAssetsArchive(ctx context.Context, gitRepo *git.Repository, rel *models.Release, withSource bool, w io.Writer) error {
+	if err := models.GetReleaseAttachments(ctx, rel); err != nil {
+		return fmt.Errorf("GetReleaseAttachments: %v", err)
+	}
+
+	zw := zip.NewWriter(w)
+	names := make(map[string]bool, len(rel.Attachments)+2)
+
+	for _, attach := range rel.Attachments {
+		if attach.IsExternal() {
+			// external files are linked, not stored by Gitea
+			continue
+		}
+		fw, err := zw.CreateHeader(&zip.FileHeader{
+			Name:     uniqueEntryName(names, attach.Name),
+			Method:   zip.Store,
+			Modified: attach.CreatedUnix.AsTime(),
+		})
+		if err != nil {
+			return err
+		}
+		if err := copyAttachment(fw, attach); err != nil {
+			return fmt.Errorf("copy attachment %s: %v", attach.UUID, err)
+		}
+		if err := attach.IncreaseDownloadCount(); err != nil {
+			log.Error("IncreaseDownloadCount: %v", err)
+		}
+	}
+
+	if withSource && !rel.IsDraft {
+		for _, format := range []git.ArchiveType{git.ZIP, git.TARGZ} {
+			fw, err := zw.CreateHeader(&zip.FileHeader{
+				Name:     uniqueEntryName(names, sourceArchiveName(rel, format)),
+				Method:   zip.Store,
+				Modified: rel.CreatedUnix.AsTime(),
+			})
+			if err != nil {
+				return err
+			}
+			if err := gitRepo.CreateArchive(ctx, format, fw, true, rel.Sha1); err != nil {
+				return fmt.Errorf("CreateArchive: %v", err)
+			}
+		}
+	}
+
+	return zw.Close()
+}
+
+func copyAttachment(w io.Writer, attach *repo_model.Attachment) error {
+	fr, err := attach.Storage().Open(attach.RelativePath())
+	if err != nil {
+		return err
+	}
+	defer fr.Close()
+
+	_, err = io.Copy(w, fr)
+	return err
+}
+
+// uniqueEntryName returns the name, numbered if an entry with the same name already exists
+func uniqueEntryName(names map[string]bool, name string) string {
+	unique := name
+	ext := path.Ext(name)
+	for i := 1; names[unique]; i++ {
+		unique = fmt.Sprintf("%s (%d)%s", strings.TrimSuffix(name, ext), i, ext)
+	}
+	names[unique] = true
+	return unique
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"archive/zip"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/services/attachment"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWriteAssetsArchive(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+
+	gitRepo, err := git.OpenRepository(git.DefaultContext, repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	uuids := make([]string, 0, 2)
+	for _, content := range []string{"first", "second"} {
+		attach, err := attachment.NewAttachment(&repo_model.Attachment{
+			RepoID:     repo.ID,
+			UploaderID: user.ID,
+			Name:       "test.txt",
+		}, strings.NewReader(content))
+		assert.NoError(t, err)
+		uuids = append(uuids, attach.UUID)
+	}
+
+	release := &models.Release{
+		RepoID:      repo.ID,
+		Repo:        repo,
+		PublisherID: user.ID,
+		Publisher:   user,
+		TagName:     "v0.2.0",
+		Target:      "65f1bf2",
+		Title:       "v0.2.0 is released",
+	}
+	assert.NoError(t, CreateRelease(gitRepo, release, uuids, "", false))
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteAssetsArchive(db.DefaultContext, gitRepo, release, true, &buf))
+	assert.Equal(t, "repo1-v0.2.0-assets.zip", AssetsArchiveName(release))
+
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	entries := make(map[string]string, len(zr.File))
+	for _, f := range zr.File {
+		rc, err := f.Open()
+		assert.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		assert.NoError(t, err)
+		rc.Close()
+		entries[f.Name] = string(content)
+	}
+	assert.Len(t, entries, 4)
+	// assets with the same name are numbered
+	assert.ElementsMatch(t, []string{"first", "second"}, []string{entries["test.txt"], entries["test (1).txt"]})
+	assert.Contains(t, entries, "repo1-v0.2.0.zip")
+	assert.NotEmpty(t, entries["repo1-v0.2.0.tar.gz"])
+
+	// without access to the code only the uploaded assets are included
+	buf.Reset()
+	assert.NoError(t, WriteAssetsArchive(db.DefaultContext, gitRepo, release, false, &buf))
+	zr, err = zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	assert.NoError(t, err)
+	assert.Len(t, zr.File, 2)
+}
@@ -18,11 +18,11 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/repository"
-	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/timeutil"
+	asymkey_service "code.gitea.io/gitea/services/asymkey"
 )
 
-func createTag(gitRepo *git.Repository, rel *models.Release, msg string) (bool, error) {
+func createTag(gitRepo *git.Repository, rel *models.Release, msg string, sign bool) (bool, error) {
 	var created bool
 	// Only actual create when publish.
 	if !rel.IsDraft {
@@ -53,7 +53,24 @@ func createTag(gitRepo *git.Repository, rel *models.Release, msg string) (bool,
 
 			// Trim '--' prefix to prevent command line argument vulnerability.
 			rel.TagName = strings.TrimPrefix(rel.TagName, "--")
-			if len(msg) > 0 {
+			if sign {
+				_, keyID, tagger, err := asymkey_service.SignTag(gitRepo.Ctx, gitRepo.Path)
+				if err != nil {
+					return false, err
+				}
+				// a signed tag is always annotated
+				if len(msg) == 0 {
+					msg = rel.TagName
+				}
+				if err = gitRepo.CreateSignedTag(rel.TagName, msg, commit.ID.String(), keyID, tagger); err != nil {
+					if strings.Contains(err.Error(), "is not a valid tag name") {
+						return false, models.ErrInvalidTagName{
+							TagName: rel.TagName,
+						}
+					}
+					return false, err
+				}
+			} else if len(msg) > 0 {
 				if err = gitRepo.CreateAnnotatedTag(rel.TagName, msg, commit.ID.String()); err != nil {
 					if strings.Contains(err.Error(), "is not a valid tag name") {
 						return false, models.ErrInvalidTagName{
@@ -111,7 +128,8 @@ func createTag(gitRepo *git.Repository, rel *models.Release, msg string) (bool,
 }
 
 // CreateRelease creates a new release of repository.
-func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs []string, msg string) error {
+// If sign is true, the tag is signed with the key of the instance.
+func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs []string, msg string, sign bool) error {
 	isExist, err := models.IsReleaseExist(rel.RepoID, rel.TagName)
 	if err != nil {
 		return err
@@ -121,7 +139,7 @@ func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs
 		}
 	}
 
-	if _, err = createTag(gitRepo, rel, msg); err != nil {
+	if _, err = createTag(gitRepo, rel, msg, sign); err != nil {
 		return err
 	}
 
@@ -136,13 +154,14 @@ func CreateRelease(gitRepo *git.Repository, rel *models.Release, attachmentUUIDs
 
 	if !rel.IsDraft {
 		notification.NotifyNewRelease(rel)
+		ScheduleSigning(rel.ID)
 	}
 
 	return nil
 }
 
-// CreateNewTag creates a new repository tag
-func CreateNewTag(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, commit, tagName, msg string) error {
+// CreateNewTag creates a new repository tag. If sign is true, the tag is signed with the key of the instance.
+func CreateNewTag(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, commit, tagName, msg string, sign bool) error {
 	isExist, err := models.IsReleaseExist(repo.ID, tagName)
 	if err != nil {
 		return err
@@ -170,7 +189,7 @@ func CreateNewTag(ctx context.Context, doer *user_model.User, repo *repo_model.R
 		IsTag:        true,
 	}
 
-	if _, err = createTag(gitRepo, rel, msg); err != nil {
+	if _, err = createTag(gitRepo, rel, msg, sign); err != nil {
 		return err
 	}
 
@@ -191,7 +210,7 @@ func UpdateRelease(doer *user_model.User, gitRepo *git.Repository, rel *models.R
 	if rel.ID == 0 {
 		return errors.New("UpdateRelease only accepts an exist release")
 	}
-	isCreated, err := createTag(gitRepo, rel, "")
+	isCreated, err := createTag(gitRepo, rel, "", false)
 	if err != nil {
 		return err
 	}
@@ -212,6 +231,7 @@ func UpdateRelease(doer *user_model.User, gitRepo *git.Repository, rel *models.R
 	}
 
 	deletedUUIDsMap := make(map[string]bool)
+	var delAttachments []*repo_model.Attachment
 	if len(delAttachmentUUIDs) > 0 {
 		// Check attachments
 		attachments, err := repo_model.GetAttachmentsByUUIDs(ctx, delAttachmentUUIDs)
@@ -228,6 +248,7 @@ func UpdateRelease(doer *user_model.User, gitRepo *git.Repository, rel *models.R
 		if _, err := repo_model.DeleteAttachments(ctx, attachments, false); err != nil {
 			return fmt.Errorf("DeleteAttachments [uuids: %v]: %v", delAttachmentUUIDs, err)
 		}
+		delAttachments = attachments
 	}
 
 	if len(editAttachments) > 0 {
@@ -262,16 +283,21 @@ func UpdateRelease(doer *user_model.User, gitRepo *git.Repository, rel *models.R
 		return
 	}
 
-	for _, uuid := range delAttachmentUUIDs {
-		if err := storage.Attachments.Delete(repo_model.AttachmentRelativePath(uuid)); err != nil {
+	for _, attach := range delAttachments {
+		if err := attach.Storage().Delete(attach.RelativePath()); err != nil {
 			// Even delete files failed, but the attachments has been removed from database, so we
 			// should not return error but only record the error on logs.
 			// users have to delete this attachments manually or we should have a
 			// synchronize between database attachment table and attachment storage
-			log.Error("delete attachment[uuid: %s] failed: %v", uuid, err)
+			log.Error("delete attachment[uuid: %s] failed: %v", attach.UUID, err)
 		}
 	}
 
+	// the assets or the tag may have changed
+	if !rel.IsDraft {
+		ScheduleSigning(rel.ID)
+	}
+
 	if !isCreated {
 		notification.NotifyUpdateRelease(doer, rel)
 		return
@@ -335,7 +361,7 @@ func DeleteReleaseByID(ctx context.Context, id int64, doer *user_model.User, del
 
 	for i := range rel.Attachments {
 		attachment := rel.Attachments[i]
-		if err := storage.Attachments.Delete(attachment.RelativePath()); err != nil {
+		if err := attachment.Storage().Delete(attachment.RelativePath()); err != nil {
 			log.Error("Delete attachment %s of release %s failed: %v", attachment.UUID, rel.ID, err)
 		}
 	}
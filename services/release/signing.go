@@ -0,0 +1,435 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/queue"
+	"code.gitea.io/gitea/modules/setting"
+	api "code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/services/attachment"
+
+	"github.com/keybase/go-crypto/openpgp"
+	pgp_errors "github.com/keybase/go-crypto/openpgp/errors"
+)
+
+// Names of the assets added to signed releases
+const (
+	ChecksumsAssetName  = "SHA256SUMS"
+	SignatureAssetName  = ChecksumsAssetName + ".asc"
+	ProvenanceAssetName = "provenance.json"
+)
+
+// Reasons of failed release verifications
+const (
+	VerificationNotSigned        = "not_signed"
+	VerificationUnknownKey       = "unknown_key"
+	VerificationInvalidSignature = "invalid_signature"
+	VerificationChecksumMismatch = "checksum_mismatch"
+)
+
+// maxSigningAssetSize is the maximum size of the checksums and the signature read when verifying a release
+const maxSigningAssetSize = 1 << 20
+
+// IsSigningAsset returns true if the asset is generated when signing a release
+func IsSigningAsset(name string) bool {
+	return name == ChecksumsAssetName || name == SignatureAssetName || name == ProvenanceAssetName
+}
+
+// releaseSigningQueue represents a queue of the ids of the releases to sign
+var releaseSigningQueue queue.UniqueQueue
+
+// releaseSigningPool prevents the same release from being signed concurrently
+var releaseSigningPool = sync.NewExclusivePool()
+
+// Init runs the task queue signing releases
+func Init() error {
+	releaseSigningQueue = queue.CreateUniqueQueue("release_signing", handleSigning, "")
+	if releaseSigningQueue == nil {
+		return fmt.Errorf("Unable to create release_signing Queue")
+	}
+	go graceful.GetManager().RunWithShutdownFns(releaseSigningQueue.Run)
+	return nil
+}
+
+func handleSigning(data ...queue.Data) []queue.Data {
+	for _, d := range data {
+		id, err := strconv.ParseInt(d.(string), 10, 64)
+		if err != nil {
+			log.Error("could not parse data from release_signing queue (%v): %v", d, err)
+			continue
+		}
+		if err := SignRelease(graceful.GetManager().ShutdownContext(), id); err != nil {
+			log.Error("Unable to sign release %d: %v", id, err)
+		}
+	}
+	return nil
+}
+
+// ScheduleSigning adds a release to the queue signing releases if signing is enabled,
+// drafts and releases which are only tags are left unsigned when the release is handled
+func ScheduleSigning(releaseID int64) {
+	if !setting.Repository.Release.EnableSigning || releaseSigningQueue == nil {
+		return
+	}
+	if err := releaseSigningQueue.Push(strconv.FormatInt(releaseID, 10)); err != nil && err != queue.ErrAlreadyInQueue {
+		log.Error("Unable to add release %d to the release_signing queue: %v", releaseID, err)
+	}
+}
+
+// releaseFile is a file of a release and its checksum
+type releaseFile struct {
+	Name   string
+	SHA256 string
+}
+
+// SignRelease publishes the checksums of the assets and source archives of a release, an in-toto statement
+// describing their provenance and a signature of the checksums as assets of the release.
+// Previously generated signing assets are replaced. Nothing is done if no signing key is configured.
+func SignRelease(ctx context.Context, id int64) error {
+	releaseSigningPool.CheckIn(strconv.FormatInt(id, 10))
+	defer releaseSigningPool.CheckOut(strconv.FormatInt(id, 10))
+
+	rel, err := models.GetReleaseByID(id)
+	if err != nil {
+		if models.IsErrReleaseNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	if rel.IsDraft || rel.IsTag {
+		return nil
+	}
+
+	key, err := GetSigningKey(ctx, rel.RepoID)
+	if err != nil {
+		if asymkey_model.IsErrReleaseSigningKeyNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	entity, err := signingEntity(key)
+	if err != nil {
+		return err
+	}
+
+	if err := rel.LoadAttributes(); err != nil {
+		return err
+	}
+	gitRepo, err := git.OpenRepository(ctx, rel.Repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+
+	files, err := releaseChecksums(ctx, gitRepo, rel)
+	if err != nil {
+		return err
+	}
+
+	provenance, err := json.MarshalIndent(newProvenanceStatement(rel, files), "", "  ")
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(provenance)
+	files = append(files, &releaseFile{Name: ProvenanceAssetName, SHA256: hex.EncodeToString(sum[:])})
+
+	var checksums bytes.Buffer
+	for _, f := range files {
+		fmt.Fprintf(&checksums, "%s  %s\n", f.SHA256, f.Name)
+	}
+
+	var signature bytes.Buffer
+	if err := openpgp.ArmoredDetachSign(&signature, entity, bytes.NewReader(checksums.Bytes()), nil); err != nil {
+		return fmt.Errorf("ArmoredDetachSign: %v", err)
+	}
+
+	return replaceSigningAssets(ctx, rel, map[string][]byte{
+		ChecksumsAssetName:  checksums.Bytes(),
+		SignatureAssetName:  signature.Bytes(),
+		ProvenanceAssetName: provenance,
+	})
+}
+
+// releaseChecksums returns the checksums of the assets and source archives of a release sorted by name,
+// the generated signing assets and external assets without a sha256 checksum are left out
+func releaseChecksums(ctx context.Context, gitRepo *git.Repository, rel *models.Release) ([]*releaseFile, error) {
+	if err := models.GetReleaseAttachments(ctx, rel); err != nil {
+		return nil, fmt.Errorf("GetReleaseAttachments: %v", err)
+	}
+
+	files := make([]*releaseFile, 0, len(rel.Attachments)+2)
+	for _, attach := range rel.Attachments {
+		if IsSigningAsset(attach.Name) {
+			continue
+		}
+		if attach.IsExternal() {
+			// the checksum given for an external file is trusted as Gitea doesn't have the file
+			if strings.HasPrefix(attach.Checksum, "sha256:") {
+				files = append(files, &releaseFile{Name: attach.Name, SHA256: strings.TrimPrefix(attach.Checksum, "sha256:")})
+			}
+			continue
+		}
+		h := sha256.New()
+		if err := copyAttachment(h, attach); err != nil {
+			return nil, fmt.Errorf("copy attachment %s: %v", attach.UUID, err)
+		}
+		files = append(files, &releaseFile{Name: attach.Name, SHA256: hex.EncodeToString(h.Sum(nil))})
+	}
+
+	for _, format := range []git.ArchiveType{git.ZIP, git.TARGZ} {
+		h := sha256.New()
+		// the archives are created like the archives downloaded from the release page
+		if err := gitRepo.CreateArchive(ctx, format, h, setting.Repository.PrefixArchiveFiles, rel.Sha1); err != nil {
+			return nil, fmt.Errorf("CreateArchive: %v", err)
+		}
+		files = append(files, &releaseFile{Name: sourceArchiveName(rel, format), SHA256: hex.EncodeToString(h.Sum(nil))})
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return files[i].Name < files[j].Name
+	})
+	return files, nil
+}
+
+// replaceSigningAssets adds the generated signing assets to a release and deletes the previous ones
+func replaceSigningAssets(ctx context.Context, rel *models.Release, assets map[string][]byte) error {
+	var previous []*repo_model.Attachment
+	for _, attach := range rel.Attachments {
+		if IsSigningAsset(attach.Name) && !attach.IsExternal() {
+			previous = append(previous, attach)
+		}
+	}
+
+	names := make([]string, 0, len(assets))
+	for name := range assets {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if _, err := attachment.NewAttachment(&repo_model.Attachment{
+			Name:       name,
+			RepoID:     rel.RepoID,
+			ReleaseID:  rel.ID,
+			UploaderID: rel.PublisherID,
+		}, bytes.NewReader(assets[name])); err != nil {
+			return fmt.Errorf("NewAttachment: %v", err)
+		}
+	}
+
+	if _, err := repo_model.DeleteAttachments(ctx, previous, true); err != nil {
+		return fmt.Errorf("DeleteAttachments: %v", err)
+	}
+	return nil
+}
+
+// provenanceStatement is an in-toto statement with a SLSA provenance predicate,
+// see https://github.com/in-toto/attestation and https://slsa.dev/provenance/v0.2
+type provenanceStatement struct {
+	Type          string               `json:"_type"`
+	PredicateType string               `json:"predicateType"`
+	Subject       []*provenanceSubject `json:"subject"`
+	Predicate     *provenancePredicate `json:"predicate"`
+}
+
+type provenanceSubject struct {
+	Name   string            `json:"name"`
+	Digest map[string]string `json:"digest"`
+}
+
+type provenancePredicate struct {
+	Builder struct {
+		ID string `json:"id"`
+	} `json:"builder"`
+	BuildType  string `json:"buildType"`
+	Invocation struct {
+		ConfigSource *provenanceSubject `json:"configSource"`
+	} `json:"invocation"`
+	Metadata struct {
+		BuildInvocationID string `json:"buildInvocationId"`
+		BuildStartedOn    string `json:"buildStartedOn"`
+	} `json:"metadata"`
+	Materials []*provenanceMaterial `json:"materials"`
+}
+
+type provenanceMaterial struct {
+	URI    string            `json:"uri"`
+	Digest map[string]string `json:"digest"`
+}
+
+func newProvenanceStatement(rel *models.Release, files []*releaseFile) *provenanceStatement {
+	source := "git+" + rel.Repo.HTMLURL() + "@" + git.TagPrefix + rel.TagName
+
+	statement := &provenanceStatement{
+		Type:          "https://in-toto.io/Statement/v0.1",
+		PredicateType: "https://slsa.dev/provenance/v0.2",
+		Subject:       make([]*provenanceSubject, 0, len(files)),
+		Predicate:     &provenancePredicate{},
+	}
+	for _, f := range files {
+		statement.Subject = append(statement.Subject, &provenanceSubject{
+			Name:   f.Name,
+			Digest: map[string]string{"sha256": f.SHA256},
+		})
+	}
+
+	p := statement.Predicate
+	p.Builder.ID = setting.AppURL
+	p.BuildType = setting.AppURL + "release-signing/v1"
+	p.Invocation.ConfigSource = &provenanceSubject{
+		Name:   source,
+		Digest: map[string]string{"sha1": rel.Sha1},
+	}
+	p.Metadata.BuildInvocationID = rel.HTMLURL()
+	p.Metadata.BuildStartedOn = rel.CreatedUnix.AsTime().UTC().Format(time.RFC3339)
+	p.Materials = []*provenanceMaterial{{
+		URI:    source,
+		Digest: map[string]string{"sha1": rel.Sha1},
+	}}
+	return statement
+}
+
+// VerifyRelease checks the signature of the checksums published with a release
+// and whether they match the current assets and source archives of the release
+func VerifyRelease(ctx context.Context, gitRepo *git.Repository, rel *models.Release) (*api.ReleaseVerification, error) {
+	if err := models.GetReleaseAttachments(ctx, rel); err != nil {
+		return nil, fmt.Errorf("GetReleaseAttachments: %v", err)
+	}
+
+	var checksumsAsset, signatureAsset, provenanceAsset *repo_model.Attachment
+	for _, attach := range rel.Attachments {
+		if attach.IsExternal() {
+			continue
+		}
+		switch attach.Name {
+		case ChecksumsAssetName:
+			checksumsAsset = attach
+		case SignatureAssetName:
+			signatureAsset = attach
+		case ProvenanceAssetName:
+			provenanceAsset = attach
+		}
+	}
+	if checksumsAsset == nil || signatureAsset == nil {
+		return &api.ReleaseVerification{
+			Reason: VerificationNotSigned,
+			Files:  []*api.ReleaseVerificationFile{},
+		}, nil
+	}
+
+	files, err := releaseChecksums(ctx, gitRepo, rel)
+	if err != nil {
+		return nil, err
+	}
+	if provenanceAsset != nil {
+		h := sha256.New()
+		if err := copyAttachment(h, provenanceAsset); err != nil {
+			return nil, fmt.Errorf("copy attachment %s: %v", provenanceAsset.UUID, err)
+		}
+		files = append(files, &releaseFile{Name: provenanceAsset.Name, SHA256: hex.EncodeToString(h.Sum(nil))})
+	}
+
+	verification := &api.ReleaseVerification{
+		Files: make([]*api.ReleaseVerificationFile, 0, len(files)),
+	}
+	for _, f := range files {
+		verification.Files = append(verification.Files, &api.ReleaseVerificationFile{Name: f.Name})
+	}
+
+	checksums, err := readAttachment(checksumsAsset)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := readAttachment(signatureAsset)
+	if err != nil {
+		return nil, err
+	}
+
+	keyRing, err := verificationKeyRing(ctx, rel.RepoID)
+	if err != nil {
+		return nil, err
+	}
+	signer, err := openpgp.CheckArmoredDetachedSignature(keyRing, bytes.NewReader(checksums), bytes.NewReader(signature))
+	if err != nil {
+		if err == pgp_errors.ErrUnknownIssuer {
+			verification.Reason = VerificationUnknownKey
+		} else {
+			verification.Reason = VerificationInvalidSignature
+		}
+		return verification, nil
+	}
+	verification.KeyID = fmt.Sprintf("%016X", signer.PrimaryKey.KeyId)
+	verification.Fingerprint = fmt.Sprintf("%X", signer.PrimaryKey.Fingerprint)
+
+	signed := parseChecksums(checksums)
+	verified := len(signed) == len(files)
+	for i, f := range files {
+		verification.Files[i].SHA256 = signed[f.Name]
+		verification.Files[i].Verified = signed[f.Name] == f.SHA256
+		verified = verified && verification.Files[i].Verified
+	}
+	verification.Verified = verified
+	if !verified {
+		verification.Reason = VerificationChecksumMismatch
+	}
+	return verification, nil
+}
+
+func readAttachment(attach *repo_model.Attachment) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := copyAttachment(&limitedWriter{w: &buf, n: maxSigningAssetSize}, attach); err != nil {
+		return nil, fmt.Errorf("copy attachment %s: %v", attach.UUID, err)
+	}
+	return buf.Bytes(), nil
+}
+
+// limitedWriter fails writes exceeding the limit
+type limitedWriter struct {
+	w io.Writer
+	n int64
+}
+
+func (l *limitedWriter) Write(p []byte) (int, error) {
+	if int64(len(p)) > l.n {
+		return 0, fmt.Errorf("file exceeds %d bytes", maxSigningAssetSize)
+	}
+	l.n -= int64(len(p))
+	return l.w.Write(p)
+}
+
+// parseChecksums parses the output of sha256sum, which lists a checksum and a file name per line
+func parseChecksums(data []byte) map[string]string {
+	checksums := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := scanner.Text()
+		idx := strings.IndexByte(line, ' ')
+		if idx < 0 || len(line) < idx+2 {
+			continue
+		}
+		// binary mode is marked by a star in front of the name
+		checksums[strings.TrimPrefix(line[idx+2:], "*")] = strings.ToLower(line[:idx])
+	}
+	return checksums
+}
@@ -0,0 +1,176 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/modules/secret"
+
+	"github.com/keybase/go-crypto/openpgp"
+	"github.com/keybase/go-crypto/openpgp/armor"
+	"github.com/keybase/go-crypto/openpgp/packet"
+)
+
+// ErrInvalidSigningKey represents a "InvalidSigningKey" kind of error.
+type ErrInvalidSigningKey struct {
+	Reason string
+}
+
+// IsErrInvalidSigningKey checks if an error is a ErrInvalidSigningKey.
+func IsErrInvalidSigningKey(err error) bool {
+	_, ok := err.(ErrInvalidSigningKey)
+	return ok
+}
+
+func (err ErrInvalidSigningKey) Error() string {
+	return fmt.Sprintf("invalid release signing key: %s", err.Reason)
+}
+
+// GenerateSigningKey generates a new release signing key for a repository, or for the instance if repoID is 0.
+// It replaces the previous key, releases which are already signed keep their signatures.
+func GenerateSigningKey(ctx context.Context, repoID int64, name, email string) (*asymkey_model.ReleaseSigningKey, error) {
+	entity, err := openpgp.NewEntity(name, "release signing key", email, &packet.Config{RSABits: 3072})
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PrivateKeyType, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := entity.SerializePrivate(w, nil); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return saveSigningKey(ctx, repoID, entity, buf.String())
+}
+
+// ImportSigningKey imports an armored OpenPGP private key as the release signing key of a repository,
+// or of the instance if repoID is 0. The key must not be protected by a passphrase.
+func ImportSigningKey(ctx context.Context, repoID int64, armored string) (*asymkey_model.ReleaseSigningKey, error) {
+	armored = strings.TrimSpace(armored)
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, ErrInvalidSigningKey{Reason: err.Error()}
+	}
+	if len(entities) != 1 {
+		return nil, ErrInvalidSigningKey{Reason: "exactly one key is required"}
+	}
+	entity := entities[0]
+	if entity.PrivateKey == nil {
+		return nil, ErrInvalidSigningKey{Reason: "the key is not a private key"}
+	}
+	// signing an empty message fails if the key can't sign or is protected by a passphrase
+	if err := openpgp.DetachSign(io.Discard, entity, strings.NewReader(""), nil); err != nil {
+		return nil, ErrInvalidSigningKey{Reason: err.Error()}
+	}
+
+	return saveSigningKey(ctx, repoID, entity, armored)
+}
+
+func saveSigningKey(ctx context.Context, repoID int64, entity *openpgp.Entity, armoredPrivateKey string) (*asymkey_model.ReleaseSigningKey, error) {
+	var buf bytes.Buffer
+	w, err := armor.Encode(&buf, openpgp.PublicKeyType, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := entity.Serialize(w); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	privateKey, err := secret.EncryptAtRest(armoredPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &asymkey_model.ReleaseSigningKey{
+		RepoID:      repoID,
+		KeyID:       fmt.Sprintf("%016X", entity.PrimaryKey.KeyId),
+		Fingerprint: fmt.Sprintf("%X", entity.PrimaryKey.Fingerprint),
+		Identity:    entityIdentity(entity),
+		PublicKey:   buf.String(),
+		PrivateKey:  privateKey,
+	}
+	return key, asymkey_model.SetReleaseSigningKey(ctx, key)
+}
+
+// entityIdentity returns the name of the primary identity of an entity
+func entityIdentity(entity *openpgp.Entity) string {
+	names := make([]string, 0, len(entity.Identities))
+	for name, identity := range entity.Identities {
+		if identity.SelfSignature != nil && identity.SelfSignature.IsPrimaryId != nil && *identity.SelfSignature.IsPrimaryId {
+			return name
+		}
+		names = append(names, name)
+	}
+	if len(names) == 0 {
+		return ""
+	}
+	sort.Strings(names)
+	return names[0]
+}
+
+// GetSigningKey returns the key signing the releases of a repository,
+// which is the key of the repository if it has one and the key of the instance otherwise
+func GetSigningKey(ctx context.Context, repoID int64) (*asymkey_model.ReleaseSigningKey, error) {
+	key, err := asymkey_model.GetReleaseSigningKey(ctx, repoID)
+	if repoID == 0 || !asymkey_model.IsErrReleaseSigningKeyNotExist(err) {
+		return key, err
+	}
+	return asymkey_model.GetReleaseSigningKey(ctx, 0)
+}
+
+// signingEntity returns the decrypted private key of a release signing key
+func signingEntity(key *asymkey_model.ReleaseSigningKey) (*openpgp.Entity, error) {
+	armored, err := secret.DecryptAtRest(key.PrivateKey)
+	if err != nil {
+		return nil, fmt.Errorf("DecryptAtRest: %v", err)
+	}
+	entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(armored))
+	if err != nil {
+		return nil, fmt.Errorf("ReadArmoredKeyRing: %v", err)
+	}
+	if len(entities) != 1 {
+		return nil, fmt.Errorf("release signing key %d contains %d keys", key.ID, len(entities))
+	}
+	return entities[0], nil
+}
+
+// verificationKeyRing returns the public keys which may have signed the releases of a repository
+func verificationKeyRing(ctx context.Context, repoID int64) (openpgp.EntityList, error) {
+	var keyRing openpgp.EntityList
+	for _, id := range []int64{repoID, 0} {
+		key, err := asymkey_model.GetReleaseSigningKey(ctx, id)
+		if err != nil {
+			if asymkey_model.IsErrReleaseSigningKeyNotExist(err) {
+				continue
+			}
+			return nil, err
+		}
+		entities, err := openpgp.ReadArmoredKeyRing(strings.NewReader(key.PublicKey))
+		if err != nil {
+			return nil, fmt.Errorf("ReadArmoredKeyRing: %v", err)
+		}
+		keyRing = append(keyRing, entities...)
+		if id == 0 {
+			break
+		}
+	}
+	return keyRing, nil
+}
@@ -0,0 +1,142 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package release
+
+import (
+	"strings"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	asymkey_model "code.gitea.io/gitea/models/asymkey"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/services/attachment"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSigningKey(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, err := GetSigningKey(db.DefaultContext, 1)
+	assert.True(t, asymkey_model.IsErrReleaseSigningKeyNotExist(err))
+
+	instanceKey, err := GenerateSigningKey(db.DefaultContext, 0, "Gitea", "gitea@example.com")
+	assert.NoError(t, err)
+	assert.Len(t, instanceKey.KeyID, 16)
+	assert.Len(t, instanceKey.Fingerprint, 40)
+	assert.Equal(t, "Gitea (release signing key) <gitea@example.com>", instanceKey.Identity)
+	assert.NotContains(t, instanceKey.PrivateKey, "PGP PRIVATE KEY")
+
+	// repositories without a key of their own use the key of the instance
+	key, err := GetSigningKey(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.True(t, key.IsInstanceKey())
+
+	armored, err := secret.DecryptAtRest(instanceKey.PrivateKey)
+	assert.NoError(t, err)
+	repoKey, err := ImportSigningKey(db.DefaultContext, 1, armored)
+	assert.NoError(t, err)
+	assert.Equal(t, instanceKey.Fingerprint, repoKey.Fingerprint)
+
+	key, err = GetSigningKey(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 1, key.RepoID)
+
+	_, err = ImportSigningKey(db.DefaultContext, 1, instanceKey.PublicKey)
+	assert.True(t, IsErrInvalidSigningKey(err))
+	_, err = ImportSigningKey(db.DefaultContext, 1, "not a key")
+	assert.True(t, IsErrInvalidSigningKey(err))
+
+	assert.NoError(t, asymkey_model.DeleteReleaseSigningKey(db.DefaultContext, 1))
+	key, err = GetSigningKey(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	assert.True(t, key.IsInstanceKey())
+}
+
+func TestSignRelease(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	_, err := GenerateSigningKey(db.DefaultContext, 1, "Gitea", "gitea@example.com")
+	assert.NoError(t, err)
+
+	// the files of the attachments of the fixtures don't exist
+	rel, err := models.GetReleaseByID(1)
+	assert.NoError(t, err)
+	assert.NoError(t, models.GetReleaseAttachments(db.DefaultContext, rel))
+	_, err = repo_model.DeleteAttachments(db.DefaultContext, rel.Attachments, false)
+	assert.NoError(t, err)
+
+	_, err = attachment.NewAttachment(&repo_model.Attachment{
+		RepoID:    1,
+		ReleaseID: 1,
+		Name:      "test.txt",
+	}, strings.NewReader("testtest"))
+	assert.NoError(t, err)
+
+	assert.NoError(t, SignRelease(db.DefaultContext, 1))
+	// signing again replaces the previous signing assets
+	assert.NoError(t, SignRelease(db.DefaultContext, 1))
+
+	rel, err = models.GetReleaseByID(1)
+	assert.NoError(t, err)
+	assert.NoError(t, rel.LoadAttributes())
+	signingAssets := 0
+	for _, attach := range rel.Attachments {
+		if IsSigningAsset(attach.Name) {
+			signingAssets++
+		}
+	}
+	assert.Equal(t, 3, signingAssets)
+
+	gitRepo, err := git.OpenRepository(git.DefaultContext, rel.Repo.RepoPath())
+	assert.NoError(t, err)
+	defer gitRepo.Close()
+
+	verification, err := VerifyRelease(db.DefaultContext, gitRepo, rel)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, verification.Verified, verification.Reason)
+	names := make([]string, 0, len(verification.Files))
+	for _, f := range verification.Files {
+		names = append(names, f.Name)
+		assert.True(t, f.Verified, f.Name)
+	}
+	assert.Contains(t, names, "test.txt")
+	assert.Contains(t, names, "repo1-v1.1.zip")
+	assert.Contains(t, names, "repo1-v1.1.tar.gz")
+	assert.Contains(t, names, ProvenanceAssetName)
+
+	// assets added after signing are not covered by the signature
+	_, err = attachment.NewAttachment(&repo_model.Attachment{
+		RepoID:    1,
+		ReleaseID: 1,
+		Name:      "unsigned.txt",
+	}, strings.NewReader("unsigned"))
+	assert.NoError(t, err)
+	verification, err = VerifyRelease(db.DefaultContext, gitRepo, rel)
+	assert.NoError(t, err)
+	assert.False(t, verification.Verified)
+	assert.Equal(t, VerificationChecksumMismatch, verification.Reason)
+
+	// the signature can't be verified once the key is deleted
+	assert.NoError(t, asymkey_model.DeleteReleaseSigningKey(db.DefaultContext, 1))
+	verification, err = VerifyRelease(db.DefaultContext, gitRepo, rel)
+	assert.NoError(t, err)
+	assert.Equal(t, VerificationUnknownKey, verification.Reason)
+}
+
+func TestParseChecksums(t *testing.T) {
+	checksums := parseChecksums([]byte("ABCDEF  file.txt\n123456 *binary.zip\ninvalid\n\n0a0b  name with spaces\n"))
+	assert.Equal(t, map[string]string{
+		"file.txt":         "abcdef",
+		"binary.zip":       "123456",
+		"name with spaces": "0a0b",
+	}, checksums)
+}
@@ -0,0 +1,186 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package reposettings
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/git"
+
+	"gopkg.in/yaml.v2"
+)
+
+// FilePath is the path of the settings file in the default branch of a repository
+const FilePath = ".gitea/settings.yaml"
+
+// ErrInvalidSettings represents a "InvalidSettings" kind of error.
+type ErrInvalidSettings struct {
+	Reason string
+}
+
+// IsErrInvalidSettings checks if an error is a ErrInvalidSettings.
+func IsErrInvalidSettings(err error) bool {
+	_, ok := err.(ErrInvalidSettings)
+	return ok
+}
+
+func (err ErrInvalidSettings) Error() string {
+	return fmt.Sprintf("invalid settings file %s: %s", FilePath, err.Reason)
+}
+
+// Config represents the settings of a repository declared in its settings file.
+// Only the sections and entries present in the file are managed, everything else is left untouched.
+type Config struct {
+	Repository        *RepositoryConfig         `yaml:"repository"`
+	Labels            []*LabelConfig            `yaml:"labels"`
+	BranchProtections []*BranchProtectionConfig `yaml:"branch_protections"`
+	Webhooks          []*WebhookConfig          `yaml:"webhooks"`
+}
+
+// RepositoryConfig represents the declared options of a repository
+type RepositoryConfig struct {
+	Description *string `yaml:"description"`
+	Website     *string `yaml:"website"`
+	Template    *bool   `yaml:"template"`
+}
+
+// LabelConfig represents a declared label, identified by its name
+type LabelConfig struct {
+	Name        string `yaml:"name"`
+	Color       string `yaml:"color"`
+	Description string `yaml:"description"`
+}
+
+// BranchProtectionConfig represents a declared branch protection, identified by its branch
+type BranchProtectionConfig struct {
+	Branch                 string   `yaml:"branch"`
+	EnablePush             bool     `yaml:"enable_push"`
+	EnableStatusCheck      bool     `yaml:"enable_status_check"`
+	StatusCheckContexts    []string `yaml:"status_check_contexts"`
+	RequiredApprovals      int64    `yaml:"required_approvals"`
+	BlockOnRejectedReviews bool     `yaml:"block_on_rejected_reviews"`
+	DismissStaleApprovals  bool     `yaml:"dismiss_stale_approvals"`
+	RequireSignedCommits   bool     `yaml:"require_signed_commits"`
+	ProtectedFilePatterns  string   `yaml:"protected_file_patterns"`
+}
+
+// WebhookConfig represents a declared webhook, identified by its url.
+// Secrets can't be declared, they have to be set in the webhook settings.
+type WebhookConfig struct {
+	URL          string   `yaml:"url"`
+	Type         string   `yaml:"type"`
+	ContentType  string   `yaml:"content_type"`
+	Events       []string `yaml:"events"`
+	BranchFilter string   `yaml:"branch_filter"`
+	Active       *bool    `yaml:"active"`
+}
+
+// Parse parses and validates the content of a settings file
+func Parse(content []byte) (*Config, error) {
+	cfg := new(Config)
+	if err := yaml.UnmarshalStrict(content, cfg); err != nil {
+		return nil, ErrInvalidSettings{Reason: err.Error()}
+	}
+
+	labels := make(map[string]bool, len(cfg.Labels))
+	for _, label := range cfg.Labels {
+		label.Name = strings.TrimSpace(label.Name)
+		if label.Name == "" {
+			return nil, ErrInvalidSettings{Reason: "label without name"}
+		} else if labels[label.Name] {
+			return nil, ErrInvalidSettings{Reason: fmt.Sprintf("duplicate label %q", label.Name)}
+		}
+		labels[label.Name] = true
+
+		color, ok := normalizeColor(label.Color)
+		if !ok {
+			return nil, ErrInvalidSettings{Reason: fmt.Sprintf("bad color code %q of label %q", label.Color, label.Name)}
+		}
+		label.Color = color
+	}
+
+	branches := make(map[string]bool, len(cfg.BranchProtections))
+	for _, bp := range cfg.BranchProtections {
+		bp.Branch = strings.TrimSpace(bp.Branch)
+		if bp.Branch == "" {
+			return nil, ErrInvalidSettings{Reason: "branch protection without branch"}
+		} else if branches[bp.Branch] {
+			return nil, ErrInvalidSettings{Reason: fmt.Sprintf("duplicate branch protection %q", bp.Branch)}
+		} else if bp.RequiredApprovals < 0 {
+			return nil, ErrInvalidSettings{Reason: fmt.Sprintf("negative required approvals of branch protection %q", bp.Branch)}
+		}
+		branches[bp.Branch] = true
+		if !bp.EnableStatusCheck {
+			bp.StatusCheckContexts = nil
+		}
+	}
+
+	urls := make(map[string]bool, len(cfg.Webhooks))
+	for _, hook := range cfg.Webhooks {
+		hook.URL = strings.TrimSpace(hook.URL)
+		if hook.URL == "" {
+			return nil, ErrInvalidSettings{Reason: "webhook without url"}
+		} else if urls[hook.URL] {
+			return nil, ErrInvalidSettings{Reason: fmt.Sprintf("duplicate webhook %q", hook.URL)}
+		}
+		urls[hook.URL] = true
+
+		if hook.Type == "" {
+			hook.Type = webhook.GITEA
+		} else if hook.Type != webhook.GITEA && hook.Type != webhook.GOGS {
+			return nil, ErrInvalidSettings{Reason: fmt.Sprintf("unsupported type %q of webhook %q", hook.Type, hook.URL)}
+		}
+		if hook.ContentType == "" {
+			hook.ContentType = "json"
+		} else if !webhook.IsValidHookContentType(hook.ContentType) {
+			return nil, ErrInvalidSettings{Reason: fmt.Sprintf("unknown content type %q of webhook %q", hook.ContentType, hook.URL)}
+		}
+		if len(hook.Events) == 0 {
+			hook.Events = []string{string(webhook.HookEventPush)}
+		}
+	}
+
+	return cfg, nil
+}
+
+// GetConfig reads the settings file of a commit, it returns nil if the commit has no settings file
+func GetConfig(commit *git.Commit) (*Config, error) {
+	entry, err := commit.GetTreeEntryByPath(FilePath)
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	r, err := entry.Blob().DataAsync()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(content)
+}
+
+// normalizeColor returns the color in the format #rrggbb used by labels
+func normalizeColor(color string) (string, bool) {
+	color = strings.ToLower(strings.TrimSpace(color))
+	if !models.LabelColorPattern.MatchString(color) {
+		return "", false
+	}
+	color = strings.TrimPrefix(color, "#")
+	if len(color) == 3 {
+		color = string([]byte{color[0], color[0], color[1], color[1], color[2], color[2]})
+	}
+	return "#" + color, true
+}
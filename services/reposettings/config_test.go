@@ -0,0 +1,60 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package reposettings
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	cfg, err := Parse([]byte(`
+repository:
+  description: my repository
+labels:
+  - name: " bug "
+    color: "#F00"
+    description: Something is not working
+branch_protections:
+  - branch: main
+    required_approvals: 1
+    status_check_contexts: [ci]
+webhooks:
+  - url: https://example.com/hook
+`))
+	assert.NoError(t, err)
+	if assert.NotNil(t, cfg.Repository.Description) {
+		assert.EqualValues(t, "my repository", *cfg.Repository.Description)
+	}
+	assert.Nil(t, cfg.Repository.Website)
+	if assert.Len(t, cfg.Labels, 1) {
+		assert.EqualValues(t, "bug", cfg.Labels[0].Name)
+		assert.EqualValues(t, "#ff0000", cfg.Labels[0].Color)
+	}
+	if assert.Len(t, cfg.BranchProtections, 1) {
+		assert.EqualValues(t, 1, cfg.BranchProtections[0].RequiredApprovals)
+		// contexts are only kept if status checks are enabled
+		assert.Empty(t, cfg.BranchProtections[0].StatusCheckContexts)
+	}
+	if assert.Len(t, cfg.Webhooks, 1) {
+		assert.EqualValues(t, "gitea", cfg.Webhooks[0].Type)
+		assert.EqualValues(t, "json", cfg.Webhooks[0].ContentType)
+		assert.EqualValues(t, []string{"push"}, cfg.Webhooks[0].Events)
+	}
+
+	for _, content := range []string{
+		"unknown: true",
+		"labels:\n  - name: bug\n    color: red",
+		"labels:\n  - name: bug\n    color: '#f00'\n  - name: bug\n    color: '#0f0'",
+		"branch_protections:\n  - required_approvals: 1",
+		"branch_protections:\n  - branch: main\n    required_approvals: -1",
+		"webhooks:\n  - url: https://example.com/hook\n    type: slack",
+		"webhooks:\n  - url: https://example.com/hook\n    content_type: xml",
+	} {
+		_, err := Parse([]byte(content))
+		assert.True(t, IsErrInvalidSettings(err), content)
+	}
+}
@@ -0,0 +1,384 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package reposettings
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// Section represents the part of the settings a change belongs to
+type Section string
+
+// The sections of a settings file
+const (
+	SectionRepository        Section = "repository"
+	SectionLabels            Section = "labels"
+	SectionBranchProtections Section = "branch_protections"
+	SectionWebhooks          Section = "webhooks"
+)
+
+// Action represents how a change is applied
+type Action string
+
+// The actions of a change
+const (
+	ActionCreate Action = "create"
+	ActionUpdate Action = "update"
+)
+
+// Change represents a drift between the settings file and the settings of a repository
+type Change struct {
+	Section Section
+	// Name identifies the changed entry: the option, label name, branch name or webhook url
+	Name   string
+	Action Action
+	// Err is set if the change could not be applied
+	Err error
+
+	apply func(ctx context.Context) error
+}
+
+// Drift returns the changes needed to make the settings of the repository match the configuration
+func Drift(ctx context.Context, repo *repo_model.Repository, cfg *Config) ([]*Change, error) {
+	changes := repositoryDrift(repo, cfg.Repository)
+
+	for _, drift := range []func(context.Context, *repo_model.Repository, *Config) ([]*Change, error){
+		labelsDrift,
+		branchProtectionsDrift,
+		webhooksDrift,
+	} {
+		sectionChanges, err := drift(ctx, repo, cfg)
+		if err != nil {
+			return nil, err
+		}
+		changes = append(changes, sectionChanges...)
+	}
+	return changes, nil
+}
+
+// Apply applies the changes, the errors of changes which could not be applied are recorded in them
+func Apply(ctx context.Context, changes []*Change) {
+	for _, change := range changes {
+		if change.apply == nil {
+			continue
+		}
+		if err := change.apply(ctx); err != nil {
+			change.Err = err
+		}
+	}
+}
+
+// Reconcile applies the settings file of the commit to the repository. It returns nil if the
+// commit has no settings file.
+func Reconcile(ctx context.Context, repo *repo_model.Repository, commit *git.Commit) ([]*Change, error) {
+	cfg, err := GetConfig(commit)
+	if err != nil || cfg == nil {
+		return nil, err
+	}
+
+	changes, err := Drift(ctx, repo, cfg)
+	if err != nil {
+		return nil, err
+	}
+	Apply(ctx, changes)
+	return changes, nil
+}
+
+// ReconcileOnPush applies the settings file after a push to the default branch changed it.
+// The settings are only applied if the pusher administrates the repository.
+func ReconcileOnPush(ctx context.Context, pusher *user_model.User, repo *repo_model.Repository, gitRepo *git.Repository, oldCommitID, newCommitID string) {
+	newCommit, err := gitRepo.GetCommit(newCommitID)
+	if err != nil {
+		log.Error("GetCommit %s in %-v: %v", newCommitID, repo, err)
+		return
+	}
+	newEntry, err := newCommit.GetTreeEntryByPath(FilePath)
+	if err != nil {
+		if !git.IsErrNotExist(err) {
+			log.Error("GetTreeEntryByPath %s in %-v: %v", FilePath, repo, err)
+		}
+		return
+	}
+	if oldCommitID != git.EmptySHA {
+		if oldCommit, err := gitRepo.GetCommit(oldCommitID); err == nil {
+			if oldEntry, err := oldCommit.GetTreeEntryByPath(FilePath); err == nil && oldEntry.ID == newEntry.ID {
+				return
+			}
+		}
+	}
+
+	perm, err := access_model.GetUserRepoPermission(ctx, repo, pusher)
+	if err != nil {
+		log.Error("GetUserRepoPermission: %v", err)
+		return
+	}
+	if !perm.IsAdmin() {
+		log.Warn("%s in %-v was changed by %s who is not an administrator, the settings are not applied", FilePath, repo, pusher.Name)
+		return
+	}
+
+	changes, err := Reconcile(ctx, repo, newCommit)
+	if err != nil {
+		log.Warn("Unable to apply %s of %-v: %v", FilePath, repo, err)
+		return
+	}
+	for _, change := range changes {
+		if change.Err != nil {
+			log.Warn("Unable to %s %s %q of %-v: %v", change.Action, change.Section, change.Name, repo, change.Err)
+		}
+	}
+}
+
+func repositoryDrift(repo *repo_model.Repository, cfg *RepositoryConfig) []*Change {
+	if cfg == nil {
+		return nil
+	}
+
+	changes := make([]*Change, 0, 3)
+	update := func(name string, set func()) {
+		changes = append(changes, &Change{
+			Section: SectionRepository,
+			Name:    name,
+			Action:  ActionUpdate,
+			apply: func(ctx context.Context) error {
+				set()
+				return models.UpdateRepository(repo, false)
+			},
+		})
+	}
+	if cfg.Description != nil && *cfg.Description != repo.Description {
+		update("description", func() { repo.Description = *cfg.Description })
+	}
+	if cfg.Website != nil && *cfg.Website != repo.Website {
+		update("website", func() { repo.Website = *cfg.Website })
+	}
+	if cfg.Template != nil && *cfg.Template != repo.IsTemplate {
+		update("template", func() { repo.IsTemplate = *cfg.Template })
+	}
+	return changes
+}
+
+func labelsDrift(ctx context.Context, repo *repo_model.Repository, cfg *Config) ([]*Change, error) {
+	if len(cfg.Labels) == 0 {
+		return nil, nil
+	}
+
+	labels, err := models.GetLabelsByRepoID(ctx, repo.ID, "", db.ListOptions{})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*models.Label, len(labels))
+	for _, label := range labels {
+		existing[label.Name] = label
+	}
+
+	changes := make([]*Change, 0, len(cfg.Labels))
+	for _, declared := range cfg.Labels {
+		declared := declared
+		label, ok := existing[declared.Name]
+		if !ok {
+			changes = append(changes, &Change{
+				Section: SectionLabels,
+				Name:    declared.Name,
+				Action:  ActionCreate,
+				apply: func(ctx context.Context) error {
+					return models.NewLabel(ctx, &models.Label{
+						RepoID:      repo.ID,
+						Name:        declared.Name,
+						Color:       declared.Color,
+						Description: declared.Description,
+					})
+				},
+			})
+		} else if label.Color != declared.Color || label.Description != declared.Description {
+			changes = append(changes, &Change{
+				Section: SectionLabels,
+				Name:    declared.Name,
+				Action:  ActionUpdate,
+				apply: func(ctx context.Context) error {
+					label.Color = declared.Color
+					label.Description = declared.Description
+					return models.UpdateLabel(label)
+				},
+			})
+		}
+	}
+	return changes, nil
+}
+
+func branchProtectionsDrift(ctx context.Context, repo *repo_model.Repository, cfg *Config) ([]*Change, error) {
+	changes := make([]*Change, 0, len(cfg.BranchProtections))
+	for _, declared := range cfg.BranchProtections {
+		declared := declared
+		protectBranch, err := models.GetProtectedBranchBy(ctx, repo.ID, declared.Branch)
+		if err != nil {
+			return nil, err
+		}
+
+		action := ActionUpdate
+		if protectBranch == nil {
+			action = ActionCreate
+			protectBranch = &models.ProtectedBranch{RepoID: repo.ID, BranchName: declared.Branch}
+		} else if protectBranch.CanPush == declared.EnablePush &&
+			(!protectBranch.CanPush || !protectBranch.EnableWhitelist) &&
+			protectBranch.EnableStatusCheck == declared.EnableStatusCheck &&
+			util.IsEqualSlice(protectBranch.StatusCheckContexts, declared.StatusCheckContexts) &&
+			protectBranch.RequiredApprovals == declared.RequiredApprovals &&
+			protectBranch.BlockOnRejectedReviews == declared.BlockOnRejectedReviews &&
+			protectBranch.DismissStaleApprovals == declared.DismissStaleApprovals &&
+			protectBranch.RequireSignedCommits == declared.RequireSignedCommits &&
+			protectBranch.ProtectedFilePatterns == declared.ProtectedFilePatterns {
+			continue
+		}
+
+		changes = append(changes, &Change{
+			Section: SectionBranchProtections,
+			Name:    declared.Branch,
+			Action:  action,
+			apply: func(ctx context.Context) error {
+				orgRule, err := models.GetOrgProtectedBranchFor(ctx, repo, declared.Branch)
+				if err != nil {
+					return err
+				} else if orgRule != nil && !orgRule.AllowOverride {
+					return fmt.Errorf("branch protection is enforced by the organization")
+				}
+
+				protectBranch.CanPush = declared.EnablePush
+				protectBranch.EnableWhitelist = false
+				protectBranch.WhitelistDeployKeys = false
+				protectBranch.EnableStatusCheck = declared.EnableStatusCheck
+				protectBranch.StatusCheckContexts = declared.StatusCheckContexts
+				protectBranch.RequiredApprovals = declared.RequiredApprovals
+				protectBranch.BlockOnRejectedReviews = declared.BlockOnRejectedReviews
+				protectBranch.DismissStaleApprovals = declared.DismissStaleApprovals
+				protectBranch.RequireSignedCommits = declared.RequireSignedCommits
+				protectBranch.ProtectedFilePatterns = declared.ProtectedFilePatterns
+				// whitelists are not declared, keep the existing ones
+				return models.UpdateProtectBranch(ctx, repo, protectBranch, models.WhitelistOptions{
+					UserIDs:          protectBranch.WhitelistUserIDs,
+					TeamIDs:          protectBranch.WhitelistTeamIDs,
+					MergeUserIDs:     protectBranch.MergeWhitelistUserIDs,
+					MergeTeamIDs:     protectBranch.MergeWhitelistTeamIDs,
+					ApprovalsUserIDs: protectBranch.ApprovalsWhitelistUserIDs,
+					ApprovalsTeamIDs: protectBranch.ApprovalsWhitelistTeamIDs,
+				})
+			},
+		})
+	}
+	return changes, nil
+}
+
+func webhooksDrift(ctx context.Context, repo *repo_model.Repository, cfg *Config) ([]*Change, error) {
+	if len(cfg.Webhooks) == 0 {
+		return nil, nil
+	}
+
+	hooks, err := webhook.ListWebhooksByOpts(ctx, &webhook.ListWebhookOptions{RepoID: repo.ID})
+	if err != nil {
+		return nil, err
+	}
+	existing := make(map[string]*webhook.Webhook, len(hooks))
+	for _, hook := range hooks {
+		existing[hook.URL] = hook
+	}
+
+	changes := make([]*Change, 0, len(cfg.Webhooks))
+	for _, declared := range cfg.Webhooks {
+		declared := declared
+		event := &webhook.HookEvent{
+			ChooseEvents: true,
+			HookEvents:   hookEvents(declared.Events),
+			BranchFilter: declared.BranchFilter,
+		}
+		active := declared.Active == nil || *declared.Active
+
+		hook, ok := existing[declared.URL]
+		if !ok {
+			changes = append(changes, &Change{
+				Section: SectionWebhooks,
+				Name:    declared.URL,
+				Action:  ActionCreate,
+				apply: func(ctx context.Context) error {
+					hook := &webhook.Webhook{
+						RepoID:      repo.ID,
+						URL:         declared.URL,
+						HTTPMethod:  "POST",
+						ContentType: webhook.ToHookContentType(declared.ContentType),
+						HookEvent:   event,
+						IsActive:    active,
+						Type:        declared.Type,
+					}
+					if err := hook.UpdateEvent(); err != nil {
+						return err
+					}
+					return webhook.CreateWebhook(ctx, hook)
+				},
+			})
+		} else if hook.Type != declared.Type || hook.ContentType.Name() != declared.ContentType ||
+			hook.IsActive != active || hook.HookEvent == nil || *hook.HookEvent != *event {
+			changes = append(changes, &Change{
+				Section: SectionWebhooks,
+				Name:    declared.URL,
+				Action:  ActionUpdate,
+				apply: func(ctx context.Context) error {
+					hook.Type = declared.Type
+					hook.ContentType = webhook.ToHookContentType(declared.ContentType)
+					hook.HookEvent = event
+					hook.IsActive = active
+					if err := hook.UpdateEvent(); err != nil {
+						return err
+					}
+					return webhook.UpdateWebhook(hook)
+				},
+			})
+		}
+	}
+	return changes, nil
+}
+
+// hookEvents returns the webhook events for the event names accepted by the API,
+// "issues" and "pull_request" select all events of issues and pull requests
+func hookEvents(events []string) webhook.HookEvents {
+	has := func(event webhook.HookEventType) bool {
+		return util.IsStringInSlice(string(event), events, true)
+	}
+	issues := func(event string) bool {
+		return has(webhook.HookEventIssues) || util.IsStringInSlice(event, events, true)
+	}
+	pulls := func(event string) bool {
+		return has(webhook.HookEventPullRequest) || util.IsStringInSlice(event, events, true)
+	}
+	return webhook.HookEvents{
+		Create:               has(webhook.HookEventCreate),
+		Delete:               has(webhook.HookEventDelete),
+		Fork:                 has(webhook.HookEventFork),
+		Issues:               issues("issues_only"),
+		IssueAssign:          issues(string(webhook.HookEventIssueAssign)),
+		IssueLabel:           issues(string(webhook.HookEventIssueLabel)),
+		IssueMilestone:       issues(string(webhook.HookEventIssueMilestone)),
+		IssueComment:         issues(string(webhook.HookEventIssueComment)),
+		Push:                 has(webhook.HookEventPush),
+		PullRequest:          pulls("pull_request_only"),
+		PullRequestAssign:    pulls(string(webhook.HookEventPullRequestAssign)),
+		PullRequestLabel:     pulls(string(webhook.HookEventPullRequestLabel)),
+		PullRequestMilestone: pulls(string(webhook.HookEventPullRequestMilestone)),
+		PullRequestComment:   pulls(string(webhook.HookEventPullRequestComment)),
+		PullRequestReview:    pulls("pull_request_review"),
+		PullRequestSync:      pulls(string(webhook.HookEventPullRequestSync)),
+		Repository:           has(webhook.HookEventRepository),
+		Release:              has(webhook.HookEventRelease),
+	}
+}
@@ -0,0 +1,84 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package reposettings
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/models/webhook"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDriftAndApply(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	cfg, err := Parse([]byte(`
+repository:
+  website: https://example.com
+labels:
+  - name: label1
+    color: "#abcdef"
+  - name: label2
+    color: "#ffffff"
+  - name: bug
+    color: "#ff0000"
+branch_protections:
+  - branch: master
+    required_approvals: 2
+webhooks:
+  - url: www.example.com/url1
+    events: [push]
+  - url: https://example.com/hook
+    events: [issues]
+`))
+	assert.NoError(t, err)
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	changes, err := Drift(db.DefaultContext, repo, cfg)
+	assert.NoError(t, err)
+
+	type change struct {
+		Section Section
+		Name    string
+		Action  Action
+	}
+	actual := make([]change, 0, len(changes))
+	for _, c := range changes {
+		actual = append(actual, change{c.Section, c.Name, c.Action})
+	}
+	assert.EqualValues(t, []change{
+		{SectionRepository, "website", ActionUpdate},
+		{SectionLabels, "label2", ActionUpdate},
+		{SectionLabels, "bug", ActionCreate},
+		{SectionBranchProtections, "master", ActionCreate},
+		{SectionWebhooks, "www.example.com/url1", ActionUpdate},
+		{SectionWebhooks, "https://example.com/hook", ActionCreate},
+	}, actual)
+
+	Apply(db.DefaultContext, changes)
+	for _, c := range changes {
+		assert.NoError(t, c.Err)
+	}
+
+	repo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	assert.EqualValues(t, "https://example.com", repo.Website)
+	unittest.AssertExistsAndLoadBean(t, &models.Label{RepoID: 1, Name: "label2", Color: "#ffffff"})
+	unittest.AssertExistsAndLoadBean(t, &models.Label{RepoID: 1, Name: "bug", Color: "#ff0000"})
+	unittest.AssertExistsAndLoadBean(t, &models.ProtectedBranch{RepoID: 1, BranchName: "master", RequiredApprovals: 2})
+	hook := unittest.AssertExistsAndLoadBean(t, &webhook.Webhook{RepoID: 1, URL: "https://example.com/hook"}).(*webhook.Webhook)
+	assert.True(t, hook.IsActive)
+	assert.True(t, hook.HookEvent.IssueComment)
+	assert.False(t, hook.HookEvent.Push)
+
+	// once applied there is no drift anymore
+	changes, err = Drift(db.DefaultContext, repo, cfg)
+	assert.NoError(t, err)
+	assert.Empty(t, changes)
+}
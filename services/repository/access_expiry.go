@@ -0,0 +1,152 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// RevokeExpiredAccess removes the collaborators and teams whose temporary access to a repository has expired
+// and notifies both the grantee and the user who granted the access.
+func RevokeExpiredAccess(ctx context.Context) error {
+	now := timeutil.TimeStampNow()
+
+	collaborations, err := repo_model.FindExpiredCollaborations(ctx, now)
+	if err != nil {
+		return fmt.Errorf("FindExpiredCollaborations: %v", err)
+	}
+	for _, collaboration := range collaborations {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before revoking collaboration %d", collaboration.ID)
+		default:
+		}
+		if err := revokeExpiredCollaboration(ctx, collaboration); err != nil {
+			log.Error("Unable to revoke expired collaboration %d: %v", collaboration.ID, err)
+		}
+	}
+
+	teamRepos, err := organization.FindExpiredTeamRepos(ctx, now)
+	if err != nil {
+		return fmt.Errorf("FindExpiredTeamRepos: %v", err)
+	}
+	for _, teamRepo := range teamRepos {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before revoking team repository %d", teamRepo.ID)
+		default:
+		}
+		if err := revokeExpiredTeamRepo(ctx, teamRepo); err != nil {
+			log.Error("Unable to revoke expired team repository %d: %v", teamRepo.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func revokeExpiredCollaboration(ctx context.Context, collaboration *repo_model.Collaboration) error {
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, collaboration.RepoID)
+	if err != nil {
+		return err
+	}
+	if err := repo.GetOwner(ctx); err != nil {
+		return err
+	}
+	user, err := user_model.GetUserByIDCtx(ctx, collaboration.UserID)
+	if user_model.IsErrUserNotExist(err) {
+		// nobody is left to notify, but the access must not outlive the user
+		return models.DeleteCollaboration(repo, collaboration.UserID)
+	} else if err != nil {
+		return err
+	}
+
+	if err := models.DeleteCollaboration(repo, user.ID); err != nil {
+		return err
+	}
+	log.Trace("Temporary access of %s to %-v expired", user.Name, repo)
+
+	if setting.Service.EnableNotifyMail {
+		recipients := accessGranters(ctx, repo, collaboration.GrantedByID)
+		mailer.SendAccessExpiredMail(appendUniqueUsers(recipients, user), repo, user.Name)
+	}
+	return nil
+}
+
+func revokeExpiredTeamRepo(ctx context.Context, teamRepo *organization.TeamRepo) error {
+	team, err := organization.GetTeamByID(ctx, teamRepo.TeamID)
+	if err != nil {
+		return err
+	}
+	if team.IncludesAllRepositories {
+		// the team would get the repository back immediately, so the expiry is meaningless
+		return organization.SetTeamRepoExpiry(ctx, team.ID, teamRepo.RepoID, 0, 0)
+	}
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, teamRepo.RepoID)
+	if err != nil {
+		return err
+	}
+	if err := repo.GetOwner(ctx); err != nil {
+		return err
+	}
+
+	if err := models.RemoveRepository(team, repo.ID); err != nil {
+		return err
+	}
+	log.Trace("Temporary access of team %s to %-v expired", team.Name, repo)
+
+	if setting.Service.EnableNotifyMail {
+		if err := team.GetMembersCtx(ctx); err != nil {
+			return err
+		}
+		recipients := accessGranters(ctx, repo, teamRepo.GrantedByID)
+		mailer.SendAccessExpiredMail(appendUniqueUsers(recipients, team.Members...), repo, repo.OwnerName+"/"+team.Name)
+	}
+	return nil
+}
+
+// accessGranters returns the user who granted an access, falling back to the owner of a personal repository.
+// The owner of the repository has to be loaded.
+func accessGranters(ctx context.Context, repo *repo_model.Repository, grantedByID int64) []*user_model.User {
+	if grantedByID > 0 {
+		granter, err := user_model.GetUserByIDCtx(ctx, grantedByID)
+		if err == nil {
+			return []*user_model.User{granter}
+		} else if !user_model.IsErrUserNotExist(err) {
+			log.Error("GetUserByID: %v", err)
+		}
+	}
+
+	if repo.Owner.IsOrganization() {
+		return nil
+	}
+	return []*user_model.User{repo.Owner}
+}
+
+func appendUniqueUsers(users []*user_model.User, others ...*user_model.User) []*user_model.User {
+	for _, other := range others {
+		found := false
+		for _, u := range users {
+			if u.ID == other.ID {
+				found = true
+				break
+			}
+		}
+		if !found {
+			users = append(users, other)
+		}
+	}
+	return users
+}
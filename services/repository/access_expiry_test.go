@@ -0,0 +1,39 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRevokeExpiredAccess(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	now := timeutil.TimeStampNow()
+	assert.NoError(t, repo_model.SetCollaborationExpiry(db.DefaultContext, 4, 4, now.Add(-60), 2))
+	assert.NoError(t, repo_model.SetCollaborationExpiry(db.DefaultContext, 4, 29, now.Add(3600), 2))
+	assert.NoError(t, organization.SetTeamRepoExpiry(db.DefaultContext, 2, 3, now.Add(-60), 2))
+	// the collaboration of a user who no longer exists is revoked as well
+	assert.NoError(t, db.Insert(db.DefaultContext, &repo_model.Collaboration{RepoID: 4, UserID: 1000, Mode: perm.AccessModeWrite, ExpiresUnix: now.Add(-60)}))
+
+	assert.NoError(t, RevokeExpiredAccess(db.DefaultContext))
+
+	unittest.AssertNotExistsBean(t, &repo_model.Collaboration{RepoID: 4, UserID: 4})
+	unittest.AssertNotExistsBean(t, &access_model.Access{RepoID: 4, UserID: 4})
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Collaboration{RepoID: 4, UserID: 29})
+	unittest.AssertNotExistsBean(t, &repo_model.Collaboration{RepoID: 4, UserID: 1000})
+	unittest.AssertNotExistsBean(t, &organization.TeamRepo{TeamID: 2, RepoID: 3})
+	unittest.AssertExistsAndLoadBean(t, &organization.TeamRepo{TeamID: 1, RepoID: 3})
+}
@@ -0,0 +1,85 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/perm"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+// RequestAccess records an access request of the doer for the repository and notifies its administrators.
+// Nothing happens if the doer can already access the repository or has a pending request.
+func RequestAccess(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, message string) error {
+	permission, err := access_model.GetUserRepoPermission(ctx, repo, doer)
+	if err != nil {
+		return fmt.Errorf("GetUserRepoPermission: %v", err)
+	}
+	if permission.HasAccess() {
+		return nil
+	}
+
+	_, created, err := repo_model.CreateAccessRequest(ctx, repo.ID, doer.ID, message)
+	if err != nil {
+		return fmt.Errorf("CreateAccessRequest: %v", err)
+	}
+	if !created {
+		return nil
+	}
+	log.Trace("User %s requested access to %-v", doer.Name, repo)
+
+	if setting.Service.EnableNotifyMail {
+		admins, err := access_model.GetRepoAdmins(ctx, repo)
+		if err != nil {
+			return fmt.Errorf("GetRepoAdmins: %v", err)
+		}
+		mailer.SendAccessRequestMail(admins, doer, repo, message)
+	}
+	return nil
+}
+
+// ApproveAccessRequest adds the requester as collaborator with the access mode to the repository
+func ApproveAccessRequest(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, req *repo_model.AccessRequest, mode perm.AccessMode) error {
+	if err := req.LoadAttributes(ctx); err != nil {
+		return err
+	}
+	if err := models.AddCollaborator(repo, req.User); err != nil {
+		return fmt.Errorf("AddCollaborator: %v", err)
+	}
+	if err := repo_model.ChangeCollaborationAccessMode(repo, req.UserID, mode); err != nil {
+		return fmt.Errorf("ChangeCollaborationAccessMode: %v", err)
+	}
+	if err := repo_model.DecideAccessRequest(ctx, req, repo_model.AccessRequestStatusApproved, doer.ID); err != nil {
+		return fmt.Errorf("DecideAccessRequest: %v", err)
+	}
+
+	if setting.Service.EnableNotifyMail {
+		mailer.SendAccessRequestDecisionMail(req.User, repo, true)
+	}
+	return nil
+}
+
+// DenyAccessRequest denies the access request and notifies the requester
+func DenyAccessRequest(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, req *repo_model.AccessRequest) error {
+	if err := req.LoadAttributes(ctx); err != nil {
+		return err
+	}
+	if err := repo_model.DecideAccessRequest(ctx, req, repo_model.AccessRequestStatusDenied, doer.ID); err != nil {
+		return fmt.Errorf("DecideAccessRequest: %v", err)
+	}
+
+	if setting.Service.EnableNotifyMail {
+		mailer.SendAccessRequestDecisionMail(req.User, repo, false)
+	}
+	return nil
+}
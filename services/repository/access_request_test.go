@@ -0,0 +1,45 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/perm"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAccessRequest(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	user2 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	user4 := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	repo2 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 2}).(*repo_model.Repository)
+
+	// no request for a repository the user can see
+	assert.NoError(t, RequestAccess(db.DefaultContext, user4, repo1, "public"))
+	unittest.AssertNotExistsBean(t, &repo_model.AccessRequest{RepoID: repo1.ID})
+
+	assert.NoError(t, RequestAccess(db.DefaultContext, user4, repo2, "please"))
+	req := unittest.AssertExistsAndLoadBean(t, &repo_model.AccessRequest{RepoID: repo2.ID, UserID: user4.ID}).(*repo_model.AccessRequest)
+
+	assert.NoError(t, DenyAccessRequest(db.DefaultContext, user2, repo2, req))
+	unittest.AssertExistsAndLoadBean(t, &repo_model.AccessRequest{ID: req.ID, Status: repo_model.AccessRequestStatusDenied, ReviewerID: user2.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.Collaboration{RepoID: repo2.ID, UserID: user4.ID})
+
+	assert.NoError(t, RequestAccess(db.DefaultContext, user4, repo2, "please again"))
+	req = unittest.AssertExistsAndLoadBean(t, &repo_model.AccessRequest{ID: req.ID}).(*repo_model.AccessRequest)
+	assert.True(t, req.IsPending())
+
+	assert.NoError(t, ApproveAccessRequest(db.DefaultContext, user2, repo2, req, perm.AccessModeRead))
+	unittest.AssertExistsAndLoadBean(t, &repo_model.AccessRequest{ID: req.ID, Status: repo_model.AccessRequestStatusApproved, ReviewerID: user2.ID})
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Collaboration{RepoID: repo2.ID, UserID: user4.ID, Mode: perm.AccessModeRead})
+}
@@ -252,7 +252,9 @@ func checkUnadoptedRepositories(userName string, repoNamesToCheck []string, unad
 		ListOptions: db.ListOptions{
 			Page:     1,
 			PageSize: len(repoNamesToCheck),
-		}, LowerNames: repoNamesToCheck,
+		},
+		LowerNames:     repoNamesToCheck,
+		IncludeTrashed: true,
 	})
 	if err != nil {
 		return err
@@ -328,6 +328,31 @@ func DeleteOldRepositoryArchives(ctx context.Context, olderThan time.Duration) e
 	return nil
 }
 
+// DeleteRepoArchives deletes the archives of a repository.
+func DeleteRepoArchives(ctx context.Context, repoID int64) error {
+	for {
+		archivers, err := repo_model.FindRepoArchives(repo_model.FindRepoArchiversOption{
+			ListOptions: db.ListOptions{
+				PageSize: 100,
+				Page:     1,
+			},
+			RepoID: repoID,
+		})
+		if err != nil {
+			return err
+		}
+
+		for _, archiver := range archivers {
+			if err := deleteOldRepoArchiver(ctx, archiver); err != nil {
+				return err
+			}
+		}
+		if len(archivers) < 100 {
+			return nil
+		}
+	}
+}
+
 // DeleteRepositoryArchives deletes all repositories' archives.
 func DeleteRepositoryArchives(ctx context.Context) error {
 	if err := repo_model.DeleteAllRepoArchives(); err != nil {
@@ -0,0 +1,21 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// AddAuditLog records a security relevant change of a repository in its audit log.
+// A failure is logged instead of being returned as the change has already been made.
+func AddAuditLog(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, action repo_model.AuditAction, target, detail string) {
+	if err := repo_model.AddAuditLog(ctx, repo.ID, doer.ID, action, target, detail); err != nil {
+		log.Error("AddAuditLog [repo_id: %d, action: %s]: %v", repo.ID, action, err)
+	}
+}
@@ -9,8 +9,10 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/git"
@@ -196,9 +198,115 @@ func DeleteBranch(doer *user_model.User, repo *repo_model.Repository, gitRepo *g
 		log.Error("Update: %v", err)
 	}
 
-	if err := models.AddDeletedBranch(repo.ID, branchName, commit.ID.String(), doer.ID); err != nil {
+	deletedBranch, err := models.AddDeletedBranch(repo.ID, branchName, commit.ID.String(), doer.ID)
+	if err != nil {
 		log.Warn("AddDeletedBranch: %v", err)
+		return nil
+	}
+
+	// Keep the commits reachable until the deleted branch expires, so the branch can be restored
+	if err := gitRepo.SetReference(deletedBranch.RefName(), deletedBranch.Commit); err != nil {
+		log.Error("SetReference[%s]: %v", deletedBranch.RefName(), err)
+	}
+
+	return nil
+}
+
+// RestoreBranch recreates a deleted branch at the commit it pointed to when it was deleted
+func RestoreBranch(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, deletedBranch *models.DeletedBranch) error {
+	if err := git.Push(ctx, repo.RepoPath(), git.PushOptions{
+		Remote: repo.RepoPath(),
+		Branch: fmt.Sprintf("%s:%s%s", deletedBranch.Commit, git.BranchPrefix, deletedBranch.Name),
+		Env:    repo_module.PushingEnvironment(doer, repo),
+	}); err != nil {
+		if strings.Contains(err.Error(), "already exists") {
+			return models.ErrBranchAlreadyExists{
+				BranchName: deletedBranch.Name,
+			}
+		}
+		if git.IsErrPushOutOfDate(err) || git.IsErrPushRejected(err) {
+			return err
+		}
+		return fmt.Errorf("Push: %v", err)
+	}
+
+	// Don't return error below this
+	if err := PushUpdate(
+		&repo_module.PushUpdateOptions{
+			RefFullName:  git.BranchPrefix + deletedBranch.Name,
+			OldCommitID:  git.EmptySHA,
+			NewCommitID:  deletedBranch.Commit,
+			PusherID:     doer.ID,
+			PusherName:   doer.Name,
+			RepoUserName: repo.OwnerName,
+			RepoName:     repo.Name,
+		}); err != nil {
+		log.Error("RestoreBranch: Update: %v", err)
 	}
 
 	return nil
 }
+
+// removeDeletedBranchesByName removes the deleted branches of a recreated branch together with their hidden refs
+func removeDeletedBranchesByName(ctx context.Context, repo *repo_model.Repository, branch string) error {
+	deletedBranches, err := models.GetDeletedBranchesByName(repo.ID, branch)
+	if err != nil {
+		return err
+	}
+
+	for _, deletedBranch := range deletedBranches {
+		if err := removeDeletedBranchRef(ctx, repo.RepoPath(), deletedBranch); err != nil {
+			return err
+		}
+	}
+
+	return models.RemoveDeletedBranchByName(repo.ID, branch)
+}
+
+// CleanupDeletedBranches removes the deleted branches whose restore window has expired.
+// The hidden refs are removed too, so the commits can get garbage collected.
+func CleanupDeletedBranches(ctx context.Context, olderThan time.Duration) error {
+	log.Trace("Doing: DeletedBranchesCleanup")
+
+	deletedBranches, err := models.FindDeletedBranchesOlderThan(ctx, olderThan)
+	if err != nil {
+		return err
+	}
+
+	var repo *repo_model.Repository
+	for _, deletedBranch := range deletedBranches {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before cleaning up deleted branch %d", deletedBranch.ID)
+		default:
+		}
+
+		if repo == nil || repo.ID != deletedBranch.RepoID {
+			repo, err = repo_model.GetRepositoryByID(deletedBranch.RepoID)
+			if err != nil {
+				if !repo_model.IsErrRepoNotExist(err) {
+					return err
+				}
+				repo = nil
+			}
+		}
+
+		if repo != nil {
+			if err := removeDeletedBranchRef(ctx, repo.RepoPath(), deletedBranch); err != nil {
+				log.Error("Unable to remove hidden ref of deleted branch %d in %s: %v", deletedBranch.ID, repo.FullName(), err)
+				continue
+			}
+		}
+
+		if err := models.RemoveDeletedBranchByID(deletedBranch.RepoID, deletedBranch.ID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func removeDeletedBranchRef(ctx context.Context, repoPath string, deletedBranch *models.DeletedBranch) error {
+	_, _, err := git.NewCommand(ctx, "update-ref", "--no-deref", "-d", deletedBranch.RefName()).RunStdString(&git.RunOpts{Dir: repoPath})
+	return err
+}
@@ -46,3 +46,23 @@ func CacheRef(ctx context.Context, repo *repo_model.Repository, gitRepo *git.Rep
 
 	return commitCache.CacheCommit(ctx, commit)
 }
+
+// ClearLastCommitCache clears the cached last commit information of the repository and caches the one of its default branch again
+func ClearLastCommitCache(ctx context.Context, repo *repo_model.Repository) error {
+	if !setting.CacheService.LastCommit.Enabled || cache.GetCache() == nil {
+		return nil
+	}
+	if err := git.ClearLastCommitCache(cache.GetCache(), repo.FullName(), setting.LastCommitCacheTTLSeconds()); err != nil {
+		return err
+	}
+	if repo.IsEmpty {
+		return nil
+	}
+
+	gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+	if err != nil {
+		return err
+	}
+	defer gitRepo.Close()
+	return CacheRef(ctx, repo, gitRepo, git.BranchPrefix+repo.DefaultBranch)
+}
@@ -0,0 +1,123 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+)
+
+// ErrUnknownConsoleCommand represents a "UnknownConsoleCommand" kind of error.
+type ErrUnknownConsoleCommand struct {
+	Name string
+}
+
+// IsErrUnknownConsoleCommand checks if an error is a ErrUnknownConsoleCommand.
+func IsErrUnknownConsoleCommand(err error) bool {
+	_, ok := err.(ErrUnknownConsoleCommand)
+	return ok
+}
+
+func (err ErrUnknownConsoleCommand) Error() string {
+	return fmt.Sprintf("unknown console command: %s", err.Name)
+}
+
+// ConsoleCommand is a maintenance command which site admins can run on a repository from the web console
+type ConsoleCommand struct {
+	Name string
+	// Line is the command line shown in the console
+	Line string
+	run  func(ctx context.Context, repo *repo_model.Repository, w io.Writer) error
+}
+
+// ConsoleCommands are the only commands which can be run from the web console,
+// they only read the repository
+var ConsoleCommands = []*ConsoleCommand{
+	{Name: "fsck", Line: "git fsck --no-progress", run: runGitConsoleCommand("fsck", "--no-progress")},
+	{Name: "count-objects", Line: "git count-objects -v -H", run: runGitConsoleCommand("count-objects", "-v", "-H")},
+	{Name: "lfs-verify", Line: "git lfs verify", run: verifyLFSObjects},
+}
+
+// GetConsoleCommand returns the console command with the name
+func GetConsoleCommand(name string) (*ConsoleCommand, error) {
+	for _, c := range ConsoleCommands {
+		if c.Name == name {
+			return c, nil
+		}
+	}
+	return nil, ErrUnknownConsoleCommand{Name: name}
+}
+
+// Run runs the command on the repository and writes its output to w
+func (c *ConsoleCommand) Run(ctx context.Context, repo *repo_model.Repository, w io.Writer) error {
+	return c.run(ctx, repo, w)
+}
+
+func runGitConsoleCommand(args ...string) func(ctx context.Context, repo *repo_model.Repository, w io.Writer) error {
+	return func(ctx context.Context, repo *repo_model.Repository, w io.Writer) error {
+		// the same writer for stdout and stderr keeps the output in order and guarantees it isn't written to concurrently
+		return git.NewCommand(ctx, args...).
+			SetDescription(fmt.Sprintf("Repository Console (git %s): %s", args[0], repo.FullName())).
+			Run(&git.RunOpts{
+				Timeout: time.Duration(setting.Git.Timeout.GC) * time.Second,
+				Dir:     repo.RepoPath(),
+				Stdout:  w,
+				Stderr:  w,
+			})
+	}
+}
+
+// verifyLFSObjects checks that every LFS object of the repository exists in the content store with the right size
+func verifyLFSObjects(ctx context.Context, repo *repo_model.Repository, w io.Writer) error {
+	if !setting.LFS.StartServer {
+		_, err := fmt.Fprintln(w, "LFS is disabled")
+		return err
+	}
+
+	const pageSize = 50
+	contentStore := lfs.NewContentStore()
+	var total, missing int
+	for page := 1; ; page++ {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		metas, err := models.GetLFSMetaObjects(repo.ID, page, pageSize)
+		if err != nil {
+			return err
+		}
+		for _, meta := range metas {
+			ok, err := contentStore.Verify(meta.Pointer)
+			if err != nil {
+				return err
+			}
+			total++
+			status := "ok"
+			if !ok {
+				missing++
+				status = "missing or corrupt"
+			}
+			if _, err := fmt.Fprintf(w, "%s %d: %s\n", meta.Oid, meta.Size, status); err != nil {
+				return err
+			}
+		}
+		if len(metas) < pageSize {
+			break
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "%d objects verified, %d missing or corrupt\n", total, missing)
+	if err == nil && missing > 0 {
+		err = fmt.Errorf("%d LFS objects are missing or corrupt", missing)
+	}
+	return err
+}
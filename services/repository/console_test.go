@@ -0,0 +1,52 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"bytes"
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetConsoleCommand(t *testing.T) {
+	for _, name := range []string{"fsck", "count-objects", "lfs-verify"} {
+		cmd, err := GetConsoleCommand(name)
+		assert.NoError(t, err)
+		assert.Equal(t, name, cmd.Name)
+	}
+
+	_, err := GetConsoleCommand("gc")
+	assert.True(t, IsErrUnknownConsoleCommand(err))
+	_, err = GetConsoleCommand("")
+	assert.True(t, IsErrUnknownConsoleCommand(err))
+}
+
+func TestConsoleLFSVerify(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	oldStartServer := setting.LFS.StartServer
+	defer func() {
+		setting.LFS.StartServer = oldStartServer
+	}()
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	cmd, err := GetConsoleCommand("lfs-verify")
+	assert.NoError(t, err)
+
+	setting.LFS.StartServer = false
+	var out bytes.Buffer
+	assert.NoError(t, cmd.Run(db.DefaultContext, repo, &out))
+	assert.Equal(t, "LFS is disabled\n", out.String())
+
+	setting.LFS.StartServer = true
+	out.Reset()
+	assert.NoError(t, cmd.Run(db.DefaultContext, repo, &out))
+	assert.Equal(t, "0 objects verified, 0 missing or corrupt\n", out.String())
+}
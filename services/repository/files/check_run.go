@@ -0,0 +1,97 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package files
+
+import (
+	"context"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+)
+
+// CreateCheckRun creates a check run with its annotations for a commit and reports it as commit status.
+// The SHA of the run is expanded to the full commit id.
+func CreateCheckRun(ctx context.Context, repo *repo_model.Repository, creator *user_model.User, run *models.CheckRun, annotations []*models.CheckRunAnnotation) error {
+	if err := run.Validate(annotations); err != nil {
+		return err
+	}
+
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, repo.RepoPath())
+	if err != nil {
+		return fmt.Errorf("OpenRepository[%s]: %v", repo.RepoPath(), err)
+	}
+	commit, err := gitRepo.GetCommit(run.SHA)
+	closer.Close()
+	if err != nil {
+		if git.IsErrNotExist(err) {
+			return models.ErrInvalidCheckRun{Reason: fmt.Sprintf("commit %s does not exist", run.SHA)}
+		}
+		return fmt.Errorf("GetCommit[%s]: %v", run.SHA, err)
+	}
+
+	run.RepoID = repo.ID
+	run.Repo = repo
+	run.SHA = commit.ID.String()
+	run.CreatorID = creator.ID
+	run.Creator = creator
+	run.UpdateTimes()
+	if err := models.CreateCheckRun(ctx, run, annotations); err != nil {
+		return fmt.Errorf("CreateCheckRun: %v", err)
+	}
+
+	return createCheckRunCommitStatus(ctx, repo, creator, run)
+}
+
+// UpdateCheckRun saves the changed columns of a check run, appends the annotations and reports the new state as commit status
+func UpdateCheckRun(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, run *models.CheckRun, annotations []*models.CheckRunAnnotation, cols ...string) error {
+	if err := run.Validate(annotations); err != nil {
+		return err
+	}
+
+	run.Repo = repo
+	run.UpdateTimes()
+	cols = append(cols, "status", "conclusion", "started_unix", "completed_unix")
+	if err := models.UpdateCheckRun(ctx, run, annotations, cols...); err != nil {
+		return fmt.Errorf("UpdateCheckRun: %v", err)
+	}
+
+	return createCheckRunCommitStatus(ctx, repo, doer, run)
+}
+
+// RerequestCheckRun queues a check run for another attempt, the service reporting it is expected to pick up
+// queued check runs and run the check again.
+func RerequestCheckRun(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, run *models.CheckRun) error {
+	run.Repo = repo
+	if err := models.ResetCheckRun(ctx, run); err != nil {
+		return fmt.Errorf("ResetCheckRun: %v", err)
+	}
+
+	return createCheckRunCommitStatus(ctx, repo, doer, run)
+}
+
+// createCheckRunCommitStatus mirrors the state of a check run as commit status with the name of the check as context
+func createCheckRunCommitStatus(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, run *models.CheckRun) error {
+	description := run.Title
+	if description == "" {
+		switch run.Status {
+		case models.CheckRunStatusQueued:
+			description = "Queued"
+		case models.CheckRunStatusInProgress:
+			description = "In progress"
+		default:
+			description = string(run.Conclusion)
+		}
+	}
+
+	return CreateCommitStatus(ctx, repo, doer, run.SHA, &models.CommitStatus{
+		State:       run.CommitStatusState(),
+		TargetURL:   run.HTMLURL(),
+		Description: description,
+		Context:     run.Name,
+	})
+}
@@ -85,7 +85,11 @@ func CherryPick(ctx context.Context, repo *repo_model.Repository, doer *user_mod
 	}
 
 	if conflict {
-		return nil, fmt.Errorf("failed to merge due to conflicts")
+		return nil, models.ErrCherryPickConflict{CommitID: commit.ID.String(), Branch: opts.OldBranch, Revert: revert}
+	}
+
+	if err := t.VerifyEditorFileRules(opts.NewBranch == opts.OldBranch); err != nil {
+		return nil, err
 	}
 
 	treeHash, err := t.WriteTree()
@@ -151,11 +151,13 @@ func GetContents(ctx context.Context, repo *repo_model.Repository, treePath, ref
 
 	// All content types have these fields in populated
 	contentsResponse := &api.ContentsResponse{
-		Name: entry.Name(),
-		Path: treePath,
-		SHA:  entry.ID.String(),
-		Size: entry.Size(),
-		URL:  &selfURLString,
+		Name:       entry.Name(),
+		Path:       treePath,
+		SHA:        entry.ID.String(),
+		Mode:       fmt.Sprintf("%06o", entry.Mode()),
+		Executable: entry.IsExecutable(),
+		Size:       entry.Size(),
+		URL:        &selfURLString,
 		Links: &api.FileLinksResponse{
 			Self: &selfURLString,
 		},
@@ -190,7 +192,7 @@ func GetContents(ctx context.Context, repo *repo_model.Repository, treePath, ref
 		contentsResponse.SubmoduleGitURL = &submodule.URL
 	}
 	// Handle links
-	if entry.IsRegular() || entry.IsLink() {
+	if entry.IsRegular() || entry.IsExecutable() || entry.IsLink() {
 		downloadURL, err := url.Parse(fmt.Sprintf("%s/raw/%s/%s/%s", repo.HTMLURL(), refType, ref, treePath))
 		if err != nil {
 			return nil, err
@@ -240,3 +242,76 @@ func GetBlobBySHA(ctx context.Context, repo *repo_model.Repository, gitRepo *git
 		Content:  content,
 	}, nil
 }
+
+// GetBlobs gets the blobs of a repository by their SHAs and by their paths at the commit, the commit is only used
+// if there are paths. The content of a blob is left out if it is larger than the max blob size, or if it doesn't fit
+// into the max size of the response anymore, which marks the response as truncated.
+func GetBlobs(ctx context.Context, repo *repo_model.Repository, gitRepo *git.Repository, commit *git.Commit, shas, paths []string) (*api.GitBlobsResponse, error) {
+	resp := &api.GitBlobsResponse{
+		Blobs:    make([]*api.GitBlobResponse, 0, len(shas)+len(paths)),
+		NotFound: []string{},
+	}
+	var totalSize int64
+	addBlob := func(gitBlob *git.Blob, path string) error {
+		content := ""
+		if size := gitBlob.Size(); size <= setting.API.DefaultMaxBlobSize {
+			if totalSize+size > setting.API.MaxBatchBlobsSize {
+				resp.Truncated = true
+			} else {
+				var err error
+				if content, err = gitBlob.GetBlobContentBase64(); err != nil {
+					return err
+				}
+				totalSize += size
+			}
+		}
+		resp.Blobs = append(resp.Blobs, &api.GitBlobResponse{
+			SHA:      gitBlob.ID.String(),
+			URL:      repo.APIURL() + "/git/blobs/" + url.PathEscape(gitBlob.ID.String()),
+			Size:     gitBlob.Size(),
+			Encoding: "base64",
+			Content:  content,
+			Path:     path,
+		})
+		return nil
+	}
+
+	for _, sha := range shas {
+		if _, err := git.NewIDFromString(sha); err != nil || !gitRepo.IsObjectExist(sha) {
+			resp.NotFound = append(resp.NotFound, sha)
+			continue
+		}
+		gitBlob, err := gitRepo.GetBlob(sha)
+		if err != nil {
+			return nil, err
+		}
+		if err := addBlob(gitBlob, ""); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, path := range paths {
+		treePath := CleanUploadFileName(path)
+		if treePath == "" {
+			resp.NotFound = append(resp.NotFound, path)
+			continue
+		}
+		entry, err := commit.GetTreeEntryByPath(treePath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				resp.NotFound = append(resp.NotFound, path)
+				continue
+			}
+			return nil, err
+		}
+		if !entry.IsRegular() && !entry.IsExecutable() && !entry.IsLink() {
+			// directories and submodules don't have a blob
+			resp.NotFound = append(resp.NotFound, path)
+			continue
+		}
+		if err := addBlob(entry.Blob(), path); err != nil {
+			return nil, err
+		}
+	}
+	return resp, nil
+}
@@ -11,6 +11,7 @@ import (
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/setting"
 	api "code.gitea.io/gitea/modules/structs"
 	"code.gitea.io/gitea/modules/test"
 
@@ -37,6 +38,7 @@ func getExpectedReadmeContentsResponse() *api.ContentsResponse {
 		Path:        treePath,
 		SHA:         "4b4851ad51df6a7d9f25c979345979eaeb5b349f",
 		Type:        "file",
+		Mode:        "100644",
 		Size:        30,
 		Encoding:    &encoding,
 		Content:     &content,
@@ -252,3 +254,47 @@ func TestGetBlobBySHA(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, expectedGBR, gbr)
 }
+
+func TestGetBlobs(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadRepoCommit(t, ctx)
+	test.LoadUser(t, ctx, 2)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	sha := "4b4851ad51df6a7d9f25c979345979eaeb5b349f"
+	readme := &api.GitBlobResponse{
+		Content:  "IyByZXBvMQoKRGVzY3JpcHRpb24gZm9yIHJlcG8x",
+		Encoding: "base64",
+		URL:      "https://try.gitea.io/api/v1/repos/user2/repo1/git/blobs/" + sha,
+		SHA:      sha,
+		Size:     30,
+	}
+	readmeByPath := *readme
+	readmeByPath.Path = "README.md"
+
+	blobs, err := GetBlobs(ctx, ctx.Repo.Repository, ctx.Repo.GitRepo, ctx.Repo.Commit,
+		[]string{sha, "0000000000000000000000000000000000000001", "invalid"}, []string{"README.md", "missing.md", ""})
+	assert.NoError(t, err)
+	assert.Equal(t, &api.GitBlobsResponse{
+		Blobs:    []*api.GitBlobResponse{readme, &readmeByPath},
+		NotFound: []string{"0000000000000000000000000000000000000001", "invalid", "missing.md", ""},
+	}, blobs)
+
+	// the content of the blobs which don't fit into the response anymore is left out
+	defer func(size int64) {
+		setting.API.MaxBatchBlobsSize = size
+	}(setting.API.MaxBatchBlobsSize)
+	setting.API.MaxBatchBlobsSize = 40
+
+	blobs, err = GetBlobs(ctx, ctx.Repo.Repository, ctx.Repo.GitRepo, ctx.Repo.Commit, []string{sha}, []string{"README.md"})
+	assert.NoError(t, err)
+	assert.True(t, blobs.Truncated)
+	if assert.Len(t, blobs.Blobs, 2) {
+		assert.Equal(t, readme, blobs.Blobs[0])
+		assert.Empty(t, blobs.Blobs[1].Content)
+		assert.EqualValues(t, 30, blobs.Blobs[1].Size)
+	}
+}
@@ -0,0 +1,393 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package files
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/lfs"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+)
+
+// FileOperation is the operation of a file change of a created commit
+type FileOperation string
+
+// The operations of the file changes of a created commit
+const (
+	FileOperationCreate FileOperation = "create"
+	FileOperationUpdate FileOperation = "update"
+	FileOperationDelete FileOperation = "delete"
+)
+
+// FileChange is a change of a file of a created commit
+type FileChange struct {
+	Operation FileOperation
+	TreePath  string
+	// FromTreePath is the path of an updated file which is moved to TreePath
+	FromTreePath string
+	Content      string
+	// SHA is the SHA of an updated or deleted file in the base commit, it isn't checked if it's empty
+	SHA string
+	// Executable sets the executable bit of the file, an updated file keeps its executable bit if it's nil
+	Executable *bool
+}
+
+// CreateCommitOptions holds the options to create a commit from a set of file changes
+type CreateCommitOptions struct {
+	BaseCommitID string
+	// Branch is updated to the new commit, it must point to the base commit if it exists
+	Branch    string
+	Message   string
+	Files     []*FileChange
+	Author    *IdentityOptions
+	Committer *IdentityOptions
+	Dates     *CommitDateOptions
+	Signoff   bool
+	// Signature is the armored signature of the commit, the dates are required to sign it
+	Signature string
+}
+
+type lfsChange struct {
+	metaObject *models.LFSMetaObject
+	content    string
+}
+
+// CreateCommit creates a commit with the file changes on top of the base commit and points the branch to it,
+// the branch is only updated if it still points to the base commit
+func CreateCommit(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, opts *CreateCommitOptions) (*structs.FileResponse, error) {
+	if opts.Branch == "" {
+		opts.Branch = repo.DefaultBranch
+	}
+	if len(opts.Files) == 0 {
+		return nil, models.ErrFilePathInvalid{Message: "no files are changed"}
+	}
+	if opts.Signature != "" && opts.Dates == nil {
+		return nil, fmt.Errorf("the dates of a signed commit are required")
+	}
+
+	gitRepo, closer, err := git.RepositoryFromContextOrOpen(ctx, repo.RepoPath())
+	if err != nil {
+		return nil, err
+	}
+	defer closer.Close()
+
+	baseCommit, err := gitRepo.GetCommit(opts.BaseCommitID)
+	if err != nil {
+		return nil, err
+	}
+	opts.BaseCommitID = baseCommit.ID.String()
+
+	// The branch is created if it doesn't exist, otherwise it must not have been changed since the base commit
+	branchExists := true
+	if branchCommitID, err := gitRepo.GetBranchCommitID(opts.Branch); err != nil {
+		if !git.IsErrNotExist(err) {
+			return nil, err
+		}
+		branchExists = false
+	} else if branchCommitID != opts.BaseCommitID {
+		return nil, models.ErrCommitIDDoesNotMatch{
+			GivenCommitID:   opts.BaseCommitID,
+			CurrentCommitID: branchCommitID,
+		}
+	}
+
+	treePaths := make([]string, 0, len(opts.Files))
+	changedPaths := make(map[string]bool, len(opts.Files))
+	for _, file := range opts.Files {
+		treePath := CleanUploadFileName(file.TreePath)
+		if treePath == "" {
+			return nil, models.ErrFilenameInvalid{
+				Path: file.TreePath,
+			}
+		}
+		file.TreePath = treePath
+		paths := []string{treePath}
+		if file.Operation == FileOperationUpdate && file.FromTreePath != "" {
+			fromTreePath := CleanUploadFileName(file.FromTreePath)
+			if fromTreePath == "" {
+				return nil, models.ErrFilenameInvalid{
+					Path: file.FromTreePath,
+				}
+			}
+			if fromTreePath != treePath {
+				paths = append(paths, fromTreePath)
+			}
+			file.FromTreePath = fromTreePath
+		} else {
+			file.FromTreePath = treePath
+		}
+		for _, p := range paths {
+			if changedPaths[p] {
+				return nil, models.ErrFilePathInvalid{
+					Message: fmt.Sprintf("a file is changed more than once [path: %s]", p),
+					Path:    p,
+					Name:    path.Base(p),
+				}
+			}
+			changedPaths[p] = true
+			treePaths = append(treePaths, p)
+		}
+	}
+
+	if branchExists {
+		for _, treePath := range treePaths {
+			if err := VerifyBranchProtection(ctx, repo, doer, opts.Branch, treePath); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := VerifyEditorFileRules(repo, branchExists, treePaths...); err != nil {
+		return nil, err
+	}
+
+	t, err := NewTemporaryUploadRepository(ctx, repo)
+	if err != nil {
+		return nil, err
+	}
+	defer t.Close()
+	// The base commit isn't necessarily on a branch, but the objects of the repository are shared with the clone
+	cloneBranch := opts.Branch
+	if !branchExists {
+		cloneBranch = repo.DefaultBranch
+	}
+	if err := t.Clone(cloneBranch); err != nil {
+		return nil, err
+	}
+	if err := t.SetIndex(opts.BaseCommitID); err != nil {
+		return nil, err
+	}
+
+	lfsPaths, err := getLFSTreePaths(t, opts.Files)
+	if err != nil {
+		return nil, err
+	}
+
+	var lfsChanges []*lfsChange
+	for _, file := range opts.Files {
+		var mode git.EntryMode
+		switch file.Operation {
+		case FileOperationCreate:
+			if err := checkNewTreePath(baseCommit, file.TreePath); err != nil {
+				return nil, err
+			}
+			mode = git.EntryModeBlob
+		case FileOperationUpdate, FileOperationDelete:
+			fromEntry, err := baseCommit.GetTreeEntryByPath(file.FromTreePath)
+			if err != nil {
+				return nil, err
+			}
+			if fromEntry.IsDir() || (file.Operation == FileOperationUpdate && !fromEntry.IsRegular() && !fromEntry.IsExecutable()) {
+				return nil, models.ErrFilePathInvalid{
+					Message: fmt.Sprintf("only files can be changed [path: %s]", file.FromTreePath),
+					Path:    file.FromTreePath,
+					Name:    path.Base(file.FromTreePath),
+					Type:    fromEntry.Mode(),
+				}
+			}
+			if file.SHA != "" && file.SHA != fromEntry.ID.String() {
+				return nil, models.ErrSHADoesNotMatch{
+					Path:       file.FromTreePath,
+					GivenSHA:   file.SHA,
+					CurrentSHA: fromEntry.ID.String(),
+				}
+			}
+			if file.Operation == FileOperationDelete {
+				if err := t.RemoveFilesFromIndex(file.TreePath); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			if file.FromTreePath != file.TreePath {
+				if err := checkNewTreePath(baseCommit, file.TreePath); err != nil {
+					return nil, err
+				}
+				if err := t.RemoveFilesFromIndex(file.FromTreePath); err != nil {
+					return nil, err
+				}
+			}
+			mode = fromEntry.Mode()
+		default:
+			return nil, models.ErrFilePathInvalid{
+				Message: fmt.Sprintf("unknown operation %q [path: %s]", file.Operation, file.TreePath),
+				Path:    file.TreePath,
+				Name:    path.Base(file.TreePath),
+			}
+		}
+		if file.Executable != nil {
+			mode = git.EntryModeBlob
+			if *file.Executable {
+				mode = git.EntryModeExec
+			}
+		}
+
+		content := file.Content
+		if lfsPaths[file.TreePath] {
+			pointer, err := lfs.GeneratePointer(strings.NewReader(file.Content))
+			if err != nil {
+				return nil, err
+			}
+			lfsChanges = append(lfsChanges, &lfsChange{
+				metaObject: &models.LFSMetaObject{Pointer: pointer, RepositoryID: repo.ID},
+				content:    file.Content,
+			})
+			content = pointer.StringContent()
+		}
+		objectHash, err := t.HashObject(strings.NewReader(content))
+		if err != nil {
+			return nil, err
+		}
+		if err := t.AddObjectToIndex(mode.String(), objectHash, file.TreePath); err != nil {
+			return nil, err
+		}
+	}
+
+	treeHash, err := t.WriteTree()
+	if err != nil {
+		return nil, err
+	}
+
+	message := strings.TrimSpace(opts.Message)
+	author, committer := GetAuthorAndCommitterUsers(opts.Author, opts.Committer, doer)
+
+	var commitHash string
+	if opts.Signature != "" {
+		commitHash, err = t.CommitTreeWithSignature(opts.BaseCommitID, author, committer, treeHash, message, opts.Signature, opts.Dates.Author, opts.Dates.Committer)
+	} else if opts.Dates != nil {
+		commitHash, err = t.CommitTreeWithDate(opts.BaseCommitID, author, committer, treeHash, message, opts.Signoff, opts.Dates.Author, opts.Dates.Committer)
+	} else {
+		commitHash, err = t.CommitTree(opts.BaseCommitID, author, committer, treeHash, message, opts.Signoff)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	for _, change := range lfsChanges {
+		if err := storeLFSObject(repo, change); err != nil {
+			return nil, err
+		}
+	}
+
+	// Then push the commit to the branch, unless it has been changed in the meantime
+	expectedCommitID := ""
+	if branchExists {
+		expectedCommitID = opts.BaseCommitID
+	}
+	if err := t.PushWithLease(doer, commitHash, opts.Branch, expectedCommitID); err != nil {
+		return nil, err
+	}
+
+	commit, err := t.GetCommit(commitHash)
+	if err != nil {
+		return nil, err
+	}
+
+	fileCommitResponse, _ := GetFileCommitResponse(repo, commit) // ok if fails, then will be nil
+	verification := GetPayloadCommitVerification(commit)
+	return &structs.FileResponse{
+		Commit:       fileCommitResponse,
+		Verification: verification,
+	}, nil
+}
+
+// checkNewTreePath checks that no file exists at the tree path in the commit, and no parent of it is a file
+func checkNewTreePath(commit *git.Commit, treePath string) error {
+	treePathParts := strings.Split(treePath, "/")
+	subTreePath := ""
+	for index, part := range treePathParts {
+		subTreePath = path.Join(subTreePath, part)
+		entry, err := commit.GetTreeEntryByPath(subTreePath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if index < len(treePathParts)-1 {
+			if !entry.IsDir() {
+				return models.ErrFilePathInvalid{
+					Message: fmt.Sprintf("a file exists where you’re trying to create a subdirectory [path: %s]", subTreePath),
+					Path:    subTreePath,
+					Name:    part,
+					Type:    entry.Mode(),
+				}
+			}
+		} else if entry.IsDir() {
+			return models.ErrFilePathInvalid{
+				Message: fmt.Sprintf("a directory exists where you’re trying to create a file [path: %s]", subTreePath),
+				Path:    subTreePath,
+				Name:    part,
+				Type:    git.EntryModeTree,
+			}
+		} else {
+			return models.ErrRepoFileAlreadyExists{
+				Path: treePath,
+			}
+		}
+	}
+	return nil
+}
+
+// getLFSTreePaths returns the tree paths of the created and updated files which are stored in LFS
+func getLFSTreePaths(t *TemporaryUploadRepository, changes []*FileChange) (map[string]bool, error) {
+	lfsPaths := make(map[string]bool)
+	if !setting.LFS.StartServer {
+		return lfsPaths, nil
+	}
+
+	filenames := make([]string, 0, len(changes))
+	for _, file := range changes {
+		if file.Operation != FileOperationDelete {
+			filenames = append(filenames, file.TreePath)
+		}
+	}
+	if len(filenames) == 0 {
+		return lfsPaths, nil
+	}
+
+	filename2attribute2info, err := t.gitRepo.CheckAttribute(git.CheckAttributeOpts{
+		Attributes: []string{"filter"},
+		Filenames:  filenames,
+		CachedOnly: true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, filename := range filenames {
+		if filename2attribute2info[filename] != nil && filename2attribute2info[filename]["filter"] == "lfs" {
+			lfsPaths[filename] = true
+		}
+	}
+	return lfsPaths, nil
+}
+
+func storeLFSObject(repo *repo_model.Repository, change *lfsChange) error {
+	metaObject, err := models.NewLFSMetaObject(change.metaObject)
+	if err != nil {
+		return err
+	}
+	contentStore := lfs.NewContentStore()
+	exist, err := contentStore.Exists(metaObject.Pointer)
+	if err != nil {
+		return err
+	}
+	if exist {
+		return nil
+	}
+	if err := contentStore.Put(metaObject.Pointer, strings.NewReader(change.content)); err != nil {
+		if _, err2 := models.RemoveLFSMetaObjectByOid(repo.ID, metaObject.Oid); err2 != nil {
+			return fmt.Errorf("Error whilst removing failed inserted LFS object %s: %v (Prev Error: %v)", metaObject.Oid, err2, err)
+		}
+		return err
+	}
+	return nil
+}
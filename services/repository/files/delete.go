@@ -67,6 +67,9 @@ func DeleteRepoFile(ctx context.Context, repo *repo_model.Repository, doer *user
 	} else if err := VerifyBranchProtection(ctx, repo, doer, opts.OldBranch, opts.TreePath); err != nil {
 		return nil, err
 	}
+	if err := VerifyEditorFileRules(repo, opts.NewBranch == opts.OldBranch, opts.TreePath); err != nil {
+		return nil, err
+	}
 
 	// Check that the path given in opts.treeName is valid (not a git path)
 	treePath := CleanUploadFileName(opts.TreePath)
@@ -47,6 +47,7 @@ func getExpectedFileResponse() *api.FileResponse {
 			Path:        treePath,
 			SHA:         sha,
 			Type:        "file",
+			Mode:        "100644",
 			Size:        30,
 			Encoding:    &encoding,
 			Content:     &content,
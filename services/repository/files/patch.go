@@ -66,7 +66,7 @@ func (opts *ApplyDiffPatchOptions) Validate(ctx context.Context, repo *repo_mode
 			return err
 		}
 	} else {
-		protectedBranch, err := models.GetProtectedBranchBy(ctx, repo.ID, opts.OldBranch)
+		protectedBranch, err := models.GetEffectiveProtectedBranch(ctx, repo.ID, opts.OldBranch)
 		if err != nil {
 			return err
 		}
@@ -154,6 +154,10 @@ func ApplyDiffPatch(ctx context.Context, repo *repo_model.Repository, doer *user
 		return nil, fmt.Errorf("Error: Stdout: %s\nStderr: %s\nErr: %v", stdout.String(), stderr.String(), err)
 	}
 
+	if err := t.VerifyEditorFileRules(opts.NewBranch == opts.OldBranch); err != nil {
+		return nil, err
+	}
+
 	// Now write the tree
 	treeHash, err := t.WriteTree()
 	if err != nil {
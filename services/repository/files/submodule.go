@@ -0,0 +1,81 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package files
+
+import (
+	"fmt"
+	"strings"
+)
+
+// updateGitModules returns the content of a .gitmodules file with the submodule at fromPath moved to treePath
+// and its url set to url. An empty url keeps the url of the submodule, a submodule which isn't in the file is
+// added to it if the url is given.
+func updateGitModules(gitModules, fromPath, treePath, url string) string {
+	lines := strings.Split(strings.TrimRight(gitModules, "\n"), "\n")
+	if gitModules == "" {
+		lines = nil
+	}
+
+	// find the section of the submodule by its path, the name of a section doesn't have to match its path
+	start, end := -1, len(lines)
+	sectionStart := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if strings.HasPrefix(trimmed, "[") {
+			if start != -1 {
+				end = i
+				break
+			}
+			sectionStart = -1
+			if strings.HasPrefix(trimmed, "[submodule") {
+				sectionStart = i
+			}
+			continue
+		}
+		if sectionStart != -1 {
+			if key, value := splitGitConfigLine(trimmed); key == "path" && value == fromPath {
+				start = sectionStart
+			}
+		}
+	}
+
+	if start == -1 {
+		if url == "" {
+			return gitModules
+		}
+		lines = append(lines,
+			fmt.Sprintf("[submodule %q]", treePath),
+			"\tpath = "+treePath,
+			"\turl = "+url,
+		)
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	hasURL := false
+	for i := start + 1; i < end; i++ {
+		switch key, _ := splitGitConfigLine(strings.TrimSpace(lines[i])); key {
+		case "path":
+			lines[i] = "\tpath = " + treePath
+		case "url":
+			hasURL = true
+			if url != "" {
+				lines[i] = "\turl = " + url
+			}
+		}
+	}
+	if !hasURL && url != "" {
+		lines = append(lines[:end], append([]string{"\turl = " + url}, lines[end:]...)...)
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+// splitGitConfigLine returns the lower case key and the value of a variable line of a git config file
+func splitGitConfigLine(line string) (string, string) {
+	parts := strings.SplitN(line, "=", 2)
+	if len(parts) != 2 {
+		return "", ""
+	}
+	return strings.ToLower(strings.TrimSpace(parts[0])), strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package files
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestUpdateGitModules(t *testing.T) {
+	gitModules := `[submodule "lib"]
+	path = lib
+	url = https://example.com/lib.git
+[submodule "themes/default"]
+	path = themes/default
+`
+
+	// a new submodule is appended
+	assert.Equal(t, `[submodule "vendor/tool"]
+	path = vendor/tool
+	url = https://example.com/tool.git
+`, updateGitModules("", "vendor/tool", "vendor/tool", "https://example.com/tool.git"))
+	assert.Equal(t, gitModules+`[submodule "tool"]
+	path = tool
+	url = ../tool.git
+`, updateGitModules(gitModules, "tool", "tool", "../tool.git"))
+
+	// the url of an existing submodule is replaced or added
+	assert.Equal(t, `[submodule "lib"]
+	path = lib
+	url = https://example.com/lib2.git
+[submodule "themes/default"]
+	path = themes/default
+`, updateGitModules(gitModules, "lib", "lib", "https://example.com/lib2.git"))
+	assert.Equal(t, `[submodule "lib"]
+	path = lib
+	url = https://example.com/lib.git
+[submodule "themes/default"]
+	path = themes/default
+	url = ../theme.git
+`, updateGitModules(gitModules, "themes/default", "themes/default", "../theme.git"))
+
+	// a moved submodule keeps its name and url
+	assert.Equal(t, `[submodule "lib"]
+	path = third_party/lib
+	url = https://example.com/lib.git
+[submodule "themes/default"]
+	path = themes/default
+`, updateGitModules(gitModules, "lib", "third_party/lib", ""))
+
+	// an unknown submodule without url doesn't change the file
+	assert.Equal(t, gitModules, updateGitModules(gitModules, "other", "other", ""))
+}
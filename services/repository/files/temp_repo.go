@@ -99,6 +99,14 @@ func (t *TemporaryUploadRepository) SetDefaultIndex() error {
 	return nil
 }
 
+// SetIndex sets the git index to the tree of the given commit
+func (t *TemporaryUploadRepository) SetIndex(commitID string) error {
+	if _, _, err := git.NewCommand(t.ctx, "read-tree", commitID).RunStdString(&git.RunOpts{Dir: t.basePath}); err != nil {
+		return fmt.Errorf("SetIndex: %v", err)
+	}
+	return nil
+}
+
 // LsFiles checks if the given filename arguments are in the index
 func (t *TemporaryUploadRepository) LsFiles(filenames ...string) ([]string, error) {
 	stdOut := new(bytes.Buffer)
@@ -130,6 +138,33 @@ func (t *TemporaryUploadRepository) LsFiles(filenames ...string) ([]string, erro
 	return filelist, nil
 }
 
+// DiffIndexNames returns the names of the files of the index which differ from HEAD
+func (t *TemporaryUploadRepository) DiffIndexNames() ([]string, error) {
+	stdout, _, err := git.NewCommand(t.ctx, "diff-index", "--cached", "--name-only", "-z", "HEAD").RunStdString(&git.RunOpts{Dir: t.basePath})
+	if err != nil {
+		return nil, fmt.Errorf("DiffIndexNames: %v", err)
+	}
+	var names []string
+	for _, name := range strings.Split(stdout, "\x00") {
+		if name != "" {
+			names = append(names, name)
+		}
+	}
+	return names, nil
+}
+
+// VerifyEditorFileRules verifies the editor file rules of the repository for the files the index changes
+func (t *TemporaryUploadRepository) VerifyEditorFileRules(directCommit bool) error {
+	if !t.repo.HasEditorFileRules() {
+		return nil
+	}
+	names, err := t.DiffIndexNames()
+	if err != nil {
+		return err
+	}
+	return VerifyEditorFileRules(t.repo, directCommit, names...)
+}
+
 // RemoveFilesFromIndex removes the given files from the index
 func (t *TemporaryUploadRepository) RemoveFilesFromIndex(filenames ...string) error {
 	stdOut := new(bytes.Buffer)
@@ -313,6 +348,48 @@ func (t *TemporaryUploadRepository) CommitTreeWithDate(parent string, author, co
 	return strings.TrimSpace(stdout.String()), nil
 }
 
+// CommitTreeWithSignature creates a commit from a given tree for the user with provided message and signature.
+// The signature is the armored signature of the commit object without it, so the signer has to know the tree and
+// the dates, the message of the commit object ends with a newline.
+func (t *TemporaryUploadRepository) CommitTreeWithSignature(parent string, author, committer *user_model.User, treeHash, message, signature string, authorDate, committerDate time.Time) (string, error) {
+	authorSig := author.NewGitSig()
+	committerSig := committer.NewGitSig()
+
+	commitBytes := new(bytes.Buffer)
+	_, _ = fmt.Fprintf(commitBytes, "tree %s\n", treeHash)
+	if parent != "" {
+		_, _ = fmt.Fprintf(commitBytes, "parent %s\n", parent)
+	}
+	_, _ = fmt.Fprintf(commitBytes, "author %s <%s> %d %s\n", authorSig.Name, authorSig.Email, authorDate.Unix(), authorDate.Format("-0700"))
+	_, _ = fmt.Fprintf(commitBytes, "committer %s <%s> %d %s\n", committerSig.Name, committerSig.Email, committerDate.Unix(), committerDate.Format("-0700"))
+	// Continuation lines of a header start with a space
+	_, _ = commitBytes.WriteString("gpgsig")
+	for _, line := range strings.Split(strings.TrimSpace(signature), "\n") {
+		_, _ = commitBytes.WriteString(" ")
+		_, _ = commitBytes.WriteString(strings.TrimRight(line, "\r"))
+		_, _ = commitBytes.WriteString("\n")
+	}
+	_, _ = commitBytes.WriteString("\n")
+	_, _ = commitBytes.WriteString(message)
+	_, _ = commitBytes.WriteString("\n")
+
+	stdout := new(bytes.Buffer)
+	stderr := new(bytes.Buffer)
+	if err := git.NewCommand(t.ctx, "hash-object", "-t", "commit", "-w", "--stdin").
+		Run(&git.RunOpts{
+			Dir:    t.basePath,
+			Stdin:  commitBytes,
+			Stdout: stdout,
+			Stderr: stderr,
+		}); err != nil {
+		log.Error("Unable to write signed commit in temporary repo: %s (%s) Error: %v\nStdout: %s\nStderr: %s",
+			t.repo.FullName(), t.basePath, err, stdout, stderr)
+		return "", fmt.Errorf("Unable to write signed commit in temporary repo: %s Error: %v\nStdout: %s\nStderr: %s",
+			t.repo.FullName(), err, stdout, stderr)
+	}
+	return strings.TrimSpace(stdout.String()), nil
+}
+
 // Push the provided commitHash to the repository branch by the provided user
 func (t *TemporaryUploadRepository) Push(doer *user_model.User, commitHash, branch string) error {
 	// Because calls hooks we need to pass in the environment
@@ -338,6 +415,29 @@ func (t *TemporaryUploadRepository) Push(doer *user_model.User, commitHash, bran
 	return nil
 }
 
+// PushWithLease pushes the provided commitHash to the repository branch by the provided user, if the branch still
+// points to the expected commit. An empty expected commit requires the branch not to exist.
+func (t *TemporaryUploadRepository) PushWithLease(doer *user_model.User, commitHash, branch, expectedCommitID string) error {
+	env := repo_module.PushingEnvironment(doer, t.repo)
+	branch = git.BranchPrefix + strings.TrimSpace(branch)
+	if err := git.Push(t.ctx, t.basePath, git.PushOptions{
+		Remote:         t.repo.RepoPath(),
+		Branch:         strings.TrimSpace(commitHash) + ":" + branch,
+		ForceWithLease: branch + ":" + expectedCommitID,
+		Env:            env,
+	}); err != nil {
+		if git.IsErrPushOutOfDate(err) || git.IsErrPushRejected(err) {
+			log.Info("Unable to push back to repo from temporary repo: %s (%s)\nError: %v", t.repo.FullName(), t.basePath, err)
+			return err
+		}
+		log.Error("Unable to push back to repo from temporary repo: %s (%s)\nError: %v",
+			t.repo.FullName(), t.basePath, err)
+		return fmt.Errorf("Unable to push back to repo from temporary repo: %s (%s) Error: %v",
+			t.repo.FullName(), t.basePath, err)
+	}
+	return nil
+}
+
 // DiffIndex returns a Diff of the current index to the head
 func (t *TemporaryUploadRepository) DiffIndex() (*gitdiff.Diff, error) {
 	stdoutReader, stdoutWriter, err := os.Pipe()
@@ -93,3 +93,47 @@ func GetTreeBySHA(ctx context.Context, repo *repo_model.Repository, gitRepo *git
 	}
 	return tree, nil
 }
+
+// GetTrees gets the first page of entries of the trees of a repository by their SHAs and by their paths at the commit,
+// the commit is only used if there are paths
+func GetTrees(ctx context.Context, repo *repo_model.Repository, gitRepo *git.Repository, commit *git.Commit, shas, paths []string, recursive bool) (*api.GitTreesResponse, error) {
+	resp := &api.GitTreesResponse{
+		Trees:    make([]*api.GitTreeResponse, 0, len(shas)+len(paths)),
+		NotFound: []string{},
+	}
+	for _, sha := range shas {
+		tree, err := GetTreeBySHA(ctx, repo, gitRepo, sha, 1, 0, recursive)
+		if err != nil {
+			if models.IsErrSHANotFound(err) {
+				resp.NotFound = append(resp.NotFound, sha)
+				continue
+			}
+			return nil, err
+		}
+		resp.Trees = append(resp.Trees, tree)
+	}
+
+	for _, path := range paths {
+		// an empty path is the root tree of the commit
+		treePath := CleanUploadFileName(path)
+		if treePath == "" && path != "" {
+			resp.NotFound = append(resp.NotFound, path)
+			continue
+		}
+		gitTree, err := commit.SubTree(treePath)
+		if err != nil {
+			if git.IsErrNotExist(err) {
+				resp.NotFound = append(resp.NotFound, path)
+				continue
+			}
+			return nil, err
+		}
+		tree, err := GetTreeBySHA(ctx, repo, gitRepo, gitTree.ID.String(), 1, 0, recursive)
+		if err != nil {
+			return nil, err
+		}
+		tree.Path = path
+		resp.Trees = append(resp.Trees, tree)
+	}
+	return resp, nil
+}
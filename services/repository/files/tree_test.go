@@ -51,3 +51,28 @@ func TestGetTreeBySHA(t *testing.T) {
 
 	assert.EqualValues(t, expectedTree, tree)
 }
+
+func TestGetTrees(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+	ctx := test.MockContext(t, "user2/repo1")
+	test.LoadRepo(t, ctx, 1)
+	test.LoadRepoCommit(t, ctx)
+	test.LoadUser(t, ctx, 2)
+	test.LoadGitRepo(t, ctx)
+	defer ctx.Repo.GitRepo.Close()
+
+	trees, err := GetTrees(ctx, ctx.Repo.Repository, ctx.Repo.GitRepo, ctx.Repo.Commit,
+		[]string{"65f1bf27bc3bf70f64657658635e66094edbcb4d", "0000000000000000000000000000000000000001"}, []string{"", "README.md", "missing"}, false)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"0000000000000000000000000000000000000001", "README.md", "missing"}, trees.NotFound)
+	if assert.Len(t, trees.Trees, 2) {
+		// a commit SHA resolves to the tree of the commit, the empty path is the root tree of the commit
+		assert.Equal(t, "65f1bf27bc3bf70f64657658635e66094edbcb4d", trees.Trees[0].SHA)
+		assert.Equal(t, "2a2f1d4670728a2e10049e345bd7a276468beab6", trees.Trees[1].SHA)
+		for _, tree := range trees.Trees {
+			if assert.Len(t, tree.Entries, 1) {
+				assert.Equal(t, "README.md", tree.Entries[0].Path)
+			}
+		}
+	}
+}
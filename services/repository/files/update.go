@@ -51,6 +51,11 @@ type UpdateRepoFileOptions struct {
 	Content      string
 	SHA          string
 	IsNewFile    bool
+	// Mode is the mode of the entry, the content of a symlink is its target and the content of a submodule is
+	// its commit. A new file without a mode is a regular file, an updated file without a mode keeps its executable bit.
+	Mode git.EntryMode
+	// SubmoduleURL is written to .gitmodules for the submodule if it's set
+	SubmoduleURL string
 	Author       *IdentityOptions
 	Committer    *IdentityOptions
 	Dates        *CommitDateOptions
@@ -161,6 +166,9 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 	} else if err := VerifyBranchProtection(ctx, repo, doer, opts.OldBranch, opts.TreePath); err != nil {
 		return nil, err
 	}
+	if err := VerifyEditorFileRules(repo, opts.NewBranch == opts.OldBranch, opts.FromTreePath, opts.TreePath); err != nil {
+		return nil, err
+	}
 
 	// If FromTreePath is not set, set it to the opts.TreePath
 	if opts.TreePath != "" && opts.FromTreePath == "" {
@@ -182,6 +190,27 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 		}
 	}
 
+	switch opts.Mode {
+	case git.EntryModeSymlink:
+		if opts.Content == "" {
+			return nil, models.ErrFilePathInvalid{
+				Message: fmt.Sprintf("a symbolic link needs a target [path: %s]", treePath),
+				Path:    treePath,
+				Name:    path.Base(treePath),
+				Type:    git.EntryModeSymlink,
+			}
+		}
+	case git.EntryModeCommit:
+		if len(opts.Content) != 40 || !git.SHAPattern.MatchString(opts.Content) {
+			return nil, models.ErrFilePathInvalid{
+				Message: fmt.Sprintf("a submodule needs the full SHA of a commit [path: %s]", treePath),
+				Path:    treePath,
+				Name:    path.Base(treePath),
+				Type:    git.EntryModeCommit,
+			}
+		}
+	}
+
 	message := strings.TrimSpace(opts.Message)
 
 	author, committer := GetAuthorAndCommitterUsers(opts.Author, opts.Committer, doer)
@@ -211,6 +240,7 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 	encoding := "UTF-8"
 	bom := false
 	executable := false
+	gitModules := ""
 
 	if hasOldBranch {
 		// Get the commit of the original branch
@@ -264,10 +294,24 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 				// haven't been made. We throw an error if one wasn't provided.
 				return nil, models.ErrSHAOrCommitIDNotProvided{}
 			}
-			encoding, bom = detectEncodingAndBOM(fromEntry, repo)
+			if fromEntry.IsRegular() || fromEntry.IsExecutable() {
+				encoding, bom = detectEncodingAndBOM(fromEntry, repo)
+			}
 			executable = fromEntry.IsExecutable()
 		}
 
+		if opts.Mode == git.EntryModeCommit {
+			entry, err := commit.GetTreeEntryByPath(".gitmodules")
+			if err != nil && !git.IsErrNotExist(err) {
+				return nil, err
+			}
+			if entry != nil {
+				if gitModules, err = entry.Blob().GetBlobContent(); err != nil {
+					return nil, err
+				}
+			}
+		}
+
 		// For the path where this file will be created/updated, we need to make
 		// sure no parts of the path are existing files or links except for the last
 		// item in the path which is the file name, and that shouldn't exist IF it is
@@ -293,7 +337,8 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 						Type:    git.EntryModeBlob,
 					}
 				}
-			} else if entry.IsLink() {
+			} else if entry.IsLink() && (opts.Mode == 0 || fromTreePath != treePath || opts.IsNewFile) {
+				// A symbolic link can only be replaced if the mode of the entry is given
 				return nil, models.ErrFilePathInvalid{
 					Message: fmt.Sprintf("a symbolic link exists where you’re trying to create a subdirectory [path: %s]", subTreePath),
 					Path:    subTreePath,
@@ -344,7 +389,20 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 		}
 	}
 
+	mode := opts.Mode
+	if mode == 0 {
+		mode = git.EntryModeBlob
+		if executable {
+			mode = git.EntryModeExec
+		}
+	}
+	isFile := mode == git.EntryModeBlob || mode == git.EntryModeExec
+
 	content := opts.Content
+	if !isFile {
+		encoding = "UTF-8"
+		bom = false
+	}
 	if bom {
 		content = string(charset.UTF8BOM) + content
 	}
@@ -366,7 +424,7 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 	opts.Content = content
 	var lfsMetaObject *models.LFSMetaObject
 
-	if setting.LFS.StartServer && hasOldBranch {
+	if setting.LFS.StartServer && hasOldBranch && isFile {
 		// Check there is no way this can return multiple infos
 		filename2attribute2info, err := t.gitRepo.CheckAttribute(git.CheckAttributeOpts{
 			Attributes: []string{"filter"},
@@ -387,20 +445,29 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 			content = pointer.StringContent()
 		}
 	}
-	// Add the object to the database
-	objectHash, err := t.HashObject(strings.NewReader(content))
-	if err != nil {
-		return nil, err
+	// Add the object to the database, the commit of a submodule is in another repository
+	objectHash := content
+	if mode != git.EntryModeCommit {
+		if objectHash, err = t.HashObject(strings.NewReader(content)); err != nil {
+			return nil, err
+		}
 	}
 
 	// Add the object to the index
-	if executable {
-		if err := t.AddObjectToIndex("100755", objectHash, treePath); err != nil {
-			return nil, err
-		}
-	} else {
-		if err := t.AddObjectToIndex("100644", objectHash, treePath); err != nil {
-			return nil, err
+	if err := t.AddObjectToIndex(mode.String(), objectHash, treePath); err != nil {
+		return nil, err
+	}
+
+	// Keep the .gitmodules in sync with the path and the url of the submodule
+	if mode == git.EntryModeCommit && (opts.SubmoduleURL != "" || fromTreePath != treePath) {
+		if newGitModules := updateGitModules(gitModules, fromTreePath, treePath, opts.SubmoduleURL); newGitModules != gitModules {
+			gitModulesHash, err := t.HashObject(strings.NewReader(newGitModules))
+			if err != nil {
+				return nil, err
+			}
+			if err := t.AddObjectToIndex(git.EntryModeBlob.String(), gitModulesHash, ".gitmodules"); err != nil {
+				return nil, err
+			}
 		}
 	}
 
@@ -462,7 +529,7 @@ func CreateOrUpdateRepoFile(ctx context.Context, repo *repo_model.Repository, do
 
 // VerifyBranchProtection verify the branch protection for modifying the given treePath on the given branch
 func VerifyBranchProtection(ctx context.Context, repo *repo_model.Repository, doer *user_model.User, branchName, treePath string) error {
-	protectedBranch, err := models.GetProtectedBranchBy(ctx, repo.ID, branchName)
+	protectedBranch, err := models.GetEffectiveProtectedBranch(ctx, repo.ID, branchName)
 	if err != nil {
 		return err
 	}
@@ -499,3 +566,21 @@ func VerifyBranchProtection(ctx context.Context, repo *repo_model.Repository, do
 	}
 	return nil
 }
+
+// VerifyEditorFileRules verifies the editor file rules of the repository for changing the given tree paths,
+// directCommit is true if the change is committed to an existing branch instead of a new one.
+// The rules apply to all the users, including the administrators.
+func VerifyEditorFileRules(repo *repo_model.Repository, directCommit bool, treePaths ...string) error {
+	for _, treePath := range treePaths {
+		if treePath == "" {
+			continue
+		}
+		if repo.IsEditorLockedFile(treePath) {
+			return models.ErrFilePathLocked{Path: treePath}
+		}
+		if directCommit && repo.IsEditorPullRequestFile(treePath) {
+			return models.ErrFilePathRequiresPullRequest{Path: treePath}
+		}
+	}
+	return nil
+}
@@ -65,6 +65,9 @@ func UploadRepoFiles(ctx context.Context, repo *repo_model.Repository, doer *use
 	for i, upload := range uploads {
 		// Check file is not lfs locked, will return nil if lock setting not enabled
 		filepath := path.Join(opts.TreePath, upload.Name)
+		if err := VerifyEditorFileRules(repo, opts.NewBranch == opts.OldBranch, filepath); err != nil {
+			return err
+		}
 		lfsLock, err := models.GetTreePathLock(repo.ID, filepath)
 		if err != nil {
 			return err
@@ -7,6 +7,7 @@ package repository
 import (
 	"context"
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -29,13 +30,34 @@ type ForkRepoOptions struct {
 	Description string
 }
 
-// ForkRepository forks a repository
+// ForkName returns the name suggested for a fork of the repository owned by owner, that is the name
+// of the repository itself, or the first unused name with a "-fork" suffix in the namespace of the repository
+func ForkName(ctx context.Context, owner *user_model.User, baseRepo *repo_model.Repository) (string, error) {
+	if owner.ID != baseRepo.OwnerID {
+		return baseRepo.Name, nil
+	}
+
+	name := baseRepo.Name + "-fork"
+	for i := 2; ; i++ {
+		has, err := repo_model.IsRepositoryExist(ctx, owner, name)
+		if err != nil {
+			return "", err
+		} else if !has {
+			return name, nil
+		}
+		name = fmt.Sprintf("%s-fork-%d", baseRepo.Name, i)
+	}
+}
+
+// ForkRepository forks a repository. Other owners may only fork a repository once,
+// while the owner of the base repository may fork it into its own namespace under
+// any unused name.
 func ForkRepository(ctx context.Context, doer, owner *user_model.User, opts ForkRepoOptions) (*repo_model.Repository, error) {
 	forkedRepo, err := repo_model.GetUserFork(ctx, opts.BaseRepo.ID, owner.ID)
 	if err != nil {
 		return nil, err
 	}
-	if forkedRepo != nil {
+	if forkedRepo != nil && owner.ID != opts.BaseRepo.OwnerID {
 		return nil, models.ErrForkAlreadyExist{
 			Uname:    owner.Name,
 			RepoName: opts.BaseRepo.FullName(),
@@ -161,8 +183,30 @@ func ForkRepository(ctx context.Context, doer, owner *user_model.User, opts Fork
 	return repo, nil
 }
 
+// ErrForkParentNotAllowed represents an error that a repository can not be declared a fork of another one
+type ErrForkParentNotAllowed struct {
+	RepoName   string
+	ParentName string
+	Reason     string
+}
+
+// IsErrForkParentNotAllowed checks if an error is an ErrForkParentNotAllowed.
+func IsErrForkParentNotAllowed(err error) bool {
+	_, ok := err.(ErrForkParentNotAllowed)
+	return ok
+}
+
+func (err ErrForkParentNotAllowed) Error() string {
+	return fmt.Sprintf("repository %s can not be a fork of %s: %s", err.RepoName, err.ParentName, err.Reason)
+}
+
 // ConvertForkToNormalRepository convert the provided repo from a forked repo to normal repo
-func ConvertForkToNormalRepository(repo *repo_model.Repository) error {
+func ConvertForkToNormalRepository(ctx context.Context, repo *repo_model.Repository) error {
+	// a fork must not keep borrowing objects from its network once it has been detached
+	if err := dissociateRepository(ctx, repo); err != nil {
+		return err
+	}
+
 	err := db.WithTx(func(ctx context.Context) error {
 		repo, err := repo_model.GetRepositoryByIDCtx(ctx, repo.ID)
 		if err != nil {
@@ -187,7 +231,80 @@ func ConvertForkToNormalRepository(repo *repo_model.Repository) error {
 		}
 
 		return nil
-	})
+	}, ctx)
 
 	return err
 }
+
+// AttachForkToRepository declares an existing repository a fork of parent, moving it out of
+// the network it was a fork of before. Forks always have their own complete object store,
+// so only the relationship changes and no objects are shared with the new network.
+func AttachForkToRepository(ctx context.Context, repo, parent *repo_model.Repository) error {
+	notAllowed := func(reason string) error {
+		return ErrForkParentNotAllowed{RepoName: repo.FullName(), ParentName: parent.FullName(), Reason: reason}
+	}
+
+	if repo.ID == parent.ID {
+		return notAllowed("a repository can not be a fork of itself")
+	}
+	if repo.IsFork && repo.ForkID == parent.ID {
+		return nil
+	}
+	if err := parent.GetOwner(ctx); err != nil {
+		return err
+	}
+	if !repo.IsPrivate && (parent.IsPrivate || parent.Owner.Visibility == structs.VisibleTypePrivate) {
+		return notAllowed("a public repository can not be a fork of a private repository")
+	}
+
+	// the parent must not be one of the forks of the repository
+	ancestor := parent
+	for ancestor.IsFork {
+		if ancestor.ForkID == repo.ID {
+			return notAllowed("the parent is a fork of the repository")
+		}
+		var err error
+		if ancestor, err = repo_model.GetRepositoryByIDCtx(ctx, ancestor.ForkID); err != nil {
+			return err
+		}
+	}
+
+	return db.WithTx(func(ctx context.Context) error {
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, repo.ID)
+		if err != nil {
+			return err
+		}
+
+		if repo.IsFork {
+			if err := models.DecrementRepoForkNum(ctx, repo.ForkID); err != nil {
+				return err
+			}
+		}
+		if err := models.IncrementRepoForkNum(ctx, parent.ID); err != nil {
+			return err
+		}
+
+		repo.IsFork = true
+		repo.ForkID = parent.ID
+		return models.UpdateRepositoryCtx(ctx, repo, false)
+	}, ctx)
+}
+
+// dissociateRepository copies all objects a repository borrows through git alternates
+// into its own object store and removes the alternates, as `git clone --dissociate` does.
+func dissociateRepository(ctx context.Context, repo *repo_model.Repository) error {
+	repoPath := repo.RepoPath()
+	alternates := filepath.Join(repoPath, "objects", "info", "alternates")
+	exist, err := util.IsExist(alternates)
+	if err != nil || !exist {
+		return err
+	}
+
+	if stdout, _, err := git.NewCommand(ctx, "repack", "-a", "-d").
+		SetDescription(fmt.Sprintf("dissociateRepository(git repack): %s", repo.FullName())).
+		RunStdString(&git.RunOpts{Dir: repoPath}); err != nil {
+		log.Error("Dissociate Repository (git repack) failed for %v:\nStdout: %s\nError: %v", repo, stdout, err)
+		return fmt.Errorf("git repack: %v", err)
+	}
+	return util.Remove(alternates)
+}
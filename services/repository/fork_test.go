@@ -5,9 +5,12 @@
 package repository
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 
 	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	user_model "code.gitea.io/gitea/models/user"
@@ -32,3 +35,103 @@ func TestForkRepository(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, models.IsErrForkAlreadyExist(err))
 }
+
+func TestForkRepositoryIntoSameOwner(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 10}).(*repo_model.Repository)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo.OwnerID}).(*user_model.User)
+	assert.NoError(t, repo.GetOwner(git.DefaultContext))
+
+	// the original name is taken in the same namespace
+	fork, err := ForkRepository(git.DefaultContext, owner, owner, ForkRepoOptions{
+		BaseRepo: repo,
+		Name:     repo.Name,
+	})
+	assert.Nil(t, fork)
+	assert.True(t, repo_model.IsErrRepoAlreadyExist(err))
+
+	fork, err = ForkRepository(git.DefaultContext, owner, owner, ForkRepoOptions{
+		BaseRepo: repo,
+		Name:     repo.Name + "-fork",
+	})
+	assert.NoError(t, err)
+	if assert.NotNil(t, fork) {
+		assert.True(t, fork.IsFork)
+		assert.EqualValues(t, repo.ID, fork.ForkID)
+		assert.EqualValues(t, owner.ID, fork.OwnerID)
+	}
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: repo.ID, NumForks: repo.NumForks + 1})
+}
+
+func TestAttachForkToRepository(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	loadRepo := func(id int64) *repo_model.Repository {
+		return unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: id}).(*repo_model.Repository)
+	}
+
+	// a repository can not be a fork of itself
+	err := AttachForkToRepository(db.DefaultContext, loadRepo(1), loadRepo(1))
+	assert.True(t, IsErrForkParentNotAllowed(err))
+
+	// a public repository can not be a fork of a private one
+	err = AttachForkToRepository(db.DefaultContext, loadRepo(1), loadRepo(2))
+	assert.True(t, IsErrForkParentNotAllowed(err))
+
+	assert.NoError(t, AttachForkToRepository(db.DefaultContext, loadRepo(11), loadRepo(10)))
+	repo11 := loadRepo(11)
+	assert.True(t, repo11.IsFork)
+	assert.EqualValues(t, 10, repo11.ForkID)
+	assert.EqualValues(t, 2, loadRepo(10).NumForks)
+
+	// the parent must not be a fork of the repository
+	err = AttachForkToRepository(db.DefaultContext, loadRepo(10), repo11)
+	assert.True(t, IsErrForkParentNotAllowed(err))
+
+	// moving a fork into another network
+	assert.NoError(t, AttachForkToRepository(db.DefaultContext, repo11, loadRepo(32)))
+	assert.EqualValues(t, 32, loadRepo(11).ForkID)
+	assert.EqualValues(t, 1, loadRepo(10).NumForks)
+	assert.EqualValues(t, 1, loadRepo(32).NumForks)
+
+	// objects borrowed through alternates are copied when the fork is detached
+	alternates := filepath.Join(repo11.RepoPath(), "objects", "info", "alternates")
+	assert.NoError(t, os.WriteFile(alternates, []byte(filepath.Join(loadRepo(10).RepoPath(), "objects")+"\n"), 0o644))
+
+	assert.NoError(t, ConvertForkToNormalRepository(db.DefaultContext, loadRepo(11)))
+	assert.NoFileExists(t, alternates)
+	repo11 = loadRepo(11)
+	assert.False(t, repo11.IsFork)
+	assert.EqualValues(t, 0, repo11.ForkID)
+	assert.EqualValues(t, 0, loadRepo(32).NumForks)
+}
+
+func TestForkName(t *testing.T) {
+	unittest.PrepareTestEnv(t)
+
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 10}).(*repo_model.Repository)
+	owner := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo.OwnerID}).(*user_model.User)
+	other := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 13}).(*user_model.User)
+	assert.NoError(t, repo.GetOwner(git.DefaultContext))
+
+	// other owners keep the original name
+	name, err := ForkName(git.DefaultContext, other, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.Name, name)
+
+	name, err = ForkName(git.DefaultContext, owner, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.Name+"-fork", name)
+
+	_, err = ForkRepository(git.DefaultContext, owner, owner, ForkRepoOptions{
+		BaseRepo: repo,
+		Name:     name,
+	})
+	assert.NoError(t, err)
+
+	// the suffix is numbered once it is taken
+	name, err = ForkName(git.DefaultContext, owner, repo)
+	assert.NoError(t, err)
+	assert.Equal(t, repo.Name+"-fork-2", name)
+}
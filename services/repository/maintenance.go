@@ -0,0 +1,81 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	admin_model "code.gitea.io/gitea/models/admin"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/log"
+)
+
+// MaintainBusyRepos runs MaintainRepo on at most maxRepos repositories which have been pushed to
+// at least minPushes times since their last maintenance, the busiest first
+func MaintainBusyRepos(ctx context.Context, timeout time.Duration, minPushes int64, maxRepos int) error {
+	log.Trace("Doing: MaintainBusyRepos")
+
+	ms, err := repo_model.FindReposNeedingMaintenance(ctx, minPushes, maxRepos)
+	if err != nil {
+		return err
+	}
+	for _, m := range ms {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before maintenance of repository %d", m.RepoID)
+		default:
+		}
+
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, m.RepoID)
+		if err != nil {
+			if repo_model.IsErrRepoNotExist(err) {
+				continue
+			}
+			return err
+		}
+
+		start := time.Now()
+		maintenanceErr := MaintainRepo(ctx, repo, timeout)
+		if maintenanceErr != nil {
+			log.Error("Repository maintenance failed for %v: %v", repo, maintenanceErr)
+			if err := admin_model.CreateRepositoryNotice("Repository maintenance failed for %s: %v", repo.FullName(), maintenanceErr); err != nil {
+				log.Error("CreateRepositoryNotice: %v", err)
+			}
+		}
+		if err := repo_model.FinishRepoMaintenance(ctx, repo.ID, m.PushCount, time.Since(start).Milliseconds(), maintenanceErr); err != nil {
+			return err
+		}
+	}
+
+	log.Trace("Finished: MaintainBusyRepos")
+	return nil
+}
+
+// MaintainRepo repacks the git data of a repository into a single pack with a reachability bitmap
+// and writes its commit-graph, which keeps clones and fetches of busy repositories fast.
+// The size of the repository is updated afterwards.
+func MaintainRepo(ctx context.Context, repo *repo_model.Repository, timeout time.Duration) error {
+	log.Trace("Running maintenance on %v", repo)
+
+	commands := [][]string{{"repack", "-a", "-d", "-l", "--write-bitmap-index"}}
+	if git.CheckGitVersionAtLeast("2.18") == nil {
+		commands = append(commands, []string{"commit-graph", "write", "--reachable"})
+	}
+	for _, args := range commands {
+		_, stderr, err := git.NewCommand(ctx, args...).
+			SetDescription(fmt.Sprintf("Repository Maintenance (git %s): %s", args[0], repo.FullName())).
+			RunStdString(&git.RunOpts{Timeout: timeout, Dir: repo.RepoPath()})
+		if err != nil {
+			return fmt.Errorf("git %s: %v - %s", args[0], err, stderr)
+		}
+	}
+
+	return models.UpdateRepoSize(ctx, repo)
+}
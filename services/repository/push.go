@@ -27,6 +27,7 @@ import (
 	"code.gitea.io/gitea/modules/timeutil"
 	issue_service "code.gitea.io/gitea/services/issue"
 	pull_service "code.gitea.io/gitea/services/pull"
+	"code.gitea.io/gitea/services/reposettings"
 )
 
 // pushQueue represents a queue to handle update pull request tests
@@ -98,6 +99,9 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 	if err = models.UpdateRepoSize(ctx, repo); err != nil {
 		log.Error("Failed to update size for repository: %v", err)
 	}
+	if err = repo_model.IncreaseMaintenancePushCount(ctx, repo.ID); err != nil {
+		log.Error("Failed to count push for maintenance of repository: %v", err)
+	}
 
 	addTags := make([]string, 0, len(optsList))
 	delTags := make([]string, 0, len(optsList))
@@ -252,14 +256,18 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 
 				notification.NotifyPushCommits(pusher, repo, opts, commits)
 
-				if err = models.RemoveDeletedBranchByName(repo.ID, branch); err != nil {
-					log.Error("models.RemoveDeletedBranch %s/%s failed: %v", repo.ID, branch, err)
+				if err = removeDeletedBranchesByName(ctx, repo, branch); err != nil {
+					log.Error("removeDeletedBranchesByName %s/%s failed: %v", repo.ID, branch, err)
 				}
 
 				// Cache for big repository
 				if err := CacheRef(graceful.GetManager().HammerContext(), repo, gitRepo, opts.RefFullName); err != nil {
 					log.Error("repo_module.CacheRef %s/%s failed: %v", repo.ID, branch, err)
 				}
+
+				if branch == repo.DefaultBranch {
+					reposettings.ReconcileOnPush(ctx, pusher, repo, gitRepo, opts.OldCommitID, opts.NewCommitID)
+				}
 			} else {
 				notification.NotifyDeleteRef(pusher, repo, "branch", opts.RefFullName)
 				if err = pull_service.CloseBranchPulls(pusher, repo.ID, branch); err != nil {
@@ -281,8 +289,8 @@ func pushUpdates(optsList []*repo_module.PushUpdateOptions) error {
 	}
 
 	// Change repository last updated time.
-	if err := repo_model.UpdateRepositoryUpdatedTime(repo.ID, time.Now()); err != nil {
-		return fmt.Errorf("UpdateRepositoryUpdatedTime: %v", err)
+	if err := repo_model.UpdateRepositoryPushedTime(repo.ID, time.Now()); err != nil {
+		return fmt.Errorf("UpdateRepositoryPushedTime: %v", err)
 	}
 
 	return nil
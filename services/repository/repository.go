@@ -53,6 +53,29 @@ func DeleteRepository(ctx context.Context, doer *user_model.User, repo *repo_mod
 	return packages_model.UnlinkRepositoryFromAllPackages(ctx, repo.ID)
 }
 
+// DeleteOwnerRepositories deletes all repositories of a user or organization, including trashed ones.
+func DeleteOwnerRepositories(ctx context.Context, doer, owner *user_model.User) error {
+	for {
+		repos, _, err := models.GetUserRepositories(&models.SearchRepoOptions{
+			ListOptions:    db.ListOptions{PageSize: setting.API.MaxResponseItems},
+			Actor:          owner,
+			Private:        true,
+			IncludeTrashed: true,
+		})
+		if err != nil {
+			return fmt.Errorf("GetUserRepositories: %v", err)
+		}
+		if len(repos) == 0 {
+			return nil
+		}
+		for _, repo := range repos {
+			if err := DeleteRepository(ctx, doer, repo, true); err != nil {
+				return fmt.Errorf("DeleteRepository [%d]: %v", repo.ID, err)
+			}
+		}
+	}
+}
+
 // PushCreateRepo creates a repository when a new repository is pushed to an appropriate namespace
 func PushCreateRepo(authUser, owner *user_model.User, repoName string) (*repo_model.Repository, error) {
 	if !authUser.IsAdmin {
@@ -0,0 +1,77 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net"
+	"strconv"
+	"sync"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+)
+
+// trafficSalt is the salt of the hashes of the anonymous visitors, it changes every day and is never stored
+// so the hashes can't be traced back to the addresses once the day is over.
+var trafficSalt struct {
+	sync.Mutex
+	day  int64
+	salt []byte
+}
+
+func trafficSaltOfToday() ([]byte, error) {
+	day := int64(timeutil.TimeStampNow()) / (24 * 60 * 60)
+
+	trafficSalt.Lock()
+	defer trafficSalt.Unlock()
+	if trafficSalt.salt == nil || trafficSalt.day != day {
+		salt, err := util.CryptoRandomBytes(32)
+		if err != nil {
+			return nil, err
+		}
+		trafficSalt.day, trafficSalt.salt = day, salt
+	}
+	return trafficSalt.salt, nil
+}
+
+// trafficVisitor identifies the visitor of the traffic, anonymous visitors by a salted hash of their address
+func trafficVisitor(doer *user_model.User, remoteAddr string) (string, error) {
+	if doer != nil {
+		return "user:" + strconv.FormatInt(doer.ID, 10), nil
+	}
+	if host, _, err := net.SplitHostPort(remoteAddr); err == nil {
+		remoteAddr = host
+	}
+	salt, err := trafficSaltOfToday()
+	if err != nil {
+		return "", err
+	}
+	h := hmac.New(sha256.New, salt)
+	_, _ = h.Write([]byte(remoteAddr))
+	return "ip:" + hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// RecordTraffic counts a git operation or download of the doer or, if it is nil, the anonymous visitor
+// with the remote address on the repository. Downloads are only counted if their statistics are enabled.
+func RecordTraffic(ctx context.Context, repoID int64, kind repo_model.TrafficKind, doer *user_model.User, remoteAddr string) {
+	if kind.IsDownload() && !setting.Repository.EnableDownloadStatistics {
+		return
+	}
+	visitor, err := trafficVisitor(doer, remoteAddr)
+	if err == nil {
+		err = repo_model.RecordTraffic(ctx, repoID, kind, visitor)
+	}
+	if err != nil {
+		log.Error("Unable to record the %s traffic of repository %d: %v", kind.Name(), repoID, err)
+	}
+}
@@ -0,0 +1,59 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrafficVisitor(t *testing.T) {
+	defer timeutil.Unset()
+	timeutil.Set(time.Date(2022, 8, 20, 10, 0, 0, 0, time.UTC))
+
+	visitor, err := trafficVisitor(&user_model.User{ID: 2}, "127.0.0.1:1234")
+	assert.NoError(t, err)
+	assert.Equal(t, "user:2", visitor)
+
+	visitor, err = trafficVisitor(nil, "127.0.0.1:1234")
+	assert.NoError(t, err)
+	other, _ := trafficVisitor(nil, "127.0.0.1:5678")
+	assert.Equal(t, visitor, other, "the port must not matter")
+	other, _ = trafficVisitor(nil, "127.0.0.2:1234")
+	assert.NotEqual(t, visitor, other)
+
+	// the salt changes with the day, so the visitor of yesterday can't be recognized today
+	timeutil.Set(time.Date(2022, 8, 21, 10, 0, 0, 0, time.UTC))
+	other, _ = trafficVisitor(nil, "127.0.0.1:1234")
+	assert.NotEqual(t, visitor, other)
+}
+
+func TestRecordTraffic(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	defer func(enabled bool) {
+		setting.Repository.EnableDownloadStatistics = enabled
+	}(setting.Repository.EnableDownloadStatistics)
+
+	setting.Repository.EnableDownloadStatistics = false
+	RecordTraffic(db.DefaultContext, 1, repo_model.TrafficKindClone, nil, "127.0.0.1:1234")
+	RecordTraffic(db.DefaultContext, 1, repo_model.TrafficKindRaw, nil, "127.0.0.1:1234")
+	unittest.AssertCount(t, &repo_model.RepoTraffic{RepoID: 1, Kind: repo_model.TrafficKindClone}, 1)
+	unittest.AssertCount(t, &repo_model.RepoTraffic{RepoID: 1, Kind: repo_model.TrafficKindRaw}, 0)
+
+	setting.Repository.EnableDownloadStatistics = true
+	RecordTraffic(db.DefaultContext, 1, repo_model.TrafficKindRaw, nil, "127.0.0.1:1234")
+	RecordTraffic(db.DefaultContext, 1, repo_model.TrafficKindArchive, &user_model.User{ID: 2}, "127.0.0.1:1234")
+	unittest.AssertCount(t, &repo_model.RepoTraffic{RepoID: 1, Kind: repo_model.TrafficKindRaw}, 1)
+	unittest.AssertExistsAndLoadBean(t, &repo_model.RepoTraffic{RepoID: 1, Kind: repo_model.TrafficKindArchive, Visitor: "user:2"})
+}
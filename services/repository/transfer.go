@@ -5,6 +5,7 @@
 package repository
 
 import (
+	"context"
 	"fmt"
 
 	"code.gitea.io/gitea/models"
@@ -17,6 +18,7 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/sync"
+	"code.gitea.io/gitea/modules/timeutil"
 )
 
 // repoWorkingPool represents a working pool to order the parallel changes to the same repository
@@ -24,7 +26,8 @@ import (
 var repoWorkingPool = sync.NewExclusivePool()
 
 // TransferOwnership transfers all corresponding setting from old user to new one.
-func TransferOwnership(doer, newOwner *user_model.User, repo *repo_model.Repository, teams []*organization.Team) error {
+// The old location redirects to the new one for redirectDays days, forever if it is 0.
+func TransferOwnership(doer, newOwner *user_model.User, repo *repo_model.Repository, teams []*organization.Team, redirectDays int) error {
 	if err := repo.GetOwner(db.DefaultContext); err != nil {
 		return err
 	}
@@ -37,7 +40,8 @@ func TransferOwnership(doer, newOwner *user_model.User, repo *repo_model.Reposit
 	oldOwner := repo.Owner
 
 	repoWorkingPool.CheckIn(fmt.Sprint(repo.ID))
-	if err := models.TransferOwnership(doer, newOwner.Name, repo); err != nil {
+	redirectExpiry := models.RepoTransferSchedule{RedirectDays: redirectDays}.RedirectExpiry()
+	if err := models.TransferOwnership(doer, newOwner.Name, repo, redirectExpiry); err != nil {
 		repoWorkingPool.CheckOut(fmt.Sprint(repo.ID))
 		return err
 	}
@@ -83,26 +87,36 @@ func ChangeRepositoryName(doer *user_model.User, repo *repo_model.Repository, ne
 }
 
 // StartRepositoryTransfer transfer a repo from one owner to a new one.
-// it make repository into pending transfer state, if doer can not create repo for new owner.
-func StartRepositoryTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository, teams []*organization.Team) error {
+// it make repository into pending transfer state, if doer can not create repo for new owner
+// or if the cutover is scheduled for later.
+func StartRepositoryTransfer(doer, newOwner *user_model.User, repo *repo_model.Repository, teams []*organization.Team, schedule models.RepoTransferSchedule) error {
 	if err := models.TestRepositoryReadyForTransfer(repo.Status); err != nil {
 		return err
 	}
 
 	// Admin is always allowed to transfer || user transfer repo back to his account
-	if doer.IsAdmin || doer.ID == newOwner.ID {
-		return TransferOwnership(doer, newOwner, repo, teams)
-	}
+	canTransfer := doer.IsAdmin || doer.ID == newOwner.ID
 
 	// If new owner is an org and user can create repos he can transfer directly too
-	if newOwner.IsOrganization() {
+	if !canTransfer && newOwner.IsOrganization() {
 		allowed, err := organization.CanCreateOrgRepo(newOwner.ID, doer.ID)
 		if err != nil {
 			return err
 		}
-		if allowed {
-			return TransferOwnership(doer, newOwner, repo, teams)
+		canTransfer = allowed
+	}
+
+	if canTransfer {
+		if !schedule.IsScheduled() {
+			return TransferOwnership(doer, newOwner, repo, teams, schedule.RedirectDays)
+		}
+
+		// The transfer doesn't need to be accepted, it only waits for its cutover
+		repo.Status = repo_model.RepositoryPendingTransfer
+		if err := models.CreatePendingRepositoryTransfer(doer, newOwner, repo.ID, teams, schedule, true); err != nil {
+			return err
 		}
+		return notifyTransferScheduled(doer, repo)
 	}
 
 	// In case the new owner would not have sufficient access to the repo, give access rights for read
@@ -121,7 +135,7 @@ func StartRepositoryTransfer(doer, newOwner *user_model.User, repo *repo_model.R
 
 	// Make repo as pending for transfer
 	repo.Status = repo_model.RepositoryPendingTransfer
-	if err := models.CreatePendingRepositoryTransfer(doer, newOwner, repo.ID, teams); err != nil {
+	if err := models.CreatePendingRepositoryTransfer(doer, newOwner, repo.ID, teams, schedule, false); err != nil {
 		return err
 	}
 
@@ -130,3 +144,84 @@ func StartRepositoryTransfer(doer, newOwner *user_model.User, repo *repo_model.R
 
 	return nil
 }
+
+// AcceptRepositoryTransfer accepts the pending transfer of the repository, it is carried out
+// right away unless its cutover is scheduled for later
+func AcceptRepositoryTransfer(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, transfer *models.RepoTransfer) error {
+	if err := transfer.LoadAttributes(); err != nil {
+		return err
+	}
+
+	if !transfer.Schedule().IsScheduled() {
+		return TransferOwnership(transfer.Doer, transfer.Recipient, repo, transfer.Teams, transfer.RedirectDays)
+	}
+
+	if transfer.Accepted {
+		return nil
+	}
+	if err := models.AcceptScheduledRepositoryTransfer(ctx, transfer); err != nil {
+		return err
+	}
+	return notifyTransferScheduled(doer, repo)
+}
+
+// notifyTransferScheduled notifies the watchers of the repository about the cutover of its accepted transfer
+func notifyTransferScheduled(doer *user_model.User, repo *repo_model.Repository) error {
+	transfer, err := models.GetPendingRepositoryTransfer(repo)
+	if err != nil {
+		return err
+	}
+	if err := transfer.LoadAttributes(); err != nil {
+		return err
+	}
+	notification.NotifyRepoTransferScheduled(doer, repo, transfer)
+	return nil
+}
+
+// ApplyScheduledTransfers carries out the accepted transfers whose cutover is due
+func ApplyScheduledTransfers(ctx context.Context) error {
+	transfers, err := models.FindDueRepositoryTransfers(ctx, timeutil.TimeStampNow())
+	if err != nil {
+		return fmt.Errorf("FindDueRepositoryTransfers: %v", err)
+	}
+
+	for _, transfer := range transfers {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before transferring repository %d", transfer.RepoID)
+		default:
+		}
+		if err := applyScheduledTransfer(transfer); err != nil {
+			log.Error("Unable to transfer repository %d: %v", transfer.RepoID, err)
+		}
+	}
+	return nil
+}
+
+func applyScheduledTransfer(transfer *models.RepoTransfer) error {
+	repo, err := repo_model.GetRepositoryByID(transfer.RepoID)
+	if err != nil {
+		return err
+	}
+	if err := transfer.LoadAttributes(); err != nil {
+		// the transfer is only tried once, a failure must not be retried every time the task runs
+		if cancelErr := models.CancelRepositoryTransfer(repo); cancelErr != nil {
+			log.Error("CancelRepositoryTransfer: %v", cancelErr)
+		}
+		return err
+	}
+
+	if err := TransferOwnership(transfer.Doer, transfer.Recipient, repo, transfer.Teams, transfer.RedirectDays); err != nil {
+		if cancelErr := models.CancelRepositoryTransfer(repo); cancelErr != nil {
+			log.Error("CancelRepositoryTransfer: %v", cancelErr)
+		}
+		return err
+	}
+	log.Trace("Scheduled transfer of repository %d to %s carried out", repo.ID, transfer.Recipient.Name)
+	return nil
+}
+
+// DeleteExpiredRedirects deletes the redirects from the old locations of transferred repositories which have expired
+func DeleteExpiredRedirects(ctx context.Context) error {
+	return repo_model.DeleteExpiredRedirects(ctx, timeutil.TimeStampNow())
+}
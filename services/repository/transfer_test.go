@@ -17,6 +17,7 @@ import (
 	user_model "code.gitea.io/gitea/models/user"
 	"code.gitea.io/gitea/modules/notification"
 	"code.gitea.io/gitea/modules/notification/action"
+	"code.gitea.io/gitea/modules/timeutil"
 	"code.gitea.io/gitea/modules/util"
 
 	"github.com/stretchr/testify/assert"
@@ -38,7 +39,7 @@ func TestTransferOwnership(t *testing.T) {
 	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
 	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
 	repo.Owner = unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: repo.OwnerID}).(*user_model.User)
-	assert.NoError(t, TransferOwnership(doer, doer, repo, nil))
+	assert.NoError(t, TransferOwnership(doer, doer, repo, nil, 0))
 
 	transferredRepo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
 	assert.EqualValues(t, 2, transferredRepo.OwnerID)
@@ -71,7 +72,7 @@ func TestStartRepositoryTransferSetPermission(t *testing.T) {
 	assert.NoError(t, err)
 	assert.False(t, hasAccess)
 
-	assert.NoError(t, StartRepositoryTransfer(doer, recipient, repo, nil))
+	assert.NoError(t, StartRepositoryTransfer(doer, recipient, repo, nil, models.RepoTransferSchedule{}))
 
 	hasAccess, err = access_model.HasAccess(db.DefaultContext, recipient.ID, repo)
 	assert.NoError(t, err)
@@ -79,3 +80,45 @@ func TestStartRepositoryTransferSetPermission(t *testing.T) {
 
 	unittest.CheckConsistencyFor(t, &repo_model.Repository{}, &user_model.User{}, &organization.Team{})
 }
+
+func TestScheduledRepositoryTransfer(t *testing.T) {
+	registerNotifier()
+
+	unittest.PrepareTestEnv(t)
+
+	// a site admin can transfer directly, the transfer only waits for its cutover
+	admin := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+	recipient := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 4}).(*user_model.User)
+	repo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	schedule := models.RepoTransferSchedule{ScheduledUnix: timeutil.TimeStampNow() + 3600, RedirectDays: 7}
+	assert.NoError(t, StartRepositoryTransfer(admin, recipient, repo, nil, schedule))
+
+	repo = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	assert.EqualValues(t, 2, repo.OwnerID)
+	assert.Equal(t, repo_model.RepositoryPendingTransfer, repo.Status)
+	transfer, err := models.GetPendingRepositoryTransfer(repo)
+	assert.NoError(t, err)
+	assert.True(t, transfer.Accepted)
+	assert.Equal(t, schedule, transfer.Schedule())
+
+	// nothing is due yet
+	assert.NoError(t, ApplyScheduledTransfers(db.DefaultContext))
+	unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1, OwnerID: 2})
+
+	// the transfer of repo3 to user1 is accepted and its cutover has come
+	_, err = db.GetEngine(db.DefaultContext).ID(1).Cols("accepted", "scheduled_unix", "redirect_days").
+		Update(&models.RepoTransfer{Accepted: true, ScheduledUnix: timeutil.TimeStampNow() - 60, RedirectDays: 7})
+	assert.NoError(t, err)
+	assert.NoError(t, ApplyScheduledTransfers(db.DefaultContext))
+
+	transferredRepo := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
+	assert.EqualValues(t, 1, transferredRepo.OwnerID)
+	assert.Equal(t, repo_model.RepositoryReady, transferredRepo.Status)
+	unittest.AssertNotExistsBean(t, &models.RepoTransfer{ID: 1})
+
+	redirect := unittest.AssertExistsAndLoadBean(t, &repo_model.Redirect{OwnerID: 3, LowerName: "repo3"}).(*repo_model.Redirect)
+	assert.EqualValues(t, 3, redirect.RedirectRepoID)
+	assert.InDelta(t, int64(timeutil.TimeStampNow())+7*24*3600, int64(redirect.ExpiresUnix), 60)
+
+	unittest.CheckConsistencyFor(t, &repo_model.Repository{}, &user_model.User{}, &organization.Team{})
+}
@@ -0,0 +1,76 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// IsTrashEnabled returns true if deleted repositories are kept in the trash
+func IsTrashEnabled() bool {
+	return setting.Repository.TrashRetentionPeriod > 0
+}
+
+// TrashRepository moves the repository into the trash if the trash is enabled, otherwise it is deleted immediately
+func TrashRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) error {
+	if !IsTrashEnabled() {
+		return DeleteRepository(ctx, doer, repo, true)
+	}
+	return repo_model.TrashRepository(ctx, repo, doer.ID)
+}
+
+// RestoreTrashedRepository takes the repository out of the trash
+func RestoreTrashedRepository(ctx context.Context, doer *user_model.User, repo *repo_model.Repository) error {
+	if err := repo_model.RestoreTrashedRepository(ctx, repo); err != nil {
+		return err
+	}
+	log.Trace("Repository restored from trash by %s: %s", doer.Name, repo.FullName())
+	return nil
+}
+
+// PurgeTrashedRepositories deletes the repositories whose retention period in the trash is over.
+// All trashed repositories are purged if the trash got disabled.
+func PurgeTrashedRepositories(ctx context.Context) error {
+	before := timeutil.TimeStamp(time.Now().Add(-setting.Repository.TrashRetentionPeriod).Unix())
+	repos, _, err := repo_model.FindTrashedRepositories(ctx, &repo_model.FindTrashedRepositoriesOptions{
+		TrashedBefore: before,
+	})
+	if err != nil {
+		return fmt.Errorf("FindTrashedRepositories: %v", err)
+	}
+
+	for _, repo := range repos {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before purging trashed repository %d", repo.ID)
+		default:
+		}
+		if err := PurgeTrashedRepository(ctx, repo); err != nil {
+			log.Error("Unable to purge trashed repository %d: %v", repo.ID, err)
+		}
+	}
+	return nil
+}
+
+// PurgeTrashedRepository deletes the trashed repository in the name of the user who trashed it
+func PurgeTrashedRepository(ctx context.Context, repo *repo_model.Repository) error {
+	doer, err := user_model.GetUserByIDCtx(ctx, repo.TrashedByID)
+	if err != nil {
+		if !user_model.IsErrUserNotExist(err) {
+			return err
+		}
+		doer = user_model.NewGhostUser()
+	}
+	return DeleteRepository(ctx, doer, repo, true)
+}
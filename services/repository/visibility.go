@@ -0,0 +1,112 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/notification"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+)
+
+// ErrForcePrivate is returned when a user who isn't a site administrator tries to make a repository public
+// while all repositories are forced to be private
+var ErrForcePrivate = errors.New("only administrators can make repositories public when FORCE_PRIVATE is enabled")
+
+// CanChangeVisibility checks whether the doer may change the visibility of the repository to the given one
+func CanChangeVisibility(doer *user_model.User, repo *repo_model.Repository, private bool) error {
+	if !private && repo.IsPrivate && setting.Repository.ForcePrivate && !doer.IsAdmin {
+		return ErrForcePrivate
+	}
+	return nil
+}
+
+// ChangeVisibility makes the repository private or public and notifies about the change
+func ChangeVisibility(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, private bool) error {
+	if repo.IsPrivate == private {
+		return nil
+	}
+	if err := CanChangeVisibility(doer, repo, private); err != nil {
+		return err
+	}
+
+	repo.IsPrivate = private
+	if err := models.UpdateRepository(repo, true); err != nil {
+		return fmt.Errorf("UpdateRepository: %v", err)
+	}
+	if err := repo_model.CancelScheduledVisibilityChange(ctx, repo.ID); err != nil {
+		return fmt.Errorf("CancelScheduledVisibilityChange: %v", err)
+	}
+
+	notification.NotifyChangeRepositoryVisibility(doer, repo)
+	return nil
+}
+
+// ScheduleVisibilityChange plans to make the repository private or public at the given time
+func ScheduleVisibilityChange(ctx context.Context, doer *user_model.User, repo *repo_model.Repository, private bool, when timeutil.TimeStamp) error {
+	if err := CanChangeVisibility(doer, repo, private); err != nil {
+		return err
+	}
+	return repo_model.ScheduleVisibilityChange(ctx, &repo_model.ScheduledVisibilityChange{
+		RepoID:        repo.ID,
+		MakePrivate:   private,
+		DoerID:        doer.ID,
+		ScheduledUnix: when,
+	})
+}
+
+// ApplyScheduledVisibilityChanges changes the visibility of the repositories whose scheduled change is due
+func ApplyScheduledVisibilityChanges(ctx context.Context) error {
+	changes, err := repo_model.FindDueVisibilityChanges(ctx, timeutil.TimeStampNow())
+	if err != nil {
+		return fmt.Errorf("FindDueVisibilityChanges: %v", err)
+	}
+
+	for _, change := range changes {
+		select {
+		case <-ctx.Done():
+			return db.ErrCancelledf("before applying the visibility change of repository %d", change.RepoID)
+		default:
+		}
+		if err := applyScheduledVisibilityChange(ctx, change); err != nil {
+			log.Error("Unable to change the visibility of repository %d: %v", change.RepoID, err)
+		}
+	}
+	return nil
+}
+
+func applyScheduledVisibilityChange(ctx context.Context, change *repo_model.ScheduledVisibilityChange) error {
+	// the change is only tried once, a failure must not be retried every time the task runs
+	if err := repo_model.CancelScheduledVisibilityChange(ctx, change.RepoID); err != nil {
+		return err
+	}
+
+	repo, err := repo_model.GetRepositoryByIDCtx(ctx, change.RepoID)
+	if err != nil {
+		return err
+	}
+	if repo.IsFork {
+		// the visibility of forks follows their base repository
+		return nil
+	}
+	doer, err := user_model.GetUserByIDCtx(ctx, change.DoerID)
+	if err != nil {
+		return err
+	}
+
+	if err := ChangeVisibility(ctx, doer, repo, change.MakePrivate); err != nil {
+		return err
+	}
+	log.Trace("Scheduled visibility change of %-v by %s applied", repo, doer.Name)
+	return nil
+}
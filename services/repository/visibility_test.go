@@ -0,0 +1,52 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package repository
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/timeutil"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestApplyScheduledVisibilityChanges(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	repo2 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 2}).(*repo_model.Repository)
+	repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	assert.True(t, repo2.IsPrivate)
+
+	now := timeutil.TimeStampNow()
+	assert.NoError(t, ScheduleVisibilityChange(db.DefaultContext, doer, repo2, true, now.Add(3600)))
+	// scheduling again replaces the planned change
+	assert.NoError(t, ScheduleVisibilityChange(db.DefaultContext, doer, repo2, false, now.Add(-60)))
+	assert.NoError(t, ScheduleVisibilityChange(db.DefaultContext, doer, repo1, true, now.Add(3600)))
+	unittest.AssertCount(t, &repo_model.ScheduledVisibilityChange{RepoID: 2}, 1)
+
+	assert.NoError(t, ApplyScheduledVisibilityChanges(db.DefaultContext))
+
+	repo2 = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 2}).(*repo_model.Repository)
+	assert.False(t, repo2.IsPrivate)
+	unittest.AssertNotExistsBean(t, &repo_model.ScheduledVisibilityChange{RepoID: 2})
+
+	// changes which aren't due yet are kept
+	change, err := repo_model.GetScheduledVisibilityChange(db.DefaultContext, 1)
+	assert.NoError(t, err)
+	if assert.NotNil(t, change) {
+		assert.True(t, change.MakePrivate)
+	}
+	repo1 = unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	assert.False(t, repo1.IsPrivate)
+
+	// changing the visibility directly drops the scheduled change
+	assert.NoError(t, ChangeVisibility(db.DefaultContext, doer, repo1, true))
+	unittest.AssertNotExistsBean(t, &repo_model.ScheduledVisibilityChange{RepoID: 1})
+}
@@ -0,0 +1,280 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package resumable
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+
+	resumable_model "code.gitea.io/gitea/models/resumable"
+	gitea_context "code.gitea.io/gitea/modules/context"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/storage"
+)
+
+// TusVersion is the supported version of the tus resumable upload protocol, see https://tus.io/protocols/resumable-upload.html
+const TusVersion = "1.0.0"
+
+// tusExtensions are the supported extensions of the tus protocol
+const tusExtensions = "creation,termination,expiration"
+
+// Finisher creates the target of a complete upload from its data.
+// If it fails it writes the error response itself and returns false.
+type Finisher func(upload *resumable_model.Upload, file *os.File) bool
+
+func chunkedUploads() *storage.ChunkedUploads {
+	return storage.NewChunkedUploads(setting.ResumableUpload.ChunkedUploadPath)
+}
+
+// ParseMetadata parses the Upload-Metadata header, a comma separated list of keys and base64 encoded values
+func ParseMetadata(header string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		fields := strings.Fields(pair)
+		if len(fields) > 2 {
+			return nil, fmt.Errorf("invalid metadata %q", pair)
+		}
+		value := ""
+		if len(fields) == 2 {
+			decoded, err := base64.StdEncoding.DecodeString(fields[1])
+			if err != nil {
+				return nil, fmt.Errorf("invalid metadata value of %q: %v", fields[0], err)
+			}
+			value = string(decoded)
+		}
+		metadata[fields[0]] = value
+	}
+	return metadata, nil
+}
+
+// serverError logs an internal error and responds with a plain text error
+func serverError(ctx *gitea_context.Context, name string, err error) {
+	log.Error("%s: %v", name, err)
+	ctx.PlainText(http.StatusInternalServerError, "internal server error")
+}
+
+func setTusHeaders(ctx *gitea_context.Context) {
+	ctx.Resp.Header().Set("Tus-Resumable", TusVersion)
+	ctx.Resp.Header().Set("Cache-Control", "no-store")
+}
+
+func setExpiresHeader(ctx *gitea_context.Context, upload *resumable_model.Upload) {
+	expires := upload.UpdatedUnix.AsTime().Add(setting.ResumableUpload.MaxAge)
+	ctx.Resp.Header().Set("Upload-Expires", expires.UTC().Format(http.TimeFormat))
+}
+
+// checkTusResumable rejects requests of clients using another version of the protocol
+func checkTusResumable(ctx *gitea_context.Context) bool {
+	if ctx.Req.Header.Get("Tus-Resumable") != TusVersion {
+		ctx.Resp.Header().Set("Tus-Version", TusVersion)
+		ctx.PlainText(http.StatusPreconditionFailed, "unsupported version of the tus protocol")
+		return false
+	}
+	return true
+}
+
+// Options describes the capabilities of the server, maxSize is the maximum size of an upload or 0 if there is no limit
+func Options(ctx *gitea_context.Context, maxSize int64) {
+	setTusHeaders(ctx)
+	ctx.Resp.Header().Set("Tus-Version", TusVersion)
+	ctx.Resp.Header().Set("Tus-Extension", tusExtensions)
+	if maxSize > 0 {
+		ctx.Resp.Header().Set("Tus-Max-Size", strconv.FormatInt(maxSize, 10))
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// Create starts an upload of the size given by the Upload-Length header.
+// The caller fills in the target of the upload, location is the URL the ids of new uploads are appended to.
+func Create(ctx *gitea_context.Context, upload *resumable_model.Upload, maxSize int64, location string, finish Finisher) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+	setTusHeaders(ctx)
+
+	size, err := strconv.ParseInt(ctx.Req.Header.Get("Upload-Length"), 10, 64)
+	if err != nil || size < 0 {
+		ctx.PlainText(http.StatusBadRequest, "missing or invalid Upload-Length")
+		return
+	}
+	if maxSize > 0 && size > maxSize {
+		ctx.PlainText(http.StatusRequestEntityTooLarge, "upload exceeds the maximum size")
+		return
+	}
+
+	upload.DoerID = ctx.Doer.ID
+	upload.Size = size
+	if err := resumable_model.CreateUpload(ctx, upload); err != nil {
+		serverError(ctx, "CreateUpload", err)
+		return
+	}
+	if err := chunkedUploads().Create(upload.ID); err != nil {
+		if err := resumable_model.DeleteUploadByID(ctx, upload.ID); err != nil {
+			log.Error("Unable to delete resumable upload %s: %v", upload.ID, err)
+		}
+		serverError(ctx, "Create", err)
+		return
+	}
+
+	if upload.IsComplete() && !complete(ctx, upload, finish) {
+		return
+	}
+
+	ctx.Resp.Header().Set("Location", strings.TrimSuffix(location, "/")+"/"+upload.ID)
+	setExpiresHeader(ctx, upload)
+	ctx.Status(http.StatusCreated)
+}
+
+// GetUpload returns the upload of the signed in user with the given id if it matches the target of the request,
+// otherwise it responds with not found
+func GetUpload(ctx *gitea_context.Context, id string, matches func(upload *resumable_model.Upload) bool) *resumable_model.Upload {
+	upload, err := resumable_model.GetUploadByID(ctx, id)
+	if err != nil {
+		if err == resumable_model.ErrUploadNotExist {
+			ctx.Resp.Header().Set("Tus-Resumable", TusVersion)
+			ctx.PlainText(http.StatusNotFound, err.Error())
+			return nil
+		}
+		serverError(ctx, "GetUploadByID", err)
+		return nil
+	}
+	if upload.DoerID != ctx.Doer.ID || !matches(upload) {
+		ctx.Resp.Header().Set("Tus-Resumable", TusVersion)
+		ctx.PlainText(http.StatusNotFound, resumable_model.ErrUploadNotExist.Error())
+		return nil
+	}
+	return upload
+}
+
+// Head returns the number of bytes received so far
+func Head(ctx *gitea_context.Context, upload *resumable_model.Upload) {
+	setTusHeaders(ctx)
+	ctx.Resp.Header().Set("Upload-Offset", strconv.FormatInt(upload.BytesReceived, 10))
+	ctx.Resp.Header().Set("Upload-Length", strconv.FormatInt(upload.Size, 10))
+	setExpiresHeader(ctx, upload)
+	ctx.Status(http.StatusOK)
+}
+
+// Patch appends the body of the request to the upload, the target is created by finish once the upload is complete
+func Patch(ctx *gitea_context.Context, upload *resumable_model.Upload, finish Finisher) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+	setTusHeaders(ctx)
+
+	if ctx.Req.Header.Get("Content-Type") != "application/offset+octet-stream" {
+		ctx.PlainText(http.StatusUnsupportedMediaType, "Content-Type must be application/offset+octet-stream")
+		return
+	}
+	offset, err := strconv.ParseInt(ctx.Req.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		ctx.PlainText(http.StatusBadRequest, "missing or invalid Upload-Offset")
+		return
+	}
+	if offset != upload.BytesReceived {
+		ctx.PlainText(http.StatusConflict, storage.ErrChunkOffsetMismatch.Error())
+		return
+	}
+
+	received, appendErr := chunkedUploads().Append(upload.ID, upload.BytesReceived, upload.Size, ctx.Req.Body)
+	if received != upload.BytesReceived {
+		upload.BytesReceived = received
+		if err := resumable_model.UpdateUploadOffset(ctx, upload); err != nil {
+			serverError(ctx, "UpdateUploadOffset", err)
+			return
+		}
+	}
+	switch appendErr {
+	case nil:
+	case storage.ErrChunkOffsetMismatch:
+		ctx.PlainText(http.StatusConflict, appendErr.Error())
+		return
+	case storage.ErrChunkTooLarge:
+		ctx.PlainText(http.StatusRequestEntityTooLarge, appendErr.Error())
+		return
+	default:
+		serverError(ctx, "Append", appendErr)
+		return
+	}
+
+	if upload.IsComplete() && !complete(ctx, upload, finish) {
+		return
+	}
+
+	ctx.Resp.Header().Set("Upload-Offset", strconv.FormatInt(upload.BytesReceived, 10))
+	if !upload.IsComplete() {
+		setExpiresHeader(ctx, upload)
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// complete creates the target of a complete upload and removes the upload
+func complete(ctx *gitea_context.Context, upload *resumable_model.Upload, finish Finisher) bool {
+	defer func() {
+		if err := Remove(ctx, upload.ID); err != nil {
+			log.Error("Unable to remove resumable upload %s: %v", upload.ID, err)
+		}
+	}()
+
+	file, err := chunkedUploads().Open(upload.ID)
+	if err != nil {
+		serverError(ctx, "Open", err)
+		return false
+	}
+	defer file.Close()
+
+	return finish(upload, file)
+}
+
+// Terminate aborts an upload
+func Terminate(ctx *gitea_context.Context, upload *resumable_model.Upload) {
+	if !checkTusResumable(ctx) {
+		return
+	}
+	setTusHeaders(ctx)
+
+	if err := Remove(ctx, upload.ID); err != nil {
+		serverError(ctx, "Remove", err)
+		return
+	}
+	ctx.Status(http.StatusNoContent)
+}
+
+// Remove deletes the data and the model of an upload
+func Remove(ctx context.Context, id string) error {
+	if err := resumable_model.DeleteUploadByID(ctx, id); err != nil {
+		return err
+	}
+	return chunkedUploads().Remove(id)
+}
+
+// CleanupExpiredUploads removes the uploads which didn't receive data for longer than the configured maximum age
+func CleanupExpiredUploads(ctx context.Context) error {
+	uploads, err := resumable_model.FindExpiredUploads(ctx, setting.ResumableUpload.MaxAge)
+	if err != nil {
+		return err
+	}
+	for _, upload := range uploads {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if err := Remove(ctx, upload.ID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secrets
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+
+	repo_model "code.gitea.io/gitea/models/repo"
+	secret_model "code.gitea.io/gitea/models/secret"
+)
+
+// referencePattern matches references like ${{ secrets.NAME }} or ${{ vars.NAME }}
+var referencePattern = regexp.MustCompile(`\$\{\{\s*(secrets|vars)\.([A-Za-z_][A-Za-z0-9_]*)\s*\}\}`)
+
+// mask replaces the values of secrets in masked strings
+const mask = "***"
+
+// HasReferences returns true if the string references secrets or variables
+func HasReferences(s string) bool {
+	return referencePattern.MatchString(s)
+}
+
+// Expander replaces references to the secrets and variables of a repository or organization
+type Expander struct {
+	secrets   map[string]string
+	variables map[string]string
+}
+
+// NewExpander returns an expander for the secrets and variables available in a repository,
+// including the ones of the organization owning it. The repoID is 0 for organization-wide expansion.
+func NewExpander(ctx context.Context, repoID, orgID int64) (*Expander, error) {
+	if repoID > 0 {
+		repo, err := repo_model.GetRepositoryByIDCtx(ctx, repoID)
+		if err != nil {
+			return nil, err
+		}
+		if err := repo.GetOwner(ctx); err != nil {
+			return nil, err
+		}
+		orgID = 0
+		if repo.Owner.IsOrganization() {
+			orgID = repo.OwnerID
+		}
+	}
+
+	e := new(Expander)
+	var err error
+	if e.secrets, err = secret_model.GetValues(ctx, repoID, orgID, secret_model.TypeSecret); err != nil {
+		return nil, err
+	}
+	if e.variables, err = secret_model.GetValues(ctx, repoID, orgID, secret_model.TypeVariable); err != nil {
+		return nil, err
+	}
+	return e, nil
+}
+
+// Expand replaces the references to secrets and variables, unknown references are left untouched
+func (e *Expander) Expand(s string) string {
+	return referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		match := referencePattern.FindStringSubmatch(ref)
+		values := e.secrets
+		if match[1] == "vars" {
+			values = e.variables
+		}
+		if value, ok := values[strings.ToUpper(match[2])]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// ExpandVariables replaces only the references to variables, references to secrets are left untouched.
+// It is used for values sent to other servers, where expanded secrets could be read by whoever chose the target.
+func (e *Expander) ExpandVariables(s string) string {
+	return referencePattern.ReplaceAllStringFunc(s, func(ref string) string {
+		match := referencePattern.FindStringSubmatch(ref)
+		if match[1] != "vars" {
+			return ref
+		}
+		if value, ok := e.variables[strings.ToUpper(match[2])]; ok {
+			return value
+		}
+		return ref
+	})
+}
+
+// Mask hides the values of the secrets in an expanded string, e.g. before it is logged or stored
+func (e *Expander) Mask(s string) string {
+	for _, value := range e.secrets {
+		if value != "" {
+			s = strings.ReplaceAll(s, value, mask)
+			s = strings.ReplaceAll(s, url.QueryEscape(value), mask)
+		}
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package secrets
+
+import (
+	"testing"
+
+	"code.gitea.io/gitea/models/db"
+	secret_model "code.gitea.io/gitea/models/secret"
+	"code.gitea.io/gitea/models/unittest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestExpander(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	for _, s := range []struct {
+		scope       secret_model.Scope
+		tp          secret_model.Type
+		name, value string
+	}{
+		{secret_model.OrgScope(3), secret_model.TypeSecret, "TOKEN", "org-token"},
+		{secret_model.OrgScope(3), secret_model.TypeVariable, "HOST", "example.com"},
+		{secret_model.RepoScope(3), secret_model.TypeSecret, "TOKEN", "repo token"},
+	} {
+		_, err := secret_model.SetSecret(db.DefaultContext, 2, s.scope, s.tp, s.name, s.value)
+		assert.NoError(t, err)
+	}
+
+	assert.True(t, HasReferences("https://${{ vars.HOST }}/"))
+	assert.False(t, HasReferences("https://{{ vars.HOST }}/"))
+
+	// repository 3 is owned by organization 3
+	e, err := NewExpander(db.DefaultContext, 3, 0)
+	assert.NoError(t, err)
+	expanded := e.Expand("https://${{vars.HOST}}/hook?token=${{ secrets.token }}&other=${{ secrets.OTHER }}")
+	assert.EqualValues(t, "https://example.com/hook?token=repo token&other=${{ secrets.OTHER }}", expanded)
+	assert.EqualValues(t, "https://example.com/hook?token=***&other=${{ secrets.OTHER }}", e.Mask(expanded))
+	assert.EqualValues(t, "token=***", e.Mask("token=repo+token"))
+	assert.EqualValues(t, "https://example.com/hook?token=${{ secrets.TOKEN }}", e.ExpandVariables("https://${{ vars.HOST }}/hook?token=${{ secrets.TOKEN }}"))
+
+	e, err = NewExpander(db.DefaultContext, 0, 3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "org-token", e.Expand("${{ secrets.TOKEN }}"))
+
+	// repository 1 is owned by a user
+	e, err = NewExpander(db.DefaultContext, 1, 3)
+	assert.NoError(t, err)
+	assert.EqualValues(t, "${{ secrets.TOKEN }}", e.Expand("${{ secrets.TOKEN }}"))
+}
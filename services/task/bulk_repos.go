@@ -0,0 +1,389 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/organization"
+	access_model "code.gitea.io/gitea/models/perm/access"
+	repo_model "code.gitea.io/gitea/models/repo"
+	"code.gitea.io/gitea/models/unit"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/migration"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/secret"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/modules/util"
+	"code.gitea.io/gitea/services/forms"
+	"code.gitea.io/gitea/services/migrations"
+	repo_service "code.gitea.io/gitea/services/repository"
+)
+
+// Status of the repositories of a bulk repository creation task
+const (
+	BulkRepoStatusPending   = "pending"
+	BulkRepoStatusCreated   = "created"
+	BulkRepoStatusImporting = "importing"
+	BulkRepoStatusImported  = "imported"
+	BulkRepoStatusFailed    = "failed"
+)
+
+// ErrInvalidBulkReposManifest represents an invalid bulk repository manifest
+type ErrInvalidBulkReposManifest struct {
+	Name   string
+	Reason string
+}
+
+// IsErrInvalidBulkReposManifest checks if an error is a ErrInvalidBulkReposManifest.
+func IsErrInvalidBulkReposManifest(err error) bool {
+	_, ok := err.(ErrInvalidBulkReposManifest)
+	return ok
+}
+
+func (err ErrInvalidBulkReposManifest) Error() string {
+	if err.Name == "" {
+		return fmt.Sprintf("invalid manifest: %s", err.Reason)
+	}
+	return fmt.Sprintf("invalid manifest entry '%s': %s", err.Name, err.Reason)
+}
+
+// ValidateBulkReposManifest checks that all repositories of the manifest can be created by doer in the organization
+func ValidateBulkReposManifest(ctx context.Context, doer *user_model.User, org *organization.Organization, manifest *structs.BulkRepoManifest) error {
+	if len(manifest.Repos) == 0 {
+		return ErrInvalidBulkReposManifest{Reason: "no repositories given"}
+	}
+
+	names := make(map[string]bool, len(manifest.Repos))
+	for _, entry := range manifest.Repos {
+		if entry == nil || entry.Name == "" {
+			return ErrInvalidBulkReposManifest{Reason: "repository name is required"}
+		}
+		lowerName := strings.ToLower(entry.Name)
+		if names[lowerName] {
+			return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: "repository is listed more than once"}
+		}
+		names[lowerName] = true
+
+		if err := repo_model.IsUsableRepoName(entry.Name); err != nil {
+			return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: err.Error()}
+		}
+		exist, err := repo_model.IsRepositoryExist(ctx, org.AsUser(), entry.Name)
+		if err != nil {
+			return err
+		} else if exist {
+			return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: "repository already exists"}
+		}
+
+		if entry.Template != "" && entry.CloneAddr != "" {
+			return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: "template and clone_addr cannot be used together"}
+		}
+		if entry.Template != "" {
+			if _, err := getBulkTemplateRepo(ctx, doer, entry.Template); err != nil {
+				if IsErrInvalidBulkReposManifest(err) {
+					return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: err.(ErrInvalidBulkReposManifest).Reason}
+				}
+				return err
+			}
+		}
+		if entry.CloneAddr != "" {
+			if setting.Repository.DisableMigrations {
+				return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: "the site administrator has disabled migrations"}
+			}
+			if entry.Mirror && setting.Mirror.DisableNewPull {
+				return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: "the site administrator has disabled the creation of new pull mirrors"}
+			}
+			remoteAddr, err := forms.ParseRemoteAddr(entry.CloneAddr, entry.AuthUsername, entry.AuthPassword)
+			if err == nil {
+				err = migrations.IsMigrateURLAllowed(remoteAddr, doer)
+			}
+			if err != nil {
+				if models.IsErrInvalidCloneAddr(err) {
+					return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: err.Error()}
+				}
+				return err
+			}
+		}
+		if len(entry.Teams) > 0 {
+			if canChange, err := canChangeBulkRepoTeamAccess(ctx, doer, org.AsUser()); err != nil {
+				return err
+			} else if !canChange {
+				return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: "not allowed to grant teams access to the repository"}
+			}
+		}
+		for _, teamName := range entry.Teams {
+			if _, err := organization.GetTeam(ctx, org.ID, teamName); err != nil {
+				if organization.IsErrTeamNotExist(err) {
+					return ErrInvalidBulkReposManifest{Name: entry.Name, Reason: fmt.Sprintf("team '%s' does not exist", teamName)}
+				}
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// canChangeBulkRepoTeamAccess checks if doer may grant teams access to the repositories it creates in the organization,
+// like changing the teams of a repository in its settings this requires to own the organization unless it lets
+// the repository administrators change the team access.
+func canChangeBulkRepoTeamAccess(ctx context.Context, doer, owner *user_model.User) (bool, error) {
+	if doer.IsAdmin || owner.RepoAdminChangeTeamAccess {
+		return true, nil
+	}
+	return organization.IsOrganizationOwner(ctx, owner.ID, doer.ID)
+}
+
+func getBulkTemplateRepo(ctx context.Context, doer *user_model.User, fullName string) (*repo_model.Repository, error) {
+	parts := strings.SplitN(fullName, "/", 2)
+	if len(parts) != 2 {
+		return nil, ErrInvalidBulkReposManifest{Reason: fmt.Sprintf("template '%s' must be given as owner/name", fullName)}
+	}
+	templateRepo, err := repo_model.GetRepositoryByOwnerAndNameCtx(ctx, parts[0], parts[1])
+	if err != nil {
+		if repo_model.IsErrRepoNotExist(err) {
+			return nil, ErrInvalidBulkReposManifest{Reason: fmt.Sprintf("template '%s' does not exist", fullName)}
+		}
+		return nil, err
+	}
+	perm, err := access_model.GetUserRepoPermission(ctx, templateRepo, doer)
+	if err != nil {
+		return nil, err
+	}
+	if !perm.CanRead(unit.TypeCode) {
+		return nil, ErrInvalidBulkReposManifest{Reason: fmt.Sprintf("template '%s' does not exist", fullName)}
+	}
+	if !templateRepo.IsTemplate {
+		return nil, ErrInvalidBulkReposManifest{Reason: fmt.Sprintf("'%s' is not a template repository", fullName)}
+	}
+	return templateRepo, nil
+}
+
+// CreateBulkReposTask queues a task creating or importing the repositories of the manifest in an organization
+func CreateBulkReposTask(doer *user_model.User, org *organization.Organization, manifest *structs.BulkRepoManifest) (*models.Task, error) {
+	// encrypt credentials for persistence
+	stored := &structs.BulkRepoManifest{Repos: make([]*structs.BulkRepoEntry, len(manifest.Repos))}
+	results := make([]*structs.BulkRepoResult, len(manifest.Repos))
+	for i, entry := range manifest.Repos {
+		e := *entry
+		for _, field := range []*string{&e.CloneAddr, &e.AuthPassword, &e.AuthToken} {
+			if *field == "" {
+				continue
+			}
+			encrypted, err := secret.EncryptSecret(setting.SecretKey, *field)
+			if err != nil {
+				return nil, err
+			}
+			*field = encrypted
+		}
+		stored.Repos[i] = &e
+		results[i] = &structs.BulkRepoResult{Name: entry.Name, Status: BulkRepoStatusPending}
+	}
+
+	payload, err := json.Marshal(stored)
+	if err != nil {
+		return nil, err
+	}
+	message, err := json.Marshal(results)
+	if err != nil {
+		return nil, err
+	}
+
+	task := &models.Task{
+		DoerID:         doer.ID,
+		OwnerID:        org.ID,
+		Type:           structs.TaskTypeBulkCreateRepos,
+		Status:         structs.TaskStatusQueue,
+		PayloadContent: string(payload),
+		Message:        string(message),
+	}
+	if err := models.CreateTask(task); err != nil {
+		return nil, err
+	}
+	return task, taskQueue.Push(task)
+}
+
+func runBulkCreateReposTask(t *models.Task) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("PANIC whilst trying to do bulk create repositories task: %v", e)
+			log.Critical("PANIC during runBulkCreateReposTask[%d] by DoerID[%d] for OwnerID[%d]: %v\nStacktrace: %v", t.ID, t.DoerID, t.OwnerID, e, log.Stack(2))
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		t.Status = structs.TaskStatusFinished
+		if err != nil {
+			t.Status = structs.TaskStatusFailed
+		}
+		// delete credentials when we're done, they're a liability.
+		if manifest, err := t.BulkReposManifest(); err == nil {
+			for _, entry := range manifest.Repos {
+				entry.CloneAddr = util.SanitizeCredentialURLs(entry.CloneAddr)
+				entry.AuthPassword = ""
+				entry.AuthToken = ""
+			}
+			if payload, err := json.Marshal(manifest); err == nil {
+				t.PayloadContent = string(payload)
+			}
+		}
+		if err := t.UpdateCols("status", "end_time", "payload_content"); err != nil {
+			log.Error("Task UpdateCols failed: %v", err)
+		}
+	}()
+
+	if err = t.LoadDoer(); err != nil {
+		return
+	}
+	if err = t.LoadOwner(); err != nil {
+		return
+	}
+	manifest, err := t.BulkReposManifest()
+	if err != nil {
+		return
+	}
+	results, err := t.BulkReposResults()
+	if err != nil {
+		return
+	}
+
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().ShutdownContext(), fmt.Sprintf("BulkCreateReposTask: %s", t.Owner.Name))
+	defer finished()
+
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err = t.UpdateCols("start_time", "status"); err != nil {
+		return
+	}
+
+	for i, entry := range manifest.Repos {
+		if i >= len(results) || results[i].Status != BulkRepoStatusPending {
+			// already handled before a restart
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+
+		repo, status, createErr := createBulkRepo(ctx, t.Doer, t.Owner, entry)
+		results[i].Status = status
+		if repo != nil {
+			results[i].RepoID = repo.ID
+		}
+		if createErr != nil {
+			log.Error("runBulkCreateReposTask[%d]: unable to create %s/%s: %v", t.ID, t.Owner.Name, entry.Name, createErr)
+			results[i].Status = BulkRepoStatusFailed
+			results[i].Error = util.SanitizeErrorCredentialURLs(handleCreateError(t.Owner, createErr)).Error()
+		}
+
+		message, err := json.Marshal(results)
+		if err != nil {
+			return err
+		}
+		t.Message = string(message)
+		if err = t.UpdateCols("message"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// createBulkRepo creates, generates or starts to import one repository of a manifest
+func createBulkRepo(ctx context.Context, doer, owner *user_model.User, entry *structs.BulkRepoEntry) (repo *repo_model.Repository, status string, err error) {
+	// the permissions could have changed since the manifest was validated
+	if len(entry.Teams) > 0 {
+		var canChange bool
+		if canChange, err = canChangeBulkRepoTeamAccess(ctx, doer, owner); err != nil {
+			return nil, "", err
+		} else if !canChange {
+			return nil, "", ErrInvalidBulkReposManifest{Name: entry.Name, Reason: "not allowed to grant teams access to the repository"}
+		}
+	}
+
+	switch {
+	case entry.CloneAddr != "":
+		var remoteAddr string
+		remoteAddr, err = forms.ParseRemoteAddr(entry.CloneAddr, entry.AuthUsername, entry.AuthPassword)
+		if err == nil {
+			err = migrations.IsMigrateURLAllowed(remoteAddr, doer)
+		}
+		if err != nil {
+			return nil, "", err
+		}
+		opts := migration.MigrateOptions{
+			CloneAddr:      remoteAddr,
+			RepoName:       entry.Name,
+			Description:    entry.Description,
+			Private:        entry.Private || setting.Repository.ForcePrivate,
+			Mirror:         entry.Mirror,
+			AuthUsername:   entry.AuthUsername,
+			AuthPassword:   entry.AuthPassword,
+			AuthToken:      entry.AuthToken,
+			GitServiceType: structs.PlainGitService,
+		}
+		var task *models.Task
+		if task, err = CreateMigrateTask(doer, owner, opts); err != nil {
+			return nil, "", err
+		}
+		if err = taskQueue.Push(task); err != nil {
+			return nil, "", err
+		}
+		if err = task.LoadRepo(); err != nil {
+			return nil, "", err
+		}
+		repo, status = task.Repo, BulkRepoStatusImporting
+	case entry.Template != "":
+		var templateRepo *repo_model.Repository
+		if templateRepo, err = getBulkTemplateRepo(ctx, doer, entry.Template); err != nil {
+			return nil, "", err
+		}
+		repo, err = repo_service.GenerateRepository(doer, owner, templateRepo, models.GenerateRepoOptions{
+			Name:          entry.Name,
+			DefaultBranch: entry.DefaultBranch,
+			Description:   entry.Description,
+			Private:       entry.Private,
+			GitContent:    true,
+			Topics:        true,
+			IssueLabels:   true,
+		})
+		status = BulkRepoStatusCreated
+	default:
+		opts := models.CreateRepoOptions{
+			Name:          entry.Name,
+			Description:   entry.Description,
+			IsPrivate:     entry.Private,
+			AutoInit:      entry.AutoInit,
+			DefaultBranch: entry.DefaultBranch,
+		}
+		if opts.AutoInit {
+			opts.Readme = "Default"
+		}
+		repo, err = repo_service.CreateRepository(doer, owner, opts)
+		status = BulkRepoStatusCreated
+	}
+	if err != nil {
+		return nil, "", err
+	}
+
+	for _, teamName := range entry.Teams {
+		team, err := organization.GetTeam(db.DefaultContext, owner.ID, teamName)
+		if err != nil {
+			return repo, status, err
+		}
+		if err := models.AddRepository(team, repo); err != nil {
+			return repo, status, err
+		}
+	}
+	return repo, status, nil
+}
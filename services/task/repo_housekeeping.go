@@ -0,0 +1,207 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package task
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"code.gitea.io/gitea/models"
+	repo_model "code.gitea.io/gitea/models/repo"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/indexer/stats"
+	"code.gitea.io/gitea/modules/json"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/process"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/structs"
+	"code.gitea.io/gitea/modules/timeutil"
+	repo_service "code.gitea.io/gitea/services/repository"
+	archiver_service "code.gitea.io/gitea/services/repository/archiver"
+)
+
+// The operations of a repository housekeeping task
+const (
+	HousekeepingGC                   = "gc"
+	HousekeepingClearLastCommitCache = "clear_last_commit_cache"
+	HousekeepingClearLanguageStats   = "clear_language_stats"
+	HousekeepingRegenerateArchives   = "regenerate_archives"
+)
+
+// ErrInvalidHousekeepingOperation represents an unknown repository housekeeping operation
+type ErrInvalidHousekeepingOperation struct {
+	Operation string
+}
+
+// IsErrInvalidHousekeepingOperation checks if an error is a ErrInvalidHousekeepingOperation.
+func IsErrInvalidHousekeepingOperation(err error) bool {
+	_, ok := err.(ErrInvalidHousekeepingOperation)
+	return ok
+}
+
+func (err ErrInvalidHousekeepingOperation) Error() string {
+	if err.Operation == "" {
+		return "no housekeeping operations given"
+	}
+	return fmt.Sprintf("unknown housekeeping operation '%s'", err.Operation)
+}
+
+// ErrHousekeepingTooSoon represents a housekeeping request within the minimum interval after the last one of a repository
+type ErrHousekeepingTooSoon struct {
+	RepoID     int64
+	RetryAfter time.Duration
+}
+
+// IsErrHousekeepingTooSoon checks if an error is a ErrHousekeepingTooSoon.
+func IsErrHousekeepingTooSoon(err error) bool {
+	_, ok := err.(ErrHousekeepingTooSoon)
+	return ok
+}
+
+func (err ErrHousekeepingTooSoon) Error() string {
+	return fmt.Sprintf("housekeeping of the repository has been requested too recently, retry in %s", err.RetryAfter)
+}
+
+// CreateRepoHousekeepingTask queues a task running the housekeeping operations on a repository.
+// A repository can only be housekept once per HOUSEKEEPING_MIN_INTERVAL, and never twice at the same time.
+func CreateRepoHousekeepingTask(doer *user_model.User, repo *repo_model.Repository, operations []string) (*models.Task, error) {
+	if len(operations) == 0 {
+		return nil, ErrInvalidHousekeepingOperation{}
+	}
+	seen := make(map[string]bool, len(operations))
+	unique := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		switch operation {
+		case HousekeepingGC, HousekeepingClearLastCommitCache, HousekeepingClearLanguageStats, HousekeepingRegenerateArchives:
+		default:
+			return nil, ErrInvalidHousekeepingOperation{Operation: operation}
+		}
+		if !seen[operation] {
+			seen[operation] = true
+			unique = append(unique, operation)
+		}
+	}
+
+	latest, err := models.GetLatestRepoHousekeepingTask(repo.ID)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil {
+		retryAfter := time.Until(latest.Created.AsTime().Add(setting.Repository.HousekeepingMinInterval))
+		unfinished := latest.Status == structs.TaskStatusQueue || latest.Status == structs.TaskStatusRunning
+		if unfinished && retryAfter < time.Minute {
+			retryAfter = time.Minute
+		}
+		if retryAfter > 0 {
+			return nil, ErrHousekeepingTooSoon{RepoID: repo.ID, RetryAfter: retryAfter.Round(time.Second)}
+		}
+	}
+
+	payload, err := json.Marshal(unique)
+	if err != nil {
+		return nil, err
+	}
+	task := &models.Task{
+		DoerID:         doer.ID,
+		OwnerID:        repo.OwnerID,
+		RepoID:         repo.ID,
+		Type:           structs.TaskTypeRepoHousekeeping,
+		Status:         structs.TaskStatusQueue,
+		PayloadContent: string(payload),
+	}
+	if err := models.CreateTask(task); err != nil {
+		return nil, err
+	}
+	return task, taskQueue.Push(task)
+}
+
+func runRepoHousekeepingTask(t *models.Task) (err error) {
+	defer func() {
+		if e := recover(); e != nil {
+			err = fmt.Errorf("PANIC whilst trying to do repository housekeeping task: %v", e)
+			log.Critical("PANIC during runRepoHousekeepingTask[%d] by DoerID[%d] for RepoID[%d]: %v\nStacktrace: %v", t.ID, t.DoerID, t.RepoID, e, log.Stack(2))
+		}
+
+		t.EndTime = timeutil.TimeStampNow()
+		t.Status = structs.TaskStatusFinished
+		if err != nil {
+			t.Status = structs.TaskStatusFailed
+			t.Message = err.Error()
+		}
+		if err := t.UpdateCols("status", "end_time", "message"); err != nil {
+			log.Error("Task UpdateCols failed: %v", err)
+		}
+	}()
+
+	if err = t.LoadRepo(); err != nil {
+		return
+	}
+	operations, err := t.RepoHousekeepingOperations()
+	if err != nil {
+		return
+	}
+
+	ctx, _, finished := process.GetManager().AddContext(graceful.GetManager().ShutdownContext(), fmt.Sprintf("RepoHousekeepingTask: %s", t.Repo.FullName()))
+	defer finished()
+
+	t.StartTime = timeutil.TimeStampNow()
+	t.Status = structs.TaskStatusRunning
+	if err = t.UpdateCols("start_time", "status"); err != nil {
+		return
+	}
+
+	for _, operation := range operations {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		default:
+		}
+		if err = runHousekeepingOperation(ctx, t.Repo, operation); err != nil {
+			log.Error("runRepoHousekeepingTask[%d]: %s of %s failed: %v", t.ID, operation, t.Repo.FullName(), err)
+			return fmt.Errorf("%s failed: %w", operation, err)
+		}
+	}
+	return nil
+}
+
+func runHousekeepingOperation(ctx context.Context, repo *repo_model.Repository, operation string) error {
+	switch operation {
+	case HousekeepingGC:
+		return repo_service.GitGcRepo(ctx, repo, time.Duration(setting.Git.Timeout.GC)*time.Second, setting.Git.GCArgs...)
+	case HousekeepingClearLastCommitCache:
+		return repo_service.ClearLastCommitCache(ctx, repo)
+	case HousekeepingClearLanguageStats:
+		if err := repo_model.ClearLanguageStats(ctx, repo); err != nil {
+			return err
+		}
+		return stats.UpdateRepoIndexer(repo)
+	case HousekeepingRegenerateArchives:
+		if err := archiver_service.DeleteRepoArchives(ctx, repo.ID); err != nil {
+			return err
+		}
+		if repo.IsEmpty {
+			return nil
+		}
+		gitRepo, err := git.OpenRepository(ctx, repo.RepoPath())
+		if err != nil {
+			return err
+		}
+		defer gitRepo.Close()
+		for _, ext := range []string{".zip", ".tar.gz"} {
+			request, err := archiver_service.NewRequest(repo.ID, gitRepo, repo.DefaultBranch+ext)
+			if err != nil {
+				return err
+			}
+			if _, err := archiver_service.ArchiveRepository(request); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return ErrInvalidHousekeepingOperation{Operation: operation}
+}
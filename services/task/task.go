@@ -31,6 +31,10 @@ func Run(t *models.Task) error {
 	switch t.Type {
 	case structs.TaskTypeMigrateRepo:
 		return runMigrateTask(t)
+	case structs.TaskTypeBulkCreateRepos:
+		return runBulkCreateReposTask(t)
+	case structs.TaskTypeRepoHousekeeping:
+		return runRepoHousekeepingTask(t)
 	default:
 		return fmt.Errorf("Unknown task type: %d", t.Type)
 	}
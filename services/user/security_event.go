@@ -0,0 +1,101 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"context"
+	"net/http"
+
+	"code.gitea.io/gitea/models"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/log"
+	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/services/mailer"
+)
+
+func newSecurityEvent(u *user_model.User, typ user_model.SecurityEventType, req *http.Request, detail string) *user_model.SecurityEvent {
+	userAgent := req.UserAgent()
+	return &user_model.SecurityEvent{
+		UserID:            u.ID,
+		Type:              typ,
+		DeviceFingerprint: user_model.DeviceFingerprint(userAgent),
+		IP:                req.RemoteAddr,
+		UserAgent:         userAgent,
+		Detail:            detail,
+	}
+}
+
+// IsSecurityNotifyEnabled returns true if the user is notified about the events of their security log,
+// users can opt out unless the notifications are enforced by the site admin
+func IsSecurityNotifyEnabled(u *user_model.User) (bool, error) {
+	if setting.Service.ForceSecurityNotify {
+		return true, nil
+	}
+	disabled, err := user_model.GetUserSetting(u.ID, user_model.SettingsKeySecurityNotifyDisabled)
+	if err != nil {
+		return false, err
+	}
+	return disabled != "true", nil
+}
+
+// notifySecurityEvent notifies the user about an event of their security log by email and in the web interface
+func notifySecurityEvent(ctx context.Context, u *user_model.User, event *user_model.SecurityEvent) {
+	enabled, err := IsSecurityNotifyEnabled(u)
+	if err != nil {
+		log.Error("IsSecurityNotifyEnabled [%d]: %v", u.ID, err)
+		return
+	} else if !enabled {
+		return
+	}
+
+	mailer.SendSecurityEventMail(u, event)
+	if err := models.CreateSecurityEventNotification(ctx, event); err != nil {
+		log.Error("CreateSecurityEventNotification [%s %d]: %v", event.Type, u.ID, err)
+	}
+}
+
+// RecordSecurityEvent records an event of the request in the security log of the user and notifies the user.
+// Failures are logged but never interrupt the caller.
+func RecordSecurityEvent(ctx context.Context, u *user_model.User, typ user_model.SecurityEventType, req *http.Request, detail string) {
+	event := newSecurityEvent(u, typ, req, detail)
+	if err := user_model.InsertSecurityEvent(ctx, event); err != nil {
+		log.Error("InsertSecurityEvent [%s %d]: %v", typ, u.ID, err)
+		return
+	}
+	notifySecurityEvent(ctx, u, event)
+}
+
+// RecordSignIn records a sign-in of the user from a new device or network in the security log of the user and notifies the user.
+// The first recorded device of a user isn't notified, as every user would be notified of their next sign-in otherwise.
+func RecordSignIn(ctx context.Context, u *user_model.User, req *http.Request) {
+	event := newSecurityEvent(u, user_model.SecurityEventNewDeviceSignIn, req, "")
+	known, err := user_model.IsKnownDevice(ctx, u.ID, event.DeviceFingerprint)
+	if err != nil {
+		log.Error("IsKnownDevice [%d]: %v", u.ID, err)
+		return
+	} else if known {
+		knownNetwork, err := user_model.IsKnownSignInNetwork(ctx, u.ID, event.IP)
+		if err != nil {
+			log.Error("IsKnownSignInNetwork [%d]: %v", u.ID, err)
+			return
+		} else if knownNetwork {
+			return
+		}
+		event.Type = user_model.SecurityEventNewIPSignIn
+	}
+	hasDevices, err := user_model.HasKnownDevices(ctx, u.ID)
+	if err != nil {
+		log.Error("HasKnownDevices [%d]: %v", u.ID, err)
+		return
+	}
+
+	if err := user_model.InsertSecurityEvent(ctx, event); err != nil {
+		log.Error("InsertSecurityEvent [%s %d]: %v", event.Type, u.ID, err)
+		return
+	}
+	if hasDevices {
+		notifySecurityEvent(ctx, u, event)
+	}
+}
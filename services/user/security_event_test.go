@@ -0,0 +1,70 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package user
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"code.gitea.io/gitea/models"
+	"code.gitea.io/gitea/models/db"
+	"code.gitea.io/gitea/models/unittest"
+	user_model "code.gitea.io/gitea/models/user"
+	"code.gitea.io/gitea/modules/setting"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRecordSignIn(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	u := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+
+	signIn := func(userAgent, ip string) {
+		req := httptest.NewRequest("POST", "/user/login", nil)
+		req.Header.Set("User-Agent", userAgent)
+		req.RemoteAddr = ip + ":1234"
+		RecordSignIn(db.DefaultContext, u, req)
+	}
+
+	signIn("Mozilla/5.0 (X11; Linux x86_64; rv:101.0) Gecko/20100101 Firefox/101.0", "192.0.2.1")
+	unittest.AssertCount(t, &user_model.SecurityEvent{UserID: u.ID}, 1)
+	// the first device isn't notified
+	unittest.AssertCount(t, &models.Notification{UserID: u.ID, Source: models.NotificationSourceSecurityEvent}, 0)
+
+	// signing in from a known device and network again isn't recorded, even after a browser update
+	signIn("Mozilla/5.0 (X11; Linux x86_64; rv:102.0) Gecko/20100101 Firefox/102.0", "192.0.2.17")
+	unittest.AssertCount(t, &user_model.SecurityEvent{UserID: u.ID}, 1)
+
+	signIn("Mozilla/5.0 (iPhone; CPU iPhone OS 15_5 like Mac OS X) AppleWebKit/605.1.15 Mobile/15E148", "192.0.2.1")
+	unittest.AssertCount(t, &user_model.SecurityEvent{UserID: u.ID, Type: user_model.SecurityEventNewDeviceSignIn}, 2)
+	unittest.AssertCount(t, &models.Notification{UserID: u.ID, Source: models.NotificationSourceSecurityEvent}, 1)
+
+	// a known device from a new network
+	signIn("Mozilla/5.0 (X11; Linux x86_64; rv:102.0) Gecko/20100101 Firefox/102.0", "198.51.100.1")
+	unittest.AssertCount(t, &user_model.SecurityEvent{UserID: u.ID, Type: user_model.SecurityEventNewIPSignIn}, 1)
+	unittest.AssertCount(t, &models.Notification{UserID: u.ID, Source: models.NotificationSourceSecurityEvent}, 2)
+}
+
+func TestSecurityNotifyOptOut(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	u := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 2}).(*user_model.User)
+	req := httptest.NewRequest("POST", "/user/settings/account", nil)
+
+	assert.NoError(t, user_model.SetUserSetting(u.ID, user_model.SettingsKeySecurityNotifyDisabled, "true"))
+	enabled, err := IsSecurityNotifyEnabled(u)
+	assert.NoError(t, err)
+	assert.False(t, enabled)
+	RecordSecurityEvent(db.DefaultContext, u, user_model.SecurityEventPasswordChange, req, "")
+	unittest.AssertCount(t, &user_model.SecurityEvent{UserID: u.ID, Type: user_model.SecurityEventPasswordChange}, 1)
+	unittest.AssertCount(t, &models.Notification{UserID: u.ID, Source: models.NotificationSourceSecurityEvent}, 0)
+
+	// the site admin can enforce the notifications
+	defer func(force bool) {
+		setting.Service.ForceSecurityNotify = force
+	}(setting.Service.ForceSecurityNotify)
+	setting.Service.ForceSecurityNotify = true
+	RecordSecurityEvent(db.DefaultContext, u, user_model.SecurityEventPasswordChange, req, "")
+	unittest.AssertCount(t, &models.Notification{UserID: u.ID, Source: models.NotificationSourceSecurityEvent}, 1)
+}
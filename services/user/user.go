@@ -24,6 +24,8 @@ import (
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/storage"
 	"code.gitea.io/gitea/modules/util"
+	packages_service "code.gitea.io/gitea/services/packages"
+	repo_service "code.gitea.io/gitea/services/repository"
 )
 
 // DeleteUser completely and permanently deletes everything of a user,
@@ -66,6 +68,20 @@ func DeleteUser(u *user_model.User) error {
 		return models.ErrUserOwnPackages{UID: u.ID}
 	}
 
+	if err := packages_model.DeletePolicyByOwnerID(ctx, u.ID); err != nil {
+		return fmt.Errorf("DeletePolicyByOwnerID: %v", err)
+	}
+
+	if err := user_model.DeleteQuotaByOwnerID(ctx, u.ID); err != nil {
+		return fmt.Errorf("DeleteQuotaByOwnerID: %v", err)
+	}
+
+	// the settings are deleted with the user, their cached values are removed once the deletion is committed
+	settingKeys, err := user_model.GetUserSettingKeys(ctx, u.ID)
+	if err != nil {
+		return fmt.Errorf("GetUserSettingKeys: %v", err)
+	}
+
 	if err := models.DeleteUser(ctx, u); err != nil {
 		return fmt.Errorf("DeleteUser: %v", err)
 	}
@@ -75,6 +91,8 @@ func DeleteUser(u *user_model.User) error {
 	}
 	committer.Close()
 
+	user_model.RemoveUserSettingsCache(u.ID, settingKeys)
+
 	if err = asymkey_model.RewriteAllPublicKeys(); err != nil {
 		return err
 	}
@@ -103,6 +121,40 @@ func DeleteUser(u *user_model.User) error {
 	return nil
 }
 
+// PurgeUser deletes a user together with everything that would prevent its deletion:
+// its repositories and packages are deleted and it leaves all its organizations.
+func PurgeUser(ctx context.Context, doer, u *user_model.User) error {
+	if u.IsOrganization() {
+		return fmt.Errorf("%s is an organization not a user", u.Name)
+	}
+
+	if err := repo_service.DeleteOwnerRepositories(ctx, doer, u); err != nil {
+		return err
+	}
+
+	if err := packages_service.RemoveAllPackagesOfOwner(doer, u); err != nil {
+		return fmt.Errorf("RemoveAllPackagesOfOwner: %v", err)
+	}
+
+	orgs, err := organization.FindOrgs(organization.FindOrgOptions{
+		UserID:         u.ID,
+		IncludePrivate: true,
+	})
+	if err != nil {
+		return fmt.Errorf("FindOrgs: %v", err)
+	}
+	for _, org := range orgs {
+		if err := models.RemoveOrgUser(org.ID, u.ID); err != nil {
+			if organization.IsErrLastOrgOwner(err) {
+				return err
+			}
+			return fmt.Errorf("RemoveOrgUser [%d]: %v", org.ID, err)
+		}
+	}
+
+	return DeleteUser(u)
+}
+
 // DeleteInactiveUsers deletes all inactive users and email addresses.
 func DeleteInactiveUsers(ctx context.Context, olderThan time.Duration) error {
 	users, err := user_model.GetInactiveUsers(ctx, olderThan)
@@ -60,6 +60,20 @@ func TestDeleteUser(t *testing.T) {
 	assert.Error(t, DeleteUser(org))
 }
 
+func TestPurgeUser(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+	doer := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 1}).(*user_model.User)
+	user := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 10}).(*user_model.User)
+
+	assert.NoError(t, PurgeUser(db.DefaultContext, doer, user))
+	unittest.AssertNotExistsBean(t, &user_model.User{ID: user.ID})
+	unittest.AssertNotExistsBean(t, &repo_model.Repository{OwnerID: user.ID})
+	unittest.CheckConsistencyFor(t, &user_model.User{}, &repo_model.Repository{})
+
+	org := unittest.AssertExistsAndLoadBean(t, &user_model.User{ID: 3}).(*user_model.User)
+	assert.Error(t, PurgeUser(db.DefaultContext, doer, org))
+}
+
 func TestCreateUser(t *testing.T) {
 	user := &user_model.User{
 		Name:               "GiteaBot",
@@ -11,14 +11,17 @@ import (
 	"crypto/sha256"
 	"crypto/tls"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
 
+	"code.gitea.io/gitea/models/db"
 	webhook_model "code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/graceful"
 	"code.gitea.io/gitea/modules/hostmatcher"
@@ -27,10 +30,27 @@ import (
 	"code.gitea.io/gitea/modules/proxy"
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
+	"code.gitea.io/gitea/modules/timeutil"
+	"code.gitea.io/gitea/services/secrets"
 
 	"github.com/gobwas/glob"
 )
 
+// maxRetryBackoff limits the delay between two attempts of a delivery
+const maxRetryBackoff = 24 * time.Hour
+
+// retryBackoff returns the delay before the next attempt of a delivery which failed the given number of times
+func retryBackoff(attempts int) time.Duration {
+	backoff := setting.Webhook.RetryBackoff
+	for i := 1; i < attempts && backoff < maxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	if backoff > maxRetryBackoff {
+		backoff = maxRetryBackoff
+	}
+	return backoff
+}
+
 // Deliver deliver hook task
 func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 	w, err := webhook_model.GetWebhookByID(t.HookID)
@@ -49,6 +69,22 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 
 	t.IsDelivered = true
 
+	// the url, the secrets and the client certificate of the webhook can reference the variables of the repository.
+	// Secrets are only inserted into the values which never leave Gitea, the signing secrets and the client key,
+	// otherwise whoever may edit the webhook could read them by sending them to a server of their own.
+	var expander *secrets.Expander
+	if secrets.HasReferences(w.URL) || secrets.HasReferences(w.Secret) || secrets.HasReferences(w.PreviousSecret) ||
+		secrets.HasReferences(w.ClientCert) || secrets.HasReferences(w.ClientKey) {
+		if expander, err = secrets.NewExpander(ctx, t.RepoID, w.OrgID); err != nil {
+			return err
+		}
+		w.URL = expander.ExpandVariables(w.URL)
+		w.Secret = expander.Expand(w.Secret)
+		w.PreviousSecret = expander.Expand(w.PreviousSecret)
+		w.ClientCert = expander.ExpandVariables(w.ClientCert)
+		w.ClientKey = expander.Expand(w.ClientKey)
+	}
+
 	var req *http.Request
 
 	switch w.HTTPMethod {
@@ -102,18 +138,7 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 		return fmt.Errorf("invalid http method for webhook: [%d] %v", t.ID, w.HTTPMethod)
 	}
 
-	var signatureSHA1 string
-	var signatureSHA256 string
-	if len(w.Secret) > 0 {
-		sig1 := hmac.New(sha1.New, []byte(w.Secret))
-		sig256 := hmac.New(sha256.New, []byte(w.Secret))
-		_, err = io.MultiWriter(sig1, sig256).Write([]byte(t.PayloadContent))
-		if err != nil {
-			log.Error("prepareWebhooks.sigWrite: %v", err)
-		}
-		signatureSHA1 = hex.EncodeToString(sig1.Sum(nil))
-		signatureSHA256 = hex.EncodeToString(sig256.Sum(nil))
-	}
+	signatureSHA1, signatureSHA256 := signPayload(w.Secret, t.PayloadContent)
 
 	event := t.EventType.Event()
 	eventType := string(t.EventType)
@@ -121,15 +146,34 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 	req.Header.Add("X-Gitea-Event", event)
 	req.Header.Add("X-Gitea-Event-Type", eventType)
 	req.Header.Add("X-Gitea-Signature", signatureSHA256)
+	req.Header.Add("X-Gitea-Payload-Version", strconv.Itoa(t.PayloadVersion))
 	req.Header.Add("X-Gogs-Delivery", t.UUID)
 	req.Header.Add("X-Gogs-Event", event)
 	req.Header.Add("X-Gogs-Event-Type", eventType)
 	req.Header.Add("X-Gogs-Signature", signatureSHA256)
 	req.Header.Add("X-Hub-Signature", "sha1="+signatureSHA1)
 	req.Header.Add("X-Hub-Signature-256", "sha256="+signatureSHA256)
+	// while the secret is rotated, receivers may still verify the payload with the previous one
+	if previousSecret := w.ActivePreviousSecret(); previousSecret != "" {
+		_, previousSHA256 := signPayload(previousSecret, t.PayloadContent)
+		req.Header.Add("X-Gitea-Signature-Previous", previousSHA256)
+		req.Header.Add("X-Gogs-Signature-Previous", previousSHA256)
+		req.Header.Add("X-Hub-Signature-256-Previous", "sha256="+previousSHA256)
+	}
 	req.Header["X-GitHub-Delivery"] = []string{t.UUID}
 	req.Header["X-GitHub-Event"] = []string{event}
 	req.Header["X-GitHub-Event-Type"] = []string{eventType}
+	if w.Type == webhook_model.GITEA && w.IsGitHubCompatible {
+		// GitHub-compatible webhooks use the event names of GitHub and identify the hook like GitHub
+		targetType, targetID := "repository", t.RepoID
+		if w.OrgID > 0 {
+			targetType, targetID = "organization", w.OrgID
+		}
+		req.Header["X-GitHub-Event"] = []string{t.EventType.GitHubEvent()}
+		req.Header["X-GitHub-Hook-ID"] = []string{strconv.FormatInt(w.ID, 10)}
+		req.Header["X-GitHub-Hook-Installation-Target-Type"] = []string{targetType}
+		req.Header["X-GitHub-Hook-Installation-Target-ID"] = []string{strconv.FormatInt(targetID, 10)}
+	}
 
 	// Record delivery information.
 	t.RequestInfo = &webhook_model.HookRequest{
@@ -140,6 +184,12 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 	for k, vals := range req.Header {
 		t.RequestInfo.Headers[k] = strings.Join(vals, ",")
 	}
+	if expander != nil {
+		t.RequestInfo.URL = expander.Mask(t.RequestInfo.URL)
+		for k, v := range t.RequestInfo.Headers {
+			t.RequestInfo.Headers[k] = expander.Mask(v)
+		}
+	}
 
 	t.ResponseInfo = &webhook_model.HookResponse{
 		Headers: map[string]string{},
@@ -147,14 +197,32 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 
 	defer func() {
 		t.Delivered = time.Now().UnixNano()
+		if w.IsActive && !setting.DisableWebhooks {
+			t.Attempts++
+			if !t.IsSucceed && t.Attempts <= setting.Webhook.MaxRetries {
+				// the task stays undelivered until the next attempt is due
+				t.IsDelivered = false
+				t.NextRetryUnix = timeutil.TimeStampNow().AddDuration(retryBackoff(t.Attempts))
+			}
+		}
 		if t.IsSucceed {
 			log.Trace("Hook delivered: %s", t.UUID)
 		} else if !w.IsActive {
 			log.Trace("Hook delivery skipped as webhook is inactive: %s", t.UUID)
+		} else if !t.IsDelivered {
+			log.Trace("Hook delivery failed, attempt %d will be at %s: %s", t.Attempts+1, t.NextRetryUnix.FormatLong(), t.UUID)
 		} else {
 			log.Trace("Hook delivery failed: %s", t.UUID)
 		}
 
+		if expander != nil {
+			// errors and responses may echo the request
+			t.ResponseInfo.Body = expander.Mask(t.ResponseInfo.Body)
+			for k, v := range t.ResponseInfo.Headers {
+				t.ResponseInfo.Headers[k] = expander.Mask(v)
+			}
+		}
+
 		if err := webhook_model.UpdateHookTask(t); err != nil {
 			log.Error("UpdateHookTask [%d]: %v", t.ID, err)
 		}
@@ -179,7 +247,18 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 		return nil
 	}
 
-	resp, err := webhookHTTPClient.Do(req.WithContext(ctx))
+	client, err := webhookClient(w)
+	if err != nil {
+		t.ResponseInfo.Body = fmt.Sprintf("Client certificate: %v", err)
+		return err
+	}
+	if client != webhookHTTPClient {
+		defer client.CloseIdleConnections()
+	}
+
+	start := time.Now()
+	resp, err := client.Do(req.WithContext(ctx))
+	t.Latency = time.Since(start).Milliseconds()
 	if err != nil {
 		t.ResponseInfo.Body = fmt.Sprintf("Delivery: %v", err)
 		return err
@@ -194,6 +273,7 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 	}
 
 	p, err := io.ReadAll(resp.Body)
+	t.Latency = time.Since(start).Milliseconds()
 	if err != nil {
 		t.ResponseInfo.Body = fmt.Sprintf("read body: %s", err)
 		return err
@@ -202,6 +282,53 @@ func Deliver(ctx context.Context, t *webhook_model.HookTask) error {
 	return nil
 }
 
+// signPayload returns the hex encoded SHA1 and SHA256 HMACs of the payload, or empty strings if there is no secret
+func signPayload(secret, payload string) (signatureSHA1, signatureSHA256 string) {
+	if len(secret) == 0 {
+		return "", ""
+	}
+	sig1 := hmac.New(sha1.New, []byte(secret))
+	sig256 := hmac.New(sha256.New, []byte(secret))
+	if _, err := io.MultiWriter(sig1, sig256).Write([]byte(payload)); err != nil {
+		log.Error("prepareWebhooks.sigWrite: %v", err)
+	}
+	return hex.EncodeToString(sig1.Sum(nil)), hex.EncodeToString(sig256.Sum(nil))
+}
+
+// CheckClientCert checks that the PEM encoded certificate and key of a webhook form a valid key pair.
+// Pairs referencing secrets of the repository can only be checked when they are used.
+func CheckClientCert(cert, key string) error {
+	if cert == "" && key == "" {
+		return nil
+	}
+	if cert == "" || key == "" {
+		return errors.New("both the certificate and the key are required")
+	}
+	if secrets.HasReferences(cert) || secrets.HasReferences(key) {
+		return nil
+	}
+	_, err := tls.X509KeyPair([]byte(cert), []byte(key))
+	return err
+}
+
+// webhookClient returns the client delivering to the webhook, which presents the client certificate of the webhook
+func webhookClient(w *webhook_model.Webhook) (*http.Client, error) {
+	if !w.HasClientCert() {
+		return webhookHTTPClient, nil
+	}
+
+	cert, err := tls.X509KeyPair([]byte(w.ClientCert), []byte(w.ClientKey))
+	if err != nil {
+		return nil, err
+	}
+	transport := webhookTransport.Clone()
+	transport.TLSClientConfig.Certificates = []tls.Certificate{cert}
+	return &http.Client{
+		Timeout:   webhookHTTPClient.Timeout,
+		Transport: transport,
+	}, nil
+}
+
 // populateDeliverHooks checks and delivers undelivered hooks.
 func populateDeliverHooks(ctx context.Context) {
 	select {
@@ -211,17 +338,22 @@ func populateDeliverHooks(ctx context.Context) {
 	}
 	ctx, _, finished := process.GetManager().AddTypedContext(ctx, "Service: DeliverHooks", process.SystemProcessType, true)
 	defer finished()
+	if err := DeliverDueHookTasks(ctx); err != nil {
+		log.Error("DeliverHooks: %v", err)
+	}
+}
+
+// DeliverDueHookTasks queues the undelivered hook tasks which are due, including the retries of failed deliveries
+func DeliverDueHookTasks(ctx context.Context) error {
 	tasks, err := webhook_model.FindUndeliveredHookTasks()
 	if err != nil {
-		log.Error("DeliverHooks: %v", err)
-		return
+		return err
 	}
 
-	// Update hook task status.
 	for _, t := range tasks {
 		select {
 		case <-ctx.Done():
-			return
+			return db.ErrCancelledf("before queueing the hook task %d", t.ID)
 		default:
 		}
 
@@ -229,10 +361,12 @@ func populateDeliverHooks(ctx context.Context) {
 			log.Error("DeliverHook failed [%d]: %v", t.RepoID, err)
 		}
 	}
+	return nil
 }
 
 var (
 	webhookHTTPClient *http.Client
+	webhookTransport  *http.Transport
 	once              sync.Once
 	hostMatchers      []glob.Glob
 )
@@ -272,13 +406,14 @@ func Init() error {
 	}
 	allowedHostMatcher := hostmatcher.ParseHostMatchList("webhook.ALLOWED_HOST_LIST", allowedHostListValue)
 
+	webhookTransport = &http.Transport{
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify},
+		Proxy:           webhookProxy(),
+		DialContext:     hostmatcher.NewDialContext("webhook", allowedHostMatcher, nil),
+	}
 	webhookHTTPClient = &http.Client{
-		Timeout: timeout,
-		Transport: &http.Transport{
-			TLSClientConfig: &tls.Config{InsecureSkipVerify: setting.Webhook.SkipTLSVerify},
-			Proxy:           webhookProxy(),
-			DialContext:     hostmatcher.NewDialContext("webhook", allowedHostMatcher, nil),
-		},
+		Timeout:   timeout,
+		Transport: webhookTransport,
 	}
 
 	hookQueue = queue.CreateUniqueQueue("webhook_sender", handle, "")
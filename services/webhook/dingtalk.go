@@ -154,6 +154,9 @@ func (d *DingtalkPayload) Repository(p *api.RepositoryPayload) (api.Payloader, e
 				Content: title,
 			},
 		}, nil
+	case api.HookRepoPublicized, api.HookRepoPrivatized:
+		title := fmt.Sprintf("[%s] Repository %s", p.Repository.FullName, repoVisibilityChangeText(p.Action))
+		return createDingtalkPayload(title, title, "view repository", p.Repository.HTMLURL), nil
 	}
 
 	return nil, nil
@@ -230,6 +230,10 @@ func (d *DiscordPayload) Repository(p *api.RepositoryPayload) (api.Payloader, er
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		color = redColor
+	case api.HookRepoPublicized, api.HookRepoPrivatized:
+		title = fmt.Sprintf("[%s] Repository %s", p.Repository.FullName, repoVisibilityChangeText(p.Action))
+		url = p.Repository.HTMLURL
+		color = yellowColor
 	}
 
 	return d.createPayload(p.Sender, title, "", url, color), nil
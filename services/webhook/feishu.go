@@ -140,6 +140,9 @@ func (f *FeishuPayload) Repository(p *api.RepositoryPayload) (api.Payloader, err
 	case api.HookRepoDeleted:
 		text = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		return newFeishuTextPayload(text), nil
+	case api.HookRepoPublicized, api.HookRepoPrivatized:
+		text = fmt.Sprintf("[%s] Repository %s", p.Repository.FullName, repoVisibilityChangeText(p.Action))
+		return newFeishuTextPayload(text), nil
 	}
 
 	return nil, nil
@@ -161,6 +161,14 @@ func getReleasePayloadInfo(p *api.ReleasePayload, linkFormatter linkFormatter, w
 	return text, color
 }
 
+// repoVisibilityChangeText describes a visibility change of a repository
+func repoVisibilityChangeText(action api.HookRepoAction) string {
+	if action == api.HookRepoPrivatized {
+		return "made private"
+	}
+	return "made public"
+}
+
 func getIssueCommentPayloadInfo(p *api.IssueCommentPayload, linkFormatter linkFormatter, withSender bool) (string, string, int) {
 	repoLink := linkFormatter(p.Repository.HTMLURL, p.Repository.FullName)
 	issueTitle := fmt.Sprintf("#%d %s", p.Issue.Index, p.Issue.Title)
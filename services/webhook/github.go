@@ -0,0 +1,203 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/git"
+	"code.gitea.io/gitea/modules/json"
+	api "code.gitea.io/gitea/modules/structs"
+)
+
+// GitHubPayload represents a payload in the format of GitHub webhooks
+type GitHubPayload map[string]interface{}
+
+// JSONPayload marshals the GitHubPayload to json
+func (p GitHubPayload) JSONPayload() ([]byte, error) {
+	return json.MarshalIndent(p, "", "  ")
+}
+
+var _ PayloadConvertor = GitHubPayload{}
+
+// githubIssueActions maps the issue and pull request actions which GitHub names differently
+var githubIssueActions = map[api.HookIssueAction]string{
+	api.HookIssueLabelUpdated: "labeled",
+	api.HookIssueLabelCleared: "unlabeled",
+	api.HookIssueSynchronized: "synchronize",
+}
+
+func githubIssueAction(action api.HookIssueAction) string {
+	if a, ok := githubIssueActions[action]; ok {
+		return a
+	}
+	return string(action)
+}
+
+// newGitHubPayload starts a GitHub payload from the fields of the Gitea payload,
+// which already use the names of GitHub for the repository, user, issue and pull request objects.
+func newGitHubPayload(p api.Payloader) (GitHubPayload, error) {
+	data, err := json.Marshal(p)
+	if err != nil {
+		return nil, err
+	}
+	payload := GitHubPayload{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, err
+	}
+	return payload, nil
+}
+
+// Create implements PayloadConvertor Create method
+func (GitHubPayload) Create(p *api.CreatePayload) (api.Payloader, error) {
+	payload, err := newGitHubPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	delete(payload, "sha")
+	payload["pusher_type"] = api.PusherTypeUser
+	if p.Repo != nil {
+		payload["master_branch"] = p.Repo.DefaultBranch
+		payload["description"] = p.Repo.Description
+	}
+	return payload, nil
+}
+
+// Delete implements PayloadConvertor Delete method
+func (GitHubPayload) Delete(p *api.DeletePayload) (api.Payloader, error) {
+	return newGitHubPayload(p)
+}
+
+// Fork implements PayloadConvertor Fork method
+func (GitHubPayload) Fork(p *api.ForkPayload) (api.Payloader, error) {
+	return newGitHubPayload(p)
+}
+
+// Push implements PayloadConvertor Push method
+func (GitHubPayload) Push(p *api.PushPayload) (api.Payloader, error) {
+	payload, err := newGitHubPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	delete(payload, "compare_url")
+	payload["compare"] = p.CompareURL
+	payload["created"] = p.Before == git.EmptySHA
+	payload["deleted"] = p.After == git.EmptySHA
+	// Gitea doesn't tell force pushes apart in its push payloads
+	payload["forced"] = false
+	payload["base_ref"] = nil
+	if p.Pusher != nil {
+		payload["pusher"] = map[string]string{
+			"name":  p.Pusher.UserName,
+			"email": p.Pusher.Email,
+		}
+	}
+	if commits, ok := payload["commits"].([]interface{}); ok {
+		for _, c := range commits {
+			if commit, ok := c.(map[string]interface{}); ok {
+				commit["distinct"] = true
+			}
+		}
+	}
+	return payload, nil
+}
+
+// Issue implements PayloadConvertor Issue method
+func (GitHubPayload) Issue(p *api.IssuePayload) (api.Payloader, error) {
+	payload, err := newGitHubPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	payload["action"] = githubIssueAction(p.Action)
+	return payload, nil
+}
+
+// IssueComment implements PayloadConvertor IssueComment method
+func (GitHubPayload) IssueComment(p *api.IssueCommentPayload) (api.Payloader, error) {
+	payload, err := newGitHubPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	delete(payload, "is_pull")
+	return payload, nil
+}
+
+// PullRequest implements PayloadConvertor PullRequest method
+func (GitHubPayload) PullRequest(p *api.PullRequestPayload) (api.Payloader, error) {
+	payload, err := newGitHubPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	delete(payload, "review")
+	payload["action"] = githubIssueAction(p.Action)
+	return payload, nil
+}
+
+// Review implements PayloadConvertor Review method
+func (GitHubPayload) Review(p *api.PullRequestPayload, event webhook_model.HookEventType) (api.Payloader, error) {
+	payload, err := newGitHubPayload(p)
+	if err != nil {
+		return nil, err
+	}
+
+	state := "commented"
+	switch event {
+	case webhook_model.HookEventPullRequestReviewApproved:
+		state = "approved"
+	case webhook_model.HookEventPullRequestReviewRejected:
+		state = "changes_requested"
+	}
+	review := map[string]interface{}{
+		"state": state,
+		"body":  "",
+		"user":  payload["sender"],
+	}
+	if p.Review != nil {
+		review["body"] = p.Review.Content
+	}
+	payload["action"] = "submitted"
+	payload["review"] = review
+	return payload, nil
+}
+
+// Repository implements PayloadConvertor Repository method
+func (GitHubPayload) Repository(p *api.RepositoryPayload) (api.Payloader, error) {
+	return newGitHubPayload(p)
+}
+
+// Release implements PayloadConvertor Release method
+func (GitHubPayload) Release(p *api.ReleasePayload) (api.Payloader, error) {
+	payload, err := newGitHubPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	if p.Action == api.HookReleaseUpdated {
+		payload["action"] = "edited"
+	}
+	return payload, nil
+}
+
+// packagePayload converts a package payload, GitHub calls a created package published
+func (GitHubPayload) packagePayload(p *api.PackagePayload) (api.Payloader, error) {
+	payload, err := newGitHubPayload(p)
+	if err != nil {
+		return nil, err
+	}
+	if p.Action == api.HookPackageCreated {
+		payload["action"] = "published"
+	}
+	return payload, nil
+}
+
+// GetGitHubPayload converts a payload of a Gitea webhook into the payload GitHub sends for the event
+func GetGitHubPayload(p api.Payloader, event webhook_model.HookEventType, meta string) (api.Payloader, error) {
+	switch event {
+	case webhook_model.HookEventPackage:
+		return GitHubPayload{}.packagePayload(p.(*api.PackagePayload))
+	case webhook_model.HookEventPreviewEnvironment:
+		// GitHub has no preview environments, the payload is sent as is
+		return newGitHubPayload(p)
+	}
+	return convertPayloader(GitHubPayload{}, p, event)
+}
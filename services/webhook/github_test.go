@@ -0,0 +1,126 @@
+// Copyright 2022 The Gitea Authors. All rights reserved.
+// Use of this source code is governed by a MIT-style
+// license that can be found in the LICENSE file.
+
+package webhook
+
+import (
+	"testing"
+
+	webhook_model "code.gitea.io/gitea/models/webhook"
+	"code.gitea.io/gitea/modules/git"
+	api "code.gitea.io/gitea/modules/structs"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGitHubPayload(t *testing.T) {
+	t.Run("Create", func(t *testing.T) {
+		p := createTestPayload()
+		p.Repo.DefaultBranch = "master"
+
+		pl, err := GetGitHubPayload(p, webhook_model.HookEventCreate, "")
+		require.NoError(t, err)
+		require.IsType(t, GitHubPayload{}, pl)
+
+		payload := pl.(GitHubPayload)
+		assert.Equal(t, api.PusherTypeUser, payload["pusher_type"])
+		assert.Equal(t, "master", payload["master_branch"])
+		assert.NotContains(t, payload, "sha")
+	})
+
+	t.Run("Push", func(t *testing.T) {
+		p := pushTestPayload()
+		p.Before = git.EmptySHA
+		p.CompareURL = "http://localhost:3000/test/repo/compare/2020558...2020559"
+
+		pl, err := GetGitHubPayload(p, webhook_model.HookEventPush, "")
+		require.NoError(t, err)
+
+		payload := pl.(GitHubPayload)
+		assert.Equal(t, p.CompareURL, payload["compare"])
+		assert.NotContains(t, payload, "compare_url")
+		assert.Equal(t, true, payload["created"])
+		assert.Equal(t, false, payload["deleted"])
+		assert.Equal(t, false, payload["forced"])
+		assert.Equal(t, map[string]string{"name": "user1", "email": ""}, payload["pusher"])
+		commits := payload["commits"].([]interface{})
+		require.Len(t, commits, 2)
+		assert.Equal(t, true, commits[0].(map[string]interface{})["distinct"])
+	})
+
+	t.Run("IssueLabel", func(t *testing.T) {
+		p := issueTestPayload()
+		p.Action = api.HookIssueLabelCleared
+
+		pl, err := GetGitHubPayload(p, webhook_model.HookEventIssueLabel, "")
+		require.NoError(t, err)
+		assert.Equal(t, "unlabeled", pl.(GitHubPayload)["action"])
+	})
+
+	t.Run("IssueComment", func(t *testing.T) {
+		p := pullRequestCommentTestPayload()
+
+		pl, err := GetGitHubPayload(p, webhook_model.HookEventPullRequestComment, "")
+		require.NoError(t, err)
+		assert.NotContains(t, pl.(GitHubPayload), "is_pull")
+		assert.Contains(t, pl.(GitHubPayload), "comment")
+	})
+
+	t.Run("PullRequest", func(t *testing.T) {
+		p := pullRequestTestPayload()
+		p.Action = api.HookIssueSynchronized
+
+		pl, err := GetGitHubPayload(p, webhook_model.HookEventPullRequestSync, "")
+		require.NoError(t, err)
+
+		payload := pl.(GitHubPayload)
+		assert.Equal(t, "synchronize", payload["action"])
+		assert.NotContains(t, payload, "review")
+		assert.Equal(t, "Fix bug", payload["pull_request"].(map[string]interface{})["title"])
+	})
+
+	t.Run("Review", func(t *testing.T) {
+		p := pullRequestTestPayload()
+		p.Action = api.HookIssueReviewed
+
+		pl, err := GetGitHubPayload(p, webhook_model.HookEventPullRequestReviewRejected, "")
+		require.NoError(t, err)
+
+		payload := pl.(GitHubPayload)
+		assert.Equal(t, "submitted", payload["action"])
+		review := payload["review"].(map[string]interface{})
+		assert.Equal(t, "changes_requested", review["state"])
+		assert.Equal(t, "good job", review["body"])
+	})
+
+	t.Run("Release", func(t *testing.T) {
+		p := pullReleaseTestPayload()
+		p.Action = api.HookReleaseUpdated
+
+		pl, err := GetGitHubPayload(p, webhook_model.HookEventRelease, "")
+		require.NoError(t, err)
+		assert.Equal(t, "edited", pl.(GitHubPayload)["action"])
+	})
+
+	t.Run("Package", func(t *testing.T) {
+		p := &api.PackagePayload{
+			Action:  api.HookPackageCreated,
+			Package: &api.Package{Name: "pkg"},
+		}
+
+		pl, err := GetGitHubPayload(p, webhook_model.HookEventPackage, "")
+		require.NoError(t, err)
+		assert.Equal(t, "published", pl.(GitHubPayload)["action"])
+	})
+}
+
+func TestHookEventType_GitHubEvent(t *testing.T) {
+	assert.Equal(t, "push", webhook_model.HookEventPush.GitHubEvent())
+	assert.Equal(t, "issues", webhook_model.HookEventIssueLabel.GitHubEvent())
+	assert.Equal(t, "issue_comment", webhook_model.HookEventPullRequestComment.GitHubEvent())
+	assert.Equal(t, "pull_request", webhook_model.HookEventPullRequestSync.GitHubEvent())
+	assert.Equal(t, "pull_request_review", webhook_model.HookEventPullRequestReviewApproved.GitHubEvent())
+	assert.Equal(t, "package", webhook_model.HookEventPackage.GitHubEvent())
+}
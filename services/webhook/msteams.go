@@ -253,6 +253,10 @@ func (m *MSTeamsPayload) Repository(p *api.RepositoryPayload) (api.Payloader, er
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		color = yellowColor
+	case api.HookRepoPublicized, api.HookRepoPrivatized:
+		title = fmt.Sprintf("[%s] Repository %s", p.Repository.FullName, repoVisibilityChangeText(p.Action))
+		url = p.Repository.HTMLURL
+		color = yellowColor
 	}
 
 	return createMSTeamsPayload(
@@ -255,6 +255,8 @@ func (s *SlackPayload) Repository(p *api.RepositoryPayload) (api.Payloader, erro
 		text = fmt.Sprintf("[%s] Repository created by %s", repoLink, senderLink)
 	case api.HookRepoDeleted:
 		text = fmt.Sprintf("[%s] Repository deleted by %s", repoLink, senderLink)
+	case api.HookRepoPublicized, api.HookRepoPrivatized:
+		text = fmt.Sprintf("[%s] Repository %s by %s", repoLink, repoVisibilityChangeText(p.Action), senderLink)
 	}
 
 	return s.createPayload(text, nil), nil
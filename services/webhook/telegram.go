@@ -167,6 +167,9 @@ func (t *TelegramPayload) Repository(p *api.RepositoryPayload) (api.Payloader, e
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		return createTelegramPayload(title), nil
+	case api.HookRepoPublicized, api.HookRepoPrivatized:
+		title = fmt.Sprintf(`[<a href="%s">%s</a>] Repository %s`, p.Repository.HTMLURL, p.Repository.FullName, repoVisibilityChangeText(p.Action))
+		return createTelegramPayload(title), nil
 	}
 	return nil, nil
 }
@@ -15,6 +15,8 @@ import (
 	webhook_model "code.gitea.io/gitea/models/webhook"
 	"code.gitea.io/gitea/modules/git"
 	"code.gitea.io/gitea/modules/graceful"
+	"code.gitea.io/gitea/modules/hookfilter"
+	"code.gitea.io/gitea/modules/json"
 	"code.gitea.io/gitea/modules/log"
 	"code.gitea.io/gitea/modules/queue"
 	"code.gitea.io/gitea/modules/setting"
@@ -162,6 +164,47 @@ func checkBranch(w *webhook_model.Webhook, branch string) bool {
 	return g.Match(branch)
 }
 
+func checkRepo(w *webhook_model.Webhook, repo *repo_model.Repository) bool {
+	if w.RepoFilter == "" || w.RepoFilter == "*" {
+		return true
+	}
+
+	g, err := glob.Compile(strings.ToLower(w.RepoFilter), '/')
+	if err != nil {
+		// should not really happen as RepoFilter is validated
+		log.Error("CheckRepo failed: %s", err)
+		return false
+	}
+
+	return g.Match(strings.ToLower(repo.FullName()))
+}
+
+func checkPayloadFilter(w *webhook_model.Webhook, p api.Payloader) bool {
+	if w.PayloadFilter == "" {
+		return true
+	}
+
+	filter, err := hookfilter.Compile(w.PayloadFilter)
+	if err != nil {
+		// should not really happen as PayloadFilter is validated
+		log.Error("CheckPayloadFilter failed: %s", err)
+		return false
+	}
+
+	data, err := p.JSONPayload()
+	if err != nil {
+		log.Error("JSONPayload: %v", err)
+		return false
+	}
+	var payload interface{}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Error("Unmarshal: %v", err)
+		return false
+	}
+
+	return filter.Match(payload)
+}
+
 func prepareWebhook(w *webhook_model.Webhook, repo *repo_model.Repository, event webhook_model.HookEventType, p api.Payloader) error {
 	// Skip sending if webhooks are disabled.
 	if setting.DisableWebhooks {
@@ -195,6 +238,16 @@ func prepareWebhook(w *webhook_model.Webhook, repo *repo_model.Repository, event
 		}
 	}
 
+	if !checkRepo(w, repo) {
+		log.Trace("Repository %q doesn't match repository filter %q of webhook %d, skipping", repo.FullName(), w.RepoFilter, w.ID)
+		return nil
+	}
+
+	if !checkPayloadFilter(w, p) {
+		log.Trace("Payload doesn't match payload filter %q of webhook %d, skipping", w.PayloadFilter, w.ID)
+		return nil
+	}
+
 	var payloader api.Payloader
 	var err error
 	webhook, ok := webhooks[w.Type]
@@ -203,15 +256,21 @@ func prepareWebhook(w *webhook_model.Webhook, repo *repo_model.Repository, event
 		if err != nil {
 			return fmt.Errorf("create payload for %s[%s]: %v", w.Type, event, err)
 		}
+	} else if w.Type == webhook_model.GITEA && w.IsGitHubCompatible {
+		payloader, err = GetGitHubPayload(p, event, w.Meta)
+		if err != nil {
+			return fmt.Errorf("create GitHub payload for %s[%s]: %v", w.Type, event, err)
+		}
 	} else {
 		payloader = p
 	}
 
 	if err = webhook_model.CreateHookTask(&webhook_model.HookTask{
-		RepoID:    repo.ID,
-		HookID:    w.ID,
-		Payloader: payloader,
-		EventType: event,
+		RepoID:         repo.ID,
+		HookID:         w.ID,
+		Payloader:      payloader,
+		EventType:      event,
+		PayloadVersion: w.PayloadVersionFor(event),
 	}); err != nil {
 		return fmt.Errorf("CreateHookTask: %v", err)
 	}
@@ -238,10 +297,11 @@ func prepareWebhooks(ctx context.Context, repo *repo_model.Repository, event web
 
 	// check if repo belongs to org and append additional webhooks
 	if repo.MustOwner().IsOrganization() {
-		// get hooks for org
+		// get hooks for org, webhook templates are delivered by their copies in the repositories
 		orgHooks, err := webhook_model.ListWebhooksByOpts(ctx, &webhook_model.ListWebhookOptions{
-			OrgID:    repo.OwnerID,
-			IsActive: util.OptionalBoolTrue,
+			OrgID:      repo.OwnerID,
+			IsActive:   util.OptionalBoolTrue,
+			IsTemplate: util.OptionalBoolFalse,
 		})
 		if err != nil {
 			return fmt.Errorf("GetActiveWebhooksByOrgID: %v", err)
@@ -268,6 +328,21 @@ func prepareWebhooks(ctx context.Context, repo *repo_model.Repository, event web
 	return nil
 }
 
+// RedeliverFailedHookTasks attempts all failed deliveries of a webhook again
+func RedeliverFailedHookTasks(ctx context.Context, w *webhook_model.Webhook) error {
+	repoIDs, err := webhook_model.ResetFailedHookTasks(ctx, w.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, repoID := range repoIDs {
+		if err := addToTask(repoID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // ReplayHookTask replays a webhook task
 func ReplayHookTask(w *webhook_model.Webhook, uuid string) error {
 	t, err := webhook_model.ReplayHookTask(w.ID, uuid)
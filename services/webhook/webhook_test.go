@@ -7,6 +7,7 @@ package webhook
 import (
 	"testing"
 
+	"code.gitea.io/gitea/models/db"
 	repo_model "code.gitea.io/gitea/models/repo"
 	"code.gitea.io/gitea/models/unittest"
 	webhook_model "code.gitea.io/gitea/models/webhook"
@@ -77,6 +78,79 @@ func TestPrepareWebhooksBranchFilterNoMatch(t *testing.T) {
 	}
 }
 
+func TestCheckPayloadFilter(t *testing.T) {
+	w := &webhook_model.Webhook{}
+	w.HookEvent = &webhook_model.HookEvent{}
+	p := &api.PushPayload{
+		Ref:     "refs/heads/release/1.17",
+		Commits: []*api.PayloadCommit{{Message: "fix typo"}, {Message: "[skip ci] bump version"}},
+	}
+	assert.True(t, checkPayloadFilter(w, p))
+
+	w.PayloadFilter = `ref startsWith "refs/heads/release/" && commits.message contains "[skip ci]"`
+	assert.True(t, checkPayloadFilter(w, p))
+
+	w.PayloadFilter = `ref == "refs/heads/main" || !commits`
+	assert.False(t, checkPayloadFilter(w, p))
+}
+
+func TestCheckRepo(t *testing.T) {
+	w := &webhook_model.Webhook{}
+	w.HookEvent = &webhook_model.HookEvent{}
+	repo := &repo_model.Repository{OwnerName: "User2", Name: "Repo1"}
+	assert.True(t, checkRepo(w, repo))
+
+	w.RepoFilter = "**"
+	assert.True(t, checkRepo(w, repo))
+
+	w.RepoFilter = "user2/*"
+	assert.True(t, checkRepo(w, repo))
+
+	w.RepoFilter = "{org3/*,user2/repo1}"
+	assert.True(t, checkRepo(w, repo))
+
+	w.RepoFilter = "org3/*"
+	assert.False(t, checkRepo(w, repo))
+
+	// * doesn't match across owners
+	w.RepoFilter = "user*"
+	assert.False(t, checkRepo(w, repo))
+}
+
+func TestPrepareSystemWebhooks(t *testing.T) {
+	assert.NoError(t, unittest.PrepareTestDatabase())
+
+	w := &webhook_model.Webhook{
+		IsSystemWebhook: true,
+		URL:             "https://www.example.com/system",
+		ContentType:     webhook_model.ContentTypeJSON,
+		IsActive:        true,
+		Type:            webhook_model.GITEA,
+		HookEvent: &webhook_model.HookEvent{
+			ChooseEvents: true,
+			HookEvents:   webhook_model.HookEvents{Push: true},
+			RepoFilter:   "user2/*",
+		},
+	}
+	assert.NoError(t, w.UpdateEvent())
+	assert.NoError(t, webhook_model.CreateWebhook(db.DefaultContext, w))
+
+	repo1 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 1}).(*repo_model.Repository)
+	repo3 := unittest.AssertExistsAndLoadBean(t, &repo_model.Repository{ID: 3}).(*repo_model.Repository)
+
+	assert.NoError(t, PrepareWebhooks(repo1, webhook_model.HookEventPush, &api.PushPayload{Commits: []*api.PayloadCommit{{}}}))
+	task := unittest.AssertExistsAndLoadBean(t, &webhook_model.HookTask{RepoID: repo1.ID, HookID: w.ID}).(*webhook_model.HookTask)
+	assert.Equal(t, 1, task.PayloadVersion)
+
+	// the event isn't chosen
+	assert.NoError(t, PrepareWebhooks(repo1, webhook_model.HookEventIssues, &api.IssuePayload{}))
+	unittest.AssertCount(t, &webhook_model.HookTask{HookID: w.ID}, 1)
+
+	// the repository doesn't match the filter
+	assert.NoError(t, PrepareWebhooks(repo3, webhook_model.HookEventPush, &api.PushPayload{Commits: []*api.PayloadCommit{{}}}))
+	unittest.AssertNotExistsBean(t, &webhook_model.HookTask{RepoID: repo3.ID, HookID: w.ID})
+}
+
 // TODO TestHookTask_deliver
 
 // TODO TestDeliverHooks
@@ -161,6 +161,9 @@ func (f *WechatworkPayload) Repository(p *api.RepositoryPayload) (api.Payloader,
 	case api.HookRepoDeleted:
 		title = fmt.Sprintf("[%s] Repository deleted", p.Repository.FullName)
 		return newWechatworkMarkdownPayload(title), nil
+	case api.HookRepoPublicized, api.HookRepoPrivatized:
+		title = fmt.Sprintf("[%s] Repository %s", p.Repository.FullName, repoVisibilityChangeText(p.Action))
+		return newWechatworkMarkdownPayload(title), nil
 	}
 
 	return nil, nil
@@ -4,15 +4,23 @@ const {csrfToken} = window.config;
 async function uploadFile(file, uploadUrl) {
   const formData = new FormData();
   formData.append('file', file, file.name);
+  // pasted images are optimized by the server in the background if the instance enables it
+  formData.append('optimize', 'true');
 
   const res = await fetch(uploadUrl, {
     method: 'POST',
     headers: {'X-Csrf-Token': csrfToken},
     body: formData,
   });
+  if (!res.ok) throw new Error(`upload failed with status ${res.status}`);
   return await res.json();
 }
 
+// the placeholder shown while a pasted image is being uploaded
+function uploadingPlaceholder(name) {
+  return `![Uploading ${name}…]()`;
+}
+
 function clipboardPastedImages(e) {
   if (!e.clipboardData) return [];
 
@@ -66,9 +74,17 @@ export function initCompImagePaste($target) {
       textarea.addEventListener('paste', async (e) => {
         for (const img of clipboardPastedImages(e)) {
           const name = img.name.slice(0, img.name.lastIndexOf('.'));
-          insertAtCursor(textarea, `![${name}]()`);
-          const data = await uploadFile(img, uploadUrl);
-          replaceAndKeepCursor(textarea, `![${name}]()`, `![${name}](/attachments/${data.uuid})`);
+          const placeholder = uploadingPlaceholder(name);
+          insertAtCursor(textarea, placeholder);
+          let data;
+          try {
+            data = await uploadFile(img, uploadUrl);
+          } catch (err) {
+            console.error(err);
+            replaceAndKeepCursor(textarea, placeholder, '');
+            continue;
+          }
+          replaceAndKeepCursor(textarea, placeholder, `![${name}](/attachments/${data.uuid})`);
           const input = $(`<input id="${data.uuid}" name="files" type="hidden">`).val(data.uuid);
           dropzoneFiles.appendChild(input[0]);
         }
@@ -82,9 +98,24 @@ export function initEasyMDEImagePaste(easyMDE, dropzone, files) {
   easyMDE.codemirror.on('paste', async (_, e) => {
     for (const img of clipboardPastedImages(e)) {
       const name = img.name.slice(0, img.name.lastIndexOf('.'));
-      const data = await uploadFile(img, uploadUrl);
-      const pos = easyMDE.codemirror.getCursor();
-      easyMDE.codemirror.replaceRange(`![${name}](/attachments/${data.uuid})`, pos);
+      const placeholder = uploadingPlaceholder(name);
+      const cm = easyMDE.codemirror;
+      const start = cm.getCursor();
+      cm.replaceRange(placeholder, start);
+      // the placeholder is marked to find it again even if the text around it has been edited meanwhile
+      const mark = cm.markText(start, {line: start.line, ch: start.ch + placeholder.length});
+      let data;
+      try {
+        data = await uploadFile(img, uploadUrl);
+      } catch (err) {
+        console.error(err);
+      }
+      const range = mark.find();
+      mark.clear();
+      if (range) {
+        cm.replaceRange(data ? `![${name}](/attachments/${data.uuid})` : '', range.from, range.to);
+      }
+      if (!data) continue;
       const input = $(`<input id="${data.uuid}" name="files" type="hidden">`).val(data.uuid);
       files.append(input);
     }
@@ -1,7 +1,7 @@
 import emojis from '../../../assets/emoji.json';
 
 const {assetUrlPrefix} = window.config;
-const {customEmojis} = window.config;
+const {customEmojis, customEmojiUrls} = window.config;
 
 const tempMap = {...customEmojis};
 for (const {emoji, aliases} of emojis) {
@@ -25,7 +25,8 @@ for (const key of emojiKeys) {
 export function emojiHTML(name) {
   let inner;
   if (Object.prototype.hasOwnProperty.call(customEmojis, name)) {
-    inner = `<img alt=":${name}:" src="${assetUrlPrefix}/img/emoji/${name}.png">`;
+    const src = customEmojiUrls[name] || `${assetUrlPrefix}/img/emoji/${name}.png`;
+    inner = `<img alt=":${name}:" src="${src}">`;
   } else {
     inner = emojiString(name);
   }
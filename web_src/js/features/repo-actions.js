@@ -0,0 +1,25 @@
+import $ from 'jquery';
+
+// polls the log lines of a running job and appends them to the log, the page is reloaded once the job has finished
+export function initRepoActionsJobLog() {
+  const $log = $('#actions-job-log');
+  if (!$log.length || $log.data('done') === true) return;
+
+  const url = $log.data('url');
+  let offset = Number($log.data('offset'));
+  const poll = () => {
+    $.getJSON(url, {offset}).done((data) => {
+      if (data.lines.length) {
+        $log.append(document.createTextNode(`${data.lines.join('\n')}\n`));
+        $log.scrollTop($log[0].scrollHeight);
+      }
+      offset = data.offset;
+      if (data.done) {
+        window.location.reload();
+        return;
+      }
+      setTimeout(poll, 2000);
+    });
+  };
+  setTimeout(poll, 2000);
+}
@@ -6,6 +6,8 @@ let i18nTextEdited;
 let i18nTextOptions;
 let i18nTextDeleteFromHistory;
 let i18nTextDeleteFromHistoryConfirm;
+let i18nTextPurgeFromHistory;
+let i18nTextPurgeFromHistoryConfirm;
 
 function showContentHistoryDetail(issueBaseUrl, commentId, historyId, itemTitleHtml) {
   let $dialog = $('.content-history-detail-dialog');
@@ -20,6 +22,7 @@ function showContentHistoryDetail(issueBaseUrl, commentId, historyId, itemTitleH
       ${i18nTextOptions} <i class="dropdown icon"></i>
       <div class="menu">
         <div class="item red text" data-option-item="delete">${i18nTextDeleteFromHistory}</div>
+        <div class="item red text" data-option-item="purge" style="display: none;">${i18nTextPurgeFromHistory}</div>
       </div>
     </div>
   </div>
@@ -34,9 +37,12 @@ function showContentHistoryDetail(issueBaseUrl, commentId, historyId, itemTitleH
     allowReselection: true,
     onChange(_value, _text, $item) {
       const optionItem = $item.data('option-item');
-      if (optionItem === 'delete') {
-        if (window.confirm(i18nTextDeleteFromHistoryConfirm)) {
-          $.post(`${issueBaseUrl}/content-history/soft-delete?comment_id=${commentId}&history_id=${historyId}`, {
+      if (optionItem === 'delete' || optionItem === 'purge') {
+        const [action, confirmText] = optionItem === 'delete' ?
+          ['soft-delete', i18nTextDeleteFromHistoryConfirm] :
+          ['purge', i18nTextPurgeFromHistoryConfirm];
+        if (window.confirm(confirmText)) {
+          $.post(`${issueBaseUrl}/content-history/${action}?comment_id=${commentId}&history_id=${historyId}`, {
             _csrf: csrfToken,
           }).done((resp) => {
             if (resp.ok) {
@@ -63,8 +69,14 @@ function showContentHistoryDetail(issueBaseUrl, commentId, historyId, itemTitleH
         },
       }).done((resp) => {
         $dialog.find('.content').html(resp.diffHtml);
-        // there is only one option "item[data-option-item=delete]", so the dropdown can be entirely shown/hidden.
-        if (resp.canSoftDelete) {
+        // "item[data-option-item=purge]" is only for site admins, the dropdown is hidden if no option is available.
+        if (!resp.canSoftDelete) {
+          $dialog.find('[data-option-item=delete]').hide();
+        }
+        if (resp.canPurge) {
+          $dialog.find('[data-option-item=purge]').show();
+        }
+        if (resp.canSoftDelete || resp.canPurge) {
           $dialog.find('.dialog-header-options').show();
         }
       });
@@ -124,6 +136,8 @@ export function initRepoIssueContentHistory() {
     i18nTextEdited = resp.i18n.textEdited;
     i18nTextDeleteFromHistory = resp.i18n.textDeleteFromHistory;
     i18nTextDeleteFromHistoryConfirm = resp.i18n.textDeleteFromHistoryConfirm;
+    i18nTextPurgeFromHistory = resp.i18n.textPurgeFromHistory;
+    i18nTextPurgeFromHistoryConfirm = resp.i18n.textPurgeFromHistoryConfirm;
     i18nTextOptions = resp.i18n.textOptions;
 
     if (resp.editedHistoryCountMap[0] && $itemIssue.length) {
@@ -486,6 +486,20 @@ export function initRepository() {
     });
   }
 
+  // Repo Fork
+  if ($('.repository.new.fork').length > 0) {
+    // suggest the name of the fork for the selected owner, unless another name has been entered
+    const $repoName = $('#repo_name');
+    let suggestedName = $repoName.val();
+    $('.repository.new.fork #uid').on('change', function () {
+      const forkName = $(`.repository.new.fork .owner.dropdown .item[data-value="${this.value}"]`).attr('data-fork-name');
+      if (forkName && $repoName.val() === suggestedName) {
+        $repoName.val(forkName);
+      }
+      suggestedName = forkName;
+    });
+  }
+
   // Compare or pull request
   const $repoDiff = $('.repository.diff');
   if ($repoDiff.length) {
@@ -11,6 +11,13 @@ export function initRepoRelease() {
     $(`input[name='attachment-del-${uuid}']`).attr('value', true);
     $(`#attachment-${id}`).hide();
   });
+  $(document).on('click', '.add-external-asset', (e) => {
+    e.preventDefault();
+    const $row = $('.external-assets .external-asset').last();
+    const $clone = $row.clone();
+    $clone.find('input').val('');
+    $clone.insertAfter($row);
+  });
 }
 
 